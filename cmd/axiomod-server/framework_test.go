@@ -111,3 +111,21 @@ func TestDIModule(t *testing.T) {
 	err = testApp.Stop(ctx)
 	assert.NoError(t, err)
 }
+
+func TestBootPlan(t *testing.T) {
+	plan, err := di.PlanBoot(getBootModules()...)
+	assert.NoError(t, err)
+
+	names := plan.Names()
+	assert.Equal(t, "observability", names[0])
+	assert.Equal(t, "server", names[len(names)-1], "servers start once everything else is in place")
+
+	// Every module's dependencies must be satisfiable in the planned order
+	err = fx.ValidateApp(
+		fx.Provide(func() (*config.Config, error) {
+			return config.Load("")
+		}),
+		fx.Options(plan.Options()...),
+	)
+	assert.NoError(t, err)
+}
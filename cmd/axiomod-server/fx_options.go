@@ -1,46 +1,20 @@
 package main
 
 import (
-	"github.com/axiomod/axiomod/framework/auth"
-	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
-	"github.com/axiomod/axiomod/framework/health"
-	"github.com/axiomod/axiomod/framework/middleware"
-	"github.com/axiomod/axiomod/framework/worker"
-	"github.com/axiomod/axiomod/platform/observability"
-	"github.com/axiomod/axiomod/platform/server"
+	"github.com/axiomod/axiomod/framework/di"
+	"github.com/axiomod/axiomod/platform/bootstrap"
 	"github.com/axiomod/axiomod/plugins"
-
-	"go.uber.org/fx"
 )
 
-// getModuleOptions returns all the fx.Option instances for the application modules
-func getModuleOptions() []fx.Option {
-	return []fx.Option{
-		// Core platform modules
-		observability.Module,
-		middleware.Module,
-		auth.Module,
-		health.Module,
-		grpc_pkg.Module,
-		server.Module,
-		plugins.Module,
-		worker.Module,
-
-		// Domain modules
-		// Add your domain modules here, for example:
-		// example.Module,
+// getBootModules returns the application modules with the modules each must boot after.
+// The framework modules come from the bootstrap package so tests can boot the same application.
+func getBootModules() []*di.Module {
+	modules := bootstrap.Modules()
+	modules = append(modules, plugins.BootModule().Invoke(RegisterNewPlugins))
 
-		// Register constructors for any additional dependencies
-		fx.Provide(
-		// Add your providers here
-		),
+	// Domain modules
+	// Add your domain modules here, for example:
+	// modules = append(modules, di.NewModule("example").Option(example.Module).After("middleware").WithPriority(10))
 
-		// Register invocations for any startup hooks
-		fx.Invoke(
-			// Register HTTP and gRPC servers
-			server.RegisterHTTPServer,
-			server.RegisterGRPCServer,
-			RegisterNewPlugins,
-		),
-	}
+	return modules
 }
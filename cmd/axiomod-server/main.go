@@ -9,16 +9,30 @@ import (
 	"syscall"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/di"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 func main() {
 	// Parse command line flags
 	configPath := flag.String("config", "", "path to config file")
+	printBootPlan := flag.Bool("boot-plan", false, "print the module boot plan and exit")
 	flag.Parse()
 
+	// Order the modules by their declared dependencies
+	plan, err := di.PlanBoot(getBootModules()...)
+	if err != nil {
+		fmt.Printf("Invalid module boot order: %v\n", err)
+		os.Exit(1)
+	}
+	plan.Print(os.Stdout)
+	if *printBootPlan {
+		return
+	}
+
 	// Create application context
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -39,13 +53,16 @@ func main() {
 			return config.Load(*configPath)
 		}),
 
-		// Register all modules
+		// Register all modules in boot order
 		fx.Options(
-			getModuleOptions()...,
+			plan.Options()...,
 		),
 
 		// Register lifecycle hooks
-		fx.Invoke(func(lc fx.Lifecycle, logger *observability.Logger) {
+		fx.Invoke(func(lc fx.Lifecycle, cfg *config.Config, logger *observability.Logger) {
+			for _, warning := range cfg.MigrationWarnings() {
+				logger.Warn("Configuration migrated", zap.String("change", warning))
+			}
 			lc.Append(fx.Hook{
 				OnStart: func(ctx context.Context) error {
 					logger.Info("Starting application")
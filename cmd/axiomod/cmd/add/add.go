@@ -0,0 +1,186 @@
+package add
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/textdiff"
+	"github.com/spf13/cobra"
+)
+
+// addCmd represents the add command
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a capability to an existing project",
+	Long: `Add a capability to an existing project.
+
+Each subcommand retrofits a feature into a project created with axiomod init: it adds the
+configuration sections to config/service_default.yaml, the fx module to the modules of
+cmd/<name>, and the services to docker-compose.yml. Keys, modules and services the project
+already has are left as they are, so running a subcommand twice changes nothing.
+
+Use --dry-run to print the changes as a diff without writing them.
+
+Example:
+  axiomod add kafka
+  axiomod add redis-cache --dry-run
+  axiomod add auth-oidc --dir=services/orders
+`,
+}
+
+// NewAddCmd returns the add command.
+func NewAddCmd() *cobra.Command {
+	return addCmd
+}
+
+// project is the project a capability is added to
+type project struct {
+	Dir   string // root directory, holding go.mod
+	Name  string // last element of the module path
+	Ident string // Name as an identifier, for database and realm names
+}
+
+// loadProject reads the go.mod of the project in dir
+func loadProject(dir string) (project, error) {
+	modulePath, err := readModulePath(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return project{}, fmt.Errorf("%w; run the command in a project or set --dir", err)
+	}
+	name := path.Base(modulePath)
+	return project{
+		Dir:   dir,
+		Name:  name,
+		Ident: strings.ToLower(strings.NewReplacer("-", "_", ".", "_").Replace(name)),
+	}, nil
+}
+
+// readModulePath returns the module path declared by a go.mod file
+func readModulePath(goMod string) (string, error) {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			modulePath := strings.TrimSpace(rest)
+			if unquoted, err := strconv.Unquote(modulePath); err == nil {
+				modulePath = unquoted
+			}
+			return modulePath, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module path in %s", goMod)
+}
+
+// fileChange is the new content of a file changed by a capability
+type fileChange struct {
+	Path    string
+	Old     []byte // nil when the file is created
+	New     []byte
+	Created bool
+}
+
+// addCapability adds a capability to the project of the --dir flag, printing the changes as
+// a diff instead of writing them with --dry-run
+func addCapability(cmd *cobra.Command, c capability) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	p, err := loadProject(dir)
+	if err != nil {
+		return err
+	}
+	changes, notes, err := c.changes(p)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("%s is already set up in %s\n", c.Name, p.Name)
+		return nil
+	}
+	for _, change := range changes {
+		if dryRun {
+			from := change.Path
+			if change.Created {
+				from = "/dev/null"
+			}
+			fmt.Print(textdiff.Unified(from, change.Path, change.Old, change.New))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(change.Path), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", change.Path, err)
+		}
+		if err := os.WriteFile(change.Path, change.New, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", change.Path, err)
+		}
+		if change.Created {
+			fmt.Printf("Generated file: %s\n", change.Path)
+		} else {
+			fmt.Printf("Updated file: %s\n", change.Path)
+		}
+	}
+	if dryRun && len(changes) > 0 {
+		fmt.Println("\nDry run: no files were written")
+	}
+
+	if len(notes) > 0 {
+		fmt.Println("\nRemember to:")
+		for i, note := range notes {
+			fmt.Printf("%d. %s\n", i+1, note)
+		}
+	}
+	return nil
+}
+
+// render renders a capability template with the project
+func render(name, text string, p project) ([]byte, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newCapabilityCmd returns the subcommand of a capability
+func newCapabilityCmd(c capability) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   c.Name,
+		Short: c.Short,
+		Long:  c.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := addCapability(cmd, c); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "Print the changes as a diff without writing them")
+	cmd.Flags().String("dir", ".", "Root directory of the project, holding go.mod")
+	return cmd
+}
+
+func init() {
+	for _, c := range capabilities {
+		addCmd.AddCommand(newCapabilityCmd(c))
+	}
+}
@@ -0,0 +1,169 @@
+package add
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files of testdata")
+
+// readTree returns the content of the files under dir, by slash-separated relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+// writeTree writes files under dir, by slash-separated relative path
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// findCapability returns the capability of a subcommand
+func findCapability(t *testing.T, name string) capability {
+	t.Helper()
+	for _, c := range capabilities {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no capability %s", name)
+	return capability{}
+}
+
+// TestAddCapability adds a capability to the project of testdata/<project>/input and compares
+// the result with the files of testdata/<project>/<capability>. Adding it again must change
+// nothing. Run the tests with -update to rewrite the golden files.
+func TestAddCapability(t *testing.T) {
+	tests := []struct {
+		project    string
+		capability string
+		// notes are the notes printed after the changes
+		notes []string
+	}{
+		{
+			// di.Module appended to the modules of bootstrap.Modules, and a Compose service
+			// appended after the blank line separating the services
+			project:    "bootstrap",
+			capability: "kafka",
+			notes: []string{
+				"Start Kafka with docker compose up -d kafka before starting the service.",
+				"Generate consumers with axiomod generate consumer --topic=<topic>.",
+			},
+		},
+		{
+			// auth.Module is one of the bootstrap modules, so only the configuration and
+			// Compose files change
+			project:    "bootstrap",
+			capability: "auth-oidc",
+			notes: []string{
+				"Start Keycloak with docker compose up -d keycloak, then create the shop realm and the shop client.",
+				"Set auth.oidc.clientSecret to the secret of the client.",
+				"Set http.auth.enabled to true to require a token on the HTTP API.",
+			},
+		},
+		{
+			// Option added after the fx.Options of a multi-line fx.New, the missing keys of an
+			// existing redis section merged and docker-compose.yml created
+			project:    "fx-options",
+			capability: "redis-cache",
+			notes: []string{
+				"Start Redis with docker compose up -d redis before starting the service.",
+				"Inject cache.Cache in the constructors that cache values.",
+			},
+		},
+		{
+			// Import added after a single import without a block, and option added to a
+			// one-line fx.New in the root of a project without cmd directory
+			project:    "single-import",
+			capability: "kafka",
+			notes: []string{
+				"Start Kafka with docker compose up -d kafka before starting the service.",
+				"Generate consumers with axiomod generate consumer --topic=<topic>.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.project+"/"+tt.capability, func(t *testing.T) {
+			input := readTree(t, filepath.Join("testdata", tt.project, "input"))
+			dir := t.TempDir()
+			writeTree(t, dir, input)
+
+			c := findCapability(t, tt.capability)
+			cmd := newCapabilityCmd(c)
+			require.NoError(t, cmd.Flags().Set("dir", dir))
+			require.NoError(t, addCapability(cmd, c))
+
+			got := readTree(t, dir)
+			golden := filepath.Join("testdata", tt.project, tt.capability)
+			if *update {
+				require.NoError(t, os.RemoveAll(golden))
+				writeTree(t, golden, got)
+			}
+			assert.Equal(t, readTree(t, golden), got)
+
+			// Adding the capability again changes nothing
+			p, err := loadProject(dir)
+			require.NoError(t, err)
+			changes, notes, err := c.changes(p)
+			require.NoError(t, err)
+			assert.Empty(t, changes)
+			assert.Equal(t, tt.notes, notes)
+		})
+	}
+}
+
+// TestAddCapabilityDryRun checks that --dry-run writes nothing
+func TestAddCapabilityDryRun(t *testing.T) {
+	input := readTree(t, filepath.Join("testdata", "bootstrap", "input"))
+	dir := t.TempDir()
+	writeTree(t, dir, input)
+
+	c := findCapability(t, "kafka")
+	cmd := newCapabilityCmd(c)
+	require.NoError(t, cmd.Flags().Set("dir", dir))
+	require.NoError(t, cmd.Flags().Set("dry-run", "true"))
+	require.NoError(t, addCapability(cmd, c))
+	assert.Equal(t, input, readTree(t, dir))
+}
+
+func TestAddModuleWithoutApplication(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod":          "module example.com/shop\n\ngo 1.24\n",
+		"cmd/shop/run.go": "package main\n\nfunc main() {}\n",
+	})
+
+	change, ok, err := addModule(dir, *findCapability(t, "kafka").Module)
+	require.NoError(t, err)
+	assert.Nil(t, change)
+	assert.False(t, ok)
+}
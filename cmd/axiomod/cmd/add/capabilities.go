@@ -0,0 +1,216 @@
+package add
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// capability is a feature added to a project by a subcommand of add. Config, Compose and
+// Notes are text/template templates of the project.
+type capability struct {
+	Name    string
+	Short   string
+	Long    string
+	Config  string      // YAML merged into the configuration of the project
+	Compose string      // YAML merged into docker-compose.yml
+	Module  *moduleSpec // fx module added to the application, if any
+	Notes   []string    // steps left to the user
+}
+
+// moduleSpec is an fx module of the framework added to the application
+type moduleSpec struct {
+	Name      string   // name of the di.Module
+	Path      string   // import path of the package
+	Option    string   // exported fx.Option of the package, e.g. Module
+	After     []string // di modules it boots after
+	Bootstrap bool     // already one of bootstrap.Modules
+}
+
+// configFiles are the configuration files of a project, by preference
+var configFiles = []string{"config/service_default.yaml", "framework/config/service_default.yaml"}
+
+// capabilities are the subcommands of add
+var capabilities = []capability{
+	{
+		Name:  "kafka",
+		Short: "Add the Kafka producer and consumer",
+		Long: `Add the Kafka producer and consumer of kafka.Module.
+
+Adds the kafka section of the configuration, kafka.Module to the application and a single
+node Kafka broker to docker-compose.yml.
+
+Example:
+  axiomod add kafka
+  axiomod add kafka --dry-run
+`,
+		Config: `kafka:
+  brokers:
+    - localhost:9092
+  groupId: "{{.Name}}"
+`,
+		Compose: `services:
+  kafka:
+    # A single KRaft node advertised on localhost:9092, creating topics on first use
+    image: apache/kafka:3.7.0
+    ports:
+      - "9092:9092"
+    healthcheck:
+      test: ["CMD-SHELL", "/opt/kafka/bin/kafka-topics.sh --bootstrap-server localhost:9092 --list"]
+      interval: 10s
+      timeout: 10s
+      retries: 10
+`,
+		Module: &moduleSpec{
+			Name:   "kafka",
+			Path:   "github.com/axiomod/axiomod/framework/kafka",
+			Option: "Module",
+			After:  []string{"observability"},
+		},
+		Notes: []string{
+			"Start Kafka with docker compose up -d kafka before starting the service.",
+			"Generate consumers with axiomod generate consumer --topic=<topic>.",
+		},
+	},
+	{
+		Name:  "redis-cache",
+		Short: "Add a Redis-backed cache",
+		Long: `Add a cache.Cache stored in Redis, provided by cache.RedisModule.
+
+Adds the redis section and the cache key prefix to the configuration, cache.RedisModule to
+the application and a Redis server to docker-compose.yml. The server is reported in the
+health checks of /ready.
+
+Example:
+  axiomod add redis-cache
+  axiomod add redis-cache --dry-run
+`,
+		Config: `redis:
+  addr: "localhost:6379"
+  password: ""
+  db: 0
+
+cache:
+  prefix: "{{.Name}}:cache"
+`,
+		Compose: `services:
+  redis:
+    image: redis:7-alpine
+    ports:
+      - "6379:6379"
+    healthcheck:
+      test: ["CMD", "redis-cli", "ping"]
+      interval: 5s
+      timeout: 5s
+      retries: 10
+`,
+		Module: &moduleSpec{
+			Name:   "rediscache",
+			Path:   "github.com/axiomod/axiomod/framework/cache",
+			Option: "RedisModule",
+			After:  []string{"cache"},
+		},
+		Notes: []string{
+			"Start Redis with docker compose up -d redis before starting the service.",
+			"Inject cache.Cache in the constructors that cache values.",
+		},
+	},
+	{
+		Name:  "auth-oidc",
+		Short: "Add OpenID Connect authentication with Keycloak",
+		Long: `Add OpenID Connect authentication, validating the tokens of an OIDC provider.
+
+Adds the auth.oidc section of the configuration, auth.Module to the application unless it
+boots the bootstrap modules, which include it, and a Keycloak server to docker-compose.yml
+as the provider.
+
+Example:
+  axiomod add auth-oidc
+  axiomod add auth-oidc --dry-run
+`,
+		Config: `auth:
+  oidc:
+    issuerUrl: "http://localhost:8180/realms/{{.Ident}}"
+    clientId: "{{.Name}}"
+    clientSecret: ""
+`,
+		Compose: `services:
+  keycloak:
+    # Create the {{.Ident}} realm and the {{.Name}} client in the console at http://localhost:8180
+    image: quay.io/keycloak/keycloak:24.0
+    command: start-dev
+    environment:
+      KEYCLOAK_ADMIN: admin
+      KEYCLOAK_ADMIN_PASSWORD: admin
+    ports:
+      - "8180:8080"
+`,
+		Module: &moduleSpec{
+			Name:      "auth",
+			Path:      "github.com/axiomod/axiomod/framework/auth",
+			Option:    "Module",
+			After:     []string{"observability"},
+			Bootstrap: true,
+		},
+		Notes: []string{
+			"Start Keycloak with docker compose up -d keycloak, then create the {{.Ident}} realm and the {{.Name}} client.",
+			"Set auth.oidc.clientSecret to the secret of the client.",
+			"Set http.auth.enabled to true to require a token on the HTTP API.",
+		},
+	},
+}
+
+// changes returns the changes adding the capability to a project, and the notes to print
+func (c capability) changes(p project) ([]fileChange, []string, error) {
+	var changes []fileChange
+
+	configPath := filepath.Join(p.Dir, configFiles[0])
+	for _, name := range configFiles {
+		if _, err := os.Stat(filepath.Join(p.Dir, name)); err == nil {
+			configPath = filepath.Join(p.Dir, name)
+			break
+		}
+	}
+	for _, file := range []struct{ path, template string }{
+		{configPath, c.Config},
+		{filepath.Join(p.Dir, "docker-compose.yml"), c.Compose},
+	} {
+		if file.template == "" {
+			continue
+		}
+		snippet, err := render(file.path, file.template, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		change, err := mergeYAMLFile(file.path, snippet)
+		if err != nil {
+			return nil, nil, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	var notes []string
+	if c.Module != nil {
+		change, ok, err := addModule(p.Dir, *c.Module)
+		if err != nil {
+			return nil, nil, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+		if !ok {
+			notes = append(notes, fmt.Sprintf("Add %s.%s to the fx options of your application.", path.Base(c.Module.Path), c.Module.Option))
+		}
+	}
+	for _, note := range c.Notes {
+		text, err := render(c.Name, note, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		notes = append(notes, string(text))
+	}
+	return changes, notes, nil
+}
@@ -0,0 +1,324 @@
+package add
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Import paths of the packages booting the modules of an application
+const (
+	bootstrapPath = "github.com/axiomod/axiomod/platform/bootstrap"
+	diPath        = "github.com/axiomod/axiomod/framework/di"
+	fxPath        = "go.uber.org/fx"
+)
+
+// addModule returns the change adding an fx module to the application in the cmd directory
+// of a project. Applications booting bootstrap.Modules get a di.Module appended to their
+// modules, before the return of the function building them; the others get the module as
+// an argument of fx.New. It returns false when the project has neither, and a nil change
+// when the application already has the module.
+func addModule(dir string, spec moduleSpec) (*fileChange, bool, error) {
+	files, err := goFiles(filepath.Join(dir, "cmd"))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(files) == 0 {
+		files, _ = goFiles(dir)
+	}
+
+	var sources []*goSource
+	for _, name := range files {
+		src, err := parseGoSource(name)
+		if err != nil {
+			return nil, false, err
+		}
+		sources = append(sources, src)
+	}
+	for _, src := range sources {
+		if src.appendDIModule(spec) {
+			return src.change()
+		}
+	}
+	for _, src := range sources {
+		if src.addFxOption(spec) {
+			return src.change()
+		}
+	}
+	return nil, false, nil
+}
+
+// goFiles returns the Go files under dir, without tests, sorted
+func goFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && name == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if name != dir && (strings.HasPrefix(entry.Name(), ".") || entry.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, name)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// goSource is a parsed Go file of the application, and the edits made to it
+type goSource struct {
+	path  string
+	src   []byte
+	fset  *token.FileSet
+	file  *ast.File
+	edits []textEdit
+}
+
+// textEdit inserts text at an offset of a source
+type textEdit struct {
+	offset int
+	text   string
+}
+
+func parseGoSource(name string) (*goSource, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return &goSource{path: name, src: src, fset: fset, file: file}, nil
+}
+
+// appendDIModule appends a di.Module of spec to the modules returned by a function calling
+// bootstrap.Modules, unless they already have it. It returns false when the file has no
+// such function.
+func (s *goSource) appendDIModule(spec moduleSpec) bool {
+	bootstrapName, ok := s.importName(bootstrapPath)
+	if !ok {
+		return false
+	}
+	for _, decl := range s.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		modules := ""
+		var ret *ast.ReturnStmt
+		for _, stmt := range fn.Body.List {
+			switch stmt := stmt.(type) {
+			case *ast.AssignStmt:
+				if len(stmt.Lhs) == 1 && len(stmt.Rhs) == 1 && isCall(stmt.Rhs[0], bootstrapName, "Modules") {
+					if ident, ok := stmt.Lhs[0].(*ast.Ident); ok {
+						modules = ident.Name
+					}
+				}
+			case *ast.ReturnStmt:
+				if len(stmt.Results) == 1 {
+					if ident, ok := stmt.Results[0].(*ast.Ident); ok && modules != "" && ident.Name == modules {
+						ret = stmt
+					}
+				}
+			}
+		}
+		if ret == nil {
+			continue
+		}
+		if spec.Bootstrap || s.hasDIModule(spec) {
+			return true
+		}
+
+		diName := s.addImport(diPath)
+		pkgName := s.addImport(spec.Path)
+		after := make([]string, len(spec.After))
+		for i, name := range spec.After {
+			after[i] = strconv.Quote(name)
+		}
+		module := fmt.Sprintf("%s.NewModule(%q).Option(%s.%s)", diName, spec.Name, pkgName, spec.Option)
+		if len(after) > 0 {
+			module += ".After(" + strings.Join(after, ", ") + ")"
+		}
+		line := s.lineStart(ret.Pos())
+		indent := string(s.src[line:s.fset.Position(ret.Pos()).Offset])
+		s.edits = append(s.edits, textEdit{
+			offset: line,
+			text:   fmt.Sprintf("%s%s = append(%s, %s)\n", indent, modules, modules, module),
+		})
+		return true
+	}
+	return false
+}
+
+// hasDIModule reports whether the file declares a di.Module named like spec, or refers to
+// the fx option of spec
+func (s *goSource) hasDIModule(spec moduleSpec) bool {
+	pkgName, imported := s.importName(spec.Path)
+	found := false
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewModule" && len(n.Args) == 1 {
+				if lit, ok := n.Args[0].(*ast.BasicLit); ok && lit.Value == strconv.Quote(spec.Name) {
+					found = true
+				}
+			}
+		case *ast.SelectorExpr:
+			if imported && isSelector(n, pkgName, spec.Option) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// addFxOption adds the fx option of spec to the arguments of fx.New, unless they already
+// have it. It returns false when the file does not call fx.New.
+func (s *goSource) addFxOption(spec moduleSpec) bool {
+	fxName, ok := s.importName(fxPath)
+	if !ok {
+		return false
+	}
+	var call *ast.CallExpr
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok && call == nil && isCall(c, fxName, "New") {
+			call = c
+		}
+		return call == nil
+	})
+	if call == nil {
+		return false
+	}
+	if pkgName, imported := s.importName(spec.Path); imported {
+		for _, arg := range call.Args {
+			if isSelector(arg, pkgName, spec.Option) {
+				return true
+			}
+		}
+	}
+
+	option := s.addImport(spec.Path) + "." + spec.Option
+	switch {
+	case len(call.Args) == 0:
+		s.edits = append(s.edits, textEdit{offset: s.offset(call.Rparen), text: option})
+	case s.fset.Position(call.Rparen).Line > s.fset.Position(call.Args[len(call.Args)-1].End()).Line:
+		s.edits = append(s.edits, textEdit{offset: s.lineStart(call.Rparen), text: "\t" + option + ",\n"})
+	default:
+		s.edits = append(s.edits, textEdit{offset: s.offset(call.Args[len(call.Args)-1].End()), text: ", " + option})
+	}
+	return true
+}
+
+// importName returns the name a file refers to an imported package by
+func (s *goSource) importName(importPath string) (string, bool) {
+	for _, spec := range s.file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == importPath {
+			if spec.Name != nil {
+				return spec.Name.Name, true
+			}
+			return path.Base(importPath), true
+		}
+	}
+	return "", false
+}
+
+// addImport imports a package unless the file already does, returning its name
+func (s *goSource) addImport(importPath string) string {
+	if name, ok := s.importName(importPath); ok {
+		return name
+	}
+	for _, edit := range s.edits {
+		if strings.Contains(edit.text, strconv.Quote(importPath)) {
+			return path.Base(importPath)
+		}
+	}
+
+	// The import is added to the last import declaration, turning a single import into a block
+	quoted := strconv.Quote(importPath)
+	var last *ast.GenDecl
+	for _, decl := range s.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	switch {
+	case last == nil:
+		s.edits = append(s.edits, textEdit{offset: s.offset(s.file.Name.End()), text: "\n\nimport " + quoted})
+	case last.Lparen.IsValid():
+		s.edits = append(s.edits, textEdit{offset: s.lineStart(last.Rparen), text: "\t" + quoted + "\n"})
+	default:
+		s.edits = append(s.edits,
+			textEdit{offset: s.offset(last.Specs[0].Pos()), text: "(\n\t"},
+			textEdit{offset: s.offset(last.End()), text: "\n\t" + quoted + "\n)"},
+		)
+	}
+	return path.Base(importPath)
+}
+
+// change returns the change of the edits to the file, formatted, for addModule
+func (s *goSource) change() (*fileChange, bool, error) {
+	if len(s.edits) == 0 {
+		return nil, true, nil
+	}
+	edits := append([]textEdit(nil), s.edits...)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	src := append([]byte(nil), s.src...)
+	for _, edit := range edits {
+		src = append(src[:edit.offset], append([]byte(edit.text), src[edit.offset:]...)...)
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, false, fmt.Errorf("formatting %s: %w", s.path, err)
+	}
+	return &fileChange{Path: s.path, Old: s.src, New: formatted}, true, nil
+}
+
+// offset returns the offset of a position in the source
+func (s *goSource) offset(pos token.Pos) int {
+	return s.fset.Position(pos).Offset
+}
+
+// lineStart returns the offset of the start of the line of a position
+func (s *goSource) lineStart(pos token.Pos) int {
+	offset := s.offset(pos)
+	for offset > 0 && s.src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// isCall reports whether an expression calls pkg.name
+func isCall(expr ast.Expr, pkg, name string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	return ok && isSelector(call.Fun, pkg, name)
+}
+
+// isSelector reports whether an expression is pkg.name
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"example.com/shop/internal/api"
+
+	"github.com/axiomod/axiomod/framework/di"
+	"github.com/axiomod/axiomod/platform/bootstrap"
+)
+
+// bootModules returns the modules of the application
+func bootModules() []*di.Module {
+	modules := bootstrap.Modules()
+	modules = append(modules, di.NewModule("api").Option(api.Module).After("middleware").WithPriority(10))
+
+	return modules
+}
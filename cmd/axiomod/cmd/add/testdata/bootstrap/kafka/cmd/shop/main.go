@@ -0,0 +1,7 @@
+package main
+
+import "github.com/axiomod/axiomod/platform/bootstrap"
+
+func main() {
+	bootstrap.Run(bootModules())
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"example.com/shop/internal/api"
+
+	"github.com/axiomod/axiomod/framework/di"
+	"github.com/axiomod/axiomod/framework/kafka"
+	"github.com/axiomod/axiomod/platform/bootstrap"
+)
+
+// bootModules returns the modules of the application
+func bootModules() []*di.Module {
+	modules := bootstrap.Modules()
+	modules = append(modules, di.NewModule("api").Option(api.Module).After("middleware").WithPriority(10))
+
+	modules = append(modules, di.NewModule("kafka").Option(kafka.Module).After("observability"))
+	return modules
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/axiomod/axiomod/framework/config"
+	"go.uber.org/fx"
+)
+
+func main() {
+	app := fx.New(
+		fx.Options(
+			config.Module,
+		),
+		fx.Invoke(run),
+	)
+	app.Run()
+}
+
+func run() {}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/axiomod/axiomod/framework/cache"
+	"github.com/axiomod/axiomod/framework/config"
+	"go.uber.org/fx"
+)
+
+func main() {
+	app := fx.New(
+		fx.Options(
+			config.Module,
+		),
+		fx.Invoke(run),
+		cache.RedisModule,
+	)
+	app.Run()
+}
+
+func run() {}
@@ -0,0 +1,9 @@
+package main
+
+import "go.uber.org/fx"
+
+func main() {
+	fx.New(fx.Invoke(run)).Run()
+}
+
+func run() {}
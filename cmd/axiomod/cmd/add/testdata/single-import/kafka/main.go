@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/axiomod/axiomod/framework/kafka"
+	"go.uber.org/fx"
+)
+
+func main() {
+	fx.New(fx.Invoke(run), kafka.Module).Run()
+}
+
+func run() {}
@@ -0,0 +1,195 @@
+package add
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeYAMLFile returns the change adding the keys of snippet that the YAML file at path is
+// missing, creating the file when it does not exist. It returns nil when the file already
+// has every key.
+func mergeYAMLFile(path string, snippet []byte) (*fileChange, error) {
+	old, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &fileChange{Path: path, New: snippet, Created: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	merged, err := mergeYAML(old, snippet)
+	if err != nil {
+		return nil, fmt.Errorf("merging into %s: %w", path, err)
+	}
+	if bytes.Equal(merged, old) {
+		return nil, nil
+	}
+	return &fileChange{Path: path, Old: old, New: merged}, nil
+}
+
+// mergeYAML adds the keys of snippet missing from doc. The keys are added as text, keeping
+// the comments and layout of both documents: the lines of a missing key in snippet are
+// appended to its mapping in doc, indented like the other keys of the mapping. Existing
+// values are never changed.
+func mergeYAML(doc, snippet []byte) ([]byte, error) {
+	var docRoot, snippetRoot yaml.Node
+	if err := yaml.Unmarshal(doc, &docRoot); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(snippet, &snippetRoot); err != nil {
+		return nil, fmt.Errorf("parsing snippet: %w", err)
+	}
+	if len(snippetRoot.Content) == 0 || snippetRoot.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("snippet is not a YAML mapping")
+	}
+	if len(docRoot.Content) == 0 {
+		if len(bytes.TrimSpace(doc)) == 0 {
+			return snippet, nil
+		}
+		docRoot.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	if docRoot.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("document is not a YAML mapping")
+	}
+
+	m := &yamlMerge{
+		doc:        splitLines(doc),
+		snippet:    splitLines(snippet),
+		insertions: make(map[int][]string),
+	}
+	m.merge(docRoot.Content[0], 1, len(m.doc)+1, 0, snippetRoot.Content[0], len(m.snippet)+1)
+
+	var out bytes.Buffer
+	for _, line := range m.insertions[0] {
+		out.WriteString(line + "\n")
+	}
+	for i, line := range m.doc {
+		out.WriteString(line + "\n")
+		for _, inserted := range m.insertions[i+1] {
+			out.WriteString(inserted + "\n")
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// yamlMerge holds the lines of the documents of mergeYAML, and the lines to insert after
+// each line of doc, by line number; 0 inserts before the first line
+type yamlMerge struct {
+	doc        []string
+	snippet    []string
+	insertions map[int][]string
+}
+
+// merge adds the keys of the snippet mapping src, spanning the snippet lines up to srcEnd,
+// to the doc mapping target, spanning the doc lines start to end and indented by indent
+func (m *yamlMerge) merge(target *yaml.Node, start, end, indent int, src *yaml.Node, srcEnd int) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		keyEnd := srcEnd
+		if i+2 < len(src.Content) {
+			keyEnd = src.Content[i+2].Line
+		}
+
+		j := mappingIndex(target, key.Value)
+		if j < 0 {
+			lines := reindent(trimBlankLines(m.snippet[key.Line-1:keyEnd-1]), key.Column-1, indent)
+			if m.blankSeparated(target) {
+				lines = append([]string{""}, lines...)
+			}
+			after := m.lastContentLine(start, end)
+			m.insertions[after] = append(m.insertions[after], lines...)
+			continue
+		}
+		if value.Kind != yaml.MappingNode {
+			continue
+		}
+
+		existingKey, existing := target.Content[j], target.Content[j+1]
+		existingEnd := end
+		if j+2 < len(target.Content) {
+			existingEnd = target.Content[j+2].Line
+		}
+		switch {
+		case existing.Kind == yaml.MappingNode && existing.Style&yaml.FlowStyle == 0 && len(existing.Content) > 0:
+			m.merge(existing, existingKey.Line, existingEnd, existing.Content[0].Column-1, value, keyEnd)
+		case existing.Kind == yaml.ScalarNode && existing.Tag == "!!null" && existing.Value == "":
+			// An empty key, such as "services:", takes the keys of the snippet
+			m.merge(&yaml.Node{Kind: yaml.MappingNode}, existingKey.Line, existingKey.Line+1, existingKey.Column+1, value, keyEnd)
+		}
+	}
+}
+
+// blankSeparated reports whether the last key of a mapping, with the comments above it, is
+// preceded by a blank line, as the sections of a configuration or the services of a compose
+// file are
+func (m *yamlMerge) blankSeparated(target *yaml.Node) bool {
+	if len(target.Content) < 2 {
+		return false
+	}
+	line := target.Content[len(target.Content)-2].Line - 1
+	for line >= 1 && strings.HasPrefix(strings.TrimSpace(m.doc[line-1]), "#") {
+		line--
+	}
+	return line >= 1 && strings.TrimSpace(m.doc[line-1]) == ""
+}
+
+// lastContentLine returns the last line from start to end that is neither blank nor only a
+// comment, or start when there is none
+func (m *yamlMerge) lastContentLine(start, end int) int {
+	for line := end - 1; line > start && line >= 1; line-- {
+		text := strings.TrimSpace(m.doc[line-1])
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return line
+		}
+	}
+	if start > len(m.doc) {
+		return len(m.doc)
+	}
+	return start
+}
+
+// mappingIndex returns the index of a key in the content of a mapping, or -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitLines splits a document into lines, without the newline ending the last line
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// trimBlankLines removes the blank lines ending lines
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// reindent changes the indentation of lines from one number of spaces to another
+func reindent(lines []string, from, to int) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		if spaces := len(line) - len(trimmed); spaces > from {
+			trimmed = line[from:]
+		}
+		out[i] = strings.Repeat(" ", to) + trimmed
+	}
+	return out
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/benchcmp"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultBenchBaseline is the baseline file of the bench command
+const defaultBenchBaseline = "bench.baseline.txt"
+
+// benchSuite is a set of curated benchmarks of the hot paths of the framework
+type benchSuite struct {
+	name        string
+	pkg         string
+	pattern     string
+	description string
+}
+
+// benchSuites are the suites run by the bench command, in order
+var benchSuites = []benchSuite{
+	{"middleware", "github.com/axiomod/axiomod/framework/middleware", "^BenchmarkMiddlewareChain$", "the middleware chain of the HTTP server"},
+	{"errors", "github.com/axiomod/axiomod/framework/errors", "^Benchmark(New|Wrap|WithCode)$", "error creation and wrapping with stack capture"},
+	{"circuitbreaker", "github.com/axiomod/axiomod/framework/circuitbreaker", "^BenchmarkCircuitBreaker", "circuit breaker executions"},
+	{"cache", "github.com/axiomod/axiomod/framework/cache", "^BenchmarkMemoryCache", "memory cache reads and writes"},
+}
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench [suite...]",
+	Short: "Benchmark the hot paths of the framework against a baseline",
+	Long: `Run the curated benchmarks of the hot paths of the framework and compare them against a
+baseline, failing on performance regressions.
+
+Suites:
+  middleware      the middleware chain of the HTTP server
+  errors          error creation and wrapping with stack capture
+  circuitbreaker  circuit breaker executions
+  cache           memory cache reads and writes
+
+Each benchmark runs --count times with go test -benchmem. Results are summarized by their
+median and spread and compared in the manner of benchstat: a change is only reported when a
+Mann-Whitney U test finds it significant, and significant increases of time, bytes or
+allocations per operation beyond --threshold percent are regressions, exiting with status 1.
+
+--save records the results as the new baseline. The baseline is the raw output of go test,
+so it can also be compared with benchstat. Record it on the machine that runs the comparison,
+such as the CI runner, as results of different machines do not compare.
+
+Example:
+  axiomod bench --save
+  axiomod bench
+  axiomod bench errors cache --count 10 --threshold 5
+  axiomod bench --format json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		baselinePath, _ := cmd.Flags().GetString("baseline")
+		count, _ := cmd.Flags().GetInt("count")
+		benchtime, _ := cmd.Flags().GetString("benchtime")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		save, _ := cmd.Flags().GetBool("save")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			fmt.Printf("Unsupported format %q (use text or json)\n", format)
+			os.Exit(1)
+		}
+		if count < 1 {
+			fmt.Println("--count must be at least 1")
+			os.Exit(1)
+		}
+
+		suites, err := selectBenchSuites(args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		var output bytes.Buffer
+		for _, suite := range suites {
+			if format == "text" {
+				fmt.Printf("Benchmarking %s...\n", suite.description)
+			}
+			out, err := runBenchSuite(dir, suite, count, benchtime)
+			if err != nil {
+				fmt.Printf("Benchmarks of %s failed: %v\n%s", suite.name, err, out)
+				os.Exit(1)
+			}
+			output.Write(out)
+		}
+		results, err := benchcmp.Parse(bytes.NewReader(output.Bytes()))
+		if err != nil {
+			fmt.Printf("Error reading benchmark results: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !filepath.IsAbs(baselinePath) {
+			baselinePath = filepath.Join(dir, baselinePath)
+		}
+		if save {
+			if err := os.WriteFile(baselinePath, output.Bytes(), 0644); err != nil {
+				fmt.Printf("Error writing baseline: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		baselineData, err := os.ReadFile(baselinePath)
+		if save || os.IsNotExist(err) {
+			if format == "json" {
+				writeBenchJSON(map[string]interface{}{"benchmarks": benchSummaries(results)})
+				return
+			}
+			fmt.Println()
+			if err := benchcmp.WriteResults(os.Stdout, results); err != nil {
+				fmt.Printf("Error writing results: %v\n", err)
+				os.Exit(1)
+			}
+			if save {
+				fmt.Printf("\nBaseline written to %s\n", baselinePath)
+			} else {
+				fmt.Printf("\nNo baseline at %s; record one with --save\n", baselinePath)
+			}
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error reading baseline: %v\n", err)
+			os.Exit(1)
+		}
+		baseline, err := benchcmp.Parse(bytes.NewReader(baselineData))
+		if err != nil {
+			fmt.Printf("Error reading baseline %s: %v\n", baselinePath, err)
+			os.Exit(1)
+		}
+
+		comparison := benchcmp.Compare(baseline, results, threshold/100)
+		if format == "json" {
+			writeBenchJSON(comparison)
+		} else {
+			fmt.Println()
+			if err := benchcmp.WriteComparison(os.Stdout, comparison); err != nil {
+				fmt.Printf("Error writing comparison: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if len(comparison.Regressions()) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// NewBenchCmd returns the bench command.
+func NewBenchCmd() *cobra.Command {
+	benchCmd.Flags().String("dir", ".", "Directory of the module the benchmarks run from")
+	benchCmd.Flags().String("baseline", defaultBenchBaseline, "Baseline file, relative to --dir")
+	benchCmd.Flags().Int("count", 6, "Number of runs of each benchmark")
+	benchCmd.Flags().String("benchtime", "", "Run time or iterations of each benchmark run, e.g. 2s or 1000x (default: go test's)")
+	benchCmd.Flags().Float64("threshold", 10, "Increase in percent beyond which a significant change fails the command")
+	benchCmd.Flags().Bool("save", false, "Record the results as the new baseline")
+	benchCmd.Flags().String("format", "text", "Output format: text or json")
+	return benchCmd
+}
+
+// selectBenchSuites returns the suites named, or all of them without names
+func selectBenchSuites(names []string) ([]benchSuite, error) {
+	if len(names) == 0 {
+		return benchSuites, nil
+	}
+	var selected []benchSuite
+	for _, name := range names {
+		found := false
+		for _, suite := range benchSuites {
+			if suite.name == name {
+				selected = append(selected, suite)
+				found = true
+				break
+			}
+		}
+		if !found {
+			known := make([]string, 0, len(benchSuites))
+			for _, suite := range benchSuites {
+				known = append(known, suite.name)
+			}
+			return nil, fmt.Errorf("unknown suite %q (use %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// runBenchSuite runs the benchmarks of a suite, without the tests of its package, and
+// returns the output of go test
+func runBenchSuite(dir string, suite benchSuite, count int, benchtime string) ([]byte, error) {
+	args := []string{"test", "-run", "^$", "-bench", suite.pattern, "-benchmem", "-count", strconv.Itoa(count)}
+	if benchtime != "" {
+		args = append(args, "-benchtime", benchtime)
+	}
+	args = append(args, suite.pkg)
+
+	goTest := exec.Command("go", args...)
+	goTest.Dir = dir
+	var stderr bytes.Buffer
+	goTest.Stderr = &stderr
+	out, err := goTest.Output()
+	if err != nil {
+		return append(out, stderr.Bytes()...), err
+	}
+	return out, nil
+}
+
+// benchSummary is a benchmark of the JSON output without a baseline
+type benchSummary struct {
+	Name    string                      `json:"name"`
+	Summary map[string]benchcmp.Summary `json:"summary"`
+}
+
+// benchSummaries summarizes the samples of each benchmark by unit
+func benchSummaries(results *benchcmp.Results) []benchSummary {
+	summaries := make([]benchSummary, 0, len(results.Benchmarks))
+	for _, b := range results.Benchmarks {
+		s := benchSummary{Name: b.Name, Summary: make(map[string]benchcmp.Summary)}
+		for unit, samples := range b.Samples {
+			s.Summary[unit] = benchcmp.Summarize(samples)
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+// writeBenchJSON writes v as indented JSON
+func writeBenchJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	configcmd "github.com/axiomod/axiomod/cmd/axiomod/cmd/core/config"
+
 	"github.com/spf13/cobra"
 )
 
@@ -12,10 +14,17 @@ var configCmd = &cobra.Command{
 
 Examples:
   axiomod config validate
-  axiomod config diff dev prod`,
+  axiomod config diff dev prod
+  axiomod config migrate`,
 }
 
 // NewConfigCmd returns the config command
 func NewConfigCmd() *cobra.Command {
 	return configCmd
 }
+
+func init() {
+	configCmd.AddCommand(configcmd.NewConfigValidateCmd())
+	configCmd.AddCommand(configcmd.NewConfigDiffCmd())
+	configCmd.AddCommand(configcmd.NewConfigMigrateCmd())
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	frameworkconfig "github.com/axiomod/axiomod/framework/config"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultMigrateFiles are the service configuration files migrated when none are given
+var defaultMigrateFiles = []string{
+	"framework/config/service_default.yaml",
+	"configs/service_default.yaml",
+}
+
+// configMigrateCmd represents the config migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [files...]",
+	Short: "Upgrade configuration files to the current config version",
+	Long: `Rewrite service configuration files in place, moving keys that were renamed
+between framework versions to their current names. Comments and key order are kept.
+
+Without arguments, framework/config/service_default.yaml and
+configs/service_default.yaml are migrated when they exist.
+
+Example:
+  axiomod config migrate
+  axiomod config migrate configs/production.yaml --dry-run
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		files := args
+		if len(files) == 0 {
+			for _, file := range defaultMigrateFiles {
+				if _, err := os.Stat(file); err == nil {
+					files = append(files, file)
+				}
+			}
+			if len(files) == 0 {
+				fmt.Println("No config files found.")
+				return
+			}
+		}
+
+		failed := 0
+		for _, file := range files {
+			if err := migrateFile(file, dryRun); err != nil {
+				fmt.Printf("Error migrating %s: %v\n", file, err)
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// migrateFile upgrades one configuration file, printing the changes made
+func migrateFile(file string, dryRun bool) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	migrated, result, err := frameworkconfig.MigrateYAML(data)
+	if err != nil {
+		return err
+	}
+	if !result.Changed() {
+		fmt.Printf("%s is already at config version %d\n", file, result.ToVersion)
+		return nil
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("  %s\n", warning)
+	}
+	if dryRun {
+		fmt.Printf("%s would be migrated from config version %d to %d\n", file, result.FromVersion, result.ToVersion)
+		return nil
+	}
+
+	if err := os.WriteFile(file, migrated, info.Mode().Perm()); err != nil {
+		return err
+	}
+	fmt.Printf("%s migrated from config version %d to %d\n", file, result.FromVersion, result.ToVersion)
+	return nil
+}
+
+// NewConfigMigrateCmd returns the config migrate command
+func NewConfigMigrateCmd() *cobra.Command {
+	return configMigrateCmd
+}
+
+func init() {
+	configMigrateCmd.Flags().Bool("dry-run", false, "Report the changes without writing the files")
+}
@@ -0,0 +1,478 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/version"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+)
+
+// Statuses of the doctor checks
+const (
+	doctorOK      = "ok"
+	doctorWarning = "warning"
+	doctorError   = "error"
+	doctorSkipped = "skipped"
+)
+
+// doctorTools are the tools the generated Makefile and the validators run
+var doctorTools = []struct {
+	name    string
+	install string
+	version []string
+}{
+	{"buf", "go install github.com/bufbuild/buf/cmd/buf@latest", []string{"--version"}},
+	{"golangci-lint", "go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest", []string{"--version"}},
+	{"staticcheck", "go install honnef.co/go/tools/cmd/staticcheck@latest", []string{"-version"}},
+}
+
+// serviceConfigCandidates are the service configuration files looked for, relative to the project
+var serviceConfigCandidates = []string{
+	"config/service_default.yaml",
+	"configs/service_default.yaml",
+	"framework/config/service_default.yaml",
+	"service_default.yaml",
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the development environment and the project",
+	Long: `Diagnose the development environment and the health of a project.
+
+The doctor checks that the Go toolchain satisfies the go directive of go.mod, that the
+tools run by the Makefile and the validators (buf, golangci-lint, staticcheck) are
+installed, that the replace directives of go.mod point to existing directories and
+required modules, and that the service configuration exists and loads. The database,
+Kafka brokers, tracing collector (Jaeger or OTLP) and Redis configured in it are dialed
+to check they are reachable. Each problem is printed with the step fixing it.
+
+With --format=json the checks are written as JSON, e.g. for scripts and CI. The command
+exits with status 1 when a check fails; warnings do not fail it.
+
+Example:
+  axiomod doctor
+  axiomod doctor --service-config configs/service_dev.yaml
+  axiomod doctor --offline --format=json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := doctorOptions{}
+		opts.dir, _ = cmd.Flags().GetString("dir")
+		opts.configPath, _ = cmd.Flags().GetString("service-config")
+		opts.offline, _ = cmd.Flags().GetBool("offline")
+		opts.timeout, _ = cmd.Flags().GetDuration("timeout")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			fmt.Printf("Unsupported format %q (use text or json)\n", format)
+			os.Exit(1)
+		}
+
+		report := runDoctor(opts)
+		if format == "json" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				fmt.Printf("Error writing report: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		} else {
+			printDoctorReport(report)
+		}
+		if report.Summary.Errors > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// NewDoctorCmd returns the doctor command.
+func NewDoctorCmd() *cobra.Command {
+	doctorCmd.Flags().String("dir", ".", "Project directory")
+	doctorCmd.Flags().String("service-config", "", "Service configuration file or directory (default: search the usual locations)")
+	doctorCmd.Flags().Bool("offline", false, "Do not dial the dependencies of the configuration")
+	doctorCmd.Flags().Duration("timeout", 3*time.Second, "Timeout of each dependency dial")
+	doctorCmd.Flags().String("format", "text", "Output format: text or json")
+	return doctorCmd
+}
+
+type doctorOptions struct {
+	dir        string
+	configPath string
+	offline    bool
+	timeout    time.Duration
+}
+
+// doctorReport lists the checks of the doctor command
+type doctorReport struct {
+	Checks  []doctorCheck `json:"checks"`
+	Summary doctorSummary `json:"summary"`
+}
+
+// doctorCheck is the result of a check, with the step fixing it when it did not pass
+type doctorCheck struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+type doctorSummary struct {
+	OK       int `json:"ok"`
+	Warnings int `json:"warnings"`
+	Errors   int `json:"errors"`
+	Skipped  int `json:"skipped"`
+}
+
+func (r *doctorReport) add(category, name, status, message, remediation string) {
+	r.Checks = append(r.Checks, doctorCheck{Category: category, Name: name, Status: status, Message: message, Remediation: remediation})
+	switch status {
+	case doctorOK:
+		r.Summary.OK++
+	case doctorWarning:
+		r.Summary.Warnings++
+	case doctorError:
+		r.Summary.Errors++
+	default:
+		r.Summary.Skipped++
+	}
+}
+
+// runDoctor runs every check. A check that cannot run because an earlier one failed is skipped.
+func runDoctor(opts doctorOptions) *doctorReport {
+	report := &doctorReport{Checks: []doctorCheck{}}
+
+	goMod, err := readGoMod(opts.dir)
+	if err != nil {
+		report.add("module", "go.mod", doctorError, err.Error(), "Run the doctor in a project, or create one with: axiomod init <name>")
+	}
+	checkGoToolchain(report, opts.dir, goMod)
+	if goMod != nil {
+		checkReplaces(report, opts.dir, goMod)
+	}
+	checkTools(report)
+
+	cfg, configFile := checkServiceConfig(report, opts)
+	switch {
+	case cfg == nil:
+		report.add("dependencies", "dependencies", doctorSkipped, "no service configuration was loaded", "")
+	case opts.offline:
+		report.add("dependencies", "dependencies", doctorSkipped, "not dialed with --offline", "")
+	default:
+		checkDependencies(report, cfg, configFile, opts)
+	}
+	return report
+}
+
+// printDoctorReport prints the checks by category, with the steps fixing the failed ones
+func printDoctorReport(report *doctorReport) {
+	symbols := map[string]string{doctorOK: "✅", doctorWarning: "⚠️ ", doctorError: "❌", doctorSkipped: "➖"}
+	category := ""
+	for _, check := range report.Checks {
+		if check.Category != category {
+			category = check.Category
+			fmt.Printf("\n%s\n", strings.ToUpper(category[:1])+category[1:])
+		}
+		fmt.Printf("  %s %-24s %s\n", symbols[check.Status], check.Name, check.Message)
+		if check.Remediation != "" {
+			fmt.Printf("     → %s\n", check.Remediation)
+		}
+	}
+	fmt.Printf("\n%d ok, %d warnings, %d errors, %d skipped\n",
+		report.Summary.OK, report.Summary.Warnings, report.Summary.Errors, report.Summary.Skipped)
+}
+
+// readGoMod parses the go.mod of the project. It is read without the go command, which fails
+// when go.mod requires a newer Go than the installed one.
+func readGoMod(dir string) (*modfile.File, error) {
+	path := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no go.mod in %s", dir)
+	}
+	goMod, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("go.mod cannot be parsed: %v", err)
+	}
+	return goMod, nil
+}
+
+// checkGoToolchain checks that the installed Go is at least the version go.mod requires. Unless
+// GOTOOLCHAIN is local, the go command downloads a newer toolchain, which needs network access.
+func checkGoToolchain(report *doctorReport, dir string, goMod *modfile.File) {
+	if _, err := exec.LookPath("go"); err != nil {
+		report.add("go", "go", doctorError, "Go is not installed", "Install Go from https://go.dev/dl/")
+		return
+	}
+	command := exec.Command("go", "env", "GOVERSION")
+	command.Dir = dir
+	command.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	out, err := command.Output()
+	if err != nil {
+		report.add("go", "go", doctorError, fmt.Sprintf("go env failed: %v", err), "Reinstall Go from https://go.dev/dl/")
+		return
+	}
+	installed := strings.TrimSpace(string(out))
+	if goMod == nil || goMod.Go == nil {
+		report.add("go", "go", doctorOK, installed, "")
+		return
+	}
+
+	required := "go" + goMod.Go.Version
+	if goMod.Toolchain != nil && version.Compare(goMod.Toolchain.Name, required) > 0 {
+		required = goMod.Toolchain.Name
+	}
+	if version.Compare(installed, required) >= 0 {
+		report.add("go", "go", doctorOK, fmt.Sprintf("%s (go.mod requires %s)", installed, required), "")
+		return
+	}
+	message := fmt.Sprintf("%s is older than the %s go.mod requires", installed, required)
+	if toolchain := os.Getenv("GOTOOLCHAIN"); toolchain == "local" || strings.HasSuffix(toolchain, "+local") {
+		report.add("go", "go", doctorError, message, "Install "+required+" from https://go.dev/dl/, or unset GOTOOLCHAIN=local")
+		return
+	}
+	report.add("go", "go", doctorWarning, message+"; the go command downloads it on the first build",
+		"Install "+required+" from https://go.dev/dl/ to build offline")
+}
+
+// checkReplaces checks the replace directives of go.mod: local directories must exist, and
+// replaced modules must still be required at the version the replacement was written for
+func checkReplaces(report *doctorReport, dir string, goMod *modfile.File) {
+	if len(goMod.Replace) == 0 {
+		report.add("module", "replace directives", doctorOK, "none", "")
+		return
+	}
+
+	required := make(map[string]string, len(goMod.Require))
+	for _, r := range goMod.Require {
+		required[r.Mod.Path] = r.Mod.Version
+	}
+	for _, replace := range goMod.Replace {
+		name := "replace " + replace.Old.Path
+		requiredVersion, isRequired := required[replace.Old.Path]
+		local := replace.New.Version == ""
+		switch {
+		case !isRequired:
+			report.add("module", name, doctorWarning, "replaces a module that is not required",
+				"Remove it with: go mod edit -dropreplace="+replace.Old.Path)
+		case replace.Old.Version != "" && replace.Old.Version != requiredVersion:
+			report.add("module", name, doctorWarning,
+				fmt.Sprintf("replaces %s but go.mod requires %s, so it does not apply", replace.Old.Version, requiredVersion),
+				"Update the replace directive to "+requiredVersion+" or remove it")
+		case local:
+			target := replace.New.Path
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			if _, err := os.Stat(filepath.Join(target, "go.mod")); err != nil {
+				report.add("module", name, doctorError, fmt.Sprintf("%s has no go.mod", replace.New.Path),
+					"Check out the module at "+replace.New.Path+", or remove the directive with: go mod edit -dropreplace="+replace.Old.Path)
+				continue
+			}
+			report.add("module", name, doctorWarning,
+				fmt.Sprintf("uses the local directory %s instead of %s", replace.New.Path, requiredVersion),
+				"Builds outside this checkout, e.g. Docker images and CI, cannot resolve it; remove it before releasing")
+		case replace.New.Path == replace.Old.Path && replace.New.Version != requiredVersion:
+			report.add("module", name, doctorWarning,
+				fmt.Sprintf("pins %s while go.mod requires %s", replace.New.Version, requiredVersion),
+				"Require the version instead with: go get "+replace.Old.Path+"@"+replace.New.Version)
+		default:
+			report.add("module", name, doctorOK, "uses "+replace.New.Path+" "+replace.New.Version, "")
+		}
+	}
+}
+
+// checkTools checks that the tools run by the Makefile and the validators are installed
+func checkTools(report *doctorReport) {
+	for _, tool := range doctorTools {
+		path, err := exec.LookPath(tool.name)
+		if err != nil {
+			report.add("tools", tool.name, doctorWarning, "not found in PATH", "Install it with: "+tool.install)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		out, err := exec.CommandContext(ctx, path, tool.version...).CombinedOutput()
+		cancel()
+		if err != nil {
+			report.add("tools", tool.name, doctorWarning, fmt.Sprintf("%s does not run: %v", path, err), "Reinstall it with: "+tool.install)
+			continue
+		}
+		versionLine, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		report.add("tools", tool.name, doctorOK, versionLine, "")
+	}
+}
+
+// checkServiceConfig finds and loads the service configuration, and checks the files it refers
+// to. It returns the configuration and its file, or nil when it cannot be loaded.
+func checkServiceConfig(report *doctorReport, opts doctorOptions) (*config.Config, string) {
+	configFile := opts.configPath
+	if configFile == "" {
+		for _, candidate := range serviceConfigCandidates {
+			if _, err := os.Stat(filepath.Join(opts.dir, candidate)); err == nil {
+				configFile = filepath.Join(opts.dir, candidate)
+				break
+			}
+		}
+	}
+	if configFile == "" {
+		report.add("config", "service config", doctorError, "no service_default.yaml found in config/, configs/ or the project",
+			"Create config/service_default.yaml, e.g. by copying it from a project generated with axiomod init")
+		return nil, ""
+	}
+	if _, err := os.Stat(configFile); err != nil {
+		report.add("config", "service config", doctorError, fmt.Sprintf("%s does not exist", configFile),
+			"Fix the --service-config path")
+		return nil, ""
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		report.add("config", "service config", doctorError, fmt.Sprintf("%s cannot be loaded: %v", configFile, err),
+			"Check the file with: axiomod validator config "+configFile)
+		return nil, ""
+	}
+	if warnings := cfg.MigrationWarnings(); len(warnings) > 0 {
+		report.add("config", "service config", doctorWarning,
+			fmt.Sprintf("%s is written for an older config version (%d changes on load)", configFile, len(warnings)),
+			"Upgrade it with: axiomod config migrate "+configFile)
+	} else {
+		report.add("config", "service config", doctorOK, configFile, "")
+	}
+
+	// Files the servers read at startup
+	tlsFiles := []struct {
+		name string
+		tls  config.TLSConfig
+	}{
+		{"http.tls", cfg.HTTP.TLS},
+		{"grpc.tls", cfg.GRPC.TLS},
+	}
+	for _, server := range tlsFiles {
+		if !server.tls.Enabled || (server.tls.Source != "" && server.tls.Source != "file") {
+			continue
+		}
+		for _, file := range []struct{ key, path string }{{"certFile", server.tls.CertFile}, {"keyFile", server.tls.KeyFile}} {
+			name, path := server.name+"."+file.key, file.path
+			if path == "" {
+				report.add("config", name, doctorError, "not set while TLS is enabled", "Set "+name+" in "+configFile)
+				continue
+			}
+			if _, err := os.Stat(resolveConfigPath(opts.dir, path)); err != nil {
+				report.add("config", name, doctorError, fmt.Sprintf("%s does not exist", path),
+					"Create the file, or fix "+name+" in "+configFile)
+				continue
+			}
+			report.add("config", name, doctorOK, path, "")
+		}
+	}
+	return cfg, configFile
+}
+
+// checkDependencies dials the database, Kafka brokers, tracing collector and Redis of the
+// configuration. Dependencies that are not configured are skipped.
+func checkDependencies(report *doctorReport, cfg *config.Config, configFile string, opts doctorOptions) {
+	start := "Start it"
+	if _, err := os.Stat(filepath.Join(opts.dir, "docker-compose.yml")); err == nil {
+		start = "Start it, e.g. with: docker compose up -d"
+	}
+	dial := func(name, address, settings string) {
+		conn, err := net.DialTimeout("tcp", address, opts.timeout)
+		if err != nil {
+			report.add("dependencies", name, doctorError, fmt.Sprintf("%s is not reachable: %v", address, err),
+				fmt.Sprintf("%s, or fix %s in %s", start, settings, configFile))
+			return
+		}
+		conn.Close()
+		report.add("dependencies", name, doctorOK, address+" is reachable", "")
+	}
+
+	if db := cfg.Database; db.Host != "" {
+		port := db.Port
+		if port == 0 {
+			port = map[string]int{"postgres": 5432, "pgx": 5432, "mysql": 3306}[db.Driver]
+		}
+		dial("database", net.JoinHostPort(db.Host, strconv.Itoa(port)), "database.host and database.port")
+	} else {
+		report.add("dependencies", "database", doctorSkipped, "database.host is not set", "")
+	}
+
+	if len(cfg.Kafka.Brokers) > 0 {
+		for _, broker := range cfg.Kafka.Brokers {
+			dial("kafka", broker, "kafka.brokers")
+		}
+	} else {
+		report.add("dependencies", "kafka", doctorSkipped, "kafka.brokers is not set", "")
+	}
+
+	obs := cfg.Observability
+	exporter := strings.ToLower(obs.TracingExporterType)
+	switch {
+	case !obs.TracingEnabled:
+		report.add("dependencies", "tracing", doctorSkipped, "observability.tracingEnabled is false", "")
+	case exporter == "stdout":
+		report.add("dependencies", "tracing", doctorSkipped, "traces are written to stdout", "")
+	default:
+		if exporter == "" {
+			exporter = "jaeger"
+		}
+		address, err := endpointAddress(obs.TracingURL)
+		if err != nil {
+			report.add("dependencies", "tracing ("+exporter+")", doctorError, err.Error(), "Fix observability.tracingURL in "+configFile)
+			break
+		}
+		dial("tracing ("+exporter+")", address, "observability.tracingURL")
+	}
+
+	if cfg.Redis.Addr != "" {
+		dial("redis", cfg.Redis.Addr, "redis.addr")
+	} else {
+		report.add("dependencies", "redis", doctorSkipped, "redis.addr is not set", "")
+	}
+}
+
+// endpointAddress returns the host:port of an endpoint written as a URL, such as the Jaeger
+// collector http://localhost:14268/api/traces, or as host:port, such as an OTLP endpoint
+func endpointAddress(endpoint string) (string, error) {
+	if endpoint == "" {
+		return "", fmt.Errorf("observability.tracingURL is not set")
+	}
+	if !strings.Contains(endpoint, "://") {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return "", fmt.Errorf("invalid tracing endpoint %q: %v", endpoint, err)
+		}
+		return endpoint, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid tracing endpoint %q", endpoint)
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"https": "443"}[u.Scheme]
+		if port == "" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// resolveConfigPath resolves a path of the configuration against the project directory, which
+// the service runs from
+func resolveConfigPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// errInitCancelled is returned when the project is not confirmed in init --interactive
+var errInitCancelled = errors.New("project creation cancelled")
+
+// projectPrompt asks the questions of init --interactive, reading the answers line by line
+type projectPrompt struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// newProjectPrompt creates a prompt reading answers from in and writing questions to out
+func newProjectPrompt(in io.Reader, out io.Writer) *projectPrompt {
+	return &projectPrompt{in: bufio.NewReader(in), out: out}
+}
+
+// ask asks a question and returns the trimmed answer, or def when the answer is empty
+func (p *projectPrompt) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("reading answer: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose asks to pick one of choices, by name or number; "none" is offered when optional
+func (p *projectPrompt) choose(question string, choices []string, def string, optional bool) (string, error) {
+	if optional {
+		choices = append(append([]string{}, choices...), "none")
+		if def == "" {
+			def = "none"
+		}
+	}
+	fmt.Fprintln(p.out, question)
+	for i, choice := range choices {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, choice)
+	}
+	for {
+		answer, err := p.ask("Choice", def)
+		if err != nil {
+			return "", err
+		}
+		if choice, ok := pickChoice(choices, answer); ok {
+			if choice == "none" && optional {
+				return "", nil
+			}
+			return choice, nil
+		}
+		fmt.Fprintf(p.out, "Please choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// chooseMany asks to pick any of choices, separated by commas or spaces
+func (p *projectPrompt) chooseMany(question string, choices []string, defs []string) ([]string, error) {
+	fmt.Fprintln(p.out, question)
+	for i, choice := range choices {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, choice)
+	}
+	def := "none"
+	if len(defs) > 0 {
+		def = strings.Join(defs, ",")
+	}
+	for {
+		answer, err := p.ask("Choices, separated by commas", def)
+		if err != nil {
+			return nil, err
+		}
+		picked, ok := pickChoices(choices, answer)
+		if ok {
+			return picked, nil
+		}
+		fmt.Fprintf(p.out, "Please choose any of: %s, or none\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirm asks a yes or no question
+func (p *projectPrompt) confirm(question string, def bool) (bool, error) {
+	defAnswer := "y/N"
+	if def {
+		defAnswer = "Y/n"
+	}
+	for {
+		answer, err := p.ask(question+" ("+defAnswer+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer yes or no")
+	}
+}
+
+// pickChoice returns the choice an answer names or numbers
+func pickChoice(choices []string, answer string) (string, bool) {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+		return choices[n-1], true
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(choice, answer) {
+			return choice, true
+		}
+	}
+	return "", false
+}
+
+// pickChoices returns the choices an answer names or numbers, in the order of choices
+func pickChoices(choices []string, answer string) ([]string, bool) {
+	if strings.EqualFold(strings.TrimSpace(answer), "none") {
+		return nil, true
+	}
+	selected := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		choice, ok := pickChoice(choices, field)
+		if !ok {
+			return nil, false
+		}
+		selected[choice] = true
+	}
+	var picked []string
+	for _, choice := range choices {
+		if selected[choice] {
+			picked = append(picked, choice)
+		}
+	}
+	return picked, true
+}
+
+// promptProject asks for the name, blueprint and options of a project, starting from the
+// given name, if any, and blueprint. It returns errInitCancelled when the summary is not
+// confirmed.
+func promptProject(p *projectPrompt, name, blueprintName string) (string, string, projectOptions, error) {
+	var err error
+	for name == "" {
+		if name, err = p.ask("Project name", ""); err != nil {
+			return "", "", projectOptions{}, err
+		}
+	}
+
+	fmt.Fprintln(p.out)
+	if blueprintName, err = p.choose("Template:", blueprintNames(), blueprintName, false); err != nil {
+		return "", "", projectOptions{}, err
+	}
+	blueprint := projectBlueprints[blueprintName]
+	options := blueprint.Options
+
+	fmt.Fprintln(p.out)
+	database := ""
+	if options.Database {
+		database = "postgresql"
+	}
+	if database, err = p.choose("Database:", []string{"postgresql"}, database, true); err != nil {
+		return "", "", projectOptions{}, err
+	}
+	options.Database = database != ""
+
+	fmt.Fprintln(p.out)
+	broker := ""
+	if options.Kafka {
+		broker = "kafka"
+	}
+	if broker, err = p.choose("Message broker:", []string{"kafka"}, broker, true); err != nil {
+		return "", "", projectOptions{}, err
+	}
+	options.Kafka = broker != ""
+
+	fmt.Fprintln(p.out)
+	if options.AuthProviders, err = p.chooseMany("Auth providers:", authProviders, options.AuthProviders); err != nil {
+		return "", "", projectOptions{}, err
+	}
+
+	fmt.Fprintln(p.out)
+	if options.Tracing, err = p.choose("Tracing backend:", tracingBackends, options.Tracing, true); err != nil {
+		return "", "", projectOptions{}, err
+	}
+
+	fmt.Fprintln(p.out)
+	if options.Logging, err = p.choose("Log shipping:", loggingBackends, options.Logging, true); err != nil {
+		return "", "", projectOptions{}, err
+	}
+
+	fmt.Fprintln(p.out)
+	if options.Prometheus, err = p.confirm("Scrape metrics with Prometheus?", options.Prometheus); err != nil {
+		return "", "", projectOptions{}, err
+	}
+
+	fmt.Fprintf(p.out, "\nProject %s from the %s template:\n", name, blueprintName)
+	for _, line := range [][2]string{
+		{"Database", orNone(database)},
+		{"Message broker", orNone(broker)},
+		{"Auth providers", orNone(strings.Join(options.AuthProviders, ", "))},
+		{"Tracing", orNone(options.Tracing)},
+		{"Log shipping", orNone(options.Logging)},
+		{"Prometheus", strconv.FormatBool(options.Prometheus)},
+	} {
+		fmt.Fprintf(p.out, "  %-15s %s\n", line[0]+":", line[1])
+	}
+	ok, err := p.confirm("Create the project?", true)
+	if err != nil {
+		return "", "", projectOptions{}, err
+	}
+	if !ok {
+		return "", "", projectOptions{}, errInitCancelled
+	}
+	return name, blueprintName, options, nil
+}
+
+// orNone returns s, or "none" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
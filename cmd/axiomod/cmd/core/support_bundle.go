@@ -0,0 +1,373 @@
+package core
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/version"
+
+	"github.com/spf13/cobra"
+)
+
+// redacted replaces secret values in the bundle
+const redacted = "[REDACTED]"
+
+// secretTextPatterns mask secrets embedded in free text such as log lines; the first group is kept
+var secretTextPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)(authorization:\s*)(?:bearer|basic)\s+[^\s"']+`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + redacted},
+	{regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`), "${1}" + redacted + "@"},
+}
+
+// supportBundleCmd represents the support-bundle command
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect diagnostics into an archive for bug reports",
+	Long: `Collect diagnostics about a service into a single archive to attach to bug reports.
+
+The bundle contains the effective configuration, version information, the tail of
+the given log files, goroutine and heap profiles and the health status of the running
+service, and the validator reports of the project. Passwords, tokens, keys and
+credentials in URLs are redacted. Review the archive before sharing it.
+
+Example:
+  axiomod support-bundle
+  axiomod support-bundle --service-config configs/service_default.yaml --log-file service.log
+  axiomod support-bundle --url http://localhost:8080 --token $METRICS_TOKEN -o bundle.tar.gz
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := supportBundleOptions{}
+		opts.configPath, _ = cmd.Flags().GetString("service-config")
+		opts.baseURL, _ = cmd.Flags().GetString("url")
+		opts.token, _ = cmd.Flags().GetString("token")
+		opts.logFiles, _ = cmd.Flags().GetStringSlice("log-file")
+		opts.logLines, _ = cmd.Flags().GetInt("log-lines")
+		opts.skipValidators, _ = cmd.Flags().GetBool("skip-validators")
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = fmt.Sprintf("axiomod-support-%s.tar.gz", time.Now().Format("20060102-150405"))
+		}
+
+		fmt.Println("Collecting support bundle...")
+		manifest, err := writeSupportBundle(output, opts)
+		if err != nil {
+			fmt.Printf("Error creating support bundle: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, item := range manifest.Items {
+			if item.Error != "" {
+				fmt.Printf("  %-28s skipped: %s\n", item.Name, item.Error)
+			} else {
+				fmt.Printf("  %-28s collected\n", item.Name)
+			}
+		}
+		fmt.Printf("\nSupport bundle written to %s\n", output)
+		fmt.Println("Review its contents before attaching it to a bug report.")
+	},
+}
+
+// NewSupportBundleCmd returns the support-bundle command.
+func NewSupportBundleCmd() *cobra.Command {
+	supportBundleCmd.Flags().StringP("output", "o", "", "Archive to write (default axiomod-support-<timestamp>.tar.gz)")
+	supportBundleCmd.Flags().String("service-config", "", "Service configuration file or directory (default: search the usual locations)")
+	supportBundleCmd.Flags().String("url", "http://localhost:8080", "Base URL of the running service")
+	supportBundleCmd.Flags().String("token", "", "Bearer token for the protected health and profiling endpoints")
+	supportBundleCmd.Flags().StringSlice("log-file", nil, "Log file to include; can be repeated")
+	supportBundleCmd.Flags().Int("log-lines", 1000, "Number of trailing lines to include from each log file")
+	supportBundleCmd.Flags().Bool("skip-validators", false, "Do not run the validators")
+	return supportBundleCmd
+}
+
+type supportBundleOptions struct {
+	configPath     string
+	baseURL        string
+	token          string
+	logFiles       []string
+	logLines       int
+	skipValidators bool
+}
+
+// bundleManifest describes what a bundle contains and what could not be collected
+type bundleManifest struct {
+	CreatedAt time.Time    `json:"created_at"`
+	Items     []bundleItem `json:"items"`
+}
+
+type bundleItem struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// writeSupportBundle collects every item into a gzipped tar archive. Items that cannot be
+// collected are recorded in the manifest instead of failing the bundle.
+func writeSupportBundle(output string, opts supportBundleOptions) (*bundleManifest, error) {
+	f, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	manifest := &bundleManifest{CreatedAt: time.Now().UTC()}
+
+	add := func(name string, collect func() ([]byte, error)) error {
+		data, err := collect()
+		if err != nil {
+			manifest.Items = append(manifest.Items, bundleItem{Name: name, Error: err.Error()})
+			return nil
+		}
+		manifest.Items = append(manifest.Items, bundleItem{Name: name})
+		return addBundleFile(tw, name, data)
+	}
+
+	steps := []struct {
+		name    string
+		collect func() ([]byte, error)
+	}{
+		{"config.json", func() ([]byte, error) { return collectConfig(opts.configPath) }},
+		{"versions.json", collectVersions},
+		{"health.json", func() ([]byte, error) { return fetchDiagnostics(opts, "/ready") }},
+		{"profiles/goroutine.txt", func() ([]byte, error) { return fetchDiagnostics(opts, "/debug/pprof/goroutine?debug=2") }},
+		{"profiles/heap.pprof", func() ([]byte, error) { return fetchDiagnostics(opts, "/debug/pprof/heap") }},
+	}
+	for _, step := range steps {
+		if err := add(step.name, step.collect); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, logFile := range opts.logFiles {
+		logFile := logFile
+		name := "logs/" + filepath.Base(logFile)
+		if err := add(name, func() ([]byte, error) { return tailLog(logFile, opts.logLines) }); err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.skipValidators {
+		if err := add("validators.txt", collectValidatorReports); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := addBundleFile(tw, "manifest.json", data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func addBundleFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    "axiomod-support/" + name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// collectConfig loads the effective configuration, including environment overrides, and redacts secrets
+func collectConfig(configPath string) ([]byte, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var values interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(redactValue("", values), "", "  ")
+}
+
+// redactValue replaces the values of secret keys and credentials embedded in strings
+func redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = redactValue(k, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(key, child)
+		}
+		return v
+	case string:
+		if v != "" && config.IsSecretKey(key) {
+			return redacted
+		}
+		return redactText(v)
+	default:
+		return v
+	}
+}
+
+// secretSettingPattern matches the settings of free text, such as password=... or "token": "...",
+// whose values are masked when config.IsSecretKey names them secrets
+var secretSettingPattern = regexp.MustCompile(`([\w.-]+)("?\s*[:=]\s*"?)([^\s",&]+)`)
+
+// redactText masks secrets found in free text
+func redactText(s string) string {
+	for _, secret := range secretTextPatterns {
+		s = secret.pattern.ReplaceAllString(s, secret.replacement)
+	}
+	return secretSettingPattern.ReplaceAllStringFunc(s, func(setting string) string {
+		parts := secretSettingPattern.FindStringSubmatch(setting)
+		if !config.IsSecretKey(parts[1]) {
+			return setting
+		}
+		return parts[1] + parts[2] + redacted
+	})
+}
+
+// collectVersions reports the CLI, Go and framework versions
+func collectVersions() ([]byte, error) {
+	versions := map[string]interface{}{
+		"cli":      version.GetInfo(),
+		"go":       runtime.Version(),
+		"platform": runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if out, err := exec.Command("go", "version").Output(); err == nil {
+		versions["go_toolchain"] = strings.TrimSpace(string(out))
+	}
+	// Read the requirements without resolving modules, which could modify go.sum
+	if out, err := exec.Command("go", "mod", "edit", "-json").Output(); err == nil {
+		var goMod struct {
+			Module  struct{ Path string }
+			Go      string
+			Require []struct{ Path, Version string }
+		}
+		if json.Unmarshal(out, &goMod) == nil {
+			versions["module"] = goMod.Module.Path
+			versions["go_directive"] = goMod.Go
+			requirements := make(map[string]string, len(goMod.Require))
+			for _, r := range goMod.Require {
+				requirements[r.Path] = r.Version
+			}
+			versions["requirements"] = requirements
+		}
+	}
+	return json.MarshalIndent(versions, "", "  ")
+}
+
+// fetchDiagnostics retrieves a diagnostics endpoint of the running service
+func fetchDiagnostics(opts supportBundleOptions, path string) ([]byte, error) {
+	base, err := url.Parse(opts.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service URL: %w", err)
+	}
+	endpoint, err := base.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// The readiness probe reports an unhealthy service with 503, which is worth collecting
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return []byte(redactText(string(data))), nil
+	}
+	return data, nil
+}
+
+// tailLog returns the last n lines of a log file with secrets redacted
+func tailLog(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, redactText(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// collectValidatorReports runs the validators through this binary and captures their report
+func collectValidatorReports() ([]byte, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	// Validators exit with an error when they find violations; the report is what matters
+	out, _ := exec.Command(self, "validator", "all").CombinedOutput()
+	if len(out) == 0 {
+		return nil, fmt.Errorf("validators produced no output")
+	}
+	return []byte(redactText(string(out))), nil
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactText(t *testing.T) {
+	tests := map[string]string{
+		"Authorization: Bearer abc.def":          "Authorization: [REDACTED]",
+		"sent bearer abc.def":                    "sent bearer [REDACTED]",
+		"db_password=hunter2&user=bob":           "db_password=[REDACTED]&user=bob",
+		`{"clientSecret": "s3cret", "id": "42"}`: `{"clientSecret": "[REDACTED]", "id": "42"}`,
+		"dsn=postgres://bob:hunter2@db/app":      "dsn=[REDACTED]",
+		"url=postgres://bob:hunter2@db/app":      "url=postgres://bob:[REDACTED]@db/app",
+		"level=info msg=started":                 "level=info msg=started",
+	}
+	for text, want := range tests {
+		assert.Equal(t, want, redactText(text), text)
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// projectTemplates holds the files of the projects created by init. The base directory has
+// the files of every project, the other directories the parts added for the APIs and
+// dependencies of the project. Files are text/template files with a .tmpl suffix; __name__
+// and __ident__ in their paths are replaced with the Name and Ident of projectData, and files
+// rendering to nothing are skipped.
+//
+//go:embed all:templates
+var projectTemplates embed.FS
+
+// projectOptions are the dependencies and plugins of a project: preset by its blueprint, or
+// chosen with init --interactive
+type projectOptions struct {
+	Database      bool     // PostgreSQL, with the postgresql plugin
+	Kafka         bool     // Kafka, with a consumer of the events of the service
+	AuthProviders []string // enabled auth plugins, from authProviders
+	Tracing       string   // tracing exporter, from tracingBackends; none if empty
+	Logging       string   // log shipping plugin, from loggingBackends; none if empty
+	Prometheus    bool     // a Prometheus service scraping /metrics
+}
+
+// Choices of projectOptions
+var (
+	authProviders   = []string{"jwt", "keycloak", "ldap", "saml"}
+	tracingBackends = []string{"jaeger", "otlp"}
+	loggingBackends = []string{"elk"}
+)
+
+// projectBlueprint is a project layout of init, selected with --template
+type projectBlueprint struct {
+	Description string
+	REST        bool     // a REST API
+	GRPC        bool     // a gRPC API
+	Dirs        []string // empty directories created for the project
+	Options     projectOptions
+}
+
+// defaultBlueprint is the blueprint of init without --template
+const defaultBlueprint = "macroservice"
+
+// projectBlueprints are the blueprints of init, by name
+var projectBlueprints = map[string]projectBlueprint{
+	"minimal": {
+		Description: "health, readiness and metrics endpoints, ready for your modules",
+		Options:     projectOptions{Tracing: "jaeger"},
+	},
+	"rest-api": {
+		Description: "a REST API backed by PostgreSQL",
+		REST:        true,
+		Options:     projectOptions{Database: true, Tracing: "jaeger"},
+	},
+	"grpc-service": {
+		Description: "a gRPC service with a buf-managed proto contract, backed by PostgreSQL",
+		GRPC:        true,
+		Options:     projectOptions{Database: true, Tracing: "jaeger"},
+	},
+	"event-worker": {
+		Description: "a Kafka consumer with a dead letter topic",
+		Options:     projectOptions{Kafka: true, Tracing: "jaeger"},
+	},
+	"macroservice": {
+		Description: "REST, gRPC and Kafka in one service backed by PostgreSQL",
+		REST:        true,
+		GRPC:        true,
+		Dirs:        []string{"internal/domain", "internal/usecase", "internal/infrastructure", "tests/unit", "docs", "scripts"},
+		Options:     projectOptions{Database: true, Kafka: true, Tracing: "jaeger"},
+	},
+}
+
+// blueprintNames returns the names of the blueprints, sorted
+func blueprintNames() []string {
+	names := make([]string, 0, len(projectBlueprints))
+	for name := range projectBlueprints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// projectData is the data of the project templates
+type projectData struct {
+	projectOptions
+	Name     string // project name, also its Go module path
+	Title    string // project name in title case, for documents
+	Ident    string // project name as an identifier, for database and proto package names
+	Service  string // project name in CamelCase, for proto services
+	Template string // blueprint name
+	Year     int
+	REST     bool
+	GRPC     bool
+}
+
+// newProjectData returns the template data of a project created from a blueprint with options
+func newProjectData(name, blueprintName string, blueprint projectBlueprint, options projectOptions) projectData {
+	title := strings.Title(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+	return projectData{
+		projectOptions: options,
+		Name:           name,
+		Title:          title,
+		Ident:          strings.ToLower(strings.ReplaceAll(name, "-", "_")),
+		Service:        strings.ReplaceAll(title, " ", ""),
+		Template:       blueprintName,
+		Year:           time.Now().Year(),
+		REST:           blueprint.REST,
+		GRPC:           blueprint.GRPC,
+	}
+}
+
+// HasAuth reports whether the auth plugin of a provider is enabled
+func (d projectData) HasAuth(provider string) bool {
+	for _, p := range d.AuthProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Plugins returns the names of the plugins enabled in the configuration
+func (d projectData) Plugins() []string {
+	var names []string
+	if d.Database {
+		names = append(names, "postgresql")
+	}
+	names = append(names, d.AuthProviders...)
+	if d.Logging != "" {
+		names = append(names, d.Logging)
+	}
+	return names
+}
+
+// Services reports whether the project has dependencies to run with docker compose
+func (d projectData) Services() bool {
+	return d.Database || d.Kafka || d.HasAuth("keycloak") || d.Tracing != "" || d.Logging != "" || d.Prometheus
+}
+
+// parts returns the template directories added to base
+func (d projectData) parts() []string {
+	var parts []string
+	if d.REST {
+		parts = append(parts, "rest")
+	}
+	if d.GRPC {
+		parts = append(parts, "grpc")
+	}
+	if d.Kafka {
+		parts = append(parts, "worker")
+	}
+	if d.Prometheus {
+		parts = append(parts, "prometheus")
+	}
+	return parts
+}
+
+// renderProject writes the files of a blueprint to the current directory
+func renderProject(data projectData, blueprint projectBlueprint) error {
+	dirs := blueprint.Dirs
+	if data.Database {
+		dirs = append(dirs, "migrations")
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+	for _, part := range append([]string{"base"}, data.parts()...) {
+		root := path.Join("templates", part)
+		err := fs.WalkDir(projectTemplates, root, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			content, err := projectTemplates.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			target := strings.TrimSuffix(strings.TrimPrefix(name, root+"/"), ".tmpl")
+			target = filepath.FromSlash(strings.NewReplacer("__name__", data.Name, "__ident__", data.Ident).Replace(target))
+			return renderProjectFile(target, string(content), data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderProjectFile renders a template to a file, formatting Go files
+func renderProjectFile(target, content string, data projectData) error {
+	tmpl, err := template.New(target).Parse(content)
+	if err != nil {
+		return fmt.Errorf("parsing template of %s: %w", target, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("rendering %s: %w", target, err)
+	}
+	output := buf.Bytes()
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	if strings.HasSuffix(target, ".go") {
+		if output, err = format.Source(output); err != nil {
+			return fmt.Errorf("formatting %s: %w", target, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", target, err)
+	}
+	if err := os.WriteFile(target, output, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", target, err)
+	}
+	fmt.Printf("Generated file: %s\n", target)
+	return nil
+}
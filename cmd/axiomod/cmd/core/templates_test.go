@@ -0,0 +1,45 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderProject renders each blueprint into a new module requiring the framework of this
+// repository and checks that the project builds, is vetted and passes its own tests
+func TestRenderProject(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping the build of the project templates in short mode")
+	}
+
+	framework, err := filepath.Abs(filepath.Join("..", "..", "..", ".."))
+	require.NoError(t, err)
+	goSum, err := os.ReadFile(filepath.Join(framework, "go.sum"))
+	require.NoError(t, err)
+
+	for _, name := range blueprintNames() {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			goMod := "module shop\n\ngo 1.24.2\n\nrequire github.com/axiomod/axiomod v0.0.0\n\nreplace github.com/axiomod/axiomod => " + framework + "\n"
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0644))
+
+			t.Chdir(dir)
+			blueprint := projectBlueprints[name]
+			require.NoError(t, renderProject(newProjectData("shop", name, blueprint, blueprint.Options), blueprint))
+
+			// The dependencies of the framework are already in the module cache
+			for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}, {"test", "./..."}} {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = dir
+				cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+				out, err := cmd.CombinedOutput()
+				require.NoError(t, err, "go %s:\n%s", args[0], out)
+			}
+		})
+	}
+}
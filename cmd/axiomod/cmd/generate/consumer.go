@@ -0,0 +1,279 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// generateConsumerCmd represents the generate consumer command
+var generateConsumerCmd = &cobra.Command{
+	Use:   "consumer --topic=[topic]",
+	Short: "Generate a Kafka consumer with a dead letter topic",
+	Long: `Generate a Kafka message handler of a module for a topic.
+
+The consumer goes to the delivery/consumer package of the module. It decodes the JSON
+messages of the topic and processes them. Failures are retried with backoff, then the
+message is published to the dead letter topic <topic>.dlq, see kafka.WithDeadLetter.
+Messages that cannot be decoded are dead-lettered without retries. An fx module registers
+the consumer on the Kafka consumer, and an integration test runs it against a fake broker.
+
+Example:
+  axiomod generate consumer --topic=orders
+  axiomod generate consumer --topic=shop.payments --module=billing
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, _ := cmd.Flags().GetString("topic")
+		if !topicPattern.MatchString(topic) {
+			fmt.Println("Error: topic must be a Kafka topic name of letters, digits, '.', '_' and '-', such as orders")
+			os.Exit(1)
+		}
+		name := strings.ToLower(topicSeparators.ReplaceAllString(topic, "_"))
+		moduleName, err := backgroundModule(cmd, name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, _ := target.module(moduleName)
+		consumerPath := filepath.Join(modulePath, "delivery", "consumer")
+		consumerFile := filepath.Join(consumerPath, name+"_consumer.go")
+		if _, err := os.Stat(consumerFile); err == nil {
+			fmt.Printf("Error: %s already exists\n", consumerFile)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(consumerPath, 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", consumerPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating consumer of topic: %s\n", topic)
+
+		goName, _ := fieldNames(name)
+		data := struct {
+			Topic string
+			Name  string
+		}{
+			Topic: topic,
+			Name:  goName,
+		}
+		generateFile(consumerTemplate, consumerFile, data)
+		generateFile(consumerTestTemplate, filepath.Join(consumerPath, name+"_consumer_test.go"), data)
+
+		fmt.Printf("\nConsumer of %s generated successfully in %s\n", topic, consumerPath)
+		fmt.Println("\nRemember to:")
+		fmt.Printf("1. Define %sMessage and implement process.\n", goName)
+		fmt.Printf("2. Add consumer.%sConsumerModule to your application, after kafka.Module.\n", goName)
+		fmt.Printf("3. Add %s to the Topics of the kafka.ConsumerConfig, and create the topic %s.dlq.\n", topic, topic)
+	},
+}
+
+var (
+	// topicPattern matches Kafka topic names
+	topicPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._-]*$`)
+	// topicSeparators matches the separators of the words of topic names
+	topicSeparators = regexp.MustCompile(`[._-]+`)
+)
+
+const consumerTemplate = `package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/axiomod/axiomod/framework/kafka"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Topics of {{.Name}}Consumer
+const (
+	{{.Name}}Topic           = "{{.Topic}}"
+	{{.Name}}DeadLetterTopic = {{.Name}}Topic + ".dlq"
+)
+
+// {{.Name}}ConsumerModule registers {{.Name}}Consumer with the Kafka consumer of kafka.Module
+var {{.Name}}ConsumerModule = fx.Options(
+	fx.Provide(New{{.Name}}Consumer),
+	fx.Invoke(Register{{.Name}}Consumer),
+)
+
+// {{.Name}}Message is the JSON payload of the messages of {{.Name}}Topic
+type {{.Name}}Message struct {
+	ID string ` + "`" + `json:"id"` + "`" + `
+}
+
+// {{.Name}}Consumer processes the messages of {{.Name}}Topic.
+type {{.Name}}Consumer struct {
+	logger *observability.Logger
+}
+
+// New{{.Name}}Consumer creates the consumer of {{.Name}}Topic
+func New{{.Name}}Consumer(logger *observability.Logger) *{{.Name}}Consumer {
+	return &{{.Name}}Consumer{logger: logger}
+}
+
+// Handler returns the handler of {{.Name}}Topic: Handle, retried with backoff, publishing the
+// messages that still fail to {{.Name}}DeadLetterTopic with publisher
+func (c *{{.Name}}Consumer) Handler(publisher kafka.Publisher) kafka.MessageHandler {
+	return kafka.WithDeadLetter(c.Handle, publisher, kafka.DeadLetterConfig{Topic: {{.Name}}DeadLetterTopic}, c.logger)
+}
+
+// Handle decodes and processes a message. Messages that cannot be decoded fail
+// permanently: retrying them cannot help.
+func (c *{{.Name}}Consumer) Handle(ctx context.Context, message *kafka.Message) error {
+	var payload {{.Name}}Message
+	if err := json.Unmarshal(message.Value, &payload); err != nil {
+		return kafka.Permanent(fmt.Errorf("decoding message: %w", err))
+	}
+	if payload.ID == "" {
+		return kafka.Permanent(errors.New("message has no id"))
+	}
+	return c.process(ctx, payload)
+}
+
+// process processes a decoded message. Processing must be idempotent: messages are
+// retried, and consumed again when the consumer restarts before committing them.
+func (c *{{.Name}}Consumer) process(ctx context.Context, payload {{.Name}}Message) error {
+	c.logger.Info("Processing message", zap.String("topic", {{.Name}}Topic), zap.String("id", payload.ID))
+	return nil
+}
+
+// Register{{.Name}}Consumer registers the handler of {{.Name}}Topic with the Kafka consumer,
+// dead-lettering messages with the producer
+func Register{{.Name}}Consumer(consumer *kafka.Consumer, producer *kafka.Producer, c *{{.Name}}Consumer) {
+	consumer.RegisterHandler({{.Name}}Topic, c.Handler(producer))
+}
+`
+
+const consumerTestTemplate = `package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/kafka"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker stands in for Kafka: it delivers messages to a handler, as the consumer
+// does, and keeps the messages published to each topic
+type fakeBroker struct {
+	mu         sync.Mutex
+	topics     map[string][]*kafka.Message
+	publishErr error // error of Publish, if any
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{topics: make(map[string][]*kafka.Message)}
+}
+
+// Publish implements kafka.Publisher
+func (b *fakeBroker) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	b.topics[topic] = append(b.topics[topic], &kafka.Message{Topic: topic, Key: key, Value: value, Offset: int64(len(b.topics[topic]))})
+	return nil
+}
+
+// Deliver publishes a message to a topic and delivers it to handler
+func (b *fakeBroker) Deliver(ctx context.Context, handler kafka.MessageHandler, topic, key string, value []byte) error {
+	if err := b.Publish(ctx, topic, key, value); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	message := b.topics[topic][len(b.topics[topic])-1]
+	b.mu.Unlock()
+	return handler(ctx, message)
+}
+
+// Messages returns the messages published to a topic
+func (b *fakeBroker) Messages(topic string) []*kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.topics[topic]
+}
+
+func Test{{.Name}}Consumer_Integration(t *testing.T) {
+	logger, err := observability.NewLogger(&config.Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		value       string
+		publishErr  error // error of the broker when dead-lettering
+		wantErr     bool
+		wantDead    bool
+		deadMessage string
+	}{
+		{name: "processed", value: ` + "`" + `{"id":"m-1"}` + "`" + `},
+		{name: "undecodable message dead-lettered", value: ` + "`" + `not json` + "`" + `, wantDead: true, deadMessage: "decoding message"},
+		{name: "invalid message dead-lettered", value: ` + "`" + `{}` + "`" + `, wantDead: true, deadMessage: "message has no id"},
+		{name: "redelivered when the dead letter topic is unavailable", value: ` + "`" + `{}` + "`" + `, publishErr: errors.New("broker down"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newFakeBroker()
+			handler := New{{.Name}}Consumer(logger).Handler(broker)
+
+			var err error
+			if tt.publishErr != nil {
+				require.NoError(t, broker.Publish(context.Background(), {{.Name}}Topic, "k", []byte(tt.value)))
+				broker.publishErr = tt.publishErr
+				err = handler(context.Background(), broker.Messages({{.Name}}Topic)[0])
+			} else {
+				err = broker.Deliver(context.Background(), handler, {{.Name}}Topic, "k", []byte(tt.value))
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			dead := broker.Messages({{.Name}}DeadLetterTopic)
+			if !tt.wantDead {
+				assert.Empty(t, dead)
+				return
+			}
+			require.Len(t, dead, 1)
+			assert.Equal(t, "k", dead[0].Key)
+			var letter kafka.DeadLetter
+			require.NoError(t, json.Unmarshal(dead[0].Value, &letter))
+			assert.Equal(t, {{.Name}}Topic, letter.Topic)
+			assert.Equal(t, []byte(tt.value), letter.Value)
+			assert.Contains(t, letter.Error, tt.deadMessage)
+			assert.Equal(t, 1, letter.Attempts, "invalid messages are not retried")
+		})
+	}
+}
+`
+
+func init() {
+	generateConsumerCmd.Flags().String("topic", "", "Kafka topic to consume, e.g. orders (required)")
+	generateConsumerCmd.Flags().StringP("module", "m", "", "Target module name (optional, defaults to the topic name)")
+	generateConsumerCmd.MarkFlagRequired("topic")
+	addTargetFlags(generateConsumerCmd)
+	generateCmd.AddCommand(generateConsumerCmd)
+}
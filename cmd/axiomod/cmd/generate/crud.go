@@ -0,0 +1,1637 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// generateCrudCmd represents the generate crud command
+var generateCrudCmd = &cobra.Command{
+	Use:   "crud --name=[name] --fields=[fields]",
+	Short: "Generate a module with full CRUD over REST and gRPC",
+	Long: `Generate a module managing an entity with create, get, update, delete and list
+use cases on the command and query buses, served over REST and gRPC.
+
+The module has the entity and its domain events, an in-memory and a SQL repository,
+the use cases, an HTTP handler validating its requests, a gRPC contract and service, an
+OpenAPI snippet, fx wiring and table-driven tests. Fields are name:type pairs, with snake_case names and
+the types string, int, int64, float, bool and time. Every field can be filtered and
+sorted on by List.
+
+Example:
+  axiomod generate crud --name=product --fields="name:string,price:float,active:bool"
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if !crudNamePattern.MatchString(name) {
+			fmt.Println("Error: name must be a lowercase word, such as product")
+			os.Exit(1)
+		}
+		spec, _ := cmd.Flags().GetString("fields")
+		fields, err := parseCrudFields(spec, name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, importPath := target.module(name)
+		if _, err := os.Stat(modulePath); err == nil {
+			fmt.Printf("Error: %s already exists\n", modulePath)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating CRUD module: %s\n", name)
+
+		entityPath := filepath.Join(modulePath, "entity")
+		repositoryPath := filepath.Join(modulePath, "repository")
+		usecasePath := filepath.Join(modulePath, "usecase")
+		deliveryHTTPPath := filepath.Join(modulePath, "delivery", "http")
+		deliveryGRPCPath := filepath.Join(modulePath, "delivery", "grpc")
+		persistencePath := filepath.Join(modulePath, "infrastructure", "persistence")
+		protoPath := target.protoDir(name)
+		for _, dir := range []string{entityPath, repositoryPath, usecasePath, deliveryHTTPPath, deliveryGRPCPath, persistencePath, protoPath} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				fmt.Printf("Error creating directory %s: %v\n", dir, err)
+				os.Exit(1)
+			}
+		}
+
+		data := crudData{
+			ModuleName:      name,
+			ImportPath:      importPath,
+			ModulePath:      target.ModulePath,
+			EntityName:      strings.Title(name),
+			EntityNameLower: name,
+			Plural:          plural(name),
+			PluralTitle:     strings.Title(plural(name)),
+			Fields:          fields,
+			CreatedAtNumber: len(fields) + 2,
+			UpdatedAtNumber: len(fields) + 3,
+		}
+		for _, field := range fields {
+			data.HasTime = data.HasTime || field.Type == "time"
+			if data.Required == "" && field.Type == "string" {
+				data.Required = field.Name
+			}
+		}
+
+		generateFile(crudEntityTemplate, filepath.Join(entityPath, name+".go"), data)
+		generateFile(crudEventsTemplate, filepath.Join(entityPath, name+"_events.go"), data)
+		generateFile(crudRepositoryTemplate, filepath.Join(repositoryPath, name+"_repository.go"), data)
+		generateFile(crudMemoryRepositoryTemplate, filepath.Join(persistencePath, name+"_memory_repository.go"), data)
+		generateFile(crudSQLRepositoryTemplate, filepath.Join(persistencePath, name+"_sql_repository.go"), data)
+		generateFile(crudSchemaTemplate, filepath.Join(persistencePath, name+"_schema.sql"), data)
+		generateFile(crudRepositoryTestTemplate, filepath.Join(persistencePath, name+"_memory_repository_test.go"), data)
+		generateFile(crudCommandsTemplate, filepath.Join(usecasePath, name+"_commands.go"), data)
+		generateFile(crudQueriesTemplate, filepath.Join(usecasePath, name+"_queries.go"), data)
+		generateFile(crudUsecaseTestTemplate, filepath.Join(usecasePath, name+"_usecase_test.go"), data)
+		generateFile(crudHandlerTemplate, filepath.Join(deliveryHTTPPath, name+"_handler.go"), data)
+		generateFile(crudHandlerTestTemplate, filepath.Join(deliveryHTTPPath, name+"_handler_test.go"), data)
+		generateFile(crudOpenAPITemplate, filepath.Join(deliveryHTTPPath, name+".openapi.yaml"), data)
+		generateFile(crudProtoTemplate, filepath.Join(protoPath, name+".proto"), data)
+		generateFile(crudGRPCServiceTemplate, filepath.Join(deliveryGRPCPath, name+"_grpc_service.go"), data)
+		generateFile(crudModuleTemplate, filepath.Join(modulePath, "module.go"), data)
+		if err := setupBuf(target.Root); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\nCRUD module %s generated successfully in %s\n", name, modulePath)
+		fmt.Println("\nRemember to:")
+		fmt.Printf("1. Add %s.Module to your application, after the cqrs, pagination and server modules.\n", name)
+		fmt.Printf("2. Run make proto to generate the Go code of %s.proto and uncomment the gRPC service.\n", name)
+		fmt.Printf("3. To store %s in SQL, create the table of %s_schema.sql and provide the SQL repository.\n", data.Plural, name)
+		fmt.Printf("4. To publish the domain events of %s, add the events and unitofwork modules.\n", data.Plural)
+	},
+}
+
+// crudData is the template data of the crud command
+type crudData struct {
+	ModuleName      string
+	ImportPath      string // Go import path of the module
+	ModulePath      string // path of the enclosing Go module
+	EntityName      string
+	EntityNameLower string
+	Plural          string // plural of the entity name, naming the HTTP resource and SQL table
+	PluralTitle     string
+	Fields          []crudField
+	HasTime         bool   // whether a field is a time.Time
+	Required        string // first string field, required by the commands; tests send requests without it
+
+	CreatedAtNumber int // proto field numbers of the timestamps, after the fields
+	UpdatedAtNumber int
+}
+
+// crudField is an entity field of the crud command
+type crudField struct {
+	Name        string // Go field name, e.g. UnitPrice
+	ProtoName   string // Go field name generated from the proto field, e.g. UnitPrice
+	Key         string // JSON, SQL column and proto field name, e.g. unit_price
+	Type        string // type given with --fields
+	Number      int    // proto field number in the entity message, after id
+	InputNumber int    // proto field number in the create request
+
+	GoType      string
+	ProtoType   string
+	SQLType     string
+	EntType     string // name of the Ent field builder, e.g. Float for field.Float
+	OpenAPIType string // type and format properties of the OpenAPI schema
+	Operators   string // Go expression of the operators List filters the field with
+	Validate    string // validate tag of the commands, if any
+	Sample      string // Go expressions of values used by the tests
+	Updated     string
+	ToProto     string // Go expressions converting the field of the entity to its message, and back from a request
+	FromProto   string
+
+	Unique     bool   // whether values are unique, declared by schema files
+	References string // table a foreign key references, declared by schema file relations
+}
+
+// crudType describes a field type of the crud command
+type crudType struct {
+	goType, protoType, sqlType, entType, openAPIType, operators, validate string
+	sample, updated                                                       string
+	toProto, fromProto                                                    string // patterns of the conversions
+}
+
+// crudTypes are the field types of the crud command
+var crudTypes = map[string]crudType{
+	"string": {"string", "string", "VARCHAR(255)", "String", "{type: string}", "query.Text", "required", `"sample %s"`, `"updated %s"`, "%s", "%s"},
+	"int":    {"int", "int64", "INTEGER", "Int", "{type: integer}", "query.Comparable", "", "1", "2", "int64(%s)", "int(%s)"},
+	"int64":  {"int64", "int64", "BIGINT", "Int64", "{type: integer, format: int64}", "query.Comparable", "", "int64(1)", "int64(2)", "%s", "%s"},
+	"float":  {"float64", "double", "DOUBLE PRECISION", "Float", "{type: number, format: double}", "query.Comparable", "", "1.5", "2.5", "%s", "%s"},
+	"bool":   {"bool", "bool", "BOOLEAN", "Bool", "{type: boolean}", "[]query.Operator{query.Eq, query.Ne}", "", "true", "false", "%s", "%s"},
+	"time": {"time.Time", "google.protobuf.Timestamp", "TIMESTAMP", "Time", "{type: string, format: date-time}", "query.Comparable", "",
+		"time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)", "time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)", "mapping.Timestamp(%s)", "mapping.Time(%s)"},
+}
+
+// crudNamePattern matches the names accepted by --name; they name Go packages
+var crudNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// parseCrudFields parses the name:type pairs of --fields
+func parseCrudFields(spec, name string) ([]crudField, error) {
+	var fields []crudField
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, typeName, ok := strings.Cut(pair, ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || !filterNamePattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid field %q, use name:type pairs with snake_case names such as unit_price:float", pair)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("field %q is declared twice", key)
+		}
+		seen[key] = true
+
+		field, err := newCrudField(key, typeName, name, len(fields))
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("--fields needs at least one field, e.g. name:string")
+	}
+	return fields, nil
+}
+
+// newCrudField returns the i-th field of an entity, held by variables named entityVar in
+// the generated code
+func newCrudField(key, typeName, entityVar string, i int) (crudField, error) {
+	typeName = strings.ToLower(strings.TrimSpace(typeName))
+	if typeName == "float64" {
+		typeName = "float"
+	}
+	t, ok := crudTypes[typeName]
+	if !ok {
+		return crudField{}, fmt.Errorf("field %q has unknown type %q; use string, int, int64, float, bool or time", key, typeName)
+	}
+	if !filterNamePattern.MatchString(key) {
+		return crudField{}, fmt.Errorf("invalid field name %q, use snake_case names such as unit_price", key)
+	}
+	switch key {
+	case "id", "created_at", "updated_at":
+		return crudField{}, fmt.Errorf("field %q is generated for every entity", key)
+	}
+
+	goName, protoName := fieldNames(key)
+	validate := ""
+	if t.validate != "" {
+		validate = ` validate:"` + t.validate + `"`
+	}
+	sample, updated := t.sample, t.updated
+	if typeName == "string" {
+		sample, updated = fmt.Sprintf(sample, key), fmt.Sprintf(updated, key)
+	}
+	return crudField{
+		Name:        goName,
+		ProtoName:   protoName,
+		Key:         key,
+		Type:        typeName,
+		Number:      i + 2,
+		InputNumber: i + 1,
+		GoType:      t.goType,
+		ProtoType:   t.protoType,
+		SQLType:     t.sqlType,
+		EntType:     t.entType,
+		OpenAPIType: t.openAPIType,
+		Operators:   t.operators,
+		Validate:    validate,
+		Sample:      sample,
+		Updated:     updated,
+		ToProto:     fmt.Sprintf(t.toProto, entityVar+"."+goName),
+		FromProto:   fmt.Sprintf(t.fromProto, "req.Get"+protoName+"()"),
+	}, nil
+}
+
+// plural returns the English plural of a lowercase noun
+func plural(noun string) string {
+	switch {
+	case strings.HasSuffix(noun, "s"), strings.HasSuffix(noun, "x"), strings.HasSuffix(noun, "ch"), strings.HasSuffix(noun, "sh"):
+		return noun + "es"
+	case strings.HasSuffix(noun, "y") && len(noun) > 1 && !strings.ContainsRune("aeiou", rune(noun[len(noun)-2])):
+		return noun[:len(noun)-1] + "ies"
+	default:
+		return noun + "s"
+	}
+}
+
+const crudEntityTemplate = `package entity
+
+import (
+	"time"
+
+	"github.com/axiomod/axiomod/framework/events"
+)
+
+// {{.EntityName}} is the entity managed by the {{.ModuleName}} module. It records the domain events
+// of its changes, published once the command changing it committed.
+type {{.EntityName}} struct {
+	events.AggregateRoot
+
+	ID string ` + "`" + `json:"id"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.Key}}"` + "`" + `
+{{- end}}
+	CreatedAt time.Time ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `json:"updated_at"` + "`" + `
+}
+`
+
+const crudEventsTemplate = `package entity
+
+// {{.EntityName}}Created is recorded when a {{.EntityName}} is created.
+type {{.EntityName}}Created struct {
+	ID string ` + "`" + `json:"id"` + "`" + `
+}
+
+// EventName implements events.Named.
+func (e {{.EntityName}}Created) EventName() string {
+	return "{{.ModuleName}}.{{.EntityNameLower}}.created"
+}
+
+// {{.EntityName}}Updated is recorded when the fields of a {{.EntityName}} are replaced.
+type {{.EntityName}}Updated struct {
+	ID string ` + "`" + `json:"id"` + "`" + `
+}
+
+// EventName implements events.Named.
+func (e {{.EntityName}}Updated) EventName() string {
+	return "{{.ModuleName}}.{{.EntityNameLower}}.updated"
+}
+
+// {{.EntityName}}Deleted is recorded when a {{.EntityName}} is deleted.
+type {{.EntityName}}Deleted struct {
+	ID string ` + "`" + `json:"id"` + "`" + `
+}
+
+// EventName implements events.Named.
+func (e {{.EntityName}}Deleted) EventName() string {
+	return "{{.ModuleName}}.{{.EntityNameLower}}.deleted"
+}
+`
+
+const crudRepositoryTemplate = `package repository
+
+import (
+	"context"
+	"errors"
+
+	"{{.ImportPath}}/entity"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// {{.EntityName}}Repository stores {{.EntityName}} entities.
+type {{.EntityName}}Repository interface {
+	// Create stores a new {{.EntityName}}, or returns Err{{.EntityName}}Exists
+	Create(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error
+	// GetByID returns the {{.EntityName}} with an ID, or Err{{.EntityName}}NotFound
+	GetByID(ctx context.Context, id string) (*entity.{{.EntityName}}, error)
+	// Update replaces a stored {{.EntityName}}, or returns Err{{.EntityName}}NotFound
+	Update(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error
+	// Delete removes the {{.EntityName}} with an ID, or returns Err{{.EntityName}}NotFound
+	Delete(ctx context.Context, id string) error
+	// List returns the page of params of the {{.EntityName}} entities matching its filters,
+	// checked against {{.EntityName}}Schema, with their total count
+	List(ctx context.Context, params query.Params) (query.ListResult[*entity.{{.EntityName}}], error)
+}
+
+// {{.EntityName}}Schema lists the fields {{.EntityName}} listings filter and sort on, by their
+// JSON names, which are their SQL columns too.
+var {{.EntityName}}Schema = query.NewSchema("id", map[string]query.Field{
+{{- range .Fields}}
+	"{{.Key}}": {Filter: {{.Operators}}, Sortable: true},
+{{- end}}
+	"created_at": {Filter: query.Comparable, Sortable: true},
+	"updated_at": {Filter: query.Comparable, Sortable: true},
+})
+
+// Repository errors
+var (
+	Err{{.EntityName}}NotFound = errors.New("{{.EntityNameLower}} not found")
+	Err{{.EntityName}}Exists   = errors.New("{{.EntityNameLower}} already exists")
+)
+`
+
+const crudMemoryRepositoryTemplate = `package persistence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// InMemory{{.EntityName}}Repository is a {{.EntityName}}Repository keeping {{.Plural}} in memory, for
+// tests and offline runs. Entities are copied in and out, so callers never share them with
+// the store.
+type InMemory{{.EntityName}}Repository struct {
+	mu    sync.RWMutex
+	store map[string]*entity.{{.EntityName}}
+}
+
+// NewInMemory{{.EntityName}}Repository creates a new InMemory{{.EntityName}}Repository.
+func NewInMemory{{.EntityName}}Repository() *InMemory{{.EntityName}}Repository {
+	return &InMemory{{.EntityName}}Repository{store: make(map[string]*entity.{{.EntityName}})}
+}
+
+// Create stores a new {{.EntityName}}.
+func (r *InMemory{{.EntityName}}Repository) Create(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store[{{.EntityNameLower}}.ID]; exists {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}Exists, {{.EntityNameLower}}.ID)
+	}
+	r.store[{{.EntityNameLower}}.ID] = {{.EntityNameLower}}WithoutEvents({{.EntityNameLower}})
+	return nil
+}
+
+// GetByID returns the {{.EntityName}} with an ID.
+func (r *InMemory{{.EntityName}}Repository) GetByID(ctx context.Context, id string) (*entity.{{.EntityName}}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, exists := r.store[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, id)
+	}
+	{{.EntityNameLower}} := *stored
+	return &{{.EntityNameLower}}, nil
+}
+
+// Update replaces a stored {{.EntityName}}.
+func (r *InMemory{{.EntityName}}Repository) Update(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store[{{.EntityNameLower}}.ID]; !exists {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, {{.EntityNameLower}}.ID)
+	}
+	r.store[{{.EntityNameLower}}.ID] = {{.EntityNameLower}}WithoutEvents({{.EntityNameLower}})
+	return nil
+}
+
+// Delete removes the {{.EntityName}} with an ID.
+func (r *InMemory{{.EntityName}}Repository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store[id]; !exists {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, id)
+	}
+	delete(r.store, id)
+	return nil
+}
+
+// List returns the page of params of the {{.EntityName}} entities matching its filters.
+func (r *InMemory{{.EntityName}}Repository) List(ctx context.Context, params query.Params) (query.ListResult[*entity.{{.EntityName}}], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]*entity.{{.EntityName}}, 0, len(r.store))
+	for _, stored := range r.store {
+		items = append(items, stored)
+	}
+	result, err := query.Apply(items, repository.{{.EntityName}}Schema, params, {{.EntityNameLower}}FieldValue)
+	if err != nil {
+		return result, err
+	}
+	for i, stored := range result.Items {
+		{{.EntityNameLower}} := *stored
+		result.Items[i] = &{{.EntityNameLower}}
+	}
+	return result, nil
+}
+
+// {{.EntityNameLower}}WithoutEvents returns the copy of a {{.EntityName}} kept in the store,
+// without the events it recorded, which the unit of work of the command storing it publishes.
+func {{.EntityNameLower}}WithoutEvents({{.EntityNameLower}} *entity.{{.EntityName}}) *entity.{{.EntityName}} {
+	copied := *{{.EntityNameLower}}
+	copied.AggregateRoot = events.AggregateRoot{}
+	return &copied
+}
+
+// {{.EntityNameLower}}FieldValue returns the value of a field of repository.{{.EntityName}}Schema.
+func {{.EntityNameLower}}FieldValue({{.EntityNameLower}} *entity.{{.EntityName}}, field string) any {
+	switch field {
+	case "id":
+		return {{.EntityNameLower}}.ID
+{{- range .Fields}}
+	case "{{.Key}}":
+		return {{$.EntityNameLower}}.{{.Name}}
+{{- end}}
+	case "created_at":
+		return {{.EntityNameLower}}.CreatedAt
+	case "updated_at":
+		return {{.EntityNameLower}}.UpdatedAt
+	}
+	return nil
+}
+`
+
+const crudSQLRepositoryTemplate = `package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// {{.EntityNameLower}}Columns are the columns of the {{.Plural}} table, in the order scan{{.EntityName}} reads them.
+const {{.EntityNameLower}}Columns = "id, {{range .Fields}}{{.Key}}, {{end}}created_at, updated_at"
+
+// SQL{{.EntityName}}Repository is a {{.EntityName}}Repository storing {{.Plural}} in the table of
+// {{.EntityNameLower}}_schema.sql. Statements run in the transaction of their context, such as the one
+// the command bus runs commands in.
+type SQL{{.EntityName}}Repository struct {
+	db          *database.DB
+	placeholder query.Placeholder
+}
+
+// NewSQL{{.EntityName}}Repository creates a new SQL{{.EntityName}}Repository for the configured database driver.
+func NewSQL{{.EntityName}}Repository(db *database.DB, cfg *config.Config) *SQL{{.EntityName}}Repository {
+	return &SQL{{.EntityName}}Repository{db: db, placeholder: query.PlaceholderFor(cfg.Database.Driver)}
+}
+
+// Create inserts a new {{.EntityName}}. Duplicate IDs fail with the primary key error of the database.
+func (r *SQL{{.EntityName}}Repository) Create(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error {
+	statement := query.Rebind("INSERT INTO {{.Plural}} ("+{{.EntityNameLower}}Columns+") VALUES (?, {{range .Fields}}?, {{end}}?, ?)", r.placeholder)
+	_, err := r.db.Exec(ctx, statement, {{.EntityNameLower}}.ID, {{range .Fields}}{{$.EntityNameLower}}.{{.Name}}, {{end}}{{.EntityNameLower}}.CreatedAt, {{.EntityNameLower}}.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create {{.EntityNameLower}} %s: %w", {{.EntityNameLower}}.ID, err)
+	}
+	return nil
+}
+
+// GetByID returns the {{.EntityName}} with an ID.
+func (r *SQL{{.EntityName}}Repository) GetByID(ctx context.Context, id string) (*entity.{{.EntityName}}, error) {
+	statement := query.Rebind("SELECT "+{{.EntityNameLower}}Columns+" FROM {{.Plural}} WHERE id = ?", r.placeholder)
+	{{.EntityNameLower}}, err := scan{{.EntityName}}(r.db.QueryRow(ctx, statement, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get {{.EntityNameLower}} %s: %w", id, err)
+	}
+	return {{.EntityNameLower}}, nil
+}
+
+// Update replaces a stored {{.EntityName}}.
+func (r *SQL{{.EntityName}}Repository) Update(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error {
+	statement := query.Rebind("UPDATE {{.Plural}} SET {{range .Fields}}{{.Key}} = ?, {{end}}updated_at = ? WHERE id = ?", r.placeholder)
+	result, err := r.db.Exec(ctx, statement, {{range .Fields}}{{$.EntityNameLower}}.{{.Name}}, {{end}}{{.EntityNameLower}}.UpdatedAt, {{.EntityNameLower}}.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update {{.EntityNameLower}} %s: %w", {{.EntityNameLower}}.ID, err)
+	}
+	return expectOne(result, {{.EntityNameLower}}.ID)
+}
+
+// Delete removes the {{.EntityName}} with an ID.
+func (r *SQL{{.EntityName}}Repository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Exec(ctx, query.Rebind("DELETE FROM {{.Plural}} WHERE id = ?", r.placeholder), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete {{.EntityNameLower}} %s: %w", id, err)
+	}
+	return expectOne(result, id)
+}
+
+// List returns the page of params of the {{.EntityName}} entities matching its filters.
+func (r *SQL{{.EntityName}}Repository) List(ctx context.Context, params query.Params) (query.ListResult[*entity.{{.EntityName}}], error) {
+	var result query.ListResult[*entity.{{.EntityName}}]
+	builder, err := query.Select({{.EntityNameLower}}Columns).From("{{.Plural}}").Apply(repository.{{.EntityName}}Schema, params)
+	if err != nil {
+		return result, err
+	}
+
+	statement, args := builder.Build(r.placeholder)
+	rows, err := r.db.Query(ctx, statement, args...)
+	if err != nil {
+		return result, fmt.Errorf("failed to list {{.Plural}}: %w", err)
+	}
+	defer rows.Close()
+	var items []*entity.{{.EntityName}}
+	for rows.Next() {
+		{{.EntityNameLower}}, err := scan{{.EntityName}}(rows)
+		if err != nil {
+			return result, fmt.Errorf("failed to scan {{.EntityNameLower}}: %w", err)
+		}
+		items = append(items, {{.EntityNameLower}})
+	}
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("failed to list {{.Plural}}: %w", err)
+	}
+
+	var total int
+	statement, args = builder.Count().Build(r.placeholder)
+	if err := r.db.QueryRow(ctx, statement, args...).Scan(&total); err != nil {
+		return result, fmt.Errorf("failed to count {{.Plural}}: %w", err)
+	}
+	return query.NewListResult(repository.{{.EntityName}}Schema, items, total, params, func({{.EntityNameLower}} *entity.{{.EntityName}}) string { return {{.EntityNameLower}}.ID }), nil
+}
+
+// scan{{.EntityName}} reads a {{.EntityName}} from a row of {{.EntityNameLower}}Columns.
+func scan{{.EntityName}}(row interface{ Scan(dest ...any) error }) (*entity.{{.EntityName}}, error) {
+	var {{.EntityNameLower}} entity.{{.EntityName}}
+	err := row.Scan(&{{.EntityNameLower}}.ID, {{range .Fields}}&{{$.EntityNameLower}}.{{.Name}}, {{end}}&{{.EntityNameLower}}.CreatedAt, &{{.EntityNameLower}}.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.EntityNameLower}}, nil
+}
+
+// expectOne returns Err{{.EntityName}}NotFound when a statement changed no row.
+func expectOne(result sql.Result, id string) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to count changed {{.Plural}}: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, id)
+	}
+	return nil
+}
+`
+
+const crudSchemaTemplate = `-- Table of the {{.ModuleName}} module, read and written by SQL{{.EntityName}}Repository.
+-- Add it to a migration with: axiomod migrate create create_{{.Plural}}
+CREATE TABLE {{.Plural}} (
+    id VARCHAR(36) PRIMARY KEY,
+{{- range .Fields}}
+    {{.Key}} {{.SQLType}} NOT NULL,
+{{- end}}
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+`
+
+const crudRepositoryTestTemplate = `package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+func TestInMemory{{.EntityName}}Repository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemory{{.EntityName}}Repository()
+	now := time.Now()
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, repo.Create(ctx, &entity.{{.EntityName}}{
+			ID: id,
+{{- range .Fields}}
+			{{.Name}}: {{.Sample}},
+{{- end}}
+			CreatedAt: now,
+			UpdatedAt: now,
+		}))
+	}
+	assert.ErrorIs(t, repo.Create(ctx, &entity.{{.EntityName}}{ID: "a"}), repository.Err{{.EntityName}}Exists)
+
+	tests := []struct {
+		name    string
+		params  query.Params
+		want    []string
+		total   int
+		wantErr error
+	}{
+		{"all", query.Params{}, []string{"a", "b", "c"}, 3, nil},
+		{"first page", query.Params{Page: query.Page{Limit: 2}}, []string{"a", "b"}, 3, nil},
+		{"after cursor", query.Params{Page: query.Page{After: "a"}}, []string{"b", "c"}, 3, nil},
+		{"descending", query.Params{Sort: []query.Sort{{"{{"}}Field: "id", Desc: true{{"}}"}}}, []string{"c", "b", "a"}, 3, nil},
+		{"filtered", query.Params{Filter: query.Filter{}.Where("id", query.Eq, "b")}, []string{"b"}, 1, nil},
+		{"unknown field", query.Params{Filter: query.Filter{}.Where("unknown", query.Eq, "x")}, nil, 0, query.ErrInvalidFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.List(ctx, tt.params)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var ids []string
+			for _, {{.EntityNameLower}} := range result.Items {
+				ids = append(ids, {{.EntityNameLower}}.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.total, result.Total)
+		})
+	}
+
+{{- with index .Fields 0}}
+
+	{{$.EntityNameLower}}, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	{{$.EntityNameLower}}.{{.Name}} = {{.Updated}}
+	require.NoError(t, repo.Update(ctx, {{$.EntityNameLower}}))
+	stored, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, {{.Updated}}, stored.{{.Name}})
+{{- end}}
+	assert.ErrorIs(t, repo.Update(ctx, &entity.{{.EntityName}}{ID: "missing"}), repository.Err{{.EntityName}}NotFound)
+
+	require.NoError(t, repo.Delete(ctx, "a"))
+	_, err = repo.GetByID(ctx, "a")
+	assert.ErrorIs(t, err, repository.Err{{.EntityName}}NotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, "a"), repository.Err{{.EntityName}}NotFound)
+}
+`
+
+const crudCommandsTemplate = `package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/unitofwork"
+)
+
+// Create{{.EntityName}}Command asks for a new {{.EntityName}}.
+type Create{{.EntityName}}Command struct {
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.Key}}"{{.Validate}}` + "`" + `
+{{- end}}
+}
+
+// Update{{.EntityName}}Command asks to replace the fields of a {{.EntityName}}. Over HTTP, ID is the
+// path parameter.
+type Update{{.EntityName}}Command struct {
+	ID string ` + "`" + `json:"-" params:"id" validate:"required"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.Key}}"{{.Validate}}` + "`" + `
+{{- end}}
+}
+
+// Delete{{.EntityName}}Command asks to delete a {{.EntityName}}.
+type Delete{{.EntityName}}Command struct {
+	ID string ` + "`" + `json:"-" params:"id" validate:"required"` + "`" + `
+}
+
+// Create{{.EntityName}}UseCase handles Create{{.EntityName}}Command on the command bus.
+type Create{{.EntityName}}UseCase struct {
+	repo repository.{{.EntityName}}Repository
+}
+
+// NewCreate{{.EntityName}}UseCase creates a new Create{{.EntityName}}UseCase.
+func NewCreate{{.EntityName}}UseCase(repo repository.{{.EntityName}}Repository) *Create{{.EntityName}}UseCase {
+	return &Create{{.EntityName}}UseCase{repo: repo}
+}
+
+// Handle creates a {{.EntityName}}. The command bus has validated the command already.
+// {{.EntityName}}Created is published once the unit of work of the command committed.
+func (uc *Create{{.EntityName}}UseCase) Handle(ctx context.Context, cmd Create{{.EntityName}}Command) (*entity.{{.EntityName}}, error) {
+	now := time.Now().UTC()
+	{{.EntityNameLower}} := &entity.{{.EntityName}}{
+		ID: uuid.NewString(),
+{{- range .Fields}}
+		{{.Name}}: cmd.{{.Name}},
+{{- end}}
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	{{.EntityNameLower}}.RecordEvent(entity.{{.EntityName}}Created{ID: {{.EntityNameLower}}.ID})
+	if err := uc.repo.Create(ctx, {{.EntityNameLower}}); err != nil {
+		return nil, repositoryError(err)
+	}
+	unitofwork.Track(ctx, {{.EntityNameLower}})
+	return {{.EntityNameLower}}, nil
+}
+
+// Update{{.EntityName}}UseCase handles Update{{.EntityName}}Command on the command bus.
+type Update{{.EntityName}}UseCase struct {
+	repo repository.{{.EntityName}}Repository
+}
+
+// NewUpdate{{.EntityName}}UseCase creates a new Update{{.EntityName}}UseCase.
+func NewUpdate{{.EntityName}}UseCase(repo repository.{{.EntityName}}Repository) *Update{{.EntityName}}UseCase {
+	return &Update{{.EntityName}}UseCase{repo: repo}
+}
+
+// Handle replaces the fields of a {{.EntityName}} and returns it. {{.EntityName}}Updated is
+// published once the unit of work of the command committed.
+func (uc *Update{{.EntityName}}UseCase) Handle(ctx context.Context, cmd Update{{.EntityName}}Command) (*entity.{{.EntityName}}, error) {
+	{{.EntityNameLower}}, err := uc.repo.GetByID(ctx, cmd.ID)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+{{- range .Fields}}
+	{{$.EntityNameLower}}.{{.Name}} = cmd.{{.Name}}
+{{- end}}
+	{{.EntityNameLower}}.UpdatedAt = time.Now().UTC()
+	{{.EntityNameLower}}.RecordEvent(entity.{{.EntityName}}Updated{ID: {{.EntityNameLower}}.ID})
+	if err := uc.repo.Update(ctx, {{.EntityNameLower}}); err != nil {
+		return nil, repositoryError(err)
+	}
+	unitofwork.Track(ctx, {{.EntityNameLower}})
+	return {{.EntityNameLower}}, nil
+}
+
+// Delete{{.EntityName}}UseCase handles Delete{{.EntityName}}Command on the command bus.
+type Delete{{.EntityName}}UseCase struct {
+	repo repository.{{.EntityName}}Repository
+}
+
+// NewDelete{{.EntityName}}UseCase creates a new Delete{{.EntityName}}UseCase.
+func NewDelete{{.EntityName}}UseCase(repo repository.{{.EntityName}}Repository) *Delete{{.EntityName}}UseCase {
+	return &Delete{{.EntityName}}UseCase{repo: repo}
+}
+
+// Handle deletes a {{.EntityName}}. {{.EntityName}}Deleted is published once the unit of work of
+// the command committed.
+func (uc *Delete{{.EntityName}}UseCase) Handle(ctx context.Context, cmd Delete{{.EntityName}}Command) (struct{}, error) {
+	if err := uc.repo.Delete(ctx, cmd.ID); err != nil {
+		return struct{}{}, repositoryError(err)
+	}
+	unitofwork.Record(ctx, entity.{{.EntityName}}Deleted{ID: cmd.ID})
+	return struct{}{}, nil
+}
+
+// repositoryError converts the errors of the repository into framework errors, which map to
+// HTTP and gRPC statuses. Framework errors, such as invalid listings, are kept.
+func repositoryError(err error) error {
+	switch {
+	case errors.GetCode(err) != "":
+		return err
+	case errors.Is(err, repository.Err{{.EntityName}}NotFound):
+		return errors.NewNotFound(err, "{{.EntityNameLower}} not found")
+	case errors.Is(err, repository.Err{{.EntityName}}Exists):
+		return errors.NewConflict(err, "{{.EntityNameLower}} already exists")
+	default:
+		return errors.NewInternal(err, "{{.EntityNameLower}} repository failed")
+	}
+}
+`
+
+const crudQueriesTemplate = `package usecase
+
+import (
+	"context"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// Get{{.EntityName}}Query asks for a {{.EntityName}} by ID.
+type Get{{.EntityName}}Query struct {
+	ID string ` + "`" + `json:"-" params:"id" validate:"required"` + "`" + `
+}
+
+// List{{.PluralTitle}}Query asks for a page of {{.Plural}}. Params holds the filters and sort order,
+// checked against repository.{{.EntityName}}Schema, and Page the page size and token.
+type List{{.PluralTitle}}Query struct {
+	Params query.Params
+	Page   pagination.Request
+}
+
+// Get{{.EntityName}}UseCase handles Get{{.EntityName}}Query on the query bus.
+type Get{{.EntityName}}UseCase struct {
+	repo repository.{{.EntityName}}Repository
+}
+
+// NewGet{{.EntityName}}UseCase creates a new Get{{.EntityName}}UseCase.
+func NewGet{{.EntityName}}UseCase(repo repository.{{.EntityName}}Repository) *Get{{.EntityName}}UseCase {
+	return &Get{{.EntityName}}UseCase{repo: repo}
+}
+
+// Handle returns the {{.EntityName}} with the ID of the query.
+func (uc *Get{{.EntityName}}UseCase) Handle(ctx context.Context, q Get{{.EntityName}}Query) (*entity.{{.EntityName}}, error) {
+	{{.EntityNameLower}}, err := uc.repo.GetByID(ctx, q.ID)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+	return {{.EntityNameLower}}, nil
+}
+
+// List{{.PluralTitle}}UseCase handles List{{.PluralTitle}}Query on the query bus.
+type List{{.PluralTitle}}UseCase struct {
+	repo      repository.{{.EntityName}}Repository
+	paginator *pagination.Paginator
+}
+
+// NewList{{.PluralTitle}}UseCase creates a new List{{.PluralTitle}}UseCase.
+func NewList{{.PluralTitle}}UseCase(repo repository.{{.EntityName}}Repository, paginator *pagination.Paginator) *List{{.PluralTitle}}UseCase {
+	return &List{{.PluralTitle}}UseCase{repo: repo, paginator: paginator}
+}
+
+// Handle returns a page of the {{.Plural}} matching the query, with their total count and the
+// token of the next page.
+func (uc *List{{.PluralTitle}}UseCase) Handle(ctx context.Context, q List{{.PluralTitle}}Query) (query.ListResult[*entity.{{.EntityName}}], error) {
+	fingerprint := q.Params.Fingerprint()
+	cursor, size, err := uc.paginator.Start(q.Page, fingerprint)
+	if err != nil {
+		return query.ListResult[*entity.{{.EntityName}}]{}, err
+	}
+
+	params := q.Params
+	params.Page = query.PageFrom(cursor, size)
+	result, err := uc.repo.List(ctx, params)
+	if err != nil {
+		return result, repositoryError(err)
+	}
+	if err := result.Sign(uc.paginator, fingerprint); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+`
+
+const crudUsecaseTestTemplate = `package usecase
+
+import (
+	"context"
+	"testing"
+{{- if .HasTime}}
+	"time"
+{{- end}}
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/infrastructure/persistence"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/cqrs"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/query"
+	"github.com/axiomod/axiomod/framework/unitofwork"
+	"github.com/axiomod/axiomod/framework/validation"
+	"github.com/axiomod/axiomod/platform/observability"
+)
+
+// newTestBuses returns buses validating messages as cqrs.Module does, with the use cases
+// registered over an in-memory repository
+func newTestBuses(t *testing.T) (*cqrs.CommandBus, *cqrs.QueryBus) {
+	repo := persistence.NewInMemory{{.EntityName}}Repository()
+	paginator, err := pagination.NewPaginator(config.PaginationConfig{})
+	require.NoError(t, err)
+
+	commands := cqrs.NewCommandBus()
+	commands.Use(cqrs.Validation(validation.New()))
+	require.NoError(t, cqrs.Register[Create{{.EntityName}}Command, *entity.{{.EntityName}}](commands, NewCreate{{.EntityName}}UseCase(repo)))
+	require.NoError(t, cqrs.Register[Update{{.EntityName}}Command, *entity.{{.EntityName}}](commands, NewUpdate{{.EntityName}}UseCase(repo)))
+	require.NoError(t, cqrs.Register[Delete{{.EntityName}}Command, struct{}](commands, NewDelete{{.EntityName}}UseCase(repo)))
+
+	queries := cqrs.NewQueryBus()
+	queries.Use(cqrs.Validation(validation.New()))
+	require.NoError(t, cqrs.Register[Get{{.EntityName}}Query, *entity.{{.EntityName}}](queries, NewGet{{.EntityName}}UseCase(repo)))
+	require.NoError(t, cqrs.Register[List{{.PluralTitle}}Query, query.ListResult[*entity.{{.EntityName}}]](queries, NewList{{.PluralTitle}}UseCase(repo, paginator)))
+	return commands, queries
+}
+
+func Test{{.EntityName}}UseCases(t *testing.T) {
+	ctx := context.Background()
+	commands, queries := newTestBuses(t)
+	created, err := cqrs.Dispatch[*entity.{{.EntityName}}](ctx, commands, Create{{.EntityName}}Command{
+{{- range .Fields}}
+		{{.Name}}: {{.Sample}},
+{{- end}}
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ID)
+
+	tests := []struct {
+		name     string
+		bus      cqrs.Dispatcher
+		message  any
+		wantCode string
+	}{
+		{"get", queries, Get{{.EntityName}}Query{ID: created.ID}, ""},
+		{"get missing", queries, Get{{.EntityName}}Query{ID: "missing"}, errors.CodeNotFound},
+{{- if .Required}}
+		{"create without {{.Required}}", commands, Create{{.EntityName}}Command{}, errors.CodeValidation},
+{{- end}}
+		{"update", commands, Update{{.EntityName}}Command{ID: created.ID{{range .Fields}}, {{.Name}}: {{.Updated}}{{end}}}, ""},
+		{"update missing", commands, Update{{.EntityName}}Command{ID: "missing"{{range .Fields}}, {{.Name}}: {{.Updated}}{{end}}}, errors.CodeNotFound},
+		{"list", queries, List{{.PluralTitle}}Query{}, ""},
+		{"list by unknown field", queries, List{{.PluralTitle}}Query{Params: query.Params{Filter: query.Filter{}.Where("unknown", query.Eq, "x")}}, errors.CodeInvalidInput},
+		{"list with bad token", queries, List{{.PluralTitle}}Query{Page: pagination.Request{PageToken: "bad"}}, errors.CodeInvalidInput},
+		{"delete missing", commands, Delete{{.EntityName}}Command{ID: "missing"}, errors.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.bus.Dispatch(ctx, tt.message)
+			if tt.wantCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantCode, errors.GetCode(err), "error: %v", err)
+		})
+	}
+
+	updated, err := cqrs.Dispatch[*entity.{{.EntityName}}](ctx, queries, Get{{.EntityName}}Query{ID: created.ID})
+	require.NoError(t, err)
+{{- range .Fields}}
+	assert.Equal(t, {{.Updated}}, updated.{{.Name}})
+{{- end}}
+
+	_, err = cqrs.Dispatch[struct{}](ctx, commands, Delete{{.EntityName}}Command{ID: created.ID})
+	require.NoError(t, err)
+	result, err := cqrs.Dispatch[query.ListResult[*entity.{{.EntityName}}]](ctx, queries, List{{.PluralTitle}}Query{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Total)
+}
+
+func Test{{.EntityName}}Events(t *testing.T) {
+	ctx := context.Background()
+	logger, err := observability.NewLogger(&config.Config{})
+	require.NoError(t, err)
+	dispatcher := events.NewDispatcher(logger)
+	var published []string
+	require.NoError(t, dispatcher.SubscribeAll(func(ctx context.Context, envelope events.Envelope) error {
+		published = append(published, envelope.Name)
+		return nil
+	}))
+	commands, _ := newTestBuses(t)
+	commands.Use(cqrs.UnitOfWork(unitofwork.New(nil, dispatcher, logger)))
+
+	created, err := cqrs.Dispatch[*entity.{{.EntityName}}](ctx, commands, Create{{.EntityName}}Command{
+{{- range .Fields}}
+		{{.Name}}: {{.Sample}},
+{{- end}}
+	})
+	require.NoError(t, err)
+	_, err = cqrs.Dispatch[*entity.{{.EntityName}}](ctx, commands, Update{{.EntityName}}Command{ID: created.ID{{range .Fields}}, {{.Name}}: {{.Updated}}{{end}}})
+	require.NoError(t, err)
+	_, err = cqrs.Dispatch[*entity.{{.EntityName}}](ctx, commands, Update{{.EntityName}}Command{ID: "missing"{{range .Fields}}, {{.Name}}: {{.Updated}}{{end}}})
+	require.Error(t, err)
+	_, err = cqrs.Dispatch[struct{}](ctx, commands, Delete{{.EntityName}}Command{ID: created.ID})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"{{.ModuleName}}.{{.EntityNameLower}}.created",
+		"{{.ModuleName}}.{{.EntityNameLower}}.updated",
+		"{{.ModuleName}}.{{.EntityNameLower}}.deleted",
+	}, published, "failed commands publish no events")
+}
+`
+
+const crudHandlerTemplate = `package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"{{.ImportPath}}/usecase"
+	"github.com/axiomod/axiomod/framework/cqrs"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// {{.EntityName}}Handler serves the {{.EntityName}} use cases over HTTP, dispatching them on the
+// command and query buses. Requests are validated by middleware.Bind, and errors written as
+// problem documents.
+type {{.EntityName}}Handler struct {
+	commands *cqrs.CommandBus
+	queries  *cqrs.QueryBus
+}
+
+// New{{.EntityName}}Handler creates a new {{.EntityName}}Handler.
+func New{{.EntityName}}Handler(commands *cqrs.CommandBus, queries *cqrs.QueryBus) *{{.EntityName}}Handler {
+	return &{{.EntityName}}Handler{commands: commands, queries: queries}
+}
+
+// RegisterRoutes registers the routes of the handler under a router, such as the /api/v1 group.
+func (h *{{.EntityName}}Handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/{{.Plural}}")
+	group.Post("/", h.Create)
+	group.Get("/", h.List)
+	group.Get("/:id", h.Get)
+	group.Put("/:id", h.Update)
+	group.Delete("/:id", h.Delete)
+}
+
+// Create handles POST /{{.Plural}}.
+func (h *{{.EntityName}}Handler) Create(c *fiber.Ctx) error {
+	cmd, err := middleware.Bind[usecase.Create{{.EntityName}}Command](c)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	{{.EntityNameLower}}, err := cqrs.Dispatch[*entity.{{.EntityName}}](c.UserContext(), h.commands, cmd)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON({{.EntityNameLower}})
+}
+
+// Get handles GET /{{.Plural}}/:id.
+func (h *{{.EntityName}}Handler) Get(c *fiber.Ctx) error {
+	q, err := middleware.Bind[usecase.Get{{.EntityName}}Query](c)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	{{.EntityNameLower}}, err := cqrs.Dispatch[*entity.{{.EntityName}}](c.UserContext(), h.queries, q)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	return c.JSON({{.EntityNameLower}})
+}
+
+// Update handles PUT /{{.Plural}}/:id.
+func (h *{{.EntityName}}Handler) Update(c *fiber.Ctx) error {
+	cmd, err := middleware.Bind[usecase.Update{{.EntityName}}Command](c)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	{{.EntityNameLower}}, err := cqrs.Dispatch[*entity.{{.EntityName}}](c.UserContext(), h.commands, cmd)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	return c.JSON({{.EntityNameLower}})
+}
+
+// Delete handles DELETE /{{.Plural}}/:id.
+func (h *{{.EntityName}}Handler) Delete(c *fiber.Ctx) error {
+	cmd, err := middleware.Bind[usecase.Delete{{.EntityName}}Command](c)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	if _, err := cqrs.Dispatch[struct{}](c.UserContext(), h.commands, cmd); err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// List handles GET /{{.Plural}}. Fields of repository.{{.EntityName}}Schema are filtered with
+// field=value or field[op]=value, and sorted with sort=-field; pages are read with
+// page_size and page_token.
+func (h *{{.EntityName}}Handler) List(c *fiber.Ctx) error {
+	params, err := query.FromFiber(c, repository.{{.EntityName}}Schema)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	page, err := pagination.FromFiber(c)
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	result, err := cqrs.Dispatch[query.ListResult[*entity.{{.EntityName}}]](c.UserContext(), h.queries, usecase.List{{.PluralTitle}}Query{Params: params, Page: page})
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	return c.JSON(result)
+}
+`
+
+const crudHandlerTestTemplate = `package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+{{- if .HasTime}}
+	"time"
+{{- end}}
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/infrastructure/persistence"
+	"{{.ImportPath}}/usecase"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/cqrs"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// newTestApp serves the routes of a {{.EntityName}}Handler over an in-memory repository
+func newTestApp(t *testing.T) *fiber.App {
+	repo := persistence.NewInMemory{{.EntityName}}Repository()
+	paginator, err := pagination.NewPaginator(config.PaginationConfig{})
+	require.NoError(t, err)
+
+	commands := cqrs.NewCommandBus()
+	require.NoError(t, cqrs.Register[usecase.Create{{.EntityName}}Command, *entity.{{.EntityName}}](commands, usecase.NewCreate{{.EntityName}}UseCase(repo)))
+	require.NoError(t, cqrs.Register[usecase.Update{{.EntityName}}Command, *entity.{{.EntityName}}](commands, usecase.NewUpdate{{.EntityName}}UseCase(repo)))
+	require.NoError(t, cqrs.Register[usecase.Delete{{.EntityName}}Command, struct{}](commands, usecase.NewDelete{{.EntityName}}UseCase(repo)))
+	queries := cqrs.NewQueryBus()
+	require.NoError(t, cqrs.Register[usecase.Get{{.EntityName}}Query, *entity.{{.EntityName}}](queries, usecase.NewGet{{.EntityName}}UseCase(repo)))
+	require.NoError(t, cqrs.Register[usecase.List{{.PluralTitle}}Query, query.ListResult[*entity.{{.EntityName}}]](queries, usecase.NewList{{.PluralTitle}}UseCase(repo, paginator)))
+
+	app := fiber.New()
+	New{{.EntityName}}Handler(commands, queries).RegisterRoutes(app)
+	return app
+}
+
+func Test{{.EntityName}}Handler(t *testing.T) {
+	app := newTestApp(t)
+	request := func(t *testing.T, method, target string, body any) (int, map[string]any) {
+		var reader io.Reader
+		if body != nil {
+			payload, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(payload)
+		}
+		req := httptest.NewRequest(method, target, reader)
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var decoded map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp.StatusCode, decoded
+	}
+
+	status, created := request(t, fiber.MethodPost, "/{{.Plural}}", usecase.Create{{.EntityName}}Command{
+{{- range .Fields}}
+		{{.Name}}: {{.Sample}},
+{{- end}}
+	})
+	require.Equal(t, fiber.StatusCreated, status)
+	id, _ := created["id"].(string)
+	require.NotEmpty(t, id)
+
+	update := usecase.Update{{.EntityName}}Command{
+{{- range .Fields}}
+		{{.Name}}: {{.Updated}},
+{{- end}}
+	}
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       any
+		wantStatus int
+	}{
+		{"get", fiber.MethodGet, "/{{.Plural}}/" + id, nil, fiber.StatusOK},
+		{"get missing", fiber.MethodGet, "/{{.Plural}}/missing", nil, fiber.StatusNotFound},
+{{- if .Required}}
+		{"create without {{.Required}}", fiber.MethodPost, "/{{.Plural}}", map[string]any{}, fiber.StatusBadRequest},
+{{- end}}
+		{"create with invalid body", fiber.MethodPost, "/{{.Plural}}", "not an object", fiber.StatusBadRequest},
+		{"update", fiber.MethodPut, "/{{.Plural}}/" + id, update, fiber.StatusOK},
+		{"update missing", fiber.MethodPut, "/{{.Plural}}/missing", update, fiber.StatusNotFound},
+		{"list", fiber.MethodGet, "/{{.Plural}}?sort=-created_at&page_size=10", nil, fiber.StatusOK},
+		{"list by unknown field", fiber.MethodGet, "/{{.Plural}}?unknown%5Beq%5D=1", nil, fiber.StatusBadRequest},
+		{"delete", fiber.MethodDelete, "/{{.Plural}}/" + id, nil, fiber.StatusNoContent},
+		{"delete again", fiber.MethodDelete, "/{{.Plural}}/" + id, nil, fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := request(t, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+
+	status, page := request(t, fiber.MethodGet, "/{{.Plural}}", nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, float64(0), page["total"])
+	assert.Empty(t, page["items"])
+}
+`
+
+const crudOpenAPITemplate = `# OpenAPI 3 paths and schemas of the {{.Plural}} API of the {{.ModuleName}} module, served by
+# {{.EntityName}}Handler under /api/v1. Merge them into the OpenAPI document of the service.
+paths:
+  /api/v1/{{.Plural}}:
+    get:
+      summary: List {{.Plural}}
+      operationId: list{{.PluralTitle}}
+      parameters:
+        - {name: page_size, in: query, schema: {type: integer}}
+        - {name: page_token, in: query, schema: {type: string}}
+        - name: sort
+          in: query
+          description: Comma-separated fields to sort on, descending when prefixed with "-", e.g. -created_at
+          schema: {type: string}
+{{- range .Fields}}
+        - name: {{.Key}}
+          in: query
+          description: Filters on {{.Key}}; other operators are given as {{.Key}}[op], e.g. {{.Key}}[ne]
+          schema: {{.OpenAPIType}}
+{{- end}}
+      responses:
+        "200":
+          description: A page of {{.Plural}}
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/{{.EntityName}}List"}
+        "400": {$ref: "#/components/responses/Problem"}
+    post:
+      summary: Create a {{.EntityNameLower}}
+      operationId: create{{.EntityName}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/{{.EntityName}}Input"}
+      responses:
+        "201":
+          description: The created {{.EntityNameLower}}
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/{{.EntityName}}"}
+        "400": {$ref: "#/components/responses/Problem"}
+  /api/v1/{{.Plural}}/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+    get:
+      summary: Get a {{.EntityNameLower}}
+      operationId: get{{.EntityName}}
+      responses:
+        "200":
+          description: The {{.EntityNameLower}}
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/{{.EntityName}}"}
+        "404": {$ref: "#/components/responses/Problem"}
+    put:
+      summary: Update a {{.EntityNameLower}}
+      operationId: update{{.EntityName}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/{{.EntityName}}Input"}
+      responses:
+        "200":
+          description: The updated {{.EntityNameLower}}
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/{{.EntityName}}"}
+        "400": {$ref: "#/components/responses/Problem"}
+        "404": {$ref: "#/components/responses/Problem"}
+    delete:
+      summary: Delete a {{.EntityNameLower}}
+      operationId: delete{{.EntityName}}
+      responses:
+        "204": {description: The {{.EntityNameLower}} was deleted}
+        "404": {$ref: "#/components/responses/Problem"}
+components:
+  schemas:
+    {{.EntityName}}:
+      type: object
+      properties:
+        id: {type: string}
+{{- range .Fields}}
+        {{.Key}}: {{.OpenAPIType}}
+{{- end}}
+        created_at: {type: string, format: date-time}
+        updated_at: {type: string, format: date-time}
+    {{.EntityName}}Input:
+      type: object
+{{- if .Required}}
+      required:
+{{- range .Fields}}{{if .Validate}}
+        - {{.Key}}
+{{- end}}{{end}}
+{{- end}}
+      properties:
+{{- range .Fields}}
+        {{.Key}}: {{.OpenAPIType}}
+{{- end}}
+    {{.EntityName}}List:
+      type: object
+      properties:
+        items:
+          type: array
+          items: {$ref: "#/components/schemas/{{.EntityName}}"}
+        total: {type: integer, description: Number of {{.Plural}} matching the filters, on every page}
+        next_page_token: {type: string, description: Token of the next page; absent on the last page}
+  responses:
+    Problem:
+      description: An RFC 7807 problem document
+      content:
+        application/problem+json:
+          schema:
+            type: object
+            properties:
+              type: {type: string}
+              title: {type: string}
+              status: {type: integer}
+              detail: {type: string}
+              code: {type: string}
+`
+
+const crudProtoTemplate = `syntax = "proto3";
+
+package {{.ModuleName}}.v1;
+
+import "google/protobuf/empty.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "{{.ModulePath}}/gen/proto/{{.ModuleName}}/v1;{{.ModuleName}}v1";
+
+// {{.EntityName}}Service is the gRPC API of the {{.ModuleName}} module.
+service {{.EntityName}}Service {
+  rpc Create{{.EntityName}}(Create{{.EntityName}}Request) returns ({{.EntityName}});
+  rpc Get{{.EntityName}}(Get{{.EntityName}}Request) returns ({{.EntityName}});
+  rpc Update{{.EntityName}}(Update{{.EntityName}}Request) returns ({{.EntityName}});
+  rpc Delete{{.EntityName}}(Delete{{.EntityName}}Request) returns (google.protobuf.Empty);
+  rpc List{{.PluralTitle}}(List{{.PluralTitle}}Request) returns (List{{.PluralTitle}}Response);
+}
+
+message {{.EntityName}} {
+  string id = 1;
+{{- range .Fields}}
+  {{.ProtoType}} {{.Key}} = {{.Number}};
+{{- end}}
+  google.protobuf.Timestamp created_at = {{.CreatedAtNumber}};
+  google.protobuf.Timestamp updated_at = {{.UpdatedAtNumber}};
+}
+
+message Create{{.EntityName}}Request {
+{{- range .Fields}}
+  {{.ProtoType}} {{.Key}} = {{.InputNumber}};
+{{- end}}
+}
+
+message Get{{.EntityName}}Request {
+  string id = 1;
+}
+
+message Update{{.EntityName}}Request {
+  string id = 1;
+{{- range .Fields}}
+  {{.ProtoType}} {{.Key}} = {{.Number}};
+{{- end}}
+}
+
+message Delete{{.EntityName}}Request {
+  string id = 1;
+}
+
+// List requests and responses use the standard pagination fields, read with
+// pagination.FromProto.
+message List{{.PluralTitle}}Request {
+  // Maximum number of items to return; the server default when 0
+  int32 page_size = 1;
+  // next_page_token of the previous response; empty for the first page
+  string page_token = 2;
+  // Filters in the query string syntax of the HTTP API, e.g. "{{(index .Fields 0).Key}}[ne]=x"
+  string filter = 3;
+  // Comma-separated fields to sort on, descending when prefixed with "-", e.g. "-created_at"
+  string sort = 4;
+}
+
+message List{{.PluralTitle}}Response {
+  repeated {{.EntityName}} items = 1;
+  // Token of the next page; empty on the last page
+  string next_page_token = 2;
+  // Number of {{.Plural}} matching the filters, on every page
+  int64 total = 3;
+}
+`
+
+const crudGRPCServiceTemplate = `package grpc
+
+// {{.EntityName}}GRPCService implements {{.EntityName}}Service of {{.ModuleName}}.proto over the command
+// and query buses, as {{.EntityName}}Handler does over HTTP. Uncomment its methods once the
+// protobuf code is generated, and register it with pb.Register{{.EntityName}}ServiceServer.
+
+import (
+	"github.com/axiomod/axiomod/framework/cqrs"
+	// "context"
+	// "net/url"
+	//
+	// "google.golang.org/protobuf/types/known/emptypb"
+	//
+	// "{{.ImportPath}}/entity"
+	// "{{.ImportPath}}/repository"
+	// "{{.ImportPath}}/usecase"
+	// "github.com/axiomod/axiomod/framework/errors"
+	// grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
+	// "github.com/axiomod/axiomod/framework/mapping"
+	// "github.com/axiomod/axiomod/framework/pagination"
+	// "github.com/axiomod/axiomod/framework/query"
+	// pb "{{.ModulePath}}/gen/proto/{{.ModuleName}}/v1"
+)
+
+// {{.EntityName}}GRPCService serves the {{.EntityName}} use cases over gRPC.
+type {{.EntityName}}GRPCService struct {
+	// pb.Unimplemented{{.EntityName}}ServiceServer
+	commands *cqrs.CommandBus
+	queries  *cqrs.QueryBus
+}
+
+// New{{.EntityName}}GRPCService creates a new {{.EntityName}}GRPCService.
+func New{{.EntityName}}GRPCService(commands *cqrs.CommandBus, queries *cqrs.QueryBus) *{{.EntityName}}GRPCService {
+	return &{{.EntityName}}GRPCService{commands: commands, queries: queries}
+}
+
+// Create{{.EntityName}} creates a {{.EntityNameLower}}.
+// func (s *{{.EntityName}}GRPCService) Create{{.EntityName}}(ctx context.Context, req *pb.Create{{.EntityName}}Request) (*pb.{{.EntityName}}, error) {
+// 	{{.EntityNameLower}}, err := cqrs.Dispatch[*entity.{{.EntityName}}](ctx, s.commands, usecase.Create{{.EntityName}}Command{
+{{- range .Fields}}
+// 		{{.Name}}: {{.FromProto}},
+{{- end}}
+// 	})
+// 	if err != nil {
+// 		return nil, grpc_pkg.ToStatus(err).Err()
+// 	}
+// 	return to{{.EntityName}}Proto({{.EntityNameLower}}), nil
+// }
+
+// Get{{.EntityName}} returns a {{.EntityNameLower}}.
+// func (s *{{.EntityName}}GRPCService) Get{{.EntityName}}(ctx context.Context, req *pb.Get{{.EntityName}}Request) (*pb.{{.EntityName}}, error) {
+// 	{{.EntityNameLower}}, err := cqrs.Dispatch[*entity.{{.EntityName}}](ctx, s.queries, usecase.Get{{.EntityName}}Query{ID: req.GetId()})
+// 	if err != nil {
+// 		return nil, grpc_pkg.ToStatus(err).Err()
+// 	}
+// 	return to{{.EntityName}}Proto({{.EntityNameLower}}), nil
+// }
+
+// Update{{.EntityName}} replaces the fields of a {{.EntityNameLower}}.
+// func (s *{{.EntityName}}GRPCService) Update{{.EntityName}}(ctx context.Context, req *pb.Update{{.EntityName}}Request) (*pb.{{.EntityName}}, error) {
+// 	{{.EntityNameLower}}, err := cqrs.Dispatch[*entity.{{.EntityName}}](ctx, s.commands, usecase.Update{{.EntityName}}Command{
+// 		ID: req.GetId(),
+{{- range .Fields}}
+// 		{{.Name}}: {{.FromProto}},
+{{- end}}
+// 	})
+// 	if err != nil {
+// 		return nil, grpc_pkg.ToStatus(err).Err()
+// 	}
+// 	return to{{.EntityName}}Proto({{.EntityNameLower}}), nil
+// }
+
+// Delete{{.EntityName}} deletes a {{.EntityNameLower}}.
+// func (s *{{.EntityName}}GRPCService) Delete{{.EntityName}}(ctx context.Context, req *pb.Delete{{.EntityName}}Request) (*emptypb.Empty, error) {
+// 	if _, err := cqrs.Dispatch[struct{}](ctx, s.commands, usecase.Delete{{.EntityName}}Command{ID: req.GetId()}); err != nil {
+// 		return nil, grpc_pkg.ToStatus(err).Err()
+// 	}
+// 	return &emptypb.Empty{}, nil
+// }
+
+// List{{.PluralTitle}} returns a page of {{.Plural}}, filtered as the HTTP API filters them.
+// func (s *{{.EntityName}}GRPCService) List{{.PluralTitle}}(ctx context.Context, req *pb.List{{.PluralTitle}}Request) (*pb.List{{.PluralTitle}}Response, error) {
+// 	values, err := url.ParseQuery(req.GetFilter())
+// 	if err != nil {
+// 		return nil, grpc_pkg.ToStatus(errors.NewInvalidInput(err, "invalid filter")).Err()
+// 	}
+// 	values.Set("sort", req.GetSort())
+// 	params, err := repository.{{.EntityName}}Schema.Parse(values)
+// 	if err != nil {
+// 		return nil, grpc_pkg.ToStatus(err).Err()
+// 	}
+// 	result, err := cqrs.Dispatch[query.ListResult[*entity.{{.EntityName}}]](ctx, s.queries, usecase.List{{.PluralTitle}}Query{
+// 		Params: params,
+// 		Page:   pagination.FromProto(req),
+// 	})
+// 	if err != nil {
+// 		return nil, grpc_pkg.ToStatus(err).Err()
+// 	}
+// 	return &pb.List{{.PluralTitle}}Response{
+// 		Items:         mapping.Slice(result.Items, to{{.EntityName}}Proto),
+// 		NextPageToken: result.NextPageToken,
+// 		Total:         int64(result.Total),
+// 	}, nil
+// }
+
+// to{{.EntityName}}Proto converts a {{.EntityName}} entity to its protobuf message.
+// func to{{.EntityName}}Proto({{.EntityNameLower}} *entity.{{.EntityName}}) *pb.{{.EntityName}} {
+// 	return &pb.{{.EntityName}}{
+// 		Id: {{.EntityNameLower}}.ID,
+{{- range .Fields}}
+// 		{{.ProtoName}}: {{.ToProto}},
+{{- end}}
+// 		CreatedAt: mapping.Timestamp({{.EntityNameLower}}.CreatedAt),
+// 		UpdatedAt: mapping.Timestamp({{.EntityNameLower}}.UpdatedAt),
+// 	}
+// }
+`
+
+const crudModuleTemplate = `package {{.ModuleName}}
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/axiomod/axiomod/framework/cqrs"
+	"github.com/axiomod/axiomod/framework/query"
+	"github.com/axiomod/axiomod/platform/server"
+
+	"{{.ImportPath}}/delivery/grpc"
+	"{{.ImportPath}}/delivery/http"
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/infrastructure/persistence"
+	"{{.ImportPath}}/repository"
+	"{{.ImportPath}}/usecase"
+)
+
+// Module provides the {{.ModuleName}} module: the {{.EntityName}} use cases on the command and query
+// buses, served over HTTP under /api/v1/{{.Plural}}. It needs cqrs.Module, pagination.Module and
+// the HTTP server. The domain events of the commands are published when events.Module and
+// unitofwork.Module are added too.
+var Module = fx.Module(
+	"{{.ModuleName}}",
+	fx.Provide(
+		// Persistence: replace persistence.NewInMemory{{.EntityName}}Repository with
+		// persistence.NewSQL{{.EntityName}}Repository to store {{.Plural}} in the database
+		fx.Annotate(
+			persistence.NewInMemory{{.EntityName}}Repository,
+			fx.As(new(repository.{{.EntityName}}Repository)),
+		),
+
+		// Delivery
+		http.New{{.EntityName}}Handler,
+		grpc.New{{.EntityName}}GRPCService,
+	),
+
+	// Use cases, dispatched with cqrs.Dispatch on the command or query bus
+	cqrs.AsCommandHandler[usecase.Create{{.EntityName}}Command, *entity.{{.EntityName}}](usecase.NewCreate{{.EntityName}}UseCase),
+	cqrs.AsCommandHandler[usecase.Update{{.EntityName}}Command, *entity.{{.EntityName}}](usecase.NewUpdate{{.EntityName}}UseCase),
+	cqrs.AsCommandHandler[usecase.Delete{{.EntityName}}Command, struct{}](usecase.NewDelete{{.EntityName}}UseCase),
+	cqrs.AsQueryHandler[usecase.Get{{.EntityName}}Query, *entity.{{.EntityName}}](usecase.NewGet{{.EntityName}}UseCase),
+	cqrs.AsQueryHandler[usecase.List{{.PluralTitle}}Query, query.ListResult[*entity.{{.EntityName}}]](usecase.NewList{{.PluralTitle}}UseCase),
+
+	// Served by the HTTP server under /api/v1. Register the gRPC service with
+	// server.AsGRPCService[*grpc.{{.EntityName}}GRPCService](&pb.{{.EntityName}}Service_ServiceDesc)
+	// once its protobuf code is generated.
+	server.AsHTTPRoutes[*http.{{.EntityName}}Handler]("/api/v1"),
+)
+`
+
+func init() {
+	generateCrudCmd.Flags().StringP("name", "n", "", "Name of the module and its entity, e.g. product (required)")
+	generateCrudCmd.Flags().String("fields", "", `Entity fields as name:type pairs, e.g. "name:string,price:float" (required)`)
+	generateCrudCmd.MarkFlagRequired("name")
+	generateCrudCmd.MarkFlagRequired("fields")
+	addTargetFlags(generateCrudCmd)
+	generateCmd.AddCommand(generateCrudCmd)
+}
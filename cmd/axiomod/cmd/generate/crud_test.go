@@ -0,0 +1,100 @@
+package generate
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newGeneratedModule creates a module requiring the framework of this repository and makes it
+// the working directory of the generators
+func newGeneratedModule(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping the build of a generated module in short mode")
+	}
+
+	framework, err := filepath.Abs(filepath.Join("..", "..", "..", ".."))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	goMod := "module example.com/shop\n\ngo 1.24.2\n\nrequire github.com/axiomod/axiomod v0.0.0\n\nreplace github.com/axiomod/axiomod => " + framework + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+	goSum, err := os.ReadFile(filepath.Join(framework, "go.sum"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0644))
+
+	t.Chdir(dir)
+	return dir
+}
+
+// checkGeneratedModule builds, vets and tests packages of a generated module
+func checkGeneratedModule(t *testing.T, dir string, packages ...string) {
+	t.Helper()
+	// The dependencies of the framework are already in the module cache
+	for _, command := range []string{"build", "vet", "test"} {
+		cmd := exec.Command("go", append([]string{command}, packages...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "go %s:\n%s", command, out)
+	}
+}
+
+// TestGenerateCrud generates a CRUD module into a new module requiring the framework of this
+// repository and checks that it builds, is vetted and passes its own tests
+func TestGenerateCrud(t *testing.T) {
+	dir := newGeneratedModule(t)
+	require.NoError(t, generateCrudCmd.Flags().Set("name", "product"))
+	require.NoError(t, generateCrudCmd.Flags().Set("fields", "name:string,price:float,active:bool,created:time"))
+	generateCrudCmd.Run(generateCrudCmd, nil)
+
+	for _, file := range []string{
+		"internal/product/module.go",
+		"internal/product/entity/product.go",
+		"internal/product/delivery/http/product_handler_test.go",
+		"proto/product/v1/product.proto",
+	} {
+		require.FileExists(t, filepath.Join(dir, file))
+	}
+	checkGeneratedModule(t, dir, "./...")
+}
+
+// TestGenerateModuleFromSchema generates a module of two related entities from a schema file
+// and checks that it builds. The Ent schemas are left out, their dependency being added by the
+// user.
+func TestGenerateModuleFromSchema(t *testing.T) {
+	dir := newGeneratedModule(t)
+	schema := `module: shop
+entities:
+  - name: customer
+    fields:
+      - {name: email, type: string, unique: true}
+  - name: order
+    fields:
+      - {name: status, type: string}
+      - {name: total, type: float}
+    relations:
+      - {name: customer, type: belongs_to, entity: customer}
+    indexes:
+      - {fields: [status, created_at]}
+`
+	require.NoError(t, os.WriteFile("schema.yaml", []byte(schema), 0644))
+	target, err := resolveTarget(generateModuleCmd)
+	require.NoError(t, err)
+	require.NoError(t, generateModuleFromSchema("schema.yaml", "", target))
+
+	for _, file := range []string{
+		"internal/shop/module.go",
+		"internal/shop/entity/customer.go",
+		"internal/shop/entity/order.go",
+		"internal/shop/infrastructure/persistence/customer_memory_repository.go",
+		"internal/shop/infrastructure/persistence/order_memory_repository.go",
+		"internal/shop/ent/schema/order.go",
+	} {
+		require.FileExists(t, filepath.Join(dir, file))
+	}
+	checkGeneratedModule(t, dir, "./internal/shop", "./internal/shop/entity", "./internal/shop/repository", "./internal/shop/infrastructure/...")
+}
@@ -0,0 +1,486 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// generateDeployCmd represents the generate deploy command
+var generateDeployCmd = &cobra.Command{
+	Use:   "deploy --target=[k8s|compose|helm]",
+	Short: "Generate a Dockerfile and the deployment manifests of the service",
+	Long: `Generate a multi-stage Dockerfile for the service, and the manifests deploying it:
+
+  k8s      Deployment, Service, HorizontalPodAutoscaler, ConfigMap and Secret in deploy/k8s,
+           with a kustomization.yaml applying them (kubectl apply -k deploy/k8s)
+  compose  docker-compose.yml running the image in deploy/compose
+  helm     a Helm chart in deploy/helm/<name>, with the same resources as k8s
+
+The liveness and readiness probes check the /live and /ready endpoints of the service, and
+Prometheus scrapes /metrics. The configuration file of the project is shipped in the
+ConfigMap with its secrets blanked out: the keys that config.Config declares as passwords,
+secrets or tokens, and the plugin settings named so. The Secret sets them back through their
+APP_ environment variables, e.g. APP_DATABASE_PASSWORD for database.password.
+
+Example:
+  axiomod generate deploy --target=k8s
+  axiomod generate deploy --target=helm --image=registry.example.com/shop:1.2.0
+  axiomod generate deploy --target=compose --force
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		deployTarget, _ := cmd.Flags().GetString("target")
+		if _, ok := deployTargets[deployTarget]; !ok {
+			fmt.Printf("Error: target must be one of %s\n", strings.Join(deployTargetNames(), ", "))
+			os.Exit(1)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		output, _ := cmd.Flags().GetString("output")
+
+		data, err := loadDeployData(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		dir := filepath.Join(data.Root, output, deployTarget)
+		switch deployTarget {
+		case "helm":
+			dir = filepath.Join(dir, data.Name)
+		case "compose":
+			data.ComposeFile = filepath.ToSlash(filepath.Join(output, deployTarget, "docker-compose.yml"))
+			context, err := filepath.Rel(filepath.Join(output, deployTarget), ".")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			data.Context = filepath.ToSlash(context)
+		}
+		files := make(map[string]string)
+		for name, content := range deployTargets[deployTarget] {
+			files[filepath.Join(dir, filepath.FromSlash(name))] = content
+		}
+		var paths []string
+		for name := range files {
+			paths = append(paths, name)
+		}
+		sort.Strings(paths)
+		if !force {
+			for _, name := range paths {
+				if _, err := os.Stat(name); err == nil {
+					fmt.Printf("Error: %s already exists; use --force to regenerate it\n", name)
+					os.Exit(1)
+				}
+			}
+		}
+
+		// The Dockerfile is shared by the targets: an existing one is kept unless --force
+		fmt.Printf("Generating %s deployment of %s\n", deployTarget, data.Name)
+		for _, name := range []string{"Dockerfile", ".dockerignore"} {
+			file := filepath.Join(data.Root, name)
+			if _, err := os.Stat(file); err == nil && !force {
+				fmt.Printf("Keeping existing file: %s\n", file)
+				continue
+			}
+			generateDeployFile(dockerFiles[name], file, data)
+		}
+		for _, name := range paths {
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				fmt.Printf("Error creating directory %s: %v\n", filepath.Dir(name), err)
+				os.Exit(1)
+			}
+			generateDeployFile(files[name], name, data)
+		}
+
+		fmt.Printf("\nDeployment of %s generated successfully in %s\n", data.Name, dir)
+		fmt.Println("\nRemember to:")
+		step := 1
+		if data.LocalReplace {
+			fmt.Printf("%d. Remove the local replace directives of go.mod, which docker build cannot follow.\n", step)
+			step++
+		}
+		fmt.Printf("%d. Build and push the image: docker build -t %s . && docker push %s\n", step, data.Image, data.Image)
+		step++
+		if len(data.Secrets) > 0 {
+			fmt.Printf("%d. Set the secrets: %s\n", step, strings.Join(data.secretEnvs(), ", "))
+			step++
+		}
+		switch deployTarget {
+		case "k8s":
+			fmt.Printf("%d. Apply the manifests: kubectl apply -k %s\n", step, dir)
+		case "compose":
+			fmt.Printf("%d. Start the service: docker compose -f %s up -d\n", step, filepath.Join(dir, "docker-compose.yml"))
+		case "helm":
+			fmt.Printf("%d. Install the chart: helm install %s %s\n", step, data.Name, dir)
+		}
+	},
+}
+
+// dockerFiles are the files of the image of the service, generated for every target
+var dockerFiles = map[string]string{
+	"Dockerfile":    dockerfileTemplate,
+	".dockerignore": dockerignoreTemplate,
+}
+
+// deployTargets are the files generated for each target of generate deploy, besides the
+// dockerFiles, by path in the target directory
+var deployTargets = map[string]map[string]string{
+	"k8s": {
+		"deployment.yaml":    k8sDeploymentTemplate,
+		"service.yaml":       k8sServiceTemplate,
+		"hpa.yaml":           k8sHPATemplate,
+		"configmap.yaml":     k8sConfigMapTemplate,
+		"secret.yaml":        k8sSecretTemplate,
+		"kustomization.yaml": k8sKustomizationTemplate,
+	},
+	"compose": {
+		"docker-compose.yml": composeDeployTemplate,
+	},
+	"helm": {
+		"Chart.yaml":                helmChartTemplate,
+		"values.yaml":               helmValuesTemplate,
+		".helmignore":               helmIgnoreTemplate,
+		"templates/_helpers.tpl":    helmHelpersTemplate,
+		"templates/deployment.yaml": helmDeploymentTemplate,
+		"templates/service.yaml":    helmServiceTemplate,
+		"templates/hpa.yaml":        helmHPATemplate,
+		"templates/configmap.yaml":  helmConfigMapTemplate,
+		"templates/secret.yaml":     helmSecretTemplate,
+		"templates/NOTES.txt":       helmNotesTemplate,
+	},
+}
+
+// deployTargetNames returns the names of the deploy targets, sorted
+func deployTargetNames() []string {
+	names := make([]string, 0, len(deployTargets))
+	for name := range deployTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// deployData is the template data of generate deploy
+type deployData struct {
+	Root         string // directory of the Go module of the service
+	Name         string // service name, the last element of the module path
+	Image        string // image reference, e.g. shop:latest
+	ImageRepo    string // Image without its tag
+	ImageTag     string
+	GoVersion    string // major.minor version of the go directive, for the builder image
+	Main         string // package of the service binary, e.g. ./cmd/shop
+	ConfigFile   string // configuration file of the project, relative to Root
+	Config       string // configuration shipped in the ConfigMap, without the secrets
+	HTTPPort     int
+	GRPCPort     int // 0 when the configuration has no grpc section
+	Replicas     int // minimum number of replicas
+	MaxReplicas  int
+	ComposeFile  string // path of the compose file, relative to Root
+	Context      string // Root relative to the directory of the compose file
+	Secrets      []deploySecret
+	LocalReplace bool // go.mod replaces modules with local directories
+}
+
+// deploySecret is a configuration key set from the Secret
+type deploySecret struct {
+	Key string // configuration key, e.g. database.password
+	Env string // environment variable overriding it, e.g. APP_DATABASE_PASSWORD
+}
+
+// secretEnvs returns the environment variables of the secrets
+func (d deployData) secretEnvs() []string {
+	envs := make([]string, len(d.Secrets))
+	for i, secret := range d.Secrets {
+		envs[i] = secret.Env
+	}
+	return envs
+}
+
+// deployConfigFiles are the configuration files of a project, by preference
+var deployConfigFiles = []string{"config/service_default.yaml", "configs/service_default.yaml", "framework/config/service_default.yaml"}
+
+// loadDeployData reads the go.mod and configuration of the project and the flags of
+// generate deploy
+func loadDeployData(cmd *cobra.Command) (deployData, error) {
+	root, modulePath, err := findModule()
+	if err != nil {
+		return deployData{}, fmt.Errorf("%w; run the generator inside the Go module of the service", err)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, root); err == nil {
+			root = rel
+		}
+	}
+	goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return deployData{}, err
+	}
+
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = path.Base(modulePath)
+	}
+	if !deployNamePattern.MatchString(name) {
+		return deployData{}, fmt.Errorf("name %q is not a DNS label; set --name, e.g. --name=shop", name)
+	}
+	image, _ := cmd.Flags().GetString("image")
+	if image == "" {
+		image = name + ":latest"
+	}
+	replicas, _ := cmd.Flags().GetInt("replicas")
+	data := deployData{
+		Root:         root,
+		Name:         name,
+		Image:        image,
+		ImageRepo:    image,
+		ImageTag:     "latest",
+		GoVersion:    goDirective(goMod),
+		Replicas:     replicas,
+		MaxReplicas:  max(replicas, 10),
+		HTTPPort:     8080,
+		LocalReplace: localReplacePattern.Match(goMod),
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		data.ImageRepo, data.ImageTag = image[:i], image[i+1:]
+	}
+
+	data.Main, _ = cmd.Flags().GetString("main")
+	if data.Main == "" {
+		if data.Main, err = mainPackage(root, name, modulePath); err != nil {
+			return deployData{}, err
+		}
+	}
+
+	data.ConfigFile, _ = cmd.Flags().GetString("config")
+	if data.ConfigFile == "" {
+		for _, file := range deployConfigFiles {
+			if _, err := os.Stat(filepath.Join(root, file)); err == nil {
+				data.ConfigFile = file
+				break
+			}
+		}
+	}
+	if data.ConfigFile == "" {
+		return deployData{}, fmt.Errorf("no configuration file found in %s; set --config", strings.Join(deployConfigFiles, ", "))
+	}
+	content, err := os.ReadFile(filepath.Join(root, data.ConfigFile))
+	if err != nil {
+		return deployData{}, err
+	}
+	if err := data.loadConfig(content); err != nil {
+		return deployData{}, fmt.Errorf("reading %s: %w", data.ConfigFile, err)
+	}
+	return data, nil
+}
+
+var (
+	// deployNamePattern matches the names of Kubernetes resources
+	deployNamePattern = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	// localReplacePattern matches the replace directives of go.mod pointing to directories
+	localReplacePattern = regexp.MustCompile(`(?m)=>\s*\.{1,2}/|=>\s*/`)
+	// goDirectivePattern matches the go directive of go.mod
+	goDirectivePattern = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	// secretFieldPattern matches the names of the configuration fields holding secrets
+	secretFieldPattern = regexp.MustCompile(`(?i)^(\w*password|\w*secret|secretkey|token)$`)
+)
+
+// goDirective returns the major.minor version of the go directive of go.mod
+func goDirective(goMod []byte) string {
+	if m := goDirectivePattern.FindSubmatch(goMod); m != nil {
+		return string(m[1])
+	}
+	return "1.24"
+}
+
+// mainPackage returns the package of the service binary: cmd/<name>, or the only main
+// package of cmd
+func mainPackage(root, name, modulePath string) (string, error) {
+	candidates := []string{name, name + "-server"}
+	if modulePath == frameworkModule {
+		candidates = []string{"axiomod-server"}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(root, "cmd", candidate, "main.go")); err == nil {
+			return "./cmd/" + candidate, nil
+		}
+	}
+	mains, _ := filepath.Glob(filepath.Join(root, "cmd", "*", "main.go"))
+	if len(mains) == 1 {
+		return "./cmd/" + filepath.Base(filepath.Dir(mains[0])), nil
+	}
+	return "", fmt.Errorf("cannot tell the main package of the service in cmd; set --main, e.g. --main=./cmd/%s", name)
+}
+
+// loadConfig reads the ports of the configuration, and blanks out its secrets for the
+// ConfigMap
+func (d *deployData) loadConfig(content []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("configuration is not a YAML mapping")
+	}
+	root := doc.Content[0]
+	if port := yamlInt(root, "http", "port"); port > 0 {
+		d.HTTPPort = port
+	}
+	if grpc := yamlValue(root, "grpc"); grpc != nil {
+		d.GRPCPort = 50051
+		if port := yamlInt(root, "grpc", "port"); port > 0 {
+			d.GRPCPort = port
+		}
+	}
+
+	schemaKeys, schemaSecrets := configSchemaKeys()
+	var walk func(node *yaml.Node, keys []string)
+	walk = func(node *yaml.Node, keys []string) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := append(append([]string(nil), keys...), key.Value)
+			dotted := strings.Join(path, ".")
+			switch value.Kind {
+			case yaml.MappingNode:
+				walk(value, path)
+			case yaml.ScalarNode:
+				lower := strings.ToLower(dotted)
+				secret := schemaSecrets[lower] || (!schemaKeys[lower] && secretFieldPattern.MatchString(key.Value))
+				if !secret || value.Tag != "!!str" {
+					continue
+				}
+				env := "APP_" + strings.ToUpper(strings.ReplaceAll(dotted, ".", "_"))
+				d.Secrets = append(d.Secrets, deploySecret{Key: dotted, Env: env})
+				value.Value = ""
+				value.Style = yaml.DoubleQuotedStyle
+				value.LineComment = "# set by " + env + " from the Secret"
+			}
+		}
+	}
+	walk(root, nil)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	d.Config = buf.String()
+	return nil
+}
+
+// configSchemaKeys returns the lowercase keys of the fields of config.Config, and those of
+// the fields holding secrets: strings named password, secret or token
+func configSchemaKeys() (keys, secrets map[string]bool) {
+	keys, secrets = make(map[string]bool), make(map[string]bool)
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key := prefix + strings.ToLower(field.Name)
+			keys[key] = true
+			switch field.Type.Kind() {
+			case reflect.Struct:
+				walk(field.Type, key+".")
+			case reflect.String:
+				if secretFieldPattern.MatchString(field.Name) {
+					secrets[key] = true
+				}
+			}
+		}
+	}
+	walk(reflect.TypeOf(config.Config{}), "")
+	return keys, secrets
+}
+
+// yamlValue returns the value of a key path of a mapping, or nil; keys match
+// case-insensitively, like Viper
+func yamlValue(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// yamlInt returns the integer value of a key path of a mapping, or 0
+func yamlInt(node *yaml.Node, keys ...string) int {
+	value := yamlValue(node, keys...)
+	if value == nil {
+		return 0
+	}
+	var n int
+	if err := value.Decode(&n); err != nil {
+		return 0
+	}
+	return n
+}
+
+// generateDeployFile renders a template of generate deploy to a file. The templates use [[
+// and ]] as delimiters, leaving {{ and }} to Helm.
+func generateDeployFile(tmplContent, filePath string, data deployData) {
+	tmpl, err := template.New(filepath.Base(filePath)).Delims("[[", "]]").Funcs(template.FuncMap{
+		"indent": func(spaces int, text string) string {
+			lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+			for i, line := range lines {
+				if line != "" {
+					lines[i] = strings.Repeat(" ", spaces) + line
+				}
+			}
+			return strings.Join(lines, "\n")
+		},
+	}).Parse(tmplContent)
+	if err != nil {
+		fmt.Printf("Error parsing template %s: %v\n", filepath.Base(filePath), err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		fmt.Printf("Error executing template %s: %v\n", filepath.Base(filePath), err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		fmt.Printf("Error creating file %s: %v\n", filePath, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Generated file: %s\n", filePath)
+}
+
+func init() {
+	generateDeployCmd.Flags().String("target", "k8s", "Deployment target: k8s, compose or helm")
+	generateDeployCmd.Flags().String("name", "", "Name of the service and its resources (default: the last element of the module path)")
+	generateDeployCmd.Flags().String("image", "", "Image of the service (default: <name>:latest)")
+	generateDeployCmd.Flags().String("main", "", "Package of the service binary (default: ./cmd/<name>)")
+	generateDeployCmd.Flags().String("config", "", "Configuration file of the service (default: config/service_default.yaml)")
+	generateDeployCmd.Flags().String("output", "deploy", "Directory of the manifests")
+	generateDeployCmd.Flags().Int("replicas", 2, "Minimum number of replicas")
+	generateDeployCmd.Flags().Bool("force", false, "Overwrite existing files")
+	generateCmd.AddCommand(generateDeployCmd)
+}
@@ -0,0 +1,464 @@
+package generate
+
+// Templates of generate deploy, rendered with [[ and ]] as delimiters
+
+const dockerfileTemplate = `# Build stage
+FROM golang:[[.GoVersion]]-alpine AS builder
+
+WORKDIR /src
+
+# Download the modules first, so that they stay cached until go.mod or go.sum change
+COPY go.mod go.sum ./
+RUN go mod download
+
+COPY . .
+RUN CGO_ENABLED=0 GOOS=linux go build -trimpath -ldflags="-s -w" -o /out/[[.Name]] [[.Main]]
+
+# Final stage
+FROM alpine:3.20
+
+RUN apk add --no-cache ca-certificates tzdata \
+    && addgroup -S -g 10001 app \
+    && adduser -S -u 10001 -G app app
+
+WORKDIR /app
+COPY --from=builder /out/[[.Name]] /app/[[.Name]]
+COPY [[.ConfigFile]] /app/config/service_default.yaml
+
+USER 10001
+EXPOSE [[.HTTPPort]][[if .GRPCPort]] [[.GRPCPort]][[end]]
+
+HEALTHCHECK --interval=10s --timeout=3s --start-period=10s \
+    CMD wget -qO- http://localhost:[[.HTTPPort]]/live || exit 1
+
+ENTRYPOINT ["/app/[[.Name]]", "-config", "/app/config/service_default.yaml"]
+`
+
+const dockerignoreTemplate = `.git
+.env
+bin/
+deploy/
+tmp/
+*.log
+`
+
+const k8sDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: [[.Name]]
+  labels:
+    app.kubernetes.io/name: [[.Name]]
+spec:
+  replicas: [[.Replicas]]
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: [[.Name]]
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: [[.Name]]
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "[[.HTTPPort]]"
+        prometheus.io/path: /metrics
+    spec:
+      terminationGracePeriodSeconds: 30
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 10001
+        runAsGroup: 10001
+      containers:
+        - name: [[.Name]]
+          image: [[.Image]]
+          ports:
+            - name: http
+              containerPort: [[.HTTPPort]]
+[[- if .GRPCPort]]
+            - name: grpc
+              containerPort: [[.GRPCPort]]
+[[- end]]
+          envFrom:
+            - secretRef:
+                name: [[.Name]]
+          startupProbe:
+            httpGet:
+              path: /live
+              port: http
+            periodSeconds: 2
+            failureThreshold: 30
+          livenessProbe:
+            httpGet:
+              path: /live
+              port: http
+            periodSeconds: 10
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: /ready
+              port: http
+            periodSeconds: 5
+            failureThreshold: 3
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 512Mi
+          securityContext:
+            allowPrivilegeEscalation: false
+          volumeMounts:
+            - name: config
+              mountPath: /app/config
+              readOnly: true
+      volumes:
+        - name: config
+          configMap:
+            name: [[.Name]]
+`
+
+const k8sServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: [[.Name]]
+  labels:
+    app.kubernetes.io/name: [[.Name]]
+spec:
+  type: ClusterIP
+  selector:
+    app.kubernetes.io/name: [[.Name]]
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+[[- if .GRPCPort]]
+    - name: grpc
+      port: [[.GRPCPort]]
+      targetPort: grpc
+[[- end]]
+`
+
+const k8sHPATemplate = `apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: [[.Name]]
+  labels:
+    app.kubernetes.io/name: [[.Name]]
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: [[.Name]]
+  minReplicas: [[.Replicas]]
+  maxReplicas: [[.MaxReplicas]]
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 70
+`
+
+const k8sConfigMapTemplate = `# Configuration of [[.Name]], from [[.ConfigFile]]; its secrets are set by the Secret
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [[.Name]]
+  labels:
+    app.kubernetes.io/name: [[.Name]]
+data:
+  service_default.yaml: |
+[[indent 4 .Config]]
+`
+
+const k8sSecretTemplate = `# Secrets of the configuration of [[.Name]], overriding the keys blanked out in the ConfigMap.
+# Fill in the values, and keep them out of version control, e.g. with Sealed Secrets or the
+# External Secrets Operator.
+apiVersion: v1
+kind: Secret
+metadata:
+  name: [[.Name]]
+  labels:
+    app.kubernetes.io/name: [[.Name]]
+type: Opaque
+stringData:[[if not .Secrets]] {}[[end]]
+[[- range .Secrets]]
+  [[.Env]]: "" # [[.Key]]
+[[- end]]
+`
+
+const k8sKustomizationTemplate = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - configmap.yaml
+  - secret.yaml
+  - deployment.yaml
+  - service.yaml
+  - hpa.yaml
+`
+
+const composeDeployTemplate = `# Runs the [[.Name]] image: docker compose -f [[.ComposeFile]] up -d
+#
+# The service reads the configuration of the project. Point its hosts to the services of
+# this network, or override them with APP_ variables, e.g. APP_DATABASE_HOST.
+services:
+  [[.Name]]:
+    image: [[.Image]]
+    build:
+      context: [[.Context]]
+    ports:
+      - "[[.HTTPPort]]:[[.HTTPPort]]"
+[[- if .GRPCPort]]
+      - "[[.GRPCPort]]:[[.GRPCPort]]"
+[[- end]]
+[[- if .Secrets]]
+    environment:
+[[- range .Secrets]]
+      [[.Env]]: ${[[.Env]]:-} # [[.Key]]
+[[- end]]
+[[- end]]
+    volumes:
+      - [[.Context]]/[[.ConfigFile]]:/app/config/service_default.yaml:ro
+    restart: unless-stopped
+`
+
+const helmChartTemplate = `apiVersion: v2
+name: [[.Name]]
+description: A Helm chart of [[.Name]]
+type: application
+version: 0.1.0
+appVersion: "[[.ImageTag]]"
+`
+
+const helmValuesTemplate = `replicaCount: [[.Replicas]]
+
+image:
+  repository: [[.ImageRepo]]
+  tag: "" # defaults to the appVersion of the chart
+  pullPolicy: IfNotPresent
+
+# Ports of the service, as configured in config
+containerPorts:
+  http: [[.HTTPPort]]
+[[- if .GRPCPort]]
+  grpc: [[.GRPCPort]]
+[[- end]]
+
+service:
+  type: ClusterIP
+  port: 80
+[[- if .GRPCPort]]
+  grpcPort: [[.GRPCPort]]
+[[- end]]
+
+autoscaling:
+  enabled: true
+  minReplicas: [[.Replicas]]
+  maxReplicas: [[.MaxReplicas]]
+  targetCPUUtilizationPercentage: 70
+
+resources:
+  requests:
+    cpu: 100m
+    memory: 128Mi
+  limits:
+    memory: 512Mi
+
+# Configuration file of the service, from [[.ConfigFile]]; its secrets are set by secrets
+config: |
+[[indent 2 .Config]]
+
+# Environment variables of the Secret, overriding the keys blanked out in config. Set them
+# at install time, e.g. --set secrets.APP_DATABASE_PASSWORD=..., rather than in this file.
+secrets:[[if not .Secrets]] {}[[end]]
+[[- range .Secrets]]
+  [[.Env]]: "" # [[.Key]]
+[[- end]]
+`
+
+const helmIgnoreTemplate = `.git/
+*.tmp
+*.bak
+`
+
+const helmHelpersTemplate = `{{/* Name of the chart */}}
+{{- define "[[.Name]].name" -}}
+{{- .Chart.Name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/* Name of the resources of the release */}}
+{{- define "[[.Name]].fullname" -}}
+{{- if contains .Chart.Name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%s-%s" .Release.Name .Chart.Name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+
+{{/* Labels of the resources */}}
+{{- define "[[.Name]].labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" }}
+{{ include "[[.Name]].selectorLabels" . }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+
+{{/* Labels selecting the pods of the release */}}
+{{- define "[[.Name]].selectorLabels" -}}
+app.kubernetes.io/name: {{ include "[[.Name]].name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+`
+
+const helmDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "[[.Name]].fullname" . }}
+  labels:
+    {{- include "[[.Name]].labels" . | nindent 4 }}
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+  {{- end }}
+  selector:
+    matchLabels:
+      {{- include "[[.Name]].selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      labels:
+        {{- include "[[.Name]].selectorLabels" . | nindent 8 }}
+      annotations:
+        checksum/config: {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}
+        checksum/secret: {{ include (print $.Template.BasePath "/secret.yaml") . | sha256sum }}
+        prometheus.io/scrape: "true"
+        prometheus.io/port: {{ .Values.containerPorts.http | quote }}
+        prometheus.io/path: /metrics
+    spec:
+      terminationGracePeriodSeconds: 30
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 10001
+        runAsGroup: 10001
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.containerPorts.http }}
+            {{- if .Values.containerPorts.grpc }}
+            - name: grpc
+              containerPort: {{ .Values.containerPorts.grpc }}
+            {{- end }}
+          envFrom:
+            - secretRef:
+                name: {{ include "[[.Name]].fullname" . }}
+          startupProbe:
+            httpGet:
+              path: /live
+              port: http
+            periodSeconds: 2
+            failureThreshold: 30
+          livenessProbe:
+            httpGet:
+              path: /live
+              port: http
+            periodSeconds: 10
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: /ready
+              port: http
+            periodSeconds: 5
+            failureThreshold: 3
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+          securityContext:
+            allowPrivilegeEscalation: false
+          volumeMounts:
+            - name: config
+              mountPath: /app/config
+              readOnly: true
+      volumes:
+        - name: config
+          configMap:
+            name: {{ include "[[.Name]].fullname" . }}
+`
+
+const helmServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "[[.Name]].fullname" . }}
+  labels:
+    {{- include "[[.Name]].labels" . | nindent 4 }}
+spec:
+  type: {{ .Values.service.type }}
+  selector:
+    {{- include "[[.Name]].selectorLabels" . | nindent 4 }}
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http
+    {{- if .Values.service.grpcPort }}
+    - name: grpc
+      port: {{ .Values.service.grpcPort }}
+      targetPort: grpc
+    {{- end }}
+`
+
+const helmHPATemplate = `{{- if .Values.autoscaling.enabled }}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ include "[[.Name]].fullname" . }}
+  labels:
+    {{- include "[[.Name]].labels" . | nindent 4 }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ include "[[.Name]].fullname" . }}
+  minReplicas: {{ .Values.autoscaling.minReplicas }}
+  maxReplicas: {{ .Values.autoscaling.maxReplicas }}
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{ .Values.autoscaling.targetCPUUtilizationPercentage }}
+{{- end }}
+`
+
+const helmConfigMapTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "[[.Name]].fullname" . }}
+  labels:
+    {{- include "[[.Name]].labels" . | nindent 4 }}
+data:
+  service_default.yaml: |
+    {{- .Values.config | nindent 4 }}
+`
+
+const helmSecretTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ include "[[.Name]].fullname" . }}
+  labels:
+    {{- include "[[.Name]].labels" . | nindent 4 }}
+type: Opaque
+stringData:
+  {{- range $key, $value := .Values.secrets }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+`
+
+const helmNotesTemplate = `[[.Name]] is deployed as {{ include "[[.Name]].fullname" . }}.
+
+Check that it is ready:
+
+  kubectl rollout status deployment/{{ include "[[.Name]].fullname" . }} --namespace {{ .Release.Namespace }}
+  kubectl port-forward service/{{ include "[[.Name]].fullname" . }} 8080:{{ .Values.service.port }} --namespace {{ .Release.Namespace }}
+  curl http://localhost:8080/ready
+`
@@ -14,8 +14,17 @@ This command has subcommands for generating different types of code.
 
 Example:
   axiomod generate module --name=user
+  axiomod generate crud --name=product --fields="name:string,price:float"
+  axiomod generate proto --name=product --force
   axiomod generate service --name=auth
   axiomod generate handler --name=product
+  axiomod generate plugin --name=stripe
+  axiomod generate job --name=cleanup --schedule="0 2 * * *"
+  axiomod generate consumer --topic=orders
+  axiomod generate graphql --module=order
+  axiomod generate deploy --target=k8s
+  axiomod generate openapi
+  axiomod generate server --spec=openapi.yaml --module=catalog
 `,
 }
 
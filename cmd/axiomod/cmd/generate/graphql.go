@@ -0,0 +1,188 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// generateGraphQLCmd represents the generate graphql command
+var generateGraphQLCmd = &cobra.Command{
+	Use:   "graphql --module=[name]",
+	Short: "Generate a GraphQL schema and resolvers for a module",
+	Long: `Generate a GraphQL schema and resolvers of a module, served with gqlgen by the
+platform/graphql module.
+
+The schema, gqlgen configuration, root resolver and server go to the delivery/graphql
+package of the module. The server enforces the @auth directive of the schema with the
+JWT claims of the request and traces the resolvers, and its fx module mounts it at
+/graphql/<module>. Run go generate in the package to generate the executable schema and
+resolver stubs with gqlgen, and again after changing the schema.
+
+Example:
+  axiomod generate graphql --module=order
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		moduleName, _ := cmd.Flags().GetString("module")
+		if !crudNamePattern.MatchString(moduleName) {
+			fmt.Println("Error: module must be a lowercase word, such as order")
+			os.Exit(1)
+		}
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, _ := target.module(moduleName)
+		graphqlPath := filepath.Join(modulePath, "delivery", "graphql")
+		schemaFile := filepath.Join(graphqlPath, "schema.graphqls")
+		if _, err := os.Stat(schemaFile); err == nil {
+			fmt.Printf("Error: %s already exists\n", schemaFile)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(graphqlPath, 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", graphqlPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating GraphQL schema: %s\n", moduleName)
+
+		goName, _ := fieldNames(moduleName)
+		data := struct {
+			Module string
+			Name   string
+			Title  string
+		}{
+			Module: moduleName,
+			Name:   goName,
+			Title:  strings.Title(moduleName),
+		}
+		generateFile(graphqlSchemaTemplate, schemaFile, data)
+		generateFile(gqlgenConfigTemplate, filepath.Join(graphqlPath, "gqlgen.yml"), data)
+		generateFile(graphqlResolverTemplate, filepath.Join(graphqlPath, "resolver.go"), data)
+		generateFile(graphqlServerTemplate, filepath.Join(graphqlPath, "server.go"), data)
+
+		fmt.Printf("\nGraphQL schema %s generated successfully in %s\n", moduleName, graphqlPath)
+		fmt.Println("\nRemember to:")
+		fmt.Println("1. Add gqlgen to your module: go get github.com/99designs/gqlgen")
+		fmt.Printf("2. Generate the executable schema: go generate ./%s\n", filepath.ToSlash(graphqlPath))
+		fmt.Println("3. Implement the resolvers in schema.resolvers.go.")
+		fmt.Println("4. Add graphql.Module of platform/graphql and the Module of the package to your application.")
+	},
+}
+
+const graphqlSchemaTemplate = `# GraphQL schema of the {{.Title}} module. Run go generate after changing it.
+
+"Restricts a field to authenticated users, and to users with one of roles when set"
+directive @auth(roles: [String!]) on FIELD_DEFINITION
+
+type {{.Name}} {
+  id: ID!
+}
+
+type Query {
+  "{{.Title}} with the given id"
+  {{.Module}}(id: ID!): {{.Name}} @auth
+}
+`
+
+const gqlgenConfigTemplate = `# gqlgen configuration, see https://gqlgen.com/config/
+schema:
+  - schema.graphqls
+
+exec:
+  filename: generated.go
+  package: graphql
+
+model:
+  filename: model/models_gen.go
+  package: model
+
+resolver:
+  layout: follow-schema
+  dir: .
+  package: graphql
+  filename_template: "{name}.resolvers.go"
+
+models:
+  ID:
+    model:
+      - github.com/99designs/gqlgen/graphql.ID
+`
+
+const graphqlResolverTemplate = `package graphql
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+// Resolver is the root resolver of the {{.Title}} schema. Add the dependencies of the
+// resolvers, such as the use cases of the module, as fields.
+type Resolver struct{}
+
+// NewResolver creates the root resolver
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+`
+
+const graphqlServerTemplate = `package graphql
+
+import (
+	"context"
+
+	"github.com/axiomod/axiomod/framework/config"
+	axgraphql "github.com/axiomod/axiomod/platform/graphql"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.uber.org/fx"
+)
+
+// Module serves the {{.Title}} schema at /{{.Module}} under the path of the GraphQL module
+var Module = fx.Options(
+	fx.Provide(NewResolver),
+	fx.Provide(NewServer),
+	axgraphql.AsEndpoint[*Server]("/{{.Module}}"),
+)
+
+// Server is the GraphQL handler of the {{.Title}} schema
+type Server struct {
+	*handler.Server
+}
+
+// NewServer creates the handler of the schema. It enforces @auth with the claims of the
+// request, traces the resolvers and disables introspection in production.
+func NewServer(cfg *config.Config, resolver *Resolver, gql *axgraphql.Server) *Server {
+	schema := Config{Resolvers: resolver}
+	schema.Directives.Auth = func(ctx context.Context, obj any, next gqlgen.Resolver, roles []string) (any, error) {
+		return axgraphql.Authorize(ctx, next, roles...)
+	}
+
+	srv := handler.New(NewExecutableSchema(schema))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	if cfg.App.Environment != "production" {
+		srv.Use(extension.Introspection{})
+	}
+	srv.AroundFields(func(ctx context.Context, next gqlgen.Resolver) (any, error) {
+		field := gqlgen.GetFieldContext(ctx)
+		if !field.IsResolver {
+			return next(ctx)
+		}
+		return gql.TraceField(ctx, field.Object, field.Field.Name, next)
+	})
+	return &Server{Server: srv}
+}
+`
+
+func init() {
+	generateGraphQLCmd.Flags().StringP("module", "m", "", "Module to generate the schema of, e.g. order (required)")
+	generateGraphQLCmd.MarkFlagRequired("module")
+	addTargetFlags(generateGraphQLCmd)
+	generateCmd.AddCommand(generateGraphQLCmd)
+}
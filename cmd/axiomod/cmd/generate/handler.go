@@ -1,7 +1,9 @@
 package generate
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
@@ -33,7 +35,12 @@ Example:
 		if moduleName == "" {
 			moduleName = name // Default to name if module not specified
 		}
-		modulePath := filepath.Join("examples", moduleName)
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, importPath := target.module(moduleName)
 		handlerPath := filepath.Join(modulePath, "delivery", "http")
 		servicePath := filepath.Join(modulePath, "service")
 		entityPath := filepath.Join(modulePath, "entity")
@@ -46,6 +53,7 @@ Example:
 		// Define template data
 		data := struct {
 			ModuleName      string
+			ImportPath      string
 			ModuleNameTitle string
 			EntityName      string
 			EntityNameLower string
@@ -53,6 +61,7 @@ Example:
 			HandlerName     string
 		}{
 			ModuleName:      name,
+			ImportPath:      importPath,
 			ModuleNameTitle: strings.Title(name),
 			EntityName:      strings.Title(name), // Assuming entity name matches module name
 			EntityNameLower: name,
@@ -68,8 +77,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
-
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/service"
+	// "{{.ImportPath}}/service"
 )
 
 // {{.HandlerName}} handles HTTP requests for the {{.ModuleName}} module.
@@ -87,10 +95,10 @@ func New{{.HandlerName}}(logger *zap.Logger /*, service *service.{{.ServiceName}
 	}
 }
 
-// RegisterRoutes registers the handler routes with the Fiber app.
-func (h *{{.HandlerName}}) RegisterRoutes(app *fiber.App) {
+// RegisterRoutes registers the handler routes under a router, such as the Fiber app.
+func (h *{{.HandlerName}}) RegisterRoutes(router fiber.Router) {
 	// Define routes for the {{.ModuleName}} module
-	group := app.Group("/{{.ModuleName}}")
+	group := router.Group("/{{.ModuleName}}")
 
 	group.Get("/", h.handleGet{{.ModuleNameTitle}})
 	// Add more routes here (POST, PUT, DELETE, etc.)
@@ -125,8 +133,8 @@ import (
 
 	"go.uber.org/zap"
 	// Import repository and entity if needed
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/repository"
+	// "{{.ImportPath}}/entity"
+	// "{{.ImportPath}}/repository"
 )
 
 // {{.ServiceName}} defines the interface for the {{.ModuleName}} service.
@@ -199,15 +207,22 @@ func generateFile(tmplContent, filePath string, data interface{}) {
 		os.Exit(1)
 	}
 
-	file, err := os.Create(filePath)
-	if err != nil {
-		fmt.Printf("Error creating file %s: %v\n", filePath, err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		fmt.Printf("Error executing template %s: %v\n", filepath.Base(filePath), err)
 		os.Exit(1)
 	}
-	defer file.Close()
 
-	if err := tmpl.Execute(file, data); err != nil {
-		fmt.Printf("Error executing template %s: %v\n", filepath.Base(filePath), err)
+	// Go sources are gofmt'ed; ones that do not parse are written as is for the user to fix
+	content := buf.Bytes()
+	if filepath.Ext(filePath) == ".go" {
+		if formatted, err := format.Source(content); err == nil {
+			content = formatted
+		}
+	}
+
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		fmt.Printf("Error creating file %s: %v\n", filePath, err)
 		os.Exit(1)
 	}
 	fmt.Printf("Generated file: %s\n", filePath)
@@ -217,6 +232,7 @@ func init() {
 	generateHandlerCmd.Flags().StringP("name", "n", "", "Name of the handler (required)")
 	generateHandlerCmd.Flags().StringP("module", "m", "", "Target module name (optional, defaults to handler name)")
 	generateHandlerCmd.MarkFlagRequired("name")
+	addTargetFlags(generateHandlerCmd)
 	// Add subcommands to the parent generateCmd
 	generateCmd.AddCommand(generateHandlerCmd)
 }
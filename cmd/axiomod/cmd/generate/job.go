@@ -0,0 +1,230 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/worker"
+
+	"github.com/spf13/cobra"
+)
+
+// generateJobCmd represents the generate job command
+var generateJobCmd = &cobra.Command{
+	Use:   "job --name=[name] --schedule=[cron]",
+	Short: "Generate a scheduled background job",
+	Long: `Generate a background job of a module, run by the worker on a cron schedule.
+
+The job goes to the delivery/job package of the module, with an fx module registering it
+with the worker, starting it with the application and stopping it on shutdown, and
+tests. Schedules are standard five-field cron expressions, or shorthands such as @hourly.
+Jobs are singletons: with a lock.Locker provided, one instance runs them at a time.
+
+Example:
+  axiomod generate job --name=cleanup --schedule="0 2 * * *" --module=order
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if !filterNamePattern.MatchString(name) {
+			fmt.Println("Error: name must be a snake_case name, such as cleanup or nightly_report")
+			os.Exit(1)
+		}
+		schedule, _ := cmd.Flags().GetString("schedule")
+		if _, err := worker.ParseSchedule(schedule); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		moduleName, err := backgroundModule(cmd, name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, _ := target.module(moduleName)
+		jobPath := filepath.Join(modulePath, "delivery", "job")
+		jobFile := filepath.Join(jobPath, name+"_job.go")
+		if _, err := os.Stat(jobFile); err == nil {
+			fmt.Printf("Error: %s already exists\n", jobFile)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(jobPath, 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", jobPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating job: %s\n", name)
+
+		goName, _ := fieldNames(name)
+		data := struct {
+			ID       string
+			Name     string
+			Title    string
+			Schedule string
+		}{
+			ID:       moduleName + "." + name,
+			Name:     goName,
+			Title:    strings.Title(strings.ReplaceAll(name, "_", " ")),
+			Schedule: schedule,
+		}
+		generateFile(jobTemplate, jobFile, data)
+		generateFile(jobTestTemplate, filepath.Join(jobPath, name+"_job_test.go"), data)
+
+		fmt.Printf("\nJob %s generated successfully in %s\n", name, jobPath)
+		fmt.Println("\nRemember to:")
+		fmt.Println("1. Implement Run.")
+		fmt.Printf("2. Add job.%sJobModule to your application, after the worker module.\n", goName)
+	},
+}
+
+// backgroundModule returns the module of a generated job or consumer, set with --module
+// or named after the component
+func backgroundModule(cmd *cobra.Command, name string) (string, error) {
+	moduleName, _ := cmd.Flags().GetString("module")
+	if moduleName == "" {
+		moduleName = name
+	}
+	if !crudNamePattern.MatchString(moduleName) {
+		return "", fmt.Errorf("%q is not a module name; set --module to a lowercase word, such as order", moduleName)
+	}
+	return moduleName, nil
+}
+
+const jobTemplate = `package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/worker"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// {{.Name}}Schedule is the cron schedule of {{.Name}}Job
+const {{.Name}}Schedule = "{{.Schedule}}"
+
+// {{.Name}}JobModule registers {{.Name}}Job with the worker, running it on its schedule while
+// the application runs
+var {{.Name}}JobModule = fx.Options(
+	fx.Provide(New{{.Name}}Job),
+	fx.Invoke(Register{{.Name}}Job),
+)
+
+// {{.Name}}Job is the {{.Title}} background job.
+type {{.Name}}Job struct {
+	logger *observability.Logger
+}
+
+// New{{.Name}}Job creates the {{.Title}} job
+func New{{.Name}}Job(logger *observability.Logger) *{{.Name}}Job {
+	return &{{.Name}}Job{logger: logger}
+}
+
+// Job returns the worker job running j on its schedule. It is a singleton, run by one
+// instance at a time when the worker has a locker.
+func (j *{{.Name}}Job) Job() *worker.Job {
+	return &worker.Job{
+		ID:        "{{.ID}}",
+		Name:      "{{.Title}}",
+		Schedule:  {{.Name}}Schedule,
+		Timeout:   time.Hour,
+		Singleton: true,
+		Func:      j.Run,
+	}
+}
+
+// Run runs the job once. It must return once ctx is done, when the job times out or the
+// application stops.
+func (j *{{.Name}}Job) Run(ctx context.Context) error {
+	j.logger.Info("Running job", zap.String("job", "{{.ID}}"))
+	return nil
+}
+
+// Register{{.Name}}Job registers the job with the worker, starts it with the application and
+// stops it on shutdown
+func Register{{.Name}}Job(lc fx.Lifecycle, w *worker.Worker, j *{{.Name}}Job) error {
+	job := j.Job()
+	if err := w.RegisterJob(job); err != nil {
+		return err
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return w.StartJob(job.ID)
+		},
+		OnStop: func(ctx context.Context) error {
+			return w.StopJob(job.ID)
+		},
+	})
+	return nil
+}
+`
+
+const jobTestTemplate = `package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/worker"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+func new{{.Name}}Job(t *testing.T) (*{{.Name}}Job, *observability.Logger) {
+	logger, err := observability.NewLogger(&config.Config{})
+	require.NoError(t, err)
+	return New{{.Name}}Job(logger), logger
+}
+
+func Test{{.Name}}Job_Run(t *testing.T) {
+	j, _ := new{{.Name}}Job(t)
+	assert.NoError(t, j.Run(context.Background()))
+}
+
+func Test{{.Name}}Job_Schedule(t *testing.T) {
+	j, _ := new{{.Name}}Job(t)
+	job := j.Job()
+	assert.Equal(t, "{{.ID}}", job.ID)
+	assert.True(t, job.Singleton)
+
+	schedule, err := worker.ParseSchedule(job.Schedule)
+	require.NoError(t, err)
+	next := schedule.Next(time.Now())
+	assert.False(t, next.IsZero(), "the schedule matches a time")
+}
+
+func TestRegister{{.Name}}Job(t *testing.T) {
+	j, logger := new{{.Name}}Job(t)
+	w := worker.New(logger)
+	lc := fxtest.NewLifecycle(t)
+
+	require.NoError(t, Register{{.Name}}Job(lc, w, j))
+	lc.RequireStart()
+	assert.NoError(t, w.StartJob("{{.ID}}"), "the job is registered and running")
+	lc.RequireStop()
+	assert.ErrorIs(t, w.StopJob("{{.ID}}"), worker.ErrJobNotFound, "the job is stopped with the application")
+}
+`
+
+func init() {
+	generateJobCmd.Flags().StringP("name", "n", "", "snake_case name of the job, e.g. cleanup (required)")
+	generateJobCmd.Flags().String("schedule", "", `Cron schedule of the job, e.g. "0 2 * * *" or @hourly (required)`)
+	generateJobCmd.Flags().StringP("module", "m", "", "Target module name (optional, defaults to the job name)")
+	generateJobCmd.MarkFlagRequired("name")
+	generateJobCmd.MarkFlagRequired("schedule")
+	addTargetFlags(generateJobCmd)
+	generateCmd.AddCommand(generateJobCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -11,25 +12,52 @@ import (
 
 // generateModuleCmd represents the generate module command
 var generateModuleCmd = &cobra.Command{
-	Use:   "module --name=[name]",
+	Use:   "module --name=[name] | --from=[schema.yaml]",
 	Short: "Generate a new module with basic structure",
 	Long: `Generate a new module with a basic directory structure and placeholder files.
 
+Fields passed with --filter are added to the entity and to the query.Schema List
+filters and sorts on. The in-memory repository keeps an index for each of them.
+
+With --from, the module is generated from a YAML schema file declaring its entities,
+with their fields, relations and indexes. Each entity gets its entity, repository
+interface, in-memory repository and Ent schema, and a migration creates their tables.
+
 Example:
   axiomod generate module --name=user
+  axiomod generate module --name=order --filter=status,customer_id
+  axiomod generate module --from=schema.yaml
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		name, _ := cmd.Flags().GetString("name")
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if from, _ := cmd.Flags().GetString("from"); from != "" {
+			if err := generateModuleFromSchema(from, name, target); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 		if name == "" {
 			fmt.Println("Error: name flag is required")
 			os.Exit(1)
 		}
 
+		filterNames, _ := cmd.Flags().GetStringSlice("filter")
+		filters, err := parseFilters(filterNames)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		fmt.Printf("Generating module: %s\n", name)
 
 		// Define paths
-		moduleBasePath := "examples" // Fixed path to examples at root
-		modulePath := filepath.Join(moduleBasePath, name)
+		modulePath, importPath := target.module(name)
 		entityPath := filepath.Join(modulePath, "entity")
 		repositoryPath := filepath.Join(modulePath, "repository")
 		usecasePath := filepath.Join(modulePath, "usecase")
@@ -39,6 +67,7 @@ Example:
 		infraPersistencePath := filepath.Join(modulePath, "infrastructure", "persistence")
 		infraCachePath := filepath.Join(modulePath, "infrastructure", "cache")
 		infraMessagingPath := filepath.Join(modulePath, "infrastructure", "messaging")
+		protoPath := target.protoDir(name)
 
 		// Create directories
 		dirs := []string{
@@ -52,6 +81,7 @@ Example:
 			infraPersistencePath,
 			infraCachePath,
 			infraMessagingPath,
+			protoPath,
 		}
 		for _, dir := range dirs {
 			if err := os.MkdirAll(dir, 0755); err != nil {
@@ -63,6 +93,8 @@ Example:
 		// Define template data
 		data := struct {
 			ModuleName      string
+			ImportPath      string
+			ModulePath      string
 			ModuleNameTitle string
 			EntityName      string
 			EntityNameLower string
@@ -70,8 +102,11 @@ Example:
 			ServiceName     string
 			HandlerName     string
 			GRPCServiceName string
+			Filters         []filterField
 		}{
 			ModuleName:      name,
+			ImportPath:      importPath,
+			ModulePath:      target.ModulePath,
 			ModuleNameTitle: strings.Title(name),
 			EntityName:      strings.Title(name),
 			EntityNameLower: name,
@@ -79,6 +114,7 @@ Example:
 			ServiceName:     strings.Title(name) + "Service",
 			HandlerName:     strings.Title(name) + "Handler",
 			GRPCServiceName: strings.Title(name) + "GRPCService",
+			Filters:         filters,
 		}
 
 		// Generate placeholder files
@@ -88,16 +124,84 @@ Example:
 		generateFile(serviceTemplate, filepath.Join(servicePath, name+"_domain_service.go"), data)
 		generateFile(handlerTemplate, filepath.Join(deliveryHTTPPath, name+"_handler.go"), data)
 		generateFile(grpcServiceTemplate, filepath.Join(deliveryGRPCPath, name+"_grpc_service.go"), data)
+		generateFile(protoTemplate, filepath.Join(protoPath, name+".proto"), data)
+		generateFile(mapperTemplate, filepath.Join(deliveryGRPCPath, name+"_mapper.go"), data)
 		generateFile(persistenceTemplate, filepath.Join(infraPersistencePath, name+"_memory_repository.go"), data)
 		generateFile(moduleFileTemplate, filepath.Join(modulePath, "module.go"), data)
+		if err := setupBuf(target.Root); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 
-		fmt.Printf("\nModule %s generated successfully in %s\n", name, moduleBasePath)
+		fmt.Printf("\nModule %s generated successfully in %s\n", name, modulePath)
 		fmt.Println("\nRemember to:")
 		fmt.Println("1. Implement the actual logic in the generated files.")
 		fmt.Println("2. Add the module to your main application setup (e.g., FX options).")
+		fmt.Printf("3. Run make proto to generate the Go code of %s.proto for the gRPC service.\n", name)
 	},
 }
 
+// filterField is an entity field List can filter and sort on
+type filterField struct {
+	Name      string // Go field name, e.g. CustomerID
+	ProtoName string // Go field name generated from the proto field, e.g. CustomerId
+	Key       string // JSON, proto field and sort key name, e.g. customer_id
+	New       bool   // false for fields the entity template already has
+	Number    int    // proto field number of new fields
+}
+
+// filterNamePattern matches the snake_case names accepted by --filter
+var filterNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// parseFilters turns the --filter names into entity fields, e.g. customer_id into CustomerID
+func parseFilters(names []string) ([]filterField, error) {
+	var filters []filterField
+	seen := make(map[string]bool)
+	number := 5 // after id, name, created_at and updated_at
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" || seen[key] {
+			continue
+		}
+		if !filterNamePattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid filter field %q, use snake_case names such as customer_id", name)
+		}
+		switch key {
+		case "id", "created_at", "updated_at":
+			return nil, fmt.Errorf("filter field %q is not a string field; List already sorts by id and created_at", name)
+		}
+		seen[key] = true
+
+		goName, protoName := fieldNames(key)
+		filter := filterField{Name: goName, ProtoName: protoName, Key: key, New: key != "name"}
+		if filter.New {
+			filter.Number = number
+			number++
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// fieldNames returns the Go field name of a snake_case name, e.g. CustomerID for customer_id,
+// and the name of the Go field generated from a proto field of that name, e.g. CustomerId
+func fieldNames(key string) (goName, protoName string) {
+	var goBuilder, protoBuilder strings.Builder
+	for _, part := range strings.Split(key, "_") {
+		if part == "" {
+			continue
+		}
+		protoBuilder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		switch part {
+		case "id", "url", "api", "ip":
+			goBuilder.WriteString(strings.ToUpper(part))
+		default:
+			goBuilder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return goBuilder.String(), protoBuilder.String()
+}
+
 // Templates (simplified placeholders)
 const entityTemplate = `package entity
 
@@ -107,6 +211,9 @@ import "time"
 type {{.EntityName}} struct {
 	ID        string    ` + "`json:\"id\"`" + `
 	Name      string    ` + "`json:\"name\"`" + `
+{{- range .Filters}}{{if .New}}
+	{{.Name}} string ` + "`json:\"{{.Key}}\"`" + `
+{{- end}}{{end}}
 	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
 	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
 }
@@ -116,15 +223,38 @@ const repositoryTemplate = `package repository
 
 import (
 	"context"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
+	"errors"
+
+	"{{.ImportPath}}/entity"
+	"github.com/axiomod/axiomod/framework/query"
 )
 
 // {{.RepositoryName}} defines the interface for data access operations for {{.EntityName}}.
 type {{.RepositoryName}} interface {
 	Create(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error
 	GetByID(ctx context.Context, id string) (*entity.{{.EntityName}}, error)
-	// Add other methods like Update, Delete, List, etc.
+	Update(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error
+	Delete(ctx context.Context, id string) error
+	// List returns the page of params of the {{.EntityName}} entities matching its filters,
+	// checked against {{.EntityName}}Schema, with their total count
+	List(ctx context.Context, params query.Params) (query.ListResult[*entity.{{.EntityName}}], error)
 }
+
+// {{.EntityName}}Schema lists the fields {{.EntityName}} listings filter and sort on, by their
+// JSON names. Listings are ordered by ID by default and to break ties.
+var {{.EntityName}}Schema = query.NewSchema("id", map[string]query.Field{
+	"name":       {Filter: query.Text, Sortable: true},
+	"created_at": {Filter: query.Comparable, Sortable: true},
+{{- range .Filters}}{{if .New}}
+	"{{.Key}}": {Filter: query.Text, Sortable: true},
+{{- end}}{{end}}
+})
+
+// Repository errors
+var (
+	Err{{.EntityName}}NotFound = errors.New("{{.EntityNameLower}} not found")
+	Err{{.EntityName}}Exists   = errors.New("{{.EntityNameLower}} already exists")
+)
 `
 
 const usecaseTemplate = `package usecase
@@ -136,11 +266,16 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/repository"
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
 )
 
-// Create{{.EntityName}}UseCase handles the creation of a new {{.EntityName}}.
+// Create{{.EntityName}}Command asks for a new {{.EntityName}}.
+type Create{{.EntityName}}Command struct {
+	Name string ` + "`" + `json:"name" validate:"required"` + "`" + `
+}
+
+// Create{{.EntityName}}UseCase handles Create{{.EntityName}}Command on the command bus.
 type Create{{.EntityName}}UseCase struct {
 	logger *zap.Logger
 	 repo   repository.{{.RepositoryName}}
@@ -154,14 +289,14 @@ func NewCreate{{.EntityName}}UseCase(logger *zap.Logger, repo repository.{{.Repo
 	}
 }
 
-// Execute creates a new {{.EntityName}}.
-func (uc *Create{{.EntityName}}UseCase) Execute(ctx context.Context, name string) (*entity.{{.EntityName}}, error) {
-	 uc.logger.Info("Creating new {{.EntityNameLower}}", zap.String("name", name))
+// Handle creates a new {{.EntityName}}. The command bus has validated the command already.
+func (uc *Create{{.EntityName}}UseCase) Handle(ctx context.Context, cmd Create{{.EntityName}}Command) (*entity.{{.EntityName}}, error) {
+	 uc.logger.Info("Creating new {{.EntityNameLower}}", zap.String("name", cmd.Name))
 
 	 now := time.Now()
 	 new{{.EntityName}} := &entity.{{.EntityName}}{
 		ID:        uuid.NewString(),
-		Name:      name,
+		Name:      cmd.Name,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -183,8 +318,8 @@ import (
 
 	"go.uber.org/zap"
 	// Import repository and entity if needed
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/repository"
+	// "{{.ImportPath}}/entity"
+	// "{{.ImportPath}}/repository"
 )
 
 // {{.ServiceName}} defines the interface for the {{.ModuleName}} service.
@@ -224,7 +359,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/service"
+	// "{{.ImportPath}}/service"
 )
 
 // {{.HandlerName}} handles HTTP requests for the {{.ModuleName}} module.
@@ -242,10 +377,10 @@ func New{{.HandlerName}}(logger *zap.Logger /*, service service.{{.ServiceName}}
 	}
 }
 
-// RegisterRoutes registers the handler routes with the Fiber app.
-func (h *{{.HandlerName}}) RegisterRoutes(app *fiber.App) {
+// RegisterRoutes registers the handler routes under a router, such as the Fiber app.
+func (h *{{.HandlerName}}) RegisterRoutes(router fiber.Router) {
 	// Define routes for the {{.ModuleName}} module
-	group := app.Group("/{{.ModuleName}}")
+	group := router.Group("/{{.ModuleName}}")
 
 	group.Get("/", h.handleGet{{.ModuleNameTitle}})
 	// Add more routes here (POST, PUT, DELETE, etc.)
@@ -263,12 +398,12 @@ func (h *{{.HandlerName}}) handleGet{{.ModuleNameTitle}}(c *fiber.Ctx) error {
 const grpcServiceTemplate = `package grpc
 
 import (
-	"context"
-
 	"go.uber.org/zap"
-	// Import generated protobuf code
-	// pb "github.com/axiomod/axiomod/gen/proto/{{.ModuleName}}/v1"
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/service"
+	// Import with the generated protobuf code
+	// "context"
+	//
+	// pb "{{.ModulePath}}/gen/proto/{{.ModuleName}}/v1"
+	// "{{.ImportPath}}/service"
 )
 
 // {{.GRPCServiceName}} implements the gRPC service for the {{.ModuleName}} module.
@@ -293,6 +428,160 @@ func New{{.GRPCServiceName}}(logger *zap.Logger /*, service service.{{.ServiceNa
 // 	 // Call service logic
 // 	 return &pb.Get{{.EntityName}}Response{ /* ... */ }, nil
 // }
+
+// Example List RPC method, paginated with framework/pagination and framework/query
+// func (s *{{.GRPCServiceName}}) List{{.EntityName}}s(ctx context.Context, req *pb.List{{.EntityName}}sRequest) (*pb.List{{.EntityName}}sResponse, error) {
+// 	 params := query.Params{ /* filters and sort of req */ }
+// 	 fingerprint := params.Fingerprint()
+// 	 cursor, size, err := s.paginator.Start(pagination.FromProto(req), fingerprint)
+// 	 if err != nil {
+// 		 return nil, grpc_pkg.ToStatus(err).Err()
+// 	 }
+// 	 params.Page = query.PageFrom(cursor, size)
+// 	 result, err := s.repo.List(ctx, params)
+// 	 if err == nil {
+// 		 err = result.Sign(s.paginator, fingerprint)
+// 	 }
+// 	 if err != nil {
+// 		 return nil, grpc_pkg.ToStatus(err).Err()
+// 	 }
+// 	 return &pb.List{{.EntityName}}sResponse{
+// 		 {{.EntityName}}s:      to{{.EntityName}}sProto(result.Items),
+// 		 NextPageToken: result.NextPageToken,
+// 		 Total:         int64(result.Total),
+// 	 }, nil
+// }
+
+// Example server-streaming RPC method. The stream interceptors of the server log,
+// authenticate, meter and trace it like unary calls; it runs until the client cancels, so
+// it must return once the stream context is done. Set grpc.streamTimeout to bound it.
+// func (s *{{.GRPCServiceName}}) Watch{{.EntityName}}s(req *pb.Watch{{.EntityName}}sRequest, stream pb.{{.ModuleNameTitle}}Service_Watch{{.EntityName}}sServer) error {
+// 	 ctx := stream.Context()
+// 	 changes, err := s.service.Watch(ctx, req.GetIds()) // e.g. fed by the domain events of {{.ModuleName}}
+// 	 if err != nil {
+// 		 return grpc_pkg.ToStatus(err).Err()
+// 	 }
+// 	 for {
+// 		 select {
+// 		 case <-ctx.Done():
+// 			 return nil
+// 		 case change := <-changes:
+// 			 if err := stream.Send(&pb.Watch{{.EntityName}}sResponse{
+// 				 {{.EntityName}}: to{{.EntityName}}Proto(change.{{.EntityName}}),
+// 				 Deleted: change.Deleted,
+// 			 }); err != nil {
+// 				 return err
+// 			 }
+// 		 }
+// 	 }
+// }
+`
+
+const protoTemplate = `syntax = "proto3";
+
+package {{.ModuleName}}.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "{{.ModulePath}}/gen/proto/{{.ModuleName}}/v1;{{.ModuleName}}v1";
+
+// {{.ModuleNameTitle}}Service is the gRPC API of the {{.ModuleName}} module.
+service {{.ModuleNameTitle}}Service {
+  rpc Get{{.EntityName}}(Get{{.EntityName}}Request) returns (Get{{.EntityName}}Response);
+  rpc List{{.EntityName}}s(List{{.EntityName}}sRequest) returns (List{{.EntityName}}sResponse);
+  // Watch{{.EntityName}}s streams the changes of {{.EntityNameLower}}s until the client cancels
+  rpc Watch{{.EntityName}}s(Watch{{.EntityName}}sRequest) returns (stream Watch{{.EntityName}}sResponse);
+}
+
+message {{.EntityName}} {
+  string id = 1;
+  string name = 2;
+  google.protobuf.Timestamp created_at = 3;
+  google.protobuf.Timestamp updated_at = 4;
+{{- range .Filters}}{{if .New}}
+  string {{.Key}} = {{.Number}};
+{{- end}}{{end}}
+}
+
+message Get{{.EntityName}}Request {
+  string id = 1;
+}
+
+message Get{{.EntityName}}Response {
+  {{.EntityName}} {{.EntityNameLower}} = 1;
+}
+
+// List requests and responses use the standard pagination fields, read with
+// pagination.FromProto. Add filters after them.
+message List{{.EntityName}}sRequest {
+  // Maximum number of items to return; the server default when 0
+  int32 page_size = 1;
+  // next_page_token of the previous response; empty for the first page
+  string page_token = 2;
+}
+
+message List{{.EntityName}}sResponse {
+  repeated {{.EntityName}} {{.EntityNameLower}}s = 1;
+  // Token of the next page; empty on the last page
+  string next_page_token = 2;
+  // Number of {{.EntityNameLower}}s matching the filters, on every page
+  int64 total = 3;
+}
+
+message Watch{{.EntityName}}sRequest {
+  // IDs of the {{.EntityNameLower}}s to watch; all when empty
+  repeated string ids = 1;
+}
+
+message Watch{{.EntityName}}sResponse {
+  {{.EntityName}} {{.EntityNameLower}} = 1;
+  // True when the {{.EntityNameLower}} was deleted
+  bool deleted = 2;
+}
+`
+
+const mapperTemplate = `package grpc
+
+// Mappers between the {{.EntityName}} entity and the {{.EntityName}} message of {{.ModuleName}}.proto,
+// using framework/mapping for the well-known types. Uncomment them once the protobuf
+// code is generated.
+
+import (
+// "{{.ImportPath}}/entity"
+// "github.com/axiomod/axiomod/framework/mapping"
+// pb "{{.ModulePath}}/gen/proto/{{.ModuleName}}/v1"
+)
+
+// to{{.EntityName}}Proto converts a {{.EntityName}} entity to its protobuf message.
+// func to{{.EntityName}}Proto({{.EntityNameLower}} *entity.{{.EntityName}}) *pb.{{.EntityName}} {
+// 	return &pb.{{.EntityName}}{
+// 		Id: {{.EntityNameLower}}.ID,
+// 		Name: {{.EntityNameLower}}.Name,
+// 		CreatedAt: mapping.Timestamp({{.EntityNameLower}}.CreatedAt),
+// 		UpdatedAt: mapping.Timestamp({{.EntityNameLower}}.UpdatedAt),
+{{- range .Filters}}{{if .New}}
+// 		{{.ProtoName}}: {{$.EntityNameLower}}.{{.Name}},
+{{- end}}{{end}}
+// 	}
+// }
+
+// from{{.EntityName}}Proto converts a protobuf message to a {{.EntityName}} entity.
+// func from{{.EntityName}}Proto(msg *pb.{{.EntityName}}) *entity.{{.EntityName}} {
+// 	return &entity.{{.EntityName}}{
+// 		ID: msg.GetId(),
+// 		Name: msg.GetName(),
+// 		CreatedAt: mapping.Time(msg.GetCreatedAt()),
+// 		UpdatedAt: mapping.Time(msg.GetUpdatedAt()),
+{{- range .Filters}}{{if .New}}
+// 		{{.Name}}: msg.Get{{.ProtoName}}(),
+{{- end}}{{end}}
+// 	}
+// }
+
+// to{{.EntityName}}sProto converts {{.EntityName}} entities to protobuf messages.
+// func to{{.EntityName}}sProto({{.EntityNameLower}}s []*entity.{{.EntityName}}) []*pb.{{.EntityName}} {
+// 	return mapping.Slice({{.EntityNameLower}}s, to{{.EntityName}}Proto)
+// }
 `
 
 const persistenceTemplate = `package persistence
@@ -302,62 +591,195 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/repository"
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/repository"
+	"github.com/axiomod/axiomod/framework/query"
 )
 
 // InMemory{{.RepositoryName}} is an in-memory implementation of {{.RepositoryName}}.
+// It is safe for concurrent use: entities are copied in and out, so callers never share
+// them with the store. Each --filter field has an index, so List only visits the entities
+// an equality filter on it selects.
 type InMemory{{.RepositoryName}} struct {
-	mu    sync.RWMutex
-	store map[string]*entity.{{.EntityName}}
+	mu      sync.RWMutex
+	store   map[string]*entity.{{.EntityName}}
+	indexes map[string]map[string]map[string]struct{} // field, value, IDs
 }
 
 // NewInMemory{{.RepositoryName}} creates a new InMemory{{.RepositoryName}}.
 func NewInMemory{{.RepositoryName}}() repository.{{.RepositoryName}} {
 	return &InMemory{{.RepositoryName}}{
-		store: make(map[string]*entity.{{.EntityName}}),
+		store:   make(map[string]*entity.{{.EntityName}}),
+		indexes: make(map[string]map[string]map[string]struct{}),
 	}
 }
 
 // Create saves a new {{.EntityName}} in memory.
 func (r *InMemory{{.RepositoryName}}) Create(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error {
-	 r.mu.Lock()
-	 defer r.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	 if _, exists := r.store[{{.EntityNameLower}}.ID]; exists {
-		 return fmt.Errorf("{{.EntityNameLower}} with ID %s already exists", {{.EntityNameLower}}.ID)
+	if _, exists := r.store[{{.EntityNameLower}}.ID]; exists {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}Exists, {{.EntityNameLower}}.ID)
 	}
-	 r.store[{{.EntityNameLower}}.ID] = {{.EntityNameLower}}
-	 return nil
+	stored := *{{.EntityNameLower}}
+	r.store[stored.ID] = &stored
+	r.index(&stored)
+	return nil
 }
 
 // GetByID retrieves a {{.EntityName}} by ID from memory.
 func (r *InMemory{{.RepositoryName}}) GetByID(ctx context.Context, id string) (*entity.{{.EntityName}}, error) {
-	 r.mu.RLock()
-	 defer r.mu.RUnlock()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, exists := r.store[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, id)
+	}
+	{{.EntityNameLower}} := *stored
+	return &{{.EntityNameLower}}, nil
+}
+
+// Update replaces a stored {{.EntityName}}.
+func (r *InMemory{{.RepositoryName}}) Update(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.store[{{.EntityNameLower}}.ID]
+	if !exists {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, {{.EntityNameLower}}.ID)
+	}
+	r.unindex(previous)
+	stored := *{{.EntityNameLower}}
+	r.store[stored.ID] = &stored
+	r.index(&stored)
+	return nil
+}
+
+// Delete removes a {{.EntityName}} by ID.
+func (r *InMemory{{.RepositoryName}}) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	 {{.EntityNameLower}}, exists := r.store[id]
-	 if !exists {
-		 return nil, fmt.Errorf("{{.EntityNameLower}} with ID %s not found", id)
+	stored, exists := r.store[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", repository.Err{{.EntityName}}NotFound, id)
 	}
-	 return {{.EntityNameLower}}, nil
+	r.unindex(stored)
+	delete(r.store, id)
+	return nil
 }
 
-// Add other methods like Update, Delete, List, etc.
+// List returns the page of params of the {{.EntityName}} entities matching its filters.
+func (r *InMemory{{.RepositoryName}}) List(ctx context.Context, params query.Params) (query.ListResult[*entity.{{.EntityName}}], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Visit the IDs of the smallest index an equality filter selects, or every entity without one
+	var ids map[string]struct{}
+	indexed := false
+	for field, values := range r.indexes {
+		if value, ok := params.Filter.Equal(field); ok {
+			matches := values[fmt.Sprint(value)]
+			if !indexed || len(matches) < len(ids) {
+				ids, indexed = matches, true
+			}
+		}
+	}
+
+	var candidates []*entity.{{.EntityName}}
+	if indexed {
+		for id := range ids {
+			candidates = append(candidates, r.store[id])
+		}
+	} else {
+		for _, stored := range r.store {
+			candidates = append(candidates, stored)
+		}
+	}
+
+	result, err := query.Apply(candidates, repository.{{.EntityName}}Schema, params, fieldValue)
+	if err != nil {
+		return result, err
+	}
+	for i, stored := range result.Items {
+		{{.EntityNameLower}} := *stored
+		result.Items[i] = &{{.EntityNameLower}}
+	}
+	return result, nil
+}
+
+// index adds a stored {{.EntityName}} to the indexes of its filterable fields.
+func (r *InMemory{{.RepositoryName}}) index({{.EntityNameLower}} *entity.{{.EntityName}}) {
+	for field, value := range indexedValues({{.EntityNameLower}}) {
+		values, ok := r.indexes[field]
+		if !ok {
+			values = make(map[string]map[string]struct{})
+			r.indexes[field] = values
+		}
+		ids, ok := values[value]
+		if !ok {
+			ids = make(map[string]struct{})
+			values[value] = ids
+		}
+		ids[{{.EntityNameLower}}.ID] = struct{}{}
+	}
+}
+
+// unindex removes a stored {{.EntityName}} from the indexes of its filterable fields.
+func (r *InMemory{{.RepositoryName}}) unindex({{.EntityNameLower}} *entity.{{.EntityName}}) {
+	for field, value := range indexedValues({{.EntityNameLower}}) {
+		ids := r.indexes[field][value]
+		delete(ids, {{.EntityNameLower}}.ID)
+		if len(ids) == 0 {
+			delete(r.indexes[field], value)
+		}
+	}
+}
+
+// indexedValues returns the values of the --filter fields of a {{.EntityName}}.
+func indexedValues({{.EntityNameLower}} *entity.{{.EntityName}}) map[string]string {
+	return map[string]string{
+{{- range .Filters}}
+		"{{.Key}}": {{$.EntityNameLower}}.{{.Name}},
+{{- end}}
+	}
+}
+
+// fieldValue returns the value of a field of repository.{{.EntityName}}Schema.
+func fieldValue({{.EntityNameLower}} *entity.{{.EntityName}}, field string) any {
+	switch field {
+	case "id":
+		return {{.EntityNameLower}}.ID
+	case "name":
+		return {{.EntityNameLower}}.Name
+	case "created_at":
+		return {{.EntityNameLower}}.CreatedAt
+{{- range .Filters}}{{if .New}}
+	case "{{.Key}}":
+		return {{$.EntityNameLower}}.{{.Name}}
+{{- end}}{{end}}
+	}
+	return nil
+}
 `
 
 const moduleFileTemplate = `package {{.ModuleName}}
 
 import (
 	"go.uber.org/fx"
-	"go.uber.org/zap"
 
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/delivery/http"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/delivery/grpc"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/infrastructure/persistence"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/repository"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/service"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/usecase"
+	"github.com/axiomod/axiomod/framework/cqrs"
+	"github.com/axiomod/axiomod/platform/server"
+
+	"{{.ImportPath}}/delivery/http"
+	"{{.ImportPath}}/delivery/grpc"
+	"{{.ImportPath}}/entity"
+	"{{.ImportPath}}/infrastructure/persistence"
+	// "{{.ImportPath}}/repository"
+	"{{.ImportPath}}/service"
+	"{{.ImportPath}}/usecase"
 )
 
 // Module provides the FX module for the {{.ModuleName}} example.
@@ -372,10 +794,6 @@ var Module = fx.Module(
 		 // 	 fx.As(new(repository.{{.RepositoryName}})),
 		 // ),
 
-		// Usecases
-		 usecase.NewCreate{{.EntityName}}UseCase,
-		 // Add other use cases here
-
 		// Domain Services
 		 service.New{{.ServiceName}},
 		 // fx.Annotate(
@@ -387,24 +805,23 @@ var Module = fx.Module(
 		 http.New{{.HandlerName}},
 		 grpc.New{{.GRPCServiceName}},
 	),
-	fx.Invoke(registerHooks),
-)
 
-// registerHooks registers hooks for the module, such as HTTP routes.
-func registerHooks(lc fx.Lifecycle, logger *zap.Logger, handler *http.{{.HandlerName}} /*, grpcServer *grpc.{{.GRPCServiceName}}*/) {
-	 logger.Info("Registering {{.ModuleName}} module hooks")
-	 // Register HTTP routes (assuming a Fiber app is provided elsewhere)
-	 // This requires the Fiber app instance to be available in the FX container.
-	 // Example: app.Get("/{{.ModuleName}}", handler.HandleGet)
+	// Usecases, dispatched with cqrs.Dispatch on the command or query bus
+	cqrs.AsCommandHandler[usecase.Create{{.EntityName}}Command, *entity.{{.EntityName}}](usecase.NewCreate{{.EntityName}}UseCase),
+	// Add other use cases here, e.g. with cqrs.AsQueryHandler
 
-	 // Register gRPC service (assuming a gRPC server is provided elsewhere)
-	 // Example: pb.Register{{.ModuleNameTitle}}ServiceServer(grpcServerInstance, grpcServer)
-}
+	// Delivery: the HTTP routes are served by the HTTP server. Register the gRPC service with
+	// server.AsGRPCService[*grpc.{{.GRPCServiceName}}](&pb.{{.ModuleNameTitle}}Service_ServiceDesc)
+	// once its protobuf code is generated.
+	server.AsHTTPRoutes[*http.{{.HandlerName}}](""),
+)
 `
 
 func init() {
-	generateModuleCmd.Flags().StringP("name", "n", "", "Name of the module (required)")
-	generateModuleCmd.MarkFlagRequired("name")
+	generateModuleCmd.Flags().StringP("name", "n", "", "Name of the module (required unless --from declares it)")
+	generateModuleCmd.Flags().StringSlice("filter", nil, "Entity fields List can filter and sort on, e.g. status,customer_id")
+	generateModuleCmd.Flags().String("from", "", "YAML schema file declaring the entities of the module")
+	addTargetFlags(generateModuleCmd)
 	// Add subcommands to the parent generateCmd
 	generateCmd.AddCommand(generateModuleCmd)
 }
@@ -0,0 +1,999 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// generateOpenAPICmd represents the generate openapi command
+var generateOpenAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Generate the OpenAPI document of the HTTP API from its handlers",
+	Long: `Generate an OpenAPI 3.1 document of the HTTP API of the project by reading its code,
+without running it.
+
+The routes are the Fiber registrations of the module, e.g. group.Get("/:id", h.Get), with the
+prefixes of the groups they are registered on, followed across functions such as
+handler.RegisterRoutes(srv.App.Group("/api/v1")), or registered with
+server.AsHTTPRoutes[*http.ProductHandler]("/api/v1"). The handlers are read for:
+
+  - the request body, query and path parameters: middleware.Bind[T], c.BodyParser,
+    c.QueryParser, c.ParamsParser, c.Query and c.Params; the struct fields tagged params
+    or query are parameters, the others the JSON body
+  - the responses: c.JSON, c.Status(...).JSON and c.SendStatus, and fiber.NewError; every
+    operation may also fail with a problem document
+  - the schemas: the JSON encoding of the Go types, with the constraints of their validate
+    tags and the doc comments of their fields
+
+Annotations in the doc comment of a handler complete or override what the code shows:
+
+  @Summary   Create a product
+  @Description Longer description of the operation
+  @Tags      products, catalog
+  @ID        createProduct
+  @Param     X-Request-ID header string required Request identifier
+  @Request   usecase.CreateProductCommand
+  @Response  201 entity.Product The created product
+  @Response  204 No content
+  @Security  bearerAuth
+  @Deprecated
+  @Ignore    leaves the route out of the document
+
+Enable http.docs in the configuration to serve the document with Swagger UI at /docs.
+
+Example:
+  axiomod generate openapi
+  axiomod generate openapi --output=docs/api/openapi.json --title="Shop API" --version=1.2.0
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root, modulePath, err := findModule()
+		if err != nil {
+			fmt.Printf("Error: %v; run the generator inside the Go module of the service\n", err)
+			os.Exit(1)
+		}
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, root); err == nil {
+				root = rel
+			}
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if !filepath.IsAbs(output) {
+			output = filepath.Join(root, output)
+		}
+
+		scanner := newAPIScanner(root, modulePath)
+		if err := scanner.Load(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		doc := scanner.document(openAPIInfoFromFlags(cmd, root, modulePath))
+		if servers, _ := cmd.Flags().GetStringSlice("server"); len(servers) > 0 {
+			for _, url := range servers {
+				doc.Servers = append(doc.Servers, openAPIServer{URL: url})
+			}
+		}
+
+		var content []byte
+		if strings.EqualFold(filepath.Ext(output), ".json") {
+			content, err = json.MarshalIndent(doc, "", "  ")
+			content = append(content, '\n')
+		} else {
+			var buf bytes.Buffer
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			err = encoder.Encode(doc)
+			content = buf.Bytes()
+		}
+		if err != nil {
+			fmt.Printf("Error encoding the OpenAPI document: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", filepath.Dir(output), err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(output, content, 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", output, err)
+			os.Exit(1)
+		}
+
+		for _, warning := range scanner.Warnings {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+		operations := 0
+		for _, item := range doc.Paths {
+			operations += len(item)
+		}
+		fmt.Printf("Generated file: %s\n", output)
+		fmt.Printf("\nOpenAPI document generated successfully: %d operations on %d paths\n", operations, len(doc.Paths))
+		if operations == 0 {
+			fmt.Println("No routes were found; register them on a fiber.Router, *fiber.App or the App of server.HTTPServer.")
+		}
+	},
+}
+
+// openAPIDocument is an OpenAPI 3.1 document
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi" yaml:"openapi"`
+	Info       openAPIInfo                             `json:"info" yaml:"info"`
+	Servers    []openAPIServer                         `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths" yaml:"paths"`
+	Components openAPIComponents                       `json:"components" yaml:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url" yaml:"url"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Responses       map[string]*openAPIResponse       `json:"responses,omitempty" yaml:"responses,omitempty"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string                      `json:"operationId" yaml:"operationId"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses" yaml:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty" yaml:"security,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name" yaml:"name"`
+	In          string         `json:"in" yaml:"in"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema" yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required" yaml:"required"`
+	Content  map[string]openAPIMediaType `json:"content" yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema" yaml:"schema"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type" yaml:"type"`
+	Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+}
+
+// problemResponse is the response of the operations failing with a problem document
+const problemResponse = "#/components/responses/Problem"
+
+// openAPIInfoFromFlags returns the title and version of the document from the --title and
+// --version flags, defaulting to the name and version of the app configuration
+func openAPIInfoFromFlags(cmd *cobra.Command, root, modulePath string) openAPIInfo {
+	info := openAPIInfo{Title: path.Base(modulePath), Version: "1.0.0"}
+	for _, file := range deployConfigFiles {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		var node yaml.Node
+		if yaml.Unmarshal(content, &node) != nil || len(node.Content) == 0 {
+			continue
+		}
+		if name := yamlValue(node.Content[0], "app", "name"); name != nil && name.Value != "" {
+			info.Title = name.Value
+		}
+		if version := yamlValue(node.Content[0], "app", "version"); version != nil && version.Value != "" {
+			info.Version = version.Value
+		}
+		break
+	}
+	if title, _ := cmd.Flags().GetString("title"); title != "" {
+		info.Title = title
+	}
+	if version, _ := cmd.Flags().GetString("version"); version != "" {
+		info.Version = version
+	}
+	return info
+}
+
+// apiScanner documents the routes of the route table of a module, with the schemas of the Go
+// types their handlers read and write
+type apiScanner struct {
+	*apiscan.Scanner
+	schemas    map[string]*openAPISchema // components, by name
+	components map[string]string         // component names of Go types, by "<import path>.<name>"
+}
+
+func newAPIScanner(root, modulePath string) *apiScanner {
+	return &apiScanner{
+		Scanner:    apiscan.New(root, modulePath),
+		schemas:    make(map[string]*openAPISchema),
+		components: make(map[string]string),
+	}
+}
+
+// document builds the OpenAPI document of the routes of the module
+func (s *apiScanner) document(info openAPIInfo) *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+	operationIDs := make(map[string]bool)
+	var security bool
+	for _, route := range s.Routes() {
+		operation, ok := s.operation(route)
+		if !ok {
+			continue
+		}
+		openAPIPath, _ := openAPIPathParams(route.Path)
+		if _, exists := doc.Paths[openAPIPath][route.Method]; exists {
+			s.Warnf("%s %s is registered more than once; the first handler is documented", strings.ToUpper(route.Method), route.Path)
+			continue
+		}
+		id := operation.OperationID
+		for i := 2; operationIDs[operation.OperationID]; i++ {
+			operation.OperationID = id + strconv.Itoa(i)
+		}
+		operationIDs[operation.OperationID] = true
+		for _, requirement := range operation.Security {
+			for name := range requirement {
+				if name != "bearerAuth" {
+					s.Warnf("security scheme %s of %s is not defined; add it to components.securitySchemes", name, operation.OperationID)
+				}
+				security = true
+			}
+		}
+		if doc.Paths[openAPIPath] == nil {
+			doc.Paths[openAPIPath] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[openAPIPath][route.Method] = operation
+	}
+
+	doc.Components.Responses = map[string]*openAPIResponse{
+		"Problem": {
+			Description: "The request failed; the problem document describes why",
+			Content:     map[string]openAPIMediaType{"application/problem+json": {Schema: s.problemSchema()}},
+		},
+	}
+	if security {
+		doc.Components.SecuritySchemes = map[string]*openAPISecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+	doc.Components.Schemas = s.schemas
+	return doc
+}
+
+// problemSchema returns the schema of the problem documents of middleware.Problem
+func (s *apiScanner) problemSchema() *openAPISchema {
+	if pkg := s.LookupPackage(frameworkModule + "/framework/middleware"); pkg != nil {
+		if spec := pkg.Types["Problem"]; spec != nil {
+			return s.namedSchema(spec, nil, 0)
+		}
+	}
+	return &openAPISchema{Type: "object"}
+}
+
+// fiberParamPattern matches the parameters of Fiber paths, e.g. :id, :id?, :id<int> and the
+// * and + wildcards
+var fiberParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)(<[^>]*>)?\??|[*+]`)
+
+// pathParam is a parameter of a Fiber path
+type pathParam struct {
+	name   string
+	schema openAPISchema
+}
+
+// openAPIPathParams converts a Fiber path to an OpenAPI path, e.g. /products/{id} for
+// /products/:id, and returns its parameters
+func openAPIPathParams(fiberPath string) (string, []pathParam) {
+	var params []pathParam
+	wildcards := 0
+	converted := fiberParamPattern.ReplaceAllStringFunc(fiberPath, func(match string) string {
+		groups := fiberParamPattern.FindStringSubmatch(match)
+		param := pathParam{name: groups[1], schema: openAPISchema{Type: "string"}}
+		if param.name == "" {
+			wildcards++
+			param.name = "wildcard"
+			if wildcards > 1 {
+				param.name += strconv.Itoa(wildcards)
+			}
+		}
+		constraint := strings.Trim(groups[2], "<>")
+		switch {
+		case strings.HasPrefix(constraint, "int"):
+			param.schema = openAPISchema{Type: "integer"}
+		case strings.HasPrefix(constraint, "bool"):
+			param.schema = openAPISchema{Type: "boolean"}
+		case strings.HasPrefix(constraint, "float"):
+			param.schema = openAPISchema{Type: "number"}
+		case strings.HasPrefix(constraint, "guid"):
+			param.schema = openAPISchema{Type: "string", Format: "uuid"}
+		}
+		params = append(params, param)
+		return "{" + param.name + "}"
+	})
+	return converted, params
+}
+
+// statusCodes are the HTTP status codes by the names of their fiber and net/http constants,
+// e.g. Created for StatusCreated
+var statusCodes = func() map[string]int {
+	codes := make(map[string]int)
+	for code := 100; code < 600; code++ {
+		if text := http.StatusText(code); text != "" {
+			codes[strings.NewReplacer(" ", "", "-", "", "'", "").Replace(text)] = code
+		}
+	}
+	codes["OK"] = http.StatusOK
+	codes["Teapot"] = http.StatusTeapot
+	return codes
+}()
+
+// apiHandler is the handler of a route: its declaration when it has one, and its body
+type apiHandler struct {
+	file *apiscan.File
+	name string // function or method name
+	recv string // receiver type of a method
+	doc  *ast.CommentGroup
+	body *ast.BlockStmt
+	ctx  string // name of the *fiber.Ctx parameter
+}
+
+// handler resolves the handler of a route: a function or method, a function literal, or a
+// function returning a function literal
+func (s *apiScanner) handler(f *apiscan.File, expr ast.Expr) apiHandler {
+	switch e := expr.(type) {
+	case *ast.FuncLit:
+		return apiHandler{file: f, body: e.Body, ctx: firstParamName(e.Type)}
+	case *ast.CallExpr:
+		fn, _ := s.Callee(f, e)
+		if fn == nil || fn.Decl.Body == nil {
+			return apiHandler{file: f}
+		}
+		h := apiHandler{file: fn.File, name: fn.Decl.Name.Name, recv: fn.Recv, doc: fn.Decl.Doc, body: fn.Decl.Body}
+		ast.Inspect(fn.Decl.Body, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.FuncLit); ok && h.ctx == "" {
+				h.body, h.ctx = lit.Body, firstParamName(lit.Type)
+			}
+			return h.ctx == ""
+		})
+		return h
+	}
+	fn := s.FuncValue(f, expr)
+	if fn == nil || fn.Decl.Body == nil {
+		return apiHandler{file: f}
+	}
+	return apiHandler{file: fn.File, name: fn.Decl.Name.Name, recv: fn.Recv, doc: fn.Decl.Doc, body: fn.Decl.Body, ctx: firstParamName(fn.Decl.Type)}
+}
+
+// firstParamName returns the name of the first parameter of a function type
+func firstParamName(fn *ast.FuncType) string {
+	if fn.Params == nil || len(fn.Params.List) == 0 || len(fn.Params.List[0].Names) == 0 {
+		return ""
+	}
+	return fn.Params.List[0].Names[0].Name
+}
+
+// operationBuilder collects the parameters, body and responses of an operation
+type operationBuilder struct {
+	scanner   *apiScanner
+	operation *openAPIOperation
+	method    string
+	body      *apiscan.Type
+	params    map[string]*openAPIParameter // by "<in>:<name>"
+	order     []string
+	statuses  []string
+	visited   map[*ast.BlockStmt]bool
+}
+
+// operation builds the operation of a route from its handler and the annotations of its doc
+// comment; it returns false for routes annotated @Ignore
+func (s *apiScanner) operation(route apiscan.Route) (*openAPIOperation, bool) {
+	h := s.handler(route.Func.File, route.Handler)
+	b := &operationBuilder{
+		scanner:   s,
+		operation: &openAPIOperation{Responses: make(map[string]*openAPIResponse)},
+		method:    route.Method,
+		params:    make(map[string]*openAPIParameter),
+		visited:   make(map[*ast.BlockStmt]bool),
+	}
+
+	_, pathParams := openAPIPathParams(route.Path)
+	for _, param := range pathParams {
+		schema := param.schema
+		b.addParam(&openAPIParameter{Name: param.name, In: "path", Required: true, Schema: &schema})
+	}
+	if h.body != nil {
+		b.inspect(h.file, h.body, h.ctx, 0)
+	}
+
+	tag := strings.TrimSuffix(h.recv, "Handler")
+	if tag != "" {
+		b.operation.Tags = []string{tag}
+	}
+	b.operation.OperationID = operationID(h, route)
+	b.operation.Summary, b.operation.Description = docSummary(h)
+	if !b.annotate(h) {
+		return nil, false
+	}
+
+	for _, key := range b.order {
+		b.operation.Parameters = append(b.operation.Parameters, b.params[key])
+	}
+	if b.body != nil && b.operation.RequestBody == nil {
+		if schema := s.schema(*b.body, 0); schema != nil {
+			b.operation.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content:  map[string]openAPIMediaType{"application/json": {Schema: schema}},
+			}
+		}
+	}
+	if len(b.operation.Responses) == 0 {
+		b.operation.Responses["200"] = &openAPIResponse{Description: "OK"}
+	}
+	if _, ok := b.operation.Responses["default"]; !ok {
+		b.operation.Responses["default"] = &openAPIResponse{Ref: problemResponse}
+	}
+	return b.operation, true
+}
+
+// operationID names an operation after its handler, e.g. createProduct for the Create
+// method of ProductHandler, or after its method and path for function literals
+func operationID(h apiHandler, route apiscan.Route) string {
+	if h.name != "" {
+		name := []rune(h.name)
+		name[0] = []rune(strings.ToLower(string(name[0])))[0]
+		return string(name) + exportedName(strings.TrimSuffix(h.recv, "Handler"))
+	}
+	id := route.Method
+	for _, element := range strings.FieldsFunc(route.Path, func(r rune) bool { return r == '/' || r == '-' || r == '_' || r == '.' }) {
+		id += exportedName(strings.Trim(element, ":*+?"))
+	}
+	return id
+}
+
+// docSummary returns the summary and description of an operation from the doc comment of its
+// handler: the first sentence, without the name of the handler, and the rest of the text
+func docSummary(h apiHandler) (summary, description string) {
+	if h.doc == nil {
+		return "", ""
+	}
+	var lines []string
+	for _, line := range strings.Split(h.doc.Text(), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "@") {
+			lines = append(lines, line)
+		}
+	}
+	text := strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+	text = strings.TrimPrefix(text, h.name+" ")
+	if text == "" {
+		return "", ""
+	}
+	summary, rest, _ := strings.Cut(text, ". ")
+	summary = strings.TrimSuffix(summary, ".")
+	// Summaries such as "handles GET /products/:id" repeat the route
+	if method, _, _ := strings.Cut(strings.TrimPrefix(summary, "handles "), " "); strings.HasPrefix(summary, "handles ") && apiscan.RouteMethods[exportedName(strings.ToLower(method))] != "" {
+		return "", rest
+	}
+	return exportedName(summary), rest
+}
+
+// inspect reads the parameters, body and responses of an operation from a handler body, and
+// the parameters read by the functions the handler passes its context to
+func (b *operationBuilder) inspect(f *apiscan.File, body *ast.BlockStmt, ctx string, depth int) {
+	if ctx == "" || b.visited[body] || depth > 3 {
+		return
+	}
+	b.visited[body] = true
+	status := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		if stmt, ok := n.(*ast.ExprStmt); ok {
+			// c.Status(code) followed by c.JSON(value)
+			if call, ok := stmt.X.(*ast.CallExpr); ok && isCtxCall(call, ctx, "Status") && len(call.Args) == 1 {
+				if status = statusCode(f, call.Args[0], b.scanner); status == 0 {
+					status = -1
+				}
+			}
+			return true
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if chained, ok := ctxChain(f, sel.X, ctx, b.scanner); ok {
+				if chained == 0 {
+					chained = status
+				}
+				b.ctxCall(f, sel.Sel.Name, call, chained, depth)
+				if sel.Sel.Name == "JSON" || sel.Sel.Name == "SendStatus" || strings.HasPrefix(sel.Sel.Name, "Send") {
+					status = 0
+				}
+				return true
+			}
+		}
+		b.call(f, call, ctx, depth)
+		return true
+	})
+}
+
+// ctxChain reports whether an expression is the context of a handler, possibly with a
+// status set, e.g. c.Status(fiber.StatusCreated); it returns the status, 0 when none is set
+// and -1 when it is only known at run time
+func ctxChain(f *apiscan.File, expr ast.Expr, ctx string, s *apiScanner) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return 0, e.Name == ctx
+	case *ast.CallExpr:
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Status" && len(e.Args) == 1 {
+			if _, ok := ctxChain(f, sel.X, ctx, s); ok {
+				if code := statusCode(f, e.Args[0], s); code != 0 {
+					return code, true
+				}
+				return -1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isCtxCall reports whether a call is ctx.name(...)
+func isCtxCall(call *ast.CallExpr, ctx, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == ctx
+}
+
+// statusCode returns the status of an expression, e.g. 201 for fiber.StatusCreated, or 0
+func statusCode(f *apiscan.File, expr ast.Expr, s *apiScanner) int {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		code, _ := strconv.Atoi(e.Value)
+		return code
+	case *ast.SelectorExpr:
+		if _, name, ok := s.SelectorPackage(f, e); ok {
+			return statusCodes[strings.TrimPrefix(name, "Status")]
+		}
+	}
+	return 0
+}
+
+// ctxCall reads a call of a method of the context of a handler
+func (b *operationBuilder) ctxCall(f *apiscan.File, method string, call *ast.CallExpr, status, depth int) {
+	switch method {
+	case "Query", "QueryInt", "QueryBool", "QueryFloat", "Params", "ParamsInt", "Get":
+		name, ok := "", false
+		if len(call.Args) > 0 {
+			name, ok = apiscan.StringLiteral(call.Args[0])
+		}
+		if !ok {
+			return
+		}
+		in, schema := "query", openAPISchema{Type: "string"}
+		switch method {
+		case "QueryInt", "ParamsInt":
+			schema.Type = "integer"
+		case "QueryBool":
+			schema.Type = "boolean"
+		case "QueryFloat":
+			schema.Type = "number"
+		}
+		switch method {
+		case "Params", "ParamsInt":
+			in = "path"
+		case "Get":
+			// Headers handled by the framework, such as Authorization, are left out
+			if !strings.HasPrefix(strings.ToLower(name), "x-") {
+				return
+			}
+			in = "header"
+		}
+		// The path and its struct tags type parameters better than their reads
+		if _, ok := b.params[paramKey(in, name)]; !ok {
+			b.addParam(&openAPIParameter{Name: name, In: in, Required: in == "path", Schema: &schema})
+		}
+	case "BodyParser", "QueryParser", "ParamsParser", "ReqHeaderParser":
+		if len(call.Args) != 1 {
+			return
+		}
+		t, ok := b.scanner.TypeOf(f, call.Args[0])
+		if !ok {
+			return
+		}
+		if method == "BodyParser" {
+			b.bind(t, true)
+			return
+		}
+		in := map[string]string{"QueryParser": "query", "ParamsParser": "path", "ReqHeaderParser": "header"}[method]
+		b.structParams(t, in, 0)
+	case "JSON":
+		if len(call.Args) == 0 {
+			return
+		}
+		var schema *openAPISchema
+		if t, ok := b.scanner.TypeOf(f, call.Args[0]); ok {
+			schema = b.scanner.schema(t, 0)
+		}
+		if schema == nil {
+			schema = &openAPISchema{}
+		}
+		b.addResponse(status, "application/json", schema)
+	case "SendStatus":
+		if len(call.Args) == 1 {
+			if code := statusCode(f, call.Args[0], b.scanner); code != 0 {
+				b.addResponse(code, "", nil)
+			}
+		}
+	case "SendString":
+		b.addResponse(status, "text/plain", &openAPISchema{Type: "string"})
+	case "Send", "SendStream", "SendFile", "Download":
+		b.addResponse(status, "application/octet-stream", &openAPISchema{Type: "string", Format: "binary"})
+	}
+}
+
+// call reads a call of a handler: binding with middleware.Bind, errors created with
+// fiber.NewError, and functions the context is passed to
+func (b *operationBuilder) call(f *apiscan.File, call *ast.CallExpr, ctx string, depth int) {
+	s := b.scanner
+	if importPath, name, ok := s.SelectorPackage(f, apiscan.UnwrapIndex(call.Fun)); ok {
+		switch {
+		case importPath == apiscan.FiberPath && name == "NewError" && len(call.Args) > 0:
+			if code := statusCode(f, call.Args[0], s); code != 0 {
+				b.operation.Responses[strconv.Itoa(code)] = &openAPIResponse{Ref: problemResponse}
+			}
+			return
+		case importPath == frameworkModule+"/framework/middleware":
+			if (name == "Bind" || name == "BindWith") && call.Fun != apiscan.UnwrapIndex(call.Fun) {
+				if t, ok := s.ResultType(f, call, 0); ok {
+					b.bind(t, false)
+				}
+			}
+			return
+		}
+	}
+	for i, arg := range call.Args {
+		ident, ok := arg.(*ast.Ident)
+		if !ok || ident.Name != ctx {
+			continue
+		}
+		fn, _ := s.Callee(f, call)
+		if fn == nil || fn.Decl.Body == nil {
+			return
+		}
+		if name := paramName(fn.Decl.Type, i); name != "" {
+			b.inspect(fn.File, fn.Decl.Body, name, depth+1)
+		}
+		return
+	}
+}
+
+// paramName returns the name of the parameter of a function at an index
+func paramName(fn *ast.FuncType, index int) string {
+	i := 0
+	for _, field := range fn.Params.List {
+		for _, name := range field.Names {
+			if i == index {
+				return name.Name
+			}
+			i++
+		}
+		if len(field.Names) == 0 {
+			i++
+		}
+	}
+	return ""
+}
+
+// bind reads a type a request is bound to. Fields tagged params, query or reqHeader are
+// parameters; the other fields are the JSON body, or query parameters for methods without a
+// body unless the body is parsed explicitly.
+func (b *operationBuilder) bind(t apiscan.Type, explicitBody bool) {
+	body := b.structParams(t, "", 0)
+	if !body {
+		return
+	}
+	if explicitBody || b.method == "post" || b.method == "put" || b.method == "patch" {
+		b.body = &t
+		return
+	}
+	b.structParams(t, "query", 0)
+}
+
+// structParams adds the parameters of the fields of a struct; in is the location of the
+// untagged fields, or empty to leave them out. It reports whether the struct has untagged
+// fields, which make the JSON body.
+func (b *operationBuilder) structParams(t apiscan.Type, in string, depth int) bool {
+	s := b.scanner
+	st := s.Underlying(t)
+	structType, ok := st.Expr.(*ast.StructType)
+	if !ok || depth > 4 {
+		return true
+	}
+	body := false
+	for _, field := range structType.Fields.List {
+		tag := fieldTag(field)
+		fieldType := apiscan.Type{File: st.File, Expr: field.Type, Args: st.Args}
+		if len(field.Names) == 0 && tag.Get("json") == "" {
+			if _, ok := s.Underlying(fieldType).Expr.(*ast.StructType); ok {
+				body = b.structParams(fieldType, in, depth+1) || body
+				continue
+			}
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(apiscan.BaseTypeName(field.Type))}
+		}
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+			paramIn, name := "", ""
+			for _, location := range []struct{ tag, in string }{{"params", "path"}, {"query", "query"}, {"reqHeader", "header"}} {
+				if value, _, _ := strings.Cut(tag.Get(location.tag), ","); value != "" && value != "-" {
+					paramIn, name = location.in, value
+					break
+				}
+			}
+			if paramIn == "" {
+				if jsonName, options, _ := strings.Cut(tag.Get("json"), ","); jsonName == "-" && options == "" {
+					continue
+				}
+				body = true
+				if in == "" {
+					continue
+				}
+				paramIn, name = in, ident.Name
+			}
+			schema := s.schema(fieldType, 0)
+			if schema == nil {
+				continue
+			}
+			required := applyValidation(schema, tag.Get("validate"))
+			b.addParam(&openAPIParameter{
+				Name:        name,
+				In:          paramIn,
+				Description: docText(field.Doc),
+				Required:    required || paramIn == "path",
+				Schema:      schema,
+			})
+		}
+	}
+	return body
+}
+
+// addParam adds a parameter, replacing the one of the same name and location
+func (b *operationBuilder) addParam(param *openAPIParameter) {
+	key := paramKey(param.In, param.Name)
+	if _, ok := b.params[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.params[key] = param
+}
+
+// paramKey identifies a parameter by its location and name; header names are case-insensitive
+func paramKey(in, name string) string {
+	if in == "header" {
+		name = strings.ToLower(name)
+	}
+	return in + ":" + name
+}
+
+// addResponse adds a response unless the operation already has one with the status.
+// Responses whose status is only known at run time are left out.
+func (b *operationBuilder) addResponse(status int, contentType string, schema *openAPISchema) {
+	if status < 0 {
+		return
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	code := strconv.Itoa(status)
+	if _, ok := b.operation.Responses[code]; ok {
+		return
+	}
+	response := &openAPIResponse{Description: http.StatusText(status)}
+	if contentType != "" {
+		response.Content = map[string]openAPIMediaType{contentType: {Schema: schema}}
+	}
+	b.operation.Responses[code] = response
+}
+
+// annotate applies the annotations of the doc comment of a handler to its operation; it
+// returns false for @Ignore
+func (b *operationBuilder) annotate(h apiHandler) bool {
+	if h.doc == nil {
+		return true
+	}
+	for _, line := range strings.Split(h.doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		name, value, _ := strings.Cut(line[1:], " ")
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(name) {
+		case "ignore":
+			return false
+		case "summary":
+			b.operation.Summary = value
+		case "description":
+			b.operation.Description = value
+		case "tags":
+			b.operation.Tags = nil
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					b.operation.Tags = append(b.operation.Tags, tag)
+				}
+			}
+		case "id":
+			b.operation.OperationID = value
+		case "deprecated":
+			b.operation.Deprecated = true
+		case "security":
+			b.operation.Security = append(b.operation.Security, map[string][]string{value: {}})
+		case "param":
+			b.annotateParam(h, value)
+		case "request":
+			if t, ok := b.annotationType(h, value); ok {
+				if schema := b.scanner.schema(t, 0); schema != nil {
+					b.body = nil
+					b.operation.RequestBody = &openAPIRequestBody{
+						Required: true,
+						Content:  map[string]openAPIMediaType{"application/json": {Schema: schema}},
+					}
+				}
+			}
+		case "response":
+			b.annotateResponse(h, value)
+		default:
+			b.scanner.Warnf("unknown annotation @%s of %s", name, b.operation.OperationID)
+		}
+	}
+	return true
+}
+
+// annotateParam applies @Param name in type [required] [description]
+func (b *operationBuilder) annotateParam(h apiHandler, value string) {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		b.scanner.Warnf("@Param %s of %s needs a name, a location and a type", value, b.operation.OperationID)
+		return
+	}
+	param := &openAPIParameter{Name: fields[0], In: fields[1], Required: fields[1] == "path", Schema: &openAPISchema{}}
+	if t, ok := b.annotationType(h, fields[2]); ok {
+		if schema := b.scanner.schema(t, 0); schema != nil {
+			param.Schema = schema
+		}
+	}
+	rest := fields[3:]
+	if len(rest) > 0 && rest[0] == "required" {
+		param.Required = true
+		rest = rest[1:]
+	}
+	param.Description = strings.Join(rest, " ")
+	b.addParam(param)
+}
+
+// annotateResponse applies @Response status [type] [description]; a type of - or none
+// documents a response without a body
+func (b *operationBuilder) annotateResponse(h apiHandler, value string) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return
+	}
+	code := fields[0]
+	status, err := strconv.Atoi(code)
+	if err != nil && code != "default" {
+		b.scanner.Warnf("@Response %s of %s needs a status", value, b.operation.OperationID)
+		return
+	}
+	response := &openAPIResponse{Description: http.StatusText(status)}
+	rest := fields[1:]
+	if len(rest) > 0 {
+		if rest[0] == "-" {
+			rest = rest[1:]
+		} else if t, ok := b.annotationType(h, rest[0]); ok {
+			if schema := b.scanner.schema(t, 0); schema != nil {
+				response.Content = map[string]openAPIMediaType{"application/json": {Schema: schema}}
+			}
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 {
+		response.Description = strings.Join(rest, " ")
+	}
+	if response.Description == "" {
+		response.Description = "Response"
+	}
+	b.operation.Responses[code] = response
+}
+
+// annotationType parses the type of an annotation in the file of the handler; it reports
+// false when the text does not name a type, such as the first word of a description
+func (b *operationBuilder) annotationType(h apiHandler, text string) (apiscan.Type, bool) {
+	if h.file == nil {
+		return apiscan.Type{}, false
+	}
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return apiscan.Type{}, false
+	}
+	t := apiscan.Type{File: h.file, Expr: expr}
+	if !b.scanner.knownType(t, 0) {
+		return apiscan.Type{}, false
+	}
+	return t, true
+}
+
+// knownType reports whether a type expression names types that can be resolved
+func (s *apiScanner) knownType(t apiscan.Type, depth int) bool {
+	if depth > 8 {
+		return false
+	}
+	switch e := t.Expr.(type) {
+	case *ast.StarExpr:
+		return s.knownType(apiscan.Type{File: t.File, Expr: e.X}, depth+1)
+	case *ast.ArrayType:
+		return s.knownType(apiscan.Type{File: t.File, Expr: e.Elt}, depth+1)
+	case *ast.MapType:
+		return s.knownType(apiscan.Type{File: t.File, Expr: e.Value}, depth+1)
+	case *ast.Ident:
+		_, basic := basicSchemas[e.Name]
+		return basic || t.File.Package.Types[e.Name] != nil
+	case *ast.SelectorExpr:
+		importPath, name, ok := s.SelectorPackage(t.File, e)
+		if !ok {
+			return false
+		}
+		if _, ok := wellKnownSchemas[importPath+"."+name]; ok {
+			return true
+		}
+		pkg := s.LookupPackage(importPath)
+		return pkg != nil && pkg.Types[name] != nil
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		spec, _ := s.NamedType(t)
+		return spec != nil
+	}
+	return false
+}
+
+func init() {
+	generateOpenAPICmd.Flags().String("output", "docs/api/openapi.yaml", "File to write the document to, as JSON when it ends with .json")
+	generateOpenAPICmd.Flags().String("title", "", "Title of the API (default: app.name of the configuration, or the module name)")
+	generateOpenAPICmd.Flags().String("version", "", "Version of the API (default: app.version of the configuration, or 1.0.0)")
+	generateOpenAPICmd.Flags().StringSlice("server", nil, "URL of a server of the API, e.g. https://api.example.com; repeatable")
+	generateCmd.AddCommand(generateOpenAPICmd)
+}
@@ -0,0 +1,354 @@
+package generate
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
+)
+
+// openAPISchema is a JSON Schema of the OpenAPI document
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                    `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMinimum     *float64                  `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64                  `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+}
+
+// basicSchemas are the schemas of the predeclared types
+var basicSchemas = map[string]openAPISchema{
+	"string":  {Type: "string"},
+	"bool":    {Type: "boolean"},
+	"int":     {Type: "integer"},
+	"int8":    {Type: "integer", Format: "int32"},
+	"int16":   {Type: "integer", Format: "int32"},
+	"int32":   {Type: "integer", Format: "int32"},
+	"rune":    {Type: "integer", Format: "int32"},
+	"int64":   {Type: "integer", Format: "int64"},
+	"uint":    {Type: "integer"},
+	"uint8":   {Type: "integer", Format: "int32"},
+	"byte":    {Type: "integer", Format: "int32"},
+	"uint16":  {Type: "integer", Format: "int32"},
+	"uint32":  {Type: "integer", Format: "int64"},
+	"uint64":  {Type: "integer", Format: "int64"},
+	"float32": {Type: "number", Format: "float"},
+	"float64": {Type: "number", Format: "double"},
+	"any":     {},
+}
+
+// wellKnownSchemas are the schemas of types whose JSON encoding differs from their structure,
+// by "<import path>.<name>"
+var wellKnownSchemas = map[string]openAPISchema{
+	"time.Time":                             {Type: "string", Format: "date-time"},
+	"time.Duration":                         {Type: "integer", Format: "int64"},
+	"encoding/json.RawMessage":              {},
+	"net/url.URL":                           {Type: "string", Format: "uri"},
+	"database/sql.NullString":               {Type: "string"},
+	"database/sql.NullInt64":                {Type: "integer", Format: "int64"},
+	"database/sql.NullInt32":                {Type: "integer", Format: "int32"},
+	"database/sql.NullFloat64":              {Type: "number", Format: "double"},
+	"database/sql.NullBool":                 {Type: "boolean"},
+	"database/sql.NullTime":                 {Type: "string", Format: "date-time"},
+	"github.com/google/uuid.UUID":           {Type: "string", Format: "uuid"},
+	"github.com/gofiber/fiber/v2.Map":       {Type: "object"},
+	"github.com/shopspring/decimal.Decimal": {Type: "string"},
+}
+
+// schema returns the schema of a type, referring to the components of named struct types.
+// It returns nil for types JSON cannot encode, such as functions and channels.
+func (s *apiScanner) schema(t apiscan.Type, depth int) *openAPISchema {
+	if depth > 24 {
+		return &openAPISchema{}
+	}
+	switch e := t.Expr.(type) {
+	case *ast.ParenExpr:
+		return s.schema(apiscan.Type{File: t.File, Expr: e.X, Args: t.Args}, depth+1)
+	case *ast.StarExpr:
+		return s.schema(apiscan.Type{File: t.File, Expr: e.X, Args: t.Args}, depth+1)
+	case *ast.ArrayType:
+		if ident, ok := e.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") && t.Args[ident.Name].Expr == nil {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		items := s.schema(apiscan.Type{File: t.File, Expr: e.Elt, Args: t.Args}, depth+1)
+		if items == nil {
+			return nil
+		}
+		return &openAPISchema{Type: "array", Items: items}
+	case *ast.MapType:
+		values := s.schema(apiscan.Type{File: t.File, Expr: e.Value, Args: t.Args}, depth+1)
+		if values == nil {
+			return nil
+		}
+		return &openAPISchema{Type: "object", AdditionalProperties: values}
+	case *ast.InterfaceType:
+		return &openAPISchema{}
+	case *ast.StructType:
+		return s.structSchema(t.File, e, t.Args, depth)
+	case *ast.FuncType, *ast.ChanType:
+		return nil
+	case *ast.Ident:
+		if arg, ok := t.Args[e.Name]; ok {
+			return s.schema(arg, depth+1)
+		}
+		if spec, ok := t.File.Package.Types[e.Name]; ok {
+			return s.namedSchema(spec, nil, depth)
+		}
+		if e.Name == "error" {
+			return nil
+		}
+		if basic, ok := basicSchemas[e.Name]; ok {
+			return &basic
+		}
+	case *ast.SelectorExpr:
+		importPath, name, ok := s.SelectorPackage(t.File, e)
+		if !ok {
+			break
+		}
+		if known, ok := wellKnownSchemas[importPath+"."+name]; ok {
+			return &known
+		}
+		if pkg := s.LookupPackage(importPath); pkg != nil && pkg.Types[name] != nil {
+			return s.namedSchema(pkg.Types[name], nil, depth)
+		}
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		if spec, args := s.NamedType(t); spec != nil {
+			return s.namedSchema(spec, args, depth)
+		}
+	}
+	return &openAPISchema{}
+}
+
+// namedSchema returns the schema of a named type. Struct types are components, referred to
+// by name; instances of generic types and the other types are inlined, with the values of
+// the constants declared with the type as enum.
+func (s *apiScanner) namedSchema(spec *apiscan.TypeSpec, args map[string]apiscan.Type, depth int) *openAPISchema {
+	underlying := apiscan.Type{File: spec.File, Expr: spec.Spec.Type, Args: args}
+	_, isStruct := spec.Spec.Type.(*ast.StructType)
+	if spec.Spec.TypeParams != nil || spec.Spec.Assign.IsValid() || !isStruct {
+		schema := s.schema(underlying, depth+1)
+		if schema != nil && schema.Ref == "" && !isStruct {
+			schema.Enum = spec.File.Package.Enums[spec.Spec.Name.Name]
+		}
+		return schema
+	}
+
+	key := spec.File.Package.Path + "." + spec.Spec.Name.Name
+	name, ok := s.components[key]
+	if !ok {
+		name = s.componentName(spec)
+		s.components[key] = name
+		component := &openAPISchema{}
+		s.schemas[name] = component
+		if schema := s.schema(underlying, depth+1); schema != nil {
+			*component = *schema
+		}
+		component.Description = docText(spec.Doc)
+	}
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName names the component of a type after it, qualified by its package when
+// another type has the name
+func (s *apiScanner) componentName(spec *apiscan.TypeSpec) string {
+	name := spec.Spec.Name.Name
+	if _, taken := s.schemas[name]; !taken {
+		return name
+	}
+	qualified := exportedName(spec.File.Package.Name) + name
+	for i := 2; ; i++ {
+		if _, taken := s.schemas[qualified]; !taken {
+			return qualified
+		}
+		qualified = exportedName(spec.File.Package.Name) + name + strconv.Itoa(i)
+	}
+}
+
+// structSchema returns the object schema of a struct type, with the properties of its JSON
+// encoding. Embedded structs without a JSON name have their properties inlined.
+func (s *apiScanner) structSchema(f *apiscan.File, st *ast.StructType, args map[string]apiscan.Type, depth int) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	for _, field := range st.Fields.List {
+		tag := fieldTag(field)
+		name, options, _ := strings.Cut(tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		fieldType := apiscan.Type{File: f, Expr: field.Type, Args: args}
+		if len(field.Names) == 0 {
+			if name == "" {
+				embedded := s.Underlying(fieldType)
+				if st, ok := embedded.Expr.(*ast.StructType); ok {
+					inlined := s.structSchema(embedded.File, st, embedded.Args, depth+1)
+					for key, property := range inlined.Properties {
+						if _, ok := schema.Properties[key]; !ok {
+							schema.Properties[key] = property
+						}
+					}
+					schema.Required = append(schema.Required, inlined.Required...)
+					continue
+				}
+			}
+			if !ast.IsExported(apiscan.BaseTypeName(field.Type)) {
+				continue
+			}
+			if name == "" {
+				name = apiscan.BaseTypeName(field.Type)
+			}
+			s.addProperty(schema, name, options, field, fieldType, depth)
+			continue
+		}
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := name
+			if key == "" {
+				key = ident.Name
+			}
+			s.addProperty(schema, key, options, field, fieldType, depth)
+		}
+	}
+	return schema
+}
+
+// addProperty adds the property of a struct field to an object schema
+func (s *apiScanner) addProperty(schema *openAPISchema, key, options string, field *ast.Field, t apiscan.Type, depth int) {
+	property := s.schema(t, depth+1)
+	if property == nil {
+		return
+	}
+	if strings.Contains(","+options+",", ",string,") {
+		property = &openAPISchema{Type: "string"}
+	}
+	description := docText(field.Doc)
+	if description == "" {
+		description = docText(field.Comment)
+	}
+	if description != "" {
+		// A $ref may only have a description next to it, so it is copied
+		copied := *property
+		copied.Description = description
+		property = &copied
+	}
+	if applyValidation(property, fieldTag(field).Get("validate")) {
+		schema.Required = append(schema.Required, key)
+	}
+	schema.Properties[key] = property
+}
+
+// fieldTag returns the tag of a struct field
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	return reflect.StructTag(tag)
+}
+
+// applyValidation sets the constraints of a validate tag on a schema, and reports whether
+// the tag makes the value required
+func applyValidation(schema *openAPISchema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = nil
+			for _, value := range strings.Fields(param) {
+				if schema.Type == "integer" || schema.Type == "number" {
+					if n, err := strconv.ParseFloat(value, 64); err == nil {
+						schema.Enum = append(schema.Enum, n)
+					}
+					continue
+				}
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "min", "gte", "max", "lte", "gt", "lt", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, name, n)
+		}
+	}
+	return required
+}
+
+// applyBound sets a bound of a validate tag on a schema, as a length for strings and arrays
+// and as a range for numbers
+func applyBound(schema *openAPISchema, rule string, n float64) {
+	length := int(n)
+	switch schema.Type {
+	case "string":
+		switch rule {
+		case "min", "gte":
+			schema.MinLength = &length
+		case "max", "lte":
+			schema.MaxLength = &length
+		case "len":
+			schema.MinLength, schema.MaxLength = &length, &length
+		}
+	case "array":
+		switch rule {
+		case "min", "gte":
+			schema.MinItems = &length
+		case "max", "lte":
+			schema.MaxItems = &length
+		case "len":
+			schema.MinItems, schema.MaxItems = &length, &length
+		}
+	case "integer", "number":
+		switch rule {
+		case "min", "gte":
+			schema.Minimum = &n
+		case "max", "lte":
+			schema.Maximum = &n
+		case "gt":
+			schema.ExclusiveMinimum = &n
+		case "lt":
+			schema.ExclusiveMaximum = &n
+		}
+	}
+}
+
+// docText returns the text of a comment, on one line
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+// exportedName capitalizes the first letter of a name
+func exportedName(name string) string {
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
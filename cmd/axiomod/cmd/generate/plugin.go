@@ -0,0 +1,310 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// generatePluginCmd represents the generate plugin command
+var generatePluginCmd = &cobra.Command{
+	Use:   "plugin --name=[name]",
+	Short: "Generate a plugin for the plugin registry",
+	Long: `Generate a plugin implementing plugins.Plugin, in its own package.
+
+The package has the plugin with its Name, Initialize, Start and Stop methods and the
+optional Health and Status ones, the parsing of its settings, an fx module registering it
+with the plugin registry, a configuration section to enable and configure it, and tests.
+
+Example:
+  axiomod generate plugin --name=stripe
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if !crudNamePattern.MatchString(name) {
+			fmt.Println("Error: name must be a lowercase word, such as stripe")
+			os.Exit(1)
+		}
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		pluginPath, importPath := target.module(name)
+		if _, err := os.Stat(pluginPath); err == nil {
+			fmt.Printf("Error: %s already exists\n", pluginPath)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(pluginPath, 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", pluginPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating plugin: %s\n", name)
+
+		data := struct {
+			Name       string
+			Title      string
+			ImportPath string
+		}{
+			Name:       name,
+			Title:      strings.Title(name),
+			ImportPath: importPath,
+		}
+		generateFile(pluginTemplate, filepath.Join(pluginPath, "plugin.go"), data)
+		generateFile(pluginSettingsTemplate, filepath.Join(pluginPath, "settings.go"), data)
+		generateFile(pluginModuleTemplate, filepath.Join(pluginPath, "module.go"), data)
+		generateFile(pluginTestTemplate, filepath.Join(pluginPath, "plugin_test.go"), data)
+		generateFile(pluginConfigTemplate, filepath.Join(pluginPath, "config.example.yaml"), data)
+
+		fmt.Printf("\nPlugin %s generated successfully in %s\n", name, pluginPath)
+		fmt.Println("\nRemember to:")
+		fmt.Printf("1. Add %s.Module to your application after plugins.Module, or register &%s.Plugin{} with the plugin registry.\n", name, name)
+		fmt.Printf("2. Merge the section of %s into your configuration.\n", filepath.Join(pluginPath, "config.example.yaml"))
+		fmt.Println("3. Implement Start, Stop and Health, and add the settings the plugin needs.")
+	},
+}
+
+const pluginTemplate = `// Package {{.Name}} is the {{.Name}} plugin of the plugin registry.
+package {{.Name}}
+
+import (
+	"context"
+	"sync"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// Plugin is the {{.Name}} plugin. It is enabled with plugins.enabled.{{.Name}} and configured
+// with plugins.settings.{{.Name}}, see config.example.yaml.
+type Plugin struct {
+	logger   *observability.Logger
+	metrics  *observability.Metrics
+	settings settings
+
+	mu      sync.RWMutex
+	running bool
+}
+
+// Name returns the name of the plugin in configuration
+func (p *Plugin) Name() string {
+	return "{{.Name}}"
+}
+
+// Initialize reads the settings of the plugin
+func (p *Plugin) Initialize(settings map[string]interface{}, logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config, health *health.Health) error {
+	parsed, err := parseSettings(settings)
+	if err != nil {
+		return err
+	}
+	p.logger = logger
+	p.metrics = metrics
+	p.settings = parsed
+	return nil
+}
+
+// Start starts the plugin, e.g. connects to its endpoint. It must return once ctx is done.
+func (p *Plugin) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running = true
+	if p.logger != nil {
+		p.logger.Info("Started {{.Name}} plugin", zap.String("endpoint", p.settings.Endpoint))
+	}
+	return nil
+}
+
+// Stop stops the plugin, releasing what Start acquired. It must return once ctx is done.
+func (p *Plugin) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running = false
+	return nil
+}
+
+// Health returns an error while the plugin does not work, e.g. when its endpoint is
+// unreachable. The registry checks it as plugin_{{.Name}} with the other health checks.
+func (p *Plugin) Health() error {
+	return nil
+}
+
+// Status reports details of the plugin in GET /admin/plugins
+func (p *Plugin) Status() map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return map[string]interface{}{
+		"running":  p.running,
+		"endpoint": p.settings.Endpoint,
+		"timeout":  p.settings.Timeout.String(),
+	}
+}
+`
+
+const pluginSettingsTemplate = `package {{.Name}}
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// settings of the plugin, read from plugins.settings.{{.Name}}
+type settings struct {
+	Endpoint string        // address of the service the plugin uses
+	Timeout  time.Duration // timeout of its calls
+}
+
+// defaultSettings returns the settings of a plugin configured with none
+func defaultSettings() settings {
+	return settings{Timeout: 5 * time.Second}
+}
+
+// parseSettings reads the settings of the plugin. Keys are matched case-insensitively,
+// since configuration loaders lowercase them.
+func parseSettings(raw map[string]interface{}) (settings, error) {
+	s := defaultSettings()
+	values := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		values[strings.ToLower(key)] = value
+	}
+
+	if v, ok := values["endpoint"]; ok && v != nil {
+		s.Endpoint = strings.TrimSpace(fmt.Sprint(v))
+	}
+	if v, ok := values["timeout"]; ok && v != nil {
+		d, err := time.ParseDuration(fmt.Sprint(v))
+		if err != nil || d <= 0 {
+			return settings{}, fmt.Errorf("{{.Name}}: timeout must be a positive duration such as 5s, got %v", v)
+		}
+		s.Timeout = d
+	}
+	return s, nil
+}
+`
+
+const pluginModuleTemplate = `package {{.Name}}
+
+import (
+	"github.com/axiomod/axiomod/plugins"
+
+	"go.uber.org/fx"
+)
+
+// Module registers the {{.Name}} plugin with the registry of plugins.Module, which
+// initializes and starts it when it is enabled
+var Module = fx.Invoke(Register)
+
+// Register registers the {{.Name}} plugin with a plugin registry
+func Register(registry *plugins.PluginRegistry) {
+	registry.Register(&Plugin{})
+}
+`
+
+const pluginTestTemplate = `package {{.Name}}
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/plugins"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ plugins.Plugin         = (*Plugin)(nil)
+	_ plugins.HealthChecker  = (*Plugin)(nil)
+	_ plugins.StatusReporter = (*Plugin)(nil)
+)
+
+func TestPlugin_Lifecycle(t *testing.T) {
+	p := &Plugin{}
+
+	assert.Equal(t, "{{.Name}}", p.Name())
+
+	err := p.Initialize(map[string]interface{}{"endpoint": "localhost:9000"}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Start(context.Background()))
+	assert.NoError(t, p.Health())
+	assert.Equal(t, true, p.Status()["running"])
+	assert.Equal(t, "localhost:9000", p.Status()["endpoint"])
+
+	require.NoError(t, p.Stop(context.Background()))
+	assert.Equal(t, false, p.Status()["running"])
+}
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     settings
+		err      string
+	}{
+		{
+			name:     "defaults",
+			settings: map[string]interface{}{},
+			want:     defaultSettings(),
+		},
+		{
+			name:     "keys in any case",
+			settings: map[string]interface{}{"Endpoint": " localhost:9000 ", "TIMEOUT": "2s"},
+			want:     settings{Endpoint: "localhost:9000", Timeout: 2 * time.Second},
+		},
+		{
+			name:     "invalid timeout",
+			settings: map[string]interface{}{"timeout": "soon"},
+			err:      "timeout must be a positive duration",
+		},
+		{
+			name:     "negative timeout",
+			settings: map[string]interface{}{"timeout": "-1s"},
+			err:      "timeout must be a positive duration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSettings(tt.settings)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+`
+
+const pluginConfigTemplate = `# Configuration of the {{.Name}} plugin, to merge into the service configuration
+plugins:
+  enabled:
+    {{.Name}}: true
+  settings:
+    {{.Name}}:
+      endpoint: localhost:9000
+      timeout: 5s
+  # Start and stop timeouts in seconds, 10 by default
+  timeouts:
+    {{.Name}}: {start: 10, stop: 10}
+`
+
+func init() {
+	generatePluginCmd.Flags().StringP("name", "n", "", "Name of the plugin and its package, e.g. stripe (required)")
+	generatePluginCmd.Flags().String("output", "plugins", "Directory to generate the plugin in")
+	generatePluginCmd.Flags().String("package", "", "Go import path of the output directory (default: derived from go.mod)")
+	generatePluginCmd.MarkFlagRequired("name")
+	generateCmd.AddCommand(generatePluginCmd)
+}
@@ -0,0 +1,272 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// generateProtoCmd represents the generate proto command
+var generateProtoCmd = &cobra.Command{
+	Use:   "proto --name=[module]",
+	Short: "Generate the versioned proto package of a module",
+	Long: `Generate the gRPC contract of a module from its entity: a service with create, get,
+update, delete and list RPCs, and messages with the fields of the entity, in the versioned
+package proto/<module>/v1.
+
+The entity is read from the entity package of the module. Fields of the types string, int,
+int64, float64, bool and time.Time are mapped to their proto types; others are skipped.
+The command also adds buf.yaml, buf.gen.yaml and a proto target to the Makefile of the
+project if they are missing, so that make proto generates the Go code of every contract
+into gen/proto for the gRPC delivery layer to register.
+
+Example:
+  axiomod generate proto --name=order
+  axiomod generate proto --name=shop --entity=order_item
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if !crudNamePattern.MatchString(name) {
+			fmt.Println("Error: name must be a lowercase word, such as order")
+			os.Exit(1)
+		}
+		entityName, _ := cmd.Flags().GetString("entity")
+		if entityName == "" {
+			entityName = name
+		}
+		if !filterNamePattern.MatchString(entityName) {
+			fmt.Println("Error: entity must be a snake_case name, such as order_item")
+			os.Exit(1)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, importPath := target.module(name)
+		entityFile := filepath.Join(modulePath, "entity", entityName+".go")
+		data, err := loadProtoEntity(entityFile, name, entityName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		data.ImportPath = importPath
+		data.ModulePath = target.ModulePath
+
+		protoDir := target.protoDir(name)
+		protoFile := filepath.Join(protoDir, entityName+".proto")
+		if _, err := os.Stat(protoFile); err == nil && !force {
+			fmt.Printf("Error: %s already exists; use --force to regenerate it\n", protoFile)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(protoDir, 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", protoDir, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating proto package %s.v1 for %s\n", name, data.EntityName)
+		generateFile(crudProtoTemplate, protoFile, data)
+		if err := setupBuf(target.Root); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\nProto package of %s generated successfully in %s\n", name, protoDir)
+		fmt.Println("\nRemember to:")
+		fmt.Println("1. Install buf, protoc-gen-go and protoc-gen-go-grpc, e.g. with make deps in projects created by axiomod init.")
+		fmt.Printf("2. Run make proto to generate the Go code into %s.\n", filepath.Join(target.Root, "gen", "proto", name, "v1"))
+		fmt.Printf("3. Uncomment the gRPC service of %s and register it with pb.Register%sServiceServer.\n", name, data.EntityName)
+	},
+}
+
+// protoGoTypes are the crud field types of the Go types of entity fields
+var protoGoTypes = map[string]string{
+	"string":    "string",
+	"int":       "int",
+	"int64":     "int64",
+	"float64":   "float",
+	"bool":      "bool",
+	"time.Time": "time",
+}
+
+// loadProtoEntity reads the struct of an entity from its Go file, returning the template
+// data of its proto contract. Fields of unsupported types are skipped with a warning.
+func loadProtoEntity(path, moduleName, entityName string) (crudData, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return crudData{}, fmt.Errorf("reading entity %s: %w", entityName, err)
+	}
+	goName, _ := fieldNames(entityName)
+	_, pluralTitle := fieldNames(plural(entityName))
+	var entity *ast.StructType
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok && spec.Name.Name == goName {
+			entity, _ = spec.Type.(*ast.StructType)
+		}
+		return entity == nil
+	})
+	if entity == nil {
+		return crudData{}, fmt.Errorf("%s declares no struct %s", path, goName)
+	}
+
+	variable := strings.ToLower(goName[:1]) + goName[1:]
+	data := crudData{
+		ModuleName:      moduleName,
+		EntityName:      goName,
+		EntityNameLower: variable,
+		Plural:          plural(entityName),
+		PluralTitle:     pluralTitle,
+	}
+	seen := make(map[string]bool)
+	for _, f := range entity.Fields.List {
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := jsonName(f, ident.Name)
+			switch {
+			case key == "-", key == "id", key == "created_at", key == "updated_at", seen[key]:
+				continue
+			}
+			goType := types.ExprString(f.Type)
+			typeName, ok := protoGoTypes[goType]
+			if !ok {
+				fmt.Printf("Warning: skipping field %s of type %s, which has no proto mapping\n", ident.Name, goType)
+				continue
+			}
+			field, err := newCrudField(key, typeName, variable, len(data.Fields))
+			if err != nil {
+				return crudData{}, fmt.Errorf("entity %s: %w", goName, err)
+			}
+			seen[key] = true
+			data.Fields = append(data.Fields, field)
+		}
+	}
+	if len(data.Fields) == 0 {
+		return crudData{}, fmt.Errorf("entity %s has no field to map to proto besides id and its timestamps", goName)
+	}
+	data.CreatedAtNumber = len(data.Fields) + 2
+	data.UpdatedAtNumber = len(data.Fields) + 3
+	return data, nil
+}
+
+// jsonName returns the snake_case name of an entity field, from its json tag if any
+func jsonName(f *ast.Field, goName string) string {
+	name := goName
+	if f.Tag != nil {
+		if tag, err := strconv.Unquote(f.Tag.Value); err == nil {
+			if value, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ","); value != "" {
+				name = value
+			}
+		}
+	}
+	if name == "-" {
+		return name
+	}
+	return snakeCase(name)
+}
+
+// snakeCase converts a Go or camelCase name to snake_case, e.g. CustomerID to customer_id
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) && runes[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// setupBuf adds the buf configuration generating the Go code of the proto packages under
+// proto into gen/proto, and a proto target running it to the Makefile, unless present
+func setupBuf(root string) error {
+	for _, file := range []struct{ name, content string }{{"buf.yaml", bufTemplate}, {"buf.gen.yaml", bufGenTemplate}} {
+		path := filepath.Join(root, file.name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			return fmt.Errorf("creating %s: %w", path, err)
+		}
+		fmt.Printf("Generated file: %s\n", path)
+	}
+
+	path := filepath.Join(root, "Makefile")
+	makefile, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(makefile), "\n") {
+		if strings.HasPrefix(line, "proto:") {
+			return nil
+		}
+	}
+	if len(makefile) > 0 && !bytes.HasSuffix(makefile, []byte("\n")) {
+		makefile = append(makefile, '\n')
+	}
+	if len(makefile) > 0 {
+		makefile = append(makefile, '\n')
+	}
+	makefile = append(makefile, makeProtoTarget...)
+	if err := os.WriteFile(path, makefile, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Printf("Added the proto target to %s\n", path)
+	return nil
+}
+
+const bufTemplate = `version: v2
+modules:
+  - path: proto
+lint:
+  use:
+    - STANDARD
+  except:
+    # Get, Create and Update return the entity message, Delete returns google.protobuf.Empty
+    - RPC_REQUEST_RESPONSE_UNIQUE
+    - RPC_RESPONSE_STANDARD_NAME
+breaking:
+  use:
+    - FILE
+`
+
+const bufGenTemplate = `version: v2
+plugins:
+  - local: protoc-gen-go
+    out: gen/proto
+    opt: paths=source_relative
+  - local: protoc-gen-go-grpc
+    out: gen/proto
+    opt: paths=source_relative
+`
+
+const makeProtoTarget = `.PHONY: proto
+proto:
+	buf generate
+`
+
+func init() {
+	generateProtoCmd.Flags().StringP("name", "n", "", "Name of the module, e.g. order (required)")
+	generateProtoCmd.Flags().String("entity", "", "snake_case name of the entity of the module (default: the module name)")
+	generateProtoCmd.Flags().Bool("force", false, "Overwrite an existing proto file")
+	generateProtoCmd.MarkFlagRequired("name")
+	addTargetFlags(generateProtoCmd)
+	generateCmd.AddCommand(generateProtoCmd)
+}
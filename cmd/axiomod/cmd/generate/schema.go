@@ -0,0 +1,491 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// moduleSchema is the schema file of generate module --from, declaring the entities of a
+// module:
+//
+//	module: shop
+//	entities:
+//	  - name: customer
+//	    fields:
+//	      - {name: email, type: string, unique: true}
+//	  - name: order
+//	    fields:
+//	      - {name: status, type: string}
+//	      - {name: total, type: float}
+//	    relations:
+//	      - {name: customer, type: belongs_to, entity: customer}
+//	    indexes:
+//	      - {fields: [status, created_at]}
+type moduleSchema struct {
+	Module   string         `yaml:"module"`
+	Entities []entitySchema `yaml:"entities"`
+}
+
+// entitySchema declares an entity of a schema file. Every entity also has the id,
+// created_at and updated_at fields.
+type entitySchema struct {
+	Name      string           `yaml:"name"`
+	Fields    []fieldSchema    `yaml:"fields"`
+	Relations []relationSchema `yaml:"relations"`
+	Indexes   []indexSchema    `yaml:"indexes"`
+}
+
+// fieldSchema declares a field of an entity, with a type of generate crud --fields
+type fieldSchema struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Unique bool   `yaml:"unique"`
+}
+
+// relationSchema declares a relation between two entities. A belongs_to relation adds the
+// foreign key <name>_id to its entity, referencing the other entity; a has_many relation
+// adds it to the other entity. Inverse names the relation seen from the other entity, by
+// default the plural, or singular, name of this entity.
+type relationSchema struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`
+	Entity  string `yaml:"entity"`
+	Inverse string `yaml:"inverse"`
+}
+
+// indexSchema declares an index of an entity on some of its fields
+type indexSchema struct {
+	Fields []string `yaml:"fields"`
+	Unique bool     `yaml:"unique"`
+}
+
+// schemaEntity is the template data of an entity of a schema file, extending the data of
+// the crud templates with its Ent schema
+type schemaEntity struct {
+	crudData
+	FileName string   // snake_case name of the entity's files, e.g. order_item
+	Edges    []string // Go expressions of the Ent edges
+	Indexes  []string // Go expressions of the Ent indexes
+	SQL      []string // CREATE INDEX statements
+}
+
+// schemaModule is the template data of a module generated from a schema file
+type schemaModule struct {
+	ModuleName     string
+	ImportPath     string
+	ModulePath     string
+	SchemaFile     string
+	Entities       []*schemaEntity // in dependency order: referenced entities first
+	DropTables     []string        // tables in the reverse order
+	MigrationTitle string
+}
+
+// loadModuleSchema reads a schema file, for a module generated in target. The module name
+// defaults to the module declared by the file.
+func loadModuleSchema(path, name string, target generationTarget) (*schemaModule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schema moduleSchema
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %w", path, err)
+	}
+	if name == "" {
+		name = schema.Module
+	}
+	if !crudNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("module name %q must be a lowercase word; set it with --name or module", name)
+	}
+	if len(schema.Entities) == 0 {
+		return nil, fmt.Errorf("schema file %s declares no entities", path)
+	}
+
+	_, importPath := target.module(name)
+	module := &schemaModule{
+		ModuleName:     name,
+		ImportPath:     importPath,
+		ModulePath:     target.ModulePath,
+		SchemaFile:     filepath.Base(path),
+		MigrationTitle: "create_" + name + "_tables",
+	}
+	byName := make(map[string]*schemaEntity)
+	for _, declared := range schema.Entities {
+		if !filterNamePattern.MatchString(declared.Name) {
+			return nil, fmt.Errorf("invalid entity name %q, use snake_case names such as order_item", declared.Name)
+		}
+		if byName[declared.Name] != nil {
+			return nil, fmt.Errorf("entity %q is declared twice", declared.Name)
+		}
+		goName, _ := fieldNames(declared.Name)
+		variable := strings.ToLower(goName[:1]) + goName[1:]
+		entity := &schemaEntity{
+			crudData: crudData{
+				ModuleName:      name,
+				ImportPath:      importPath,
+				ModulePath:      target.ModulePath,
+				EntityName:      goName,
+				EntityNameLower: variable,
+				Plural:          plural(declared.Name),
+			},
+			FileName: declared.Name,
+		}
+		for _, f := range declared.Fields {
+			if entity.field(f.Name) != nil {
+				return nil, fmt.Errorf("field %q of entity %q is declared twice", f.Name, declared.Name)
+			}
+			field, err := newCrudField(f.Name, f.Type, variable, len(entity.Fields))
+			if err != nil {
+				return nil, fmt.Errorf("entity %q: %w", declared.Name, err)
+			}
+			field.Unique = f.Unique
+			entity.Fields = append(entity.Fields, field)
+		}
+		byName[declared.Name] = entity
+		module.Entities = append(module.Entities, entity)
+	}
+
+	// Relations add foreign keys, which need every entity declared first
+	for _, declared := range schema.Entities {
+		for _, relation := range declared.Relations {
+			if err := addRelation(byName, declared.Name, relation); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, declared := range schema.Entities {
+		if err := addIndexes(module.Entities[i], declared.Indexes); err != nil {
+			return nil, fmt.Errorf("entity %q: %w", declared.Name, err)
+		}
+		addForeignKeyIndexes(module.Entities[i], declared.Indexes)
+	}
+	for _, entity := range module.Entities {
+		if len(entity.Fields) == 0 {
+			return nil, fmt.Errorf("entity %q needs at least one field", entity.FileName)
+		}
+		for _, field := range entity.Fields {
+			entity.HasTime = entity.HasTime || field.Type == "time"
+		}
+	}
+
+	ordered, err := dependencyOrder(module.Entities)
+	if err != nil {
+		return nil, err
+	}
+	module.Entities = ordered
+	for i := len(ordered) - 1; i >= 0; i-- {
+		module.DropTables = append(module.DropTables, ordered[i].Plural)
+	}
+	return module, nil
+}
+
+// addRelation adds the foreign key and the Ent edges of a relation declared by entity from
+func addRelation(entities map[string]*schemaEntity, from string, relation relationSchema) error {
+	target, ok := entities[relation.Entity]
+	if !ok {
+		return fmt.Errorf("relation %q of entity %q refers to unknown entity %q", relation.Name, from, relation.Entity)
+	}
+	if relation.Entity == from {
+		return fmt.Errorf("relation %q of entity %q refers to the entity itself, which is not supported", relation.Name, from)
+	}
+	if !filterNamePattern.MatchString(relation.Name) {
+		return fmt.Errorf("invalid relation name %q of entity %q", relation.Name, from)
+	}
+
+	// owner holds the foreign key referencing referenced; edge is the name of the relation
+	// seen from owner, and inverse seen from referenced
+	owner, referenced := entities[from], target
+	edge, inverse := relation.Name, relation.Inverse
+	switch relation.Type {
+	case "belongs_to", "":
+		if inverse == "" {
+			inverse = owner.Plural
+		}
+	case "has_many":
+		owner, referenced = target, entities[from]
+		edge, inverse = inverse, relation.Name
+		if edge == "" {
+			edge = from
+		}
+	default:
+		return fmt.Errorf("relation %q of entity %q has unknown type %q; use belongs_to or has_many", relation.Name, from, relation.Type)
+	}
+
+	key := edge + "_id"
+	if owner.field(key) != nil {
+		return fmt.Errorf("entity %q already has a field %q for relation %q", owner.FileName, key, relation.Name)
+	}
+	field, err := newCrudField(key, "string", owner.EntityNameLower, len(owner.Fields))
+	if err != nil {
+		return fmt.Errorf("relation %q of entity %q: %w", relation.Name, from, err)
+	}
+	field.SQLType = "VARCHAR(36)"
+	field.Operators = "[]query.Operator{query.Eq, query.Ne, query.In}"
+	field.References = referenced.Plural
+	owner.Fields = append(owner.Fields, field)
+
+	owner.Edges = append(owner.Edges, fmt.Sprintf("edge.From(%q, %s.Type).Ref(%q).Field(%q).Unique().Required()", edge, referenced.EntityName, inverse, key))
+	referenced.Edges = append(referenced.Edges, fmt.Sprintf("edge.To(%q, %s.Type)", inverse, owner.EntityName))
+	return nil
+}
+
+// addIndexes adds the Ent indexes and CREATE INDEX statements of an entity
+func addIndexes(entity *schemaEntity, indexes []indexSchema) error {
+	for _, index := range indexes {
+		if len(index.Fields) == 0 {
+			return fmt.Errorf("an index needs at least one field")
+		}
+		for _, name := range index.Fields {
+			if entity.field(name) == nil && !slices.Contains([]string{"id", "created_at", "updated_at"}, name) {
+				return fmt.Errorf("index field %q is not a field", name)
+			}
+		}
+
+		quoted := make([]string, len(index.Fields))
+		for i, name := range index.Fields {
+			quoted[i] = fmt.Sprintf("%q", name)
+		}
+		expression := "index.Fields(" + strings.Join(quoted, ", ") + ")"
+		statement := "CREATE INDEX idx_"
+		if index.Unique {
+			expression += ".Unique()"
+			statement = "CREATE UNIQUE INDEX uq_"
+		}
+		entity.Indexes = append(entity.Indexes, expression)
+		entity.SQL = append(entity.SQL, fmt.Sprintf("%s%s_%s ON %s (%s);", statement, entity.Plural,
+			strings.Join(index.Fields, "_"), entity.Plural, strings.Join(index.Fields, ", ")))
+	}
+	return nil
+}
+
+// addForeignKeyIndexes adds the CREATE INDEX statements of the foreign keys of an entity that no
+// declared index starts with, which the validator requires
+func addForeignKeyIndexes(entity *schemaEntity, indexes []indexSchema) {
+	for _, field := range entity.Fields {
+		if field.References == "" || field.Unique || slices.ContainsFunc(indexes, func(index indexSchema) bool {
+			return index.Fields[0] == field.Key
+		}) {
+			continue
+		}
+		entity.SQL = append(entity.SQL, fmt.Sprintf("CREATE INDEX idx_%s_%s ON %s (%s);", entity.Plural, field.Key, entity.Plural, field.Key))
+	}
+}
+
+// field returns the field of an entity with a snake_case name, if any
+func (e *schemaEntity) field(key string) *crudField {
+	for i := range e.Fields {
+		if e.Fields[i].Key == key {
+			return &e.Fields[i]
+		}
+	}
+	return nil
+}
+
+// dependencyOrder orders entities so that tables are created after the tables their
+// foreign keys reference
+func dependencyOrder(entities []*schemaEntity) ([]*schemaEntity, error) {
+	byTable := make(map[string]*schemaEntity, len(entities))
+	for _, entity := range entities {
+		byTable[entity.Plural] = entity
+	}
+
+	var ordered []*schemaEntity
+	state := make(map[*schemaEntity]int) // 1 while visiting, 2 once ordered
+	var visit func(entity *schemaEntity) error
+	visit = func(entity *schemaEntity) error {
+		switch state[entity] {
+		case 1:
+			return fmt.Errorf("the relations of entity %q form a cycle; declare one of them without a foreign key", entity.FileName)
+		case 2:
+			return nil
+		}
+		state[entity] = 1
+		for _, field := range entity.Fields {
+			if field.References != "" {
+				if err := visit(byTable[field.References]); err != nil {
+					return err
+				}
+			}
+		}
+		state[entity] = 2
+		ordered = append(ordered, entity)
+		return nil
+	}
+	for _, entity := range entities {
+		if err := visit(entity); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// generateModuleFromSchema generates the entities, repositories, Ent schemas and migration
+// of the module declared by a schema file
+func generateModuleFromSchema(path, name string, target generationTarget) error {
+	module, err := loadModuleSchema(path, name, target)
+	if err != nil {
+		return err
+	}
+	modulePath, _ := target.module(module.ModuleName)
+	if _, err := os.Stat(modulePath); err == nil {
+		return fmt.Errorf("%s already exists", modulePath)
+	}
+	fmt.Printf("Generating module %s from %s\n", module.ModuleName, path)
+
+	entityPath := filepath.Join(modulePath, "entity")
+	repositoryPath := filepath.Join(modulePath, "repository")
+	persistencePath := filepath.Join(modulePath, "infrastructure", "persistence")
+	entSchemaPath := filepath.Join(modulePath, "ent", "schema")
+	migrationsPath := "migrations"
+	for _, dir := range []string{entityPath, repositoryPath, persistencePath, entSchemaPath, migrationsPath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	for _, entity := range module.Entities {
+		generateFile(crudEntityTemplate, filepath.Join(entityPath, entity.FileName+".go"), entity)
+		generateFile(crudRepositoryTemplate, filepath.Join(repositoryPath, entity.FileName+"_repository.go"), entity)
+		generateFile(crudMemoryRepositoryTemplate, filepath.Join(persistencePath, entity.FileName+"_memory_repository.go"), entity)
+		generateFile(entSchemaTemplate, filepath.Join(entSchemaPath, entity.FileName+".go"), entity)
+	}
+	generateFile(entGenerateTemplate, filepath.Join(modulePath, "ent", "generate.go"), module)
+	generateFile(schemaModuleTemplate, filepath.Join(modulePath, "module.go"), module)
+
+	migration := filepath.Join(migrationsPath, time.Now().Format("20060102150405")+"_"+module.MigrationTitle)
+	generateFile(migrationUpTemplate, migration+".up.sql", module)
+	generateFile(migrationDownTemplate, migration+".down.sql", module)
+
+	fmt.Printf("\nModule %s generated successfully in %s\n", module.ModuleName, modulePath)
+	fmt.Println("\nRemember to:")
+	fmt.Printf("1. Add %s.Module to your application.\n", module.ModuleName)
+	fmt.Println("2. Add entgo.io/ent to go.mod and run go generate ./" + filepath.ToSlash(filepath.Join(modulePath, "ent")) + " to generate the Ent client.")
+	fmt.Printf("3. Apply the migration with axiomod migrate up.\n")
+	return nil
+}
+
+const entSchemaTemplate = `package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+{{- if .Edges}}
+	"entgo.io/ent/schema/edge"
+{{- end}}
+	"entgo.io/ent/schema/field"
+{{- if .Indexes}}
+	"entgo.io/ent/schema/index"
+{{- end}}
+)
+
+// {{.EntityName}} holds the Ent schema of the {{.EntityName}} entity of the {{.ModuleName}} module.
+type {{.EntityName}} struct {
+	ent.Schema
+}
+
+// Fields of the {{.EntityName}}.
+func ({{.EntityName}}) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").Immutable(),
+{{- range .Fields}}
+		field.{{.EntType}}("{{.Key}}"){{if .Unique}}.Unique(){{end}},
+{{- end}}
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+{{- if .Edges}}
+
+// Edges of the {{.EntityName}}.
+func ({{.EntityName}}) Edges() []ent.Edge {
+	return []ent.Edge{
+{{- range .Edges}}
+		{{.}},
+{{- end}}
+	}
+}
+{{- end}}
+{{- if .Indexes}}
+
+// Indexes of the {{.EntityName}}.
+func ({{.EntityName}}) Indexes() []ent.Index {
+	return []ent.Index{
+{{- range .Indexes}}
+		{{.}},
+{{- end}}
+	}
+}
+{{- end}}
+
+// Annotations of the {{.EntityName}}, naming its table as the migration does.
+func ({{.EntityName}}) Annotations() []schema.Annotation {
+	return []schema.Annotation{entsql.Annotation{Table: "{{.Plural}}"}}
+}
+`
+
+const entGenerateTemplate = `// Package ent holds the Ent schemas of the {{.ModuleName}} module, generated from {{.SchemaFile}}.
+package ent
+
+//go:generate go run -mod=mod entgo.io/ent/cmd/ent generate ./schema
+`
+
+const schemaModuleTemplate = `package {{.ModuleName}}
+
+import (
+	"go.uber.org/fx"
+
+	"{{.ImportPath}}/infrastructure/persistence"
+	"{{.ImportPath}}/repository"
+)
+
+// Module provides the repositories of the {{.ModuleName}} module, generated from {{.SchemaFile}}.
+// They keep entities in memory; provide implementations over the Ent client or the tables of
+// the migration instead to store them in the database.
+var Module = fx.Module(
+	"{{.ModuleName}}",
+	fx.Provide(
+{{- range .Entities}}
+		fx.Annotate(
+			persistence.NewInMemory{{.EntityName}}Repository,
+			fx.As(new(repository.{{.EntityName}}Repository)),
+		),
+{{- end}}
+	),
+)
+`
+
+const migrationUpTemplate = `-- Migration: {{.MigrationTitle}} (up)
+-- Tables of the {{.ModuleName}} module, generated from {{.SchemaFile}}
+{{- range .Entities}}
+
+CREATE TABLE {{.Plural}} (
+    id VARCHAR(36) PRIMARY KEY,
+{{- range .Fields}}
+    {{.Key}} {{.SQLType}} NOT NULL{{if .Unique}} UNIQUE{{end}}{{if .References}} REFERENCES {{.References}} (id){{end}},
+{{- end}}
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+{{- range .SQL}}
+{{.}}
+{{- end}}
+{{- end}}
+`
+
+const migrationDownTemplate = `-- Migration: {{.MigrationTitle}} (down)
+{{- range .DropTables}}
+DROP TABLE IF EXISTS {{.}};
+{{- end}}
+`
@@ -0,0 +1,152 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schemaTarget = generationTarget{Dir: "internal", ImportPath: "example.com/shop/internal", ModulePath: "example.com/shop", Root: "."}
+
+// loadSchema loads a schema file with content for schemaTarget
+func loadSchema(t *testing.T, content string) (*schemaModule, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return loadModuleSchema(path, "", schemaTarget)
+}
+
+// schemaFieldKeys returns the keys of the fields of an entity
+func schemaFieldKeys(entity *schemaEntity) []string {
+	var keys []string
+	for _, field := range entity.Fields {
+		keys = append(keys, field.Key)
+	}
+	return keys
+}
+
+func TestLoadModuleSchema(t *testing.T) {
+	// Entities are declared before the entities their foreign keys reference, and get their edges
+	// in the order of the relations
+	module, err := loadSchema(t, `module: shop
+entities:
+  - name: order_item
+    fields:
+      - {name: quantity, type: int}
+    relations:
+      - {name: order, entity: order}
+  - name: order
+    fields:
+      - {name: status, type: string}
+      - {name: total, type: float}
+      - {name: placed, type: time}
+    indexes:
+      - {fields: [status, created_at]}
+  - name: customer
+    fields:
+      - {name: email, type: string, unique: true}
+    relations:
+      - {name: orders, type: has_many, entity: order}
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "shop", module.ModuleName)
+	assert.Equal(t, "example.com/shop/internal/shop", module.ImportPath)
+	assert.Equal(t, "schema.yaml", module.SchemaFile)
+	assert.Equal(t, "create_shop_tables", module.MigrationTitle)
+	assert.Equal(t, []string{"order_items", "orders", "customers"}, module.DropTables)
+	require.Len(t, module.Entities, 3)
+	customer, order, item := module.Entities[0], module.Entities[1], module.Entities[2]
+
+	assert.Equal(t, "Customer", customer.EntityName)
+	assert.Equal(t, []string{"email"}, schemaFieldKeys(customer))
+	assert.True(t, customer.Fields[0].Unique)
+	assert.Equal(t, []string{`edge.To("orders", Order.Type)`}, customer.Edges)
+	assert.False(t, customer.HasTime)
+
+	assert.Equal(t, "Order", order.EntityName)
+	assert.Equal(t, "order", order.FileName)
+	assert.Equal(t, []string{"status", "total", "placed", "customer_id"}, schemaFieldKeys(order))
+	assert.Equal(t, "float64", order.Fields[1].GoType)
+	assert.True(t, order.HasTime)
+	assert.Equal(t, "customers", order.Fields[3].References)
+	assert.Equal(t, "VARCHAR(36)", order.Fields[3].SQLType)
+	assert.Equal(t, []string{
+		`edge.To("order_items", OrderItem.Type)`,
+		`edge.From("customer", Customer.Type).Ref("orders").Field("customer_id").Unique().Required()`,
+	}, order.Edges)
+	assert.Equal(t, []string{`index.Fields("status", "created_at")`}, order.Indexes)
+	assert.Equal(t, []string{
+		"CREATE INDEX idx_orders_status_created_at ON orders (status, created_at);",
+		"CREATE INDEX idx_orders_customer_id ON orders (customer_id);",
+	}, order.SQL)
+
+	assert.Equal(t, "OrderItem", item.EntityName)
+	assert.Equal(t, "orderItem", item.EntityNameLower)
+	assert.Equal(t, "order_item", item.FileName)
+	assert.Equal(t, []string{"quantity", "order_id"}, schemaFieldKeys(item))
+	assert.Equal(t, "orders", item.Fields[1].References)
+}
+
+func TestLoadModuleSchemaErrors(t *testing.T) {
+	tests := map[string]struct {
+		schema string
+		err    string
+	}{
+		"missing module name": {
+			schema: "entities:\n  - name: order\n    fields: [{name: status, type: string}]\n",
+			err:    `module name "" must be a lowercase word`,
+		},
+		"unknown key": {
+			schema: "module: shop\ntables: []\n",
+			err:    "field tables not found",
+		},
+		"no entities": {
+			schema: "module: shop\n",
+			err:    "declares no entities",
+		},
+		"entity declared twice": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: string}]\n  - name: order\n    fields: [{name: status, type: string}]\n",
+			err:    `entity "order" is declared twice`,
+		},
+		"entity without fields": {
+			schema: "module: shop\nentities:\n  - name: order\n",
+			err:    `entity "order" needs at least one field`,
+		},
+		"unknown field type": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: enum}]\n",
+			err:    `entity "order"`,
+		},
+		"relation to an unknown entity": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: string}]\n    relations: [{name: customer, entity: customer}]\n",
+			err:    `refers to unknown entity "customer"`,
+		},
+		"relation to the entity itself": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: string}]\n    relations: [{name: parent, entity: order}]\n",
+			err:    "refers to the entity itself",
+		},
+		"unknown relation type": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: string}]\n  - name: customer\n    fields: [{name: email, type: string}]\n    relations: [{name: orders, type: many_to_many, entity: order}]\n",
+			err:    `unknown type "many_to_many"`,
+		},
+		"index on an unknown field": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: string}]\n    indexes: [{fields: [total]}]\n",
+			err:    `index field "total" is not a field`,
+		},
+		"cycle of relations": {
+			schema: "module: shop\nentities:\n  - name: order\n    fields: [{name: status, type: string}]\n    relations: [{name: customer, entity: customer}]\n  - name: customer\n    fields: [{name: email, type: string}]\n    relations: [{name: last_order, entity: order}]\n",
+			err:    "form a cycle",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadSchema(t, tt.schema)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,890 @@
+package generate
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// generateServerCmd represents the generate server command
+var generateServerCmd = &cobra.Command{
+	Use:   "server --spec=[file]",
+	Short: "Generate the HTTP handlers of an OpenAPI document",
+	Long: `Generate the HTTP server of an API from its OpenAPI 3.0 or 3.1 document, for
+API-first development.
+
+The code goes to the delivery/http package of the module:
+
+  - api.gen.go, regenerated on every run: the models of the schemas, a request type per
+    operation with its parameters and body, the APIHandler interface with one method per
+    operation, and APIRoutes, registering the operations on the Fiber router. The routes
+    bind the parameters and the JSON body of the requests, validate them against the
+    constraints of the document and write the response of the first 2xx status, or the
+    errors of the handler as problem documents.
+  - api.go, generated once: API, implementing APIHandler with stubs, and APIModule, its fx
+    wiring under the path of the first server of the document, or --prefix.
+
+Schemas referenced with $ref must be components of the document.
+
+Example:
+  axiomod generate server --spec=openapi.yaml --module=catalog
+  axiomod generate server --spec=api/openapi.json --module=catalog --prefix=/api/v1
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		specPath, _ := cmd.Flags().GetString("spec")
+		moduleName, _ := cmd.Flags().GetString("module")
+		if !crudNamePattern.MatchString(moduleName) {
+			fmt.Println("Error: module must be a lowercase word, such as catalog")
+			os.Exit(1)
+		}
+		spec, err := loadServerSpec(specPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		prefix, _ := cmd.Flags().GetString("prefix")
+		if !cmd.Flags().Changed("prefix") {
+			prefix = serverPrefix(spec)
+		}
+		api, err := newServerAPI(spec, filepath.Base(specPath), prefix)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, _ := target.module(moduleName)
+		httpPath := filepath.Join(modulePath, "delivery", "http")
+		if err := os.MkdirAll(httpPath, 0755); err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", httpPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generating server: %d operations of %s\n", len(api.Operations), specPath)
+
+		generateFile(serverAPITemplate, filepath.Join(httpPath, "api.gen.go"), api)
+		stubFile := filepath.Join(httpPath, "api.go")
+		stubExists := false
+		if _, err := os.Stat(stubFile); err == nil {
+			stubExists = true
+			fmt.Printf("Kept file: %s\n", stubFile)
+		} else {
+			generateFile(serverStubTemplate, stubFile, api)
+		}
+		for _, warning := range api.Warnings {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+
+		fmt.Printf("\nServer generated successfully in %s\n", httpPath)
+		fmt.Println("\nRemember to:")
+		if stubExists {
+			fmt.Println("1. Add the methods of new operations to API in api.go; the build lists the missing ones.")
+		} else {
+			fmt.Println("1. Implement the operations in api.go.")
+		}
+		fmt.Println("2. Add http.APIModule to your application.")
+		fmt.Println("3. Run the generator again after changing the document; it only rewrites api.gen.go.")
+	},
+}
+
+// serverAPI is the template data of generate server
+type serverAPI struct {
+	Spec       string             // file name of the document
+	Prefix     string             // path the routes are registered under
+	Time       bool               // whether the models use time.Time
+	Models     []*serverModel     // in the order of the document
+	Operations []*serverOperation // in the order of the document
+	Routes     []*serverOperation // in registration order, static segments first
+	Warnings   []string
+
+	spec       *serverSpec
+	types      map[string]bool // Go types declared
+	schemas    map[string]string
+	references map[string]bool // declared types that are slices, maps or interfaces
+	structs    map[string]bool // declared struct types, whose fields are validated
+}
+
+// serverModel is a type declared by the generated code
+type serverModel struct {
+	Name   string
+	Doc    []string
+	Type   string // underlying type, or alias target; empty for structs
+	Alias  bool
+	Fields []serverField
+	Enum   []serverEnum
+}
+
+type serverField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+type serverEnum struct {
+	Name  string
+	Value string
+}
+
+// serverOperation is an operation of the document, served by a method of APIHandler
+type serverOperation struct {
+	Name            string // method of APIHandler, e.g. GetProduct
+	Method          string // HTTP method
+	Register        string // method of fiber.Router registering the route
+	Path            string // path of the document
+	Route           string // Fiber route
+	Doc             []string
+	Request         string // request type; empty without parameters nor body
+	PathParams      bool
+	Query           bool
+	Headers         bool
+	Cookies         bool
+	Body            bool // whether the JSON body is bound to Request.Body
+	BodyRequired    bool
+	Status          string // status of the response, e.g. fiber.StatusCreated
+	Response        string // Go type of the JSON response; empty without
+	ResponseContent string // content type of a response written by the handler
+}
+
+// StubTime reports whether the stubs of the operations use time.Time
+func (a *serverAPI) StubTime() bool {
+	for _, operation := range a.Operations {
+		if strings.Contains(operation.Response, "time.") {
+			return true
+		}
+	}
+	return false
+}
+
+// Params returns the parameters of the method of APIHandler
+func (o *serverOperation) Params() string {
+	if o.Request == "" {
+		return "c *fiber.Ctx"
+	}
+	return "c *fiber.Ctx, req " + o.Request
+}
+
+// Args returns the arguments the routes call the method of APIHandler with
+func (o *serverOperation) Args() string {
+	if o.Request == "" {
+		return "c"
+	}
+	return "c, req"
+}
+
+// Results returns the results of the method of APIHandler, named for the stubs
+func (o *serverOperation) Results(named bool) string {
+	switch {
+	case o.Response == "":
+		return "error"
+	case named:
+		return "(resp " + o.Response + ", err error)"
+	default:
+		return "(" + o.Response + ", error)"
+	}
+}
+
+// successStatuses are the Fiber constants of the 2xx statuses
+var successStatuses = map[int]string{
+	200: "fiber.StatusOK",
+	201: "fiber.StatusCreated",
+	202: "fiber.StatusAccepted",
+	203: "fiber.StatusNonAuthoritativeInformation",
+	204: "fiber.StatusNoContent",
+	205: "fiber.StatusResetContent",
+	206: "fiber.StatusPartialContent",
+	207: "fiber.StatusMultiStatus",
+	208: "fiber.StatusAlreadyReported",
+	226: "fiber.StatusIMUsed",
+}
+
+// serverPrefix returns the path of the first server of the document, e.g. /v1 for
+// https://api.example.com/v1
+func serverPrefix(spec *serverSpec) string {
+	if len(spec.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(spec.Servers[0].URL)
+	if err != nil || strings.Contains(u.Path, "{") {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// newServerAPI returns the template data of the operations and schemas of a document
+func newServerAPI(spec *serverSpec, specFile, prefix string) (*serverAPI, error) {
+	api := &serverAPI{
+		Spec:       specFile,
+		Prefix:     prefix,
+		spec:       spec,
+		types:      map[string]bool{"API": true, "APIHandler": true, "APIRoutes": true},
+		schemas:    make(map[string]string),
+		references: make(map[string]bool),
+		structs:    make(map[string]bool),
+	}
+	for _, entry := range spec.Components.Schemas {
+		name := api.typeName(goIdentifier(entry.Key))
+		api.schemas[entry.Key] = name
+		api.structs[name] = entry.Value.Ref == "" && isObject(entry.Value)
+	}
+	for _, entry := range spec.Components.Schemas {
+		if err := api.component(entry.Key, entry.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	methods := make(map[string]bool)
+	for _, entry := range spec.Paths {
+		for _, op := range entry.Value.operations() {
+			operation, err := api.operation(entry.Key, entry.Value, op.Method, op.Operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Method, entry.Key, err)
+			}
+			if methods[operation.Name] {
+				return nil, fmt.Errorf("%s %s: operation %s is declared twice; give the operations unique operationIds", op.Method, entry.Key, operation.Name)
+			}
+			methods[operation.Name] = true
+			api.Operations = append(api.Operations, operation)
+		}
+	}
+	if len(api.Operations) == 0 {
+		return nil, fmt.Errorf("the document declares no operations")
+	}
+
+	api.Routes = slices.Clone(api.Operations)
+	slices.SortStableFunc(api.Routes, func(a, b *serverOperation) int {
+		return strings.Compare(routeShape(a.Route), routeShape(b.Route))
+	})
+	return api, nil
+}
+
+// routeShape returns the kinds of the segments of a route, 0 for static segments and 1 for
+// parameters, so that routes sorted by shape register /products/new before /products/:id
+func routeShape(route string) string {
+	var shape strings.Builder
+	for _, segment := range strings.Split(strings.Trim(route, "/"), "/") {
+		if strings.Contains(segment, ":") {
+			shape.WriteByte('1')
+		} else {
+			shape.WriteByte('0')
+		}
+	}
+	return shape.String()
+}
+
+// typeName reserves a Go type name, numbering it when it is taken
+func (a *serverAPI) typeName(name string) string {
+	unique := name
+	for i := 2; a.types[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	a.types[unique] = true
+	return unique
+}
+
+// component declares the model of a schema of the components
+func (a *serverAPI) component(key string, schema *specSchema) error {
+	name := a.schemas[key]
+	model := &serverModel{Name: name, Doc: []string{fmt.Sprintf("%s is the %s schema of %s.", name, key, a.Spec)}}
+	if schema.Description != "" {
+		model.Doc = append(model.Doc, docLine(schema.Description))
+	}
+
+	switch {
+	case schema.Ref != "":
+		target, err := a.goType(schema, name)
+		if err != nil {
+			return err
+		}
+		model.Type, model.Alias = target, true
+		a.references[name] = a.references[target]
+		a.structs[name] = a.structs[target]
+	case isObject(schema):
+		a.Models = append(a.Models, model)
+		return a.structFields(model, schema)
+	case schema.typeName() == "string" && len(schema.Enum) > 0 && len(schema.OneOf)+len(schema.AnyOf) == 0:
+		model.Type = "string"
+		for _, value := range schema.Enum {
+			s, ok := value.(string)
+			if !ok {
+				continue
+			}
+			constant := name + goIdentifier(s)
+			if s == "" || constant == name {
+				constant = name + "Empty"
+			}
+			model.Enum = append(model.Enum, serverEnum{Name: a.typeName(constant), Value: strconv.Quote(s)})
+		}
+	default:
+		underlying, err := a.goType(schema, name+"Item")
+		if err != nil {
+			return err
+		}
+		model.Type = underlying
+		a.references[name] = isReferenceType(underlying)
+		if underlying == "any" {
+			model.Alias = true
+		}
+	}
+	a.Models = append(a.Models, model)
+	return nil
+}
+
+// structFields adds the properties of an object schema to a struct model. The components
+// its allOf refers to are embedded.
+func (a *serverAPI) structFields(model *serverModel, schema *specSchema) error {
+	for _, part := range schema.AllOf {
+		if part.Ref != "" {
+			embedded, err := a.goType(part, "")
+			if err != nil {
+				return err
+			}
+			model.Fields = append(model.Fields, serverField{Type: embedded})
+			continue
+		}
+		if err := a.structFields(model, part); err != nil {
+			return err
+		}
+	}
+	for _, property := range schema.Properties {
+		field, err := a.field(model.Name, property.Key, property.Value, slices.Contains(schema.Required, property.Key))
+		if err != nil {
+			return fmt.Errorf("property %s of %s: %w", property.Key, model.Name, err)
+		}
+		model.Fields = append(model.Fields, field)
+	}
+	return nil
+}
+
+// field returns the struct field of a property. Properties are pointers, unless their type
+// is a slice, map or interface, so that a required property missing from a body fails its
+// required rule instead of reading as its zero value. Required nullable properties may be
+// null, which reads as missing, so they are not checked.
+func (a *serverAPI) field(parent, key string, schema *specSchema, required bool) (serverField, error) {
+	name := goIdentifier(key)
+	t, err := a.goType(schema, parent+name)
+	if err != nil {
+		return serverField{}, err
+	}
+	if !a.isReference(t) {
+		t = "*" + t
+	}
+	tag := `json:"` + key + `"`
+	if !required {
+		tag = `json:"` + key + `,omitempty"`
+	}
+	checked := required && !schema.nullable()
+	if rules := a.validateRules(schema, t, checked); rules != "" {
+		if !checked {
+			rules = "omitempty," + rules
+		}
+		tag += ` validate:"` + rules + `"`
+	}
+	return serverField{Name: name, Type: t, Tag: tag, Doc: docLine(schema.Description)}, nil
+}
+
+// goType returns the Go type of a schema. Inline objects are declared as structs named
+// name.
+func (a *serverAPI) goType(schema *specSchema, name string) (string, error) {
+	if schema == nil {
+		return "any", nil
+	}
+	if schema.Ref != "" {
+		key, err := componentRef(schema.Ref, "schemas")
+		if err != nil {
+			return "", err
+		}
+		target, ok := a.schemas[key]
+		if !ok {
+			return "", fmt.Errorf("schema %s is not declared in the components", key)
+		}
+		return target, nil
+	}
+	if len(schema.OneOf)+len(schema.AnyOf) > 0 {
+		return "any", nil
+	}
+
+	switch schema.typeName() {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			a.Time = true
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		if schema.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := a.goType(schema.Items, name+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		if isObject(schema) {
+			model := &serverModel{Name: a.typeName(name)}
+			a.structs[model.Name] = true
+			model.Doc = []string{model.Name + " is an inline object of " + a.Spec + "."}
+			if schema.Description != "" {
+				model.Doc = append(model.Doc, docLine(schema.Description))
+			}
+			a.Models = append(a.Models, model)
+			return model.Name, a.structFields(model, schema)
+		}
+		if additional := schema.AdditionalProperties; additional != nil && additional.Schema != nil {
+			value, err := a.goType(additional.Schema, name+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + value, nil
+		}
+		return "map[string]any", nil
+	}
+	return "any", nil
+}
+
+// isReference reports whether a Go type is nil-able without a pointer
+func (a *serverAPI) isReference(t string) bool {
+	return isReferenceType(t) || a.references[t]
+}
+
+func isReferenceType(t string) bool {
+	return t == "any" || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") || strings.HasPrefix(t, "*")
+}
+
+// validateRules returns the validate tag of the constraints of a schema
+func (a *serverAPI) validateRules(schema *specSchema, t string, required bool) string {
+	var rules []string
+	if required {
+		rules = append(rules, "required")
+	}
+	if schema == nil || schema.Ref != "" {
+		return strings.Join(rules, ",")
+	}
+	bound := func(name string, n float64) {
+		rules = append(rules, name+"="+strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	count := func(name string, n *int) {
+		if n != nil {
+			rules = append(rules, name+"="+strconv.Itoa(*n))
+		}
+	}
+
+	switch schema.typeName() {
+	case "string":
+		if t == "time.Time" || t == "*time.Time" {
+			break
+		}
+		count("min", schema.MinLength)
+		count("max", schema.MaxLength)
+		switch schema.Format {
+		case "email":
+			rules = append(rules, "email")
+		case "uuid":
+			rules = append(rules, "uuid")
+		case "uri":
+			rules = append(rules, "uri")
+		}
+		if values := enumValues(schema.Enum); values != "" {
+			rules = append(rules, "oneof="+values)
+		}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			if schema.ExclusiveMinimum.Exclusive && schema.ExclusiveMinimum.Value == nil {
+				bound("gt", *schema.Minimum)
+			} else {
+				bound("min", *schema.Minimum)
+			}
+		}
+		if schema.ExclusiveMinimum.Value != nil {
+			bound("gt", *schema.ExclusiveMinimum.Value)
+		}
+		if schema.Maximum != nil {
+			if schema.ExclusiveMaximum.Exclusive && schema.ExclusiveMaximum.Value == nil {
+				bound("lt", *schema.Maximum)
+			} else {
+				bound("max", *schema.Maximum)
+			}
+		}
+		if schema.ExclusiveMaximum.Value != nil {
+			bound("lt", *schema.ExclusiveMaximum.Value)
+		}
+	case "array":
+		count("min", schema.MinItems)
+		count("max", schema.MaxItems)
+		if item := strings.TrimPrefix(strings.TrimPrefix(t, "*"), "[]"); a.isStruct(item) {
+			rules = append(rules, "dive")
+		}
+	}
+	return strings.Join(rules, ",")
+}
+
+// isStruct reports whether t is a struct model, whose fields are validated
+func (a *serverAPI) isStruct(t string) bool {
+	return a.structs[t]
+}
+
+// isObject reports whether a schema is modeled as a struct
+func isObject(schema *specSchema) bool {
+	return schema.typeName() == "object" && len(schema.OneOf)+len(schema.AnyOf) == 0 &&
+		(len(schema.Properties) > 0 || len(schema.AllOf) > 0)
+}
+
+// enumValues returns the values of a string enum for the oneof rule, or "" when they
+// cannot be expressed in it
+func enumValues(enum []any) string {
+	var values []string
+	for _, value := range enum {
+		s, ok := value.(string)
+		if !ok || s == "" || strings.ContainsAny(s, " ,|'\"") {
+			return ""
+		}
+		values = append(values, s)
+	}
+	return strings.Join(values, " ")
+}
+
+// operation returns the operation of a path item
+func (a *serverAPI) operation(path string, item *specPathItem, method string, op *specOperation) (*serverOperation, error) {
+	name := goIdentifier(op.OperationID)
+	if name == "" {
+		name = operationName(method, path)
+	}
+	operation := &serverOperation{
+		Name:     name,
+		Method:   method,
+		Register: strings.ToUpper(method[:1]) + strings.ToLower(method[1:]),
+		Path:     path,
+		Route:    fiberRoute(path),
+	}
+	summary := docLine(op.Summary)
+	if summary == "" {
+		summary = docLine(op.Description)
+	} else if op.Description != "" {
+		operation.Doc = append(operation.Doc, docLine(op.Description))
+	}
+	first := fmt.Sprintf("%s handles %s %s", name, method, path)
+	if summary != "" {
+		first += ": " + summary
+	}
+	operation.Doc = append([]string{first}, operation.Doc...)
+
+	request := &serverModel{Name: name + "Request", Doc: []string{name + "Request is the request of " + name + "."}}
+	params, err := a.parameters(item.Parameters, op.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range params {
+		field, err := a.parameterField(operation, request.Name, param)
+		if err != nil {
+			return nil, err
+		}
+		if field.Name == "" {
+			continue
+		}
+		request.Fields = append(request.Fields, field)
+	}
+	if err := a.requestBody(operation, request, op.RequestBody); err != nil {
+		return nil, err
+	}
+	if len(request.Fields) > 0 {
+		request.Name = a.typeName(request.Name)
+		operation.Request = request.Name
+		a.Models = append(a.Models, request)
+	}
+
+	if err := a.response(operation, op.Responses); err != nil {
+		return nil, err
+	}
+	if op.Deprecated {
+		operation.Doc = append(operation.Doc, "", "Deprecated: the operation is deprecated by "+a.Spec+".")
+	}
+	return operation, nil
+}
+
+// parameters returns the parameters of an operation, including those of its path item it
+// does not override
+func (a *serverAPI) parameters(pathParams, opParams []*specParameter) ([]*specParameter, error) {
+	var params []*specParameter
+	seen := make(map[string]bool)
+	for _, list := range [][]*specParameter{opParams, pathParams} {
+		for _, param := range list {
+			if param.Ref != "" {
+				key, err := componentRef(param.Ref, "parameters")
+				if err != nil {
+					return nil, err
+				}
+				resolved, ok := a.spec.Components.Parameters[key]
+				if !ok {
+					return nil, fmt.Errorf("parameter %s is not declared in the components", key)
+				}
+				param = resolved
+			}
+			if seen[param.In+":"+param.Name] {
+				continue
+			}
+			seen[param.In+":"+param.Name] = true
+			params = append(params, param)
+		}
+	}
+	return params, nil
+}
+
+// parameterTags are the struct tags Fiber binds the parameters of each location with
+var parameterTags = map[string]string{"path": "params", "query": "query", "header": "reqHeader", "cookie": "cookie"}
+
+// parameterField returns the field of the request type binding a parameter
+func (a *serverAPI) parameterField(operation *serverOperation, parent string, param *specParameter) (serverField, error) {
+	tag, ok := parameterTags[param.In]
+	if !ok {
+		return serverField{}, fmt.Errorf("parameter %s is in %q; expected path, query, header or cookie", param.Name, param.In)
+	}
+	switch param.In {
+	case "path":
+		operation.PathParams = true
+	case "query":
+		operation.Query = true
+	case "header":
+		operation.Headers = true
+	case "cookie":
+		operation.Cookies = true
+	}
+
+	name := goIdentifier(param.Name)
+	t, err := a.goType(param.Schema, parent+name)
+	if err != nil {
+		return serverField{}, err
+	}
+	switch {
+	case t == "time.Time":
+		// Parameters are bound from their text, dates are parsed by the handler
+		t = "string"
+	case strings.HasPrefix(t, "[]") && !slices.Contains([]string{"[]string", "[]int", "[]int32", "[]int64", "[]float32", "[]float64", "[]bool"}, t),
+		strings.HasPrefix(t, "map["), t == "any", a.isStruct(t):
+		a.Warnings = append(a.Warnings, fmt.Sprintf("%s %s: parameter %s is bound as a string; decode it in %s", operation.Method, operation.Path, param.Name, operation.Name))
+		t = "string"
+	}
+
+	key := param.Name
+	if param.In == "path" {
+		key = routeParam(param.Name)
+	}
+	structTag := fmt.Sprintf(`%s:"%s" json:"%s"`, tag, key, param.Name)
+	required := param.Required && param.In != "path"
+	if rules := a.validateRules(param.Schema, t, required); rules != "" {
+		if !required && param.In != "path" {
+			rules = "omitempty," + rules
+		}
+		structTag += ` validate:"` + rules + `"`
+	}
+	return serverField{Name: name, Type: t, Tag: structTag, Doc: docLine(param.Description)}, nil
+}
+
+// requestBody adds the body of an operation to its request type. JSON bodies are bound to
+// its Body field; other content types are read by the handler.
+func (a *serverAPI) requestBody(operation *serverOperation, request *serverModel, body *specRequestBody) error {
+	if body == nil {
+		return nil
+	}
+	if body.Ref != "" {
+		key, err := componentRef(body.Ref, "requestBodies")
+		if err != nil {
+			return err
+		}
+		resolved, ok := a.spec.Components.RequestBodies[key]
+		if !ok {
+			return fmt.Errorf("request body %s is not declared in the components", key)
+		}
+		body = resolved
+	}
+	contentType, media := jsonContent(body.Content)
+	if media == nil {
+		if len(body.Content) > 0 {
+			operation.Doc = append(operation.Doc, "", "The "+body.Content[0].Key+" body of the request is read from c.")
+		}
+		return nil
+	}
+
+	t, err := a.goType(media.Schema, operation.Name+"Body")
+	if err != nil {
+		return err
+	}
+	tag := `params:"-" query:"-" reqHeader:"-" cookie:"-" json:"-"`
+	if rules := a.validateRules(media.Schema, t, false); rules != "" {
+		tag += ` validate:"` + rules + `"`
+	}
+	request.Fields = append(request.Fields, serverField{Name: "Body", Type: t, Tag: tag, Doc: "Body is the " + contentType + " body of the request"})
+	operation.Body = true
+	operation.BodyRequired = body.Required
+	return nil
+}
+
+// response sets the response of an operation: the lowest 2xx status of the document,
+// written as JSON when it has a JSON schema
+func (a *serverAPI) response(operation *serverOperation, responses orderedMap[*specResponse]) error {
+	status := 0
+	var selected *specResponse
+	for _, entry := range responses {
+		code, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(entry.Key), "XX", "00"))
+		if err != nil || code < 200 || code > 299 {
+			continue
+		}
+		if status == 0 || code < status {
+			status, selected = code, entry.Value
+		}
+	}
+	if status == 0 {
+		status = 200
+	}
+	operation.Status = successStatuses[status]
+	if operation.Status == "" {
+		operation.Status = strconv.Itoa(status)
+	}
+	if selected == nil {
+		return nil
+	}
+	if selected.Ref != "" {
+		key, err := componentRef(selected.Ref, "responses")
+		if err != nil {
+			return err
+		}
+		resolved, ok := a.spec.Components.Responses[key]
+		if !ok {
+			return fmt.Errorf("response %s is not declared in the components", key)
+		}
+		selected = resolved
+	}
+
+	_, media := jsonContent(selected.Content)
+	if media == nil {
+		if len(selected.Content) > 0 {
+			operation.ResponseContent = selected.Content[0].Key
+			operation.Doc = append(operation.Doc, "", "The handler writes the "+operation.ResponseContent+" response to c.")
+		}
+		return nil
+	}
+	t, err := a.goType(media.Schema, operation.Name+"Response")
+	if err != nil {
+		return err
+	}
+	if !a.isReference(t) && a.isStruct(t) {
+		t = "*" + t
+	}
+	operation.Response = t
+	return nil
+}
+
+// jsonContent returns the JSON media type of a body, e.g. application/json or
+// application/problem+json
+func jsonContent(content orderedMap[*specMediaType]) (string, *specMediaType) {
+	for _, entry := range content {
+		mediaType := strings.TrimSpace(strings.SplitN(entry.Key, ";", 2)[0])
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			if entry.Value == nil {
+				return mediaType, &specMediaType{}
+			}
+			return mediaType, entry.Value
+		}
+	}
+	return "", nil
+}
+
+// operationName returns the name of an operation without operationId, e.g. GetProductsByID
+// for GET /products/{id}
+func operationName(method, path string) string {
+	name := strings.ToUpper(method[:1]) + strings.ToLower(method[1:])
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name += "By" + goIdentifier(strings.Trim(segment, "{}"))
+			continue
+		}
+		name += goIdentifier(segment)
+	}
+	return name
+}
+
+// fiberRoute returns the Fiber route of a path, e.g. /products/:id for /products/{id}
+func fiberRoute(path string) string {
+	var route strings.Builder
+	for {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			route.WriteString(path)
+			return route.String()
+		}
+		route.WriteString(path[:start] + ":" + routeParam(path[start+1:end]))
+		path = path[end+1:]
+	}
+}
+
+// routeParam returns the Fiber name of a path parameter, which ends at characters other
+// than letters, digits and underscores
+func routeParam(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// goIdentifier returns the exported Go name of a name of the document, e.g. CustomerID for
+// customer_id or customer-id, and CreatedAt for createdAt
+func goIdentifier(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		switch strings.ToLower(part) {
+		case "id", "url", "uri", "api", "ip", "uuid", "http", "json", "html", "sql":
+			b.WriteString(strings.ToUpper(part))
+		default:
+			b.WriteString(exportedName(part))
+		}
+	}
+	identifier := b.String()
+	if identifier != "" && unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "N" + identifier
+	}
+	return identifier
+}
+
+// docLine returns a text of the document as one comment line
+func docLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func init() {
+	generateServerCmd.Flags().String("spec", "", "OpenAPI 3.0 or 3.1 document, in YAML or JSON (required)")
+	generateServerCmd.Flags().StringP("module", "m", "api", "Module to generate the server in")
+	generateServerCmd.Flags().String("prefix", "", "Path the routes are registered under (default: the path of the first server of the document)")
+	generateServerCmd.MarkFlagRequired("spec")
+	addTargetFlags(generateServerCmd)
+	generateCmd.AddCommand(generateServerCmd)
+}
@@ -0,0 +1,256 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serverSpec is the part of an OpenAPI 3.0 or 3.1 document read by generate server. JSON
+// documents are read as YAML.
+type serverSpec struct {
+	OpenAPI    string                    `yaml:"openapi"`
+	Servers    []openAPIServer           `yaml:"servers"`
+	Paths      orderedMap[*specPathItem] `yaml:"paths"`
+	Components specComponents            `yaml:"components"`
+}
+
+type specComponents struct {
+	Schemas       orderedMap[*specSchema]     `yaml:"schemas"`
+	Parameters    map[string]*specParameter   `yaml:"parameters"`
+	RequestBodies map[string]*specRequestBody `yaml:"requestBodies"`
+	Responses     map[string]*specResponse    `yaml:"responses"`
+}
+
+type specPathItem struct {
+	Parameters []*specParameter `yaml:"parameters"`
+	Get        *specOperation   `yaml:"get"`
+	Put        *specOperation   `yaml:"put"`
+	Post       *specOperation   `yaml:"post"`
+	Delete     *specOperation   `yaml:"delete"`
+	Options    *specOperation   `yaml:"options"`
+	Head       *specOperation   `yaml:"head"`
+	Patch      *specOperation   `yaml:"patch"`
+	Trace      *specOperation   `yaml:"trace"`
+}
+
+// specMethod is an operation of a path item with its HTTP method
+type specMethod struct {
+	Method    string
+	Operation *specOperation
+}
+
+// operations returns the operations of the path item, in the order of the OpenAPI
+// specification
+func (p *specPathItem) operations() []specMethod {
+	var operations []specMethod
+	for _, op := range []specMethod{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"OPTIONS", p.Options}, {"HEAD", p.Head}, {"PATCH", p.Patch}, {"TRACE", p.Trace},
+	} {
+		if op.Operation != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+type specOperation struct {
+	OperationID string                    `yaml:"operationId"`
+	Summary     string                    `yaml:"summary"`
+	Description string                    `yaml:"description"`
+	Deprecated  bool                      `yaml:"deprecated"`
+	Parameters  []*specParameter          `yaml:"parameters"`
+	RequestBody *specRequestBody          `yaml:"requestBody"`
+	Responses   orderedMap[*specResponse] `yaml:"responses"`
+}
+
+type specParameter struct {
+	Ref         string      `yaml:"$ref"`
+	Name        string      `yaml:"name"`
+	In          string      `yaml:"in"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Schema      *specSchema `yaml:"schema"`
+}
+
+type specRequestBody struct {
+	Ref         string                     `yaml:"$ref"`
+	Description string                     `yaml:"description"`
+	Required    bool                       `yaml:"required"`
+	Content     orderedMap[*specMediaType] `yaml:"content"`
+}
+
+type specResponse struct {
+	Ref         string                     `yaml:"$ref"`
+	Description string                     `yaml:"description"`
+	Content     orderedMap[*specMediaType] `yaml:"content"`
+}
+
+type specMediaType struct {
+	Schema *specSchema `yaml:"schema"`
+}
+
+// specSchema is a JSON Schema of the document, with the keywords the generated models and
+// validation rules are made of
+type specSchema struct {
+	Ref                  string                  `yaml:"$ref"`
+	Type                 specTypes               `yaml:"type"`
+	Format               string                  `yaml:"format"`
+	Description          string                  `yaml:"description"`
+	Nullable             bool                    `yaml:"nullable"`
+	Enum                 []any                   `yaml:"enum"`
+	Items                *specSchema             `yaml:"items"`
+	Properties           orderedMap[*specSchema] `yaml:"properties"`
+	Required             []string                `yaml:"required"`
+	AdditionalProperties *specAdditional         `yaml:"additionalProperties"`
+	AllOf                []*specSchema           `yaml:"allOf"`
+	OneOf                []*specSchema           `yaml:"oneOf"`
+	AnyOf                []*specSchema           `yaml:"anyOf"`
+	Minimum              *float64                `yaml:"minimum"`
+	Maximum              *float64                `yaml:"maximum"`
+	ExclusiveMinimum     specBound               `yaml:"exclusiveMinimum"`
+	ExclusiveMaximum     specBound               `yaml:"exclusiveMaximum"`
+	MinLength            *int                    `yaml:"minLength"`
+	MaxLength            *int                    `yaml:"maxLength"`
+	MinItems             *int                    `yaml:"minItems"`
+	MaxItems             *int                    `yaml:"maxItems"`
+}
+
+// typeName returns the type of the schema other than null, or "" when it has none
+func (s *specSchema) typeName() string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	if len(s.Properties) > 0 || len(s.AllOf) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+// nullable reports whether the schema accepts null, with nullable in OpenAPI 3.0 or the
+// null type in 3.1
+func (s *specSchema) nullable() bool {
+	if s.Nullable {
+		return true
+	}
+	for _, t := range s.Type {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// specTypes is the type of a schema: one name in OpenAPI 3.0, one or a list in 3.1
+type specTypes []string
+
+func (t *specTypes) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = specTypes{node.Value}
+		return nil
+	}
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// specAdditional is the additionalProperties of a schema: a boolean or a schema
+type specAdditional struct {
+	Allowed bool
+	Schema  *specSchema
+}
+
+func (a *specAdditional) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&a.Allowed)
+	}
+	a.Allowed = true
+	return node.Decode(&a.Schema)
+}
+
+// specBound is an exclusive bound: a boolean qualifying minimum or maximum in OpenAPI 3.0,
+// the bound itself in 3.1
+type specBound struct {
+	Exclusive bool
+	Value     *float64
+}
+
+func (b *specBound) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!bool" {
+		return node.Decode(&b.Exclusive)
+	}
+	b.Exclusive = true
+	return node.Decode(&b.Value)
+}
+
+// orderedMap is a mapping of the document keeping the order of its keys, so that the
+// generated code follows the document
+type orderedMap[T any] []orderedEntry[T]
+
+type orderedEntry[T any] struct {
+	Key   string
+	Value T
+}
+
+func (m *orderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		*m = append(*m, orderedEntry[T]{Key: node.Content[i].Value, Value: value})
+	}
+	return nil
+}
+
+// get returns the value of key
+func (m orderedMap[T]) get(key string) (T, bool) {
+	for _, entry := range m {
+		if entry.Key == key {
+			return entry.Value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// loadServerSpec reads an OpenAPI document
+func loadServerSpec(path string) (*serverSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec serverSpec
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document %s: %w", path, err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document; convert Swagger 2.0 documents first", path)
+	}
+	if len(spec.Paths) == 0 {
+		return nil, fmt.Errorf("OpenAPI document %s declares no paths", path)
+	}
+	return &spec, nil
+}
+
+// componentRef returns the name of a component referenced by ref within the document, e.g.
+// Product for #/components/schemas/Product
+func componentRef(ref, kind string) (string, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("unsupported reference %q; only references to #/components/%s of the document are supported", ref, kind)
+	}
+	return name, nil
+}
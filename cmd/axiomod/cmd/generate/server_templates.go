@@ -0,0 +1,189 @@
+package generate
+
+const serverAPITemplate = `// Code generated by axiomod generate server from {{.Spec}}; DO NOT EDIT.
+
+package http
+
+import (
+{{- if .Time}}
+	"time"
+{{end}}
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+{{range $model := .Models}}
+{{range .Doc}}//{{if .}} {{.}}{{end}}
+{{end -}}
+{{if .Type -}}
+type {{.Name}} {{if .Alias}}= {{end}}{{.Type}}
+{{- if .Enum}}
+
+// Values of {{.Name}}
+const (
+{{- range .Enum}}
+	{{.Name}} {{$model.Name}} = {{.Value}}
+{{- end}}
+)
+{{- end}}
+{{else -}}
+type {{.Name}} struct {
+{{- range .Fields}}
+{{- if .Doc}}
+	// {{.Doc}}
+{{- end}}
+	{{if .Name}}{{.Name}} {{end}}{{.Type}}{{if .Tag}} ` + "`{{.Tag}}`" + `{{end}}
+{{- end}}
+}
+{{end -}}
+{{end}}
+// APIHandler implements the operations of {{.Spec}}. The routes bind and validate the
+// requests before calling it; the errors it returns are written as problem documents.
+type APIHandler interface {
+{{- range .Operations}}
+{{- range .Doc}}
+	//{{if .}} {{.}}{{end}}
+{{- end}}
+	{{.Name}}({{.Params}}) {{.Results false}}
+{{- end}}
+}
+
+// APIRoutes serves the operations of {{.Spec}} with an APIHandler
+type APIRoutes struct {
+	handler APIHandler
+}
+
+// NewAPIRoutes creates the routes of the API
+func NewAPIRoutes(handler APIHandler) *APIRoutes {
+	return &APIRoutes{handler: handler}
+}
+
+// RegisterRoutes registers the operations on a router, such as the group of the API prefix.
+// Routes with static segments are registered before the routes they overlap.
+func (r *APIRoutes) RegisterRoutes(router fiber.Router) {
+{{- range .Routes}}
+	router.{{.Register}}("{{.Route}}", r.handle{{.Name}})
+{{- end}}
+}
+{{range .Operations}}
+// handle{{.Name}} serves {{.Method}} {{.Path}} with {{.Name}}
+func (r *APIRoutes) handle{{.Name}}(c *fiber.Ctx) error {
+{{- if .Request}}
+	var req {{.Request}}
+{{- if .PathParams}}
+	if err := c.ParamsParser(&req); err != nil {
+		return middleware.RespondProblem(c, errors.NewInvalidInput(err, "invalid path parameters"))
+	}
+{{- end}}
+{{- if .Query}}
+	if err := c.QueryParser(&req); err != nil {
+		return middleware.RespondProblem(c, errors.NewInvalidInput(err, "invalid query parameters"))
+	}
+{{- end}}
+{{- if .Headers}}
+	if err := c.ReqHeaderParser(&req); err != nil {
+		return middleware.RespondProblem(c, errors.NewInvalidInput(err, "invalid headers"))
+	}
+{{- end}}
+{{- if .Cookies}}
+	if err := c.CookieParser(&req); err != nil {
+		return middleware.RespondProblem(c, errors.NewInvalidInput(err, "invalid cookies"))
+	}
+{{- end}}
+{{- if .Body}}
+{{- if .BodyRequired}}
+	if len(c.Body()) == 0 {
+		return middleware.RespondProblem(c, errors.WithCode(errors.New("request body is required"), errors.CodeInvalidInput))
+	}
+	if err := c.BodyParser(&req.Body); err != nil {
+		return middleware.RespondProblem(c, errors.NewInvalidInput(err, "invalid request body"))
+	}
+{{- else}}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req.Body); err != nil {
+			return middleware.RespondProblem(c, errors.NewInvalidInput(err, "invalid request body"))
+		}
+	}
+{{- end}}
+{{- end}}
+	if err := validateAPIRequest(req); err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+{{- end}}
+{{- if .Response}}
+	resp, err := r.handler.{{.Name}}({{.Args}})
+	if err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+	return c.Status({{.Status}}).JSON(resp)
+{{- else}}
+	if err := r.handler.{{.Name}}({{.Args}}); err != nil {
+		return middleware.RespondProblem(c, err)
+	}
+{{- if .ResponseContent}}
+	return nil
+{{- else}}
+	return c.SendStatus({{.Status}})
+{{- end}}
+{{- end}}
+}
+{{end}}
+// apiValidator validates the requests of the API
+var apiValidator = validation.New()
+
+// validateAPIRequest checks a request against the constraints of {{.Spec}}, like
+// middleware.Bind
+func validateAPIRequest(req any) error {
+	violations, err := apiValidator.Validate(req)
+	if err != nil {
+		err = errors.WithCode(errors.Wrap(err, "invalid request"), errors.CodeValidation)
+		return errors.WithMetadata(err, "violations", violations)
+	}
+	return nil
+}
+`
+
+const serverStubTemplate = `package http
+
+import (
+{{- if .StubTime}}
+	"time"
+{{end}}
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/platform/server"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+// APIModule serves the operations of {{.Spec}}{{if .Prefix}} under {{.Prefix}}{{end}}, implemented by API
+var APIModule = fx.Options(
+	fx.Provide(fx.Annotate(NewAPI, fx.As(new(APIHandler)))),
+	fx.Provide(NewAPIRoutes),
+	server.AsHTTPRoutes[*APIRoutes]("{{.Prefix}}"),
+)
+
+// API implements the operations of {{.Spec}}. Add the dependencies of the operations,
+// such as the use cases of the module, as fields.
+type API struct{}
+
+// NewAPI creates the implementation of the API
+func NewAPI() *API {
+	return &API{}
+}
+
+var _ APIHandler = (*API)(nil)
+{{range .Operations}}
+{{- range .Doc}}
+//{{if .}} {{.}}{{end}}
+{{- end}}
+func (a *API) {{.Name}}({{.Params}}) {{.Results true}} {
+{{- if .Response}}
+	return resp, errors.WithCode(errors.New("{{.Name}} is not implemented"), errors.CodeNotImplemented)
+{{- else}}
+	return errors.WithCode(errors.New("{{.Name}} is not implemented"), errors.CodeNotImplemented)
+{{- end}}
+}
+{{end}}`
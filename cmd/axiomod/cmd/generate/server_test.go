@@ -0,0 +1,114 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petSpec = `openapi: 3.0.3
+info: {title: Pets, version: "1.0"}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/NewPet"}
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+components:
+  schemas:
+    Owner:
+      type: object
+      required: [email]
+      properties:
+        email: {type: string, format: email}
+    NewPet:
+      type: object
+      required: [name, age, vaccinated, tags, owner, nickname]
+      properties:
+        name: {type: string, maxLength: 50}
+        age: {type: integer, minimum: 0}
+        vaccinated: {type: boolean}
+        tags: {type: array, items: {type: string}}
+        owner: {$ref: "#/components/schemas/Owner"}
+        nickname: {type: string, nullable: true, minLength: 2}
+        weight: {type: number, maximum: 100}
+    Pet:
+      allOf:
+        - $ref: "#/components/schemas/NewPet"
+        - type: object
+          required: [id]
+          properties:
+            id: {type: integer, format: int64}
+`
+
+// loadPetAPI returns the template data of petSpec
+func loadPetAPI(t *testing.T) *serverAPI {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(petSpec), 0644))
+	spec, err := loadServerSpec(path)
+	require.NoError(t, err)
+	api, err := newServerAPI(spec, "openapi.yaml", serverPrefix(spec))
+	require.NoError(t, err)
+	return api
+}
+
+func TestServerFieldTags(t *testing.T) {
+	api := loadPetAPI(t)
+	fields := make(map[string]serverField)
+	for _, model := range api.Models {
+		for _, field := range model.Fields {
+			fields[model.Name+"."+field.Name] = field
+		}
+	}
+
+	tests := []struct {
+		field string
+		typ   string
+		tag   string
+	}{
+		{"NewPet.Name", "*string", `json:"name" validate:"required,max=50"`},
+		{"NewPet.Age", "*int", `json:"age" validate:"required,min=0"`},
+		{"NewPet.Vaccinated", "*bool", `json:"vaccinated" validate:"required"`},
+		{"NewPet.Tags", "[]string", `json:"tags" validate:"required"`},
+		{"NewPet.Owner", "*Owner", `json:"owner" validate:"required"`},
+		{"NewPet.Nickname", "*string", `json:"nickname" validate:"omitempty,min=2"`},
+		{"NewPet.Weight", "*float64", `json:"weight,omitempty" validate:"omitempty,max=100"`},
+		{"Owner.Email", "*string", `json:"email" validate:"required,email"`},
+		{"Pet.ID", "*int64", `json:"id" validate:"required"`},
+	}
+	for _, tt := range tests {
+		field, ok := fields[tt.field]
+		if assert.True(t, ok, "field %s is generated", tt.field) {
+			assert.Equal(t, tt.typ, field.Type, tt.field)
+			assert.Equal(t, tt.tag, field.Tag, tt.field)
+		}
+	}
+}
+
+// TestGenerateServer generates the server of petSpec into a new module and checks that it
+// builds
+func TestGenerateServer(t *testing.T) {
+	dir := newGeneratedModule(t)
+	require.NoError(t, os.WriteFile("openapi.yaml", []byte(petSpec), 0644))
+	require.NoError(t, generateServerCmd.Flags().Set("spec", "openapi.yaml"))
+	require.NoError(t, generateServerCmd.Flags().Set("module", "pets"))
+	generateServerCmd.Run(generateServerCmd, nil)
+
+	require.FileExists(t, filepath.Join(dir, "internal/pets/delivery/http/api.gen.go"))
+	checkGeneratedModule(t, dir, "./...")
+}
@@ -32,109 +32,136 @@ Example:
 		if moduleName == "" {
 			moduleName = name // Default to service name if module not specified
 		}
-		modulePath := filepath.Join("examples", moduleName)
+		target, err := resolveTarget(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		modulePath, importPath := target.module(moduleName)
 		servicePath := filepath.Join(modulePath, "service")
 		repositoryPath := filepath.Join(modulePath, "repository")
+		entityPath := filepath.Join(modulePath, "entity")
 
 		// Create directories if they don't exist
-		os.MkdirAll(servicePath, 0755)
-		os.MkdirAll(repositoryPath, 0755) // Ensure repo dir exists for import
+		for _, dir := range []string{servicePath, repositoryPath, entityPath} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				fmt.Printf("Error creating directory %s: %v\n", dir, err)
+				os.Exit(1)
+			}
+		}
 
 		// Define template data
 		data := struct {
 			ModuleName      string
+			ImportPath      string
 			ModuleNameTitle string
 			ServiceName     string
+			ServiceType     string
 			RepositoryName  string
 			EntityName      string
+			EntityNameLower string
+			Filters         []filterField
 		}{
 			ModuleName:      moduleName,
+			ImportPath:      importPath,
 			ModuleNameTitle: strings.Title(moduleName),
 			ServiceName:     strings.Title(name) + "Service",
+			ServiceType:     strings.ToLower(name[:1]) + name[1:] + "Service",
 			RepositoryName:  strings.Title(moduleName) + "Repository", // Assuming repo name convention
 			EntityName:      strings.Title(moduleName),                // Assuming entity name convention
+			EntityNameLower: moduleName,
 		}
 
 		// Generate service file
 		serviceFilePath := filepath.Join(servicePath, name+"_domain_service.go")
 		if _, err := os.Stat(serviceFilePath); os.IsNotExist(err) {
-			serviceTemplate := `package service
+			generateFile(domainServiceTemplate, serviceFilePath, data)
+		} else {
+			fmt.Printf("Service file already exists: %s\n", serviceFilePath)
+		}
+
+		// The repository and the entity belong to the module, which generate module or
+		// generate crud may have created already; only the missing ones are generated
+		repositoryFilePath := filepath.Join(repositoryPath, moduleName+"_repository.go")
+		if _, err := os.Stat(repositoryFilePath); os.IsNotExist(err) {
+			generateFile(domainRepositoryTemplate, repositoryFilePath, data)
+		} else {
+			fmt.Printf("Repository file already exists: %s\n", repositoryFilePath)
+		}
+		entityFilePath := filepath.Join(entityPath, moduleName+".go")
+		if _, err := os.Stat(entityFilePath); os.IsNotExist(err) {
+			generateFile(entityTemplate, entityFilePath, data)
+		} else {
+			fmt.Printf("Entity file already exists: %s\n", entityFilePath)
+		}
+
+		fmt.Printf("\nDomain service %s generated successfully.", name)
+		fmt.Println("\nRemember to:")
+		fmt.Println("1. Implement the actual logic in the service and repository.")
+		fmt.Println("2. Add the service and repository implementation to your dependency injection setup.")
+	},
+}
+
+const domainServiceTemplate = `package service
 
 import (
 	"context"
 
 	"go.uber.org/zap"
-	// Import repository and entity if needed
-	// "github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/repository"
+
+	"{{.ImportPath}}/repository"
 )
 
-// {{.ServiceName}} defines the interface for the {{.ModuleName}} service.
+// {{.ServiceName}} defines the interface for the {{.ServiceName}} of the {{.ModuleName}} module.
 type {{.ServiceName}} interface {
 	// Define service methods here
 	ProcessData(ctx context.Context, data string) error
 }
 
-// {{.ModuleName}}Service implements the {{.ServiceName}} interface.
-type {{.ModuleName}}Service struct {
+// {{.ServiceType}} implements the {{.ServiceName}} interface.
+type {{.ServiceType}} struct {
 	logger *zap.Logger
-	 repo   repository.{{.RepositoryName}}
+	repo   repository.{{.RepositoryName}}
 }
 
-// New{{.ServiceName}} creates a new {{.ModuleName}}Service.
+// New{{.ServiceName}} creates a new {{.ServiceName}}.
 func New{{.ServiceName}}(logger *zap.Logger, repo repository.{{.RepositoryName}}) {{.ServiceName}} {
-	return &{{.ModuleName}}Service{
+	return &{{.ServiceType}}{
 		logger: logger,
-		 repo:   repo,
+		repo:   repo,
 	}
 }
 
 // ProcessData is an example service method.
-func (s *{{.ModuleName}}Service) ProcessData(ctx context.Context, data string) error {
-	 s.logger.Info("Processing data in {{.ModuleName}} service", zap.String("data", data))
+func (s *{{.ServiceType}}) ProcessData(ctx context.Context, data string) error {
+	s.logger.Info("Processing data in {{.ServiceName}}", zap.String("data", data))
 	// Implement logic here, potentially calling the repository
-	// Example: entity, err := s.repo.GetByID(ctx, data)
-	 return nil
+	// Example: {{.EntityNameLower}}, err := s.repo.GetByID(ctx, data)
+	return nil
 }
 `
-			generateFile(serviceTemplate, serviceFilePath, data)
-		} else {
-			fmt.Printf("Service file already exists: %s\n", serviceFilePath)
-		}
 
-		// Generate basic repository file (if it doesn't exist)
-		repositoryFilePath := filepath.Join(repositoryPath, name+"_repository.go")
-		if _, err := os.Stat(repositoryFilePath); os.IsNotExist(err) {
-			repositoryTemplate := `package repository
+const domainRepositoryTemplate = `package repository
 
 import (
 	"context"
-	"github.com/axiomod/axiomod/examples/{{.ModuleName}}/entity"
+
+	"{{.ImportPath}}/entity"
 )
 
 // {{.RepositoryName}} defines the interface for data access operations for {{.EntityName}}.
 type {{.RepositoryName}} interface {
 	// Define repository methods here
 	GetByID(ctx context.Context, id string) (*entity.{{.EntityName}}, error)
-	Save(ctx context.Context, entity *entity.{{.EntityName}}) error
+	Save(ctx context.Context, {{.EntityNameLower}} *entity.{{.EntityName}}) error
 }
 `
-			generateFile(repositoryTemplate, repositoryFilePath, data)
-		} else {
-			fmt.Printf("Repository file already exists: %s\n", repositoryFilePath)
-		}
-
-		fmt.Printf("\nDomain service %s generated successfully.", name)
-		fmt.Println("\nRemember to:")
-		fmt.Println("1. Implement the actual logic in the service and repository.")
-		fmt.Println("2. Add the service and repository implementation to your dependency injection setup.")
-	},
-}
 
 func init() {
 	generateServiceCmd.Flags().StringP("name", "n", "", "Name of the service (required)")
 	generateServiceCmd.Flags().StringP("module", "m", "", "Target module name (optional, defaults to service name)")
 	generateServiceCmd.MarkFlagRequired("name")
+	addTargetFlags(generateServiceCmd)
 	// Add subcommands to the parent generateCmd
 	generateCmd.AddCommand(generateServiceCmd)
 }
@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+// setFlags sets flags of a generator command, and resets them to their defaults after the test
+func setFlags(t *testing.T, cmd *cobra.Command, values map[string]string) {
+	t.Helper()
+	for name, value := range values {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, name)
+		require.NoError(t, cmd.Flags().Set(name, value))
+		t.Cleanup(func() {
+			flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		})
+	}
+}
+
+// readFile returns the content of a generated file
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+// TestGenerateServiceNewModule generates a service into a module that does not exist yet, which
+// gets its repository and entity, and checks that it builds
+func TestGenerateServiceNewModule(t *testing.T) {
+	dir := newGeneratedModule(t)
+	setFlags(t, generateServiceCmd, map[string]string{"name": "payment", "module": "billing"})
+	generateServiceCmd.Run(generateServiceCmd, nil)
+
+	for _, file := range []string{
+		"internal/billing/service/payment_domain_service.go",
+		"internal/billing/repository/billing_repository.go",
+		"internal/billing/entity/billing.go",
+	} {
+		require.FileExists(t, filepath.Join(dir, file))
+	}
+	checkGeneratedModule(t, dir, "./...")
+}
+
+// TestGenerateServiceExistingModule generates a service into a module made by generate module,
+// whose repository and entity it uses, and checks that the module still builds
+func TestGenerateServiceExistingModule(t *testing.T) {
+	dir := newGeneratedModule(t)
+	setFlags(t, generateModuleCmd, map[string]string{"name": "order"})
+	generateModuleCmd.Run(generateModuleCmd, nil)
+	repository := readFile(t, filepath.Join(dir, "internal/order/repository/order_repository.go"))
+
+	setFlags(t, generateServiceCmd, map[string]string{"name": "pricing", "module": "order"})
+	generateServiceCmd.Run(generateServiceCmd, nil)
+
+	require.FileExists(t, filepath.Join(dir, "internal/order/service/pricing_domain_service.go"))
+	require.NoFileExists(t, filepath.Join(dir, "internal/order/repository/pricing_repository.go"))
+	require.Equal(t, repository, readFile(t, filepath.Join(dir, "internal/order/repository/order_repository.go")))
+	checkGeneratedModule(t, dir, "./...")
+}
+
+// TestGenerateServiceOutput generates a service into the --output directory of a module nested
+// in a subdirectory, and checks that its imports resolve
+func TestGenerateServiceOutput(t *testing.T) {
+	dir := newGeneratedModule(t)
+	setFlags(t, generateServiceCmd, map[string]string{"name": "auth", "output": "pkg/domains"})
+	generateServiceCmd.Run(generateServiceCmd, nil)
+
+	service := readFile(t, filepath.Join(dir, "pkg/domains/auth/service/auth_domain_service.go"))
+	require.Contains(t, service, `"example.com/shop/pkg/domains/auth/repository"`)
+	checkGeneratedModule(t, dir, "./...")
+}
@@ -0,0 +1,94 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
+	"github.com/spf13/cobra"
+)
+
+// frameworkModule is the module path of axiomod itself, whose generated modules go to examples
+const frameworkModule = "github.com/axiomod/axiomod"
+
+// generationTarget is where generators write modules, and how generated code imports them
+type generationTarget struct {
+	Dir        string // output directory, e.g. internal
+	ImportPath string // Go import path of Dir, e.g. example.com/shop/internal
+	ModulePath string // path of the enclosing Go module, e.g. example.com/shop
+	Root       string // directory of the enclosing Go module, holding the proto files and buf configuration
+}
+
+// module returns the directory and the import path of a generated module
+func (t generationTarget) module(name string) (dir, importPath string) {
+	return filepath.Join(t.Dir, name), path.Join(t.ImportPath, name)
+}
+
+// protoDir returns the directory of the versioned proto package of a module, e.g.
+// proto/order/v1, which buf generates into gen/proto/order/v1
+func (t generationTarget) protoDir(name string) string {
+	return filepath.Join(t.Root, "proto", name, "v1")
+}
+
+// addTargetFlags adds the --output and --package flags of the generators
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().String("output", "", "Directory to generate modules in (default: internal, or examples in the axiomod repository)")
+	cmd.Flags().String("package", "", "Go import path of the output directory (default: derived from go.mod)")
+}
+
+// resolveTarget returns the target of a generator from its --output and --package flags.
+// The import path of the output directory is derived from the go.mod enclosing it unless
+// --package sets it.
+func resolveTarget(cmd *cobra.Command) (generationTarget, error) {
+	output, _ := cmd.Flags().GetString("output")
+	importPath, _ := cmd.Flags().GetString("package")
+
+	root, modulePath, err := findModule()
+	if err != nil && importPath == "" {
+		return generationTarget{}, fmt.Errorf("%w; run the generator inside a Go module or set --package", err)
+	}
+	if output == "" {
+		output = "internal"
+		if modulePath == frameworkModule {
+			output = "examples"
+		}
+	}
+	target := generationTarget{Dir: output, ImportPath: importPath, ModulePath: modulePath, Root: "."}
+	if root != "" {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, root); err == nil {
+				target.Root = rel
+			}
+		}
+	}
+	if importPath != "" {
+		if modulePath == "" {
+			target.ModulePath = importPath
+		}
+		return target, nil
+	}
+
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return generationTarget{}, err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return generationTarget{}, fmt.Errorf("output directory %s is outside of module %s; set --package", output, modulePath)
+	}
+	target.ImportPath = path.Join(modulePath, filepath.ToSlash(rel))
+	return target, nil
+}
+
+// findModule returns the directory and path of the Go module enclosing the working
+// directory
+func findModule() (root, modulePath string, err error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	return apiscan.FindModule(dir)
+}
@@ -0,0 +1,101 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		goMod   string // go.mod of the temporary directory, none when empty
+		workDir string // working directory, relative to the temporary directory
+		flags   map[string]string
+		want    generationTarget
+		wantErr string
+	}{
+		{
+			name:  "default output",
+			goMod: "module example.com/shop\n",
+			want:  generationTarget{Dir: "internal", ImportPath: "example.com/shop/internal", ModulePath: "example.com/shop", Root: "."},
+		},
+		{
+			name:  "output",
+			goMod: "module example.com/shop\n",
+			flags: map[string]string{"output": filepath.Join("pkg", "domains")},
+			want:  generationTarget{Dir: filepath.Join("pkg", "domains"), ImportPath: "example.com/shop/pkg/domains", ModulePath: "example.com/shop", Root: "."},
+		},
+		{
+			// The output directory is relative to the working directory, the proto files to the module
+			name:    "subdirectory",
+			goMod:   "module example.com/shop\n",
+			workDir: filepath.Join("services", "billing"),
+			want:    generationTarget{Dir: "internal", ImportPath: "example.com/shop/services/billing/internal", ModulePath: "example.com/shop", Root: filepath.Join("..", "..")},
+		},
+		{
+			name:  "package",
+			goMod: "module example.com/shop\n",
+			flags: map[string]string{"output": "gen", "package": "example.com/shop/generated"},
+			want:  generationTarget{Dir: "gen", ImportPath: "example.com/shop/generated", ModulePath: "example.com/shop", Root: "."},
+		},
+		{
+			name:  "framework module",
+			goMod: "module github.com/axiomod/axiomod\n",
+			want:  generationTarget{Dir: "examples", ImportPath: "github.com/axiomod/axiomod/examples", ModulePath: "github.com/axiomod/axiomod", Root: "."},
+		},
+		{
+			name:    "output outside of the module",
+			goMod:   "module example.com/shop\n",
+			flags:   map[string]string{"output": filepath.Join("..", "elsewhere")},
+			wantErr: "is outside of module example.com/shop; set --package",
+		},
+		{
+			name:    "no module",
+			wantErr: "run the generator inside a Go module or set --package",
+		},
+		{
+			name:  "no module with package",
+			flags: map[string]string{"package": "example.com/shop/internal"},
+			want:  generationTarget{Dir: "internal", ImportPath: "example.com/shop/internal", ModulePath: "example.com/shop/internal", Root: "."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.goMod != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.goMod), 0644))
+			}
+			workDir := filepath.Join(dir, tt.workDir)
+			require.NoError(t, os.MkdirAll(workDir, 0755))
+			t.Chdir(workDir)
+
+			cmd := &cobra.Command{}
+			addTargetFlags(cmd)
+			for name, value := range tt.flags {
+				require.NoError(t, cmd.Flags().Set(name, value))
+			}
+			target, err := resolveTarget(cmd)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestGenerationTargetModule(t *testing.T) {
+	target := generationTarget{Dir: "internal", ImportPath: "example.com/shop/internal", ModulePath: "example.com/shop", Root: ".."}
+	dir, importPath := target.module("order")
+	assert.Equal(t, filepath.Join("internal", "order"), dir)
+	assert.Equal(t, "example.com/shop/internal/order", importPath)
+	assert.Equal(t, filepath.Join("..", "proto", "order", "v1"), target.protoDir("order"))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/viper"
 
 	// Import command packages
+	"github.com/axiomod/axiomod/cmd/axiomod/cmd/add"
 	"github.com/axiomod/axiomod/cmd/axiomod/cmd/core"
 	"github.com/axiomod/axiomod/cmd/axiomod/cmd/generate"
 	"github.com/axiomod/axiomod/cmd/axiomod/cmd/migrate"
@@ -30,6 +31,7 @@ management, and deployment of services built with the Axiomod framework.
 
 It provides commands for:
 - Initializing new projects
+- Adding capabilities, such as Kafka or a Redis cache, to existing projects
 - Generating code (modules, handlers, services)
 - Managing database migrations
 - Running validators (architecture, naming, static analysis, etc.)
@@ -66,10 +68,12 @@ func init() {
 
 	// Add commands from sub-packages
 	rootCmd.AddCommand(core.NewInitCmd())
+	rootCmd.AddCommand(add.NewAddCmd())           // Parent add command
 	rootCmd.AddCommand(generate.NewGenerateCmd()) // Parent generate command
 	rootCmd.AddCommand(migrate.NewMigrateCmd())   // Parent migrate command
 	rootCmd.AddCommand(core.NewConfigCmd())       // Parent config command
 	rootCmd.AddCommand(core.NewTestCmd())
+	rootCmd.AddCommand(core.NewBenchCmd())
 	rootCmd.AddCommand(core.NewLintCmd())
 	rootCmd.AddCommand(core.NewFmtCmd())
 	rootCmd.AddCommand(core.NewBuildCmd())
@@ -78,6 +82,8 @@ func init() {
 	rootCmd.AddCommand(core.NewStatusCmd())
 	rootCmd.AddCommand(core.NewLogsCmd())
 	rootCmd.AddCommand(core.NewHealthcheckCmd())
+	rootCmd.AddCommand(core.NewSupportBundleCmd())
+	rootCmd.AddCommand(core.NewDoctorCmd())
 	rootCmd.AddCommand(plugin.NewPluginCmd()) // Parent plugin command
 	rootCmd.AddCommand(policy.NewPolicyCmd()) // Parent policy command
 	rootCmd.AddCommand(core.NewInteractiveCmd())
@@ -259,7 +259,7 @@ func RunAllValidators(dir string, configPath string, sqlPath string, apiPath str
 
 	// Run naming validation
 	fmt.Println("\n=== Naming Convention Validation ===")
-	namingSuccess, _ := RunNamingValidation(dir, sqlPath, apiPath, false)
+	namingSuccess, _ := RunNamingValidation(dir, sqlPath, apiPath)
 	if !namingSuccess {
 		allPassed = false
 		failedChecks = append(failedChecks, "naming")
@@ -13,13 +13,24 @@ var architectureCmd = &cobra.Command{
 	Short: "Validate project architecture against defined rules",
 	Long: `Validate the project architecture by checking dependencies against rules defined in architecture-rules.json.
 
+With --format=json, sarif or junit the violations are written as a report for CI, e.g. to upload
+them as GitHub code scanning alerts. The command still exits with status 1 when there are violations.
+
+With --changed-only only the packages of the files changed since --since (HEAD by default) and of
+untracked files are validated, e.g. in a pre-commit hook. With --watch the packages of the files
+changed while the command runs are validated again, until it is interrupted.
+
 Example:
   axiomod validator architecture
   axiomod validator architecture --config=path/to/rules.json
+  axiomod validator architecture --format=sarif --output=architecture.sarif
+  axiomod validator architecture --changed-only --since=origin/main
+  axiomod validator architecture --watch
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		configPath, _ := cmd.Flags().GetString("config")
-		fmt.Println("Validating project architecture...")
+		format := reportFormat(cmd)
+		fmt.Fprintln(console, "Validating project architecture...")
 
 		// Default config path
 		if configPath == "" {
@@ -28,12 +39,33 @@ Example:
 
 		// Check if config file exists
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			fmt.Printf("Architecture rules file not found: %s\n", configPath)
-			fmt.Println("Please create an architecture-rules.json file or specify a path using --config.")
+			fmt.Fprintf(console, "Architecture rules file not found: %s\n", configPath)
+			fmt.Fprintln(console, "Please create an architecture-rules.json file or specify a path using --config.")
 			os.Exit(1)
 		}
 
-		fmt.Printf("Using rules file: %s\n", configPath)
+		fmt.Fprintf(console, "Using rules file: %s\n", configPath)
+		if !applyChangedOnly(cmd, configPath) {
+			return
+		}
+		if watching(cmd, []string{"architecture"}, configPath, defaultSQLPath, defaultAPIPath) {
+			return
+		}
+
+		if usesReport(cmd, format) {
+			rootDir, err := os.Getwd()
+			if err != nil {
+				fmt.Fprintf(console, "Failed to get current directory: %v\n", err)
+				os.Exit(1)
+			}
+			report, err := ArchitectureReport(rootDir, configPath)
+			if err != nil {
+				fmt.Fprintf(console, "Architecture validation error: %v\n", err)
+				os.Exit(1)
+			}
+			emitReport(cmd, format, report)
+			return
+		}
 
 		// Load rules and perform validation
 		err := ValidateArchitecture(configPath)
@@ -48,11 +80,14 @@ Example:
 
 // NewArchitectureCmd returns the validator architecture command.
 func NewArchitectureCmd() *cobra.Command {
-	architectureCmd.Flags().StringP("config", "c", "", "Path to the architecture rules JSON file")
 	return architectureCmd
 }
 
 func init() {
+	architectureCmd.Flags().StringP("config", "c", "", "Path to the architecture rules JSON file")
+	addReportFlags(architectureCmd)
+	addIncrementalFlags(architectureCmd)
+
 	// Add subcommands to the parent validatorCmd
 	validatorCmd.AddCommand(architectureCmd)
 }
@@ -3,10 +3,9 @@ package validator
 import (
 	"encoding/json"
 	"fmt"
-	"go/parser"
-	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -60,6 +59,8 @@ type ValidationSummary struct {
 	ImportsChecked int
 	// Total number of violations found
 	TotalViolations int
+	// Total number of violations of rules lowered to warnings
+	TotalWarnings int
 	// Violations by rule category
 	ViolationsByCategory map[string]int
 	// Violations by source package
@@ -80,6 +81,13 @@ type Violation struct {
 	Line int
 	// Category of violation
 	Category string
+	// Level of the violation, error unless the rule configuration lowers it
+	Level string
+}
+
+// String returns the violation as file:line: source imports target (not allowed)
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d: %s imports %s (not allowed)", v.FilePath, v.Line, v.Source, v.Target)
 }
 
 // getDefaultConfig returns the default configuration with predefined dependency rules
@@ -152,49 +160,92 @@ func getDefaultConfig() Configuration {
 
 // RunArchitectureValidation validates the architecture of the codebase
 func RunArchitectureValidation(rootDir string, configPath string) (bool, error) {
-	fmt.Println("Running architecture validation...")
+	fmt.Fprintln(console, "Running architecture validation...")
 
 	// Load configuration
 	config := loadConfiguration(configPath)
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return false, err
+	}
 
 	// Start the validation process
-	violations, summary := validateArchitecture(rootDir, config)
+	violations, summary, err := validateArchitecture(rootDir, config, policy)
+	if err != nil {
+		fmt.Fprintf(console, "Error during validation: %v\n", err)
+	}
 
 	// Print summary report before violations
 	printSummaryReport(summary)
 
+	if summary.TotalWarnings > 0 {
+		fmt.Fprintln(console, "\n⚠️  Architecture warnings:")
+		for _, v := range violations {
+			if v.Level == LevelWarning {
+				fmt.Fprintf(console, "  - %s\n", v)
+			}
+		}
+	}
+
 	if summary.TotalViolations > 0 {
-		fmt.Println("\n❌ Architecture violations found:")
+		fmt.Fprintln(console, "\n❌ Architecture violations found:")
 		for _, v := range violations {
-			fmt.Printf("  - %s\n", v)
+			if v.Level == LevelError {
+				fmt.Fprintf(console, "  - %s\n", v)
+			}
 		}
 
 		// Print the summary report again after violations for better visibility
-		fmt.Println("\nArchitecture Validation Summary:")
+		fmt.Fprintln(console, "\nArchitecture Validation Summary:")
 		printSummaryReport(summary)
 		return false, fmt.Errorf("architecture validation failed with %d violations", summary.TotalViolations)
 	}
 
-	fmt.Println("\n✅ Architecture validation passed!")
+	if err != nil {
+		return false, fmt.Errorf("architecture validation failed: %w", err)
+	}
+
+	fmt.Fprintln(console, "\n✅ Architecture validation passed!")
 	return true, nil
 }
 
+// ArchitectureReport validates the architecture of the codebase and returns its violations
+func ArchitectureReport(rootDir string, configPath string) (*Report, error) {
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, err
+	}
+	violations, _, err := validateArchitecture(rootDir, loadConfiguration(configPath), policy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Validator: "architecture"}
+	for _, v := range violations {
+		report.Add(v.Category, v.Level, fmt.Sprintf("%s imports %s, which the architecture rules do not allow", v.Source, v.Target), v.FilePath, v.Line, 0)
+	}
+	return report, nil
+}
+
 // printSummaryReport prints a summary report of architecture validation
 func printSummaryReport(summary ValidationSummary) {
-	fmt.Printf("  Files checked: %d\n", summary.FilesChecked)
-	fmt.Printf("  Imports checked: %d\n", summary.ImportsChecked)
-	fmt.Printf("  Total violations: %d\n", summary.TotalViolations)
+	fmt.Fprintf(console, "  Files checked: %d\n", summary.FilesChecked)
+	fmt.Fprintf(console, "  Imports checked: %d\n", summary.ImportsChecked)
+	fmt.Fprintf(console, "  Total violations: %d\n", summary.TotalViolations)
+	if summary.TotalWarnings > 0 {
+		fmt.Fprintf(console, "  Total warnings: %d\n", summary.TotalWarnings)
+	}
 
 	if summary.TotalViolations > 0 {
-		fmt.Println("\nViolations by rule category:")
+		fmt.Fprintln(console, "\nViolations by rule category:")
 		for category, count := range summary.ViolationsByCategory {
-			fmt.Printf("  - %s: %d\n", category, count)
+			fmt.Fprintf(console, "  - %s: %d\n", category, count)
 		}
 
-		fmt.Println("\nTop violating source packages:")
+		fmt.Fprintln(console, "\nTop violating source packages:")
 		printTopViolations(summary.ViolationsBySource, 5)
 
-		fmt.Println("\nTop violated target packages:")
+		fmt.Fprintln(console, "\nTop violated target packages:")
 		printTopViolations(summary.ViolationsByTarget, 5)
 	}
 }
@@ -228,7 +279,7 @@ func printTopViolations(violations map[string]int, topN int) {
 	}
 
 	for i := 0; i < count; i++ {
-		fmt.Printf("  - %s: %d\n", items[i].pkg, items[i].count)
+		fmt.Fprintf(console, "  - %s: %d\n", items[i].pkg, items[i].count)
 	}
 }
 
@@ -259,13 +310,13 @@ func loadConfiguration(configPath string) Configuration {
 	// Load config from file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		fmt.Printf("Warning: Could not read config file %s: %v\nUsing default configuration.\n", configPath, err)
+		fmt.Fprintf(console, "Warning: Could not read config file %s: %v\nUsing default configuration.\n", configPath, err)
 		return getDefaultConfig()
 	}
 
 	var config Configuration
 	if err := json.Unmarshal(data, &config); err != nil {
-		fmt.Printf("Warning: Could not parse config file %s: %v\nUsing default configuration.\n", configPath, err)
+		fmt.Fprintf(console, "Warning: Could not parse config file %s: %v\nUsing default configuration.\n", configPath, err)
 		return getDefaultConfig()
 	}
 
@@ -323,22 +374,59 @@ func isWildcardMatch(pattern, str string) bool {
 	return true
 }
 
-// isDomainPath checks if a path is within a domain package
-func isDomainPath(path string) bool {
-	return strings.HasPrefix(path, "domain/") || strings.Contains(path, "/examples/example/")
+// packageLayer is where a package sits in the architecture
+type packageLayer struct {
+	// Layer is the key of the allowed dependencies the package belongs to, "" when none applies
+	Layer string
+	// Domain is the path of the domain of the package, e.g. internal/product, "" outside domains
+	Domain string
+	// Internal is the key of the allowed internal structure of the domain the package belongs to
+	Internal string
+}
+
+// classifyPackage returns the layer of a package from its import path relative to its module.
+// A package belongs to the layer whose key matches the leftmost, then longest, sequence of
+// elements of its path, so internal/product/delivery/http/dto is in delivery/http. The elements
+// before the layer of the domain rules are the domain of the package.
+func classifyPackage(rel string, config Configuration) packageLayer {
+	var layer packageLayer
+	if _, key := matchLayer(rel, config.AllowedDependencies); key != "" {
+		layer.Layer = key
+	}
+	if start, key := matchLayer(rel, config.DomainRules.AllowedInternalStructure); key != "" && start > 0 {
+		elements := strings.Split(rel, "/")
+		layer.Domain = strings.Join(elements[:start], "/")
+		layer.Internal = key
+	}
+	return layer
 }
 
-// extractDomainName extracts the domain name from a path like "domain/example/model"
-func extractDomainName(path string) string {
-	if strings.HasPrefix(path, "domain/") {
-		parts := strings.Split(path, "/")
-		if len(parts) >= 2 {
-			return parts[1]
+// matchLayer returns the key matching the leftmost, then longest, sequence of elements of a path,
+// and the index of its first element
+func matchLayer(rel string, layers map[string][]string) (int, string) {
+	var wildcards []string
+	for key := range layers {
+		if strings.Contains(key, "*") {
+			wildcards = append(wildcards, key)
+		}
+	}
+	sort.Strings(wildcards)
+
+	elements := strings.Split(rel, "/")
+	for i := range elements {
+		for j := len(elements); j > i; j-- {
+			candidate := strings.Join(elements[i:j], "/")
+			if _, ok := layers[candidate]; ok {
+				return i, candidate
+			}
+			for _, key := range wildcards {
+				if isWildcardMatch(key, candidate) {
+					return i, key
+				}
+			}
 		}
-	} else if strings.Contains(path, "/examples/example/") {
-		return "example"
 	}
-	return ""
+	return 0, ""
 }
 
 // checkDomainDependency checks if a domain dependency is allowed
@@ -363,110 +451,56 @@ func checkDomainDependency(sourceDomain, targetDomain string, config Configurati
 	return false
 }
 
-// checkInternalDomainStructure checks if within a domain, the file dependencies follow allowed patterns
-func checkInternalDomainStructure(sourceFile, targetFile string, config Configuration) bool {
-	// Extract file types (e.g., "model", "service", etc.) from paths
-	// Assuming paths like "domain/example/model.go" or "domain/example/service/user_service.go"
-	sourceType := getFileType(sourceFile)
-	targetType := getFileType(targetFile)
-
-	if sourceType == "" || targetType == "" {
-		// If we can't determine types, default to allowing the dependency
-		return true
-	}
-
-	// Check if this internal structure dependency is allowed
-	allowedTargets, exists := config.DomainRules.AllowedInternalStructure[sourceType]
-	if !exists {
-		// If no specific rule, default to allowing
-		return true
-	}
-
-	// Check if the target type is in the allowed list
-	for _, allowed := range allowedTargets {
-		if allowed == targetType || isWildcardMatch(allowed, targetType) {
-			return true
+// isAllowedLayer reports whether one of the allowed entries matches the layer or the path of the
+// imported package
+func isAllowedLayer(allowed []string, target *projectPackage, targetLayer packageLayer) bool {
+	for _, entry := range allowed {
+		for _, name := range []string{targetLayer.Layer, targetLayer.Internal, target.Rel} {
+			if name != "" && (entry == name || isWildcardMatch(entry, name)) {
+				return true
+			}
 		}
 	}
-
 	return false
 }
 
-// getFileType extracts the type of file from a path
-// For example, from "domain/example/model.go" extracts "model"
-// From "domain/example/service/user_service.go" extracts "service"
-func getFileType(path string) string {
-	// Remove the .go extension if present
-	path = strings.TrimSuffix(path, ".go")
-
-	parts := strings.Split(path, "/")
-	if len(parts) < 3 {
-		return ""
-	}
-
-	// If it's a file directly in the domain root, like model.go
-	if len(parts) == 3 {
-		return parts[2]
-	}
-
-	// If it's in a subdirectory, like service/user_service.go
-	return parts[3]
-}
-
-// checkLayerDependency checks if a dependency is allowed based on direct matches or pattern rules
+// checkLayerDependency checks if a package of the module may import another one of the same
+// module. Packages outside of the configured layers are not restricted.
 // Returns a boolean indicating if allowed and a category string for violation reporting
-func checkLayerDependency(currentLayer, importedLayer string, config Configuration) (bool, string) {
-	// Handle domain-specific rules
-	if isDomainPath(currentLayer) && isDomainPath(importedLayer) {
-		sourceDomain := extractDomainName(currentLayer)
-		targetDomain := extractDomainName(importedLayer)
+func checkLayerDependency(source, target *projectPackage, config Configuration) (bool, string) {
+	sourceLayer := classifyPackage(source.Rel, config)
+	targetLayer := classifyPackage(target.Rel, config)
 
+	// Handle domain-specific rules
+	if sourceLayer.Domain != "" && targetLayer.Domain != "" {
 		// Check if cross-domain dependency is allowed
-		if !checkDomainDependency(sourceDomain, targetDomain, config) {
+		if !checkDomainDependency(sourceLayer.Domain, targetLayer.Domain, config) {
 			return false, "cross-domain-dependency"
 		}
 
-		// If it's within the same domain, check internal structure
-		if sourceDomain == targetDomain {
-			if !checkInternalDomainStructure(currentLayer, importedLayer, config) {
+		// Within a domain, the internal structure decides
+		if sourceLayer.Domain == targetLayer.Domain && sourceLayer.Internal != targetLayer.Internal {
+			allowed := config.DomainRules.AllowedInternalStructure[sourceLayer.Internal]
+			if !isAllowedLayer(allowed, target, targetLayer) {
 				return false, "domain-internal-structure"
 			}
+			return true, ""
 		}
 	}
 
-	// Check direct allowed dependencies
-	if allowedLayers, exists := config.AllowedDependencies[currentLayer]; exists {
-		for _, layer := range allowedLayers {
-			// Check exact match
-			if layer == importedLayer {
-				return true, ""
-			}
-			// Check wildcard match
-			if strings.Contains(layer, "*") && isWildcardMatch(layer, importedLayer) {
-				return true, ""
-			}
-		}
+	// Packages outside of the layers, and packages of the same layer, are not restricted
+	if sourceLayer.Layer == "" || sourceLayer.Layer == targetLayer.Layer {
+		return true, ""
 	}
 
-	// Check wildcard source layer match
-	for wildcardLayer, allowedLayers := range config.AllowedDependencies {
-		if strings.Contains(wildcardLayer, "*") && isWildcardMatch(wildcardLayer, currentLayer) {
-			for _, layer := range allowedLayers {
-				// Check exact match
-				if layer == importedLayer {
-					return true, ""
-				}
-				// Check wildcard match
-				if strings.Contains(layer, "*") && isWildcardMatch(layer, importedLayer) {
-					return true, ""
-				}
-			}
-		}
+	// Check the allowed dependencies of the layer
+	if isAllowedLayer(config.AllowedDependencies[sourceLayer.Layer], target, targetLayer) {
+		return true, ""
 	}
 
 	// Check pattern rules
 	for _, rule := range config.PatternRules {
-		if isWildcardMatch(rule.Pattern, currentLayer) && isWildcardMatch(rule.AllowedToImport, importedLayer) {
+		if isWildcardMatch(rule.Pattern, source.Rel) && isWildcardMatch(rule.AllowedToImport, target.Rel) {
 			return true, ""
 		}
 	}
@@ -474,9 +508,12 @@ func checkLayerDependency(currentLayer, importedLayer string, config Configurati
 	return false, "layer-dependency"
 }
 
-func validateArchitecture(rootDir string, config Configuration) ([]string, ValidationSummary) {
-	var violations []string
-	var structuralViolations []Violation
+// validateArchitecture checks the imports between the packages of each Go module under rootDir
+// against the configuration, at the levels of the rule policy. Imports of other modules, such as
+// the framework imported by the examples module, are dependencies rather than layers and are not
+// checked.
+func validateArchitecture(rootDir string, config Configuration, policy *rulePolicy) ([]Violation, ValidationSummary, error) {
+	var violations []Violation
 
 	// Initialize summary
 	summary := ValidationSummary{
@@ -485,104 +522,73 @@ func validateArchitecture(rootDir string, config Configuration) ([]string, Valid
 		ViolationsByTarget:   make(map[string]int),
 	}
 
-	// Walk through all Go files in the project
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return fmt.Errorf("error accessing %s: %w", path, err)
-		}
-
-		// Skip directories and non-Go files
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
-		}
+	pkgs, err := loadProjectPackages(rootDir)
+	if err != nil {
+		return nil, summary, err
+	}
+	byPath := make(map[string]*projectPackage, len(pkgs))
+	for _, pkg := range pkgs {
+		byPath[pkg.Path] = pkg
+	}
 
-		// Check if this file is exempt from validation
-		for _, exception := range config.Exceptions {
-			if strings.Contains(path, exception) {
-				return nil
+	for _, pkg := range pkgs {
+	files:
+		for _, file := range pkg.Files {
+			// Check if this file is exempt from validation
+			for _, exception := range config.Exceptions {
+				if strings.Contains(file.Path, exception) {
+					continue files
+				}
 			}
-		}
-
-		// Increment file counter
-		summary.FilesChecked++
-
-		// Determine the package from the file path
-		relPath, err := filepath.Rel(rootDir, filepath.Dir(path))
-		if err != nil {
-			return fmt.Errorf("failed to get relative path for %s: %w", path, err)
-		}
 
-		// Convert path separators to '/' for consistency across platforms
-		relPath = filepath.ToSlash(relPath)
-
-		// Determine current layer or package
-		currentLayer := relPath
-
-		// For old structure compatibility
-		if !strings.Contains(relPath, "/") {
-			currentLayer = relPath
-		}
-
-		// Parse the Go file
-		fset := token.NewFileSet()
-		node, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
-		if err != nil {
-			return fmt.Errorf("failed to parse %s: %w", path, err)
-		}
+			// Increment file counter
+			summary.FilesChecked++
 
-		// Check each import statement
-		for _, imp := range node.Imports {
-			importPath := strings.Trim(imp.Path.Value, "\"")
+			// Check each import statement
+			for _, imp := range file.Imports {
+				// Increment import counter
+				summary.ImportsChecked++
 
-			// Increment import counter
-			summary.ImportsChecked++
-
-			// Only check project imports
-			if !strings.Contains(importPath, "internal/") && !strings.Contains(importPath, "github.com/axiomod/axiomod/") {
-				continue
-			}
+				// Only check imports of packages of the same module
+				target := moduleImport(byPath, pkg, imp.Path)
+				if target == nil {
+					continue
+				}
 
-			// Extract the layer/package from the import path
-			var importedLayer string
-			if strings.HasPrefix(importPath, "github.com/axiomod/axiomod/") {
-				importedLayer = strings.TrimPrefix(importPath, "github.com/axiomod/axiomod/")
-			} else {
-				continue // Skip internal/ and external imports
-			}
+				// Check if this dependency is allowed
+				allowed, category := checkLayerDependency(pkg, target, config)
+				if allowed {
+					continue
+				}
 
-			// Check if this dependency is allowed
-			allowed, category := checkLayerDependency(currentLayer, importedLayer, config)
-			if !allowed {
-				// Get position information for the import
-				pos := fset.Position(imp.Pos())
+				// Skip rules that are off or suppressed by a comment
+				level := policy.level(ruleID("architecture", category), LevelError, file.Path, imp.Line)
+				if level == "" {
+					continue
+				}
 
 				// Record the violation
-				violation := fmt.Sprintf("%s:%d: %s imports %s (not allowed)", path, pos.Line, currentLayer, importedLayer)
-				violations = append(violations, violation)
-
-				// Record detailed violation for statistics
-				structuralViolations = append(structuralViolations, Violation{
-					Source:   currentLayer,
-					Target:   importedLayer,
-					FilePath: path,
-					Line:     pos.Line,
+				violations = append(violations, Violation{
+					Source:   pkg.Rel,
+					Target:   target.Rel,
+					FilePath: file.Path,
+					Line:     imp.Line,
 					Category: category,
+					Level:    level,
 				})
+				if level == LevelWarning {
+					summary.TotalWarnings++
+					continue
+				}
 
 				// Update summary counters
 				summary.TotalViolations++
 				summary.ViolationsByCategory[category]++
-				summary.ViolationsBySource[currentLayer]++
-				summary.ViolationsByTarget[importedLayer]++
+				summary.ViolationsBySource[pkg.Rel]++
+				summary.ViolationsByTarget[target.Rel]++
 			}
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		violations = append(violations, fmt.Sprintf("Error during validation: %v", err))
 	}
 
-	return violations, summary
+	return violations, summary, nil
 }
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateArchitecture validates the module of testdata/architecture against the default
+// rules. Its violations are in a test of an external test package, in a file built with the
+// integration tag and between two domains; tools/seed is outside the rules and may import any
+// layer.
+func TestValidateArchitecture(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("testdata", "architecture"))
+	require.NoError(t, err)
+
+	policy, err := loadRulePolicy()
+	require.NoError(t, err)
+	violations, summary, err := validateArchitecture(root, getDefaultConfig(), policy)
+	require.NoError(t, err)
+
+	var got []string
+	for _, v := range violations {
+		rel, err := filepath.Rel(root, v.FilePath)
+		require.NoError(t, err)
+		got = append(got, fmt.Sprintf("%s:%d: %s -> %s (%s)", filepath.ToSlash(rel), v.Line, v.Source, v.Target, v.Category))
+	}
+	assert.ElementsMatch(t, []string{
+		"internal/billing/entity/invoice.go:3: internal/billing/entity -> internal/shop/entity (cross-domain-dependency)",
+		"internal/shop/delivery/http/handler_integration.go:5: internal/shop/delivery/http -> internal/shop/infrastructure/persistence (domain-internal-structure)",
+		"internal/shop/entity/order_test.go:7: internal/shop/entity -> internal/shop/usecase (domain-internal-structure)",
+		"internal/shop/usecase/usecase.go:4: internal/shop/usecase -> internal/shop/delivery/http (domain-internal-structure)",
+	}, got)
+
+	assert.Equal(t, 4, summary.TotalViolations)
+	assert.Equal(t, 9, summary.FilesChecked)
+	assert.Equal(t, map[string]int{"cross-domain-dependency": 1, "domain-internal-structure": 3}, summary.ViolationsByCategory)
+}
+
+func TestClassifyPackage(t *testing.T) {
+	config := getDefaultConfig()
+	tests := []struct {
+		rel  string
+		want packageLayer
+	}{
+		{"internal/shop/entity", packageLayer{Layer: "entity", Domain: "internal/shop", Internal: "entity"}},
+		{"internal/shop/delivery/http/dto", packageLayer{Layer: "delivery/http", Domain: "internal/shop", Internal: "delivery/http"}},
+		{"internal/shop/infrastructure/persistence", packageLayer{Layer: "infrastructure/persistence", Domain: "internal/shop", Internal: "infrastructure/persistence"}},
+		{"platform/server", packageLayer{Layer: "platform/*"}},
+		{"plugins/auth/ldap", packageLayer{Layer: "plugins/*"}},
+		{"tools/seed", packageLayer{}},
+		{".", packageLayer{}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyPackage(tt.rel, config), tt.rel)
+	}
+}
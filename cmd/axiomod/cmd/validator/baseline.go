@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// baselineCmd represents the validator baseline command
+var baselineCmd = &cobra.Command{
+	Use:   "baseline [validator...]",
+	Short: "Record the current violations in a baseline file",
+	Long: `Record the current errors of the architecture, naming and domain validators in a baseline file.
+
+Validators run with --baseline=<file> then only fail on errors that are not in the baseline, so a
+legacy codebase can adopt them without fixing every existing violation first. Violations are matched
+by rule, file and message, so they still match after the lines around them change.
+
+Run the command again to re-baseline, e.g. after fixing violations. Only the validators given as
+arguments are re-recorded; the entries of the other validators are kept.
+
+Example:
+  axiomod validator baseline
+  axiomod validator baseline naming --output=naming.baseline.json
+  axiomod validator naming --baseline=violations.baseline.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		configPath, _ := cmd.Flags().GetString("config")
+		sqlPath, _ := cmd.Flags().GetString("sql")
+		apiPath, _ := cmd.Flags().GetString("api")
+
+		validators := args
+		if len(validators) == 0 {
+			validators = baselineValidators
+		}
+
+		baseline := &Baseline{}
+		if _, err := os.Stat(output); err == nil {
+			existing, err := LoadBaseline(output)
+			if err != nil {
+				fmt.Printf("Error loading baseline: %v\n", err)
+				os.Exit(1)
+			}
+			baseline = existing
+		}
+
+		for _, validator := range validators {
+			fmt.Printf("Recording %s violations...\n", validator)
+			report, err := ValidatorReport(validator, configPath, sqlPath, apiPath)
+			if err != nil {
+				fmt.Printf("Error running the %s validator: %v\n", validator, err)
+				os.Exit(1)
+			}
+			baseline.Update(report)
+			fmt.Printf("Recorded %d %s violations\n", report.Count(LevelError), validator)
+		}
+
+		if err := baseline.Save(output); err != nil {
+			fmt.Printf("Error writing baseline: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Baseline written to %s (%d violations)\n", output, len(baseline.Violations))
+	},
+}
+
+// NewBaselineCmd returns the validator baseline command.
+func NewBaselineCmd() *cobra.Command {
+	return baselineCmd
+}
+
+func init() {
+	baselineCmd.Flags().StringP("output", "o", DefaultBaselineFile, "Baseline file to write")
+	baselineCmd.Flags().StringP("config", "c", "", "Path to the architecture rules JSON file")
+	baselineCmd.Flags().String("sql", defaultSQLPath, "Directory containing SQL migrations")
+	baselineCmd.Flags().String("api", defaultAPIPath, "Directory containing API handlers")
+
+	// Add subcommands to the parent validatorCmd
+	validatorCmd.AddCommand(baselineCmd)
+}
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultBaselineFile is the baseline file written by the baseline command
+const DefaultBaselineFile = "violations.baseline.json"
+
+// baselineValidators are the validators whose errors can be recorded in a baseline
+var baselineValidators = []string{"architecture", "naming", "domain"}
+
+// Baseline records the accepted errors of a codebase, so only new ones fail the validation
+type Baseline struct {
+	Violations []BaselineViolation `json:"violations"`
+}
+
+// BaselineViolation is an accepted error. It is matched by rule, file and message rather than
+// by line, so it still matches after unrelated edits move it.
+type BaselineViolation struct {
+	RuleID  string `json:"ruleId"`
+	File    string `json:"file"`
+	Message string `json:"message"`
+}
+
+// key returns the rule, file and message the violation is matched by
+func (v BaselineViolation) key() string {
+	return v.RuleID + "\x00" + v.File + "\x00" + v.Message
+}
+
+// LoadBaseline reads a baseline file
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline file, with the violations sorted so re-baselining gives small diffs
+func (b *Baseline) Save(path string) error {
+	sort.SliceStable(b.Violations, func(i, j int) bool {
+		return b.Violations[i].key() < b.Violations[j].key()
+	})
+	if b.Violations == nil {
+		b.Violations = []BaselineViolation{}
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Update replaces the violations of the report's validator with the errors of the report,
+// keeping the violations of the other validators
+func (b *Baseline) Update(report *Report) {
+	prefix := report.Validator + "/"
+	violations := make([]BaselineViolation, 0, len(b.Violations))
+	for _, v := range b.Violations {
+		if !strings.HasPrefix(v.RuleID, prefix) {
+			violations = append(violations, v)
+		}
+	}
+	for _, f := range report.Findings {
+		if f.Level == LevelError {
+			violations = append(violations, baselineViolation(f))
+		}
+	}
+	b.Violations = violations
+}
+
+// ApplyBaseline marks the errors recorded in the baseline. An error recorded once only marks one
+// matching finding, so a second copy of an accepted violation is still reported.
+func (r *Report) ApplyBaseline(baseline *Baseline) {
+	accepted := make(map[string]int)
+	for _, v := range baseline.Violations {
+		accepted[v.key()]++
+	}
+	for i, f := range r.Findings {
+		key := baselineViolation(f).key()
+		if f.Level == LevelError && accepted[key] > 0 {
+			accepted[key]--
+			r.Findings[i].Baselined = true
+		}
+	}
+	r.baselined = true
+}
+
+// baselineViolation returns the baseline entry of a finding, with its file relative to the
+// working directory
+func baselineViolation(f Finding) BaselineViolation {
+	return BaselineViolation{RuleID: f.RuleID, File: relativePath(f.File), Message: f.Message}
+}
+
+// ValidatorReport runs a validator on the working directory and returns its report
+func ValidatorReport(validator string, configPath string, sqlPath string, apiPath string) (*Report, error) {
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	switch validator {
+	case "architecture":
+		return ArchitectureReport(rootDir, configPath)
+	case "naming":
+		return NamingReport(rootDir, sqlPath, apiPath)
+	case "domain":
+		return DomainReport(rootDir, configPath)
+	default:
+		return nil, fmt.Errorf("unknown validator %q (use %s)", validator, strings.Join(baselineValidators, ", "))
+	}
+}
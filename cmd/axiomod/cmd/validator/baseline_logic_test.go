@@ -0,0 +1,146 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBaselineDomain records the violations of a module in a baseline, then checks that they
+// are still accepted after the lines of the file move, while a new violation fails
+func TestBaselineDomain(t *testing.T) {
+	captureConsole(t)
+	t.Chdir(t.TempDir())
+	handler := filepath.Join("examples", "example", "delivery", "http", "handler.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(handler), 0755))
+	require.NoError(t, os.WriteFile(handler, []byte(`package http
+
+import "github.com/axiomod/axiomod/examples/example/repository"
+
+var _ repository.Repository
+`), 0644))
+
+	report, err := ValidatorReport("domain", "", "", "")
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Count(LevelError))
+	baseline := &Baseline{}
+	baseline.Update(report)
+	require.NoError(t, baseline.Save(DefaultBaselineFile))
+
+	// The violation moves down after unrelated edits
+	require.NoError(t, os.WriteFile(handler, []byte(`// Package http serves the examples
+// over HTTP.
+package http
+
+import "github.com/axiomod/axiomod/examples/example/repository"
+
+var _ repository.Repository
+`), 0644))
+	baseline, err = LoadBaseline(DefaultBaselineFile)
+	require.NoError(t, err)
+	report, err = ValidatorReport("domain", "", "", "")
+	require.NoError(t, err)
+	report.ApplyBaseline(baseline)
+	assert.Equal(t, 0, report.Count(LevelError))
+	assert.Equal(t, 1, report.CountBaselined())
+
+	// A new violation fails the validation
+	require.NoError(t, os.WriteFile(handler, []byte(`package http
+
+import (
+	"github.com/axiomod/axiomod/examples/example/infrastructure/persistence"
+	"github.com/axiomod/axiomod/examples/example/repository"
+)
+
+var _ repository.Repository
+var _ persistence.Repository
+`), 0644))
+	report, err = ValidatorReport("domain", "", "", "")
+	require.NoError(t, err)
+	report.ApplyBaseline(baseline)
+	assert.Equal(t, 1, report.CountBaselined())
+	assert.Equal(t, []string{"examples/example/delivery/http/handler.go:4: Module 'examples/example/delivery/http' is not allowed to import 'examples/example/infrastructure/persistence'"}, report.Issues())
+}
+
+func TestApplyBaseline(t *testing.T) {
+	baseline := &Baseline{Violations: []BaselineViolation{
+		{RuleID: "naming/type", File: "user.go", Message: "Type 'user_Name' should be PascalCase"},
+	}}
+
+	report := &Report{Validator: "naming"}
+	report.Add("type", LevelError, "Type 'user_Name' should be PascalCase", "user.go", 3, 1)
+	report.Add("type", LevelError, "Type 'user_Name' should be PascalCase", "user.go", 9, 1)
+	report.Add("type", LevelError, "Type 'order_Name' should be PascalCase", "user.go", 12, 1)
+	report.Add("type", LevelError, "Type 'user_Name' should be PascalCase", "order.go", 3, 1)
+	report.Add("variable", LevelWarning, "Variable 'userID' is fine", "user.go", 4, 1)
+	report.ApplyBaseline(baseline)
+
+	// The baseline entry accepts one copy of the violation, whatever its line
+	assert.Equal(t, []bool{true, false, false, false, false}, []bool{
+		report.Findings[0].Baselined,
+		report.Findings[1].Baselined,
+		report.Findings[2].Baselined,
+		report.Findings[3].Baselined,
+		report.Findings[4].Baselined,
+	})
+	assert.Equal(t, 3, report.Count(LevelError))
+	assert.Equal(t, 1, report.Count(LevelWarning))
+}
+
+func TestBaselineUpdate(t *testing.T) {
+	baseline := &Baseline{Violations: []BaselineViolation{
+		{RuleID: "domain/layer-dependency", File: "handler.go", Message: "old"},
+		{RuleID: "naming/type", File: "user.go", Message: "fixed since"},
+	}}
+
+	report := &Report{Validator: "naming"}
+	report.Add("file-name", LevelError, "new", "OrderItem.go", 1, 1)
+	report.Add("variable", LevelWarning, "warnings are not recorded", "user.go", 4, 1)
+	baseline.Update(report)
+
+	path := filepath.Join(t.TempDir(), DefaultBaselineFile)
+	require.NoError(t, baseline.Save(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "violations": [
+    {
+      "ruleId": "domain/layer-dependency",
+      "file": "handler.go",
+      "message": "old"
+    },
+    {
+      "ruleId": "naming/file-name",
+      "file": "OrderItem.go",
+      "message": "new"
+    }
+  ]
+}
+`, string(data))
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.json")
+	require.NoError(t, (&Baseline{}).Save(empty))
+	data, err := os.ReadFile(empty)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"violations\": []\n}\n", string(data))
+	baseline, err := LoadBaseline(empty)
+	require.NoError(t, err)
+	assert.Empty(t, baseline.Violations)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"violations": {}}`), 0644))
+	_, err = LoadBaseline(invalid)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "failed to parse baseline"), err.Error())
+
+	_, err = LoadBaseline(filepath.Join(dir, "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+}
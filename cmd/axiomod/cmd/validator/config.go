@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the validator config command
+var configCmd = &cobra.Command{
+	Use:   "config [files...]",
+	Short: "Check configuration files against the framework configuration",
+	Long: `Check YAML configuration files against the config.Config struct of the framework, so a
+misspelled or misplaced key does not silently fall back to its default.
+
+The command reports keys matching no field, values the field cannot hold (e.g. a string for a
+port), missing app and http sections, keys repeated with a different case, of which Viper only
+keeps one, and ${NAME} references to environment variables that are not set. Directories are
+checked through their service_*.yaml files. Without arguments, the service_default.yaml files of
+config/, framework/config/ and configs/ are checked.
+
+Example:
+  axiomod validator config
+  axiomod validator config config/service_production.yaml
+  axiomod validator config ./config --format=sarif --output=config.sarif
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := reportFormat(cmd)
+		fmt.Fprintln(console, "Validating configuration files...")
+
+		report, err := ConfigReport(args)
+		if err != nil {
+			fmt.Fprintf(console, "Config validation error: %v\n", err)
+			os.Exit(1)
+		}
+		emitReport(cmd, format, report)
+	},
+}
+
+// NewConfigCmd returns the validator config command.
+func NewConfigCmd() *cobra.Command {
+	return configCmd
+}
+
+func init() {
+	addReportFlags(configCmd)
+
+	// Add subcommands to the parent validatorCmd
+	validatorCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,486 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"gopkg.in/yaml.v3"
+)
+
+// configValidator is the validator of the configuration files
+const configValidator = "config"
+
+// defaultConfigFiles are the service configurations checked when no file is given
+var defaultConfigFiles = []string{
+	filepath.Join("config", "service_default.yaml"),
+	filepath.Join("framework", "config", "service_default.yaml"),
+	filepath.Join("configs", "service_default.yaml"),
+}
+
+// requiredConfigSections are the sections a service configuration must set: the service has no
+// name without app, and listens on no known port without http
+var requiredConfigSections = []string{"app", "http"}
+
+// envReference matches the ${NAME} references to environment variables of configuration values,
+// with the optional shell default of ${NAME:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:?[-=?+][^}]*)?\}`)
+
+// configChecker checks a configuration file against the config.Config struct
+type configChecker struct {
+	report *Report
+	file   string
+	// migrated is the document upgraded to config.CurrentVersion, when the file is older
+	migrated *yaml.Node
+}
+
+// ConfigReport checks the YAML configuration files, or their service_*.yaml files for
+// directories, against config.Config: unknown keys, values the fields cannot hold, missing
+// sections, duplicate keys and unset environment variables. Without files it checks the
+// service_default.yaml files of the project.
+func ConfigReport(files []string) (*Report, error) {
+	paths, err := configFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Validator: configValidator}
+	for _, path := range paths {
+		fmt.Fprintf(console, "Checking %s\n", path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		file, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		checker := &configChecker{report: report, file: file}
+		if err := checker.check(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, err
+	}
+	findings := report.Findings
+	report.Findings = nil
+	for _, finding := range findings {
+		finding.Level = policy.level(finding.RuleID, finding.Level, finding.File, finding.Line)
+		if finding.Level != "" {
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+	return report, nil
+}
+
+// configFiles returns the configuration files to check
+func configFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		var paths []string
+		for _, path := range defaultConfigFiles {
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no configuration file found in %s", strings.Join(defaultConfigFiles, ", "))
+		}
+		return paths, nil
+	}
+
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		var matches []string
+		for _, pattern := range []string{"service_*.yaml", "service_*.yml"} {
+			found, _ := filepath.Glob(filepath.Join(arg, pattern))
+			matches = append(matches, found...)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no service_*.yaml configuration in %s", arg)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// check checks a configuration document
+func (c *configChecker) check(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		c.add("missing-section", LevelError, 1, 1, "configuration is empty; it needs the %s sections", strings.Join(requiredConfigSections, " and "))
+		return nil
+	}
+	root := resolveAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		c.add("type-mismatch", LevelError, root.Line, root.Column, "configuration must be a mapping of sections")
+		return nil
+	}
+
+	if c.checkVersion(root) {
+		// Keys renamed since the file was written are moved while loading, so they are not unknown
+		if upgraded, _, err := config.MigrateYAML(data); err == nil {
+			var migrated yaml.Node
+			if yaml.Unmarshal(upgraded, &migrated) == nil && len(migrated.Content) > 0 {
+				c.migrated = resolveAlias(migrated.Content[0])
+			}
+		}
+	}
+	c.checkNode(root, reflect.TypeOf(config.Config{}), "")
+
+	for _, section := range requiredConfigSections {
+		if value := mappingValue(root, section); value == nil || value.Tag == "!!null" {
+			c.add("missing-section", LevelError, 1, 1, "section %s is missing", section)
+		}
+	}
+	return nil
+}
+
+// checkVersion reports files written for another configuration version, and returns whether
+// the file is older than config.CurrentVersion
+func (c *configChecker) checkVersion(root *yaml.Node) bool {
+	value := mappingValue(root, config.VersionKey)
+	version := 0
+	line, column := 1, 1
+	if value != nil {
+		parsed, err := strconv.Atoi(value.Value)
+		if err != nil || value.Kind != yaml.ScalarNode {
+			c.add("type-mismatch", LevelError, value.Line, value.Column, "%s must be a number, got %q", config.VersionKey, value.Value)
+			return false
+		}
+		version, line, column = parsed, value.Line, value.Column
+	}
+	switch {
+	case version > config.CurrentVersion:
+		c.add("version", LevelError, line, column, "%s %d is newer than %d, the latest version of this release", config.VersionKey, version, config.CurrentVersion)
+	case version < config.CurrentVersion:
+		c.add("version", LevelWarning, line, column, "%s %d is older than %d; upgrade the file with axiomod config migrate", config.VersionKey, version, config.CurrentVersion)
+		return true
+	}
+	return false
+}
+
+// checkNode checks a value against the type of the field holding it. Like Viper, keys match the
+// field names case-insensitively, and scalars are converted to the type of their field.
+func (c *configChecker) checkNode(node *yaml.Node, t reflect.Type, path string) {
+	node = resolveAlias(node)
+	if node.Tag == "!!null" {
+		return
+	}
+	if node.Kind == yaml.ScalarNode && c.checkEnvReferences(node, path) {
+		// The value is only known once the references are expanded
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !c.expectMapping(node, path) {
+			return
+		}
+		fields := structFields(t)
+		for _, entry := range mappingEntries(node, c) {
+			key := strings.ToLower(entry.key.Value)
+			if path == "" && key == strings.ToLower(config.VersionKey) {
+				continue
+			}
+			field, ok := fields[key]
+			if !ok {
+				if c.moved(joinKey(path, entry.key.Value)) {
+					continue
+				}
+				message := "unknown key " + joinKey(path, entry.key.Value)
+				if suggestion := suggestKey(entry.key.Value, fields); suggestion != "" {
+					message += "; did you mean " + joinKey(path, suggestion) + "?"
+				}
+				c.add("unknown-key", LevelError, entry.key.Line, entry.key.Column, "%s", message)
+				continue
+			}
+			c.checkNode(entry.value, field.Type, joinKey(path, entry.key.Value))
+		}
+
+	case reflect.Map:
+		if !c.expectMapping(node, path) {
+			return
+		}
+		for _, entry := range mappingEntries(node, c) {
+			c.checkNode(entry.value, t.Elem(), joinKey(path, entry.key.Value))
+		}
+
+	case reflect.Slice:
+		switch {
+		case node.Kind == yaml.SequenceNode:
+			for i, item := range node.Content {
+				c.checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			}
+		case node.Kind == yaml.ScalarNode && t.Elem().Kind() == reflect.String:
+			// Viper splits strings on commas into string slices
+		default:
+			c.mismatch(node, path, "a list")
+		}
+
+	case reflect.Interface:
+		// Free-form values, such as the plugin settings
+
+	default:
+		if node.Kind != yaml.ScalarNode {
+			c.mismatch(node, path, scalarDescription(t))
+			return
+		}
+		if !scalarFits(node.Value, t) {
+			c.mismatch(node, path, scalarDescription(t))
+		}
+	}
+}
+
+// moved reports whether upgrading the file moves the value of a key elsewhere
+func (c *configChecker) moved(path string) bool {
+	if c.migrated == nil || strings.Contains(path, "[") {
+		return false
+	}
+	node := c.migrated
+	for _, key := range strings.Split(path, ".") {
+		if node = mappingValue(node, key); node == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEnvReferences reports the ${NAME} references of a value to unset environment variables,
+// and whether the value has references
+func (c *configChecker) checkEnvReferences(node *yaml.Node, path string) bool {
+	matches := envReference.FindAllStringSubmatch(node.Value, -1)
+	for _, match := range matches {
+		if match[2] != "" && !strings.HasPrefix(strings.TrimPrefix(match[2], ":"), "?") {
+			// ${NAME:-default} and similar forms have a value when the variable is unset
+			continue
+		}
+		if _, ok := os.LookupEnv(match[1]); !ok {
+			c.add("unresolved-env", LevelWarning, node.Line, node.Column, "%s references the environment variable %s, which is not set", path, match[1])
+		}
+	}
+	return len(matches) > 0
+}
+
+// expectMapping reports a value that is not a mapping, and returns whether it is one
+func (c *configChecker) expectMapping(node *yaml.Node, path string) bool {
+	if node.Kind == yaml.MappingNode {
+		return true
+	}
+	c.mismatch(node, path, "a mapping")
+	return false
+}
+
+// mismatch reports a value the field cannot hold
+func (c *configChecker) mismatch(node *yaml.Node, path string, expected string) {
+	got := nodeDescription(node)
+	c.add("type-mismatch", LevelError, node.Line, node.Column, "%s must be %s, got %s", path, expected, got)
+}
+
+// add adds a finding of a category to the report
+func (c *configChecker) add(category, level string, line, column int, format string, args ...interface{}) {
+	c.report.Add(category, level, fmt.Sprintf(format, args...), c.file, line, column)
+}
+
+// configEntry is a key and its value in a mapping
+type configEntry struct {
+	key, value *yaml.Node
+}
+
+// mappingEntries returns the entries of a mapping, with those of its << merge keys. Keys
+// repeated case-insensitively are reported, since Viper keeps only one of them.
+func mappingEntries(node *yaml.Node, c *configChecker) []configEntry {
+	var entries []configEntry
+	seen := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" && key.Tag == "!!merge" {
+			merged := resolveAlias(value)
+			sources := []*yaml.Node{merged}
+			if merged.Kind == yaml.SequenceNode {
+				sources = merged.Content
+			}
+			for _, source := range sources {
+				if source = resolveAlias(source); source.Kind == yaml.MappingNode {
+					entries = append(entries, mappingEntries(source, nil)...)
+				}
+			}
+			continue
+		}
+		lower := strings.ToLower(key.Value)
+		if previous, ok := seen[lower]; ok && c != nil {
+			c.add("duplicate-key", LevelError, key.Line, key.Column, "key %s repeats %s of line %d; only one of them is used", key.Value, previous.Value, previous.Line)
+		}
+		seen[lower] = key
+		entries = append(entries, configEntry{key: key, value: value})
+	}
+	return entries
+}
+
+// mappingValue returns the value of a key of a mapping, matched case-insensitively, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias refers to
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// structFields returns the exported fields of a struct by lowercase name
+func structFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() {
+			fields[strings.ToLower(field.Name)] = field
+		}
+	}
+	return fields
+}
+
+// suggestKey returns the YAML key of the field a mistyped key most likely means, or ""
+func suggestKey(key string, fields map[string]reflect.StructField) string {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	// Short keys are too close to each other for a suggestion to mean much
+	maxDistance := 2
+	if len(normalized) < 5 {
+		maxDistance = 0
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	best, bestDistance := "", maxDistance+1
+	for _, name := range names {
+		if distance := editDistance(normalized, name); distance < bestDistance {
+			best, bestDistance = yamlKey(fields[name].Name), distance
+		}
+	}
+	return best
+}
+
+// yamlKey returns the camelCase YAML key of a field name, e.g. tracingURL for TracingURL and
+// sslMode for SSLMode
+func yamlKey(name string) string {
+	upper := 0
+	for upper < len(name) && name[upper] >= 'A' && name[upper] <= 'Z' {
+		upper++
+	}
+	switch {
+	case upper == len(name):
+		return strings.ToLower(name)
+	case upper > 1:
+		// The last capital of an initialism starts the next word
+		upper--
+	}
+	return strings.ToLower(name[:upper]) + name[upper:]
+}
+
+// editDistance returns the Levenshtein distance of two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// scalarFits reports whether a scalar converts to a type without losing its value
+func scalarFits(value string, t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Bool:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			_, err := time.ParseDuration(value)
+			return err == nil || scalarFits(value, reflect.TypeOf(0))
+		}
+		_, err := strconv.ParseInt(strings.ReplaceAll(value, "_", ""), 0, t.Bits())
+		return err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err := strconv.ParseUint(strings.ReplaceAll(value, "_", ""), 0, t.Bits())
+		return err == nil
+	case reflect.Float32, reflect.Float64:
+		_, err := strconv.ParseFloat(value, t.Bits())
+		return err == nil
+	}
+	return true
+}
+
+// scalarDescription describes the values of a scalar type in messages
+func scalarDescription(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a string"
+}
+
+// nodeDescription describes a YAML value in messages
+func nodeDescription(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return strconv.Quote(node.Value)
+}
+
+// joinKey joins a key to the dotted path of its parent
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigReport checks configuration files against config.Config. Findings are formatted as
+// "line: level ruleId: message".
+func TestConfigReport(t *testing.T) {
+	t.Setenv("AXIOMOD_TEST_PORT", "8080")
+	t.Setenv("AXIOMOD_TEST_UNSET", "")
+	require.NoError(t, os.Unsetenv("AXIOMOD_TEST_UNSET"))
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: `configVersion: 1
+app:
+  Name: shop
+  debug: true
+http:
+  port: 8080
+  readTimeout: 30
+observability:
+  tracingSamplerRatio: 0.5
+auth:
+  oidc:
+    issuers: [https://id.example.com]
+    audiences: shop,admin
+plugins: {}
+`,
+		},
+		{
+			name: "unknown keys",
+			config: `configVersion: 1
+app:
+  name: shop
+  enviroment: production
+  nmae: shop
+http:
+  port: 8080
+databse:
+  host: localhost
+`,
+			want: []string{
+				"4: error config/unknown-key: unknown key app.enviroment; did you mean app.environment?",
+				"5: error config/unknown-key: unknown key app.nmae",
+				"8: error config/unknown-key: unknown key databse; did you mean database?",
+			},
+		},
+		{
+			name: "type mismatches",
+			config: `configVersion: 1
+app:
+  name: shop
+  debug: yes
+http:
+  port: eighty
+  readTimeout: [30]
+observability:
+  tracingSamplerRatio: high
+auth:
+  oidc:
+    issuers:
+      primary: https://id.example.com
+grpc: 50051
+`,
+			want: []string{
+				"4: error config/type-mismatch: app.debug must be true or false, got \"yes\"",
+				"6: error config/type-mismatch: http.port must be an integer, got \"eighty\"",
+				"7: error config/type-mismatch: http.readTimeout must be an integer, got a list",
+				"9: error config/type-mismatch: observability.tracingSamplerRatio must be a number, got \"high\"",
+				"13: error config/type-mismatch: auth.oidc.issuers must be a list, got a mapping",
+				"14: error config/type-mismatch: grpc must be a mapping, got \"50051\"",
+			},
+		},
+		{
+			name:   "not a mapping",
+			config: "- app\n- http\n",
+			want:   []string{"1: error config/type-mismatch: configuration must be a mapping of sections"},
+		},
+		{
+			name:   "missing section",
+			config: "configVersion: 1\napp:\n  name: shop\nhttp:\n",
+			want:   []string{"1: error config/missing-section: section http is missing"},
+		},
+		{
+			name:   "empty",
+			config: "",
+			want:   []string{"1: error config/missing-section: configuration is empty; it needs the app and http sections"},
+		},
+		{
+			name:   "newer version",
+			config: "configVersion: 2\napp:\n  name: shop\nhttp:\n  port: 8080\n",
+			want:   []string{"1: error config/version: configVersion 2 is newer than 1, the latest version of this release"},
+		},
+		{
+			// The keys renamed by the migrations are not unknown in older files
+			name:   "older version",
+			config: "app:\n  name: shop\nhttp:\n  port: 8080\nobservability:\n  tracingExporterURL: http://jaeger:14268\n",
+			want:   []string{"1: warning config/version: configVersion 0 is older than 1; upgrade the file with axiomod config migrate"},
+		},
+		{
+			name:   "invalid version",
+			config: "configVersion: one\napp:\n  name: shop\nhttp:\n  port: 8080\n",
+			want:   []string{"1: error config/type-mismatch: configVersion must be a number, got \"one\""},
+		},
+		{
+			name: "environment variables",
+			config: `configVersion: 1
+app:
+  name: ${AXIOMOD_TEST_UNSET}
+  environment: ${AXIOMOD_TEST_UNSET:-development}
+  version: ${AXIOMOD_TEST_UNSET:?required}
+http:
+  port: ${AXIOMOD_TEST_PORT}
+`,
+			want: []string{
+				"3: warning config/unresolved-env: app.name references the environment variable AXIOMOD_TEST_UNSET, which is not set",
+				"5: warning config/unresolved-env: app.version references the environment variable AXIOMOD_TEST_UNSET, which is not set",
+			},
+		},
+		{
+			name:   "duplicate key",
+			config: "configVersion: 1\napp:\n  name: shop\n  Name: store\nhttp:\n  port: 8080\n",
+			want:   []string{"4: error config/duplicate-key: key Name repeats name of line 3; only one of them is used"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureConsole(t)
+			t.Chdir(t.TempDir())
+			require.NoError(t, os.WriteFile("service_default.yaml", []byte(tt.config), 0644))
+
+			report, err := ConfigReport([]string{"service_default.yaml"})
+			require.NoError(t, err)
+			var got []string
+			for _, finding := range report.Findings {
+				assert.Equal(t, "service_default.yaml", filepath.Base(finding.File))
+				got = append(got, fmt.Sprintf("%d: %s %s: %s", finding.Line, finding.Level, finding.RuleID, finding.Message))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfigReportInvalidYAML(t *testing.T) {
+	captureConsole(t)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("service_default.yaml", []byte("app:\n  name: [shop\n"), 0644))
+
+	_, err := ConfigReport([]string{"service_default.yaml"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service_default.yaml: failed to parse config")
+}
+
+func TestConfigFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := configFiles(nil)
+	assert.EqualError(t, err, "no configuration file found in config/service_default.yaml, framework/config/service_default.yaml, configs/service_default.yaml")
+
+	require.NoError(t, os.MkdirAll("configs", 0755))
+	require.NoError(t, os.MkdirAll("empty", 0755))
+	for _, name := range []string{"service_prod.yml", "service_default.yaml", "other.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join("configs", name), nil, 0644))
+	}
+
+	_, err = configFiles([]string{"configs", "other.yaml"})
+	assert.True(t, os.IsNotExist(err))
+
+	paths, err := configFiles([]string{"configs", filepath.Join("configs", "other.yaml")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join("configs", "service_default.yaml"),
+		filepath.Join("configs", "service_prod.yml"),
+		filepath.Join("configs", "other.yaml"),
+	}, paths)
+
+	paths, err = configFiles(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("configs", "service_default.yaml")}, paths)
+
+	_, err = configFiles([]string{"empty"})
+	assert.EqualError(t, err, "no service_*.yaml configuration in empty")
+}
@@ -9,46 +9,70 @@ import (
 
 // domainCmd represents the validator domain command
 var domainCmd = &cobra.Command{
-	Use:   "domain",
+	Use:   "domain [path]",
 	Short: "Validate domain boundaries and dependencies",
 	Long: `Validate that domain boundaries are respected according to defined rules.
 
 This check helps ensure modules do not improperly depend on each other.
-Rules are typically defined within the code or a configuration file.
+Rules are read from architecture-rules.json, or from the file given with --config.
+
+With --format=json, sarif or junit the violations are written as a report for CI, e.g. to upload
+them as GitHub code scanning alerts. The command still exits with status 1 when there are violations.
+
+With --changed-only only the packages of the files changed since --since (HEAD by default) and of
+untracked files are validated, e.g. in a pre-commit hook. With --watch the working directory is
+validated again for the files changed while the command runs, until it is interrupted.
 
 Example:
   axiomod validator domain
+  axiomod validator domain ./internal --format=sarif --output=domain.sarif
+  axiomod validator domain --changed-only
 `,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Validating domain boundaries...")
-
-		// Perform validation
-		issues, err := ValidateDomainBoundaries()
-		if err != nil {
-			fmt.Printf("Domain boundary validation error: %v\n", err)
-			os.Exit(1)
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		configPath, _ := cmd.Flags().GetString("config")
+		format := reportFormat(cmd)
+		fmt.Fprintln(console, "Validating domain boundaries...")
+		if !applyChangedOnly(cmd, configPath) {
+			return
+		}
+		if watching(cmd, []string{"domain"}, configPath, defaultSQLPath, defaultAPIPath) {
+			return
 		}
 
-		if len(issues) > 0 {
-			fmt.Printf("Found %d domain boundary violations:\n", len(issues))
-			for i, issue := range issues {
-				fmt.Printf("%d. %s\n", i+1, issue)
+		if usesReport(cmd, format) {
+			report, err := DomainReport(path, configPath)
+			if err != nil {
+				fmt.Fprintf(console, "Domain boundary validation error: %v\n", err)
+				os.Exit(1)
 			}
+			emitReport(cmd, format, report)
+			return
+		}
+
+		// Perform validation
+		if passed, err := RunDomainValidation(path, configPath); !passed {
+			fmt.Printf("Domain boundary validation failed: %v\n", err)
 			os.Exit(1)
-		} else {
-			fmt.Println("Domain boundary validation passed successfully.")
 		}
+		fmt.Println("Domain boundary validation passed successfully.")
 	},
 }
 
 // NewDomainCmd returns the validator domain command.
 func NewDomainCmd() *cobra.Command {
-	// Add flags if needed, e.g., for configuration
-	// domainCmd.Flags().StringP("config", "c", "", "Path to domain rules configuration")
 	return domainCmd
 }
 
 func init() {
+	domainCmd.Flags().StringP("config", "c", "", "Path to the architecture rules JSON file")
+	addReportFlags(domainCmd)
+	addIncrementalFlags(domainCmd)
+
 	// Add subcommands to the parent validatorCmd
 	validatorCmd.AddCommand(domainCmd)
 }
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"go/parser"
-	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
@@ -34,6 +33,7 @@ type CrossDomainDependency struct {
 type Import struct {
 	Path string
 	File string
+	Line int
 }
 
 // DomainValidationSummary holds summary data for the validation
@@ -41,6 +41,7 @@ type DomainValidationSummary struct {
 	FilesScanned       int
 	ImportsChecked     int
 	TotalViolations    int
+	TotalWarnings      int
 	ViolationsBySource map[string]int
 	ViolationsByTarget map[string]int
 	ViolationsByType   map[string]int
@@ -51,24 +52,98 @@ type ViolationDetail struct {
 	Source        string
 	Target        string
 	ViolationType string
+	File          string
+	Line          int
+	Message       string
+	// Level of the violation, error unless the rule configuration lowers it
+	Level string
 }
 
 // RunDomainValidation validates domain boundaries in the codebase
 func RunDomainValidation(path string, configPath string) (bool, error) {
-	fmt.Println("Domain Boundary Validator")
-	fmt.Println("========================")
+	fmt.Fprintln(console, "Domain Boundary Validator")
+	fmt.Fprintln(console, "========================")
+
+	violations, summary, err := collectDomainViolations(path, configPath)
+	if err != nil {
+		return false, err
+	}
+
+	// Print the summary report before details
+	printDomainSummaryReport(summary)
+
+	if summary.TotalWarnings > 0 {
+		fmt.Fprintln(console, "\n⚠️  Domain boundary warnings:")
+		for _, v := range violations {
+			if v.Level == LevelWarning {
+				fmt.Fprintf(console, "%s (in file %s)\n", v.Message, v.File)
+			}
+		}
+	}
+
+	if summary.TotalViolations > 0 {
+		fmt.Fprintln(console, "\n❌ Domain boundary violations found:")
+		for _, v := range violations {
+			if v.Level == LevelError {
+				fmt.Fprintf(console, "%s (in file %s)\n", v.Message, v.File)
+			}
+		}
+
+		// Print the summary again after violations
+		fmt.Fprintln(console, "\nDomain Boundary Validation Summary:")
+		printDomainSummaryReport(summary)
+
+		return false, fmt.Errorf("domain validation failed with %d violations", summary.TotalViolations)
+	}
+
+	fmt.Fprintln(console, "\n✅ Domain boundaries are valid!")
+	return true, nil
+}
+
+// DomainReport validates domain boundaries in the codebase and returns its violations
+func DomainReport(path string, configPath string) (*Report, error) {
+	violations, _, err := collectDomainViolations(path, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Validator: "domain"}
+	for _, v := range violations {
+		report.Add(v.ViolationType, v.Level, v.Message, v.File, v.Line, 0)
+	}
+	return report, nil
+}
+
+// collectDomainViolations checks the imports of the Go files under path against the
+// architecture rules
+func collectDomainViolations(path string, configPath string) ([]ViolationDetail, DomainValidationSummary, error) {
+	summary := DomainValidationSummary{
+		ViolationsBySource: make(map[string]int),
+		ViolationsByTarget: make(map[string]int),
+		ViolationsByType:   make(map[string]int),
+	}
 
 	// Check if the path exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return false, fmt.Errorf("error: Path '%s' does not exist", path)
+		return nil, summary, fmt.Errorf("error: Path '%s' does not exist", path)
+	}
+
+	// Modules are identified from paths relative to the working directory, so walk absolute paths
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, summary, fmt.Errorf("error resolving path: %v", err)
 	}
 
-	fmt.Printf("Validating domain boundaries in: %s\n", path)
+	fmt.Fprintf(console, "Validating domain boundaries in: %s\n", path)
 
 	// Load architecture rules
 	rules, err := loadDomainRules(configPath)
 	if err != nil {
-		return false, fmt.Errorf("error loading architecture rules: %v", err)
+		return nil, summary, fmt.Errorf("error loading architecture rules: %v", err)
+	}
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, summary, err
 	}
 
 	// Find all Go files in the given path
@@ -85,38 +160,31 @@ func RunDomainValidation(path string, configPath string) (bool, error) {
 			}
 		}
 
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+		if !info.IsDir() && strings.HasSuffix(path, ".go") && scope.includes(path) {
 			goFiles = append(goFiles, path)
 		}
 		return nil
 	})
 	if err != nil {
-		return false, fmt.Errorf("error walking directory: %v", err)
+		return nil, summary, fmt.Errorf("error walking directory: %v", err)
 	}
 
-	fmt.Printf("Found %d Go files to check\n", len(goFiles))
-
-	// Initialize summary
-	summary := DomainValidationSummary{
-		FilesScanned:       len(goFiles),
-		ViolationsBySource: make(map[string]int),
-		ViolationsByTarget: make(map[string]int),
-		ViolationsByType:   make(map[string]int),
-	}
+	fmt.Fprintf(console, "Found %d Go files to check\n", len(goFiles))
+	summary.FilesScanned = len(goFiles)
 
 	// Extract imports from all Go files
 	imports := make(map[string][]Import)
 	for _, file := range goFiles {
 		fileImports, err := extractImports(file)
 		if err != nil {
-			fmt.Printf("Error extracting imports from %s: %v\n", file, err)
+			fmt.Fprintf(console, "Error extracting imports from %s: %v\n", file, err)
 			continue
 		}
 
 		summary.ImportsChecked += len(fileImports)
 
 		// Identify the domain/module of the current file
-		module := identifyModule(file, rules.AllowedDependencies)
+		module := identifyModule(file)
 		if module == "" {
 			continue // Skip files that don't match any module
 		}
@@ -124,51 +192,48 @@ func RunDomainValidation(path string, configPath string) (bool, error) {
 		imports[module] = append(imports[module], fileImports...)
 	}
 
-	// Validate imports against rules
-	validationErrors, violationDetails := validateImportsWithDetails(imports, rules)
-	summary.TotalViolations = len(validationErrors)
+	// Validate imports against rules, at the levels of the rule policy
+	var violations []ViolationDetail
+	for _, detail := range validateImportsWithDetails(imports, rules) {
+		detail.Level = policy.level(ruleID("domain", detail.ViolationType), LevelError, detail.File, detail.Line)
+		if detail.Level == "" {
+			continue
+		}
+		violations = append(violations, detail)
+	}
 
 	// Count violations by source, target and type
-	for _, detail := range violationDetails {
+	for _, detail := range violations {
+		if detail.Level == LevelWarning {
+			summary.TotalWarnings++
+			continue
+		}
+		summary.TotalViolations++
 		summary.ViolationsBySource[detail.Source]++
 		summary.ViolationsByTarget[detail.Target]++
 		summary.ViolationsByType[detail.ViolationType]++
 	}
 
-	// Print the summary report before details
-	printDomainSummaryReport(summary)
-
-	if len(validationErrors) > 0 {
-		fmt.Println("\n❌ Domain boundary violations found:")
-		for _, err := range validationErrors {
-			fmt.Println(err)
-		}
-
-		// Print the summary again after violations
-		fmt.Println("\nDomain Boundary Validation Summary:")
-		printDomainSummaryReport(summary)
-
-		return false, fmt.Errorf("domain validation failed with %d violations", summary.TotalViolations)
-	}
-
-	fmt.Println("\n✅ Domain boundaries are valid!")
-	return true, nil
+	return violations, summary, nil
 }
 
 // printDomainSummaryReport prints a summary of the validation
 func printDomainSummaryReport(summary DomainValidationSummary) {
-	fmt.Printf("  Files scanned: %d\n", summary.FilesScanned)
-	fmt.Printf("  Imports checked: %d\n", summary.ImportsChecked)
-	fmt.Printf("  Total violations: %d\n", summary.TotalViolations)
+	fmt.Fprintf(console, "  Files scanned: %d\n", summary.FilesScanned)
+	fmt.Fprintf(console, "  Imports checked: %d\n", summary.ImportsChecked)
+	fmt.Fprintf(console, "  Total violations: %d\n", summary.TotalViolations)
+	if summary.TotalWarnings > 0 {
+		fmt.Fprintf(console, "  Total warnings: %d\n", summary.TotalWarnings)
+	}
 
 	if summary.TotalViolations > 0 {
-		fmt.Println("\nViolations by type:")
+		fmt.Fprintln(console, "\nViolations by type:")
 		printTopDomainViolations(summary.ViolationsByType)
 
-		fmt.Println("\nTop violating source modules:")
+		fmt.Fprintln(console, "\nTop violating source modules:")
 		printTopDomainViolations(summary.ViolationsBySource)
 
-		fmt.Println("\nTop violated target modules:")
+		fmt.Fprintln(console, "\nTop violated target modules:")
 		printTopDomainViolations(summary.ViolationsByTarget)
 	}
 }
@@ -202,7 +267,7 @@ func printTopDomainViolations(violations map[string]int) {
 	}
 
 	for i := 0; i < count; i++ {
-		fmt.Printf("  - %s: %d\n", items[i].item, items[i].count)
+		fmt.Fprintf(console, "  - %s: %d\n", items[i].item, items[i].count)
 	}
 }
 
@@ -294,8 +359,7 @@ func getDefaultDomainRules() *ArchitectureRules {
 
 // extractImports extracts all imports from a Go file
 func extractImports(filePath string) ([]Import, error) {
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ImportsOnly)
+	fset, node, err := parsedFiles.parse(filePath, parser.ImportsOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -310,6 +374,7 @@ func extractImports(filePath string) ([]Import, error) {
 			imports = append(imports, Import{
 				Path: path,
 				File: filePath,
+				Line: fset.Position(imp.Pos()).Line,
 			})
 		}
 	}
@@ -317,68 +382,66 @@ func extractImports(filePath string) ([]Import, error) {
 	return imports, nil
 }
 
-// identifyModule identifies which module a file belongs to
-func identifyModule(filePath string, allowedDeps map[string][]string) string {
+// identifyModule returns the module of a file, the directory of its package relative to the
+// working directory, e.g. examples/example/delivery/http or platform/server
+func identifyModule(filePath string) string {
 	// Get relative path from working directory
 	workDir, err := os.Getwd()
 	if err != nil {
 		return ""
 	}
 
-	relPath, err := filepath.Rel(workDir, filePath)
-	if err != nil {
+	relPath, err := filepath.Rel(workDir, filepath.Dir(filePath))
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
 		return ""
 	}
 
 	// Normalize path separators
-	relPath = filepath.ToSlash(relPath)
-
-	// Check if the file is in examples/example structure
-	if strings.Contains(relPath, "examples/example/") {
-		parts := strings.Split(relPath, "/")
-		if len(parts) >= 4 {
-			// For deeper paths like examples/example/delivery/http
-			if len(parts) >= 5 && (parts[3] == "delivery" || parts[3] == "infrastructure") {
-				return "examples/example/" + parts[3] + "/" + parts[4]
-			}
-			// For paths like examples/example/entity, examples/example/repository, etc.
-			return "examples/example/" + parts[3]
-		}
-		return "examples/example"
-	} else if strings.HasPrefix(relPath, "platform/") {
-		parts := strings.Split(relPath, "/")
-		if len(parts) >= 2 {
-			return "platform/" + parts[1]
-		}
-		return "platform"
-	} else if strings.HasPrefix(relPath, "plugins/") {
-		parts := strings.Split(relPath, "/")
-		if len(parts) >= 2 {
-			return "plugins/" + parts[1]
-		}
-		return "plugins"
+	return filepath.ToSlash(relPath)
+}
+
+// moduleDomain returns the domain of a module, e.g. examples/example for
+// examples/example/delivery/http, or "" for modules outside of the domains
+func moduleDomain(module string) string {
+	parts := strings.Split(module, "/")
+	if len(parts) >= 2 && (parts[0] == "examples" || parts[0] == "internal") {
+		return parts[0] + "/" + parts[1]
 	}
+	return ""
+}
 
-	// Legacy code paths - check for simple module names
-	for module := range allowedDeps {
-		if !strings.Contains(module, "/") {
-			// Simple module (legacy code)
-			if strings.Contains(relPath, "internal/"+module+"/") ||
-				strings.Contains(relPath, "/"+module+"/") {
-				return module
-			}
+// moduleRule returns the key of the allowed dependencies a module follows. The key matching the
+// leftmost, then longest, sequence of elements of the module applies, so
+// examples/example/delivery/http/middleware follows examples/example/delivery/http and
+// platform/server follows platform/*.
+func moduleRule(module string, allowedDeps map[string][]string) string {
+	_, key := matchLayer(module, allowedDeps)
+	return key
+}
+
+// isAllowedModule reports whether one of the allowed entries matches the imported module, a
+// module it is nested in or the key of its rule
+func isAllowedModule(allowed []string, importModule, importRule string) bool {
+	for _, entry := range allowed {
+		if entry == importModule || strings.HasPrefix(importModule, entry+"/") ||
+			isWildcardMatch(entry, importModule) || (importRule != "" && entry == importRule) {
+			return true
 		}
 	}
-
-	return ""
+	return false
 }
 
-// validateImportsWithDetails validates imports and returns errors and detailed violation info
-func validateImportsWithDetails(imports map[string][]Import, rules *ArchitectureRules) ([]string, []ViolationDetail) {
-	var errors []string
+// validateImportsWithDetails validates imports and returns the details of the violations.
+// Imports of another domain follow the domain rules, the other imports follow the allowed
+// dependencies of the rule of the module. Modules without a rule, such as the root of a domain
+// wiring its layers, and imports within the same rule are not restricted.
+func validateImportsWithDetails(imports map[string][]Import, rules *ArchitectureRules) []ViolationDetail {
 	var details []ViolationDetail
 
 	for module, moduleImports := range imports {
+		rule := moduleRule(module, rules.AllowedDependencies)
+		domain := moduleDomain(module)
+
 		for _, imp := range moduleImports {
 			// Convert import path to module format
 			importModule := convertImportToModule(imp.Path)
@@ -391,55 +454,43 @@ func validateImportsWithDetails(imports map[string][]Import, rules *Architecture
 				continue
 			}
 
-			// Special case for domain-to-domain dependencies
-			if (strings.HasPrefix(module, "examples/example/") && strings.HasPrefix(importModule, "examples/example/")) ||
-				(strings.HasPrefix(module, "domain/") && strings.HasPrefix(importModule, "domain/")) {
-				if !validateDomainDependency(module, importModule, rules) {
-					errors = append(errors, fmt.Sprintf("Cross-domain dependency not allowed: '%s' should not import '%s' (in file %s)",
-						module, importModule, imp.File))
+			// Imports leaving the domain follow the domain rules
+			importDomain := moduleDomain(importModule)
+			if domain != "" && importDomain != domain {
+				if validateDomainDependency(domain, importModule, rules) {
+					continue
+				}
+				if importDomain != "" {
 					details = append(details, ViolationDetail{
 						Source:        module,
 						Target:        importModule,
 						ViolationType: "cross-domain-dependency",
+						File:          imp.File,
+						Line:          imp.Line,
+						Message:       fmt.Sprintf("Cross-domain dependency not allowed: '%s' should not import '%s'", module, importModule),
 					})
 					continue
 				}
-			} else {
-				// Check if the import is allowed based on general rules
-				allowed := false
-				if allowedImports, ok := rules.AllowedDependencies[module]; ok {
-					for _, allowedImport := range allowedImports {
-						// Check direct match
-						if importModule == allowedImport {
-							allowed = true
-							break
-						}
-
-						// Check pattern match (e.g., shared/* matches shared/errors, shared/logger, etc.)
-						if strings.Contains(allowedImport, "*") {
-							prefix := strings.Split(allowedImport, "*")[0]
-							if strings.HasPrefix(importModule, prefix) {
-								allowed = true
-								break
-							}
-						}
-					}
-				}
+			}
 
-				if !allowed {
-					errors = append(errors, fmt.Sprintf("Module '%s' is not allowed to import '%s' (in file %s)",
-						module, importModule, imp.File))
-					details = append(details, ViolationDetail{
-						Source:        module,
-						Target:        importModule,
-						ViolationType: "layer-dependency",
-					})
-				}
+			// Check if the import is allowed based on the rule of the module
+			importRule := moduleRule(importModule, rules.AllowedDependencies)
+			if rule == "" || rule == importRule || isAllowedModule(rules.AllowedDependencies[rule], importModule, importRule) {
+				continue
 			}
+
+			details = append(details, ViolationDetail{
+				Source:        module,
+				Target:        importModule,
+				ViolationType: "layer-dependency",
+				File:          imp.File,
+				Line:          imp.Line,
+				Message:       fmt.Sprintf("Module '%s' is not allowed to import '%s'", module, importModule),
+			})
 		}
 	}
 
-	return errors, details
+	return details
 }
 
 // validateDomainDependency checks if a domain-to-domain dependency is allowed
@@ -469,32 +520,8 @@ func validateDomainDependency(source, target string, rules *ArchitectureRules) b
 	return false
 }
 
-// convertImportToModule converts an import path to a module name
+// convertImportToModule converts an import path of the framework to a module, the path of its
+// package in the repository
 func convertImportToModule(importPath string) string {
-	if strings.Contains(importPath, "/examples/example/") {
-		parts := strings.Split(importPath, "/examples/example/")
-		if len(parts) >= 2 {
-			subParts := strings.Split(parts[1], "/")
-			if len(subParts) >= 2 && (subParts[0] == "delivery" || subParts[0] == "infrastructure") {
-				return "examples/example/" + subParts[0] + "/" + subParts[1]
-			}
-			return "examples/example/" + subParts[0]
-		}
-	} else if strings.HasPrefix(importPath, "github.com/axiomod/axiomod/platform/") {
-		return strings.TrimPrefix(importPath, "github.com/axiomod/axiomod/")
-	} else if strings.HasPrefix(importPath, "github.com/axiomod/axiomod/plugins/") {
-		return strings.TrimPrefix(importPath, "github.com/axiomod/axiomod/")
-	} else if strings.HasPrefix(importPath, "github.com/axiomod/axiomod/framework/") {
-		return strings.TrimPrefix(importPath, "github.com/axiomod/axiomod/")
-	} else if strings.Contains(importPath, "/internal/") {
-		parts := strings.Split(importPath, "/internal/")
-		if len(parts) >= 2 {
-			subParts := strings.Split(parts[1], "/")
-			if len(subParts) >= 1 {
-				return subParts[0]
-			}
-		}
-	}
-
-	return ""
+	return strings.TrimPrefix(importPath, "github.com/axiomod/axiomod/")
 }
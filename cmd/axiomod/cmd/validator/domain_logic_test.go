@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateImportsWithDetails(t *testing.T) {
+	rules := &ArchitectureRules{
+		AllowedDependencies: map[string][]string{
+			"examples/example/entity":               {},
+			"examples/example/repository":           {"examples/example/entity"},
+			"examples/example/delivery/http":        {"examples/example/usecase", "examples/example/entity"},
+			"examples/example/infrastructure/cache": {"examples/example/entity"},
+			"delivery/http":                         {"usecase", "entity"},
+			"usecase":                               {"entity"},
+			"platform/*":                            {"framework/*"},
+		},
+		DomainRules: DomainRules{
+			AllowedCrossDomainImports: []CrossDomainDependency{{Source: "examples/example", Target: "platform"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		module string
+		imp    string
+		want   string
+	}{
+		{"layer of the same domain", "examples/example/infrastructure/cache", "examples/example/entity", ""},
+		{"sub-package of a layer", "examples/example/delivery/http/middleware", "examples/example/usecase", ""},
+		{"same layer", "examples/example/delivery/http", "examples/example/delivery/http/middleware", ""},
+		{"layer not allowed", "examples/example/delivery/http", "examples/example/repository", "layer-dependency"},
+		{"allowed outside the domain", "examples/example/infrastructure/cache", "platform/observability", ""},
+		{"domain root wiring its layers", "examples/example", "examples/example/delivery/http", ""},
+		{"other domain", "examples/example/delivery/http", "examples/shop/usecase", "cross-domain-dependency"},
+		{"layer key of another domain", "internal/shop/delivery/http", "internal/shop/usecase", ""},
+		{"layer key not allowed", "internal/shop/delivery/http", "framework/errors", "layer-dependency"},
+		{"wildcard key", "platform/server", "framework/config", ""},
+		{"same wildcard key", "platform/bootstrap", "platform/server", ""},
+		{"wildcard key not allowed", "platform/server", "plugins", "layer-dependency"},
+		{"package without a key", "framework/errors", "platform/server", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imports := map[string][]Import{tt.module: {{Path: "github.com/axiomod/axiomod/" + tt.imp, File: "file.go", Line: 3}}}
+			details := validateImportsWithDetails(imports, rules)
+			if tt.want == "" {
+				assert.Empty(t, details)
+				return
+			}
+			if assert.Len(t, details, 1) {
+				assert.Equal(t, tt.want, details[0].ViolationType)
+				assert.Equal(t, tt.module, details[0].Source)
+				assert.Equal(t, tt.imp, details[0].Target)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// errorCodesCmd represents the validator error-codes command
+var errorCodesCmd = &cobra.Command{
+	Use:   "error-codes [path]",
+	Short: "Validate that handlers only return registered error codes",
+	Long: `Validate that the error codes returned by HTTP and gRPC handlers are declared in the
+error code registry, and that no code is declared by two modules.
+
+Codes are registered with errors.CodeDefinition values, e.g. through errors.Codes in a
+module's fx options. Handlers are the files under delivery/ directories and files named
+*handler*.go. Codes that are not string literals or constants cannot be checked and are skipped.
+
+Example:
+  axiomod validator error-codes
+  axiomod validator error-codes ./internal
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		fmt.Println("Validating error codes...")
+
+		issues, err := ValidateErrorCodes(path)
+		if err != nil {
+			fmt.Printf("Error code validation error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(issues) > 0 {
+			fmt.Printf("Found %d error code issues:\n", len(issues))
+			for i, issue := range issues {
+				fmt.Printf("%d. %s\n", i+1, issue)
+			}
+			os.Exit(1)
+		} else {
+			fmt.Println("Error code validation passed successfully.")
+		}
+	},
+}
+
+// NewErrorCodesCmd returns the validator error-codes command.
+func NewErrorCodesCmd() *cobra.Command {
+	return errorCodesCmd
+}
+
+func init() {
+	// Add subcommands to the parent validatorCmd
+	validatorCmd.AddCommand(errorCodesCmd)
+}
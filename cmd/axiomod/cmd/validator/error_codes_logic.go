@@ -0,0 +1,200 @@
+package validator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// errorCodeUse is an error code returned by a handler
+type errorCodeUse struct {
+	code     string
+	position token.Position
+}
+
+// errorCodeScan holds what ValidateErrorCodes collects from the source files
+type errorCodeScan struct {
+	fset       *token.FileSet
+	constants  map[string]string              // string constants by name
+	declared   map[string]map[string]struct{} // declaring directories by code
+	pending    []ast.Expr                     // declared codes to resolve once all constants are known
+	pendingDir []string
+	uses       []ast.Expr // handler codes to resolve once all constants are known
+}
+
+// ValidateErrorCodes checks that handlers under root only return registered error codes and
+// that no code is declared in two places
+func ValidateErrorCodes(root string) ([]string, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+
+	scan := &errorCodeScan{
+		fset:      token.NewFileSet(),
+		constants: make(map[string]string),
+		declared:  make(map[string]map[string]struct{}),
+	}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "vendor", "node_modules", "testdata", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(scan.fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		scan.inspect(file, filepath.Dir(path), isHandlerFile(path))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []string
+	registered := make(map[string]bool)
+	for _, code := range errors.BuiltinCodes() {
+		registered[code] = true
+	}
+	for i, expr := range scan.pending {
+		code, ok := scan.resolve(expr)
+		if !ok {
+			continue
+		}
+		registered[code] = true
+		if scan.declared[code] == nil {
+			scan.declared[code] = make(map[string]struct{})
+		}
+		scan.declared[code][scan.pendingDir[i]] = struct{}{}
+	}
+
+	for code, dirs := range scan.declared {
+		if len(dirs) < 2 {
+			continue
+		}
+		var names []string
+		for dir := range dirs {
+			names = append(names, dir)
+		}
+		sort.Strings(names)
+		issues = append(issues, fmt.Sprintf("error code %q is declared in more than one place: %s", code, strings.Join(names, ", ")))
+	}
+
+	for _, expr := range scan.uses {
+		code, ok := scan.resolve(expr)
+		if !ok || registered[code] {
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("%s: handler returns unregistered error code %q", scan.fset.Position(expr.Pos()), code))
+	}
+
+	sort.Strings(issues)
+	return issues, nil
+}
+
+// inspect collects the string constants, declared codes and, in handler files, returned codes of a file
+func (s *errorCodeScan) inspect(file *ast.File, dir string, handler bool) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					if value, ok := stringLiteral(n.Values[i]); ok {
+						s.constants[name.Name] = value
+					}
+				}
+			}
+		case *ast.CompositeLit:
+			if typeName(n.Type) == "CodeDefinition" {
+				s.declare(n, dir)
+			} else if array, ok := n.Type.(*ast.ArrayType); ok && typeName(array.Elt) == "CodeDefinition" {
+				// Elements of a []CodeDefinition literal may omit their type
+				for _, elt := range n.Elts {
+					if lit, ok := elt.(*ast.CompositeLit); ok && lit.Type == nil {
+						s.declare(lit, dir)
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if handler && typeName(n.Fun) == "WithCode" && len(n.Args) == 2 {
+				s.uses = append(s.uses, n.Args[1])
+			}
+		}
+		return true
+	})
+}
+
+// declare records the code of a CodeDefinition literal
+func (s *errorCodeScan) declare(lit *ast.CompositeLit, dir string) {
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Code" {
+				s.pending = append(s.pending, kv.Value)
+				s.pendingDir = append(s.pendingDir, dir)
+			}
+		} else if i == 0 {
+			s.pending = append(s.pending, elt)
+			s.pendingDir = append(s.pendingDir, dir)
+		}
+	}
+}
+
+// resolve returns the value of a string literal or of a string constant found in the scan
+func (s *errorCodeScan) resolve(expr ast.Expr) (string, bool) {
+	if value, ok := stringLiteral(expr); ok {
+		return value, true
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		value, ok := s.constants[e.Name]
+		return value, ok
+	case *ast.SelectorExpr:
+		value, ok := s.constants[e.Sel.Name]
+		return value, ok
+	}
+	return "", false
+}
+
+// stringLiteral returns the value of a string literal expression
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// typeName returns the unqualified name of an identifier or selector, e.g. WithCode for errors.WithCode
+func typeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+// isHandlerFile reports whether a file holds HTTP or gRPC handlers
+func isHandlerFile(path string) bool {
+	slashed := filepath.ToSlash(path)
+	return strings.Contains(slashed, "/delivery/") || strings.HasPrefix(slashed, "delivery/") ||
+		strings.Contains(strings.ToLower(filepath.Base(path)), "handler")
+}
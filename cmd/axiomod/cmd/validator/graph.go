@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// graphCmd represents the validator graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the dependency graph checked by the architecture validator",
+	Long: `Export the dependency graph of the project packages, or of its domains, as checked by the
+architecture validator. Imports violating the architecture rules are highlighted, and import cycles
+between domains are detected.
+
+Formats:
+  dot      Graphviz DOT, rendered with e.g. dot -Tsvg
+  mermaid  Mermaid flowchart, rendered by GitHub in Markdown files
+  json     Nodes, edges, violations and cycles, for other tools
+
+The command exits with status 1 when the domains import each other in a cycle.
+
+Example:
+  axiomod validator graph > dependencies.dot
+  axiomod validator graph --level=domain --format=mermaid --output=docs/dependencies.mmd
+  axiomod validator graph --format=json --config=path/to/rules.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configPath, _ := cmd.Flags().GetString("config")
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		level, _ := cmd.Flags().GetString("level")
+		format, level = strings.ToLower(format), strings.ToLower(level)
+
+		// The graph alone is written to stdout
+		if output == "" {
+			console = os.Stderr
+		}
+		switch format {
+		case GraphFormatDOT, GraphFormatMermaid, GraphFormatJSON:
+		default:
+			fmt.Fprintf(console, "Unsupported format %q (use dot, mermaid or json)\n", format)
+			os.Exit(1)
+		}
+		if level != GraphLevelPackage && level != GraphLevelDomain {
+			fmt.Fprintf(console, "Unsupported level %q (use package or domain)\n", level)
+			os.Exit(1)
+		}
+
+		rootDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(console, "Failed to get current directory: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(console, "Building the dependency graph...")
+		graph, err := BuildDependencyGraph(rootDir, configPath, level)
+		if err != nil {
+			fmt.Fprintf(console, "Dependency graph error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if output == "" {
+			err = WriteGraph(os.Stdout, format, graph)
+		} else {
+			err = writeGraphFile(output, format, graph)
+		}
+		if err != nil {
+			fmt.Fprintf(console, "Error writing graph: %v\n", err)
+			os.Exit(1)
+		}
+		if output != "" {
+			fmt.Fprintf(console, "Graph written to %s\n", output)
+		}
+
+		violations := 0
+		for _, edge := range graph.Edges {
+			if len(edge.Violations) > 0 {
+				violations++
+			}
+		}
+		fmt.Fprintf(console, "%d nodes, %d edges, %d edges violating the architecture rules\n", len(graph.Nodes), len(graph.Edges), violations)
+
+		if len(graph.Cycles) > 0 {
+			fmt.Fprintf(console, "Found %d import cycles between domains:\n", len(graph.Cycles))
+			for _, cycle := range graph.Cycles {
+				fmt.Fprintf(console, "  - %s\n", strings.Join(cycle, " <-> "))
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+// NewGraphCmd returns the validator graph command.
+func NewGraphCmd() *cobra.Command {
+	return graphCmd
+}
+
+// writeGraphFile writes the graph in the format to a file
+func writeGraphFile(path string, format string, graph *DependencyGraph) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteGraph(file, format, graph); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func init() {
+	graphCmd.Flags().StringP("config", "c", "", "Path to the architecture rules JSON file")
+	graphCmd.Flags().String("format", GraphFormatDOT, "Output format: dot, mermaid or json")
+	graphCmd.Flags().StringP("output", "o", "", "Write the graph to a file instead of stdout")
+	graphCmd.Flags().String("level", GraphLevelPackage, "Graph nodes: package or domain")
+
+	// Add subcommands to the parent validatorCmd
+	validatorCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,408 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats of the dependency graph
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+	GraphFormatJSON    = "json"
+)
+
+// Levels of the dependency graph
+const (
+	GraphLevelPackage = "package"
+	GraphLevelDomain  = "domain"
+)
+
+// DependencyGraph is the import graph the architecture validator checks, between the packages or
+// the domains of the modules under a directory
+type DependencyGraph struct {
+	Level string      `json:"level"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+	// Cycles are the import cycles between domains, by domain label
+	Cycles [][]string `json:"cycles"`
+}
+
+// GraphNode is a package, or a domain
+type GraphNode struct {
+	// ID is the import path of the package, or of the directory of the domain
+	ID string `json:"id"`
+	// Label is the path relative to the module
+	Label  string `json:"label"`
+	Module string `json:"module"`
+	Domain string `json:"domain,omitempty"`
+	Layer  string `json:"layer,omitempty"`
+}
+
+// GraphEdge is the imports of a node by another one
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Imports is the number of import statements
+	Imports int `json:"imports"`
+	// Violations are the rules the imports violate
+	Violations []string `json:"violations,omitempty"`
+	// Cycle reports whether the edge is part of an import cycle between domains
+	Cycle bool `json:"cycle,omitempty"`
+}
+
+// graphImport is an import between two packages of a module, with the rule it violates
+type graphImport struct {
+	from, to  *projectPackage
+	violation string
+}
+
+// BuildDependencyGraph builds the dependency graph of the packages under rootDir at a level,
+// with the violations of the architecture rules and the import cycles between domains
+func BuildDependencyGraph(rootDir string, configPath string, level string) (*DependencyGraph, error) {
+	config := loadConfiguration(configPath)
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := loadProjectPackages(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*projectPackage, len(pkgs))
+	for _, pkg := range pkgs {
+		byPath[pkg.Path] = pkg
+	}
+
+	var imports []graphImport
+	for _, pkg := range pkgs {
+	files:
+		for _, file := range pkg.Files {
+			for _, exception := range config.Exceptions {
+				if strings.Contains(file.Path, exception) {
+					continue files
+				}
+			}
+			for _, imp := range file.Imports {
+				target := moduleImport(byPath, pkg, imp.Path)
+				if target == nil {
+					continue
+				}
+				var violation string
+				if allowed, category := checkLayerDependency(pkg, target, config); !allowed {
+					if policy.level(ruleID("architecture", category), LevelError, file.Path, imp.Line) != "" {
+						violation = ruleID("architecture", category)
+					}
+				}
+				imports = append(imports, graphImport{from: pkg, to: target, violation: violation})
+			}
+		}
+	}
+
+	graph := &DependencyGraph{Level: level, Nodes: []GraphNode{}, Edges: []GraphEdge{}, Cycles: [][]string{}}
+	nodes := make(map[string]GraphNode)
+	edges := make(map[[2]string]*GraphEdge)
+	addNode := func(pkg *projectPackage) string {
+		node := graphNode(pkg, level, config)
+		if _, ok := nodes[node.ID]; !ok {
+			nodes[node.ID] = node
+		}
+		return node.ID
+	}
+	for _, pkg := range pkgs {
+		addNode(pkg)
+	}
+	for _, imp := range imports {
+		from, to := addNode(imp.from), addNode(imp.to)
+		if from == to {
+			continue
+		}
+		edge, ok := edges[[2]string{from, to}]
+		if !ok {
+			edge = &GraphEdge{From: from, To: to}
+			edges[[2]string{from, to}] = edge
+		}
+		edge.Imports++
+		if imp.violation != "" && !containsString(edge.Violations, imp.violation) {
+			edge.Violations = append(edge.Violations, imp.violation)
+		}
+	}
+
+	// Cycles are detected between the domains of the architecture rules, whatever the level of
+	// the graph
+	domainEdges := make(map[string]map[string]bool)
+	domainLabels := make(map[string]string)
+	for _, imp := range imports {
+		from, to := graphNode(imp.from, GraphLevelDomain, config), graphNode(imp.to, GraphLevelDomain, config)
+		if from.Domain == "" || to.Domain == "" || from.ID == to.ID {
+			continue
+		}
+		domainLabels[from.ID], domainLabels[to.ID] = from.Label, to.Label
+		if domainEdges[from.ID] == nil {
+			domainEdges[from.ID] = make(map[string]bool)
+		}
+		domainEdges[from.ID][to.ID] = true
+	}
+	componentOf := make(map[string]int)
+	for i, component := range findCycles(domainEdges) {
+		labels := make([]string, len(component))
+		for j, id := range component {
+			componentOf[id] = i + 1
+			labels[j] = domainLabels[id]
+		}
+		graph.Cycles = append(graph.Cycles, labels)
+	}
+	for _, imp := range imports {
+		from, to := graphNode(imp.from, GraphLevelDomain, config).ID, graphNode(imp.to, GraphLevelDomain, config).ID
+		if from == to || componentOf[from] == 0 || componentOf[from] != componentOf[to] {
+			continue
+		}
+		if edge, ok := edges[[2]string{addNode(imp.from), addNode(imp.to)}]; ok {
+			edge.Cycle = true
+		}
+	}
+
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	for _, edge := range edges {
+		sort.Strings(edge.Violations)
+		graph.Edges = append(graph.Edges, *edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph, nil
+}
+
+// graphNode returns the node of a package at a level. The domain of a package outside the domains
+// of the architecture rules is the first element of its path, e.g. platform.
+func graphNode(pkg *projectPackage, level string, config Configuration) GraphNode {
+	layer := classifyPackage(pkg.Rel, config)
+	if level == GraphLevelPackage {
+		return GraphNode{ID: pkg.Path, Label: pkg.Rel, Module: pkg.Module, Domain: layer.Domain, Layer: layer.Layer}
+	}
+
+	domain := layer.Domain
+	if domain == "" {
+		domain, _, _ = strings.Cut(pkg.Rel, "/")
+	}
+	id := pkg.Module
+	if domain != "." {
+		id += "/" + domain
+	}
+	return GraphNode{ID: id, Label: domain, Module: pkg.Module, Domain: layer.Domain}
+}
+
+// findCycles returns the strongly connected components of more than one node of a graph, found
+// with Tarjan's algorithm. Nodes are sorted within and across components.
+func findCycles(edges map[string]map[string]bool) [][]string {
+	var ids []string
+	seen := make(map[string]bool)
+	for from, targets := range edges {
+		for _, id := range append([]string{from}, sortedKeys(targets)...) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, target := range sortedKeys(edges[id]) {
+			if _, ok := index[target]; !ok {
+				visit(target)
+				lowLink[id] = min(lowLink[id], lowLink[target])
+			} else if onStack[target] {
+				lowLink[id] = min(lowLink[id], index[target])
+			}
+		}
+
+		if lowLink[id] != index[id] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == id {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			visit(id)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// sortedKeys returns the keys of a set, sorted
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteGraph writes a dependency graph in a format
+func WriteGraph(w io.Writer, format string, graph *DependencyGraph) error {
+	switch format {
+	case GraphFormatDOT:
+		return writeGraphDOT(w, graph)
+	case GraphFormatMermaid:
+		return writeGraphMermaid(w, graph)
+	case GraphFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	default:
+		return fmt.Errorf("unsupported graph format %q, expected %s, %s or %s", format, GraphFormatDOT, GraphFormatMermaid, GraphFormatJSON)
+	}
+}
+
+// graphClusters groups the nodes of a graph by module, then by domain at the package level
+func graphClusters(graph *DependencyGraph) (keys []string, clusters map[string][]GraphNode) {
+	clusters = make(map[string][]GraphNode)
+	for _, node := range graph.Nodes {
+		key := node.Module
+		if graph.Level == GraphLevelPackage && node.Domain != "" {
+			key += "/" + node.Domain
+		}
+		if _, ok := clusters[key]; !ok {
+			keys = append(keys, key)
+		}
+		clusters[key] = append(clusters[key], node)
+	}
+	sort.Strings(keys)
+	return keys, clusters
+}
+
+// writeGraphDOT writes a dependency graph in the Graphviz DOT language. Edges violating the
+// architecture rules are red, edges of import cycles are bold and orange.
+func writeGraphDOT(w io.Writer, graph *DependencyGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	keys, clusters := graphClusters(graph)
+	for i, key := range keys {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", strconv.Quote(key))
+		for _, node := range clusters[key] {
+			fmt.Fprintf(&b, "    %s [label=%s];\n", strconv.Quote(node.ID), strconv.Quote(node.Label))
+		}
+		b.WriteString("  }\n")
+	}
+
+	for _, edge := range graph.Edges {
+		var attributes []string
+		switch {
+		case len(edge.Violations) > 0:
+			attributes = append(attributes, "color=red", "fontcolor=red", "label="+strconv.Quote(strings.Join(edge.Violations, "\n")))
+		case edge.Cycle:
+			attributes = append(attributes, "color=orange")
+		}
+		if edge.Cycle {
+			attributes = append(attributes, "style=bold")
+		}
+		fmt.Fprintf(&b, "  %s -> %s", strconv.Quote(edge.From), strconv.Quote(edge.To))
+		if len(attributes) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attributes, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeGraphMermaid writes a dependency graph as a Mermaid flowchart. Edges violating the
+// architecture rules are red, edges of import cycles are orange.
+func writeGraphMermaid(w io.Writer, graph *DependencyGraph) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	// Mermaid IDs cannot contain slashes, so nodes are numbered
+	ids := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+	keys, clusters := graphClusters(graph)
+	for i, key := range keys {
+		fmt.Fprintf(&b, "  subgraph c%d[\"%s\"]\n", i, mermaidText(key))
+		for _, node := range clusters[key] {
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", ids[node.ID], mermaidText(node.Label))
+		}
+		b.WriteString("  end\n")
+	}
+
+	var violations, cycles []string
+	for i, edge := range graph.Edges {
+		arrow := "-->"
+		if len(edge.Violations) > 0 {
+			arrow = fmt.Sprintf("-->|\"%s\"|", mermaidText(strings.Join(edge.Violations, ", ")))
+			violations = append(violations, strconv.Itoa(i))
+		} else if edge.Cycle {
+			cycles = append(cycles, strconv.Itoa(i))
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+	if len(violations) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red,color:red\n", strings.Join(violations, ","))
+	}
+	if len(cycles) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:orange,stroke-width:2px\n", strings.Join(cycles, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidText escapes the double quotes of a Mermaid label
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
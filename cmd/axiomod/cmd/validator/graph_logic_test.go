@@ -0,0 +1,170 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildCycleGraph builds the graph of the module of testdata/cycle at a level. Its shop and billing
+// domains import each other's entities; shop also imports the catalog domain and platform/clock.
+func buildCycleGraph(t *testing.T, level string) *DependencyGraph {
+	t.Helper()
+	root, err := filepath.Abs(filepath.Join("testdata", "cycle"))
+	require.NoError(t, err)
+	graph, err := BuildDependencyGraph(root, "", level)
+	require.NoError(t, err)
+	return graph
+}
+
+// graphEdges formats the edges of a graph as "from -> to (violations) cycle", without the module
+// path
+func graphEdges(graph *DependencyGraph) []string {
+	var edges []string
+	for _, edge := range graph.Edges {
+		line := strings.TrimPrefix(edge.From, "example.com/cycle/") + " -> " + strings.TrimPrefix(edge.To, "example.com/cycle/")
+		if len(edge.Violations) > 0 {
+			line += " (" + strings.Join(edge.Violations, ", ") + ")"
+		}
+		if edge.Cycle {
+			line += " cycle"
+		}
+		edges = append(edges, line)
+	}
+	return edges
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	t.Run("domain", func(t *testing.T) {
+		graph := buildCycleGraph(t, GraphLevelDomain)
+		assert.Equal(t, [][]string{{"internal/billing", "internal/shop"}}, graph.Cycles)
+
+		var labels []string
+		for _, node := range graph.Nodes {
+			labels = append(labels, node.Label)
+		}
+		assert.Equal(t, []string{"internal/billing", "internal/catalog", "internal/shop", "platform"}, labels)
+		assert.Equal(t, []string{
+			"internal/billing -> internal/shop (architecture/cross-domain-dependency) cycle",
+			"internal/shop -> internal/billing (architecture/cross-domain-dependency) cycle",
+			"internal/shop -> internal/catalog (architecture/cross-domain-dependency)",
+			"internal/shop -> platform (architecture/layer-dependency)",
+		}, graphEdges(graph))
+	})
+
+	t.Run("package", func(t *testing.T) {
+		// Cycles are between domains: the packages themselves do not import each other in a cycle
+		graph := buildCycleGraph(t, GraphLevelPackage)
+		assert.Equal(t, [][]string{{"internal/billing", "internal/shop"}}, graph.Cycles)
+		assert.Len(t, graph.Nodes, 6)
+		assert.Equal(t, []string{
+			"internal/billing/usecase -> internal/billing/entity",
+			"internal/billing/usecase -> internal/shop/entity (architecture/cross-domain-dependency) cycle",
+			"internal/shop/entity -> internal/billing/entity (architecture/cross-domain-dependency) cycle",
+			"internal/shop/usecase -> internal/catalog/entity (architecture/cross-domain-dependency)",
+			"internal/shop/usecase -> internal/shop/entity",
+			"internal/shop/usecase -> platform/clock (architecture/layer-dependency)",
+		}, graphEdges(graph))
+	})
+}
+
+func TestWriteGraph(t *testing.T) {
+	graph := buildCycleGraph(t, GraphLevelDomain)
+
+	t.Run("dot", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, WriteGraph(&out, GraphFormatDOT, graph))
+		assert.Equal(t, `digraph dependencies {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica"];
+  edge [fontname="Helvetica", fontsize=10];
+  subgraph cluster_0 {
+    label="example.com/cycle";
+    "example.com/cycle/internal/billing" [label="internal/billing"];
+    "example.com/cycle/internal/catalog" [label="internal/catalog"];
+    "example.com/cycle/internal/shop" [label="internal/shop"];
+    "example.com/cycle/platform" [label="platform"];
+  }
+  "example.com/cycle/internal/billing" -> "example.com/cycle/internal/shop" [color=red, fontcolor=red, label="architecture/cross-domain-dependency", style=bold];
+  "example.com/cycle/internal/shop" -> "example.com/cycle/internal/billing" [color=red, fontcolor=red, label="architecture/cross-domain-dependency", style=bold];
+  "example.com/cycle/internal/shop" -> "example.com/cycle/internal/catalog" [color=red, fontcolor=red, label="architecture/cross-domain-dependency"];
+  "example.com/cycle/internal/shop" -> "example.com/cycle/platform" [color=red, fontcolor=red, label="architecture/layer-dependency"];
+}
+`, out.String())
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, WriteGraph(&out, GraphFormatMermaid, graph))
+		assert.Equal(t, `graph LR
+  subgraph c0["example.com/cycle"]
+    n0["internal/billing"]
+    n1["internal/catalog"]
+    n2["internal/shop"]
+    n3["platform"]
+  end
+  n0 -->|"architecture/cross-domain-dependency"| n2
+  n2 -->|"architecture/cross-domain-dependency"| n0
+  n2 -->|"architecture/cross-domain-dependency"| n1
+  n2 -->|"architecture/layer-dependency"| n3
+  linkStyle 0,1,2,3 stroke:red,color:red
+`, out.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, WriteGraph(&out, GraphFormatJSON, graph))
+		assert.Contains(t, out.String(), "\"cycles\": [\n    [\n      \"internal/billing\",\n      \"internal/shop\"\n    ]\n  ]")
+		var decoded DependencyGraph
+		require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, *graph, decoded)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		err := WriteGraph(&bytes.Buffer{}, "svg", graph)
+		assert.EqualError(t, err, `unsupported graph format "svg", expected dot, mermaid or json`)
+	})
+}
+
+// TestWriteGraphCycleStyle checks the style of the edges of a cycle that violate no rule, which
+// only a custom configuration allows between domains
+func TestWriteGraphCycleStyle(t *testing.T) {
+	graph := &DependencyGraph{
+		Level: GraphLevelDomain,
+		Nodes: []GraphNode{
+			{ID: "example.com/shop/internal/a", Label: "internal/a", Module: "example.com/shop", Domain: "internal/a"},
+			{ID: "example.com/shop/internal/b", Label: "internal/b", Module: "example.com/shop", Domain: "internal/b"},
+		},
+		Edges: []GraphEdge{
+			{From: "example.com/shop/internal/a", To: "example.com/shop/internal/b", Imports: 2, Cycle: true},
+			{From: "example.com/shop/internal/b", To: "example.com/shop/internal/a", Imports: 1, Cycle: true},
+		},
+		Cycles: [][]string{{"internal/a", "internal/b"}},
+	}
+
+	var dot bytes.Buffer
+	require.NoError(t, WriteGraph(&dot, GraphFormatDOT, graph))
+	assert.Contains(t, dot.String(), `"example.com/shop/internal/a" -> "example.com/shop/internal/b" [color=orange, style=bold];`)
+
+	var mermaid bytes.Buffer
+	require.NoError(t, WriteGraph(&mermaid, GraphFormatMermaid, graph))
+	assert.True(t, strings.HasSuffix(mermaid.String(), "  n0 --> n1\n  n1 --> n0\n  linkStyle 0,1 stroke:orange,stroke-width:2px\n"), mermaid.String())
+}
+
+func TestFindCycles(t *testing.T) {
+	edges := map[string]map[string]bool{
+		"a": {"b": true},
+		"b": {"c": true},
+		"c": {"a": true, "d": true},
+		"d": {"e": true},
+		"e": {"d": true},
+		"f": {"a": true},
+	}
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e"}}, findCycles(edges))
+	assert.Empty(t, findCycles(map[string]map[string]bool{"a": {"b": true}, "b": {"c": true}}))
+}
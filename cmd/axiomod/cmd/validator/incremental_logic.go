@@ -0,0 +1,329 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// watchDebounce groups the file events of one save, e.g. of an editor writing several files
+const watchDebounce = 300 * time.Millisecond
+
+// scope restricts the architecture, naming and domain validators to the packages of changed files,
+// with --changed-only and --watch. nil validates every package.
+var scope *validationScope
+
+// validationScope is a set of package directories to validate
+type validationScope struct {
+	dirs map[string]bool
+}
+
+// newValidationScope returns the scope of the packages of files
+func newValidationScope(files []string) *validationScope {
+	s := &validationScope{dirs: make(map[string]bool)}
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			s.dirs[filepath.Dir(abs)] = true
+		}
+	}
+	return s
+}
+
+// includes reports whether a file belongs to a package of the scope
+func (s *validationScope) includes(path string) bool {
+	if s == nil {
+		return true
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return s.dirs[filepath.Dir(abs)]
+}
+
+// packagesUnder returns the directories of the scope under dir that still exist, sorted
+func (s *validationScope) packagesUnder(dir string) []string {
+	var dirs []string
+	for d := range s.dirs {
+		if d != dir && !strings.HasPrefix(d, dir+string(filepath.Separator)) {
+			continue
+		}
+		if info, err := os.Stat(d); err == nil && info.IsDir() {
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// len returns the number of packages of the scope
+func (s *validationScope) len() int {
+	return len(s.dirs)
+}
+
+// addIncrementalFlags adds the --changed-only, --since and --watch flags to a validator command
+func addIncrementalFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("changed-only", false, "Only validate the packages of the files changed since --since, and of untracked files")
+	cmd.Flags().String("since", "HEAD", "Git ref the changed files are compared with")
+	cmd.Flags().Bool("watch", false, "Validate again the packages of the files changed while running, until interrupted")
+}
+
+// applyChangedOnly restricts the validators to the packages of the Go and SQL files changed since
+// --since with --changed-only. A changed rules file validates every package. It reports false when
+// no file needs validating.
+func applyChangedOnly(cmd *cobra.Command, configPath string) bool {
+	changedOnly, _ := cmd.Flags().GetBool("changed-only")
+	if !changedOnly {
+		return true
+	}
+	since, _ := cmd.Flags().GetString("since")
+	files, err := changedFiles(since)
+	if err != nil {
+		fmt.Fprintf(console, "Error getting changed files: %v\n", err)
+		os.Exit(1)
+	}
+
+	rules := rulesFiles(configPath)
+	var validated []string
+	for _, file := range files {
+		if rules[filepath.Base(file)] {
+			fmt.Fprintf(console, "%s changed since %s, validating every package\n", relativePath(file), since)
+			return true
+		}
+		if isValidatedFile(file) {
+			validated = append(validated, file)
+		}
+	}
+	if len(validated) == 0 {
+		fmt.Fprintf(console, "No Go or SQL files changed since %s\n", since)
+		return false
+	}
+	scope = newValidationScope(validated)
+	fmt.Fprintf(console, "Validating the %d packages of the %d Go and SQL files changed since %s\n", scope.len(), len(validated), since)
+	return true
+}
+
+// watching runs the validators in watch mode with --watch, and reports whether it did
+func watching(cmd *cobra.Command, validators []string, configPath string, sqlPath string, apiPath string) bool {
+	watch, _ := cmd.Flags().GetBool("watch")
+	if !watch {
+		return false
+	}
+	if err := watchValidators(validators, configPath, sqlPath, apiPath); err != nil {
+		fmt.Fprintf(console, "Watch error: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// isValidatedFile reports whether the validators check a file, i.e. it is Go code or SQL
+func isValidatedFile(path string) bool {
+	return strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".sql")
+}
+
+// rulesFiles returns the names of the files of rules, which change the findings of every package
+func rulesFiles(configPath string) map[string]bool {
+	names := map[string]bool{"architecture-rules.json": true, ".architecture-rules.json": true, DefaultRulesFile: true}
+	for _, path := range []string{configPath, rulesPath} {
+		if path != "" {
+			names[filepath.Base(path)] = true
+		}
+	}
+	return names
+}
+
+// changedFiles returns the files changed since a git ref, committed or not, and the untracked
+// files, as absolute paths
+func changedFiles(since string) ([]string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git command not found: %w", err)
+	}
+	top, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	root := strings.TrimSpace(string(top))
+
+	diff, err := exec.Command("git", "diff", "--name-only", since).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", since, err)
+	}
+	untracked, err := exec.Command("git", "ls-files", "--others", "--exclude-standard", "--full-name", root).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(diff)+string(untracked), "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			files = append(files, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// parsedFiles caches the Go files parsed by the validators, so a watch run only parses the
+// files changed since the previous run
+var parsedFiles = &parseCache{fset: token.NewFileSet(), files: make(map[parseKey]*parsedFile)}
+
+// parseCache is a cache of parsed Go files, invalidated by their size and modification time
+type parseCache struct {
+	fset  *token.FileSet
+	files map[parseKey]*parsedFile
+}
+
+type parseKey struct {
+	path string
+	mode parser.Mode
+}
+
+type parsedFile struct {
+	modTime time.Time
+	size    int64
+	file    *ast.File
+	err     error
+}
+
+// parse returns a Go file parsed with a mode, and the file set of its positions
+func (c *parseCache) parse(path string, mode parser.Mode) (*token.FileSet, *ast.File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return c.fset, nil, err
+	}
+	key := parseKey{path: path, mode: mode}
+	if cached, ok := c.files[key]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return c.fset, cached.file, cached.err
+	}
+
+	file, err := parser.ParseFile(c.fset, path, nil, mode)
+	c.files[key] = &parsedFile{modTime: info.ModTime(), size: info.Size(), file: file, err: err}
+	return c.fset, file, err
+}
+
+// watchValidators runs the validators on the working directory, then on the packages of the
+// files changed under it each time files change, until interrupted. A change of a rules file
+// validates every package again.
+func watchValidators(validators []string, configPath string, sqlPath string, apiPath string) error {
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch files: %w", err)
+	}
+	defer watcher.Close()
+	if err := watchDirs(watcher, rootDir); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The first run validates the packages of --changed-only, then the packages of the files
+	// changed while watching
+	runWatchedValidators(validators, configPath, sqlPath, apiPath)
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	rules := rulesFiles(configPath)
+	changed := make(map[string]bool)
+	rulesChanged := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() && event.Has(fsnotify.Create) {
+				if err := watchDirs(watcher, event.Name); err != nil {
+					fmt.Fprintf(console, "Error watching %s: %v\n", event.Name, err)
+				}
+				continue
+			}
+			switch {
+			case rules[filepath.Base(event.Name)]:
+				rulesChanged = true
+			case isValidatedFile(event.Name):
+				changed[event.Name] = true
+			default:
+				continue
+			}
+			debounce.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(console, "Error watching files: %v\n", err)
+		case <-debounce.C:
+			files := make([]string, 0, len(changed))
+			for file := range changed {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			if rulesChanged {
+				scope = nil
+				fmt.Fprintln(console, "\nRules changed, validating every package...")
+			} else {
+				scope = newValidationScope(files)
+				fmt.Fprintf(console, "\n%d files changed, validating %d packages...\n", len(files), scope.len())
+			}
+			changed = make(map[string]bool)
+			rulesChanged = false
+			runWatchedValidators(validators, configPath, sqlPath, apiPath)
+		}
+	}
+}
+
+// runWatchedValidators runs the validators in the scope and prints their findings
+func runWatchedValidators(validators []string, configPath string, sqlPath string, apiPath string) {
+	start := time.Now()
+	for _, validator := range validators {
+		report, err := ValidatorReport(validator, configPath, sqlPath, apiPath)
+		if err != nil {
+			fmt.Fprintf(console, "Error running the %s validator: %v\n", validator, err)
+			continue
+		}
+		if err := WriteReport(console, FormatText, report); err != nil {
+			fmt.Fprintf(console, "Error writing report: %v\n", err)
+		}
+	}
+	fmt.Fprintf(console, "Validated in %s, watching for changes (Ctrl+C to stop)...\n", time.Since(start).Round(time.Millisecond))
+}
+
+// watchDirs watches dir and its subdirectories, except hidden, vendored and generated ones
+func watchDirs(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,311 @@
+package validator
+
+import (
+	"go/ast"
+	"go/parser"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handlerImportingRepository is a delivery file importing the repository layer, which the domain
+// validator reports
+const handlerImportingRepository = `package http
+
+import "github.com/axiomod/axiomod/examples/example/repository"
+
+var _ repository.Repository
+`
+
+// runGit runs a git command in the working directory
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v:\n%s", args, out)
+}
+
+// newGitRepo makes a new git repository of files, committed once, the working directory, and
+// returns its directory
+func newGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// git reports the paths of the repository with the symbolic links resolved
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	t.Chdir(dir)
+	writeFiles(t, files)
+	runGit(t, "init", "-q")
+	runGit(t, "add", "-A")
+	runGit(t, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// writeFiles writes files under the working directory, by slash-separated path
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.FromSlash(name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// changedOnlyCmd returns a command with --changed-only set, and resets the scope after the test
+func changedOnlyCmd(t *testing.T, since string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	addIncrementalFlags(cmd)
+	require.NoError(t, cmd.Flags().Set("changed-only", "true"))
+	if since != "" {
+		require.NoError(t, cmd.Flags().Set("since", since))
+	}
+	t.Cleanup(func() { scope = nil })
+	return cmd
+}
+
+// scopeDirs returns the package directories of the scope, relative to dir
+func scopeDirs(t *testing.T, dir string) []string {
+	t.Helper()
+	var dirs []string
+	for d := range scope.dirs {
+		rel, err := filepath.Rel(dir, d)
+		require.NoError(t, err)
+		dirs = append(dirs, filepath.ToSlash(rel))
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := newGitRepo(t, map[string]string{
+		".gitignore":                      "tmp/\n",
+		"README.md":                       "# Shop\n",
+		"internal/shop/entity/order.go":   "package entity\n",
+		"internal/shop/usecase/create.go": "package usecase\n",
+		"internal/shop/usecase/delete.go": "package usecase\n",
+	})
+	writeFiles(t, map[string]string{"internal/shop/usecase/create.go": "package usecase\n\n// Create creates\n"})
+	runGit(t, "commit", "-q", "-am", "document create")
+
+	// Unstaged, staged, deleted and untracked changes, and an ignored file
+	writeFiles(t, map[string]string{
+		"README.md":                       "# Shop service\n",
+		"internal/shop/entity/order.go":   "package entity\n\n// Order is an order\n",
+		"internal/billing/entity/bill.go": "package entity\n",
+		"migrations/001_init.up.sql":      "CREATE TABLE bills (id INT);\n",
+		"tmp/scratch.go":                  "package tmp\n",
+	})
+	runGit(t, "add", "migrations")
+	require.NoError(t, os.Remove(filepath.Join("internal", "shop", "usecase", "delete.go")))
+
+	relative := func(files []string) []string {
+		var rel []string
+		for _, file := range files {
+			r, err := filepath.Rel(dir, file)
+			require.NoError(t, err)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		sort.Strings(rel)
+		return rel
+	}
+
+	files, err := changedFiles("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"README.md",
+		"internal/billing/entity/bill.go",
+		"internal/shop/entity/order.go",
+		"internal/shop/usecase/delete.go",
+		"migrations/001_init.up.sql",
+	}, relative(files))
+
+	// Committed changes count since an older ref, and the paths do not depend on the working
+	// directory
+	t.Chdir(filepath.Join(dir, "internal", "shop"))
+	files, err = changedFiles("HEAD~1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"README.md",
+		"internal/billing/entity/bill.go",
+		"internal/shop/entity/order.go",
+		"internal/shop/usecase/create.go",
+		"internal/shop/usecase/delete.go",
+		"migrations/001_init.up.sql",
+	}, relative(files))
+
+	_, err = changedFiles("no-such-ref")
+	assert.ErrorContains(t, err, "failed to diff against no-such-ref")
+
+	t.Chdir(t.TempDir())
+	_, err = changedFiles("HEAD")
+	assert.ErrorContains(t, err, "not in a git repository")
+}
+
+func TestApplyChangedOnly(t *testing.T) {
+	t.Run("packages of changed files", func(t *testing.T) {
+		captureConsole(t)
+		dir := newGitRepo(t, map[string]string{
+			"internal/shop/entity/order.go":   "package entity\n",
+			"internal/shop/usecase/create.go": "package usecase\n",
+		})
+		writeFiles(t, map[string]string{
+			"README.md":                        "# Shop\n",
+			"internal/shop/entity/order.go":    "package entity\n\n// Order is an order\n",
+			"internal/shop/entity/item.go":     "package entity\n",
+			"internal/billing/entity/bill.go":  "package entity\n",
+			"migrations/001_init.up.sql":       "CREATE TABLE bills (id INT);\n",
+			"internal/shop/usecase/README.txt": "Use cases\n",
+		})
+
+		require.True(t, applyChangedOnly(changedOnlyCmd(t, ""), ""))
+		assert.Equal(t, []string{"internal/billing/entity", "internal/shop/entity", "migrations"}, scopeDirs(t, dir))
+		assert.True(t, scope.includes(filepath.Join("internal", "shop", "entity", "unchanged.go")))
+		assert.False(t, scope.includes(filepath.Join("internal", "shop", "usecase", "create.go")))
+	})
+
+	t.Run("rules changed", func(t *testing.T) {
+		out := captureConsole(t)
+		newGitRepo(t, map[string]string{"internal/shop/entity/order.go": "package entity\n"})
+		writeFiles(t, map[string]string{
+			"internal/shop/entity/order.go": "package entity\n\n// Order is an order\n",
+			DefaultRulesFile:                `{"rules": {}}`,
+		})
+
+		require.True(t, applyChangedOnly(changedOnlyCmd(t, ""), ""))
+		assert.Nil(t, scope)
+		assert.Contains(t, out.String(), DefaultRulesFile+" changed since HEAD, validating every package")
+	})
+
+	t.Run("custom rules file changed", func(t *testing.T) {
+		captureConsole(t)
+		newGitRepo(t, map[string]string{"internal/shop/entity/order.go": "package entity\n"})
+		writeFiles(t, map[string]string{"rules/layers.json": "{}"})
+
+		require.True(t, applyChangedOnly(changedOnlyCmd(t, ""), filepath.Join("rules", "layers.json")))
+		assert.Nil(t, scope)
+	})
+
+	t.Run("nothing to validate", func(t *testing.T) {
+		out := captureConsole(t)
+		newGitRepo(t, map[string]string{"internal/shop/entity/order.go": "package entity\n"})
+		writeFiles(t, map[string]string{"README.md": "# Shop\n"})
+
+		assert.False(t, applyChangedOnly(changedOnlyCmd(t, ""), ""))
+		assert.Equal(t, "No Go or SQL files changed since HEAD\n", out.String())
+	})
+
+	t.Run("since", func(t *testing.T) {
+		captureConsole(t)
+		dir := newGitRepo(t, map[string]string{"internal/shop/entity/order.go": "package entity\n"})
+		writeFiles(t, map[string]string{"internal/shop/usecase/create.go": "package usecase\n"})
+		runGit(t, "add", "-A")
+		runGit(t, "commit", "-q", "-m", "create orders")
+
+		require.True(t, applyChangedOnly(changedOnlyCmd(t, "HEAD~1"), ""))
+		assert.Equal(t, []string{"internal/shop/usecase"}, scopeDirs(t, dir))
+	})
+
+	t.Run("not requested", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		addIncrementalFlags(cmd)
+		assert.True(t, applyChangedOnly(cmd, ""))
+		assert.Nil(t, scope)
+	})
+}
+
+// TestChangedOnlyDomain checks that the domain validator only reports the packages of the changed
+// files with --changed-only
+func TestChangedOnlyDomain(t *testing.T) {
+	captureConsole(t)
+	newGitRepo(t, map[string]string{
+		"examples/example/delivery/http/handler.go": handlerImportingRepository,
+		"examples/example/delivery/grpc/handler.go": "package grpc\n",
+	})
+	writeFiles(t, map[string]string{
+		"examples/example/delivery/grpc/handler.go": "package grpc\n\nimport \"github.com/axiomod/axiomod/examples/example/repository\"\n\nvar _ repository.Repository\n",
+	})
+
+	report, err := ValidatorReport("domain", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Count(LevelError))
+
+	require.True(t, applyChangedOnly(changedOnlyCmd(t, ""), ""))
+	report, err = ValidatorReport("domain", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"examples/example/delivery/grpc/handler.go:3: Module 'examples/example/delivery/grpc' is not allowed to import 'examples/example/repository'"}, report.Issues())
+}
+
+func TestValidationScopePackagesUnder(t *testing.T) {
+	dir := t.TempDir()
+	for _, pkg := range []string{"internal/shop/entity", "internal/shop/usecase", "cmd/shop"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.FromSlash(pkg)), 0755))
+	}
+	s := newValidationScope([]string{
+		filepath.Join(dir, "internal", "shop", "entity", "order.go"),
+		filepath.Join(dir, "internal", "shop", "entity", "item.go"),
+		filepath.Join(dir, "internal", "shop", "usecase", "create.go"),
+		// The package of a deleted file may be gone too
+		filepath.Join(dir, "internal", "billing", "entity", "bill.go"),
+		filepath.Join(dir, "cmd", "shop", "main.go"),
+	})
+
+	assert.Equal(t, 4, s.len())
+	assert.Equal(t, []string{
+		filepath.Join(dir, "internal", "shop", "entity"),
+		filepath.Join(dir, "internal", "shop", "usecase"),
+	}, s.packagesUnder(filepath.Join(dir, "internal")))
+	assert.Empty(t, s.packagesUnder(filepath.Join(dir, "intern")))
+}
+
+func TestParseCache(t *testing.T) {
+	cache := &parseCache{fset: parsedFiles.fset, files: make(map[parseKey]*parsedFile)}
+	path := filepath.Join(t.TempDir(), "order.go")
+	require.NoError(t, os.WriteFile(path, []byte("package entity\n\ntype Order struct{}\n"), 0644))
+
+	_, first, err := cache.parse(path, parser.ParseComments)
+	require.NoError(t, err)
+	_, again, err := cache.parse(path, parser.ParseComments)
+	require.NoError(t, err)
+	assert.Same(t, first, again, "an unchanged file is parsed once")
+
+	_, importsOnly, err := cache.parse(path, parser.ImportsOnly)
+	require.NoError(t, err)
+	assert.NotSame(t, first, importsOnly, "files are cached by parser mode")
+	assert.Len(t, cache.files, 2)
+
+	// A change of size invalidates the file
+	require.NoError(t, os.WriteFile(path, []byte("package entity\n\ntype Order struct{ ID string }\n"), 0644))
+	_, changed, err := cache.parse(path, parser.ParseComments)
+	require.NoError(t, err)
+	assert.NotSame(t, first, changed)
+
+	// So does a change of modification time, with the same size
+	require.NoError(t, os.WriteFile(path, []byte("package entity\n\ntype Order struct{ Id string }\n"), 0644))
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, later, later))
+	_, touched, err := cache.parse(path, parser.ParseComments)
+	require.NoError(t, err)
+	assert.NotSame(t, changed, touched)
+	order := touched.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+	assert.Equal(t, "Id", order.Fields.List[0].Names[0].Name)
+
+	// Parse errors are cached with the file
+	require.NoError(t, os.WriteFile(path, []byte("package entity\n\ntype Order struct{\n"), 0644))
+	_, _, err = cache.parse(path, parser.ParseComments)
+	require.Error(t, err)
+	_, _, cachedErr := cache.parse(path, parser.ParseComments)
+	assert.Equal(t, err, cachedErr)
+
+	_, _, err = cache.parse(filepath.Join(filepath.Dir(path), "missing.go"), parser.ParseComments)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,498 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// destructiveMarker marks a migration statement whose data loss is intended
+const destructiveMarker = "axiomod:destructive"
+
+// sqlStatement is a statement of a SQL file
+type sqlStatement struct {
+	// text is the statement without its comments and its final semicolon. Comments are replaced
+	// by spaces, so offsets in text keep their lines.
+	text string
+	// line is the line of the first character of text
+	line int
+	// comments are the comments from the end of the previous statement to the end of this one,
+	// and the comment following it on its last line
+	comments string
+}
+
+// lineOf returns the line of an offset of the statement text
+func (s sqlStatement) lineOf(offset int) int {
+	return s.line + strings.Count(s.text[:offset], "\n")
+}
+
+// splitSQLStatements splits SQL into statements, with their comments. Semicolons in comments,
+// strings, quoted identifiers and dollar-quoted function bodies do not end statements.
+func splitSQLStatements(content string) []sqlStatement {
+	var statements []sqlStatement
+	var text, comments strings.Builder
+	line, start := 1, 1
+	lastEnd := -1 // line of the semicolon of the previous statement
+
+	flush := func() {
+		raw := text.String()
+		trimmed := strings.TrimLeft(raw, " \t\r\n")
+		if strings.TrimSpace(trimmed) != "" {
+			statements = append(statements, sqlStatement{
+				text:     strings.TrimRight(trimmed, " \t\r\n"),
+				line:     start + strings.Count(raw[:len(raw)-len(trimmed)], "\n"),
+				comments: comments.String(),
+			})
+		}
+		text.Reset()
+		comments.Reset()
+		start = line
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '-' && strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			comment := content[i : i+end]
+			// A comment after a semicolon on its line belongs to the statement it ends
+			if line == lastEnd && strings.TrimSpace(text.String()) == "" && len(statements) > 0 {
+				statements[len(statements)-1].comments += comment + "\n"
+			} else {
+				comments.WriteString(comment + "\n")
+			}
+			text.WriteString(strings.Repeat(" ", len(comment)))
+			i += end - 1
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			} else {
+				end += 2
+			}
+			comment := content[i : i+2+end]
+			comments.WriteString(comment + "\n")
+			text.WriteString(blankComment(comment))
+			line += strings.Count(comment, "\n")
+			i += len(comment) - 1
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(content[i+1:], c)
+			if end < 0 {
+				end = len(content) - i - 1
+			}
+			quoted := content[i : i+min(end+2, len(content)-i)]
+			text.WriteString(quoted)
+			line += strings.Count(quoted, "\n")
+			i += len(quoted) - 1
+		case c == '$':
+			tag := dollarTag.FindString(content[i:])
+			if tag == "" {
+				text.WriteByte(c)
+				continue
+			}
+			end := strings.Index(content[i+len(tag):], tag)
+			if end < 0 {
+				end = len(content) - i - len(tag)
+			} else {
+				end += len(tag)
+			}
+			body := content[i : i+len(tag)+end]
+			text.WriteString(body)
+			line += strings.Count(body, "\n")
+			i += len(body) - 1
+		case c == ';':
+			lastEnd = line
+			flush()
+		default:
+			if c == '\n' {
+				line++
+			}
+			text.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// dollarTag matches the opening tag of a dollar-quoted string, e.g. $$ or $body$
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// blankComment replaces a block comment by spaces, keeping its newlines
+func blankComment(comment string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' {
+			return r
+		}
+		return ' '
+	}, comment)
+}
+
+// SQL statements checked by the migration linter. Identifiers may be quoted and schema-qualified.
+const sqlIdent = `(?:"[^"]+"|[A-Za-z_][A-Za-z0-9_$]*)`
+const sqlName = sqlIdent + `(?:\.` + sqlIdent + `)?`
+
+var (
+	createTableStatement = regexp.MustCompile(`(?is)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:(?:TEMP|TEMPORARY|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + sqlName + `)\s*\(`)
+	createIndexStatement = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(` + sqlName + `\s+)?ON\s+(?:ONLY\s+)?(` + sqlName + `)\s*(?:USING\s+\w+\s*)?\(`)
+	alterTableStatement  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(` + sqlName + `)\s+`)
+	dropStatement        = regexp.MustCompile(`(?is)^(DROP\s+(?:TABLE|SCHEMA|DATABASE)|TRUNCATE)(?:\s+TABLE)?(?:\s+IF\s+EXISTS)?(?:\s+ONLY)?\s+(` + sqlName + `)`)
+
+	tableConstraint   = regexp.MustCompile(`(?is)^(?:CONSTRAINT\s+(` + sqlIdent + `)\s+)?(PRIMARY\s+KEY|UNIQUE|FOREIGN\s+KEY|CHECK|EXCLUDE)\b`)
+	columnDefinition  = regexp.MustCompile(`(?is)^(` + sqlIdent + `)\s+\S`)
+	inlineConstraint  = regexp.MustCompile(`(?is)\bCONSTRAINT\s+(` + sqlIdent + `)\s+(?:NOT\s+NULL\s+)?REFERENCES\b`)
+	inlineUnique      = regexp.MustCompile(`(?is)\b(PRIMARY\s+KEY|UNIQUE)\b`)
+	referencesTarget  = regexp.MustCompile(`(?is)\bREFERENCES\s+(` + sqlName + `)`)
+	addColumnAction   = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?`)
+	addConstraint     = regexp.MustCompile(`(?is)^ADD\s+`)
+	dropColumnAction  = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?(` + sqlIdent + `)`)
+	dropOtherAction   = regexp.MustCompile(`(?is)^DROP\s+(CONSTRAINT|DEFAULT|NOT\s+NULL|IDENTITY|EXPRESSION)\b`)
+	alterTypeAction   = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?(` + sqlIdent + `)\s+(?:SET\s+DATA\s+)?TYPE\b`)
+	renameAction      = regexp.MustCompile(`(?is)^RENAME\s+(?:(?:COLUMN|CONSTRAINT)\s+)?(?:` + sqlIdent + `\s+)?TO\s+(` + sqlIdent + `)`)
+	constraintColumns = regexp.MustCompile(`(?s)\(([^)]*)\)`)
+	leadingIdentifier = regexp.MustCompile(`^\s*(` + sqlIdent + `)`)
+)
+
+// migrationColumn is a column ending with _id, which needs an index
+type migrationColumn struct {
+	table, column string
+	file          string
+	line          int
+}
+
+// migrationLinter checks the statements of the SQL migrations of a directory
+type migrationLinter struct {
+	validator *NamingValidator
+	// file is the migration being checked
+	file string
+	// indexed holds the table.column keys leading an index, a primary key or a unique constraint
+	indexed map[string]bool
+	// idColumns are the columns ending with _id, checked for an index once every file is read
+	idColumns []migrationColumn
+}
+
+// lintMigrations checks the SQL migrations of sqlDir for unindexed _id columns, foreign key
+// names, index creations locking existing tables, destructive statements without a marker
+// comment, mixed-case identifiers and up migrations without a down migration. The statements
+// assume PostgreSQL, the database of axiomod migrate.
+func lintMigrations(sqlDir string, validator *NamingValidator, summary *NamingValidationSummary) {
+	var files []string
+	err := filepath.Walk(sqlDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".sql") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(console, "Error walking SQL directory: %v\n", err)
+		return
+	}
+	sort.Strings(files)
+
+	linter := &migrationLinter{validator: validator, indexed: make(map[string]bool)}
+	for _, file := range files {
+		// Down migrations undo their up migration, so they drop what it created
+		if strings.HasSuffix(file, ".down.sql") {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(console, "Error reading file %s: %v\n", file, err)
+			continue
+		}
+
+		// Every file is read for the indexes, but only the files in scope are reported
+		report := scope.includes(file)
+		if report {
+			summary.MigrationsChecked++
+		}
+		linter.lintFile(file, string(content), report)
+
+		if up := strings.TrimSuffix(file, ".up.sql"); report && up != file {
+			if _, err := os.Stat(up + ".down.sql"); os.IsNotExist(err) {
+				linter.add(LevelError, file, 1, "Migration", "migration-down", filepath.Base(file),
+					"paired with "+filepath.Base(up)+".down.sql", "Up migrations need a down migration to be rolled back")
+			}
+		}
+	}
+
+	for _, column := range linter.idColumns {
+		if !linter.indexed[column.table+"."+column.column] {
+			linter.add(LevelWarning, column.file, column.line, "Foreign key column", "fk-index", column.table+"."+column.column,
+				"indexed", "Columns ending with _id are joined and filtered on, so they need an index")
+		}
+	}
+}
+
+// add reports a finding of the linter at a level
+func (l *migrationLinter) add(level, file string, line int, kind, rule, name, expected, description string) {
+	result := &ValidationResult{
+		File:        file,
+		Line:        line,
+		Column:      1,
+		Type:        kind,
+		Rule:        rule,
+		Name:        name,
+		Expected:    expected,
+		Description: description,
+	}
+	if level == LevelWarning {
+		l.validator.AddWarning(result)
+	} else {
+		l.validator.AddError(result)
+	}
+}
+
+// lintFile checks the statements of a migration, and records its indexes and _id columns
+func (l *migrationLinter) lintFile(file string, content string, report bool) {
+	l.file = file
+	// Indexes on tables created by the same migration do not lock existing rows
+	created := make(map[string]bool)
+
+	for _, statement := range splitSQLStatements(content) {
+		text := statement.text
+		switch {
+		case createTableStatement.MatchString(text):
+			match := createTableStatement.FindStringSubmatchIndex(text)
+			table := sqlKey(text[match[2]:match[3]])
+			created[table] = true
+			body, offset := parenthesized(text, match[1]-1)
+			for _, element := range splitTopLevel(body, offset) {
+				l.tableElement(statement, table, element, report)
+			}
+
+		case createIndexStatement.MatchString(text):
+			match := createIndexStatement.FindStringSubmatchIndex(text)
+			table := sqlKey(text[match[6]:match[7]])
+			columns, offset := parenthesized(text, match[1]-1)
+			if elements := splitTopLevel(columns, offset); len(elements) > 0 {
+				if column := leadingIdent(elements[0].text); column != "" {
+					l.indexed[table+"."+sqlKey(column)] = true
+				}
+			}
+			if !report {
+				continue
+			}
+			if match[4] >= 0 {
+				l.checkIdentifier(statement, match[4], strings.TrimSpace(text[match[4]:match[5]]))
+			}
+			if match[2] < 0 && !created[table] {
+				name := "on " + text[match[6]:match[7]]
+				if match[4] >= 0 {
+					name = strings.TrimSpace(text[match[4]:match[5]])
+				}
+				l.add(LevelError, file, statement.line, "Index", "concurrent-index", name, "created CONCURRENTLY",
+					"CREATE INDEX locks the writes of an existing table until the index is built")
+			}
+
+		case alterTableStatement.MatchString(text):
+			match := alterTableStatement.FindStringSubmatchIndex(text)
+			table := sqlKey(text[match[2]:match[3]])
+			for _, action := range splitTopLevel(text[match[1]:], match[1]) {
+				l.alterAction(statement, table, action, report)
+			}
+
+		case dropStatement.MatchString(text) && report:
+			match := dropStatement.FindStringSubmatch(text)
+			l.destructive(statement, statement.line, strings.ToUpper(strings.Join(strings.Fields(match[1]), " "))+" "+match[2])
+		}
+	}
+}
+
+// tableElement checks a column or a constraint of a CREATE TABLE statement
+func (l *migrationLinter) tableElement(statement sqlStatement, table string, element sqlElement, report bool) {
+	line := statement.lineOf(element.offset)
+	if match := tableConstraint.FindStringSubmatch(element.text); match != nil {
+		l.constraint(statement, table, element, match[1], match[2], report)
+		return
+	}
+	match := columnDefinition.FindStringSubmatch(element.text)
+	if match == nil {
+		return
+	}
+	column := sqlKey(match[1])
+	if inlineUnique.MatchString(element.text) {
+		l.indexed[table+"."+column] = true
+	}
+	if strings.HasSuffix(column, "_id") && report {
+		l.idColumns = append(l.idColumns, migrationColumn{table: table, column: column, file: l.file, line: line})
+	}
+	if report {
+		l.references(statement, element)
+	}
+}
+
+// constraint checks a table constraint, e.g. CONSTRAINT fk_orders_user_id FOREIGN KEY (user_id)
+func (l *migrationLinter) constraint(statement sqlStatement, table string, element sqlElement, name string, kind string, report bool) {
+	columns := constraintColumns.FindStringSubmatch(element.text)
+	kind = strings.ToUpper(strings.Join(strings.Fields(kind), " "))
+	if columns != nil && (kind == "PRIMARY KEY" || kind == "UNIQUE") {
+		if column := leadingIdent(strings.Split(columns[1], ",")[0]); column != "" {
+			l.indexed[table+"."+sqlKey(column)] = true
+		}
+	}
+	if !report {
+		return
+	}
+	line := statement.lineOf(element.offset)
+	if name != "" {
+		l.checkIdentifierAt(line, name)
+		if kind == "FOREIGN KEY" && !strings.HasPrefix(strings.ToLower(strings.Trim(name, `"`)), "fk_") {
+			l.add(LevelWarning, l.file, line, "Foreign key constraint", "foreign-key-name", strings.Trim(name, `"`),
+				"prefixed with fk_, e.g. fk_<table>_<column>", "Foreign key constraint names tell the tables they link")
+		}
+	}
+	if kind == "FOREIGN KEY" {
+		l.references(statement, element)
+	}
+}
+
+// references checks the names of an inline foreign key constraint and of the table it references
+func (l *migrationLinter) references(statement sqlStatement, element sqlElement) {
+	line := statement.lineOf(element.offset)
+	if match := inlineConstraint.FindStringSubmatch(element.text); match != nil {
+		l.checkIdentifierAt(line, match[1])
+		if !strings.HasPrefix(strings.ToLower(strings.Trim(match[1], `"`)), "fk_") {
+			l.add(LevelWarning, l.file, line, "Foreign key constraint", "foreign-key-name", strings.Trim(match[1], `"`),
+				"prefixed with fk_, e.g. fk_<table>_<column>", "Foreign key constraint names tell the tables they link")
+		}
+	}
+	if match := referencesTarget.FindStringSubmatch(element.text); match != nil {
+		l.checkIdentifierAt(line, match[1])
+	}
+}
+
+// alterAction checks an action of an ALTER TABLE statement
+func (l *migrationLinter) alterAction(statement sqlStatement, table string, action sqlElement, report bool) {
+	text := action.text
+	line := statement.lineOf(action.offset)
+	switch {
+	case addColumnAction.MatchString(text) && !tableConstraint.MatchString(addConstraint.ReplaceAllString(text, "")):
+		column := addColumnAction.ReplaceAllString(text, "")
+		l.tableElement(statement, table, sqlElement{text: column, offset: action.offset + len(text) - len(column)}, report)
+	case addConstraint.MatchString(text):
+		constraint := addConstraint.ReplaceAllString(text, "")
+		if match := tableConstraint.FindStringSubmatch(constraint); match != nil {
+			l.constraint(statement, table, sqlElement{text: constraint, offset: action.offset + len(text) - len(constraint)}, match[1], match[2], report)
+		}
+	case !report:
+	case dropOtherAction.MatchString(text):
+	case dropColumnAction.MatchString(text):
+		l.destructive(statement, line, "DROP COLUMN "+dropColumnAction.FindStringSubmatch(text)[1])
+	case alterTypeAction.MatchString(text):
+		l.destructive(statement, line, "ALTER COLUMN "+alterTypeAction.FindStringSubmatch(text)[1]+" TYPE")
+	case renameAction.MatchString(text):
+		l.checkIdentifierAt(line, renameAction.FindStringSubmatch(text)[1])
+	}
+}
+
+// destructive reports a statement losing data, unless a marker comment acknowledges it
+func (l *migrationLinter) destructive(statement sqlStatement, line int, operation string) {
+	if strings.Contains(statement.comments, destructiveMarker) {
+		return
+	}
+	l.add(LevelError, l.file, line, "Destructive statement", "destructive-migration", operation,
+		"marked with a -- "+destructiveMarker+` reason="..." comment`, "Statements losing data must be acknowledged, so they are not applied by accident")
+}
+
+// checkIdentifier reports an identifier at an offset of a statement that is not lowercase
+func (l *migrationLinter) checkIdentifier(statement sqlStatement, offset int, identifier string) {
+	l.checkIdentifierAt(statement.lineOf(offset), identifier)
+}
+
+// checkIdentifierAt reports an identifier that is not lowercase
+func (l *migrationLinter) checkIdentifierAt(line int, identifier string) {
+	for _, part := range strings.Split(identifier, ".") {
+		name := strings.Trim(part, `"`)
+		if name != strings.ToLower(name) {
+			l.add(LevelError, l.file, line, "Identifier", "mixed-case-identifier", name, "lowercase",
+				"PostgreSQL folds unquoted identifiers to lowercase and quoted ones are case-sensitive, so mixed case breaks queries")
+			return
+		}
+	}
+}
+
+// sqlElement is a comma-separated element of a statement, with its offset in the statement
+type sqlElement struct {
+	text   string
+	offset int
+}
+
+// parenthesized returns the text between the parenthesis at an offset and the matching one, and
+// the offset of the text
+func parenthesized(text string, open int) (string, int) {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return text[open+1 : i], open + 1
+			}
+		}
+	}
+	return text[open+1:], open + 1
+}
+
+// splitTopLevel splits text on the commas outside of parentheses and quotes
+func splitTopLevel(text string, offset int) []sqlElement {
+	var elements []sqlElement
+	depth, start := 0, 0
+	var quote byte
+	add := func(end int) {
+		element := text[start:end]
+		trimmed := strings.TrimLeft(element, " \t\r\n")
+		if strings.TrimSpace(trimmed) != "" {
+			elements = append(elements, sqlElement{text: strings.TrimSpace(trimmed), offset: offset + start + len(element) - len(trimmed)})
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			add(i)
+			start = i + 1
+		}
+	}
+	add(len(text))
+	return elements
+}
+
+// leadingIdent returns the identifier an element starts with
+func leadingIdent(text string) string {
+	if match := leadingIdentifier.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// sqlKey returns an identifier as PostgreSQL resolves it: unquoted identifiers are folded to
+// lowercase, and the schema of qualified names is dropped
+func sqlKey(identifier string) string {
+	identifier = strings.TrimSpace(identifier)
+	if strings.HasSuffix(identifier, `"`) {
+		quoted := identifier[strings.LastIndex(identifier[:len(identifier)-1], `"`):]
+		return strings.Trim(quoted, `"`)
+	}
+	return strings.ToLower(identifier[strings.LastIndex(identifier, ".")+1:])
+}
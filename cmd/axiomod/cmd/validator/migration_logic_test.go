@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLintMigrations lints the migrations of each directory of testdata/migrations, named by the
+// rule they break; clean breaks none
+func TestLintMigrations(t *testing.T) {
+	tests := map[string][]string{
+		"fk-index": {
+			"001_create_orders.up.sql:8: warning fk-index orders.coupon_id",
+		},
+		"foreign-key-name": {
+			"001_create_orders.up.sql:3: warning foreign-key-name orders_user",
+			"001_create_orders.up.sql:5: warning foreign-key-name shop_ref",
+		},
+		"concurrent-index": {
+			"002_index_orders.up.sql:3: error concurrent-index idx_orders_placed_at",
+		},
+		"destructive-migration": {
+			"002_cleanup.up.sql:1: error destructive-migration DROP COLUMN legacy_code",
+			"002_cleanup.up.sql:3: error destructive-migration ALTER COLUMN total TYPE",
+			"002_cleanup.up.sql:5: error destructive-migration TRUNCATE order_events",
+		},
+		"mixed-case-identifier": {
+			"001_create_orders.up.sql:3: error mixed-case-identifier FK_orders_user",
+			"001_create_orders.up.sql:3: error mixed-case-identifier Users",
+			"001_create_orders.up.sql:6: error mixed-case-identifier IDX_orders_user",
+			"001_create_orders.up.sql:8: error mixed-case-identifier orderId",
+		},
+		"migration-down": {
+			"001_create_orders.up.sql:1: error migration-down 001_create_orders.up.sql",
+		},
+		"clean": nil,
+	}
+
+	for dir, want := range tests {
+		t.Run(dir, func(t *testing.T) {
+			validator := NewNamingValidator()
+			summary := &NamingValidationSummary{}
+			lintMigrations(filepath.Join("testdata", "migrations", dir), validator, summary)
+
+			var findings []string
+			for level, results := range map[string][]ValidationResult{LevelError: validator.results.Errors, LevelWarning: validator.results.Warnings} {
+				for _, result := range results {
+					findings = append(findings, fmt.Sprintf("%s:%d: %s %s %s", filepath.Base(result.File), result.Line, level, result.Rule, result.Name))
+				}
+			}
+			sort.Strings(findings)
+			assert.Equal(t, want, findings)
+			assert.Positive(t, summary.MigrationsChecked)
+		})
+	}
+}
@@ -2,61 +2,129 @@ package validator
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+// Default locations checked by the naming validator, relative to the validated directory
+const (
+	defaultSQLPath = "migrations"
+	defaultAPIPath = "."
+)
+
 // namingCmd represents the validator naming command
 var namingCmd = &cobra.Command{
-	Use:   "naming",
+	Use:   "naming [dir]",
 	Short: "Validate naming conventions across the project",
 	Long: `Validate naming conventions for Go code, API endpoints, and database schemas.
 
+With --format=json, sarif or junit the errors and warnings are written as a report for CI, e.g. to
+upload them as GitHub code scanning alerts. The command still exits with status 1 when there are errors.
+
+With --fix the violations that can be fixed without breaking the build are fixed before validating:
+unexported functions, variables and struct fields are renamed to camelCase with their references,
+using the types of their package, and Go files are renamed to snake_case. The changed files are
+formatted with gofmt. Renames that would conflict with or shadow another name are skipped, and
+the errors left to be fixed by hand, such as exported names, are listed with the reason. With
+--dry-run the fixes are printed as a diff without being written, and the command exits with
+status 1 when errors are left to be fixed by hand, as it does once the fixes are applied.
+
+With --changed-only only the packages of the files changed since --since (HEAD by default) and of
+untracked files are validated, e.g. in a pre-commit hook. With --watch the working directory is
+validated again for the files changed while the command runs, until it is interrupted.
+
 Example:
   axiomod validator naming
   axiomod validator naming --fix
+  axiomod validator naming --fix --dry-run > naming.patch
+  axiomod validator naming --sql=db/migrations --format=junit --output=naming.xml
+  axiomod validator naming --changed-only
 `,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
 		fix, _ := cmd.Flags().GetBool("fix")
-		fmt.Println("Validating naming conventions...")
-
-		if fix {
-			fmt.Println("Fix mode enabled - will attempt to correct naming issues")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		sqlPath, _ := cmd.Flags().GetString("sql")
+		apiPath, _ := cmd.Flags().GetString("api")
+		format := reportFormat(cmd)
+		if fix && dryRun {
+			// stdout only holds the diff
+			console = os.Stderr
 		}
-
-		// Perform validation
-		issues, err := ValidateNaming(fix)
-		if err != nil {
-			fmt.Printf("Naming validation error: %v\n", err)
-			os.Exit(1)
+		fmt.Fprintln(console, "Validating naming conventions...")
+		if !applyChangedOnly(cmd, "") {
+			return
+		}
+		if watching(cmd, []string{"naming"}, "", sqlPath, apiPath) {
+			return
 		}
 
-		if len(issues) > 0 {
-			fmt.Printf("Found %d naming convention issues:\n", len(issues))
-			for i, issue := range issues {
-				fmt.Printf("%d. %s\n", i+1, issue)
+		if fix {
+			var diff io.Writer
+			if dryRun {
+				diff = os.Stdout
+			}
+			summary, err := FixNaming(dir, sqlPath, apiPath, diff)
+			if err != nil {
+				fmt.Fprintf(console, "Naming fix error: %v\n", err)
+				os.Exit(1)
 			}
+			printNamingFixes(summary)
+			if dryRun {
+				// Fail like the run that applies the fixes, whose validation finds the errors
+				// left to be fixed by hand
+				if len(summary.Unfixed) > 0 {
+					os.Exit(1)
+				}
+				return
+			}
+			fmt.Fprintln(console, "\nValidating the fixed code...")
+		}
 
-			if !fix {
-				fmt.Println("\nRun with --fix flag to attempt automatic fixes")
+		if usesReport(cmd, format) {
+			report, err := NamingReport(dir, sqlPath, apiPath)
+			if err != nil {
+				fmt.Fprintf(console, "Naming validation error: %v\n", err)
 				os.Exit(1)
-			} else {
-				fmt.Println("\nAttempted to fix issues. Please review changes.")
 			}
-		} else {
+			emitReport(cmd, format, report)
+			return
+		}
+
+		// Perform validation
+		passed, err := RunNamingValidation(dir, sqlPath, apiPath)
+		if passed {
 			fmt.Println("Naming validation passed successfully.")
+			return
 		}
+
+		fmt.Printf("Naming validation failed: %v\n", err)
+		if !fix {
+			fmt.Println("\nRun with --fix flag to attempt automatic fixes")
+		}
+		os.Exit(1)
 	},
 }
 
 // NewNamingCmd returns the validator naming command.
 func NewNamingCmd() *cobra.Command {
-	namingCmd.Flags().BoolP("fix", "f", false, "Attempt to fix naming issues automatically")
 	return namingCmd
 }
 
 func init() {
+	namingCmd.Flags().BoolP("fix", "f", false, "Fix the naming violations that can be fixed automatically")
+	namingCmd.Flags().Bool("dry-run", false, "With --fix, print the fixes as a diff without writing them")
+	namingCmd.Flags().String("sql", defaultSQLPath, "Directory containing SQL migrations")
+	namingCmd.Flags().String("api", defaultAPIPath, "Directory containing API handlers")
+	addReportFlags(namingCmd)
+	addIncrementalFlags(namingCmd)
+
 	// Add subcommands to the parent validatorCmd
 	validatorCmd.AddCommand(namingCmd)
 }
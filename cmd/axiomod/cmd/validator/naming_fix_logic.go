@@ -0,0 +1,734 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/textdiff"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// NamingFix is a naming violation with the name it was fixed to, or the reason it was not fixed
+type NamingFix struct {
+	ValidationResult
+	// Replacement is the new name of the identifier or file
+	Replacement string
+	// References is the number of references renamed with an identifier, its declaration excluded
+	References int
+	// Reason is why the violation was left to be fixed by hand, empty when it was fixed
+	Reason string
+}
+
+// NamingFixSummary lists the naming violations fixed by FixNaming and the errors it left
+type NamingFixSummary struct {
+	Fixed   []NamingFix
+	Unfixed []NamingFix
+}
+
+// camelCasePattern and snakeCaseFilePattern are the names the naming validator accepts for
+// unexported identifiers and Go files
+var (
+	camelCasePattern     = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	snakeCaseFilePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*\.go$`)
+)
+
+// unfixableReasons are the reasons the violations of the rules FixNaming does not fix are left
+// to be fixed by hand
+var unfixableReasons = map[string]string{
+	"exported-function":     "exported names are part of the API of the package",
+	"exported-variable":     "exported names are part of the API of the package",
+	"exported-struct-field": "exported names are part of the API of the package",
+	"type":                  "types are left to be renamed by hand",
+	"package":               "packages are renamed with their directory and imports",
+	"package-singular":      "packages are renamed with their directory and imports",
+	"api-endpoint":          "API paths are part of the public API",
+	"api-trailing-slash":    "API paths are part of the public API",
+	"api-version":           "API paths are part of the public API",
+	"api-resource":          "API paths are part of the public API",
+	"ent-schema":            "Ent schemas are renamed with their generated code",
+	"ent-schema-singular":   "Ent schemas are renamed with their generated code",
+}
+
+// commonInitialisms are the words written in capitals in camelCase names, as in userID
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true,
+	"URI": true, "URL": true, "UTF8": true, "UUID": true, "XML": true,
+}
+
+// knownOS and knownArch are the GOOS and GOARCH values that constrain the build of the files
+// named after them, as in file_linux.go
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+		"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// sourceEdit replaces the identifier at an offset of a file by a new name
+type sourceEdit struct {
+	offset int
+	length int
+	text   string
+}
+
+// namingFixer collects the edits and file renames fixing naming violations before they are
+// written, so the fixes are either all applied or all printed
+type namingFixer struct {
+	summary *NamingFixSummary
+	// errors are the violations reported as errors, the others are warnings
+	errors map[ValidationResult]bool
+	// edits are the identifier edits by file
+	edits map[string][]sourceEdit
+	// renames are the new paths of the renamed files
+	renames map[string]string
+	// planned are the new names given in a scope, a struct or a method set, so two identifiers
+	// are not renamed to the same name
+	planned map[any]map[string]bool
+}
+
+// FixNaming fixes the naming violations that can be fixed without breaking the build: it renames
+// unexported functions, variables and struct fields to camelCase, with their references, and Go
+// files to snake_case. The changed files are formatted with gofmt. When diff is not nil, the
+// fixes are written to it as a unified diff instead of being applied. Violations that need a
+// decision, such as exported names that are part of an API, are returned as unfixed errors.
+func FixNaming(dirPath string, sqlPath string, apiPath string, diff io.Writer) (*NamingFixSummary, error) {
+	validator, _, err := collectNamingResults(dirPath, sqlPath, apiPath)
+	if err != nil {
+		return nil, err
+	}
+	fixer := &namingFixer{
+		summary: &NamingFixSummary{},
+		errors:  make(map[ValidationResult]bool),
+		edits:   make(map[string][]sourceEdit),
+		renames: make(map[string]string),
+		planned: make(map[any]map[string]bool),
+	}
+
+	// Rules configured as warnings are fixed as well, but only errors are reported as unfixed
+	for _, result := range validator.results.Errors {
+		fixer.errors[result] = true
+	}
+	var identifiers []NamingFix
+	results := append(append([]ValidationResult{}, validator.results.Errors...), validator.results.Warnings...)
+	for _, result := range results {
+		switch result.Rule {
+		case "unexported-function", "unexported-variable", "unexported-struct-field":
+			identifiers = append(identifiers, NamingFix{ValidationResult: result})
+		case "file-name":
+			fixer.renameFile(NamingFix{ValidationResult: result})
+		default:
+			reason, ok := unfixableReasons[result.Rule]
+			if !ok {
+				reason = "no automatic fix for this rule"
+			}
+			fixer.unfixed(NamingFix{ValidationResult: result}, reason)
+		}
+	}
+	fixer.renameIdentifiers(identifiers)
+
+	if err := fixer.write(diff); err != nil {
+		return nil, err
+	}
+	sortNamingFixes(fixer.summary.Fixed)
+	sortNamingFixes(fixer.summary.Unfixed)
+	return fixer.summary, nil
+}
+
+// printNamingFixes prints the fixed violations and the errors left to be fixed by hand
+func printNamingFixes(summary *NamingFixSummary) {
+	if len(summary.Fixed) > 0 {
+		fmt.Fprintf(console, "\n🔧 Fixed %d naming violations:\n", len(summary.Fixed))
+		for _, fix := range summary.Fixed {
+			if fix.Rule == "file-name" {
+				fmt.Fprintf(console, "  • %s: %s '%s' renamed to %s\n", fix.File, fix.Type, fix.Name, fix.Replacement)
+				continue
+			}
+			fmt.Fprintf(console, "  • %s:%d:%d: %s '%s' renamed to %s (%d references)\n",
+				fix.File, fix.Line, fix.Column, fix.Type, fix.Name, fix.Replacement, fix.References)
+		}
+	} else if len(summary.Unfixed) == 0 {
+		fmt.Fprintln(console, "\nNo naming violations to fix.")
+	} else {
+		fmt.Fprintln(console, "\nNo naming violations could be fixed automatically.")
+	}
+
+	if len(summary.Unfixed) > 0 {
+		fmt.Fprintf(console, "\n⚠️  %d naming errors must be fixed by hand:\n", len(summary.Unfixed))
+		for _, fix := range summary.Unfixed {
+			fmt.Fprintf(console, "  • %s:%d:%d: %s: %s\n", fix.File, fix.Line, fix.Column, fix.Message(), fix.Reason)
+		}
+	}
+}
+
+// unfixed records a violation left to be fixed by hand. Only errors are recorded.
+func (f *namingFixer) unfixed(fix NamingFix, reason string) {
+	if !f.errors[fix.ValidationResult] {
+		return
+	}
+	fix.Reason = reason
+	f.summary.Unfixed = append(f.summary.Unfixed, fix)
+}
+
+// plan reserves a new name in a scope, a struct or a method set, reporting whether it was free
+func (f *namingFixer) plan(key any, name string) bool {
+	names := f.planned[key]
+	if names == nil {
+		names = make(map[string]bool)
+		f.planned[key] = names
+	}
+	if names[name] {
+		return false
+	}
+	names[name] = true
+	return true
+}
+
+// renameFile renames a Go file to snake_case, unless the new name would change its build
+// constraints or is taken
+func (f *namingFixer) renameFile(fix NamingFix) {
+	base := filepath.Base(fix.File)
+	if strings.HasPrefix(base, "_") || strings.HasPrefix(base, ".") {
+		f.unfixed(fix, "the go command ignores files starting with _ or ., renaming it would add it to the build")
+		return
+	}
+	newBase := snakeCaseFileName(base)
+	if !snakeCaseFilePattern.MatchString(newBase) {
+		f.unfixed(fix, "no snake_case spelling of the name")
+		return
+	}
+	oldStem, newStem := strings.TrimSuffix(base, ".go"), strings.TrimSuffix(newBase, ".go")
+	if fileConstraint(oldStem) != fileConstraint(newStem) {
+		f.unfixed(fix, fmt.Sprintf("%s would change the GOOS or GOARCH the file is built for", newBase))
+		return
+	}
+
+	newPath := filepath.Join(filepath.Dir(fix.File), newBase)
+	if existing, err := os.Stat(newPath); err == nil {
+		// Renaming Foo.go to foo.go finds the file itself on case-insensitive file systems
+		if current, err := os.Stat(fix.File); err != nil || !os.SameFile(existing, current) {
+			f.unfixed(fix, newBase+" already exists")
+			return
+		}
+	}
+	if !f.plan(filepath.Dir(newPath), newBase) {
+		f.unfixed(fix, "another file is renamed to "+newBase)
+		return
+	}
+
+	f.renames[fix.File] = newPath
+	fix.Replacement = newBase
+	f.summary.Fixed = append(f.summary.Fixed, fix)
+}
+
+// renameIdentifiers renames unexported identifiers to camelCase with their references. The
+// packages declaring them are loaded with their types, so only the references to the renamed
+// object are changed, and renames that would conflict with or shadow another name are skipped.
+func (f *namingFixer) renameIdentifiers(fixes []NamingFix) {
+	if len(fixes) == 0 {
+		return
+	}
+
+	// Load the packages of the files by module
+	byModule := make(map[string]map[string]bool)
+	for _, fix := range fixes {
+		moduleDir, _, err := apiscan.FindModule(filepath.Dir(fix.File))
+		if err != nil {
+			continue
+		}
+		if byModule[moduleDir] == nil {
+			byModule[moduleDir] = make(map[string]bool)
+		}
+		byModule[moduleDir][filepath.Dir(fix.File)] = true
+	}
+	byFile := make(map[string]*packages.Package)
+	for moduleDir, dirs := range byModule {
+		pkgs, err := loadTypedPackages(moduleDir, dirs)
+		if err != nil {
+			fmt.Fprintf(console, "Error loading the packages of %s: %v\n", moduleDir, err)
+			continue
+		}
+		// A file belongs to a package and its test variant, which has all the files that can
+		// refer to its unexported names
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Syntax {
+				name := pkg.Fset.File(file.Pos()).Name()
+				if current, ok := byFile[name]; !ok || len(pkg.Syntax) > len(current.Syntax) {
+					byFile[name] = pkg
+				}
+			}
+		}
+	}
+
+	for _, fix := range fixes {
+		pkg, ok := byFile[fix.File]
+		switch {
+		case !ok:
+			f.unfixed(fix, "the package of the file could not be loaded")
+			continue
+		case len(pkg.Errors) > 0 || len(pkg.TypeErrors) > 0:
+			f.unfixed(fix, "the package has errors, fix them first")
+			continue
+		}
+		f.renameIdentifier(pkg, fix)
+	}
+}
+
+// renameIdentifier renames the identifier declared at the position of fix in pkg
+func (f *namingFixer) renameIdentifier(pkg *packages.Package, fix NamingFix) {
+	var decl *ast.Ident
+	var obj types.Object
+	for id, def := range pkg.TypesInfo.Defs {
+		pos := pkg.Fset.Position(id.Pos())
+		if def != nil && pos.Filename == fix.File && pos.Line == fix.Line && pos.Column == fix.Column {
+			decl, obj = id, def
+			break
+		}
+	}
+	if obj == nil {
+		f.unfixed(fix, "the declaration could not be resolved")
+		return
+	}
+	file := fileOf(pkg, decl.Pos())
+	if file != nil && ast.IsGenerated(file) {
+		f.unfixed(fix, "the file is generated")
+		return
+	}
+
+	name := camelCaseName(obj.Name())
+	if !camelCasePattern.MatchString(name) || token.IsKeyword(name) || name == "init" || name == "main" {
+		f.unfixed(fix, "no camelCase spelling of the name")
+		return
+	}
+	if reason := renameConflict(pkg, obj, decl, name); reason != "" {
+		f.unfixed(fix, reason)
+		return
+	}
+	if !f.plan(renameScope(pkg, obj, decl), name) {
+		f.unfixed(fix, "another identifier of the same scope is renamed to "+name)
+		return
+	}
+
+	refs := references(pkg, obj)
+	for _, id := range refs {
+		pos := pkg.Fset.Position(id.Pos())
+		f.edits[pos.Filename] = append(f.edits[pos.Filename], sourceEdit{offset: pos.Offset, length: len(id.Name), text: name})
+	}
+	fix.Replacement = name
+	fix.References = len(refs) - 1
+	f.summary.Fixed = append(f.summary.Fixed, fix)
+}
+
+// write applies the edits and renames, or writes them to diff
+func (f *namingFixer) write(diff io.Writer) error {
+	paths := make(map[string]bool)
+	for path := range f.edits {
+		paths[path] = true
+	}
+	for path := range f.renames {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	// Every file is edited before any is written, so a failure leaves the project untouched
+	type change struct {
+		path, newPath string
+		old, new      []byte
+	}
+	changes := make([]change, 0, len(sorted))
+	for _, path := range sorted {
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated := old
+		if edits := f.edits[path]; len(edits) > 0 {
+			if updated, err = applySourceEdits(old, edits); err != nil {
+				return fmt.Errorf("failed to fix %s: %w", path, err)
+			}
+		}
+		newPath := path
+		if renamed, ok := f.renames[path]; ok {
+			newPath = renamed
+		}
+		changes = append(changes, change{path: path, newPath: newPath, old: old, new: updated})
+	}
+
+	for _, c := range changes {
+		if diff != nil {
+			fmt.Fprint(diff, textdiff.Unified(relativePath(c.path), relativePath(c.newPath), c.old, c.new))
+			continue
+		}
+		if string(c.new) != string(c.old) {
+			info, err := os.Stat(c.path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(c.path, c.new, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("writing %s: %w", c.path, err)
+			}
+		}
+		if c.newPath != c.path {
+			if err := os.Rename(c.path, c.newPath); err != nil {
+				return fmt.Errorf("renaming %s: %w", c.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applySourceEdits replaces the identifiers of src and formats the result with gofmt, since
+// names of another length change the alignment of fields and comments
+func applySourceEdits(src []byte, edits []sourceEdit) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	out := append([]byte{}, src...)
+	last := -1
+	for _, edit := range edits {
+		// A declaration is both a definition and a reference of some objects, e.g. struct
+		// fields in composite literals, so an offset can be edited twice
+		if edit.offset == last {
+			continue
+		}
+		last = edit.offset
+		out = append(out[:edit.offset], append([]byte(edit.text), out[edit.offset+edit.length:]...)...)
+	}
+	return format.Source(out)
+}
+
+// loadTypedPackages loads the packages of dirs in a module, with their test files, syntax and types
+func loadTypedPackages(moduleDir string, dirs map[string]bool) ([]*packages.Package, error) {
+	var patterns []string
+	for dir := range dirs {
+		rel, err := filepath.Rel(moduleDir, dir)
+		if err != nil {
+			continue
+		}
+		patterns = append(patterns, "./"+filepath.ToSlash(rel))
+	}
+	sort.Strings(patterns)
+
+	flags, cleanup, err := scratchModFlags(moduleDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	config := &packages.Config{
+		Mode:       typedLoadMode(moduleDir),
+		Dir:        moduleDir,
+		Tests:      true,
+		BuildFlags: flags,
+	}
+	pkgs, err := packages.Load(config, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := pkgs[:0]
+	for _, pkg := range pkgs {
+		// Skip the generated test mains
+		if !strings.HasSuffix(pkg.ID, ".test") {
+			loaded = append(loaded, pkg)
+		}
+	}
+	return loaded, nil
+}
+
+// typedLoadMode returns the mode loading packages with their syntax and types. The types of the
+// dependencies are read from their export data, unless the toolchain writes export data the
+// loader cannot read, in which case the dependencies are type-checked from source.
+func typedLoadMode(dir string) packages.LoadMode {
+	mode := packages.LoadSyntax | packages.NeedModule
+	probe, err := packages.Load(&packages.Config{Context: context.Background(), Dir: dir, Mode: packages.NeedName | packages.NeedTypes}, "errors")
+	if err != nil || len(probe) != 1 || len(probe[0].Errors) > 0 || probe[0].Types == nil || !probe[0].Types.Complete() {
+		mode |= packages.NeedDeps
+	}
+	return mode
+}
+
+// renameConflict returns why obj cannot be renamed to name, or "" when the rename is safe
+func renameConflict(pkg *packages.Package, obj types.Object, decl *ast.Ident, name string) string {
+	info := pkg.TypesInfo
+
+	// Fields and methods are looked up on the types they are selected from
+	if isMember(obj) {
+		if fn, ok := obj.(*types.Func); ok {
+			recv := fn.Type().(*types.Signature).Recv().Type()
+			if other, _, _ := types.LookupFieldOrMethod(recv, true, pkg.Types, name); other != nil {
+				return fmt.Sprintf("%s already has a field or method %s", types.TypeString(recv, types.RelativeTo(pkg.Types)), name)
+			}
+			// An unexported method can implement the unexported interfaces of its package
+			for _, def := range info.Defs {
+				if typeName, ok := def.(*types.TypeName); ok {
+					if iface, ok := typeName.Type().Underlying().(*types.Interface); ok {
+						for i := 0; i < iface.NumMethods(); i++ {
+							if iface.Method(i).Name() == obj.Name() {
+								return "the method may implement interface " + typeName.Name()
+							}
+						}
+					}
+				}
+			}
+		} else if owner := fieldOwner(pkg, decl); owner != nil {
+			if other, _, _ := types.LookupFieldOrMethod(owner, true, pkg.Types, name); other != nil {
+				return fmt.Sprintf("%s already has a field or method %s", types.TypeString(owner, types.RelativeTo(pkg.Types)), name)
+			}
+		}
+		for _, selection := range info.Selections {
+			if origin(selection.Obj()) != obj {
+				continue
+			}
+			if other, _, _ := types.LookupFieldOrMethod(selection.Recv(), true, pkg.Types, name); other != nil {
+				return fmt.Sprintf("%s already has a field or method %s", types.TypeString(selection.Recv(), types.RelativeTo(pkg.Types)), name)
+			}
+		}
+		return ""
+	}
+
+	scope := obj.Parent()
+	if scope.Lookup(name) != nil {
+		return name + " is already declared in the same scope"
+	}
+	// Package names share the scope of the package, although they are declared by files
+	if scope == pkg.Types.Scope() {
+		for _, file := range pkg.Syntax {
+			if fileScope := info.Scopes[file]; fileScope != nil && fileScope.Lookup(name) != nil {
+				return name + " is already imported by " + filepath.Base(pkg.Fset.File(file.Pos()).Name())
+			}
+		}
+	}
+
+	// The references must not be shadowed by a declaration of name in an inner scope
+	for _, id := range references(pkg, obj) {
+		inner := pkg.Types.Scope().Innermost(id.Pos())
+		if inner == nil {
+			continue
+		}
+		if found, other := inner.LookupParent(name, id.Pos()); other != nil && found != scope && encloses(scope, found) {
+			return fmt.Sprintf("a reference would refer to the %s declared at %s", name, pkg.Fset.Position(other.Pos()))
+		}
+	}
+
+	// The renamed object must not shadow an outer declaration of name used in its scope
+	shadowed := token.NoPos
+	for id, use := range info.Uses {
+		if id.Name != name || use.Parent() == nil || !encloses(use.Parent(), scope) {
+			continue
+		}
+		if (scope == pkg.Types.Scope() || scope.Contains(id.Pos())) && (shadowed == token.NoPos || id.Pos() < shadowed) {
+			shadowed = id.Pos()
+		}
+	}
+	if shadowed != token.NoPos {
+		return fmt.Sprintf("the new name would shadow the %s used at %s", name, pkg.Fset.Position(shadowed))
+	}
+	return ""
+}
+
+// references returns the identifiers referring to obj in pkg, its declaration included
+func references(pkg *packages.Package, obj types.Object) []*ast.Ident {
+	var refs []*ast.Ident
+	for id, def := range pkg.TypesInfo.Defs {
+		if def != nil && origin(def) == obj {
+			refs = append(refs, id)
+		}
+	}
+	for id, use := range pkg.TypesInfo.Uses {
+		if origin(use) == obj {
+			refs = append(refs, id)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Pos() < refs[j].Pos() })
+	return refs
+}
+
+// origin returns the generic object of the fields and methods of instantiated types
+func origin(obj types.Object) types.Object {
+	switch obj := obj.(type) {
+	case *types.Var:
+		return obj.Origin()
+	case *types.Func:
+		return obj.Origin()
+	}
+	return obj
+}
+
+// isMember reports whether obj is a struct field or a method
+func isMember(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.Var:
+		return obj.IsField()
+	case *types.Func:
+		return obj.Type().(*types.Signature).Recv() != nil
+	}
+	return false
+}
+
+// fieldOwner returns the type declaring the field of decl: the named type of the struct, with its
+// methods, or the struct itself when it is anonymous
+func fieldOwner(pkg *packages.Package, decl *ast.Ident) types.Type {
+	file := fileOf(pkg, decl.Pos())
+	if file == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, decl.Pos(), decl.End())
+	for i, node := range path {
+		structType, ok := node.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		if i+1 < len(path) {
+			if spec, ok := path[i+1].(*ast.TypeSpec); ok && spec.Type == structType {
+				if def := pkg.TypesInfo.Defs[spec.Name]; def != nil {
+					return def.Type()
+				}
+			}
+		}
+		return pkg.TypesInfo.TypeOf(structType)
+	}
+	return nil
+}
+
+// renameScope returns the key of the names an object is renamed next to: its scope, or the type
+// declaring a field or method
+func renameScope(pkg *packages.Package, obj types.Object, decl *ast.Ident) any {
+	if fn, ok := obj.(*types.Func); ok && isMember(obj) {
+		return types.TypeString(fn.Type().(*types.Signature).Recv().Type(), nil)
+	}
+	if isMember(obj) {
+		if owner := fieldOwner(pkg, decl); owner != nil {
+			return types.TypeString(owner, nil)
+		}
+	}
+	return obj.Parent()
+}
+
+// encloses reports whether outer is inner or one of its parents
+func encloses(outer, inner *types.Scope) bool {
+	for scope := inner; scope != nil; scope = scope.Parent() {
+		if scope == outer {
+			return true
+		}
+	}
+	return false
+}
+
+// fileOf returns the file of pkg containing pos
+func fileOf(pkg *packages.Package, pos token.Pos) *ast.File {
+	for _, file := range pkg.Syntax {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// camelCaseName returns the camelCase spelling of an unexported name, e.g. userID for user_id
+func camelCaseName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' })
+	var b strings.Builder
+	for i, part := range parts {
+		runes := []rune(part)
+		switch {
+		case i == 0 && strings.ToUpper(part) == part:
+			b.WriteString(strings.ToLower(part))
+		case i == 0:
+			b.WriteRune(unicode.ToLower(runes[0]))
+			b.WriteString(string(runes[1:]))
+		case commonInitialisms[strings.ToUpper(part)]:
+			b.WriteString(strings.ToUpper(part))
+		default:
+			b.WriteRune(unicode.ToUpper(runes[0]))
+			b.WriteString(string(runes[1:]))
+		}
+	}
+	return b.String()
+}
+
+// snakeCaseFileName returns the snake_case spelling of a Go file name, e.g. user_service.go for
+// UserService.go or user-service.go
+func snakeCaseFileName(name string) string {
+	runes := []rune(strings.TrimSuffix(name, ".go"))
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '.' || r == ' ':
+			r = '_'
+		case unicode.IsUpper(r) && i > 0:
+			// A word starts at an uppercase letter after a lowercase letter or a digit, or at the
+			// last uppercase letter of an initialism followed by a lowercase letter, as in HTTPServer
+			previous := runes[i-1]
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	snake := strings.Trim(b.String(), "_")
+	for strings.Contains(snake, "__") {
+		snake = strings.ReplaceAll(snake, "__", "_")
+	}
+	return snake + ".go"
+}
+
+// fileConstraint returns the GOOS and GOARCH a file name without its extension constrains the
+// build to, the way the go command reads them: the words after the first underscore, _test aside
+func fileConstraint(stem string) string {
+	i := strings.Index(stem, "_")
+	if i < 0 {
+		return ""
+	}
+	words := strings.Split(stem[i+1:], "_")
+	if n := len(words); n > 0 && words[n-1] == "test" {
+		words = words[:n-1]
+	}
+	n := len(words)
+	if n >= 2 && knownOS[words[n-2]] && knownArch[words[n-1]] {
+		return words[n-2] + "_" + words[n-1]
+	}
+	if n >= 1 && (knownOS[words[n-1]] || knownArch[words[n-1]]) {
+		return words[n-1]
+	}
+	return ""
+}
+
+// sortNamingFixes sorts fixes by position
+func sortNamingFixes(fixes []NamingFix) {
+	sort.SliceStable(fixes, func(i, j int) bool {
+		a, b := fixes[i], fixes[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeNamingModule writes files into a new Go module and makes it the working directory, where
+// the rules of the validator are looked up
+func writeNamingModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/shop\n\ngo 1.24\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func TestFixNaming(t *testing.T) {
+	previous := console
+	console = io.Discard
+	t.Cleanup(func() { console = previous })
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		// fixed and unfixed map the names of the violations to their replacement, or to the
+		// reason they were left to be fixed by hand
+		fixed   map[string]string
+		unfixed map[string]string
+		// want maps the files after the fixes to their content
+		want map[string]string
+	}{
+		{
+			name: "function renamed with its references",
+			files: map[string]string{
+				"shop/user.go": "package shop\n\nfunc get_user_id() int { return 1 }\n\nfunc Lookup() int { return get_user_id() + get_user_id() }\n",
+			},
+			fixed: map[string]string{"get_user_id": "getUserID"},
+			want: map[string]string{
+				"shop/user.go": "package shop\n\nfunc getUserID() int { return 1 }\n\nfunc Lookup() int { return getUserID() + getUserID() }\n",
+			},
+		},
+		{
+			name: "struct field renamed in literals and selectors",
+			files: map[string]string{
+				"shop/order.go": "package shop\n\ntype Order struct {\n\tunit_price int\n}\n\nfunc total() int {\n\to := Order{unit_price: 2}\n\treturn o.unit_price\n}\n",
+			},
+			fixed: map[string]string{"unit_price": "unitPrice"},
+			want: map[string]string{
+				"shop/order.go": "package shop\n\ntype Order struct {\n\tunitPrice int\n}\n\nfunc total() int {\n\to := Order{unitPrice: 2}\n\treturn o.unitPrice\n}\n",
+			},
+		},
+		{
+			name: "rename conflicting with a declaration of the scope",
+			files: map[string]string{
+				"shop/user.go": "package shop\n\nfunc get_user() {}\n\nfunc getUser() { get_user() }\n",
+			},
+			unfixed: map[string]string{"get_user": "getUser is already declared in the same scope"},
+			want: map[string]string{
+				"shop/user.go": "package shop\n\nfunc get_user() {}\n\nfunc getUser() { get_user() }\n",
+			},
+		},
+		{
+			name: "rename shadowed by an inner declaration",
+			files: map[string]string{
+				"shop/user.go": "package shop\n\nvar user_name = \"alice\"\n\nfunc Greet() string {\n\tuserName := \"bob\"\n\treturn userName + user_name\n}\n",
+			},
+			unfixed: map[string]string{"user_name": "a reference would refer to the userName declared at"},
+		},
+		{
+			name: "rename shadowing an outer declaration",
+			files: map[string]string{
+				"shop/user.go": "package shop\n\nvar userName = \"alice\"\n\nfunc Greet() string {\n\tvar user_name = \"bob\"\n\treturn userName + user_name\n}\n",
+			},
+			unfixed: map[string]string{"user_name": "the new name would shadow the userName used at"},
+		},
+		{
+			name: "two names renamed to the same name",
+			files: map[string]string{
+				"shop/user.go": "package shop\n\nfunc get_user() {}\n\nfunc get__user() {}\n\nfunc Use() { get_user(); get__user() }\n",
+			},
+			fixed:   map[string]string{"get_user": "getUser"},
+			unfixed: map[string]string{"get__user": "another identifier of the same scope is renamed to getUser"},
+		},
+		{
+			name: "exported name left to be fixed by hand",
+			files: map[string]string{
+				"shop/user.go": "package shop\n\nfunc Get_user() {}\n",
+			},
+			unfixed: map[string]string{"Get_user": "exported names are part of the API of the package"},
+		},
+		{
+			name: "file renamed to snake_case",
+			files: map[string]string{
+				"shop/UserService.go": "package shop\n",
+			},
+			fixed: map[string]string{"UserService.go": "user_service.go"},
+			want:  map[string]string{"shop/user_service.go": "package shop\n"},
+		},
+		{
+			name: "file rename to an existing file",
+			files: map[string]string{
+				"shop/UserService.go":  "package shop\n",
+				"shop/user_service.go": "package shop\n\nvar Users = 1\n",
+			},
+			unfixed: map[string]string{"UserService.go": "user_service.go already exists"},
+			want:    map[string]string{"shop/UserService.go": "package shop\n"},
+		},
+		{
+			name: "file rename changing its build constraints",
+			files: map[string]string{
+				"shop/Server-Linux.go": "package shop\n",
+			},
+			unfixed: map[string]string{"Server-Linux.go": "server_linux.go would change the GOOS or GOARCH the file is built for"},
+			want:    map[string]string{"shop/Server-Linux.go": "package shop\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeNamingModule(t, tt.files)
+
+			summary, err := FixNaming(dir, defaultSQLPath, defaultAPIPath, nil)
+			require.NoError(t, err)
+
+			fixed := make(map[string]string)
+			for _, fix := range summary.Fixed {
+				fixed[fix.Name] = fix.Replacement
+			}
+			for name, replacement := range tt.fixed {
+				assert.Equal(t, replacement, fixed[name], "fix of %s", name)
+			}
+			assert.Len(t, fixed, len(tt.fixed))
+
+			unfixed := make(map[string]string)
+			for _, fix := range summary.Unfixed {
+				unfixed[fix.Name] = fix.Reason
+			}
+			for name, reason := range tt.unfixed {
+				assert.Contains(t, unfixed[name], reason, "reason %s is left to be fixed by hand", name)
+			}
+			assert.Len(t, unfixed, len(tt.unfixed))
+
+			for name, content := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				require.NoError(t, err)
+				assert.Equal(t, content, string(got), name)
+			}
+		})
+	}
+}
+
+func TestFixNamingDryRun(t *testing.T) {
+	previous := console
+	console = io.Discard
+	t.Cleanup(func() { console = previous })
+
+	source := "package shop\n\nfunc get_user() {}\n\nfunc Use() { get_user() }\n"
+	dir := writeNamingModule(t, map[string]string{"shop/UserService.go": source})
+
+	var diff bytes.Buffer
+	summary, err := FixNaming(dir, defaultSQLPath, defaultAPIPath, &diff)
+	require.NoError(t, err)
+	assert.Len(t, summary.Fixed, 2)
+	assert.Contains(t, diff.String(), "+++ shop/user_service.go")
+	assert.Contains(t, diff.String(), "+func getUser() {}")
+
+	// Nothing is written
+	got, err := os.ReadFile(filepath.Join(dir, "shop", "UserService.go"))
+	require.NoError(t, err)
+	assert.Equal(t, source, string(got))
+	assert.NoFileExists(t, filepath.Join(dir, "shop", "user_service.go"))
+}
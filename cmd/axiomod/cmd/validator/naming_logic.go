@@ -2,7 +2,6 @@ package validator
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -11,6 +10,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
 )
 
 // ValidationResult stores the result of a validation check
@@ -19,11 +20,17 @@ type ValidationResult struct {
 	Line        int    `json:"line"`
 	Column      int    `json:"column"`
 	Type        string `json:"type"`
+	Rule        string `json:"rule"`
 	Name        string `json:"name"`
 	Expected    string `json:"expected"`
 	Description string `json:"description"`
 }
 
+// Message returns the result as "<type> '<name>' should be <expected>: <description>"
+func (r ValidationResult) Message() string {
+	return fmt.Sprintf("%s '%s' should be %s: %s", r.Type, r.Name, r.Expected, r.Description)
+}
+
 // ValidationResults holds all errors and warnings
 type ValidationResults struct {
 	Errors   []ValidationResult `json:"errors"`
@@ -42,6 +49,7 @@ type NamingValidationSummary struct {
 	EndpointsChecked   int
 	TablesChecked      int
 	ColumnsChecked     int
+	MigrationsChecked  int
 	SchemaTypesChecked int
 
 	// Violation statistics
@@ -77,6 +85,30 @@ func (v *NamingValidator) AddWarning(result *ValidationResult) {
 	v.results.Warnings = append(v.results.Warnings, *result)
 }
 
+// applyPolicy moves the results to the level configured for their rule, and drops the results of
+// rules that are off or suppressed by a comment
+func (v *NamingValidator) applyPolicy(policy *rulePolicy) {
+	results := v.results
+	v.results = ValidationResults{
+		Errors:   make([]ValidationResult, 0, len(results.Errors)),
+		Warnings: make([]ValidationResult, 0, len(results.Warnings)),
+	}
+	apply := func(result ValidationResult, defaultLevel string) {
+		switch policy.level(ruleID("naming", result.Rule), defaultLevel, result.File, result.Line) {
+		case LevelError:
+			v.AddError(&result)
+		case LevelWarning:
+			v.AddWarning(&result)
+		}
+	}
+	for _, result := range results.Errors {
+		apply(result, LevelError)
+	}
+	for _, result := range results.Warnings {
+		apply(result, LevelWarning)
+	}
+}
+
 // HasErrors returns true if there are errors
 func (v *NamingValidator) HasErrors() bool {
 	return len(v.results.Errors) > 0
@@ -93,14 +125,65 @@ func (v *NamingValidator) GetWarningCount() int {
 }
 
 // RunNamingValidation validates naming conventions in the codebase
-func RunNamingValidation(dirPath string, sqlPath string, apiPath string, jsonOutput bool) (bool, error) {
-	fmt.Println("⏳ Running naming convention checks...")
+func RunNamingValidation(dirPath string, sqlPath string, apiPath string) (bool, error) {
+	fmt.Fprintln(console, "⏳ Running naming convention checks...")
+
+	validator, summary, err := collectNamingResults(dirPath, sqlPath, apiPath)
+	if err != nil {
+		return false, err
+	}
+
+	// Print summary report first
+	fmt.Fprintln(console, "\nNaming Convention Validation Summary:")
+	printNamingSummaryReport(summary)
+
+	// Report results
+	outputConsole(validator)
+
+	// Print summary report again after violations
+	if summary.TotalErrors > 0 {
+		fmt.Fprintln(console, "\nNaming Convention Validation Summary:")
+		printNamingSummaryReport(summary)
+	}
+
+	// Return success if there are no errors
+	if validator.HasErrors() {
+		return false, fmt.Errorf("naming convention validation failed with %d errors", validator.GetErrorCount())
+	}
+
+	return true, nil
+}
+
+// NamingReport validates naming conventions in the codebase and returns its errors and warnings
+func NamingReport(dirPath string, sqlPath string, apiPath string) (*Report, error) {
+	validator, _, err := collectNamingResults(dirPath, sqlPath, apiPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Validator: "naming"}
+	for _, result := range validator.results.Errors {
+		report.Add(result.Rule, LevelError, result.Message(), result.File, result.Line, result.Column)
+	}
+	for _, result := range validator.results.Warnings {
+		report.Add(result.Rule, LevelWarning, result.Message(), result.File, result.Line, result.Column)
+	}
+	return report, nil
+}
+
+// collectNamingResults runs the naming checks on Go code, API endpoints, SQL migrations and Ent
+// schemas. Relative SQL and API paths are resolved against dirPath.
+func collectNamingResults(dirPath string, sqlPath string, apiPath string) (*NamingValidator, *NamingValidationSummary, error) {
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Create validator instance
 	validator := NewNamingValidator()
 
 	// Initialize validation summary
-	summary := NamingValidationSummary{
+	summary := &NamingValidationSummary{
 		ErrorsByType:   make(map[string]int),
 		WarningsByType: make(map[string]int),
 		ErrorsByFile:   make(map[string]int),
@@ -109,29 +192,32 @@ func RunNamingValidation(dirPath string, sqlPath string, apiPath string, jsonOut
 	// Resolve relative paths to absolute paths for consistency
 	rootDir, err := filepath.Abs(dirPath)
 	if err != nil {
-		return false, fmt.Errorf("error resolving path: %v", err)
+		return nil, nil, fmt.Errorf("error resolving path: %v", err)
 	}
 
 	// Validate Go code (files, packages, variables, functions)
-	validateGoCode(rootDir, validator, &summary)
+	validateGoCode(rootDir, validator, summary)
 
 	// Validate API endpoint naming in handlers
 	apiDir := apiPath
 	if !filepath.IsAbs(apiDir) {
 		apiDir = filepath.Join(rootDir, apiDir)
 	}
-	validateAPIEndpoints(apiDir, validator, &summary)
+	validateAPIEndpoints(apiDir, validator, summary)
 
 	// Validate database naming (tables, columns, etc.)
 	sqlDir := sqlPath
 	if !filepath.IsAbs(sqlDir) {
 		sqlDir = filepath.Join(rootDir, sqlDir)
 	}
-	validateDatabaseNaming(sqlDir, validator, &summary)
+	validateDatabaseNaming(sqlDir, validator, summary)
 
 	// Validate Ent schemas
 	entDir := filepath.Join(rootDir, filepath.FromSlash("platform/ent/schema"))
-	validateEntSchemas(entDir, validator, &summary)
+	validateEntSchemas(entDir, validator, summary)
+
+	// Apply the configured rule severities and the suppression comments
+	validator.applyPolicy(policy)
 
 	// Update summary counters
 	summary.TotalErrors = validator.GetErrorCount()
@@ -148,78 +234,57 @@ func RunNamingValidation(dirPath string, sqlPath string, apiPath string, jsonOut
 		summary.WarningsByType[warning.Type]++
 	}
 
-	// Print summary report first
-	if !jsonOutput {
-		fmt.Println("\nNaming Convention Validation Summary:")
-		printNamingSummaryReport(&summary)
-	}
-
-	// Report results
-	if jsonOutput {
-		outputJSON(validator)
-	} else {
-		outputConsole(validator)
-	}
-
-	// Print summary report again after violations
-	if summary.TotalErrors > 0 && !jsonOutput {
-		fmt.Println("\nNaming Convention Validation Summary:")
-		printNamingSummaryReport(&summary)
-	}
-
-	// Return success if there are no errors
-	if validator.HasErrors() {
-		return false, fmt.Errorf("naming convention validation failed with %d errors", validator.GetErrorCount())
-	}
-
-	return true, nil
+	return validator, summary, nil
 }
 
 // printNamingSummaryReport prints a summary of the validation results
 func printNamingSummaryReport(summary *NamingValidationSummary) {
-	fmt.Printf("  Files checked: %d\n", summary.FilesChecked)
+	fmt.Fprintf(console, "  Files checked: %d\n", summary.FilesChecked)
 
 	if summary.PackagesChecked > 0 {
-		fmt.Printf("  Packages checked: %d\n", summary.PackagesChecked)
+		fmt.Fprintf(console, "  Packages checked: %d\n", summary.PackagesChecked)
 	}
 	if summary.FunctionsChecked > 0 {
-		fmt.Printf("  Functions checked: %d\n", summary.FunctionsChecked)
+		fmt.Fprintf(console, "  Functions checked: %d\n", summary.FunctionsChecked)
 	}
 	if summary.TypesChecked > 0 {
-		fmt.Printf("  Types checked: %d\n", summary.TypesChecked)
+		fmt.Fprintf(console, "  Types checked: %d\n", summary.TypesChecked)
 	}
 	if summary.StructsChecked > 0 {
-		fmt.Printf("  Structs checked: %d\n", summary.StructsChecked)
+		fmt.Fprintf(console, "  Structs checked: %d\n", summary.StructsChecked)
 	}
 	if summary.VariablesChecked > 0 {
-		fmt.Printf("  Variables checked: %d\n", summary.VariablesChecked)
+		fmt.Fprintf(console, "  Variables checked: %d\n", summary.VariablesChecked)
 	}
 	if summary.TablesChecked > 0 {
-		fmt.Printf("  Database tables checked: %d\n", summary.TablesChecked)
+		fmt.Fprintf(console, "  Database tables checked: %d\n", summary.TablesChecked)
 	}
 	if summary.ColumnsChecked > 0 {
-		fmt.Printf("  Database columns checked: %d\n", summary.ColumnsChecked)
+		fmt.Fprintf(console, "  Database columns checked: %d\n", summary.ColumnsChecked)
+	}
+	if summary.MigrationsChecked > 0 {
+		fmt.Fprintf(console, "  SQL migrations checked: %d\n", summary.MigrationsChecked)
 	}
 	if summary.EndpointsChecked > 0 {
-		fmt.Printf("  API endpoints checked: %d\n", summary.EndpointsChecked)
+		fmt.Fprintf(console, "  API endpoints checked: %d\n", summary.EndpointsChecked)
 	}
 	if summary.SchemaTypesChecked > 0 {
-		fmt.Printf("  Schema types checked: %d\n", summary.SchemaTypesChecked)
+		fmt.Fprintf(console, "  Schema types checked: %d\n", summary.SchemaTypesChecked)
 	}
 
-	fmt.Printf("  Total errors: %d\n", summary.TotalErrors)
-	fmt.Printf("  Total warnings: %d\n", summary.TotalWarnings)
+	fmt.Fprintf(console, "  Total errors: %d\n", summary.TotalErrors)
+	fmt.Fprintf(console, "  Total warnings: %d\n", summary.TotalWarnings)
 
 	if summary.TotalErrors > 0 {
-		fmt.Println("\nErrors by type:")
+		fmt.Fprintln(console, "\nErrors by type:")
 		printTopNamingViolations(summary.ErrorsByType)
 
-		fmt.Println("\nTop files with naming violations:")
+		fmt.Fprintln(console, "\nTop files with naming violations:")
 		printTopNamingViolations(summary.ErrorsByFile)
 	}
 
 	if summary.TotalWarnings > 0 {
-		fmt.Println("\nWarnings by type:")
+		fmt.Fprintln(console, "\nWarnings by type:")
 		printTopNamingViolations(summary.WarningsByType)
 	}
 }
@@ -253,56 +318,38 @@ func printTopNamingViolations(violations map[string]int) {
 	}
 
 	for i := 0; i < count; i++ {
-		fmt.Printf("  - %s: %d\n", items[i].item, items[i].count)
+		fmt.Fprintf(console, "  - %s: %d\n", items[i].item, items[i].count)
 	}
 }
 
 func outputConsole(validator *NamingValidator) {
 	// Print warnings
 	if validator.GetWarningCount() > 0 {
-		fmt.Println("\n⚠️  Warnings:")
+		fmt.Fprintln(console, "\n⚠️  Warnings:")
 		for _, warning := range validator.results.Warnings {
-			fmt.Printf("  • %s:%d:%d: %s '%s' should be %s: %s\n",
-				warning.File, warning.Line, warning.Column,
-				warning.Type, warning.Name, warning.Expected, warning.Description)
+			fmt.Fprintf(console, "  • %s:%d:%d: %s\n", warning.File, warning.Line, warning.Column, warning.Message())
 		}
 	}
 
 	// Print errors
 	if validator.HasErrors() {
-		fmt.Println("\n❌ Naming convention errors:")
+		fmt.Fprintln(console, "\n❌ Naming convention errors:")
 		for _, err := range validator.results.Errors {
-			fmt.Printf("  • %s:%d:%d: %s '%s' should be %s: %s\n",
-				err.File, err.Line, err.Column,
-				err.Type, err.Name, err.Expected, err.Description)
+			fmt.Fprintf(console, "  • %s:%d:%d: %s\n", err.File, err.Line, err.Column, err.Message())
 		}
-		fmt.Printf("\nFound %d naming convention errors.\n", validator.GetErrorCount())
-		fmt.Println("Please fix these issues to comply with the project naming standards.")
+		fmt.Fprintf(console, "\nFound %d naming convention errors.\n", validator.GetErrorCount())
+		fmt.Fprintln(console, "Please fix these issues to comply with the project naming standards.")
 	} else {
-		fmt.Println("\n✅ All naming conventions checks passed!")
+		fmt.Fprintln(console, "\n✅ All naming conventions checks passed!")
 	}
 
 	if validator.GetWarningCount() > 0 {
-		fmt.Printf("Found %d warnings (these won't fail the build).\n", validator.GetWarningCount())
+		fmt.Fprintf(console, "Found %d warnings (these won't fail the build).\n", validator.GetWarningCount())
 	}
 }
 
-func outputJSON(validator *NamingValidator) {
-	// Marshal to JSON
-	jsonData, err := json.MarshalIndent(validator.results, "", "  ")
-	if err != nil {
-		fmt.Printf("Error generating JSON output: %v\n", err)
-		return
-	}
-
-	fmt.Println(string(jsonData))
-}
-
 func validateGoCode(dirPath string, validator *NamingValidator, summary *NamingValidationSummary) {
-	fmt.Println("Checking Go code naming conventions...")
-
-	// Create a new file set
-	fset := token.NewFileSet()
+	fmt.Fprintln(console, "Checking Go code naming conventions...")
 
 	// Walk through directory
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
@@ -316,24 +363,24 @@ func validateGoCode(dirPath string, validator *NamingValidator, summary *NamingV
 			return nil
 		}
 
-		// Check only Go files
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+		// Check only Go files of the packages in scope
+		if !info.IsDir() && strings.HasSuffix(path, ".go") && scope.includes(path) {
 			summary.FilesChecked++
-			validateGoFile(fset, path, validator, summary)
+			validateGoFile(path, validator, summary)
 		}
 
 		return nil
 	})
 	if err != nil {
-		fmt.Printf("Error walking directory: %v\n", err)
+		fmt.Fprintf(console, "Error walking directory: %v\n", err)
 	}
 }
 
-func validateGoFile(fset *token.FileSet, path string, validator *NamingValidator, summary *NamingValidationSummary) {
-	// Parse the Go file
-	node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+func validateGoFile(path string, validator *NamingValidator, summary *NamingValidationSummary) {
+	// Parse the Go file, or reuse it when it did not change since the previous watch run
+	fset, node, err := parsedFiles.parse(path, parser.ParseComments)
 	if err != nil {
-		fmt.Printf("Error parsing file %s: %v\n", path, err)
+		fmt.Fprintf(console, "Error parsing file %s: %v\n", path, err)
 		return
 	}
 
@@ -393,14 +440,15 @@ func validatePackageName(fset *token.FileSet, node *ast.File, path string, valid
 		return // Skip validation for API version packages
 	}
 
-	// Package names should be lowercase, single words
-	if !regexp.MustCompile(`^[a-z]+$`).MatchString(name) {
+	// Package names should be lowercase, single words; external test packages end with _test
+	if !regexp.MustCompile(`^[a-z][a-z0-9]*$`).MatchString(strings.TrimSuffix(name, "_test")) {
 		pos := fset.Position(node.Name.Pos())
 		validator.AddError(&ValidationResult{
 			File:        path,
 			Line:        pos.Line,
 			Column:      pos.Column,
 			Type:        "Package",
+			Rule:        "package",
 			Name:        name,
 			Expected:    "lowercase, single word",
 			Description: "Package names should be lowercase, single words without underscores",
@@ -426,6 +474,7 @@ func validatePackageName(fset *token.FileSet, node *ast.File, path string, valid
 			Line:        pos.Line,
 			Column:      pos.Column,
 			Type:        "Package",
+			Rule:        "package-singular",
 			Name:        name,
 			Expected:    "singular form",
 			Description: "Package names should use singular form, not plural",
@@ -448,6 +497,7 @@ func validateFunctionName(fset *token.FileSet, fn *ast.FuncDecl, path string, va
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Test function",
+				Rule:        "test-function",
 				Name:        name,
 				Expected:    "Test[Type]_[Method] or similar pattern",
 				Description: "Test functions should follow patterns like Test[Type]_[Method]",
@@ -466,6 +516,7 @@ func validateFunctionName(fset *token.FileSet, fn *ast.FuncDecl, path string, va
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Exported function",
+				Rule:        "exported-function",
 				Name:        name,
 				Expected:    "PascalCase",
 				Description: "Exported functions should use PascalCase",
@@ -480,6 +531,7 @@ func validateFunctionName(fset *token.FileSet, fn *ast.FuncDecl, path string, va
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Unexported function",
+				Rule:        "unexported-function",
 				Name:        name,
 				Expected:    "camelCase",
 				Description: "Unexported functions should use camelCase",
@@ -501,6 +553,7 @@ func validateVariableName(fset *token.FileSet, name *ast.Ident, path string, val
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Exported variable",
+				Rule:        "exported-variable",
 				Name:        varName,
 				Expected:    "PascalCase",
 				Description: "Exported variables should use PascalCase",
@@ -515,6 +568,7 @@ func validateVariableName(fset *token.FileSet, name *ast.Ident, path string, val
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Unexported variable",
+				Rule:        "unexported-variable",
 				Name:        varName,
 				Expected:    "camelCase",
 				Description: "Unexported variables should use camelCase",
@@ -548,6 +602,7 @@ func validateVariableName(fset *token.FileSet, name *ast.Ident, path string, val
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Boolean variable",
+				Rule:        "boolean-variable",
 				Name:        varName,
 				Expected:    "boolean type",
 				Description: "Variable name suggests it's a boolean, verify the type is correct",
@@ -559,17 +614,22 @@ func validateVariableName(fset *token.FileSet, name *ast.Ident, path string, val
 func validateTypeName(fset *token.FileSet, typeSpec *ast.TypeSpec, path string, validator *NamingValidator) {
 	typeName := typeSpec.Name.String()
 
-	// All types should be PascalCase
-	if !regexp.MustCompile("^[A-Z][a-zA-Z0-9]*$").MatchString(typeName) {
+	// Exported types should be PascalCase, unexported types camelCase
+	expected, pattern := "PascalCase", "^[A-Z][a-zA-Z0-9]*$"
+	if !ast.IsExported(typeName) {
+		expected, pattern = "camelCase", "^[a-z][a-zA-Z0-9]*$"
+	}
+	if !regexp.MustCompile(pattern).MatchString(typeName) && typeName != "_" {
 		pos := fset.Position(typeSpec.Name.Pos())
 		validator.AddError(&ValidationResult{
 			File:        path,
 			Line:        pos.Line,
 			Column:      pos.Column,
 			Type:        "Type",
+			Rule:        "type",
 			Name:        typeName,
-			Expected:    "PascalCase",
-			Description: "Type names should use PascalCase",
+			Expected:    expected,
+			Description: "Type names should use " + expected,
 		})
 	}
 
@@ -586,6 +646,7 @@ func validateTypeName(fset *token.FileSet, typeSpec *ast.TypeSpec, path string,
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Interface",
+				Rule:        "interface",
 				Name:        typeName,
 				Expected:    "name with -er suffix or standard pattern",
 				Description: "Consider using -er suffix for interfaces that define a single behavior",
@@ -607,6 +668,7 @@ func validateStructFieldName(fset *token.FileSet, name *ast.Ident, structName st
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Exported struct field",
+				Rule:        "exported-struct-field",
 				Name:        fieldName,
 				Expected:    "PascalCase",
 				Description: fmt.Sprintf("Exported fields in struct %s should use PascalCase", structName),
@@ -621,6 +683,7 @@ func validateStructFieldName(fset *token.FileSet, name *ast.Ident, structName st
 				Line:        pos.Line,
 				Column:      pos.Column,
 				Type:        "Unexported struct field",
+				Rule:        "unexported-struct-field",
 				Name:        fieldName,
 				Expected:    "camelCase",
 				Description: fmt.Sprintf("Unexported fields in struct %s should use camelCase", structName),
@@ -645,6 +708,7 @@ func validateFileName(path string, validator *NamingValidator) {
 			Line:        1,
 			Column:      1,
 			Type:        "File name",
+			Rule:        "file-name",
 			Name:        filename,
 			Expected:    "snake_case.go",
 			Description: "File names should use snake_case",
@@ -652,78 +716,83 @@ func validateFileName(path string, validator *NamingValidator) {
 	}
 }
 
+// validateAPIEndpoints checks the routes registered by the Go files under apiDir. The routes
+// are read from the route table of their module, so they have their full paths, with the
+// prefixes of the groups they are registered on and the constants they are built from.
 func validateAPIEndpoints(apiDir string, validator *NamingValidator, summary *NamingValidationSummary) {
-	fmt.Println("Checking API endpoint naming conventions...")
+	fmt.Fprintln(console, "Checking API endpoint naming conventions...")
 
-	// Walk through the API handlers directory
-	err := filepath.Walk(apiDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Only check Go files
-		if !info.IsDir() && strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
-			// Parse the file to find route definitions
-			content, err := os.ReadFile(path)
-			if err != nil {
-				fmt.Printf("Error reading file %s: %v\n", path, err)
-				return nil
-			}
+	// Check if the API directory exists
+	if _, err := os.Stat(apiDir); os.IsNotExist(err) {
+		fmt.Fprintf(console, "API directory %s does not exist, skipping API endpoint naming checks\n", apiDir)
+		return
+	}
 
-			// Look for route definitions in the file
-			scanner := bufio.NewScanner(strings.NewReader(string(content)))
-			lineNum := 0
-			for scanner.Scan() {
-				lineNum++
-				line := scanner.Text()
+	// The route table is only read when handlers changed
+	if scope != nil && len(scope.packagesUnder(apiDir)) == 0 {
+		return
+	}
 
-				// Look for route definitions like app.Get("/api/v1/users", ...)
-				// or router.Post("/api/v1/users", ...)
-				if routeRegex := regexp.MustCompile(`\.(Get|Post|Put|Delete|Patch)\s*\(\s*"([^"]+)"`); routeRegex.MatchString(line) {
-					matches := routeRegex.FindStringSubmatch(line)
-					if len(matches) >= 3 {
-						method := matches[1]
-						endpoint := matches[2]
-						summary.EndpointsChecked++
+	root, modulePath, err := apiscan.FindModule(apiDir)
+	if err != nil {
+		fmt.Fprintf(console, "No Go module found for %s, skipping API endpoint naming checks\n", apiDir)
+		return
+	}
+	scanner := apiscan.New(root, modulePath)
+	if err := scanner.Load(); err != nil {
+		fmt.Fprintf(console, "Error loading API routes: %v\n", err)
+		return
+	}
 
-						// Validate the endpoint
-						validateAPIEndpoint(path, lineNum, method, endpoint, validator)
-					}
-				}
-			}
+	for _, route := range scanner.Routes() {
+		position := scanner.Fset.Position(route.Pos)
+		if rel, err := filepath.Rel(apiDir, position.Filename); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || !scope.includes(position.Filename) {
+			continue
 		}
+		summary.EndpointsChecked++
 
-		return nil
-	})
-
-	if err != nil {
-		fmt.Printf("Error walking API directory: %v\n", err)
+		// Validate the endpoint
+		validateAPIEndpoint(position.Filename, position.Line, strings.ToUpper(route.Method), route.Path, route.RawPath, validator)
 	}
 }
 
-func validateAPIEndpoint(file string, line int, method string, endpoint string, validator *NamingValidator) {
+// validateAPIEndpoint checks the full path of a route, and the path it is registered with
+func validateAPIEndpoint(file string, line int, method string, endpoint string, registered string, validator *NamingValidator) {
+	// Path parameters and wildcards, e.g. /:id or /*, are named by the handlers
+	routeParam := regexp.MustCompile(`^(:.+|\*|\+)$`)
+	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
+	literal := "/"
+	for _, part := range parts {
+		if part != "" && !routeParam.MatchString(part) {
+			literal += part + "/"
+		}
+	}
+
 	// API endpoints should follow RESTful conventions
 	// They should be lowercase with hyphens as separators
-	if !regexp.MustCompile(`^/[a-z0-9/-]+$`).MatchString(endpoint) {
+	if !regexp.MustCompile(`^/[a-z0-9/-]*$`).MatchString(literal) {
 		validator.AddError(&ValidationResult{
 			File:        file,
 			Line:        line,
 			Column:      1,
 			Type:        "API endpoint",
+			Rule:        "api-endpoint",
 			Name:        endpoint,
 			Expected:    "lowercase with hyphens",
 			Description: "API endpoints should be lowercase with hyphens as separators",
 		})
 	}
 
-	// Check for trailing slashes (except for root endpoint)
-	if endpoint != "/" && strings.HasSuffix(endpoint, "/") {
+	// Check for trailing slashes (except for root endpoint). Full paths are joined without them,
+	// so the registered path is checked.
+	if registered != "/" && strings.HasSuffix(registered, "/") {
 		validator.AddWarning(&ValidationResult{
 			File:        file,
 			Line:        line,
 			Column:      1,
 			Type:        "API endpoint",
-			Name:        endpoint,
+			Rule:        "api-trailing-slash",
+			Name:        registered,
 			Expected:    "no trailing slash",
 			Description: "API endpoints should not have trailing slashes",
 		})
@@ -738,6 +807,7 @@ func validateAPIEndpoint(file string, line int, method string, endpoint string,
 				Line:        line,
 				Column:      1,
 				Type:        "API version",
+				Rule:        "api-version",
 				Name:        endpoint,
 				Expected:    "/v{number}/",
 				Description: "API version should follow the format /v{number}/",
@@ -745,37 +815,44 @@ func validateAPIEndpoint(file string, line int, method string, endpoint string,
 		}
 	}
 
-	// Check for proper resource naming in RESTful endpoints
-	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
-	for i, part := range parts {
-		// Skip version parts like "v1"
-		if regexp.MustCompile(`^v\d+$`).MatchString(part) {
+	// Resource names should be plural for collections. A collection is a segment followed by a
+	// path parameter naming one of its items, e.g. users in /users/:id; the other segments may be
+	// namespaces or singletons such as /admin/config.
+	for i, part := range parts[:len(parts)-1] {
+		if part == "" || routeParam.MatchString(part) || !routeParam.MatchString(parts[i+1]) {
 			continue
 		}
-
-		// Resource names should be plural for collections
-		if i == len(parts)-1 || (i < len(parts)-1 && !regexp.MustCompile(`^\d+$`).MatchString(parts[i+1])) {
-			if !strings.HasSuffix(part, "s") && part != "health" && part != "status" && part != "login" && part != "logout" {
-				validator.AddWarning(&ValidationResult{
-					File:        file,
-					Line:        line,
-					Column:      1,
-					Type:        "API resource",
-					Name:        part,
-					Expected:    "plural form",
-					Description: "RESTful resource names should use plural form for collections",
-				})
-			}
+		if !isPluralResource(part) {
+			validator.AddWarning(&ValidationResult{
+				File:        file,
+				Line:        line,
+				Column:      1,
+				Type:        "API resource",
+				Rule:        "api-resource",
+				Name:        part,
+				Expected:    "plural form",
+				Description: "RESTful resource names should use plural form for collections",
+			})
 		}
 	}
 }
 
+// irregularPlurals are the plural resource names not ending in s
+var irregularPlurals = map[string]bool{"people": true, "children": true, "men": true, "women": true, "data": true, "media": true, "criteria": true}
+
+// isPluralResource reports whether the last word of a resource name is plural, e.g.
+// order-items
+func isPluralResource(name string) bool {
+	word := name[strings.LastIndex(name, "-")+1:]
+	return strings.HasSuffix(word, "s") || irregularPlurals[word]
+}
+
 func validateDatabaseNaming(sqlDir string, validator *NamingValidator, summary *NamingValidationSummary) {
-	fmt.Println("Checking database naming conventions...")
+	fmt.Fprintln(console, "Checking database naming conventions...")
 
 	// Check if the SQL directory exists
 	if _, err := os.Stat(sqlDir); os.IsNotExist(err) {
-		fmt.Printf("SQL directory %s does not exist, skipping database naming checks\n", sqlDir)
+		fmt.Fprintf(console, "SQL directory %s does not exist, skipping database naming checks\n", sqlDir)
 		return
 	}
 
@@ -785,11 +862,11 @@ func validateDatabaseNaming(sqlDir string, validator *NamingValidator, summary *
 			return err
 		}
 
-		// Only check SQL files
-		if !info.IsDir() && (strings.HasSuffix(path, ".sql") || strings.HasSuffix(path, ".up.sql")) {
+		// Only check SQL files of the directories in scope
+		if !info.IsDir() && (strings.HasSuffix(path, ".sql") || strings.HasSuffix(path, ".up.sql")) && scope.includes(path) {
 			content, err := os.ReadFile(path)
 			if err != nil {
-				fmt.Printf("Error reading file %s: %v\n", path, err)
+				fmt.Fprintf(console, "Error reading file %s: %v\n", path, err)
 				return nil
 			}
 
@@ -829,8 +906,11 @@ func validateDatabaseNaming(sqlDir string, validator *NamingValidator, summary *
 	})
 
 	if err != nil {
-		fmt.Printf("Error walking SQL directory: %v\n", err)
+		fmt.Fprintf(console, "Error walking SQL directory: %v\n", err)
 	}
+
+	// Check the statements of the migrations beyond their names
+	lintMigrations(sqlDir, validator, summary)
 }
 
 func validateTableName(file string, line int, tableName string, validator *NamingValidator) {
@@ -850,6 +930,7 @@ func validateTableName(file string, line int, tableName string, validator *Namin
 				Line:        line,
 				Column:      1,
 				Type:        "Table name",
+				Rule:        "table-name",
 				Name:        tableName,
 				Expected:    "snake_case and plural",
 				Description: "Table names should use snake_case and be plural",
@@ -866,6 +947,7 @@ func validateColumnName(file string, line int, columnName string, columnType str
 			Line:        line,
 			Column:      1,
 			Type:        "Column name",
+			Rule:        "column-name",
 			Name:        columnName,
 			Expected:    "snake_case",
 			Description: "Column names should use snake_case",
@@ -880,6 +962,7 @@ func validateColumnName(file string, line int, columnName string, columnType str
 				Line:        line,
 				Column:      1,
 				Type:        "ID column",
+				Rule:        "id-column",
 				Name:        columnName,
 				Expected:    "suffix with _id",
 				Description: "Foreign key columns should end with _id",
@@ -904,6 +987,7 @@ func validateColumnName(file string, line int, columnName string, columnType str
 				Line:        line,
 				Column:      1,
 				Type:        "Boolean column",
+				Rule:        "boolean-column",
 				Name:        columnName,
 				Expected:    "prefix with is_, has_, can_, etc.",
 				Description: "Boolean columns should have a descriptive prefix like is_, has_, can_",
@@ -928,6 +1012,7 @@ func validateColumnName(file string, line int, columnName string, columnType str
 				Line:        line,
 				Column:      1,
 				Type:        "Timestamp column",
+				Rule:        "timestamp-column",
 				Name:        columnName,
 				Expected:    "suffix with _at, _date, or _time",
 				Description: "Timestamp columns should have a descriptive suffix like _at, _date, or _time",
@@ -937,11 +1022,11 @@ func validateColumnName(file string, line int, columnName string, columnType str
 }
 
 func validateEntSchemas(entDir string, validator *NamingValidator, summary *NamingValidationSummary) {
-	fmt.Println("Checking Ent schema naming conventions...")
+	fmt.Fprintln(console, "Checking Ent schema naming conventions...")
 
 	// Check if the Ent schema directory exists
 	if _, err := os.Stat(entDir); os.IsNotExist(err) {
-		fmt.Printf("Ent schema directory %s does not exist, skipping Ent schema naming checks\n", entDir)
+		fmt.Fprintf(console, "Ent schema directory %s does not exist, skipping Ent schema naming checks\n", entDir)
 		return
 	}
 
@@ -951,13 +1036,12 @@ func validateEntSchemas(entDir string, validator *NamingValidator, summary *Nami
 			return err
 		}
 
-		// Only check Go files
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+		// Only check Go files of the packages in scope
+		if !info.IsDir() && strings.HasSuffix(path, ".go") && scope.includes(path) {
 			// Parse the Go file
-			fset := token.NewFileSet()
-			node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			fset, node, err := parsedFiles.parse(path, parser.ParseComments)
 			if err != nil {
-				fmt.Printf("Error parsing file %s: %v\n", path, err)
+				fmt.Fprintf(console, "Error parsing file %s: %v\n", path, err)
 				return nil
 			}
 
@@ -996,7 +1080,7 @@ func validateEntSchemas(entDir string, validator *NamingValidator, summary *Nami
 	})
 
 	if err != nil {
-		fmt.Printf("Error walking Ent schema directory: %v\n", err)
+		fmt.Fprintf(console, "Error walking Ent schema directory: %v\n", err)
 	}
 }
 
@@ -1011,6 +1095,7 @@ func validateEntSchemaName(fset *token.FileSet, typeSpec *ast.TypeSpec, path str
 			Line:        pos.Line,
 			Column:      pos.Column,
 			Type:        "Ent schema",
+			Rule:        "ent-schema",
 			Name:        schemaName,
 			Expected:    "PascalCase",
 			Description: "Ent schema names should use PascalCase",
@@ -1025,6 +1110,7 @@ func validateEntSchemaName(fset *token.FileSet, typeSpec *ast.TypeSpec, path str
 			Line:        pos.Line,
 			Column:      pos.Column,
 			Type:        "Ent schema",
+			Rule:        "ent-schema-singular",
 			Name:        schemaName,
 			Expected:    "singular form",
 			Description: "Ent schema names should be singular, not plural",
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAPIEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint   string
+		registered string
+		// want are the findings as rule: name
+		want []string
+	}{
+		{"/api/v1/users", "/users", nil},
+		{"/api/v1/users/:id", "/:id", nil},
+		{"/api/v1/user/:id", "/user/:id", []string{"api-resource: user"}},
+		{"/api/v1/people/:id/order-items/:item", "/:id/order-items/:item", nil},
+		{"/api/v1/people/:id/order-item/:item", "/:id/order-item/:item", []string{"api-resource: order-item"}},
+		{"/admin/config", "/admin/config", nil},
+		{"/ready", "/ready", nil},
+		{"/files/*", "/files/*", nil},
+		{"/api/v1/Users", "/Users", []string{"api-endpoint: /api/v1/Users"}},
+		{"/api/v1/order_items", "/order_items", []string{"api-endpoint: /api/v1/order_items"}},
+		{"/api/users", "/users/", []string{"api-trailing-slash: /users/"}},
+		{"/api/version1/users", "/users", []string{"api-version: /api/version1/users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			validator := NewNamingValidator()
+			validateAPIEndpoint("handler.go", 3, "GET", tt.endpoint, tt.registered, validator)
+
+			var findings []string
+			for _, result := range append(validator.results.Errors, validator.results.Warnings...) {
+				findings = append(findings, result.Rule+": "+result.Name)
+			}
+			assert.Equal(t, tt.want, findings)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package validator
+
+import (
+	"fmt"
+	"go/parser"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
+	"golang.org/x/tools/go/packages"
+)
+
+// projectPackage is a package of one of the Go modules under the validated directory
+type projectPackage struct {
+	// Path is the import path of the package
+	Path string
+	// Module is the path of the module of the package
+	Module string
+	// Rel is the import path relative to the module, "." for the root package of the module
+	Rel string
+	// Files are the Go files of the package, test files and files excluded by build constraints
+	// included
+	Files []projectFile
+}
+
+// projectFile is a Go file of a package, with its imports
+type projectFile struct {
+	Path    string
+	Imports []projectImport
+}
+
+// projectImport is an import of a file, resolved to the import path of the imported package
+type projectImport struct {
+	Path string
+	Line int
+}
+
+// loadProjectPackages loads the packages of the Go modules under rootDir, with the import paths
+// go/packages resolves their imports to. Nested modules, such as examples with a go.mod of their
+// own, are loaded as separate modules. When rootDir is inside a module, the packages of the module
+// under rootDir are loaded. Files excluded by build constraints, e.g. integration tests, are
+// read too, so the imports of every build are checked.
+func loadProjectPackages(rootDir string) ([]*projectPackage, error) {
+	moduleDirs, err := findModuleDirs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*projectPackage)
+	seenFiles := make(map[string]bool)
+	for _, dir := range moduleDirs {
+		config := &packages.Config{
+			Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedModule,
+			Dir:   dir,
+			Tests: true,
+		}
+		patterns := []string{"./..."}
+		if scope != nil {
+			patterns = nil
+			for _, pkgDir := range scope.packagesUnder(dir) {
+				if rel, err := filepath.Rel(dir, pkgDir); err == nil && !inNestedModule(pkgDir, dir) {
+					patterns = append(patterns, "./"+filepath.ToSlash(rel))
+				}
+			}
+			if len(patterns) == 0 {
+				continue
+			}
+		}
+
+		flags, cleanup, err := scratchModFlags(dir)
+		if err != nil {
+			return nil, err
+		}
+		config.BuildFlags = flags
+		pkgs, err := packages.Load(config, patterns...)
+		cleanup()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the packages of %s: %w", dir, err)
+		}
+
+		for _, pkg := range pkgs {
+			// Skip the generated test mains and the packages outside of modules
+			if strings.HasSuffix(pkg.ID, ".test") || pkg.Module == nil {
+				continue
+			}
+
+			// External test packages, e.g. product_test, belong to the package they test
+			importPath := pkg.PkgPath
+			if strings.HasSuffix(pkg.Name, "_test") {
+				importPath = strings.TrimSuffix(importPath, "_test")
+			}
+			project, ok := byPath[importPath]
+			if !ok {
+				project = &projectPackage{
+					Path:   importPath,
+					Module: pkg.Module.Path,
+					Rel:    moduleRelativePath(importPath, pkg.Module.Path),
+				}
+				byPath[importPath] = project
+			}
+
+			// A package and its test variant share files, which are read once
+			for _, path := range append(pkg.GoFiles, constrainedFiles(pkg)...) {
+				if seenFiles[path] {
+					continue
+				}
+				seenFiles[path] = true
+
+				file, err := parseFileImports(path, pkg)
+				if err != nil {
+					return nil, err
+				}
+				project.Files = append(project.Files, file)
+			}
+		}
+	}
+
+	projectPackages := make([]*projectPackage, 0, len(byPath))
+	for _, pkg := range byPath {
+		sort.Slice(pkg.Files, func(i, j int) bool { return pkg.Files[i].Path < pkg.Files[j].Path })
+		projectPackages = append(projectPackages, pkg)
+	}
+	sort.Slice(projectPackages, func(i, j int) bool { return projectPackages[i].Path < projectPackages[j].Path })
+	return projectPackages, nil
+}
+
+// constrainedFiles returns the Go files of a package excluded by build constraints
+func constrainedFiles(pkg *packages.Package) []string {
+	var files []string
+	for _, path := range pkg.IgnoredFiles {
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// parseFileImports reads the imports of a file of a package, with their lines
+func parseFileImports(path string, pkg *packages.Package) (projectFile, error) {
+	fset, node, err := parsedFiles.parse(path, parser.ImportsOnly)
+	if err != nil {
+		return projectFile{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	file := projectFile{Path: path}
+	for _, imp := range node.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || importPath == "C" {
+			continue
+		}
+
+		// Test variants of packages have IDs like "<path> [<path>.test]"
+		if resolved, ok := pkg.Imports[importPath]; ok {
+			importPath, _, _ = strings.Cut(resolved.ID, " ")
+		}
+		file.Imports = append(file.Imports, projectImport{Path: importPath, Line: fset.Position(imp.Pos()).Line})
+	}
+	return file, nil
+}
+
+// scratchModFlags returns the build flags resolving the packages of a module against a scratch
+// copy of its go.mod and go.sum, so a stale go.mod is completed without being written to. Vendored
+// modules are resolved from their vendor directory.
+func scratchModFlags(dir string) ([]string, func(), error) {
+	// A directory inside a module uses the go.mod of the module
+	moduleDir, _, err := apiscan.FindModule(dir)
+	if err != nil {
+		return nil, func() {}, nil
+	}
+	goMod, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(filepath.Join(moduleDir, "vendor")); err == nil {
+		return nil, func() {}, nil
+	}
+
+	scratch, err := os.MkdirTemp("", "axiomod-validator-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(scratch) }
+	if err := os.WriteFile(filepath.Join(scratch, "go.mod"), goMod, 0644); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if goSum, err := os.ReadFile(filepath.Join(moduleDir, "go.sum")); err == nil {
+		if err := os.WriteFile(filepath.Join(scratch, "go.sum"), goSum, 0644); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+	return []string{"-mod=mod", "-modfile=" + filepath.Join(scratch, "go.mod")}, cleanup, nil
+}
+
+// findModuleDirs returns the directories of the Go modules under rootDir, or rootDir itself when
+// it has no go.mod and is inside a module. Vendored code, testdata and hidden directories are
+// skipped.
+func findModuleDirs(rootDir string) ([]string, error) {
+	var dirs []string
+	if _, err := os.Stat(filepath.Join(rootDir, "go.mod")); err != nil {
+		dirs = append(dirs, rootDir)
+	}
+
+	err := filepath.WalkDir(rootDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error accessing %s: %w", path, err)
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != rootDir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" || name == "node_modules") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// inNestedModule reports whether a directory under the directory of a module belongs to a module
+// nested in it, which is loaded on its own
+func inNestedModule(pkgDir, moduleDir string) bool {
+	for dir := pkgDir; dir != moduleDir && strings.HasPrefix(dir, moduleDir); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleImport returns the imported package when it belongs to the module of the importing
+// package, nil otherwise. Packages of the module outside the validated directory are not loaded,
+// and are identified by their import path.
+func moduleImport(byPath map[string]*projectPackage, pkg *projectPackage, importPath string) *projectPackage {
+	if target, ok := byPath[importPath]; ok {
+		if target.Module != pkg.Module {
+			return nil
+		}
+		return target
+	}
+	if !strings.HasPrefix(importPath+"/", pkg.Module+"/") {
+		return nil
+	}
+	return &projectPackage{Path: importPath, Module: pkg.Module, Rel: moduleRelativePath(importPath, pkg.Module)}
+}
+
+// moduleRelativePath returns an import path relative to the path of its module
+func moduleRelativePath(importPath, modulePath string) string {
+	if importPath == modulePath {
+		return "."
+	}
+	return strings.TrimPrefix(importPath, modulePath+"/")
+}
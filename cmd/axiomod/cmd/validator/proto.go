@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// protoCmd represents the validator proto command
+var protoCmd = &cobra.Command{
+	Use:   "proto [dir]",
+	Short: "Check proto files for changes breaking their clients",
+	Long: `Compare the .proto files under a directory (the current directory by default) with those of
+a git ref, and fail on the changes breaking the clients of their messages and services.
+
+Breaking changes are removed messages, enums, services and RPCs, removed or renumbered fields,
+changed field types, labels and oneofs, renamed fields and enum values, which changes their JSON
+names, and reserved numbers or names that are used again. A field or an enum value may be removed
+once its number is reserved. Files under vendor, third_party and hidden directories are skipped.
+
+Example:
+  axiomod validator proto --against=main
+  axiomod validator proto ./proto --against=v1.4.0
+  axiomod validator proto --against=origin/main --format=sarif --output=proto.sarif
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		against, _ := cmd.Flags().GetString("against")
+		format := reportFormat(cmd)
+		fmt.Fprintf(console, "Checking proto compatibility against %s...\n", against)
+
+		report, err := ProtoReport(dir, against)
+		if err != nil {
+			fmt.Fprintf(console, "Proto validation error: %v\n", err)
+			os.Exit(1)
+		}
+		emitReport(cmd, format, report)
+	},
+}
+
+// NewProtoCmd returns the validator proto command.
+func NewProtoCmd() *cobra.Command {
+	return protoCmd
+}
+
+func init() {
+	protoCmd.Flags().String("against", "main", "Git ref the proto files are compared with")
+	addReportFlags(protoCmd)
+
+	// Add subcommands to the parent validatorCmd
+	validatorCmd.AddCommand(protoCmd)
+}
@@ -0,0 +1,423 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/protoscan"
+)
+
+// protoValidator is the validator of the compatibility of the proto files
+const protoValidator = "proto"
+
+// protoVersion holds the proto files of a version of the repository, by path relative to its root
+type protoVersion struct {
+	files    map[string]*protoscan.File
+	messages map[string]*protoscan.Message
+	enums    map[string]*protoscan.Enum
+	services map[string]*protoscan.Service
+	// fileOf holds the path of the file declaring each message, enum and service
+	fileOf map[string]string
+}
+
+// newProtoVersion indexes the declarations of parsed proto files, after resolving their types
+func newProtoVersion(files map[string]*protoscan.File) *protoVersion {
+	v := &protoVersion{
+		files:    files,
+		messages: make(map[string]*protoscan.Message),
+		enums:    make(map[string]*protoscan.Enum),
+		services: make(map[string]*protoscan.Service),
+		fileOf:   make(map[string]string),
+	}
+	var all []*protoscan.File
+	for _, file := range files {
+		all = append(all, file)
+	}
+	protoscan.Resolve(all)
+
+	for path, file := range files {
+		protoscan.WalkMessages(file, func(message *protoscan.Message) {
+			v.messages[message.FullName] = message
+			v.fileOf[message.FullName] = path
+			for _, enum := range message.Enums {
+				v.enums[enum.FullName] = enum
+				v.fileOf[enum.FullName] = path
+			}
+		})
+		for _, enum := range file.Enums {
+			v.enums[enum.FullName] = enum
+			v.fileOf[enum.FullName] = path
+		}
+		for _, service := range file.Services {
+			v.services[service.FullName] = service
+			v.fileOf[service.FullName] = path
+		}
+	}
+	return v
+}
+
+// protoComparison compares the proto files of a git ref with those of the working tree
+type protoComparison struct {
+	report   *Report
+	root     string
+	old, new *protoVersion
+}
+
+// ProtoReport compares the .proto files under dir with those of a git ref, and reports the
+// changes breaking the clients of their messages and services: removed or renumbered fields,
+// changed types, removed enum values, services and RPCs. Fields and enum values may be removed
+// once their number is reserved.
+func ProtoReport(dir string, against string) (*Report, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git command not found: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	top, err := gitOutput(absDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	root := strings.TrimSpace(top)
+	if _, err := gitOutput(root, "rev-parse", "--verify", "--quiet", against+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q", against)
+	}
+	prefix, err := filepath.Rel(root, absDir)
+	if err != nil {
+		return nil, err
+	}
+	prefix = filepath.ToSlash(prefix)
+
+	oldFiles, err := refProtoFiles(root, against, prefix)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := workingProtoFiles(root, absDir)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(console, "Comparing %d proto files with the %d files of %s\n", len(newFiles), len(oldFiles), against)
+
+	c := &protoComparison{
+		report: &Report{Validator: protoValidator},
+		root:   root,
+		old:    newProtoVersion(oldFiles),
+		new:    newProtoVersion(newFiles),
+	}
+	c.compare()
+
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, err
+	}
+	findings := c.report.Findings
+	c.report.Findings = nil
+	for _, finding := range findings {
+		finding.Level = policy.level(finding.RuleID, finding.Level, finding.File, finding.Line)
+		if finding.Level != "" {
+			c.report.Findings = append(c.report.Findings, finding)
+		}
+	}
+	return c.report, nil
+}
+
+// gitOutput runs a git command in a directory and returns its output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// skippedProtoDir reports whether the proto files of a directory belong to others, such as
+// vendored dependencies
+func skippedProtoDir(name string) bool {
+	return name == "vendor" || name == "third_party" || name == "node_modules" || strings.HasPrefix(name, ".") && name != "."
+}
+
+// refProtoFiles parses the .proto files under a directory of the repository at a git ref
+func refProtoFiles(root, ref, prefix string) (map[string]*protoscan.File, error) {
+	args := []string{"ls-tree", "-r", "--name-only", ref}
+	if prefix != "." {
+		args = append(args, "--", prefix)
+	}
+	list, err := gitOutput(root, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of %s: %w", ref, err)
+	}
+
+	files := make(map[string]*protoscan.File)
+	for _, name := range strings.Split(list, "\n") {
+		if !strings.HasSuffix(name, ".proto") || protoPathSkipped(name) {
+			continue
+		}
+		src, err := gitOutput(root, "show", ref+":"+name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", name, ref, err)
+		}
+		file, err := protoscan.Parse(name, []byte(src))
+		if err != nil {
+			// The ref cannot be compared with, but the working tree is not at fault
+			fmt.Fprintf(console, "Skipping %s at %s: %v\n", name, ref, err)
+			continue
+		}
+		files[name] = file
+	}
+	return files, nil
+}
+
+// protoPathSkipped reports whether a slash-separated path is in a skipped directory
+func protoPathSkipped(name string) bool {
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if skippedProtoDir(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// workingProtoFiles parses the .proto files under a directory of the working tree
+func workingProtoFiles(root, dir string) (map[string]*protoscan.File, error) {
+	files := make(map[string]*protoscan.File)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && skippedProtoDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".proto") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		file, err := protoscan.Parse(rel, src)
+		if err != nil {
+			return err
+		}
+		files[rel] = file
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the proto files: %w", err)
+	}
+	return files, nil
+}
+
+// add adds a finding of a category at a line of a file of the working tree, or of the ref when
+// the file was deleted
+func (c *protoComparison) add(category, file string, line int, format string, args ...interface{}) {
+	c.report.Add(category, LevelError, fmt.Sprintf(format, args...), filepath.Join(c.root, filepath.FromSlash(file)), line, 0)
+}
+
+// removedAt returns where to report the removal of a declaration: its parent message in the
+// working tree, the package statement of its file, or its former location when the file was
+// deleted. It returns false when its parent message was removed too, which is reported instead.
+func (c *protoComparison) removedAt(fullName string, oldLine int) (string, int, bool) {
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		parent := fullName[:i]
+		if message, ok := c.new.messages[parent]; ok {
+			return c.new.fileOf[parent], message.Line, true
+		}
+		if _, ok := c.old.messages[parent]; ok {
+			return "", 0, false
+		}
+	}
+	file := c.old.fileOf[fullName]
+	if newFile, ok := c.new.files[file]; ok {
+		return file, max(newFile.PackageLine, 1), true
+	}
+	return file, oldLine, true
+}
+
+// compare reports the breaking changes between the versions
+func (c *protoComparison) compare() {
+	for _, path := range sortedProtoKeys(c.old.files) {
+		oldFile, newFile := c.old.files[path], c.new.files[path]
+		if newFile != nil && newFile.Package != oldFile.Package {
+			c.add("package-changed", path, max(newFile.PackageLine, 1), "package of %s changed from %q to %q, which renames all of its declarations", path, oldFile.Package, newFile.Package)
+		}
+	}
+
+	for _, name := range sortedProtoKeys(c.old.messages) {
+		oldMessage := c.old.messages[name]
+		if newMessage, ok := c.new.messages[name]; ok {
+			c.compareMessage(oldMessage, newMessage)
+		} else if file, line, ok := c.removedAt(name, oldMessage.Line); ok {
+			c.add("message-removed", file, line, "message %s was removed", name)
+		}
+	}
+
+	for _, name := range sortedProtoKeys(c.old.enums) {
+		oldEnum := c.old.enums[name]
+		if newEnum, ok := c.new.enums[name]; ok {
+			c.compareEnum(oldEnum, newEnum)
+		} else if file, line, ok := c.removedAt(name, oldEnum.Line); ok {
+			c.add("enum-removed", file, line, "enum %s was removed", name)
+		}
+	}
+
+	for _, name := range sortedProtoKeys(c.old.services) {
+		oldService := c.old.services[name]
+		if newService, ok := c.new.services[name]; ok {
+			c.compareService(oldService, newService)
+		} else if file, line, ok := c.removedAt(name, oldService.Line); ok {
+			c.add("service-removed", file, line, "service %s was removed", name)
+		}
+	}
+}
+
+// compareMessage reports the breaking changes of the fields of a message
+func (c *protoComparison) compareMessage(oldMessage, newMessage *protoscan.Message) {
+	file := c.new.fileOf[newMessage.FullName]
+	byNumber := make(map[int]*protoscan.Field)
+	byName := make(map[string]*protoscan.Field)
+	for _, field := range newMessage.Fields {
+		byNumber[field.Number] = field
+		byName[field.Name] = field
+
+		if oldMessage.Reserved.HasNumber(field.Number) || oldMessage.Reserved.HasName(field.Name) {
+			c.add("reserved-reused", file, field.Line, "field %s.%s = %d reuses a number or name reserved by %s", newMessage.Name, field.Name, field.Number, newMessage.Name)
+		}
+	}
+
+	for _, oldField := range oldMessage.Fields {
+		field, ok := byNumber[oldField.Number]
+		if !ok {
+			switch renumbered, ok := byName[oldField.Name]; {
+			case ok:
+				c.add("field-number-changed", file, renumbered.Line, "field %s.%s changed from number %d to %d", newMessage.Name, oldField.Name, oldField.Number, renumbered.Number)
+			case !newMessage.Reserved.HasNumber(oldField.Number):
+				c.add("field-removed", file, newMessage.Line, "field %s.%s = %d was removed; reserve its number to remove it", newMessage.Name, oldField.Name, oldField.Number)
+			}
+			continue
+		}
+
+		if field.Name != oldField.Name {
+			c.add("field-renamed", file, field.Line, "field %s = %d of %s was renamed to %s, which changes its JSON name", oldField.Name, oldField.Number, newMessage.Name, field.Name)
+		}
+		if field.Type != oldField.Type {
+			c.add("field-type-changed", file, field.Line, "field %s.%s changed type from %s to %s", newMessage.Name, field.Name, oldField.Type, field.Type)
+		}
+		if field.Label != oldField.Label {
+			c.add("field-label-changed", file, field.Line, "field %s.%s changed from %s to %s", newMessage.Name, field.Name, protoLabel(oldField.Label), protoLabel(field.Label))
+		}
+		if field.Oneof != oldField.Oneof {
+			c.add("field-oneof-changed", file, field.Line, "field %s.%s moved from %s to %s", newMessage.Name, field.Name, protoOneof(oldField.Oneof), protoOneof(field.Oneof))
+		}
+	}
+}
+
+// compareEnum reports the breaking changes of the values of an enum
+func (c *protoComparison) compareEnum(oldEnum, newEnum *protoscan.Enum) {
+	file := c.new.fileOf[newEnum.FullName]
+	byNumber := make(map[int][]*protoscan.EnumValue)
+	for _, value := range newEnum.Values {
+		byNumber[value.Number] = append(byNumber[value.Number], value)
+
+		if oldEnum.Reserved.HasNumber(value.Number) || oldEnum.Reserved.HasName(value.Name) {
+			c.add("reserved-reused", file, value.Line, "value %s = %d of %s reuses a number or name reserved by %s", value.Name, value.Number, newEnum.Name, newEnum.Name)
+		}
+	}
+
+	for _, oldValue := range oldEnum.Values {
+		values, ok := byNumber[oldValue.Number]
+		switch {
+		case !ok && !newEnum.Reserved.HasNumber(oldValue.Number):
+			c.add("enum-value-removed", file, newEnum.Line, "value %s = %d of %s was removed; reserve its number to remove it", oldValue.Name, oldValue.Number, newEnum.Name)
+		case ok && !hasEnumValue(values, oldValue.Name):
+			c.add("enum-value-renamed", file, values[0].Line, "value %s = %d of %s was renamed to %s, which changes its JSON name", oldValue.Name, oldValue.Number, newEnum.Name, values[0].Name)
+		}
+	}
+}
+
+// compareService reports the breaking changes of the RPCs of a service
+func (c *protoComparison) compareService(oldService, newService *protoscan.Service) {
+	file := c.new.fileOf[newService.FullName]
+	methods := make(map[string]*protoscan.Method)
+	for _, method := range newService.Methods {
+		methods[method.Name] = method
+	}
+
+	for _, oldMethod := range oldService.Methods {
+		method, ok := methods[oldMethod.Name]
+		if !ok {
+			c.add("rpc-removed", file, newService.Line, "rpc %s.%s was removed", newService.Name, oldMethod.Name)
+			continue
+		}
+		if method.Input != oldMethod.Input || method.Output != oldMethod.Output {
+			c.add("rpc-type-changed", file, method.Line, "rpc %s.%s changed from (%s) returns (%s) to (%s) returns (%s)", newService.Name, method.Name,
+				oldMethod.Input, oldMethod.Output, method.Input, method.Output)
+		}
+		if method.ClientStreaming != oldMethod.ClientStreaming || method.ServerStreaming != oldMethod.ServerStreaming {
+			c.add("rpc-streaming-changed", file, method.Line, "rpc %s.%s changed from %s to %s", newService.Name, method.Name,
+				protoStreaming(oldMethod), protoStreaming(method))
+		}
+	}
+}
+
+// hasEnumValue reports whether one of the enum values is named name
+func hasEnumValue(values []*protoscan.EnumValue, name string) bool {
+	for _, value := range values {
+		if value.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// protoLabel describes a field label in messages
+func protoLabel(label string) string {
+	if label == protoscan.LabelNone {
+		return "a singular field"
+	}
+	return "a " + label + " field"
+}
+
+// protoOneof describes the oneof of a field in messages
+func protoOneof(oneof string) string {
+	if oneof == "" {
+		return "no oneof"
+	}
+	return "oneof " + oneof
+}
+
+// protoStreaming describes the streaming of an RPC in messages
+func protoStreaming(method *protoscan.Method) string {
+	switch {
+	case method.ClientStreaming && method.ServerStreaming:
+		return "bidirectional streaming"
+	case method.ClientStreaming:
+		return "client streaming"
+	case method.ServerStreaming:
+		return "server streaming"
+	}
+	return "unary"
+}
+
+// sortedProtoKeys returns the keys of a map of declarations, sorted
+func sortedProtoKeys[T any](declarations map[string]T) []string {
+	keys := make([]string, 0, len(declarations))
+	for key := range declarations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
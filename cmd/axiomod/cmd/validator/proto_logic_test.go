@@ -0,0 +1,153 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/protoscan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userProto = `syntax = "proto3";
+
+package user.v1;
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_BANNED = 2;
+}
+
+message User {
+  reserved 9;
+  string id = 1;
+  string name = 2;
+  int64 age = 3;
+  Status status = 4;
+}
+
+message GetUserRequest {
+  string id = 1;
+}
+
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc DeleteUser(GetUserRequest) returns (User);
+}
+`
+
+// compareProto compares two versions of a proto file and returns the findings as rule: message
+func compareProto(t *testing.T, oldSource, newSource string) []string {
+	t.Helper()
+	const path = "proto/user/v1/user.proto"
+	oldFile, err := protoscan.Parse(path, []byte(oldSource))
+	require.NoError(t, err)
+	newFile, err := protoscan.Parse(path, []byte(newSource))
+	require.NoError(t, err)
+
+	c := &protoComparison{
+		report: &Report{Validator: protoValidator},
+		root:   t.TempDir(),
+		old:    newProtoVersion(map[string]*protoscan.File{path: oldFile}),
+		new:    newProtoVersion(map[string]*protoscan.File{path: newFile}),
+	}
+	c.compare()
+
+	var findings []string
+	for _, finding := range c.report.Findings {
+		findings = append(findings, finding.RuleID+": "+finding.Message)
+	}
+	return findings
+}
+
+func TestProtoComparison(t *testing.T) {
+	tests := []struct {
+		name string
+		// changes are the replacements making the new version of userProto
+		changes [][2]string
+		want    []string
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name:    "field added",
+			changes: [][2]string{{"Status status = 4;", "Status status = 4;\n  string email = 5;"}},
+		},
+		{
+			name:    "field type changed",
+			changes: [][2]string{{"int64 age = 3;", "int32 age = 3;"}},
+			want:    []string{"proto/field-type-changed: field User.age changed type from int64 to int32"},
+		},
+		{
+			name:    "field label changed",
+			changes: [][2]string{{"int64 age = 3;", "repeated int64 age = 3;"}},
+			want:    []string{"proto/field-label-changed: field User.age changed from a singular field to a repeated field"},
+		},
+		{
+			name:    "field removed",
+			changes: [][2]string{{"  int64 age = 3;\n", ""}},
+			want:    []string{"proto/field-removed: field User.age = 3 was removed; reserve its number to remove it"},
+		},
+		{
+			name:    "field reserved then removed",
+			changes: [][2]string{{"  int64 age = 3;\n", "  reserved 3;\n  reserved \"age\";\n"}},
+		},
+		{
+			name:    "field renumbered",
+			changes: [][2]string{{"string name = 2;", "string name = 5;"}},
+			want:    []string{"proto/field-number-changed: field User.name changed from number 2 to 5"},
+		},
+		{
+			name:    "field renamed",
+			changes: [][2]string{{"string name = 2;", "string full_name = 2;"}},
+			want:    []string{"proto/field-renamed: field name = 2 of User was renamed to full_name, which changes its JSON name"},
+		},
+		{
+			name:    "reserved number reused",
+			changes: [][2]string{{"Status status = 4;", "Status status = 4;\n  string email = 9;"}},
+			want:    []string{"proto/reserved-reused: field User.email = 9 reuses a number or name reserved by User"},
+		},
+		{
+			name:    "enum value removed",
+			changes: [][2]string{{"  STATUS_BANNED = 2;\n", ""}},
+			want:    []string{"proto/enum-value-removed: value STATUS_BANNED = 2 of Status was removed; reserve its number to remove it"},
+		},
+		{
+			name:    "enum value reserved then removed",
+			changes: [][2]string{{"  STATUS_BANNED = 2;\n", "  reserved 2;\n"}},
+		},
+		{
+			name:    "enum value renamed",
+			changes: [][2]string{{"STATUS_BANNED = 2;", "STATUS_BLOCKED = 2;"}},
+			want:    []string{"proto/enum-value-renamed: value STATUS_BANNED = 2 of Status was renamed to STATUS_BLOCKED, which changes its JSON name"},
+		},
+		{
+			name:    "rpc removed",
+			changes: [][2]string{{"  rpc DeleteUser(GetUserRequest) returns (User);\n", ""}},
+			want:    []string{"proto/rpc-removed: rpc UserService.DeleteUser was removed"},
+		},
+		{
+			name:    "rpc streaming changed",
+			changes: [][2]string{{"returns (User);\n  rpc DeleteUser", "returns (stream User);\n  rpc DeleteUser"}},
+			want:    []string{"proto/rpc-streaming-changed: rpc UserService.GetUser changed from unary to server streaming"},
+		},
+		{
+			name:    "rpc request type changed",
+			changes: [][2]string{{"message GetUserRequest {\n  string id = 1;\n}\n", "message GetUserRequest {\n  string id = 1;\n}\n\nmessage Empty {}\n"}, {"rpc DeleteUser(GetUserRequest)", "rpc DeleteUser(Empty)"}},
+			want:    []string{"proto/rpc-type-changed: rpc UserService.DeleteUser changed from (user.v1.GetUserRequest) returns (user.v1.User) to (user.v1.Empty) returns (user.v1.User)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newSource := userProto
+			for _, change := range tt.changes {
+				require.Contains(t, newSource, change[0])
+				newSource = strings.Replace(newSource, change[0], change[1], 1)
+			}
+			assert.Equal(t, tt.want, compareProto(t, userProto, newSource))
+		})
+	}
+}
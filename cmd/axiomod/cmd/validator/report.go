@@ -0,0 +1,576 @@
+package validator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the validators
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+	FormatJUnit = "junit"
+)
+
+// Levels of the findings
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// console receives the progress and the text reports of the validators. It is stderr when a
+// machine-readable report is written to stdout.
+var console io.Writer = os.Stdout
+
+// Rule is a check of a validator, identified by an ID that is stable across releases
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// rules lists the rules of each validator, in the order they are reported
+var rules = map[string][]Rule{
+	"architecture": {
+		{ID: "architecture/layer-dependency", Description: "Packages only import the layers their rules allow"},
+		{ID: "architecture/cross-domain-dependency", Description: "Domains do not import other domains unless allowed"},
+		{ID: "architecture/domain-internal-structure", Description: "Imports within a domain follow its internal structure"},
+	},
+	"config": {
+		{ID: "config/unknown-key", Description: "Keys match a field of the framework configuration"},
+		{ID: "config/type-mismatch", Description: "Values convert to the type of their field"},
+		{ID: "config/missing-section", Description: "Service configurations set the app and http sections"},
+		{ID: "config/duplicate-key", Description: "Keys are not repeated with a different case"},
+		{ID: "config/unresolved-env", Description: "Referenced environment variables are set"},
+		{ID: "config/version", Description: "Configuration files are at the configuration version of the release"},
+	},
+	"proto": {
+		{ID: "proto/package-changed", Description: "Proto files keep their package"},
+		{ID: "proto/message-removed", Description: "Messages are not removed"},
+		{ID: "proto/field-removed", Description: "Fields are only removed once their number is reserved"},
+		{ID: "proto/field-number-changed", Description: "Fields keep their number"},
+		{ID: "proto/field-renamed", Description: "Fields keep their name, which is their JSON name"},
+		{ID: "proto/field-type-changed", Description: "Fields keep their type"},
+		{ID: "proto/field-label-changed", Description: "Fields stay singular, optional, repeated or maps"},
+		{ID: "proto/field-oneof-changed", Description: "Fields stay in or out of their oneof"},
+		{ID: "proto/reserved-reused", Description: "Reserved numbers and names are not used again"},
+		{ID: "proto/enum-removed", Description: "Enums are not removed"},
+		{ID: "proto/enum-value-removed", Description: "Enum values are only removed once their number is reserved"},
+		{ID: "proto/enum-value-renamed", Description: "Enum values keep their name, which is their JSON name"},
+		{ID: "proto/service-removed", Description: "Services are not removed"},
+		{ID: "proto/rpc-removed", Description: "RPCs are not removed"},
+		{ID: "proto/rpc-type-changed", Description: "RPCs keep their request and response types"},
+		{ID: "proto/rpc-streaming-changed", Description: "RPCs keep their streaming"},
+	},
+	"domain": {
+		{ID: "domain/layer-dependency", Description: "Modules only import their allowed dependencies"},
+		{ID: "domain/cross-domain-dependency", Description: "Domains do not import other domains unless allowed"},
+	},
+	"naming": {
+		{ID: "naming/package", Description: "Package names are lowercase single words"},
+		{ID: "naming/package-singular", Description: "Package names are singular"},
+		{ID: "naming/file-name", Description: "File names are snake_case"},
+		{ID: "naming/test-function", Description: "Test functions are named TestXxx"},
+		{ID: "naming/exported-function", Description: "Exported functions are PascalCase"},
+		{ID: "naming/unexported-function", Description: "Unexported functions are camelCase"},
+		{ID: "naming/exported-variable", Description: "Exported variables are PascalCase"},
+		{ID: "naming/unexported-variable", Description: "Unexported variables are camelCase"},
+		{ID: "naming/boolean-variable", Description: "Variables named like booleans are booleans"},
+		{ID: "naming/type", Description: "Types are PascalCase"},
+		{ID: "naming/interface", Description: "Single-method interfaces use the -er suffix"},
+		{ID: "naming/exported-struct-field", Description: "Exported struct fields are PascalCase"},
+		{ID: "naming/unexported-struct-field", Description: "Unexported struct fields are camelCase"},
+		{ID: "naming/api-endpoint", Description: "API endpoints are lowercase kebab-case"},
+		{ID: "naming/api-trailing-slash", Description: "API endpoints have no trailing slash"},
+		{ID: "naming/api-version", Description: "API versions follow the /v{number}/ format"},
+		{ID: "naming/api-resource", Description: "API collections are plural"},
+		{ID: "naming/table-name", Description: "Tables are snake_case and plural"},
+		{ID: "naming/column-name", Description: "Columns are snake_case"},
+		{ID: "naming/id-column", Description: "Foreign key columns end with _id"},
+		{ID: "naming/boolean-column", Description: "Boolean columns start with is_, has_, can_ or similar"},
+		{ID: "naming/timestamp-column", Description: "Timestamp columns end with _at, _date or _time"},
+		{ID: "naming/fk-index", Description: "Columns ending with _id are indexed"},
+		{ID: "naming/foreign-key-name", Description: "Foreign key constraints are named fk_*"},
+		{ID: "naming/concurrent-index", Description: "Indexes on existing tables are created concurrently"},
+		{ID: "naming/destructive-migration", Description: "Statements losing data are marked with an axiomod:destructive comment"},
+		{ID: "naming/mixed-case-identifier", Description: "SQL identifiers are lowercase"},
+		{ID: "naming/migration-down", Description: "Up migrations have a down migration"},
+		{ID: "naming/ent-schema", Description: "Ent schemas are PascalCase"},
+		{ID: "naming/ent-schema-singular", Description: "Ent schemas are singular"},
+	},
+}
+
+// ruleID returns the ID of a category of findings of a validator, e.g. naming/exported-function
+func ruleID(validator, category string) string {
+	return validator + "/" + strings.ReplaceAll(strings.ToLower(category), " ", "-")
+}
+
+// Finding is an issue reported by a validator
+type Finding struct {
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	// Baselined is set for errors recorded in the baseline, which do not fail the validation
+	Baselined bool `json:"baselined,omitempty"`
+}
+
+// Location returns the file:line:column of the finding
+func (f Finding) Location() string {
+	location := relativePath(f.File)
+	if f.Line > 0 {
+		location += fmt.Sprintf(":%d", f.Line)
+		if f.Column > 0 {
+			location += fmt.Sprintf(":%d", f.Column)
+		}
+	}
+	return location
+}
+
+// Report holds the findings of a validator
+type Report struct {
+	Validator string
+	Findings  []Finding
+	// baselined is set once a baseline has been applied to the findings
+	baselined bool
+}
+
+// Add adds a finding to the report
+func (r *Report) Add(category, level, message, file string, line, column int) {
+	r.Findings = append(r.Findings, Finding{
+		RuleID:  ruleID(r.Validator, category),
+		Level:   level,
+		Message: message,
+		File:    file,
+		Line:    line,
+		Column:  column,
+	})
+}
+
+// Count returns the number of findings of a level, except the baselined ones
+func (r *Report) Count(level string) int {
+	count := 0
+	for _, f := range r.Findings {
+		if f.Level == level && !f.Baselined {
+			count++
+		}
+	}
+	return count
+}
+
+// CountBaselined returns the number of findings recorded in the baseline
+func (r *Report) CountBaselined() int {
+	count := 0
+	for _, f := range r.Findings {
+		if f.Baselined {
+			count++
+		}
+	}
+	return count
+}
+
+// Issues returns the errors of the report that are not baselined, as file:line: message lines
+func (r *Report) Issues() []string {
+	var issues []string
+	for _, f := range r.Findings {
+		if f.Level == LevelError && !f.Baselined {
+			issues = append(issues, fmt.Sprintf("%s: %s", f.Location(), f.Message))
+		}
+	}
+	return issues
+}
+
+// sort orders the findings by file, position and rule, so reports are stable between runs
+func (r *Report) sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.RuleID < b.RuleID
+	})
+}
+
+// rules returns the rules of the validator, followed by the rules of findings missing from
+// the catalog
+func (r *Report) rules() []Rule {
+	known := append([]Rule(nil), rules[r.Validator]...)
+	seen := make(map[string]bool)
+	for _, rule := range known {
+		seen[rule.ID] = true
+	}
+	for _, f := range r.Findings {
+		if !seen[f.RuleID] {
+			seen[f.RuleID] = true
+			known = append(known, Rule{ID: f.RuleID, Description: f.RuleID})
+		}
+	}
+	return known
+}
+
+// relativePath returns path relative to the working directory, with forward slashes
+func relativePath(path string) string {
+	if path == "" || !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// WriteReport writes the report in the format
+func WriteReport(w io.Writer, format string, report *Report) error {
+	report.sort()
+	switch format {
+	case FormatText:
+		return writeText(w, report)
+	case FormatJSON:
+		return writeJSON(w, report)
+	case FormatSARIF:
+		return writeSARIF(w, report)
+	case FormatJUnit:
+		return writeJUnit(w, report)
+	default:
+		return fmt.Errorf("unsupported report format %q (use text, json, sarif or junit)", format)
+	}
+}
+
+// writeText writes the findings that are not baselined as file:line:column: level: message [rule]
+// lines, followed by the counts
+func writeText(w io.Writer, report *Report) error {
+	for _, f := range report.Findings {
+		if f.Baselined {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s [%s]\n", f.Location(), f.Level, f.Message, f.RuleID); err != nil {
+			return err
+		}
+	}
+	counts := fmt.Sprintf("%s: %d errors, %d warnings", report.Validator, report.Count(LevelError), report.Count(LevelWarning))
+	if report.baselined {
+		counts += fmt.Sprintf(", %d baselined", report.CountBaselined())
+	}
+	_, err := fmt.Fprintln(w, counts)
+	return err
+}
+
+// writeJSON writes the report as a JSON object with the counts and the findings
+func writeJSON(w io.Writer, report *Report) error {
+	findings := make([]Finding, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		f.File = relativePath(f.File)
+		findings = append(findings, f)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Validator string    `json:"validator"`
+		Errors    int       `json:"errors"`
+		Warnings  int       `json:"warnings"`
+		Baselined int       `json:"baselined"`
+		Findings  []Finding `json:"findings"`
+	}{report.Validator, report.Count(LevelError), report.Count(LevelWarning), report.CountBaselined(), findings})
+}
+
+// SARIF 2.1.0 document, as uploaded to GitHub code scanning
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool              `json:"tool"`
+	AutomationDetails sarifAutomationDetails `json:"automationDetails"`
+	Results           []sarifResult          `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID        string             `json:"ruleId"`
+	RuleIndex     int                `json:"ruleIndex"`
+	Level         string             `json:"level"`
+	Message       sarifMessage       `json:"message"`
+	Locations     []sarifLocation    `json:"locations,omitempty"`
+	BaselineState string             `json:"baselineState,omitempty"`
+	Suppressions  []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// writeSARIF writes the report as a SARIF log with one run, categorized by the validator so
+// the reports of several validators can be uploaded side by side
+func writeSARIF(w io.Writer, report *Report) error {
+	driver := sarifDriver{Name: "axiomod", InformationURI: "https://github.com/axiomod/axiomod"}
+	index := make(map[string]int)
+	for i, rule := range report.rules() {
+		index[rule.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{ID: rule.ID, ShortDescription: sarifMessage{Text: rule.Description}})
+	}
+
+	results := make([]sarifResult, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		result := sarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: index[f.RuleID],
+			Level:     f.Level,
+			Message:   sarifMessage{Text: f.Message},
+		}
+		if f.File != "" {
+			location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: relativePath(f.File)}}
+			if !filepath.IsAbs(location.ArtifactLocation.URI) {
+				location.ArtifactLocation.URIBaseID = "%SRCROOT%"
+			}
+			if f.Line > 0 {
+				location.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		if report.baselined {
+			result.BaselineState = "new"
+			if f.Baselined {
+				result.BaselineState = "unchanged"
+				result.Suppressions = []sarifSuppression{{Kind: "external", Justification: "Recorded in the validator baseline"}}
+			}
+		}
+		results = append(results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:              sarifTool{Driver: driver},
+			AutomationDetails: sarifAutomationDetails{ID: "axiomod/" + report.Validator + "/"},
+			Results:           results,
+		}},
+	})
+}
+
+// JUnit XML document, as read by CI test reporters
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as a JUnit test suite with a test case per rule. A rule fails
+// with its errors; its warnings and baselined errors are listed in the output of the test case.
+func writeJUnit(w io.Writer, report *Report) error {
+	suite := junitTestSuite{Name: report.Validator}
+	for _, rule := range report.rules() {
+		var errors, warnings []string
+		for _, f := range report.Findings {
+			if f.RuleID != rule.ID {
+				continue
+			}
+			line := fmt.Sprintf("%s: %s", f.Location(), f.Message)
+			switch {
+			case f.Baselined:
+				warnings = append(warnings, line+" (baselined)")
+			case f.Level == LevelError:
+				errors = append(errors, line)
+			default:
+				warnings = append(warnings, line)
+			}
+		}
+
+		testCase := junitTestCase{Name: rule.ID, ClassName: "axiomod.validator." + report.Validator}
+		if len(errors) > 0 {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s: %d violation(s)", rule.Description, len(errors)),
+				Type:    rule.ID,
+				Text:    strings.Join(errors, "\n"),
+			}
+			suite.Failures++
+		}
+		if len(warnings) > 0 {
+			testCase.SystemOut = strings.Join(warnings, "\n")
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{
+		Name:     "axiomod validator",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// addReportFlags adds the --format, --output and --baseline flags of a validator command
+func addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", FormatText, "Output format: text, json, sarif or junit")
+	cmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+	cmd.Flags().String("baseline", "", "Baseline file of accepted violations, e.g. "+DefaultBaselineFile)
+}
+
+// reportFormat returns the --format of a validator command. The progress output moves to
+// stderr when a report is written to stdout, so stdout only holds the report.
+func reportFormat(cmd *cobra.Command) string {
+	format, _ := cmd.Flags().GetString("format")
+	format = strings.ToLower(format)
+	switch format {
+	case FormatText, FormatJSON, FormatSARIF, FormatJUnit:
+	default:
+		fmt.Printf("Unsupported format %q (use text, json, sarif or junit)\n", format)
+		os.Exit(1)
+	}
+	if usesReport(cmd, format) {
+		console = os.Stderr
+	}
+	return format
+}
+
+// usesReport reports whether a validator command writes a report rather than its console
+// output, which it does for machine-readable formats and when comparing with a baseline
+func usesReport(cmd *cobra.Command, format string) bool {
+	baseline, _ := cmd.Flags().GetString("baseline")
+	return format != FormatText || baseline != ""
+}
+
+// emitReport applies the --baseline to the report, writes it to the --output file or stdout,
+// and exits with status 1 when it has errors that are not baselined
+func emitReport(cmd *cobra.Command, format string, report *Report) {
+	if path, _ := cmd.Flags().GetString("baseline"); path != "" {
+		baseline, err := LoadBaseline(path)
+		if err != nil {
+			fmt.Fprintf(console, "Error loading baseline: %v\n", err)
+			fmt.Fprintln(console, "Record the current violations with: axiomod validator baseline --output="+path)
+			os.Exit(1)
+		}
+		report.ApplyBaseline(baseline)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		if err := WriteReport(os.Stdout, format, report); err != nil {
+			fmt.Fprintf(console, "Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		if err := writeReportFile(output, format, report); err != nil {
+			fmt.Fprintf(console, "Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(console, "Report written to %s\n", output)
+	}
+
+	if errors := report.Count(LevelError); errors > 0 {
+		fmt.Fprintf(console, "%s validation failed with %d errors\n", report.Validator, errors)
+		os.Exit(1)
+	}
+}
+
+// writeReportFile writes the report in the format to a file
+func writeReportFile(path string, format string, report *Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteReport(file, format, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
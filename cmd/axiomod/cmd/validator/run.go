@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the validator run command
+var runCmd = &cobra.Command{
+	Use:   "run [packages...]",
+	Short: "Run project-specific validator rules",
+	Long: `Run the project-specific rules registered with the validator SDK
+(github.com/axiomod/axiomod/framework/validatorsdk) by the packages matching --rules.
+
+The command builds a program importing the rule packages, which register their rules in init
+functions, and runs it on the packages given as arguments (./... by default). The findings use the
+rule configuration of validator-rules.json, the axiomod:ignore suppression comments, and the
+report formats and baselines of the other validators. --rules names the rule packages here, so the
+rule configuration is read from its default locations.
+
+Example:
+  axiomod validator run --rules=./rules/...
+  axiomod validator run --rules=./tools/lint/house ./internal/...
+  axiomod validator run --rules=./rules/... --format=sarif --output=rules.sarif
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rulePatterns, _ := cmd.Flags().GetStringSlice("rules")
+		format := reportFormat(cmd)
+		fmt.Fprintln(console, "Running project rules...")
+
+		report, err := CustomRulesReport(rulePatterns, args)
+		if err != nil {
+			fmt.Fprintf(console, "Project rules error: %v\n", err)
+			os.Exit(1)
+		}
+		emitReport(cmd, format, report)
+	},
+}
+
+// NewRunCmd returns the validator run command.
+func NewRunCmd() *cobra.Command {
+	return runCmd
+}
+
+func init() {
+	runCmd.Flags().StringSlice("rules", []string{"./rules/..."}, "Packages registering the project rules")
+	addReportFlags(runCmd)
+
+	// Add subcommands to the parent validatorCmd
+	validatorCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,161 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/axiomod/axiomod/cmd/axiomod/internal/apiscan"
+	"golang.org/x/tools/go/packages"
+)
+
+// customValidator is the validator of the reports of the project rules
+const customValidator = "custom"
+
+// sdkPath is the import path of the validator SDK the project rules are written with
+const sdkPath = "github.com/axiomod/axiomod/framework/validatorsdk"
+
+// rulesMainTemplate is the program running the rules registered by the rule packages
+var rulesMainTemplate = template.Must(template.New("main").Parse(`// Code generated by axiomod validator run. DO NOT EDIT.
+
+package main
+
+import (
+	"{{.SDK}}"
+{{range .Packages}}
+	_ "{{.}}"
+{{- end}}
+)
+
+func main() {
+	validatorsdk.Main()
+}
+`))
+
+// sdkResult is the output of the rules program, see validatorsdk.Result
+type sdkResult struct {
+	Rules []struct {
+		ID          string `json:"id"`
+		Description string `json:"description"`
+	} `json:"rules"`
+	Findings []Finding `json:"findings"`
+}
+
+// CustomRulesReport builds a program importing the rule packages matching rulePatterns, runs the
+// rules they register on the packages matching patterns in the working directory, and returns
+// their findings at the levels of the rule configuration
+func CustomRulesReport(rulePatterns []string, patterns []string) (*Report, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	moduleDir, _, err := apiscan.FindModule(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no Go module found for %s: %w", dir, err)
+	}
+	flags, cleanup, err := scratchModFlags(moduleDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	rulePackages, err := listRulePackages(dir, rulePatterns, flags)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(console, "Running the rules of %s\n", strings.Join(rulePackages, ", "))
+
+	// The program is built inside the module so it may import its internal packages
+	programDir, err := os.MkdirTemp(moduleDir, ".axiomod-rules-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the rules program: %w", err)
+	}
+	defer os.RemoveAll(programDir)
+	var source bytes.Buffer
+	if err := rulesMainTemplate.Execute(&source, map[string]any{"SDK": sdkPath, "Packages": rulePackages}); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(programDir, "main.go"), source.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to create the rules program: %w", err)
+	}
+
+	// The packages the program loads resolve against the same go.mod as the program
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", append([]string{"run", programDir}, patterns...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS="+goFlags(flags))
+	cmd.Stdout = &stdout
+	cmd.Stderr = console
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run the project rules: %w", err)
+	}
+
+	var result sdkResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("failed to read the findings of the project rules: %w", err)
+	}
+
+	// The project rules join the catalog, for the report rule descriptions and the configuration
+	rules[customValidator] = nil
+	for _, rule := range result.Rules {
+		rules[customValidator] = append(rules[customValidator], Rule{ID: rule.ID, Description: rule.Description})
+	}
+	policy, err := loadRulePolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Validator: customValidator}
+	for _, finding := range result.Findings {
+		finding.Level = policy.level(finding.RuleID, finding.Level, finding.File, finding.Line)
+		if finding.Level == "" {
+			continue
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	return report, nil
+}
+
+// listRulePackages returns the import paths of the packages matching the rule patterns
+func listRulePackages(dir string, rulePatterns []string, flags []string) ([]string, error) {
+	config := &packages.Config{Mode: packages.NeedName, Dir: dir, BuildFlags: flags}
+	pkgs, err := packages.Load(config, rulePatterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the rule packages: %w", err)
+	}
+
+	var paths []string
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("failed to load the rule package %s: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+		if pkg.Name == "main" {
+			return nil, fmt.Errorf("rule package %s is a command; rules are registered by library packages", pkg.PkgPath)
+		}
+		paths = append(paths, pkg.PkgPath)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no rule packages match %s", strings.Join(rulePatterns, " "))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// goFlags returns the GOFLAGS of the environment with flags, which replace the -mod and -modfile
+// flags of the environment
+func goFlags(flags []string) string {
+	var merged []string
+	for _, flag := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if len(flags) > 0 && (strings.HasPrefix(flag, "-mod=") || strings.HasPrefix(flag, "-modfile=")) {
+			continue
+		}
+		merged = append(merged, flag)
+	}
+	return strings.Join(append(merged, flags...), " ")
+}
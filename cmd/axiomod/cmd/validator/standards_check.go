@@ -17,16 +17,30 @@ This is equivalent to running:
 - axiomod validator architecture
 - axiomod validator naming
 - axiomod validator domain
+- axiomod validator config
 - axiomod validator static-analysis
 - axiomod validator check-api-spec (if spec provided)
 - axiomod validator check-docs
 
+With --changed-only the architecture, naming and domain validators only check the packages of the
+files changed since --since (HEAD by default) and of untracked files, e.g. in a pre-commit hook.
+With --watch they validate again the packages of the files changed while the command runs, until
+it is interrupted.
+
 Example:
   axiomod validator standards-check
   axiomod validator all
+  axiomod validator all --changed-only
+  axiomod validator all --watch
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Running all standard checks...")
+		if !applyChangedOnly(cmd, "") {
+			return
+		}
+		if watching(cmd, baselineValidators, "", defaultSQLPath, defaultAPIPath) {
+			return
+		}
 
 		// Note: This command should ideally trigger the execution of other validator commands.
 		// For simplicity in this example, we just print a message.
@@ -61,6 +75,15 @@ Example:
 			fmt.Println("Domain boundary validation passed.")
 		}
 
+		fmt.Println("\n--- Running Config Validator ---")
+		if report, err := ConfigReport(nil); err != nil {
+			fmt.Printf("Config validation skipped: %v\n", err)
+		} else if errors := report.Count(LevelError); errors > 0 {
+			fmt.Printf("Config validation failed with %d issues.\n", errors)
+		} else {
+			fmt.Println("Config validation passed.")
+		}
+
 		fmt.Println("\n--- Running Static Analysis Validator ---")
 		// Simulate running staticAnalysisCmd.Run(cmd, args)
 		// This would involve running go vet, gosec, staticcheck
@@ -87,6 +110,8 @@ func NewStandardsCheckCmd() *cobra.Command {
 }
 
 func init() {
+	addIncrementalFlags(standardsCheckCmd)
+
 	// Add subcommands to the parent validatorCmd
 	validatorCmd.AddCommand(standardsCheckCmd)
 }
@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultRulesFile is the rule severity configuration looked up by the validators
+const DefaultRulesFile = "validator-rules.json"
+
+// Severities of the rules in the rule configuration
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// rulesPath is the --rules file of the validator commands. When empty, DefaultRulesFile is
+// looked up in the working directory and in configs/.
+var rulesPath string
+
+// RulesConfig sets the severity of the validator rules
+type RulesConfig struct {
+	// Rules maps rule IDs, or wildcard patterns like naming/*, to error, warning (or warn) or off
+	Rules map[string]string `json:"rules"`
+}
+
+// ignoreComment matches //axiomod:ignore <rule>[,<rule>...] [reason="..."] comments, and their
+// -- and # forms in SQL and YAML files
+var ignoreComment = regexp.MustCompile(`(?://|--|#)\s*axiomod:ignore\s+([^\s]+)`)
+
+// rulePolicy decides the level of findings from the rule severities and the suppression comments
+type rulePolicy struct {
+	severities map[string]string
+	// suppressions holds the suppressed rule patterns of each line of the files read so far
+	suppressions map[string]map[int][]string
+}
+
+// loadRulePolicy loads the rule configuration of the --rules flag or the default locations.
+// Without a configuration, the rules keep their default levels.
+func loadRulePolicy() (*rulePolicy, error) {
+	policy := &rulePolicy{
+		severities:   make(map[string]string),
+		suppressions: make(map[string]map[int][]string),
+	}
+
+	path := rulesPath
+	if path == "" {
+		for _, candidate := range []string{DefaultRulesFile, "." + DefaultRulesFile, filepath.Join("configs", DefaultRulesFile)} {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+	if path == "" {
+		return policy, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule configuration: %w", err)
+	}
+	var config RulesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse rule configuration %s: %w", path, err)
+	}
+
+	for id, severity := range config.Rules {
+		switch strings.ToLower(severity) {
+		case SeverityError:
+			policy.severities[id] = LevelError
+		case SeverityWarning, "warn":
+			policy.severities[id] = LevelWarning
+		case SeverityOff:
+			policy.severities[id] = SeverityOff
+		default:
+			return nil, fmt.Errorf("invalid severity %q of rule %s in %s (use error, warning or off)", severity, id, path)
+		}
+		if !strings.Contains(id, "*") && !knownRule(id) {
+			fmt.Fprintf(console, "Warning: unknown rule %s in %s\n", id, path)
+		}
+	}
+	return policy, nil
+}
+
+// knownRule reports whether a rule ID is in the rule catalog
+func knownRule(id string) bool {
+	for _, validatorRules := range rules {
+		for _, rule := range validatorRules {
+			if rule.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// level returns the level of a finding of the rule at file:line, or "" when the rule is off or
+// the finding is suppressed by a comment
+func (p *rulePolicy) level(ruleID, defaultLevel, file string, line int) string {
+	level := p.severity(ruleID, defaultLevel)
+	if level == SeverityOff || p.suppressed(ruleID, file, line) {
+		return ""
+	}
+	return level
+}
+
+// severity returns the configured level of a rule. An exact rule ID wins over wildcard patterns.
+func (p *rulePolicy) severity(ruleID, defaultLevel string) string {
+	if level, ok := p.severities[ruleID]; ok {
+		return level
+	}
+	for pattern, level := range p.severities {
+		if strings.Contains(pattern, "*") && isWildcardMatch(pattern, ruleID) {
+			return level
+		}
+	}
+	return defaultLevel
+}
+
+// suppressed reports whether an axiomod:ignore comment on the line, or on the line above it,
+// names the rule
+func (p *rulePolicy) suppressed(ruleID, file string, line int) bool {
+	if file == "" || line == 0 {
+		return false
+	}
+	lines, ok := p.suppressions[file]
+	if !ok {
+		lines = fileSuppressions(file)
+		p.suppressions[file] = lines
+	}
+	for _, l := range []int{line, line - 1} {
+		for _, pattern := range lines[l] {
+			if pattern == ruleID || isWildcardMatch(pattern, ruleID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fileSuppressions returns the rule patterns of the axiomod:ignore comments of a file by line
+func fileSuppressions(file string) map[int][]string {
+	suppressions := make(map[int][]string)
+	f, err := os.Open(file)
+	if err != nil {
+		return suppressions
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !strings.Contains(text, "axiomod:ignore") {
+			continue
+		}
+		if match := ignoreComment.FindStringSubmatch(text); match != nil {
+			suppressions[line] = strings.Split(match[1], ",")
+		}
+	}
+	return suppressions
+}
@@ -0,0 +1,198 @@
+package validator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadRules writes a validator-rules.json file into a new working directory and loads it
+func loadRules(t *testing.T, config string) (*rulePolicy, error) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if config != "" {
+		require.NoError(t, os.WriteFile(DefaultRulesFile, []byte(config), 0644))
+	}
+	return loadRulePolicy()
+}
+
+// captureConsole returns the buffer receiving the console output of the test
+func captureConsole(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	previous := console
+	var buf bytes.Buffer
+	console = &buf
+	t.Cleanup(func() { console = previous })
+	return &buf
+}
+
+func TestRulePolicySeverity(t *testing.T) {
+	captureConsole(t)
+	policy, err := loadRules(t, `{"rules": {
+		"naming/*": "warning",
+		"naming/package": "error",
+		"domain/layer-dependency": "warn",
+		"architecture/*": "off"
+	}}`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		ruleID       string
+		defaultLevel string
+		want         string
+	}{
+		{"naming/package", LevelWarning, LevelError},
+		{"naming/file-name", LevelError, LevelWarning},
+		{"domain/layer-dependency", LevelError, LevelWarning},
+		{"domain/cross-domain-dependency", LevelError, LevelError},
+		{"architecture/layer-dependency", LevelError, ""},
+		{"config/unknown-key", LevelWarning, LevelWarning},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.level(tt.ruleID, tt.defaultLevel, "", 0), tt.ruleID)
+	}
+}
+
+func TestLoadRulePolicy(t *testing.T) {
+	t.Run("no configuration", func(t *testing.T) {
+		policy, err := loadRules(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, LevelError, policy.level("naming/package", LevelError, "", 0))
+	})
+
+	t.Run("unknown rule", func(t *testing.T) {
+		out := captureConsole(t)
+		policy, err := loadRules(t, `{"rules": {"naming/no-such-rule": "off", "house/*": "off"}}`)
+		require.NoError(t, err)
+		assert.Equal(t, "Warning: unknown rule naming/no-such-rule in validator-rules.json\n", out.String())
+		assert.Equal(t, "", policy.level("naming/no-such-rule", LevelError, "", 0))
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		_, err := loadRules(t, `{"rules": {"naming/package": "fatal"}}`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid severity "fatal" of rule naming/package`)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := loadRules(t, `{"rules": [`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse rule configuration")
+	})
+}
+
+func TestRulePolicySuppressed(t *testing.T) {
+	dir := t.TempDir()
+	goFile := filepath.Join(dir, "user.go")
+	require.NoError(t, os.WriteFile(goFile, []byte(`package user
+
+//axiomod:ignore naming/type reason="generated by protoc"
+type user_Name struct{}
+
+var user_id = 1 // axiomod:ignore naming/variable,naming/exported-variable
+
+//axiomod:ignore naming/*
+
+var ServerURL = ""
+
+// Not a suppression: axiomod-ignore naming/variable
+var plain_name = ""
+`), 0644))
+	sqlFile := filepath.Join(dir, "001_init.up.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte(`-- axiomod:ignore naming/table-name reason="legacy table"
+CREATE TABLE UserData (id INT);
+`), 0644))
+	yamlFile := filepath.Join(dir, "service.yaml")
+	require.NoError(t, os.WriteFile(yamlFile, []byte(`app:
+  nmae: shop # axiomod:ignore config/unknown-key
+`), 0644))
+
+	tests := []struct {
+		name   string
+		ruleID string
+		file   string
+		line   int
+		want   bool
+	}{
+		{"comment on the line above", "naming/type", goFile, 4, true},
+		{"comment two lines above", "naming/type", goFile, 5, false},
+		{"other rule", "naming/function", goFile, 4, false},
+		{"comment on the same line", "naming/variable", goFile, 6, true},
+		{"second rule of a list", "naming/exported-variable", goFile, 6, true},
+		{"wildcard", "naming/file-name", goFile, 9, true},
+		{"wildcard separated by a blank line", "naming/variable", goFile, 10, false},
+		{"malformed comment", "naming/variable", goFile, 13, false},
+		{"SQL comment", "naming/table-name", sqlFile, 2, true},
+		{"YAML comment", "config/unknown-key", yamlFile, 2, true},
+		{"no line", "naming/type", goFile, 0, false},
+		{"missing file", "naming/type", filepath.Join(dir, "missing.go"), 4, false},
+	}
+
+	policy := &rulePolicy{severities: map[string]string{}, suppressions: map[string]map[int][]string{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.suppressed(tt.ruleID, tt.file, tt.line))
+			if tt.want {
+				assert.Empty(t, policy.level(tt.ruleID, LevelError, tt.file, tt.line))
+			}
+		})
+	}
+}
+
+// TestSeverityExitStatus checks that the rule configuration and the suppression comments decide
+// whether a validation fails, which makes the commands exit with status 1
+func TestSeverityExitStatus(t *testing.T) {
+	const handler = `package http
+
+import "github.com/axiomod/axiomod/examples/example/repository"
+
+var _ repository.Repository
+`
+	tests := []struct {
+		name     string
+		rules    string
+		source   string
+		passed   bool
+		warnings int
+	}{
+		{name: "default level", source: handler},
+		{name: "lowered to a warning", rules: `{"rules": {"domain/layer-dependency": "warning"}}`, source: handler, passed: true, warnings: 1},
+		{name: "lowered by a pattern", rules: `{"rules": {"domain/*": "warn"}}`, source: handler, passed: true, warnings: 1},
+		{name: "turned off", rules: `{"rules": {"domain/layer-dependency": "off"}}`, source: handler, passed: true},
+		{name: "raised after a pattern", rules: `{"rules": {"domain/*": "off", "domain/layer-dependency": "error"}}`, source: handler},
+		{
+			name:   "suppressed",
+			source: "package http\n\n//axiomod:ignore domain/layer-dependency reason=\"migration\"\nimport \"github.com/axiomod/axiomod/examples/example/repository\"\n\nvar _ repository.Repository\n",
+			passed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureConsole(t)
+			dir := t.TempDir()
+			t.Chdir(dir)
+			path := filepath.Join("examples", "example", "delivery", "http", "handler.go")
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(tt.source), 0644))
+			if tt.rules != "" {
+				require.NoError(t, os.WriteFile(DefaultRulesFile, []byte(tt.rules), 0644))
+			}
+
+			passed, err := RunDomainValidation(".", "")
+			assert.Equal(t, tt.passed, passed)
+			if !tt.passed {
+				require.Error(t, err)
+			}
+
+			report, err := DomainReport(".", "")
+			require.NoError(t, err)
+			assert.Equal(t, !tt.passed, report.Count(LevelError) > 0)
+			assert.Equal(t, tt.warnings, report.Count(LevelWarning))
+		})
+	}
+}
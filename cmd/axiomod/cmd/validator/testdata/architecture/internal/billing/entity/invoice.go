@@ -0,0 +1,8 @@
+package entity
+
+import shop "example.com/layered/internal/shop/entity"
+
+// Invoice bills an order
+type Invoice struct {
+	Order shop.Order
+}
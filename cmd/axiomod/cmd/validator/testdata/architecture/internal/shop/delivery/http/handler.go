@@ -0,0 +1,6 @@
+package http
+
+import _ "example.com/layered/internal/shop/entity"
+
+// Path is the path of the orders
+const Path = "/orders/"
@@ -0,0 +1,5 @@
+//go:build integration
+
+package http
+
+import _ "example.com/layered/internal/shop/infrastructure/persistence"
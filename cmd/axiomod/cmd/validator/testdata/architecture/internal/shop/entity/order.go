@@ -0,0 +1,6 @@
+package entity
+
+// Order is an order of the shop
+type Order struct {
+	ID string
+}
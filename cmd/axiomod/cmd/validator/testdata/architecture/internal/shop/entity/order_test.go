@@ -0,0 +1,12 @@
+package entity_test
+
+import (
+	"testing"
+
+	"example.com/layered/internal/shop/entity"
+	"example.com/layered/internal/shop/usecase"
+)
+
+func TestOrder(t *testing.T) {
+	_ = usecase.Get(&entity.Order{ID: "1"})
+}
@@ -0,0 +1,6 @@
+package persistence
+
+import (
+	_ "example.com/layered/internal/shop/entity"
+	_ "example.com/layered/internal/shop/repository"
+)
@@ -0,0 +1,8 @@
+package repository
+
+import "example.com/layered/internal/shop/entity"
+
+// Repository stores orders
+type Repository interface {
+	Get(id string) (*entity.Order, error)
+}
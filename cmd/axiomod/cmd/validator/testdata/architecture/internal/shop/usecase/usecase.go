@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"example.com/layered/internal/shop/delivery/http"
+	"example.com/layered/internal/shop/entity"
+	_ "example.com/layered/internal/shop/repository"
+)
+
+// Get returns the path of an order
+func Get(order *entity.Order) string {
+	return http.Path + order.ID
+}
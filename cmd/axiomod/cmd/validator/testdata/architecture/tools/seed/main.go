@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/layered/internal/shop/entity"
+	"example.com/layered/internal/shop/usecase"
+)
+
+func main() {
+	fmt.Println(usecase.Get(&entity.Order{ID: "1"}))
+}
@@ -0,0 +1,6 @@
+package entity
+
+// Invoice bills an order
+type Invoice struct {
+	ID string
+}
@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"example.com/cycle/internal/billing/entity"
+	shop "example.com/cycle/internal/shop/entity"
+)
+
+// BillOrder bills an order of the shop domain, which closes the cycle between the shop and
+// billing domains
+func BillOrder(order shop.Order) *entity.Invoice {
+	return &entity.Invoice{ID: order.ID}
+}
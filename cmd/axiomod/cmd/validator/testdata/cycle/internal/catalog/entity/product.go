@@ -0,0 +1,6 @@
+package entity
+
+// Product is sold by the shop domain
+type Product struct {
+	ID string
+}
@@ -0,0 +1,9 @@
+package entity
+
+import billing "example.com/cycle/internal/billing/entity"
+
+// Order is paid by an invoice of the billing domain
+type Order struct {
+	ID      string
+	Invoice *billing.Invoice
+}
@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	catalog "example.com/cycle/internal/catalog/entity"
+	"example.com/cycle/internal/shop/entity"
+	"example.com/cycle/platform/clock"
+)
+
+// PlaceOrder orders a product of the catalog domain
+func PlaceOrder(product catalog.Product) entity.Order {
+	return entity.Order{ID: product.ID + "-" + clock.Now()}
+}
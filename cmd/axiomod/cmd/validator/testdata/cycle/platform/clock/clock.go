@@ -0,0 +1,8 @@
+package clock
+
+import "time"
+
+// Now returns the current time as text
+func Now() string {
+	return time.Now().Format(time.RFC3339)
+}
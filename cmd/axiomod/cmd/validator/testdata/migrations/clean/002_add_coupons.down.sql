@@ -0,0 +1,2 @@
+DROP INDEX idx_orders_coupon_id;
+DROP TABLE coupons;
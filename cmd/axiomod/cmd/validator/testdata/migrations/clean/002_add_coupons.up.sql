@@ -0,0 +1,7 @@
+CREATE TABLE coupons (
+    id UUID PRIMARY KEY,
+    order_id UUID UNIQUE CONSTRAINT fk_coupons_order_id REFERENCES orders (id)
+);
+
+ALTER TABLE orders ADD COLUMN coupon_id UUID;
+CREATE INDEX CONCURRENTLY idx_orders_coupon_id ON orders (coupon_id);
@@ -0,0 +1,7 @@
+CREATE TABLE orders (
+    id UUID PRIMARY KEY,
+    status TEXT NOT NULL
+);
+
+-- The table is created by this migration, so the index locks no rows
+CREATE INDEX idx_orders_status ON orders (status);
@@ -0,0 +1,2 @@
+DROP INDEX idx_orders_status_placed_at;
+DROP INDEX idx_orders_placed_at;
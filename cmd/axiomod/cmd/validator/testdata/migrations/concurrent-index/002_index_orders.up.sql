@@ -0,0 +1,4 @@
+ALTER TABLE orders ADD COLUMN placed_at TIMESTAMP;
+
+CREATE INDEX idx_orders_placed_at ON orders (placed_at);
+CREATE INDEX CONCURRENTLY idx_orders_status_placed_at ON orders (status, placed_at);
@@ -0,0 +1 @@
+CREATE TABLE order_archive (id UUID PRIMARY KEY);
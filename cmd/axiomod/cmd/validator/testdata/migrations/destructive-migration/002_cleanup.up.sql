@@ -0,0 +1,10 @@
+ALTER TABLE orders DROP COLUMN legacy_code;
+
+ALTER TABLE orders ALTER COLUMN total TYPE NUMERIC(12, 2);
+
+TRUNCATE TABLE order_events;
+
+-- axiomod:destructive reason="the archive moved to the warehouse"
+DROP TABLE order_archive;
+
+ALTER TABLE orders DROP CONSTRAINT fk_orders_coupon_id;
@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id UUID PRIMARY KEY
+);
+
+CREATE TABLE orders (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    coupon_id UUID
+);
+
+CREATE INDEX idx_orders_user_id ON orders (user_id);
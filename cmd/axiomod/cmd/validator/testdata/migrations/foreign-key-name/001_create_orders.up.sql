@@ -0,0 +1,9 @@
+CREATE TABLE orders (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL CONSTRAINT orders_user REFERENCES users (id),
+    shop_id UUID NOT NULL,
+    CONSTRAINT shop_ref FOREIGN KEY (shop_id) REFERENCES shops (id)
+);
+
+CREATE INDEX idx_orders_user_id ON orders (user_id);
+CREATE INDEX idx_orders_shop_id ON orders (shop_id);
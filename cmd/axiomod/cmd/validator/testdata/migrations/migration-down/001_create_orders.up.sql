@@ -0,0 +1 @@
+CREATE TABLE orders (id UUID PRIMARY KEY);
@@ -0,0 +1 @@
+CREATE TABLE items (id UUID PRIMARY KEY);
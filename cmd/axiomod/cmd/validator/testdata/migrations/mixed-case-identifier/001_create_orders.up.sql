@@ -0,0 +1,8 @@
+CREATE TABLE orders (
+    id UUID PRIMARY KEY,
+    "userId" UUID NOT NULL CONSTRAINT "FK_orders_user" REFERENCES "Users" (id)
+);
+
+CREATE INDEX "IDX_orders_user" ON orders ("userId");
+
+ALTER TABLE orders RENAME COLUMN id TO "orderId";
@@ -32,3 +32,7 @@ func NewValidatorCmd() *cobra.Command {
 	// Add subcommands in their respective files using init()
 	return validatorCmd
 }
+
+func init() {
+	validatorCmd.PersistentFlags().StringVar(&rulesPath, "rules", "", "Rule severity configuration (default: "+DefaultRulesFile+" if present)")
+}
@@ -44,8 +44,8 @@ func CheckAPISpec(specPath string) error {
 	return nil
 }
 
-// ValidateNaming checks naming conventions across the codebase
-// This function is called by the naming command and standards-check command
+// ValidateNaming checks naming conventions across the codebase and returns its errors
+// This function is called by the standards-check command
 func ValidateNaming(fixIssues bool) ([]string, error) {
 	// Get current working directory
 	rootDir, err := os.Getwd()
@@ -53,15 +53,17 @@ func ValidateNaming(fixIssues bool) ([]string, error) {
 		return nil, fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	// In a real implementation, this would scan the codebase for naming convention violations
-	fmt.Printf("Validating naming conventions in: %s\n", rootDir)
+	fmt.Fprintf(console, "Validating naming conventions in: %s\n", rootDir)
 
-	// For demonstration purposes, we'll just return an empty list of issues
-	return []string{}, nil
+	report, err := NamingReport(rootDir, defaultSQLPath, defaultAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	return report.Issues(), nil
 }
 
-// ValidateDomainBoundaries checks that domain boundaries are respected
-// This function is called by the domain command and standards-check command
+// ValidateDomainBoundaries checks that domain boundaries are respected and returns the violations
+// This function is called by the standards-check command
 func ValidateDomainBoundaries() ([]string, error) {
 	// Get current working directory
 	rootDir, err := os.Getwd()
@@ -69,9 +71,9 @@ func ValidateDomainBoundaries() ([]string, error) {
 		return nil, fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	// In a real implementation, this would analyze imports to ensure domain boundaries are respected
-	fmt.Printf("Validating domain boundaries in: %s\n", rootDir)
-
-	// For demonstration purposes, we'll just return an empty list of issues
-	return []string{}, nil
+	report, err := DomainReport(rootDir, "")
+	if err != nil {
+		return nil, err
+	}
+	return report.Issues(), nil
 }
@@ -0,0 +1,1084 @@
+package apiscan
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Import paths the scanner recognizes routers and servers by
+const (
+	FiberPath  = "github.com/gofiber/fiber/v2"
+	serverPath = "github.com/axiomod/axiomod/platform/server"
+)
+
+// Scanner statically extracts the route table of a Go module from its Fiber route
+// registrations, without type checking it. Packages outside the module are parsed from the
+// directories go list finds them in, when their types are needed.
+type Scanner struct {
+	Root       string
+	ModulePath string
+	Fset       *token.FileSet
+	Warnings   []string
+
+	packages map[string]*Package // by import path; nil for packages that were not found
+	project  []*Package          // packages of the module, sorted by import path
+}
+
+// Package is a parsed Go package
+type Package struct {
+	Path  string
+	Name  string
+	Files []*File
+	Types map[string]*TypeSpec
+	Funcs map[string]*Func // functions by name, methods by "<receiver type>.<name>"
+	Enums map[string][]any // values of the constants declared with a type, by type name
+
+	values map[string]value // package-level constants and variables with a single value
+}
+
+// File is a parsed Go file of a package
+type File struct {
+	Package *Package
+	AST     *ast.File
+}
+
+// TypeSpec is a type declaration, with the doc comment of its declaration
+type TypeSpec struct {
+	File *File
+	Spec *ast.TypeSpec
+	Doc  *ast.CommentGroup
+}
+
+// Func is a function or method declaration, with the routes it registers and the calls
+// handing its routers over to other functions
+type Func struct {
+	File *File
+	Decl *ast.FuncDecl
+	Recv string // name of the receiver type of a method
+
+	routes []registration
+	mounts []mount
+}
+
+// Type is a type expression in the file it appears in, with the type arguments of the
+// generic declaration it belongs to
+type Type struct {
+	File *File
+	Expr ast.Expr
+	Args map[string]Type
+}
+
+// Route is a route of the module with its full path
+type Route struct {
+	Func    *Func    // function registering the route
+	Method  string   // lowercase HTTP method, e.g. get
+	Path    string   // full path, with the prefixes of the groups the route is registered on
+	RawPath string   // path given to the registration, without the prefixes
+	Handler ast.Expr // last argument of the registration
+	Pos     token.Pos
+}
+
+// value is the value of a package-level constant or variable, in the file it is declared in
+type value struct {
+	file *File
+	expr ast.Expr
+}
+
+// routerRef is a router expression of a function: a router or server parameter, or the root
+// of the application, and the prefix of the groups created on it
+type routerRef struct {
+	param  int // index of the parameter, -1 for the root
+	prefix string
+}
+
+// registration is a route registration, e.g. group.Get("/:id", h.Get)
+type registration struct {
+	router  routerRef
+	method  string
+	path    string
+	handler ast.Expr
+	pos     token.Pos
+}
+
+// mount is a call passing a router to another function, e.g. handler.RegisterRoutes(api)
+type mount struct {
+	call   *ast.CallExpr
+	arg    int
+	router routerRef
+}
+
+// RouteMethods are the Fiber router methods registering routes, and their HTTP methods
+var RouteMethods = map[string]string{
+	"Get":     "get",
+	"Post":    "post",
+	"Put":     "put",
+	"Patch":   "patch",
+	"Delete":  "delete",
+	"Head":    "head",
+	"Options": "options",
+}
+
+// New returns a scanner of the module of the given directory and path
+func New(root, modulePath string) *Scanner {
+	return &Scanner{
+		Root:       root,
+		ModulePath: modulePath,
+		Fset:       token.NewFileSet(),
+		packages:   make(map[string]*Package),
+	}
+}
+
+// Load parses the packages of the module, skipping nested modules, vendored code and
+// test files
+func (s *Scanner) Load() error {
+	err := filepath.WalkDir(s.Root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if name != s.Root {
+			base := entry.Name()
+			if strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") || base == "vendor" || base == "testdata" || base == "node_modules" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(name, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		rel, err := filepath.Rel(s.Root, name)
+		if err != nil {
+			return err
+		}
+		importPath := s.ModulePath
+		if rel != "." {
+			importPath = path.Join(s.ModulePath, filepath.ToSlash(rel))
+		}
+		pkg, err := s.parsePackage(name, importPath)
+		if err != nil {
+			return err
+		}
+		if pkg != nil {
+			s.packages[importPath] = pkg
+			s.project = append(s.project, pkg)
+		}
+		return nil
+	})
+	sort.Slice(s.project, func(i, j int) bool { return s.project[i].Path < s.project[j].Path })
+	return err
+}
+
+// parsePackage parses the Go files of a directory, without tests; it returns nil when the
+// directory has none. Files of another package than the first one, such as ignored programs,
+// are left out.
+func (s *Scanner) parsePackage(dir, importPath string) (*Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var pkg *Package
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(s.Fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, name), err)
+		}
+		if pkg == nil {
+			pkg = &Package{
+				Path:  importPath,
+				Name:  file.Name.Name,
+				Types: make(map[string]*TypeSpec),
+				Funcs: make(map[string]*Func),
+				Enums: make(map[string][]any),
+
+				values: make(map[string]value),
+			}
+		}
+		if file.Name.Name != pkg.Name {
+			continue
+		}
+		pkg.addFile(&File{Package: pkg, AST: file})
+	}
+	return pkg, nil
+}
+
+// addFile indexes the declarations of a file
+func (p *Package) addFile(f *File) {
+	p.Files = append(p.Files, f)
+	for _, decl := range f.AST.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			fn := &Func{File: f, Decl: decl}
+			key := decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) == 1 {
+				fn.Recv = BaseTypeName(decl.Recv.List[0].Type)
+				key = fn.Recv + "." + key
+			}
+			p.Funcs[key] = fn
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc := spec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					p.Types[spec.Name.Name] = &TypeSpec{File: f, Spec: spec, Doc: doc}
+				case *ast.ValueSpec:
+					if len(spec.Names) == len(spec.Values) {
+						for i, name := range spec.Names {
+							p.values[name.Name] = value{file: f, expr: spec.Values[i]}
+						}
+					}
+					if decl.Tok != token.CONST || spec.Type == nil {
+						continue
+					}
+					typeName, ok := spec.Type.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					for _, value := range spec.Values {
+						if v, ok := literalValue(value); ok {
+							p.Enums[typeName.Name] = append(p.Enums[typeName.Name], v)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// BaseTypeName returns the name of a receiver type, e.g. Handler for *Handler or List[T]
+func BaseTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return BaseTypeName(e.X)
+	case *ast.ParenExpr:
+		return BaseTypeName(e.X)
+	case *ast.IndexExpr:
+		return BaseTypeName(e.X)
+	case *ast.IndexListExpr:
+		return BaseTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// LookupPackage returns the package of an import path, parsing packages outside the module
+// from the directory go list finds them in; it returns nil when the package is not found
+func (s *Scanner) LookupPackage(importPath string) *Package {
+	if pkg, ok := s.packages[importPath]; ok {
+		return pkg
+	}
+	s.packages[importPath] = nil
+	if importPath == s.ModulePath || strings.HasPrefix(importPath, s.ModulePath+"/") || importPath == "C" {
+		return nil
+	}
+	cmd := exec.Command("go", "list", "-find", "-f", "{{.Dir}}", importPath)
+	cmd.Dir = s.Root
+	out, err := cmd.Output()
+	dir := strings.TrimSpace(string(out))
+	if err != nil || dir == "" {
+		s.Warnf("package %s was not found; its types are documented as any value", importPath)
+		return nil
+	}
+	pkg, err := s.parsePackage(dir, importPath)
+	if err != nil {
+		s.Warnf("%v", err)
+		return nil
+	}
+	s.packages[importPath] = pkg
+	return pkg
+}
+
+// Warnf records a warning about the code the scanner could not follow
+func (s *Scanner) Warnf(format string, args ...any) {
+	s.Warnings = append(s.Warnings, fmt.Sprintf(format, args...))
+}
+
+// versionSuffix matches the major version elements of import paths, e.g. v2
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importPath returns the path of the package a file refers to by name
+func (s *Scanner) importPath(f *File, name string) (string, bool) {
+	var unnamed []string
+	for _, spec := range f.AST.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return importPath, true
+			}
+			continue
+		}
+		if guessPackageName(importPath) == name {
+			return importPath, true
+		}
+		unnamed = append(unnamed, importPath)
+	}
+	// The name of a package may differ from its path
+	for _, importPath := range unnamed {
+		if pkg := s.LookupPackage(importPath); pkg != nil && pkg.Name == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// guessPackageName returns the usual name of the package of an import path, without its
+// major version, e.g. fiber for github.com/gofiber/fiber/v2 and yaml for gopkg.in/yaml.v3
+func guessPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if versionSuffix.MatchString(base) && path.Dir(importPath) != "." {
+		base = path.Base(path.Dir(importPath))
+	}
+	if i := strings.Index(base, ".v"); i > 0 && strings.HasPrefix(importPath, "gopkg.in/") {
+		base = base[:i]
+	}
+	base = strings.TrimPrefix(base, "go-")
+	return strings.ReplaceAll(base, "-", "_")
+}
+
+// SelectorPackage returns the import path of a qualified identifier, e.g. fiber.Router
+func (s *Scanner) SelectorPackage(f *File, expr ast.Expr) (string, string, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || ident.Obj != nil {
+		return "", "", false
+	}
+	importPath, ok := s.importPath(f, ident.Name)
+	return importPath, sel.Sel.Name, ok
+}
+
+// isRouterType reports whether a type is a Fiber router, or the HTTP server of the framework
+// serving its routes on App
+func (s *Scanner) isRouterType(f *File, expr ast.Expr) (router, server bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	importPath, name, ok := s.SelectorPackage(f, expr)
+	switch {
+	case !ok:
+		return false, false
+	case importPath == FiberPath:
+		return name == "Router" || name == "App" || name == "Group", false
+	case importPath == serverPath:
+		return false, name == "HTTPServer"
+	}
+	return false, false
+}
+
+// scanRoutes collects the routes a function registers, and the calls passing its routers on
+func (s *Scanner) scanRoutes(fn *Func) {
+	fn.routes, fn.mounts = nil, nil
+	if fn.Decl.Body == nil {
+		return
+	}
+	params := s.routerParams(fn)
+	ast.Inspect(fn.Decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if method, ok := RouteMethods[sel.Sel.Name]; ok && len(call.Args) >= 2 {
+				if routePath, ok := s.stringValue(fn.File, call.Args[0], 0); ok {
+					if ref, ok := s.router(fn.File, params, sel.X, 0); ok {
+						fn.routes = append(fn.routes, registration{router: ref, method: method, path: routePath, handler: call.Args[len(call.Args)-1], pos: call.Pos()})
+						return true
+					}
+				}
+			}
+		}
+		for i, arg := range call.Args {
+			if ref, ok := s.router(fn.File, params, arg, 0); ok {
+				fn.mounts = append(fn.mounts, mount{call: call, arg: i, router: ref})
+			}
+		}
+		return true
+	})
+}
+
+// routerParams returns the indexes of the router and server parameters of a function
+func (s *Scanner) routerParams(fn *Func) map[*ast.Object]int {
+	params := make(map[*ast.Object]int)
+	i := 0
+	for _, field := range fn.Decl.Type.Params.List {
+		router, server := s.isRouterType(fn.File, field.Type)
+		if len(field.Names) == 0 {
+			i++
+			continue
+		}
+		for _, name := range field.Names {
+			if (router || server) && name.Obj != nil {
+				params[name.Obj] = i
+			}
+			i++
+		}
+	}
+	return params
+}
+
+// router resolves a router expression of a function: a parameter, a group created on a
+// router, the App of a server, or a new Fiber app
+func (s *Scanner) router(f *File, params map[*ast.Object]int, expr ast.Expr, depth int) (routerRef, bool) {
+	if depth > 8 {
+		return routerRef{}, false
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return s.router(f, params, e.X, depth+1)
+	case *ast.Ident:
+		if e.Obj == nil {
+			return routerRef{}, false
+		}
+		if i, ok := params[e.Obj]; ok {
+			if field, ok := e.Obj.Decl.(*ast.Field); ok {
+				if router, _ := s.isRouterType(f, field.Type); router {
+					return routerRef{param: i}, true
+				}
+			}
+			return routerRef{}, false
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.Field:
+			// Parameters of function literals are injected, e.g. by fx.Invoke
+			if router, _ := s.isRouterType(f, decl.Type); router {
+				return routerRef{param: -1}, true
+			}
+		case *ast.AssignStmt, *ast.ValueSpec:
+			if value, index := assignedValue(decl, e); value != nil && index < 0 {
+				return s.router(f, params, value, depth+1)
+			}
+		}
+	case *ast.SelectorExpr:
+		if e.Sel.Name == "App" {
+			return s.server(f, params, e.X)
+		}
+	case *ast.CallExpr:
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" && len(e.Args) >= 1 {
+			prefix, ok := s.stringValue(f, e.Args[0], 0)
+			if !ok {
+				return routerRef{}, false
+			}
+			ref, ok := s.router(f, params, sel.X, depth+1)
+			ref.prefix = joinRoute(ref.prefix, prefix)
+			return ref, ok
+		}
+		if importPath, name, ok := s.SelectorPackage(f, e.Fun); ok && importPath == FiberPath && name == "New" {
+			return routerRef{param: -1}, true
+		}
+	}
+	return routerRef{}, false
+}
+
+// server resolves the HTTP server whose App serves routes
+func (s *Scanner) server(f *File, params map[*ast.Object]int, expr ast.Expr) (routerRef, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Obj == nil {
+		return routerRef{}, false
+	}
+	field, ok := ident.Obj.Decl.(*ast.Field)
+	if !ok {
+		return routerRef{}, false
+	}
+	if _, server := s.isRouterType(f, field.Type); !server {
+		return routerRef{}, false
+	}
+	if i, ok := params[ident.Obj]; ok {
+		return routerRef{param: i}, true
+	}
+	return routerRef{param: -1}, true
+}
+
+// assignedValue returns the value assigned to an identifier by its declaration. When the
+// identifier is one of the results of a call, it returns the call and the index of the result;
+// the index is -1 otherwise.
+func assignedValue(decl any, ident *ast.Ident) (ast.Expr, int) {
+	var lhs []*ast.Ident
+	var rhs []ast.Expr
+	switch decl := decl.(type) {
+	case *ast.AssignStmt:
+		for _, expr := range decl.Lhs {
+			id, _ := expr.(*ast.Ident)
+			lhs = append(lhs, id)
+		}
+		rhs = decl.Rhs
+	case *ast.ValueSpec:
+		lhs = decl.Names
+		rhs = decl.Values
+	}
+	for i, id := range lhs {
+		if id == nil || id.Name != ident.Name {
+			continue
+		}
+		switch {
+		case len(rhs) == len(lhs):
+			return rhs[i], -1
+		case len(rhs) == 1:
+			return rhs[0], i
+		}
+	}
+	return nil, -1
+}
+
+// Routes returns the routes of the module with their full paths. Functions receiving
+// routers from other functions of the module get the prefixes of the groups they are given;
+// the others, such as functions invoked by fx, serve at the root.
+func (s *Scanner) Routes() []Route {
+	type edge struct {
+		from  *Func
+		ref   routerRef
+		to    *Func
+		param int
+	}
+	var funcs []*Func
+	for _, pkg := range s.project {
+		names := make([]string, 0, len(pkg.Funcs))
+		for name := range pkg.Funcs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fn := pkg.Funcs[name]
+			s.scanRoutes(fn)
+			funcs = append(funcs, fn)
+		}
+	}
+
+	var edges []edge
+	mounted := make(map[*Func]bool)
+	for _, fn := range funcs {
+		for _, mount := range fn.mounts {
+			for _, callee := range s.callees(fn.File, mount.call) {
+				if _, ok := s.paramAt(callee, mount.arg); ok {
+					edges = append(edges, edge{from: fn, ref: mount.router, to: callee, param: mount.arg})
+					mounted[callee] = true
+				}
+			}
+		}
+	}
+
+	// Handlers registered with server.AsHTTPRoutes[T](prefix) serve their routes under prefix
+	for _, pkg := range s.project {
+		for _, file := range pkg.Files {
+			for _, registered := range s.httpRoutes(file) {
+				edges = append(edges, edge{ref: routerRef{param: -1, prefix: registered.prefix}, to: registered.fn})
+				mounted[registered.fn] = true
+			}
+		}
+	}
+
+	prefixes := make(map[*Func]map[int][]string)
+	add := func(fn *Func, param int, prefix string) bool {
+		if prefixes[fn] == nil {
+			prefixes[fn] = make(map[int][]string)
+		}
+		for _, p := range prefixes[fn][param] {
+			if p == prefix {
+				return false
+			}
+		}
+		if len(prefixes[fn][param]) >= 32 {
+			return false
+		}
+		prefixes[fn][param] = append(prefixes[fn][param], prefix)
+		return true
+	}
+	for _, fn := range funcs {
+		if !mounted[fn] {
+			for _, i := range s.routerParams(fn) {
+				add(fn, i, "")
+			}
+		}
+	}
+	for round, changed := 0, true; changed && round < 16; round++ {
+		changed = false
+		for _, e := range edges {
+			from := []string{""}
+			if e.ref.param >= 0 {
+				from = prefixes[e.from][e.ref.param]
+			}
+			for _, prefix := range from {
+				if add(e.to, e.param, joinRoute(prefix, e.ref.prefix)) {
+					changed = true
+				}
+			}
+		}
+	}
+
+	var routes []Route
+	for _, fn := range funcs {
+		for _, route := range fn.routes {
+			from := []string{""}
+			if route.router.param >= 0 && len(prefixes[fn][route.router.param]) > 0 {
+				from = prefixes[fn][route.router.param]
+			}
+			for _, prefix := range from {
+				routes = append(routes, Route{
+					Func:    fn,
+					Method:  route.method,
+					Path:    joinRoute(joinRoute(prefix, route.router.prefix), route.path),
+					RawPath: route.path,
+					Handler: route.handler,
+					Pos:     route.pos,
+				})
+			}
+		}
+	}
+	return routes
+}
+
+// registeredRoutes is a RegisterRoutes method registered on the HTTP server under a prefix
+type registeredRoutes struct {
+	fn     *Func
+	prefix string
+}
+
+// httpRoutes returns the RegisterRoutes methods a file registers with server.AsHTTPRoutes
+func (s *Scanner) httpRoutes(f *File) []registeredRoutes {
+	var registered []registeredRoutes
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+		index, ok := call.Fun.(*ast.IndexExpr)
+		if !ok {
+			return true
+		}
+		if importPath, name, ok := s.SelectorPackage(f, index.X); !ok || importPath != serverPath || name != "AsHTTPRoutes" {
+			return true
+		}
+		prefix, ok := s.stringValue(f, call.Args[0], 0)
+		if !ok {
+			s.Warnf("%s: the prefix of the routes is not a constant", s.Fset.Position(call.Pos()))
+			return true
+		}
+		spec, _ := s.NamedType(Type{File: f, Expr: index.Index})
+		if spec == nil {
+			return true
+		}
+		if fn := spec.File.Package.Funcs[spec.Spec.Name.Name+".RegisterRoutes"]; fn != nil {
+			if _, ok := s.paramAt(fn, 0); ok {
+				registered = append(registered, registeredRoutes{fn: fn, prefix: prefix})
+			}
+		}
+		return true
+	})
+	return registered
+}
+
+// paramAt returns the type of the parameter of a function at an index
+func (s *Scanner) paramAt(fn *Func, index int) (ast.Expr, bool) {
+	i := 0
+	for _, field := range fn.Decl.Type.Params.List {
+		n := max(len(field.Names), 1)
+		if index < i+n {
+			router, server := s.isRouterType(fn.File, field.Type)
+			return field.Type, router || server
+		}
+		i += n
+	}
+	return nil, false
+}
+
+// callees returns the functions of the module a call may call: the function or method it
+// names when its receiver type is known, every method of the name otherwise
+func (s *Scanner) callees(f *File, call *ast.CallExpr) []*Func {
+	if fn, _ := s.Callee(f, call); fn != nil {
+		return []*Func{fn}
+	}
+	sel, ok := UnwrapIndex(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	if _, _, ok := s.SelectorPackage(f, sel); ok {
+		return nil
+	}
+	var methods []*Func
+	for _, pkg := range s.project {
+		for _, fn := range pkg.Funcs {
+			if fn.Recv != "" && fn.Decl.Name.Name == sel.Sel.Name {
+				methods = append(methods, fn)
+			}
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].File.Package.Path+"."+methods[i].Recv < methods[j].File.Package.Path+"."+methods[j].Recv
+	})
+	return methods
+}
+
+// Callee resolves the function a call calls, and the type arguments it is given
+func (s *Scanner) Callee(f *File, call *ast.CallExpr) (*Func, []Type) {
+	fun := call.Fun
+	var typeArgs []Type
+	switch e := fun.(type) {
+	case *ast.IndexExpr:
+		fun, typeArgs = e.X, []Type{{File: f, Expr: e.Index}}
+	case *ast.IndexListExpr:
+		fun = e.X
+		for _, index := range e.Indices {
+			typeArgs = append(typeArgs, Type{File: f, Expr: index})
+		}
+	}
+	return s.FuncValue(f, fun), typeArgs
+}
+
+// FuncValue resolves a function or method value, e.g. h.Create or middleware.Bind
+func (s *Scanner) FuncValue(f *File, expr ast.Expr) *Func {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return s.FuncValue(f, e.X)
+	case *ast.Ident:
+		if e.Obj != nil && e.Obj.Kind != ast.Fun {
+			return nil
+		}
+		return f.Package.Funcs[e.Name]
+	case *ast.SelectorExpr:
+		if importPath, name, ok := s.SelectorPackage(f, e); ok {
+			if pkg := s.LookupPackage(importPath); pkg != nil {
+				return pkg.Funcs[name]
+			}
+			return nil
+		}
+		t, ok := s.typeOf(f, e.X, 0)
+		if !ok {
+			return nil
+		}
+		if spec, _ := s.NamedType(t); spec != nil {
+			return spec.File.Package.Funcs[spec.Spec.Name.Name+"."+e.Sel.Name]
+		}
+	}
+	return nil
+}
+
+// UnwrapIndex returns the generic function of an instantiation, e.g. Bind of Bind[T]
+func UnwrapIndex(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return e.X
+	case *ast.IndexListExpr:
+		return e.X
+	}
+	return expr
+}
+
+// TypeOf infers the type of an expression of a file
+func (s *Scanner) TypeOf(f *File, expr ast.Expr) (Type, bool) {
+	return s.typeOf(f, expr, 0)
+}
+
+// typeOf infers the type of an expression from the declarations of its identifiers, the
+// results of the functions it calls and the fields it selects
+func (s *Scanner) typeOf(f *File, expr ast.Expr, depth int) (Type, bool) {
+	if depth > 12 {
+		return Type{}, false
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return s.typeOf(f, e.X, depth+1)
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return Type{File: f, Expr: e.Type}, true
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return s.typeOf(f, e.X, depth+1)
+		}
+	case *ast.StarExpr:
+		return s.typeOf(f, e.X, depth+1)
+	case *ast.Ident:
+		if e.Obj == nil {
+			return Type{}, false
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.Field:
+			return Type{File: f, Expr: decl.Type}, true
+		case *ast.ValueSpec:
+			if decl.Type != nil {
+				return Type{File: f, Expr: decl.Type}, true
+			}
+			if value, index := assignedValue(decl, e); value != nil {
+				return s.valueType(f, value, index, depth)
+			}
+		case *ast.AssignStmt:
+			if value, index := assignedValue(decl, e); value != nil {
+				return s.valueType(f, value, index, depth)
+			}
+		}
+	case *ast.CallExpr:
+		return s.resultType(f, e, 0, depth)
+	case *ast.SelectorExpr:
+		if _, _, ok := s.SelectorPackage(f, e); ok {
+			return Type{}, false
+		}
+		t, ok := s.typeOf(f, e.X, depth+1)
+		if !ok {
+			return Type{}, false
+		}
+		return s.fieldType(t, e.Sel.Name)
+	case *ast.IndexExpr:
+		t, ok := s.typeOf(f, e.X, depth+1)
+		if !ok {
+			return Type{}, false
+		}
+		switch elem := s.Underlying(t).Expr.(type) {
+		case *ast.ArrayType:
+			return Type{File: t.File, Expr: elem.Elt, Args: t.Args}, true
+		case *ast.MapType:
+			return Type{File: t.File, Expr: elem.Value, Args: t.Args}, true
+		}
+	}
+	return Type{}, false
+}
+
+// valueType returns the type of a value assigned to an identifier, or of one of the results
+// of a call
+func (s *Scanner) valueType(f *File, value ast.Expr, index, depth int) (Type, bool) {
+	if index < 0 {
+		return s.typeOf(f, value, depth+1)
+	}
+	if call, ok := value.(*ast.CallExpr); ok {
+		return s.resultType(f, call, index, depth+1)
+	}
+	return Type{}, false
+}
+
+// ResultType returns the type of a result of a call
+func (s *Scanner) ResultType(f *File, call *ast.CallExpr, index int) (Type, bool) {
+	return s.resultType(f, call, index, 0)
+}
+
+// resultType returns the type of a result of a call, with the type arguments of generic
+// functions substituted, e.g. entity.Product for cqrs.Dispatch[entity.Product](...)
+func (s *Scanner) resultType(f *File, call *ast.CallExpr, index, depth int) (Type, bool) {
+	fn, typeArgs := s.Callee(f, call)
+	if fn == nil || fn.Decl.Type.Results == nil {
+		return Type{}, false
+	}
+	var results []ast.Expr
+	for _, field := range fn.Decl.Type.Results.List {
+		for range max(len(field.Names), 1) {
+			results = append(results, field.Type)
+		}
+	}
+	if index >= len(results) {
+		return Type{}, false
+	}
+	t := Type{File: fn.File, Expr: results[index]}
+	if params := fn.Decl.Type.TypeParams; params != nil && len(typeArgs) > 0 {
+		t.Args = make(map[string]Type)
+		i := 0
+		for _, field := range params.List {
+			for _, name := range field.Names {
+				if i < len(typeArgs) {
+					t.Args[name.Name] = typeArgs[i]
+				}
+				i++
+			}
+		}
+	}
+	return t, true
+}
+
+// fieldType returns the type of a field of a struct type
+func (s *Scanner) fieldType(t Type, name string) (Type, bool) {
+	st := s.Underlying(t)
+	structType, ok := st.Expr.(*ast.StructType)
+	if !ok {
+		return Type{}, false
+	}
+	for _, field := range structType.Fields.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return Type{File: st.File, Expr: field.Type, Args: st.Args}, true
+			}
+		}
+		if len(field.Names) == 0 && BaseTypeName(field.Type) == name {
+			return Type{File: st.File, Expr: field.Type, Args: st.Args}, true
+		}
+	}
+	return Type{}, false
+}
+
+// NamedType resolves the declaration of a named type, dereferencing pointers, with the type
+// arguments of generic types
+func (s *Scanner) NamedType(t Type) (*TypeSpec, map[string]Type) {
+	for range 12 {
+		switch e := t.Expr.(type) {
+		case *ast.ParenExpr:
+			t.Expr = e.X
+			continue
+		case *ast.StarExpr:
+			t.Expr = e.X
+			continue
+		case *ast.Ident:
+			if arg, ok := t.Args[e.Name]; ok {
+				t = arg
+				continue
+			}
+			return t.File.Package.Types[e.Name], nil
+		case *ast.SelectorExpr:
+			importPath, name, ok := s.SelectorPackage(t.File, e)
+			if !ok {
+				return nil, nil
+			}
+			if pkg := s.LookupPackage(importPath); pkg != nil {
+				return pkg.Types[name], nil
+			}
+			return nil, nil
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			spec, _ := s.NamedType(Type{File: t.File, Expr: UnwrapIndex(e), Args: t.Args})
+			if spec == nil || spec.Spec.TypeParams == nil {
+				return spec, nil
+			}
+			var indices []ast.Expr
+			if index, ok := e.(*ast.IndexExpr); ok {
+				indices = []ast.Expr{index.Index}
+			} else {
+				indices = e.(*ast.IndexListExpr).Indices
+			}
+			args := make(map[string]Type)
+			i := 0
+			for _, field := range spec.Spec.TypeParams.List {
+				for _, name := range field.Names {
+					if i < len(indices) {
+						args[name.Name] = Type{File: t.File, Expr: indices[i], Args: t.Args}
+					}
+					i++
+				}
+			}
+			return spec, args
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+// Underlying returns the type expression a type is declared with, e.g. the struct of a named
+// struct type
+func (s *Scanner) Underlying(t Type) Type {
+	for range 12 {
+		if star, ok := t.Expr.(*ast.StarExpr); ok {
+			t.Expr = star.X
+			continue
+		}
+		if ident, ok := t.Expr.(*ast.Ident); ok {
+			if arg, ok := t.Args[ident.Name]; ok {
+				t = arg
+				continue
+			}
+		}
+		spec, args := s.NamedType(t)
+		if spec == nil {
+			return t
+		}
+		t = Type{File: spec.File, Expr: spec.Spec.Type, Args: args}
+	}
+	return t
+}
+
+// stringValue returns the value of a string expression made of literals and of the constants
+// and variables they are declared with, e.g. basePath + "/:id"
+func (s *Scanner) stringValue(f *File, expr ast.Expr, depth int) (string, bool) {
+	if depth > 8 {
+		return "", false
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return StringLiteral(e)
+	case *ast.ParenExpr:
+		return s.stringValue(f, e.X, depth+1)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := s.stringValue(f, e.X, depth+1)
+		if !ok {
+			return "", false
+		}
+		y, ok := s.stringValue(f, e.Y, depth+1)
+		return x + y, ok
+	case *ast.Ident:
+		if e.Obj != nil {
+			if value, index := assignedValue(e.Obj.Decl, e); value != nil && index < 0 {
+				return s.stringValue(f, value, depth+1)
+			}
+			return "", false
+		}
+		// Identifiers declared in another file of the package are not resolved by the parser
+		if v, ok := f.Package.values[e.Name]; ok {
+			return s.stringValue(v.file, v.expr, depth+1)
+		}
+	case *ast.SelectorExpr:
+		importPath, name, ok := s.SelectorPackage(f, e)
+		if !ok {
+			return "", false
+		}
+		if pkg := s.LookupPackage(importPath); pkg != nil {
+			if v, ok := pkg.values[name]; ok {
+				return s.stringValue(v.file, v.expr, depth+1)
+			}
+		}
+	}
+	return "", false
+}
+
+// StringLiteral returns the value of a string literal
+func StringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// literalValue returns the value of a string, integer or float literal
+func literalValue(expr ast.Expr) (any, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok {
+		return nil, false
+	}
+	switch lit.Kind {
+	case token.STRING:
+		value, err := strconv.Unquote(lit.Value)
+		return value, err == nil
+	case token.INT:
+		value, err := strconv.ParseInt(lit.Value, 0, 64)
+		return value, err == nil
+	case token.FLOAT:
+		value, err := strconv.ParseFloat(lit.Value, 64)
+		return value, err == nil
+	}
+	return nil, false
+}
+
+// joinRoute joins a route path to the prefix of its group, like Fiber
+func joinRoute(prefix, route string) string {
+	joined := strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(route, "/")
+	if len(joined) > 1 {
+		joined = strings.TrimSuffix(joined, "/")
+	}
+	return joined
+}
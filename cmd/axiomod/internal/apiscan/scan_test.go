@@ -0,0 +1,223 @@
+package apiscan
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scanRoutes writes files into a new module and returns its routes as "method path"
+func scanRoutes(t *testing.T, files map[string]string) []string {
+	t.Helper()
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/api\n\ngo 1.24\n"
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	scanner := New(root, "example.com/api")
+	require.NoError(t, scanner.Load())
+	var routes []string
+	for _, route := range scanner.Routes() {
+		routes = append(routes, route.Method+" "+route.Path)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "literal paths",
+			files: map[string]string{"main.go": `package main
+
+import "github.com/gofiber/fiber/v2"
+
+func main() {
+	app := fiber.New()
+	app.Get("/users", list)
+	app.Post("/users/", create)
+	app.Delete("/", remove)
+}
+`},
+			want: []string{"delete /", "get /users", "post /users"},
+		},
+		{
+			name: "constant and variable paths",
+			files: map[string]string{
+				"main.go": `package main
+
+import "github.com/gofiber/fiber/v2"
+
+const orders = "/orders"
+
+func main() {
+	app := fiber.New()
+	item := orders + "/:id"
+	app.Get(orders, list)
+	app.Put(item, update)
+	app.Patch(itemPath, update)
+}
+`,
+				"paths.go": `package main
+
+var itemPath = orders + "/:id/status"
+`,
+			},
+			want: []string{"get /orders", "patch /orders/:id/status", "put /orders/:id"},
+		},
+		{
+			name: "constant of another package",
+			files: map[string]string{
+				"main.go": `package main
+
+import (
+	"example.com/api/routes"
+	"github.com/gofiber/fiber/v2"
+)
+
+func main() {
+	fiber.New().Get(routes.Health, health)
+}
+`,
+				"routes/routes.go": `package routes
+
+const Health = "/health"
+`,
+			},
+			want: []string{"get /health"},
+		},
+		{
+			name: "group prefixes",
+			files: map[string]string{"main.go": `package main
+
+import "github.com/gofiber/fiber/v2"
+
+func main() {
+	app := fiber.New()
+	api := app.Group("/api")
+	v1 := api.Group("/v1")
+	v1.Get("/items", list)
+	api.Group("/v2").Get("/items/:id", get)
+}
+`},
+			want: []string{"get /api/v1/items", "get /api/v2/items/:id"},
+		},
+		{
+			name: "routers given to other functions",
+			files: map[string]string{
+				"main.go": `package main
+
+import (
+	"example.com/api/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func main() {
+	app := fiber.New()
+	h := handler.New()
+	h.RegisterRoutes(app.Group("/api/v1"))
+	h.RegisterRoutes(app.Group("/api/v2"))
+}
+`,
+				"handler/handler.go": `package handler
+
+import "github.com/gofiber/fiber/v2"
+
+type Handler struct{}
+
+func New() *Handler { return &Handler{} }
+
+func (h *Handler) RegisterRoutes(router fiber.Router) {
+	products := router.Group("/products")
+	products.Get("/:id", h.Get)
+}
+
+func (h *Handler) Get(c *fiber.Ctx) error { return nil }
+`,
+			},
+			want: []string{"get /api/v1/products/:id", "get /api/v2/products/:id"},
+		},
+		{
+			name: "handlers registered on the HTTP server",
+			files: map[string]string{"handler/handler.go": `package handler
+
+import (
+	"github.com/axiomod/axiomod/platform/server"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Options(server.AsHTTPRoutes[*Handler]("/api/v1"))
+
+type Handler struct{}
+
+func (h *Handler) RegisterRoutes(router fiber.Router) {
+	router.Post("/carts", h.Create)
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error { return nil }
+`},
+			want: []string{"post /api/v1/carts"},
+		},
+		{
+			name: "paths that are not constants",
+			files: map[string]string{"main.go": `package main
+
+import (
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func main() {
+	app := fiber.New()
+	app.Get(os.Getenv("ROUTE"), list)
+	app.Group(os.Getenv("PREFIX")).Get("/items", list)
+}
+`},
+		},
+		{
+			name: "net/http routes",
+			files: map[string]string{"main.go": `package main
+
+import "net/http"
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", list)
+	http.Handle("/health", health)
+}
+`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, scanRoutes(t, tt.files))
+		})
+	}
+}
+
+func TestJoinRoute(t *testing.T) {
+	tests := []struct{ prefix, route, want string }{
+		{"", "/", "/"},
+		{"", "users", "/users"},
+		{"/api", "/users", "/api/users"},
+		{"/api/", "/users/", "/api/users"},
+		{"/api", "/", "/api"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, joinRoute(tt.prefix, tt.route), "%q + %q", tt.prefix, tt.route)
+	}
+}
@@ -0,0 +1,263 @@
+// Package benchcmp compares the results of Go benchmarks against a baseline, in the manner
+// of benchstat: samples are summarized by their median and spread, and changes are only
+// reported as such when a Mann-Whitney U test finds them significant.
+package benchcmp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Units reported by go test -benchmem, in the order they are reported
+const (
+	UnitTime   = "ns/op"
+	UnitBytes  = "B/op"
+	UnitAllocs = "allocs/op"
+)
+
+// Alpha is the significance level under which a change is reported
+const Alpha = 0.05
+
+// procsSuffix is the GOMAXPROCS suffix of benchmark names, dropped so that baselines
+// recorded on machines with different numbers of CPUs still match
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// Benchmark holds the samples of a benchmark by unit
+type Benchmark struct {
+	// Name is the base name of the package and the name of the benchmark, e.g. errors/Wrap
+	Name    string
+	Samples map[string][]float64
+}
+
+// Results are the benchmarks of go test output, in the order they first appear
+type Results struct {
+	Benchmarks []*Benchmark
+	byName     map[string]*Benchmark
+}
+
+// Get returns the benchmark named name
+func (r *Results) Get(name string) (*Benchmark, bool) {
+	b, ok := r.byName[name]
+	return b, ok
+}
+
+// Parse reads the benchmarks of go test -bench output; runs of a benchmark with -count
+// add samples to it
+func Parse(r io.Reader) (*Results, error) {
+	results := &Results{byName: make(map[string]*Benchmark)}
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = path.Base(strings.TrimSpace(p))
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+
+		name := procsSuffix.ReplaceAllString(strings.TrimPrefix(fields[0], "Benchmark"), "")
+		if pkg != "" {
+			name = pkg + "/" + name
+		}
+		b, ok := results.byName[name]
+		if !ok {
+			b = &Benchmark{Name: name, Samples: make(map[string][]float64)}
+			results.byName[name] = b
+			results.Benchmarks = append(results.Benchmarks, b)
+		}
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q of %s: %w", fields[i], fields[0], err)
+			}
+			unit := fields[i+1]
+			b.Samples[unit] = append(b.Samples[unit], value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Summary describes the samples of a benchmark in a unit
+type Summary struct {
+	Median float64 `json:"median"`
+	// Spread is the largest deviation of a sample from the median, relative to it
+	Spread float64 `json:"spread"`
+	N      int     `json:"n"`
+}
+
+// Summarize returns the median and the spread of samples
+func Summarize(samples []float64) Summary {
+	if len(samples) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	spread := 0.0
+	if median != 0 {
+		spread = math.Max(median-sorted[0], sorted[n-1]-median) / median
+	}
+	return Summary{Median: median, Spread: spread, N: n}
+}
+
+// Row compares a benchmark in a unit
+type Row struct {
+	Name string  `json:"name"`
+	Unit string  `json:"unit"`
+	Old  Summary `json:"old"`
+	New  Summary `json:"new"`
+	// Delta is the change of the median, relative to the old one
+	Delta float64 `json:"delta"`
+	// P is the p-value of the Mann-Whitney U test of the samples
+	P           float64 `json:"p"`
+	Significant bool    `json:"significant"`
+	// Regression is set for significant increases beyond the threshold
+	Regression bool `json:"regression"`
+}
+
+// Comparison compares results against a baseline
+type Comparison struct {
+	Rows []Row `json:"rows"`
+	// Threshold is the increase of a median, relative to the baseline, above which a
+	// significant change is a regression
+	Threshold float64 `json:"threshold"`
+	// Added are the benchmarks missing from the baseline
+	Added []string `json:"added,omitempty"`
+}
+
+// Compare compares the benchmarks of results present in baseline. Every unit measures a
+// cost, so increases beyond threshold, e.g. 0.1 for 10%, are regressions.
+func Compare(baseline, results *Results, threshold float64) *Comparison {
+	c := &Comparison{Threshold: threshold}
+	for _, b := range results.Benchmarks {
+		old, ok := baseline.Get(b.Name)
+		if !ok {
+			c.Added = append(c.Added, b.Name)
+			continue
+		}
+		for _, unit := range units(b) {
+			oldSamples, ok := old.Samples[unit]
+			if !ok {
+				continue
+			}
+			row := Row{
+				Name: b.Name,
+				Unit: unit,
+				Old:  Summarize(oldSamples),
+				New:  Summarize(b.Samples[unit]),
+				P:    MannWhitneyU(oldSamples, b.Samples[unit]),
+			}
+			if row.Old.Median != 0 {
+				row.Delta = (row.New.Median - row.Old.Median) / row.Old.Median
+			}
+			row.Significant = row.P < Alpha && row.Old.Median != row.New.Median
+			row.Regression = row.Significant && row.Delta > threshold
+			c.Rows = append(c.Rows, row)
+		}
+	}
+	return c
+}
+
+// Regressions returns the rows of regressions
+func (c *Comparison) Regressions() []Row {
+	var regressions []Row
+	for _, row := range c.Rows {
+		if row.Regression {
+			regressions = append(regressions, row)
+		}
+	}
+	return regressions
+}
+
+// units returns the units of a benchmark, the -benchmem ones first
+func units(b *Benchmark) []string {
+	var units []string
+	for _, unit := range []string{UnitTime, UnitBytes, UnitAllocs} {
+		if _, ok := b.Samples[unit]; ok {
+			units = append(units, unit)
+		}
+	}
+	var custom []string
+	for unit := range b.Samples {
+		if unit != UnitTime && unit != UnitBytes && unit != UnitAllocs {
+			custom = append(custom, unit)
+		}
+	}
+	sort.Strings(custom)
+	return append(units, custom...)
+}
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of x and y, with the
+// normal approximation corrected for ties. It is 1 when either has no samples or all samples
+// are equal.
+func MannWhitneyU(x, y []float64) float64 {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type sample struct {
+		value float64
+		first bool
+	}
+	all := make([]sample, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range y {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Rank the samples, ties sharing their mean rank
+	rankSum, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	u := rankSum - n1*(n1+1)/2
+	n := n1 + n2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	// Continuity correction
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		return 1
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
@@ -0,0 +1,178 @@
+package benchcmp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteResults writes the median and spread of each benchmark, one table per unit
+func WriteResults(w io.Writer, results *Results) error {
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	for i, unit := range resultUnits(results) {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "name\t%s\n", metricName(unit))
+		for _, b := range results.Benchmarks {
+			samples, ok := b.Samples[unit]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", b.Name, formatSummary(Summarize(samples), unit))
+		}
+	}
+	return writeTable(w, tw, &table)
+}
+
+// WriteComparison writes the comparison in the tables of benchstat, one per unit: the old
+// and new medians with their spread, then the change, or "~" when it is not significant
+func WriteComparison(w io.Writer, c *Comparison) error {
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	for i, unit := range rowUnits(c.Rows) {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		metric := metricName(unit)
+		fmt.Fprintf(tw, "name\told %s\tnew %s\tdelta\t\n", metric, metric)
+		for _, row := range c.Rows {
+			if row.Unit != unit {
+				continue
+			}
+			delta := "~"
+			if row.Significant {
+				delta = fmt.Sprintf("%+.2f%%", row.Delta*100)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t(p=%.3f n=%d+%d)\n", row.Name,
+				formatSummary(row.Old, unit), formatSummary(row.New, unit), delta, row.P, row.Old.N, row.New.N)
+		}
+	}
+	if err := writeTable(w, tw, &table); err != nil {
+		return err
+	}
+
+	if len(c.Added) > 0 {
+		fmt.Fprintf(w, "\nNot in the baseline: %v\n", c.Added)
+	}
+	if regressions := c.Regressions(); len(regressions) > 0 {
+		fmt.Fprintf(w, "\nRegressions beyond %.0f%%:\n", c.Threshold*100)
+		for _, row := range regressions {
+			fmt.Fprintf(w, "  %s %s: %s -> %s (%+.2f%%)\n", row.Name, metricName(row.Unit),
+				formatValue(row.Old.Median, row.Unit), formatValue(row.New.Median, row.Unit), row.Delta*100)
+		}
+	}
+	return nil
+}
+
+// writeTable flushes the tabwriter of a table and writes the table to w, without the padding
+// the tabwriter leaves at the end of lines
+func writeTable(w io.Writer, tw *tabwriter.Writer, table *bytes.Buffer) error {
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// resultUnits returns the units of the benchmarks of results, in the order of units
+func resultUnits(results *Results) []string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, b := range results.Benchmarks {
+		for _, unit := range units(b) {
+			if !seen[unit] {
+				seen[unit] = true
+				all = append(all, unit)
+			}
+		}
+	}
+	return all
+}
+
+// rowUnits returns the units of rows, in the order they appear
+func rowUnits(rows []Row) []string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, row := range rows {
+		if !seen[row.Unit] {
+			seen[row.Unit] = true
+			all = append(all, row.Unit)
+		}
+	}
+	return all
+}
+
+// metricName returns the name of the metric of a unit, as benchstat names it
+func metricName(unit string) string {
+	switch unit {
+	case UnitTime:
+		return "time/op"
+	case UnitBytes:
+		return "alloc/op"
+	default:
+		return unit
+	}
+}
+
+// formatSummary formats a median with its spread, e.g. 6.19µs ± 2%
+func formatSummary(s Summary, unit string) string {
+	return fmt.Sprintf("%s ± %.0f%%", formatValue(s.Median, unit), s.Spread*100)
+}
+
+// formatValue formats a value of a unit with three significant digits and a scaled unit
+func formatValue(v float64, unit string) string {
+	switch unit {
+	case UnitTime:
+		switch {
+		case v >= 1e9:
+			return significant(v/1e9) + "s"
+		case v >= 1e6:
+			return significant(v/1e6) + "ms"
+		case v >= 1e3:
+			return significant(v/1e3) + "µs"
+		default:
+			return significant(v) + "ns"
+		}
+	case UnitBytes:
+		switch {
+		case v >= 1e9:
+			return significant(v/1e9) + "GB"
+		case v >= 1e6:
+			return significant(v/1e6) + "MB"
+		case v >= 1e3:
+			return significant(v/1e3) + "kB"
+		default:
+			return significant(v) + "B"
+		}
+	default:
+		switch {
+		case v >= 1e6:
+			return significant(v/1e6) + "M"
+		case v >= 1e3:
+			return significant(v/1e3) + "k"
+		default:
+			return significant(v)
+		}
+	}
+}
+
+// significant formats v with three significant digits
+func significant(v float64) string {
+	switch a := math.Abs(v); {
+	case a >= 100:
+		return fmt.Sprintf("%.0f", v)
+	case a >= 10:
+		return fmt.Sprintf("%.1f", v)
+	default:
+		return fmt.Sprintf("%.2f", v)
+	}
+}
@@ -0,0 +1,691 @@
+// Package protoscan parses the declarations of .proto files that matter to their wire and
+// generated code compatibility: packages, messages with their fields and reserved numbers,
+// enums and services. Options, extensions and comments are skipped.
+package protoscan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field labels; fields declared without one are LabelNone
+const (
+	LabelNone     = ""
+	LabelOptional = "optional"
+	LabelRequired = "required"
+	LabelRepeated = "repeated"
+	LabelMap      = "map"
+)
+
+// maxFieldNumber is the number of "reserved 10 to max"
+const maxFieldNumber = 536870911
+
+// File is a parsed .proto file
+type File struct {
+	Path        string
+	Package     string
+	PackageLine int
+	Messages    []*Message
+	Enums       []*Enum
+	Services    []*Service
+}
+
+// Message is a message declaration, with its nested declarations
+type Message struct {
+	Name     string
+	FullName string // package and enclosing messages, e.g. shop.v1.Order.Item
+	Line     int
+	Fields   []*Field
+	Messages []*Message
+	Enums    []*Enum
+	Reserved Reserved
+}
+
+// Field is a field of a message. Type is the scalar type or the full name of the message or enum,
+// once resolved; map fields have the type map<key, value>.
+type Field struct {
+	Name   string
+	Number int
+	Type   string
+	Label  string
+	Oneof  string // name of the oneof holding the field, if any
+	Line   int
+}
+
+// Enum is an enum declaration
+type Enum struct {
+	Name     string
+	FullName string
+	Line     int
+	Values   []*EnumValue
+	Reserved Reserved
+}
+
+// EnumValue is a value of an enum
+type EnumValue struct {
+	Name   string
+	Number int
+	Line   int
+}
+
+// Service is a service declaration
+type Service struct {
+	Name     string
+	FullName string
+	Line     int
+	Methods  []*Method
+}
+
+// Method is an RPC of a service. Input and Output are full names once resolved.
+type Method struct {
+	Name            string
+	Input           string
+	Output          string
+	ClientStreaming bool
+	ServerStreaming bool
+	Line            int
+}
+
+// Reserved holds the reserved numbers and names of a message or an enum
+type Reserved struct {
+	Ranges [][2]int // inclusive
+	Names  []string
+}
+
+// HasNumber reports whether a number is reserved
+func (r Reserved) HasNumber(number int) bool {
+	for _, span := range r.Ranges {
+		if number >= span[0] && number <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasName reports whether a name is reserved
+func (r Reserved) HasName(name string) bool {
+	for _, reserved := range r.Names {
+		if reserved == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses the source of a .proto file
+func Parse(path string, src []byte) (*File, error) {
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p := &parser{tokens: tokens, file: &File{Path: path}}
+	if err := p.parseFile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p.file, nil
+}
+
+// token is a lexical token of a .proto file: an identifier, a number, a string or a symbol
+type token struct {
+	text   string
+	line   int
+	string bool
+}
+
+// tokenize splits the source into tokens, without comments and whitespace
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(src) && src[i] != c {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && src[i] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			i++
+			tokens = append(tokens, token{text: src[start+1 : i-1], line: line, string: true})
+		case isIdentChar(c) || c == '.' && i+1 < len(src) && isIdentChar(src[i+1]):
+			// Identifiers keep their dots, so full names and numbers such as 1.5 are one token
+			start := i
+			for i < len(src) && (isIdentChar(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{text: src[start:i], line: line})
+		default:
+			tokens = append(tokens, token{text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// isIdentChar reports whether a byte may be part of an identifier or a number
+func isIdentChar(c byte) bool {
+	return c == '_' || c < 0x80 && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+// parser is a recursive descent parser over the tokens of a file
+type parser struct {
+	tokens []token
+	pos    int
+	file   *File
+}
+
+// peek returns the text of the next token, or "" at the end of the file
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+// line returns the line of the next token
+func (p *parser) line() int {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].line
+	}
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1].line
+	}
+	return 1
+}
+
+// next consumes and returns the next token
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of file")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+// expect consumes the next token, which must be text
+func (p *parser) expect(text string) error {
+	line := p.line()
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.text != text || t.string {
+		return fmt.Errorf("line %d: expected %q, got %q", line, text, t.text)
+	}
+	return nil
+}
+
+// ident consumes an identifier, possibly qualified
+func (p *parser) ident() (string, error) {
+	line := p.line()
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.string || t.text == "" || !isIdentChar(t.text[0]) && t.text[0] != '.' {
+		return "", fmt.Errorf("line %d: expected an identifier, got %q", line, t.text)
+	}
+	return t.text, nil
+}
+
+// number consumes an integer, possibly negative
+func (p *parser) number() (int, error) {
+	line := p.line()
+	sign := 1
+	if p.peek() == "-" {
+		p.pos++
+		sign = -1
+	}
+	t, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(t.text, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: expected a number, got %q", line, t.text)
+	}
+	return sign * int(n), nil
+}
+
+// skipStatement skips to the end of a statement, past its ";" or its braced block
+func (p *parser) skipStatement() error {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.string {
+			continue
+		}
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// skipOptions skips the [...] options of a field or an enum value, if any
+func (p *parser) skipOptions() error {
+	if p.peek() != "[" {
+		return nil
+	}
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.string {
+			continue
+		}
+		switch t.text {
+		case "[":
+			depth++
+		case "]":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// qualify returns the full name of a declaration in a scope
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// parseFile parses the top-level statements of the file
+func (p *parser) parseFile() error {
+	for p.pos < len(p.tokens) {
+		switch p.peek() {
+		case "package":
+			p.file.PackageLine = p.line()
+			p.pos++
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			p.file.Package = name
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "message":
+			message, err := p.parseMessage(p.file.Package)
+			if err != nil {
+				return err
+			}
+			p.file.Messages = append(p.file.Messages, message)
+		case "enum":
+			enum, err := p.parseEnum(p.file.Package)
+			if err != nil {
+				return err
+			}
+			p.file.Enums = append(p.file.Enums, enum)
+		case "service":
+			service, err := p.parseService()
+			if err != nil {
+				return err
+			}
+			p.file.Services = append(p.file.Services, service)
+		case ";":
+			p.pos++
+		default:
+			// syntax, edition, import, option and extend statements
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseMessage parses a message declaration in a scope
+func (p *parser) parseMessage(scope string) (*Message, error) {
+	message := &Message{Line: p.line()}
+	if err := p.expect("message"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	message.Name, message.FullName = name, qualify(scope, name)
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.parseMessageBody(message, ""); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// parseMessageBody parses the statements of a message, or of a oneof of it, up to its "}"
+func (p *parser) parseMessageBody(message *Message, oneof string) error {
+	for {
+		switch p.peek() {
+		case "":
+			return fmt.Errorf("line %d: unterminated message %s", message.Line, message.Name)
+		case "}":
+			p.pos++
+			return nil
+		case ";":
+			p.pos++
+		case "message":
+			nested, err := p.parseMessage(message.FullName)
+			if err != nil {
+				return err
+			}
+			message.Messages = append(message.Messages, nested)
+		case "enum":
+			nested, err := p.parseEnum(message.FullName)
+			if err != nil {
+				return err
+			}
+			message.Enums = append(message.Enums, nested)
+		case "oneof":
+			p.pos++
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(message, name); err != nil {
+				return err
+			}
+		case "reserved":
+			p.pos++
+			if err := p.parseReserved(&message.Reserved); err != nil {
+				return err
+			}
+		case "option", "extensions", "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			field, err := p.parseField(message)
+			if err != nil {
+				return err
+			}
+			if field != nil {
+				field.Oneof = oneof
+				message.Fields = append(message.Fields, field)
+			}
+		}
+	}
+}
+
+// parseField parses a field, a map field or a proto2 group of a message
+func (p *parser) parseField(message *Message) (*Field, error) {
+	field := &Field{Line: p.line()}
+	switch p.peek() {
+	case LabelOptional, LabelRequired, LabelRepeated:
+		field.Label = p.peek()
+		p.pos++
+	}
+
+	if p.peek() == "map" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "<" {
+		p.pos += 2
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		value, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		field.Label = LabelMap
+		field.Type = "map<" + key + ", " + value + ">"
+	} else {
+		typ, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		field.Type = typ
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if field.Number, err = p.number(); err != nil {
+		return nil, err
+	}
+	if err := p.skipOptions(); err != nil {
+		return nil, err
+	}
+
+	if field.Type == "group" {
+		// A group declares a nested message named after it and a field of that type
+		group := &Message{Name: field.Name, FullName: qualify(message.FullName, field.Name), Line: field.Line}
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		if err := p.parseMessageBody(group, ""); err != nil {
+			return nil, err
+		}
+		message.Messages = append(message.Messages, group)
+		field.Type, field.Name = group.FullName, strings.ToLower(field.Name)
+		return field, nil
+	}
+	return field, p.expect(";")
+}
+
+// parseReserved parses the numbers, ranges and names of a reserved statement
+func (p *parser) parseReserved(reserved *Reserved) error {
+	for {
+		line := p.line()
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.string:
+			reserved.Names = append(reserved.Names, t.text)
+		case t.text == ";":
+			return nil
+		case t.text == ",":
+		default:
+			p.pos--
+			from, err := p.number()
+			if err != nil {
+				return fmt.Errorf("line %d: invalid reserved statement", line)
+			}
+			to := from
+			if p.peek() == "to" {
+				p.pos++
+				if p.peek() == "max" {
+					p.pos++
+					to = maxFieldNumber
+				} else if to, err = p.number(); err != nil {
+					return err
+				}
+			}
+			reserved.Ranges = append(reserved.Ranges, [2]int{from, to})
+		}
+	}
+}
+
+// parseEnum parses an enum declaration in a scope
+func (p *parser) parseEnum(scope string) (*Enum, error) {
+	enum := &Enum{Line: p.line()}
+	if err := p.expect("enum"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	enum.Name, enum.FullName = name, qualify(scope, name)
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "":
+			return nil, fmt.Errorf("line %d: unterminated enum %s", enum.Line, enum.Name)
+		case "}":
+			p.pos++
+			return enum, nil
+		case ";":
+			p.pos++
+		case "option":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case "reserved":
+			p.pos++
+			if err := p.parseReserved(&enum.Reserved); err != nil {
+				return nil, err
+			}
+		default:
+			value := &EnumValue{Line: p.line()}
+			if value.Name, err = p.ident(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			if value.Number, err = p.number(); err != nil {
+				return nil, err
+			}
+			if err := p.skipOptions(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+			enum.Values = append(enum.Values, value)
+		}
+	}
+}
+
+// parseService parses a service declaration
+func (p *parser) parseService() (*Service, error) {
+	service := &Service{Line: p.line()}
+	p.pos++
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	service.Name, service.FullName = name, qualify(p.file.Package, name)
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "":
+			return nil, fmt.Errorf("line %d: unterminated service %s", service.Line, service.Name)
+		case "}":
+			p.pos++
+			return service, nil
+		case ";":
+			p.pos++
+		case "rpc":
+			method, err := p.parseMethod()
+			if err != nil {
+				return nil, err
+			}
+			service.Methods = append(service.Methods, method)
+		default:
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// parseMethod parses an rpc of a service
+func (p *parser) parseMethod() (*Method, error) {
+	method := &Method{Line: p.line()}
+	p.pos++
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	method.Name = name
+	if method.Input, method.ClientStreaming, err = p.parseMethodType(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if method.Output, method.ServerStreaming, err = p.parseMethodType(); err != nil {
+		return nil, err
+	}
+	if p.peek() == "{" {
+		// The options of the method
+		return method, p.skipStatement()
+	}
+	return method, p.expect(";")
+}
+
+// parseMethodType parses the (stream Type) of a method
+func (p *parser) parseMethodType() (string, bool, error) {
+	if err := p.expect("("); err != nil {
+		return "", false, err
+	}
+	stream := false
+	if p.peek() == "stream" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text != ")" {
+		stream = true
+		p.pos++
+	}
+	typ, err := p.ident()
+	if err != nil {
+		return "", false, err
+	}
+	return typ, stream, p.expect(")")
+}
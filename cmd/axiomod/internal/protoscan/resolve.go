@@ -0,0 +1,110 @@
+package protoscan
+
+import "strings"
+
+// scalarTypes are the field types that are not messages or enums
+var scalarTypes = map[string]bool{
+	"double": true, "float": true, "int32": true, "int64": true, "uint32": true, "uint64": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true, "sfixed32": true,
+	"sfixed64": true, "bool": true, "string": true, "bytes": true,
+}
+
+// Resolve replaces the type names of the fields and methods of files by the full names of the
+// messages and enums they refer to, following the scoping rules of protobuf. Names of types
+// declared outside the files, such as google.protobuf.Timestamp, are kept as written.
+func Resolve(files []*File) {
+	declared := make(map[string]bool)
+	for _, file := range files {
+		WalkMessages(file, func(message *Message) {
+			declared[message.FullName] = true
+			for _, enum := range message.Enums {
+				declared[enum.FullName] = true
+			}
+		})
+		for _, enum := range file.Enums {
+			declared[enum.FullName] = true
+		}
+	}
+
+	for _, file := range files {
+		WalkMessages(file, func(message *Message) {
+			for _, field := range message.Fields {
+				if key, value, ok := mapTypes(field.Type); ok {
+					field.Type = "map<" + key + ", " + resolveName(declared, message.FullName, value) + ">"
+				} else {
+					field.Type = resolveName(declared, message.FullName, field.Type)
+				}
+			}
+		})
+		for _, service := range file.Services {
+			for _, method := range service.Methods {
+				method.Input = resolveName(declared, file.Package, method.Input)
+				method.Output = resolveName(declared, file.Package, method.Output)
+			}
+		}
+	}
+}
+
+// WalkMessages calls fn for the messages of a file, nested messages included
+func WalkMessages(file *File, fn func(*Message)) {
+	var walk func(messages []*Message)
+	walk = func(messages []*Message) {
+		for _, message := range messages {
+			fn(message)
+			walk(message.Messages)
+		}
+	}
+	walk(file.Messages)
+}
+
+// resolveName returns the full name of a type referred to from a scope: the innermost
+// declaration of the scope or its parents that the name matches
+func resolveName(declared map[string]bool, scope, name string) string {
+	if scalarTypes[name] {
+		return name
+	}
+	if strings.HasPrefix(name, ".") {
+		return name[1:]
+	}
+	// The first component of the name is looked up, then the rest within it
+	first, rest, _ := strings.Cut(name, ".")
+	for {
+		candidate := qualify(scope, first)
+		if declared[candidate] || declaredPrefix(declared, candidate) {
+			if rest != "" {
+				candidate += "." + rest
+			}
+			if declared[candidate] {
+				return candidate
+			}
+		}
+		if scope == "" {
+			return name
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// declaredPrefix reports whether a package or a message is named prefix
+func declaredPrefix(declared map[string]bool, prefix string) bool {
+	for name := range declared {
+		if strings.HasPrefix(name, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// mapTypes returns the key and value types of a map<key, value> type
+func mapTypes(typ string) (key, value string, ok bool) {
+	inner, ok := strings.CutPrefix(typ, "map<")
+	if !ok {
+		return "", "", false
+	}
+	key, value, ok = strings.Cut(strings.TrimSuffix(inner, ">"), ", ")
+	return key, value, ok
+}
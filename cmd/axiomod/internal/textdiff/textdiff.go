@@ -0,0 +1,119 @@
+// Package textdiff writes the changes to text files as unified diffs.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a diff
+const diffContext = 3
+
+// Unified returns the change from old to new as a unified diff of the files from and to. from
+// is /dev/null for a created file.
+func Unified(from, to string, old, new []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+
+	edits := diffLines(splitLines(old), splitLines(new))
+	for start := 0; start < len(edits); {
+		// Find the next change, then the end of its hunk: the first run of unchanged lines
+		// long enough to separate it from the following change
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for i := first; i < len(edits); i++ {
+			if edits[i].op != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(last+diffContext+1, len(edits))
+		writeHunk(&b, edits, from, to)
+		start = to
+	}
+	return b.String()
+}
+
+// splitLines splits data into lines, without the newline ending the last one
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// lineEdit is a line of a diff: ' ' kept, '-' removed or '+' added
+type lineEdit struct {
+	op         byte
+	text       string
+	oldN, newN int // numbers of the line in the old and new file, before the edit
+}
+
+// writeHunk writes the edits from to to as a hunk
+func writeHunk(b *strings.Builder, edits []lineEdit, from, to int) {
+	var oldCount, newCount int
+	for _, e := range edits[from:to] {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+	oldStart, newStart := edits[from].oldN+1, edits[from].newN+1
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, e := range edits[from:to] {
+		fmt.Fprintf(b, "%c%s\n", e.op, e.text)
+	}
+}
+
+// diffLines returns the edits turning a into b, keeping their longest common subsequence
+func diffLines(a, b []string) []lineEdit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []lineEdit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, lineEdit{op: ' ', text: a[i], oldN: i, newN: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, lineEdit{op: '-', text: a[i], oldN: i, newN: j})
+			i++
+		default:
+			edits = append(edits, lineEdit{op: '+', text: b[j], oldN: i, newN: j})
+			j++
+		}
+	}
+	return edits
+}
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/router"
 	"github.com/axiomod/axiomod/framework/worker"
 	"github.com/axiomod/axiomod/platform/observability"
 	"github.com/axiomod/axiomod/platform/server"
@@ -55,6 +56,7 @@ func main() {
 
 		// Core platform modules
 		observability.Module,
+		router.Module,
 		server.Module,
 		plugins.Module,
 		worker.Module,
@@ -62,6 +64,7 @@ func main() {
 
 		// Register HTTP and gRPC servers
 		fx.Invoke(
+			server.RegisterRoutes,
 			server.RegisterHTTPServer,
 			server.RegisterGRPCServer,
 		),
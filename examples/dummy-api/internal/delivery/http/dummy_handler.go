@@ -17,7 +17,7 @@ func NewDummyHandler(logger *observability.Logger) *DummyHandler {
 	}
 }
 
-func (h *DummyHandler) RegisterRoutes(app *fiber.App) {
+func (h *DummyHandler) RegisterRoutes(app fiber.Router) {
 	h.logger.Info("Registering dummy API routes")
 
 	app.Get("/info", h.GetInfo)
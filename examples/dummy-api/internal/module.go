@@ -12,10 +12,6 @@ var Module = fx.Module(
 	fx.Provide(
 		http.NewDummyHandler,
 	),
-	fx.Invoke(registerRoutes),
-)
-
-func registerRoutes(handler *http.DummyHandler, s *server.HTTPServer) {
 	// Register routes with the framework's Fiber app
-	handler.RegisterRoutes(s.App)
-}
+	server.AsHTTPRoutes[*http.DummyHandler](""),
+)
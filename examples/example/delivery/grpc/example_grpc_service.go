@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/axiomod/axiomod/examples/example/usecase"
+	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
+	"github.com/axiomod/axiomod/framework/pagination"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"go.uber.org/zap"
@@ -15,6 +17,7 @@ import (
 type ExampleGRPCService struct {
 	createUseCase *usecase.CreateExampleUseCase
 	getUseCase    *usecase.GetExampleUseCase
+	listUseCase   *usecase.ListExamplesUseCase
 	logger        *observability.Logger
 	UnimplementedExampleServiceServer
 }
@@ -23,11 +26,13 @@ type ExampleGRPCService struct {
 func NewExampleGRPCService(
 	createUseCase *usecase.CreateExampleUseCase,
 	getUseCase *usecase.GetExampleUseCase,
+	listUseCase *usecase.ListExamplesUseCase,
 	logger *observability.Logger,
 ) *ExampleGRPCService {
 	return &ExampleGRPCService{
 		createUseCase: createUseCase,
 		getUseCase:    getUseCase,
+		listUseCase:   listUseCase,
 		logger:        logger,
 	}
 }
@@ -78,6 +83,37 @@ func (s *ExampleGRPCService) GetExample(ctx context.Context, req *GetExampleRequ
 	}, nil
 }
 
+// ListExamples handles the retrieval of a page of Examples via gRPC
+func (s *ExampleGRPCService) ListExamples(ctx context.Context, req *ListExamplesRequest) (*ListExamplesResponse, error) {
+	// Execute use case
+	output, err := s.listUseCase.Execute(ctx, usecase.ListExamplesInput{
+		Name:      req.Name,
+		ValueType: req.ValueType,
+		Tag:       req.Tag,
+		Page:      pagination.FromProto(req),
+	})
+	if err != nil {
+		s.logger.Error("Failed to list examples", zap.Error(err))
+		return nil, grpc_pkg.ToStatus(err).Err()
+	}
+
+	// Return response
+	resp := &ListExamplesResponse{NextPageToken: output.NextPageToken}
+	for _, item := range output.Items {
+		resp.Examples = append(resp.Examples, &GetExampleResponse{
+			Id:          item.ID,
+			Name:        item.Name,
+			Description: item.Description,
+			ValueType:   item.ValueType,
+			Count:       int32(item.Count),
+			Tags:        item.Tags,
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   item.UpdatedAt,
+		})
+	}
+	return resp, nil
+}
+
 // Note: In a real implementation, we would have generated gRPC service definitions
 // from protobuf files. For this example, we're defining placeholder types.
 
@@ -114,3 +150,34 @@ type GetExampleResponse struct {
 	CreatedAt   string   `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt   string   `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 }
+
+// ListExamplesRequest represents the request for listing examples
+type ListExamplesRequest struct {
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ValueType string `protobuf:"bytes,2,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	Tag       string `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	PageSize  int32  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+// GetPageSize returns the requested page size
+func (r *ListExamplesRequest) GetPageSize() int32 {
+	if r == nil {
+		return 0
+	}
+	return r.PageSize
+}
+
+// GetPageToken returns the token of the requested page
+func (r *ListExamplesRequest) GetPageToken() string {
+	if r == nil {
+		return ""
+	}
+	return r.PageToken
+}
+
+// ListExamplesResponse represents the response for listing examples
+type ListExamplesResponse struct {
+	Examples      []*GetExampleResponse `protobuf:"bytes,1,rep,name=examples,proto3" json:"examples,omitempty"`
+	NextPageToken string                `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
@@ -2,6 +2,8 @@ package http
 
 import (
 	"github.com/axiomod/axiomod/examples/example/usecase"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/pagination"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +14,7 @@ import (
 type ExampleHandler struct {
 	createUseCase *usecase.CreateExampleUseCase
 	getUseCase    *usecase.GetExampleUseCase
+	listUseCase   *usecase.ListExamplesUseCase
 	logger        *observability.Logger
 }
 
@@ -19,11 +22,13 @@ type ExampleHandler struct {
 func NewExampleHandler(
 	createUseCase *usecase.CreateExampleUseCase,
 	getUseCase *usecase.GetExampleUseCase,
+	listUseCase *usecase.ListExamplesUseCase,
 	logger *observability.Logger,
 ) *ExampleHandler {
 	return &ExampleHandler{
 		createUseCase: createUseCase,
 		getUseCase:    getUseCase,
+		listUseCase:   listUseCase,
 		logger:        logger,
 	}
 }
@@ -32,6 +37,7 @@ func NewExampleHandler(
 func (h *ExampleHandler) RegisterRoutes(router fiber.Router) {
 	group := router.Group("/examples")
 	group.Post("/", h.Create)
+	group.Get("/", h.List)
 	group.Get("/:id", h.Get)
 }
 
@@ -81,3 +87,32 @@ func (h *ExampleHandler) Get(c *fiber.Ctx) error {
 	// Return response
 	return c.Status(fiber.StatusOK).JSON(output)
 }
+
+// List handles the retrieval of a page of Examples
+func (h *ExampleHandler) List(c *fiber.Ctx) error {
+	// Get filters and page from query parameters
+	page, err := pagination.FromFiber(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	input := usecase.ListExamplesInput{
+		Name:      c.Query("name"),
+		ValueType: c.Query("valueType"),
+		Tag:       c.Query("tag"),
+		Page:      page,
+	}
+
+	// Execute use case
+	output, err := h.listUseCase.Execute(c.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to list examples", zap.Error(err))
+		return c.Status(errors.ToHTTPCode(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Return response
+	return c.Status(fiber.StatusOK).JSON(output)
+}
@@ -0,0 +1,31 @@
+package example
+
+import (
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// Error codes of the example module, returned by its HTTP and gRPC handlers
+var (
+	CodeExampleNotFound = errors.CodeDefinition{
+		Code:        "EXAMPLE_NOT_FOUND",
+		Kind:        errors.CodeNotFound,
+		Description: "No example exists with the requested ID",
+	}
+	CodeExampleDuplicateID = errors.CodeDefinition{
+		Code:        "EXAMPLE_DUPLICATE_ID",
+		Kind:        errors.CodeAlreadyExists,
+		Description: "An example with the same ID already exists",
+	}
+	CodeExampleInvalid = errors.CodeDefinition{
+		Code:        "EXAMPLE_INVALID",
+		Kind:        errors.CodeValidation,
+		Description: "The example has an empty name or description",
+	}
+)
+
+// ErrorCodes lists the error codes of the example module
+var ErrorCodes = []errors.CodeDefinition{
+	CodeExampleNotFound,
+	CodeExampleDuplicateID,
+	CodeExampleInvalid,
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/axiomod/axiomod/examples/example/infrastructure/persistence"
 	"github.com/axiomod/axiomod/examples/example/repository"
 	"github.com/axiomod/axiomod/examples/example/usecase"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/contract"
+	"github.com/axiomod/axiomod/framework/pagination"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -188,3 +191,50 @@ func TestGetExampleUseCase(t *testing.T) {
 	assert.Equal(t, example.Value.Count, output.Count)
 	assert.ElementsMatch(t, example.Value.Tags, output.Tags)
 }
+
+func TestListExamplesUseCase(t *testing.T) {
+	// Create a repository with five examples, two of another type
+	repo := persistence.NewExampleMemoryRepository()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		valueType := "test"
+		if i%2 == 1 {
+			valueType = "other"
+		}
+		err := repo.Create(ctx, entity.NewExample("Example", "", entity.NewExampleValue(valueType, i, nil)))
+		assert.NoError(t, err)
+	}
+
+	// Create a use case
+	paginator, err := pagination.NewPaginator(config.PaginationConfig{})
+	assert.NoError(t, err)
+	uc := usecase.NewListExamplesUseCase(repo, paginator)
+
+	// Page through the examples of one type
+	input := usecase.ListExamplesInput{ValueType: "test", Page: pagination.Request{PageSize: 2}}
+	first, err := uc.Execute(ctx, input)
+	assert.NoError(t, err)
+	assert.Len(t, first.Items, 2)
+	assert.NotEmpty(t, first.NextPageToken)
+
+	input.Page.PageToken = first.NextPageToken
+	second, err := uc.Execute(ctx, input)
+	assert.NoError(t, err)
+	assert.Len(t, second.Items, 1)
+	assert.Empty(t, second.NextPageToken)
+	assert.Less(t, first.Items[1].ID, second.Items[0].ID)
+
+	// A page token cannot be reused with other filters
+	_, err = uc.Execute(ctx, usecase.ListExamplesInput{ValueType: "other", Page: input.Page})
+	assert.Error(t, err)
+}
+
+func TestExampleContracts(t *testing.T) {
+	// HTTP and gRPC payloads are built from these structs
+	contract.Snapshot(t, "example",
+		entity.Example{},
+		usecase.CreateExampleInput{}, usecase.CreateExampleOutput{},
+		usecase.GetExampleInput{}, usecase.GetExampleOutput{},
+		usecase.ListExamplesInput{}, pagination.Page[*usecase.GetExampleOutput]{},
+	)
+}
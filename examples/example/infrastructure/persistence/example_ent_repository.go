@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/axiomod/axiomod/examples/example/entity"
 	"github.com/axiomod/axiomod/examples/example/repository"
+	"github.com/axiomod/axiomod/framework/query"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"go.uber.org/zap"
@@ -244,40 +246,28 @@ func (r *ExampleEntRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List retrieves all Example entities with optional filtering
+// List retrieves Example entities with optional filtering, ordered by ID
 func (r *ExampleEntRepository) List(ctx context.Context, filter repository.ExampleFilter) ([]*entity.Example, error) {
 	// In a real implementation, we would use the Ent ORM to query the entities
 	// For this example, we'll use a simple SQL query
-	query := `
-		SELECT id, name, description, value_type, value_count, created_at, updated_at
-		FROM examples
-		WHERE 1=1
-	`
-	var args []interface{}
-
-	// Apply filters
-	if filter.Name != "" {
-		query += " AND name = ?"
-		args = append(args, filter.Name)
+	builder := query.Select("id", "name", "description", "value_type", "value_count", "created_at", "updated_at").
+		From("examples")
+
+	// Tags are in their own table, so tag filters are a subquery
+	params := filter.Params()
+	params.Filter = slices.DeleteFunc(params.Filter, func(c query.Condition) bool { return c.Field == "tag" })
+	if filter.Tag != "" {
+		builder.Where("EXISTS (SELECT 1 FROM example_tags WHERE example_tags.example_id = examples.id AND example_tags.tag = ?)", filter.Tag)
 	}
 
-	if filter.ValueType != "" {
-		query += " AND value_type = ?"
-		args = append(args, filter.ValueType)
-	}
-
-	// Apply pagination
-	if filter.Limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, filter.Limit)
-
-		if filter.Offset > 0 {
-			query += " OFFSET ?"
-			args = append(args, filter.Offset)
-		}
+	// Apply filters and pagination, ordered by ID so pages are stable
+	builder, err := builder.Apply(repository.ExampleSchema, params)
+	if err != nil {
+		return nil, err
 	}
+	statement, args := builder.Build(query.Question)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.db.QueryContext(ctx, statement, args...)
 	if err != nil {
 		r.logger.Error("Failed to list examples", zap.Error(err))
 		return nil, fmt.Errorf("failed to list examples: %w", err)
@@ -334,20 +324,6 @@ func (r *ExampleEntRepository) List(ctx context.Context, filter repository.Examp
 		}
 		tagRows.Close()
 
-		// Filter by tag if specified
-		if filter.Tag != "" {
-			hasTag := false
-			for _, tag := range tags {
-				if tag == filter.Tag {
-					hasTag = true
-					break
-				}
-			}
-			if !hasTag {
-				continue
-			}
-		}
-
 		example.Value = entity.ExampleValue{
 			Type:  valueType,
 			Count: valueCount,
@@ -357,5 +333,14 @@ func (r *ExampleEntRepository) List(ctx context.Context, filter repository.Examp
 		examples = append(examples, &example)
 	}
 
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to list examples", zap.Error(err))
+		return nil, fmt.Errorf("failed to list examples: %w", err)
+	}
+
+	// Drop the row Apply fetches to know whether another page follows
+	if filter.Limit > 0 && len(examples) > filter.Limit {
+		examples = examples[:filter.Limit]
+	}
 	return examples, nil
 }
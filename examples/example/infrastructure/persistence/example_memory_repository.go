@@ -6,6 +6,7 @@ import (
 
 	"github.com/axiomod/axiomod/examples/example/entity"
 	"github.com/axiomod/axiomod/examples/example/repository"
+	"github.com/axiomod/axiomod/framework/query"
 )
 
 // ExampleMemoryRepository implements the ExampleRepository interface with in-memory storage
@@ -81,56 +82,40 @@ func (r *ExampleMemoryRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List retrieves all Example entities with optional filtering
+// List retrieves Example entities with optional filtering, ordered by ID
 func (r *ExampleMemoryRepository) List(ctx context.Context, filter repository.ExampleFilter) ([]*entity.Example, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var result []*entity.Example
-
-	// Apply filters
+	examples := make([]*entity.Example, 0, len(r.examples))
 	for _, example := range r.examples {
-		if matchesFilter(example, filter) {
-			result = append(result, cloneExample(example))
-		}
+		examples = append(examples, example)
 	}
-
-	// Apply pagination
-	if filter.Limit > 0 {
-		offset := filter.Offset
-		if offset >= len(result) {
-			return []*entity.Example{}, nil
-		}
-
-		end := offset + filter.Limit
-		if end > len(result) {
-			end = len(result)
-		}
-
-		result = result[offset:end]
+	result, err := query.Apply(examples, repository.ExampleSchema, filter.Params(), exampleField)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
-}
-
-// matchesFilter checks if an example matches the filter criteria
-func matchesFilter(example *entity.Example, filter repository.ExampleFilter) bool {
-	// Filter by name
-	if filter.Name != "" && example.Name != filter.Name {
-		return false
-	}
-
-	// Filter by value type
-	if filter.ValueType != "" && example.Value.Type != filter.ValueType {
-		return false
+	// Return copies of the examples
+	for i, example := range result.Items {
+		result.Items[i] = cloneExample(example)
 	}
+	return result.Items, nil
+}
 
-	// Filter by tag
-	if filter.Tag != "" && !example.Value.HasTag(filter.Tag) {
-		return false
+// exampleField returns the value of a field of repository.ExampleSchema
+func exampleField(example *entity.Example, field string) any {
+	switch field {
+	case "id":
+		return example.ID
+	case "name":
+		return example.Name
+	case "value_type":
+		return example.Value.Type
+	case "tag":
+		return example.Value.Tags
 	}
-
-	return true
+	return nil
 }
 
 // cloneExample creates a deep copy of an Example entity
@@ -8,6 +8,7 @@ import (
 	"github.com/axiomod/axiomod/examples/example/repository"
 	"github.com/axiomod/axiomod/examples/example/service"
 	"github.com/axiomod/axiomod/examples/example/usecase"
+	"github.com/axiomod/axiomod/framework/errors"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,6 +18,9 @@ import (
 
 // Module provides the fx options for the example module
 var Module = fx.Options(
+	// Declare error codes
+	errors.Codes("example", ErrorCodes...),
+
 	// Provide repositories
 	fx.Provide(persistence.NewExampleMemoryRepository),
 	fx.Provide(func(repo *persistence.ExampleMemoryRepository) repository.ExampleRepository {
@@ -26,6 +30,7 @@ var Module = fx.Options(
 	// Provide use cases
 	fx.Provide(usecase.NewCreateExampleUseCase),
 	fx.Provide(usecase.NewGetExampleUseCase),
+	fx.Provide(usecase.NewListExamplesUseCase),
 
 	// Provide domain services
 	fx.Provide(service.NewExampleDomainService),
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/axiomod/axiomod/examples/example/entity"
+	"github.com/axiomod/axiomod/framework/query"
 )
 
 // ExampleRepository defines the interface for Example entity persistence
@@ -20,7 +21,7 @@ type ExampleRepository interface {
 	// Delete deletes an Example entity by ID
 	Delete(ctx context.Context, id string) error
 
-	// List retrieves all Example entities with optional filtering
+	// List retrieves Example entities with optional filtering, ordered by ID
 	List(ctx context.Context, filter ExampleFilter) ([]*entity.Example, error)
 }
 
@@ -31,6 +32,29 @@ type ExampleFilter struct {
 	Tag       string
 	Limit     int
 	Offset    int
+	After     string // only entities with a greater ID, for cursor pagination
+}
+
+// ExampleSchema lists the fields Example listings filter on
+var ExampleSchema = query.NewSchema("id", map[string]query.Field{
+	"name":       {Filter: []query.Operator{query.Eq}},
+	"value_type": {Filter: []query.Operator{query.Eq}},
+	"tag":        {Filter: []query.Operator{query.Eq}},
+})
+
+// Params returns the listing parameters of the filter, checked against ExampleSchema
+func (f ExampleFilter) Params() query.Params {
+	var filter query.Filter
+	if f.Name != "" {
+		filter = filter.Where("name", query.Eq, f.Name)
+	}
+	if f.ValueType != "" {
+		filter = filter.Where("value_type", query.Eq, f.ValueType)
+	}
+	if f.Tag != "" {
+		filter = filter.Where("tag", query.Eq, f.Tag)
+	}
+	return query.Params{Filter: filter, Page: query.Page{Limit: f.Limit, Offset: f.Offset, After: f.After}}
 }
 
 // Repository errors
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 
+	"github.com/axiomod/axiomod/examples/example/entity"
 	"github.com/axiomod/axiomod/examples/example/repository"
 )
 
@@ -44,6 +45,11 @@ func (uc *GetExampleUseCase) Execute(ctx context.Context, input GetExampleInput)
 	}
 
 	// Map to output
+	return newGetExampleOutput(example), nil
+}
+
+// newGetExampleOutput maps an Example entity to the output of getting it
+func newGetExampleOutput(example *entity.Example) *GetExampleOutput {
 	return &GetExampleOutput{
 		ID:          example.ID,
 		Name:        example.Name,
@@ -53,5 +59,5 @@ func (uc *GetExampleUseCase) Execute(ctx context.Context, input GetExampleInput)
 		Tags:        example.Value.Tags,
 		CreatedAt:   example.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   example.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	}
 }
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/axiomod/axiomod/examples/example/repository"
+	"github.com/axiomod/axiomod/framework/pagination"
+)
+
+// ListExamplesInput represents the input for listing examples
+type ListExamplesInput struct {
+	Name      string `json:"name" query:"name"`
+	ValueType string `json:"valueType" query:"valueType"`
+	Tag       string `json:"tag" query:"tag"`
+	Page      pagination.Request
+}
+
+// ListExamplesUseCase defines the use case for listing examples a page at a time
+type ListExamplesUseCase struct {
+	repo      repository.ExampleRepository
+	paginator *pagination.Paginator
+}
+
+// NewListExamplesUseCase creates a new ListExamplesUseCase
+func NewListExamplesUseCase(repo repository.ExampleRepository, paginator *pagination.Paginator) *ListExamplesUseCase {
+	return &ListExamplesUseCase{
+		repo:      repo,
+		paginator: paginator,
+	}
+}
+
+// Execute executes the use case
+func (uc *ListExamplesUseCase) Execute(ctx context.Context, input ListExamplesInput) (pagination.Page[*GetExampleOutput], error) {
+	query := pagination.Fingerprint(input.Name, input.ValueType, input.Tag)
+	cursor, size, err := uc.paginator.Start(input.Page, query)
+	if err != nil {
+		return pagination.Page[*GetExampleOutput]{}, err
+	}
+
+	// Fetch one more than the page to know whether another page follows
+	examples, err := uc.repo.List(ctx, repository.ExampleFilter{
+		Name:      input.Name,
+		ValueType: input.ValueType,
+		Tag:       input.Tag,
+		After:     cursor.After,
+		Limit:     size + 1,
+	})
+	if err != nil {
+		return pagination.Page[*GetExampleOutput]{}, err
+	}
+
+	var next string
+	if len(examples) > size {
+		examples = examples[:size]
+		next, err = uc.paginator.Next(pagination.Cursor{After: examples[size-1].ID}, query)
+		if err != nil {
+			return pagination.Page[*GetExampleOutput]{}, err
+		}
+	}
+
+	items := make([]*GetExampleOutput, len(examples))
+	for i, example := range examples {
+		items[i] = newGetExampleOutput(example)
+	}
+	return pagination.NewPage(items, next), nil
+}
@@ -2,16 +2,24 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
 	"encoding/json"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/testkit/fake"
 	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJWTService(t *testing.T) {
@@ -56,6 +64,76 @@ func TestJWTService(t *testing.T) {
 		assert.Equal(t, ErrInvalidToken, err)
 	})
 
+	t.Run("Issuer and Audience Validation", func(t *testing.T) {
+		issuer := NewJWTService(secret, duration).WithValidation(TokenValidation{
+			Issuers:   []string{"https://auth.example.com", "https://legacy.example.com"},
+			Audiences: []string{"orders-api"},
+		})
+		token, err := issuer.GenerateToken("id", "user", "email", nil)
+		require.NoError(t, err)
+
+		claims, err := issuer.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "https://auth.example.com", claims.Issuer)
+		assert.Equal(t, jwt.ClaimStrings{"orders-api"}, claims.Audience)
+
+		otherAudience := NewJWTService(secret, duration).WithValidation(TokenValidation{Audiences: []string{"billing-api"}})
+		_, err = otherAudience.ValidateToken(token)
+		assert.Equal(t, ErrInvalidToken, err)
+
+		otherIssuer := NewJWTService(secret, duration).WithValidation(TokenValidation{Issuers: []string{"https://other.example.com"}})
+		_, err = otherIssuer.ValidateToken(token)
+		assert.Equal(t, ErrInvalidToken, err)
+
+		// Tokens without configured expectations remain accepted by the default service
+		_, err = service.ValidateToken(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Clock Skew", func(t *testing.T) {
+		expired, err := NewJWTService(secret, -10*time.Second).GenerateToken("id", "user", "email", nil)
+		require.NoError(t, err)
+
+		_, err = service.ValidateToken(expired)
+		assert.Equal(t, ErrExpiredToken, err)
+
+		lenient := NewJWTService(secret, duration).WithValidation(TokenValidation{ClockSkew: time.Minute})
+		_, err = lenient.ValidateToken(expired)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Clock", func(t *testing.T) {
+		clk := fake.NewClock(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+		clocked := NewJWTService(secret, time.Hour).WithClock(clk)
+		token, err := clocked.GenerateToken("id", "user", "email", nil)
+		require.NoError(t, err)
+
+		claims, err := clocked.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, clk.Now().Add(time.Hour), claims.ExpiresAt.Time.UTC())
+
+		clk.Advance(time.Hour + time.Second)
+		_, err = clocked.ValidateToken(token)
+		assert.Equal(t, ErrExpiredToken, err)
+	})
+
+	t.Run("Algorithms", func(t *testing.T) {
+		hs512 := NewJWTService(secret, duration).WithValidation(TokenValidation{Algorithms: []string{"HS512"}})
+		token, err := hs512.GenerateToken("id", "user", "email", nil)
+		require.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, "HS512", parsed.Method.Alg())
+
+		_, err = hs512.ValidateToken(token)
+		assert.NoError(t, err)
+
+		hs256Only := NewJWTService(secret, duration).WithValidation(TokenValidation{Algorithms: []string{"HS256"}})
+		_, err = hs256Only.ValidateToken(token)
+		assert.Equal(t, ErrInvalidToken, err)
+	})
+
 	t.Run("Claims HasRole", func(t *testing.T) {
 		claims := &Claims{Roles: []string{"admin", "editor"}}
 		assert.True(t, claims.HasRole("admin"))
@@ -132,3 +210,117 @@ func TestOIDCService(t *testing.T) {
 		assert.Equal(t, "https://mock.com", mockService.discovery.Issuer)
 	})
 }
+
+func TestOIDCServiceSnapshot(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/.well-known/openid-configuration"):
+			_ = json.NewEncoder(w).Encode(OIDCDiscovery{Issuer: "https://issuer.test", JWKSURL: "http://" + r.Host + "/jwks"})
+		case strings.HasSuffix(r.URL.Path, "/jwks"):
+			_, _ = w.Write(jwks)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	issuerURL := server.URL
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &Claims{
+		UserID: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://issuer.test",
+			Audience:  jwt.ClaimStrings{"client"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	logger, _ := observability.NewLogger(&config.Config{})
+	store := NewFileOIDCSnapshotStore(filepath.Join(t.TempDir(), "oidc", "snapshot.json"))
+	cfg := OIDCConfig{IssuerURL: issuerURL, ClientID: "client", SnapshotStore: store}
+
+	// Populate the snapshot while the issuer is reachable
+	online := NewOIDCService(cfg, logger)
+	require.NoError(t, online.Discover(context.Background()))
+	claims, err := online.VerifyToken(context.Background(), signed)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+
+	server.Close()
+
+	t.Run("Verifies from snapshot while issuer is down", func(t *testing.T) {
+		offline := NewOIDCService(cfg, logger)
+		offline.Start()
+		defer offline.Stop()
+
+		claims, err := offline.VerifyToken(context.Background(), signed)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.UserID)
+	})
+
+	t.Run("Honours configured issuers, audiences and algorithms", func(t *testing.T) {
+		snapshot, err := store.Load(context.Background())
+		require.NoError(t, err)
+
+		tests := []struct {
+			name       string
+			validation TokenValidation
+			wantErr    string
+		}{
+			{"additional issuer and audience", TokenValidation{Issuers: []string{"https://other.test", "https://issuer.test"}, Audiences: []string{"api", "client"}}, ""},
+			{"issuer not accepted", TokenValidation{Issuers: []string{"https://other.test"}}, "invalid issuer"},
+			{"audience not accepted", TokenValidation{Audiences: []string{"api"}}, "invalid audience"},
+			{"algorithm not accepted", TokenValidation{Algorithms: []string{"ES256"}}, "signing method RS256 is invalid"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				validatedCfg := cfg
+				validatedCfg.Validation = tt.validation
+				service := NewOIDCService(validatedCfg, logger)
+				require.NoError(t, service.install(snapshot))
+
+				_, err := service.VerifyToken(context.Background(), signed)
+				if tt.wantErr == "" {
+					assert.NoError(t, err)
+				} else {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+			})
+		}
+	})
+
+	t.Run("Rejects snapshot beyond max staleness", func(t *testing.T) {
+		snapshot, err := store.Load(context.Background())
+		require.NoError(t, err)
+		snapshot.FetchedAt = time.Now().Add(-2 * time.Hour)
+		require.NoError(t, store.Save(context.Background(), snapshot))
+
+		staleCfg := cfg
+		staleCfg.MaxStaleness = time.Hour
+		stale := NewOIDCService(staleCfg, logger)
+		stale.loadSnapshot(context.Background())
+		assert.Nil(t, stale.jwks)
+
+		// Keys installed earlier also stop being trusted once they age out
+		require.NoError(t, stale.install(snapshot))
+		_, err = stale.VerifyToken(context.Background(), signed)
+		assert.ErrorIs(t, err, ErrOIDCKeysStale)
+	})
+}
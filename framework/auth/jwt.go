@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/clock"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -27,6 +29,8 @@ type Claims struct {
 type JWTService struct {
 	secretKey     []byte
 	tokenDuration time.Duration
+	validation    TokenValidation
+	clock         clock.Clock
 }
 
 // NewJWTService creates a new JWTService
@@ -34,12 +38,30 @@ func NewJWTService(secretKey string, tokenDuration time.Duration) *JWTService {
 	return &JWTService{
 		secretKey:     []byte(secretKey),
 		tokenDuration: tokenDuration,
+		clock:         clock.System,
 	}
 }
 
+// WithValidation sets the accepted issuers, audiences, clock skew and algorithms.
+// Generated tokens use the first issuer and algorithm and carry all audiences.
+func (s *JWTService) WithValidation(validation TokenValidation) *JWTService {
+	s.validation = validation
+	return s
+}
+
+// WithClock sets the clock tokens are issued and expire on
+func (s *JWTService) WithClock(c clock.Clock) *JWTService {
+	s.clock = clock.OrSystem(c)
+	return s
+}
+
 // GenerateToken generates a new JWT token
 func (s *JWTService) GenerateToken(userID, username, email string, roles []string) (string, error) {
-	now := time.Now()
+	now := s.clock.Now()
+	issuer := "axiomod"
+	if len(s.validation.Issuers) > 0 {
+		issuer = s.validation.Issuers[0]
+	}
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
@@ -49,12 +71,15 @@ func (s *JWTService) GenerateToken(userID, username, email string, roles []strin
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "axiomod",
+			Issuer:    issuer,
 			Subject:   userID,
 		},
 	}
+	if len(s.validation.Audiences) > 0 {
+		claims.Audience = jwt.ClaimStrings(s.validation.Audiences)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(s.signingMethod(), claims)
 	return token.SignedString(s.secretKey)
 }
 
@@ -66,7 +91,7 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return s.secretKey, nil
-	})
+	}, append(s.validation.parserOptions(DefaultJWTAlgorithms), jwt.WithTimeFunc(s.clock.Now))...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -84,9 +109,23 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	if s.validation.checkIssuer(claims, "") != nil || s.validation.checkAudience(claims, "") != nil {
+		return nil, ErrInvalidToken
+	}
+
 	return claims, nil
 }
 
+// signingMethod returns the first configured HMAC algorithm, defaulting to HS256
+func (s *JWTService) signingMethod() jwt.SigningMethod {
+	for _, alg := range s.validation.Algorithms {
+		if method, ok := jwt.GetSigningMethod(alg).(*jwt.SigningMethodHMAC); ok {
+			return method
+		}
+	}
+	return jwt.SigningMethodHS256
+}
+
 // HasRole checks if the claims have a specific role
 func (c *Claims) HasRole(role string) bool {
 	for _, r := range c.Roles {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/clock"
 	"github.com/axiomod/axiomod/framework/config"
 	"github.com/axiomod/axiomod/platform/observability"
 	"go.uber.org/fx"
@@ -14,7 +15,7 @@ var Module = fx.Options(
 	fx.Provide(ProvideJWTService),
 	fx.Provide(ProvideOIDCService),
 	fx.Provide(ProvideRBACService),
-	fx.Invoke(RegisterOIDCLifecycle),
+	fx.Invoke(RegisterOIDCLifecycle, RegisterJWTClock),
 )
 
 // ProvideJWTService provides a JWTService
@@ -22,17 +23,49 @@ func ProvideJWTService(cfg *config.Config) *JWTService {
 	return NewJWTService(
 		cfg.Auth.JWT.SecretKey,
 		time.Duration(cfg.Auth.JWT.TokenDuration)*time.Minute,
-	)
+	).WithValidation(TokenValidation{
+		Issuers:    cfg.Auth.JWT.Issuers,
+		Audiences:  cfg.Auth.JWT.Audiences,
+		ClockSkew:  time.Duration(cfg.Auth.JWT.ClockSkew) * time.Second,
+		Algorithms: cfg.Auth.JWT.Algorithms,
+	})
+}
+
+// JWTClockParams holds the clock tokens are issued and expire on, if one is provided
+type JWTClockParams struct {
+	fx.In
+
+	JWTService *JWTService
+	Clock      clock.Clock `optional:"true"`
+}
+
+// RegisterJWTClock makes tokens issue and expire on the provided clock, such as a fake clock
+// of a test
+func RegisterJWTClock(p JWTClockParams) {
+	if p.Clock != nil {
+		p.JWTService.WithClock(p.Clock)
+	}
 }
 
 // ProvideOIDCService provides an OIDCService
-func ProvideOIDCService(cfg *config.Config, logger *observability.Logger) *OIDCService {
-	return NewOIDCService(OIDCConfig{
+func ProvideOIDCService(cfg *config.Config, logger *observability.Logger, metrics *observability.Metrics) *OIDCService {
+	oidcCfg := OIDCConfig{
 		IssuerURL:    cfg.Auth.OIDC.IssuerURL,
 		ClientID:     cfg.Auth.OIDC.ClientID,
 		ClientSecret: cfg.Auth.OIDC.ClientSecret,
 		JWKSCacheTTL: time.Duration(cfg.Auth.OIDC.JWKSCacheTTL) * time.Minute,
-	}, logger)
+		MaxStaleness: time.Duration(cfg.Auth.OIDC.JWKSMaxStaleness) * time.Minute,
+		Validation: TokenValidation{
+			Issuers:    cfg.Auth.OIDC.Issuers,
+			Audiences:  cfg.Auth.OIDC.Audiences,
+			ClockSkew:  time.Duration(cfg.Auth.OIDC.ClockSkew) * time.Second,
+			Algorithms: cfg.Auth.OIDC.Algorithms,
+		},
+	}
+	if cfg.Auth.OIDC.JWKSCachePath != "" {
+		oidcCfg.SnapshotStore = NewFileOIDCSnapshotStore(cfg.Auth.OIDC.JWKSCachePath)
+	}
+	return NewOIDCService(oidcCfg, logger).WithMetrics(metrics)
 }
 
 // ProvideRBACService provides an RBACService
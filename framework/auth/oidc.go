@@ -3,17 +3,32 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/MicahParks/jwkset"
 	"github.com/MicahParks/keyfunc/v3"
 	"github.com/axiomod/axiomod/platform/observability"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
+// ErrOIDCKeysStale is returned when the cached signing keys are older than the allowed staleness
+var ErrOIDCKeysStale = errors.New("OIDC signing keys are stale")
+
+const (
+	// oidcRetryInterval is how soon a failed refresh is retried
+	oidcRetryInterval = 30 * time.Second
+	// oidcUnknownKeyRefreshInterval rate limits refreshes triggered by tokens signed with unknown keys
+	oidcUnknownKeyRefreshInterval = time.Minute
+	// oidcMaxDocumentSize bounds the size of discovery and JWKS documents
+	oidcMaxDocumentSize = 1 << 20
+)
+
 // OIDCConfig represents the configuration for the OIDC service
 type OIDCConfig struct {
 	IssuerURL    string
@@ -22,6 +37,15 @@ type OIDCConfig struct {
 	RedirectURL  string
 	Scopes       []string
 	JWKSCacheTTL time.Duration
+
+	// SnapshotStore persists the last known discovery and JWKS documents, if set
+	SnapshotStore OIDCSnapshotStore
+	// MaxStaleness is how long cached keys may be used without a successful refresh; zero means no limit
+	MaxStaleness time.Duration
+
+	// Validation overrides the accepted issuers (default: the discovered issuer),
+	// audiences (default: ClientID), clock skew and algorithms
+	Validation TokenValidation
 }
 
 // OIDCDiscovery represents the OIDC discovery document
@@ -42,7 +66,9 @@ type OIDCService struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	logger        *observability.Logger
+	metrics       *observability.Metrics
 	lastDiscovery time.Time
+	lastKeyMiss   time.Time
 }
 
 // NewOIDCService creates a new OIDCService
@@ -59,24 +85,29 @@ func NewOIDCService(cfg OIDCConfig, logger *observability.Logger) *OIDCService {
 	}
 }
 
-// Start initiates the background refresh of discovery and JWKS
+// Start loads the persisted snapshot, if any, and initiates the background refresh of discovery and JWKS
 func (s *OIDCService) Start() {
+	s.loadSnapshot(s.ctx)
+
 	// Initial discovery
-	if err := s.Discover(s.ctx); err != nil {
+	err := s.Discover(s.ctx)
+	if err != nil {
 		s.logger.Error("Initial OIDC discovery failed", zap.Error(err))
 	}
 
-	// Start background refresh ticker
+	// Start background refresh, retrying sooner after a failure
 	go func() {
-		ticker := time.NewTicker(s.config.JWKSCacheTTL)
-		defer ticker.Stop()
+		timer := time.NewTimer(s.nextRefresh(err))
+		defer timer.Stop()
 
 		for {
 			select {
-			case <-ticker.C:
-				if err := s.Discover(s.ctx); err != nil {
+			case <-timer.C:
+				err := s.Discover(s.ctx)
+				if err != nil {
 					s.logger.Error("Background OIDC discovery failed", zap.Error(err))
 				}
+				timer.Reset(s.nextRefresh(err))
 			case <-s.ctx.Done():
 				return
 			}
@@ -84,6 +115,12 @@ func (s *OIDCService) Start() {
 	}()
 }
 
+// WithMetrics records refresh results and key age on metrics
+func (s *OIDCService) WithMetrics(metrics *observability.Metrics) *OIDCService {
+	s.metrics = metrics
+	return s
+}
+
 // Stop stops the background refresh
 func (s *OIDCService) Stop() {
 	s.cancel()
@@ -91,44 +128,137 @@ func (s *OIDCService) Stop() {
 
 // Discover performs OIDC discovery and initializes JWKS
 func (s *OIDCService) Discover(ctx context.Context) error {
+	snapshot, err := s.fetch(ctx)
+	if s.metrics != nil && s.metrics.OIDCRefreshTotal != nil {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		s.metrics.OIDCRefreshTotal.WithLabelValues(result).Inc()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.install(snapshot); err != nil {
+		return err
+	}
+
+	if s.config.SnapshotStore != nil {
+		if err := s.config.SnapshotStore.Save(ctx, snapshot); err != nil {
+			s.logger.Warn("Failed to persist OIDC snapshot", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// fetch downloads the discovery and JWKS documents
+func (s *OIDCService) fetch(ctx context.Context) (*OIDCSnapshot, error) {
 	discoveryURL := fmt.Sprintf("%s/.well-known/openid-configuration", s.config.IssuerURL)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	body, err := s.get(ctx, discoveryURL)
 	if err != nil {
-		return fmt.Errorf("failed to create discovery request: %w", err)
+		return nil, fmt.Errorf("failed to perform discovery: %w", err)
+	}
+
+	var discovery OIDCDiscovery
+	if err := json.Unmarshal(body, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+
+	jwks, err := s.get(ctx, discovery.JWKSURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize JWKS: %w", err)
+	}
+
+	return &OIDCSnapshot{
+		IssuerURL: s.config.IssuerURL,
+		Discovery: discovery,
+		JWKS:      jwks,
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+// get fetches a document from the issuer
+func (s *OIDCService) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to perform discovery: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("discovery failed with status: %s", resp.Status)
+		return nil, fmt.Errorf("discovery failed with status: %s", resp.Status)
 	}
 
-	var discovery OIDCDiscovery
-	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
-		return fmt.Errorf("failed to decode discovery document: %w", err)
-	}
+	return io.ReadAll(io.LimitReader(resp.Body, oidcMaxDocumentSize))
+}
 
-	// Initialize JWKS
-	kf, err := keyfunc.NewDefault([]string{discovery.JWKSURL})
+// install makes the snapshot the active discovery document and key set
+func (s *OIDCService) install(snapshot *OIDCSnapshot) error {
+	kf, err := keyfunc.NewJWKSetJSON(snapshot.JWKS)
 	if err != nil {
 		return fmt.Errorf("failed to initialize JWKS: %w", err)
 	}
 
+	discovery := snapshot.Discovery
 	s.mu.Lock()
 	s.discovery = &discovery
 	s.jwks = kf
-	s.lastDiscovery = time.Now()
+	s.lastDiscovery = snapshot.FetchedAt
 	s.mu.Unlock()
 
+	if s.metrics != nil && s.metrics.OIDCKeysLastRefresh != nil {
+		s.metrics.OIDCKeysLastRefresh.Set(float64(snapshot.FetchedAt.Unix()))
+	}
 	return nil
 }
 
+// loadSnapshot installs the persisted snapshot so tokens can be verified before the issuer is reachable
+func (s *OIDCService) loadSnapshot(ctx context.Context) {
+	if s.config.SnapshotStore == nil {
+		return
+	}
+
+	snapshot, err := s.config.SnapshotStore.Load(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load OIDC snapshot", zap.Error(err))
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+	if snapshot.IssuerURL != s.config.IssuerURL {
+		s.logger.Warn("Ignoring OIDC snapshot for a different issuer", zap.String("issuer", snapshot.IssuerURL))
+		return
+	}
+	if s.config.MaxStaleness > 0 && time.Since(snapshot.FetchedAt) > s.config.MaxStaleness {
+		s.logger.Warn("Ignoring stale OIDC snapshot", zap.Time("fetched_at", snapshot.FetchedAt))
+		return
+	}
+
+	if err := s.install(snapshot); err != nil {
+		s.logger.Warn("Failed to install OIDC snapshot", zap.Error(err))
+		return
+	}
+	s.logger.Info("Loaded cached OIDC keys", zap.Time("fetched_at", snapshot.FetchedAt))
+}
+
+// nextRefresh returns the delay before the next background refresh
+func (s *OIDCService) nextRefresh(lastErr error) time.Duration {
+	if lastErr != nil && oidcRetryInterval < s.config.JWKSCacheTTL {
+		return oidcRetryInterval
+	}
+	return s.config.JWKSCacheTTL
+}
+
 // VerifyToken verifies an OIDC ID token
 func (s *OIDCService) VerifyToken(ctx context.Context, tokenString string) (*Claims, error) {
 	s.mu.RLock()
@@ -151,11 +281,23 @@ func (s *OIDCService) VerifyToken(ctx context.Context, tokenString string) (*Cla
 	lastDisco := s.lastDiscovery
 	s.mu.RUnlock()
 
+	if s.config.MaxStaleness > 0 && time.Since(lastDisco) > s.config.MaxStaleness {
+		return nil, fmt.Errorf("%w: last refreshed at %s", ErrOIDCKeysStale, lastDisco.Format(time.RFC3339))
+	}
 	if time.Since(lastDisco) > s.config.JWKSCacheTTL*2 && lastDisco.IsZero() == false {
 		s.logger.Warn("OIDC discovery is stale", zap.Time("last_success", lastDisco))
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, jwks.Keyfunc)
+	parserOpts := s.config.Validation.parserOptions(DefaultOIDCAlgorithms)
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, jwks.Keyfunc, parserOpts...)
+	if err != nil && errors.Is(err, jwkset.ErrKeyNotFound) && s.refreshForUnknownKey(ctx) {
+		// The issuer may have rotated its keys; retry with the refreshed key set
+		s.mu.RLock()
+		discovery = s.discovery
+		jwks = s.jwks
+		s.mu.RUnlock()
+		token, err = jwt.ParseWithClaims(tokenString, &Claims{}, jwks.Keyfunc, parserOpts...)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify token: %w", err)
 	}
@@ -165,27 +307,31 @@ func (s *OIDCService) VerifyToken(ctx context.Context, tokenString string) (*Cla
 		return nil, fmt.Errorf("invalid token or claims")
 	}
 
-	// Verify issuer and audience
-	if claims.Issuer != discovery.Issuer {
-		return nil, fmt.Errorf("invalid issuer: expected %s, got %s", discovery.Issuer, claims.Issuer)
+	// Verify issuer and audience; aud usually contains ClientID for ID tokens
+	if err := s.config.Validation.checkIssuer(claims, discovery.Issuer); err != nil {
+		return nil, err
 	}
-
-	// Aud usually contains ClientID for ID tokens
-	aud, err := claims.GetAudience()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get audience: %w", err)
+	if err := s.config.Validation.checkAudience(claims, s.config.ClientID); err != nil {
+		return nil, err
 	}
 
-	foundAud := false
-	for _, a := range aud {
-		if a == s.config.ClientID {
-			foundAud = true
-			break
-		}
-	}
-	if !foundAud {
-		return nil, fmt.Errorf("invalid audience: %v", aud)
+	return claims, nil
+}
+
+// refreshForUnknownKey refreshes the key set after a token signed with an unknown key,
+// at most once per oidcUnknownKeyRefreshInterval. It reports whether the refresh succeeded.
+func (s *OIDCService) refreshForUnknownKey(ctx context.Context) bool {
+	s.mu.Lock()
+	if time.Since(s.lastKeyMiss) < oidcUnknownKeyRefreshInterval {
+		s.mu.Unlock()
+		return false
 	}
+	s.lastKeyMiss = time.Now()
+	s.mu.Unlock()
 
-	return claims, nil
+	if err := s.Discover(ctx); err != nil {
+		s.logger.Warn("OIDC refresh for unknown signing key failed", zap.Error(err))
+		return false
+	}
+	return true
 }
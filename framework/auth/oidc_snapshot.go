@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/cache"
+)
+
+// OIDCSnapshot is the last known discovery and JWKS documents of an issuer
+type OIDCSnapshot struct {
+	IssuerURL string          `json:"issuer_url"`
+	Discovery OIDCDiscovery   `json:"discovery"`
+	JWKS      json.RawMessage `json:"jwks"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+// OIDCSnapshotStore persists OIDC snapshots so token verification can continue
+// across restarts while the issuer is unreachable
+type OIDCSnapshotStore interface {
+	// Load returns the stored snapshot, or nil if there is none
+	Load(ctx context.Context) (*OIDCSnapshot, error)
+
+	// Save stores the snapshot, replacing any previous one
+	Save(ctx context.Context, snapshot *OIDCSnapshot) error
+}
+
+// FileOIDCSnapshotStore stores the snapshot as a JSON file
+type FileOIDCSnapshotStore struct {
+	path string
+}
+
+// NewFileOIDCSnapshotStore creates a new file-backed snapshot store
+func NewFileOIDCSnapshotStore(path string) *FileOIDCSnapshotStore {
+	return &FileOIDCSnapshotStore{path: path}
+}
+
+// Load reads the snapshot file
+func (s *FileOIDCSnapshotStore) Load(ctx context.Context) (*OIDCSnapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC snapshot: %w", err)
+	}
+
+	var snapshot OIDCSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Save writes the snapshot file atomically so a crash never leaves a partial file
+func (s *FileOIDCSnapshotStore) Save(ctx context.Context, snapshot *OIDCSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode OIDC snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create OIDC snapshot directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".oidc-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create OIDC snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write OIDC snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write OIDC snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace OIDC snapshot: %w", err)
+	}
+	return nil
+}
+
+// CacheOIDCSnapshotStore stores the snapshot in a cache, e.g. one shared by all replicas
+type CacheOIDCSnapshotStore struct {
+	cache cache.Cache
+	key   string
+}
+
+// NewCacheOIDCSnapshotStore creates a new cache-backed snapshot store
+func NewCacheOIDCSnapshotStore(c cache.Cache, key string) *CacheOIDCSnapshotStore {
+	return &CacheOIDCSnapshotStore{cache: c, key: key}
+}
+
+// Load reads the snapshot from the cache
+func (s *CacheOIDCSnapshotStore) Load(ctx context.Context) (*OIDCSnapshot, error) {
+	data, err := s.cache.Get(ctx, s.key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC snapshot: %w", err)
+	}
+
+	var snapshot OIDCSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Save writes the snapshot to the cache without expiry; staleness is enforced on use
+func (s *CacheOIDCSnapshotStore) Save(ctx context.Context, snapshot *OIDCSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode OIDC snapshot: %w", err)
+	}
+	return s.cache.Set(ctx, s.key, data, 0)
+}
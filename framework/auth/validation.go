@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultJWTAlgorithms are the signing algorithms accepted by JWTService when none are configured
+var DefaultJWTAlgorithms = []string{"HS256", "HS384", "HS512"}
+
+// DefaultOIDCAlgorithms are the signing algorithms accepted by OIDCService when none are configured
+var DefaultOIDCAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// TokenValidation configures which tokens are accepted beyond a valid signature
+type TokenValidation struct {
+	// Issuers lists the accepted "iss" values; empty accepts the service default
+	Issuers []string
+	// Audiences lists the accepted "aud" values; a token must contain at least one of them
+	Audiences []string
+	// ClockSkew is the leeway applied to the "exp", "nbf" and "iat" claims
+	ClockSkew time.Duration
+	// Algorithms lists the accepted "alg" header values
+	Algorithms []string
+}
+
+// parserOptions returns the jwt parser options enforcing the algorithms and clock skew
+func (v TokenValidation) parserOptions(defaultAlgorithms []string) []jwt.ParserOption {
+	algorithms := v.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultAlgorithms
+	}
+	opts := []jwt.ParserOption{jwt.WithValidMethods(algorithms)}
+	if v.ClockSkew > 0 {
+		opts = append(opts, jwt.WithLeeway(v.ClockSkew))
+	}
+	return opts
+}
+
+// checkIssuer verifies that the token was issued by one of the accepted issuers,
+// falling back to defaultIssuer when none are configured
+func (v TokenValidation) checkIssuer(claims *Claims, defaultIssuer string) error {
+	issuers := v.Issuers
+	if len(issuers) == 0 {
+		if defaultIssuer == "" {
+			return nil
+		}
+		issuers = []string{defaultIssuer}
+	}
+	for _, iss := range issuers {
+		if claims.Issuer == iss {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid issuer: %s", claims.Issuer)
+}
+
+// checkAudience verifies that the token is intended for one of the accepted audiences,
+// falling back to defaultAudience when none are configured
+func (v TokenValidation) checkAudience(claims *Claims, defaultAudience string) error {
+	audiences := v.Audiences
+	if len(audiences) == 0 {
+		if defaultAudience == "" {
+			return nil
+		}
+		audiences = []string{defaultAudience}
+	}
+	for _, aud := range claims.Audience {
+		for _, accepted := range audiences {
+			if aud == accepted {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("invalid audience: %v", []string(claims.Audience))
+}
@@ -0,0 +1,165 @@
+// Package axiomodtest boots the default Axiomod application inside a test so downstream
+// projects can smoke-test framework upgrades together with their own modules.
+package axiomodtest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/di"
+	"github.com/axiomod/axiomod/framework/events"
+	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
+	"github.com/axiomod/axiomod/platform/bootstrap"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/axiomod/axiomod/platform/server"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// StartTimeout bounds how long the application may take to start or stop
+const StartTimeout = 15 * time.Second
+
+// App is a running default application with handles to reach it
+type App struct {
+	// Config is the configuration the application was started with
+	Config *config.Config
+	// BaseURL is the address of the HTTP server, such as "http://127.0.0.1:41234"
+	BaseURL string
+	// HTTP is a client for the HTTP server
+	HTTP *http.Client
+	// GRPC is a client connection to the gRPC server
+	GRPC *grpc.ClientConn
+	// Events is the in-memory event bus provided as events.Publisher and events.Consumer
+	Events *events.EventBus
+}
+
+// URL returns the absolute URL of a path on the HTTP server
+func (a *App) URL(path string) string {
+	return a.BaseURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// DefaultConfig returns a configuration that needs no external services: servers listen on
+// random loopback ports, tracing is off and no plugins are enabled.
+func DefaultConfig() *config.Config {
+	return &config.Config{
+		App: config.AppConfig{
+			Name:        "axiomodtest",
+			Environment: "test",
+			Version:     "0.0.0",
+		},
+		Observability: config.ObservabilityConfig{
+			LogLevel:       "error",
+			LogFormat:      "json",
+			MetricsEnabled: true,
+		},
+		HTTP: config.HTTPConfig{
+			Host:         "127.0.0.1",
+			ReadTimeout:  10,
+			WriteTimeout: 10,
+		},
+		GRPC: config.GRPCConfig{
+			Host:    "127.0.0.1",
+			Gateway: config.GRPCGatewayConfig{Enabled: true},
+		},
+		Auth: config.AuthConfig{
+			JWT: config.JWTConfig{
+				SecretKey:     "axiomodtest-secret",
+				TokenDuration: 60,
+			},
+		},
+	}
+}
+
+// bootModules is the option of WithModules
+type bootModules struct {
+	fx.Option
+	modules []*di.Module
+}
+
+// WithModules boots modules in the boot plan of the framework modules, such as the plugin
+// registry with the plugins of the project, instead of after all of them:
+//
+//	axiomodtest.StartDefaultApp(t, nil, axiomodtest.WithModules(plugins.BootModule(&ldap.Plugin{})))
+func WithModules(modules ...*di.Module) fx.Option {
+	return bootModules{Option: fx.Options(), modules: modules}
+}
+
+// StartDefaultApp boots every framework module on DefaultConfig, adjusted by configOverrides
+// when it is not nil, and stops the application when the test ends. Extra options, such as the
+// modules of the project under test or fx.Populate, are added to the application. No plugin
+// registry is booted unless it is added with WithModules.
+func StartDefaultApp(t testing.TB, configOverrides func(cfg *config.Config), opts ...fx.Option) *App {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	if configOverrides != nil {
+		configOverrides(cfg)
+	}
+
+	modules := bootstrap.Modules()
+	for _, opt := range opts {
+		if boot, ok := opt.(bootModules); ok {
+			modules = append(modules, boot.modules...)
+		}
+	}
+	plan, err := di.PlanBoot(modules...)
+	if err != nil {
+		t.Fatalf("invalid module boot order: %v", err)
+	}
+
+	var (
+		httpServer *server.HTTPServer
+		grpcServer *grpc_pkg.Server
+		bus        *events.EventBus
+	)
+	app := fxtest.New(t,
+		fx.Supply(cfg),
+		fx.Options(plan.Options()...),
+		// The event bus stands in for brokers such as Kafka
+		fx.Provide(
+			func(lc fx.Lifecycle, logger *observability.Logger, metrics *observability.Metrics) *events.EventBus {
+				bus := events.NewEventBus(logger).WithMetrics(metrics)
+				lc.Append(fx.Hook{
+					OnStop: func(ctx context.Context) error {
+						return bus.Close()
+					},
+				})
+				return bus
+			},
+			func(bus *events.EventBus) events.Publisher { return bus },
+			func(bus *events.EventBus) events.Consumer { return bus },
+		),
+		fx.Options(opts...),
+		fx.Populate(&httpServer, &grpcServer, &bus),
+		fx.StartTimeout(StartTimeout),
+		fx.StopTimeout(StartTimeout),
+		fx.NopLogger,
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+
+	conn, err := grpc.NewClient(grpcServer.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to the gRPC server: %v", err)
+	}
+	// Cleanups run in reverse, so the connection closes before the application stops
+	t.Cleanup(func() { _ = conn.Close() })
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	t.Cleanup(client.CloseIdleConnections)
+
+	return &App{
+		Config:  cfg,
+		BaseURL: "http://" + httpServer.Addr().String(),
+		HTTP:    client,
+		GRPC:    conn,
+		Events:  bus,
+	}
+}
@@ -0,0 +1,108 @@
+package axiomodtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/axiomod/axiomod/plugins"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestStartDefaultApp(t *testing.T) {
+	received := make(chan events.Event, 1)
+	app := StartDefaultApp(t, func(cfg *config.Config) {
+		cfg.App.Name = "smoke-test"
+	},
+		// A module of the project under test consuming the provided ports
+		fx.Invoke(func(consumer events.Consumer) error {
+			return consumer.Subscribe(context.Background(), []string{"orders.created"}, func(ctx context.Context, event events.Event) error {
+				received <- event
+				return nil
+			})
+		}),
+	)
+	assert.Equal(t, "smoke-test", app.Config.App.Name)
+
+	t.Run("HTTP", func(t *testing.T) {
+		for _, path := range []string{"/live", "/ready", "/health", "/metrics"} {
+			resp, err := app.HTTP.Get(app.URL(path))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		}
+
+		// Unknown routes go through the shared error handler
+		resp, err := app.HTTP.Get(app.URL("/missing"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		var problem map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+		assert.Equal(t, "/missing", problem["instance"])
+	})
+
+	t.Run("gRPC", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		resp, err := healthpb.NewHealthClient(app.GRPC).Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		require.NoError(t, app.Events.Publish(context.Background(), "orders.created", []byte(`{"id":1}`), nil))
+		select {
+		case event := <-received:
+			assert.JSONEq(t, `{"id":1}`, string(event.Payload))
+		case <-time.After(2 * time.Second):
+			t.Fatal("event not delivered")
+		}
+	})
+}
+
+// greeterPlugin is a plugin of the project under test
+type greeterPlugin struct{ started bool }
+
+func (p *greeterPlugin) Name() string { return "greeter" }
+func (p *greeterPlugin) Initialize(settings map[string]interface{}, logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config, health *health.Health) error {
+	return nil
+}
+func (p *greeterPlugin) Start(ctx context.Context) error {
+	p.started = true
+	return nil
+}
+func (p *greeterPlugin) Stop(ctx context.Context) error { return nil }
+
+func TestStartDefaultAppWithModules(t *testing.T) {
+	greeter := &greeterPlugin{}
+	app := StartDefaultApp(t, func(cfg *config.Config) {
+		cfg.Plugins.Enabled = map[string]bool{"greeter": true}
+	}, WithModules(plugins.BootModule(greeter)))
+	assert.True(t, greeter.started)
+
+	resp, err := app.HTTP.Get(app.URL("/admin/plugins"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var body struct {
+		Plugins []plugins.Status `json:"plugins"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	var states []string
+	for _, status := range body.Plugins {
+		if status.Name == "greeter" {
+			states = append(states, string(status.State))
+		}
+	}
+	assert.Equal(t, []string{"running"}, states)
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
 )
 
 // Common errors
@@ -27,6 +29,7 @@ type MemoryCache struct {
 	maxItems  int
 	mu        sync.RWMutex
 	janitorOn bool
+	clock     clock.Clock
 }
 
 type cacheItem struct {
@@ -39,6 +42,7 @@ func NewMemoryCache(maxItems int) *MemoryCache {
 	cache := &MemoryCache{
 		items:    make(map[string]cacheItem),
 		maxItems: maxItems,
+		clock:    clock.System,
 	}
 
 	// Start the janitor if maxItems > 0
@@ -50,10 +54,19 @@ func NewMemoryCache(maxItems int) *MemoryCache {
 	return cache
 }
 
+// WithClock sets the clock items expire on
+func (c *MemoryCache) WithClock(clk clock.Clock) *MemoryCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clock.OrSystem(clk)
+	return c
+}
+
 // Get retrieves a value from the cache
 func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
 	c.mu.RLock()
 	item, found := c.items[key]
+	now := c.clock.Now()
 	c.mu.RUnlock()
 
 	if !found {
@@ -61,7 +74,7 @@ func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
 	}
 
 	// Check if the item has expired
-	if !item.expiration.IsZero() && item.expiration.Before(time.Now()) {
+	if !item.expiration.IsZero() && item.expiration.Before(now) {
 		c.mu.Lock()
 		delete(c.items, key)
 		c.mu.Unlock()
@@ -87,7 +100,7 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl tim
 	// Calculate expiration time
 	var expiration time.Time
 	if ttl > 0 {
-		expiration = time.Now().Add(ttl)
+		expiration = c.clock.Now().Add(ttl)
 	}
 
 	// Store a copy of the value to prevent modification
@@ -127,7 +140,7 @@ func (c *MemoryCache) janitor() {
 
 	for range ticker.C {
 		c.mu.Lock()
-		now := time.Now()
+		now := c.clock.Now()
 		for key, item := range c.items {
 			if !item.expiration.IsZero() && item.expiration.Before(now) {
 				delete(c.items, key)
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/testkit/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCacheClock(t *testing.T) {
+	ctx := context.Background()
+	clk := fake.NewClock(time.Now())
+	c := NewMemoryCache(0).WithClock(clk)
+
+	require.NoError(t, c.Set(ctx, "session", []byte("s-1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "config", []byte("c-1"), 0))
+
+	clk.Advance(59 * time.Second)
+	value, err := c.Get(ctx, "session")
+	require.NoError(t, err)
+	assert.Equal(t, "s-1", string(value))
+
+	clk.Advance(2 * time.Second)
+	_, err = c.Get(ctx, "session")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	clk.Advance(24 * time.Hour)
+	value, err = c.Get(ctx, "config")
+	require.NoError(t, err)
+	assert.Equal(t, "c-1", string(value), "items without TTL do not expire")
+}
+
+func BenchmarkMemoryCacheGet(b *testing.B) {
+	ctx := context.Background()
+	c := NewMemoryCache(1000)
+	for _, key := range keys {
+		require.NoError(b, c.Set(ctx, key, []byte("value"), time.Hour))
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_, _ = c.Get(ctx, keys[i%len(keys)])
+			i++
+		}
+	})
+}
+
+func BenchmarkMemoryCacheSet(b *testing.B) {
+	ctx := context.Background()
+	c := NewMemoryCache(1000)
+	value := []byte("value")
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_ = c.Set(ctx, keys[i%len(keys)], value, time.Hour)
+			i++
+		}
+	})
+}
+
+// keys are the keys of the cache benchmarks, formatted up front so as not to be measured
+var keys = func() []string {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	return keys
+}()
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+)
+
+// LoaderGroup is the fx value group collecting the warm-up loaders of modules
+const LoaderGroup = "cache_loaders"
+
+// Module provides the cache warmer and runs the registered loaders at startup
+var Module = fx.Options(
+	fx.Provide(ProvideWarmer),
+	fx.Invoke(RegisterWarmer),
+)
+
+// AsLoader annotates a constructor returning a *Loader so that the loader runs at startup:
+//
+//	fx.Provide(cache.AsLoader(NewProductLoader))
+func AsLoader(constructor interface{}) interface{} {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"cache_loaders"`))
+}
+
+// WarmerParams holds the dependencies of the cache warmer
+type WarmerParams struct {
+	fx.In
+
+	Config  *config.Config
+	Logger  *observability.Logger
+	Metrics *observability.Metrics
+	Loaders []*Loader `group:"cache_loaders"`
+}
+
+// ProvideWarmer provides a Warmer holding the loaders declared with AsLoader
+func ProvideWarmer(params WarmerParams) *Warmer {
+	cfg := params.Config.Cache.Warmup
+	warmer := NewWarmer(&WarmupOptions{
+		Concurrency: cfg.Concurrency,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+	}, params.Logger).WithMetrics(params.Metrics)
+	warmer.Register(params.Loaders...)
+	return warmer
+}
+
+// RegisterWarmer gates readiness on the warm-up and runs it in the background once the
+// application starts
+func RegisterWarmer(lc fx.Lifecycle, warmer *Warmer, h *health.Health) {
+	if warmer.Check() == nil {
+		return
+	}
+	h.RegisterCheck("cache_warmup", warmer.Check)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go warmer.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// RedisModule provides a Cache backed by the Redis server of the redis configuration. It
+// includes redis.Module, whose client reports the server in the health checks.
+var RedisModule = fx.Options(
+	axredis.Module,
+	fx.Provide(fx.Annotate(ProvideRedisCache, fx.As(fx.Self()), fx.As(new(Cache)))),
+)
+
+// RedisCache implements a cache stored in Redis, under a key prefix
+type RedisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisCache creates a cache storing its keys in Redis under prefix
+func NewRedisCache(client redis.UniversalClient, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+// Get retrieves a value from the cache
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	return value, err
+}
+
+// Set stores a value in the cache; a zero ttl keeps it until it is deleted
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.key(key), value, ttl).Err()
+}
+
+// Delete removes a value from the cache
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.key(key)).Err()
+}
+
+// Clear removes every value of the cache, leaving the other keys of the server
+func (c *RedisCache) Clear(ctx context.Context) error {
+	iter := c.client.Scan(ctx, 0, c.key("*"), 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 100 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return c.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+func (c *RedisCache) key(key string) string {
+	return c.prefix + ":" + key
+}
+
+// Ping checks that the Redis server is reachable
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Close closes the connections to the Redis server
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// ProvideRedisCache provides a RedisCache on the client of redis.Module, with the keys under
+// the cache prefix, "<app name>:cache" by default
+func ProvideRedisCache(cfg *config.Config, client redis.UniversalClient) *RedisCache {
+	prefix := cfg.Cache.Prefix
+	if prefix == "" {
+		prefix = cfg.App.Name + ":cache"
+	}
+
+	return NewRedisCache(client, prefix)
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCache(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("Skipping Redis cache test; set REDIS_ADDR")
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	cache := NewRedisCache(client, "cache-test-"+time.Now().Format("150405.000"))
+
+	_, err := cache.Get(ctx, "product")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Set(ctx, "product", []byte("42"), time.Minute))
+	value, err := cache.Get(ctx, "product")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("42"), value)
+
+	require.NoError(t, cache.Delete(ctx, "product"))
+	_, err = cache.Get(ctx, "product")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), 0))
+	require.NoError(t, cache.Clear(ctx))
+	_, err = cache.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = cache.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// ErrWarmupInProgress is reported by the readiness check while the loaders run
+var ErrWarmupInProgress = errors.New("cache warm-up in progress")
+
+// Loader fills a cache before the service reports ready, so the first requests after a
+// deploy do not all miss
+type Loader struct {
+	// Name identifies the loader in logs and metrics
+	Name string
+	// Cache receives the loaded entries
+	Cache Cache
+	// TTL is the lifetime of the loaded entries; zero keeps them until they are evicted
+	TTL time.Duration
+	// Load returns the entries to cache. It must return when ctx is done.
+	Load func(ctx context.Context) (map[string][]byte, error)
+}
+
+// WarmupOptions contains options for a cache warmer
+type WarmupOptions struct {
+	// Concurrency is the number of loaders run at once
+	Concurrency int
+	// Timeout bounds the whole warm-up; loaders still running are abandoned and the
+	// service reports ready with a partly cold cache
+	Timeout time.Duration
+}
+
+// DefaultWarmupOptions returns the default warm-up options
+func DefaultWarmupOptions() *WarmupOptions {
+	return &WarmupOptions{
+		Concurrency: 4,
+		Timeout:     30 * time.Second,
+	}
+}
+
+// WarmupResult describes the outcome of a loader
+type WarmupResult struct {
+	Loader   string
+	Keys     int   // entries written to the cache
+	Failed   int   // entries the cache rejected
+	Err      error // set when the loader failed or timed out
+	Duration time.Duration
+}
+
+// Warmer runs the registered loaders at startup and holds readiness until they are done
+type Warmer struct {
+	options  *WarmupOptions
+	logger   *observability.Logger
+	metrics  *observability.Metrics
+	mu       sync.Mutex
+	loaders  []*Loader
+	finished bool
+}
+
+// NewWarmer creates a new cache warmer
+func NewWarmer(options *WarmupOptions, logger *observability.Logger) *Warmer {
+	defaults := DefaultWarmupOptions()
+	if options == nil {
+		options = defaults
+	}
+	opts := *options
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaults.Concurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+
+	return &Warmer{options: &opts, logger: logger}
+}
+
+// WithMetrics records warmed keys, failures and loader durations on metrics
+func (w *Warmer) WithMetrics(metrics *observability.Metrics) *Warmer {
+	w.metrics = metrics
+	return w
+}
+
+// Register adds loaders to run on the next Run
+func (w *Warmer) Register(loaders ...*Loader) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loaders = append(w.loaders, loaders...)
+	w.finished = false
+}
+
+// Check is a health check failing with ErrWarmupInProgress until the registered loaders ran
+func (w *Warmer) Check() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.loaders) > 0 && !w.finished {
+		return ErrWarmupInProgress
+	}
+	return nil
+}
+
+// Run runs the registered loaders, Concurrency at a time, until they finish or Timeout
+// passes. Loader failures are logged and reported but do not fail the warm-up.
+func (w *Warmer) Run(ctx context.Context) []WarmupResult {
+	w.mu.Lock()
+	loaders := append([]*Loader(nil), w.loaders...)
+	w.mu.Unlock()
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, w.options.Timeout)
+	defer cancel()
+
+	// Buffered for every loader, so loaders abandoned at the timeout do not block
+	outcomes := make(chan WarmupResult, len(loaders))
+	go func() {
+		slots := make(chan struct{}, w.options.Concurrency)
+		for _, loader := range loaders {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				outcomes <- WarmupResult{Loader: loader.Name, Err: ctx.Err()}
+				continue
+			}
+			go func(loader *Loader) {
+				defer func() { <-slots }()
+				outcomes <- w.load(ctx, loader)
+			}(loader)
+		}
+	}()
+
+	results := make([]WarmupResult, 0, len(loaders))
+	done := make(map[string]bool, len(loaders))
+collect:
+	for len(results) < len(loaders) {
+		select {
+		case result := <-outcomes:
+			results = append(results, result)
+			done[result.Loader] = true
+		case <-ctx.Done():
+			for _, loader := range loaders {
+				if !done[loader.Name] {
+					results = append(results, WarmupResult{Loader: loader.Name, Err: ctx.Err(), Duration: time.Since(start)})
+				}
+			}
+			break collect
+		}
+	}
+
+	keys, failures := 0, 0
+	for _, result := range results {
+		w.record(result)
+		keys += result.Keys
+		if result.Err != nil || result.Failed > 0 {
+			failures++
+		}
+	}
+	w.logger.Info("Cache warm-up finished",
+		zap.Int("loaders", len(loaders)),
+		zap.Int("keys", keys),
+		zap.Int("failed_loaders", failures),
+		zap.Duration("duration", time.Since(start)))
+
+	w.mu.Lock()
+	w.finished = true
+	w.mu.Unlock()
+	return results
+}
+
+// load runs a loader and writes its entries to its cache
+func (w *Warmer) load(ctx context.Context, loader *Loader) WarmupResult {
+	start := time.Now()
+	result := WarmupResult{Loader: loader.Name}
+
+	entries, err := loader.Load(ctx)
+	if err != nil {
+		result.Err = err
+	}
+	for key, value := range entries {
+		if ctx.Err() != nil {
+			result.Err = ctx.Err()
+			break
+		}
+		if err := loader.Cache.Set(ctx, key, value, loader.TTL); err != nil {
+			result.Failed++
+			continue
+		}
+		result.Keys++
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// record logs a loader result and adds it to the metrics
+func (w *Warmer) record(result WarmupResult) {
+	if result.Err != nil {
+		w.logger.Warn("Cache warm-up loader failed",
+			zap.String("loader", result.Loader),
+			zap.Int("keys", result.Keys),
+			zap.Error(result.Err))
+	} else if result.Failed > 0 {
+		w.logger.Warn("Cache rejected warm-up entries",
+			zap.String("loader", result.Loader),
+			zap.Int("keys", result.Keys),
+			zap.Int("failed", result.Failed))
+	}
+
+	if w.metrics == nil || w.metrics.CacheWarmupKeysTotal == nil {
+		return
+	}
+	w.metrics.CacheWarmupKeysTotal.WithLabelValues(result.Loader).Add(float64(result.Keys))
+	if result.Err != nil || result.Failed > 0 {
+		w.metrics.CacheWarmupFailuresTotal.WithLabelValues(result.Loader).Inc()
+	}
+	w.metrics.CacheWarmupDuration.WithLabelValues(result.Loader).Set(result.Duration.Seconds())
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// staticLoader returns a loader caching the given number of entries
+func staticLoader(name string, c Cache, entries int) *Loader {
+	return &Loader{
+		Name:  name,
+		Cache: c,
+		Load: func(ctx context.Context) (map[string][]byte, error) {
+			values := make(map[string][]byte, entries)
+			for i := 0; i < entries; i++ {
+				values[fmt.Sprintf("%s:%d", name, i)] = []byte("value")
+			}
+			return values, nil
+		},
+	}
+}
+
+func TestWarmerRun(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+
+	c := NewMemoryCache(0)
+	warmer := NewWarmer(&WarmupOptions{Concurrency: 2, Timeout: 50 * time.Millisecond}, logger).WithMetrics(metrics)
+	assert.NoError(t, warmer.Check(), "a warmer without loaders is ready")
+
+	warmer.Register(
+		staticLoader("products", c, 3),
+		&Loader{
+			Name:  "prices",
+			Cache: c,
+			Load: func(ctx context.Context) (map[string][]byte, error) {
+				return nil, errors.New("pricing service unavailable")
+			},
+		},
+		&Loader{
+			Name:  "slow",
+			Cache: c,
+			Load: func(ctx context.Context) (map[string][]byte, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+		&Loader{
+			Name:  "stuck",
+			Cache: c,
+			Load: func(ctx context.Context) (map[string][]byte, error) {
+				// Ignores ctx; the warm-up does not wait for it
+				time.Sleep(time.Second)
+				return nil, nil
+			},
+		},
+	)
+	assert.ErrorIs(t, warmer.Check(), ErrWarmupInProgress)
+
+	start := time.Now()
+	results := warmer.Run(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the warm-up is time-boxed")
+	assert.NoError(t, warmer.Check(), "failed loaders do not hold readiness")
+
+	byLoader := make(map[string]WarmupResult)
+	for _, result := range results {
+		byLoader[result.Loader] = result
+	}
+	require.Len(t, byLoader, 4)
+	assert.Equal(t, 3, byLoader["products"].Keys)
+	assert.NoError(t, byLoader["products"].Err)
+	assert.EqualError(t, byLoader["prices"].Err, "pricing service unavailable")
+	assert.ErrorIs(t, byLoader["slow"].Err, context.DeadlineExceeded)
+	assert.ErrorIs(t, byLoader["stuck"].Err, context.DeadlineExceeded)
+
+	value, err := c.Get(context.Background(), "products:2")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.CacheWarmupKeysTotal.WithLabelValues("products")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.CacheWarmupFailuresTotal.WithLabelValues("products")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.CacheWarmupFailuresTotal.WithLabelValues("prices")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.CacheWarmupFailuresTotal.WithLabelValues("stuck")))
+}
+
+func TestWarmerModule(t *testing.T) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, _ := observability.NewMetrics(cfg, logger)
+	h := health.New(logger)
+	c := NewMemoryCache(0)
+
+	release := make(chan struct{})
+	app := fxtest.New(t,
+		fx.Supply(cfg, logger, metrics, h),
+		fx.Provide(AsLoader(func() *Loader {
+			loader := staticLoader("catalog", c, 1)
+			load := loader.Load
+			loader.Load = func(ctx context.Context) (map[string][]byte, error) {
+				<-release
+				return load(ctx)
+			}
+			return loader
+		})),
+		Module,
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	// Readiness waits for the loaders
+	h.RunChecks()
+	assert.Equal(t, health.StatusDown, h.GetStatus())
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		h.RunChecks()
+		return h.GetStatus() == health.StatusUp
+	}, time.Second, 5*time.Millisecond)
+	_, err := c.Get(context.Background(), "catalog:0")
+	assert.NoError(t, err)
+}
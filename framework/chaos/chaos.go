@@ -0,0 +1,254 @@
+// Package chaos injects faults in requests for chaos experiments: latency, errors and reset
+// connections on the HTTP routes and gRPC methods they match. Faults come from the chaos
+// section of the configuration and are replaced at runtime through the admin API, so that
+// staging environments can be disturbed while the resilience of their clients is observed.
+package chaos
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// DefaultErrorStatus is the HTTP status of failed requests when a fault sets none
+const DefaultErrorStatus = 503
+
+// Module provides the Injector configured under chaos
+var Module = fx.Options(
+	fx.Provide(ProvideInjector),
+)
+
+// ErrDisabled is returned when faults are set while fault injection is disabled
+var ErrDisabled = stderrors.New("chaos: fault injection is disabled")
+
+// Fault describes the faults injected in the requests matching it. A request matching
+// several faults gets the first.
+type Fault struct {
+	Name        string  `json:"name"`
+	Method      string  `json:"method,omitempty"`      // HTTP method; empty matches any method and gRPC calls
+	Path        string  `json:"path"`                  // HTTP path or gRPC full method, a prefix when ending with "*"
+	Latency     int     `json:"latency,omitempty"`     // in milliseconds, added before the request is handled
+	ErrorRate   float64 `json:"errorRate,omitempty"`   // share of requests failed, from 0 to 1
+	ErrorStatus int     `json:"errorStatus,omitempty"` // HTTP status of failed requests; defaults to 503
+	ResetRate   float64 `json:"resetRate,omitempty"`   // share of requests whose connection is reset, from 0 to 1
+}
+
+// FaultFromConfig converts the configuration of a fault
+func FaultFromConfig(cfg config.FaultConfig) Fault {
+	return Fault{
+		Name:        cfg.Name,
+		Method:      cfg.Method,
+		Path:        cfg.Path,
+		Latency:     cfg.Latency,
+		ErrorRate:   cfg.ErrorRate,
+		ErrorStatus: cfg.ErrorStatus,
+		ResetRate:   cfg.ResetRate,
+	}
+}
+
+// Validate checks that the fault matches requests and injects something sensible
+func (f Fault) Validate() error {
+	switch {
+	case f.Name == "":
+		return errors.NewInvalidInput(errors.ErrInvalidInput, "fault without a name")
+	case f.Path == "":
+		return errors.NewInvalidInput(errors.ErrInvalidInput, fmt.Sprintf("fault %q without a path", f.Name))
+	case f.Latency < 0:
+		return errors.NewInvalidInput(errors.ErrInvalidInput, fmt.Sprintf("fault %q has a negative latency", f.Name))
+	case f.ErrorRate < 0 || f.ResetRate < 0 || f.ErrorRate+f.ResetRate > 1:
+		return errors.NewInvalidInput(errors.ErrInvalidInput, fmt.Sprintf("fault %q has rates outside of 0 to 1", f.Name))
+	case f.ErrorStatus != 0 && (f.ErrorStatus < 400 || f.ErrorStatus > 599):
+		return errors.NewInvalidInput(errors.ErrInvalidInput, fmt.Sprintf("fault %q has an error status outside of 400 to 599", f.Name))
+	}
+	return nil
+}
+
+// matches reports whether a request to method and path gets the fault
+func (f Fault) matches(method, path string) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(f.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == f.Path
+}
+
+// Injection is what a fault does to a request
+type Injection struct {
+	// Fault is the name of the fault
+	Fault string
+	// Latency is waited for before the request goes on
+	Latency time.Duration
+	// Reset drops the connection of the request without a response
+	Reset bool
+	// Status fails the request with this HTTP status when not 0
+	Status int
+}
+
+// Injector holds the faults injected by the HTTP middleware and the gRPC interceptor
+type Injector struct {
+	enabled bool
+	logger  *observability.Logger
+	clock   clock.Clock
+	random  func() float64
+
+	mu     sync.RWMutex
+	faults []Fault
+}
+
+// NewInjector creates an injector without faults; it injects nothing unless enabled
+func NewInjector(enabled bool, logger *observability.Logger) *Injector {
+	return &Injector{
+		enabled: enabled,
+		logger:  logger,
+		clock:   clock.System,
+		random:  rand.Float64,
+	}
+}
+
+// InjectorParams holds the dependencies of the injector
+type InjectorParams struct {
+	fx.In
+
+	Config *config.Config
+	Logger *observability.Logger
+	Clock  clock.Clock `optional:"true"`
+}
+
+// ProvideInjector creates the injector of the faults configured under chaos
+func ProvideInjector(p InjectorParams) (*Injector, error) {
+	injector := NewInjector(p.Config.Chaos.Enabled, p.Logger)
+	if p.Clock != nil {
+		injector.WithClock(p.Clock)
+	}
+	if !injector.Enabled() || len(p.Config.Chaos.Faults) == 0 {
+		return injector, nil
+	}
+	faults := make([]Fault, 0, len(p.Config.Chaos.Faults))
+	for _, fault := range p.Config.Chaos.Faults {
+		faults = append(faults, FaultFromConfig(fault))
+	}
+	if err := injector.SetFaults(faults); err != nil {
+		return nil, err
+	}
+	return injector, nil
+}
+
+// WithClock times the latency of faults on c
+func (i *Injector) WithClock(c clock.Clock) *Injector {
+	i.clock = c
+	return i
+}
+
+// Enabled reports whether faults are injected
+func (i *Injector) Enabled() bool {
+	return i.enabled
+}
+
+// Faults returns the faults injected
+func (i *Injector) Faults() []Fault {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]Fault{}, i.faults...)
+}
+
+// SetFaults replaces the faults injected; no faults ends the experiment. It fails with
+// ErrDisabled unless fault injection is enabled.
+func (i *Injector) SetFaults(faults []Fault) error {
+	if !i.enabled {
+		return errors.NewConflict(ErrDisabled, "cannot set faults")
+	}
+	names := make(map[string]bool, len(faults))
+	for _, fault := range faults {
+		if err := fault.Validate(); err != nil {
+			return err
+		}
+		if names[fault.Name] {
+			return errors.NewInvalidInput(errors.ErrInvalidInput, fmt.Sprintf("duplicate fault %q", fault.Name))
+		}
+		names[fault.Name] = true
+	}
+
+	i.mu.Lock()
+	i.faults = append([]Fault(nil), faults...)
+	i.mu.Unlock()
+
+	if len(faults) == 0 {
+		i.logger.Info("Fault injection stopped")
+		return nil
+	}
+	for _, fault := range faults {
+		i.logger.Warn("Injecting fault",
+			zap.String("fault", fault.Name),
+			zap.String("method", fault.Method),
+			zap.String("path", fault.Path),
+			zap.Int("latency_ms", fault.Latency),
+			zap.Float64("error_rate", fault.ErrorRate),
+			zap.Float64("reset_rate", fault.ResetRate),
+		)
+	}
+	return nil
+}
+
+// Inject returns what to do to a request to method and path; gRPC calls pass an empty
+// method and their full method as path. It reports false when no fault matches.
+func (i *Injector) Inject(method, path string) (Injection, bool) {
+	if !i.enabled {
+		return Injection{}, false
+	}
+	i.mu.RLock()
+	var fault Fault
+	found := false
+	for _, f := range i.faults {
+		if f.matches(method, path) {
+			fault, found = f, true
+			break
+		}
+	}
+	i.mu.RUnlock()
+	if !found {
+		return Injection{}, false
+	}
+
+	injection := Injection{Fault: fault.Name, Latency: time.Duration(fault.Latency) * time.Millisecond}
+	// One draw decides between a reset, an error and neither, so their rates add up
+	r := i.random()
+	switch {
+	case r < fault.ResetRate:
+		injection.Reset = true
+	case r < fault.ResetRate+fault.ErrorRate:
+		injection.Status = fault.ErrorStatus
+		if injection.Status == 0 {
+			injection.Status = DefaultErrorStatus
+		}
+	}
+	return injection, true
+}
+
+// Wait waits for the latency of an injection, returning the error of ctx if it ends first
+func (i *Injector) Wait(ctx context.Context, injection Injection) error {
+	if injection.Latency <= 0 {
+		return nil
+	}
+	timer := i.clock.NewTimer(injection.Latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
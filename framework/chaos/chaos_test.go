@@ -0,0 +1,142 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/testkit/fake"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInjector(t *testing.T, random float64, faults ...Fault) *Injector {
+	logger, _ := observability.NewLogger(&config.Config{})
+	injector := NewInjector(true, logger)
+	injector.random = func() float64 { return random }
+	require.NoError(t, injector.SetFaults(faults))
+	return injector
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   Fault
+		wantErr bool
+	}{
+		{name: "valid", fault: Fault{Name: "slow", Path: "/api/*", Latency: 200, ErrorRate: 0.1, ResetRate: 0.1, ErrorStatus: 502}},
+		{name: "without name", fault: Fault{Path: "/api/*"}, wantErr: true},
+		{name: "without path", fault: Fault{Name: "slow"}, wantErr: true},
+		{name: "negative latency", fault: Fault{Name: "slow", Path: "/", Latency: -1}, wantErr: true},
+		{name: "rates above 1", fault: Fault{Name: "down", Path: "/", ErrorRate: 0.6, ResetRate: 0.6}, wantErr: true},
+		{name: "negative rate", fault: Fault{Name: "down", Path: "/", ErrorRate: -0.1}, wantErr: true},
+		{name: "success status", fault: Fault{Name: "down", Path: "/", ErrorRate: 1, ErrorStatus: 200}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fault.Validate()
+			if tt.wantErr {
+				assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestInject(t *testing.T) {
+	faults := []Fault{
+		{Name: "payments-down", Method: "POST", Path: "/api/payments", ErrorRate: 0.5},
+		{Name: "api-flaky", Path: "/api/*", Latency: 100, ResetRate: 0.2, ErrorRate: 0.3, ErrorStatus: 502},
+		{Name: "grpc-slow", Path: "/orders.v1.Orders/*", Latency: 50},
+	}
+	tests := []struct {
+		name   string
+		random float64
+		method string
+		path   string
+		want   Injection
+		wantOK bool
+	}{
+		{name: "no match", random: 0, method: "GET", path: "/live"},
+		{name: "first match fails", random: 0.4, method: "post", path: "/api/payments", wantOK: true,
+			want: Injection{Fault: "payments-down", Status: DefaultErrorStatus}},
+		{name: "first match passes", random: 0.5, method: "POST", path: "/api/payments", wantOK: true,
+			want: Injection{Fault: "payments-down"}},
+		{name: "method mismatch falls through", random: 0.1, method: "GET", path: "/api/payments", wantOK: true,
+			want: Injection{Fault: "api-flaky", Latency: 100 * time.Millisecond, Reset: true}},
+		{name: "error after reset rate", random: 0.3, method: "GET", path: "/api/orders", wantOK: true,
+			want: Injection{Fault: "api-flaky", Latency: 100 * time.Millisecond, Status: 502}},
+		{name: "latency only", random: 0.9, method: "GET", path: "/api/orders", wantOK: true,
+			want: Injection{Fault: "api-flaky", Latency: 100 * time.Millisecond}},
+		{name: "gRPC", random: 0, method: "", path: "/orders.v1.Orders/Get", wantOK: true,
+			want: Injection{Fault: "grpc-slow", Latency: 50 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := newInjector(t, tt.random, faults...)
+			got, ok := injector.Inject(tt.method, tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSetFaults(t *testing.T) {
+	injector := newInjector(t, 0, Fault{Name: "down", Path: "/", ErrorRate: 1})
+
+	err := injector.SetFaults([]Fault{{Name: "a", Path: "/a"}, {Name: "a", Path: "/b"}})
+	assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+	assert.Equal(t, []Fault{{Name: "down", Path: "/", ErrorRate: 1}}, injector.Faults(), "invalid faults are not applied")
+
+	require.NoError(t, injector.SetFaults(nil))
+	assert.Empty(t, injector.Faults())
+	_, ok := injector.Inject("GET", "/")
+	assert.False(t, ok)
+
+	logger, _ := observability.NewLogger(&config.Config{})
+	disabled := NewInjector(false, logger)
+	err = disabled.SetFaults([]Fault{{Name: "down", Path: "/", ErrorRate: 1}})
+	assert.ErrorIs(t, err, ErrDisabled)
+	assert.Equal(t, errors.CodeConflict, errors.GetCode(err))
+}
+
+func TestProvideInjector(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{Chaos: config.ChaosConfig{
+		Enabled: true,
+		Faults:  []config.FaultConfig{{Name: "slow", Path: "/api/*", Latency: 250}},
+	}}
+	injector, err := ProvideInjector(InjectorParams{Config: cfg, Logger: logger})
+	require.NoError(t, err)
+	assert.Equal(t, []Fault{{Name: "slow", Path: "/api/*", Latency: 250}}, injector.Faults())
+
+	cfg.Chaos.Enabled = false
+	injector, err = ProvideInjector(InjectorParams{Config: cfg, Logger: logger})
+	require.NoError(t, err)
+	assert.Empty(t, injector.Faults(), "configured faults wait for chaos.enabled")
+
+	cfg.Chaos = config.ChaosConfig{Enabled: true, Faults: []config.FaultConfig{{Name: "broken"}}}
+	_, err = ProvideInjector(InjectorParams{Config: cfg, Logger: logger})
+	assert.Error(t, err)
+}
+
+func TestWait(t *testing.T) {
+	clk := fake.NewClock(time.Now())
+	injector := newInjector(t, 0).WithClock(clk)
+	injection := Injection{Fault: "slow", Latency: time.Second}
+
+	done := make(chan error, 1)
+	go func() { done <- injector.Wait(context.Background(), injection) }()
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	assert.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, injector.Wait(ctx, injection), context.Canceled)
+}
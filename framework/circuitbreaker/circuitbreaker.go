@@ -3,7 +3,10 @@ package circuitbreaker
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
 )
 
 // State represents the state of the circuit breaker
@@ -18,6 +21,32 @@ const (
 	StateHalfOpen
 )
 
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the state as its name, e.g. in JSON
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// StateChangeFunc is called after a circuit breaker changed state. It runs on the goroutine
+// of the request causing the change, so it should return quickly.
+type StateChangeFunc func(name string, from, to State)
+
+// ErrOpen is returned by Execute while the circuit breaker rejects requests
+var ErrOpen = errors.New("circuit breaker is open")
+
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker struct {
 	name          string
@@ -28,7 +57,17 @@ type CircuitBreaker struct {
 	failures      int // Current consecutive failures in closed state
 	halfOpenCount int // Current successful requests in half-open state
 	lastFailure   time.Time
+	clock         clock.Clock
 	mutex         sync.RWMutex // Changed back to RWMutex for State() read optimization
+
+	// Outcome counts since creation
+	successes  atomic.Uint64
+	failed     atomic.Uint64
+	rejections atomic.Uint64
+
+	hooksMu  sync.RWMutex
+	hooks    []StateChangeFunc
+	registry *Registry
 }
 
 // Options contains options for creating a new CircuitBreaker
@@ -41,6 +80,10 @@ type Options struct {
 	ResetTimeout time.Duration
 	// HalfOpenLimit is the number of successful requests required in half-open state to close the circuit
 	HalfOpenLimit int
+	// OnStateChange is called after each state change
+	OnStateChange StateChangeFunc
+	// Clock times the reset timeout; nil uses the system clock
+	Clock clock.Clock
 }
 
 // DefaultOptions returns the default options for a circuit breaker
@@ -53,26 +96,43 @@ func DefaultOptions() Options {
 	}
 }
 
-// New creates a new CircuitBreaker with the given options
+// New creates a new CircuitBreaker with the given options and adds it to the default registry
 func New(options Options) *CircuitBreaker {
+	return DefaultRegistry.New(options)
+}
+
+// newCircuitBreaker creates a new CircuitBreaker outside any registry
+func newCircuitBreaker(options Options) *CircuitBreaker {
 	// Ensure HalfOpenLimit is at least 1
 	halfOpenLimit := options.HalfOpenLimit
 	if halfOpenLimit < 1 {
 		halfOpenLimit = 1
 	}
-	return &CircuitBreaker{
+	cb := &CircuitBreaker{
 		name:          options.Name,
 		maxFailures:   options.MaxFailures,
 		resetTimeout:  options.ResetTimeout,
 		halfOpenLimit: halfOpenLimit,
 		state:         StateClosed,
+		clock:         clock.OrSystem(options.Clock),
+	}
+	if options.OnStateChange != nil {
+		cb.hooks = append(cb.hooks, options.OnStateChange)
 	}
+	return cb
+}
+
+// OnStateChange adds a function called after each state change
+func (cb *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	cb.hooksMu.Lock()
+	defer cb.hooksMu.Unlock()
+	cb.hooks = append(cb.hooks, fn)
 }
 
 // Execute executes the given function with circuit breaker protection
 func (cb *CircuitBreaker) Execute(fn func() error) error {
 	if !cb.AllowRequest() {
-		return errors.New("circuit breaker is open")
+		return ErrOpen
 	}
 
 	err := fn()
@@ -83,43 +143,72 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 // AllowRequest checks if a request is allowed based on the current state
 // It handles state transitions from Open to HalfOpen.
 func (cb *CircuitBreaker) AllowRequest() bool {
+	from, allowed := cb.allowRequest()
+	if !allowed {
+		cb.rejections.Add(1)
+		cb.registry.recordRejection(cb.name)
+		return false
+	}
+	if from == StateOpen {
+		cb.notify(StateOpen, StateHalfOpen)
+	}
+	return true
+}
+
+// allowRequest checks if a request is allowed and returns the state it was checked in
+func (cb *CircuitBreaker) allowRequest() (State, bool) {
 	cb.mutex.Lock() // Use write lock as state transitions might occur
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
 	state := cb.state
 
 	switch state {
 	case StateClosed:
-		return true
+		return state, true
 	case StateOpen:
 		// Check if reset timeout has elapsed
 		if cb.lastFailure.IsZero() || now.Sub(cb.lastFailure) > cb.resetTimeout {
 			// Transition to half-open state
 			cb.state = StateHalfOpen
 			cb.halfOpenCount = 0 // Reset success counter for half-open
-			return true          // Allow the first request in half-open
+			return state, true   // Allow the first request in half-open
 		}
 		// Timeout not elapsed, still open
-		return false
+		return state, false
 	case StateHalfOpen:
 		// Allow requests up to the limit. The actual counting happens in RecordResult.
 		// This check might seem redundant if RecordResult handles the state change, but it prevents
 		// excessive requests if RecordResult is slow or fails to be called.
 		// A simpler approach might be to always allow in HalfOpen and let RecordResult manage state.
 		// Let's allow and rely on RecordResult.
-		return true
+		return state, true
 	default:
-		return false // Should not happen
+		return state, false // Should not happen
 	}
 }
 
 // RecordResult records the result of a request and handles state transitions
 func (cb *CircuitBreaker) RecordResult(err error) {
+	if err != nil {
+		cb.failed.Add(1)
+	} else {
+		cb.successes.Add(1)
+	}
+	cb.registry.recordResult(cb.name, err)
+
+	if from, to := cb.recordResult(err); from != to {
+		cb.notify(from, to)
+	}
+}
+
+// recordResult applies the result of a request and returns the state before and after it
+func (cb *CircuitBreaker) recordResult(err error) (State, State) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
+	from := cb.state
 
 	switch cb.state {
 	case StateClosed:
@@ -150,17 +239,22 @@ func (cb *CircuitBreaker) RecordResult(err error) {
 		}
 		// No action needed if StateOpen, as requests shouldn't reach RecordResult then.
 	}
+	return from, cb.state
 }
 
 // Reset resets the circuit breaker to the closed state
 func (cb *CircuitBreaker) Reset() {
 	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
-
+	from := cb.state
 	cb.state = StateClosed
 	cb.failures = 0
 	cb.halfOpenCount = 0
 	cb.lastFailure = time.Time{} // Reset last failure time
+	cb.mutex.Unlock()
+
+	if from != StateClosed {
+		cb.notify(from, StateClosed)
+	}
 }
 
 // State returns the current state of the circuit breaker
@@ -187,3 +281,32 @@ func (cb *CircuitBreaker) State() State {
 func (cb *CircuitBreaker) Name() string {
 	return cb.name
 }
+
+// Counts holds the outcomes of the requests through a circuit breaker
+type Counts struct {
+	Successes  uint64 `json:"successes"`
+	Failures   uint64 `json:"failures"`
+	Rejections uint64 `json:"rejections"` // requests refused while open
+}
+
+// Counts returns the outcomes of the requests since the circuit breaker was created
+func (cb *CircuitBreaker) Counts() Counts {
+	return Counts{
+		Successes:  cb.successes.Load(),
+		Failures:   cb.failed.Load(),
+		Rejections: cb.rejections.Load(),
+	}
+}
+
+// notify calls the state change hooks of the circuit breaker and of its registry. It must
+// not be called with cb.mutex held, so hooks can inspect the circuit breaker.
+func (cb *CircuitBreaker) notify(from, to State) {
+	cb.registry.recordStateChange(cb.name, from, to)
+
+	cb.hooksMu.RLock()
+	hooks := append([]StateChangeFunc(nil), cb.hooks...)
+	cb.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(cb.name, from, to)
+	}
+}
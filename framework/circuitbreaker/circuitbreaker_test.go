@@ -5,9 +5,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/clock"
+
 	"github.com/stretchr/testify/assert"
 )
 
+// manualClock is the system clock with a time that only moves when told to
+type manualClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
 func TestCircuitBreaker(t *testing.T) {
 	opts := DefaultOptions()
 	opts.MaxFailures = 2
@@ -74,3 +84,40 @@ func TestCircuitBreaker(t *testing.T) {
 		assert.Equal(t, "circuit breaker is open", err.Error())
 	})
 }
+
+func TestResetTimeoutClock(t *testing.T) {
+	clk := &manualClock{Clock: clock.System, now: time.Now()}
+	cb := NewRegistry().New(Options{Name: "clock", MaxFailures: 1, ResetTimeout: time.Minute, Clock: clk})
+
+	cb.RecordResult(errors.New("fail"))
+	assert.Equal(t, StateOpen, cb.State())
+
+	clk.now = clk.now.Add(time.Minute)
+	assert.False(t, cb.AllowRequest(), "open until the reset timeout passed")
+
+	clk.now = clk.now.Add(time.Second)
+	assert.True(t, cb.AllowRequest())
+	assert.Equal(t, StateHalfOpen, cb.State())
+}
+
+func BenchmarkCircuitBreakerExecute(b *testing.B) {
+	cb := NewRegistry().New(DefaultOptions())
+	fn := func() error { return nil }
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = cb.Execute(fn)
+	}
+}
+
+func BenchmarkCircuitBreakerExecuteParallel(b *testing.B) {
+	cb := NewRegistry().New(DefaultOptions())
+	fn := func() error { return nil }
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = cb.Execute(fn)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Module reports the circuit breakers of the default registry in logs and metrics
+var Module = fx.Options(
+	fx.Invoke(ObserveDefaultRegistry),
+)
+
+// ObserveDefaultRegistry logs the state changes of the circuit breakers in the default
+// registry and records them on metrics
+func ObserveDefaultRegistry(logger *observability.Logger, metrics *observability.Metrics) {
+	DefaultRegistry.Observe(logger, metrics)
+}
+
+// Snapshot describes a circuit breaker at a point in time
+type Snapshot struct {
+	Name   string `json:"name"`
+	State  State  `json:"state"`
+	Counts Counts `json:"counts"`
+}
+
+// Registry tracks circuit breakers by name, so their states can be listed and observed
+// together. A circuit breaker replaces an earlier one of the same name.
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+	hooks    []StateChangeFunc
+	logger   *observability.Logger
+	metrics  *observability.Metrics
+}
+
+// NewRegistry creates a new registry
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*CircuitBreaker)}
+}
+
+// DefaultRegistry is the registry of the circuit breakers created with New
+var DefaultRegistry = NewRegistry()
+
+// New creates a circuit breaker and adds it to the registry
+func (r *Registry) New(options Options) *CircuitBreaker {
+	cb := newCircuitBreaker(options)
+	cb.registry = r
+
+	r.mu.Lock()
+	r.breakers[cb.name] = cb
+	metrics := r.metrics
+	r.mu.Unlock()
+
+	if metrics != nil && metrics.CircuitBreakerState != nil {
+		metrics.CircuitBreakerState.WithLabelValues(cb.name).Set(float64(StateClosed))
+	}
+	return cb
+}
+
+// Get returns the circuit breaker with a name
+func (r *Registry) Get(name string) (*CircuitBreaker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cb, ok := r.breakers[name]
+	return cb, ok
+}
+
+// Snapshots returns the state of every circuit breaker, sorted by name
+func (r *Registry) Snapshots() []Snapshot {
+	r.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.RUnlock()
+
+	snapshots := make([]Snapshot, len(breakers))
+	for i, cb := range breakers {
+		snapshots[i] = Snapshot{Name: cb.Name(), State: cb.State(), Counts: cb.Counts()}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+	return snapshots
+}
+
+// OnStateChange adds a function called after any circuit breaker of the registry changed
+// state, e.g. to alert when one opens
+func (r *Registry) OnStateChange(fn StateChangeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// Observe logs state changes to logger and records states, transitions and outcomes on
+// metrics. It replaces the logger and metrics of an earlier call.
+func (r *Registry) Observe(logger *observability.Logger, metrics *observability.Metrics) {
+	r.mu.Lock()
+	r.logger = logger
+	r.metrics = metrics
+	r.mu.Unlock()
+
+	if metrics == nil || metrics.CircuitBreakerState == nil {
+		return
+	}
+	for _, snapshot := range r.Snapshots() {
+		metrics.CircuitBreakerState.WithLabelValues(snapshot.Name).Set(float64(snapshot.State))
+	}
+}
+
+// observers returns the logger, metrics and hooks of the registry
+func (r *Registry) observers() (*observability.Logger, *observability.Metrics, []StateChangeFunc) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.logger, r.metrics, append([]StateChangeFunc(nil), r.hooks...)
+}
+
+// observedMetrics returns the metrics of the registry
+func (r *Registry) observedMetrics() *observability.Metrics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.metrics
+}
+
+// recordStateChange reports a state change of one of the registry's circuit breakers
+func (r *Registry) recordStateChange(name string, from, to State) {
+	if r == nil {
+		return
+	}
+	logger, metrics, hooks := r.observers()
+
+	if logger != nil {
+		fields := []zap.Field{zap.String("circuit_breaker", name), zap.Stringer("from", from), zap.Stringer("to", to)}
+		if to == StateOpen {
+			logger.Warn("Circuit breaker opened", fields...)
+		} else {
+			logger.Info("Circuit breaker changed state", fields...)
+		}
+	}
+	if metrics != nil && metrics.CircuitBreakerState != nil {
+		metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(to))
+		metrics.CircuitBreakerTransitionsTotal.WithLabelValues(name, from.String(), to.String()).Inc()
+	}
+	for _, hook := range hooks {
+		hook(name, from, to)
+	}
+}
+
+// recordResult counts the outcome of a request through one of the registry's circuit breakers
+func (r *Registry) recordResult(name string, err error) {
+	if r == nil {
+		return
+	}
+	metrics := r.observedMetrics()
+	if metrics == nil || metrics.CircuitBreakerRequestsTotal == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	metrics.CircuitBreakerRequestsTotal.WithLabelValues(name, result).Inc()
+}
+
+// recordRejection counts a request refused by one of the registry's circuit breakers
+func (r *Registry) recordRejection(name string) {
+	if r == nil {
+		return
+	}
+	metrics := r.observedMetrics()
+	if metrics == nil || metrics.CircuitBreakerRequestsTotal == nil {
+		return
+	}
+	metrics.CircuitBreakerRequestsTotal.WithLabelValues(name, "rejected").Inc()
+}
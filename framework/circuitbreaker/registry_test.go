@@ -0,0 +1,132 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transition is a recorded state change
+type transition struct {
+	name     string
+	from, to State
+}
+
+// recorder collects state changes
+type recorder struct {
+	mu          sync.Mutex
+	transitions []transition
+}
+
+func (r *recorder) record(name string, from, to State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, transition{name: name, from: from, to: to})
+}
+
+func (r *recorder) all() []transition {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]transition(nil), r.transitions...)
+}
+
+func TestStateChangeHooks(t *testing.T) {
+	registry := NewRegistry()
+	breakerHook, registryHook := &recorder{}, &recorder{}
+	registry.OnStateChange(registryHook.record)
+
+	cb := registry.New(Options{
+		Name:          "payments",
+		MaxFailures:   1,
+		ResetTimeout:  10 * time.Millisecond,
+		OnStateChange: breakerHook.record,
+	})
+	// Hooks may inspect the breaker without deadlocking
+	cb.OnStateChange(func(name string, from, to State) {
+		assert.Equal(t, to, cb.State())
+	})
+
+	cb.RecordResult(errors.New("fail"))
+	assert.False(t, cb.AllowRequest())
+	time.Sleep(15 * time.Millisecond)
+	assert.True(t, cb.AllowRequest())
+	cb.RecordResult(nil)
+	cb.Reset() // already closed, no change
+
+	want := []transition{
+		{name: "payments", from: StateClosed, to: StateOpen},
+		{name: "payments", from: StateOpen, to: StateHalfOpen},
+		{name: "payments", from: StateHalfOpen, to: StateClosed},
+	}
+	assert.Equal(t, want, breakerHook.all())
+	assert.Equal(t, want, registryHook.all())
+	assert.Equal(t, Counts{Successes: 1, Failures: 1, Rejections: 1}, cb.Counts())
+}
+
+func TestRegistrySnapshots(t *testing.T) {
+	registry := NewRegistry()
+	orders := registry.New(Options{Name: "orders", MaxFailures: 1, ResetTimeout: time.Minute})
+	registry.New(Options{Name: "inventory", MaxFailures: 1, ResetTimeout: time.Minute})
+	orders.RecordResult(errors.New("fail"))
+
+	got, ok := registry.Get("orders")
+	require.True(t, ok)
+	assert.Same(t, orders, got)
+	assert.Equal(t, []Snapshot{
+		{Name: "inventory", State: StateClosed},
+		{Name: "orders", State: StateOpen, Counts: Counts{Failures: 1}},
+	}, registry.Snapshots())
+
+	// A breaker replaces an earlier one of the same name
+	replacement := registry.New(Options{Name: "orders", MaxFailures: 1})
+	got, _ = registry.Get("orders")
+	assert.Same(t, replacement, got)
+	assert.Len(t, registry.Snapshots(), 2)
+}
+
+func TestRegistryMetrics(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+
+	registry := NewRegistry()
+	cb := registry.New(Options{Name: "search", MaxFailures: 2, ResetTimeout: time.Minute})
+	registry.Observe(logger, metrics)
+
+	assert.NoError(t, cb.Execute(func() error { return nil }))
+	assert.Error(t, cb.Execute(func() error { return errors.New("fail") }))
+	assert.Error(t, cb.Execute(func() error { return errors.New("fail") }))
+	assert.ErrorIs(t, cb.Execute(func() error { return nil }), ErrOpen)
+
+	assert.Equal(t, float64(StateOpen), testutil.ToFloat64(metrics.CircuitBreakerState.WithLabelValues("search")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.CircuitBreakerTransitionsTotal.WithLabelValues("search", "closed", "open")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.CircuitBreakerRequestsTotal.WithLabelValues("search", "success")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.CircuitBreakerRequestsTotal.WithLabelValues("search", "failure")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.CircuitBreakerRequestsTotal.WithLabelValues("search", "rejected")))
+}
+
+func TestStateString(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{StateClosed, "closed"},
+		{StateOpen, "open"},
+		{StateHalfOpen, "half-open"},
+		{State(7), "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.state.String())
+		})
+	}
+}
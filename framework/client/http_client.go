@@ -4,32 +4,61 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/axiomod/axiomod/framework/circuitbreaker"
+	"github.com/axiomod/axiomod/framework/metering"
+	"github.com/axiomod/axiomod/framework/resilience"
 )
 
-// HTTPClient is a resilient HTTP client with circuit breaker, retries, and timeouts
+// HTTPClient is a resilient HTTP client with circuit breakers, retries, and timeouts.
+// Each destination host gets its own circuit breaker, so one failing downstream does not
+// reject the requests to every other one. Requests are retried after transport errors and
+// transient statuses (429, 502, 503, 504) when their method is idempotent or they carry an
+// Idempotency-Key header.
 type HTTPClient struct {
 	client         *http.Client
-	circuitBreaker *circuitbreaker.CircuitBreaker
+	breakerOptions circuitbreaker.Options
+	breakers       map[string]*circuitbreaker.CircuitBreaker
+	mu             sync.Mutex
 	maxRetries     int
 	retryDelay     time.Duration
+	maxRetryAfter  time.Duration
+	retryBudget    *RetryBudget
+	hedger         *resilience.Hedger
 }
 
 // Options contains options for creating a new HTTPClient
 type Options struct {
 	// Timeout is the timeout for HTTP requests
 	Timeout time.Duration
-	// CircuitBreakerOptions contains options for the circuit breaker
+	// CircuitBreakerOptions contains options for the circuit breaker of each host
 	CircuitBreakerOptions circuitbreaker.Options
 	// MaxRetries is the maximum number of retries for failed requests
 	MaxRetries int
 	// RetryDelay is the delay between retries
 	RetryDelay time.Duration
+	// MaxRetryAfter is the longest Retry-After delay the client waits for before retrying;
+	// a response asking for longer is returned as is. Zero waits as long as asked.
+	MaxRetryAfter time.Duration
+	// RetryBudget limits retries to a share of the requests; nil leaves them unlimited
+	RetryBudget *RetryBudget
+	// Transport sends the requests; defaults to http.DefaultTransport
+	Transport http.RoundTripper
+	// Middleware wraps the transport. The first middleware sees each attempt first, and
+	// retries go through the whole chain again.
+	Middleware []Middleware
+	// DisableContextPropagation stops the client from forwarding the request ID, tenant, user,
+	// locale and deadline of the request context, as the Context middleware does by default
+	DisableContextPropagation bool
+	// Hedge sends another attempt of GET and HEAD requests that are slow to respond and
+	// uses the first response; nil disables it. Its Discard option is set by the client.
+	Hedge *resilience.HedgeOptions
 }
 
 // DefaultOptions returns the default options for an HTTP client
@@ -39,24 +68,79 @@ func DefaultOptions() Options {
 		CircuitBreakerOptions: circuitbreaker.DefaultOptions(),
 		MaxRetries:            3,
 		RetryDelay:            100 * time.Millisecond,
+		MaxRetryAfter:         10 * time.Second,
+		RetryBudget:           NewRetryBudget(0.2, 10),
 	}
 }
 
 // New creates a new HTTPClient with the given options
 func New(options Options) *HTTPClient {
-	return &HTTPClient{
+	transport := options.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	middleware := options.Middleware
+	if !options.DisableContextPropagation {
+		middleware = append([]Middleware{Context()}, middleware...)
+	}
+	c := &HTTPClient{
 		client: &http.Client{
-			Timeout: options.Timeout,
+			Timeout:   options.Timeout,
+			Transport: Chain(transport, middleware...),
 		},
-		circuitBreaker: circuitbreaker.New(options.CircuitBreakerOptions),
+		breakerOptions: options.CircuitBreakerOptions,
+		breakers:       make(map[string]*circuitbreaker.CircuitBreaker),
 		maxRetries:     options.MaxRetries,
 		retryDelay:     options.RetryDelay,
+		maxRetryAfter:  options.MaxRetryAfter,
+		retryBudget:    options.RetryBudget,
+	}
+	if options.Hedge != nil {
+		hedge := *options.Hedge
+		hedge.Discard = discardResponse
+		c.hedger = resilience.NewHedger(&hedge)
 	}
+	return c
 }
 
-// Get performs a GET request with circuit breaker and retry logic
-func (c *HTTPClient) Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// CircuitBreaker returns the circuit breaker of a host, such as "api.example.com:8443",
+// creating it on first use
+func (c *HTTPClient) CircuitBreaker(host string) *circuitbreaker.CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cb, ok := c.breakers[host]; ok {
+		return cb
+	}
+	options := c.breakerOptions
+	if options.Name == "" {
+		options.Name = host
+	} else {
+		options.Name += ":" + host
+	}
+	cb := circuitbreaker.New(options)
+	c.breakers[host] = cb
+	return cb
+}
+
+// StatusError is returned by the JSON helpers for responses outside the 2xx range
+type StatusError struct {
+	StatusCode int
+	// Body holds the start of the response body, which often explains the error
+	Body []byte
+}
+
+// Error returns the error message
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// maxErrorBody bounds how much of an error response is kept in a StatusError
+const maxErrorBody = 64 * 1024
+
+// newRequest creates a request with the given headers
+func newRequest(ctx context.Context, method, url string, headers map[string]string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -65,111 +149,228 @@ func (c *HTTPClient) Get(ctx context.Context, url string, headers map[string]str
 	for key, value := range headers {
 		req.Header.Add(key, value)
 	}
-
-	return c.doWithRetry(req)
+	return req, nil
 }
 
-// GetJSON performs a GET request and unmarshals the response into the given value
-func (c *HTTPClient) GetJSON(ctx context.Context, url string, headers map[string]string, v interface{}) error {
-	resp, err := c.Get(ctx, url, headers)
+// Get performs a GET request with circuit breaker and retry logic
+func (c *HTTPClient) Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	req, err := newRequest(ctx, http.MethodGet, url, headers, nil)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, err
 	}
-
-	return json.NewDecoder(resp.Body).Decode(v)
+	return c.send(req)
 }
 
-// Post performs a POST request with circuit breaker and retry logic
+// Post performs a POST request with circuit breaker and retry logic. It is retried only
+// when it carries an Idempotency-Key header.
 func (c *HTTPClient) Post(ctx context.Context, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	req, err := newRequest(ctx, http.MethodPost, url, headers, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers
-	for key, value := range headers {
-		req.Header.Add(key, value)
+		return nil, err
 	}
-
 	return c.doWithRetry(req)
 }
 
+// GetJSON performs a GET request and unmarshals the response into the given value
+func (c *HTTPClient) GetJSON(ctx context.Context, url string, headers map[string]string, v interface{}) error {
+	return c.doJSON(ctx, http.MethodGet, url, headers, nil, v)
+}
+
 // PostJSON performs a POST request with a JSON body and unmarshals the response into the given value
 func (c *HTTPClient) PostJSON(ctx context.Context, url string, headers map[string]string, body interface{}, v interface{}) error {
-	// Marshal the body to JSON
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("failed to marshal body: %w", err)
+	return c.doJSON(ctx, http.MethodPost, url, headers, body, v)
+}
+
+// PutJSON performs a PUT request with a JSON body and unmarshals the response into the given value
+func (c *HTTPClient) PutJSON(ctx context.Context, url string, headers map[string]string, body interface{}, v interface{}) error {
+	return c.doJSON(ctx, http.MethodPut, url, headers, body, v)
+}
+
+// PatchJSON performs a PATCH request with a JSON body and unmarshals the response into the given value
+func (c *HTTPClient) PatchJSON(ctx context.Context, url string, headers map[string]string, body interface{}, v interface{}) error {
+	return c.doJSON(ctx, http.MethodPatch, url, headers, body, v)
+}
+
+// DeleteJSON performs a DELETE request and unmarshals the response, if any, into the given value
+func (c *HTTPClient) DeleteJSON(ctx context.Context, url string, headers map[string]string, v interface{}) error {
+	return c.doJSON(ctx, http.MethodDelete, url, headers, nil, v)
+}
+
+// DoJSON performs a request with body, if not nil, encoded as JSON and decodes the JSON response into a T.
+// Responses outside the 2xx range fail with a *StatusError.
+func DoJSON[T any](ctx context.Context, c *HTTPClient, method, url string, headers map[string]string, body interface{}) (T, error) {
+	var result T
+	err := c.doJSON(ctx, method, url, headers, body, &result)
+	return result, err
+}
+
+// doJSON performs a JSON request and unmarshals the response into v when it is not nil
+func (c *HTTPClient) doJSON(ctx context.Context, method, url string, headers map[string]string, body interface{}, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal body: %w", err)
+		}
+		// A bytes.Reader lets the request be replayed on retries
+		reader = bytes.NewReader(bodyBytes)
 	}
 
-	// Add content type header
-	if headers == nil {
-		headers = make(map[string]string)
+	req, err := newRequest(ctx, method, url, headers, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
 	}
-	headers["Content-Type"] = "application/json"
 
-	resp, err := c.Post(ctx, url, headers, bytes.NewReader(bodyBytes))
+	resp, err := c.send(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{StatusCode: resp.StatusCode, Body: data}
 	}
 
-	if v != nil {
-		return json.NewDecoder(resp.Body).Decode(v)
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
-
 	return nil
 }
 
+// Do sends a prepared request with circuit breaker and retry logic
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return c.send(req)
+}
+
+// errTransientStatus marks a hedged attempt answered with a retryable status, so that a
+// parallel attempt can still win
+var errTransientStatus = errors.New("transient status")
+
+// send performs a request, hedged when hedging is enabled and the request is a read
+func (c *HTTPClient) send(req *http.Request) (*http.Response, error) {
+	if c.hedger == nil || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return c.doWithRetry(req)
+	}
+
+	result, err := c.hedger.Execute(req.Context(), func(ctx context.Context) (interface{}, error) {
+		resp, err := c.doWithRetry(req.Clone(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if isRetryableStatus(resp.StatusCode) {
+			return resp, errTransientStatus
+		}
+		return resp, nil
+	})
+	if err != nil && !errors.Is(err, errTransientStatus) {
+		return nil, err
+	}
+	return result.(*http.Response), nil
+}
+
+// discardResponse releases the connection of a response that is not used
+func discardResponse(result interface{}) {
+	if resp, ok := result.(*http.Response); ok {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+	}
+}
+
 // doWithRetry performs an HTTP request with circuit breaker and retry logic
 func (c *HTTPClient) doWithRetry(req *http.Request) (*http.Response, error) {
-	var resp *http.Response
-	var err error
+	ctx := req.Context()
+	retryable := isRetryable(req)
+	if c.retryBudget != nil {
+		c.retryBudget.RecordRequest()
+	}
 
-	// Execute with circuit breaker
-	err = c.circuitBreaker.Execute(func() error {
-		// Retry logic
-		for i := 0; i <= c.maxRetries; i++ {
-			// Clone the request for each retry to ensure it can be reused
-			reqClone := req.Clone(req.Context())
+	// Execute with the circuit breaker of the destination host
+	cb := c.CircuitBreaker(req.URL.Host)
+	if !cb.AllowRequest() {
+		return nil, fmt.Errorf("%s: %w", req.URL.Host, circuitbreaker.ErrOpen)
+	}
+
+	var resp *http.Response
+	err := func() error {
+		for attempt := 0; ; attempt++ {
+			attemptReq, err := newAttempt(req, attempt)
+			if err != nil {
+				return err
+			}
 
-			resp, err = c.client.Do(reqClone)
+			start := time.Now()
+			resp, err = c.client.Do(attemptReq)
+			metering.RecordDownstreamCall(ctx, time.Since(start))
 
-			// If successful or context canceled, return immediately
-			if err == nil || req.Context().Err() != nil {
+			// Return successes, permanent failures and the last attempt as they are
+			failed := err != nil || isRetryableStatus(resp.StatusCode)
+			if !failed || !retryable || attempt == c.maxRetries || ctx.Err() != nil {
 				return err
 			}
 
-			// If this was the last retry, return the error
-			if i == c.maxRetries {
+			delay := c.retryDelay
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				if c.maxRetryAfter > 0 && after > c.maxRetryAfter {
+					return err
+				}
+				if after > delay {
+					delay = after
+				}
+			}
+			if c.retryBudget != nil && !c.retryBudget.TryRetry() {
 				return err
 			}
 
+			// Release the connection of the failed attempt before retrying
+			if resp != nil {
+				io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+				resp.Body.Close()
+				resp = nil
+			}
+
 			// Wait before retrying
+			timer := time.NewTimer(delay)
 			select {
-			case <-time.After(c.retryDelay):
+			case <-timer.C:
 				// Continue to next retry
-			case <-req.Context().Done():
+			case <-ctx.Done():
 				// Context canceled, return immediately
-				return req.Context().Err()
+				timer.Stop()
+				return ctx.Err()
 			}
 		}
+	}()
 
-		return err
-	})
-
+	// Requests canceled by the caller, such as hedges that lost, say nothing about the host
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		cb.RecordResult(err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return resp, nil
 }
+
+// newAttempt clones the request for an attempt, with a fresh body for retries
+func newAttempt(req *http.Request, attempt int) (*http.Request, error) {
+	attemptReq := req.Clone(req.Context())
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		attemptReq.Body = body
+	}
+	return attemptReq, nil
+}
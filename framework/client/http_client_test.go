@@ -0,0 +1,389 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/circuitbreaker"
+	"github.com/axiomod/axiomod/framework/ctxkit"
+	"github.com/axiomod/axiomod/framework/resilience"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport fails requests to hosts listed in down and records the rest
+type fakeTransport struct {
+	mu       sync.Mutex
+	down     map[string]bool
+	requests []*http.Request
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	if f.down[req.URL.Host] {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+		Header:     http.Header{},
+		Request:    req,
+	}, nil
+}
+
+func newTestClient(transport http.RoundTripper, middleware ...Middleware) *HTTPClient {
+	options := DefaultOptions()
+	options.MaxRetries = 1
+	options.RetryDelay = time.Millisecond
+	options.CircuitBreakerOptions.MaxFailures = 2
+	options.CircuitBreakerOptions.ResetTimeout = time.Minute
+	options.Transport = transport
+	options.Middleware = middleware
+	return New(options)
+}
+
+func TestPerHostCircuitBreakers(t *testing.T) {
+	transport := &fakeTransport{down: map[string]bool{"flaky.local": true}}
+	c := newTestClient(transport)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(ctx, "http://flaky.local/items", nil)
+		require.Error(t, err)
+	}
+	assert.Equal(t, circuitbreaker.StateOpen, c.CircuitBreaker("flaky.local").State())
+	assert.Equal(t, "default:flaky.local", c.CircuitBreaker("flaky.local").Name())
+
+	// The open breaker rejects requests without sending them
+	sent := len(transport.requests)
+	_, err := c.Get(ctx, "http://flaky.local/items", nil)
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+	assert.Contains(t, err.Error(), "flaky.local")
+	assert.Len(t, transport.requests, sent)
+
+	// Other hosts are unaffected
+	var result map[string]bool
+	require.NoError(t, c.GetJSON(ctx, "http://healthy.local/items", nil, &result))
+	assert.True(t, result["ok"])
+	assert.Equal(t, circuitbreaker.StateClosed, c.CircuitBreaker("healthy.local").State())
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	transport := &fakeTransport{down: map[string]bool{"flaky.local": true}}
+	c := newTestClient(transport,
+		trace("outer"),
+		trace("inner"),
+		Header("X-Client", "axiomod"),
+		BearerToken(func(ctx context.Context) (string, error) { return "s3cr3t", nil }),
+	)
+
+	resp, err := c.Get(context.Background(), "http://api.local/items", map[string]string{"X-Client": "custom"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	require.Len(t, transport.requests, 1)
+	assert.Equal(t, "custom", transport.requests[0].Header.Get("X-Client"), "explicit headers win")
+	assert.Equal(t, "Bearer s3cr3t", transport.requests[0].Header.Get("Authorization"))
+
+	// Every retry passes through the chain again
+	order = nil
+	_, err = c.Get(context.Background(), "http://flaky.local/items", nil)
+	require.Error(t, err)
+	assert.Equal(t, []string{"outer", "inner", "outer", "inner"}, order)
+	assert.Equal(t, "axiomod", transport.requests[1].Header.Get("X-Client"))
+}
+
+func TestBearerTokenError(t *testing.T) {
+	transport := &fakeTransport{}
+	c := newTestClient(transport, BearerToken(func(ctx context.Context) (string, error) {
+		return "", errors.New("token expired")
+	}))
+
+	_, err := c.Get(context.Background(), "http://api.local/items", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token expired")
+	assert.Empty(t, transport.requests, "requests are not sent without a token")
+}
+
+func TestContextPropagation(t *testing.T) {
+	ctx := ctxkit.WithRequestID(context.Background(), "req-1")
+	ctx = ctxkit.WithTenantID(ctx, "acme")
+	ctx = ctxkit.WithUserID(ctx, "user-7")
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	transport := &fakeTransport{}
+	c := newTestClient(transport)
+	resp, err := c.Get(ctx, "http://api.local/items", map[string]string{ctxkit.HeaderTenantID: "explicit"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Len(t, transport.requests, 1)
+	header := transport.requests[0].Header
+	assert.Equal(t, "req-1", header.Get(ctxkit.HeaderRequestID))
+	assert.Equal(t, "explicit", header.Get(ctxkit.HeaderTenantID), "explicit headers win")
+	assert.Equal(t, "user-7", header.Get(ctxkit.HeaderUserID))
+	assert.Empty(t, header.Get(ctxkit.HeaderLocale))
+	timeout, err := strconv.Atoi(header.Get(ctxkit.HeaderTimeout))
+	require.NoError(t, err)
+	assert.InDelta(t, 10000, timeout, 1000)
+
+	options := DefaultOptions()
+	options.Transport = transport
+	options.DisableContextPropagation = true
+	resp, err = New(options).Get(ctx, "http://api.local/items", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Len(t, transport.requests, 2)
+	assert.Empty(t, transport.requests[1].Header.Get(ctxkit.HeaderRequestID))
+}
+
+// scriptedTransport answers requests with the given statuses in turn, then with 200
+type scriptedTransport struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	body     string
+	bodies   []string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(data))
+	} else {
+		s.bodies = append(s.bodies, "")
+	}
+	status := http.StatusOK
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	header := http.Header{}
+	if status != http.StatusOK {
+		for key, values := range s.header {
+			header[key] = values
+		}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(s.body)),
+		Header:     header,
+		Request:    req,
+	}, nil
+}
+
+func (s *scriptedTransport) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func TestRetryAfter(t *testing.T) {
+	transport := &scriptedTransport{
+		statuses: []int{http.StatusServiceUnavailable},
+		header:   http.Header{"Retry-After": []string{"1"}},
+		body:     `{}`,
+	}
+	c := newTestClient(transport)
+
+	start := time.Now()
+	resp, err := c.Get(context.Background(), "http://api.local/items", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, 2, transport.attempts())
+
+	// A response asking for more than MaxRetryAfter is returned without retrying
+	transport = &scriptedTransport{
+		statuses: []int{http.StatusTooManyRequests},
+		header:   http.Header{"Retry-After": []string{"3600"}},
+	}
+	c = newTestClient(transport)
+	resp, err = c.Get(context.Background(), "http://api.local/items", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, 1, transport.attempts())
+}
+
+func TestRetryRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		attempts int
+	}{
+		{name: "PUT is retried", method: http.MethodPut, attempts: 2},
+		{name: "DELETE is retried", method: http.MethodDelete, attempts: 2},
+		{name: "POST is not retried", method: http.MethodPost, attempts: 1},
+		{name: "PATCH is not retried", method: http.MethodPatch, attempts: 1},
+		{
+			name:     "POST with an idempotency key is retried",
+			method:   http.MethodPost,
+			headers:  map[string]string{IdempotencyKeyHeader: "order-42"},
+			attempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &scriptedTransport{statuses: []int{http.StatusBadGateway}, body: `{}`}
+			c := newTestClient(transport)
+
+			_, err := DoJSON[map[string]string](context.Background(), c, tt.method, "http://api.local/orders", tt.headers, map[string]int{"qty": 1})
+			if tt.attempts == 1 {
+				var statusErr *StatusError
+				require.ErrorAs(t, err, &statusErr)
+				assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.attempts, transport.attempts())
+			for _, body := range transport.bodies {
+				assert.JSONEq(t, `{"qty":1}`, body, "every attempt sends the whole body")
+			}
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(0.5, 1)
+	for i := 0; i < 2; i++ {
+		budget.RecordRequest()
+	}
+	// 0.5 * 2 requests + 1 = 2 retries
+	assert.True(t, budget.TryRetry())
+	assert.True(t, budget.TryRetry())
+	assert.False(t, budget.TryRetry())
+
+	// An exhausted budget returns the failed response instead of retrying
+	transport := &scriptedTransport{statuses: []int{http.StatusServiceUnavailable}}
+	options := DefaultOptions()
+	options.RetryDelay = time.Millisecond
+	options.RetryBudget = NewRetryBudget(0, 1)
+	options.Transport = transport
+	c := New(options)
+
+	resp, err := c.Get(context.Background(), "http://api.local/items", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, transport.attempts())
+
+	transport.statuses = []int{http.StatusServiceUnavailable}
+	resp, err = c.Get(context.Background(), "http://api.local/items", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 3, transport.attempts())
+}
+
+func TestJSONHelpers(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	transport := &scriptedTransport{body: `{"id":7,"name":"widget"}`}
+	c := newTestClient(transport)
+	ctx := context.Background()
+
+	got, err := DoJSON[item](ctx, c, http.MethodGet, "http://api.local/items/7", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, item{ID: 7, Name: "widget"}, got)
+
+	headers := map[string]string{"X-Request-ID": "abc"}
+	var updated item
+	require.NoError(t, c.PutJSON(ctx, "http://api.local/items/7", headers, item{Name: "gadget"}, &updated))
+	require.NoError(t, c.PatchJSON(ctx, "http://api.local/items/7", headers, map[string]string{"name": "gizmo"}, nil))
+	require.NoError(t, c.DeleteJSON(ctx, "http://api.local/items/7", headers, nil))
+	assert.Equal(t, map[string]string{"X-Request-ID": "abc"}, headers, "caller headers are not modified")
+
+	transport = &scriptedTransport{statuses: []int{http.StatusNotFound}, body: `{"error":"not found"}`}
+	c = newTestClient(transport)
+	_, err = DoJSON[item](ctx, c, http.MethodGet, "http://api.local/items/8", nil, nil)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.JSONEq(t, `{"error":"not found"}`, string(statusErr.Body))
+	assert.Equal(t, 1, transport.attempts(), "permanent failures are not retried")
+}
+
+// stallingTransport holds the first request until it is canceled and answers the rest at once
+type stallingTransport struct {
+	mu       sync.Mutex
+	requests int
+	closed   chan struct{}
+}
+
+func (s *stallingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.requests++
+	first := s.requests == 1
+	s.mu.Unlock()
+
+	if first {
+		<-req.Context().Done()
+		close(s.closed)
+		return nil, req.Context().Err()
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+		Header:     http.Header{},
+		Request:    req,
+	}, nil
+}
+
+func TestHedgedRequests(t *testing.T) {
+	transport := &stallingTransport{closed: make(chan struct{})}
+	options := DefaultOptions()
+	options.CircuitBreakerOptions.MaxFailures = 1
+	options.Transport = transport
+	options.Hedge = &resilience.HedgeOptions{Delay: 10 * time.Millisecond}
+	c := New(options)
+
+	var got struct{ OK bool }
+	require.NoError(t, c.GetJSON(context.Background(), "http://api.local/items", nil, &got))
+	assert.True(t, got.OK)
+
+	// The stalled attempt is canceled without counting against the host
+	select {
+	case <-transport.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the losing attempt was not canceled")
+	}
+	assert.Equal(t, circuitbreaker.StateClosed, c.CircuitBreaker("api.local").State())
+
+	// Writes are never hedged
+	transport = &stallingTransport{closed: make(chan struct{})}
+	options.Transport = transport
+	c = New(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Post(ctx, "http://api.local/items", nil, strings.NewReader(`{}`))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, transport.requests)
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/ctxkit"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Middleware wraps a transport to act on every request and response, for example to add
+// headers, log or trace requests
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps transport in the middleware; the first middleware is the outermost
+func Chain(transport http.RoundTripper, middleware ...Middleware) http.RoundTripper {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+	return transport
+}
+
+// Header sets a header on requests that do not already have it
+func Header(key, value string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(key) != "" {
+				return next.RoundTrip(req)
+			}
+			// A RoundTripper must not modify the request it was given
+			req = req.Clone(req.Context())
+			req.Header.Set(key, value)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Context forwards the request ID, tenant, user and locale of the request context as headers,
+// and the time left before its deadline as the timeout header. Headers already set on the
+// request are kept.
+func Context() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			headers := ctxkit.Headers(ctx)
+			if timeout, ok := ctxkit.Timeout(ctx); ok {
+				headers[ctxkit.HeaderTimeout] = timeout
+			}
+			cloned := false
+			for key, value := range headers {
+				if req.Header.Get(key) != "" {
+					continue
+				}
+				if !cloned {
+					req = req.Clone(ctx)
+					cloned = true
+				}
+				req.Header.Set(key, value)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// BearerToken sets the Authorization header to a token obtained for each request, so
+// tokens can be refreshed or taken from the request context. Requests that already carry
+// an Authorization header are left alone.
+func BearerToken(token func(ctx context.Context) (string, error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "" {
+				return next.RoundTrip(req)
+			}
+			value, err := token(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("failed to obtain bearer token: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer "+value)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Logging logs every attempt at debug level and failed attempts at warn level
+func Logging(logger *observability.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			fields := []zap.Field{
+				zap.String("method", req.Method),
+				zap.String("host", req.URL.Host),
+				zap.String("path", req.URL.Path),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.Warn("Downstream request failed", append(fields, zap.Error(err))...)
+				return nil, err
+			}
+			fields = append(fields, zap.Int("status", resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				logger.Warn("Downstream request failed", fields...)
+			} else {
+				logger.Debug("Downstream request", fields...)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// Tracing records a client span for every attempt and propagates the trace context in the
+// request headers
+func Tracing(tracer *observability.Tracer) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Tracer.Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient))
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.host", req.URL.Host),
+				attribute.String("http.path", req.URL.Path),
+			)
+
+			req = req.Clone(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+			span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, resp.Status)
+			}
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader marks a request as safe to retry whatever its method
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// retryBudgetWindow is the period over which a retry budget compares retries to requests
+const retryBudgetWindow = 10 * time.Second
+
+// isRetryable reports whether a request may be sent again. Only idempotent methods are
+// retried, unless the request carries an idempotency key, and a body must be replayable.
+func isRetryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay requested by a Retry-After header, given in seconds or as an HTTP date
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// RetryBudget caps retries at a share of the requests sent over the last ten seconds, so
+// retries cannot multiply the load on a downstream that is already struggling. A few retries
+// are always allowed so that clients with little traffic can still retry.
+type RetryBudget struct {
+	ratio      float64
+	minRetries int
+	mu         sync.Mutex
+	buckets    [10]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// NewRetryBudget creates a retry budget allowing retries up to ratio of the requests, plus
+// minRetries in every window
+func NewRetryBudget(ratio float64, minRetries int) *RetryBudget {
+	return &RetryBudget{
+		ratio:      ratio,
+		minRetries: minRetries,
+	}
+}
+
+// bucket returns the bucket of the current second, clearing it if it holds an older one
+func (b *RetryBudget) bucket(now time.Time) *retryBudgetBucket {
+	second := now.Unix()
+	bucket := &b.buckets[second%int64(len(b.buckets))]
+	if bucket.second != second {
+		*bucket = retryBudgetBucket{second: second}
+	}
+	return bucket
+}
+
+// totals sums the buckets within the window
+func (b *RetryBudget) totals(now time.Time) (requests, retries int) {
+	oldest := now.Add(-retryBudgetWindow).Unix()
+	for _, bucket := range b.buckets {
+		if bucket.second > oldest {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	return requests, retries
+}
+
+// RecordRequest counts a first attempt
+func (b *RetryBudget) RecordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now()).requests++
+}
+
+// TryRetry reports whether a retry fits in the budget and counts it if so
+func (b *RetryBudget) TryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	requests, retries := b.totals(now)
+	if float64(retries) >= b.ratio*float64(requests)+float64(b.minRetries) {
+		return false
+	}
+	b.bucket(now).retries++
+	return true
+}
@@ -0,0 +1,83 @@
+// Package clock abstracts the passing of time, so that code waiting for it, such as the
+// schedules of the worker, the delays of retries and the expiry of tokens, can be tested
+// without sleeping. Production code uses System; tests use fake.Clock of testkit/fake.
+package clock
+
+import "time"
+
+// Clock tells the time and waits for it to pass
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+	// After returns a channel receiving the time once d passed
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a timer firing once d passed
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a ticker firing every d
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f in its own goroutine once d passed
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer of a Clock, such as a *time.Timer
+type Timer interface {
+	// C returns the channel receiving the time when the timer fires; nil for AfterFunc
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, reporting whether it was active
+	Stop() bool
+	// Reset makes the timer fire once d passed, reporting whether it was active
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a ticker of a Clock, such as a *time.Ticker
+type Ticker interface {
+	// C returns the channel receiving the time of the ticks
+	C() <-chan time.Time
+	// Stop turns the ticker off
+	Stop()
+	// Reset makes the ticker fire every d from now on
+	Reset(d time.Duration)
+}
+
+// System is the clock of the system, delegating to the time package
+var System Clock = systemClock{}
+
+// OrSystem returns c, or System if c is nil
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time        { return t.t.C }
+func (t systemTimer) Stop() bool                 { return t.t.Stop() }
+func (t systemTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time   { return t.t.C }
+func (t systemTicker) Stop()                 { t.t.Stop() }
+func (t systemTicker) Reset(d time.Duration) { t.t.Reset(d) }
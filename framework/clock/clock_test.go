@@ -0,0 +1,49 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystem(t *testing.T) {
+	before := time.Now()
+	now := System.Now()
+	assert.False(t, now.Before(before))
+	assert.GreaterOrEqual(t, System.Since(before), time.Duration(0))
+
+	select {
+	case <-System.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Fatal("After did not fire")
+	}
+
+	timer := System.NewTimer(time.Hour)
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Reset(time.Millisecond))
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("reset timer did not fire")
+	}
+
+	ticker := System.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	<-ticker.C()
+	<-ticker.C()
+
+	fired := make(chan struct{})
+	System.AfterFunc(time.Millisecond, func() { close(fired) })
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("AfterFunc did not fire")
+	}
+}
+
+func TestOrSystem(t *testing.T) {
+	assert.Equal(t, System, OrSystem(nil))
+	c := systemClock{}
+	assert.Equal(t, Clock(c), OrSystem(c))
+}
@@ -0,0 +1,15 @@
+package clock
+
+import "go.uber.org/fx"
+
+// Module provides System as the Clock of the application. The worker, the JWT service, the
+// resilience policies and the memory cache of tests run on it; tests replace it with a fake
+// clock through fx.Decorate.
+var Module = fx.Options(
+	fx.Provide(ProvideClock),
+)
+
+// ProvideClock provides the system clock
+func ProvideClock() Clock {
+	return System
+}
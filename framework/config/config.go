@@ -296,6 +296,15 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("unexpected provider type")
 	}
 
+	// Upgrade files written for older releases, so renamed keys are not silently ignored
+	warnings, err := migrateLoadedConfig(viperProvider.viper)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep gRPC reflection on for files written before it could be turned off
+	viperProvider.viper.SetDefault("grpc.enableReflection", true)
+
 	// Set default values (optional, Viper can also handle defaults)
 	// viperProvider.viper.SetDefault("app.name", "axiomod-viper-default")
 	// viperProvider.viper.SetDefault("app.environment", "development")
@@ -304,6 +313,7 @@ func Load(configPath string) (*Config, error) {
 	if err := viperProvider.viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	cfg.migrationWarnings = warnings
 
 	return &cfg, nil
 }
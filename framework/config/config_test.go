@@ -38,6 +38,7 @@ auth:
 		assert.Equal(t, "https://example.com", cfg.Auth.OIDC.IssuerURL)
 		assert.Equal(t, "test-client", cfg.Auth.OIDC.ClientID)
 		assert.Equal(t, 60, cfg.Auth.OIDC.JWKSCacheTTL)
+		assert.True(t, cfg.GRPC.EnableReflection, "reflection stays on when the file does not set it")
 	})
 
 	t.Run("Load non-existent config", func(t *testing.T) {
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the configuration schema version of this release. Files without a
+// configVersion key are treated as version 0.
+const CurrentVersion = 1
+
+// VersionKey is the top-level key holding the schema version of a configuration file
+const VersionKey = "configVersion"
+
+// KeyMove moves the value of a renamed or relocated key, optionally converting it
+type KeyMove struct {
+	From    string // dotted path of the old key
+	To      string // dotted path of the new key
+	Convert func(value interface{}) (interface{}, error)
+}
+
+// Migration upgrades configuration files to a schema version
+type Migration struct {
+	Version     int
+	Description string
+	Moves       []KeyMove
+}
+
+// migrations lists the schema changes between releases, in version order. Keys that are
+// renamed without a migration silently fall back to their defaults in existing files.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "flat JWT and tracing keys moved to their current names",
+		Moves: []KeyMove{
+			{From: "auth.jwtSecret", To: "auth.jwt.secretKey"},
+			{From: "auth.jwtDuration", To: "auth.jwt.tokenDuration", Convert: secondsToMinutes},
+			{From: "observability.tracingExporterURL", To: "observability.tracingURL"},
+			{From: "observability.tracingSamplingRatio", To: "observability.tracingSamplerRatio"},
+		},
+	},
+}
+
+// MigrationResult describes the changes made to a configuration document
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Warnings    []string
+}
+
+// Changed reports whether the document was modified
+func (r *MigrationResult) Changed() bool {
+	return r.FromVersion != r.ToVersion
+}
+
+// MigrateYAML upgrades a YAML configuration document to CurrentVersion, keeping its
+// comments and key order. Documents already at CurrentVersion are returned unchanged.
+func MigrateYAML(data []byte) ([]byte, *MigrationResult, error) {
+	result := &MigrationResult{ToVersion: CurrentVersion}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		result.FromVersion = CurrentVersion
+		return data, result, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config must be a mapping, got %s", nodeKind(root))
+	}
+
+	version, err := documentVersion(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.FromVersion = version
+	if version > CurrentVersion {
+		return nil, nil, fmt.Errorf("config version %d is newer than the latest supported version %d", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, result, nil
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+		for _, move := range migration.Moves {
+			warning, err := applyMove(root, move, migration.Version)
+			if err != nil {
+				return nil, nil, err
+			}
+			if warning != "" {
+				result.Warnings = append(result.Warnings, warning)
+			}
+		}
+	}
+	setVersion(root, CurrentVersion)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), result, nil
+}
+
+// migrateLoadedConfig upgrades the YAML file read by v in memory when it is at an older
+// version and returns the warnings to report
+func migrateLoadedConfig(v *viper.Viper) ([]string, error) {
+	file := v.ConfigFileUsed()
+	ext := strings.ToLower(filepath.Ext(file))
+	if file == "" || (ext != ".yaml" && ext != ".yml") {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	migrated, result, err := MigrateYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate config file %s: %w", file, err)
+	}
+	if !result.Changed() {
+		return nil, nil
+	}
+	if err := v.ReadConfig(bytes.NewReader(migrated)); err != nil {
+		return nil, fmt.Errorf("failed to read migrated config file %s: %w", file, err)
+	}
+
+	warnings := append(result.Warnings, fmt.Sprintf(
+		"config file %s is at version %d; run `axiomod config migrate %s` to upgrade it to version %d",
+		file, result.FromVersion, file, CurrentVersion))
+	return warnings, nil
+}
+
+// applyMove moves a key and returns a warning describing the change, if any
+func applyMove(root *yaml.Node, move KeyMove, version int) (string, error) {
+	from := strings.Split(move.From, ".")
+	parent, index := lookup(root, from)
+	if parent == nil {
+		return "", nil
+	}
+	key, value := parent.Content[index], parent.Content[index+1]
+	parent.Content = append(parent.Content[:index], parent.Content[index+2:]...)
+
+	to := strings.Split(move.To, ".")
+	if existing, _ := lookup(root, to); existing != nil {
+		return fmt.Sprintf("%s is ignored: %s is also set", move.From, move.To), nil
+	}
+
+	if move.Convert != nil {
+		var decoded interface{}
+		if err := value.Decode(&decoded); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", move.From, err)
+		}
+		converted, err := move.Convert(decoded)
+		if err != nil {
+			return "", fmt.Errorf("failed to convert %s: %w", move.From, err)
+		}
+		var node yaml.Node
+		if err := node.Encode(converted); err != nil {
+			return "", fmt.Errorf("failed to convert %s: %w", move.From, err)
+		}
+		node.HeadComment, node.LineComment, node.FootComment = value.HeadComment, value.LineComment, value.FootComment
+		value = &node
+	}
+
+	// Reuse the key node so its comments follow the value
+	key.Value = to[len(to)-1]
+	target := ensureMapping(root, to[:len(to)-1])
+	target.Content = append(target.Content, key, value)
+	return fmt.Sprintf("%s was renamed to %s in config version %d", move.From, move.To, version), nil
+}
+
+// lookup finds a dotted path and returns the mapping holding its last key and the index of
+// that key, or nil if the path does not exist. Keys match case-insensitively, like Viper.
+func lookup(node *yaml.Node, path []string) (*yaml.Node, int) {
+	for i, segment := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil, 0
+		}
+		index := keyIndex(node, segment)
+		if index < 0 {
+			return nil, 0
+		}
+		if i == len(path)-1 {
+			return node, index
+		}
+		node = node.Content[index+1]
+	}
+	return nil, 0
+}
+
+// ensureMapping returns the mapping at a path, creating the missing levels
+func ensureMapping(node *yaml.Node, path []string) *yaml.Node {
+	for _, segment := range path {
+		index := keyIndex(node, segment)
+		if index >= 0 && node.Content[index+1].Kind == yaml.MappingNode {
+			node = node.Content[index+1]
+			continue
+		}
+		child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if index >= 0 {
+			node.Content[index+1] = child
+		} else {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, child)
+		}
+		node = child
+	}
+	return node
+}
+
+// keyIndex returns the index of a key in a mapping node, or -1
+func keyIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// documentVersion returns the schema version of a document; 0 if it has none
+func documentVersion(root *yaml.Node) (int, error) {
+	index := keyIndex(root, VersionKey)
+	if index < 0 {
+		return 0, nil
+	}
+	var version int
+	if err := root.Content[index+1].Decode(&version); err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid %s %q", VersionKey, root.Content[index+1].Value)
+	}
+	return version, nil
+}
+
+// setVersion sets the schema version, adding the key at the top of the document if needed
+func setVersion(root *yaml.Node, version int) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(version)}
+	if index := keyIndex(root, VersionKey); index >= 0 {
+		root.Content[index+1] = value
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: VersionKey}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// nodeKind names a YAML node kind for error messages
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a sequence"
+	case yaml.ScalarNode:
+		return "a scalar"
+	default:
+		return "an unexpected node"
+	}
+}
+
+// secondsToMinutes converts a duration in seconds to whole minutes, rounding up
+func secondsToMinutes(value interface{}) (interface{}, error) {
+	var seconds float64
+	switch v := value.(type) {
+	case int:
+		seconds = float64(v)
+	case float64:
+		seconds = v
+	default:
+		return nil, fmt.Errorf("expected a number of seconds, got %v", value)
+	}
+	return int(math.Ceil(seconds / 60)), nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const legacyConfig = `# Service configuration
+app:
+  name: legacy-app
+
+auth:
+  provider: jwt
+  # Shared HMAC secret
+  jwtSecret: s3cr3t
+  jwtDuration: 3600 # seconds
+
+observability:
+  tracingEnabled: true
+  tracingExporterURL: http://jaeger:14268/api/traces
+  tracingSamplingRatio: 0.1
+`
+
+func TestMigrateYAML(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantContains []string
+		wantMissing  []string
+		wantWarnings int
+		wantErr      string
+	}{
+		{
+			name:  "legacy keys are moved",
+			input: legacyConfig,
+			wantContains: []string{
+				"configVersion: 1\n",
+				"# Service configuration",
+				"  jwt:\n    # Shared HMAC secret\n    secretKey: s3cr3t\n    tokenDuration: 60 # seconds\n",
+				"  tracingURL: http://jaeger:14268/api/traces\n",
+				"  tracingSamplerRatio: 0.1\n",
+			},
+			wantMissing:  []string{"jwtSecret", "jwtDuration", "tracingExporterURL", "tracingSamplingRatio"},
+			wantWarnings: 4,
+		},
+		{
+			name:         "current keys win over legacy ones",
+			input:        "observability:\n  tracingURL: http://otel:4318\n  tracingExporterURL: http://jaeger:14268\n",
+			wantContains: []string{"tracingURL: http://otel:4318"},
+			wantMissing:  []string{"jaeger"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "files without legacy keys only get a version",
+			input:        "app:\n  name: fresh\n",
+			wantContains: []string{"configVersion: 1\napp:\n  name: fresh\n"},
+		},
+		{
+			name:    "newer versions are rejected",
+			input:   "configVersion: 99\n",
+			wantErr: "newer than the latest supported version",
+		},
+		{
+			name:    "documents must be mappings",
+			input:   "- a\n- b\n",
+			wantErr: "must be a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, result, err := MigrateYAML([]byte(tt.input))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, result.Changed())
+			assert.Len(t, result.Warnings, tt.wantWarnings)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, string(out), want)
+			}
+			for _, missing := range tt.wantMissing {
+				assert.NotContains(t, string(out), missing)
+			}
+
+			// Migrating again changes nothing
+			again, result, err := MigrateYAML(out)
+			require.NoError(t, err)
+			assert.False(t, result.Changed())
+			assert.Equal(t, string(out), string(again))
+		})
+	}
+}
+
+func TestLoadMigratesLegacyConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "service_default.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(legacyConfig), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", cfg.Auth.JWT.SecretKey)
+	assert.Equal(t, 60, cfg.Auth.JWT.TokenDuration)
+	assert.Equal(t, "http://jaeger:14268/api/traces", cfg.Observability.TracingURL)
+	assert.Equal(t, 0.1, cfg.Observability.TracingSamplerRatio)
+
+	warnings := cfg.MigrationWarnings()
+	require.Len(t, warnings, 5)
+	assert.True(t, strings.HasPrefix(warnings[4], "config file "+configPath+" is at version 0"))
+
+	// Loading does not rewrite the file
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(data))
+}
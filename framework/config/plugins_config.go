@@ -5,4 +5,18 @@ type PluginsConfig struct {
 	Enabled  map[string]bool
 	Settings map[string]map[string]interface{}
 	Paths    []string
+	// DependsOn adds dependencies to those a plugin declares: plugin name to the names of
+	// the plugins it needs started first
+	DependsOn map[string][]string
+	// StartTimeout and StopTimeout bound each plugin's Start and Stop
+	StartTimeout int // in seconds; defaults to 10
+	StopTimeout  int // in seconds; defaults to 10
+	// Timeouts overrides StartTimeout and StopTimeout by plugin name
+	Timeouts map[string]PluginTimeouts
+}
+
+// PluginTimeouts overrides the start and stop timeouts of one plugin
+type PluginTimeouts struct {
+	Start int // in seconds; 0 uses StartTimeout
+	Stop  int // in seconds; 0 uses StopTimeout
 }
@@ -3,13 +3,38 @@ package config
 // Config represents the application configuration
 type Config struct {
 	App           AppConfig
+	Errors        ErrorsConfig
 	Observability ObservabilityConfig
 	Database      DatabaseConfig
 	HTTP          HTTPConfig
 	GRPC          GRPCConfig
 	Auth          AuthConfig
 	Casbin        CasbinConfig
+	Redis         RedisConfig
+	Cache         CacheConfig
+	Resilience    ResilienceConfig
+	Chaos         ChaosConfig
+	Pagination    PaginationConfig
+	FeatureFlags  FeatureFlagsConfig
+	I18n          I18nConfig
+	Lock          LockConfig
+	Worker        WorkerConfig
+	Events        EventsConfig
+	Kafka         KafkaConfig
+	Notify        NotifyConfig
+	Storage       StorageConfig
+	Session       SessionConfig
+	GraphQL       GraphQLConfig
 	Plugins       PluginsConfig
+
+	// Changes made while upgrading the loaded file from an older config version
+	migrationWarnings []string
+}
+
+// MigrationWarnings returns the changes made while upgrading the loaded file from an older
+// config version, to be logged at startup
+func (c *Config) MigrationWarnings() []string {
+	return c.migrationWarnings
 }
 
 // AuthConfig represents the authentication configuration
@@ -24,12 +49,28 @@ type OIDCConfig struct {
 	ClientID     string
 	ClientSecret string
 	JWKSCacheTTL int // in minutes
+
+	// Last known discovery and JWKS documents, used while the issuer is unreachable
+	JWKSCachePath    string // file path; empty disables persistence
+	JWKSMaxStaleness int    // in minutes; 0 means cached keys never expire
+
+	// Token validation; empty lists fall back to the discovered issuer, ClientID and asymmetric algorithms
+	Issuers    []string
+	Audiences  []string
+	ClockSkew  int // in seconds
+	Algorithms []string
 }
 
 // JWTConfig represents the JWT configuration
 type JWTConfig struct {
 	SecretKey     string
 	TokenDuration int // in minutes
+
+	// Token validation; generated tokens use the first issuer and algorithm and carry all audiences
+	Issuers    []string
+	Audiences  []string
+	ClockSkew  int // in seconds
+	Algorithms []string
 }
 
 // CasbinConfig represents the Casbin RBAC configuration
@@ -47,6 +88,13 @@ type AppConfig struct {
 	Debug       bool
 }
 
+// ErrorsConfig represents the stack traces captured by framework errors
+type ErrorsConfig struct {
+	// "all" (default) or "internal": only errors without a code or with an internal or
+	// unknown code; app.debug captures all
+	StackTraces string
+}
+
 // ObservabilityConfig represents the observability configuration
 type ObservabilityConfig struct {
 	LogLevel            string
@@ -57,6 +105,62 @@ type ObservabilityConfig struct {
 	TracingSamplerRatio float64
 	MetricsEnabled      bool
 	MetricsPort         int
+
+	// Tenant labels on metrics and logs, with cardinality protection
+	TenantLabelsEnabled     bool
+	TenantHeader            string
+	TenantLabelLimit        int // distinct tenants tracked before folding into "other"
+	TenantRebalanceInterval int // in seconds
+
+	// Per-request resource usage accounting
+	MeteringEnabled      bool
+	MeteringLogEvents    bool
+	MeteringAPIKeyHeader string
+
+	ErrorReporting ErrorReportingConfig
+	Profiling      ProfilingConfig
+	DebugTrace     DebugTraceConfig
+}
+
+// DebugTraceConfig configures forcing the sampling of a request and the requests it causes,
+// by allowed callers sending the debug trace header
+type DebugTraceConfig struct {
+	Enabled      bool
+	Header       string   // defaults to X-Debug-Trace
+	Users        []string // user IDs allowed to force sampling
+	Roles        []string // roles allowed to force sampling
+	TrustBaggage bool     // honor the debug trace baggage of callers, for services only reached by other services
+}
+
+// ProfilingConfig configures the pprof endpoints of the admin server and continuous profiling
+type ProfilingConfig struct {
+	Pprof    bool   // serve net/http/pprof at /debug/pprof on the admin server
+	Enabled  bool   // profile continuously
+	Provider string // "pyroscope" pushes profiles; "parca" scrapes the pprof endpoints of the admin server
+
+	// Pyroscope settings
+	ServerAddress   string            // e.g. http://pyroscope:4040
+	ApplicationName string            // defaults to app.name
+	Labels          map[string]string // added to the service, version and environment labels
+	ProfileTypes    []string          // cpu, heap, goroutine, mutex, block; defaults to cpu, heap and goroutine
+	UploadInterval  int               // in seconds; defaults to 15
+	Timeout         int               // in milliseconds; defaults to 10000
+	TenantID        string            // sent as X-Scope-OrgID
+	Username        string            // basic auth, e.g. with Grafana Cloud
+	Password        string
+	AuthToken       string // bearer auth
+}
+
+// ErrorReportingConfig configures reporting internal errors and panics to Sentry or a
+// Sentry-compatible service
+type ErrorReportingConfig struct {
+	Enabled     bool
+	DSN         string  // e.g. https://<key>@o0.ingest.sentry.io/<project>
+	Environment string  // defaults to app.environment
+	Release     string  // defaults to app.version
+	SampleRate  float64 // share of events sent, from 0 to 1; defaults to 1
+	QueueSize   int     // events waiting to be sent before new ones are dropped; defaults to 100
+	Timeout     int     // in milliseconds; defaults to 5000
 }
 
 // DatabaseConfig represents the database configuration
@@ -76,14 +180,541 @@ type DatabaseConfig struct {
 
 // HTTPConfig represents the HTTP server configuration
 type HTTPConfig struct {
-	Port         int
-	Host         string
-	ReadTimeout  int
-	WriteTimeout int
+	Port             int
+	Host             string
+	ReadTimeout      int
+	WriteTimeout     int
+	RateLimit        RateLimitConfig
+	ConcurrencyLimit ConcurrencyLimitConfig
+	TLS              TLSConfig
+	Idempotency      IdempotencyConfig
+	Auth             HTTPAuthConfig
+
+	// Request and response size limits
+	BodyLimit         int // in bytes; defaults to 4MB
+	BodyLimits        []BodyLimitRouteConfig
+	StreamRequestBody bool // hand bodies larger than BodyLimit to handlers as a stream
+	MaxResponseSize   int  // in bytes; 0 disables the limit
+	MaxHeaderSize     int  // in bytes, of the request line and headers; defaults to 4096
+	Upload            UploadConfig
+
+	// HTTP/2, alongside HTTP/1.1
+	HTTP2 bool // serve HTTP/2 to clients negotiating it with TLS
+	H2C   bool // serve cleartext HTTP/2 to clients with prior knowledge
+
+	Endpoints   EndpointsConfig
+	AdminServer AdminServerConfig
+	Security    SecurityConfig
+	PayloadLog  PayloadLogConfig
+	WebSocket   WebSocketConfig
+	SSE         SSEConfig
+	Docs        DocsConfig
+}
+
+// PayloadLogConfig represents the logging of request and response payloads, to troubleshoot
+// integrations
+type PayloadLogConfig struct {
+	Enabled     bool
+	Paths       []string // routes logged, exact or prefix when ending with "*"; empty logs all but probes and metrics
+	SampleRate  float64  // share of requests logged, from 0 to 1; defaults to 1
+	MaxBodySize int      // in bytes; longer bodies are truncated in the log; defaults to 4096
+	RedactPaths []string // JSON paths of body fields replaced in the log, e.g. $.card.number, $.items[*].token or $..iban
+	Headers     []string // headers logged with their value; others are logged redacted. Defaults to content and tracing headers.
+}
+
+// SecurityConfig represents the browser security policy of the HTTP server
+type SecurityConfig struct {
+	CORS    CORSConfig
+	CSRF    CSRFConfig
+	Headers SecurityHeadersConfig
+}
+
+// CORSConfig represents the origins allowed to call the API from browsers
+type CORSConfig struct {
+	AllowOrigins     []string // e.g. ["https://app.example.com", "https://*.example.com"]; empty allows any origin, except in production where cross-origin requests are refused
+	AllowMethods     []string // defaults to GET, POST, HEAD, PUT, DELETE and PATCH
+	AllowHeaders     []string // empty allows the headers requested by the preflight
+	ExposeHeaders    []string // response headers readable by scripts
+	AllowCredentials bool     // allow cookies and credentials; requires explicit origins
+	MaxAge           int      // in seconds; how long browsers cache preflight responses
+}
+
+// CSRFConfig represents the protection of cookie-authenticated requests against cross-site
+// request forgery, with a signed token sent both as a cookie and as a header
+type CSRFConfig struct {
+	Enabled     bool
+	Secret      string   // signs the tokens; share it between replicas. Empty generates one per process
+	CookieName  string   // defaults to "csrf_token"
+	HeaderName  string   // defaults to "X-CSRF-Token"
+	Expiration  int      // in seconds; defaults to 43200
+	ExemptPaths []string // exact, or prefixes ending with "*", e.g. "/webhooks/*"
+}
+
+// SecurityHeadersConfig represents the security headers added to every response
+type SecurityHeadersConfig struct {
+	Enabled               bool
+	HSTSMaxAge            int    // in seconds; 0 defaults to 31536000 in production and sends no header elsewhere; negative never sends it
+	HSTSIncludeSubdomains bool   // apply HSTS to the subdomains too
+	ContentSecurityPolicy string // defaults to "default-src 'none'; frame-ancestors 'none'"
+	FrameOptions          string // defaults to "DENY"
+	ReferrerPolicy        string // defaults to "no-referrer"
+}
+
+// DocsConfig represents the API documentation served by the HTTP server
+type DocsConfig struct {
+	Enabled  bool   // serve Swagger UI and the OpenAPI document; they are public
+	Path     string // defaults to /docs
+	SpecFile string // OpenAPI document generated by axiomod generate openapi; defaults to docs/api/openapi.yaml
+}
+
+// SSEConfig represents the Server-Sent Events stream settings
+type SSEConfig struct {
+	HeartbeatInterval int // in seconds; defaults to 15
+	BufferSize        int // queued events per stream; defaults to 64
+	Retry             int // reconnect delay suggested to clients, in milliseconds; 0 leaves the client default
+}
+
+// WebSocketConfig represents the WebSocket connection settings
+type WebSocketConfig struct {
+	PingInterval    int      // in seconds; defaults to 30
+	PongTimeout     int      // in seconds; defaults to 60
+	WriteTimeout    int      // in seconds; defaults to 10
+	MaxMessageSize  int64    // in bytes; defaults to 64KB
+	SendBuffer      int      // queued outgoing messages per connection; defaults to 256
+	ShutdownTimeout int      // in seconds; how long to wait for connections to drain; defaults to 10
+	Origins         []string // allowed Origin headers; empty allows any
+}
+
+// AdminServerConfig represents the dedicated listener of the operational endpoints
+type AdminServerConfig struct {
+	Enabled bool   // serve metrics, pprof, probes and the admin endpoints here instead of on the API port
+	Host    string // defaults to the host of the HTTP server
+	Port    int    // defaults to 9092
+}
+
+// EndpointsConfig represents the protection of the operational endpoints
+type EndpointsConfig struct {
+	Metrics       EndpointAuthConfig
+	HealthDetails EndpointAuthConfig // component details of /ready; the status itself stays public
+	Admin         EndpointAuthConfig // the /admin endpoints, e.g. the error code catalog
+}
+
+// EndpointAuthConfig represents the access restrictions of an operational endpoint
+type EndpointAuthConfig struct {
+	Auth       string // "none", "basic", "bearer"
+	Username   string
+	Password   string
+	Token      string
+	AllowedIPs []string // addresses or CIDRs; empty allows any
+}
+
+// BodyLimitRouteConfig represents a per-route request body limit
+type BodyLimitRouteConfig struct {
+	Method string // empty matches any method
+	Path   string // exact path, or prefix when ending with "*"
+	Limit  int    // in bytes
+}
+
+// UploadConfig represents the multipart upload configuration
+type UploadConfig struct {
+	MaxFileSize         int64 // in bytes; 0 disables the limit
+	MaxMemory           int64 // in bytes kept in memory before spilling to disk
+	AllowedContentTypes []string
+	TempDir             string
+}
+
+// HTTPAuthConfig represents the server-wide HTTP authentication configuration
+type HTTPAuthConfig struct {
+	Enabled bool // authenticate every request, except routes that opt out
+	Routes  []AuthRouteConfig
+}
+
+// AuthRouteConfig represents a per-route authentication requirement
+type AuthRouteConfig struct {
+	Method       string // empty matches any method
+	Path         string // exact path, or prefix when ending with "*"
+	AuthRequired bool   // false lets anonymous requests through; tokens are still validated when present
+}
+
+// RateLimitConfig represents the HTTP rate limiting configuration
+type RateLimitConfig struct {
+	Enabled      bool
+	Algorithm    string // "sliding-window", "token-bucket"
+	Backend      string // "memory", "redis"
+	KeyBy        string // "ip", "apiKey", "subject"
+	APIKeyHeader string
+	Limit        int
+	Window       int // in seconds
+	Burst        int // token bucket capacity, defaults to Limit
+	Routes       []RateLimitRouteConfig
+}
+
+// ConcurrencyLimitConfig represents an adaptive concurrency limit on a server
+type ConcurrencyLimitConfig struct {
+	Enabled          bool
+	Algorithm        string // "aimd", "vegas"
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	LatencyThreshold int // in milliseconds; slower requests count as overload (aimd)
+}
+
+// TLSConfig represents the TLS settings of a server. Certificates are reloaded while the
+// server runs, so a rotation needs no restart.
+type TLSConfig struct {
+	Enabled        bool
+	Source         string // "file", "acme", "vault"; defaults to "file"
+	CertFile       string // PEM certificate chain (file)
+	KeyFile        string // PEM private key (file)
+	ReloadInterval int    // in seconds; how often the source is checked, files are also watched; defaults to 60
+	ExpiryWarning  int    // in days; certificates closer to expiry are logged as warnings; defaults to 14
+	ACME           ACMEConfig
+	Vault          VaultTLSConfig
+}
+
+// ACMEConfig represents certificates obtained and renewed from an ACME CA such as Let's Encrypt
+type ACMEConfig struct {
+	Domains           []string // host names the certificates are requested for
+	Email             string   // contact address registered with the CA
+	DirectoryURL      string   // ACME directory; defaults to Let's Encrypt production
+	Storage           string   // "dir", "redis", "vault"; where the account key and certificates are kept; defaults to "dir"
+	CacheDir          string   // directory of the dir storage; defaults to "acme-cache"
+	VaultPath         string   // KV version 2 path prefix of the vault storage, e.g. "secret/data/acme"; the address and token come from the vault settings
+	HTTPChallengeAddr string   // address answering HTTP-01 challenges and redirecting other requests to HTTPS, e.g. ":80"; empty uses TLS-ALPN-01 only
+	RenewBefore       int      // in days; certificates are renewed this long before expiry; defaults to 30
+}
+
+// VaultTLSConfig represents a certificate read from a Vault KV secret
+type VaultTLSConfig struct {
+	Address   string // defaults to the VAULT_ADDR environment variable
+	Token     string // defaults to the VAULT_TOKEN environment variable
+	Path      string // secret path, e.g. "secret/data/tls/api" for KV version 2
+	CertField string // secret field holding the PEM certificate chain; defaults to "certificate"
+	KeyField  string // secret field holding the PEM private key; defaults to "private_key"
+}
+
+// RateLimitRouteConfig represents a per-route rate limit override
+type RateLimitRouteConfig struct {
+	Method string // empty matches any method
+	Path   string // exact path, or prefix when ending with "*"
+	Limit  int
+	Window int // in seconds
+	KeyBy  string
+}
+
+// IdempotencyConfig represents the configuration of the Idempotency-Key middleware
+type IdempotencyConfig struct {
+	Backend  string // "memory", "redis"
+	Header   string
+	TTL      int  // in seconds
+	Required bool // reject opted-in requests without a key
+}
+
+// RedisConfig represents the Redis connection configuration
+type RedisConfig struct {
+	Mode             string   // "standalone" (default), "cluster" or "sentinel"
+	Addr             string   // server of the standalone mode
+	Addrs            []string // nodes of the cluster mode, or sentinels of the sentinel mode
+	MasterName       string   // master monitored by the sentinels
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int  // not supported by the cluster mode
+	TLS              bool // connect with TLS
+	PoolSize         int  // connections per node; defaults to 10 per CPU
+	MinIdleConns     int
+	DialTimeout      int // in milliseconds; defaults to 5000
+	ReadTimeout      int // in milliseconds; defaults to 3000
+	WriteTimeout     int // in milliseconds; defaults to the read timeout
+}
+
+// CacheConfig represents the cache configuration
+type CacheConfig struct {
+	Prefix string // prefix of the keys of the Redis cache; defaults to "<app name>:cache"
+	Warmup CacheWarmupConfig
+}
+
+// CacheWarmupConfig represents the startup cache warm-up settings
+type CacheWarmupConfig struct {
+	Concurrency int // loaders run at once; defaults to 4
+	Timeout     int // in seconds; defaults to 30
+}
+
+// PaginationConfig represents the page sizes and page token signing of List endpoints
+type PaginationConfig struct {
+	Secret          string // signs page tokens; a random per-process key when empty
+	DefaultPageSize int    // when a request sets none; defaults to 50
+	MaxPageSize     int    // larger requests are capped; defaults to 1000
+	TokenTTL        int    // in seconds; 0 means page tokens never expire
+}
+
+// I18nConfig represents the localization of API error messages
+type I18nConfig struct {
+	Enabled       bool   // negotiate the locale of requests and localize error responses
+	DefaultLocale string // locale used when the caller accepts none of the catalog; defaults to "en"
+	Directory     string // directory of message files named after their locale, e.g. de-CH.yaml
+}
+
+// FeatureFlagsConfig represents the feature flags and where they are defined
+type FeatureFlagsConfig struct {
+	Provider    string                       // "static" (default), "redis" or "openfeature"
+	Flags       map[string]FeatureFlagConfig // static flags, by key; keys are case-insensitive
+	RedisPrefix string                       // prefix of the flag keys in Redis; defaults to "featureflags"
+	CacheTTL    int                          // in milliseconds; how long flags read from Redis are reused; defaults to 5000
+	OpenFeature OpenFeatureConfig
+}
+
+// FeatureFlagConfig represents the definition of a feature flag
+type FeatureFlagConfig struct {
+	Enabled   bool
+	Value     string   // value of string flags while on; bool flags are on unless it is "false"
+	Rollout   int      // percentage of users or tenants the flag is on for; 0 means all
+	RolloutBy string   // "user" (default) or "tenant"
+	Users     []string // the flag is on for these users regardless of the rollout
+	Tenants   []string // the flag is on for these tenants regardless of the rollout
+}
+
+// OpenFeatureConfig represents a flag service evaluating flags over the OpenFeature Remote
+// Evaluation Protocol (OFREP), such as flagd or GO Feature Flag
+type OpenFeatureConfig struct {
+	URL     string            // base URL of the service, e.g. http://flagd:8016
+	Headers map[string]string // sent with every request, e.g. Authorization
+	Timeout int               // in milliseconds; defaults to 1000
+}
+
+// LockConfig represents the backend of distributed locks
+type LockConfig struct {
+	Backend       string   // "memory" (default, for a single instance), "redis" or "postgres"
+	RedisAddrs    []string // independent Redis nodes locks are taken on by majority; defaults to the redis configuration
+	Prefix        string   // prefix of lock keys; defaults to "lock"
+	RetryInterval int      // in milliseconds; how often a held lock is retried while waiting; defaults to 100
+}
+
+// WorkerConfig represents the background job worker
+type WorkerConfig struct {
+	History   WorkerHistoryConfig
+	Scheduled WorkerScheduledConfig
+}
+
+// WorkerHistoryConfig represents where the runs of background jobs are recorded
+type WorkerHistoryConfig struct {
+	Backend string // "" (default, runs are not recorded), "memory" or "postgres"
+	Size    int    // runs kept by the memory backend; defaults to 1000
+	Table   string // table of the postgres backend; defaults to "job_runs"
+}
+
+// WorkerScheduledConfig represents where one-shot jobs waiting for their time are persisted
+type WorkerScheduledConfig struct {
+	Backend string // "" (default, jobs are lost on restart), "memory" or "postgres"
+	Table   string // table of the postgres backend; defaults to "scheduled_jobs"
+}
+
+// EventsConfig represents the in-process domain event dispatcher
+type EventsConfig struct {
+	QueueSize int               // events waiting for each async handler before publishers wait; defaults to 256
+	Bridge    map[string]string // Kafka topic of each domain event forwarded to Kafka, by event name
+}
+
+// NotifyConfig represents the providers notifications are delivered with
+type NotifyConfig struct {
+	Templates string // directory of message templates, overriding those of modules
+	Email     NotifyEmailConfig
+	Webhook   NotifyWebhookConfig
+	Slack     NotifySlackConfig
+}
+
+// NotifyEmailConfig represents the provider of email notifications
+type NotifyEmailConfig struct {
+	Provider string // "smtp", "ses" or "sendgrid"; empty disables email
+	From     string // sender of messages without one, e.g. "Acme <no-reply@acme.io>"
+	SMTP     NotifySMTPConfig
+	SES      NotifySESConfig
+	SendGrid NotifySendGridConfig
+}
+
+// NotifySMTPConfig represents an SMTP server
+type NotifySMTPConfig struct {
+	Host     string
+	Port     int // defaults to 587
+	Username string
+	Password string
+	TLS      bool // connect with TLS, as on port 465; otherwise STARTTLS is used when offered
+}
+
+// NotifySESConfig represents Amazon SES; credentials default to the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables
+type NotifySESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string // defaults to https://email.<region>.amazonaws.com
+}
+
+// NotifySendGridConfig represents SendGrid
+type NotifySendGridConfig struct {
+	APIKey   string
+	Endpoint string // defaults to https://api.sendgrid.com
+}
+
+// NotifyWebhookConfig represents the endpoint webhook notifications are posted to
+type NotifyWebhookConfig struct {
+	URL     string // empty disables webhooks
+	Secret  string // signs bodies with HMAC-SHA256 in the X-Signature-256 header
+	Headers map[string]string
+}
+
+// NotifySlackConfig represents the Slack incoming webhook notifications are posted to
+type NotifySlackConfig struct {
+	WebhookURL string // empty disables Slack
+}
+
+// StorageConfig represents the object store uploads and files are kept in
+type StorageConfig struct {
+	Backend string // "local" (default), "s3", for any S3-compatible service such as MinIO or GCS, or "memory"
+	Local   StorageLocalConfig
+	S3      StorageS3Config
+}
+
+// StorageLocalConfig represents the local filesystem store
+type StorageLocalConfig struct {
+	Dir     string // defaults to "storage"
+	BaseURL string // URL the HTTP server serves presigned URLs under, e.g. "http://localhost:8080/files"; empty disables presigning
+	Secret  string // signs presigned URLs
+}
+
+// StorageS3Config represents an S3-compatible bucket; credentials default to the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables
+type StorageS3Config struct {
+	Bucket          string
+	Region          string // defaults to "us-east-1"
+	Endpoint        string // defaults to https://s3.<region>.amazonaws.com, e.g. http://localhost:9000 for MinIO or https://storage.googleapis.com for GCS
+	PathStyle       bool   // address the bucket in the path instead of the host, as MinIO needs
+	AccessKeyID     string
+	SecretAccessKey string
+	PartSize        int // in MB; uploads larger than a part are sent in parts of this size; defaults to 8
+}
+
+// SessionConfig represents the cookie sessions of browser clients. The cookie is Secure in
+// production or with TLS enabled.
+type SessionConfig struct {
+	Backend         string // "memory" (default) or "redis", to share sessions across replicas
+	Prefix          string // prefix of the Redis keys; defaults to "<app name>:session"
+	CookieName      string // defaults to "session"
+	CookieDomain    string
+	CookiePath      string // defaults to "/"
+	CookieSameSite  string // "Lax" (default), "Strict" or "None"
+	IdleTimeout     int    // in minutes; sessions unused for longer expire; defaults to 30
+	AbsoluteTimeout int    // in minutes; sessions older than this expire however active; defaults to 720
+}
+
+// GraphQLConfig represents the GraphQL endpoints of graphql.Module
+type GraphQLConfig struct {
+	Path           string // prefix of the endpoints; defaults to "/graphql"
+	AllowAnonymous bool   // let requests without a token reach the endpoints, leaving authorization to the @auth directive
+	MaxDepth       int    // deepest selection accepted; defaults to 15, negative disables the limit
+	MaxComplexity  int    // fields selected, multiplied by list sizes; defaults to 1000, negative disables the limit
+}
+
+// KafkaConfig represents the brokers and client settings of the Kafka producer and consumer
+type KafkaConfig struct {
+	Brokers  []string // defaults to localhost:9092
+	ClientID string   // defaults to go-axiomod
+	GroupID  string   // consumer group; defaults to go-axiomod
+}
+
+// ResilienceConfig represents the named resilience policies of outgoing dependencies
+type ResilienceConfig struct {
+	Policies map[string]ResiliencePolicyConfig // by policy name, e.g. "payments"
+}
+
+// ResiliencePolicyConfig represents the timeout, retry, circuit breaker, bulkhead and
+// hedging settings of a dependency
+type ResiliencePolicyConfig struct {
+	Timeout        int // in milliseconds; defaults to 30000
+	Retry          RetryPolicyConfig
+	CircuitBreaker CircuitBreakerPolicyConfig
+	Bulkhead       BulkheadPolicyConfig
+	Hedge          HedgePolicyConfig
+}
+
+// RetryPolicyConfig represents the retries of a resilience policy
+type RetryPolicyConfig struct {
+	MaxRetries    int     // 0 disables retries
+	Delay         int     // in milliseconds; defaults to 100
+	BackoffFactor float64 // defaults to 2
+	MaxDelay      int     // in milliseconds; defaults to 30000
+}
+
+// CircuitBreakerPolicyConfig represents the circuit breaker of a resilience policy
+type CircuitBreakerPolicyConfig struct {
+	MaxFailures   int // defaults to 5
+	ResetTimeout  int // in seconds; defaults to 10
+	HalfOpenLimit int // successes needed to close the breaker again; defaults to 1
+}
+
+// BulkheadPolicyConfig represents the bulkhead of a resilience policy
+type BulkheadPolicyConfig struct {
+	MaxConcurrent int // 0 disables the bulkhead
+	MaxQueue      int
+	QueueTimeout  int // in milliseconds; 0 waits until the call's context is done
+}
+
+// HedgePolicyConfig represents the hedging of a resilience policy
+type HedgePolicyConfig struct {
+	Delay     int     // in milliseconds; 0 disables hedging
+	MaxHedges int     // defaults to 1
+	MaxRatio  float64 // defaults to 0.1
+}
+
+// ChaosConfig represents the faults injected in requests for chaos experiments. Nothing is
+// injected, and the admin API cannot add faults, unless it is enabled.
+type ChaosConfig struct {
+	Enabled bool
+	Faults  []FaultConfig // injected from startup; the admin API replaces them at runtime
+}
+
+// FaultConfig represents a fault injected in the HTTP requests and gRPC calls it matches
+type FaultConfig struct {
+	Name        string
+	Method      string  // HTTP method; empty matches any method and gRPC calls
+	Path        string  // HTTP path or gRPC full method, a prefix when ending with "*"
+	Latency     int     // in milliseconds, added before the request is handled
+	ErrorRate   float64 // share of requests failed, from 0 to 1
+	ErrorStatus int     // HTTP status of failed requests; defaults to 503
+	ResetRate   float64 // share of requests whose connection is reset, from 0 to 1
 }
 
 // GRPCConfig represents the gRPC server configuration
 type GRPCConfig struct {
-	Port int
-	Host string
+	Port             int
+	Host             string
+	Timeout          int // in seconds; bounds unary calls, defaults to 30
+	StreamTimeout    int // in seconds; bounds the context of streams, 0 leaves them open until either side ends them
+	EnableReflection bool
+	MaxRecvMsgSize   int // in bytes; defaults to 4 MiB
+	MaxSendMsgSize   int // in bytes; defaults to 2 GiB
+	Keepalive        GRPCKeepaliveConfig
+	Gateway          GRPCGatewayConfig
+	ConcurrencyLimit ConcurrencyLimitConfig
+	TLS              TLSConfig
+}
+
+// GRPCKeepaliveConfig represents the pings keeping long-lived streams open through proxies and
+// detecting dead clients
+type GRPCKeepaliveConfig struct {
+	Time                int  // in seconds; idle connections are pinged after this time, defaults to 7200
+	Timeout             int  // in seconds; connections not answering a ping are closed, defaults to 20
+	MinTime             int  // in seconds; clients pinging more often are disconnected, defaults to 300
+	PermitWithoutStream bool // let clients ping connections without active streams
+
+	MaxConnectionIdle     int // in seconds; idle connections are closed after this time, defaults to 900
+	MaxConnectionAge      int // in seconds; connections are closed after this time so that clients rebalance, defaults to 3600
+	MaxConnectionAgeGrace int // in seconds; calls still running when a connection gets too old may finish within this time, 0 waits for them
+}
+
+// GRPCGatewayConfig represents the REST gateway for gRPC services
+type GRPCGatewayConfig struct {
+	Enabled  bool
+	Prefix   string // path prefix routed to the gateway; defaults to "/v1"
+	Endpoint string // address of the gRPC server; defaults to the local gRPC server
 }
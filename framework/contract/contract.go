@@ -0,0 +1,207 @@
+// Package contract snapshots the shape of exported structs, such as entities and use
+// case inputs and outputs, so tests fail when a change would break the API payloads
+// that share them.
+package contract
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// header starts every snapshot
+const header = "# Contract snapshot: exported fields and their JSON names. Update with " + UpdateEnv + "=1 go test ./..."
+
+// Describe returns the snapshot of the structs of values, which may be values, pointers
+// or nil pointers such as (*entity.Order)(nil). Named structs used by their fields are
+// described too, except those of the standard library such as time.Time. Types are
+// sorted by name, and fields keep their declaration order.
+func Describe(values ...interface{}) string {
+	types := make(map[string]reflect.Type)
+	for _, value := range values {
+		collect(reflect.TypeOf(value), types)
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	for _, name := range names {
+		b.WriteString("\n" + name + "\n")
+		for _, field := range fields(types[name]) {
+			b.WriteString("\t" + field + "\n")
+		}
+	}
+	return b.String()
+}
+
+// typeName returns the package qualified name of a named type
+func typeName(t reflect.Type) string {
+	return t.PkgPath() + "." + t.Name()
+}
+
+// collect adds a struct type, and the named structs its fields use, to types
+func collect(t reflect.Type, types map[string]reflect.Type) {
+	for t != nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+			continue
+		case reflect.Map:
+			collect(t.Key(), types)
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			if t.Name() != "" && (isStandard(t.PkgPath()) || types[typeName(t)] != nil) {
+				return
+			}
+			if t.Name() != "" {
+				types[typeName(t)] = t
+			}
+			for i := 0; i < t.NumField(); i++ {
+				if field := t.Field(i); field.IsExported() {
+					collect(field.Type, types)
+				}
+			}
+		}
+		return
+	}
+}
+
+// isStandard reports whether a package belongs to the standard library, whose import
+// paths have no dot in their first element
+func isStandard(pkgPath string) bool {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// fields describes the exported fields of a struct, e.g. `Name string json:"name"`
+func fields(t reflect.Type) []string {
+	var described []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		line := field.Name + " " + field.Type.String()
+		if field.Anonymous {
+			line = "embedded " + line
+		}
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			line += fmt.Sprintf(" json:%q", tag)
+		}
+		described = append(described, line)
+	}
+	return described
+}
+
+// Change is a difference between two snapshots
+type Change struct {
+	Type  string // package qualified type name
+	Field string // empty when the whole type was added or removed
+	Old   string // field description before the change, empty if added
+	New   string // field description after the change, empty if removed
+}
+
+// Breaking reports whether the change can break clients: removed types and fields, and
+// fields with a new type or JSON name. Added fields and types are not breaking.
+func (c Change) Breaking() bool {
+	return c.Old != ""
+}
+
+// String describes the change, e.g. `changed entity.Order.Total: int64 json:"total" -> string json:"total"`
+func (c Change) String() string {
+	name := c.Type
+	if c.Field != "" {
+		name += "." + c.Field
+	}
+	switch {
+	case c.Old == "":
+		return fmt.Sprintf("added %s %s", name, c.New)
+	case c.New == "":
+		return fmt.Sprintf("removed %s %s", name, c.Old)
+	default:
+		return fmt.Sprintf("changed %s: %s -> %s", name, c.Old, c.New)
+	}
+}
+
+// snapshotType is a type of a parsed snapshot
+type snapshotType struct {
+	fields map[string]string // field name to description, without the name
+	order  []string
+}
+
+// parse reads a snapshot written by Describe
+func parse(snapshot string) (map[string]*snapshotType, []string) {
+	types := make(map[string]*snapshotType)
+	var names []string
+	var current *snapshotType
+
+	scanner := bufio.NewScanner(strings.NewReader(snapshot))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "\t"):
+			if current == nil {
+				continue
+			}
+			// Embedding flattens the JSON of a field, so it stays part of the description
+			line = strings.TrimPrefix(line, "\t")
+			embedded := strings.HasPrefix(line, "embedded ")
+			name, description, _ := strings.Cut(strings.TrimPrefix(line, "embedded "), " ")
+			if embedded {
+				description = "embedded " + description
+			}
+			current.fields[name] = description
+			current.order = append(current.order, name)
+		default:
+			current = &snapshotType{fields: make(map[string]string)}
+			types[line] = current
+			names = append(names, line)
+		}
+	}
+	return types, names
+}
+
+// Diff returns the changes from the old snapshot to the new one, removed and changed
+// fields first
+func Diff(old, new string) []Change {
+	oldTypes, oldNames := parse(old)
+	newTypes, newNames := parse(new)
+
+	var changes []Change
+	for _, name := range oldNames {
+		before, after := oldTypes[name], newTypes[name]
+		if after == nil {
+			changes = append(changes, Change{Type: name, Old: "struct"})
+			continue
+		}
+		for _, field := range before.order {
+			if description, ok := after.fields[field]; !ok {
+				changes = append(changes, Change{Type: name, Field: field, Old: before.fields[field]})
+			} else if description != before.fields[field] {
+				changes = append(changes, Change{Type: name, Field: field, Old: before.fields[field], New: description})
+			}
+		}
+	}
+	for _, name := range newNames {
+		before, after := oldTypes[name], newTypes[name]
+		if before == nil {
+			changes = append(changes, Change{Type: name, New: "struct"})
+			continue
+		}
+		for _, field := range after.order {
+			if _, ok := before.fields[field]; !ok {
+				changes = append(changes, Change{Type: name, Field: field, New: after.fields[field]})
+			}
+		}
+	}
+	return changes
+}
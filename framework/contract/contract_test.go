@@ -0,0 +1,150 @@
+package contract
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Money struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+type Audit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Order struct {
+	Audit
+	ID     string            `json:"id"`
+	Lines  []*Line           `json:"lines,omitempty"`
+	Totals map[string]Money  `json:"totals"`
+	Labels map[string]string `json:"-"`
+	Note   string
+	secret string
+}
+
+type Line struct {
+	SKU string `json:"sku"`
+}
+
+func TestDescribe(t *testing.T) {
+	want := `# Contract snapshot: exported fields and their JSON names. Update with AXIOMOD_UPDATE_CONTRACTS=1 go test ./...
+
+github.com/axiomod/axiomod/framework/contract.Audit
+	CreatedAt time.Time json:"created_at"
+
+github.com/axiomod/axiomod/framework/contract.Line
+	SKU string json:"sku"
+
+github.com/axiomod/axiomod/framework/contract.Money
+	Currency string json:"currency"
+	Amount int64 json:"amount"
+
+github.com/axiomod/axiomod/framework/contract.Order
+	embedded Audit contract.Audit
+	ID string json:"id"
+	Lines []*contract.Line json:"lines,omitempty"
+	Totals map[string]contract.Money json:"totals"
+	Labels map[string]string json:"-"
+	Note string
+`
+	assert.Equal(t, want, Describe(Order{}))
+	assert.Equal(t, want, Describe((*Order)(nil), &Line{}), "pointers and types reached from fields are described once")
+}
+
+func TestDiff(t *testing.T) {
+	old := `# snapshot
+
+example.com/orders.Order
+	ID string json:"id"
+	Total int64 json:"total"
+	Status string json:"status"
+	embedded Audit orders.Audit
+
+example.com/orders.Legacy
+	Code string
+`
+	changes := Diff(old, `
+example.com/orders.Order
+	ID string json:"id"
+	Total string json:"total"
+	Audit orders.Audit
+	Note string json:"note,omitempty"
+
+example.com/orders.Line
+	SKU string json:"sku"
+`)
+
+	var described []string
+	for _, change := range changes {
+		described = append(described, fmt.Sprintf("%t %s", change.Breaking(), change))
+	}
+	assert.Equal(t, []string{
+		`true changed example.com/orders.Order.Total: int64 json:"total" -> string json:"total"`,
+		`true removed example.com/orders.Order.Status string json:"status"`,
+		`true changed example.com/orders.Order.Audit: embedded orders.Audit -> orders.Audit`,
+		`true removed example.com/orders.Legacy struct`,
+		`false added example.com/orders.Order.Note string json:"note,omitempty"`,
+		`false added example.com/orders.Line struct`,
+	}, described)
+
+	assert.Empty(t, Diff(old, old))
+}
+
+// recorder records the failures of Snapshot
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Error(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+// snapshot runs Snapshot and returns its failures
+func snapshot(t *testing.T, name string, values ...interface{}) []string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Snapshot(r, name, values...)
+	}()
+	<-done
+	return r.errors
+}
+
+func TestSnapshot(t *testing.T) {
+	defer func(dir string) { Dir = dir }(Dir)
+	Dir = t.TempDir()
+
+	errs := snapshot(t, "orders", Line{})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "no snapshot")
+
+	t.Setenv(UpdateEnv, "1")
+	assert.Empty(t, snapshot(t, "orders", Line{}))
+	stored, err := os.ReadFile(filepath.Join(Dir, "orders.snap"))
+	require.NoError(t, err)
+	assert.Equal(t, Describe(Line{}), string(stored))
+
+	t.Setenv(UpdateEnv, "")
+	assert.Empty(t, snapshot(t, "orders", Line{}), "unchanged structs match")
+
+	errs = snapshot(t, "orders", Money{})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "breaking changes:\n  removed github.com/axiomod/axiomod/framework/contract.Line struct")
+	assert.Contains(t, errs[0], "compatible changes:\n  added github.com/axiomod/axiomod/framework/contract.Money struct")
+}
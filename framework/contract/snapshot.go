@@ -0,0 +1,70 @@
+package contract
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes Snapshot write snapshots instead of
+// comparing them
+const UpdateEnv = "AXIOMOD_UPDATE_CONTRACTS"
+
+// Dir is where Snapshot keeps snapshots, relative to the package under test
+var Dir = filepath.Join("testdata", "contracts")
+
+// Snapshot compares the structs of values with the snapshot called name and fails the
+// test if they changed, listing breaking changes first. With AXIOMOD_UPDATE_CONTRACTS=1
+// it writes the snapshot instead, to be reviewed and committed with the change.
+//
+//	contract.Snapshot(t, "orders", entity.Order{}, usecase.CreateOrderInput{}, usecase.CreateOrderOutput{})
+func Snapshot(t testing.TB, name string, values ...interface{}) {
+	t.Helper()
+
+	path := filepath.Join(Dir, name+".snap")
+	current := Describe(values...)
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("contract: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(current), 0o644); err != nil {
+			t.Fatalf("contract: %v", err)
+		}
+		return
+	}
+
+	stored, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("contract: no snapshot %s; create it with %s=1 go test", path, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("contract: %v", err)
+	}
+
+	changes := Diff(string(stored), current)
+	if len(changes) == 0 {
+		return
+	}
+
+	var breaking, compatible []string
+	for _, change := range changes {
+		if change.Breaking() {
+			breaking = append(breaking, "  "+change.String())
+		} else {
+			compatible = append(compatible, "  "+change.String())
+		}
+	}
+	var b strings.Builder
+	b.WriteString("contract: " + name + " changed without updating " + path + "\n")
+	if len(breaking) > 0 {
+		b.WriteString("breaking changes:\n" + strings.Join(breaking, "\n") + "\n")
+	}
+	if len(compatible) > 0 {
+		b.WriteString("compatible changes:\n" + strings.Join(compatible, "\n") + "\n")
+	}
+	b.WriteString("if the changes are intended, update the snapshot with " + UpdateEnv + "=1 go test")
+	t.Error(b.String())
+}
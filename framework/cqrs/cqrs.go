@@ -0,0 +1,176 @@
+// Package cqrs provides command and query buses. Use cases are handlers of a command or query
+// type, registered on a bus and dispatched through its middleware, so that validation,
+// authorization, transactions, tracing and metrics are applied the same way to all of them.
+package cqrs
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// Common errors
+var (
+	ErrNoHandler        = stderrors.New("cqrs: no handler registered")
+	ErrHandlerExists    = stderrors.New("cqrs: handler already registered")
+	ErrUnexpectedResult = stderrors.New("cqrs: unexpected result type")
+)
+
+// Kind is the kind of messages a bus dispatches
+type Kind string
+
+// Kinds of messages
+const (
+	// KindCommand messages change state
+	KindCommand Kind = "command"
+	// KindQuery messages read state without changing it
+	KindQuery Kind = "query"
+)
+
+// Message is a command or query on its way to its handler
+type Message struct {
+	Kind  Kind
+	Name  string // name of the message type, e.g. "usecase.CreateUser"
+	Value any
+}
+
+// HandlerFunc handles a message, returning its result
+type HandlerFunc func(ctx context.Context, message Message) (any, error)
+
+// Middleware wraps the handling of every message dispatched by a bus
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Handler handles messages of type M with results of type R
+type Handler[M, R any] interface {
+	Handle(ctx context.Context, message M) (R, error)
+}
+
+// Func adapts a function to a Handler
+type Func[M, R any] func(ctx context.Context, message M) (R, error)
+
+// Handle calls f
+func (f Func[M, R]) Handle(ctx context.Context, message M) (R, error) {
+	return f(ctx, message)
+}
+
+// Bus dispatches messages to the handler registered for their type
+type Bus struct {
+	kind       Kind
+	handlers   map[reflect.Type]HandlerFunc
+	middleware []Middleware
+	mu         sync.RWMutex
+}
+
+func newBus(kind Kind) *Bus {
+	return &Bus{kind: kind, handlers: make(map[reflect.Type]HandlerFunc)}
+}
+
+// CommandBus dispatches commands
+type CommandBus struct {
+	*Bus
+}
+
+// NewCommandBus creates a new command bus
+func NewCommandBus() *CommandBus {
+	return &CommandBus{newBus(KindCommand)}
+}
+
+// QueryBus dispatches queries
+type QueryBus struct {
+	*Bus
+}
+
+// NewQueryBus creates a new query bus
+func NewQueryBus() *QueryBus {
+	return &QueryBus{newBus(KindQuery)}
+}
+
+// Kind returns the kind of messages the bus dispatches
+func (b *Bus) Kind() Kind {
+	return b.kind
+}
+
+// Use wraps the handling of every message in middleware, the first being the outermost
+func (b *Bus) Use(middleware ...Middleware) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// Register sets the handler of the messages of messageType. Each type has one handler.
+func (b *Bus) Register(messageType reflect.Type, handler HandlerFunc) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.handlers[messageType]; ok {
+		return fmt.Errorf("%w for %s %s", ErrHandlerExists, b.kind, nameOf(messageType))
+	}
+	b.handlers[messageType] = handler
+	return nil
+}
+
+// Dispatch hands a message to the handler of its type through the middleware and returns
+// its result
+func (b *Bus) Dispatch(ctx context.Context, message any) (any, error) {
+	if message == nil {
+		return nil, errors.NewInvalidInput(stderrors.New("nil message"), fmt.Sprintf("no %s to dispatch", b.kind))
+	}
+	t := reflect.TypeOf(message)
+
+	b.mu.RLock()
+	handler, ok := b.handlers[t]
+	if ok {
+		for i := len(b.middleware) - 1; i >= 0; i-- {
+			handler = b.middleware[i](handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	if !ok {
+		err := fmt.Errorf("%w for %s %s", ErrNoHandler, b.kind, nameOf(t))
+		return nil, errors.WithCode(err, errors.CodeNotImplemented)
+	}
+	return handler(ctx, Message{Kind: b.kind, Name: nameOf(t), Value: message})
+}
+
+// Registrar is a bus handlers are registered on: a *CommandBus or a *QueryBus
+type Registrar interface {
+	Register(messageType reflect.Type, handler HandlerFunc) error
+}
+
+// Dispatcher is a bus messages are dispatched on: a *CommandBus or a *QueryBus
+type Dispatcher interface {
+	Dispatch(ctx context.Context, message any) (any, error)
+}
+
+// Register sets handler as the handler of the messages of type M
+func Register[M, R any](bus Registrar, handler Handler[M, R]) error {
+	return bus.Register(reflect.TypeFor[M](), func(ctx context.Context, message Message) (any, error) {
+		return handler.Handle(ctx, message.Value.(M))
+	})
+}
+
+// Dispatch dispatches a message on bus and returns the result of its handler as an R:
+//
+//	user, err := cqrs.Dispatch[*entity.User](ctx, commands, usecase.CreateUserCommand{Name: name})
+func Dispatch[R, M any](ctx context.Context, bus Dispatcher, message M) (R, error) {
+	var zero R
+	result, err := bus.Dispatch(ctx, message)
+	if result == nil {
+		return zero, err
+	}
+	r, ok := result.(R)
+	if !ok {
+		return zero, fmt.Errorf("%w: %T, not %s", ErrUnexpectedResult, result, reflect.TypeFor[R]())
+	}
+	return r, err
+}
+
+// nameOf names a message type after its Go type, without the pointer
+func nameOf(t reflect.Type) string {
+	return strings.TrimPrefix(t.String(), "*")
+}
@@ -0,0 +1,349 @@
+package cqrs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"sync"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/framework/unitofwork"
+	"github.com/axiomod/axiomod/framework/validation"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx"
+)
+
+type createUser struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func (c createUser) Permission() (string, string) { return "users", "create" }
+
+type getUser struct {
+	ID string
+}
+
+type renameUser struct {
+	ID   string
+	Name string
+}
+
+func (c renameUser) Validate() error {
+	if c.ID == c.Name {
+		return stderrors.New("name must differ from ID")
+	}
+	return nil
+}
+
+func TestDispatch(t *testing.T) {
+	ctx := context.Background()
+	commands := NewCommandBus()
+	require.NoError(t, Register[createUser, string](commands, Func[createUser, string](func(ctx context.Context, c createUser) (string, error) {
+		return "id-" + c.Name, nil
+	})))
+
+	id, err := Dispatch[string](ctx, commands, createUser{Name: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "id-alice", id)
+
+	_, err = Dispatch[int](ctx, commands, createUser{Name: "alice"})
+	assert.ErrorIs(t, err, ErrUnexpectedResult)
+
+	_, err = Dispatch[string](ctx, commands, getUser{ID: "1"})
+	assert.ErrorIs(t, err, ErrNoHandler)
+	assert.Equal(t, errors.CodeNotImplemented, errors.GetCode(err))
+	_, err = Dispatch[string](ctx, commands, &createUser{Name: "alice"})
+	assert.ErrorIs(t, err, ErrNoHandler, "pointers are other types than their elements")
+
+	err = Register[createUser, string](commands, Func[createUser, string](func(ctx context.Context, c createUser) (string, error) {
+		return "", nil
+	}))
+	assert.ErrorIs(t, err, ErrHandlerExists)
+}
+
+func TestValidation(t *testing.T) {
+	ctx := context.Background()
+	commands := NewCommandBus()
+	commands.Use(Validation(validation.New()))
+	var handled int
+	handler := func(ctx context.Context, c any) (any, error) {
+		handled++
+		return nil, nil
+	}
+	require.NoError(t, Register[createUser, any](commands, Func[createUser, any](func(ctx context.Context, c createUser) (any, error) { return handler(ctx, c) })))
+	require.NoError(t, Register[renameUser, any](commands, Func[renameUser, any](func(ctx context.Context, c renameUser) (any, error) { return handler(ctx, c) })))
+
+	_, err := commands.Dispatch(ctx, createUser{})
+	assert.Equal(t, errors.CodeValidation, errors.GetCode(err))
+	violations, _ := errors.GetMetadata(err)["violations"].([]validation.ValidationError)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "name", violations[0].Field)
+
+	_, err = commands.Dispatch(ctx, renameUser{ID: "a", Name: "a"})
+	assert.Equal(t, errors.CodeValidation, errors.GetCode(err))
+
+	_, err = commands.Dispatch(ctx, renameUser{ID: "a", Name: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, handled, "invalid messages do not reach their handler")
+}
+
+type fakeEnforcer map[string]bool
+
+func (e fakeEnforcer) Enforce(sub, obj, act string) (bool, error) {
+	if sub == "broken" {
+		return false, stderrors.New("policy unavailable")
+	}
+	return e[sub+":"+obj+":"+act], nil
+}
+
+type subjectKey struct{}
+
+func TestRBAC(t *testing.T) {
+	authorize := RBAC(fakeEnforcer{"alice:users:create": true}, func(ctx context.Context) (string, bool) {
+		sub, ok := ctx.Value(subjectKey{}).(string)
+		return sub, ok
+	})
+	tests := []struct {
+		name     string
+		subject  string
+		message  any
+		wantCode string
+	}{
+		{"allowed", "alice", createUser{Name: "a"}, ""},
+		{"no permission needed", "", getUser{ID: "1"}, ""},
+		{"no subject", "", createUser{Name: "a"}, errors.CodeUnauthorized},
+		{"denied", "bob", createUser{Name: "a"}, errors.CodeForbidden},
+		{"enforcer failure", "broken", createUser{Name: "a"}, errors.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.subject != "" {
+				ctx = context.WithValue(ctx, subjectKey{}, tt.subject)
+			}
+			err := authorize(ctx, Message{Kind: KindCommand, Name: "test", Value: tt.message})
+			if tt.wantCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantCode, errors.GetCode(err))
+		})
+	}
+}
+
+// recorder is a database driver recording the statements and transactions it runs
+type recorder struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (r *recorder) record(entry string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log = append(r.log, entry)
+}
+
+func (r *recorder) entries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.log...)
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                            { return nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, stderrors.New("not supported")
+}
+func (c recorderConn) Close() error { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("begin")
+	return recorderTx(c), nil
+}
+func (c recorderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+type recorderTx struct{ r *recorder }
+
+func (t recorderTx) Commit() error   { t.r.record("commit"); return nil }
+func (t recorderTx) Rollback() error { t.r.record("rollback"); return nil }
+
+func TestTransaction(t *testing.T) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	rec := &recorder{}
+	sqlDB := sql.OpenDB(rec)
+	defer sqlDB.Close()
+	db := database.New(sqlDB, logger, nil, cfg)
+
+	commands := NewCommandBus()
+	commands.Use(Transaction(db))
+	require.NoError(t, Register[createUser, any](commands, Func[createUser, any](func(ctx context.Context, c createUser) (any, error) {
+		_, inTx := database.TxFromContext(ctx)
+		assert.True(t, inTx)
+		if _, err := db.Exec(ctx, "INSERT "+c.Name); err != nil {
+			return nil, err
+		}
+		// Commands dispatched by a handler join its transaction
+		if _, err := commands.Dispatch(ctx, renameUser{ID: c.Name}); err != nil {
+			return nil, err
+		}
+		if c.Name == "fail" {
+			return nil, stderrors.New("failed")
+		}
+		return nil, nil
+	})))
+	require.NoError(t, Register[renameUser, any](commands, Func[renameUser, any](func(ctx context.Context, c renameUser) (any, error) {
+		_, err := db.Exec(ctx, "UPDATE "+c.ID)
+		return nil, err
+	})))
+
+	_, err := commands.Dispatch(context.Background(), createUser{Name: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"begin", "INSERT alice", "UPDATE alice", "commit"}, rec.entries())
+
+	rec.log = nil
+	_, err = commands.Dispatch(context.Background(), createUser{Name: "fail"})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, []string{"begin", "INSERT fail", "UPDATE fail", "rollback"}, rec.entries())
+
+	queries := NewQueryBus()
+	queries.Use(Transaction(db))
+	require.NoError(t, Register[getUser, any](queries, Func[getUser, any](func(ctx context.Context, q getUser) (any, error) {
+		_, inTx := database.TxFromContext(ctx)
+		assert.False(t, inTx, "queries do not run in transactions")
+		return nil, nil
+	})))
+	_, err = queries.Dispatch(context.Background(), getUser{ID: "1"})
+	assert.NoError(t, err)
+}
+
+func TestUnitOfWork(t *testing.T) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	rec := &recorder{}
+	sqlDB := sql.OpenDB(rec)
+	defer sqlDB.Close()
+	db := database.New(sqlDB, logger, nil, cfg)
+
+	dispatcher := events.NewDispatcher(logger)
+	require.NoError(t, events.Subscribe(dispatcher, func(ctx context.Context, name string) error {
+		rec.record("published " + name)
+		return nil
+	}))
+	commands := NewCommandBus()
+	commands.Use(UnitOfWork(unitofwork.New(db, dispatcher, logger)))
+	require.NoError(t, Register[createUser, any](commands, Func[createUser, any](func(ctx context.Context, c createUser) (any, error) {
+		if _, err := db.Exec(ctx, "INSERT "+c.Name); err != nil {
+			return nil, err
+		}
+		unitofwork.Record(ctx, "created "+c.Name)
+		// Commands dispatched by a handler join its unit of work
+		if _, err := commands.Dispatch(ctx, renameUser{ID: c.Name}); err != nil {
+			return nil, err
+		}
+		if c.Name == "fail" {
+			return nil, stderrors.New("failed")
+		}
+		return nil, nil
+	})))
+	require.NoError(t, Register[renameUser, any](commands, Func[renameUser, any](func(ctx context.Context, c renameUser) (any, error) {
+		unitofwork.Record(ctx, "renamed "+c.ID)
+		return nil, nil
+	})))
+
+	_, err := commands.Dispatch(context.Background(), createUser{Name: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"begin", "INSERT alice", "commit", "published created alice", "published renamed alice"}, rec.entries())
+
+	rec.log = nil
+	_, err = commands.Dispatch(context.Background(), createUser{Name: "fail"})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, []string{"begin", "INSERT fail", "rollback"}, rec.entries(), "events of rolled back commands are dropped")
+}
+
+type createUserHandler struct {
+	created []string
+}
+
+func newCreateUserHandler() *createUserHandler {
+	return &createUserHandler{}
+}
+
+func (h *createUserHandler) Handle(ctx context.Context, c createUser) (string, error) {
+	h.created = append(h.created, c.Name)
+	return "id-" + c.Name, nil
+}
+
+func TestModule(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := &observability.Tracer{Tracer: trace.NewTracerProvider(trace.WithSyncer(exporter)).Tracer("test")}
+
+	var commands *CommandBus
+	var queries *QueryBus
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(metrics, tracer),
+		Module,
+		AsCommandHandler[createUser, string](newCreateUserHandler),
+		AsQueryHandler[getUser, string](func() Handler[getUser, string] {
+			return Func[getUser, string](func(ctx context.Context, q getUser) (string, error) {
+				return "user " + q.ID, nil
+			})
+		}),
+		fx.Populate(&commands, &queries),
+	)
+	require.NoError(t, app.Err())
+
+	ctx := context.Background()
+	id, err := Dispatch[string](ctx, commands, createUser{Name: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "id-alice", id)
+	user, err := Dispatch[string](ctx, queries, getUser{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "user 1", user)
+
+	_, err = Dispatch[string](ctx, commands, createUser{})
+	assert.Equal(t, errors.CodeValidation, errors.GetCode(err), "messages are validated")
+	_, err = Dispatch[string](ctx, queries, createUser{Name: "alice"})
+	assert.ErrorIs(t, err, ErrNoHandler, "commands are not queries")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.CQRSMessagesTotal.WithLabelValues("command", "cqrs.createUser", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.CQRSMessagesTotal.WithLabelValues("command", "cqrs.createUser", "failure")))
+	spans := exporter.GetSpans()
+	require.NotEmpty(t, spans)
+	assert.Equal(t, "command cqrs.createUser", spans[0].Name)
+
+	app = fx.New(
+		fx.NopLogger,
+		fx.Supply(metrics, tracer),
+		Module,
+		AsCommandHandler[createUser, string](newCreateUserHandler),
+		fx.Provide(fx.Annotate(func() Registration {
+			return func(bus Registrar) error {
+				return Register[createUser, string](bus, newCreateUserHandler())
+			}
+		}, fx.ResultTags(`group:"cqrs_commands"`))),
+		fx.Populate(&commands),
+	)
+	assert.ErrorIs(t, app.Err(), ErrHandlerExists, "a command has one handler")
+}
@@ -0,0 +1,201 @@
+package cqrs
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/unitofwork"
+	"github.com/axiomod/axiomod/framework/validation"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Validatable is implemented by messages with rules beyond their validate tags
+type Validatable interface {
+	Validate() error
+}
+
+// Validation rejects messages failing their validate tags or their Validate method with a
+// VALIDATION_ERROR, before they reach their handler. The violations of validate tags are
+// listed in the "violations" metadata of the error, as Bind does.
+func Validation(v *validation.Validator) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (any, error) {
+			if isStruct(message.Value) {
+				if violations, err := v.Validate(message.Value); err != nil {
+					err = errors.WithCode(errors.Wrap(err, fmt.Sprintf("invalid %s", message.Kind)), errors.CodeValidation)
+					return nil, errors.WithMetadata(err, "violations", violations)
+				}
+			}
+			if validatable, ok := message.Value.(Validatable); ok {
+				if err := validatable.Validate(); err != nil {
+					return nil, errors.WithCode(errors.Wrap(err, fmt.Sprintf("invalid %s", message.Kind)), errors.CodeValidation)
+				}
+			}
+			return next(ctx, message)
+		}
+	}
+}
+
+// isStruct reports whether v is a struct or a pointer to one
+func isStruct(v any) bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// Authorizer returns an error if the caller of ctx may not dispatch message
+type Authorizer func(ctx context.Context, message Message) error
+
+// Authorization rejects the messages authorize refuses before they reach their handler
+func Authorization(authorize Authorizer) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (any, error) {
+			if err := authorize(ctx, message); err != nil {
+				return nil, err
+			}
+			return next(ctx, message)
+		}
+	}
+}
+
+// Permissioned is implemented by messages that need a permission to be dispatched
+type Permissioned interface {
+	// Permission returns the object and action the caller must be allowed
+	Permission() (object, action string)
+}
+
+// Enforcer decides whether a subject may perform an action on an object; *auth.RBACService
+// implements it
+type Enforcer interface {
+	Enforce(sub, obj, act string) (bool, error)
+}
+
+// SubjectFunc returns the subject dispatching with ctx, e.g. the ID of the authenticated user
+type SubjectFunc func(ctx context.Context) (string, bool)
+
+// RBAC authorizes messages implementing Permissioned with enforcer, for the subject of ctx.
+// Other messages are allowed.
+func RBAC(enforcer Enforcer, subject SubjectFunc) Authorizer {
+	return func(ctx context.Context, message Message) error {
+		permissioned, ok := message.Value.(Permissioned)
+		if !ok {
+			return nil
+		}
+		object, action := permissioned.Permission()
+		sub, ok := subject(ctx)
+		if !ok || sub == "" {
+			return errors.NewUnauthorized(stderrors.New("no subject"), fmt.Sprintf("%s %s needs an authenticated caller", message.Kind, message.Name))
+		}
+		allowed, err := enforcer.Enforce(sub, object, action)
+		if err != nil {
+			return errors.NewInternal(err, "authorization failed")
+		}
+		if !allowed {
+			return errors.NewForbidden(fmt.Errorf("%s may not %s %s", sub, action, object), "access denied")
+		}
+		return nil
+	}
+}
+
+// Transaction runs the handling of commands in a transaction of db, committed if the handler
+// succeeds and rolled back otherwise. Handlers run their queries in it by passing their
+// context to db, or with the *sql.Tx of database.TxFromContext. Commands dispatched while
+// handling a command join its transaction. Queries are not wrapped.
+func Transaction(db *database.DB) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (any, error) {
+			if message.Kind != KindCommand {
+				return next(ctx, message)
+			}
+			if _, ok := database.TxFromContext(ctx); ok {
+				return next(ctx, message)
+			}
+
+			var result any
+			err := db.WithTransaction(ctx, func(ctx context.Context, _ *sql.Tx) error {
+				var err error
+				result, err = next(ctx, message)
+				return err
+			})
+			return result, err
+		}
+	}
+}
+
+// UnitOfWork runs the handling of commands in a unit of work: in a transaction, as with
+// Transaction, after which the domain events the handler recorded with unitofwork.Track or
+// unitofwork.Record are delivered. Commands dispatched while handling a command join its unit
+// of work. Queries are not wrapped.
+func UnitOfWork(u *unitofwork.UnitOfWork) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (any, error) {
+			if message.Kind != KindCommand {
+				return next(ctx, message)
+			}
+
+			var result any
+			err := u.Do(ctx, func(ctx context.Context) error {
+				var err error
+				result, err = next(ctx, message)
+				return err
+			})
+			return result, err
+		}
+	}
+}
+
+// Tracing records a span for every message
+func Tracing(tracer trace.Tracer) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (any, error) {
+			ctx, span := tracer.Start(ctx, string(message.Kind)+" "+message.Name,
+				trace.WithAttributes(
+					attribute.String("cqrs.kind", string(message.Kind)),
+					attribute.String("cqrs.message", message.Name),
+				),
+			)
+			defer span.End()
+
+			result, err := next(ctx, message)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return result, err
+		}
+	}
+}
+
+// Metrics counts messages by result and records how long they took to handle
+func Metrics(metrics *observability.Metrics) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (any, error) {
+			start := time.Now()
+			result, err := next(ctx, message)
+
+			outcome := "success"
+			if err != nil {
+				outcome = "failure"
+			}
+			if metrics != nil && metrics.CQRSMessagesTotal != nil {
+				metrics.CQRSMessagesTotal.WithLabelValues(string(message.Kind), message.Name, outcome).Inc()
+			}
+			if metrics != nil && metrics.CQRSMessageDuration != nil {
+				metrics.CQRSMessageDuration.WithLabelValues(string(message.Kind), message.Name).Observe(time.Since(start).Seconds())
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cqrs
+
+import (
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/unitofwork"
+	"github.com/axiomod/axiomod/framework/validation"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+)
+
+// Value groups collecting the handlers of modules
+const (
+	CommandGroup = "cqrs_commands"
+	QueryGroup   = "cqrs_queries"
+)
+
+// Module provides the command and query buses with the handlers declared with
+// AsCommandHandler and AsQueryHandler. Both buses trace, measure and validate messages, and
+// commands run in the unit of work of unitofwork.Module when it is provided, or else in a
+// transaction when a *database.DB is provided. Add authorization with
+// bus.Use(cqrs.Authorization(...)) from an fx.Invoke.
+var Module = fx.Options(
+	fx.Provide(ProvideCommandBus, ProvideQueryBus),
+)
+
+// Registration is a handler of a message type, collected in a value group
+type Registration func(bus Registrar) error
+
+// AsCommandHandler provides the handler of commands of type C built by constructor, which
+// returns a Handler[C, R]:
+//
+//	cqrs.AsCommandHandler[usecase.CreateUserCommand, *entity.User](usecase.NewCreateUserUseCase)
+func AsCommandHandler[C, R any](constructor interface{}) fx.Option {
+	return asHandler[C, R](constructor, CommandGroup)
+}
+
+// AsQueryHandler provides the handler of queries of type Q built by constructor, which
+// returns a Handler[Q, R]
+func AsQueryHandler[Q, R any](constructor interface{}) fx.Option {
+	return asHandler[Q, R](constructor, QueryGroup)
+}
+
+func asHandler[M, R any](constructor interface{}, group string) fx.Option {
+	return fx.Options(
+		fx.Provide(fx.Annotate(constructor, fx.As(new(Handler[M, R])))),
+		fx.Provide(fx.Annotate(func(handler Handler[M, R]) Registration {
+			return func(bus Registrar) error {
+				return Register(bus, handler)
+			}
+		}, fx.ResultTags(`group:"`+group+`"`))),
+	)
+}
+
+// CommandBusParams holds the dependencies of the command bus
+type CommandBusParams struct {
+	fx.In
+
+	Metrics    *observability.Metrics
+	Tracer     *observability.Tracer
+	DB         *database.DB           `optional:"true"`
+	UnitOfWork *unitofwork.UnitOfWork `optional:"true"`
+	Handlers   []Registration         `group:"cqrs_commands"`
+}
+
+// ProvideCommandBus provides the command bus with the handlers declared with AsCommandHandler
+func ProvideCommandBus(p CommandBusParams) (*CommandBus, error) {
+	bus := NewCommandBus()
+	bus.Use(Tracing(p.Tracer.Tracer), Metrics(p.Metrics), Validation(validation.New()))
+	switch {
+	case p.UnitOfWork != nil:
+		bus.Use(UnitOfWork(p.UnitOfWork))
+	case p.DB != nil:
+		bus.Use(Transaction(p.DB))
+	}
+	for _, register := range p.Handlers {
+		if err := register(bus); err != nil {
+			return nil, err
+		}
+	}
+	return bus, nil
+}
+
+// QueryBusParams holds the dependencies of the query bus
+type QueryBusParams struct {
+	fx.In
+
+	Metrics  *observability.Metrics
+	Tracer   *observability.Tracer
+	Handlers []Registration `group:"cqrs_queries"`
+}
+
+// ProvideQueryBus provides the query bus with the handlers declared with AsQueryHandler
+func ProvideQueryBus(p QueryBusParams) (*QueryBus, error) {
+	bus := NewQueryBus()
+	bus.Use(Tracing(p.Tracer.Tracer), Metrics(p.Metrics), Validation(validation.New()))
+	for _, register := range p.Handlers {
+		if err := register(bus); err != nil {
+			return nil, err
+		}
+	}
+	return bus, nil
+}
@@ -0,0 +1,47 @@
+package ctxkit
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// DebugTraceMember is the OTel baggage member forcing the sampling of the traces of a request
+// and of the requests it causes in other services
+const DebugTraceMember = "axiomod.debug_trace"
+
+// Baggage returns the value of an OTel baggage member of ctx, or "" when it has none. Callers
+// set baggage with the W3C baggage header, and it is forwarded with the trace context, so
+// members can toggle behavior for a single request across services.
+func Baggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// BaggageMembers returns the OTel baggage members of ctx
+func BaggageMembers(ctx context.Context) map[string]string {
+	members := baggage.FromContext(ctx).Members()
+	values := make(map[string]string, len(members))
+	for _, member := range members {
+		values[member.Key()] = member.Value()
+	}
+	return values
+}
+
+// WithBaggage returns a context carrying an OTel baggage member, forwarded to the services
+// the request calls. Keys must not be empty.
+func WithBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, err
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, err
+	}
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// DebugTrace reports whether the traces of the request of ctx are sampled for debugging
+func DebugTrace(ctx context.Context) bool {
+	return Baggage(ctx, DebugTraceMember) == "1"
+}
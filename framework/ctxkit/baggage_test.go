@@ -0,0 +1,27 @@
+package ctxkit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaggage(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, Baggage(ctx, "feature.checkout"))
+	assert.Empty(t, BaggageMembers(ctx))
+	assert.False(t, DebugTrace(ctx))
+
+	ctx, err := WithBaggage(ctx, "feature.checkout", "v2")
+	require.NoError(t, err)
+	ctx, err = WithBaggage(ctx, DebugTraceMember, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", Baggage(ctx, "feature.checkout"))
+	assert.Equal(t, map[string]string{"feature.checkout": "v2", DebugTraceMember: "1"}, BaggageMembers(ctx))
+	assert.True(t, DebugTrace(ctx))
+
+	_, err = WithBaggage(ctx, "", "v")
+	assert.Error(t, err)
+}
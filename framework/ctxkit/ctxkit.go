@@ -0,0 +1,190 @@
+// Package ctxkit carries the request ID, tenant, user and locale of a request in its context
+// so they reach services, database calls and logs, and forwards them as headers to the
+// services and topics the request calls. The HTTP middleware and gRPC interceptors set them
+// on incoming requests; the HTTP client and Kafka producer forward them.
+package ctxkit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Headers carrying the request values between services. gRPC metadata uses their lower-case form.
+const (
+	HeaderRequestID = "X-Request-ID"
+	HeaderTenantID  = "X-Tenant-ID"
+	HeaderUserID    = "X-User-ID"
+	HeaderLocale    = "Accept-Language"
+	// HeaderTimeout carries the time left before the deadline of the caller, in milliseconds
+	HeaderTimeout = "X-Request-Timeout"
+)
+
+// maxRequestIDLength bounds the request IDs accepted from callers
+const maxRequestIDLength = 128
+
+type key int
+
+const (
+	requestIDKey key = iota
+	tenantIDKey
+	userIDKey
+	localeKey
+)
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return withValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID of ctx, empty if it has none
+func RequestID(ctx context.Context) string {
+	return value(ctx, requestIDKey)
+}
+
+// WithTenantID returns a context carrying the tenant ID
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return withValue(ctx, tenantIDKey, tenantID)
+}
+
+// TenantID returns the tenant ID of ctx, empty if it has none
+func TenantID(ctx context.Context) string {
+	return value(ctx, tenantIDKey)
+}
+
+// WithUserID returns a context carrying the ID of the authenticated user
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return withValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the user ID of ctx, empty if it has none
+func UserID(ctx context.Context) string {
+	return value(ctx, userIDKey)
+}
+
+// WithLocale returns a context carrying the preferred locale of the caller, such as "fr-CH"
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return withValue(ctx, localeKey, locale)
+}
+
+// Locale returns the locale of ctx, empty if it has none
+func Locale(ctx context.Context) string {
+	return value(ctx, localeKey)
+}
+
+// withValue returns ctx unchanged for empty values, so they do not hide values set earlier
+func withValue(ctx context.Context, k key, v string) context.Context {
+	if v == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, k, v)
+}
+
+func value(ctx context.Context, k key) string {
+	v, _ := ctx.Value(k).(string)
+	return v
+}
+
+// NewRequestID returns a new random request ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// ValidRequestID reports whether a request ID received from a caller can be used as is: it
+// must have at most 128 printable ASCII characters and no spaces, so it is safe to log and
+// to send back in headers
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLocale returns the preferred locale of an Accept-Language header, such as "fr-CH" for
+// "fr-CH, fr;q=0.9, en;q=0.8", or an empty string when it names none
+func ParseLocale(acceptLanguage string) string {
+	first, _, _ := strings.Cut(acceptLanguage, ",")
+	locale, _, _ := strings.Cut(first, ";")
+	locale = strings.TrimSpace(locale)
+	if locale == "*" || len(locale) > 35 {
+		return ""
+	}
+	return locale
+}
+
+// Headers returns the headers forwarding the values of ctx to another service. The user ID
+// is informational: servers authenticate their callers and do not read it back.
+func Headers(ctx context.Context) map[string]string {
+	headers := make(map[string]string, 4)
+	for header, v := range map[string]string{
+		HeaderRequestID: RequestID(ctx),
+		HeaderTenantID:  TenantID(ctx),
+		HeaderUserID:    UserID(ctx),
+		HeaderLocale:    Locale(ctx),
+	} {
+		if v != "" {
+			headers[header] = v
+		}
+	}
+	return headers
+}
+
+// FromHeaders returns ctx with the request ID, tenant and locale read from headers by get.
+// Invalid request IDs are ignored; the user ID is not read, since it must come from
+// authentication.
+func FromHeaders(ctx context.Context, get func(header string) string) context.Context {
+	if requestID := get(HeaderRequestID); ValidRequestID(requestID) {
+		ctx = WithRequestID(ctx, requestID)
+	}
+	ctx = WithTenantID(ctx, get(HeaderTenantID))
+	return WithLocale(ctx, ParseLocale(get(HeaderLocale)))
+}
+
+// Timeout returns the value of the timeout header for the deadline of ctx, or false when ctx
+// has no deadline
+func Timeout(ctx context.Context) (string, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "", false
+	}
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining < 1 {
+		remaining = 1
+	}
+	return strconv.FormatInt(remaining, 10), true
+}
+
+// WithTimeout returns ctx bounded by the deadline of a timeout header value. Invalid values
+// leave ctx unchanged; the cancel function must be called in any case.
+func WithTimeout(ctx context.Context, timeout string) (context.Context, context.CancelFunc) {
+	ms, err := strconv.ParseInt(timeout, 10, 64)
+	if err != nil || ms <= 0 || ms > int64(math.MaxInt64/time.Millisecond) {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(ms)*time.Millisecond)
+}
+
+// Fields returns the log fields of the values of ctx
+func Fields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if tenantID := TenantID(ctx); tenantID != "" {
+		fields = append(fields, zap.String("tenant_id", tenantID))
+	}
+	if userID := UserID(ctx); userID != "" {
+		fields = append(fields, zap.String("user_id", userID))
+	}
+	return fields
+}
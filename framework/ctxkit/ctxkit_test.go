@@ -0,0 +1,109 @@
+package ctxkit
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessors(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestID(ctx))
+	assert.Empty(t, Headers(ctx))
+	assert.Empty(t, Fields(ctx))
+
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithTenantID(ctx, "acme")
+	ctx = WithUserID(ctx, "user-7")
+	ctx = WithLocale(ctx, "fr-CH")
+	// Empty values do not hide values set earlier
+	ctx = WithTenantID(ctx, "")
+
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, "acme", TenantID(ctx))
+	assert.Equal(t, "user-7", UserID(ctx))
+	assert.Equal(t, "fr-CH", Locale(ctx))
+	assert.Equal(t, map[string]string{
+		HeaderRequestID: "req-1",
+		HeaderTenantID:  "acme",
+		HeaderUserID:    "user-7",
+		HeaderLocale:    "fr-CH",
+	}, Headers(ctx))
+	assert.Len(t, Fields(ctx), 3)
+}
+
+func TestFromHeaders(t *testing.T) {
+	headers := map[string]string{
+		HeaderRequestID: "req-1",
+		HeaderTenantID:  "acme",
+		HeaderUserID:    "admin",
+		HeaderLocale:    "de-CH;q=1, de;q=0.8",
+	}
+	ctx := FromHeaders(context.Background(), func(header string) string { return headers[header] })
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, "acme", TenantID(ctx))
+	assert.Equal(t, "de-CH", Locale(ctx))
+	assert.Empty(t, UserID(ctx), "the user ID comes from authentication")
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		requestID string
+		valid     bool
+	}{
+		{NewRequestID(), true},
+		{"req-1:retry.2", true},
+		{"", false},
+		{"has spaces", false},
+		{"line\nbreak", false},
+		{"café", false},
+		{strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, ValidRequestID(tt.requestID), tt.requestID)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"*":                         "",
+		"en":                        "en",
+		"fr-CH, fr;q=0.9, en;q=0.8": "fr-CH",
+		" pt-BR ;q=0.5":             "pt-BR",
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, ParseLocale(header), header)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	_, ok := Timeout(context.Background())
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	value, ok := Timeout(ctx)
+	require.True(t, ok)
+	ms, err := strconv.Atoi(value)
+	require.NoError(t, err)
+	assert.InDelta(t, 2000, ms, 100)
+
+	bounded, cancel := WithTimeout(context.Background(), value)
+	defer cancel()
+	deadline, ok := bounded.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), deadline, 100*time.Millisecond)
+
+	for _, invalid := range []string{"", "soon", "-5", "0", "99999999999999999"} {
+		unbounded, cancel := WithTimeout(context.Background(), invalid)
+		_, ok := unbounded.Deadline()
+		assert.False(t, ok, invalid)
+		cancel()
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/ctxkit"
 	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/framework/metering"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"go.uber.org/zap"
@@ -34,7 +36,24 @@ func New(db *sql.DB, logger *observability.Logger, metrics *observability.Metric
 	}
 }
 
-// WithTransaction executes the given function within a transaction
+// txKey is the context key of the transaction queries run in
+type txKey struct{}
+
+// ctxTx is a transaction carried by a context, with the database it belongs to
+type ctxTx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// TxFromContext returns the transaction started by WithTransaction that ctx runs in, if any
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	t, ok := ctx.Value(txKey{}).(ctxTx)
+	return t.tx, ok
+}
+
+// WithTransaction executes the given function within a transaction. The context passed to
+// fn carries the transaction, so that Exec, Query and QueryRow called with it run in the
+// transaction too.
 func (d *DB) WithTransaction(ctx context.Context, fn TransactionFunc) error {
 	// Start a transaction
 	tx, err := d.db.BeginTx(ctx, nil)
@@ -42,6 +61,7 @@ func (d *DB) WithTransaction(ctx context.Context, fn TransactionFunc) error {
 		d.logger.Error("Failed to begin transaction", zap.Error(err))
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	ctx = context.WithValue(ctx, txKey{}, ctxTx{db: d.db, tx: tx})
 
 	// Execute the function
 	if err := fn(ctx, tx); err != nil {
@@ -127,31 +147,44 @@ func (d *DB) Close() error {
 // Exec executes a query without returning any rows
 func (d *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	start := time.Now()
-	res, err := d.db.ExecContext(ctx, query, args...)
-	d.recordQuery(query, "exec", start, err)
+	res, err := d.conn(ctx).ExecContext(ctx, query, args...)
+	d.recordQuery(ctx, query, "exec", start, err)
 	return res, err
 }
 
 // Query executes a query that returns rows
 func (d *DB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	start := time.Now()
-	rows, err := d.db.QueryContext(ctx, query, args...)
-	d.recordQuery(query, "query", start, err)
+	rows, err := d.conn(ctx).QueryContext(ctx, query, args...)
+	d.recordQuery(ctx, query, "query", start, err)
 	return rows, err
 }
 
 // QueryRow executes a query that is expected to return at most one row
 func (d *DB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	start := time.Now()
-	row := d.db.QueryRowContext(ctx, query, args...)
+	row := d.conn(ctx).QueryRowContext(ctx, query, args...)
 	// Note: We can't easily check for error until Scan is called,
 	// but we record the duration anyway.
-	d.recordQuery(query, "query_row", start, nil)
+	d.recordQuery(ctx, query, "query_row", start, nil)
 	return row
 }
 
-func (d *DB) recordQuery(query, queryType string, start time.Time, err error) {
+// conn returns the transaction of ctx if it belongs to this database, or the database itself
+func (d *DB) conn(ctx context.Context) interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+} {
+	if t, ok := ctx.Value(txKey{}).(ctxTx); ok && t.db == d.db {
+		return t.tx
+	}
+	return d.db
+}
+
+func (d *DB) recordQuery(ctx context.Context, query, queryType string, start time.Time, err error) {
 	duration := time.Since(start)
+	metering.RecordDBQuery(ctx, duration)
 
 	// Record metrics
 	status := "success"
@@ -169,12 +202,12 @@ func (d *DB) recordQuery(query, queryType string, start time.Time, err error) {
 	}
 
 	if duration > threshold {
-		d.logger.Warn("Slow database query detected",
+		d.logger.Warn("Slow database query detected", append(ctxkit.Fields(ctx),
 			zap.String("query", query),
 			zap.String("type", queryType),
 			zap.Duration("duration", duration),
 			zap.Error(err),
-		)
+		)...)
 	}
 }
 
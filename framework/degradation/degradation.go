@@ -0,0 +1,160 @@
+// Package degradation serves fallback data, such as stale cached values or defaults,
+// when a dependency is unavailable, and flags the results as degraded.
+package degradation
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/axiomod/axiomod/framework/circuitbreaker"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+)
+
+// Module records the results of every Fallback on metrics
+var Module = fx.Options(
+	fx.Invoke(Observe),
+)
+
+// SourcePrimary is the source of results from the primary operation
+const SourcePrimary = "primary"
+
+// sourceFailed is the metrics source of operations without any result
+const sourceFailed = "failed"
+
+// ErrNoFallback is returned, joined with the errors of the primary and the providers,
+// when no fallback provided a result. The error has the SERVICE_UNAVAILABLE code.
+var ErrNoFallback = stderrors.New("no fallback provided a result")
+
+// observedMetrics are the metrics results are recorded on
+var observedMetrics atomic.Pointer[observability.Metrics]
+
+// Observe records the results of every Fallback on metrics
+func Observe(metrics *observability.Metrics) {
+	observedMetrics.Store(metrics)
+}
+
+// Provider supplies a fallback result. Fetch receives the error of the primary.
+type Provider[T any] struct {
+	Name  string // source of its results, e.g. "stale" or "default"
+	Fetch func(ctx context.Context, cause error) (T, error)
+}
+
+// Default returns a provider of a fixed value, e.g. an empty list or default settings
+func Default[T any](value T) Provider[T] {
+	return Provider[T]{
+		Name: "default",
+		Fetch: func(ctx context.Context, cause error) (T, error) {
+			return value, nil
+		},
+	}
+}
+
+// Result is the result of an operation with fallbacks
+type Result[T any] struct {
+	Value    T
+	Degraded bool   // the value comes from a fallback
+	Source   string // SourcePrimary or the name of the fallback
+	Cause    error  // why the primary was not used, nil if it was
+}
+
+// Unavailable reports whether err means a dependency is unavailable rather than that the
+// request failed: an open circuit, a full bulkhead, an exceeded concurrency limit, a
+// timeout, or a SERVICE_UNAVAILABLE or TIMEOUT error
+func Unavailable(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case stderrors.Is(err, resilience.ErrCircuitOpen),
+		stderrors.Is(err, circuitbreaker.ErrOpen),
+		stderrors.Is(err, resilience.ErrBulkheadFull),
+		stderrors.Is(err, resilience.ErrLimitExceeded),
+		stderrors.Is(err, resilience.ErrTimeout),
+		stderrors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	var coded *errors.Error
+	if stderrors.As(err, &coded) {
+		switch coded.Code {
+		case errors.CodeUnavailable, errors.CodeTimeout, errors.CodeDeadlineExceeded:
+			return true
+		}
+	}
+	return false
+}
+
+// Fallback runs an operation through a resilience policy and falls back to providers
+// when its dependency is unavailable
+type Fallback[T any] struct {
+	name      string
+	policy    *resilience.Resilience
+	providers []Provider[T]
+	degrade   func(error) bool
+}
+
+// New creates a fallback for the operation called name, e.g. "products.get". policy may
+// be nil to call the primary directly. Providers are tried in order.
+func New[T any](name string, policy *resilience.Resilience, providers ...Provider[T]) *Fallback[T] {
+	return &Fallback[T]{
+		name:      name,
+		policy:    policy,
+		providers: providers,
+		degrade:   Unavailable,
+	}
+}
+
+// DegradeWhen replaces Unavailable as the test of which errors of the primary are served
+// from fallbacks; other errors are returned as they are
+func (f *Fallback[T]) DegradeWhen(degrade func(error) bool) *Fallback[T] {
+	f.degrade = degrade
+	return f
+}
+
+// Execute runs primary and returns its result. If it fails because its dependency is
+// unavailable, the providers passed here and then those of the fallback are tried in
+// order, and the first result is returned flagged as degraded. Degraded results are
+// added to the Report of ctx and, in gRPC servers, to the response header.
+func (f *Fallback[T]) Execute(ctx context.Context, primary func(ctx context.Context) (T, error), providers ...Provider[T]) (Result[T], error) {
+	var value T
+	var err error
+	if f.policy != nil {
+		value, err = resilience.Execute(ctx, f.policy, primary)
+	} else {
+		value, err = primary(ctx)
+	}
+	if err == nil {
+		f.record(SourcePrimary)
+		return Result[T]{Value: value, Source: SourcePrimary}, nil
+	}
+	if !f.degrade(err) {
+		f.record(sourceFailed)
+		return Result[T]{}, err
+	}
+
+	errs := []error{err}
+	for _, provider := range append(providers, f.providers...) {
+		value, fetchErr := provider.Fetch(ctx, err)
+		if fetchErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name, fetchErr))
+			continue
+		}
+		f.record(provider.Name)
+		report(ctx, Degradation{Operation: f.name, Source: provider.Name, Cause: err})
+		return Result[T]{Value: value, Degraded: true, Source: provider.Name, Cause: err}, nil
+	}
+
+	f.record(sourceFailed)
+	return Result[T]{}, errors.WithCode(stderrors.Join(append([]error{ErrNoFallback}, errs...)...), errors.CodeUnavailable)
+}
+
+// record counts a result by source
+func (f *Fallback[T]) record(source string) {
+	if metrics := observedMetrics.Load(); metrics != nil && metrics.DegradationResultsTotal != nil {
+		metrics.DegradationResultsTotal.WithLabelValues(f.name, source).Inc()
+	}
+}
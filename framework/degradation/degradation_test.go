@@ -0,0 +1,153 @@
+package degradation
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/circuitbreaker"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDown = stderrors.New("connection refused")
+
+func TestUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"open circuit", resilience.ErrCircuitOpen, true},
+		{"open breaker", circuitbreaker.ErrOpen, true},
+		{"full bulkhead", resilience.ErrBulkheadFull, true},
+		{"timeout", resilience.ErrTimeout, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unavailable code", errors.WithCode(errDown, errors.CodeUnavailable), true},
+		{"wrapped code", stderrors.Join(errDown, errors.WithCode(errDown, errors.CodeTimeout)), true},
+		{"not found", errors.NewNotFound(errDown, "missing"), false},
+		{"plain", errDown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Unavailable(tt.err))
+		})
+	}
+}
+
+func TestFallbackExecute(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	Observe(metrics)
+	t.Cleanup(func() { observedMetrics.Store(nil) })
+
+	options := resilience.DefaultResilienceOptions()
+	options.Retry = nil
+	options.CircuitBreaker.MaxFailures = 1
+	options.CircuitBreaker.ResetTimeout = time.Hour
+	policy := resilience.New(options)
+
+	stale := NewStale[string](0, 0)
+	fallback := New("products.get", policy, Default("placeholder"))
+
+	// The primary's results are stored for later
+	result, err := fallback.Execute(context.Background(), stale.Record("1", func(ctx context.Context) (string, error) {
+		return "fresh", nil
+	}), stale.For("1"))
+	require.NoError(t, err)
+	assert.Equal(t, Result[string]{Value: "fresh", Source: SourcePrimary}, result)
+
+	// Errors that do not mean the dependency is down are returned as they are, and
+	// trip the circuit
+	notFound := errors.NewNotFound(errDown, "missing")
+	_, err = fallback.Execute(context.Background(), func(ctx context.Context) (string, error) {
+		return "", notFound
+	}, stale.For("1"))
+	assert.ErrorIs(t, err, notFound)
+
+	// Once the circuit is open, the stale value is served
+	ctx, report := NewContext(context.Background())
+	result, err = fallback.Execute(ctx, func(ctx context.Context) (string, error) {
+		t.Fatal("the primary is not called while the circuit is open")
+		return "", nil
+	}, stale.For("1"))
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", result.Value)
+	assert.True(t, result.Degraded)
+	assert.Equal(t, "stale", result.Source)
+	assert.ErrorIs(t, result.Cause, resilience.ErrCircuitOpen)
+
+	// Without a stale value, the registered default is served
+	result, err = fallback.Execute(ctx, func(ctx context.Context) (string, error) {
+		return "", nil
+	}, stale.For("2"))
+	require.NoError(t, err)
+	assert.Equal(t, "placeholder", result.Value)
+	assert.Equal(t, "default", result.Source)
+
+	assert.True(t, report.Degraded())
+	assert.Equal(t, "products.get=stale, products.get=default", report.Header())
+	require.Len(t, report.Degradations(), 2)
+
+	counter := metrics.DegradationResultsTotal
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues("products.get", SourcePrimary)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues("products.get", "stale")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues("products.get", "default")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues("products.get", sourceFailed)))
+}
+
+func TestFallbackWithoutResult(t *testing.T) {
+	fallback := New[int]("inventory.count", nil, NewStale[int](0, 0).For("sku"))
+
+	_, err := fallback.Execute(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errors.WithCode(errDown, errors.CodeUnavailable)
+	})
+	assert.ErrorIs(t, err, ErrNoFallback)
+	assert.ErrorIs(t, err, errDown)
+	assert.ErrorIs(t, err, ErrNoStale)
+	assert.Equal(t, errors.CodeUnavailable, errors.GetCode(err))
+
+	// Reports are optional
+	fallback = New("inventory.count", nil, Default(0)).DegradeWhen(func(err error) bool { return true })
+	result, err := fallback.Execute(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errDown
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Degraded)
+	assert.Nil(t, FromContext(context.Background()))
+}
+
+func TestStale(t *testing.T) {
+	now := time.Now()
+	stale := NewStale[int](time.Minute, 2)
+	stale.now = func() time.Time { return now }
+
+	stale.Put("a", 1)
+	stale.Put("b", 2)
+	_, ok := stale.Get("a")
+	assert.True(t, ok)
+
+	// "b" is the least recently used
+	stale.Put("c", 3)
+	_, ok = stale.Get("b")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = stale.Get("a")
+	assert.False(t, ok, "values older than the max age are not served")
+
+	stale.Put("a", 4)
+	value, ok := stale.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+}
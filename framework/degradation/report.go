@@ -0,0 +1,87 @@
+package degradation
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the response header listing the degraded operations of a request, e.g.
+// "X-Degraded: products.get=stale"
+const Header = "X-Degraded"
+
+// grpcHeader is the gRPC response header listing the degraded operations of a call
+const grpcHeader = "x-degraded"
+
+// Degradation is a result served from a fallback
+type Degradation struct {
+	Operation string
+	Source    string
+	Cause     error
+}
+
+// Report collects the degradations of a request
+type Report struct {
+	mu           sync.Mutex
+	degradations []Degradation
+}
+
+type reportKey struct{}
+
+// NewContext returns a context collecting the degradations of Fallbacks executed with it
+func NewContext(ctx context.Context) (context.Context, *Report) {
+	r := &Report{}
+	return context.WithValue(ctx, reportKey{}, r), r
+}
+
+// FromContext returns the report of ctx, or nil if it has none
+func FromContext(ctx context.Context) *Report {
+	r, _ := ctx.Value(reportKey{}).(*Report)
+	return r
+}
+
+// Degraded reports whether any result was served from a fallback
+func (r *Report) Degraded() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.degradations) > 0
+}
+
+// Degradations returns the degradations in the order they happened
+func (r *Report) Degradations() []Degradation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Degradation(nil), r.degradations...)
+}
+
+// Header returns the value of the Header response header, empty if nothing was degraded
+func (r *Report) Header() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]string, len(r.degradations))
+	for i, d := range r.degradations {
+		values[i] = d.Operation + "=" + d.Source
+	}
+	return strings.Join(values, ", ")
+}
+
+// add records a degradation
+func (r *Report) add(d Degradation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.degradations = append(r.degradations, d)
+}
+
+// report adds a degradation to the report of ctx and, in gRPC servers, to the response
+// header
+func report(ctx context.Context, d Degradation) {
+	if r := FromContext(ctx); r != nil {
+		r.add(d)
+	}
+	// Fails outside of gRPC servers and after the header was sent, neither of which
+	// affects the result
+	_ = grpc.SetHeader(ctx, metadata.Pairs(grpcHeader, d.Operation+"="+d.Source))
+}
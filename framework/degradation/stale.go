@@ -0,0 +1,106 @@
+package degradation
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoStale is returned by the providers of a Stale cache without a fresh enough value
+var ErrNoStale = errors.New("no stale value")
+
+// Stale keeps the last successful results of an operation so they can be served while
+// its dependency is unavailable. It holds at most maxEntries keys, evicting the least
+// recently used.
+type Stale[T any] struct {
+	maxAge     time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+}
+
+// staleEntry is a cached result
+type staleEntry[T any] struct {
+	key    string
+	value  T
+	stored time.Time
+}
+
+// NewStale creates a cache serving values up to maxAge old (0 for any age) for up to
+// maxEntries keys (0 for no limit)
+func NewStale[T any](maxAge time.Duration, maxEntries int) *Stale[T] {
+	return &Stale[T]{
+		maxAge:     maxAge,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Put stores the latest value of key
+func (s *Stale[T]) Put(key string, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		entry := el.Value.(*staleEntry[T])
+		entry.value, entry.stored = value, s.now()
+		s.order.MoveToFront(el)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&staleEntry[T]{key: key, value: value, stored: s.now()})
+	if s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*staleEntry[T]).key)
+	}
+}
+
+// Get returns the value of key if it is not older than maxAge
+func (s *Stale[T]) Get(key string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var zero T
+	el, ok := s.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*staleEntry[T])
+	if s.maxAge > 0 && s.now().Sub(entry.stored) > s.maxAge {
+		s.order.Remove(el)
+		delete(s.entries, key)
+		return zero, false
+	}
+	s.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// For returns a provider of the value of key, named "stale"
+func (s *Stale[T]) For(key string) Provider[T] {
+	return Provider[T]{
+		Name: "stale",
+		Fetch: func(ctx context.Context, cause error) (T, error) {
+			if value, ok := s.Get(key); ok {
+				return value, nil
+			}
+			var zero T
+			return zero, ErrNoStale
+		},
+	}
+}
+
+// Record wraps a primary operation so its successful results are stored under key
+func (s *Stale[T]) Record(key string, primary func(ctx context.Context) (T, error)) func(ctx context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		value, err := primary(ctx)
+		if err == nil {
+			s.Put(key, value)
+		}
+		return value, err
+	}
+}
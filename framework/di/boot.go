@@ -0,0 +1,175 @@
+package di
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.uber.org/fx"
+)
+
+// After declares modules that must boot before this one
+func (m *Module) After(names ...string) *Module {
+	m.DependsOn = append(m.DependsOn, names...)
+	return m
+}
+
+// WithPriority sets the boot priority of the module. Among modules whose dependencies have
+// booted, lower priorities boot first; modules with equal priorities keep their declaration order.
+func (m *Module) WithPriority(priority int) *Module {
+	m.Priority = priority
+	return m
+}
+
+// BootPlan is the order in which modules boot. Modules are added to the fx application in
+// this order, so their invocations, and the lifecycle hooks they register, run in it too.
+type BootPlan struct {
+	Modules []*Module
+}
+
+// PlanBoot orders modules so that every module boots after the modules it depends on.
+// It fails on duplicate module names, unknown dependencies and dependency cycles.
+func PlanBoot(modules ...*Module) (*BootPlan, error) {
+	index := make(map[string]int, len(modules))
+	for i, m := range modules {
+		if _, ok := index[m.Name]; ok {
+			return nil, fmt.Errorf("duplicate module %q", m.Name)
+		}
+		index[m.Name] = i
+	}
+
+	pending := make([]int, len(modules))
+	dependents := make([][]int, len(modules))
+	for i, m := range modules {
+		for _, dep := range m.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("module %q depends on unknown module %q", m.Name, dep)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Kahn's algorithm, picking the ready module with the lowest priority, then declaration order
+	var ready []int
+	for i := range modules {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	plan := &BootPlan{Modules: make([]*Module, 0, len(modules))}
+	for len(ready) > 0 {
+		sort.Slice(ready, func(a, b int) bool {
+			ma, mb := modules[ready[a]], modules[ready[b]]
+			if ma.Priority != mb.Priority {
+				return ma.Priority < mb.Priority
+			}
+			return ready[a] < ready[b]
+		})
+		next := ready[0]
+		ready = ready[1:]
+		plan.Modules = append(plan.Modules, modules[next])
+		for _, i := range dependents[next] {
+			pending[i]--
+			if pending[i] == 0 {
+				ready = append(ready, i)
+			}
+		}
+	}
+
+	if len(plan.Modules) < len(modules) {
+		return nil, fmt.Errorf("module dependency cycle: %s", strings.Join(findCycle(modules, index, pending), " -> "))
+	}
+	return plan, nil
+}
+
+// findCycle returns the names along a dependency cycle among the modules left unplanned
+func findCycle(modules []*Module, index map[string]int, pending []int) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(modules))
+	var path []int
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		path = append(path, i)
+		for _, dep := range modules[i].DependsOn {
+			j := index[dep]
+			switch state[j] {
+			case visiting:
+				// The cycle starts where j entered the path
+				var names []string
+				for k := len(path) - 1; k >= 0; k-- {
+					names = append(names, modules[path[k]].Name)
+					if path[k] == j {
+						break
+					}
+				}
+				// Reverse to read as "a -> b", meaning a boots after b
+				for l, r := 0, len(names)-1; l < r; l, r = l+1, r-1 {
+					names[l], names[r] = names[r], names[l]
+				}
+				return append(names, modules[j].Name)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		return nil
+	}
+
+	for i := range modules {
+		if pending[i] > 0 && state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Options returns the fx options of the modules in boot order
+func (p *BootPlan) Options() []fx.Option {
+	options := make([]fx.Option, 0, len(p.Modules))
+	for _, m := range p.Modules {
+		options = append(options, m.Build())
+	}
+	return options
+}
+
+// Names returns the module names in boot order
+func (p *BootPlan) Names() []string {
+	names := make([]string, len(p.Modules))
+	for i, m := range p.Modules {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// String returns the boot plan as a numbered list
+func (p *BootPlan) String() string {
+	var b strings.Builder
+	for i, m := range p.Modules {
+		fmt.Fprintf(&b, "%2d. %s", i+1, m.Name)
+		if len(m.DependsOn) > 0 {
+			fmt.Fprintf(&b, " (after %s)", strings.Join(m.DependsOn, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Print writes the boot plan to w
+func (p *BootPlan) Print(w io.Writer) {
+	fmt.Fprintln(w, "Boot plan:")
+	fmt.Fprint(w, p.String())
+}
@@ -0,0 +1,95 @@
+package di
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestPlanBoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []*Module
+		want    []string
+		err     string
+	}{
+		{
+			name: "dependencies boot first",
+			modules: []*Module{
+				NewModule("server").After("plugins", "database"),
+				NewModule("plugins").After("database"),
+				NewModule("database"),
+			},
+			want: []string{"database", "plugins", "server"},
+		},
+		{
+			name: "priority breaks ties",
+			modules: []*Module{
+				NewModule("cache"),
+				NewModule("logging").WithPriority(-10),
+				NewModule("metrics"),
+				NewModule("api").After("cache").WithPriority(-20),
+			},
+			want: []string{"logging", "cache", "api", "metrics"},
+		},
+		{
+			name: "cycles are reported",
+			modules: []*Module{
+				NewModule("a").After("b"),
+				NewModule("b").After("c"),
+				NewModule("c").After("a"),
+				NewModule("d").After("a"),
+			},
+			err: "module dependency cycle: a -> b -> c -> a",
+		},
+		{
+			name:    "unknown dependencies are reported",
+			modules: []*Module{NewModule("server").After("database")},
+			err:     `module "server" depends on unknown module "database"`,
+		},
+		{
+			name:    "duplicate names are reported",
+			modules: []*Module{NewModule("server"), NewModule("server")},
+			err:     `duplicate module "server"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanBoot(tt.modules...)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, plan.Names())
+		})
+	}
+}
+
+func TestBootPlanOrdersInvocations(t *testing.T) {
+	var booted []string
+	record := func(name string) func() {
+		return func() { booted = append(booted, name) }
+	}
+
+	plan, err := PlanBoot(
+		NewModule("server").Invoke(record("server")).After("plugins"),
+		NewModule("plugins").Invoke(record("plugins")).After("database"),
+		NewModule("database").Invoke(record("database")),
+	)
+	require.NoError(t, err)
+
+	app := fxtest.New(t, fx.Options(plan.Options()...))
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+	assert.Equal(t, []string{"database", "plugins", "server"}, booted)
+
+	var out bytes.Buffer
+	plan.Print(&out)
+	assert.Equal(t, "Boot plan:\n 1. database\n 2. plugins (after database)\n 3. server (after plugins)\n", out.String())
+}
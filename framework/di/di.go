@@ -20,6 +20,12 @@ type Module struct {
 
 	// Options are additional fx options
 	Options []fx.Option
+
+	// DependsOn names the modules that must boot before this one
+	DependsOn []string
+
+	// Priority orders modules whose dependencies have booted; lower boots first
+	Priority int
 }
 
 // NewModule creates a new module
@@ -0,0 +1,50 @@
+package errorreport
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// dsn is a parsed Sentry DSN, https://<public key>[:<secret key>]@<host>[/<path>]/<project>
+type dsn struct {
+	publicKey string
+	secretKey string
+	endpoint  string // the store endpoint of the project
+}
+
+// parseDSN parses a Sentry DSN
+func parseDSN(raw string) (*dsn, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("errorreport: invalid DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("errorreport: invalid DSN scheme %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("errorreport: DSN has no public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("errorreport: DSN has no project")
+	}
+
+	secretKey, _ := u.User.Password()
+	return &dsn{
+		publicKey: u.User.Username(),
+		secretKey: secretKey,
+		endpoint:  fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:i], project),
+	}, nil
+}
+
+// authHeader returns the X-Sentry-Auth header of requests
+func (d *dsn) authHeader() string {
+	header := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, d.publicKey)
+	if d.secretKey != "" {
+		header += ", sentry_secret=" + d.secretKey
+	}
+	return header
+}
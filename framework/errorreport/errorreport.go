@@ -0,0 +1,303 @@
+// Package errorreport reports internal errors and panics to Sentry or a Sentry-compatible
+// service. Events are sent in the background and dropped rather than slowing requests
+// down when the service is slow or unreachable.
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// clientName identifies the client to the service
+const clientName = "axiomod-errorreport/1.0"
+
+// Module provides the error reporting client and, when it is enabled, reports internal
+// errors and panics with it
+var Module = fx.Options(
+	fx.Provide(NewClient),
+	fx.Invoke(Register),
+)
+
+// defaultClient is the client the package-level functions report with
+var defaultClient atomic.Pointer[Client]
+
+// SetDefault sets the client CaptureError and CapturePanic report with; nil disables them
+func SetDefault(client *Client) {
+	defaultClient.Store(client)
+}
+
+// CaptureError reports err with the default client, if there is one
+func CaptureError(ctx context.Context, err error, tags Tags) {
+	defaultClient.Load().captureError(ctx, err, tags, 1)
+}
+
+// CapturePanic reports a recovered panic with the default client, if there is one. Call
+// it from the deferred function that recovered value, so the stack of the panic is sent.
+func CapturePanic(ctx context.Context, value interface{}, tags Tags) {
+	defaultClient.Load().capturePanic(ctx, value, tags, 1)
+}
+
+// Client sends events to a Sentry project. A nil client reports nothing.
+type Client struct {
+	dsn         *dsn
+	environment string
+	release     string
+	serverName  string
+	sampleRate  float64
+	random      func() float64
+	httpClient  *http.Client
+	logger      *observability.Logger
+
+	queue   chan *Event
+	done    chan struct{}
+	stopped chan struct{}
+	start   sync.Once
+	stop    sync.Once
+
+	sent    atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewClient creates a client from the configuration, or returns nil when error reporting
+// is disabled
+func NewClient(cfg *config.Config, logger *observability.Logger) (*Client, error) {
+	rc := cfg.Observability.ErrorReporting
+	if !rc.Enabled {
+		return nil, nil
+	}
+	parsed, err := parseDSN(rc.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if rc.SampleRate < 0 || rc.SampleRate > 1 {
+		return nil, fmt.Errorf("errorreport: sample rate %v is not between 0 and 1", rc.SampleRate)
+	}
+
+	environment := rc.Environment
+	if environment == "" {
+		environment = cfg.App.Environment
+	}
+	release := rc.Release
+	if release == "" {
+		release = cfg.App.Version
+	}
+	sampleRate := rc.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+	queueSize := rc.QueueSize
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	timeout := time.Duration(rc.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	serverName, _ := os.Hostname()
+
+	return &Client{
+		dsn:         parsed,
+		environment: environment,
+		release:     release,
+		serverName:  serverName,
+		sampleRate:  sampleRate,
+		random:      rand.Float64,
+		httpClient:  &http.Client{Timeout: timeout},
+		logger:      logger,
+		queue:       make(chan *Event, queueSize),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}, nil
+}
+
+// Register starts the client with the application and makes it the default client and
+// the reporter of errors.NewInternal
+func Register(lc fx.Lifecycle, client *Client) {
+	if client == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			client.Start()
+			SetDefault(client)
+			errors.SetReporter(func(err *errors.Error) {
+				client.captureError(context.Background(), err, nil, 2)
+			})
+			if client.logger != nil {
+				client.logger.Info("Reporting errors", zap.String("endpoint", client.dsn.endpoint))
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			errors.SetReporter(nil)
+			SetDefault(nil)
+			return client.Close(ctx)
+		},
+	})
+}
+
+// CaptureError reports err. Tags are added to the tags of the environment, the release,
+// the error code and the trace ID.
+func (c *Client) CaptureError(ctx context.Context, err error, tags Tags) {
+	c.captureError(ctx, err, tags, 1)
+}
+
+// CapturePanic reports a recovered panic. Call it from the deferred function that
+// recovered value, so the stack of the panic is sent.
+func (c *Client) CapturePanic(ctx context.Context, value interface{}, tags Tags) {
+	c.capturePanic(ctx, value, tags, 1)
+}
+
+// captureError reports err, skipping skip frames above the caller for its stack
+func (c *Client) captureError(ctx context.Context, err error, tags Tags, skip int) {
+	if c == nil || err == nil || !c.sampled() {
+		return
+	}
+	c.enqueue(ctx, errorEvent(err, skip+1), tags)
+}
+
+// capturePanic reports a panic, skipping skip frames above the caller for its stack
+func (c *Client) capturePanic(ctx context.Context, value interface{}, tags Tags, skip int) {
+	if c == nil || !c.sampled() {
+		return
+	}
+	c.enqueue(ctx, panicEvent(value, skip+1), tags)
+}
+
+// sampled reports whether an event is sent under the sample rate
+func (c *Client) sampled() bool {
+	return c.sampleRate >= 1 || c.random() < c.sampleRate
+}
+
+// enqueue completes an event and queues it, dropping it if the queue is full
+func (c *Client) enqueue(ctx context.Context, event *Event, tags Tags) {
+	event.ServerName = c.serverName
+	event.Environment = c.environment
+	event.Release = c.release
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		event.Tags["trace_id"] = spanCtx.TraceID().String()
+	}
+	for key, value := range tags {
+		event.Tags[key] = value
+	}
+
+	select {
+	case <-c.done:
+		c.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case c.queue <- event:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// Start sends queued events in the background until Close
+func (c *Client) Start() {
+	if c == nil {
+		return
+	}
+	c.start.Do(func() { go c.run() })
+}
+
+// run sends queued events until Close, then sends those left
+func (c *Client) run() {
+	defer close(c.stopped)
+	for {
+		select {
+		case event := <-c.queue:
+			c.send(event)
+		case <-c.done:
+			for {
+				select {
+				case event := <-c.queue:
+					c.send(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send posts an event to the store endpoint
+func (c *Client) send(event *Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		c.failure(event, err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, c.dsn.endpoint, bytes.NewReader(body))
+	if err != nil {
+		c.failure(event, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", c.dsn.authHeader())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.failure(event, err)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		c.failure(event, fmt.Errorf("errorreport: unexpected status %d", resp.StatusCode))
+		return
+	}
+	c.sent.Add(1)
+}
+
+// failure counts and logs an event that could not be sent
+func (c *Client) failure(event *Event, err error) {
+	c.failed.Add(1)
+	if c.logger != nil {
+		c.logger.Warn("Failed to report error", zap.String("event_id", event.EventID), zap.Error(err))
+	}
+}
+
+// Close sends the queued events and stops. Events still queued when ctx is done are
+// dropped.
+func (c *Client) Close(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.Start()
+	c.stop.Do(func() { close(c.done) })
+	select {
+	case <-c.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns how many events were sent, dropped because the queue was full and failed
+// to send
+func (c *Client) Stats() (sent, dropped, failed int64) {
+	if c == nil {
+		return 0, 0, 0
+	}
+	return c.sent.Load(), c.dropped.Load(), c.failed.Load()
+}
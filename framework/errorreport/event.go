@@ -0,0 +1,215 @@
+package errorreport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// Event is an error or panic in the Sentry event format
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	Exception   *Exceptions            `json:"exception,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Exceptions holds the exception of an event
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception is an error and where it happened
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace lists frames from the outermost call to the one that failed
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a function call
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// errorsPackage is the package of the functions creating framework errors, whose frames
+// are dropped from their stacks
+var errorsPackage = reflect.TypeOf(errors.Error{}).PkgPath()
+
+// Tags are added to an event, e.g. the method and route of a request
+type Tags map[string]string
+
+// newEvent creates an event with a random ID
+func newEvent(level, message string) *Event {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &Event{
+		EventID:   hex.EncodeToString(id),
+		Timestamp: time.Now().UTC(),
+		Level:     level,
+		Platform:  "go",
+		Message:   message,
+		Tags:      make(map[string]string),
+	}
+}
+
+// errorEvent creates the event of an error. The stack trace of framework errors is the
+// one captured when they were created, others get the stack of the caller.
+func errorEvent(err error, skip int) *Event {
+	event := newEvent("error", err.Error())
+
+	exception := Exception{Type: fmt.Sprintf("%T", err), Value: err.Error()}
+	var coded *errors.Error
+	if stderrors.As(err, &coded) {
+		if coded.Code != "" {
+			exception.Type = coded.Code
+			event.Tags["code"] = coded.Code
+		}
+		if coded.Stack != "" {
+			exception.Stacktrace = &Stacktrace{Frames: parseStack(coded.Stack)}
+		}
+		if len(coded.Metadata) > 0 {
+			event.Extra = make(map[string]interface{}, len(coded.Metadata))
+			for key, value := range coded.Metadata {
+				event.Extra[key] = value
+			}
+		}
+	}
+	if exception.Stacktrace == nil {
+		exception.Stacktrace = &Stacktrace{Frames: callers(skip + 1)}
+	}
+	event.Exception = &Exceptions{Values: []Exception{exception}}
+	return event
+}
+
+// panicEvent creates the event of a recovered panic, with the stack of the panic
+func panicEvent(value interface{}, skip int) *Event {
+	message := fmt.Sprint(value)
+	event := newEvent("fatal", message)
+	event.Exception = &Exceptions{Values: []Exception{{
+		Type:       "panic",
+		Value:      message,
+		Stacktrace: &Stacktrace{Frames: callers(skip + 1)},
+	}}}
+	return event
+}
+
+// callers returns the stack of the caller, skipping skip frames above it. In deferred
+// functions recovering a panic, the frames from the panic up are dropped, so the stack
+// ends where it happened.
+func callers(skip int) []Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []Frame
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			stack = stack[:0]
+		} else if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, newFrame(frame.File, frame.Line, frame.Function))
+		}
+		if !more {
+			break
+		}
+	}
+	return reverse(stack)
+}
+
+// parseStack parses a stack captured by the errors package, "<file>:<line> <function>"
+// per frame, innermost first. The frames of the errors package itself are dropped.
+func parseStack(stack string) []Frame {
+	var frames []Frame
+	for _, line := range strings.Split(strings.TrimSpace(stack), "\n") {
+		location, function, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(location, ":")
+		if i < 0 {
+			continue
+		}
+		lineno, err := strconv.Atoi(location[i+1:])
+		if err != nil {
+			continue
+		}
+		frame := newFrame(location[:i], lineno, function)
+		if len(frames) == 0 && frame.Module == errorsPackage {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	return reverse(frames)
+}
+
+// newFrame creates a frame. Frames outside the standard library and the module cache
+// are the application's.
+func newFrame(file string, line int, function string) Frame {
+	module, name := splitFunction(function)
+	root, _, _ := strings.Cut(module, "/")
+	return Frame{
+		Function: name,
+		Module:   module,
+		Filename: filename(file),
+		AbsPath:  file,
+		Lineno:   line,
+		InApp:    !strings.Contains(file, "/pkg/mod/") && (module == "main" || strings.Contains(root, ".")),
+	}
+}
+
+// splitFunction splits a qualified function name, e.g. "github.com/a/b.(*T).Do", into
+// its package and its name
+func splitFunction(function string) (string, string) {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return "", function
+	}
+	dot += slash + 1
+	return function[:dot], function[dot+1:]
+}
+
+// filename returns the last two elements of a path, e.g. "orders/service.go"
+func filename(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i > 0 {
+		if j := strings.LastIndex(path[:i], "/"); j >= 0 {
+			return path[j+1:]
+		}
+	}
+	return path
+}
+
+// reverse reverses frames in place, as Sentry lists the outermost call first
+func reverse(frames []Frame) []Frame {
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
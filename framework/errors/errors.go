@@ -2,9 +2,7 @@ package errors
 
 import (
 	"errors"
-	"fmt"
-	"runtime"
-	"strings"
+	"sync/atomic"
 )
 
 // Common error types
@@ -63,39 +61,59 @@ func New(message string) error {
 	return &Error{
 		Original: errors.New(message),
 		Message:  message,
-		Stack:    captureStack(),
+		Stack:    stackOf(nil, ""),
 		Metadata: make(map[string]interface{}),
 	}
 }
 
-// NewInternal creates a new internal error
+// Reporter is notified of the internal errors created with NewInternal, e.g. to send
+// them to an error tracker
+type Reporter func(err *Error)
+
+// reporter is the Reporter set with SetReporter
+var reporter atomic.Pointer[Reporter]
+
+// SetReporter sets the reporter notified by NewInternal; nil removes it
+func SetReporter(r Reporter) {
+	if r == nil {
+		reporter.Store(nil)
+		return
+	}
+	reporter.Store(&r)
+}
+
+// NewInternal creates a new internal error and notifies the reporter
 func NewInternal(err error, message string) error {
-	return WithCode(Wrap(err, message), CodeInternal)
+	internal := wrapWithCode(err, message, CodeInternal)
+	if r := reporter.Load(); r != nil && internal != nil {
+		(*r)(internal.(*Error))
+	}
+	return internal
 }
 
 // NewNotFound creates a new not found error
 func NewNotFound(err error, message string) error {
-	return WithCode(Wrap(err, message), CodeNotFound)
+	return wrapWithCode(err, message, CodeNotFound)
 }
 
 // NewInvalidInput creates a new invalid input error
 func NewInvalidInput(err error, message string) error {
-	return WithCode(Wrap(err, message), CodeInvalidInput)
+	return wrapWithCode(err, message, CodeInvalidInput)
 }
 
 // NewUnauthorized creates a new unauthorized error
 func NewUnauthorized(err error, message string) error {
-	return WithCode(Wrap(err, message), CodeUnauthorized)
+	return wrapWithCode(err, message, CodeUnauthorized)
 }
 
 // NewForbidden creates a new forbidden error
 func NewForbidden(err error, message string) error {
-	return WithCode(Wrap(err, message), CodeForbidden)
+	return wrapWithCode(err, message, CodeForbidden)
 }
 
 // NewConflict creates a new conflict error
 func NewConflict(err error, message string) error {
-	return WithCode(Wrap(err, message), CodeConflict)
+	return wrapWithCode(err, message, CodeConflict)
 }
 
 // Wrap wraps an error with a message
@@ -115,24 +133,49 @@ func Wrap(err error, message string) error {
 		}
 	}
 
-	// Create a new Error
+	// Create a new Error, reusing the stack of a framework error it wraps
 	return &Error{
 		Original: err,
 		Message:  message + ": " + err.Error(),
-		Stack:    captureStack(),
+		Stack:    stackOf(err, ""),
 		Metadata: make(map[string]interface{}),
 	}
 }
 
+// wrapWithCode wraps an error with a message and a code, like WithCode(Wrap(err, message), code),
+// but knows the code before deciding whether to capture the stack
+func wrapWithCode(err error, message, code string) error {
+	if err == nil {
+		return nil
+	}
+
+	wrapped := &Error{
+		Original: err,
+		Message:  message + ": " + err.Error(),
+		Code:     code,
+		Stack:    stackOf(err, code),
+		Metadata: make(map[string]interface{}),
+	}
+	if e, ok := err.(*Error); ok {
+		wrapped.Original = e.Original
+		wrapped.Metadata = e.Metadata
+	}
+	return wrapped
+}
+
 // WithCode adds a code to an error
 func WithCode(err error, code string) error {
 	if err == nil {
 		return nil
 	}
 
-	// If the error is already an Error, just update the code
+	// If the error is already an Error, just update the code, capturing the stack it may have
+	// skipped for its previous code
 	if e, ok := err.(*Error); ok {
 		e.Code = code
+		if e.Stack == "" {
+			e.Stack = stackOf(e, code)
+		}
 		return e
 	}
 
@@ -141,7 +184,7 @@ func WithCode(err error, code string) error {
 		Original: err,
 		Message:  err.Error(),
 		Code:     code,
-		Stack:    captureStack(),
+		Stack:    stackOf(err, code),
 		Metadata: make(map[string]interface{}),
 	}
 }
@@ -162,7 +205,7 @@ func WithMetadata(err error, key string, value interface{}) error {
 	e := &Error{
 		Original: err,
 		Message:  err.Error(),
-		Stack:    captureStack(),
+		Stack:    stackOf(err, ""),
 		Metadata: make(map[string]interface{}),
 	}
 	e.Metadata[key] = value
@@ -221,9 +264,9 @@ func GetStack(err error) string {
 	return ""
 }
 
-// ToHTTPCode maps an error code to an HTTP status code
+// ToHTTPCode maps an error code to an HTTP status code; registered domain codes map by their kind
 func ToHTTPCode(err error) int {
-	code := GetCode(err)
+	code := kindOf(GetCode(err))
 	switch code {
 	case CodeNotFound:
 		return 404
@@ -246,9 +289,9 @@ func ToHTTPCode(err error) int {
 	}
 }
 
-// ToGRPCCode maps an error code to a gRPC status code
+// ToGRPCCode maps an error code to a gRPC status code; registered domain codes map by their kind
 func ToGRPCCode(err error) uint32 {
-	code := GetCode(err)
+	code := kindOf(GetCode(err))
 	switch code {
 	case CodeNotFound:
 		return 5 // NotFound
@@ -283,32 +326,3 @@ func Is(err, target error) bool {
 func As(err error, target interface{}) bool {
 	return errors.As(err, target)
 }
-
-// captureStack captures the current stack trace
-func captureStack() string {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(3, pcs[:])
-	frames := runtime.CallersFrames(pcs[:n])
-
-	var builder strings.Builder
-	for {
-		frame, more := frames.Next()
-		if !more {
-			break
-		}
-
-		// Skip runtime and standard library frames
-		if strings.Contains(frame.File, "runtime/") {
-			continue
-		}
-
-		fmt.Fprintf(&builder, "%s:%d %s\n", frame.File, frame.Line, frame.Function)
-
-		if !more {
-			break
-		}
-	}
-
-	return builder.String()
-}
@@ -2,9 +2,92 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestNewInternalReporter(t *testing.T) {
+	var reported []*Error
+	SetReporter(func(err *Error) { reported = append(reported, err) })
+	t.Cleanup(func() { SetReporter(nil) })
+
+	err := NewInternal(errors.New("disk full"), "failed to save order")
+	require.Len(t, reported, 1)
+	assert.Same(t, err, reported[0])
+	assert.Equal(t, CodeInternal, reported[0].Code)
+	assert.NotEmpty(t, reported[0].Stack)
+
+	_ = NewNotFound(errors.New("no rows"), "order not found")
+	assert.Nil(t, NewInternal(nil, "nothing failed"))
+	assert.Len(t, reported, 1, "only internal errors are reported")
+
+	SetReporter(nil)
+	_ = NewInternal(errors.New("disk full"), "failed to save order")
+	assert.Len(t, reported, 1)
+}
+
+func TestStackCapture(t *testing.T) {
+	t.Cleanup(func() { SetStackCapture(StackAll) })
+	orderMissing := CodeDefinition{Code: "STACKS_ORDER_MISSING", Kind: CodeNotFound}
+	require.NoError(t, Register("stacks", orderMissing))
+	orderFailed := CodeDefinition{Code: "ORDER_FAILED"}
+
+	tests := []struct {
+		name      string
+		mode      StackCapture
+		err       func() error
+		wantStack bool
+	}{
+		{name: "All captures client errors", mode: StackAll, err: func() error { return NewNotFound(assert.AnError, "no order") }, wantStack: true},
+		{name: "Client error", mode: StackInternal, err: func() error { return NewNotFound(assert.AnError, "no order") }},
+		{name: "Registered client code", mode: StackInternal, err: func() error { return orderMissing.New("no order") }},
+		{name: "Client code set on a plain error", mode: StackInternal, err: func() error { return WithCode(assert.AnError, CodeConflict) }},
+		{name: "Internal error", mode: StackInternal, err: func() error { return NewInternal(assert.AnError, "db down") }, wantStack: true},
+		{name: "No code", mode: StackInternal, err: func() error { return Wrap(assert.AnError, "db down") }, wantStack: true},
+		{name: "Unknown code", mode: StackInternal, err: func() error { return orderFailed.New("payment declined") }, wantStack: true},
+		{name: "Client error made internal", mode: StackInternal, err: func() error {
+			return WithCode(NewNotFound(assert.AnError, "no order"), CodeInternal)
+		}, wantStack: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStackCapture(tt.mode)
+			assert.Equal(t, tt.wantStack, GetStack(tt.err()) != "")
+		})
+	}
+}
+
+func TestWrapReusesStack(t *testing.T) {
+	inner := New("disk full")
+	stack := GetStack(inner)
+	require.NotEmpty(t, stack)
+
+	assert.Equal(t, stack, GetStack(Wrap(inner, "failed to save order")))
+	assert.Equal(t, stack, GetStack(Wrap(fmt.Errorf("saving: %w", inner), "failed to save order")))
+	assert.Equal(t, stack, GetStack(NewInternal(fmt.Errorf("saving: %w", inner), "failed to save order")))
+	assert.Equal(t, stack, GetStack(WithMetadata(fmt.Errorf("saving: %w", inner), "order_id", "42")))
+	assert.Contains(t, stack, "TestWrapReusesStack", "the stack starts at the caller")
+}
+
+func TestConfigureStackCapture(t *testing.T) {
+	t.Cleanup(func() { SetStackCapture(StackAll) })
+
+	require.NoError(t, ConfigureStackCapture(StackCaptureParams{Config: &config.Config{Errors: config.ErrorsConfig{StackTraces: "internal"}}}))
+	assert.Equal(t, StackInternal, GetStackCapture())
+
+	debug := &config.Config{App: config.AppConfig{Debug: true}, Errors: config.ErrorsConfig{StackTraces: "internal"}}
+	require.NoError(t, ConfigureStackCapture(StackCaptureParams{Config: debug}))
+	assert.Equal(t, StackAll, GetStackCapture(), "debug captures every stack")
+
+	err := ConfigureStackCapture(StackCaptureParams{Config: &config.Config{Errors: config.ErrorsConfig{StackTraces: "some"}}})
+	assert.ErrorIs(t, err, ErrInvalidStackCapture)
+}
+
 func BenchmarkNew(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = New("test error")
@@ -24,3 +107,12 @@ func BenchmarkWithCode(b *testing.B) {
 		_ = WithCode(err, CodeInternal)
 	}
 }
+
+func BenchmarkNewNotFoundInternalStacks(b *testing.B) {
+	SetStackCapture(StackInternal)
+	b.Cleanup(func() { SetStackCapture(StackAll) })
+	err := errors.New("no rows")
+	for i := 0; i < b.N; i++ {
+		_ = NewNotFound(err, "order not found")
+	}
+}
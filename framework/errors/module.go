@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"github.com/axiomod/axiomod/framework/config"
+
+	"go.uber.org/fx"
+)
+
+// CodeGroup is the fx value group collecting the error codes declared by modules
+const CodeGroup = "error_codes"
+
+// CodeSet is the error codes declared by a module
+type CodeSet struct {
+	Module      string
+	Definitions []CodeDefinition
+}
+
+// Module registers the error codes declared with Codes in the default registry at startup,
+// failing the start if two modules declare the same code, and sets the stack capture
+var Module = fx.Options(
+	fx.Invoke(RegisterCodeSets),
+	fx.Invoke(ConfigureStackCapture),
+)
+
+// Codes declares the error codes of a module, registered when the application starts
+func Codes(module string, definitions ...CodeDefinition) fx.Option {
+	set := CodeSet{Module: module, Definitions: definitions}
+	return fx.Provide(fx.Annotated{
+		Group:  CodeGroup,
+		Target: func() CodeSet { return set },
+	})
+}
+
+// CodeSetsParams holds the error codes declared by modules
+type CodeSetsParams struct {
+	fx.In
+
+	Sets []CodeSet `group:"error_codes"`
+}
+
+// RegisterCodeSets registers the declared error codes in the default registry
+func RegisterCodeSets(params CodeSetsParams) error {
+	for _, set := range params.Sets {
+		if err := Register(set.Module, set.Definitions...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StackCaptureParams holds the configuration of the stack capture
+type StackCaptureParams struct {
+	fx.In
+
+	Config *config.Config `optional:"true"`
+}
+
+// ConfigureStackCapture sets the stack capture of errors.stackTraces; app.debug captures the
+// stack of every error. Without a configuration every stack is captured.
+func ConfigureStackCapture(params StackCaptureParams) error {
+	cfg := params.Config
+	if cfg == nil {
+		SetStackCapture(StackAll)
+		return nil
+	}
+	mode, err := ParseStackCapture(cfg.Errors.StackTraces)
+	if err != nil {
+		return err
+	}
+	if cfg.App.Debug {
+		mode = StackAll
+	}
+	SetStackCapture(mode)
+	return nil
+}
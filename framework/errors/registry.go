@@ -0,0 +1,207 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FrameworkModule is the module owning the built-in error codes
+const FrameworkModule = "framework"
+
+// Registry errors
+var (
+	ErrCodeCollision = errors.New("error code already registered")
+	ErrInvalidCode   = errors.New("invalid error code")
+)
+
+// codePattern is the format of error codes, e.g. EXAMPLE_NOT_FOUND
+var codePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// CodeDefinition declares a domain error code
+type CodeDefinition struct {
+	// Code is the code, prefixed with the module namespace, e.g. EXAMPLE_NOT_FOUND
+	Code string `json:"code"`
+	// Kind is the built-in code giving the HTTP and gRPC status, e.g. CodeNotFound;
+	// empty means CodeInternal
+	Kind string `json:"kind"`
+	// Description explains when the code is returned
+	Description string `json:"description"`
+	// Module is the module that declared the code, set on registration
+	Module string `json:"module"`
+}
+
+// New creates an error with the code
+func (d CodeDefinition) New(message string) error {
+	return &Error{
+		Original: errors.New(message),
+		Message:  message,
+		Code:     d.Code,
+		Stack:    stackOf(nil, d.Code),
+		Metadata: make(map[string]interface{}),
+	}
+}
+
+// Wrap wraps an error with a message and the code
+func (d CodeDefinition) Wrap(err error, message string) error {
+	return wrapWithCode(err, message, d.Code)
+}
+
+// builtinCodes are the codes understood by ToHTTPCode and ToGRPCCode
+var builtinCodes = []CodeDefinition{
+	{Code: CodeNotFound, Description: "The requested resource does not exist"},
+	{Code: CodeInvalidInput, Description: "The request is malformed"},
+	{Code: CodeUnauthorized, Description: "The caller is not authenticated"},
+	{Code: CodeForbidden, Description: "The caller may not perform the operation"},
+	{Code: CodeInternal, Description: "An unexpected error occurred"},
+	{Code: CodeUnavailable, Description: "The service or a dependency is unavailable"},
+	{Code: CodeTimeout, Description: "The operation timed out"},
+	{Code: CodeAlreadyExists, Description: "The resource already exists"},
+	{Code: CodeConflict, Description: "The operation conflicts with the current state"},
+	{Code: CodeNotImplemented, Description: "The operation is not implemented"},
+	{Code: CodeValidation, Description: "The request failed validation"},
+	{Code: CodeDeadlineExceeded, Description: "The deadline expired before the operation completed"},
+	{Code: CodeCanceled, Description: "The operation was canceled"},
+}
+
+// BuiltinCodes returns the built-in error codes
+func BuiltinCodes() []string {
+	codes := make([]string, len(builtinCodes))
+	for i, definition := range builtinCodes {
+		codes[i] = definition.Code
+	}
+	return codes
+}
+
+// isBuiltin reports whether code is a built-in code
+func isBuiltin(code string) bool {
+	for _, definition := range builtinCodes {
+		if definition.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the error codes declared by modules, so each code has one owner
+// and a known HTTP and gRPC mapping
+type Registry struct {
+	mu    sync.RWMutex
+	codes map[string]CodeDefinition
+}
+
+// NewRegistry creates a registry holding the built-in codes
+func NewRegistry() *Registry {
+	r := &Registry{codes: make(map[string]CodeDefinition)}
+	for _, definition := range builtinCodes {
+		definition.Kind = definition.Code
+		definition.Module = FrameworkModule
+		r.codes[definition.Code] = definition
+	}
+	return r
+}
+
+// Register declares the error codes of a module. Codes must be prefixed with the module
+// namespace, e.g. EXAMPLE_ for module "example". Nothing is registered if any code is
+// invalid or already owned by another module; registering a module's codes again
+// replaces them.
+func (r *Registry) Register(module string, definitions ...CodeDefinition) error {
+	prefix := Namespace(module) + "_"
+	if prefix == "_" {
+		return fmt.Errorf("%w: module name is required", ErrInvalidCode)
+	}
+
+	definitions = append([]CodeDefinition(nil), definitions...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(definitions))
+	for i := range definitions {
+		definition := &definitions[i]
+		if !codePattern.MatchString(definition.Code) {
+			return fmt.Errorf("%w: %q must be upper snake case", ErrInvalidCode, definition.Code)
+		}
+		if !strings.HasPrefix(definition.Code, prefix) {
+			return fmt.Errorf("%w: %s of module %s must start with %s", ErrInvalidCode, definition.Code, module, prefix)
+		}
+		if definition.Kind == "" {
+			definition.Kind = CodeInternal
+		}
+		if !isBuiltin(definition.Kind) {
+			return fmt.Errorf("%w: kind %q of %s is not a built-in code", ErrInvalidCode, definition.Kind, definition.Code)
+		}
+		if seen[definition.Code] {
+			return fmt.Errorf("%w: %s is declared twice by module %s", ErrCodeCollision, definition.Code, module)
+		}
+		seen[definition.Code] = true
+		if existing, ok := r.codes[definition.Code]; ok && existing.Module != module {
+			return fmt.Errorf("%w: %s is declared by modules %s and %s", ErrCodeCollision, definition.Code, existing.Module, module)
+		}
+		definition.Module = module
+	}
+
+	for _, definition := range definitions {
+		r.codes[definition.Code] = definition
+	}
+	return nil
+}
+
+// Lookup returns the definition of a code
+func (r *Registry) Lookup(code string) (CodeDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	definition, ok := r.codes[code]
+	return definition, ok
+}
+
+// Definitions returns all registered codes sorted by code
+func (r *Registry) Definitions() []CodeDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]CodeDefinition, 0, len(r.codes))
+	for _, definition := range r.codes {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Code < definitions[j].Code
+	})
+	return definitions
+}
+
+// Namespace returns the code prefix of a module, e.g. USER_PROFILE for "user-profile"
+func Namespace(module string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, module), "_")
+}
+
+// DefaultRegistry is the registry used by Register and by the HTTP and gRPC mappings
+var DefaultRegistry = NewRegistry()
+
+// Register declares the error codes of a module in the default registry
+func Register(module string, definitions ...CodeDefinition) error {
+	return DefaultRegistry.Register(module, definitions...)
+}
+
+// kindOf returns the built-in code a code maps to; unknown codes map to themselves
+func kindOf(code string) string {
+	if code == "" || isBuiltin(code) {
+		return code
+	}
+	if definition, ok := DefaultRegistry.Lookup(code); ok {
+		return definition.Kind
+	}
+	return code
+}
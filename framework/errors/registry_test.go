@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name        string
+		module      string
+		definitions []CodeDefinition
+		wantErr     error
+	}{
+		{
+			name:        "namespaced codes are registered",
+			module:      "user-profile",
+			definitions: []CodeDefinition{{Code: "USER_PROFILE_NOT_FOUND", Kind: CodeNotFound}},
+		},
+		{
+			name:        "codes must carry the module prefix",
+			module:      "billing",
+			definitions: []CodeDefinition{{Code: "INVOICE_NOT_FOUND"}},
+			wantErr:     ErrInvalidCode,
+		},
+		{
+			name:        "codes must be upper snake case",
+			module:      "billing",
+			definitions: []CodeDefinition{{Code: "BILLING_not_found"}},
+			wantErr:     ErrInvalidCode,
+		},
+		{
+			name:        "kinds must be built-in codes",
+			module:      "billing",
+			definitions: []CodeDefinition{{Code: "BILLING_LATE", Kind: "PAYMENT_LATE"}},
+			wantErr:     ErrInvalidCode,
+		},
+		{
+			name:        "codes may not be declared twice",
+			module:      "billing",
+			definitions: []CodeDefinition{{Code: "BILLING_LATE"}, {Code: "BILLING_LATE"}},
+			wantErr:     ErrCodeCollision,
+		},
+		{
+			name:        "codes owned by another module collide",
+			module:      "user",
+			definitions: []CodeDefinition{{Code: "USER_PROFILE_NOT_FOUND"}},
+			wantErr:     ErrCodeCollision,
+		},
+		{
+			name:        "built-in codes are owned by the framework",
+			module:      "not",
+			definitions: []CodeDefinition{{Code: CodeNotFound}},
+			wantErr:     ErrCodeCollision,
+		},
+	}
+
+	registry := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Register(tt.module, tt.definitions...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				for _, definition := range tt.definitions {
+					if existing, ok := registry.Lookup(definition.Code); ok {
+						assert.NotEqual(t, tt.module, existing.Module, "failed registrations leave no codes behind")
+					}
+				}
+				return
+			}
+			require.NoError(t, err)
+			for _, definition := range tt.definitions {
+				registered, ok := registry.Lookup(definition.Code)
+				require.True(t, ok)
+				assert.Equal(t, tt.module, registered.Module)
+			}
+		})
+	}
+
+	// Registering a module's codes again is allowed, e.g. when an application is rebuilt
+	assert.NoError(t, registry.Register("user-profile", CodeDefinition{Code: "USER_PROFILE_NOT_FOUND", Kind: CodeNotFound}))
+}
+
+func TestRegistryDefinitions(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register("billing", CodeDefinition{Code: "BILLING_LATE", Description: "Payment is overdue"}))
+
+	definitions := registry.Definitions()
+	assert.Len(t, definitions, len(BuiltinCodes())+1)
+	for i := 1; i < len(definitions); i++ {
+		assert.Less(t, definitions[i-1].Code, definitions[i].Code)
+	}
+
+	late, ok := registry.Lookup("BILLING_LATE")
+	require.True(t, ok)
+	assert.Equal(t, CodeDefinition{Code: "BILLING_LATE", Kind: CodeInternal, Description: "Payment is overdue", Module: "billing"}, late)
+}
+
+func TestRegisteredCodeMapping(t *testing.T) {
+	definition := CodeDefinition{Code: "MAPPING_GONE", Kind: CodeNotFound}
+	require.NoError(t, Register("mapping", definition))
+
+	err := definition.New("order 42 is gone")
+	assert.Equal(t, "MAPPING_GONE", GetCode(err))
+	assert.Equal(t, 404, ToHTTPCode(err))
+	assert.Equal(t, uint32(5), ToGRPCCode(err))
+
+	assert.Equal(t, 500, ToHTTPCode(WithCode(New("unregistered"), "MAPPING_UNKNOWN")))
+}
+
+func TestCodesModule(t *testing.T) {
+	app := fxtest.New(t,
+		Module,
+		Codes("fx-codes", CodeDefinition{Code: "FX_CODES_EMPTY", Kind: CodeValidation}),
+	)
+	app.RequireStart().RequireStop()
+
+	definition, ok := DefaultRegistry.Lookup("FX_CODES_EMPTY")
+	require.True(t, ok)
+	assert.Equal(t, "fx-codes", definition.Module)
+
+	// A second module declaring the same code fails the start
+	collision := fx.New(
+		fx.NopLogger,
+		Module,
+		Codes("fx-codes", CodeDefinition{Code: "FX_CODES_EMPTY"}),
+		Codes("fx", CodeDefinition{Code: "FX_CODES_EMPTY"}),
+	)
+	assert.ErrorIs(t, collision.Err(), ErrCodeCollision)
+}
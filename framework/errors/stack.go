@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrInvalidStackCapture is returned for unknown stack capture modes
+var ErrInvalidStackCapture = errors.New("invalid stack capture")
+
+// StackCapture selects the errors whose stack trace is captured when they are created
+type StackCapture int32
+
+const (
+	// StackAll captures the stack of every error; the default
+	StackAll StackCapture = iota
+	// StackInternal only captures the stack of errors without a code, with an internal code
+	// or with an unknown code, sparing client errors such as not found the walk of the callers
+	StackInternal
+)
+
+// stackCapture is the StackCapture set with SetStackCapture
+var stackCapture atomic.Int32
+
+// SetStackCapture sets the errors whose stack trace is captured
+func SetStackCapture(mode StackCapture) {
+	stackCapture.Store(int32(mode))
+}
+
+// GetStackCapture returns the errors whose stack trace is captured
+func GetStackCapture() StackCapture {
+	return StackCapture(stackCapture.Load())
+}
+
+// ParseStackCapture parses a stack capture mode: "all", the default when empty, or "internal"
+func ParseStackCapture(mode string) (StackCapture, error) {
+	switch strings.ToLower(mode) {
+	case "", "all":
+		return StackAll, nil
+	case "internal":
+		return StackInternal, nil
+	default:
+		return StackAll, fmt.Errorf("%w: %q (use all or internal)", ErrInvalidStackCapture, mode)
+	}
+}
+
+// wantsStack reports whether the stack of an error with code is captured
+func wantsStack(code string) bool {
+	if GetStackCapture() == StackAll {
+		return true
+	}
+	kind := kindOf(code)
+	return kind == "" || kind == CodeInternal || !isBuiltin(kind)
+}
+
+// stackOf returns the stack of the framework error in the chain of err, so that wrapping it
+// again does not walk the callers, or else captures the stack of the caller when code wants one
+func stackOf(err error, code string) string {
+	var e *Error
+	if errors.As(err, &e) && e.Stack != "" {
+		return e.Stack
+	}
+	if !wantsStack(code) {
+		return ""
+	}
+	return captureStack()
+}
+
+// captureStack captures the stack trace from the caller of the function calling stackOf
+func captureStack() string {
+	const depth = 32
+	var pcs [depth]uintptr
+	n := runtime.Callers(4, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var builder strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !more {
+			break
+		}
+
+		// Skip runtime and standard library frames
+		if strings.Contains(frame.File, "runtime/") {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "%s:%d %s\n", frame.File, frame.Line, frame.Function)
+	}
+
+	return builder.String()
+}
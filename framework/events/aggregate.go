@@ -0,0 +1,37 @@
+package events
+
+// Aggregate is an entity recording the domain events of its changes, such as one embedding
+// AggregateRoot
+type Aggregate interface {
+	// PullEvents returns the events recorded since the last call, in the order they were
+	// recorded, and forgets them
+	PullEvents() []any
+}
+
+// AggregateRoot is embedded in entities recording the domain events of their changes, which
+// the unit of work storing them publishes once it committed:
+//
+//	func (o *Order) Cancel() {
+//		o.Status = "canceled"
+//		o.RecordEvent(OrderCanceled{OrderID: o.ID})
+//	}
+type AggregateRoot struct {
+	events []any
+}
+
+// RecordEvent records a domain event of the entity
+func (a *AggregateRoot) RecordEvent(event any) {
+	a.events = append(a.events, event)
+}
+
+// RecordedEvents returns the events recorded and not pulled yet
+func (a *AggregateRoot) RecordedEvents() []any {
+	return a.events
+}
+
+// PullEvents returns the recorded events and forgets them
+func (a *AggregateRoot) PullEvents() []any {
+	events := a.events
+	a.events = nil
+	return events
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+)
+
+// KafkaProducer publishes messages to Kafka topics; *kafka.Producer implements it
+type KafkaProducer interface {
+	Publish(ctx context.Context, topic string, key string, value []byte) error
+}
+
+// Keyed is implemented by domain events that choose the Kafka key they are forwarded with,
+// e.g. the ID of their aggregate, so that the events of an aggregate stay in order. Other
+// events are keyed by their ID.
+type Keyed interface {
+	EventKey() string
+}
+
+// BridgeMessage is the JSON message a domain event is forwarded to Kafka as
+type BridgeMessage struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+// Bridge forwards selected domain events to Kafka topics
+type Bridge struct {
+	producer KafkaProducer
+	topics   map[string]string
+	logger   *observability.Logger
+}
+
+// NewBridge creates a bridge forwarding the domain events named in topics to the Kafka topic
+// of each. Event names are case-insensitive.
+func NewBridge(producer KafkaProducer, topics map[string]string, logger *observability.Logger) *Bridge {
+	lowered := make(map[string]string, len(topics))
+	for name, topic := range topics {
+		lowered[strings.ToLower(name)] = topic
+	}
+	return &Bridge{producer: producer, topics: lowered, logger: logger}
+}
+
+// Forwards reports whether the bridge forwards the events of a name
+func (b *Bridge) Forwards(name string) bool {
+	_, ok := b.topics[strings.ToLower(name)]
+	return ok
+}
+
+// Forward publishes a domain event to its Kafka topic
+func (b *Bridge) Forward(ctx context.Context, envelope Envelope) error {
+	topic, ok := b.topics[strings.ToLower(envelope.Name)]
+	if !ok {
+		return nil
+	}
+	value, err := json.Marshal(BridgeMessage{
+		ID:        envelope.ID,
+		Name:      envelope.Name,
+		Timestamp: envelope.OccurredAt,
+		Data:      envelope.Event,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	key := envelope.ID
+	if keyed, ok := envelope.Event.(Keyed); ok {
+		key = keyed.EventKey()
+	}
+	return b.producer.Publish(ctx, topic, key, value)
+}
+
+// Attach subscribes the bridge to the events it forwards. It forwards them asynchronously,
+// retrying failed publishes, unless opts say otherwise.
+func (b *Bridge) Attach(d *Dispatcher, opts ...HandlerOption) error {
+	defaults := []HandlerOption{
+		WithHandlerName("kafka-bridge"),
+		WithDispatchMode(Async),
+		WithRetry(3, 100*time.Millisecond),
+		WithEventFilter(b.Forwards),
+	}
+	return d.SubscribeAll(b.Forward, append(defaults, opts...)...)
+}
@@ -0,0 +1,411 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrEventNil is returned when publishing a nil domain event
+var ErrEventNil = errors.New("event cannot be nil")
+
+// Mode decides when a domain event handler runs
+type Mode string
+
+// Dispatch modes
+const (
+	// Sync runs the handler in Publish, before it returns
+	Sync Mode = "sync"
+	// Async queues the event for the handler, which handles its events one at a time in
+	// publish order on a goroutine of its own
+	Async Mode = "async"
+)
+
+// ErrorPolicy decides what happens when a domain event handler fails, once its retries are used up
+type ErrorPolicy string
+
+// Error policies
+const (
+	// ErrorReturn returns the error from Publish. Async handlers fail after Publish returned,
+	// so their errors are logged instead.
+	ErrorReturn ErrorPolicy = "return"
+	// ErrorLog logs the error
+	ErrorLog ErrorPolicy = "log"
+	// ErrorIgnore discards the error
+	ErrorIgnore ErrorPolicy = "ignore"
+)
+
+// Named is implemented by domain events that choose their own name. Other events are named
+// after their Go type, e.g. "orders.OrderPlaced".
+type Named interface {
+	EventName() string
+}
+
+// NameOf returns the name of a domain event
+func NameOf(event any) string {
+	if named, ok := event.(Named); ok {
+		return named.EventName()
+	}
+	return strings.TrimPrefix(reflect.TypeOf(event).String(), "*")
+}
+
+// Envelope is a domain event on its way to a handler
+type Envelope struct {
+	ID         string
+	Name       string
+	Event      any
+	OccurredAt time.Time
+	Handler    string // name of the handler it is delivered to
+}
+
+// HandlerFunc handles a domain event
+type HandlerFunc func(ctx context.Context, envelope Envelope) error
+
+// Middleware wraps every call of a domain event handler, retries included
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// HandlerOption configures how a handler receives domain events
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	name     string
+	mode     Mode
+	policy   ErrorPolicy
+	attempts int
+	backoff  time.Duration
+	filter   func(name string) bool
+}
+
+// WithHandlerName names the handler in logs, metrics and traces. Handlers are named after
+// their function by default.
+func WithHandlerName(name string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.name = name
+	}
+}
+
+// WithDispatchMode sets whether the handler runs in Publish or asynchronously; Sync by default
+func WithDispatchMode(mode Mode) HandlerOption {
+	return func(o *handlerOptions) {
+		o.mode = mode
+	}
+}
+
+// WithErrorPolicy sets what happens when the handler fails; ErrorReturn by default
+func WithErrorPolicy(policy ErrorPolicy) HandlerOption {
+	return func(o *handlerOptions) {
+		o.policy = policy
+	}
+}
+
+// WithRetry calls a failing handler up to attempts times, waiting backoff before the second
+// call and twice as long before each of the next ones
+func WithRetry(attempts int, backoff time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.attempts = attempts
+		o.backoff = backoff
+	}
+}
+
+// WithEventFilter only delivers the events whose name filter accepts
+func WithEventFilter(filter func(name string) bool) HandlerOption {
+	return func(o *handlerOptions) {
+		o.filter = filter
+	}
+}
+
+// handler is a subscription to the domain events of a type
+type handler struct {
+	handlerOptions
+	eventType reflect.Type
+	fn        HandlerFunc
+	queue     chan delivery
+	done      chan struct{}
+}
+
+// delivery is a domain event queued for an async handler
+type delivery struct {
+	ctx      context.Context
+	envelope Envelope
+}
+
+// matches reports whether the handler receives events of type t
+func (h *handler) matches(t reflect.Type, name string) bool {
+	if t != h.eventType && (h.eventType.Kind() != reflect.Interface || !t.Implements(h.eventType)) {
+		return false
+	}
+	return h.filter == nil || h.filter(name)
+}
+
+// enqueue queues an event for an async handler, waiting for room until ctx is done
+func (h *handler) enqueue(ctx context.Context, d delivery) error {
+	select {
+	case h.queue <- d:
+		return nil
+	default:
+	}
+	select {
+	case h.queue <- d:
+		return nil
+	case <-ctx.Done():
+		return ErrPublishTimeout
+	}
+}
+
+// Dispatcher delivers in-process domain events to the handlers subscribed to their type.
+// Unlike the EventBus, events are Go values rather than serialized payloads, and handlers
+// may run in Publish so that their errors reach the publisher.
+type Dispatcher struct {
+	handlers   []*handler
+	middleware []Middleware
+	queueSize  int
+	closed     bool
+	publishing sync.WaitGroup
+	mu         sync.RWMutex
+	logger     *observability.Logger
+	metrics    *observability.Metrics
+}
+
+// NewDispatcher creates a new domain event dispatcher
+func NewDispatcher(logger *observability.Logger) *Dispatcher {
+	return &Dispatcher{
+		queueSize: DefaultMailboxSize,
+		logger:    logger,
+	}
+}
+
+// WithMetrics records published and handled events on metrics
+func (d *Dispatcher) WithMetrics(metrics *observability.Metrics) *Dispatcher {
+	d.metrics = metrics
+	return d
+}
+
+// WithQueueSize sets how many events may wait for each async handler subscribed afterwards
+// before publishers wait for room
+func (d *Dispatcher) WithQueueSize(size int) *Dispatcher {
+	if size > 0 {
+		d.queueSize = size
+	}
+	return d
+}
+
+// Use wraps every handler call in middleware, the first being the outermost
+func (d *Dispatcher) Use(middleware ...Middleware) *Dispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.middleware = append(d.middleware, middleware...)
+	return d
+}
+
+// Subscribe calls handler with the domain events of type T. T may be an interface, to
+// receive every event implementing it.
+func Subscribe[T any](d *Dispatcher, handler func(ctx context.Context, event T) error, opts ...HandlerOption) error {
+	fn := func(ctx context.Context, envelope Envelope) error {
+		return handler(ctx, envelope.Event.(T))
+	}
+	return d.subscribe(reflect.TypeFor[T](), funcName(handler), fn, opts)
+}
+
+// SubscribeAll calls handler with every domain event
+func (d *Dispatcher) SubscribeAll(handler HandlerFunc, opts ...HandlerOption) error {
+	return d.subscribe(reflect.TypeFor[any](), funcName(handler), handler, opts)
+}
+
+// subscribe adds a handler of the events of type t
+func (d *Dispatcher) subscribe(t reflect.Type, name string, fn HandlerFunc, opts []HandlerOption) error {
+	options := handlerOptions{
+		name:     name,
+		mode:     Sync,
+		policy:   ErrorReturn,
+		attempts: 1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.mode != Sync && options.mode != Async {
+		return fmt.Errorf("events: unknown dispatch mode %q", options.mode)
+	}
+	if options.attempts < 1 {
+		options.attempts = 1
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrBusClosed
+	}
+
+	h := &handler{handlerOptions: options, eventType: t, fn: fn}
+	if h.mode == Async {
+		h.queue = make(chan delivery, d.queueSize)
+		h.done = make(chan struct{})
+		go d.run(h)
+	}
+	d.handlers = append(d.handlers, h)
+	d.logger.Debug("Subscribed to domain events", zap.String("event", t.String()), zap.String("handler", h.name), zap.String("mode", string(h.mode)))
+	return nil
+}
+
+// Publish delivers a domain event to its handlers: sync ones run before Publish returns,
+// async ones have it queued. It returns the errors of sync handlers with the ErrorReturn
+// policy, and ErrPublishTimeout if ctx is done while an async handler's queue is full.
+func (d *Dispatcher) Publish(ctx context.Context, event any) error {
+	if event == nil {
+		return ErrEventNil
+	}
+	t := reflect.TypeOf(event)
+	envelope := Envelope{
+		ID:         uuid.NewString(),
+		Name:       NameOf(event),
+		Event:      event,
+		OccurredAt: time.Now(),
+	}
+
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return ErrBusClosed
+	}
+	var handlers []*handler
+	for _, h := range d.handlers {
+		if h.matches(t, envelope.Name) {
+			handlers = append(handlers, h)
+		}
+	}
+	d.publishing.Add(1)
+	d.mu.RUnlock()
+	defer d.publishing.Done()
+
+	if d.metrics != nil && d.metrics.DomainEventsPublishedTotal != nil {
+		d.metrics.DomainEventsPublishedTotal.WithLabelValues(envelope.Name).Inc()
+	}
+
+	// Async handlers keep the values of the publisher's context but not its deadline
+	deliveryCtx := context.WithoutCancel(ctx)
+	var errs []error
+	for _, h := range handlers {
+		if h.mode == Async {
+			if err := h.enqueue(ctx, delivery{ctx: deliveryCtx, envelope: envelope}); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := d.handle(ctx, h, envelope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run handles the events queued for an async handler until its queue is closed
+func (d *Dispatcher) run(h *handler) {
+	defer close(h.done)
+	for item := range h.queue {
+		d.handle(item.ctx, h, item.envelope)
+	}
+}
+
+// handle calls a handler through the middleware, retrying it as configured, and applies its
+// error policy. It returns the error to pass on to the publisher, if any.
+func (d *Dispatcher) handle(ctx context.Context, h *handler, envelope Envelope) error {
+	envelope.Handler = h.name
+	d.mu.RLock()
+	next := h.fn
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		next = d.middleware[i](next)
+	}
+	d.mu.RUnlock()
+
+	err := call(ctx, next, envelope)
+	backoff := h.backoff
+retry:
+	for attempt := 1; err != nil && attempt < h.attempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			break retry
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = call(ctx, next, envelope)
+	}
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	if d.metrics != nil && d.metrics.DomainEventsHandledTotal != nil {
+		d.metrics.DomainEventsHandledTotal.WithLabelValues(envelope.Name, h.name, result).Inc()
+	}
+	if err == nil || h.policy == ErrorIgnore {
+		return nil
+	}
+	if h.policy == ErrorReturn && h.mode == Sync {
+		return fmt.Errorf("%s failed to handle %s: %w", h.name, envelope.Name, err)
+	}
+	d.logger.Error("Failed to handle domain event",
+		zap.String("event", envelope.Name),
+		zap.String("id", envelope.ID),
+		zap.String("handler", h.name),
+		zap.Error(err),
+	)
+	return nil
+}
+
+// call calls a handler, turning a panic into an error
+func call(ctx context.Context, fn HandlerFunc, envelope Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return fn(ctx, envelope)
+}
+
+// funcName names a handler after its function, without the package path
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// Close stops accepting events and waits until the async handlers handled the events queued
+// for them, or until ctx is done
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	handlers := d.handlers
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.publishing.Wait()
+		for _, h := range handlers {
+			if h.mode == Async {
+				close(h.queue)
+				<-h.done
+			}
+		}
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
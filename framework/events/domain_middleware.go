@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// LoggingMiddleware logs every call of a domain event handler at debug level, with how long
+// it took and its error
+func LoggingMiddleware(logger *observability.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, envelope Envelope) error {
+			start := time.Now()
+			err := next(ctx, envelope)
+			logger.Debug("Handled domain event",
+				zap.String("event", envelope.Name),
+				zap.String("id", envelope.ID),
+				zap.String("handler", envelope.Handler),
+				zap.Duration("duration", time.Since(start)),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+}
+
+// TracingMiddleware records a span for every call of a domain event handler. Async handlers
+// keep the publisher's context, so their spans belong to the publisher's trace.
+func TracingMiddleware(tracer trace.Tracer) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, envelope Envelope) error {
+			ctx, span := tracer.Start(ctx, envelope.Name+" "+envelope.Handler,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("event.name", envelope.Name),
+					attribute.String("event.id", envelope.ID),
+					attribute.String("event.handler", envelope.Handler),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, envelope)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
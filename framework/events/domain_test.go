@@ -0,0 +1,268 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type orderPlaced struct {
+	OrderID string `json:"orderId"`
+}
+
+type orderShipped struct {
+	OrderID string
+}
+
+func (e orderShipped) EventName() string { return "orders.shipped" }
+func (e orderShipped) EventKey() string  { return e.OrderID }
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, *observability.Metrics) {
+	t.Helper()
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	d := NewDispatcher(logger).WithMetrics(metrics)
+	t.Cleanup(func() { _ = d.Close(context.Background()) })
+	return d, metrics
+}
+
+func TestNameOf(t *testing.T) {
+	assert.Equal(t, "events.orderPlaced", NameOf(orderPlaced{}))
+	assert.Equal(t, "events.orderPlaced", NameOf(&orderPlaced{}))
+	assert.Equal(t, "orders.shipped", NameOf(orderShipped{}))
+}
+
+func TestAggregateRoot(t *testing.T) {
+	var order struct {
+		AggregateRoot
+		ID string
+	}
+	order.RecordEvent(orderPlaced{OrderID: "1"})
+	order.RecordEvent(orderShipped{OrderID: "1"})
+	assert.Len(t, order.RecordedEvents(), 2)
+
+	var aggregate Aggregate = &order
+	assert.Equal(t, []any{orderPlaced{OrderID: "1"}, orderShipped{OrderID: "1"}}, aggregate.PullEvents())
+	assert.Empty(t, order.RecordedEvents(), "pulled events are forgotten")
+	assert.Empty(t, aggregate.PullEvents())
+}
+
+func TestDispatcherSync(t *testing.T) {
+	ctx := context.Background()
+	d, metrics := newTestDispatcher(t)
+
+	var placed []string
+	var named []string
+	require.NoError(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error {
+		placed = append(placed, e.OrderID)
+		return nil
+	}, WithHandlerName("placed")))
+	require.NoError(t, Subscribe(d, func(ctx context.Context, e Named) error {
+		named = append(named, e.EventName())
+		return nil
+	}))
+
+	require.NoError(t, d.Publish(ctx, orderPlaced{OrderID: "1"}))
+	require.NoError(t, d.Publish(ctx, orderShipped{OrderID: "1"}))
+	require.NoError(t, d.Publish(ctx, &orderPlaced{OrderID: "2"}), "events without handlers are dropped")
+	assert.Equal(t, []string{"1"}, placed, "handlers receive events of their type")
+	assert.Equal(t, []string{"orders.shipped"}, named, "handlers of an interface receive the events implementing it")
+
+	assert.ErrorIs(t, d.Publish(ctx, nil), ErrEventNil)
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.DomainEventsPublishedTotal.WithLabelValues("events.orderPlaced")), "pointers are named after their element type")
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DomainEventsHandledTotal.WithLabelValues("events.orderPlaced", "placed", "success")))
+}
+
+func TestDispatcherErrorPolicies(t *testing.T) {
+	failure := errors.New("failed")
+	tests := []struct {
+		name    string
+		opts    []HandlerOption
+		handler func(calls int) error
+		wantErr bool
+		calls   int
+	}{
+		{"return", nil, func(int) error { return failure }, true, 1},
+		{"log", []HandlerOption{WithErrorPolicy(ErrorLog)}, func(int) error { return failure }, false, 1},
+		{"ignore", []HandlerOption{WithErrorPolicy(ErrorIgnore)}, func(int) error { return failure }, false, 1},
+		{"panic", nil, func(int) error { panic("boom") }, true, 1},
+		{"retried", []HandlerOption{WithRetry(3, time.Millisecond)}, func(calls int) error {
+			if calls < 3 {
+				return failure
+			}
+			return nil
+		}, false, 3},
+		{"retries used up", []HandlerOption{WithRetry(2, time.Millisecond)}, func(int) error { return failure }, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _ := newTestDispatcher(t)
+			var calls, after int
+			require.NoError(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error {
+				calls++
+				return tt.handler(calls)
+			}, tt.opts...))
+			require.NoError(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error {
+				after++
+				return nil
+			}))
+
+			err := d.Publish(context.Background(), orderPlaced{OrderID: "1"})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.calls, calls)
+			assert.Equal(t, 1, after, "a failing handler does not stop the others")
+		})
+	}
+}
+
+func TestDispatcherAsync(t *testing.T) {
+	ctx := context.Background()
+	d, metrics := newTestDispatcher(t)
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var received []string
+	require.NoError(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error {
+		<-release
+		mu.Lock()
+		received = append(received, e.OrderID)
+		mu.Unlock()
+		return errors.New("failed")
+	}, WithDispatchMode(Async), WithHandlerName("async")))
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, d.Publish(ctx, orderPlaced{OrderID: id}), "errors of async handlers are not returned")
+	}
+	mu.Lock()
+	assert.Empty(t, received, "Publish does not wait for async handlers")
+	mu.Unlock()
+
+	close(release)
+	require.NoError(t, d.Close(ctx))
+	assert.Equal(t, []string{"1", "2", "3"}, received, "Close waits for queued events, handled in publish order")
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.DomainEventsHandledTotal.WithLabelValues("events.orderPlaced", "async", "failure")))
+
+	assert.ErrorIs(t, d.Publish(ctx, orderPlaced{}), ErrBusClosed)
+	assert.ErrorIs(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error { return nil }), ErrBusClosed)
+}
+
+func TestDispatcherAsyncQueueFull(t *testing.T) {
+	d, _ := newTestDispatcher(t)
+	d.WithQueueSize(1)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	require.NoError(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, WithDispatchMode(Async)))
+	defer close(release)
+
+	require.NoError(t, d.Publish(context.Background(), orderPlaced{OrderID: "1"}))
+	<-started
+	require.NoError(t, d.Publish(context.Background(), orderPlaced{OrderID: "2"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Publish(ctx, orderPlaced{OrderID: "3"}), ErrPublishTimeout)
+}
+
+func TestDispatcherMiddleware(t *testing.T) {
+	d, _ := newTestDispatcher(t)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := trace.NewTracerProvider(trace.WithSyncer(exporter))
+
+	var order []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, envelope Envelope) error {
+				order = append(order, name+":"+envelope.Handler)
+				return next(ctx, envelope)
+			}
+		}
+	}
+	d.Use(record("outer"), TracingMiddleware(provider.Tracer("test")), LoggingMiddleware(d.logger), record("inner"))
+
+	var calls atomic.Int32
+	require.NoError(t, Subscribe(d, func(ctx context.Context, e orderPlaced) error {
+		if calls.Add(1) == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithHandlerName("ship"), WithRetry(2, time.Millisecond)))
+
+	require.NoError(t, d.Publish(context.Background(), orderPlaced{OrderID: "1"}))
+	assert.Equal(t, []string{"outer:ship", "inner:ship", "outer:ship", "inner:ship"}, order, "middleware wraps every call, retries included")
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "events.orderPlaced ship", spans[0].Name)
+	assert.Len(t, spans[0].Events, 1, "the error of the first call is recorded")
+}
+
+type fakeProducer struct {
+	mu       sync.Mutex
+	messages map[string][]string
+	keys     []string
+}
+
+func (p *fakeProducer) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.messages == nil {
+		p.messages = make(map[string][]string)
+	}
+	p.messages[topic] = append(p.messages[topic], string(value))
+	p.keys = append(p.keys, key)
+	return nil
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+	d, metrics := newTestDispatcher(t)
+	producer := &fakeProducer{}
+	// Config keys are lowercased when loaded, so event names match case-insensitively
+	bridge := NewBridge(producer, map[string]string{"events.orderplaced": "orders.placed"}, d.logger)
+	require.NoError(t, bridge.Attach(d))
+
+	require.NoError(t, d.Publish(ctx, orderPlaced{OrderID: "1"}))
+	require.NoError(t, d.Publish(ctx, orderShipped{OrderID: "1"}))
+	require.NoError(t, d.Close(ctx))
+
+	require.Len(t, producer.messages["orders.placed"], 1, "only selected events are forwarded")
+	var message struct {
+		ID   string      `json:"id"`
+		Name string      `json:"name"`
+		Data orderPlaced `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(producer.messages["orders.placed"][0]), &message))
+	assert.Equal(t, "events.orderPlaced", message.Name)
+	assert.Equal(t, "1", message.Data.OrderID)
+	assert.Equal(t, []string{message.ID}, producer.keys, "events are keyed by their ID unless they are Keyed")
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DomainEventsHandledTotal.WithLabelValues("orders.shipped", "kafka-bridge", "success")))
+
+	keyed := &fakeProducer{}
+	require.NoError(t, NewBridge(keyed, map[string]string{"orders.shipped": "orders.shipped"}, d.logger).
+		Forward(ctx, Envelope{ID: "id", Name: "orders.shipped", Event: orderShipped{OrderID: "42"}}))
+	assert.Equal(t, []string{"42"}, keyed.keys)
+}
@@ -18,6 +18,7 @@ var (
 	ErrTopicEmpty     = errors.New("topic cannot be empty")
 	ErrPayloadEmpty   = errors.New("payload cannot be empty")
 	ErrPublishTimeout = errors.New("publish timeout")
+	ErrBusClosed      = errors.New("event bus is closed")
 )
 
 // Event represents a message to be published or consumed
@@ -27,6 +28,7 @@ type Event struct {
 	Payload   json.RawMessage   `json:"payload"`
 	Timestamp time.Time         `json:"timestamp"`
 	Headers   map[string]string `json:"headers,omitempty"`
+	Priority  int               `json:"priority,omitempty"`
 }
 
 // Publisher defines the interface for publishing events
@@ -47,23 +49,94 @@ type Consumer interface {
 	Close() error
 }
 
-// EventBus provides a simple in-memory event bus for testing
+// Default mailbox settings of event bus subscribers
+const (
+	DefaultMailboxSize    = 256
+	DefaultOverflowPolicy = OverflowSpill
+)
+
+// EventBus is an in-memory event bus. Every subscriber has its own mailbox and receives
+// events one at a time, highest priority first, so a slow subscriber neither delays the
+// others nor stalls publishers unless it asks for OverflowBlock.
 type EventBus struct {
-	subscribers map[string][]func(ctx context.Context, event Event) error
+	subscribers map[string][]*subscriber
+	delayed     map[*time.Timer]struct{}
+	seq         uint64
+	closed      bool
 	mu          sync.RWMutex
 	logger      *observability.Logger
+	metrics     *observability.Metrics
 }
 
 // NewEventBus creates a new in-memory event bus
 func NewEventBus(logger *observability.Logger) *EventBus {
 	return &EventBus{
-		subscribers: make(map[string][]func(ctx context.Context, event Event) error),
+		subscribers: make(map[string][]*subscriber),
+		delayed:     make(map[*time.Timer]struct{}),
 		logger:      logger,
 	}
 }
 
+// WithMetrics records published, delivered, queued and overflowing events on metrics
+func (b *EventBus) WithMetrics(metrics *observability.Metrics) *EventBus {
+	b.metrics = metrics
+	return b
+}
+
+// PublishOption configures how an event is published
+type PublishOption func(*publishOptions)
+
+type publishOptions struct {
+	priority int
+	delay    time.Duration
+}
+
+// WithPriority sets the priority of an event; subscribers receive higher priorities first
+func WithPriority(priority int) PublishOption {
+	return func(o *publishOptions) {
+		o.priority = priority
+	}
+}
+
+// WithDelay holds an event back for the given duration before it is delivered
+func WithDelay(delay time.Duration) PublishOption {
+	return func(o *publishOptions) {
+		o.delay = delay
+	}
+}
+
+// SubscribeOption configures the mailbox of a subscriber
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	mailboxSize int
+	overflow    OverflowPolicy
+}
+
+// WithMailboxSize sets how many events may wait for the subscriber before its overflow policy applies
+func WithMailboxSize(size int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if size > 0 {
+			o.mailboxSize = size
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens to events published while the subscriber's mailbox is full
+func WithOverflowPolicy(policy OverflowPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.overflow = policy
+	}
+}
+
 // Publish publishes an event to the specified topic
 func (b *EventBus) Publish(ctx context.Context, topic string, payload []byte, headers map[string]string) error {
+	return b.PublishWithOptions(ctx, topic, payload, headers)
+}
+
+// PublishWithOptions publishes an event to the specified topic with a priority or delay.
+// It returns once the event is queued for every subscriber, or, with a delay, scheduled.
+func (b *EventBus) PublishWithOptions(ctx context.Context, topic string, payload []byte, headers map[string]string, opts ...PublishOption) error {
 	if topic == "" {
 		return ErrTopicEmpty
 	}
@@ -71,51 +144,207 @@ func (b *EventBus) Publish(ctx context.Context, topic string, payload []byte, he
 		return ErrPayloadEmpty
 	}
 
+	var options publishOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	event := Event{
 		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
 		Topic:     topic,
 		Payload:   payload,
 		Timestamp: time.Now(),
 		Headers:   headers,
+		Priority:  options.priority,
+	}
+	// Handlers run after the publisher has moved on, so they keep its values but not its deadline
+	deliveryCtx := context.WithoutCancel(ctx)
+
+	if options.delay > 0 {
+		return b.schedule(deliveryCtx, event, options.delay)
 	}
+	return b.dispatch(ctx, deliveryCtx, event)
+}
 
-	b.mu.RLock()
-	subscribers := b.subscribers[topic]
-	b.mu.RUnlock()
+// dispatch queues an event for every subscriber of its topic
+func (b *EventBus) dispatch(ctx, deliveryCtx context.Context, event Event) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBusClosed
+	}
+	subscribers := b.subscribers[event.Topic]
+	b.seq++
+	seq := b.seq
+	b.mu.Unlock()
 
-	for _, handler := range subscribers {
-		go func(h func(ctx context.Context, event Event) error) {
-			if err := h(ctx, event); err != nil {
-				b.logger.Error("Failed to handle event",
-					zap.String("topic", topic),
-					zap.String("id", event.ID),
-					zap.Error(err),
-				)
-			}
-		}(handler)
+	if b.metrics != nil && b.metrics.EventBusPublishedTotal != nil {
+		b.metrics.EventBusPublishedTotal.WithLabelValues(event.Topic).Inc()
 	}
 
+	var errs []error
+	for _, s := range subscribers {
+		if err := s.enqueue(ctx, &mailboxItem{ctx: deliveryCtx, event: event, seq: seq}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// schedule dispatches an event once its delay has passed
+func (b *EventBus) schedule(ctx context.Context, event Event, delay time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		b.mu.Lock()
+		delete(b.delayed, timer)
+		b.mu.Unlock()
+		b.recordDelayed(-1)
+
+		// A full mailbox with OverflowBlock waits as long as it takes
+		if err := b.dispatch(ctx, ctx, event); err != nil && !errors.Is(err, ErrBusClosed) {
+			b.logger.Warn("Failed to deliver delayed event", zap.String("topic", event.Topic), zap.Error(err))
+		}
+	})
+	b.delayed[timer] = struct{}{}
+	b.recordDelayed(1)
 	return nil
 }
 
+// deliver hands an event to a subscriber's handler
+func (b *EventBus) deliver(ctx context.Context, s *subscriber, event Event) {
+	result := "success"
+	if err := s.handler(ctx, event); err != nil {
+		result = "failure"
+		b.logger.Error("Failed to handle event",
+			zap.String("topic", event.Topic),
+			zap.String("id", event.ID),
+			zap.Error(err),
+		)
+	}
+	if b.metrics != nil && b.metrics.EventBusDeliveredTotal != nil {
+		b.metrics.EventBusDeliveredTotal.WithLabelValues(event.Topic, result).Inc()
+	}
+}
+
 // Subscribe subscribes to the specified topics and calls the handler for each event
 func (b *EventBus) Subscribe(ctx context.Context, topics []string, handler func(ctx context.Context, event Event) error) error {
+	return b.SubscribeWithOptions(ctx, topics, handler)
+}
+
+// SubscribeWithOptions subscribes to the specified topics with a mailbox configured by opts.
+// The handler receives the events of all the topics one at a time. The subscription ends when
+// ctx is done or the bus is closed.
+func (b *EventBus) SubscribeWithOptions(ctx context.Context, topics []string, handler func(ctx context.Context, event Event) error, opts ...SubscribeOption) error {
+	options := subscribeOptions{
+		mailboxSize: DefaultMailboxSize,
+		overflow:    DefaultOverflowPolicy,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
 
+	s := newSubscriber(b, topics, handler, options)
 	for _, topic := range topics {
-		b.subscribers[topic] = append(b.subscribers[topic], handler)
-		b.logger.Info("Subscribed to topic", zap.String("topic", topic))
+		b.subscribers[topic] = append(b.subscribers[topic], s)
+		b.logger.Info("Subscribed to topic", zap.String("topic", topic), zap.String("overflow", string(options.overflow)))
 	}
+	go s.run()
 
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				b.unsubscribe(s)
+			case <-s.stop:
+			}
+		}()
+	}
 	return nil
 }
 
-// Close closes the event bus
+// unsubscribe removes a subscriber from its topics and stops it
+func (b *EventBus) unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	for _, topic := range s.topics {
+		subscribers := b.subscribers[topic]
+		for i, candidate := range subscribers {
+			if candidate == s {
+				b.subscribers[topic] = append(subscribers[:i:i], subscribers[i+1:]...)
+				break
+			}
+		}
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+	}
+	b.mu.Unlock()
+	s.close()
+}
+
+// recordOverflow counts an event that found a full mailbox
+func (b *EventBus) recordOverflow(topic, action string) {
+	if b.metrics != nil && b.metrics.EventBusOverflowTotal != nil {
+		b.metrics.EventBusOverflowTotal.WithLabelValues(topic, action).Inc()
+	}
+}
+
+// recordQueued tracks the events waiting in mailboxes
+func (b *EventBus) recordQueued(topic string, delta float64) {
+	if b.metrics != nil && b.metrics.EventBusQueuedEvents != nil {
+		b.metrics.EventBusQueuedEvents.WithLabelValues(topic).Add(delta)
+	}
+}
+
+// recordDelayed tracks the events waiting for their delay to pass
+func (b *EventBus) recordDelayed(delta float64) {
+	if b.metrics != nil && b.metrics.EventBusDelayedEvents != nil {
+		b.metrics.EventBusDelayedEvents.Add(delta)
+	}
+}
+
+// Close closes the event bus. Delayed events are canceled, and subscribers stop after the
+// event they are handling; the events still queued for them are discarded.
 func (b *EventBus) Close() error {
 	b.mu.Lock()
-	defer b.mu.Unlock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for timer := range b.delayed {
+		if timer.Stop() {
+			b.recordDelayed(-1)
+		}
+	}
+	b.delayed = make(map[*time.Timer]struct{})
 
-	b.subscribers = make(map[string][]func(ctx context.Context, event Event) error)
+	seen := make(map[*subscriber]bool)
+	var subscribers []*subscriber
+	for _, topicSubscribers := range b.subscribers {
+		for _, s := range topicSubscribers {
+			if !seen[s] {
+				seen[s] = true
+				subscribers = append(subscribers, s)
+			}
+		}
+	}
+	b.subscribers = make(map[string][]*subscriber)
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		s.close()
+	}
 	return nil
 }
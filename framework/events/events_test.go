@@ -0,0 +1,238 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBus(t *testing.T) (*EventBus, *observability.Metrics) {
+	t.Helper()
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	bus := NewEventBus(logger).WithMetrics(metrics)
+	t.Cleanup(func() { _ = bus.Close() })
+	return bus, metrics
+}
+
+// gatedRecorder records payloads; its handler holds the first event until release is closed
+type gatedRecorder struct {
+	mu       sync.Mutex
+	payloads []string
+	started  chan struct{}
+	release  chan struct{}
+	once     sync.Once
+}
+
+func newGatedRecorder() *gatedRecorder {
+	return &gatedRecorder{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (r *gatedRecorder) handle(ctx context.Context, event Event) error {
+	r.once.Do(func() {
+		close(r.started)
+		<-r.release
+	})
+	r.mu.Lock()
+	r.payloads = append(r.payloads, string(event.Payload))
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *gatedRecorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.payloads...)
+}
+
+// publishAndHold publishes a first event and waits until the subscriber is busy with it
+func publishAndHold(t *testing.T, bus *EventBus, r *gatedRecorder) {
+	t.Helper()
+	require.NoError(t, bus.Publish(context.Background(), "orders", []byte("first"), nil))
+	select {
+	case <-r.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("first event not delivered")
+	}
+}
+
+func TestEventBusPriority(t *testing.T) {
+	bus, _ := newTestBus(t)
+	r := newGatedRecorder()
+	require.NoError(t, bus.Subscribe(context.Background(), []string{"orders"}, r.handle))
+	publishAndHold(t, bus, r)
+
+	ctx := context.Background()
+	require.NoError(t, bus.PublishWithOptions(ctx, "orders", []byte("low"), nil, WithPriority(-1)))
+	require.NoError(t, bus.PublishWithOptions(ctx, "orders", []byte("normal-1"), nil))
+	require.NoError(t, bus.PublishWithOptions(ctx, "orders", []byte("high"), nil, WithPriority(10)))
+	require.NoError(t, bus.PublishWithOptions(ctx, "orders", []byte("normal-2"), nil))
+	close(r.release)
+
+	want := []string{"first", "high", "normal-1", "normal-2", "low"}
+	assert.Eventually(t, func() bool { return len(r.received()) == len(want) }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, want, r.received())
+}
+
+func TestEventBusDelay(t *testing.T) {
+	bus, metrics := newTestBus(t)
+	received := make(chan time.Time, 1)
+	require.NoError(t, bus.Subscribe(context.Background(), []string{"reminders"}, func(ctx context.Context, event Event) error {
+		received <- time.Now()
+		return nil
+	}))
+
+	start := time.Now()
+	require.NoError(t, bus.PublishWithOptions(context.Background(), "reminders", []byte("later"), nil, WithDelay(50*time.Millisecond)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.EventBusDelayedEvents))
+
+	select {
+	case at := <-received:
+		assert.GreaterOrEqual(t, at.Sub(start), 50*time.Millisecond)
+	case <-time.After(2 * time.Second):
+		t.Fatal("delayed event not delivered")
+	}
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.EventBusDelayedEvents))
+
+	// Closing the bus cancels events still waiting for their delay
+	require.NoError(t, bus.PublishWithOptions(context.Background(), "reminders", []byte("never"), nil, WithDelay(time.Hour)))
+	require.NoError(t, bus.Close())
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.EventBusDelayedEvents))
+}
+
+func TestEventBusOverflowPolicies(t *testing.T) {
+	t.Run("drop oldest", func(t *testing.T) {
+		bus, metrics := newTestBus(t)
+		r := newGatedRecorder()
+		require.NoError(t, bus.SubscribeWithOptions(context.Background(), []string{"orders"}, r.handle,
+			WithMailboxSize(2), WithOverflowPolicy(OverflowDropOldest)))
+		publishAndHold(t, bus, r)
+
+		ctx := context.Background()
+		require.NoError(t, bus.Publish(ctx, "orders", []byte("a"), nil))
+		require.NoError(t, bus.Publish(ctx, "orders", []byte("b"), nil))
+		// The mailbox is full: the oldest of the lowest priority goes first
+		require.NoError(t, bus.PublishWithOptions(ctx, "orders", []byte("urgent"), nil, WithPriority(1)))
+		require.NoError(t, bus.Publish(ctx, "orders", []byte("c"), nil))
+		require.NoError(t, bus.PublishWithOptions(ctx, "orders", []byte("ignored"), nil, WithPriority(-1)))
+		assert.Equal(t, 3.0, testutil.ToFloat64(metrics.EventBusOverflowTotal.WithLabelValues("orders", "dropped")))
+		close(r.release)
+
+		want := []string{"first", "urgent", "c"}
+		assert.Eventually(t, func() bool { return len(r.received()) == len(want) }, 2*time.Second, 5*time.Millisecond)
+		assert.Equal(t, want, r.received())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		bus, metrics := newTestBus(t)
+		r := newGatedRecorder()
+		require.NoError(t, bus.SubscribeWithOptions(context.Background(), []string{"orders"}, r.handle,
+			WithMailboxSize(1), WithOverflowPolicy(OverflowBlock)))
+		publishAndHold(t, bus, r)
+		require.NoError(t, bus.Publish(context.Background(), "orders", []byte("queued"), nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := bus.Publish(ctx, "orders", []byte("waits"), nil)
+		assert.ErrorIs(t, err, ErrPublishTimeout)
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.EventBusOverflowTotal.WithLabelValues("orders", "blocked")))
+
+		// Once there is room the publisher gets through
+		published := make(chan error, 1)
+		go func() { published <- bus.Publish(context.Background(), "orders", []byte("next"), nil) }()
+		close(r.release)
+		require.NoError(t, <-published)
+		want := []string{"first", "queued", "next"}
+		assert.Eventually(t, func() bool { return len(r.received()) == len(want) }, 2*time.Second, 5*time.Millisecond)
+		assert.Equal(t, want, r.received())
+	})
+
+	t.Run("spill", func(t *testing.T) {
+		bus, metrics := newTestBus(t)
+		r := newGatedRecorder()
+		require.NoError(t, bus.SubscribeWithOptions(context.Background(), []string{"orders"}, r.handle,
+			WithMailboxSize(1), WithOverflowPolicy(OverflowSpill)))
+		publishAndHold(t, bus, r)
+
+		for _, payload := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, bus.Publish(context.Background(), "orders", []byte(payload), nil))
+		}
+		assert.Equal(t, 3.0, testutil.ToFloat64(metrics.EventBusOverflowTotal.WithLabelValues("orders", "spilled")))
+		assert.Equal(t, 4.0, testutil.ToFloat64(metrics.EventBusQueuedEvents.WithLabelValues("orders")))
+		close(r.release)
+
+		want := []string{"first", "a", "b", "c", "d"}
+		assert.Eventually(t, func() bool { return len(r.received()) == len(want) }, 2*time.Second, 5*time.Millisecond)
+		assert.Equal(t, want, r.received())
+		assert.Equal(t, 0.0, testutil.ToFloat64(metrics.EventBusQueuedEvents.WithLabelValues("orders")))
+		assert.Equal(t, 5.0, testutil.ToFloat64(metrics.EventBusDeliveredTotal.WithLabelValues("orders", "success")))
+	})
+}
+
+func TestEventBusSlowSubscriber(t *testing.T) {
+	bus, _ := newTestBus(t)
+	slow := newGatedRecorder()
+	require.NoError(t, bus.Subscribe(context.Background(), []string{"orders"}, slow.handle))
+	fast := make(chan string, 10)
+	require.NoError(t, bus.Subscribe(context.Background(), []string{"orders"}, func(ctx context.Context, event Event) error {
+		fast <- string(event.Payload)
+		return nil
+	}))
+	publishAndHold(t, bus, slow)
+	defer close(slow.release)
+
+	require.NoError(t, bus.Publish(context.Background(), "orders", []byte("second"), nil))
+	for _, want := range []string{"first", "second"} {
+		select {
+		case got := <-fast:
+			assert.Equal(t, want, got)
+		case <-time.After(2 * time.Second):
+			t.Fatal("a slow subscriber held up another one")
+		}
+	}
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus, _ := newTestBus(t)
+	received := make(chan string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, bus.Subscribe(ctx, []string{"orders"}, func(ctx context.Context, event Event) error {
+		received <- string(event.Payload)
+		return nil
+	}))
+
+	// Handlers outlive the publisher's context
+	publishCtx, publishCancel := context.WithCancel(context.Background())
+	require.NoError(t, bus.Publish(publishCtx, "orders", []byte("kept"), nil))
+	publishCancel()
+	assert.Equal(t, "kept", <-received)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		bus.mu.RLock()
+		defer bus.mu.RUnlock()
+		return len(bus.subscribers["orders"]) == 0
+	}, 2*time.Second, 5*time.Millisecond)
+	require.NoError(t, bus.Publish(context.Background(), "orders", []byte("dropped"), nil))
+
+	require.NoError(t, bus.Close())
+	assert.ErrorIs(t, bus.Publish(context.Background(), "orders", []byte("late"), nil), ErrBusClosed)
+	assert.ErrorIs(t, bus.Subscribe(context.Background(), []string{"orders"}, nil), ErrBusClosed)
+	assert.Empty(t, received)
+}
+
+func TestPublishValidation(t *testing.T) {
+	bus, _ := newTestBus(t)
+	assert.ErrorIs(t, bus.Publish(context.Background(), "", []byte("x"), nil), ErrTopicEmpty)
+	assert.ErrorIs(t, bus.Publish(context.Background(), "orders", nil, nil), ErrPayloadEmpty)
+}
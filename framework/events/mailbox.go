@@ -0,0 +1,219 @@
+package events
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// OverflowPolicy decides what happens to an event when a subscriber's mailbox is full
+type OverflowPolicy string
+
+// Overflow policies
+const (
+	// OverflowSpill moves the event to an unbounded overflow queue that refills the mailbox as
+	// it drains. Nothing is lost and publishers never wait, at the cost of memory.
+	OverflowSpill OverflowPolicy = "spill"
+	// OverflowDropOldest discards the oldest event of the lowest priority, which may be the new one
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowBlock makes the publisher wait for room until its context is done
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// mailboxItem is a queued event with its delivery order
+type mailboxItem struct {
+	ctx   context.Context
+	event Event
+	seq   uint64
+	index int
+}
+
+// before reports whether a is delivered before b: higher priorities first, then in publish order
+func (a *mailboxItem) before(b *mailboxItem) bool {
+	if a.event.Priority != b.event.Priority {
+		return a.event.Priority > b.event.Priority
+	}
+	return a.seq < b.seq
+}
+
+// mailboxHeap orders queued events for delivery
+type mailboxHeap []*mailboxItem
+
+func (h mailboxHeap) Len() int           { return len(h) }
+func (h mailboxHeap) Less(i, j int) bool { return h[i].before(h[j]) }
+func (h mailboxHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *mailboxHeap) Push(x interface{}) {
+	item := x.(*mailboxItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *mailboxHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// subscriber delivers the events of its topics to a handler, one at a time, from a bounded
+// mailbox. Each queued event holds a slot until it is taken for delivery.
+type subscriber struct {
+	topics  []string
+	handler func(ctx context.Context, event Event) error
+	policy  OverflowPolicy
+	slots   chan struct{}
+	ready   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	mu      sync.Mutex
+	queue   mailboxHeap
+	spilled []*mailboxItem
+	once    sync.Once
+	bus     *EventBus
+}
+
+func newSubscriber(bus *EventBus, topics []string, handler func(ctx context.Context, event Event) error, options subscribeOptions) *subscriber {
+	return &subscriber{
+		topics:  topics,
+		handler: handler,
+		policy:  options.overflow,
+		slots:   make(chan struct{}, options.mailboxSize),
+		ready:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		bus:     bus,
+	}
+}
+
+// enqueue adds an event to the mailbox, applying the overflow policy when it is full
+func (s *subscriber) enqueue(ctx context.Context, item *mailboxItem) error {
+	if s.policy == OverflowBlock {
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			s.bus.recordOverflow(item.event.Topic, "blocked")
+			select {
+			case s.slots <- struct{}{}:
+			case <-ctx.Done():
+				return ErrPublishTimeout
+			case <-s.stop:
+				return ErrBusClosed
+			}
+		}
+		s.mu.Lock()
+		heap.Push(&s.queue, item)
+		s.mu.Unlock()
+		s.bus.recordQueued(item.event.Topic, 1)
+		s.notify()
+		return nil
+	}
+
+	// The other policies decide under the lock, so a slot cannot be freed behind their back
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case s.slots <- struct{}{}:
+		heap.Push(&s.queue, item)
+		s.bus.recordQueued(item.event.Topic, 1)
+	default:
+		if s.policy == OverflowDropOldest {
+			s.dropOldest(item)
+			return nil
+		}
+		s.spilled = append(s.spilled, item)
+		s.bus.recordOverflow(item.event.Topic, "spilled")
+		s.bus.recordQueued(item.event.Topic, 1)
+	}
+	s.notify()
+	return nil
+}
+
+// dropOldest makes room for item by discarding the oldest event of the lowest priority among the
+// queued ones and item itself, so the number of queued events is unchanged. s.mu must be held.
+func (s *subscriber) dropOldest(item *mailboxItem) {
+	victim := item
+	for _, queued := range s.queue {
+		if queued.event.Priority < victim.event.Priority ||
+			(queued.event.Priority == victim.event.Priority && queued.seq < victim.seq) {
+			victim = queued
+		}
+	}
+	s.bus.recordOverflow(victim.event.Topic, "dropped")
+	if victim == item {
+		return
+	}
+	heap.Remove(&s.queue, victim.index)
+	heap.Push(&s.queue, item)
+	s.bus.recordQueued(victim.event.Topic, -1)
+	s.bus.recordQueued(item.event.Topic, 1)
+}
+
+// notify wakes the dispatcher without waiting
+func (s *subscriber) notify() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the next event for delivery. A spilled event takes over the freed slot.
+func (s *subscriber) next() *mailboxItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return nil
+	}
+	item := heap.Pop(&s.queue).(*mailboxItem)
+	if len(s.spilled) > 0 {
+		heap.Push(&s.queue, s.spilled[0])
+		s.spilled[0] = nil
+		s.spilled = s.spilled[1:]
+	} else {
+		<-s.slots
+	}
+	return item
+}
+
+// run delivers queued events until the subscriber is closed
+func (s *subscriber) run() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.ready:
+		case <-s.stop:
+			return
+		}
+		for item := s.next(); item != nil; item = s.next() {
+			s.bus.recordQueued(item.event.Topic, -1)
+			s.bus.deliver(item.ctx, s, item.event)
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// close stops delivery after the event being handled and discards the queued ones
+func (s *subscriber) close() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range s.queue {
+		s.bus.recordQueued(item.event.Topic, -1)
+	}
+	for _, item := range s.spilled {
+		s.bus.recordQueued(item.event.Topic, -1)
+	}
+	s.queue = nil
+	s.spilled = nil
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/kafka"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the domain event Dispatcher, with logging and tracing middleware and the
+// configured Kafka bridge
+var Module = fx.Options(
+	fx.Provide(ProvideDispatcher),
+	fx.Invoke(RegisterDispatcher),
+)
+
+// DispatcherParams holds the dependencies of the dispatcher. Producer is needed by the Kafka
+// bridge only.
+type DispatcherParams struct {
+	fx.In
+
+	Config   *config.Config
+	Logger   *observability.Logger
+	Metrics  *observability.Metrics
+	Tracer   *observability.Tracer
+	Producer *kafka.Producer `optional:"true"`
+}
+
+// ProvideDispatcher creates the dispatcher of domain events
+func ProvideDispatcher(p DispatcherParams) (*Dispatcher, error) {
+	d := NewDispatcher(p.Logger).
+		WithMetrics(p.Metrics).
+		WithQueueSize(p.Config.Events.QueueSize).
+		Use(TracingMiddleware(p.Tracer.Tracer), LoggingMiddleware(p.Logger))
+
+	if len(p.Config.Events.Bridge) > 0 {
+		if p.Producer == nil {
+			return nil, fmt.Errorf("events: the kafka bridge needs a *kafka.Producer, e.g. from kafka.Module")
+		}
+		if err := NewBridge(p.Producer, p.Config.Events.Bridge, p.Logger).Attach(d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// RegisterDispatcher drains the async handlers of the dispatcher on shutdown
+func RegisterDispatcher(lc fx.Lifecycle, d *Dispatcher) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return d.Close(ctx)
+		},
+	})
+}
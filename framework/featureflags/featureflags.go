@@ -0,0 +1,89 @@
+// Package featureflags evaluates feature flags for the user and tenant of a request.
+// Flags are defined in the configuration or in Redis and evaluated locally, or evaluated
+// by a flag service speaking the OpenFeature Remote Evaluation Protocol.
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// Providers of flags
+const (
+	ProviderStatic      = "static"
+	ProviderRedis       = "redis"
+	ProviderOpenFeature = "openfeature"
+)
+
+// Module provides the Evaluator of the configured provider
+var Module = fx.Options(
+	fx.Provide(fx.Annotate(NewEvaluator, fx.ParamTags(``, ``, `optional:"true"`))),
+)
+
+// Evaluator evaluates feature flags for the EvalContext of ctx. Flags that are not defined
+// or cannot be evaluated return the default value, and so do string flags that are off.
+type Evaluator interface {
+	BoolFlag(ctx context.Context, key string, defaultValue bool) bool
+	StringFlag(ctx context.Context, key string, defaultValue string) string
+}
+
+// EvalContext is who flags are evaluated for
+type EvalContext struct {
+	UserID     string
+	TenantID   string
+	Roles      []string
+	Attributes map[string]string // e.g. the email of the user
+}
+
+type evalContextKey struct{}
+
+// NewContext returns a context evaluating flags for ec
+func NewContext(ctx context.Context, ec EvalContext) context.Context {
+	return context.WithValue(ctx, evalContextKey{}, ec)
+}
+
+// FromContext returns the EvalContext of ctx, empty if it has none
+func FromContext(ctx context.Context) EvalContext {
+	ec, _ := ctx.Value(evalContextKey{}).(EvalContext)
+	return ec
+}
+
+// NewEvaluator creates the evaluator of the configured provider. The redis provider reads the
+// flags with client, the client of redis.Module.
+func NewEvaluator(cfg *config.Config, logger *observability.Logger, client redis.UniversalClient) (Evaluator, error) {
+	ffCfg := cfg.FeatureFlags
+	switch strings.ToLower(ffCfg.Provider) {
+	case "", ProviderStatic:
+		flags := make(map[string]Flag, len(ffCfg.Flags))
+		for key, flag := range ffCfg.Flags {
+			flags[key] = FlagFromConfig(flag)
+		}
+		return NewFlags(NewStaticSource(flags), logger), nil
+	case ProviderRedis:
+		prefix := ffCfg.RedisPrefix
+		if prefix == "" {
+			prefix = "featureflags"
+		}
+		cacheTTL := time.Duration(ffCfg.CacheTTL) * time.Millisecond
+		if cacheTTL <= 0 {
+			cacheTTL = 5 * time.Second
+		}
+		if client == nil {
+			return nil, fmt.Errorf("featureflags: %w", axredis.ErrNoClient)
+		}
+		return NewFlags(NewRedisSource(client, prefix, cacheTTL), logger), nil
+	case ProviderOpenFeature:
+		return NewOpenFeature(ffCfg.OpenFeature, logger)
+	default:
+		return nil, fmt.Errorf("featureflags: unknown provider %q", ffCfg.Provider)
+	}
+}
@@ -0,0 +1,211 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagOn(t *testing.T) {
+	alice := EvalContext{UserID: "alice", TenantID: "acme"}
+	tests := []struct {
+		name string
+		flag Flag
+		ec   EvalContext
+		want bool
+	}{
+		{"disabled", Flag{Users: []string{"alice"}}, alice, false},
+		{"everyone", Flag{Enabled: true}, EvalContext{}, true},
+		{"listed user", Flag{Enabled: true, Rollout: 1, Users: []string{"alice"}}, alice, true},
+		{"listed tenant", Flag{Enabled: true, Rollout: 1, Tenants: []string{"acme"}}, alice, true},
+		{"rollout without user", Flag{Enabled: true, Rollout: 99}, EvalContext{TenantID: "acme"}, false},
+		{"rollout without tenant", Flag{Enabled: true, Rollout: 99, RolloutBy: "tenant"}, EvalContext{UserID: "alice"}, false},
+		{"full rollout", Flag{Enabled: true, Rollout: 100}, EvalContext{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.flag.On("checkout", tt.ec))
+		})
+	}
+}
+
+func TestRollout(t *testing.T) {
+	on := func(rollout int, by string) map[string]bool {
+		result := make(map[string]bool)
+		flag := Flag{Enabled: true, Rollout: rollout, RolloutBy: by}
+		for i := 0; i < 1000; i++ {
+			id := fmt.Sprintf("id-%d", i)
+			result[id] = flag.On("checkout", EvalContext{UserID: id, TenantID: id})
+		}
+		return result
+	}
+	count := func(result map[string]bool) (n int) {
+		for _, v := range result {
+			if v {
+				n++
+			}
+		}
+		return n
+	}
+
+	ten, thirty := on(10, RolloutByUser), on(30, RolloutByUser)
+	assert.InDelta(t, 100, count(ten), 40)
+	assert.InDelta(t, 300, count(thirty), 60)
+	for id, enabled := range ten {
+		if enabled {
+			assert.True(t, thirty[id], "%s keeps the flag as the rollout grows", id)
+		}
+	}
+	assert.Equal(t, ten, on(10, RolloutByTenant), "the same IDs are bucketed the same way by tenant")
+
+	// Other flags bucket IDs differently
+	other := Flag{Enabled: true, Rollout: 10}
+	differs := false
+	for id, enabled := range ten {
+		if other.On("search", EvalContext{UserID: id}) != enabled {
+			differs = true
+			break
+		}
+	}
+	assert.True(t, differs)
+}
+
+func TestFlags(t *testing.T) {
+	flags := NewFlags(NewStaticSource(map[string]Flag{
+		"newCheckout": {Enabled: true, Tenants: []string{"acme"}, Rollout: 1},
+		"theme":       {Enabled: true, Value: "dark", Users: []string{"alice"}, Rollout: 1},
+		"legacyApi":   {Enabled: true, Value: "false"},
+	}), nil)
+
+	acme := NewContext(context.Background(), EvalContext{TenantID: "acme"})
+	alice := NewContext(context.Background(), EvalContext{UserID: "alice"})
+
+	assert.True(t, flags.BoolFlag(acme, "NEWCHECKOUT", false), "keys are case-insensitive")
+	assert.False(t, flags.BoolFlag(alice, "newCheckout", true), "defined flags are not defaulted")
+	assert.True(t, flags.BoolFlag(alice, "undefined", true))
+	assert.False(t, flags.BoolFlag(alice, "legacyApi", true))
+
+	assert.Equal(t, "dark", flags.StringFlag(alice, "theme", "light"))
+	assert.Equal(t, "light", flags.StringFlag(acme, "theme", "light"))
+	assert.Equal(t, "light", flags.StringFlag(acme, "newCheckout", "light"), "flags without a value")
+}
+
+func TestNewEvaluator(t *testing.T) {
+	evaluator, err := NewEvaluator(&config.Config{FeatureFlags: config.FeatureFlagsConfig{
+		Flags: map[string]config.FeatureFlagConfig{"newcheckout": {Enabled: true}},
+	}}, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, evaluator.BoolFlag(context.Background(), "newCheckout", false))
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	evaluator, err = NewEvaluator(&config.Config{FeatureFlags: config.FeatureFlagsConfig{Provider: "Redis"}}, nil, client)
+	require.NoError(t, err)
+	assert.IsType(t, &Flags{}, evaluator)
+
+	_, err = NewEvaluator(&config.Config{FeatureFlags: config.FeatureFlagsConfig{Provider: "redis"}}, nil, nil)
+	assert.ErrorIs(t, err, axredis.ErrNoClient)
+
+	_, err = NewEvaluator(&config.Config{FeatureFlags: config.FeatureFlagsConfig{Provider: "openfeature"}}, nil, nil)
+	assert.ErrorContains(t, err, "invalid OpenFeature URL")
+
+	_, err = NewEvaluator(&config.Config{FeatureFlags: config.FeatureFlagsConfig{Provider: "launchdarkly"}}, nil, nil)
+	assert.ErrorContains(t, err, "unknown provider")
+}
+
+func TestOpenFeature(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received = body.Context
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ofrep/v1/evaluate/flags/newCheckout":
+			fmt.Fprint(w, `{"key":"newCheckout","value":true,"reason":"TARGETING_MATCH","variant":"on"}`)
+		case "/ofrep/v1/evaluate/flags/theme":
+			fmt.Fprint(w, `{"key":"theme","value":"dark","reason":"STATIC"}`)
+		case "/ofrep/v1/evaluate/flags/broken":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"key":"broken","errorCode":"PARSE_ERROR","errorDetails":"bad rule"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode":"FLAG_NOT_FOUND"}`)
+		}
+	}))
+	defer server.Close()
+
+	evaluator, err := NewOpenFeature(config.OpenFeatureConfig{
+		URL:     server.URL + "/",
+		Headers: map[string]string{"authorization": "Bearer secret"},
+	}, nil)
+	require.NoError(t, err)
+
+	ctx := NewContext(context.Background(), EvalContext{
+		UserID:     "alice",
+		TenantID:   "acme",
+		Roles:      []string{"admin"},
+		Attributes: map[string]string{"email": "alice@acme.test"},
+	})
+	assert.True(t, evaluator.BoolFlag(ctx, "newCheckout", false))
+	assert.Equal(t, map[string]interface{}{
+		"targetingKey": "alice",
+		"userId":       "alice",
+		"tenantId":     "acme",
+		"roles":        []interface{}{"admin"},
+		"email":        "alice@acme.test",
+	}, received)
+
+	assert.Equal(t, "dark", evaluator.StringFlag(ctx, "theme", "light"))
+	assert.Equal(t, "light", evaluator.StringFlag(ctx, "newCheckout", "light"), "values of another type")
+	assert.True(t, evaluator.BoolFlag(ctx, "broken", true))
+	assert.True(t, evaluator.BoolFlag(ctx, "missing", true))
+
+	// Tenants are targeted when there is no user
+	evaluator.BoolFlag(NewContext(context.Background(), EvalContext{TenantID: "acme"}), "newCheckout", false)
+	assert.Equal(t, "acme", received["targetingKey"])
+
+	server.Close()
+	assert.True(t, evaluator.BoolFlag(ctx, "newCheckout", true), "unreachable services return the default")
+}
+
+func TestRedisSource(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("Skipping Redis feature flag test; set REDIS_ADDR")
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	source := NewRedisSource(client, "featureflags-test-"+time.Now().Format("150405.000"), time.Minute)
+	flags := NewFlags(source, nil)
+
+	assert.True(t, flags.BoolFlag(ctx, "newCheckout", true))
+	require.NoError(t, source.Set(ctx, "newCheckout", Flag{Enabled: false}))
+	assert.False(t, flags.BoolFlag(ctx, "newCheckout", true), "Set drops the cached copy")
+
+	// Other instances see changes once their cached copy expires
+	other := NewRedisSource(client, source.prefix, 0)
+	require.NoError(t, other.Set(ctx, "newCheckout", Flag{Enabled: true}))
+	assert.False(t, flags.BoolFlag(ctx, "newCheckout", false))
+	assert.True(t, NewFlags(other, nil).BoolFlag(ctx, "newCheckout", false))
+
+	require.NoError(t, source.Delete(ctx, "newCheckout"))
+	assert.True(t, flags.BoolFlag(ctx, "newCheckout", true))
+}
@@ -0,0 +1,142 @@
+package featureflags
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// Rollout dimensions
+const (
+	RolloutByUser   = "user"
+	RolloutByTenant = "tenant"
+)
+
+// Flag is the definition of a feature flag
+type Flag struct {
+	Enabled   bool     `json:"enabled"`
+	Value     string   `json:"value,omitempty"`     // value of string flags while on; bool flags are on unless it is "false"
+	Rollout   int      `json:"rollout,omitempty"`   // percentage of users or tenants the flag is on for; 0 means all
+	RolloutBy string   `json:"rolloutBy,omitempty"` // RolloutByUser (default) or RolloutByTenant
+	Users     []string `json:"users,omitempty"`     // the flag is on for these users regardless of the rollout
+	Tenants   []string `json:"tenants,omitempty"`   // the flag is on for these tenants regardless of the rollout
+}
+
+// FlagFromConfig converts the configuration of a flag
+func FlagFromConfig(cfg config.FeatureFlagConfig) Flag {
+	return Flag{
+		Enabled:   cfg.Enabled,
+		Value:     cfg.Value,
+		Rollout:   cfg.Rollout,
+		RolloutBy: cfg.RolloutBy,
+		Users:     cfg.Users,
+		Tenants:   cfg.Tenants,
+	}
+}
+
+// On reports whether the flag named key is on for ec. Percentage rollouts hash the key
+// with the user or tenant ID, so each keeps its result as the percentage grows, and
+// contexts without that ID are left out.
+func (f Flag) On(key string, ec EvalContext) bool {
+	switch {
+	case !f.Enabled:
+		return false
+	case ec.UserID != "" && slices.Contains(f.Users, ec.UserID),
+		ec.TenantID != "" && slices.Contains(f.Tenants, ec.TenantID):
+		return true
+	case f.Rollout <= 0 || f.Rollout >= 100:
+		return true
+	}
+
+	id := ec.UserID
+	if strings.EqualFold(f.RolloutBy, RolloutByTenant) {
+		id = ec.TenantID
+	}
+	if id == "" {
+		return false
+	}
+	return bucket(key, id) < f.Rollout
+}
+
+// bucket places an ID in one of 100 buckets of a flag
+func bucket(key, id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(key)))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	return int(h.Sum32() % 100)
+}
+
+// Source looks up flag definitions
+type Source interface {
+	// Lookup returns the flag named key, or false if it is not defined
+	Lookup(ctx context.Context, key string) (Flag, bool, error)
+}
+
+// Flags evaluates the flags of a source locally
+type Flags struct {
+	source Source
+	logger *observability.Logger
+}
+
+// NewFlags creates an evaluator of the flags of source. Lookup errors are logged and
+// evaluate to the default value.
+func NewFlags(source Source, logger *observability.Logger) *Flags {
+	return &Flags{source: source, logger: logger}
+}
+
+// BoolFlag returns whether the flag is on for the EvalContext of ctx
+func (f *Flags) BoolFlag(ctx context.Context, key string, defaultValue bool) bool {
+	flag, ok := f.lookup(ctx, key)
+	if !ok {
+		return defaultValue
+	}
+	return flag.On(key, FromContext(ctx)) && flag.Value != "false"
+}
+
+// StringFlag returns the value of the flag while it is on for the EvalContext of ctx
+func (f *Flags) StringFlag(ctx context.Context, key string, defaultValue string) string {
+	flag, ok := f.lookup(ctx, key)
+	if !ok || flag.Value == "" || !flag.On(key, FromContext(ctx)) {
+		return defaultValue
+	}
+	return flag.Value
+}
+
+// lookup returns the flag named key, logging lookup errors
+func (f *Flags) lookup(ctx context.Context, key string) (Flag, bool) {
+	flag, ok, err := f.source.Lookup(ctx, key)
+	if err != nil {
+		if f.logger != nil {
+			f.logger.Warn("Failed to look up feature flag", zap.String("flag", key), zap.Error(err))
+		}
+		return Flag{}, false
+	}
+	return flag, ok
+}
+
+// StaticSource holds a fixed set of flags
+type StaticSource struct {
+	flags map[string]Flag
+}
+
+// NewStaticSource creates a source of flags by key; keys are case-insensitive
+func NewStaticSource(flags map[string]Flag) *StaticSource {
+	s := &StaticSource{flags: make(map[string]Flag, len(flags))}
+	for key, flag := range flags {
+		s.flags[strings.ToLower(key)] = flag
+	}
+	return s
+}
+
+// Lookup returns the flag named key
+func (s *StaticSource) Lookup(ctx context.Context, key string) (Flag, bool, error) {
+	flag, ok := s.flags[strings.ToLower(key)]
+	return flag, ok, nil
+}
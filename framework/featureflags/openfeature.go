@@ -0,0 +1,152 @@
+package featureflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// OpenFeature evaluates flags with a flag service speaking the OpenFeature Remote
+// Evaluation Protocol (OFREP), such as flagd or GO Feature Flag. The targeting key is the
+// user ID, or the tenant ID for requests without a user.
+type OpenFeature struct {
+	baseURL string
+	headers map[string]string
+	client  *http.Client
+	logger  *observability.Logger
+}
+
+// NewOpenFeature creates an evaluator calling the OFREP service at cfg.URL
+func NewOpenFeature(cfg config.OpenFeatureConfig, logger *observability.Logger) (*OpenFeature, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("featureflags: invalid OpenFeature URL %q", cfg.URL)
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	return &OpenFeature{
+		baseURL: strings.TrimSuffix(cfg.URL, "/"),
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+		logger:  logger,
+	}, nil
+}
+
+// BoolFlag evaluates a bool flag
+func (o *OpenFeature) BoolFlag(ctx context.Context, key string, defaultValue bool) bool {
+	value, ok := o.evaluate(ctx, key).(bool)
+	if !ok {
+		return defaultValue
+	}
+	return value
+}
+
+// StringFlag evaluates a string flag
+func (o *OpenFeature) StringFlag(ctx context.Context, key string, defaultValue string) string {
+	value, ok := o.evaluate(ctx, key).(string)
+	if !ok {
+		return defaultValue
+	}
+	return value
+}
+
+// ofrepResponse is the result of a flag evaluation. Failed evaluations carry an error
+// code instead of a value.
+type ofrepResponse struct {
+	Value        interface{} `json:"value"`
+	Reason       string      `json:"reason"`
+	Variant      string      `json:"variant"`
+	ErrorCode    string      `json:"errorCode"`
+	ErrorDetails string      `json:"errorDetails"`
+}
+
+// evaluate returns the value of a flag, or nil if it cannot be evaluated. Flags the
+// service does not know are not logged.
+func (o *OpenFeature) evaluate(ctx context.Context, key string) interface{} {
+	body, err := json.Marshal(map[string]interface{}{"context": evaluationContext(FromContext(ctx))})
+	if err != nil {
+		o.warn(key, err)
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/ofrep/v1/evaluate/flags/"+url.PathEscape(key), bytes.NewReader(body))
+	if err != nil {
+		o.warn(key, err)
+		return nil
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range o.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		o.warn(key, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	var result ofrepResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		o.warn(key, fmt.Errorf("status %d: %w", resp.StatusCode, err))
+		return nil
+	}
+	switch {
+	case result.ErrorCode == "FLAG_NOT_FOUND":
+		return nil
+	case result.ErrorCode != "":
+		o.warn(key, fmt.Errorf("%s: %s", result.ErrorCode, result.ErrorDetails))
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		o.warn(key, fmt.Errorf("unexpected status %d", resp.StatusCode))
+		return nil
+	}
+	return result.Value
+}
+
+// warn logs a failed evaluation
+func (o *OpenFeature) warn(key string, err error) {
+	if o.logger != nil {
+		o.logger.Warn("Failed to evaluate feature flag", zap.String("flag", key), zap.Error(err))
+	}
+}
+
+// evaluationContext converts an EvalContext to an OpenFeature evaluation context
+func evaluationContext(ec EvalContext) map[string]interface{} {
+	evalCtx := make(map[string]interface{}, len(ec.Attributes)+4)
+	for name, value := range ec.Attributes {
+		evalCtx[name] = value
+	}
+	targetingKey := ec.UserID
+	if targetingKey == "" {
+		targetingKey = ec.TenantID
+	}
+	if targetingKey != "" {
+		evalCtx["targetingKey"] = targetingKey
+	}
+	if ec.UserID != "" {
+		evalCtx["userId"] = ec.UserID
+	}
+	if ec.TenantID != "" {
+		evalCtx["tenantId"] = ec.TenantID
+	}
+	if len(ec.Roles) > 0 {
+		evalCtx["roles"] = ec.Roles
+	}
+	return evalCtx
+}
@@ -0,0 +1,107 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisSource reads flags from Redis, where they can be changed at runtime. Each flag
+// is a JSON Flag stored at "<prefix>:<key>", with the key in lower case. Flags are reused
+// for cacheTTL before they are read again.
+type RedisSource struct {
+	client   redis.UniversalClient
+	prefix   string
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	cached map[string]cachedFlag
+}
+
+// cachedFlag is a flag read from Redis
+type cachedFlag struct {
+	flag    Flag
+	found   bool
+	expires time.Time
+}
+
+// NewRedisSource creates a source of the flags stored in Redis
+func NewRedisSource(client redis.UniversalClient, prefix string, cacheTTL time.Duration) *RedisSource {
+	return &RedisSource{
+		client:   client,
+		prefix:   prefix,
+		cacheTTL: cacheTTL,
+		cached:   make(map[string]cachedFlag),
+	}
+}
+
+// Lookup returns the flag named key
+func (s *RedisSource) Lookup(ctx context.Context, key string) (Flag, bool, error) {
+	key = strings.ToLower(key)
+	s.mu.Lock()
+	cached, ok := s.cached[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.flag, cached.found, nil
+	}
+
+	var flag Flag
+	found := true
+	data, err := s.client.Get(ctx, s.redisKey(key)).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		found = false
+	case err != nil:
+		return Flag{}, false, err
+	default:
+		if err := json.Unmarshal(data, &flag); err != nil {
+			return Flag{}, false, err
+		}
+	}
+
+	s.mu.Lock()
+	s.cached[key] = cachedFlag{flag: flag, found: found, expires: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return flag, found, nil
+}
+
+// Set stores a flag. Other instances see it once their cached copy expires.
+func (s *RedisSource) Set(ctx context.Context, key string, flag Flag) error {
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	key = strings.ToLower(key)
+	if err := s.client.Set(ctx, s.redisKey(key), data, 0).Err(); err != nil {
+		return err
+	}
+	s.forget(key)
+	return nil
+}
+
+// Delete removes a flag
+func (s *RedisSource) Delete(ctx context.Context, key string) error {
+	key = strings.ToLower(key)
+	if err := s.client.Del(ctx, s.redisKey(key)).Err(); err != nil {
+		return err
+	}
+	s.forget(key)
+	return nil
+}
+
+// forget drops the cached copy of a flag
+func (s *RedisSource) forget(key string) {
+	s.mu.Lock()
+	delete(s.cached, key)
+	s.mu.Unlock()
+}
+
+// redisKey returns the Redis key of a flag
+func (s *RedisSource) redisKey(key string) string {
+	return s.prefix + ":" + key
+}
@@ -0,0 +1,178 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	grpc_validator "github.com/grpc-ecosystem/go-grpc-middleware/validator"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// InterceptorsGroup is the fx value group collecting the custom interceptors of modules
+const InterceptorsGroup = "grpc_interceptors"
+
+// Interceptor pairs the unary and stream interceptors of a concern, like MetricsInterceptor
+// and TracingInterceptor. Either method may return nil when the concern does not apply.
+type Interceptor interface {
+	Unary() grpc.UnaryServerInterceptor
+	Stream() grpc.StreamServerInterceptor
+}
+
+// InterceptorFuncs adapts a unary interceptor, a stream interceptor or both to Interceptor
+type InterceptorFuncs struct {
+	UnaryFunc  grpc.UnaryServerInterceptor
+	StreamFunc grpc.StreamServerInterceptor
+}
+
+// Unary implements Interceptor
+func (f InterceptorFuncs) Unary() grpc.UnaryServerInterceptor {
+	return f.UnaryFunc
+}
+
+// Stream implements Interceptor
+func (f InterceptorFuncs) Stream() grpc.StreamServerInterceptor {
+	return f.StreamFunc
+}
+
+// AsInterceptor annotates the constructor of an Interceptor so that the server runs it after
+// the framework interceptors:
+//
+//	fx.Provide(grpc.AsInterceptor(NewAuditInterceptor))
+func AsInterceptor(constructor interface{}) interface{} {
+	return fx.Annotate(constructor, fx.As(new(Interceptor)), fx.ResultTags(`group:"`+InterceptorsGroup+`"`))
+}
+
+// InterceptorChain composes server interceptors, in the order they are added, into a single
+// unary and a single stream interceptor option. gRPC accepts only one of each, so every
+// interceptor must go through the chain. Nil interceptors are skipped.
+type InterceptorChain struct {
+	unary  []grpc.UnaryServerInterceptor
+	stream []grpc.StreamServerInterceptor
+}
+
+// NewInterceptorChain creates an empty interceptor chain
+func NewInterceptorChain() *InterceptorChain {
+	return &InterceptorChain{}
+}
+
+// WithUnary adds unary interceptors
+func (c *InterceptorChain) WithUnary(interceptors ...grpc.UnaryServerInterceptor) *InterceptorChain {
+	for _, interceptor := range interceptors {
+		if interceptor != nil {
+			c.unary = append(c.unary, interceptor)
+		}
+	}
+	return c
+}
+
+// WithStream adds stream interceptors
+func (c *InterceptorChain) WithStream(interceptors ...grpc.StreamServerInterceptor) *InterceptorChain {
+	for _, interceptor := range interceptors {
+		if interceptor != nil {
+			c.stream = append(c.stream, interceptor)
+		}
+	}
+	return c
+}
+
+// WithCustom adds the unary and stream interceptors of each interceptor
+func (c *InterceptorChain) WithCustom(interceptors ...Interceptor) *InterceptorChain {
+	for _, interceptor := range interceptors {
+		if interceptor != nil {
+			c.WithUnary(interceptor.Unary())
+			c.WithStream(interceptor.Stream())
+		}
+	}
+	return c
+}
+
+// WithContext adds the request tags and the request context of ctxkit
+func (c *InterceptorChain) WithContext() *InterceptorChain {
+	c.WithUnary(grpc_ctxtags.UnaryServerInterceptor(), contextUnaryInterceptor())
+	return c.WithStream(grpc_ctxtags.StreamServerInterceptor(), contextStreamInterceptor())
+}
+
+// WithLogging logs every call
+func (c *InterceptorChain) WithLogging(logger *observability.Logger) *InterceptorChain {
+	c.WithUnary(grpc_zap.UnaryServerInterceptor(logger.Logger))
+	return c.WithStream(grpc_zap.StreamServerInterceptor(logger.Logger))
+}
+
+// WithValidation validates requests generated with a Validate method
+func (c *InterceptorChain) WithValidation() *InterceptorChain {
+	c.WithUnary(grpc_validator.UnaryServerInterceptor())
+	return c.WithStream(grpc_validator.StreamServerInterceptor())
+}
+
+// WithRecovery turns panics into Internal errors, logged and reported
+func (c *InterceptorChain) WithRecovery(logger *observability.Logger) *InterceptorChain {
+	handler := grpc_recovery.WithRecoveryHandlerContext(recoveryHandler(logger))
+	c.WithUnary(grpc_recovery.UnaryServerInterceptor(handler))
+	return c.WithStream(grpc_recovery.StreamServerInterceptor(handler))
+}
+
+// WithAuth authenticates calls with authFunc; a nil authFunc adds nothing
+func (c *InterceptorChain) WithAuth(authFunc grpc_auth.AuthFunc) *InterceptorChain {
+	if authFunc == nil {
+		return c
+	}
+	c.WithUnary(grpc_auth.UnaryServerInterceptor(authFunc))
+	return c.WithStream(grpc_auth.StreamServerInterceptor(authFunc))
+}
+
+// WithMetrics records the calls and stream messages in metrics
+func (c *InterceptorChain) WithMetrics(metrics *MetricsInterceptor) *InterceptorChain {
+	if metrics == nil {
+		return c
+	}
+	return c.WithCustom(metrics)
+}
+
+// WithTracing records a span per call
+func (c *InterceptorChain) WithTracing(tracing *TracingInterceptor) *InterceptorChain {
+	if tracing == nil {
+		return c
+	}
+	return c.WithCustom(tracing)
+}
+
+// WithConcurrencyLimit sheds unary calls beyond the adaptive limit when it is enabled. Streams
+// are left out, since their open-ended duration would distort the latency signal.
+func (c *InterceptorChain) WithConcurrencyLimit(limit *ConcurrencyLimitInterceptor) *InterceptorChain {
+	if limit == nil || !limit.Enabled() {
+		return c
+	}
+	return c.WithUnary(limit.Unary())
+}
+
+// WithErrors converts framework errors into statuses
+func (c *InterceptorChain) WithErrors(errors *ErrorInterceptor) *InterceptorChain {
+	if errors == nil {
+		return c
+	}
+	return c.WithCustom(errors)
+}
+
+// WithTimeouts bounds unary calls by unary and the context of streams by stream; 0 leaves
+// them unbounded
+func (c *InterceptorChain) WithTimeouts(unary, stream time.Duration) *InterceptorChain {
+	if unary > 0 {
+		c.WithUnary(timeoutInterceptor(unary))
+	}
+	return c.WithStream(streamTimeoutInterceptor(stream))
+}
+
+// ServerOptions returns the chained unary and stream interceptors as gRPC server options
+func (c *InterceptorChain) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(c.unary...)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(c.stream...)),
+	}
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// recordingInterceptor appends its name to calls when it runs
+func recordingInterceptor(name string, calls *[]string) InterceptorFuncs {
+	return InterceptorFuncs{
+		UnaryFunc: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			*calls = append(*calls, name)
+			return handler(ctx, req)
+		},
+		StreamFunc: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			*calls = append(*calls, name)
+			return handler(srv, ss)
+		},
+	}
+}
+
+func TestInterceptorChain(t *testing.T) {
+	var calls []string
+	chain := NewInterceptorChain().
+		WithAuth(nil).
+		WithMetrics(nil).
+		WithTracing(nil).
+		WithConcurrencyLimit(nil).
+		WithErrors(nil).
+		WithCustom(recordingInterceptor("first", &calls), nil).
+		WithCustom(InterceptorFuncs{UnaryFunc: recordingInterceptor("unary only", &calls).UnaryFunc}).
+		WithCustom(recordingInterceptor("last", &calls))
+
+	assert.Len(t, chain.unary, 3, "nil interceptors are skipped")
+	assert.Len(t, chain.stream, 2)
+	assert.Len(t, chain.ServerOptions(), 2, "one unary and one stream option")
+
+	unary := grpc_middleware.ChainUnaryServer(chain.unary...)
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "unary only", "last", "handler"}, calls)
+}
+
+func TestServerCustomInterceptors(t *testing.T) {
+	var calls []string
+	options := DefaultServerOptions()
+	options.Port = 0
+	options.Interceptors = []Interceptor{
+		recordingInterceptor("audit", &calls),
+		InterceptorFuncs{StreamFunc: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return status.Error(codes.PermissionDenied, "streams are closed")
+		}},
+	}
+	conn, _ := startStreamServer(t, options)
+
+	err := conn.Invoke(context.Background(), "/grpc.health.v1.Health/Check", wrapperspb.String(""), new(wrapperspb.StringValue))
+	assert.NotEqual(t, codes.PermissionDenied, status.Code(err), "stream-only interceptors leave unary calls alone")
+	_, err = count(context.Background(), conn, 1)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []string{"audit", "audit"}, calls)
+}
+
+func TestServerCustomInterceptorErrors(t *testing.T) {
+	options := DefaultServerOptions()
+	options.Port = 0
+	options.Interceptors = []Interceptor{InterceptorFuncs{
+		UnaryFunc: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return nil, errors.NewUnauthorized(errors.New("missing api key"), "unauthenticated")
+		},
+		StreamFunc: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return errors.WithCode(errors.New("streams are closed"), errors.CodeForbidden)
+		},
+	}}
+	conn, metrics := startStreamServer(t, options)
+
+	err := conn.Invoke(context.Background(), "/grpc.health.v1.Health/Check", wrapperspb.String(""), new(wrapperspb.StringValue))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "framework errors of custom interceptors are converted")
+	_, err = count(context.Background(), conn, 1)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.GRPCRequestsTotal.WithLabelValues("test.Counter", "Count", "PermissionDenied")),
+		"metrics observe the converted code")
+}
+
+func TestProvideServerOptions(t *testing.T) {
+	var options *ServerOptions
+	app := fxtest.New(t,
+		fx.Supply(&config.Config{}),
+		fx.Provide(func(cfg *config.Config) (*observability.Logger, error) { return observability.NewLogger(cfg) }),
+		fx.Provide(func() *observability.Metrics { return nil }),
+		fx.Provide(AsInterceptor(func() InterceptorFuncs { return InterceptorFuncs{} })),
+		fx.Provide(ProvideServerOptions),
+		fx.Populate(&options),
+	)
+	defer app.RequireStart().RequireStop()
+	assert.Len(t, options.Interceptors, 1)
+}
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConcurrencyLimitInterceptor sheds calls beyond an adaptive concurrency limit with
+// codes.Unavailable, which clients treat as retryable
+type ConcurrencyLimitInterceptor struct {
+	enabled bool
+	limiter *resilience.AdaptiveLimiter
+	logger  *observability.Logger
+}
+
+// NewConcurrencyLimitInterceptor creates a new concurrency limit interceptor from the gRPC configuration
+func NewConcurrencyLimitInterceptor(cfg *config.Config, logger *observability.Logger) *ConcurrencyLimitInterceptor {
+	return &ConcurrencyLimitInterceptor{
+		enabled: cfg.GRPC.ConcurrencyLimit.Enabled,
+		limiter: resilience.NewAdaptiveLimiter(resilience.AdaptiveLimiterOptionsFromConfig(cfg.GRPC.ConcurrencyLimit)),
+		logger:  logger,
+	}
+}
+
+// Enabled reports whether the limit is enabled in the configuration
+func (i *ConcurrencyLimitInterceptor) Enabled() bool {
+	return i.enabled
+}
+
+// Limiter returns the adaptive limiter, e.g. to report its limit
+func (i *ConcurrencyLimitInterceptor) Limiter() *resilience.AdaptiveLimiter {
+	return i.limiter
+}
+
+// Unary returns a gRPC unary interceptor
+func (i *ConcurrencyLimitInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		// Health checks must answer under load
+		if info.FullMethod == "/grpc.health.v1.Health/Check" {
+			return handler(ctx, req)
+		}
+
+		token, err := i.limiter.Acquire()
+		if err != nil {
+			i.logger.Debug("gRPC call shed",
+				zap.String("method", info.FullMethod),
+				zap.Int("limit", i.limiter.Limit()),
+			)
+			return nil, status.Error(codes.Unavailable, "server is overloaded")
+		}
+
+		resp, err := handler(ctx, req)
+
+		// Timeouts and unavailability signal overload; other outcomes only feed the latency
+		switch status.Code(err) {
+		case codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted:
+			token.Dropped()
+		default:
+			token.Success()
+		}
+		return resp, err
+	}
+}
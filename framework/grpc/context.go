@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// contextUnaryInterceptor stores the request ID, tenant and locale of the caller in the
+// request context, generating the request ID when the caller sent none and returning it in
+// the response header. Deadlines are propagated by gRPC itself.
+func contextUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = incomingContext(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(ctxkit.HeaderRequestID), ctxkit.RequestID(ctx)))
+		return handler(ctx, req)
+	}
+}
+
+// contextStreamInterceptor is the stream counterpart of contextUnaryInterceptor
+func contextStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = incomingContext(stream.Context())
+		_ = stream.SetHeader(metadata.Pairs(strings.ToLower(ctxkit.HeaderRequestID), ctxkit.RequestID(wrapped.WrappedContext)))
+		return handler(srv, wrapped)
+	}
+}
+
+func incomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = ctxkit.FromHeaders(ctx, func(header string) string {
+		if values := md.Get(header); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+	if ctxkit.RequestID(ctx) == "" {
+		ctx = ctxkit.WithRequestID(ctx, ctxkit.NewRequestID())
+	}
+	return ctx
+}
+
+// ContextUnaryClientInterceptor forwards the request ID, tenant, user, locale, trace context
+// and baggage of the call context as metadata. Metadata already set on the call is kept.
+func ContextUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// ContextStreamClientInterceptor is the stream counterpart of ContextUnaryClientInterceptor
+func ContextStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	headers := propagation.MapCarrier(ctxkit.Headers(ctx))
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+	var pairs []string
+	for header, value := range headers {
+		if len(md.Get(header)) == 0 {
+			pairs = append(pairs, strings.ToLower(header), value)
+		}
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// gatewayMetadata forwards the request ID, tenant and locale headers of a gateway request,
+// read by the HTTP middleware, to the gRPC server. The tenant is read from tenantHeader.
+func gatewayMetadata(header http.Header, tenantHeader string) metadata.MD {
+	md := metadata.MD{}
+	for name, value := range map[string]string{
+		ctxkit.HeaderRequestID: header.Get(ctxkit.HeaderRequestID),
+		ctxkit.HeaderTenantID:  header.Get(tenantHeader),
+		ctxkit.HeaderLocale:    header.Get(ctxkit.HeaderLocale),
+	} {
+		if value != "" {
+			md.Set(name, value)
+		}
+	}
+	return md
+}
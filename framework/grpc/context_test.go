@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestContextServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		requestID string
+		tenantID  string
+		locale    string
+	}{
+		{"from metadata", metadata.Pairs("x-request-id", "req-1", "x-tenant-id", "acme", "accept-language", "fr-CH, fr;q=0.9"), "req-1", "acme", "fr-CH"},
+		{"generated request ID", metadata.Pairs("x-request-id", "not valid"), "", "", ""},
+		{"user ID not trusted", metadata.Pairs("x-user-id", "admin"), "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got context.Context
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				got = ctx
+				return nil, nil
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := contextUnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, handler)
+			require.NoError(t, err)
+
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, ctxkit.RequestID(got))
+			} else {
+				assert.NotEmpty(t, ctxkit.RequestID(got))
+				assert.NotEqual(t, "not valid", ctxkit.RequestID(got))
+			}
+			assert.Equal(t, tt.tenantID, ctxkit.TenantID(got))
+			assert.Equal(t, tt.locale, ctxkit.Locale(got))
+			assert.Empty(t, ctxkit.UserID(got))
+		})
+	}
+}
+
+func TestContextClientInterceptor(t *testing.T) {
+	ctx := ctxkit.WithRequestID(context.Background(), "req-1")
+	ctx = ctxkit.WithTenantID(ctx, "acme")
+	ctx = ctxkit.WithUserID(ctx, "user-7")
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", "explicit")
+
+	var md metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	require.NoError(t, ContextUnaryClientInterceptor()(ctx, "/test.Service/Call", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-1"}, md.Get("x-request-id"))
+	assert.Equal(t, []string{"explicit"}, md.Get("x-tenant-id"), "metadata set on the call wins")
+	assert.Equal(t, []string{"user-7"}, md.Get("x-user-id"))
+	assert.Empty(t, md.Get("accept-language"))
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	ctx, err := ctxkit.WithBaggage(context.Background(), ctxkit.DebugTraceMember, "1")
+	require.NoError(t, err)
+	require.NoError(t, ContextUnaryClientInterceptor()(ctx, "/test.Service/Call", nil, nil, nil, invoker))
+	assert.Equal(t, []string{ctxkit.DebugTraceMember + "=1"}, md.Get("baggage"), "baggage is forwarded")
+}
+
+func TestGatewayMetadata(t *testing.T) {
+	header := http.Header{}
+	header.Set(ctxkit.HeaderRequestID, "req-1")
+	header.Set("X-Org-ID", "acme")
+	header.Set(ctxkit.HeaderUserID, "admin")
+
+	md := gatewayMetadata(header, "X-Org-ID")
+	assert.Equal(t, metadata.Pairs("x-request-id", "req-1", "x-tenant-id", "acme"), md)
+}
@@ -0,0 +1,197 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/validation"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the google.rpc.ErrorInfo domain of errors returned by the framework
+const ErrorDomain = "axiomod"
+
+// ErrorInterceptor converts framework errors returned by handlers into gRPC statuses
+// carrying google.rpc.ErrorInfo and google.rpc.BadRequest details
+type ErrorInterceptor struct {
+	logger *observability.Logger
+}
+
+// NewErrorInterceptor creates a new error interceptor
+func NewErrorInterceptor(logger *observability.Logger) *ErrorInterceptor {
+	return &ErrorInterceptor{
+		logger: logger,
+	}
+}
+
+// Unary returns a gRPC unary interceptor
+func (i *ErrorInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, i.convert(info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// Stream returns a gRPC stream interceptor
+func (i *ErrorInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := handler(srv, ss); err != nil {
+			return i.convert(info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+// convert maps err to a status error, logging the errors converted to Internal with their
+// original cause, which the status hides. Status errors are already converted.
+func (i *ErrorInterceptor) convert(method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	st := ToStatus(err)
+	if st.Code() == codes.Internal {
+		i.logger.Error("gRPC request failed",
+			zap.String("method", method),
+			zap.Error(err),
+		)
+	}
+	return st.Err()
+}
+
+// ToStatus converts an error into a gRPC status. Framework errors are mapped with
+// errors.ToGRPCCode and get an ErrorInfo detail with their code and metadata, plus a
+// BadRequest detail for validation violations. Internal errors do not expose their message.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	code := errors.GetCode(err)
+	grpcCode := codes.Code(errors.ToGRPCCode(err))
+	if code == "" {
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			code, grpcCode = errors.CodeDeadlineExceeded, codes.DeadlineExceeded
+		case errors.Is(err, context.Canceled):
+			code, grpcCode = errors.CodeCanceled, codes.Canceled
+		default:
+			return status.New(codes.Internal, "internal server error")
+		}
+	}
+
+	message := err.Error()
+	if grpcCode == codes.Internal {
+		message = "internal server error"
+	}
+	st := status.New(grpcCode, message)
+
+	info := &errdetails.ErrorInfo{
+		Reason: code,
+		Domain: ErrorDomain,
+	}
+	var badRequest *errdetails.BadRequest
+	if grpcCode != codes.Internal {
+		for key, value := range errors.GetMetadata(err) {
+			if violations, ok := value.([]validation.ValidationError); ok && key == "violations" {
+				badRequest = &errdetails.BadRequest{}
+				for _, v := range violations {
+					badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+						Field:       v.Field,
+						Description: v.Message,
+					})
+				}
+				continue
+			}
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			info.Metadata[key] = fmt.Sprint(value)
+		}
+	}
+
+	withDetails, detailErr := st.WithDetails(info)
+	if detailErr != nil {
+		return st
+	}
+	if badRequest != nil {
+		if withBadRequest, err := withDetails.WithDetails(badRequest); err == nil {
+			withDetails = withBadRequest
+		}
+	}
+	return withDetails
+}
+
+// FromStatus converts a gRPC status error received by a client back into a framework
+// error, restoring the code and metadata from its ErrorInfo detail when present
+func FromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+
+	result := errors.New(st.Message())
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != ErrorDomain {
+			continue
+		}
+		result = errors.WithCode(result, info.Reason)
+		for key, value := range info.Metadata {
+			result = errors.WithMetadata(result, key, value)
+		}
+		return result
+	}
+
+	return errors.WithCode(result, codeFromGRPC(st.Code()))
+}
+
+// codeFromGRPC maps a gRPC status code to a framework error code
+func codeFromGRPC(code codes.Code) string {
+	switch code {
+	case codes.NotFound:
+		return errors.CodeNotFound
+	case codes.InvalidArgument:
+		return errors.CodeInvalidInput
+	case codes.Unauthenticated:
+		return errors.CodeUnauthorized
+	case codes.PermissionDenied:
+		return errors.CodeForbidden
+	case codes.AlreadyExists:
+		return errors.CodeAlreadyExists
+	case codes.Aborted:
+		return errors.CodeConflict
+	case codes.DeadlineExceeded:
+		return errors.CodeDeadlineExceeded
+	case codes.Unavailable:
+		return errors.CodeUnavailable
+	case codes.Unimplemented:
+		return errors.CodeNotImplemented
+	case codes.Canceled:
+		return errors.CodeCanceled
+	default:
+		return errors.CodeInternal
+	}
+}
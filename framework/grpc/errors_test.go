@@ -0,0 +1,173 @@
+package grpc
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/validation"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	violations := []validation.ValidationError{{Field: "email", Tag: "email", Message: "Invalid email format"}}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+		wantReason  string
+		wantMeta    map[string]string
+		wantFields  []string
+	}{
+		{
+			name:        "Not found with metadata",
+			err:         errors.WithMetadata(errors.WithCode(errors.New("user not found"), errors.CodeNotFound), "user_id", 42),
+			wantCode:    codes.NotFound,
+			wantMessage: "user not found",
+			wantReason:  errors.CodeNotFound,
+			wantMeta:    map[string]string{"user_id": "42"},
+		},
+		{
+			name:        "Validation violations",
+			err:         errors.WithMetadata(errors.WithCode(errors.New("invalid request"), errors.CodeValidation), "violations", violations),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "invalid request",
+			wantReason:  errors.CodeValidation,
+			wantFields:  []string{"email"},
+		},
+		{
+			name:        "Internal hides message",
+			err:         errors.NewInternal(assert.AnError, "db exploded"),
+			wantCode:    codes.Internal,
+			wantMessage: "internal server error",
+			wantReason:  errors.CodeInternal,
+		},
+		{
+			name:        "Context deadline",
+			err:         errors.Wrap(context.DeadlineExceeded, "request timeout"),
+			wantCode:    codes.DeadlineExceeded,
+			wantMessage: "request timeout: context deadline exceeded",
+			wantReason:  errors.CodeDeadlineExceeded,
+		},
+		{
+			name:        "Plain error",
+			err:         assert.AnError,
+			wantCode:    codes.Internal,
+			wantMessage: "internal server error",
+		},
+		{
+			name:        "Existing status is kept",
+			err:         status.Error(codes.PermissionDenied, "nope"),
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := ToStatus(tt.err)
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantMessage, st.Message())
+
+			var info *errdetails.ErrorInfo
+			var fields []string
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					info = d
+				case *errdetails.BadRequest:
+					for _, v := range d.FieldViolations {
+						fields = append(fields, v.Field)
+					}
+				}
+			}
+
+			if tt.wantReason == "" {
+				assert.Nil(t, info)
+				return
+			}
+			require.NotNil(t, info)
+			assert.Equal(t, tt.wantReason, info.Reason)
+			assert.Equal(t, ErrorDomain, info.Domain)
+			if tt.wantMeta != nil {
+				assert.Equal(t, tt.wantMeta, info.Metadata)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	original := errors.WithMetadata(errors.WithCode(errors.New("user not found"), errors.CodeNotFound), "user_id", "42")
+
+	err := FromStatus(ToStatus(original).Err())
+	assert.Equal(t, errors.CodeNotFound, errors.GetCode(err))
+	assert.Equal(t, "42", errors.GetMetadata(err)["user_id"])
+	assert.Equal(t, "user not found", err.Error())
+
+	err = FromStatus(status.Error(codes.Unavailable, "down"))
+	assert.Equal(t, errors.CodeUnavailable, errors.GetCode(err))
+}
+
+func TestErrorInterceptorUnary(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	interceptor := NewErrorInterceptor(logger).Unary()
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.WithCode(errors.New("forbidden"), errors.CodeForbidden)
+		})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestErrorInterceptorLogsInternalErrors(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	core, logs := observer.New(zapcore.ErrorLevel)
+	_, err := logger.AddCore(core)
+	require.NoError(t, err)
+	interceptor := NewErrorInterceptor(logger).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantLog bool
+	}{
+		{name: "plain error", err: fmt.Errorf("query orders: %w", stderrors.New("connection reset")), wantLog: true},
+		{name: "internal framework error", err: errors.NewInternal(stderrors.New("disk full"), "save order"), wantLog: true},
+		{name: "client error", err: errors.NewNotFound(stderrors.New("no rows"), "order not found")},
+		{name: "status error", err: status.Error(codes.Internal, "converted by a custom interceptor")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.TakeAll()
+			_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+			require.Error(t, err)
+
+			entries := logs.TakeAll()
+			if !tt.wantLog {
+				assert.Empty(t, entries)
+				return
+			}
+			require.Len(t, entries, 1)
+			assert.Equal(t, "internal server error", status.Convert(err).Message(), "the cause is hidden from the client")
+			assert.Equal(t, tt.err.Error(), entries[0].ContextMap()["error"], "the cause is logged")
+		})
+	}
+}
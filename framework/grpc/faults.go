@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FaultInjectionInterceptor injects the faults of a chaos.Injector in the calls they match,
+// by full method. Failed calls get the code of the HTTP status of the fault, and reset
+// connections codes.Unavailable, which clients see when a connection drops.
+type FaultInjectionInterceptor struct {
+	injector *chaos.Injector
+	logger   *observability.Logger
+}
+
+// NewFaultInjectionInterceptor creates a new fault injection interceptor
+func NewFaultInjectionInterceptor(injector *chaos.Injector, logger *observability.Logger) *FaultInjectionInterceptor {
+	return &FaultInjectionInterceptor{injector: injector, logger: logger}
+}
+
+// Unary returns a gRPC unary interceptor, nil unless fault injection is enabled
+func (i *FaultInjectionInterceptor) Unary() grpc.UnaryServerInterceptor {
+	if !i.injector.Enabled() {
+		return nil
+	}
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := i.inject(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns a gRPC stream interceptor, nil unless fault injection is enabled
+func (i *FaultInjectionInterceptor) Stream() grpc.StreamServerInterceptor {
+	if !i.injector.Enabled() {
+		return nil
+	}
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := i.inject(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// inject applies the fault matching a call, returning the error failing it
+func (i *FaultInjectionInterceptor) inject(ctx context.Context, fullMethod string) error {
+	// Health checks keep answering during experiments
+	if fullMethod == "/grpc.health.v1.Health/Check" || fullMethod == "/grpc.health.v1.Health/Watch" {
+		return nil
+	}
+	injection, ok := i.injector.Inject("", fullMethod)
+	if !ok {
+		return nil
+	}
+
+	i.logger.Debug("Injecting fault",
+		zap.String("fault", injection.Fault),
+		zap.String("method", fullMethod),
+	)
+	if err := i.injector.Wait(ctx, injection); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	switch {
+	case injection.Reset:
+		return status.Error(codes.Unavailable, "connection reset by injected fault "+injection.Fault)
+	case injection.Status != 0:
+		return status.Error(codeForHTTPStatus(injection.Status), "fault injected: "+injection.Fault)
+	}
+	return nil
+}
+
+// codeForHTTPStatus returns the gRPC code closest to an HTTP error status
+func codeForHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	if httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFaultInjectionInterceptor(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	assert.Nil(t, NewFaultInjectionInterceptor(chaos.NewInjector(false, logger), logger).Unary(), "disabled without chaos.enabled")
+
+	injector := chaos.NewInjector(true, logger)
+	require.NoError(t, injector.SetFaults([]chaos.Fault{
+		{Name: "orders-busy", Path: "/orders.v1.Orders/Create", ErrorRate: 1, ErrorStatus: http.StatusTooManyRequests},
+		{Name: "orders-reset", Path: "/orders.v1.Orders/*", ResetRate: 1},
+		{Name: "http-only", Method: "GET", Path: "/*", ErrorRate: 1},
+	}))
+	unary := NewFaultInjectionInterceptor(injector, logger).Unary()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	tests := []struct {
+		method   string
+		wantCode codes.Code
+	}{
+		{method: "/orders.v1.Orders/Create", wantCode: codes.ResourceExhausted},
+		{method: "/orders.v1.Orders/Get", wantCode: codes.Unavailable},
+		{method: "/users.v1.Users/Get", wantCode: codes.OK},
+		{method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+		assert.Equal(t, tt.wantCode, status.Code(err), tt.method)
+	}
+}
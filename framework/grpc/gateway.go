@@ -0,0 +1,208 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/ctxkit"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/adaptor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultGatewayPrefix is the path prefix the gateway is mounted under when none is configured
+const DefaultGatewayPrefix = "/v1"
+
+// gatewayErrorLocal is the context key holding where the gateway stores the error of a request
+const gatewayErrorLocal = "grpc_gateway_error"
+
+// GatewayRegisterFunc registers generated gateway handlers that call the gRPC server over a
+// client connection, such as pb.RegisterUserServiceHandler
+type GatewayRegisterFunc func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+// GatewayServerRegisterFunc registers generated gateway handlers that call a service
+// implementation in process, typically a closure over pb.RegisterUserServiceHandlerServer
+type GatewayServerRegisterFunc func(ctx context.Context, mux *runtime.ServeMux) error
+
+// Gateway exposes gRPC services annotated with google.api.http rules as REST endpoints on the
+// HTTP server. Requests pass through the HTTP middleware, including authentication, and errors
+// are returned to Fiber so they are rendered by the shared error handler.
+type Gateway struct {
+	mux     *runtime.ServeMux
+	prefix  string
+	target  string
+	enabled bool
+	creds   credentials.TransportCredentials
+	mu      sync.Mutex
+	conn    *grpc.ClientConn
+	logger  *observability.Logger
+}
+
+// NewGateway creates a new gRPC gateway from the configuration
+func NewGateway(cfg *config.Config, logger *observability.Logger) *Gateway {
+	prefix := strings.TrimSuffix(cfg.GRPC.Gateway.Prefix, "/")
+	if prefix == "" {
+		prefix = DefaultGatewayPrefix
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.GRPC.TLS.Enabled {
+		// The loopback connection reaches this process, whose certificate names its public
+		// host rather than the loopback address, so only a configured endpoint is verified
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: cfg.GRPC.Gateway.Endpoint == ""})
+	}
+
+	target := cfg.GRPC.Gateway.Endpoint
+	if target == "" {
+		host := cfg.GRPC.Host
+		// Reach a server listening on all interfaces through loopback
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		target = net.JoinHostPort(host, strconv.Itoa(cfg.GRPC.Port))
+	}
+
+	g := &Gateway{
+		prefix:  prefix,
+		target:  target,
+		enabled: cfg.GRPC.Gateway.Enabled,
+		creds:   creds,
+		logger:  logger,
+	}
+	tenantHeader := cfg.Observability.TenantHeader
+	if tenantHeader == "" {
+		tenantHeader = ctxkit.HeaderTenantID
+	}
+	g.mux = runtime.NewServeMux(
+		runtime.WithMetadata(func(_ context.Context, r *http.Request) metadata.MD {
+			return gatewayMetadata(r.Header, tenantHeader)
+		}),
+		runtime.WithErrorHandler(g.handleError),
+		runtime.WithRoutingErrorHandler(g.handleRoutingError),
+	)
+	return g
+}
+
+// Enabled reports whether the gateway should be mounted on the HTTP server
+func (g *Gateway) Enabled() bool {
+	return g.enabled
+}
+
+// Prefix returns the path prefix the gateway is mounted under
+func (g *Gateway) Prefix() string {
+	return g.prefix
+}
+
+// Mux returns the underlying gateway mux
+func (g *Gateway) Mux() *runtime.ServeMux {
+	return g.mux
+}
+
+// Register registers handlers that call the gRPC server through a loopback connection, so the
+// gRPC interceptors run for gateway requests too. The Authorization header is forwarded as metadata.
+func (g *Gateway) Register(fn GatewayRegisterFunc) error {
+	conn, err := g.clientConn()
+	if err != nil {
+		return err
+	}
+	return fn(context.Background(), g.mux, conn)
+}
+
+// RegisterServer registers handlers that call a service implementation directly. The gRPC
+// interceptors do not run; the request context exposes the HTTP request locals, such as "user_id".
+func (g *Gateway) RegisterServer(fn GatewayServerRegisterFunc) error {
+	return fn(context.Background(), g.mux)
+}
+
+// clientConn returns the loopback connection to the gRPC server, creating it on first use
+func (g *Gateway) clientConn() (*grpc.ClientConn, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		return g.conn, nil
+	}
+
+	// The client connects lazily, so the gRPC server does not need to be running yet
+	conn, err := grpc.NewClient(g.target, grpc.WithTransportCredentials(g.creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway connection to %s: %w", g.target, err)
+	}
+	g.conn = conn
+	return conn, nil
+}
+
+// Handler returns a Fiber handler serving the gateway routes
+func (g *Gateway) Handler() fiber.Handler {
+	serve := adaptor.HTTPHandler(g.mux)
+	return func(c *fiber.Ctx) error {
+		// The error handler stores the request's error here instead of writing a response
+		var gatewayErr error
+		c.Locals(gatewayErrorLocal, &gatewayErr)
+		if err := serve(c); err != nil {
+			return err
+		}
+		return gatewayErr
+	}
+}
+
+// Close closes the loopback connection to the gRPC server
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+// handleError hands the error of a gateway call back to the Fiber handler. Status errors from
+// the gRPC server are converted back into framework errors so they map to the same responses.
+func (g *Gateway) handleError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if _, ok := status.FromError(err); ok {
+		err = FromStatus(err)
+	}
+
+	slot, ok := r.Context().Value(gatewayErrorLocal).(*error)
+	if !ok {
+		// Served outside of Handler, so there is no Fiber error handler to defer to
+		g.logger.Debug("gRPC gateway error outside of Fiber", zap.Error(err))
+		runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, ToStatus(err).Err())
+		return
+	}
+	*slot = err
+}
+
+// handleRoutingError reports unknown routes and methods like the rest of the HTTP server
+func (g *Gateway) handleRoutingError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, httpStatus int) {
+	var err error
+	switch httpStatus {
+	case http.StatusMethodNotAllowed:
+		err = fiber.ErrMethodNotAllowed
+	case http.StatusBadRequest:
+		err = fiber.ErrBadRequest
+	default:
+		err = fiber.ErrNotFound
+	}
+	if slot, ok := r.Context().Value(gatewayErrorLocal).(*error); ok {
+		*slot = err
+		return
+	}
+	runtime.DefaultRoutingErrorHandler(ctx, mux, marshaler, w, r, httpStatus)
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// newGatewayApp mounts the gateway like the HTTP server does, behind the shared error handler
+func newGatewayApp(t *testing.T, cfg *config.Config) (*fiber.App, *Gateway) {
+	t.Helper()
+	logger, _ := observability.NewLogger(cfg)
+	gateway := NewGateway(cfg, logger)
+	t.Cleanup(func() { _ = gateway.Close() })
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.NewErrorHandler(cfg, logger).Handler()})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "alice")
+		return c.Next()
+	})
+	app.All(gateway.Prefix()+"/*", gateway.Handler())
+	return app, gateway
+}
+
+func TestGateway(t *testing.T) {
+	cfg := &config.Config{GRPC: config.GRPCConfig{Gateway: config.GRPCGatewayConfig{Enabled: true}}}
+	app, gateway := newGatewayApp(t, cfg)
+	assert.Equal(t, DefaultGatewayPrefix, gateway.Prefix())
+
+	require.NoError(t, gateway.RegisterServer(func(ctx context.Context, mux *runtime.ServeMux) error {
+		if err := mux.HandlePath(http.MethodGet, "/v1/users/{id}", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+			if params["id"] != "42" {
+				runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+					errors.WithCode(errors.New("user not found"), errors.CodeNotFound))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":     params["id"],
+				"caller": r.Context().Value("user_id"),
+			})
+		}); err != nil {
+			return err
+		}
+		return mux.HandlePath(http.MethodGet, "/v1/failures", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, ToStatus(errors.WithCode(errors.New("version mismatch"), errors.CodeConflict)).Err())
+		})
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   map[string]interface{}
+		wantCode   string
+	}{
+		{"success", http.MethodGet, "/v1/users/42", http.StatusOK, map[string]interface{}{"id": "42", "caller": "alice"}, ""},
+		{"framework error", http.MethodGet, "/v1/users/7", http.StatusNotFound, nil, errors.CodeNotFound},
+		{"status error", http.MethodGet, "/v1/failures", http.StatusConflict, nil, errors.CodeConflict},
+		{"unknown route", http.MethodGet, "/v1/orders", http.StatusNotFound, nil, ""},
+		{"unknown method", http.MethodDelete, "/v1/users/42", http.StatusMethodNotAllowed, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			if tt.wantBody != nil {
+				assert.Equal(t, tt.wantBody, body)
+				return
+			}
+			assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+			assert.Equal(t, tt.path, body["instance"])
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, body["code"])
+			}
+		})
+	}
+}
+
+func TestGatewayLoopback(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	cfg := &config.Config{GRPC: config.GRPCConfig{
+		Host:    "0.0.0.0",
+		Port:    port,
+		Gateway: config.GRPCGatewayConfig{Enabled: true, Prefix: "/api/"},
+	}}
+	app, gateway := newGatewayApp(t, cfg)
+	assert.Equal(t, "/api", gateway.Prefix())
+
+	// Stands in for a generated RegisterXxxHandler calling the service through the connection
+	require.NoError(t, gateway.Register(func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
+		client := healthpb.NewHealthClient(conn)
+		return mux.HandlePath(http.MethodGet, "/api/health/{service}", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+			resp, err := client.Check(r.Context(), &healthpb.HealthCheckRequest{Service: params["service"]})
+			if err != nil {
+				runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+				return
+			}
+			_, _ = io.WriteString(w, resp.GetStatus().String())
+		})
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/health/orders", nil), -1)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "SERVING", string(body))
+
+	// The gRPC NotFound status maps back to the framework's not found problem
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/health/billing", nil), -1)
+	require.NoError(t, err)
+	var problem map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, errors.CodeNotFound, problem["code"])
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
 )
@@ -31,24 +32,91 @@ func (i *MetricsInterceptor) Unary() grpc.UnaryServerInterceptor {
 	) (interface{}, error) {
 		start := time.Now()
 
-		// Skip metrics for health check and reflection
-		if info.FullMethod == "/grpc.health.v1.Health/Check" || info.FullMethod == "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo" {
+		// Skip metrics for health check and reflection, and when metrics are disabled
+		if i.unmetered(info.FullMethod) {
 			return handler(ctx, req)
 		}
 
 		resp, err := handler(ctx, req)
+		i.observe(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// Stream returns a gRPC stream interceptor. Streams count as requests, observed when they
+// end, and the messages they send and receive are counted as they flow.
+func (i *MetricsInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+
+		if i.unmetered(info.FullMethod) {
+			return handler(srv, ss)
+		}
 
-		st, _ := status.FromError(err)
-		statusCode := st.Code().String()
 		service, method := parseFullMethod(info.FullMethod)
+		err := handler(srv, &meteredStream{
+			ServerStream: ss,
+			sent:         i.metrics.GRPCStreamMessagesTotal.WithLabelValues(service, method, "sent"),
+			received:     i.metrics.GRPCStreamMessagesTotal.WithLabelValues(service, method, "received"),
+		})
+		i.observe(info.FullMethod, start, err)
+		return err
+	}
+}
 
-		duration := time.Since(start).Seconds()
+// observe records a call that started at start and ended with err
+func (i *MetricsInterceptor) observe(fullMethod string, start time.Time, err error) {
+	st, _ := status.FromError(err)
+	statusCode := st.Code().String()
+	service, method := parseFullMethod(fullMethod)
 
-		i.metrics.GRPCRequestsTotal.WithLabelValues(service, method, statusCode).Inc()
-		i.metrics.GRPCRequestDuration.WithLabelValues(service, method, statusCode).Observe(duration)
+	duration := time.Since(start).Seconds()
 
-		return resp, err
+	i.metrics.GRPCRequestsTotal.WithLabelValues(service, method, statusCode).Inc()
+	i.metrics.GRPCRequestDuration.WithLabelValues(service, method, statusCode).Observe(duration)
+}
+
+// unmetered reports whether a method is left out of metrics: health checks and reflection,
+// and every method when metrics are disabled
+func (i *MetricsInterceptor) unmetered(fullMethod string) bool {
+	if i.metrics.GRPCRequestsTotal == nil {
+		return true
+	}
+	switch fullMethod {
+	case "/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch",
+		"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":
+		return true
+	}
+	return false
+}
+
+// meteredStream counts the messages sent and received on a stream
+type meteredStream struct {
+	grpc.ServerStream
+	sent     prometheus.Counter
+	received prometheus.Counter
+}
+
+func (s *meteredStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+func (s *meteredStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Inc()
 	}
+	return err
 }
 
 // parseFullMethod splits full method into service and method
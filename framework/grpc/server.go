@@ -7,15 +7,15 @@ import (
 	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errorreport"
 	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/tlscert"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
-	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
-	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
-	grpc_validator "github.com/grpc-ecosystem/go-grpc-middleware/validator"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -31,21 +31,80 @@ import (
 // Module provides the fx options for the grpc module
 var Module = fx.Options(
 	fx.Provide(NewServer),
-	fx.Provide(NewServerOptions),
+	fx.Provide(ProvideServerOptions),
 	fx.Provide(NewMetricsInterceptor),
 	fx.Provide(NewTracingInterceptor),
+	fx.Provide(NewErrorInterceptor),
+	fx.Provide(NewConcurrencyLimitInterceptor),
+	fx.Provide(AsInterceptor(NewFaultInjectionInterceptor)),
+	fx.Provide(NewGateway),
 )
 
-// NewServerOptions creates default server options from config
-func NewServerOptions(cfg *config.Config) *ServerOptions {
-	return &ServerOptions{
-		Host: cfg.GRPC.Host,
-		Port: cfg.GRPC.Port,
-		// Other fields can be mapped here as needed
-		MaxConnectionAge:  time.Hour,
-		MaxConnectionIdle: time.Minute * 15,
-		Timeout:           time.Second * 30,
+// NewServerOptions creates default server options from config. redisClient, the client of
+// redis.Module, stores ACME certificates kept in Redis; it may be nil otherwise.
+func NewServerOptions(cfg *config.Config, logger *observability.Logger, metrics *observability.Metrics, redisClient redis.UniversalClient) (*ServerOptions, error) {
+	options := &ServerOptions{
+		Host:                         cfg.GRPC.Host,
+		Port:                         cfg.GRPC.Port,
+		MaxConnectionAge:             time.Hour,
+		MaxConnectionIdle:            time.Minute * 15,
+		MaxConnectionAgeGrace:        time.Duration(cfg.GRPC.Keepalive.MaxConnectionAgeGrace) * time.Second,
+		Timeout:                      time.Second * 30,
+		StreamTimeout:                time.Duration(cfg.GRPC.StreamTimeout) * time.Second,
+		KeepaliveTime:                time.Duration(cfg.GRPC.Keepalive.Time) * time.Second,
+		KeepaliveTimeout:             time.Duration(cfg.GRPC.Keepalive.Timeout) * time.Second,
+		KeepaliveMinTime:             time.Duration(cfg.GRPC.Keepalive.MinTime) * time.Second,
+		KeepalivePermitWithoutStream: cfg.GRPC.Keepalive.PermitWithoutStream,
+		EnableReflection:             cfg.GRPC.EnableReflection,
+		MaxRecvMsgSize:               cfg.GRPC.MaxRecvMsgSize,
+		MaxSendMsgSize:               cfg.GRPC.MaxSendMsgSize,
+	}
+	if cfg.GRPC.Timeout > 0 {
+		options.Timeout = time.Duration(cfg.GRPC.Timeout) * time.Second
+	}
+	if cfg.GRPC.Keepalive.MaxConnectionAge > 0 {
+		options.MaxConnectionAge = time.Duration(cfg.GRPC.Keepalive.MaxConnectionAge) * time.Second
+	}
+	if cfg.GRPC.Keepalive.MaxConnectionIdle > 0 {
+		options.MaxConnectionIdle = time.Duration(cfg.GRPC.Keepalive.MaxConnectionIdle) * time.Second
+	}
+	if options.MaxRecvMsgSize < 0 || options.MaxSendMsgSize < 0 {
+		return nil, fmt.Errorf("grpc.maxRecvMsgSize and grpc.maxSendMsgSize must not be negative")
+	}
+	if options.EnableReflection && cfg.App.Environment == "production" {
+		logger.Warn("gRPC reflection is enabled in production; set grpc.enableReflection to false to hide the service descriptions")
+	}
+
+	if cfg.GRPC.TLS.Enabled {
+		certificates, err := tlscert.NewReloaderFromConfig("grpc", cfg.GRPC.TLS, redisClient, logger)
+		if err != nil {
+			return nil, err
+		}
+		options.Certificates = certificates.WithMetrics(metrics)
+	}
+	return options, nil
+}
+
+// ServerOptionsParams contains the dependencies of the server options
+type ServerOptionsParams struct {
+	fx.In
+
+	Config       *config.Config
+	Logger       *observability.Logger
+	Metrics      *observability.Metrics
+	Redis        redis.UniversalClient `optional:"true"`
+	Interceptors []Interceptor         `group:"grpc_interceptors"`
+}
+
+// ProvideServerOptions creates the server options from config with the interceptors of the
+// grpc_interceptors group
+func ProvideServerOptions(params ServerOptionsParams) (*ServerOptions, error) {
+	options, err := NewServerOptions(params.Config, params.Logger, params.Metrics, params.Redis)
+	if err != nil {
+		return nil, err
 	}
+	options.Interceptors = append(options.Interceptors, params.Interceptors...)
+	return options, nil
 }
 
 // Server represents a gRPC server
@@ -58,14 +117,34 @@ type Server struct {
 
 // ServerOptions contains options for the gRPC server
 type ServerOptions struct {
-	Host              string
-	Port              int
-	TLSCertFile       string
-	TLSKeyFile        string
+	Host        string
+	Port        int
+	TLSCertFile string
+	TLSKeyFile  string
+	// Certificates serves TLS with certificates reloaded while the server runs; it takes
+	// precedence over TLSCertFile and TLSKeyFile
+	Certificates      *tlscert.Reloader
 	MaxConnectionAge  time.Duration
 	MaxConnectionIdle time.Duration
-	Timeout           time.Duration
-	AuthFunc          grpc_auth.AuthFunc
+	// MaxConnectionAgeGrace lets calls still running on a connection that got too old finish;
+	// 0 waits for them
+	MaxConnectionAgeGrace time.Duration
+	Timeout               time.Duration // bounds unary calls
+	// StreamTimeout bounds the context of streams; 0 leaves them open until either side ends
+	// them. Stream handlers must return when stream.Context() is done.
+	StreamTimeout time.Duration
+	// Keepalive pings; 0 keeps the gRPC defaults of 2h, 20s and 5m
+	KeepaliveTime                time.Duration
+	KeepaliveTimeout             time.Duration
+	KeepaliveMinTime             time.Duration // clients pinging more often are disconnected
+	KeepalivePermitWithoutStream bool
+	AuthFunc                     grpc_auth.AuthFunc // authenticates unary calls and streams
+	EnableReflection             bool               // serve the reflection service, e.g. for grpcurl
+	MaxRecvMsgSize               int                // in bytes; 0 keeps the gRPC default of 4 MiB
+	MaxSendMsgSize               int                // in bytes; 0 keeps the gRPC default of 2 GiB
+	// Interceptors run, in order, after the framework interceptors and before the error
+	// conversion and timeouts
+	Interceptors []Interceptor
 }
 
 // DefaultServerOptions returns the default server options
@@ -77,11 +156,12 @@ func DefaultServerOptions() *ServerOptions {
 		MaxConnectionIdle: time.Minute * 15,
 		Timeout:           time.Second * 30,
 		AuthFunc:          nil,
+		EnableReflection:  true,
 	}
 }
 
 // NewServer creates a new gRPC server
-func NewServer(logger *observability.Logger, options *ServerOptions, metricsInterceptor *MetricsInterceptor, tracingInterceptor *TracingInterceptor) (*Server, error) {
+func NewServer(logger *observability.Logger, options *ServerOptions, metricsInterceptor *MetricsInterceptor, tracingInterceptor *TracingInterceptor, errorInterceptor *ErrorInterceptor, concurrencyLimitInterceptor *ConcurrencyLimitInterceptor) (*Server, error) {
 	if options == nil {
 		options = DefaultServerOptions()
 	}
@@ -89,38 +169,51 @@ func NewServer(logger *observability.Logger, options *ServerOptions, metricsInte
 	// Create server options
 	var serverOptions []grpc.ServerOption
 
-	// Add keepalive parameters
+	// Add keepalive parameters, so that long-lived streams survive idle proxies and dead
+	// clients are detected
 	serverOptions = append(serverOptions, grpc.KeepaliveParams(keepalive.ServerParameters{
-		MaxConnectionAge:  options.MaxConnectionAge,
-		MaxConnectionIdle: options.MaxConnectionIdle,
+		MaxConnectionAge:      options.MaxConnectionAge,
+		MaxConnectionIdle:     options.MaxConnectionIdle,
+		MaxConnectionAgeGrace: options.MaxConnectionAgeGrace,
+		Time:                  options.KeepaliveTime,
+		Timeout:               options.KeepaliveTimeout,
+	}))
+	serverOptions = append(serverOptions, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             options.KeepaliveMinTime,
+		PermitWithoutStream: options.KeepalivePermitWithoutStream,
 	}))
 
-	// Add interceptors
-	serverOptions = append(serverOptions, grpc.UnaryInterceptor(
-		grpc_middleware.ChainUnaryServer(
-			grpc_ctxtags.UnaryServerInterceptor(),
-			grpc_zap.UnaryServerInterceptor(logger.Logger),
-			grpc_validator.UnaryServerInterceptor(),
-			grpc_recovery.UnaryServerInterceptor(
-				grpc_recovery.WithRecoveryHandler(recoveryHandler(logger)),
-			),
-			metricsInterceptor.Unary(),
-			tracingInterceptor.Unary(),
-			timeoutInterceptor(options.Timeout),
-		),
-	))
-
-	// Add auth interceptor if provided
-	if options.AuthFunc != nil {
-		serverOptions = append(serverOptions, grpc.UnaryInterceptor(
-			grpc_middleware.ChainUnaryServer(
-				grpc_auth.UnaryServerInterceptor(options.AuthFunc),
-			),
-		))
+	// Bound the size of messages
+	if options.MaxRecvMsgSize > 0 {
+		serverOptions = append(serverOptions, grpc.MaxRecvMsgSize(options.MaxRecvMsgSize))
 	}
+	if options.MaxSendMsgSize > 0 {
+		serverOptions = append(serverOptions, grpc.MaxSendMsgSize(options.MaxSendMsgSize))
+	}
+
+	// Add interceptors. The error interceptor sits inside metrics and tracing so
+	// they observe the converted status codes, and inside the concurrency limit so
+	// it sees them too. Custom interceptors run inside the error interceptor, so the
+	// framework errors they return are converted, measured and traced like those of
+	// the handlers; they see the errors of the handlers before their conversion.
+	chain := NewInterceptorChain().
+		WithContext().
+		WithLogging(logger).
+		WithValidation().
+		WithRecovery(logger).
+		WithAuth(options.AuthFunc).
+		WithMetrics(metricsInterceptor).
+		WithTracing(tracingInterceptor).
+		WithConcurrencyLimit(concurrencyLimitInterceptor).
+		WithErrors(errorInterceptor).
+		WithCustom(options.Interceptors...).
+		WithTimeouts(options.Timeout, options.StreamTimeout)
+	serverOptions = append(serverOptions, chain.ServerOptions()...)
 
 	// Add TLS if configured
-	if options.TLSCertFile != "" && options.TLSKeyFile != "" {
+	if options.Certificates != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(options.Certificates.TLSConfig())))
+	} else if options.TLSCertFile != "" && options.TLSKeyFile != "" {
 		creds, err := credentials.NewServerTLSFromFile(options.TLSCertFile, options.TLSKeyFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
@@ -131,40 +224,82 @@ func NewServer(logger *observability.Logger, options *ServerOptions, metricsInte
 	// Create gRPC server
 	server := grpc.NewServer(serverOptions...)
 
-	// Create listener
-	addr := fmt.Sprintf("%s:%d", options.Host, options.Port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
-	}
-
 	// Register health service
 	healthServer := health.NewServer()
 	healthpb.RegisterHealthServer(server, healthServer)
 
-	// Enable reflection
-	reflection.Register(server)
+	// Enable reflection if configured
+	if options.EnableReflection {
+		reflection.Register(server)
+	}
 
 	return &Server{
-		server:   server,
-		listener: listener,
-		logger:   logger,
-		options:  options,
+		server:  server,
+		logger:  logger,
+		options: options,
 	}, nil
 }
 
-// Start starts the gRPC server
+// Listen binds the address of the server, so that a taken port fails before serving and Addr
+// is known; port 0 picks a free port
+func (s *Server) Listen() error {
+	if s.listener != nil {
+		return nil
+	}
+	addr := net.JoinHostPort(s.options.Host, fmt.Sprint(s.options.Port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = listener
+	return nil
+}
+
+// Start starts the gRPC server, binding its address first unless Listen was called
 func (s *Server) Start() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
 	s.logger.Info("Starting gRPC server", zap.String("address", s.listener.Addr().String()))
 	return s.server.Serve(s.listener)
 }
 
-// Stop stops the gRPC server
+// Stop stops the gRPC server, waiting for pending calls to finish
 func (s *Server) Stop() {
 	s.logger.Info("Stopping gRPC server")
 	s.server.GracefulStop()
 }
 
+// Shutdown stops the gRPC server gracefully, closing the connections still open when ctx ends
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+// Addr returns the address the server listens on once it has started, or nil before
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Certificates returns the reloaded TLS certificates, or nil when they are not used
+func (s *Server) Certificates() *tlscert.Reloader {
+	return s.options.Certificates
+}
+
 // GetServer returns the underlying gRPC server
 func (s *Server) GetServer() *grpc.Server {
 	return s.server
@@ -184,9 +319,11 @@ func (s *Server) SetServingStatus(service string, status healthpb.HealthCheckRes
 }
 
 // recoveryHandler handles panics in gRPC handlers
-func recoveryHandler(logger *observability.Logger) grpc_recovery.RecoveryHandlerFunc {
-	return func(p interface{}) error {
+func recoveryHandler(logger *observability.Logger) grpc_recovery.RecoveryHandlerFuncContext {
+	return func(ctx context.Context, p interface{}) error {
 		logger.Error("Recovered from panic in gRPC handler", zap.Any("panic", p))
+		method, _ := grpc.Method(ctx)
+		errorreport.CapturePanic(ctx, p, errorreport.Tags{"grpc.method": method})
 		return status.Errorf(codes.Internal, "internal server error")
 	}
 }
@@ -214,3 +351,19 @@ func timeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
 		}
 	}
 }
+
+// streamTimeoutInterceptor bounds the context of streams when timeout is positive. Unlike
+// unary calls, streams cannot be abandoned while their handler runs, so handlers must return
+// once the stream context is done.
+func streamTimeoutInterceptor(timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if timeout <= 0 {
+			return handler(srv, stream)
+		}
+		ctx, cancel := context.WithTimeout(stream.Context(), timeout)
+		defer cancel()
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
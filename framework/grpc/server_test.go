@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// countService is a server-streaming service sending the numbers up to the requested count
+var countService = grpc.ServiceDesc{
+	ServiceName: "test.Counter",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Count",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			req := new(wrapperspb.Int32Value)
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			switch req.Value {
+			case -1:
+				panic("negative count")
+			case 0:
+				// Wait for the stream timeout
+				<-stream.Context().Done()
+				return stream.Context().Err()
+			}
+			for i := int32(1); i <= req.Value; i++ {
+				if err := stream.SendMsg(wrapperspb.Int32(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}},
+}
+
+func startStreamServer(t *testing.T, options *ServerOptions) (*grpc.ClientConn, *observability.Metrics) {
+	t.Helper()
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+
+	options.Host = "127.0.0.1"
+	server, err := NewServer(logger, options, NewMetricsInterceptor(metrics),
+		NewTracingInterceptor(&observability.Tracer{Tracer: noop.NewTracerProvider().Tracer("test")}),
+		NewErrorInterceptor(logger), NewConcurrencyLimitInterceptor(cfg, logger))
+	require.NoError(t, err)
+	server.RegisterService(&countService, struct{}{})
+	require.NoError(t, server.Listen())
+	go func() { _ = server.Start() }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, metrics
+}
+
+// count calls the Count stream, returning the numbers received
+func count(ctx context.Context, conn *grpc.ClientConn, n int32) ([]int32, error) {
+	stream, err := conn.NewStream(ctx, &countService.Streams[0], "/test.Counter/Count")
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(wrapperspb.Int32(n)); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	var numbers []int32
+	for {
+		msg := new(wrapperspb.Int32Value)
+		if err := stream.RecvMsg(msg); err == io.EOF {
+			return numbers, nil
+		} else if err != nil {
+			return numbers, err
+		}
+		numbers = append(numbers, msg.Value)
+	}
+}
+
+func TestServerStreams(t *testing.T) {
+	options := DefaultServerOptions()
+	options.Port = 0
+	options.StreamTimeout = 200 * time.Millisecond
+	options.AuthFunc = func(ctx context.Context) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if len(md.Get("authorization")) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing token")
+		}
+		return ctx, nil
+	}
+	conn, metrics := startStreamServer(t, options)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	t.Run("streams messages", func(t *testing.T) {
+		numbers, err := count(ctx, conn, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2, 3}, numbers)
+		assert.Equal(t, 3.0, testutil.ToFloat64(metrics.GRPCStreamMessagesTotal.WithLabelValues("test.Counter", "Count", "sent")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.GRPCStreamMessagesTotal.WithLabelValues("test.Counter", "Count", "received")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.GRPCRequestsTotal.WithLabelValues("test.Counter", "Count", "OK")))
+	})
+
+	t.Run("authenticates streams", func(t *testing.T) {
+		_, err := count(context.Background(), conn, 3)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("recovers panics", func(t *testing.T) {
+		_, err := count(ctx, conn, -1)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("bounds streams", func(t *testing.T) {
+		start := time.Now()
+		_, err := count(ctx, conn, 0)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestServerUnaryAuth(t *testing.T) {
+	options := DefaultServerOptions()
+	options.Port = 0
+	options.AuthFunc = func(ctx context.Context) (context.Context, error) {
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+	// AuthFunc guards unary calls as well as streams
+	conn, _ := startStreamServer(t, options)
+	err := conn.Invoke(context.Background(), "/grpc.health.v1.Health/Check", wrapperspb.String(""), new(wrapperspb.StringValue))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestNewServerOptions(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{GRPC: config.GRPCConfig{
+		Port:             9090,
+		Timeout:          5,
+		StreamTimeout:    600,
+		EnableReflection: true,
+		MaxRecvMsgSize:   1 << 20,
+		Keepalive: config.GRPCKeepaliveConfig{
+			Time:                60,
+			MinTime:             30,
+			PermitWithoutStream: true,
+			MaxConnectionAge:    1800,
+		},
+	}}
+	options, err := NewServerOptions(cfg, logger, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, options.Timeout)
+	assert.Equal(t, 10*time.Minute, options.StreamTimeout)
+	assert.True(t, options.EnableReflection)
+	assert.Equal(t, 1<<20, options.MaxRecvMsgSize)
+	assert.Zero(t, options.MaxSendMsgSize)
+	assert.Equal(t, time.Minute, options.KeepaliveTime)
+	assert.Equal(t, 30*time.Second, options.KeepaliveMinTime)
+	assert.True(t, options.KeepalivePermitWithoutStream)
+	assert.Equal(t, 30*time.Minute, options.MaxConnectionAge)
+	assert.Equal(t, 15*time.Minute, options.MaxConnectionIdle, "defaults apply to unset values")
+
+	cfg.GRPC.MaxSendMsgSize = -1
+	_, err = NewServerOptions(cfg, logger, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestServerOptionToggles(t *testing.T) {
+	options := DefaultServerOptions()
+	options.Port = 0
+	options.EnableReflection = false
+	options.MaxRecvMsgSize = 16
+	conn, _ := startStreamServer(t, options)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "reflection is not served")
+
+	numbers, err := count(context.Background(), conn, 2)
+	require.NoError(t, err, "small messages are accepted")
+	assert.Equal(t, []int32{1, 2}, numbers)
+
+	large, err := conn.NewStream(context.Background(), &countService.Streams[0], "/test.Counter/Count")
+	require.NoError(t, err)
+	require.NoError(t, large.SendMsg(wrapperspb.String(strings.Repeat("x", 64))))
+	require.NoError(t, large.CloseSend())
+	err = large.RecvMsg(new(wrapperspb.Int32Value))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "larger messages are rejected")
+}
@@ -63,6 +63,82 @@ func (i *TracingInterceptor) Unary() grpc.UnaryServerInterceptor {
 	}
 }
 
+// Stream returns a gRPC stream interceptor. The span covers the whole stream and records
+// an event per message sent and received.
+func (i *TracingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := ss.Context()
+		md, ok := metadata.FromIncomingContext(ctx)
+		if ok {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		}
+
+		service, method := parseFullMethod(info.FullMethod)
+		ctx, span := i.tracer.Tracer.Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+			attribute.Bool("rpc.grpc.client_stream", info.IsClientStream),
+			attribute.Bool("rpc.grpc.server_stream", info.IsServerStream),
+		)
+
+		wrapped := &tracedStream{ServerStream: ss, ctx: ctx, span: span}
+		err := handler(srv, wrapped)
+
+		st, _ := status.FromError(err)
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", st.Code().String()))
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return err
+	}
+}
+
+// tracedStream carries the span of a stream in its context and records its messages
+type tracedStream struct {
+	grpc.ServerStream
+	ctx            context.Context
+	span           trace.Span
+	sent, received int
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *tracedStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+		s.span.AddEvent("message", trace.WithAttributes(
+			attribute.String("message.type", "SENT"),
+			attribute.Int("message.id", s.sent),
+		))
+	}
+	return err
+}
+
+func (s *tracedStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+		s.span.AddEvent("message", trace.WithAttributes(
+			attribute.String("message.type", "RECEIVED"),
+			attribute.Int("message.id", s.received),
+		))
+	}
+	return err
+}
+
 type metadataCarrier metadata.MD
 
 func (m metadataCarrier) Get(key string) string {
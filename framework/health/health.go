@@ -136,12 +136,40 @@ func (h *Health) GetResponse() Response {
 
 // Handler returns an HTTP handler for health checks
 func (h *Health) Handler() http.HandlerFunc {
+	return h.handler(true)
+}
+
+// StatusHandler returns an HTTP handler for health checks that reports the overall
+// status without component details, for callers not allowed to see them
+func (h *Health) StatusHandler() http.HandlerFunc {
+	return h.handler(false)
+}
+
+// LiveHandler returns an HTTP handler for liveness probes. It does not run any checks,
+// so it stays cheap to call and cannot be used to load the checked dependencies.
+func (h *Health) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(Response{Status: StatusUp, Timestamp: time.Now()}); err != nil {
+			h.logger.Error("Failed to encode health check response", zap.Error(err))
+		}
+	}
+}
+
+func (h *Health) handler(verbose bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Run health checks
 		h.RunChecks()
 
 		// Get response
 		response := h.GetResponse()
+		if !verbose {
+			response.Components = nil
+		}
+
+		// Set content type
+		w.Header().Set("Content-Type", "application/json")
 
 		// Set status code
 		if response.Status == StatusDown {
@@ -150,9 +178,6 @@ func (h *Health) Handler() http.HandlerFunc {
 			w.WriteHeader(http.StatusOK)
 		}
 
-		// Set content type
-		w.Header().Set("Content-Type", "application/json")
-
 		// Write response
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode health check response", zap.Error(err))
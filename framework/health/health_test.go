@@ -45,6 +45,31 @@ func TestHealth(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "DOWN")
 	})
 
+	t.Run("Status Handler Hides Components", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.StatusHandler()(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "DOWN")
+		assert.NotContains(t, w.Body.String(), "redis down")
+	})
+
+	t.Run("Live Handler Runs No Checks", func(t *testing.T) {
+		called := false
+		h.RegisterCheck("expensive", func() error {
+			called = true
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		h.LiveHandler()(w, httptest.NewRequest(http.MethodGet, "/live", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "UP")
+		assert.False(t, called)
+	})
+
 	t.Run("Background Checks", func(t *testing.T) {
 		h.RegisterCheck("bg", func() error { return nil })
 		// Just verify it doesn't panic and can be stopped
@@ -0,0 +1,200 @@
+// Package httpquery reads the listing parameters of List endpoints from query strings
+// following the JSON:API conventions: filter[status]=open or filter[total][gte]=100 for
+// filters, sort=-created_at,name for the sort order, and page[size], page[number] or
+// page[cursor] for the page. They are parsed into query.Params, checked against the
+// query.Schema of the endpoint, which lists the fields clients may filter and sort on.
+package httpquery
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/query"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Query parameters of the conventions
+const (
+	SortParam       = "sort"
+	FilterParam     = "filter"
+	PageSizeParam   = "page[size]"
+	PageNumberParam = "page[number]"
+	PageCursorParam = "page[cursor]"
+)
+
+// Options are the conventions of an endpoint beyond its schema
+type Options struct {
+	// DefaultSort orders the listings of requests without sort
+	DefaultSort []query.Sort
+	// Paginator sizes the pages and verifies page[cursor]. Without one, pages have the
+	// default sizes of the pagination package and cursors are rejected.
+	Paginator *pagination.Paginator
+	// MaxPageSize caps page[size] below the maximum of the paginator; 0 leaves it
+	MaxPageSize int
+}
+
+// Parser reads the listings of an endpoint from query strings
+type Parser struct {
+	schema *query.Schema
+	opts   Options
+}
+
+// NewParser creates a parser of the listings of schema
+func NewParser(schema *query.Schema, opts Options) *Parser {
+	return &Parser{schema: schema, opts: opts}
+}
+
+// FromFiber reads the listing of a request, see Parse
+func (p *Parser) FromFiber(c *fiber.Ctx) (query.Params, error) {
+	values := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		values.Add(string(key), string(value))
+	})
+	return p.Parse(values)
+}
+
+// Parse reads the filters, sort order and page of a listing from query parameters.
+// Filters and sorts on fields the schema does not allow, unknown operators and malformed
+// parameters are rejected with framework errors with an INVALID_INPUT code, wrapping
+// query.ErrInvalidFilter, query.ErrInvalidSort or query.ErrInvalidCursor. Parameters
+// outside of the conventions are ignored.
+//
+// page[cursor] takes the next_page_token of the previous page, signed by the paginator
+// for the same filters and sort order with query.ListResult.Sign and Params.Fingerprint.
+func (p *Parser) Parse(values url.Values) (query.Params, error) {
+	var params query.Params
+	page := make(map[string]string)
+	for key, vals := range values {
+		switch {
+		case key == SortParam:
+			for _, v := range vals {
+				params.Sort = append(params.Sort, query.ParseSort(v)...)
+			}
+		case strings.HasPrefix(key, FilterParam+"["):
+			field, op, err := filterKey(key)
+			if err != nil {
+				return query.Params{}, err
+			}
+			for _, v := range vals {
+				params.Filter = params.Filter.Where(field, op, v)
+			}
+		case strings.HasPrefix(key, "page["):
+			if key != PageSizeParam && key != PageNumberParam && key != PageCursorParam {
+				return query.Params{}, invalid(query.ErrInvalidCursor, "unknown page parameter %q", key)
+			}
+			page[key] = vals[0]
+		}
+	}
+	// Map iteration is random: order conditions so that fingerprints are stable
+	slices.SortStableFunc(params.Filter, func(a, b query.Condition) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	params, err := p.schema.Check(params)
+	if err != nil {
+		return query.Params{}, err
+	}
+	if len(params.Sort) == 0 {
+		params.Sort = p.opts.DefaultSort
+	}
+	params.Page, err = p.page(page, params.Fingerprint())
+	if err != nil {
+		return query.Params{}, err
+	}
+	return params, nil
+}
+
+// page resolves the page parameters of a listing with the given fingerprint
+func (p *Parser) page(values map[string]string, fingerprint string) (query.Page, error) {
+	requested := 0
+	if size, ok := values[PageSizeParam]; ok {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 0 {
+			return query.Page{}, invalid(query.ErrInvalidCursor, "%s must be a positive integer", PageSizeParam)
+		}
+		requested = n
+	}
+	size := p.pageSize(requested)
+
+	number, hasNumber := values[PageNumberParam]
+	token, hasCursor := values[PageCursorParam]
+	switch {
+	case hasNumber && hasCursor:
+		return query.Page{}, invalid(query.ErrInvalidCursor, "%s and %s cannot be combined", PageNumberParam, PageCursorParam)
+	case hasNumber:
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 {
+			return query.Page{}, invalid(query.ErrInvalidCursor, "%s must be a positive integer", PageNumberParam)
+		}
+		if n-1 > math.MaxInt/size {
+			return query.Page{}, invalid(query.ErrInvalidCursor, "%s is too large", PageNumberParam)
+		}
+		return query.Page{Limit: size, Offset: (n - 1) * size}, nil
+	case hasCursor:
+		if p.opts.Paginator == nil {
+			return query.Page{}, invalid(query.ErrInvalidCursor, "%s is not supported", PageCursorParam)
+		}
+		cursor, err := p.opts.Paginator.Decode(token)
+		if err != nil {
+			return query.Page{}, invalid(err, "invalid %s", PageCursorParam)
+		}
+		if cursor.Query != fingerprint {
+			return query.Page{}, invalid(pagination.ErrInvalidPageToken, "%s was issued for a different query", PageCursorParam)
+		}
+		return query.Page{Limit: size, Offset: cursor.Offset, After: cursor.After}, nil
+	}
+	return query.Page{Limit: size}, nil
+}
+
+// pageSize returns the size of a page for a requested one: the default when none is
+// requested, capped at the maximum
+func (p *Parser) pageSize(requested int) int {
+	var size int
+	if p.opts.Paginator != nil {
+		size = p.opts.Paginator.PageSize(requested)
+	} else {
+		size = requested
+		if size <= 0 {
+			size = pagination.DefaultPageSize
+		}
+		size = min(size, pagination.MaxPageSize)
+	}
+	if p.opts.MaxPageSize > 0 {
+		size = min(size, p.opts.MaxPageSize)
+	}
+	return size
+}
+
+// filterKey returns the field and operator of a filter parameter: filter[field] for
+// equality, or filter[field][op]
+func filterKey(key string) (string, query.Operator, error) {
+	rest := strings.TrimPrefix(key, FilterParam)
+	var parts []string
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 2 {
+			return "", "", invalid(query.ErrInvalidFilter, "malformed filter parameter %q", key)
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+	switch len(parts) {
+	case 1:
+		return parts[0], query.Eq, nil
+	case 2:
+		return parts[0], query.Operator(parts[1]), nil
+	}
+	return "", "", invalid(query.ErrInvalidFilter, "malformed filter parameter %q", key)
+}
+
+// invalid wraps err in a framework error with an INVALID_INPUT code
+func invalid(err error, format string, args ...any) error {
+	return errors.NewInvalidInput(err, fmt.Sprintf(format, args...))
+}
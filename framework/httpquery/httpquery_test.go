@@ -0,0 +1,172 @@
+package httpquery
+
+import (
+	stderrors "errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var orderSchema = query.NewSchema("id", map[string]query.Field{
+	"status":     {Filter: query.Text, Sortable: true},
+	"total":      {Filter: query.Comparable, Sortable: true},
+	"created_at": {Filter: query.Comparable, Sortable: true},
+	"note":       {},
+})
+
+func TestParse(t *testing.T) {
+	parser := NewParser(orderSchema, Options{MaxPageSize: 100})
+
+	params, err := parser.Parse(url.Values{
+		"filter[status][in]": {"open,pending"},
+		"filter[total][gte]": {"10"},
+		"filter[id]":         {"o-1"},
+		"sort":               {"-created_at,id"},
+		"page[size]":         {"20"},
+		"page[number]":       {"3"},
+		"include":            {"customer"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, query.Filter{
+		{Field: "id", Op: query.Eq, Value: "o-1"},
+		{Field: "status", Op: query.In, Value: []string{"open", "pending"}},
+		{Field: "total", Op: query.Gte, Value: "10"},
+	}, params.Filter)
+	assert.Equal(t, []query.Sort{{Field: "created_at", Desc: true}, {Field: "id"}}, params.Sort)
+	assert.Equal(t, query.Page{Limit: 20, Offset: 40}, params.Page)
+}
+
+func TestParsePageSize(t *testing.T) {
+	paginator, err := pagination.NewPaginator(config.PaginationConfig{Secret: "secret", DefaultPageSize: 25, MaxPageSize: 200})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts Options
+		size string
+		want int
+	}{
+		{"default", Options{}, "", pagination.DefaultPageSize},
+		{"requested", Options{}, "10", 10},
+		{"capped", Options{}, "5000", pagination.MaxPageSize},
+		{"endpoint maximum", Options{MaxPageSize: 100}, "500", 100},
+		{"paginator default", Options{Paginator: paginator}, "", 25},
+		{"paginator maximum", Options{Paginator: paginator}, "500", 200},
+		{"endpoint below paginator", Options{Paginator: paginator, MaxPageSize: 20}, "", 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := url.Values{}
+			if tt.size != "" {
+				values.Set(PageSizeParam, tt.size)
+			}
+			params, err := NewParser(orderSchema, tt.opts).Parse(values)
+			require.NoError(t, err)
+			assert.Equal(t, query.Page{Limit: tt.want}, params.Page)
+		})
+	}
+}
+
+func TestParseDefaultSort(t *testing.T) {
+	parser := NewParser(orderSchema, Options{DefaultSort: []query.Sort{{Field: "created_at", Desc: true}}})
+
+	params, err := parser.Parse(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, []query.Sort{{Field: "created_at", Desc: true}}, params.Sort)
+
+	params, err = parser.Parse(url.Values{"sort": {"total"}})
+	require.NoError(t, err)
+	assert.Equal(t, []query.Sort{{Field: "total"}}, params.Sort)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   error
+	}{
+		{"unknown filter field", url.Values{"filter[customer]": {"c-1"}}, query.ErrInvalidFilter},
+		{"field without filters", url.Values{"filter[note]": {"rush"}}, query.ErrInvalidFilter},
+		{"unknown operator", url.Values{"filter[status][gt]": {"open"}}, query.ErrInvalidFilter},
+		{"malformed filter", url.Values{"filter[status": {"open"}}, query.ErrInvalidFilter},
+		{"empty filter field", url.Values{"filter[]": {"open"}}, query.ErrInvalidFilter},
+		{"nested filter", url.Values{"filter[total][gt][x]": {"1"}}, query.ErrInvalidFilter},
+		{"unsortable field", url.Values{"sort": {"-note"}}, query.ErrInvalidSort},
+		{"unknown sort field", url.Values{"sort": {"customer"}}, query.ErrInvalidSort},
+		{"unknown page parameter", url.Values{"page[offset]": {"10"}}, query.ErrInvalidCursor},
+		{"invalid page size", url.Values{"page[size]": {"ten"}}, query.ErrInvalidCursor},
+		{"negative page size", url.Values{"page[size]": {"-1"}}, query.ErrInvalidCursor},
+		{"page number zero", url.Values{"page[number]": {"0"}}, query.ErrInvalidCursor},
+		{"page number overflow", url.Values{"page[number]": {"9223372036854775807"}}, query.ErrInvalidCursor},
+		{"number and cursor", url.Values{"page[number]": {"2"}, "page[cursor]": {"abc"}}, query.ErrInvalidCursor},
+		{"cursor without paginator", url.Values{"page[cursor]": {"abc"}}, query.ErrInvalidCursor},
+	}
+	parser := NewParser(orderSchema, Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.values)
+			require.Error(t, err)
+			assert.True(t, stderrors.Is(err, tt.want), err.Error())
+			assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+		})
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	paginator, err := pagination.NewPaginator(config.PaginationConfig{Secret: "secret"})
+	require.NoError(t, err)
+	parser := NewParser(orderSchema, Options{Paginator: paginator})
+
+	values := url.Values{"filter[status]": {"open"}, "sort": {"-total"}, "page[size]": {"2"}}
+	params, err := parser.Parse(values)
+	require.NoError(t, err)
+	items := []string{"o-1", "o-2", "o-3"}
+	result := query.NewListResult(orderSchema, items, 5, params, func(id string) string { return id })
+	require.NoError(t, result.Sign(paginator, params.Fingerprint()))
+	require.NotEmpty(t, result.NextPageToken)
+
+	values.Set(PageCursorParam, result.NextPageToken)
+	next, err := parser.Parse(values)
+	require.NoError(t, err)
+	assert.Equal(t, query.Page{Limit: 2, Offset: 2}, next.Page)
+
+	values.Set("filter[status]", "closed")
+	_, err = parser.Parse(values)
+	require.Error(t, err)
+	assert.True(t, stderrors.Is(err, pagination.ErrInvalidPageToken), "tokens are bound to their filters")
+	assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+
+	_, err = parser.Parse(url.Values{"page[cursor]": {"forged"}})
+	require.Error(t, err)
+	assert.True(t, stderrors.Is(err, pagination.ErrInvalidPageToken))
+}
+
+func TestFromFiber(t *testing.T) {
+	parser := NewParser(orderSchema, Options{})
+	app := fiber.New()
+	var params query.Params
+	app.Get("/orders", func(c *fiber.Ctx) error {
+		var err error
+		params, err = parser.FromFiber(c)
+		return err
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders?filter%5Bstatus%5D=open&filter%5Btotal%5D%5Blt%5D=5&sort=-total&page%5Bsize%5D=2", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, query.Filter{
+		{Field: "status", Op: query.Eq, Value: "open"},
+		{Field: "total", Op: query.Lt, Value: "5"},
+	}, params.Filter)
+	assert.Equal(t, []query.Sort{{Field: "total", Desc: true}}, params.Sort)
+	assert.Equal(t, query.Page{Limit: 2}, params.Page)
+}
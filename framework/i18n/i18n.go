@@ -0,0 +1,322 @@
+// Package i18n localizes messages, such as the messages of API errors, for the locale a
+// caller prefers. Catalogs hold the messages of each locale, loaded from message files
+// embedded in the binary or read from a directory. Locales are negotiated from the
+// Accept-Language header, and missing messages fall back to the parent locales, then to
+// the default locale. Messages may have a form for each plural category of their language.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is the default locale of catalogs when none is configured
+const DefaultLocale = "en"
+
+// CountArg is the argument choosing the plural form of messages
+const CountArg = "count"
+
+// frameworkMessages are the messages of the framework, such as the titles of HTTP statuses
+//
+//go:embed locales
+var frameworkMessages embed.FS
+
+// Message is a message template with a form for each plural category its language uses, or
+// only the Other form when it does not depend on a count. Templates reference arguments as
+// {name}, e.g. "Orders may hold at most {count} items".
+type Message map[string]string
+
+// Catalog holds the messages of each locale
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale language.Tag
+	messages      map[string]map[string]Message // by locale, then key
+	tags          []language.Tag                // supported locales, the default first
+	matcher       language.Matcher
+}
+
+// NewCatalog creates a catalog holding the messages of the framework. defaultLocale, "en"
+// when empty, is used when callers accept none of the locales of the catalog.
+func NewCatalog(defaultLocale string) (*Catalog, error) {
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+	tag, err := language.Parse(defaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("i18n: invalid default locale %q: %w", defaultLocale, err)
+	}
+	c := &Catalog{
+		defaultLocale: tag,
+		messages:      make(map[string]map[string]Message),
+	}
+	if err := c.LoadFS(frameworkMessages, "locales"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Add adds messages to a locale, replacing messages with the same keys
+func (c *Catalog) Add(locale string, messages map[string]Message) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return fmt.Errorf("i18n: invalid locale %q: %w", locale, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := tag.String()
+	if c.messages[key] == nil {
+		c.messages[key] = make(map[string]Message, len(messages))
+	}
+	for name, message := range messages {
+		c.messages[key][name] = message
+	}
+	c.buildMatcher()
+	return nil
+}
+
+// buildMatcher updates the locales negotiated by the catalog
+func (c *Catalog) buildMatcher() {
+	c.tags = []language.Tag{c.defaultLocale}
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		if locale != c.defaultLocale.String() {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		c.tags = append(c.tags, language.Make(locale))
+	}
+	c.matcher = language.NewMatcher(c.tags)
+}
+
+// LoadFS adds the message files of dir in fsys, such as a directory embedded with go:embed.
+// Files are named after their locale, e.g. de-CH.yaml, and hold YAML or JSON mapping keys
+// to messages. Nested keys are joined with dots, and a mapping of plural categories, such as
+// {one: "...", other: "..."}, is a message with plural forms.
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: failed to read messages: %w", err)
+	}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		file := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("i18n: failed to read %s: %w", file, err)
+		}
+		messages, err := ParseMessages(data)
+		if err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+		if err := c.Add(strings.TrimSuffix(entry.Name(), ext), messages); err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// ParseMessages parses a YAML or JSON message file
+func ParseMessages(data []byte) (map[string]Message, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	messages := make(map[string]Message)
+	if len(doc.Content) == 0 {
+		return messages, nil
+	}
+	if err := flatten("", doc.Content[0], messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func flatten(prefix string, node *yaml.Node, messages map[string]Message) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if prefix == "" {
+			return fmt.Errorf("line %d: expected a mapping of keys to messages", node.Line)
+		}
+		messages[prefix] = Message{Other: node.Value}
+		return nil
+	case yaml.MappingNode:
+		if prefix != "" && isPluralForms(node) {
+			message := make(Message, len(node.Content)/2)
+			for i := 0; i < len(node.Content); i += 2 {
+				message[node.Content[i].Value] = node.Content[i+1].Value
+			}
+			messages[prefix] = message
+			return nil
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flatten(key, node.Content[i+1], messages); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("line %d: messages must be strings or mappings", node.Line)
+}
+
+// isPluralForms reports whether a mapping holds the plural forms of a message
+func isPluralForms(node *yaml.Node) bool {
+	hasOther := false
+	for i := 0; i < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case Zero, One, Two, Few, Many:
+		case Other:
+			hasOther = true
+		default:
+			return false
+		}
+		if node.Content[i+1].Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return hasOther
+}
+
+// Locales returns the locales of the catalog, the default first
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.tags))
+	for _, tag := range c.tags {
+		locales = append(locales, tag.String())
+	}
+	return locales
+}
+
+// Negotiate returns the localizer of the catalog locale best matching an Accept-Language
+// header, or of the default locale when the caller accepts none of them
+func (c *Catalog) Negotiate(acceptLanguage string) *Localizer {
+	c.mu.RLock()
+	tag := c.defaultLocale
+	if desired, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(desired) > 0 {
+		if _, index, confidence := c.matcher.Match(desired...); confidence != language.No {
+			tag = c.tags[index]
+		}
+	}
+	c.mu.RUnlock()
+	return c.localizer(tag)
+}
+
+// Localizer returns the localizer of a locale; invalid locales get the default locale
+func (c *Catalog) Localizer(locale string) *Localizer {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		tag = c.defaultLocale
+	}
+	return c.localizer(tag)
+}
+
+// localizer returns the localizer of tag, which falls back to its parents, then to the
+// default locale and its parents
+func (c *Catalog) localizer(tag language.Tag) *Localizer {
+	l := &Localizer{catalog: c, locale: tag.String()}
+	seen := make(map[string]bool)
+	for _, start := range []language.Tag{tag, c.defaultLocale} {
+		for t := start; t != language.Und; t = t.Parent() {
+			if locale := t.String(); !seen[locale] {
+				seen[locale] = true
+				l.fallbacks = append(l.fallbacks, locale)
+			}
+		}
+	}
+	return l
+}
+
+// Localizer translates messages for a locale
+type Localizer struct {
+	catalog   *Catalog
+	locale    string
+	fallbacks []string // the locales messages are looked up in, in order
+}
+
+// Locale returns the locale of the localizer, such as "de-CH"
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// Translate returns the message of key with its arguments filled in, or false when no
+// locale of the fallback chain has it. The CountArg argument chooses the plural form.
+func (l *Localizer) Translate(key string, args map[string]interface{}) (string, bool) {
+	l.catalog.mu.RLock()
+	defer l.catalog.mu.RUnlock()
+
+	for _, locale := range l.fallbacks {
+		message, ok := l.catalog.messages[locale][key]
+		if !ok {
+			continue
+		}
+		text := message[Other]
+		if n, ok := count(args[CountArg]); ok {
+			if form, ok := message[PluralCategory(locale, n)]; ok {
+				text = form
+			}
+		}
+		return format(text, args), true
+	}
+	return "", false
+}
+
+// format replaces the {name} references of a template by the arguments; unknown references
+// are kept
+func format(template string, args map[string]interface{}) string {
+	if len(args) == 0 || !strings.Contains(template, "{") {
+		return template
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(template[:start])
+		if value, ok := args[template[start+1:end]]; ok {
+			fmt.Fprint(&b, value)
+		} else {
+			b.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+type localizerKey struct{}
+
+// NewContext returns a context carrying the localizer of a request
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the localizer of ctx, or nil when it has none
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(localizerKey{}).(*Localizer)
+	return l
+}
@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	catalog, err := NewCatalog("en")
+	require.NoError(t, err)
+	require.NoError(t, catalog.LoadFS(fstest.MapFS{
+		"locales/en.yaml": {Data: []byte(`
+errors:
+  ORDERS_ITEMS_LIMIT:
+    one: "An order may hold one item"
+    other: "An order may hold {count} items"
+  ORDERS_CLOSED: "Order {order_id} is closed"
+`)},
+		"locales/de.json":    {Data: []byte(`{"errors": {"ORDERS_ITEMS_LIMIT": {"one": "Eine Bestellung darf einen Artikel enthalten", "other": "Eine Bestellung darf {count} Artikel enthalten"}}}`)},
+		"locales/de-CH.yaml": {Data: []byte(`status: {404: "Nöd gfunde"}`)},
+		"locales/ru.yaml": {Data: []byte(`
+errors:
+  ORDERS_ITEMS_LIMIT:
+    one: "{count} товар"
+    few: "{count} товара"
+    many: "{count} товаров"
+    other: "{count} товара"
+`)},
+		"locales/README.md": {Data: []byte("not a message file")},
+	}, "locales"))
+	return catalog
+}
+
+func TestNegotiate(t *testing.T) {
+	catalog := newTestCatalog(t)
+	assert.Equal(t, "en", catalog.Locales()[0], "the default locale comes first")
+
+	tests := []struct {
+		acceptLanguage string
+		locale         string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT, en;q=0.5", "de"},
+		{"de-CH", "de-CH"},
+		{"ja, fr;q=0.8", "fr"},
+		{"ja", "en"},
+		{"*", "en"},
+		{"not a header;;", "en"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.locale, catalog.Negotiate(tt.acceptLanguage).Locale(), tt.acceptLanguage)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	catalog := newTestCatalog(t)
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   map[string]interface{}
+		want   string
+		found  bool
+	}{
+		{"framework message", "fr", "status.404", nil, "Introuvable", true},
+		{"own locale first", "de-CH", "status.404", nil, "Nöd gfunde", true},
+		{"parent locale", "de-CH", "status.409", nil, "Konflikt", true},
+		{"default locale", "de", "errors.ORDERS_CLOSED", map[string]interface{}{"order_id": 42}, "Order 42 is closed", true},
+		{"missing", "de", "errors.UNKNOWN", nil, "", false},
+		{"singular", "en", "errors.ORDERS_ITEMS_LIMIT", map[string]interface{}{"count": 1}, "An order may hold one item", true},
+		{"plural", "de", "errors.ORDERS_ITEMS_LIMIT", map[string]interface{}{"count": int64(5)}, "Eine Bestellung darf 5 Artikel enthalten", true},
+		{"no count", "en", "errors.ORDERS_ITEMS_LIMIT", nil, "An order may hold {count} items", true},
+		{"few", "ru", "errors.ORDERS_ITEMS_LIMIT", map[string]interface{}{"count": 3}, "3 товара", true},
+		{"many", "ru", "errors.ORDERS_ITEMS_LIMIT", map[string]interface{}{"count": 11}, "11 товаров", true},
+		{"one after ten", "ru", "errors.ORDERS_ITEMS_LIMIT", map[string]interface{}{"count": 21}, "21 товар", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := catalog.Localizer(tt.locale).Translate(tt.key, tt.args)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int64
+		want   string
+	}{
+		{"en", 0, Other},
+		{"en-GB", 1, One},
+		{"en", -1, One},
+		{"fr", 0, One},
+		{"pt-BR", 1, One},
+		{"fr", 2, Other},
+		{"ja", 1, Other},
+		{"ru", 1, One},
+		{"ru", 11, Many},
+		{"ru", 22, Few},
+		{"pl", 5, Many},
+		{"pl", 22, Few},
+		{"cs", 3, Few},
+		{"cs", 5, Other},
+		{"ar", 0, Zero},
+		{"ar", 2, Two},
+		{"ar", 105, Few},
+		{"ar", 111, Many},
+		{"ar", 100, Other},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, PluralCategory(tt.locale, tt.n), "%s %d", tt.locale, tt.n)
+	}
+}
+
+func TestParseMessages(t *testing.T) {
+	messages, err := ParseMessages([]byte(`
+greeting: "Hello {name}"
+items:
+  one: "{count} item"
+  other: "{count} items"
+nested:
+  one: "not plural forms without other"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Message{
+		"greeting":   {Other: "Hello {name}"},
+		"items":      {One: "{count} item", Other: "{count} items"},
+		"nested.one": {Other: "not plural forms without other"},
+	}, messages)
+
+	for _, invalid := range []string{`"just a string"`, "list: [a, b]", "key: [unclosed"} {
+		_, err := ParseMessages([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+
+	catalog, err := NewCatalog("")
+	require.NoError(t, err)
+	assert.Error(t, catalog.LoadFS(fstest.MapFS{"de.yaml": {Data: []byte("- a")}}, "."))
+	assert.Error(t, catalog.LoadFS(fstest.MapFS{"not_a_locale!.yaml": {Data: []byte("a: b")}}, "."))
+	assert.Error(t, catalog.LoadFS(fstest.MapFS{}, "missing"))
+	_, err = NewCatalog("???")
+	assert.Error(t, err)
+}
+
+func TestFormat(t *testing.T) {
+	args := map[string]interface{}{"name": "Ada", "count": 3}
+	assert.Equal(t, "Hello Ada, 3 new", format("Hello {name}, {count} new", args))
+	assert.Equal(t, "{unknown} and {name", format("{unknown} and {name", args))
+	assert.Equal(t, "no args {name}", format("no args {name}", nil))
+}
+
+func TestContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+	localizer := newTestCatalog(t).Localizer("de")
+	assert.Same(t, localizer, FromContext(NewContext(context.Background(), localizer)))
+}
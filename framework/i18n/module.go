@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// MessagesGroup is the fx value group collecting the message catalogs of modules
+const MessagesGroup = "i18n_messages"
+
+// Module provides the message catalog
+var Module = fx.Options(
+	fx.Provide(ProvideCatalog),
+)
+
+// MessageSource is a directory of message files declared by a module
+type MessageSource struct {
+	FS  fs.FS
+	Dir string
+}
+
+// Messages declares the message files of a module, loaded into the catalog at startup:
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	i18n.Messages(locales, "locales")
+func Messages(fsys fs.FS, dir string) fx.Option {
+	source := MessageSource{FS: fsys, Dir: dir}
+	return fx.Provide(fx.Annotated{
+		Group:  MessagesGroup,
+		Target: func() MessageSource { return source },
+	})
+}
+
+// CatalogParams holds the dependencies of the catalog
+type CatalogParams struct {
+	fx.In
+
+	Config  *config.Config
+	Logger  *observability.Logger
+	Sources []MessageSource `group:"i18n_messages"`
+}
+
+// ProvideCatalog provides the catalog of the framework messages, the messages declared with
+// Messages and the message files of the configured directory, which take precedence
+func ProvideCatalog(params CatalogParams) (*Catalog, error) {
+	cfg := params.Config.I18n
+	catalog, err := NewCatalog(cfg.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range params.Sources {
+		if err := catalog.LoadFS(source.FS, source.Dir); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Directory != "" {
+		if err := catalog.LoadFS(os.DirFS(cfg.Directory), "."); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Enabled {
+		params.Logger.Info("Localizing error messages", zap.Strings("locales", catalog.Locales()))
+	}
+	return catalog, nil
+}
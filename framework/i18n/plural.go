@@ -0,0 +1,144 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Plural categories of CLDR; messages depending on a count have a form for some of them
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// PluralRule returns the plural category of a count
+type PluralRule func(n int64) string
+
+// pluralRules are the rules of languages whose plurals differ from English, by base language.
+// Counts are integers, so the rules for fractions are left out.
+var pluralRules = map[string]PluralRule{
+	"fr": frenchPlural, "pt": frenchPlural,
+	"ja": noPlural, "zh": noPlural, "ko": noPlural, "vi": noPlural, "th": noPlural, "id": noPlural, "ms": noPlural,
+	"ru": eastSlavicPlural, "uk": eastSlavicPlural, "be": eastSlavicPlural,
+	"pl": polishPlural,
+	"cs": czechPlural, "sk": czechPlural,
+	"ar": arabicPlural,
+}
+
+// PluralCategory returns the plural category of n in the language of locale
+func PluralCategory(locale string, n int64) string {
+	base, _, _ := strings.Cut(locale, "-")
+	if rule, ok := pluralRules[strings.ToLower(base)]; ok {
+		return rule(abs(n))
+	}
+	return englishPlural(abs(n))
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func englishPlural(n int64) string {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
+
+func frenchPlural(n int64) string {
+	if n <= 1 {
+		return One
+	}
+	return Other
+}
+
+func noPlural(int64) string {
+	return Other
+}
+
+func eastSlavicPlural(n int64) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return One
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return Few
+	}
+	return Many
+}
+
+func polishPlural(n int64) string {
+	switch {
+	case n == 1:
+		return One
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return Few
+	}
+	return Many
+}
+
+func czechPlural(n int64) string {
+	switch {
+	case n == 1:
+		return One
+	case n >= 2 && n <= 4:
+		return Few
+	}
+	return Other
+}
+
+func arabicPlural(n int64) string {
+	switch {
+	case n == 0:
+		return Zero
+	case n == 1:
+		return One
+	case n == 2:
+		return Two
+	case n%100 >= 3 && n%100 <= 10:
+		return Few
+	case n%100 >= 11:
+		return Many
+	}
+	return Other
+}
+
+// count returns the integer value of a count argument
+func count(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case float32:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,131 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// Publisher publishes messages to a topic; *Producer is one
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key string, value []byte) error
+}
+
+var _ Publisher = (*Producer)(nil)
+
+// DeadLetterConfig configures the retries of WithDeadLetter
+type DeadLetterConfig struct {
+	Topic       string        // dead letter topic; defaults to the topic of the message with a .dlq suffix
+	MaxAttempts int           // attempts before a message is dead-lettered; defaults to 3
+	Backoff     time.Duration // delay before the second attempt, doubled after each; defaults to 100ms
+}
+
+// DeadLetter is the value published to a dead letter topic: the failed message, with
+// the error it failed with
+type DeadLetter struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       string            `json:"key,omitempty"`
+	Value     []byte            `json:"value"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Error     string            `json:"error"`
+	Attempts  int               `json:"attempts"`
+	FailedAt  time.Time         `json:"failed_at"`
+}
+
+// permanentError marks errors that retrying cannot fix
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error of a handler as permanent, e.g. for a message that cannot be
+// decoded: WithDeadLetter dead-letters the message without retrying it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether an error was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// WithDeadLetter returns a handler retrying handler with backoff, and publishing the
+// messages that still fail to the dead letter topic with publisher. Dead-lettered messages
+// count as processed. If publishing fails, the error is returned so that the message is
+// consumed again.
+func WithDeadLetter(handler MessageHandler, publisher Publisher, config DeadLetterConfig, logger *observability.Logger) MessageHandler {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 100 * time.Millisecond
+	}
+
+	return func(ctx context.Context, message *Message) error {
+		var err error
+		attempts := 0
+		backoff := config.Backoff
+		for attempts < config.MaxAttempts {
+			attempts++
+			if err = handler(ctx, message); err == nil {
+				return nil
+			}
+			if IsPermanent(err) || attempts == config.MaxAttempts {
+				break
+			}
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+			case <-ctx.Done():
+				return err
+			}
+		}
+
+		topic := config.Topic
+		if topic == "" {
+			topic = message.Topic + ".dlq"
+		}
+		value, marshalErr := json.Marshal(DeadLetter{
+			Topic:     message.Topic,
+			Partition: message.Partition,
+			Offset:    message.Offset,
+			Key:       message.Key,
+			Value:     message.Value,
+			Headers:   message.Headers,
+			Error:     err.Error(),
+			Attempts:  attempts,
+			FailedAt:  time.Now().UTC(),
+		})
+		if marshalErr != nil {
+			return fmt.Errorf("encoding dead letter: %w", marshalErr)
+		}
+		if pubErr := publisher.Publish(ctx, topic, message.Key, value); pubErr != nil {
+			return fmt.Errorf("publishing to dead letter topic %s: %w (message failed with: %v)", topic, pubErr, err)
+		}
+
+		if logger != nil {
+			logger.Warn("Dead-lettered message",
+				zap.String("topic", message.Topic),
+				zap.String("dead_letter_topic", topic),
+				zap.String("key", message.Key),
+				zap.Int32("partition", message.Partition),
+				zap.Int64("offset", message.Offset),
+				zap.Int("attempts", attempts),
+				zap.Error(err),
+			)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishedMessage is a message recorded by recordingPublisher
+type publishedMessage struct {
+	topic, key string
+	value      []byte
+}
+
+// recordingPublisher records the messages it publishes, or fails with err
+type recordingPublisher struct {
+	published []publishedMessage
+	err       error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, publishedMessage{topic, key, value})
+	return nil
+}
+
+func TestWithDeadLetter(t *testing.T) {
+	message := &Message{Topic: "orders", Key: "o-1", Value: []byte(`{"id":"o-1"}`), Partition: 2, Offset: 42}
+	failing := errors.New("database unavailable")
+
+	tests := []struct {
+		name         string
+		failures     int   // number of attempts failing before one succeeds
+		err          error // error of the failing attempts
+		publishErr   error
+		wantAttempts int
+		wantDead     bool
+		wantErr      bool
+	}{
+		{name: "processed", failures: 0, wantAttempts: 1},
+		{name: "retried until processed", failures: 2, err: failing, wantAttempts: 3},
+		{name: "dead-lettered after max attempts", failures: 5, err: failing, wantAttempts: 3, wantDead: true},
+		{name: "permanent errors are not retried", failures: 5, err: Permanent(failing), wantAttempts: 1, wantDead: true},
+		{name: "redelivered when dead-lettering fails", failures: 5, err: failing, publishErr: errors.New("broker down"), wantAttempts: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			handler := func(ctx context.Context, m *Message) error {
+				attempts++
+				if attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			}
+			publisher := &recordingPublisher{err: tt.publishErr}
+
+			err := WithDeadLetter(handler, publisher, DeadLetterConfig{Backoff: time.Millisecond}, nil)(context.Background(), message)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "publishing to dead letter topic orders.dlq")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, attempts)
+			if !tt.wantDead {
+				assert.Empty(t, publisher.published)
+				return
+			}
+
+			require.Len(t, publisher.published, 1)
+			published := publisher.published[0]
+			assert.Equal(t, "orders.dlq", published.topic)
+			assert.Equal(t, "o-1", published.key)
+			var dead DeadLetter
+			require.NoError(t, json.Unmarshal(published.value, &dead))
+			assert.Equal(t, "orders", dead.Topic)
+			assert.Equal(t, int32(2), dead.Partition)
+			assert.Equal(t, int64(42), dead.Offset)
+			assert.Equal(t, message.Value, dead.Value)
+			assert.Equal(t, "database unavailable", dead.Error)
+			assert.Equal(t, tt.wantAttempts, dead.Attempts)
+		})
+	}
+
+	t.Run("configured topic", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		handler := func(ctx context.Context, m *Message) error { return Permanent(failing) }
+		require.NoError(t, WithDeadLetter(handler, publisher, DeadLetterConfig{Topic: "failed"}, nil)(context.Background(), message))
+		require.Len(t, publisher.published, 1)
+		assert.Equal(t, "failed", publisher.published[0].topic)
+	})
+
+	assert.True(t, IsPermanent(Permanent(failing)))
+	assert.False(t, IsPermanent(failing))
+	assert.Nil(t, Permanent(nil))
+}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/ctxkit"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 )
 
@@ -85,8 +88,9 @@ func (p *Producer) Publish(ctx context.Context, topic string, key string, value
 	}
 
 	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.ByteEncoder(value),
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(value),
+		Headers: recordHeaders(ctx),
 	}
 
 	if key != "" {
@@ -161,6 +165,13 @@ type MessageProcessor interface {
 	Process(ctx context.Context, message *Message) error
 }
 
+// Subscriber registers the handlers of the messages of topics, such as *Consumer
+type Subscriber interface {
+	RegisterHandler(topic string, handler MessageHandler)
+}
+
+var _ Subscriber = (*Consumer)(nil)
+
 // MessageHandler handles messages from Kafka
 type MessageHandler func(ctx context.Context, message *Message) error
 
@@ -247,6 +258,10 @@ func (c *Consumer) Start(ctx context.Context) error {
 	if c.consumer == nil {
 		return ErrNotConnected
 	}
+	if len(c.config.Topics) == 0 {
+		c.logger.Info("Kafka consumer has no topics to consume", zap.String("group", c.config.GroupID))
+		return nil
+	}
 
 	// Create consumer handler
 	handler := &consumerHandler{
@@ -338,23 +353,24 @@ func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 		}
 
 		// Process message
+		ctx := messageContext(session.Context(), message)
 		var err error
 		if h.processor != nil {
-			err = h.processor.Process(session.Context(), message)
+			err = h.processor.Process(ctx, message)
 		} else if handler, ok := h.handlers[msg.Topic]; ok {
-			err = handler(session.Context(), message)
+			err = handler(ctx, message)
 		} else {
 			h.logger.Warn("No handler for topic", zap.String("topic", msg.Topic))
 		}
 
 		if err != nil {
-			h.logger.Error("Failed to process message",
+			h.logger.Error("Failed to process message", append(ctxkit.Fields(ctx),
 				zap.String("topic", msg.Topic),
 				zap.String("key", message.Key),
 				zap.Int32("partition", msg.Partition),
 				zap.Int64("offset", msg.Offset),
 				zap.Error(err),
-			)
+			)...)
 		} else {
 			// Mark message as processed
 			session.MarkMessage(msg, "")
@@ -370,3 +386,33 @@ func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 
 	return nil
 }
+
+// recordHeaders returns the headers forwarding the request ID, tenant, user and locale of ctx,
+// and its trace context and baggage
+func recordHeaders(ctx context.Context) []sarama.RecordHeader {
+	headers := propagation.MapCarrier(ctxkit.Headers(ctx))
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+	if len(headers) == 0 {
+		return nil
+	}
+	records := make([]sarama.RecordHeader, 0, len(headers))
+	for key, value := range headers {
+		records = append(records, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return records
+}
+
+// messageContext returns the context handlers process message with: it carries the request
+// ID, tenant, user, locale, trace context and baggage of the publisher, and a new request ID
+// when it sent none
+func messageContext(ctx context.Context, message *Message) context.Context {
+	get := func(header string) string { return message.Headers[header] }
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(message.Headers))
+	ctx = ctxkit.FromHeaders(ctx, get)
+	// Messages come from producers trusted with the topic, unlike HTTP callers
+	ctx = ctxkit.WithUserID(ctx, get(ctxkit.HeaderUserID))
+	if ctxkit.RequestID(ctx) == "" {
+		ctx = ctxkit.WithRequestID(ctx, ctxkit.NewRequestID())
+	}
+	return ctx
+}
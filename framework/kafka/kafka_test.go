@@ -1,12 +1,20 @@
 package kafka
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/ctxkit"
 	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func TestKafkaProducerConfig(t *testing.T) {
@@ -58,3 +66,77 @@ func TestKafkaConsumerConfig(t *testing.T) {
 		assert.Equal(t, ErrInvalidConfig, err)
 	})
 }
+
+func TestProvideConfigs(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg := &config.Config{}
+		assert.Equal(t, DefaultProducerConfig(), ProvideProducerConfig(cfg))
+		assert.Equal(t, DefaultConsumerConfig(), ProvideConsumerConfig(cfg))
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		cfg := &config.Config{Kafka: config.KafkaConfig{
+			Brokers:  []string{"kafka-1:9092", "kafka-2:9092"},
+			ClientID: "orders",
+			GroupID:  "orders-workers",
+		}}
+
+		producerConfig := ProvideProducerConfig(cfg)
+		assert.Equal(t, cfg.Kafka.Brokers, producerConfig.Brokers)
+		assert.Equal(t, "orders", producerConfig.ClientID)
+		assert.Equal(t, 3, producerConfig.Retries, "other settings keep their defaults")
+
+		consumerConfig := ProvideConsumerConfig(cfg)
+		assert.Equal(t, cfg.Kafka.Brokers, consumerConfig.Brokers)
+		assert.Equal(t, "orders", consumerConfig.ClientID)
+		assert.Equal(t, "orders-workers", consumerConfig.GroupID)
+	})
+}
+
+func TestContextPropagation(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	ctx := ctxkit.WithRequestID(context.Background(), "req-1")
+	ctx = ctxkit.WithTenantID(ctx, "acme")
+	ctx = ctxkit.WithUserID(ctx, "user-7")
+
+	// The producer forwards the values as record headers
+	var headers map[string]string
+	mock := mocks.NewSyncProducer(t, nil)
+	mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		headers = make(map[string]string)
+		for _, header := range msg.Headers {
+			headers[string(header.Key)] = string(header.Value)
+		}
+		return nil
+	})
+	producer := &Producer{producer: mock, logger: logger, config: DefaultProducerConfig()}
+	require.NoError(t, producer.Publish(ctx, "orders", "", []byte("{}")))
+	require.NoError(t, mock.Close())
+	assert.Equal(t, map[string]string{
+		ctxkit.HeaderRequestID: "req-1",
+		ctxkit.HeaderTenantID:  "acme",
+		ctxkit.HeaderUserID:    "user-7",
+	}, headers)
+
+	// Consumers restore them in the handler context
+	got := messageContext(context.Background(), &Message{Headers: headers})
+	assert.Equal(t, "req-1", ctxkit.RequestID(got))
+	assert.Equal(t, "acme", ctxkit.TenantID(got))
+	assert.Equal(t, "user-7", ctxkit.UserID(got))
+
+	// Messages without a request ID get a new one
+	got = messageContext(context.Background(), &Message{Headers: map[string]string{}})
+	assert.NotEmpty(t, ctxkit.RequestID(got))
+	assert.Empty(t, ctxkit.TenantID(got))
+
+	// Baggage, such as the debug trace member, reaches consumers with the trace context
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	ctx, err := ctxkit.WithBaggage(context.Background(), ctxkit.DebugTraceMember, "1")
+	require.NoError(t, err)
+	headers = make(map[string]string)
+	for _, header := range recordHeaders(ctx) {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	assert.Equal(t, map[string]string{"baggage": ctxkit.DebugTraceMember + "=1"}, headers)
+	assert.True(t, ctxkit.DebugTrace(messageContext(context.Background(), &Message{Headers: headers})))
+}
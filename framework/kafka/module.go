@@ -3,19 +3,50 @@ package kafka
 import (
 	"context"
 
+	"github.com/axiomod/axiomod/framework/config"
+
 	"go.uber.org/fx"
 )
 
 // Module provides the fx options for the kafka module
 var Module = fx.Options(
-	fx.Provide(DefaultProducerConfig),
+	fx.Provide(ProvideProducerConfig),
 	fx.Provide(NewProducer),
-	fx.Provide(DefaultConsumerConfig),
+	fx.Provide(ProvideConsumerConfig),
 	fx.Provide(NewConsumer),
 	fx.Invoke(RegisterProducerLifecycle),
 	fx.Invoke(RegisterConsumerLifecycle),
 )
 
+// ProvideProducerConfig returns the default producer configuration with the brokers and
+// client ID of the kafka configuration
+func ProvideProducerConfig(cfg *config.Config) *ProducerConfig {
+	producerConfig := DefaultProducerConfig()
+	if len(cfg.Kafka.Brokers) > 0 {
+		producerConfig.Brokers = cfg.Kafka.Brokers
+	}
+	if cfg.Kafka.ClientID != "" {
+		producerConfig.ClientID = cfg.Kafka.ClientID
+	}
+	return producerConfig
+}
+
+// ProvideConsumerConfig returns the default consumer configuration with the brokers, client
+// ID and group of the kafka configuration
+func ProvideConsumerConfig(cfg *config.Config) *ConsumerConfig {
+	consumerConfig := DefaultConsumerConfig()
+	if len(cfg.Kafka.Brokers) > 0 {
+		consumerConfig.Brokers = cfg.Kafka.Brokers
+	}
+	if cfg.Kafka.ClientID != "" {
+		consumerConfig.ClientID = cfg.Kafka.ClientID
+	}
+	if cfg.Kafka.GroupID != "" {
+		consumerConfig.GroupID = cfg.Kafka.GroupID
+	}
+	return consumerConfig
+}
+
 // RegisterProducerLifecycle registers lifecycle hooks for the Kafka producer
 func RegisterProducerLifecycle(lc fx.Lifecycle, producer *Producer) {
 	lc.Append(fx.Hook{
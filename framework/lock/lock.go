@@ -0,0 +1,255 @@
+// Package lock provides distributed locks, so that workers, migrations and singleton
+// background tasks run on one instance at a time. Locks are held for a TTL and renewed
+// while held, so a crashed instance releases its locks once their TTL expires.
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// Common errors
+var (
+	ErrNotAcquired = errors.New("lock: held by another owner")
+	ErrLost        = errors.New("lock: lost")
+)
+
+// Results of acquisition attempts, as recorded on metrics
+const (
+	resultAcquired = "acquired"
+	resultBusy     = "busy"
+	resultError    = "error"
+)
+
+// Lock is a distributed lock backend
+type Lock interface {
+	// Acquire takes key for ttl, or returns ErrNotAcquired if another owner holds it
+	Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error)
+}
+
+// Lease is a lock held by this process
+type Lease interface {
+	// Refresh extends the lease to ttl from now, or returns ErrLost if it expired
+	Refresh(ctx context.Context, ttl time.Duration) error
+	// Release gives the lock up
+	Release(ctx context.Context) error
+}
+
+// Locker acquires locks from a backend and renews them while they are held
+type Locker struct {
+	backend       Lock
+	logger        *observability.Logger
+	metrics       *observability.Metrics
+	retryInterval time.Duration
+}
+
+// New creates a locker of the backend's locks
+func New(backend Lock, logger *observability.Logger) *Locker {
+	return &Locker{
+		backend:       backend,
+		logger:        logger,
+		retryInterval: 100 * time.Millisecond,
+	}
+}
+
+// WithMetrics records acquisitions, hold times and lost locks on metrics
+func (l *Locker) WithMetrics(metrics *observability.Metrics) *Locker {
+	l.metrics = metrics
+	return l
+}
+
+// WithRetryInterval sets how often Lock retries a lock held by another owner
+func (l *Locker) WithRetryInterval(interval time.Duration) *Locker {
+	l.retryInterval = interval
+	return l
+}
+
+// TryLock acquires key for ttl without waiting, or returns ErrNotAcquired. The lock is
+// renewed every third of ttl until it is released.
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (*Guard, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock: ttl of %s must be positive", key)
+	}
+	lease, err := l.backend.Acquire(ctx, key, ttl)
+	switch {
+	case errors.Is(err, ErrNotAcquired):
+		l.record(key, resultBusy)
+		return nil, err
+	case err != nil:
+		l.record(key, resultError)
+		return nil, err
+	}
+	l.record(key, resultAcquired)
+
+	g := &Guard{
+		key:      key,
+		lease:    lease,
+		locker:   l,
+		ttl:      ttl,
+		acquired: time.Now(),
+		lost:     make(chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go g.renew()
+	return g, nil
+}
+
+// Lock acquires key for ttl, waiting until it is free or ctx is done
+func (l *Locker) Lock(ctx context.Context, key string, ttl time.Duration) (*Guard, error) {
+	for {
+		g, err := l.TryLock(ctx, key, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return g, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.retryInterval):
+		}
+	}
+}
+
+// WithLock runs fn while holding key, waiting for it first. The context of fn is
+// cancelled if the lock is lost, and ErrLost is then returned with the error of fn.
+func (l *Locker) WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	g, err := l.Lock(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	return g.run(ctx, fn)
+}
+
+// TryWithLock runs fn while holding key, or returns ErrNotAcquired without running it if
+// another owner holds it
+func (l *Locker) TryWithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	g, err := l.TryLock(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	return g.run(ctx, fn)
+}
+
+// record counts an acquisition attempt
+func (l *Locker) record(key, result string) {
+	if l.metrics != nil && l.metrics.LockAcquisitionsTotal != nil {
+		l.metrics.LockAcquisitionsTotal.WithLabelValues(key, result).Inc()
+	}
+}
+
+// Guard is a held lock, renewed until it is released
+type Guard struct {
+	key      string
+	lease    Lease
+	locker   *Locker
+	ttl      time.Duration
+	acquired time.Time
+
+	lost     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Key returns the key of the lock
+func (g *Guard) Key() string {
+	return g.key
+}
+
+// Lost is closed when the lock could not be renewed before its TTL expired, after which
+// another owner may hold it
+func (g *Guard) Lost() <-chan struct{} {
+	return g.lost
+}
+
+// Release stops renewing the lock and gives it up
+func (g *Guard) Release(ctx context.Context) error {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+		if metrics := g.locker.metrics; metrics != nil && metrics.LockHeldDuration != nil {
+			metrics.LockHeldDuration.WithLabelValues(g.key).Observe(time.Since(g.acquired).Seconds())
+		}
+	})
+	<-g.done
+	return g.lease.Release(ctx)
+}
+
+// renew refreshes the lease every third of its TTL. Failed refreshes are retried until
+// the TTL of the last successful one runs out, or until the backend reports the lease lost.
+func (g *Guard) renew() {
+	defer close(g.done)
+
+	interval := g.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewed := time.Now()
+
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := g.lease.Refresh(ctx, g.ttl)
+		cancel()
+		if err == nil {
+			renewed = time.Now()
+			continue
+		}
+		if errors.Is(err, ErrLost) || time.Since(renewed) >= g.ttl {
+			g.markLost(err)
+			return
+		}
+		if logger := g.locker.logger; logger != nil {
+			logger.Warn("Failed to renew lock", zap.String("lock", g.key), zap.Error(err))
+		}
+	}
+}
+
+// markLost reports the lock lost
+func (g *Guard) markLost(err error) {
+	if logger := g.locker.logger; logger != nil {
+		logger.Error("Lost lock", zap.String("lock", g.key), zap.Error(err))
+	}
+	if metrics := g.locker.metrics; metrics != nil && metrics.LockLostTotal != nil {
+		metrics.LockLostTotal.WithLabelValues(g.key).Inc()
+	}
+	close(g.lost)
+}
+
+// run runs fn while the lock is held and releases it
+func (g *Guard) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		select {
+		case <-g.lost:
+			cancel(ErrLost)
+		case <-fnCtx.Done():
+		}
+	}()
+
+	err := fn(fnCtx)
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), g.ttl)
+	defer cancelRelease()
+	if releaseErr := g.Release(releaseCtx); releaseErr != nil && g.locker.logger != nil {
+		g.locker.logger.Warn("Failed to release lock", zap.String("lock", g.key), zap.Error(releaseErr))
+	}
+
+	select {
+	case <-g.lost:
+		return errors.Join(err, ErrLost)
+	default:
+		return err
+	}
+}
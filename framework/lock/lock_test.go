@@ -0,0 +1,244 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+func newTestLocker(t *testing.T, backend Lock) (*Locker, *observability.Metrics) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	return New(backend, logger).WithMetrics(metrics).WithRetryInterval(10 * time.Millisecond), metrics
+}
+
+func TestTryLock(t *testing.T) {
+	ctx := context.Background()
+	locker, metrics := newTestLocker(t, NewMemoryLock())
+
+	g, err := locker.TryLock(ctx, "report", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "report", g.Key())
+
+	_, err = locker.TryLock(ctx, "report", time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	require.NoError(t, g.Release(ctx))
+	g, err = locker.TryLock(ctx, "report", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, g.Release(ctx))
+
+	_, err = locker.TryLock(ctx, "report", 0)
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.LockAcquisitionsTotal.WithLabelValues("report", resultAcquired)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.LockAcquisitionsTotal.WithLabelValues("report", resultBusy)))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.LockHeldDuration))
+}
+
+func TestLockRenewal(t *testing.T) {
+	ctx := context.Background()
+	locker, _ := newTestLocker(t, NewMemoryLock())
+
+	g, err := locker.TryLock(ctx, "report", 60*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	_, err = locker.TryLock(ctx, "report", time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired, "the lock is renewed past its TTL while held")
+	select {
+	case <-g.Lost():
+		t.Fatal("a renewed lock is not lost")
+	default:
+	}
+	require.NoError(t, g.Release(ctx))
+}
+
+func TestWithLock(t *testing.T) {
+	ctx := context.Background()
+	locker, _ := newTestLocker(t, NewMemoryLock())
+
+	g, err := locker.TryLock(ctx, "migrations", time.Second)
+	require.NoError(t, err)
+
+	err = locker.TryWithLock(ctx, "migrations", time.Second, func(ctx context.Context) error {
+		t.Fatal("fn runs without the lock")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		g.Release(ctx)
+	}()
+	var ran bool
+	err = locker.WithLock(ctx, "migrations", time.Second, func(ctx context.Context) error {
+		ran = true
+		return errors.New("failed")
+	})
+	assert.True(t, ran, "WithLock waits for the lock")
+	assert.EqualError(t, err, "failed")
+
+	require.NoError(t, locker.TryWithLock(ctx, "migrations", time.Second, func(ctx context.Context) error { return nil }),
+		"the lock is released after fn")
+
+	g, err = locker.TryLock(ctx, "migrations", time.Second)
+	require.NoError(t, err)
+	defer g.Release(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err = locker.WithLock(waitCtx, "migrations", time.Second, func(ctx context.Context) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// losingLock grants leases that are lost on their first refresh
+type losingLock struct {
+	refreshes atomic.Int32
+}
+
+func (l *losingLock) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	return l, nil
+}
+
+func (l *losingLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.refreshes.Add(1)
+	return ErrLost
+}
+
+func (l *losingLock) Release(ctx context.Context) error {
+	return nil
+}
+
+func TestLostLock(t *testing.T) {
+	backend := &losingLock{}
+	locker, metrics := newTestLocker(t, backend)
+
+	err := locker.WithLock(context.Background(), "report", 30*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), ErrLost, "fn is cancelled when the lock is lost")
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, ErrLost)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), backend.refreshes.Load())
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.LockLostTotal.WithLabelValues("report")))
+}
+
+func TestNewLocker(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LockConfig
+		db      *sql.DB
+		want    any
+		wantErr bool
+	}{
+		{"default", config.LockConfig{}, nil, &MemoryLock{}, false},
+		{"memory", config.LockConfig{Backend: "Memory"}, nil, &MemoryLock{}, false},
+		{"redis", config.LockConfig{Backend: "redis", RedisAddrs: []string{"a:6379", "b:6379", "c:6379"}}, nil, &RedisLock{}, false},
+		{"redis without a client", config.LockConfig{Backend: "redis"}, nil, nil, true},
+		{"postgres", config.LockConfig{Backend: "postgres"}, &sql.DB{}, &PostgresLock{}, false},
+		{"postgres without a database", config.LockConfig{Backend: "postgres"}, nil, nil, true},
+		{"unknown", config.LockConfig{Backend: "etcd"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Lock: tt.cfg}
+			logger, _ := observability.NewLogger(cfg)
+			locker, err := NewLocker(LockerParams{Lifecycle: fxtest.NewLifecycle(t), Config: cfg, Logger: logger, DB: tt.db})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, locker.backend)
+		})
+	}
+}
+
+func TestRedisClients(t *testing.T) {
+	shared := redis.NewClient(&redis.Options{Addr: "shared:6379"})
+	defer shared.Close()
+	cfg := config.RedisConfig{Mode: "cluster", Addrs: []string{"node:6379"}, Password: "secret"}
+
+	clients, err := redisClients(nil, cfg, shared)
+	require.NoError(t, err)
+	assert.Equal(t, []redis.UniversalClient{shared}, clients)
+
+	_, err = redisClients(nil, cfg, nil)
+	assert.ErrorIs(t, err, axredis.ErrNoClient)
+
+	clients, err = redisClients([]string{"a:6379", "b:6379"}, cfg, shared)
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+	for i, addr := range []string{"a:6379", "b:6379"} {
+		require.IsType(t, &redis.Client{}, clients[i])
+		assert.Equal(t, addr, clients[i].(*redis.Client).Options().Addr)
+		assert.Equal(t, "secret", clients[i].(*redis.Client).Options().Password)
+	}
+	assert.NoError(t, closeClients(clients))
+}
+
+func TestRedisLock(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("Skipping Redis lock test; set REDIS_ADDR")
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	backend := NewRedisLock("lock-test-"+time.Now().Format("150405.000"), client)
+
+	lease, err := backend.Acquire(ctx, "report", time.Second)
+	require.NoError(t, err)
+	_, err = backend.Acquire(ctx, "report", time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	require.NoError(t, lease.Refresh(ctx, time.Second))
+
+	require.NoError(t, lease.Release(ctx))
+	assert.ErrorIs(t, lease.Refresh(ctx, time.Second), ErrLost)
+	lease, err = backend.Acquire(ctx, "report", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, lease.Release(ctx))
+}
+
+func TestPostgresLock(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("Skipping Postgres lock test; set POSTGRES_DSN")
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	backend := NewPostgresLock(db, "lock-test")
+
+	lease, err := backend.Acquire(ctx, "report", time.Second)
+	require.NoError(t, err)
+	_, err = backend.Acquire(ctx, "report", time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	require.NoError(t, lease.Refresh(ctx, time.Second))
+
+	require.NoError(t, lease.Release(ctx))
+	lease, err = backend.Acquire(ctx, "report", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, lease.Release(ctx))
+}
@@ -0,0 +1,70 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryLock keeps locks in process memory. It only excludes goroutines of one process,
+// so it suits single-instance deployments and tests.
+type MemoryLock struct {
+	mu    sync.Mutex
+	locks map[string]memoryEntry
+}
+
+// memoryEntry is a held lock
+type memoryEntry struct {
+	token   string
+	expires time.Time
+}
+
+// NewMemoryLock creates an in-memory lock backend
+func NewMemoryLock() *MemoryLock {
+	return &MemoryLock{locks: make(map[string]memoryEntry)}
+}
+
+// Acquire takes key for ttl
+func (m *MemoryLock) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if entry, ok := m.locks[key]; ok && now.Before(entry.expires) {
+		return nil, ErrNotAcquired
+	}
+	token := uuid.NewString()
+	m.locks[key] = memoryEntry{token: token, expires: now.Add(ttl)}
+	return &memoryLease{lock: m, key: key, token: token}, nil
+}
+
+// memoryLease is a lock held in memory
+type memoryLease struct {
+	lock  *MemoryLock
+	key   string
+	token string
+}
+
+// Refresh extends the lease
+func (l *memoryLease) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.lock.mu.Lock()
+	defer l.lock.mu.Unlock()
+	now := time.Now()
+	entry, ok := l.lock.locks[l.key]
+	if !ok || entry.token != l.token || !now.Before(entry.expires) {
+		return ErrLost
+	}
+	l.lock.locks[l.key] = memoryEntry{token: l.token, expires: now.Add(ttl)}
+	return nil
+}
+
+// Release gives the lock up if it is still held
+func (l *memoryLease) Release(ctx context.Context) error {
+	l.lock.mu.Lock()
+	defer l.lock.mu.Unlock()
+	if entry, ok := l.lock.locks[l.key]; ok && entry.token == l.token {
+		delete(l.lock.locks, l.key)
+	}
+	return nil
+}
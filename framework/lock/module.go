@@ -0,0 +1,116 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// Backends of locks
+const (
+	BackendMemory   = "memory"
+	BackendRedis    = "redis"
+	BackendPostgres = "postgres"
+)
+
+// Module provides the Locker of the configured backend
+var Module = fx.Options(
+	fx.Provide(NewLocker),
+)
+
+// LockerParams holds the dependencies of the locker. DB is needed by the postgres
+// backend only; the redis backend uses Redis, the client of redis.Module, unless
+// lock.redisAddrs lists independent nodes.
+type LockerParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    *config.Config
+	Logger    *observability.Logger
+	Metrics   *observability.Metrics
+	DB        *sql.DB               `optional:"true"`
+	Redis     redis.UniversalClient `optional:"true"`
+}
+
+// NewLocker creates the locker of the configured backend
+func NewLocker(p LockerParams) (*Locker, error) {
+	lockCfg := p.Config.Lock
+	prefix := lockCfg.Prefix
+	if prefix == "" {
+		prefix = "lock"
+	}
+
+	var backend Lock
+	switch strings.ToLower(lockCfg.Backend) {
+	case "", BackendMemory:
+		backend = NewMemoryLock()
+	case BackendRedis:
+		clients, err := redisClients(lockCfg.RedisAddrs, p.Config.Redis, p.Redis)
+		if err != nil {
+			return nil, err
+		}
+		if len(lockCfg.RedisAddrs) > 0 {
+			p.Lifecycle.Append(fx.Hook{OnStop: func(context.Context) error {
+				return closeClients(clients)
+			}})
+		}
+		backend = NewRedisLock(prefix, clients...)
+	case BackendPostgres:
+		if p.DB == nil {
+			return nil, fmt.Errorf("lock: the postgres backend needs a *sql.DB provided to the application")
+		}
+		backend = NewPostgresLock(p.DB, prefix)
+	default:
+		return nil, fmt.Errorf("lock: unknown backend %q", lockCfg.Backend)
+	}
+
+	locker := New(backend, p.Logger).WithMetrics(p.Metrics)
+	if lockCfg.RetryInterval > 0 {
+		locker.WithRetryInterval(time.Duration(lockCfg.RetryInterval) * time.Millisecond)
+	}
+	return locker, nil
+}
+
+// redisClients returns the clients of the redis backend: a standalone client per address of
+// addrs, which take their credentials from cfg, or else the shared client of redis.Module
+func redisClients(addrs []string, cfg config.RedisConfig, shared redis.UniversalClient) ([]redis.UniversalClient, error) {
+	if len(addrs) == 0 {
+		if shared == nil {
+			return nil, fmt.Errorf("lock: %w", axredis.ErrNoClient)
+		}
+		return []redis.UniversalClient{shared}, nil
+	}
+
+	clients := make([]redis.UniversalClient, 0, len(addrs))
+	for _, addr := range addrs {
+		nodeCfg := cfg
+		nodeCfg.Mode = axredis.ModeStandalone
+		nodeCfg.Addr = addr
+		client, err := axredis.NewClient(nodeCfg)
+		if err != nil {
+			closeClients(clients)
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// closeClients closes the clients of the independent nodes of lock.redisAddrs
+func closeClients(clients []redis.UniversalClient) error {
+	var errs []error
+	for _, client := range clients {
+		errs = append(errs, client.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// PostgresLock takes PostgreSQL session advisory locks. A lock is held by a connection
+// taken from the pool for as long as the lock is held, and is released by PostgreSQL when
+// that connection drops, so the TTL is not used.
+type PostgresLock struct {
+	db     *sql.DB
+	prefix string
+}
+
+// NewPostgresLock creates a backend of advisory locks on db. Keys are hashed with prefix
+// into 64-bit lock IDs.
+func NewPostgresLock(db *sql.DB, prefix string) *PostgresLock {
+	return &PostgresLock{db: db, prefix: prefix}
+}
+
+// Acquire takes the advisory lock of key
+func (p *PostgresLock) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lock: failed to get a connection: %w", err)
+	}
+	id := advisoryID(p.prefix + ":" + key)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("lock: failed to take advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+	return &postgresLease{conn: conn, id: id}, nil
+}
+
+// advisoryID hashes a key into an advisory lock ID
+func advisoryID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// postgresLease is an advisory lock held by a connection
+type postgresLease struct {
+	conn *sql.Conn
+	id   int64
+}
+
+// Refresh checks that the connection holding the lock is still alive
+func (l *postgresLease) Refresh(ctx context.Context, ttl time.Duration) error {
+	if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err != nil {
+		return fmt.Errorf("%w: the connection holding it failed: %v", ErrLost, err)
+	}
+	return nil
+}
+
+// Release unlocks the advisory lock and returns the connection to the pool
+func (l *postgresLease) Release(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.id)
+	if closeErr := l.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
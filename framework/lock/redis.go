@@ -0,0 +1,116 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// refreshScript extends a lock if it is still held with the token
+var refreshScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes a lock if it is still held with the token
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLock takes locks on independent Redis nodes with the Redlock algorithm: a lock is
+// held when a majority of the nodes granted it, in less time than its TTL. With a single
+// node it is a plain SET NX lock.
+type RedisLock struct {
+	clients []redis.UniversalClient
+	prefix  string
+}
+
+// NewRedisLock creates a backend of locks on the given nodes, under keys "<prefix>:<key>"
+func NewRedisLock(prefix string, clients ...redis.UniversalClient) *RedisLock {
+	return &RedisLock{clients: clients, prefix: prefix}
+}
+
+// quorum returns how many nodes must grant a lock
+func (r *RedisLock) quorum() int {
+	return len(r.clients)/2 + 1
+}
+
+// Acquire takes key for ttl on a majority of the nodes
+func (r *RedisLock) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	lease := &redisLease{lock: r, key: r.prefix + ":" + key, token: uuid.NewString()}
+	start := time.Now()
+
+	granted := 0
+	var errs []error
+	for _, client := range r.clients {
+		ok, err := client.SetNX(ctx, lease.key, lease.token, ttl).Result()
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case ok:
+			granted++
+		}
+	}
+
+	// Leave room for clock drift between the nodes, as Redlock does
+	drift := ttl/100 + 2*time.Millisecond
+	if granted >= r.quorum() && time.Since(start)+drift < ttl {
+		return lease, nil
+	}
+
+	lease.Release(context.WithoutCancel(ctx))
+	if len(errs) > len(r.clients)-r.quorum() {
+		return nil, fmt.Errorf("lock: failed to reach a majority of redis nodes: %w", errors.Join(errs...))
+	}
+	return nil, ErrNotAcquired
+}
+
+// redisLease is a lock held on Redis nodes
+type redisLease struct {
+	lock  *RedisLock
+	key   string
+	token string
+}
+
+// Refresh extends the lock on the nodes still holding it. It is lost when fewer than a
+// majority do.
+func (l *redisLease) Refresh(ctx context.Context, ttl time.Duration) error {
+	extended := 0
+	var errs []error
+	for _, client := range l.lock.clients {
+		n, err := refreshScript.Run(ctx, client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case n == 1:
+			extended++
+		}
+	}
+	if extended >= l.lock.quorum() {
+		return nil
+	}
+	if len(errs) > len(l.lock.clients)-l.lock.quorum() {
+		return fmt.Errorf("lock: failed to reach a majority of redis nodes: %w", errors.Join(errs...))
+	}
+	return ErrLost
+}
+
+// Release deletes the lock from the nodes still holding it
+func (l *redisLease) Release(ctx context.Context) error {
+	var errs []error
+	for _, client := range l.lock.clients {
+		if err := releaseScript.Run(ctx, client, []string{l.key}, l.token).Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
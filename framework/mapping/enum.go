@@ -0,0 +1,55 @@
+package mapping
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// ErrUnknownEnum is returned for protobuf enum values without an entity value
+var ErrUnknownEnum = stderrors.New("unknown enum value")
+
+// Enum maps the values of an entity type, such as a status string, to the values of a
+// generated protobuf enum and back. The zero protobuf value is the UNSPECIFIED value
+// required by proto3.
+type Enum[E comparable, P ~int32] struct {
+	name    string
+	toProto map[E]P
+	toValue map[P]E
+}
+
+// NewEnum creates an enum mapping from entity values to protobuf values. name is used in
+// error messages, e.g. "status". It panics if two entity values map to the same
+// protobuf value, since the mapping could not be reversed.
+func NewEnum[E comparable, P ~int32](name string, values map[E]P) *Enum[E, P] {
+	e := &Enum[E, P]{
+		name:    name,
+		toProto: make(map[E]P, len(values)),
+		toValue: make(map[P]E, len(values)),
+	}
+	for value, proto := range values {
+		if other, ok := e.toValue[proto]; ok {
+			panic(fmt.Sprintf("mapping: %s values %v and %v map to the same protobuf value %d", name, other, value, proto))
+		}
+		e.toProto[value] = proto
+		e.toValue[proto] = value
+	}
+	return e
+}
+
+// ToProto returns the protobuf value of an entity value, or the zero UNSPECIFIED value
+// for values without one
+func (e *Enum[E, P]) ToProto(value E) P {
+	return e.toProto[value]
+}
+
+// FromProto returns the entity value of a protobuf value. Values without one, including
+// UNSPECIFIED unless it is mapped, return an INVALID_INPUT error wrapping ErrUnknownEnum.
+func (e *Enum[E, P]) FromProto(proto P) (E, error) {
+	value, ok := e.toValue[proto]
+	if !ok {
+		return value, errors.NewInvalidInput(ErrUnknownEnum, fmt.Sprintf("invalid %s %d", e.name, proto))
+	}
+	return value, nil
+}
@@ -0,0 +1,155 @@
+package mapping
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Timestamp converts a time to a protobuf timestamp. The zero time, which entities use
+// for "not set", becomes nil.
+func Timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// TimestampPtr converts an optional time to a protobuf timestamp
+func TimestampPtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return Timestamp(*t)
+}
+
+// Time converts a protobuf timestamp to a UTC time. nil becomes the zero time.
+func Time(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// TimePtr converts a protobuf timestamp to an optional time
+func TimePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// Duration converts a duration to a protobuf duration
+func Duration(d time.Duration) *durationpb.Duration {
+	return durationpb.New(d)
+}
+
+// FromDuration converts a protobuf duration to a duration. nil becomes 0 and durations
+// out of range are clamped.
+func FromDuration(d *durationpb.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.AsDuration()
+}
+
+// String converts an optional string to a wrapper, so an unset value stays
+// distinguishable from an empty one
+func String(v *string) *wrapperspb.StringValue {
+	if v == nil {
+		return nil
+	}
+	return wrapperspb.String(*v)
+}
+
+// FromString converts a string wrapper to an optional string
+func FromString(w *wrapperspb.StringValue) *string {
+	if w == nil {
+		return nil
+	}
+	v := w.GetValue()
+	return &v
+}
+
+// Bool converts an optional bool to a wrapper
+func Bool(v *bool) *wrapperspb.BoolValue {
+	if v == nil {
+		return nil
+	}
+	return wrapperspb.Bool(*v)
+}
+
+// FromBool converts a bool wrapper to an optional bool
+func FromBool(w *wrapperspb.BoolValue) *bool {
+	if w == nil {
+		return nil
+	}
+	v := w.GetValue()
+	return &v
+}
+
+// Int32 converts an optional int32 to a wrapper
+func Int32(v *int32) *wrapperspb.Int32Value {
+	if v == nil {
+		return nil
+	}
+	return wrapperspb.Int32(*v)
+}
+
+// FromInt32 converts an int32 wrapper to an optional int32
+func FromInt32(w *wrapperspb.Int32Value) *int32 {
+	if w == nil {
+		return nil
+	}
+	v := w.GetValue()
+	return &v
+}
+
+// Int64 converts an optional int64 to a wrapper
+func Int64(v *int64) *wrapperspb.Int64Value {
+	if v == nil {
+		return nil
+	}
+	return wrapperspb.Int64(*v)
+}
+
+// FromInt64 converts an int64 wrapper to an optional int64
+func FromInt64(w *wrapperspb.Int64Value) *int64 {
+	if w == nil {
+		return nil
+	}
+	v := w.GetValue()
+	return &v
+}
+
+// Double converts an optional float64 to a wrapper
+func Double(v *float64) *wrapperspb.DoubleValue {
+	if v == nil {
+		return nil
+	}
+	return wrapperspb.Double(*v)
+}
+
+// FromDouble converts a double wrapper to an optional float64
+func FromDouble(w *wrapperspb.DoubleValue) *float64 {
+	if w == nil {
+		return nil
+	}
+	v := w.GetValue()
+	return &v
+}
+
+// Slice converts every element of a slice, e.g. entities to messages. nil stays nil.
+func Slice[From, To any](items []From, convert func(From) To) []To {
+	if items == nil {
+		return nil
+	}
+	converted := make([]To, len(items))
+	for i, item := range items {
+		converted[i] = convert(item)
+	}
+	return converted
+}
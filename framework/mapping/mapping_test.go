@@ -0,0 +1,149 @@
+package mapping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestTimestamps(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 500, time.FixedZone("CET", 3600))
+
+	ts := Timestamp(created)
+	require.NotNil(t, ts)
+	assert.True(t, created.Equal(Time(ts)))
+	assert.Equal(t, time.UTC, Time(ts).Location())
+
+	assert.Nil(t, Timestamp(time.Time{}), "zero times are not set")
+	assert.True(t, Time(nil).IsZero())
+	assert.Nil(t, TimestampPtr(nil))
+	assert.Nil(t, TimePtr(nil))
+	assert.True(t, created.Equal(*TimePtr(TimestampPtr(&created))))
+}
+
+func TestDurations(t *testing.T) {
+	assert.Equal(t, 90*time.Second, FromDuration(Duration(90*time.Second)))
+	assert.Equal(t, time.Duration(0), FromDuration(nil))
+	assert.Equal(t, time.Duration(1<<63-1), FromDuration(&durationpb.Duration{Seconds: 1 << 62}), "out of range durations are clamped")
+}
+
+func TestWrappers(t *testing.T) {
+	name, empty, flag, count, total, ratio := "order", "", true, int32(3), int64(-7), 0.5
+
+	assert.Equal(t, &name, FromString(String(&name)))
+	assert.Equal(t, &empty, FromString(String(&empty)), "empty values stay set")
+	assert.Equal(t, &flag, FromBool(Bool(&flag)))
+	assert.Equal(t, &count, FromInt32(Int32(&count)))
+	assert.Equal(t, &total, FromInt64(Int64(&total)))
+	assert.Equal(t, &ratio, FromDouble(Double(&ratio)))
+
+	assert.Nil(t, String(nil))
+	assert.Nil(t, FromString(nil))
+	assert.Nil(t, FromBool(Bool(nil)))
+	assert.Nil(t, FromInt32(Int32(nil)))
+	assert.Nil(t, FromInt64(Int64(nil)))
+	assert.Nil(t, FromDouble(Double(nil)))
+}
+
+func TestSlice(t *testing.T) {
+	assert.Equal(t, []int{1, 2}, Slice([]string{"a", "bb"}, func(s string) int { return len(s) }))
+	assert.Nil(t, Slice[string, int](nil, func(s string) int { return len(s) }))
+}
+
+// OrderStatus mirrors a generated protobuf enum
+type OrderStatus int32
+
+const (
+	OrderStatus_ORDER_STATUS_UNSPECIFIED OrderStatus = 0 //axiomod:ignore naming/exported-variable reason="protoc enum value names"
+	OrderStatus_ORDER_STATUS_OPEN        OrderStatus = 1 //axiomod:ignore naming/exported-variable reason="protoc enum value names"
+	OrderStatus_ORDER_STATUS_SHIPPED     OrderStatus = 2 //axiomod:ignore naming/exported-variable reason="protoc enum value names"
+)
+
+func TestEnum(t *testing.T) {
+	statuses := NewEnum("status", map[string]OrderStatus{
+		"open":    OrderStatus_ORDER_STATUS_OPEN,
+		"shipped": OrderStatus_ORDER_STATUS_SHIPPED,
+	})
+
+	assert.Equal(t, OrderStatus_ORDER_STATUS_SHIPPED, statuses.ToProto("shipped"))
+	assert.Equal(t, OrderStatus_ORDER_STATUS_UNSPECIFIED, statuses.ToProto("lost"))
+
+	status, err := statuses.FromProto(OrderStatus_ORDER_STATUS_OPEN)
+	require.NoError(t, err)
+	assert.Equal(t, "open", status)
+
+	for _, value := range []OrderStatus{OrderStatus_ORDER_STATUS_UNSPECIFIED, 42} {
+		_, err := statuses.FromProto(value)
+		assert.ErrorIs(t, err, ErrUnknownEnum)
+		assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+	}
+
+	assert.Panics(t, func() {
+		NewEnum("status", map[string]OrderStatus{"open": 1, "opened": 1})
+	})
+}
+
+// protoMoney mirrors google.type.Money
+type protoMoney struct {
+	CurrencyCode string
+	Units        int64
+	Nanos        int32
+}
+
+func (m *protoMoney) GetCurrencyCode() string { return m.CurrencyCode }
+func (m *protoMoney) GetUnits() int64         { return m.Units }
+func (m *protoMoney) GetNanos() int32         { return m.Nanos }
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		name   string
+		proto  protoMoney
+		want   Money
+		format string
+	}{
+		{name: "cents", proto: protoMoney{"EUR", 10, 500_000_000}, want: Money{"EUR", 1050}, format: "EUR 10.50"},
+		{name: "negative", proto: protoMoney{"usd", -1, -750_000_000}, want: Money{"USD", -175}, format: "USD -1.75"},
+		{name: "under one unit", proto: protoMoney{"EUR", 0, -10_000_000}, want: Money{"EUR", -1}, format: "EUR -0.01"},
+		{name: "no minor unit", proto: protoMoney{"JPY", 1200, 0}, want: Money{"JPY", 1200}, format: "JPY 1200"},
+		{name: "three decimals", proto: protoMoney{"KWD", 2, 125_000_000}, want: Money{"KWD", 2125}, format: "KWD 2.125"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyFromProto(&tt.proto)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.format, got.String())
+
+			// And back
+			assert.Equal(t, tt.proto.Units, got.Units())
+			assert.Equal(t, tt.proto.Nanos, got.Nanos())
+		})
+	}
+}
+
+func TestInvalidMoney(t *testing.T) {
+	tests := []struct {
+		name  string
+		proto ProtoMoney
+	}{
+		{name: "missing", proto: nil},
+		{name: "currency", proto: &protoMoney{"EURO", 1, 0}},
+		{name: "nanos out of range", proto: &protoMoney{"EUR", 1, 1_000_000_000}},
+		{name: "mixed signs", proto: &protoMoney{"EUR", 1, -500_000_000}},
+		{name: "fraction of a cent", proto: &protoMoney{"EUR", 1, 5_000_000}},
+		{name: "fraction of a yen", proto: &protoMoney{"JPY", 1, 500_000_000}},
+		{name: "out of range", proto: &protoMoney{"EUR", 1 << 62, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MoneyFromProto(tt.proto)
+			assert.ErrorIs(t, err, ErrInvalidMoney)
+			assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+		})
+	}
+}
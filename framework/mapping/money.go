@@ -0,0 +1,108 @@
+package mapping
+
+import (
+	stderrors "errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// ErrInvalidMoney is returned for protobuf money that is malformed or more precise than
+// the minor unit of its currency
+var ErrInvalidMoney = stderrors.New("invalid money")
+
+// ProtoMoney is implemented by google.type.Money and by messages with the same fields
+type ProtoMoney interface {
+	GetCurrencyCode() string
+	GetUnits() int64
+	GetNanos() int32
+}
+
+// Money is an amount in the minor unit of a currency, such as cents, the usual way
+// entities store money
+type Money struct {
+	Currency string // ISO 4217 code, e.g. "EUR"
+	Amount   int64  // in minor units, e.g. 1050 for EUR 10.50
+}
+
+// currencyPattern matches ISO 4217 currency codes
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not a hundredth
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the number of decimals of the minor unit of a currency: 2 for
+// most currencies, 0 for e.g. JPY and 3 for e.g. KWD
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// nanosPerMinorUnit returns how many nanos make one minor unit of a currency
+func nanosPerMinorUnit(currency string) int64 {
+	return int64(math.Pow10(9 - CurrencyExponent(currency)))
+}
+
+// Units returns the whole units of the amount, the units field of google.type.Money
+func (m Money) Units() int64 {
+	return m.Amount / int64(math.Pow10(CurrencyExponent(m.Currency)))
+}
+
+// Nanos returns the fractional part of the amount in nanos, the nanos field of
+// google.type.Money. It has the sign of the amount.
+func (m Money) Nanos() int32 {
+	minor := m.Amount % int64(math.Pow10(CurrencyExponent(m.Currency)))
+	return int32(minor * nanosPerMinorUnit(m.Currency))
+}
+
+// String formats the amount with the decimals of its currency, e.g. "EUR 10.50"
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+	if exponent == 0 {
+		return fmt.Sprintf("%s %d", m.Currency, m.Amount)
+	}
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := int64(math.Pow10(exponent))
+	return fmt.Sprintf("%s %s%d.%0*d", m.Currency, sign, amount/scale, exponent, amount%scale)
+}
+
+// MoneyFromProto converts protobuf money, such as a google.type.Money, to an amount in
+// minor units. Malformed amounts, and amounts with fractions of a minor unit, return an
+// INVALID_INPUT error wrapping ErrInvalidMoney.
+func MoneyFromProto(p ProtoMoney) (Money, error) {
+	if p == nil {
+		return Money{}, errors.NewInvalidInput(ErrInvalidMoney, "money is required")
+	}
+	currency := strings.ToUpper(p.GetCurrencyCode())
+	units, nanos := p.GetUnits(), int64(p.GetNanos())
+
+	switch {
+	case !currencyPattern.MatchString(currency):
+		return Money{}, errors.NewInvalidInput(ErrInvalidMoney, fmt.Sprintf("invalid currency code %q", p.GetCurrencyCode()))
+	case nanos <= -1e9 || nanos >= 1e9:
+		return Money{}, errors.NewInvalidInput(ErrInvalidMoney, "nanos must be between -999,999,999 and 999,999,999")
+	case units > 0 && nanos < 0, units < 0 && nanos > 0:
+		return Money{}, errors.NewInvalidInput(ErrInvalidMoney, "units and nanos must have the same sign")
+	case nanos%nanosPerMinorUnit(currency) != 0:
+		return Money{}, errors.NewInvalidInput(ErrInvalidMoney, fmt.Sprintf("%s amounts cannot have more than %d decimals", currency, CurrencyExponent(currency)))
+	}
+
+	scale := int64(math.Pow10(CurrencyExponent(currency)))
+	if units > math.MaxInt64/scale || units < math.MinInt64/scale {
+		return Money{}, errors.NewInvalidInput(ErrInvalidMoney, "amount is out of range")
+	}
+	return Money{Currency: currency, Amount: units*scale + nanos/nanosPerMinorUnit(currency)}, nil
+}
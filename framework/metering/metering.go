@@ -0,0 +1,131 @@
+package metering
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Usage holds the resources consumed while serving a request
+type Usage struct {
+	DBQueries       int           `json:"db_queries"`
+	DBTime          time.Duration `json:"db_time"`
+	DownstreamCalls int           `json:"downstream_calls"`
+	DownstreamTime  time.Duration `json:"downstream_time"`
+	BytesEgress     int64         `json:"bytes_egress"`
+	CPUTime         time.Duration `json:"cpu_time"`
+}
+
+// Add accumulates other into u
+func (u *Usage) Add(other Usage) {
+	u.DBQueries += other.DBQueries
+	u.DBTime += other.DBTime
+	u.DownstreamCalls += other.DownstreamCalls
+	u.DownstreamTime += other.DownstreamTime
+	u.BytesEgress += other.BytesEgress
+	u.CPUTime += other.CPUTime
+}
+
+// Meter accumulates resource usage for a single request.
+// It is safe for concurrent use and all methods are no-ops on a nil Meter.
+type Meter struct {
+	mu    sync.Mutex
+	start time.Time
+	usage Usage
+}
+
+// NewMeter creates a new Meter starting now
+func NewMeter() *Meter {
+	return &Meter{start: time.Now()}
+}
+
+// AddDBQuery records a database query and the time spent in it
+func (m *Meter) AddDBQuery(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.DBQueries++
+	m.usage.DBTime += d
+}
+
+// AddDownstreamCall records a call to a downstream service and the time spent in it
+func (m *Meter) AddDownstreamCall(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.DownstreamCalls++
+	m.usage.DownstreamTime += d
+}
+
+// AddEgressBytes records bytes sent to the client
+func (m *Meter) AddEgressBytes(n int64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.BytesEgress += n
+}
+
+// Usage returns a snapshot of the usage recorded so far
+func (m *Meter) Usage() Usage {
+	if m == nil {
+		return Usage{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// Finish stops the meter and returns the final usage. CPU time is estimated as the
+// wall time of the request not spent waiting on the database or downstream calls.
+func (m *Meter) Finish() Usage {
+	if m == nil {
+		return Usage{}
+	}
+	elapsed := time.Since(m.start)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cpu := elapsed - m.usage.DBTime - m.usage.DownstreamTime
+	if cpu < 0 {
+		cpu = 0
+	}
+	m.usage.CPUTime = cpu
+	return m.usage
+}
+
+type meterKey struct{}
+
+// NewContext returns a copy of ctx carrying m
+func NewContext(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// FromContext returns the Meter carried by ctx, or nil if there is none
+func FromContext(ctx context.Context) *Meter {
+	if ctx == nil {
+		return nil
+	}
+	m, _ := ctx.Value(meterKey{}).(*Meter)
+	return m
+}
+
+// RecordDBQuery records a database query on the Meter carried by ctx, if any
+func RecordDBQuery(ctx context.Context, d time.Duration) {
+	FromContext(ctx).AddDBQuery(d)
+}
+
+// RecordDownstreamCall records a downstream call on the Meter carried by ctx, if any
+func RecordDownstreamCall(ctx context.Context, d time.Duration) {
+	FromContext(ctx).AddDownstreamCall(d)
+}
+
+// RecordEgressBytes records egress bytes on the Meter carried by ctx, if any
+func RecordEgressBytes(ctx context.Context, n int64) {
+	FromContext(ctx).AddEgressBytes(n)
+}
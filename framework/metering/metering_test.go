@@ -0,0 +1,124 @@
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter(t *testing.T) {
+	meter := NewMeter()
+	ctx := NewContext(context.Background(), meter)
+
+	RecordDBQuery(ctx, 10*time.Millisecond)
+	RecordDBQuery(ctx, 5*time.Millisecond)
+	RecordDownstreamCall(ctx, 20*time.Millisecond)
+	RecordEgressBytes(ctx, 512)
+
+	usage := meter.Usage()
+	assert.Equal(t, 2, usage.DBQueries)
+	assert.Equal(t, 15*time.Millisecond, usage.DBTime)
+	assert.Equal(t, 1, usage.DownstreamCalls)
+	assert.Equal(t, 20*time.Millisecond, usage.DownstreamTime)
+	assert.Equal(t, int64(512), usage.BytesEgress)
+
+	// Waiting time exceeds the elapsed wall time here, so the CPU estimate is clamped
+	assert.Equal(t, time.Duration(0), meter.Finish().CPUTime)
+}
+
+func TestRecordWithoutMeter(t *testing.T) {
+	assert.NotPanics(t, func() {
+		RecordDBQuery(context.Background(), time.Millisecond)
+		RecordDownstreamCall(context.Background(), time.Millisecond)
+		RecordEgressBytes(context.Background(), 1)
+	})
+	assert.Nil(t, FromContext(context.Background()))
+}
+
+func TestAggregator(t *testing.T) {
+	recorder := NewRecorder()
+	ctx := context.Background()
+
+	records := []Record{
+		{TenantID: "acme", APIKey: "k1", Usage: Usage{DBQueries: 1, BytesEgress: 100}},
+		{TenantID: "acme", APIKey: "k2", Usage: Usage{DBQueries: 2, BytesEgress: 50}},
+		{TenantID: "globex", Usage: Usage{DownstreamCalls: 3}},
+		{APIKey: "k1", Usage: Usage{BytesEgress: 10}},
+	}
+	for _, record := range records {
+		recorder.Emit(ctx, record)
+	}
+
+	byTenant := recorder.Aggregator().ByTenant()
+	assert.Equal(t, Total{Requests: 2, Usage: Usage{DBQueries: 3, BytesEgress: 150}}, byTenant["acme"])
+	assert.Equal(t, Total{Requests: 1, Usage: Usage{DownstreamCalls: 3}}, byTenant["globex"])
+
+	byAPIKey := recorder.Aggregator().ByAPIKey()
+	assert.Equal(t, int64(2), byAPIKey["k1"].Requests)
+	assert.Equal(t, int64(110), byAPIKey["k1"].Usage.BytesEgress)
+
+	recorder.Aggregator().Reset()
+	assert.Empty(t, recorder.Aggregator().ByTenant())
+}
+
+func TestAggregatorBoundsTenants(t *testing.T) {
+	aggregator := newAggregator(1, 0)
+	ctx := context.Background()
+
+	aggregator.Emit(ctx, Record{TenantID: "acme", Usage: Usage{DBQueries: 1}})
+	for _, tenant := range []string{"globex", "globex", "initech"} {
+		aggregator.Emit(ctx, Record{TenantID: tenant, APIKey: tenant, Usage: Usage{DBQueries: 2}})
+	}
+	assert.Equal(t, map[string]Total{
+		"acme":                        {Requests: 1, Usage: Usage{DBQueries: 1}},
+		observability.OtherLabelValue: {Requests: 3, Usage: Usage{DBQueries: 6}},
+	}, aggregator.ByTenant())
+	assert.Len(t, aggregator.ByAPIKey(), 2)
+
+	// globex outranks acme, whose total joins the others
+	aggregator.tenants.Rebalance()
+	aggregator.Emit(ctx, Record{TenantID: "globex", Usage: Usage{DBQueries: 2}})
+	assert.Equal(t, map[string]Total{
+		"globex":                      {Requests: 1, Usage: Usage{DBQueries: 2}},
+		observability.OtherLabelValue: {Requests: 4, Usage: Usage{DBQueries: 7}},
+	}, aggregator.ByTenant())
+}
+
+func TestEventSink(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	bus := events.NewEventBus(logger)
+
+	received := make(chan events.Event, 1)
+	require.NoError(t, bus.Subscribe(context.Background(), []string{UsageRecordedEvent}, func(ctx context.Context, event events.Event) error {
+		received <- event
+		return nil
+	}))
+
+	recorder := NewRecorder(NewEventSink(bus, logger))
+	recorder.Emit(context.Background(), Record{TenantID: "acme", Route: "/items", Usage: Usage{DBQueries: 1}})
+
+	select {
+	case event := <-received:
+		var record Record
+		require.NoError(t, json.Unmarshal(event.Payload, &record))
+		assert.Equal(t, "acme", record.TenantID)
+		assert.Equal(t, 1, record.Usage.DBQueries)
+	case <-time.After(time.Second):
+		t.Fatal("usage event was not published")
+	}
+}
+
+func TestAPIKeyFingerprint(t *testing.T) {
+	assert.Empty(t, APIKeyFingerprint(""))
+	assert.Len(t, APIKeyFingerprint("secret"), 16)
+	assert.Equal(t, APIKeyFingerprint("secret"), APIKeyFingerprint("secret"))
+	assert.NotEqual(t, APIKeyFingerprint("secret"), APIKeyFingerprint("other"))
+}
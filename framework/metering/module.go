@@ -0,0 +1,22 @@
+package metering
+
+import (
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the fx options for the metering module
+var Module = fx.Options(
+	fx.Provide(ProvideRecorder),
+)
+
+// ProvideRecorder provides a Recorder, logging every record when configured to
+func ProvideRecorder(cfg *config.Config, logger *observability.Logger) *Recorder {
+	recorder := NewRecorder()
+	if cfg.Observability.MeteringLogEvents {
+		recorder.AddSink(NewLogSink(logger))
+	}
+	return recorder
+}
@@ -0,0 +1,251 @@
+package metering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// UsageRecordedEvent is the topic usage records are published to by EventSink
+const UsageRecordedEvent = "metering.usage_recorded"
+
+// Record is the usage of a single completed request
+type Record struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Method    string        `json:"method"`
+	Route     string        `json:"route"`
+	Status    int           `json:"status"`
+	TenantID  string        `json:"tenant_id,omitempty"`
+	APIKey    string        `json:"api_key,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	Usage     Usage         `json:"usage"`
+}
+
+// Sink receives usage records for billing or analysis
+type Sink interface {
+	Emit(ctx context.Context, record Record)
+}
+
+// Recorder fans usage records out to sinks and keeps per-tenant and per-API-key totals
+type Recorder struct {
+	mu         sync.RWMutex
+	sinks      []Sink
+	aggregator *Aggregator
+}
+
+// NewRecorder creates a new Recorder with the given sinks
+func NewRecorder(sinks ...Sink) *Recorder {
+	return &Recorder{
+		sinks:      sinks,
+		aggregator: NewAggregator(),
+	}
+}
+
+// AddSink registers an additional sink
+func (r *Recorder) AddSink(sink Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks = append(r.sinks, sink)
+}
+
+// Emit records a usage record
+func (r *Recorder) Emit(ctx context.Context, record Record) {
+	r.aggregator.Emit(ctx, record)
+
+	r.mu.RLock()
+	sinks := r.sinks
+	r.mu.RUnlock()
+	for _, sink := range sinks {
+		sink.Emit(ctx, record)
+	}
+}
+
+// Aggregator returns the aggregated usage totals
+func (r *Recorder) Aggregator() *Aggregator {
+	return r.aggregator
+}
+
+// Total is the aggregated usage of a tenant or API key
+type Total struct {
+	Requests int64 `json:"requests"`
+	Usage    Usage `json:"usage"`
+}
+
+// AggregatorLimit is the number of distinct tenants, and of API keys, an Aggregator keeps
+// totals of. Both come from request headers, so the others are summed under
+// observability.OtherLabelValue instead of growing the totals without bound.
+const AggregatorLimit = 1000
+
+// aggregatorRebalanceInterval is how often the tenants and API keys with their own totals
+// are ranked again by traffic
+const aggregatorRebalanceInterval = 10 * time.Minute
+
+// Aggregator sums usage records per tenant and per API key
+type Aggregator struct {
+	mu       sync.Mutex
+	byTenant map[string]*Total
+	byAPIKey map[string]*Total
+	tenants  *observability.CardinalityGuard
+	apiKeys  *observability.CardinalityGuard
+}
+
+// NewAggregator creates a new Aggregator keeping the totals of the AggregatorLimit busiest
+// tenants and API keys
+func NewAggregator() *Aggregator {
+	return newAggregator(AggregatorLimit, aggregatorRebalanceInterval)
+}
+
+func newAggregator(limit int, interval time.Duration) *Aggregator {
+	a := &Aggregator{
+		byTenant: make(map[string]*Total),
+		byAPIKey: make(map[string]*Total),
+		tenants:  observability.NewCardinalityGuard(limit, interval),
+		apiKeys:  observability.NewCardinalityGuard(limit, interval),
+	}
+	// The guards rebalance within Emit, under a.mu, so the totals of the values that lose
+	// their own entry move to the other entry there
+	a.tenants.OnEvict(func(tenant string) { foldTotal(a.byTenant, tenant) })
+	a.apiKeys.OnEvict(func(apiKey string) { foldTotal(a.byAPIKey, apiKey) })
+	return a
+}
+
+// Emit adds a record to the totals
+func (a *Aggregator) Emit(ctx context.Context, record Record) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if record.TenantID != "" {
+		addTotal(a.byTenant, a.tenants.Label(record.TenantID), record.Usage)
+	}
+	if record.APIKey != "" {
+		addTotal(a.byAPIKey, a.apiKeys.Label(record.APIKey), record.Usage)
+	}
+}
+
+// ByTenant returns a snapshot of the totals per tenant
+func (a *Aggregator) ByTenant() map[string]Total {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return snapshot(a.byTenant)
+}
+
+// ByAPIKey returns a snapshot of the totals per API key fingerprint
+func (a *Aggregator) ByAPIKey() map[string]Total {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return snapshot(a.byAPIKey)
+}
+
+// Reset clears all totals, e.g. after they have been exported for a billing period
+func (a *Aggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.byTenant = make(map[string]*Total)
+	a.byAPIKey = make(map[string]*Total)
+}
+
+func addTotal(totals map[string]*Total, key string, usage Usage) {
+	total, ok := totals[key]
+	if !ok {
+		total = &Total{}
+		totals[key] = total
+	}
+	total.Requests++
+	total.Usage.Add(usage)
+}
+
+// foldTotal moves the total of key to the total of observability.OtherLabelValue
+func foldTotal(totals map[string]*Total, key string) {
+	total, ok := totals[key]
+	if !ok {
+		return
+	}
+	delete(totals, key)
+	other, ok := totals[observability.OtherLabelValue]
+	if !ok {
+		other = &Total{}
+		totals[observability.OtherLabelValue] = other
+	}
+	other.Requests += total.Requests
+	other.Usage.Add(total.Usage)
+}
+
+func snapshot(totals map[string]*Total) map[string]Total {
+	out := make(map[string]Total, len(totals))
+	for key, total := range totals {
+		out[key] = *total
+	}
+	return out
+}
+
+// LogSink writes usage records as structured log entries
+type LogSink struct {
+	logger *observability.Logger
+}
+
+// NewLogSink creates a new LogSink
+func NewLogSink(logger *observability.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Emit logs the record
+func (s *LogSink) Emit(ctx context.Context, record Record) {
+	s.logger.Info("Request usage",
+		zap.String("method", record.Method),
+		zap.String("route", record.Route),
+		zap.Int("status", record.Status),
+		zap.String("tenant_id", record.TenantID),
+		zap.String("api_key", record.APIKey),
+		zap.Duration("duration", record.Duration),
+		zap.Int("db_queries", record.Usage.DBQueries),
+		zap.Duration("db_time", record.Usage.DBTime),
+		zap.Int("downstream_calls", record.Usage.DownstreamCalls),
+		zap.Duration("downstream_time", record.Usage.DownstreamTime),
+		zap.Int64("bytes_egress", record.Usage.BytesEgress),
+		zap.Duration("cpu_time", record.Usage.CPUTime),
+	)
+}
+
+// EventSink publishes usage records as JSON events on UsageRecordedEvent
+type EventSink struct {
+	publisher events.Publisher
+	logger    *observability.Logger
+}
+
+// NewEventSink creates a new EventSink
+func NewEventSink(publisher events.Publisher, logger *observability.Logger) *EventSink {
+	return &EventSink{
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Emit publishes the record. Failures are logged and never affect the request.
+func (s *EventSink) Emit(ctx context.Context, record Record) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		s.logger.Error("Failed to marshal usage record", zap.Error(err))
+		return
+	}
+	if err := s.publisher.Publish(context.WithoutCancel(ctx), UsageRecordedEvent, payload, nil); err != nil {
+		s.logger.Warn("Failed to publish usage record", zap.Error(err))
+	}
+}
+
+// APIKeyFingerprint returns a stable, non-reversible identifier for an API key
+// so records can be attributed without storing the key itself
+func APIKeyFingerprint(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"reflect"
+
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// validatedRequestKey is the Fiber locals key holding the request bound by ValidateRequest
+const validatedRequestKey = "validated_request"
+
+// defaultValidator is shared by Bind and ValidateRequest
+var defaultValidator = validation.New()
+
+// Bind parses the request into the struct type T and validates it using go-playground/validator tags.
+// The body is used when present, otherwise query parameters; path parameters are
+// always applied. Failures are returned as framework errors with an INVALID_INPUT or
+// VALIDATION_ERROR code, ready for RespondProblem.
+func Bind[T any](c *fiber.Ctx) (T, error) {
+	return BindWith[T](c, defaultValidator)
+}
+
+// BindWith is like Bind but uses the given validator, e.g. one with custom rules registered
+func BindWith[T any](c *fiber.Ctx, v *validation.Validator) (T, error) {
+	var input T
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return input, errors.NewInvalidInput(err, "invalid request body")
+		}
+	}
+
+	// Query and path parameters can only be decoded into structs
+	if !isStruct(input) {
+		return input, nil
+	}
+
+	if len(c.Body()) == 0 {
+		if err := c.QueryParser(&input); err != nil {
+			return input, errors.NewInvalidInput(err, "invalid query parameters")
+		}
+	}
+
+	if err := c.ParamsParser(&input); err != nil {
+		return input, errors.NewInvalidInput(err, "invalid path parameters")
+	}
+
+	violations, err := v.Validate(input)
+	if err != nil {
+		err = errors.WithCode(errors.Wrap(err, "invalid request"), errors.CodeValidation)
+		return input, errors.WithMetadata(err, "violations", violations)
+	}
+
+	return input, nil
+}
+
+// ValidateRequest returns a Fiber handler that binds and validates the request into T,
+// responding with a problem document on failure. Handlers read the result with ValidatedRequest.
+func ValidateRequest[T any]() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		input, err := Bind[T](c)
+		if err != nil {
+			return RespondProblem(c, err)
+		}
+		c.Locals(validatedRequestKey, input)
+		return c.Next()
+	}
+}
+
+// ValidatedRequest returns the request bound by ValidateRequest
+func ValidatedRequest[T any](c *fiber.Ctx) T {
+	input, _ := c.Locals(validatedRequestKey).(T)
+	return input
+}
+
+// isStruct reports whether v is a struct value
+func isStruct(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	return t != nil && t.Kind() == reflect.Struct
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindTestRequest struct {
+	ID    string `json:"id" params:"id"`
+	Name  string `json:"name" query:"name" validate:"required"`
+	Email string `json:"email" query:"email" validate:"omitempty,email"`
+}
+
+func TestBind(t *testing.T) {
+	app := fiber.New()
+	app.All("/users/:id", func(c *fiber.Ctx) error {
+		req, err := Bind[bindTestRequest](c)
+		if err != nil {
+			return RespondProblem(c, err)
+		}
+		return c.JSON(req)
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantFields []string
+		want       bindTestRequest
+	}{
+		{
+			name:       "Valid JSON body",
+			method:     http.MethodPost,
+			target:     "/users/42",
+			body:       `{"name":"alice","email":"alice@example.com"}`,
+			wantStatus: http.StatusOK,
+			want:       bindTestRequest{ID: "42", Name: "alice", Email: "alice@example.com"},
+		},
+		{
+			name:       "Valid query string",
+			method:     http.MethodGet,
+			target:     "/users/7?name=bob",
+			wantStatus: http.StatusOK,
+			want:       bindTestRequest{ID: "7", Name: "bob"},
+		},
+		{
+			name:       "Validation failure",
+			method:     http.MethodPost,
+			target:     "/users/1",
+			body:       `{"email":"not-an-email"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "VALIDATION_ERROR",
+			wantFields: []string{"name", "email"},
+		},
+		{
+			name:       "Malformed body",
+			method:     http.MethodPost,
+			target:     "/users/1",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_INPUT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantStatus == http.StatusOK {
+				var got bindTestRequest
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+				assert.Equal(t, tt.want, got)
+				return
+			}
+
+			assert.Equal(t, ProblemContentType, resp.Header.Get("Content-Type"))
+			var problem Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.wantStatus, problem.Status)
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Equal(t, tt.target, problem.Instance)
+
+			fields := make([]string, 0, len(problem.Errors))
+			for _, e := range problem.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	app := fiber.New()
+	app.Post("/users", ValidateRequest[bindTestRequest](), func(c *fiber.Ctx) error {
+		return c.SendString(ValidatedRequest[bindTestRequest](c).Name)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"carol"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestNewProblemHidesInternalErrors(t *testing.T) {
+	problem := NewProblem(assert.AnError)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Equal(t, "INTERNAL_ERROR", problem.Code)
+	assert.Empty(t, problem.Detail)
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultBodyLimit is the request body limit used when none is configured, matching Fiber's default
+const DefaultBodyLimit = 4 * 1024 * 1024
+
+// bodyLimitLocal is the context key holding the body limit applied to the current request
+const bodyLimitLocal = "body_limit"
+
+// bodyLimitRoute is a per-route override of the default body limit
+type bodyLimitRoute struct {
+	routePattern
+	limit int
+}
+
+// BodyLimitMiddleware enforces request body limits per route and an optional response size limit.
+// Fiber rejects bodies above the largest configured limit on its own; this middleware applies the
+// tighter per-route limits and bounds bodies that are streamed to handlers.
+type BodyLimitMiddleware struct {
+	limit           int
+	routes          []bodyLimitRoute
+	maxResponseSize int
+	logger          *observability.Logger
+}
+
+// NewBodyLimitMiddleware creates a new body limit middleware from the HTTP configuration
+func NewBodyLimitMiddleware(cfg *config.Config, logger *observability.Logger) *BodyLimitMiddleware {
+	limit := cfg.HTTP.BodyLimit
+	if limit <= 0 {
+		limit = DefaultBodyLimit
+	}
+
+	routes := make([]bodyLimitRoute, 0, len(cfg.HTTP.BodyLimits))
+	for _, rc := range cfg.HTTP.BodyLimits {
+		routes = append(routes, bodyLimitRoute{
+			routePattern: newRoutePattern(rc.Method, rc.Path),
+			limit:        rc.Limit,
+		})
+	}
+
+	return &BodyLimitMiddleware{
+		limit:           limit,
+		routes:          routes,
+		maxResponseSize: cfg.HTTP.MaxResponseSize,
+		logger:          logger,
+	}
+}
+
+// MaxLimit returns the largest configured body limit, which Fiber must accept before routing
+func (m *BodyLimitMiddleware) MaxLimit() int {
+	maxLimit := m.limit
+	for _, route := range m.routes {
+		if route.limit > maxLimit {
+			maxLimit = route.limit
+		}
+	}
+	return maxLimit
+}
+
+// Handle returns a Fiber middleware handler applying the default limit and per-route overrides
+func (m *BodyLimitMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit := m.limit
+		for _, route := range m.routes {
+			if route.matches(c.Method(), c.Path()) {
+				limit = route.limit
+				break
+			}
+		}
+		return m.enforce(c, limit)
+	}
+}
+
+// Limit returns a Fiber handler enforcing the given body limit, for attaching to individual routes.
+// The limit cannot exceed what Fiber accepts unless request body streaming is enabled.
+func (m *BodyLimitMiddleware) Limit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return m.enforce(c, limit)
+	}
+}
+
+// enforce rejects requests over limit and checks the response size once the handler returns
+func (m *BodyLimitMiddleware) enforce(c *fiber.Ctx, limit int) error {
+	if limit > 0 {
+		if c.Request().Header.ContentLength() > limit {
+			m.closeUnreadStream(c)
+			return fiber.ErrRequestEntityTooLarge
+		}
+		// Buffered bodies of unknown length can only be checked once read
+		if !c.Request().IsBodyStream() && len(c.Request().Body()) > limit {
+			return fiber.ErrRequestEntityTooLarge
+		}
+		c.Locals(bodyLimitLocal, limit)
+	}
+
+	if err := c.Next(); err != nil {
+		m.closeUnreadStream(c)
+		return err
+	}
+	if m.maxResponseSize <= 0 {
+		return nil
+	}
+
+	if !c.Response().IsBodyStream() && len(c.Response().Body()) > m.maxResponseSize {
+		m.logger.Error("Response exceeds the maximum size",
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.Int("size", len(c.Response().Body())),
+			zap.Int("max_size", m.maxResponseSize),
+		)
+		c.Response().ResetBody()
+		return fiber.NewError(fiber.StatusInternalServerError, "response too large")
+	}
+	return nil
+}
+
+// closeUnreadStream closes the connection after a failed streamed request, since the rest of
+// its body may still be waiting to be read and would corrupt the next request on the connection
+func (m *BodyLimitMiddleware) closeUnreadStream(c *fiber.Ctx) {
+	if c.Request().IsBodyStream() {
+		c.Context().SetConnectionClose()
+	}
+}
+
+// BodyReader returns the request body as a reader. Streamed bodies are read directly from the
+// connection and fail with 413 Request Entity Too Large past the limit set by BodyLimitMiddleware.
+func BodyReader(c *fiber.Ctx) io.Reader {
+	if !c.Request().IsBodyStream() {
+		return bytes.NewReader(c.Request().Body())
+	}
+
+	stream := c.Request().BodyStream()
+	if limit, ok := c.Locals(bodyLimitLocal).(int); ok && limit > 0 {
+		return newMaxBytesReader(stream, int64(limit), fiber.ErrRequestEntityTooLarge)
+	}
+	return stream
+}
+
+// maxBytesReader reads at most n bytes and fails with err if the source has more
+type maxBytesReader struct {
+	r   io.Reader
+	n   int64
+	err error
+	hit bool
+}
+
+func newMaxBytesReader(r io.Reader, n int64, err error) *maxBytesReader {
+	return &maxBytesReader{r: r, n: n, err: err}
+}
+
+func (l *maxBytesReader) Read(p []byte) (int, error) {
+	if l.hit {
+		return 0, l.err
+	}
+	if l.n <= 0 {
+		// Probe for one more byte to tell an exact fit from an overflow
+		var b [1]byte
+		if n, _ := io.ReadFull(l.r, b[:]); n > 0 {
+			l.hit = true
+			return 0, l.err
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// exceeded reports whether the source had more than n bytes
+func (l *maxBytesReader) exceeded() bool {
+	return l.hit
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{
+			BodyLimit: 16,
+			BodyLimits: []config.BodyLimitRouteConfig{
+				{Method: "POST", Path: "/uploads*", Limit: 64},
+			},
+			MaxResponseSize: 32,
+		},
+	}
+	logger, _ := observability.NewLogger(&config.Config{})
+	m := NewBodyLimitMiddleware(cfg, logger)
+	assert.Equal(t, 64, m.MaxLimit())
+
+	app := fiber.New(fiber.Config{BodyLimit: m.MaxLimit()})
+	app.Use(m.Handle())
+	echo := func(c *fiber.Ctx) error { return c.Send(c.Body()) }
+	app.Post("/items", echo)
+	app.Post("/uploads/avatar", echo)
+	app.Post("/small", m.Limit(4), echo)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"within default limit", "/items", strings.Repeat("a", 16), http.StatusOK},
+		{"over default limit", "/items", strings.Repeat("a", 17), http.StatusRequestEntityTooLarge},
+		{"within route limit", "/uploads/avatar", strings.Repeat("a", 30), http.StatusOK},
+		{"over handler limit", "/small", strings.Repeat("a", 5), http.StatusRequestEntityTooLarge},
+		{"response too large", "/uploads/avatar", strings.Repeat("a", 33), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/"+strings.TrimPrefix(tt.path, "/"), strings.NewReader(tt.body))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestBodyReaderStreaming(t *testing.T) {
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{
+			BodyLimit:         8,
+			BodyLimits:        []config.BodyLimitRouteConfig{{Path: "/stream", Limit: 1024}},
+			StreamRequestBody: true,
+		},
+	}
+	logger, _ := observability.NewLogger(&config.Config{})
+	m := NewBodyLimitMiddleware(cfg, logger)
+
+	// Fiber only buffers up to the default limit; larger bodies are streamed
+	app := fiber.New(fiber.Config{BodyLimit: cfg.HTTP.BodyLimit, StreamRequestBody: true})
+	app.Use(m.Handle())
+	app.Post("/stream", func(c *fiber.Ctx) error {
+		n, err := io.Copy(io.Discard, BodyReader(c))
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"streamed": c.Request().IsBodyStream(), "bytes": n})
+	})
+
+	// Unread streamed bodies confuse app.Test, so serve over a real listener
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	defer app.Shutdown()
+	url := "http://" + ln.Addr().String() + "/stream"
+
+	t.Run("streams bodies over the default limit", func(t *testing.T) {
+		resp, err := http.Post(url, "application/octet-stream", bytes.NewReader(make([]byte, 1024)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"streamed":true,"bytes":1024}`, string(body))
+	})
+
+	t.Run("rejects bodies over the route limit", func(t *testing.T) {
+		resp, err := http.Post(url, "application/octet-stream", bytes.NewReader(make([]byte, 1025)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("stops reading chunked bodies at the route limit", func(t *testing.T) {
+		// Hiding the length makes the client send the body chunked
+		resp, err := http.Post(url, "application/octet-stream", io.MultiReader(bytes.NewReader(make([]byte, 2048))))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+}
+
+func TestMaxBytesReader(t *testing.T) {
+	exact := newMaxBytesReader(strings.NewReader("abcd"), 4, fiber.ErrRequestEntityTooLarge)
+	data, err := io.ReadAll(exact)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", string(data))
+	assert.False(t, exact.exceeded())
+
+	over := newMaxBytesReader(strings.NewReader("abcde"), 4, fiber.ErrRequestEntityTooLarge)
+	_, err = io.ReadAll(over)
+	assert.ErrorIs(t, err, fiber.ErrRequestEntityTooLarge)
+	assert.True(t, over.exceeded())
+}
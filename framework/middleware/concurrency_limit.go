@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ConcurrencyLimitMiddleware sheds requests beyond an adaptive concurrency limit with
+// 503 Service Unavailable, so a saturated server keeps answering the requests it accepts
+type ConcurrencyLimitMiddleware struct {
+	limiter *resilience.AdaptiveLimiter
+	exempt  map[string]bool
+	logger  *observability.Logger
+}
+
+// NewConcurrencyLimitMiddleware creates a new concurrency limit middleware from the HTTP configuration
+func NewConcurrencyLimitMiddleware(cfg *config.Config, logger *observability.Logger) *ConcurrencyLimitMiddleware {
+	return &ConcurrencyLimitMiddleware{
+		limiter: resilience.NewAdaptiveLimiter(resilience.AdaptiveLimiterOptionsFromConfig(cfg.HTTP.ConcurrencyLimit)),
+		exempt:  make(map[string]bool),
+		logger:  logger,
+	}
+}
+
+// Exempt lets requests to path through without counting them, e.g. health probes that must
+// answer under load
+func (m *ConcurrencyLimitMiddleware) Exempt(path string) {
+	m.exempt[path] = true
+}
+
+// Limiter returns the adaptive limiter, e.g. to report its limit
+func (m *ConcurrencyLimitMiddleware) Limiter() *resilience.AdaptiveLimiter {
+	return m.limiter
+}
+
+// Handle returns a Fiber middleware handler
+func (m *ConcurrencyLimitMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if m.exempt[c.Path()] {
+			return c.Next()
+		}
+
+		token, err := m.limiter.Acquire()
+		if err != nil {
+			m.logger.Debug("Request shed",
+				zap.String("method", c.Method()),
+				zap.String("path", c.Path()),
+				zap.Int("limit", m.limiter.Limit()),
+			)
+			c.Set(fiber.HeaderRetryAfter, "1")
+			return fiber.NewError(fiber.StatusServiceUnavailable, "server is overloaded")
+		}
+
+		err = c.Next()
+
+		// Timeouts and unavailability signal overload; other outcomes only feed the latency
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		}
+		switch {
+		case status == fiber.StatusRequestTimeout, status == fiber.StatusServiceUnavailable,
+			status == fiber.StatusGatewayTimeout, errors.Is(err, context.DeadlineExceeded):
+			token.Dropped()
+		default:
+			token.Success()
+		}
+		return err
+	}
+}
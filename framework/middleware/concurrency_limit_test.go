@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{
+			ConcurrencyLimit: config.ConcurrencyLimitConfig{Enabled: true, InitialLimit: 1, MaxLimit: 1},
+		},
+	}
+	m := NewConcurrencyLimitMiddleware(cfg, logger)
+	m.Exempt("/live")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app := fiber.New()
+	app.Use(m.Handle())
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendString("ok")
+	})
+	app.Get("/fast", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/live", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/timeout", func(c *fiber.Ctx) error { return fiber.ErrGatewayTimeout })
+
+	done := make(chan int, 1)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+	<-started
+
+	// The only slot is taken: requests are shed, probes still answer
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/fast", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/live", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Eventually(t, func() bool { return m.Limiter().InFlight() == 0 }, time.Second, time.Millisecond)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/fast", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Timeouts count as drops and release their slot
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/timeout", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.Equal(t, 0, m.Limiter().InFlight())
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/ctxkit"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultDebugTraceHeader is the header requesting debug tracing when none is configured
+const DefaultDebugTraceHeader = "X-Debug-Trace"
+
+// baggageHeader is the W3C header carrying OTel baggage
+const baggageHeader = "Baggage"
+
+// DebugTraceMiddleware lets allowed callers force the sampling of a request in every service
+// it reaches. When an allowed user or role sends the debug trace header, the middleware adds
+// the ctxkit.DebugTraceMember baggage member, which the sampler honors and the clients
+// forward with the trace context. The member is dropped from the baggage of other callers.
+type DebugTraceMiddleware struct {
+	enabled      bool
+	header       string
+	users        map[string]bool
+	roles        map[string]bool
+	trustBaggage bool
+	jwtService   *auth.JWTService
+	logger       *observability.Logger
+}
+
+// NewDebugTraceMiddleware creates the debug trace middleware from the observability configuration
+func NewDebugTraceMiddleware(cfg *config.Config, jwtService *auth.JWTService, logger *observability.Logger) *DebugTraceMiddleware {
+	debugCfg := cfg.Observability.DebugTrace
+	m := &DebugTraceMiddleware{
+		enabled:      debugCfg.Enabled,
+		header:       valueOr(debugCfg.Header, DefaultDebugTraceHeader),
+		users:        make(map[string]bool, len(debugCfg.Users)),
+		roles:        make(map[string]bool, len(debugCfg.Roles)),
+		trustBaggage: debugCfg.TrustBaggage,
+		jwtService:   jwtService,
+		logger:       logger,
+	}
+	for _, user := range debugCfg.Users {
+		m.users[user] = true
+	}
+	for _, role := range debugCfg.Roles {
+		m.roles[role] = true
+	}
+	return m
+}
+
+// Handle returns a Fiber middleware handler. It must run before the tracing middleware, so
+// that the server span is sampled. Debug traced responses return their trace ID in the
+// debug trace header.
+func (m *DebugTraceMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		allowed := m.enabled && requested(c.Get(m.header)) && m.allowed(c)
+		m.rewriteBaggage(c, allowed)
+		if !allowed {
+			return c.Next()
+		}
+
+		err := c.Next()
+		if span := trace.SpanContextFromContext(c.UserContext()); span.IsValid() {
+			c.Set(m.header, span.TraceID().String())
+			m.logger.Info("Debug traced request",
+				zap.String("trace_id", span.TraceID().String()),
+				zap.String("method", c.Method()),
+				zap.String("path", c.Path()),
+			)
+		}
+		return err
+	}
+}
+
+// requested reports whether a debug trace header value asks for debug tracing
+func requested(value string) bool {
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// allowed reports whether the bearer token of the request belongs to an allowed user or role.
+// The token is validated here because the authentication middleware runs after spans start.
+func (m *DebugTraceMiddleware) allowed(c *fiber.Ctx) bool {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" || m.jwtService == nil {
+		return false
+	}
+	claims, err := m.jwtService.ValidateToken(token)
+	if err != nil {
+		return false
+	}
+	if m.users[claims.UserID] {
+		return true
+	}
+	for _, role := range claims.Roles {
+		if m.roles[role] {
+			return true
+		}
+	}
+	m.logger.Warn("Debug tracing denied", zap.String("user_id", claims.UserID))
+	return false
+}
+
+// rewriteBaggage adds the debug trace member to the baggage header of allowed requests, and
+// drops the member callers sent unless their baggage is trusted
+func (m *DebugTraceMiddleware) rewriteBaggage(c *fiber.Ctx, allowed bool) {
+	header := c.Get(baggageHeader)
+	if !allowed && (m.trustBaggage || !strings.Contains(header, ctxkit.DebugTraceMember)) {
+		return
+	}
+	bag, err := baggage.Parse(header)
+	if err != nil {
+		// The propagator ignores invalid baggage as well
+		bag = baggage.Baggage{}
+	}
+	if !m.trustBaggage {
+		bag = bag.DeleteMember(ctxkit.DebugTraceMember)
+	}
+	if allowed {
+		member, _ := baggage.NewMemberRaw(ctxkit.DebugTraceMember, "1")
+		bag, _ = bag.SetMember(member)
+	}
+	if bag.Len() == 0 {
+		c.Request().Header.Del(baggageHeader)
+		return
+	}
+	c.Request().Header.Set(baggageHeader, bag.String())
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/ctxkit"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDebugTrace(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	jwtService := auth.NewJWTService("test-secret", time.Hour)
+	sre, _ := jwtService.GenerateToken("user-1", "ada", "ada@example.com", []string{"sre"})
+	oncall, _ := jwtService.GenerateToken("user-2", "bob", "bob@example.com", nil)
+	other, _ := jwtService.GenerateToken("user-3", "eve", "eve@example.com", []string{"user"})
+
+	tests := []struct {
+		name         string
+		trustBaggage bool
+		token        string
+		header       string
+		baggage      string
+		sampled      bool
+		wantBaggage  map[string]string
+	}{
+		{"allowed role", false, sre, "1", "", true, map[string]string{ctxkit.DebugTraceMember: "1"}},
+		{"allowed user", false, oncall, "true", "feature.checkout=v2", true, map[string]string{ctxkit.DebugTraceMember: "1", "feature.checkout": "v2"}},
+		{"not requested", false, sre, "", "", false, map[string]string{}},
+		{"not allowed", false, other, "1", "", false, map[string]string{}},
+		{"invalid token", false, "invalid", "1", "", false, map[string]string{}},
+		{"anonymous", false, "", "1", "", false, map[string]string{}},
+		{"untrusted baggage dropped", false, "", "", ctxkit.DebugTraceMember + "=1,feature.checkout=v2", false, map[string]string{"feature.checkout": "v2"}},
+		{"trusted baggage", true, "", "", ctxkit.DebugTraceMember + "=1", true, map[string]string{ctxkit.DebugTraceMember: "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Observability.DebugTrace = config.DebugTraceConfig{
+				Enabled:      true,
+				Users:        []string{"user-2"},
+				Roles:        []string{"sre"},
+				TrustBaggage: tt.trustBaggage,
+			}
+			logger, _ := observability.NewLogger(cfg)
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(observability.NewDebugSampler(sdktrace.NeverSample())),
+				sdktrace.WithSpanProcessor(recorder),
+			)
+
+			var members map[string]string
+			app := fiber.New()
+			app.Use(NewDebugTraceMiddleware(cfg, jwtService, logger).Handle())
+			app.Use(NewTracingMiddleware(&observability.Tracer{Tracer: provider.Tracer("test")}).Handle())
+			app.Get("/orders", func(c *fiber.Ctx) error {
+				members = ctxkit.BaggageMembers(c.UserContext())
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/orders", nil)
+			if tt.token != "" {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			if tt.header != "" {
+				req.Header.Set(DefaultDebugTraceHeader, tt.header)
+			}
+			if tt.baggage != "" {
+				req.Header.Set("baggage", tt.baggage)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			assert.Equal(t, tt.wantBaggage, members)
+			if tt.sampled {
+				require.Len(t, recorder.Ended(), 1)
+				traceID := recorder.Ended()[0].SpanContext().TraceID().String()
+				if tt.header != "" {
+					assert.Equal(t, traceID, resp.Header.Get(DefaultDebugTraceHeader))
+				}
+			} else {
+				assert.Empty(t, recorder.Ended())
+				assert.Empty(t, resp.Header.Get(DefaultDebugTraceHeader))
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/axiomod/axiomod/framework/degradation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Degradation collects the results served from fallbacks while handling a request and
+// lists them in the X-Degraded response header
+func Degradation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, report := degradation.NewContext(c.UserContext())
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		if report.Degraded() {
+			c.Set(degradation.Header, report.Header())
+		}
+		return err
+	}
+}
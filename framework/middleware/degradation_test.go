@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/degradation"
+	"github.com/axiomod/axiomod/framework/resilience"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegradation(t *testing.T) {
+	fallback := degradation.New("products.list", nil, degradation.Default([]string{}))
+
+	app := fiber.New()
+	app.Use(Degradation())
+	app.Get("/products", func(c *fiber.Ctx) error {
+		result, err := fallback.Execute(c.UserContext(), func(ctx context.Context) ([]string, error) {
+			if c.Query("down") != "" {
+				return nil, resilience.ErrCircuitOpen
+			}
+			return []string{"book"}, nil
+		})
+		if err != nil {
+			return err
+		}
+		return c.JSON(result.Value)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/products", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(degradation.Header))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/products?down=1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "products.list=default", resp.Header.Get(degradation.Header))
+}
@@ -0,0 +1,304 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// guardMaxFailures is how many failed attempts a client may make within guardFailureWindow
+	guardMaxFailures = 5
+	// guardFailureWindow is both the window failures are counted in and the lockout that follows
+	guardFailureWindow = time.Minute
+	// guardMaxTrackedClients bounds the memory used to track failed attempts
+	guardMaxTrackedClients = 10000
+)
+
+// Endpoint guard errors
+var (
+	ErrEndpointCredentialsMissing = errors.New("credentials required")
+	ErrEndpointCredentialsInvalid = errors.New("invalid credentials")
+	ErrEndpointIPNotAllowed       = errors.New("client address not allowed")
+	ErrEndpointLockedOut          = errors.New("too many failed attempts")
+)
+
+// EndpointGuards protects the operational endpoints served next to the API
+type EndpointGuards struct {
+	Metrics       *EndpointGuard
+	HealthDetails *EndpointGuard
+	Admin         *EndpointGuard
+}
+
+// NewEndpointGuards creates the guards for the metrics, health detail and admin endpoints
+func NewEndpointGuards(cfg *config.Config, logger *observability.Logger) (*EndpointGuards, error) {
+	metrics, err := NewEndpointGuard("metrics", cfg.HTTP.Endpoints.Metrics, logger)
+	if err != nil {
+		return nil, err
+	}
+	healthDetails, err := NewEndpointGuard("healthDetails", cfg.HTTP.Endpoints.HealthDetails, logger)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := NewEndpointGuard("admin", cfg.HTTP.Endpoints.Admin, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &EndpointGuards{
+		Metrics:       metrics,
+		HealthDetails: healthDetails,
+		Admin:         admin,
+	}, nil
+}
+
+// EndpointGuard restricts an endpoint by client address and basic or bearer credentials.
+// Credentials are compared in constant time, and clients are locked out for a minute after
+// repeated failures to make guessing impractical.
+type EndpointGuard struct {
+	name      string
+	mode      string
+	username  [32]byte
+	password  [32]byte
+	token     [32]byte
+	allowlist []*net.IPNet
+	failures  *failureTracker
+	logger    *observability.Logger
+}
+
+// NewEndpointGuard creates a guard for the named endpoint
+func NewEndpointGuard(name string, cfg config.EndpointAuthConfig, logger *observability.Logger) (*EndpointGuard, error) {
+	mode := strings.ToLower(cfg.Auth)
+	if mode == "" {
+		mode = "none"
+	}
+
+	g := &EndpointGuard{
+		name:     name,
+		mode:     mode,
+		failures: newFailureTracker(),
+		logger:   logger,
+	}
+
+	switch mode {
+	case "none":
+	case "basic":
+		if cfg.Username == "" || cfg.Password == "" {
+			return nil, fmt.Errorf("%s endpoint: basic auth requires a username and password", name)
+		}
+		g.username = sha256.Sum256([]byte(cfg.Username))
+		g.password = sha256.Sum256([]byte(cfg.Password))
+	case "bearer":
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("%s endpoint: bearer auth requires a token", name)
+		}
+		g.token = sha256.Sum256([]byte(cfg.Token))
+	default:
+		return nil, fmt.Errorf("%s endpoint: unknown auth mode %q", name, cfg.Auth)
+	}
+
+	for _, entry := range cfg.AllowedIPs {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s endpoint: invalid allowed address %q: %w", name, entry, err)
+		}
+		g.allowlist = append(g.allowlist, network)
+	}
+
+	return g, nil
+}
+
+// Enabled reports whether the guard restricts access at all
+func (g *EndpointGuard) Enabled() bool {
+	return g.mode != "none" || len(g.allowlist) > 0
+}
+
+// Handle returns a Fiber middleware handler that rejects unauthorized requests
+func (g *EndpointGuard) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := g.Check(c); err != nil {
+			return g.Reject(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// Check verifies the client address and credentials of the request
+func (g *EndpointGuard) Check(c *fiber.Ctx) error {
+	if len(g.allowlist) > 0 && !g.allowed(net.ParseIP(c.IP())) {
+		return ErrEndpointIPNotAllowed
+	}
+	if g.mode == "none" {
+		return nil
+	}
+
+	client := c.IP()
+	if g.failures.locked(client) {
+		return ErrEndpointLockedOut
+	}
+
+	header := c.Get(fiber.HeaderAuthorization)
+	if header == "" {
+		return ErrEndpointCredentialsMissing
+	}
+
+	if !g.verify(header) {
+		g.failures.add(client)
+		g.logger.Warn("Rejected credentials for protected endpoint",
+			zap.String("endpoint", g.name),
+			zap.String("ip", client),
+		)
+		return ErrEndpointCredentialsInvalid
+	}
+	return nil
+}
+
+// Reject returns the error response matching an error from Check
+func (g *EndpointGuard) Reject(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrEndpointIPNotAllowed):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrEndpointLockedOut):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(guardFailureWindow.Seconds())))
+		return fiber.NewError(fiber.StatusTooManyRequests, err.Error())
+	default:
+		if g.mode == "basic" {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="`+g.name+`"`)
+		} else {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		}
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+}
+
+// verify compares the Authorization header against the configured credentials in constant time
+func (g *EndpointGuard) verify(header string) bool {
+	switch g.mode {
+	case "basic":
+		username, password, ok := parseBasicAuth(header)
+		if !ok {
+			return false
+		}
+		u := sha256.Sum256([]byte(username))
+		p := sha256.Sum256([]byte(password))
+		// Evaluate both comparisons so timing does not reveal which one failed
+		return subtle.ConstantTimeCompare(u[:], g.username[:])&subtle.ConstantTimeCompare(p[:], g.password[:]) == 1
+	case "bearer":
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return false
+		}
+		t := sha256.Sum256([]byte(token))
+		return subtle.ConstantTimeCompare(t[:], g.token[:]) == 1
+	}
+	return false
+}
+
+// parseBasicAuth extracts the credentials of a Basic Authorization header
+func parseBasicAuth(header string) (username, password string, ok bool) {
+	encoded, ok := strings.CutPrefix(header, "Basic ")
+	if !ok {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// allowed reports whether ip is in the allowlist
+func (g *EndpointGuard) allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range g.allowlist {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// failureTracker counts failed attempts per client within a fixed window
+type failureTracker struct {
+	mu      sync.Mutex
+	clients map[string]*failureWindow
+	now     func() time.Time
+}
+
+type failureWindow struct {
+	start time.Time
+	count int
+}
+
+func newFailureTracker() *failureTracker {
+	return &failureTracker{
+		clients: make(map[string]*failureWindow),
+		now:     time.Now,
+	}
+}
+
+// locked reports whether client exceeded the allowed failures in the current window
+func (t *failureTracker) locked(client string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, ok := t.clients[client]
+	if !ok {
+		return false
+	}
+	if t.now().Sub(w.start) >= guardFailureWindow {
+		delete(t.clients, client)
+		return false
+	}
+	return w.count >= guardMaxFailures
+}
+
+// add records a failed attempt by client
+func (t *failureTracker) add(client string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	w, ok := t.clients[client]
+	if !ok || now.Sub(w.start) >= guardFailureWindow {
+		if len(t.clients) >= guardMaxTrackedClients {
+			t.evictExpired(now)
+		}
+		w = &failureWindow{start: now}
+		t.clients[client] = w
+	}
+	w.count++
+}
+
+// evictExpired drops expired windows, or all of them if none have expired
+func (t *failureTracker) evictExpired(now time.Time) {
+	for client, w := range t.clients {
+		if now.Sub(w.start) >= guardFailureWindow {
+			delete(t.clients, client)
+		}
+	}
+	if len(t.clients) >= guardMaxTrackedClients {
+		t.clients = make(map[string]*failureWindow)
+	}
+}
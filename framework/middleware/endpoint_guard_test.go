@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func TestEndpointGuard(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	tests := []struct {
+		name   string
+		cfg    config.EndpointAuthConfig
+		header string
+		status int
+	}{
+		{"none", config.EndpointAuthConfig{}, "", http.StatusOK},
+		{"basic valid", config.EndpointAuthConfig{Auth: "basic", Username: "prom", Password: "s3cret"}, basicAuth("prom", "s3cret"), http.StatusOK},
+		{"basic wrong password", config.EndpointAuthConfig{Auth: "basic", Username: "prom", Password: "s3cret"}, basicAuth("prom", "guess"), http.StatusUnauthorized},
+		{"basic missing", config.EndpointAuthConfig{Auth: "basic", Username: "prom", Password: "s3cret"}, "", http.StatusUnauthorized},
+		{"bearer valid", config.EndpointAuthConfig{Auth: "bearer", Token: "tok"}, "Bearer tok", http.StatusOK},
+		{"bearer wrong", config.EndpointAuthConfig{Auth: "bearer", Token: "tok"}, "Bearer other", http.StatusUnauthorized},
+		// app.Test requests come from 0.0.0.0
+		{"allowlisted", config.EndpointAuthConfig{AllowedIPs: []string{"0.0.0.0/8"}}, "", http.StatusOK},
+		{"not allowlisted", config.EndpointAuthConfig{AllowedIPs: []string{"10.0.0.1"}}, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewEndpointGuard("metrics", tt.cfg, logger)
+			require.NoError(t, err)
+
+			app := fiber.New()
+			app.Get("/metrics", g.Handle(), func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.status == http.StatusUnauthorized {
+				assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := NewEndpointGuard("metrics", config.EndpointAuthConfig{Auth: "basic"}, logger)
+		assert.Error(t, err)
+		_, err = NewEndpointGuard("metrics", config.EndpointAuthConfig{Auth: "digest"}, logger)
+		assert.Error(t, err)
+		_, err = NewEndpointGuard("metrics", config.EndpointAuthConfig{AllowedIPs: []string{"not-an-ip"}}, logger)
+		assert.Error(t, err)
+	})
+}
+
+func TestEndpointGuardLockout(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	g, err := NewEndpointGuard("metrics", config.EndpointAuthConfig{Auth: "bearer", Token: "tok"}, logger)
+	require.NoError(t, err)
+
+	now := time.Now()
+	g.failures.now = func() time.Time { return now }
+
+	app := fiber.New()
+	app.Get("/metrics", g.Handle(), func(c *fiber.Ctx) error { return c.SendString("ok") })
+	request := func(token string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	for i := 0; i < guardMaxFailures; i++ {
+		assert.Equal(t, http.StatusUnauthorized, request("guess").StatusCode)
+	}
+
+	// Even the right token is refused while locked out
+	resp := request("tok")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	now = now.Add(guardFailureWindow)
+	assert.Equal(t, http.StatusOK, request("tok").StatusCode)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/i18n"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ErrorHandler converts errors returned by handlers into RFC 7807 problem responses
+type ErrorHandler struct {
+	logger     *observability.Logger
+	production bool
+}
+
+// NewErrorHandler creates a new error handler. Stack traces are included in
+// responses only outside the production environment.
+func NewErrorHandler(cfg *config.Config, logger *observability.Logger) *ErrorHandler {
+	return &ErrorHandler{
+		logger:     logger,
+		production: cfg.App.Environment == "production",
+	}
+}
+
+// Handler returns a handler to be used as fiber.Config.ErrorHandler
+func (h *ErrorHandler) Handler() fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		problem := NewProblem(err)
+		problem.Instance = c.Path()
+
+		spanCtx := trace.SpanContextFromContext(c.UserContext())
+		if spanCtx.HasTraceID() {
+			problem.TraceID = spanCtx.TraceID().String()
+		}
+		if !h.production {
+			problem.Stack = errors.GetStack(err)
+		}
+		if localizer := i18n.FromContext(c.UserContext()); localizer != nil {
+			localize(&problem, err, localizer)
+			c.Set(fiber.HeaderContentLanguage, localizer.Locale())
+			c.Vary(fiber.HeaderAcceptLanguage)
+		}
+
+		h.log(c, err, problem, spanCtx)
+
+		return WriteProblem(c, problem)
+	}
+}
+
+// Handle returns a Fiber middleware handler that applies the error handler to a
+// route group, for apps that do not install it as the global ErrorHandler
+func (h *ErrorHandler) Handle() fiber.Handler {
+	handler := h.Handler()
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return handler(c, err)
+		}
+		return nil
+	}
+}
+
+// localize translates the title of a problem, with the "status.<status>" message, and its
+// detail, with the "errors.<code>" message. The metadata of the error are the arguments of
+// the detail message; problems without a message keep their detail.
+func localize(problem *Problem, err error, localizer *i18n.Localizer) {
+	if title, ok := localizer.Translate("status."+strconv.Itoa(problem.Status), nil); ok {
+		problem.Title = title
+	}
+	if problem.Code == "" {
+		return
+	}
+	if detail, ok := localizer.Translate("errors."+problem.Code, errors.GetMetadata(err)); ok {
+		problem.Detail = detail
+	}
+}
+
+// log records server errors at error level and client errors at debug level
+func (h *ErrorHandler) log(c *fiber.Ctx, err error, problem Problem, spanCtx trace.SpanContext) {
+	fields := []zap.Field{
+		zap.Error(err),
+		zap.String("code", problem.Code),
+		zap.Int("status", problem.Status),
+		zap.String("method", c.Method()),
+		zap.String("path", c.Path()),
+	}
+	if requestID := RequestID(c); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if spanCtx.HasTraceID() {
+		fields = append(fields, zap.String("trace_id", spanCtx.TraceID().String()))
+	}
+	if spanCtx.HasSpanID() {
+		fields = append(fields, zap.String("span_id", spanCtx.SpanID().String()))
+	}
+
+	logger := h.logger.WithTenant(TenantID(c))
+	if problem.Status >= http.StatusInternalServerError {
+		if stack := errors.GetStack(err); stack != "" {
+			fields = append(fields, zap.String("stack", stack))
+		}
+		logger.Error("Request failed", fields...)
+		return
+	}
+	logger.Debug("Request rejected", fields...)
+}
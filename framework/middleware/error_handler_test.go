@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	tests := []struct {
+		name        string
+		environment string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantDetail  string
+		wantStack   bool
+		wantMeta    map[string]interface{}
+	}{
+		{
+			name:       "Framework client error",
+			err:        errors.WithMetadata(errors.WithCode(errors.New("user not found"), errors.CodeNotFound), "user_id", "42"),
+			wantStatus: http.StatusNotFound,
+			wantCode:   errors.CodeNotFound,
+			wantDetail: "user not found",
+			wantStack:  true,
+			wantMeta:   map[string]interface{}{"user_id": "42"},
+		},
+		{
+			name:       "Fiber error",
+			err:        fiber.NewError(http.StatusUnauthorized, "missing token"),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   errors.CodeUnauthorized,
+			wantDetail: "missing token",
+		},
+		{
+			name:       "Internal error hides message",
+			err:        errors.NewInternal(assert.AnError, "db exploded"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   errors.CodeInternal,
+			wantStack:  true,
+		},
+		{
+			name:        "Production hides stack",
+			environment: "production",
+			err:         errors.NewInternal(assert.AnError, "db exploded"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    errors.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{App: config.AppConfig{Environment: tt.environment}}
+			h := NewErrorHandler(cfg, logger)
+
+			app := fiber.New(fiber.Config{ErrorHandler: h.Handler()})
+			app.Get("/", func(c *fiber.Ctx) error {
+				return tt.err
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, ProblemContentType, resp.Header.Get("Content-Type"))
+
+			var problem Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Equal(t, tt.wantDetail, problem.Detail)
+			assert.Equal(t, tt.wantStack, problem.Stack != "")
+			assert.Equal(t, tt.wantMeta, problem.Metadata)
+		})
+	}
+}
+
+func TestErrorHandlerMiddleware(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	h := NewErrorHandler(&config.Config{}, logger)
+
+	app := fiber.New()
+	app.Use(h.Handle())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return errors.WithCode(errors.New("already exists"), errors.CodeConflict)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, ProblemContentType, resp.Header.Get("Content-Type"))
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// FaultInjectionMiddleware injects the faults of a chaos.Injector in the requests they match:
+// latency before the request is handled, error responses and reset connections
+type FaultInjectionMiddleware struct {
+	injector *chaos.Injector
+	exempt   []routePattern
+	logger   *observability.Logger
+}
+
+// NewFaultInjectionMiddleware creates a new fault injection middleware
+func NewFaultInjectionMiddleware(injector *chaos.Injector, logger *observability.Logger) *FaultInjectionMiddleware {
+	return &FaultInjectionMiddleware{injector: injector, logger: logger}
+}
+
+// Enabled reports whether fault injection is enabled in the configuration
+func (m *FaultInjectionMiddleware) Enabled() bool {
+	return m.injector.Enabled()
+}
+
+// Exempt keeps faults away from path, a prefix when ending with "*", e.g. the probes and the
+// admin endpoints ending experiments
+func (m *FaultInjectionMiddleware) Exempt(path string) {
+	m.exempt = append(m.exempt, newRoutePattern("", path))
+}
+
+// Handle returns a Fiber middleware handler
+func (m *FaultInjectionMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, route := range m.exempt {
+			if route.matches(c.Method(), c.Path()) {
+				return c.Next()
+			}
+		}
+		injection, ok := m.injector.Inject(c.Method(), c.Path())
+		if !ok {
+			return c.Next()
+		}
+
+		m.logger.Debug("Injecting fault",
+			zap.String("fault", injection.Fault),
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+		)
+		if err := m.injector.Wait(c.UserContext(), injection); err != nil {
+			return err
+		}
+		switch {
+		case injection.Reset:
+			resetConnection(c)
+			return nil
+		case injection.Status != 0:
+			return fiber.NewError(injection.Status, "fault injected: "+injection.Fault)
+		}
+		return c.Next()
+	}
+}
+
+// resetConnection drops the connection of the request without a response, with a TCP reset
+// where the connection allows it
+func resetConnection(c *fiber.Ctx) {
+	c.Context().HijackSetNoResponse(true)
+	c.Context().Hijack(func(conn net.Conn) {
+		if tcp, ok := conn.(interface{ SetLinger(sec int) error }); ok {
+			_ = tcp.SetLinger(0)
+		}
+		_ = conn.Close()
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	injector := chaos.NewInjector(true, logger)
+	require.NoError(t, injector.SetFaults([]chaos.Fault{
+		{Name: "orders-down", Method: "POST", Path: "/api/orders", ErrorRate: 1, ErrorStatus: http.StatusBadGateway},
+		{Name: "payments-reset", Path: "/api/payments", ResetRate: 1},
+		{Name: "everything", Path: "/*", ErrorRate: 1},
+	}))
+	m := NewFaultInjectionMiddleware(injector, logger)
+	m.Exempt("/live")
+	m.Exempt("/admin/*")
+	assert.True(t, m.Enabled())
+
+	app := fiber.New()
+	app.Use(m.Handle())
+	handler := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Post("/api/orders", handler)
+	app.Get("/api/payments", handler)
+	app.Get("/api/users", handler)
+	app.Get("/live", handler)
+	app.Get("/admin/faults", handler)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: http.MethodPost, path: "/api/orders", want: http.StatusBadGateway},
+		{method: http.MethodGet, path: "/api/users", want: http.StatusServiceUnavailable},
+		{method: http.MethodGet, path: "/live", want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/faults", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, resp.StatusCode, tt.path)
+	}
+
+	// Reset connections get no response
+	_, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/payments", nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/axiomod/axiomod/framework/featureflags"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeatureFlagContext evaluates feature flags in handlers for the authenticated user, their
+// roles and their tenant, taken from the tenant of the request or else tenantHeader. It
+// must run after authentication.
+func FeatureFlagContext(tenantHeader string) fiber.Handler {
+	if tenantHeader == "" {
+		tenantHeader = "X-Tenant-ID"
+	}
+	return func(c *fiber.Ctx) error {
+		ec := featureflags.EvalContext{Attributes: make(map[string]string)}
+		ec.UserID, _ = c.Locals("user_id").(string)
+		ec.Roles, _ = c.Locals("roles").([]string)
+		for _, name := range []string{"username", "email"} {
+			if value, _ := c.Locals(name).(string); value != "" {
+				ec.Attributes[name] = value
+			}
+		}
+		ec.TenantID = TenantID(c)
+		if ec.TenantID == "" {
+			ec.TenantID = c.Get(tenantHeader)
+		}
+
+		c.SetUserContext(featureflags.NewContext(c.UserContext(), ec))
+		return c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/featureflags"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagContext(t *testing.T) {
+	flags := featureflags.NewFlags(featureflags.NewStaticSource(map[string]featureflags.Flag{
+		"newCheckout": {Enabled: true, Users: []string{"alice"}, Tenants: []string{"acme"}, Rollout: 1},
+	}), nil)
+
+	var got featureflags.EvalContext
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		// Stands in for the authentication middleware
+		if c.Get("Authorization") != "" {
+			c.Locals("user_id", "alice")
+			c.Locals("email", "alice@acme.test")
+			c.Locals("roles", []string{"admin"})
+		}
+		return c.Next()
+	})
+	app.Use(FeatureFlagContext(""))
+	app.Get("/checkout", func(c *fiber.Ctx) error {
+		got = featureflags.FromContext(c.UserContext())
+		if flags.BoolFlag(c.UserContext(), "newCheckout", false) {
+			return c.SendString("new")
+		}
+		return c.SendString("old")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/checkout", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "new", string(body))
+	assert.Equal(t, featureflags.EvalContext{
+		UserID:     "alice",
+		Roles:      []string{"admin"},
+		Attributes: map[string]string{"email": "alice@acme.test"},
+	}, got)
+
+	req = httptest.NewRequest(http.MethodGet, "/checkout", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "new", string(body))
+	assert.Equal(t, "acme", got.TenantID)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/checkout", nil))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "old", string(body))
+}
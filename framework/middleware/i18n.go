@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/axiomod/axiomod/framework/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Localization negotiates the locale of the request from its Accept-Language header among the
+// locales of catalog. Handlers get the localizer with i18n.FromContext(c.UserContext()), and
+// the error handler uses it to localize error responses.
+func Localization(catalog *i18n.Catalog) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		localizer := catalog.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+		c.SetUserContext(i18n.NewContext(c.UserContext(), localizer))
+		return c.Next()
+	}
+}
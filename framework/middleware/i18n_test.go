@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/i18n"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizedErrors(t *testing.T) {
+	catalog, err := i18n.NewCatalog("en")
+	require.NoError(t, err)
+	require.NoError(t, catalog.LoadFS(fstest.MapFS{
+		"fr.yaml": {Data: []byte(`
+errors:
+  INVALID_INPUT:
+    one: "Une commande contient au plus {count} article"
+    other: "Une commande contient au plus {count} articles"
+`)},
+	}, "."))
+
+	logger, _ := observability.NewLogger(&config.Config{})
+	app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler(&config.Config{}, logger).Handler()})
+	app.Use(Localization(catalog))
+	app.Post("/orders", func(c *fiber.Ctx) error {
+		return errors.WithMetadata(errors.WithCode(errors.New("too many items"), errors.CodeInvalidInput), "count", 10)
+	})
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		return errors.WithCode(errors.New("order not found"), errors.CodeNotFound)
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		acceptLanguage string
+		language       string
+		title          string
+		detail         string
+	}{
+		{"localized detail", http.MethodPost, "/orders", "fr-CA", "fr", "Requête invalide", "Une commande contient au plus 10 articles"},
+		{"detail without message", http.MethodGet, "/orders/7", "fr", "fr", "Introuvable", "order not found"},
+		{"default locale", http.MethodPost, "/orders", "ja", "en", "Bad Request", "too many items"},
+		{"fiber error", http.MethodGet, "/missing", "es", "es", "No encontrado", "Cannot GET /missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.language, resp.Header.Get(fiber.HeaderContentLanguage))
+			assert.Contains(t, resp.Header.Get(fiber.HeaderVary), fiber.HeaderAcceptLanguage)
+
+			var problem Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.title, problem.Title)
+			assert.Equal(t, tt.detail, problem.Detail)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/cache"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// IdempotencyReplayedHeader is set on responses replayed from a stored record
+const IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyInFlightTTL bounds how long a key stays locked if the first request never completes
+const idempotencyInFlightTTL = time.Minute
+
+// idempotencySkippedHeaders are response headers that are not replayed
+var idempotencySkippedHeaders = map[string]bool{
+	fiber.HeaderDate:          true,
+	fiber.HeaderContentLength: true,
+	fiber.HeaderSetCookie:     true,
+	fiber.HeaderConnection:    true,
+}
+
+// IdempotencyMiddleware replays the stored response of requests retried with the same
+// Idempotency-Key. It is opt-in: attach Handle() to the POST/PUT/PATCH routes that need it.
+type IdempotencyMiddleware struct {
+	store    IdempotencyStore
+	header   string
+	ttl      time.Duration
+	required bool
+	logger   *observability.Logger
+}
+
+// NewIdempotencyMiddleware creates a new idempotency middleware from the HTTP idempotency
+// configuration. The redis backend stores the records with client, the client of redis.Module.
+func NewIdempotencyMiddleware(cfg *config.Config, logger *observability.Logger, client redis.UniversalClient) (*IdempotencyMiddleware, error) {
+	idCfg := cfg.HTTP.Idempotency
+
+	header := idCfg.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	ttl := time.Duration(idCfg.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	var store IdempotencyStore
+	if idCfg.Backend == "redis" {
+		if client == nil {
+			return nil, fmt.Errorf("idempotency: %w", axredis.ErrNoClient)
+		}
+		store = NewRedisIdempotencyStore(client, "idempotency")
+	} else {
+		store = NewCacheIdempotencyStore(cache.NewMemoryCache(10000))
+	}
+
+	return &IdempotencyMiddleware{
+		store:    store,
+		header:   header,
+		ttl:      ttl,
+		required: idCfg.Required,
+		logger:   logger,
+	}, nil
+}
+
+// WithStore replaces the record store, e.g. to share a Redis client
+func (m *IdempotencyMiddleware) WithStore(store IdempotencyStore) *IdempotencyMiddleware {
+	m.store = store
+	return m
+}
+
+// Handle returns a Fiber middleware handler
+func (m *IdempotencyMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		key := strings.TrimSpace(c.Get(m.header))
+		if key == "" {
+			if m.required {
+				return fiber.NewError(fiber.StatusBadRequest, m.header+" header is required")
+			}
+			return c.Next()
+		}
+		if len(key) > 255 {
+			return fiber.NewError(fiber.StatusBadRequest, m.header+" header is too long")
+		}
+
+		storeKey := m.storeKey(c, key)
+		fingerprint := requestFingerprint(c)
+
+		existing, err := m.store.Claim(c.UserContext(), storeKey, IdempotencyRecord{Fingerprint: fingerprint}, idempotencyInFlightTTL)
+		if err != nil {
+			// Fail open: processing the request is preferable to rejecting it
+			m.logger.Error("Idempotency store unavailable", zap.Error(err))
+			return c.Next()
+		}
+		if existing != nil {
+			return m.replay(c, existing, fingerprint)
+		}
+
+		if err := c.Next(); err != nil {
+			m.release(c, storeKey)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= http.StatusInternalServerError {
+			// Server errors are not final; let the client retry with the same key
+			m.release(c, storeKey)
+			return nil
+		}
+
+		record := IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			Headers:     make(map[string]string),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		c.Response().Header.VisitAll(func(k, v []byte) {
+			if name := string(k); !idempotencySkippedHeaders[name] {
+				record.Headers[name] = string(v)
+			}
+		})
+
+		if err := m.store.Complete(c.UserContext(), storeKey, record, m.ttl); err != nil {
+			m.logger.Error("Failed to store idempotent response", zap.Error(err))
+		}
+		return nil
+	}
+}
+
+// replay answers a retried request from its stored record
+func (m *IdempotencyMiddleware) replay(c *fiber.Ctx, record *IdempotencyRecord, fingerprint string) error {
+	if !record.Completed {
+		return fiber.NewError(fiber.StatusConflict, "a request with this "+m.header+" is still being processed")
+	}
+	if record.Fingerprint != fingerprint {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, m.header+" was already used with a different request")
+	}
+
+	for name, value := range record.Headers {
+		c.Set(name, value)
+	}
+	c.Set(IdempotencyReplayedHeader, "true")
+	return c.Status(record.Status).Send(record.Body)
+}
+
+// release frees the key after a failed request
+func (m *IdempotencyMiddleware) release(c *fiber.Ctx, storeKey string) {
+	if err := m.store.Release(c.UserContext(), storeKey); err != nil {
+		m.logger.Warn("Failed to release idempotency key", zap.Error(err))
+	}
+}
+
+// storeKey scopes the client's key to the route and the authenticated user, if any
+func (m *IdempotencyMiddleware) storeKey(c *fiber.Ctx, key string) string {
+	route := c.Path()
+	if r := c.Route(); r != nil {
+		route = r.Path
+	}
+	userID, _ := c.Locals("user_id").(string)
+	return c.Method() + ":" + route + ":" + userID + ":" + key
+}
+
+// requestFingerprint hashes the parts of the request that must match on a retry
+func requestFingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.OriginalURL()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
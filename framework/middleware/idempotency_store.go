@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request payload the key was first used with
+	Fingerprint string `json:"fingerprint"`
+	// Completed is false while the first request is still being processed
+	Completed bool              `json:"completed"`
+	Status    int               `json:"status,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      []byte            `json:"body,omitempty"`
+}
+
+// IdempotencyStore persists idempotency records
+type IdempotencyStore interface {
+	// Claim atomically stores record under key unless the key is already in use,
+	// in which case the existing record is returned
+	Claim(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, error)
+
+	// Complete replaces the record under key with the final response
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+
+	// Release removes the record under key so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// CacheIdempotencyStore keeps idempotency records in a cache.Cache.
+// Claims are only atomic within a single process.
+type CacheIdempotencyStore struct {
+	mu    sync.Mutex
+	cache cache.Cache
+}
+
+// NewCacheIdempotencyStore creates a new cache-backed idempotency store
+func NewCacheIdempotencyStore(c cache.Cache) *CacheIdempotencyStore {
+	return &CacheIdempotencyStore{cache: c}
+}
+
+// Claim stores record under key unless the key is already in use
+func (s *CacheIdempotencyStore) Claim(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.cache.Get(ctx, key)
+	if err == nil {
+		var existing IdempotencyRecord
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, err
+	}
+
+	return nil, s.set(ctx, key, record, ttl)
+}
+
+// Complete replaces the record under key with the final response
+func (s *CacheIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	return s.set(ctx, key, record, ttl)
+}
+
+// Release removes the record under key
+func (s *CacheIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.cache.Delete(ctx, key)
+}
+
+func (s *CacheIdempotencyStore) set(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, key, data, ttl)
+}
+
+// RedisIdempotencyStore keeps idempotency records in Redis so keys are honoured across replicas
+type RedisIdempotencyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisIdempotencyStore creates a new Redis-backed idempotency store
+func NewRedisIdempotencyStore(client redis.UniversalClient, prefix string) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Claim stores record under key with SET NX unless the key is already in use
+func (s *RedisIdempotencyStore) Claim(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.client.SetNX(ctx, s.prefix+":"+key, data, ttl).Result()
+	if err != nil || ok {
+		return nil, err
+	}
+
+	existing, err := s.client.Get(ctx, s.prefix+":"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The claim expired between SETNX and GET; treat the key as free
+		return s.Claim(ctx, key, record, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rec IdempotencyRecord
+	if err := json.Unmarshal(existing, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Complete replaces the record under key with the final response
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+":"+key, data, ttl).Err()
+}
+
+// Release removes the record under key
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+":"+key).Err()
+}
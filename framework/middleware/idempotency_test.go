@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	m, err := NewIdempotencyMiddleware(&config.Config{}, logger, nil)
+	require.NoError(t, err)
+
+	calls := 0
+	app := fiber.New()
+	app.Post("/orders", m.Handle(), func(c *fiber.Ctx) error {
+		calls++
+		if string(c.Body()) == "fail" {
+			return c.Status(http.StatusInternalServerError).SendString("boom")
+		}
+		c.Set("X-Order-ID", "order-1")
+		return c.Status(http.StatusCreated).SendString("created")
+	})
+
+	request := func(key, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Replays the first response", func(t *testing.T) {
+		first := request("key-1", "payload")
+		assert.Equal(t, http.StatusCreated, first.StatusCode)
+		assert.Empty(t, first.Header.Get(IdempotencyReplayedHeader))
+
+		second := request("key-1", "payload")
+		assert.Equal(t, http.StatusCreated, second.StatusCode)
+		assert.Equal(t, "true", second.Header.Get(IdempotencyReplayedHeader))
+		assert.Equal(t, "order-1", second.Header.Get("X-Order-ID"))
+		body, _ := io.ReadAll(second.Body)
+		assert.Equal(t, "created", string(body))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Rejects key reuse with a different payload", func(t *testing.T) {
+		resp := request("key-1", "other payload")
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("Requests without a key are not deduplicated", func(t *testing.T) {
+		before := calls
+		request("", "payload")
+		request("", "payload")
+		assert.Equal(t, before+2, calls)
+	})
+
+	t.Run("Server errors release the key", func(t *testing.T) {
+		before := calls
+		assert.Equal(t, http.StatusInternalServerError, request("key-2", "fail").StatusCode)
+		assert.Equal(t, http.StatusInternalServerError, request("key-2", "fail").StatusCode)
+		assert.Equal(t, before+2, calls)
+	})
+}
+
+func TestIdempotencyMiddlewareRequired(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{HTTP: config.HTTPConfig{Idempotency: config.IdempotencyConfig{Required: true}}}
+	m, err := NewIdempotencyMiddleware(cfg, logger, nil)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Post("/orders", m.Handle(), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/orders", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	m, err := NewIdempotencyMiddleware(&config.Config{}, logger, nil)
+	require.NoError(t, err)
+
+	// Simulate a first request that has claimed the key but not completed
+	_, err = m.store.Claim(t.Context(), "POST:/orders::key-1", IdempotencyRecord{Fingerprint: "other"}, idempotencyInFlightTTL)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Post("/orders", m.Handle(), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set("Idempotency-Key", "key-1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestIdempotencyMiddlewareRedisBackend(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{HTTP: config.HTTPConfig{Idempotency: config.IdempotencyConfig{Backend: "redis"}}}
+
+	_, err := NewIdempotencyMiddleware(cfg, logger, nil)
+	assert.ErrorIs(t, err, axredis.ErrNoClient)
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	m, err := NewIdempotencyMiddleware(cfg, logger, client)
+	require.NoError(t, err)
+	assert.IsType(t, &RedisIdempotencyStore{}, m.store)
+}
+
+func TestIdempotencyMiddlewareClosesRedisClientOnStop(t *testing.T) {
+	cfg := &config.Config{
+		HTTP:  config.HTTPConfig{Idempotency: config.IdempotencyConfig{Backend: "redis"}},
+		Redis: config.RedisConfig{Addr: "localhost:0"},
+	}
+	logger, _ := observability.NewLogger(cfg)
+
+	var m *IdempotencyMiddleware
+	app := fxtest.New(t,
+		fx.Supply(cfg, logger),
+		axredis.Module,
+		Module,
+		fx.Populate(&m),
+	)
+	app.RequireStart().RequireStop()
+
+	store, ok := m.store.(*RedisIdempotencyStore)
+	require.True(t, ok)
+	assert.ErrorIs(t, store.client.Ping(context.Background()).Err(), redis.ErrClosed)
+}
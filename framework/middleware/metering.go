@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/metering"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MeteringMiddleware accounts the resources used by each request and emits a usage record
+type MeteringMiddleware struct {
+	recorder     *metering.Recorder
+	apiKeyHeader string
+	tenantHeader string
+}
+
+// NewMeteringMiddleware creates a new metering middleware
+func NewMeteringMiddleware(cfg *config.Config, recorder *metering.Recorder) *MeteringMiddleware {
+	apiKeyHeader := cfg.Observability.MeteringAPIKeyHeader
+	if apiKeyHeader == "" {
+		apiKeyHeader = "X-API-Key"
+	}
+	tenantHeader := cfg.Observability.TenantHeader
+	if tenantHeader == "" {
+		tenantHeader = "X-Tenant-ID"
+	}
+
+	return &MeteringMiddleware{
+		recorder:     recorder,
+		apiKeyHeader: apiKeyHeader,
+		tenantHeader: tenantHeader,
+	}
+}
+
+// Handle returns a Fiber middleware handler
+func (m *MeteringMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		meter := metering.NewMeter()
+		c.SetUserContext(metering.NewContext(c.UserContext(), meter))
+
+		err := c.Next()
+
+		meter.AddEgressBytes(int64(len(c.Response().Body())))
+
+		route := c.Path()
+		if r := c.Route(); r != nil {
+			route = r.Path
+		}
+
+		tenantID := TenantID(c)
+		if tenantID == "" {
+			tenantID = c.Get(m.tenantHeader)
+		}
+
+		m.recorder.Emit(c.UserContext(), metering.Record{
+			Timestamp: start,
+			Method:    c.Method(),
+			Route:     route,
+			Status:    c.Response().StatusCode(),
+			TenantID:  tenantID,
+			APIKey:    metering.APIKeyFingerprint(c.Get(m.apiKeyHeader)),
+			Duration:  time.Since(start),
+			Usage:     meter.Finish(),
+		})
+
+		return err
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/metering"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteringMiddleware(t *testing.T) {
+	recorder := metering.NewRecorder()
+	m := NewMeteringMiddleware(&config.Config{}, recorder)
+
+	app := fiber.New()
+	app.Use(m.Handle())
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		metering.RecordDBQuery(c.UserContext(), time.Millisecond)
+		metering.RecordDownstreamCall(c.UserContext(), time.Millisecond)
+		return c.SendString("hello")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("X-API-Key", "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	total, ok := recorder.Aggregator().ByTenant()["acme"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), total.Requests)
+	assert.Equal(t, 1, total.Usage.DBQueries)
+	assert.Equal(t, 1, total.Usage.DownstreamCalls)
+	assert.Equal(t, int64(len("hello")), total.Usage.BytesEgress)
+
+	_, ok = recorder.Aggregator().ByAPIKey()[metering.APIKeyFingerprint("secret")]
+	assert.True(t, ok)
+}
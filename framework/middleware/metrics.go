@@ -30,6 +30,18 @@ func (m *MetricsMiddleware) Handle() fiber.Handler {
 			return c.Next()
 		}
 
+		// Resolve the tenant before the handlers run so downstream loggers can use it
+		var tenantID string
+		if m.metrics.TenantGuard != nil {
+			tenantID = TenantID(c)
+			if tenantID == "" {
+				tenantID = c.Get(m.metrics.TenantHeader)
+				if tenantID != "" {
+					c.Locals("tenant_id", tenantID)
+				}
+			}
+		}
+
 		err := c.Next()
 
 		status := strconv.Itoa(c.Response().StatusCode())
@@ -49,7 +61,18 @@ func (m *MetricsMiddleware) Handle() fiber.Handler {
 		if m.metrics.HTTPRequestDuration != nil {
 			m.metrics.HTTPRequestDuration.WithLabelValues(method, path, status).Observe(duration)
 		}
+		if m.metrics.TenantGuard != nil {
+			tenant := m.metrics.TenantGuard.Label(tenantID)
+			m.metrics.HTTPTenantRequestsTotal.WithLabelValues(tenant, method, status).Inc()
+			m.metrics.HTTPTenantRequestDuration.WithLabelValues(tenant, method, status).Observe(duration)
+		}
 
 		return err
 	}
 }
+
+// TenantID returns the tenant ID stored in the Fiber context, if any
+func TenantID(c *fiber.Ctx) string {
+	tenantID, _ := c.Locals("tenant_id").(string)
+	return tenantID
+}
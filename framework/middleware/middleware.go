@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/ctxkit"
+	"github.com/axiomod/axiomod/framework/errorreport"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"github.com/gofiber/fiber/v2"
@@ -21,6 +25,17 @@ var Module = fx.Options(
 	fx.Provide(NewRecoveryMiddleware),
 	fx.Provide(NewMetricsMiddleware),
 	fx.Provide(NewTracingMiddleware),
+	fx.Provide(fx.Annotate(NewRateLimitMiddleware, fx.ParamTags(``, ``, `optional:"true"`))),
+	fx.Provide(NewConcurrencyLimitMiddleware),
+	fx.Provide(NewMeteringMiddleware),
+	fx.Provide(NewErrorHandler),
+	fx.Provide(fx.Annotate(NewIdempotencyMiddleware, fx.ParamTags(``, ``, `optional:"true"`))),
+	fx.Provide(NewBodyLimitMiddleware),
+	fx.Provide(NewEndpointGuards),
+	fx.Provide(NewSecurityMiddleware),
+	fx.Provide(NewPayloadLogMiddleware),
+	fx.Provide(NewDebugTraceMiddleware),
+	fx.Provide(NewFaultInjectionMiddleware),
 )
 
 // LoggingMiddleware logs HTTP requests
@@ -54,62 +69,144 @@ func (m *LoggingMiddleware) Handle() fiber.Handler {
 		latency := time.Since(start)
 
 		// Log request
-		m.logger.Info("HTTP request",
+		m.logger.WithTenant(TenantID(c)).Info("HTTP request",
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", status),
 			zap.Duration("latency", latency),
 			zap.String("ip", ip),
 			zap.String("user_agent", userAgent),
+			zap.String("request_id", RequestID(c)),
 		)
 
 		return err
 	}
 }
 
+// routePattern matches requests by method and path
+type routePattern struct {
+	method string
+	path   string
+	prefix bool
+}
+
+// newRoutePattern creates a pattern for method (empty matches any) and path
+// (exact, or prefix when ending with "*")
+func newRoutePattern(method, path string) routePattern {
+	return routePattern{
+		method: method,
+		path:   strings.TrimSuffix(path, "*"),
+		prefix: strings.HasSuffix(path, "*"),
+	}
+}
+
+func (r routePattern) matches(method, path string) bool {
+	if r.method != "" && !strings.EqualFold(r.method, method) {
+		return false
+	}
+	if r.prefix {
+		return strings.HasPrefix(path, r.path)
+	}
+	return path == r.path
+}
+
+// authRoute is a per-route authentication requirement
+type authRoute struct {
+	routePattern
+	required bool
+}
+
 // AuthMiddleware authenticates HTTP requests
 type AuthMiddleware struct {
 	jwtService *auth.JWTService
+	routes     []authRoute
 	logger     *observability.Logger
 }
 
-// NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(jwtService *auth.JWTService, logger *observability.Logger) *AuthMiddleware {
+// NewAuthMiddleware creates a new authentication middleware.
+// Routes listed in the HTTP auth configuration may opt out of authentication.
+func NewAuthMiddleware(cfg *config.Config, jwtService *auth.JWTService, logger *observability.Logger) *AuthMiddleware {
+	routes := make([]authRoute, 0, len(cfg.HTTP.Auth.Routes))
+	for _, rc := range cfg.HTTP.Auth.Routes {
+		routes = append(routes, authRoute{
+			routePattern: newRoutePattern(rc.Method, rc.Path),
+			required:     rc.AuthRequired,
+		})
+	}
+
 	return &AuthMiddleware{
 		jwtService: jwtService,
+		routes:     routes,
 		logger:     logger,
 	}
 }
 
-// Handle returns a Fiber middleware handler
+// AllowAnonymous marks a route as public for Handle. Configured routes take precedence.
+func (m *AuthMiddleware) AllowAnonymous(method, path string) *AuthMiddleware {
+	m.routes = append(m.routes, authRoute{routePattern: newRoutePattern(method, path)})
+	return m
+}
+
+// Handle returns a Fiber middleware handler that requires a valid token,
+// except on routes that do not require authentication
 func (m *AuthMiddleware) Handle() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get token from header
-		token := c.Get("Authorization")
-		if token == "" {
-			return fiber.NewError(fiber.StatusUnauthorized, "missing authorization header")
+		required := true
+		for _, route := range m.routes {
+			if route.matches(c.Method(), c.Path()) {
+				required = route.required
+				break
+			}
 		}
+		return m.authenticate(c, required)
+	}
+}
 
-		// Remove "Bearer " prefix if present
-		if len(token) > 7 && token[:7] == "Bearer " {
-			token = token[7:]
-		}
+// OptionalAuth returns a Fiber middleware handler that authenticates requests carrying a token
+// and lets requests without one continue anonymously. Invalid tokens are still rejected.
+func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return m.authenticate(c, false)
+	}
+}
 
-		// Validate token
-		claims, err := m.jwtService.ValidateToken(token)
-		if err != nil {
-			m.logger.Warn("Invalid token", zap.Error(err))
-			return fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+// authenticate validates the bearer token and stores its claims in the context
+func (m *AuthMiddleware) authenticate(c *fiber.Ctx, required bool) error {
+	// Get token from header
+	token := c.Get("Authorization")
+	if token == "" {
+		if !required {
+			return c.Next()
 		}
+		return fiber.NewError(fiber.StatusUnauthorized, "missing authorization header")
+	}
 
-		// Store claims in context
-		c.Locals("user_id", claims.UserID)
-		c.Locals("username", claims.Username)
-		c.Locals("email", claims.Email)
-		c.Locals("roles", claims.Roles)
+	// Remove "Bearer " prefix if present
+	if len(token) > 7 && token[:7] == "Bearer " {
+		token = token[7:]
+	}
 
-		return c.Next()
+	// Validate token
+	claims, err := m.jwtService.ValidateToken(token)
+	if err != nil {
+		m.logger.Warn("Invalid token", zap.Error(err))
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid token")
 	}
+
+	// Store claims in context
+	c.Locals("user_id", claims.UserID)
+	c.Locals("username", claims.Username)
+	c.Locals("email", claims.Email)
+	c.Locals("roles", claims.Roles)
+	c.SetUserContext(ctxkit.WithUserID(c.UserContext(), claims.UserID))
+
+	return c.Next()
+}
+
+// IsAuthenticated reports whether the request was authenticated by AuthMiddleware
+func IsAuthenticated(c *fiber.Ctx) bool {
+	userID, _ := c.Locals("user_id").(string)
+	return userID != ""
 }
 
 // RoleMiddleware checks if the user has the required role
@@ -212,6 +309,7 @@ func (m *RecoveryMiddleware) Handle() fiber.Handler {
 					zap.String("method", c.Method()),
 					zap.String("path", c.Path()),
 				)
+				ReportPanic(c, r)
 				c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 					"error": "internal server error",
 				})
@@ -221,3 +319,16 @@ func (m *RecoveryMiddleware) Handle() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// ReportPanic reports a panic recovered while handling a request to the error reporting
+// service. It is also the StackTraceHandler of Fiber's recover middleware.
+func ReportPanic(c *fiber.Ctx, r interface{}) {
+	route := c.Path()
+	if rt := c.Route(); rt != nil {
+		route = rt.Path
+	}
+	errorreport.CapturePanic(c.UserContext(), r, errorreport.Tags{
+		"http.method": c.Method(),
+		"http.route":  route,
+	})
+}
@@ -1,16 +1,24 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/axiomod/axiomod/framework/auth"
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errorreport"
 	"github.com/axiomod/axiomod/platform/observability"
 	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
@@ -32,7 +40,7 @@ func TestAuthMiddleware(t *testing.T) {
 	secret := "test-secret"
 	jwtService := auth.NewJWTService(secret, time.Hour)
 	logger, _ := observability.NewLogger(&config.Config{})
-	m := NewAuthMiddleware(jwtService, logger)
+	m := NewAuthMiddleware(&config.Config{}, jwtService, logger)
 
 	app := fiber.New()
 	app.Use(m.Handle())
@@ -55,6 +63,87 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+func TestOptionalAuth(t *testing.T) {
+	jwtService := auth.NewJWTService("test-secret", time.Hour)
+	logger, _ := observability.NewLogger(&config.Config{})
+	m := NewAuthMiddleware(&config.Config{}, jwtService, logger)
+
+	app := fiber.New()
+	app.Get("/feed", m.OptionalAuth(), func(c *fiber.Ctx) error {
+		if !IsAuthenticated(c) {
+			return c.SendString("anonymous")
+		}
+		return c.SendString(c.Locals("username").(string))
+	})
+
+	token, _ := jwtService.GenerateToken("123", "alice", "alice@example.com", nil)
+	tests := []struct {
+		name   string
+		header string
+		status int
+		body   string
+	}{
+		{"anonymous", "", http.StatusOK, "anonymous"},
+		{"authenticated", "Bearer " + token, http.StatusOK, "alice"},
+		{"invalid token", "Bearer invalid", http.StatusUnauthorized, "invalid token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestAuthMiddlewareRoutes(t *testing.T) {
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{
+			Auth: config.HTTPAuthConfig{
+				Routes: []config.AuthRouteConfig{
+					{Path: "/public/admin", AuthRequired: true},
+					{Method: "GET", Path: "/public/*", AuthRequired: false},
+				},
+			},
+		},
+	}
+	jwtService := auth.NewJWTService("test-secret", time.Hour)
+	logger, _ := observability.NewLogger(&config.Config{})
+	m := NewAuthMiddleware(cfg, jwtService, logger).AllowAnonymous("", "/docs")
+
+	app := fiber.New()
+	app.Use(m.Handle())
+	handler := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app.Get("/public/*", handler)
+	app.Post("/public/items", handler)
+	app.Get("/docs", handler)
+	app.Get("/private", handler)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodGet, "/public/items", http.StatusOK},
+		// The first matching rule wins
+		{http.MethodGet, "/public/admin", http.StatusUnauthorized},
+		{http.MethodPost, "/public/items", http.StatusUnauthorized},
+		{http.MethodGet, "/docs", http.StatusOK},
+		{http.MethodGet, "/private", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, _ := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
 func TestTimeoutMiddleware(t *testing.T) {
 	logger, _ := observability.NewLogger(&config.Config{})
 	m := NewTimeoutMiddleware(10*time.Millisecond, logger)
@@ -73,3 +162,109 @@ func TestTimeoutMiddleware(t *testing.T) {
 	resp, _ := app.Test(req, 100)
 	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode)
 }
+
+func TestMetricsMiddlewareTenantLabels(t *testing.T) {
+	cfg := &config.Config{
+		Observability: config.ObservabilityConfig{
+			MetricsEnabled:      true,
+			TenantLabelsEnabled: true,
+			TenantLabelLimit:    1,
+		},
+	}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	assert.NoError(t, err)
+	m := NewMetricsMiddleware(metrics)
+
+	app := fiber.New()
+	app.Use(m.Handle())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(TenantID(c))
+	})
+
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, tenant, string(body))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.HTTPTenantRequestsTotal.WithLabelValues("acme", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.HTTPTenantRequestsTotal.WithLabelValues(observability.OtherLabelValue, "GET", "200")))
+}
+
+func TestRecoveryMiddlewareReportsPanics(t *testing.T) {
+	events := make(chan errorreport.Event, 1)
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event errorreport.Event
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer sentry.Close()
+
+	client, err := errorreport.NewClient(&config.Config{Observability: config.ObservabilityConfig{
+		ErrorReporting: config.ErrorReportingConfig{Enabled: true, DSN: strings.Replace(sentry.URL, "http://", "http://key@", 1) + "/1"},
+	}}, nil)
+	require.NoError(t, err)
+	client.Start()
+	errorreport.SetDefault(client)
+	defer errorreport.SetDefault(nil)
+
+	logger, _ := observability.NewLogger(&config.Config{})
+	app := fiber.New()
+	app.Use(NewRecoveryMiddleware(logger).Handle())
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		panic("nil order")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/orders/7", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.NoError(t, client.Close(context.Background()))
+
+	event := <-events
+	assert.Equal(t, "nil order", event.Message)
+	assert.Equal(t, "GET", event.Tags["http.method"])
+	assert.Equal(t, "/orders/:id", event.Tags["http.route"])
+}
+
+func BenchmarkMiddlewareChain(b *testing.B) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	jwtService := auth.NewJWTService("bench-secret", time.Hour)
+	security, err := NewSecurityMiddleware(cfg, logger)
+	require.NoError(b, err)
+
+	// The middleware every request of the HTTP server goes through
+	app := fiber.New()
+	app.Use(
+		RequestContext("X-Tenant-ID"),
+		security.Headers(),
+		Degradation(),
+		NewAuthMiddleware(cfg, jwtService, logger).Handle(),
+		FeatureFlagContext("X-Tenant-ID"),
+		NewBodyLimitMiddleware(cfg, logger).Handle(),
+	)
+	app.Get("/api/orders/:id", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	handler := app.Handler()
+	token, err := jwtService.GenerateToken("123", "alice", "alice@example.com", []string{"user"})
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(fiber.MethodGet)
+		ctx.Request.SetRequestURI("/api/orders/42")
+		ctx.Request.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		ctx.Request.Header.Set("X-Tenant-ID", "acme")
+		handler(&ctx)
+		if status := ctx.Response.StatusCode(); status != http.StatusOK {
+			b.Fatalf("status %d", status)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultPayloadLogBodySize is the number of bytes of each body logged when none is configured
+const DefaultPayloadLogBodySize = 4096
+
+// DefaultPayloadLogHeaders are the headers logged with their value when none are configured
+var DefaultPayloadLogHeaders = []string{
+	fiber.HeaderContentType, fiber.HeaderContentLength, fiber.HeaderContentEncoding, fiber.HeaderAccept,
+	fiber.HeaderUserAgent, fiber.HeaderLocation, "X-Request-ID", "Traceparent",
+}
+
+// redactedValue replaces redacted values in payload logs
+const redactedValue = "[REDACTED]"
+
+// PayloadLogMiddleware logs the headers and bodies of requests and responses to troubleshoot
+// integrations. Secret fields, configured JSON paths and headers that are not allowed are
+// redacted, and bodies are truncated.
+type PayloadLogMiddleware struct {
+	enabled     bool
+	routes      []routePattern
+	sampleRate  float64
+	maxBodySize int
+	redact      [][]jsonPathSegment
+	headers     map[string]bool
+	logger      *observability.Logger
+}
+
+// NewPayloadLogMiddleware creates the payload log middleware from the HTTP configuration
+func NewPayloadLogMiddleware(cfg *config.Config, logger *observability.Logger) (*PayloadLogMiddleware, error) {
+	logCfg := cfg.HTTP.PayloadLog
+	m := &PayloadLogMiddleware{
+		enabled:     logCfg.Enabled,
+		sampleRate:  logCfg.SampleRate,
+		maxBodySize: logCfg.MaxBodySize,
+		headers:     make(map[string]bool),
+		logger:      logger,
+	}
+	if m.sampleRate == 0 {
+		m.sampleRate = 1
+	}
+	if m.sampleRate < 0 || m.sampleRate > 1 {
+		return nil, fmt.Errorf("http.payloadLog.sampleRate must be between 0 and 1, got %v", logCfg.SampleRate)
+	}
+	if m.maxBodySize <= 0 {
+		m.maxBodySize = DefaultPayloadLogBodySize
+	}
+	for _, path := range logCfg.Paths {
+		m.routes = append(m.routes, newRoutePattern("", path))
+	}
+	for _, path := range logCfg.RedactPaths {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("http.payloadLog.redactPaths: %w", err)
+		}
+		m.redact = append(m.redact, segments)
+	}
+	headers := logCfg.Headers
+	if len(headers) == 0 {
+		headers = DefaultPayloadLogHeaders
+	}
+	for _, header := range headers {
+		m.headers[strings.ToLower(header)] = true
+	}
+
+	if m.enabled && cfg.App.Environment == "production" {
+		logger.Warn("Payload logging is enabled in production; request and response bodies are written to the logs")
+	}
+	return m, nil
+}
+
+// Enabled reports whether payload logging is configured
+func (m *PayloadLogMiddleware) Enabled() bool {
+	return m != nil && m.enabled
+}
+
+// Handle returns a Fiber middleware handler logging the payloads of sampled requests. It must
+// run after compression so that it sees plain response bodies. Errors are rendered by the
+// error handler before logging, so error responses are logged too.
+func (m *PayloadLogMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !m.logged(c.Path()) {
+			return c.Next()
+		}
+		start := time.Now()
+		req := c.Request()
+		requestBody := m.body(string(req.Header.ContentType()), req.IsBodyStream(), req.Body)
+
+		if err := c.Next(); err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				return handlerErr
+			}
+		}
+
+		resp := c.Response()
+		m.logger.Info("HTTP payload",
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.Int("status", resp.StatusCode()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("request_id", RequestID(c)),
+			zap.Any("request_headers", m.headerValues(req.Header.VisitAll)),
+			zap.String("request_body", requestBody),
+			zap.Any("response_headers", m.headerValues(resp.Header.VisitAll)),
+			zap.String("response_body", m.body(string(resp.Header.ContentType()), resp.IsBodyStream(), resp.Body)),
+		)
+		return nil
+	}
+}
+
+// logged reports whether the payloads of a request to path are sampled for logging
+func (m *PayloadLogMiddleware) logged(path string) bool {
+	if len(m.routes) == 0 {
+		switch path {
+		case "/live", "/ready", "/health", "/metrics":
+			return false
+		}
+	} else {
+		matched := false
+		for _, route := range m.routes {
+			if route.matches("", path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return m.sampleRate >= 1 || rand.Float64() < m.sampleRate
+}
+
+// headerValues returns the headers visited by visitAll, with the values of headers that are
+// not allowed redacted
+func (m *PayloadLogMiddleware) headerValues(visitAll func(func(key, value []byte))) map[string]string {
+	headers := make(map[string]string)
+	visitAll(func(key, value []byte) {
+		name := string(key)
+		if m.headers[strings.ToLower(name)] {
+			headers[name] = string(value)
+		} else {
+			headers[name] = redactedValue
+		}
+	})
+	return headers
+}
+
+// body returns the loggable form of a body: JSON and form bodies with their secrets redacted,
+// text as is and a summary of other content, truncated to the maximum body size. Streamed
+// bodies are not read, so that they reach handlers and clients untouched.
+func (m *PayloadLogMiddleware) body(contentType string, streamed bool, read func() []byte) string {
+	if streamed {
+		return "[streamed body]"
+	}
+	body := read()
+	if len(body) == 0 {
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var text string
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		text = m.redactJSON(body)
+	case mediaType == fiber.MIMEApplicationForm:
+		text = m.redactForm(body)
+	case strings.HasPrefix(mediaType, "text/") || mediaType == fiber.MIMEApplicationXML || strings.HasSuffix(mediaType, "+xml"):
+		text = string(body)
+	default:
+		return fmt.Sprintf("[%d bytes of %s]", len(body), valueOr(mediaType, "unknown content"))
+	}
+
+	if len(text) <= m.maxBodySize {
+		return text
+	}
+	cut := m.maxBodySize
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "...[truncated " + strconv.Itoa(len(text)) + " bytes]"
+}
+
+// redactJSON replaces the secret fields and the fields of the redacted paths of a JSON body
+func (m *PayloadLogMiddleware) redactJSON(body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Sprintf("[%d bytes of invalid JSON]", len(body))
+	}
+	redactSecretFields(document)
+	for _, path := range m.redact {
+		redactJSONPath(document, path)
+	}
+	redacted, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Sprintf("[%d bytes of JSON]", len(body))
+	}
+	return string(redacted)
+}
+
+// redactForm replaces the secret fields of a form body and the fields named by top-level
+// redacted paths, such as $.password
+func (m *PayloadLogMiddleware) redactForm(body []byte) string {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes of invalid form]", len(body))
+	}
+	for name := range values {
+		redact := config.IsSecretKey(name)
+		for _, path := range m.redact {
+			if len(path) == 1 && (path[0].wildcard || path[0].key == name) {
+				redact = true
+			}
+		}
+		if redact {
+			values[name] = []string{redactedValue}
+		}
+	}
+	return values.Encode()
+}
+
+// redactSecretFields replaces the values of fields named like secrets at any depth
+func redactSecretFields(node interface{}) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if config.IsSecretKey(key) {
+				node[key] = redactedValue
+			} else {
+				redactSecretFields(value)
+			}
+		}
+	case []interface{}:
+		for _, value := range node {
+			redactSecretFields(value)
+		}
+	}
+}
+
+// jsonPathSegment is a step of a JSON path: a field, an array index, any field or element
+// (wildcard), or a field at any depth (recursive)
+type jsonPathSegment struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// parseJSONPath parses the subset of JSONPath used to redact fields: $.a.b, $.a[0], $.a[*].b,
+// $.a.* and $..b
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") || len(path) < 2 {
+		return nil, fmt.Errorf("invalid JSON path %q: expected e.g. $.password", path)
+	}
+	var segments []jsonPathSegment
+	rest := path[1:]
+	for rest != "" {
+		var segment jsonPathSegment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.recursive = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: unclosed [", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			if selector == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: index %q", path, selector)
+			}
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			continue
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", path, rest[:1])
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		segment.key, rest = rest[:end], rest[end:]
+		if segment.key == "" {
+			return nil, fmt.Errorf("invalid JSON path %q: empty field name", path)
+		}
+		segment.wildcard = segment.key == "*" && !segment.recursive
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// redactJSONPath replaces the values path selects in a decoded JSON document
+func redactJSONPath(node interface{}, path []jsonPathSegment) {
+	segment, last := path[0], len(path) == 1
+	apply := func(value interface{}) interface{} {
+		if last {
+			return redactedValue
+		}
+		redactJSONPath(value, path[1:])
+		return value
+	}
+
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if segment.wildcard || (!segment.isIndex && key == segment.key) {
+				node[key] = apply(value)
+			} else if segment.recursive {
+				redactJSONPath(value, path)
+			}
+		}
+	case []interface{}:
+		for i, value := range node {
+			if segment.wildcard || (segment.isIndex && i == segment.index) {
+				node[i] = apply(value)
+			} else if segment.recursive {
+				redactJSONPath(value, path)
+			}
+		}
+	}
+}
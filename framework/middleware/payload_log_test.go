@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newPayloadLogApp(t *testing.T, logCfg config.PayloadLogConfig) (*fiber.App, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg := &config.Config{}
+	cfg.HTTP.PayloadLog = logCfg
+	mid, err := NewPayloadLogMiddleware(cfg, &observability.Logger{Logger: zap.New(core)})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(mid.Handle())
+	app.Post("/orders", func(c *fiber.Ctx) error {
+		c.Set("Set-Cookie", "session=abc")
+		c.Set(fiber.HeaderContentType, c.Get(fiber.HeaderContentType))
+		return c.Status(fiber.StatusCreated).Send(c.Body())
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusConflict, "order is closed")
+	})
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app, logs
+}
+
+func TestPayloadLog(t *testing.T) {
+	app, logs := newPayloadLogApp(t, config.PayloadLogConfig{
+		Enabled:     true,
+		MaxBodySize: 200,
+		RedactPaths: []string{"$.card.number", "$.items[*].sku", "$..iban", "$.note"},
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			"json redacted",
+			fiber.MIMEApplicationJSON,
+			`{"password":"hunter2","card":{"number":"4111","expiry":"12/30"},"items":[{"sku":"A1","qty":2}],"payout":{"accounts":[{"iban":"DE89"}]},"note":"x"}`,
+			`{"card":{"expiry":"12/30","number":"[REDACTED]"},"items":[{"qty":2,"sku":"[REDACTED]"}],"note":"[REDACTED]","password":"[REDACTED]","payout":{"accounts":[{"iban":"[REDACTED]"}]}}`,
+		},
+		{"nested secrets", "application/vnd.api+json", `{"auth":{"accessToken":"t"},"amount":12.50}`, `{"amount":12.50,"auth":{"accessToken":"[REDACTED]"}}`},
+		{"invalid json", fiber.MIMEApplicationJSON, `{"password":`, "[12 bytes of invalid JSON]"},
+		{"form", fiber.MIMEApplicationForm, "user=ada&password=hunter2&note=hi", "note=%5BREDACTED%5D&password=%5BREDACTED%5D&user=ada"},
+		{"text", fiber.MIMETextPlainCharsetUTF8, "hello", "hello"},
+		{"binary", "image/png", "\x89PNG", "[4 bytes of image/png]"},
+		{"truncated", fiber.MIMETextPlain, strings.Repeat("é", 150), strings.Repeat("é", 100) + "...[truncated 300 bytes]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, tt.contentType)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer secret")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+			entries := logs.TakeAll()
+			require.Len(t, entries, 1)
+			fields := entries[0].ContextMap()
+			assert.Equal(t, tt.want, fields["request_body"])
+			assert.Equal(t, tt.want, fields["response_body"])
+			assert.EqualValues(t, fiber.StatusCreated, fields["status"])
+
+			requestHeaders := fields["request_headers"].(map[string]string)
+			assert.Equal(t, "[REDACTED]", requestHeaders[fiber.HeaderAuthorization])
+			assert.Equal(t, tt.contentType, requestHeaders[fiber.HeaderContentType])
+			assert.Equal(t, "[REDACTED]", fields["response_headers"].(map[string]string)[fiber.HeaderSetCookie])
+		})
+	}
+
+	t.Run("error responses", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/fail", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.EqualValues(t, fiber.StatusConflict, entries[0].ContextMap()["status"])
+		assert.Equal(t, "order is closed", entries[0].ContextMap()["response_body"])
+	})
+
+	t.Run("probes skipped", func(t *testing.T) {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health", nil))
+		require.NoError(t, err)
+		assert.Zero(t, logs.Len())
+	})
+}
+
+func TestPayloadLogPaths(t *testing.T) {
+	app, logs := newPayloadLogApp(t, config.PayloadLogConfig{Enabled: true, Paths: []string{"/fa*"}, Headers: []string{"X-Debug"}})
+
+	_, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader("{}")))
+	require.NoError(t, err)
+	assert.Zero(t, logs.Len(), "routes not configured are not logged")
+
+	req := httptest.NewRequest(fiber.MethodGet, "/fail", nil)
+	req.Header.Set("X-Debug", "1")
+	_, err = app.Test(req)
+	require.NoError(t, err)
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "1", entries[0].ContextMap()["request_headers"].(map[string]string)["X-Debug"])
+}
+
+func TestPayloadLogSampling(t *testing.T) {
+	m := &PayloadLogMiddleware{sampleRate: 0.0001}
+	logged := 0
+	for i := 0; i < 1000; i++ {
+		if m.logged("/orders") {
+			logged++
+		}
+	}
+	assert.Less(t, logged, 50)
+	assert.False(t, (*PayloadLogMiddleware)(nil).Enabled())
+}
+
+func TestNewPayloadLogMiddleware(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	tests := []struct {
+		name   string
+		logCfg config.PayloadLogConfig
+		valid  bool
+	}{
+		{"defaults", config.PayloadLogConfig{Enabled: true}, true},
+		{"paths", config.PayloadLogConfig{RedactPaths: []string{"$.a.b", "$.a[0].b", "$.a[*]", "$.a.*", "$..b"}}, true},
+		{"sample rate above 1", config.PayloadLogConfig{SampleRate: 1.5}, false},
+		{"negative sample rate", config.PayloadLogConfig{SampleRate: -0.1}, false},
+		{"missing root", config.PayloadLogConfig{RedactPaths: []string{"password"}}, false},
+		{"unclosed bracket", config.PayloadLogConfig{RedactPaths: []string{"$.a[0"}}, false},
+		{"invalid index", config.PayloadLogConfig{RedactPaths: []string{"$.a[x]"}}, false},
+		{"empty field", config.PayloadLogConfig{RedactPaths: []string{"$.a..", "$."}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.HTTP.PayloadLog = tt.logCfg
+			mid, err := NewPayloadLogMiddleware(cfg, logger)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.logCfg.Enabled, mid.Enabled())
+			assert.Equal(t, DefaultPayloadLogBodySize, mid.maxBodySize)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem documents
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	Type     string                       `json:"type"`
+	Title    string                       `json:"title"`
+	Status   int                          `json:"status"`
+	Detail   string                       `json:"detail,omitempty"`
+	Instance string                       `json:"instance,omitempty"`
+	Code     string                       `json:"code,omitempty"`
+	Errors   []validation.ValidationError `json:"errors,omitempty"`
+	Metadata map[string]interface{}       `json:"metadata,omitempty"`
+	TraceID  string                       `json:"trace_id,omitempty"`
+	Stack    string                       `json:"stack,omitempty"`
+}
+
+// NewProblem builds a problem document from an error using its framework/errors code.
+// Errors without a code are reported as internal errors; the message and metadata
+// of server errors are not exposed.
+// Fiber errors keep their status code and message.
+func NewProblem(err error) Problem {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(fiberErr.Code),
+			Status: fiberErr.Code,
+			Detail: fiberErr.Message,
+			Code:   codeForStatus(fiberErr.Code),
+		}
+	}
+
+	status := errors.ToHTTPCode(err)
+	code := errors.GetCode(err)
+	if code == "" {
+		code = errors.CodeInternal
+	}
+
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+	}
+
+	// Only client errors expose their message and metadata
+	if status >= http.StatusInternalServerError {
+		return problem
+	}
+
+	problem.Detail = err.Error()
+	for key, value := range errors.GetMetadata(err) {
+		if violations, ok := value.([]validation.ValidationError); ok && key == "violations" {
+			problem.Errors = violations
+			continue
+		}
+		if problem.Metadata == nil {
+			problem.Metadata = make(map[string]interface{})
+		}
+		problem.Metadata[key] = value
+	}
+
+	return problem
+}
+
+// codeForStatus maps an HTTP status code back to a framework/errors code
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return errors.CodeInvalidInput
+	case http.StatusUnauthorized:
+		return errors.CodeUnauthorized
+	case http.StatusForbidden:
+		return errors.CodeForbidden
+	case http.StatusNotFound:
+		return errors.CodeNotFound
+	case http.StatusConflict:
+		return errors.CodeConflict
+	case http.StatusRequestTimeout:
+		return errors.CodeTimeout
+	case http.StatusNotImplemented:
+		return errors.CodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return errors.CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return errors.CodeInternal
+	}
+	return ""
+}
+
+// RespondProblem writes err to the response as an RFC 7807 problem document
+func RespondProblem(c *fiber.Ctx, err error) error {
+	problem := NewProblem(err)
+	problem.Instance = c.Path()
+	return WriteProblem(c, problem)
+}
+
+// WriteProblem writes a problem document with the problem+json content type
+func WriteProblem(c *fiber.Ctx, problem Problem) error {
+	c.Status(problem.Status)
+	if err := c.JSON(problem); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, ProblemContentType)
+	return nil
+}
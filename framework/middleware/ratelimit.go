@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Rate limiting algorithms
+const (
+	RateLimitAlgorithmSlidingWindow = "sliding-window"
+	RateLimitAlgorithmTokenBucket   = "token-bucket"
+)
+
+// Rate limiting key strategies
+const (
+	RateLimitKeyByIP      = "ip"
+	RateLimitKeyByAPIKey  = "apiKey"
+	RateLimitKeyBySubject = "subject"
+)
+
+// RateLimitRule describes a single rate limit
+type RateLimitRule struct {
+	// Algorithm is either RateLimitAlgorithmSlidingWindow or RateLimitAlgorithmTokenBucket
+	Algorithm string
+	// Limit is the number of requests allowed per Window
+	Limit int
+	// Window is the period over which Limit applies
+	Window time.Duration
+	// Burst is the token bucket capacity (defaults to Limit)
+	Burst int
+	// KeyBy selects how clients are identified
+	KeyBy string
+}
+
+// DefaultRateLimitRule returns the default rate limit rule
+func DefaultRateLimitRule() RateLimitRule {
+	return RateLimitRule{
+		Algorithm: RateLimitAlgorithmSlidingWindow,
+		Limit:     100,
+		Window:    time.Minute,
+		KeyBy:     RateLimitKeyByIP,
+	}
+}
+
+// RateLimitResult is the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is (at least partially) replenished
+	Reset time.Duration
+}
+
+// RateLimitStore keeps rate limit counters
+type RateLimitStore interface {
+	// Allow records a hit for key and reports whether it is within the rule
+	Allow(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+// rateLimitRoute is a per-route override of the default rule
+type rateLimitRoute struct {
+	routePattern
+	rule RateLimitRule
+}
+
+// RateLimitMiddleware limits the request rate per client
+type RateLimitMiddleware struct {
+	store        RateLimitStore
+	rule         RateLimitRule
+	routes       []rateLimitRoute
+	apiKeyHeader string
+	logger       *observability.Logger
+}
+
+// NewRateLimitMiddleware creates a new rate limit middleware from the HTTP rate limit configuration.
+// The redis backend counts on client, the client of redis.Module.
+func NewRateLimitMiddleware(cfg *config.Config, logger *observability.Logger, client redis.UniversalClient) (*RateLimitMiddleware, error) {
+	rlCfg := cfg.HTTP.RateLimit
+
+	rule := DefaultRateLimitRule()
+	if rlCfg.Algorithm != "" {
+		rule.Algorithm = rlCfg.Algorithm
+	}
+	if rlCfg.Limit > 0 {
+		rule.Limit = rlCfg.Limit
+	}
+	if rlCfg.Window > 0 {
+		rule.Window = time.Duration(rlCfg.Window) * time.Second
+	}
+	if rlCfg.Burst > 0 {
+		rule.Burst = rlCfg.Burst
+	}
+	if rlCfg.KeyBy != "" {
+		rule.KeyBy = rlCfg.KeyBy
+	}
+
+	routes := make([]rateLimitRoute, 0, len(rlCfg.Routes))
+	for _, rc := range rlCfg.Routes {
+		routeRule := rule
+		if rc.Limit > 0 {
+			routeRule.Limit = rc.Limit
+			routeRule.Burst = 0
+		}
+		if rc.Window > 0 {
+			routeRule.Window = time.Duration(rc.Window) * time.Second
+		}
+		if rc.KeyBy != "" {
+			routeRule.KeyBy = rc.KeyBy
+		}
+		routes = append(routes, rateLimitRoute{
+			routePattern: newRoutePattern(rc.Method, rc.Path),
+			rule:         routeRule,
+		})
+	}
+
+	apiKeyHeader := rlCfg.APIKeyHeader
+	if apiKeyHeader == "" {
+		apiKeyHeader = "X-API-Key"
+	}
+
+	var store RateLimitStore
+	if rlCfg.Backend == "redis" {
+		if client == nil {
+			return nil, fmt.Errorf("rate limit: %w", axredis.ErrNoClient)
+		}
+		store = NewRedisRateLimitStore(client, "ratelimit")
+	} else {
+		store = NewMemoryRateLimitStore()
+	}
+
+	return &RateLimitMiddleware{
+		store:        store,
+		rule:         rule,
+		routes:       routes,
+		apiKeyHeader: apiKeyHeader,
+		logger:       logger,
+	}, nil
+}
+
+// WithStore replaces the counter store, e.g. to share a Redis client
+func (m *RateLimitMiddleware) WithStore(store RateLimitStore) *RateLimitMiddleware {
+	m.store = store
+	return m
+}
+
+// Handle returns a Fiber middleware handler applying the default rule and per-route overrides
+func (m *RateLimitMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scope := "global"
+		rule := m.rule
+		for _, route := range m.routes {
+			if route.matches(c.Method(), c.Path()) {
+				scope = route.method + " " + route.path
+				rule = route.rule
+				break
+			}
+		}
+		return m.limit(c, scope, rule)
+	}
+}
+
+// Limit returns a Fiber handler enforcing the given rule, for attaching to individual routes
+func (m *RateLimitMiddleware) Limit(rule RateLimitRule) fiber.Handler {
+	if rule.Algorithm == "" {
+		rule.Algorithm = m.rule.Algorithm
+	}
+	if rule.KeyBy == "" {
+		rule.KeyBy = m.rule.KeyBy
+	}
+	if rule.Window <= 0 {
+		rule.Window = m.rule.Window
+	}
+	return func(c *fiber.Ctx) error {
+		scope := c.Method() + " " + c.Path()
+		if route := c.Route(); route != nil {
+			scope = route.Method + " " + route.Path
+		}
+		return m.limit(c, scope, rule)
+	}
+}
+
+// limit checks the rule for the current client and writes the RateLimit-* headers
+func (m *RateLimitMiddleware) limit(c *fiber.Ctx, scope string, rule RateLimitRule) error {
+	key := scope + "|" + rule.KeyBy + ":" + m.clientKey(c, rule.KeyBy)
+
+	result, err := m.store.Allow(c.UserContext(), key, rule)
+	if err != nil {
+		// Fail open: an unavailable store must not take the API down
+		m.logger.Error("Rate limit store error", zap.String("scope", scope), zap.Error(err))
+		return c.Next()
+	}
+
+	resetSeconds := int(math.Ceil(result.Reset.Seconds()))
+	c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+	if !result.Allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resetSeconds))
+		m.logger.Debug("Rate limit exceeded",
+			zap.String("scope", scope),
+			zap.String("key_by", rule.KeyBy),
+			zap.String("ip", c.IP()),
+		)
+		return fiber.NewError(fiber.StatusTooManyRequests, "rate limit exceeded")
+	}
+
+	return c.Next()
+}
+
+// clientKey identifies the client according to the key strategy, falling back to the IP
+func (m *RateLimitMiddleware) clientKey(c *fiber.Ctx, keyBy string) string {
+	switch keyBy {
+	case RateLimitKeyByAPIKey:
+		if apiKey := c.Get(m.apiKeyHeader); apiKey != "" {
+			return apiKey
+		}
+	case RateLimitKeyBySubject:
+		if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+			return userID
+		}
+	}
+	return c.IP()
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryRateLimitStore keeps rate limit counters in process memory.
+// It is only correct for single-replica deployments.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	windows map[string]*slidingWindow
+	buckets map[string]*tokenBucket
+	calls   int
+}
+
+// slidingWindow is the hit log of a key, with the window of the rule it is counted under
+type slidingWindow struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+type tokenBucket struct {
+	tokens   float64
+	last     time.Time
+	capacity float64
+	rate     float64 // tokens per second
+}
+
+// NewMemoryRateLimitStore creates a new in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		windows: make(map[string]*slidingWindow),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow records a hit for key and reports whether it is within the rule
+func (s *MemoryRateLimitStore) Allow(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// Periodically evict idle keys so the maps don't grow without bound
+	s.calls++
+	if s.calls%1000 == 0 {
+		s.evict(now)
+	}
+
+	if rule.Algorithm == RateLimitAlgorithmTokenBucket {
+		return s.allowTokenBucket(now, key, rule), nil
+	}
+	return s.allowSlidingWindow(now, key, rule), nil
+}
+
+// allowSlidingWindow implements a sliding window log
+func (s *MemoryRateLimitStore) allowSlidingWindow(now time.Time, key string, rule RateLimitRule) RateLimitResult {
+	cutoff := now.Add(-rule.Window)
+	log, ok := s.windows[key]
+	if !ok {
+		log = &slidingWindow{}
+		s.windows[key] = log
+	}
+	log.window = rule.Window
+	hits := log.hits
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	hits = hits[i:]
+
+	allowed := len(hits) < rule.Limit
+	if allowed {
+		hits = append(hits, now)
+	}
+	log.hits = hits
+
+	reset := rule.Window
+	if len(hits) > 0 {
+		reset = hits[0].Add(rule.Window).Sub(now)
+	}
+
+	return RateLimitResult{
+		Allowed:   allowed,
+		Limit:     rule.Limit,
+		Remaining: rule.Limit - len(hits),
+		Reset:     reset,
+	}
+}
+
+// allowTokenBucket implements a token bucket refilled at Limit per Window
+func (s *MemoryRateLimitStore) allowTokenBucket(now time.Time, key string, rule RateLimitRule) RateLimitResult {
+	capacity := float64(burstOf(rule))
+	rate := float64(rule.Limit) / rule.Window.Seconds()
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, last: now, capacity: capacity, rate: rate}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	allowed := bucket.tokens >= 1
+	var reset time.Duration
+	if allowed {
+		bucket.tokens--
+		reset = time.Duration((capacity - bucket.tokens) / rate * float64(time.Second))
+	} else {
+		reset = time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+
+	return RateLimitResult{
+		Allowed:   allowed,
+		Limit:     int(capacity),
+		Remaining: int(bucket.tokens),
+		Reset:     reset,
+	}
+}
+
+// evict removes the keys whose hits have all expired, each under the window of its own rule,
+// and the buckets that have refilled
+func (s *MemoryRateLimitStore) evict(now time.Time) {
+	for key, log := range s.windows {
+		if len(log.hits) == 0 || now.Sub(log.hits[len(log.hits)-1]) > log.window {
+			delete(s.windows, key)
+		}
+	}
+	for key, bucket := range s.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.rate >= bucket.capacity {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// slidingWindowScript implements a sliding window log on a sorted set.
+// KEYS[1] = key; ARGV = now (ms), window (ms), limit, member
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', key, now, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+redis.call('PEXPIRE', key, window)
+return {allowed, limit - count, reset}
+`)
+
+// tokenBucketScript implements a token bucket on a hash.
+// KEYS[1] = key; ARGV = now (ms), rate (tokens per ms), capacity
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local capacity = tonumber(ARGV[3])
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + (now - ts) * rate)
+local allowed = 0
+local reset
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+  reset = (capacity - tokens) / rate
+else
+  reset = (1 - tokens) / rate
+end
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, math.ceil(capacity / rate))
+return {allowed, math.floor(tokens), math.ceil(reset)}
+`)
+
+// RedisRateLimitStore keeps rate limit counters in Redis so limits hold across replicas
+type RedisRateLimitStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRateLimitStore creates a new Redis-backed rate limit store
+func NewRedisRateLimitStore(client redis.UniversalClient, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Allow records a hit for key and reports whether it is within the rule
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+	redisKey := s.prefix + ":" + key
+
+	var (
+		res   []interface{}
+		err   error
+		limit = rule.Limit
+	)
+	if rule.Algorithm == RateLimitAlgorithmTokenBucket {
+		limit = burstOf(rule)
+		rate := float64(rule.Limit) / float64(rule.Window.Milliseconds())
+		res, err = tokenBucketScript.Run(ctx, s.client, []string{redisKey},
+			now, strconv.FormatFloat(rate, 'f', -1, 64), limit).Slice()
+	} else {
+		res, err = slidingWindowScript.Run(ctx, s.client, []string{redisKey},
+			now, rule.Window.Milliseconds(), rule.Limit, strconv.FormatInt(now, 10)+"-"+uuid.NewString()).Slice()
+	}
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	return RateLimitResult{
+		Allowed:   res[0].(int64) == 1,
+		Limit:     limit,
+		Remaining: int(res[1].(int64)),
+		Reset:     time.Duration(res[2].(int64)) * time.Millisecond,
+	}, nil
+}
+
+// burstOf returns the token bucket capacity of a rule
+func burstOf(rule RateLimitRule) int {
+	if rule.Burst > 0 {
+		return rule.Burst
+	}
+	return rule.Limit
+}
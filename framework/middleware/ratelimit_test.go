@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{
+			RateLimit: config.RateLimitConfig{
+				Enabled: true,
+				Limit:   2,
+				Window:  60,
+				Routes: []config.RateLimitRouteConfig{
+					{Method: "POST", Path: "/login", Limit: 1},
+					{Path: "/public/*", Limit: 3},
+				},
+			},
+		},
+	}
+	m, err := NewRateLimitMiddleware(cfg, logger, nil)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(m.Handle())
+	app.All("/*", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		allowed  int
+		limitHdr string
+	}{
+		{"Default rule", http.MethodGet, "/items", 2, "2"},
+		{"Exact route override", http.MethodPost, "/login", 1, "1"},
+		{"Prefix route override", http.MethodGet, "/public/docs", 3, "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.allowed; i++ {
+				resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, tt.limitHdr, resp.Header.Get("RateLimit-Limit"))
+			}
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+			assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+			assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+		})
+	}
+}
+
+func TestRateLimitMiddlewareKeyBy(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{
+			RateLimit: config.RateLimitConfig{Limit: 1, Window: 60, KeyBy: RateLimitKeyByAPIKey},
+		},
+	}
+	m, err := NewRateLimitMiddleware(cfg, logger, nil)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/", m.Handle(), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	request := func(apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, request("client-a"))
+	assert.Equal(t, http.StatusTooManyRequests, request("client-a"))
+	assert.Equal(t, http.StatusOK, request("client-b"))
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Sliding window frees slots after the window", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+		rule := RateLimitRule{Algorithm: RateLimitAlgorithmSlidingWindow, Limit: 1, Window: 20 * time.Millisecond}
+
+		res, _ := store.Allow(ctx, "k", rule)
+		assert.True(t, res.Allowed)
+		res, _ = store.Allow(ctx, "k", rule)
+		assert.False(t, res.Allowed)
+
+		time.Sleep(25 * time.Millisecond)
+		res, _ = store.Allow(ctx, "k", rule)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Eviction keeps the keys of rules with longer windows", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+		hourly := RateLimitRule{Algorithm: RateLimitAlgorithmSlidingWindow, Limit: 1, Window: time.Hour}
+		short := RateLimitRule{Algorithm: RateLimitAlgorithmSlidingWindow, Limit: 1, Window: time.Millisecond}
+
+		res, _ := store.Allow(ctx, "hourly", hourly)
+		assert.True(t, res.Allowed)
+		time.Sleep(5 * time.Millisecond)
+
+		// Calls under the short rule sweep idle keys
+		for i := 0; i < 999; i++ {
+			_, _ = store.Allow(ctx, "short", short)
+		}
+		assert.Contains(t, store.windows, "hourly")
+		res, _ = store.Allow(ctx, "hourly", hourly)
+		assert.False(t, res.Allowed, "the hourly count is not reset")
+	})
+
+	t.Run("Token bucket allows bursts then refills", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+		rule := RateLimitRule{Algorithm: RateLimitAlgorithmTokenBucket, Limit: 1, Window: 20 * time.Millisecond, Burst: 2}
+
+		for i := 0; i < 2; i++ {
+			res, _ := store.Allow(ctx, "k", rule)
+			assert.True(t, res.Allowed)
+		}
+		res, _ := store.Allow(ctx, "k", rule)
+		assert.False(t, res.Allowed)
+		assert.Greater(t, res.Reset, time.Duration(0))
+
+		time.Sleep(25 * time.Millisecond)
+		res, _ = store.Allow(ctx, "k", rule)
+		assert.True(t, res.Allowed)
+	})
+}
+
+func TestRateLimitMiddlewareRedisBackend(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	cfg := &config.Config{HTTP: config.HTTPConfig{RateLimit: config.RateLimitConfig{Enabled: true, Backend: "redis"}}}
+
+	_, err := NewRateLimitMiddleware(cfg, logger, nil)
+	assert.ErrorIs(t, err, axredis.ErrNoClient)
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	m, err := NewRateLimitMiddleware(cfg, logger, client)
+	require.NoError(t, err)
+	assert.IsType(t, &RedisRateLimitStore{}, m.store)
+}
+
+func TestRedisRateLimitStore(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("Skipping Redis rate limit test; set REDIS_ADDR")
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	store := NewRedisRateLimitStore(client, "ratelimit-test-"+time.Now().Format("150405.000"))
+
+	for _, algorithm := range []string{RateLimitAlgorithmSlidingWindow, RateLimitAlgorithmTokenBucket} {
+		t.Run(algorithm, func(t *testing.T) {
+			rule := RateLimitRule{Algorithm: algorithm, Limit: 2, Window: time.Minute}
+			for i := 0; i < 2; i++ {
+				res, err := store.Allow(ctx, algorithm, rule)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+			}
+			res, err := store.Allow(ctx, algorithm, rule)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestContext stores the request ID, tenant, locale and deadline of the request in its
+// user context, so they reach services, database calls and the HTTP clients and Kafka
+// producers that forward them. The request ID is taken from the caller or generated, and
+// returned in the response; the tenant is read from tenantHeader. The user ID is added by
+// the authentication middleware.
+func RequestContext(tenantHeader string) fiber.Handler {
+	if tenantHeader == "" {
+		tenantHeader = ctxkit.HeaderTenantID
+	}
+	return func(c *fiber.Ctx) error {
+		ctx := ctxkit.FromHeaders(c.UserContext(), func(header string) string {
+			if header == ctxkit.HeaderTenantID {
+				header = tenantHeader
+			}
+			return c.Get(header)
+		})
+
+		requestID := ctxkit.RequestID(ctx)
+		if requestID == "" {
+			requestID = ctxkit.NewRequestID()
+			ctx = ctxkit.WithRequestID(ctx, requestID)
+			// Handlers and proxies reading the header see the generated ID too
+			c.Request().Header.Set(ctxkit.HeaderRequestID, requestID)
+		}
+		c.Set(ctxkit.HeaderRequestID, requestID)
+
+		ctx, cancel := ctxkit.WithTimeout(ctx, c.Get(ctxkit.HeaderTimeout))
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// RequestID returns the request ID of the request, set by RequestContext
+func RequestID(c *fiber.Ctx) string {
+	return ctxkit.RequestID(c.UserContext())
+}
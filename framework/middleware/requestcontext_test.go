@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestContext(t *testing.T) {
+	var ctx context.Context
+	var header string
+	app := fiber.New()
+	app.Use(RequestContext("X-Org-ID"))
+	app.Get("/orders", func(c *fiber.Ctx) error {
+		ctx = c.UserContext()
+		header = c.Get(ctxkit.HeaderRequestID)
+		return nil
+	})
+
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		requestID string
+		tenantID  string
+		locale    string
+		deadline  bool
+	}{
+		{"from headers", map[string]string{
+			ctxkit.HeaderRequestID: "req-1",
+			"X-Org-ID":             "acme",
+			ctxkit.HeaderLocale:    "fr-CH, fr;q=0.9",
+			ctxkit.HeaderTimeout:   "5000",
+		}, "req-1", "acme", "fr-CH", true},
+		{"generated request ID", nil, "", "", "", false},
+		{"invalid request ID", map[string]string{ctxkit.HeaderRequestID: "has spaces"}, "", "", "", false},
+		{"user ID not trusted", map[string]string{ctxkit.HeaderUserID: "admin", ctxkit.HeaderTimeout: "soon"}, "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			requestID := ctxkit.RequestID(ctx)
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, requestID)
+			} else {
+				assert.True(t, ctxkit.ValidRequestID(requestID))
+				assert.NotEqual(t, tt.headers[ctxkit.HeaderRequestID], requestID)
+			}
+			assert.Equal(t, requestID, resp.Header.Get(ctxkit.HeaderRequestID))
+			assert.Equal(t, requestID, header, "the request header carries the ID used")
+			assert.Equal(t, tt.tenantID, ctxkit.TenantID(ctx))
+			assert.Equal(t, tt.locale, ctxkit.Locale(ctx))
+			assert.Empty(t, ctxkit.UserID(ctx))
+
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, tt.deadline, ok)
+			if ok {
+				assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"go.uber.org/zap"
+)
+
+// Defaults of the security middleware
+const (
+	DefaultCSRFCookieName        = "csrf_token"
+	DefaultCSRFHeaderName        = "X-CSRF-Token"
+	DefaultCSRFExpiration        = 12 * time.Hour
+	DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	DefaultFrameOptions          = "DENY"
+	DefaultReferrerPolicy        = "no-referrer"
+	// DefaultHSTSMaxAge is the HSTS max age sent in production when none is configured
+	DefaultHSTSMaxAge = 365 * 24 * 60 * 60
+)
+
+// csrfTokenLocal is the context key holding the CSRF token of the current request
+const csrfTokenLocal = "csrf_token"
+
+// SecurityMiddleware applies the browser security policy of the HTTP server: the CORS policy,
+// CSRF protection of cookie-authenticated requests and the security headers
+type SecurityMiddleware struct {
+	cors    fiber.Handler
+	headers [][2]string
+	csrf    *csrfProtection
+	logger  *observability.Logger
+}
+
+// csrfProtection checks signed double-submit tokens: the token is set as a cookie readable by
+// scripts and must be echoed in a header, which other sites cannot do
+type csrfProtection struct {
+	secret     []byte
+	cookieName string
+	headerName string
+	expiration time.Duration
+	secure     bool
+	exempt     []routePattern
+}
+
+// NewSecurityMiddleware creates the security middleware from the HTTP security configuration.
+// Defaults are stricter in production: cross-origin requests need explicitly allowed origins
+// and HSTS is sent.
+func NewSecurityMiddleware(cfg *config.Config, logger *observability.Logger) (*SecurityMiddleware, error) {
+	production := cfg.App.Environment == "production"
+	secCfg := cfg.HTTP.Security
+
+	corsHandler, err := newCORSHandler(secCfg.CORS, production)
+	if err != nil {
+		return nil, err
+	}
+	if corsHandler == nil {
+		logger.Info("CORS disabled in production without http.security.cors.allowOrigins; cross-origin requests are refused")
+		corsHandler = func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	m := &SecurityMiddleware{
+		cors:    corsHandler,
+		headers: securityHeaders(secCfg.Headers, production),
+		logger:  logger,
+	}
+
+	if secCfg.CSRF.Enabled {
+		m.csrf, err = newCSRFProtection(secCfg.CSRF, production || cfg.HTTP.TLS.Enabled, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// newCORSHandler creates the CORS middleware, or returns nil when cross-origin requests are refused
+func newCORSHandler(corsCfg config.CORSConfig, production bool) (handler fiber.Handler, err error) {
+	origins := strings.Join(corsCfg.AllowOrigins, ",")
+	if origins == "" {
+		if production {
+			return nil, nil
+		}
+		origins = "*"
+	}
+	if corsCfg.AllowCredentials && strings.Contains(origins, "*") && !strings.Contains(origins, "*.") {
+		return nil, fmt.Errorf("http.security.cors: allowCredentials requires explicit origins")
+	}
+
+	// The CORS middleware panics on invalid settings
+	defer func() {
+		if r := recover(); r != nil {
+			handler, err = nil, fmt.Errorf("http.security.cors: %v", r)
+		}
+	}()
+	return cors.New(cors.Config{
+		AllowOrigins:     origins,
+		AllowMethods:     strings.Join(corsCfg.AllowMethods, ","),
+		AllowHeaders:     strings.Join(corsCfg.AllowHeaders, ","),
+		ExposeHeaders:    strings.Join(corsCfg.ExposeHeaders, ","),
+		AllowCredentials: corsCfg.AllowCredentials,
+		MaxAge:           corsCfg.MaxAge,
+	}), nil
+}
+
+// securityHeaders returns the headers added to every response
+func securityHeaders(headersCfg config.SecurityHeadersConfig, production bool) [][2]string {
+	if !headersCfg.Enabled {
+		return nil
+	}
+	headers := [][2]string{
+		{fiber.HeaderXContentTypeOptions, "nosniff"},
+		{fiber.HeaderXFrameOptions, valueOr(headersCfg.FrameOptions, DefaultFrameOptions)},
+		{fiber.HeaderReferrerPolicy, valueOr(headersCfg.ReferrerPolicy, DefaultReferrerPolicy)},
+		{fiber.HeaderContentSecurityPolicy, valueOr(headersCfg.ContentSecurityPolicy, DefaultContentSecurityPolicy)},
+	}
+
+	maxAge := headersCfg.HSTSMaxAge
+	if maxAge == 0 && production {
+		maxAge = DefaultHSTSMaxAge
+	}
+	if maxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(maxAge)
+		if headersCfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		headers = append(headers, [2]string{fiber.HeaderStrictTransportSecurity, hsts})
+	}
+	return headers
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// newCSRFProtection creates the CSRF protection; secure sets the Secure attribute of the cookie
+func newCSRFProtection(csrfCfg config.CSRFConfig, secure bool, logger *observability.Logger) (*csrfProtection, error) {
+	secret := []byte(csrfCfg.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate the CSRF secret: %w", err)
+		}
+		logger.Warn("No http.security.csrf.secret set; CSRF tokens are only valid on this instance until it restarts")
+	}
+
+	expiration := time.Duration(csrfCfg.Expiration) * time.Second
+	if expiration <= 0 {
+		expiration = DefaultCSRFExpiration
+	}
+	exempt := make([]routePattern, 0, len(csrfCfg.ExemptPaths))
+	for _, path := range csrfCfg.ExemptPaths {
+		exempt = append(exempt, newRoutePattern("", path))
+	}
+
+	return &csrfProtection{
+		secret:     secret,
+		cookieName: valueOr(csrfCfg.CookieName, DefaultCSRFCookieName),
+		headerName: valueOr(csrfCfg.HeaderName, DefaultCSRFHeaderName),
+		expiration: expiration,
+		secure:     secure,
+		exempt:     exempt,
+	}, nil
+}
+
+// Headers returns a Fiber middleware handler adding the security headers to every response
+func (m *SecurityMiddleware) Headers() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, header := range m.headers {
+			c.Set(header[0], header[1])
+		}
+		return c.Next()
+	}
+}
+
+// CORS returns a Fiber middleware handler applying the CORS policy
+func (m *SecurityMiddleware) CORS() fiber.Handler {
+	return m.cors
+}
+
+// CSRFEnabled reports whether CSRF protection is configured
+func (m *SecurityMiddleware) CSRFEnabled() bool {
+	return m.csrf != nil
+}
+
+// CSRF returns a Fiber middleware handler protecting cookie-authenticated requests against
+// cross-site request forgery. Safe requests get a token cookie; other requests carrying
+// cookies must echo it in the CSRF header. Requests with an Authorization header are not
+// checked, since browsers do not add it on their own.
+func (m *SecurityMiddleware) CSRF() fiber.Handler {
+	p := m.csrf
+	return func(c *fiber.Ctx) error {
+		if p == nil {
+			return c.Next()
+		}
+		cookie := c.Cookies(p.cookieName)
+		valid := p.verify(cookie, time.Now())
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+			if !valid {
+				cookie = p.issue(c, time.Now())
+			}
+			c.Locals(csrfTokenLocal, cookie)
+			return c.Next()
+		}
+
+		if c.Get(fiber.HeaderAuthorization) != "" || len(c.Request().Header.Peek(fiber.HeaderCookie)) == 0 || p.exempted(c.Path()) {
+			return c.Next()
+		}
+		token := c.Get(p.headerName)
+		if !valid || subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) != 1 {
+			m.logger.Debug("CSRF check failed", zap.String("method", c.Method()), zap.String("path", c.Path()))
+			return fiber.NewError(fiber.StatusForbidden, "missing or invalid CSRF token")
+		}
+		c.Locals(csrfTokenLocal, cookie)
+		return c.Next()
+	}
+}
+
+// CSRFToken returns the CSRF token of the request, to embed in pages that send it back, or
+// an empty string when CSRF protection is disabled
+func CSRFToken(c *fiber.Ctx) string {
+	token, _ := c.Locals(csrfTokenLocal).(string)
+	return token
+}
+
+func (p *csrfProtection) exempted(path string) bool {
+	for _, pattern := range p.exempt {
+		if pattern.matches("", path) {
+			return true
+		}
+	}
+	return false
+}
+
+// issue sets a new token cookie and returns the token
+func (p *csrfProtection) issue(c *fiber.Ctx, now time.Time) string {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	expires := now.Add(p.expiration)
+	payload := base64.RawURLEncoding.EncodeToString(nonce) + "." + strconv.FormatInt(expires.Unix(), 10)
+	token := payload + "." + p.sign(payload)
+
+	c.Cookie(&fiber.Cookie{
+		Name:     p.cookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   p.secure,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		// Scripts read the cookie to send the token back in the header
+		HTTPOnly: false,
+	})
+	return token
+}
+
+// verify reports whether token was signed with the secret and has not expired
+func (p *csrfProtection) verify(token string, now time.Time) bool {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(p.sign(payload))) {
+		return false
+	}
+	_, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	return err == nil && now.Unix() < expires
+}
+
+func (p *csrfProtection) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecurityApp(t *testing.T, cfg *config.Config) *fiber.App {
+	logger, _ := observability.NewLogger(cfg)
+	m, err := NewSecurityMiddleware(cfg, logger)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(m.Headers(), m.CORS(), m.CSRF())
+	app.Get("/form", func(c *fiber.Ctx) error { return c.SendString(CSRFToken(c)) })
+	app.Post("/orders", func(c *fiber.Ctx) error { return c.SendString("created") })
+	app.Post("/webhooks/stripe", func(c *fiber.Ctx) error { return c.SendString("received") })
+	return app
+}
+
+func TestSecurityCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		cors        config.CORSConfig
+		origin      string
+		allowOrigin string
+	}{
+		{"any origin by default", "local", config.CORSConfig{}, "https://evil.example", "*"},
+		{"refused in production by default", "production", config.CORSConfig{}, "https://app.example.com", ""},
+		{"allowed origin", "production", config.CORSConfig{AllowOrigins: []string{"https://app.example.com"}}, "https://app.example.com", "https://app.example.com"},
+		{"other origin", "production", config.CORSConfig{AllowOrigins: []string{"https://app.example.com"}}, "https://evil.example", ""},
+		{"subdomain", "production", config.CORSConfig{AllowOrigins: []string{"https://*.example.com"}}, "https://admin.example.com", "https://admin.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newSecurityApp(t, &config.Config{
+				App:  config.AppConfig{Environment: tt.environment},
+				HTTP: config.HTTPConfig{Security: config.SecurityConfig{CORS: tt.cors}},
+			})
+			req := httptest.NewRequest(http.MethodOptions, "/orders", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowOrigin, resp.Header.Get("Access-Control-Allow-Origin"))
+		})
+	}
+
+	t.Run("credentials need explicit origins", func(t *testing.T) {
+		logger, _ := observability.NewLogger(&config.Config{})
+		_, err := NewSecurityMiddleware(&config.Config{HTTP: config.HTTPConfig{Security: config.SecurityConfig{
+			CORS: config.CORSConfig{AllowCredentials: true},
+		}}}, logger)
+		assert.Error(t, err)
+
+		_, err = NewSecurityMiddleware(&config.Config{HTTP: config.HTTPConfig{Security: config.SecurityConfig{
+			CORS: config.CORSConfig{AllowOrigins: []string{"not an origin"}},
+		}}}, logger)
+		assert.Error(t, err)
+	})
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		headers     config.SecurityHeadersConfig
+		want        map[string]string
+	}{
+		{"disabled", "production", config.SecurityHeadersConfig{}, map[string]string{
+			"X-Content-Type-Options": "", "Strict-Transport-Security": "",
+		}},
+		{"defaults", "local", config.SecurityHeadersConfig{Enabled: true}, map[string]string{
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           DefaultFrameOptions,
+			"Referrer-Policy":           DefaultReferrerPolicy,
+			"Content-Security-Policy":   DefaultContentSecurityPolicy,
+			"Strict-Transport-Security": "",
+		}},
+		{"HSTS in production", "production", config.SecurityHeadersConfig{Enabled: true}, map[string]string{
+			"Strict-Transport-Security": "max-age=31536000",
+		}},
+		{"HSTS disabled in production", "production", config.SecurityHeadersConfig{Enabled: true, HSTSMaxAge: -1}, map[string]string{
+			"Strict-Transport-Security": "",
+		}},
+		{"configured", "local", config.SecurityHeadersConfig{
+			Enabled: true, HSTSMaxAge: 600, HSTSIncludeSubdomains: true,
+			ContentSecurityPolicy: "default-src 'self'", FrameOptions: "SAMEORIGIN", ReferrerPolicy: "same-origin",
+		}, map[string]string{
+			"Strict-Transport-Security": "max-age=600; includeSubDomains",
+			"Content-Security-Policy":   "default-src 'self'",
+			"X-Frame-Options":           "SAMEORIGIN",
+			"Referrer-Policy":           "same-origin",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newSecurityApp(t, &config.Config{
+				App:  config.AppConfig{Environment: tt.environment},
+				HTTP: config.HTTPConfig{Security: config.SecurityConfig{Headers: tt.headers}},
+			})
+			// Error responses carry the headers too
+			for _, path := range []string{"/form", "/missing"} {
+				resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+				require.NoError(t, err)
+				for header, value := range tt.want {
+					assert.Equal(t, value, resp.Header.Get(header), header)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityCSRF(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{Security: config.SecurityConfig{CSRF: config.CSRFConfig{
+		Enabled:     true,
+		Secret:      "csrf-secret",
+		ExemptPaths: []string{"/webhooks/*"},
+	}}}}
+	app := newSecurityApp(t, cfg)
+
+	// Safe requests get a token cookie, also exposed to handlers
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/form", nil))
+	require.NoError(t, err)
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == DefaultCSRFCookieName {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie)
+	assert.False(t, cookie.HttpOnly)
+	assert.False(t, cookie.Secure)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, cookie.Value, string(body))
+
+	// A token signed with another secret is not accepted
+	otherCfg := *cfg
+	otherCfg.HTTP.Security.CSRF.Secret = "other-secret"
+	logger, _ := observability.NewLogger(cfg)
+	other, err := NewSecurityMiddleware(&otherCfg, logger)
+	require.NoError(t, err)
+	forged := other.csrf.sign("nonce.9999999999")
+
+	tests := []struct {
+		name   string
+		path   string
+		cookie string
+		header string
+		auth   string
+		status int
+	}{
+		{"matching token", "/orders", cookie.Value, cookie.Value, "", http.StatusOK},
+		{"missing header", "/orders", cookie.Value, "", "", http.StatusForbidden},
+		{"different header", "/orders", cookie.Value, cookie.Value + "x", "", http.StatusForbidden},
+		{"forged token", "/orders", "nonce.9999999999." + forged, "nonce.9999999999." + forged, "", http.StatusForbidden},
+		{"no cookies", "/orders", "", "", "", http.StatusOK},
+		{"bearer auth", "/orders", cookie.Value, "", "Bearer token", http.StatusOK},
+		{"exempt path", "/webhooks/stripe", cookie.Value, "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(DefaultCSRFHeaderName, tt.header)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+
+	t.Run("expired token", func(t *testing.T) {
+		logger, _ := observability.NewLogger(cfg)
+		m, err := NewSecurityMiddleware(cfg, logger)
+		require.NoError(t, err)
+		payload := "nonce.1"
+		assert.False(t, m.csrf.verify(payload+"."+m.csrf.sign(payload), time.Now()))
+		assert.True(t, m.csrf.verify("nonce.9999999999."+m.csrf.sign("nonce.9999999999"), time.Now()))
+	})
+
+	t.Run("secure cookie in production", func(t *testing.T) {
+		prodCfg := *cfg
+		prodCfg.App.Environment = "production"
+		resp, err := newSecurityApp(t, &prodCfg).Test(httptest.NewRequest(http.MethodGet, "/form", nil))
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.Cookies())
+		assert.True(t, resp.Cookies()[0].Secure)
+	})
+}
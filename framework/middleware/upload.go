@@ -0,0 +1,312 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultUploadMaxMemory is how many bytes of uploaded files ParseMultipart keeps in memory per request
+const defaultUploadMaxMemory = 1 << 20
+
+// sniffLen is the number of bytes used to detect the content type of an uploaded file
+const sniffLen = 512
+
+// UploadOptions controls how multipart/form-data uploads are read
+type UploadOptions struct {
+	// MaxFileSize is the maximum size of a single file in bytes; zero means no limit
+	MaxFileSize int64
+	// MaxMemory is how many bytes of files ParseMultipart keeps in memory before spilling to disk
+	MaxMemory int64
+	// AllowedContentTypes lists the accepted file types, e.g. "image/png" or "image/*"; empty accepts any
+	AllowedContentTypes []string
+	// TempDir is where ParseMultipart spills large files; empty uses the system default
+	TempDir string
+}
+
+// UploadOptionsFromConfig returns the upload options from the HTTP upload configuration
+func UploadOptionsFromConfig(cfg *config.Config) UploadOptions {
+	return UploadOptions{
+		MaxFileSize:         cfg.HTTP.Upload.MaxFileSize,
+		MaxMemory:           cfg.HTTP.Upload.MaxMemory,
+		AllowedContentTypes: cfg.HTTP.Upload.AllowedContentTypes,
+		TempDir:             cfg.HTTP.Upload.TempDir,
+	}
+}
+
+// UploadPart is a file from a multipart/form-data request, read directly from the request body
+type UploadPart struct {
+	io.Reader
+	FieldName string
+	FileName  string
+	// ContentType is detected from the file content, falling back to the declared type
+	ContentType string
+	// Values holds the form fields sent before this file
+	Values url.Values
+}
+
+// UploadHandler returns a Fiber handler that streams every file of a multipart/form-data request
+// to fn without buffering it. Parts must be consumed in order; a part is discarded once fn returns.
+// With request body streaming enabled, files larger than the body limit never reach memory.
+func UploadHandler(opts UploadOptions, fn func(c *fiber.Ctx, part *UploadPart) error) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		reader, err := multipartReader(c)
+		if err != nil {
+			return err
+		}
+
+		values := url.Values{}
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return uploadError(err)
+			}
+
+			if p.FileName() == "" {
+				if err := readFormValue(p, values); err != nil {
+					return err
+				}
+				continue
+			}
+
+			part, limited, err := newUploadPart(p, opts, values)
+			if err != nil {
+				return err
+			}
+			err = fn(c, part)
+			if limited != nil && limited.exceeded() {
+				return fiber.NewError(fiber.StatusRequestEntityTooLarge, "file "+p.FileName()+" is too large")
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MultipartForm is a parsed multipart/form-data request
+type MultipartForm struct {
+	Value url.Values
+	File  map[string][]*UploadedFile
+}
+
+// RemoveAll removes the temporary files of the form
+func (f *MultipartForm) RemoveAll() error {
+	var errs []error
+	for _, files := range f.File {
+		for _, file := range files {
+			if file.path != "" {
+				if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UploadedFile is a file read by ParseMultipart, held in memory or in a temporary file
+type UploadedFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Size        int64
+
+	content []byte
+	path    string
+}
+
+// Open returns a reader for the file content
+func (f *UploadedFile) Open() (io.ReadCloser, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return io.NopCloser(bytes.NewReader(f.content)), nil
+}
+
+// ParseMultipart reads a multipart/form-data request, enforcing opts. Files are kept in memory
+// up to MaxMemory in total and spilled to temporary files beyond that; call RemoveAll when done.
+func ParseMultipart(c *fiber.Ctx, opts UploadOptions) (*MultipartForm, error) {
+	reader, err := multipartReader(c)
+	if err != nil {
+		return nil, err
+	}
+
+	memory := opts.MaxMemory
+	if memory <= 0 {
+		memory = defaultUploadMaxMemory
+	}
+
+	form := &MultipartForm{
+		Value: url.Values{},
+		File:  make(map[string][]*UploadedFile),
+	}
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			_ = form.RemoveAll()
+			return nil, uploadError(err)
+		}
+
+		if p.FileName() == "" {
+			if err := readFormValue(p, form.Value); err != nil {
+				_ = form.RemoveAll()
+				return nil, err
+			}
+			continue
+		}
+
+		file, err := spillFile(p, opts, form.Value, &memory)
+		if err != nil {
+			_ = form.RemoveAll()
+			return nil, err
+		}
+		form.File[file.FieldName] = append(form.File[file.FieldName], file)
+	}
+}
+
+// spillFile reads a file part into memory while the budget allows and into a temporary file otherwise
+func spillFile(p *multipart.Part, opts UploadOptions, values url.Values, memory *int64) (*UploadedFile, error) {
+	part, limited, err := newUploadPart(p, opts, values)
+	if err != nil {
+		return nil, err
+	}
+	file := &UploadedFile{
+		FieldName:   part.FieldName,
+		FileName:    part.FileName,
+		ContentType: part.ContentType,
+	}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, part, *memory+1)
+	if err != nil && err != io.EOF {
+		return nil, fileReadError(p, limited, err)
+	}
+	if n <= *memory {
+		*memory -= n
+		file.content = buf.Bytes()
+		file.Size = n
+		return file, nil
+	}
+
+	tmp, err := os.CreateTemp(opts.TempDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	file.path = tmp.Name()
+
+	size, err := io.Copy(tmp, io.MultiReader(&buf, part))
+	if err != nil {
+		os.Remove(file.path)
+		return nil, fileReadError(p, limited, err)
+	}
+	file.Size = size
+	return file, nil
+}
+
+// newUploadPart wraps a file part with the size limit and checks its content type
+func newUploadPart(p *multipart.Part, opts UploadOptions, values url.Values) (*UploadPart, *maxBytesReader, error) {
+	var r io.Reader = p
+	var limited *maxBytesReader
+	if opts.MaxFileSize > 0 {
+		limited = newMaxBytesReader(p, opts.MaxFileSize, fiber.ErrRequestEntityTooLarge)
+		r = limited
+	}
+
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, nil, fileReadError(p, limited, err)
+	}
+
+	contentType := http.DetectContentType(head)
+	if declared := p.Header.Get(fiber.HeaderContentType); declared != "" && strings.HasPrefix(contentType, "application/octet-stream") {
+		contentType = declared
+	}
+	if !contentTypeAllowed(opts.AllowedContentTypes, contentType) {
+		return nil, nil, fiber.NewError(fiber.StatusUnsupportedMediaType, "file type "+contentType+" is not allowed")
+	}
+
+	return &UploadPart{
+		Reader:      br,
+		FieldName:   p.FormName(),
+		FileName:    p.FileName(),
+		ContentType: contentType,
+		Values:      values,
+	}, limited, nil
+}
+
+// multipartReader returns a streaming reader over the multipart/form-data request body
+func multipartReader(c *fiber.Ctx) (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if err != nil || mediaType != fiber.MIMEMultipartForm || params["boundary"] == "" {
+		return nil, fiber.NewError(fiber.StatusUnsupportedMediaType, "expected a multipart/form-data request")
+	}
+	return multipart.NewReader(BodyReader(c), params["boundary"]), nil
+}
+
+// readFormValue reads a non-file field into values
+func readFormValue(p *multipart.Part, values url.Values) error {
+	// Form fields are small; cap them so they cannot be used to exhaust memory
+	value, err := io.ReadAll(io.LimitReader(p, defaultUploadMaxMemory))
+	if err != nil {
+		return uploadError(err)
+	}
+	values.Add(p.FormName(), string(value))
+	return nil
+}
+
+// contentTypeAllowed reports whether contentType matches one of the allowed types
+func contentTypeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		if a == mediaType || a == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fileReadError reports an oversized file as 413 and anything else as a malformed upload
+func fileReadError(p *multipart.Part, limited *maxBytesReader, err error) error {
+	if limited != nil && limited.exceeded() {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "file "+p.FileName()+" is too large")
+	}
+	return uploadError(err)
+}
+
+// uploadError maps errors reading the request body to a client error
+func uploadError(err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr
+	}
+	return fiber.NewError(fiber.StatusBadRequest, "malformed multipart request: "+err.Error())
+}
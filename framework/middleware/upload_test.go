@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// multipartRequest builds a multipart/form-data request with a description field and the given files
+func multipartRequest(t *testing.T, files map[string][]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	require.NoError(t, w.WriteField("description", "holiday"))
+	for name, content := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+		h.Set("Content-Type", "application/octet-stream")
+		part, err := w.CreatePart(h)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestUploadHandler(t *testing.T) {
+	opts := UploadOptions{
+		MaxFileSize:         64,
+		AllowedContentTypes: []string{"image/*", "text/plain"},
+	}
+
+	var received []string
+	app := fiber.New()
+	app.Post("/upload", UploadHandler(opts, func(c *fiber.Ctx, part *UploadPart) error {
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		received = append(received, part.FileName+":"+part.ContentType+":"+part.Values.Get("description")+":"+string(data[:4]))
+		return nil
+	}))
+
+	tests := []struct {
+		name     string
+		files    map[string][]byte
+		status   int
+		received []string
+	}{
+		{
+			name:     "detects and streams allowed files",
+			files:    map[string][]byte{"photo.png": append(pngHeader, make([]byte, 16)...)},
+			status:   http.StatusOK,
+			received: []string{"photo.png:image/png:holiday:\x89PNG"},
+		},
+		{
+			name:   "rejects disallowed content",
+			files:  map[string][]byte{"doc.pdf": []byte("%PDF-1.7 ...")},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "rejects oversized files",
+			files:  map[string][]byte{"big.png": append(pngHeader, make([]byte, 64)...)},
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			resp, err := app.Test(multipartRequest(t, tt.files))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.received, received)
+		})
+	}
+
+	t.Run("rejects non-multipart requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader([]byte("{}")))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+}
+
+func TestParseMultipart(t *testing.T) {
+	opts := UploadOptions{MaxMemory: 32, TempDir: t.TempDir()}
+	small := []byte("hello world")
+	large := bytes.Repeat([]byte("x"), 100)
+
+	app := fiber.New()
+	app.Post("/upload", func(c *fiber.Ctx) error {
+		form, err := ParseMultipart(c, opts)
+		if err != nil {
+			return err
+		}
+		defer form.RemoveAll()
+
+		assert.Equal(t, "holiday", form.Value.Get("description"))
+		require.Len(t, form.File["file"], 2)
+
+		var spilled string
+		for _, file := range form.File["file"] {
+			r, err := file.Open()
+			require.NoError(t, err)
+			data, _ := io.ReadAll(r)
+			r.Close()
+
+			switch file.FileName {
+			case "small.txt":
+				assert.Equal(t, small, data)
+				assert.Empty(t, file.path, "small files stay in memory")
+			case "large.txt":
+				assert.Equal(t, large, data)
+				assert.EqualValues(t, len(large), file.Size)
+				require.NotEmpty(t, file.path, "large files spill to disk")
+				spilled = file.path
+			}
+		}
+
+		require.NoError(t, form.RemoveAll())
+		_, err = os.Stat(spilled)
+		assert.True(t, os.IsNotExist(err))
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	resp, err := app.Test(multipartRequest(t, map[string][]byte{"small.txt": small, "large.txt": large}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestContentTypeAllowed(t *testing.T) {
+	tests := []struct {
+		allowed     []string
+		contentType string
+		want        bool
+	}{
+		{nil, "application/zip", true},
+		{[]string{"image/png"}, "image/png", true},
+		{[]string{"image/*"}, "image/jpeg", true},
+		{[]string{"image/*"}, "imagex/jpeg", false},
+		{[]string{"text/plain"}, "text/plain; charset=utf-8", true},
+		{[]string{"text/plain"}, "text/html; charset=utf-8", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, contentTypeAllowed(tt.allowed, tt.contentType), tt.contentType)
+	}
+}
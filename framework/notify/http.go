@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout bounds the requests of the HTTP providers
+const defaultHTTPTimeout = 10 * time.Second
+
+// postJSON posts body as JSON to url, marking transport errors and the statuses of
+// throttled or failing services as temporary
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Temporary(err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// checkResponse turns an unsuccessful response into an error, temporary for 429 and 5xx
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err := fmt.Errorf("notify: %s responded %d: %s", resp.Request.URL.Host, resp.StatusCode, strings.TrimSpace(string(detail)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return Temporary(err)
+	}
+	return err
+}
+
+// WebhookProvider posts messages as JSON to an endpoint
+type WebhookProvider struct {
+	url     string
+	secret  []byte
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookProvider creates a provider posting to url. With a secret, bodies are signed
+// with HMAC-SHA256 in the X-Signature-256 header, as "sha256=<hex digest>".
+func NewWebhookProvider(url, secret string, headers map[string]string) *WebhookProvider {
+	return &WebhookProvider{
+		url:     url,
+		secret:  []byte(secret),
+		headers: headers,
+		client:  &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Name implements Provider
+func (p *WebhookProvider) Name() string {
+	return "webhook"
+}
+
+// Channel implements Provider
+func (p *WebhookProvider) Channel() string {
+	return ChannelWebhook
+}
+
+// webhookPayload is the body of webhook notifications
+type webhookPayload struct {
+	To       []string          `json:"to,omitempty"`
+	Subject  string            `json:"subject,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	HTML     string            `json:"html,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Send posts msg
+func (p *WebhookProvider) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(webhookPayload{
+		To:       msg.To,
+		Subject:  msg.Subject,
+		Text:     msg.Text,
+		HTML:     msg.HTML,
+		Template: msg.Template,
+		Metadata: msg.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	for key, value := range p.headers {
+		header.Set(key, value)
+	}
+	if len(p.secret) > 0 {
+		header.Set("X-Signature-256", Signature(p.secret, body))
+	}
+	return postJSON(ctx, p.client, p.url, header, json.RawMessage(body))
+}
+
+// Signature returns the X-Signature-256 header of a webhook body signed with secret, for
+// receivers to compare with hmac.Equal
+func Signature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SlackProvider posts messages to a Slack incoming webhook
+type SlackProvider struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackProvider creates a provider posting to a Slack incoming webhook
+func NewSlackProvider(webhookURL string) *SlackProvider {
+	return &SlackProvider{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Name implements Provider
+func (p *SlackProvider) Name() string {
+	return "slack"
+}
+
+// Channel implements Provider
+func (p *SlackProvider) Channel() string {
+	return ChannelSlack
+}
+
+// Send posts the text of msg, under its subject in bold if it has one
+func (p *SlackProvider) Send(ctx context.Context, msg *Message) error {
+	text := msg.Text
+	if msg.Subject != "" {
+		text = "*" + msg.Subject + "*\n" + text
+	}
+	return postJSON(ctx, p.client, p.webhookURL, nil, map[string]string{"text": text})
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Fx value groups collecting the templates and providers of modules
+const (
+	TemplatesGroup = "notify_templates"
+	ProvidersGroup = "notify_providers"
+)
+
+// policyName is the resilience policy configuring the retries and circuit breakers of sends
+const policyName = "notify"
+
+// Module provides the notifier
+var Module = fx.Options(
+	fx.Provide(ProvideNotifier),
+)
+
+// TemplateSource is a directory of message templates declared by a module
+type TemplateSource struct {
+	FS  fs.FS
+	Dir string
+}
+
+// Templates declares the message templates of a module, loaded into the notifier at startup:
+//
+//	//go:embed templates
+//	var templates embed.FS
+//
+//	notify.Templates(templates, "templates")
+func Templates(fsys fs.FS, dir string) fx.Option {
+	source := TemplateSource{FS: fsys, Dir: dir}
+	return fx.Provide(fx.Annotated{
+		Group:  TemplatesGroup,
+		Target: func() TemplateSource { return source },
+	})
+}
+
+// AsProvider annotates the constructor of a provider, e.g. of SMS, for the notifier to
+// register it. Providers declared this way replace the configured provider of their channel.
+func AsProvider(constructor interface{}) interface{} {
+	return fx.Annotate(
+		constructor,
+		fx.As(new(Provider)),
+		fx.ResultTags(`group:"`+ProvidersGroup+`"`),
+	)
+}
+
+// NotifierParams holds the dependencies of the notifier
+type NotifierParams struct {
+	fx.In
+
+	Config    *config.Config
+	Logger    *observability.Logger
+	Metrics   *observability.Metrics `optional:"true"`
+	Templates []TemplateSource       `group:"notify_templates"`
+	Providers []Provider             `group:"notify_providers"`
+}
+
+// ProvideNotifier provides the notifier with the configured providers and those declared with
+// AsProvider. Templates declared with Templates are loaded first, then those of the configured
+// directory, which take precedence.
+func ProvideNotifier(params NotifierParams) (*Notifier, error) {
+	cfg := params.Config.Notify
+
+	templates := NewRenderer()
+	for _, source := range params.Templates {
+		if err := templates.LoadFS(source.FS, source.Dir); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Templates != "" {
+		if err := templates.LoadFS(os.DirFS(cfg.Templates), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	providers, err := configuredProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+	providers = append(providers, params.Providers...)
+
+	notifier := New(params.Logger).WithTemplates(templates).WithMetrics(params.Metrics)
+	for _, provider := range providers {
+		notifier.Register(provider, policyOptions(params.Config))
+		params.Logger.Info("Registered notification provider",
+			zap.String("channel", provider.Channel()),
+			zap.String("provider", provider.Name()),
+		)
+	}
+	return notifier, nil
+}
+
+// configuredProviders creates the providers enabled in the configuration
+func configuredProviders(cfg config.NotifyConfig) ([]Provider, error) {
+	var providers []Provider
+	switch cfg.Email.Provider {
+	case "":
+	case "smtp":
+		providers = append(providers, NewSMTPProvider(cfg.Email.SMTP, cfg.Email.From))
+	case "ses":
+		provider, err := NewSESProvider(cfg.Email.SES, cfg.Email.From)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	case "sendgrid":
+		providers = append(providers, NewSendGridProvider(cfg.Email.SendGrid, cfg.Email.From))
+	default:
+		return nil, fmt.Errorf("notify: unknown email provider %q", cfg.Email.Provider)
+	}
+	if cfg.Webhook.URL != "" {
+		providers = append(providers, NewWebhookProvider(cfg.Webhook.URL, cfg.Webhook.Secret, cfg.Webhook.Headers))
+	}
+	if cfg.Slack.WebhookURL != "" {
+		providers = append(providers, NewSlackProvider(cfg.Slack.WebhookURL))
+	}
+	return providers, nil
+}
+
+// policyOptions returns the resilience options of a provider from the notify policy, retrying
+// three times unless the policy configures retries
+func policyOptions(cfg *config.Config) *resilience.ResilienceOptions {
+	options := resilience.OptionsFromPolicyConfig(policyName, cfg.Resilience.Policies[policyName])
+	if options.Retry == nil {
+		options.Retry = resilience.DefaultRetryOptions()
+	}
+	return options
+}
@@ -0,0 +1,180 @@
+// Package notify sends email, SMS, webhook and Slack notifications through pluggable
+// providers, rendering messages from templates and retrying temporary failures.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// Channels notifications are sent on
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+)
+
+// Common errors
+var (
+	// ErrNoProvider is returned for messages on a channel no provider is registered for
+	ErrNoProvider = errors.New("notify: no provider for channel")
+	// ErrTemporary marks the failures of providers worth retrying, e.g. a provider that is
+	// down or throttling. Other failures, such as a rejected recipient, are not retried.
+	ErrTemporary = errors.New("notify: temporary failure")
+)
+
+// Temporary marks err as a temporary failure, to be retried
+func Temporary(err error) error {
+	if err == nil || errors.Is(err, ErrTemporary) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrTemporary, err)
+}
+
+// Message is a notification
+type Message struct {
+	Channel string   // email, sms, webhook or slack
+	To      []string // email addresses or phone numbers; webhooks and Slack post to their configured URL
+	From    string   // defaults to the sender of the provider
+	Subject string
+	Text    string
+	HTML    string
+
+	// Template renders the subject, text and HTML bodies from the templates of that name,
+	// executed with Data
+	Template string
+	Data     interface{}
+
+	// Metadata is sent along by the providers that can, e.g. webhooks
+	Metadata map[string]string
+}
+
+// Provider delivers the messages of a channel
+type Provider interface {
+	// Name identifies the provider in logs, metrics and circuit breakers, e.g. "smtp"
+	Name() string
+	// Channel is the channel the provider delivers
+	Channel() string
+	// Send delivers a message. Failures worth retrying are marked with Temporary.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// route is a provider with the resilience policy its sends go through
+type route struct {
+	provider Provider
+	policy   *resilience.Resilience
+}
+
+// Notifier sends messages with the provider of their channel
+type Notifier struct {
+	mu        sync.RWMutex
+	routes    map[string]*route
+	templates *Renderer
+	logger    *observability.Logger
+	metrics   *observability.Metrics
+}
+
+// New creates a notifier without providers
+func New(logger *observability.Logger) *Notifier {
+	return &Notifier{
+		routes:    make(map[string]*route),
+		templates: NewRenderer(),
+		logger:    logger,
+	}
+}
+
+// WithTemplates sets the templates messages are rendered from
+func (n *Notifier) WithTemplates(templates *Renderer) *Notifier {
+	n.templates = templates
+	return n
+}
+
+// WithMetrics records the messages sent on metrics
+func (n *Notifier) WithMetrics(metrics *observability.Metrics) *Notifier {
+	n.metrics = metrics
+	return n
+}
+
+// Register makes provider deliver the messages of its channel, replacing the provider of
+// that channel. Its sends go through a policy with options, retrying temporary failures;
+// nil options retry them three times.
+func (n *Notifier) Register(provider Provider, options *resilience.ResilienceOptions) {
+	if options == nil {
+		options = resilience.DefaultResilienceOptions()
+	}
+	if options.Retry != nil {
+		options.Retry.RetryableErrors = []error{ErrTemporary}
+	}
+	if options.CircuitBreaker != nil {
+		options.CircuitBreaker.Name = "notify-" + provider.Name()
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.routes[provider.Channel()] = &route{provider: provider, policy: resilience.New(options)}
+}
+
+// Provider returns the provider of a channel, nil if there is none
+func (n *Notifier) Provider(channel string) Provider {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if r, ok := n.routes[channel]; ok {
+		return r.provider
+	}
+	return nil
+}
+
+// Send renders msg from its template, if it has one, and delivers it with the provider of
+// its channel
+func (n *Notifier) Send(ctx context.Context, msg Message) error {
+	n.mu.RLock()
+	r, ok := n.routes[msg.Channel]
+	n.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w %q", ErrNoProvider, msg.Channel)
+	}
+
+	if msg.Template != "" {
+		if err := n.templates.Render(&msg); err != nil {
+			return err
+		}
+	}
+	if msg.Channel == ChannelEmail || msg.Channel == ChannelSMS {
+		if len(msg.To) == 0 {
+			return fmt.Errorf("notify: %s message has no recipient", msg.Channel)
+		}
+	}
+
+	_, err := r.policy.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, r.provider.Send(ctx, &msg)
+	})
+
+	result := "sent"
+	if err != nil {
+		result = "failed"
+		n.logger.Error("Failed to send notification",
+			zap.String("channel", msg.Channel),
+			zap.String("provider", r.provider.Name()),
+			zap.String("template", msg.Template),
+			zap.Error(err),
+		)
+	} else {
+		n.logger.Debug("Sent notification",
+			zap.String("channel", msg.Channel),
+			zap.String("provider", r.provider.Name()),
+			zap.String("template", msg.Template),
+		)
+	}
+	if n.metrics != nil && n.metrics.NotifyMessagesTotal != nil {
+		n.metrics.NotifyMessagesTotal.WithLabelValues(msg.Channel, r.provider.Name(), result).Inc()
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider records the messages it is sent, failing with the errors of fail in turn
+type fakeProvider struct {
+	channel string
+	fail    []error
+	calls   atomic.Int32
+	last    *Message
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) Channel() string {
+	return p.channel
+}
+
+func (p *fakeProvider) Send(ctx context.Context, msg *Message) error {
+	call := int(p.calls.Add(1))
+	p.last = msg
+	if call <= len(p.fail) {
+		return p.fail[call-1]
+	}
+	return nil
+}
+
+func fastRetries() *resilience.ResilienceOptions {
+	options := resilience.DefaultResilienceOptions()
+	options.Retry.MaxRetries = 2
+	options.Retry.RetryDelay = time.Millisecond
+	options.Retry.BackoffFactor = 1
+	return options
+}
+
+func TestNotifierSend(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	permanent := errors.New("recipient rejected")
+
+	tests := []struct {
+		name      string
+		msg       Message
+		fail      []error
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name:      "delivers",
+			msg:       Message{Channel: ChannelEmail, To: []string{"a@example.com"}, Subject: "Hi"},
+			wantCalls: 1,
+		},
+		{
+			name:    "no provider for the channel",
+			msg:     Message{Channel: ChannelSlack, Text: "Hi"},
+			wantErr: ErrNoProvider,
+		},
+		{
+			name:    "email without recipient",
+			msg:     Message{Channel: ChannelEmail, Subject: "Hi"},
+			wantErr: errors.New("notify: email message has no recipient"),
+		},
+		{
+			name:      "retries temporary failures",
+			msg:       Message{Channel: ChannelEmail, To: []string{"a@example.com"}},
+			fail:      []error{Temporary(errors.New("down")), Temporary(errors.New("down"))},
+			wantCalls: 3,
+		},
+		{
+			name:      "does not retry permanent failures",
+			msg:       Message{Channel: ChannelEmail, To: []string{"a@example.com"}},
+			fail:      []error{permanent},
+			wantErr:   permanent,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{channel: ChannelEmail, fail: tt.fail}
+			notifier := New(logger)
+			notifier.Register(provider, fastRetries())
+
+			err := notifier.Send(context.Background(), tt.msg)
+			switch {
+			case tt.wantErr == nil:
+				require.NoError(t, err)
+			case errors.Is(err, tt.wantErr):
+			default:
+				assert.EqualError(t, err, tt.wantErr.Error())
+			}
+			assert.Equal(t, tt.wantCalls, provider.calls.Load())
+		})
+	}
+}
+
+func TestNotifierSendTemplate(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	templates := NewRenderer()
+	require.NoError(t, templates.LoadFS(fstest.MapFS{
+		"templates/reset.subject.tmpl": {Data: []byte("Reset your\n password, {{.Name}}")},
+		"templates/reset.txt.tmpl":     {Data: []byte("Open {{.Link}}")},
+		"templates/reset.html.tmpl":    {Data: []byte(`<a href="{{.Link}}">{{.Name}}</a>`)},
+		"templates/README.md":          {Data: []byte("ignored")},
+	}, "templates"))
+	assert.Equal(t, []string{"reset"}, templates.Names())
+
+	provider := &fakeProvider{channel: ChannelEmail}
+	notifier := New(logger).WithTemplates(templates)
+	notifier.Register(provider, nil)
+
+	data := map[string]string{"Name": "<Ann>", "Link": "https://example.com/reset?t=1&u=2"}
+	require.NoError(t, notifier.Send(context.Background(), Message{
+		Channel:  ChannelEmail,
+		To:       []string{"ann@example.com"},
+		Template: "reset",
+		Data:     data,
+	}))
+	assert.Equal(t, "Reset your password, <Ann>", provider.last.Subject)
+	assert.Equal(t, "Open https://example.com/reset?t=1&u=2", provider.last.Text)
+	assert.Equal(t, `<a href="https://example.com/reset?t=1&amp;u=2">&lt;Ann&gt;</a>`, provider.last.HTML)
+
+	err := notifier.Send(context.Background(), Message{Channel: ChannelEmail, To: []string{"a@example.com"}, Template: "welcome"})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	err = notifier.Send(context.Background(), Message{Channel: ChannelEmail, To: []string{"a@example.com"}, Template: "reset", Data: map[string]string{}})
+	assert.ErrorContains(t, err, "failed to render template")
+}
+
+func TestNotifierMetrics(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Observability.MetricsEnabled = true
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+
+	provider := &fakeProvider{channel: ChannelWebhook, fail: []error{errors.New("rejected")}}
+	notifier := New(logger).WithMetrics(metrics)
+	notifier.Register(provider, fastRetries())
+
+	assert.Error(t, notifier.Send(context.Background(), Message{Channel: ChannelWebhook}))
+	assert.NoError(t, notifier.Send(context.Background(), Message{Channel: ChannelWebhook}))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.NotifyMessagesTotal.WithLabelValues(ChannelWebhook, "fake", "failed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.NotifyMessagesTotal.WithLabelValues(ChannelWebhook, "fake", "sent")))
+}
+
+func TestProvideNotifier(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	tests := []struct {
+		name     string
+		notify   config.NotifyConfig
+		channels map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "nothing configured",
+			channels: map[string]string{ChannelSMS: "fake"},
+		},
+		{
+			name: "configured providers",
+			notify: config.NotifyConfig{
+				Email:   config.NotifyEmailConfig{Provider: "sendgrid", From: "no-reply@example.com"},
+				Webhook: config.NotifyWebhookConfig{URL: "http://localhost/hook"},
+				Slack:   config.NotifySlackConfig{WebhookURL: "http://localhost/slack"},
+			},
+			channels: map[string]string{
+				ChannelEmail:   "sendgrid",
+				ChannelWebhook: "webhook",
+				ChannelSlack:   "slack",
+				ChannelSMS:     "fake",
+			},
+		},
+		{
+			name: "ses",
+			notify: config.NotifyConfig{Email: config.NotifyEmailConfig{
+				Provider: "ses",
+				SES:      config.NotifySESConfig{Region: "eu-west-1", AccessKeyID: "id", SecretAccessKey: "secret"},
+			}},
+			channels: map[string]string{ChannelEmail: "ses", ChannelSMS: "fake"},
+		},
+		{
+			name:    "unknown email provider",
+			notify:  config.NotifyConfig{Email: config.NotifyEmailConfig{Provider: "pigeon"}},
+			wantErr: `notify: unknown email provider "pigeon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier, err := ProvideNotifier(NotifierParams{
+				Config:    &config.Config{Notify: tt.notify},
+				Logger:    logger,
+				Providers: []Provider{&fakeProvider{channel: ChannelSMS}},
+				Templates: []TemplateSource{{FS: fstest.MapFS{"t/a.txt.tmpl": {Data: []byte("a")}}, Dir: "t"}},
+			})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			for channel, name := range tt.channels {
+				require.NotNil(t, notifier.Provider(channel), channel)
+				assert.Equal(t, name, notifier.Provider(channel).Name())
+			}
+			assert.Nil(t, notifier.Provider("pager"))
+		})
+	}
+}
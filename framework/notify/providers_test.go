@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a test server recording the last request it received
+type recorder struct {
+	*httptest.Server
+	status int
+	req    *http.Request
+	body   []byte
+}
+
+func newRecorder(t *testing.T, status int) *recorder {
+	r := &recorder{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.req = req
+		r.body, _ = io.ReadAll(req.Body)
+		w.WriteHeader(r.status)
+		_, _ = w.Write([]byte("detail"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestHTTPProviders(t *testing.T) {
+	msg := &Message{
+		To:       []string{"Ann <ann@example.com>"},
+		Subject:  "Hello",
+		Text:     "Hi Ann",
+		HTML:     "<p>Hi Ann</p>",
+		Metadata: map[string]string{"tenant": "acme"},
+	}
+
+	t.Run("webhook", func(t *testing.T) {
+		server := newRecorder(t, http.StatusNoContent)
+		provider := NewWebhookProvider(server.URL, "secret", map[string]string{"X-Source": "axiomod"})
+		require.NoError(t, provider.Send(context.Background(), msg))
+
+		assert.Equal(t, "axiomod", server.req.Header.Get("X-Source"))
+		assert.Equal(t, Signature([]byte("secret"), server.body), server.req.Header.Get("X-Signature-256"))
+		assert.JSONEq(t, `{
+			"to": ["Ann <ann@example.com>"],
+			"subject": "Hello",
+			"text": "Hi Ann",
+			"html": "<p>Hi Ann</p>",
+			"metadata": {"tenant": "acme"}
+		}`, string(server.body))
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		server := newRecorder(t, http.StatusOK)
+		require.NoError(t, NewSlackProvider(server.URL).Send(context.Background(), msg))
+		assert.JSONEq(t, `{"text": "*Hello*\nHi Ann"}`, string(server.body))
+	})
+
+	t.Run("sendgrid", func(t *testing.T) {
+		server := newRecorder(t, http.StatusAccepted)
+		provider := NewSendGridProvider(config.NotifySendGridConfig{APIKey: "key", Endpoint: server.URL}, "Acme <no-reply@acme.io>")
+		require.NoError(t, provider.Send(context.Background(), msg))
+
+		assert.Equal(t, "/v3/mail/send", server.req.URL.Path)
+		assert.Equal(t, "Bearer key", server.req.Header.Get("Authorization"))
+		assert.JSONEq(t, `{
+			"personalizations": [{"to": [{"email": "ann@example.com", "name": "Ann"}]}],
+			"from": {"email": "no-reply@acme.io", "name": "Acme"},
+			"subject": "Hello",
+			"content": [{"type": "text/plain", "value": "Hi Ann"}, {"type": "text/html", "value": "<p>Hi Ann</p>"}]
+		}`, string(server.body))
+	})
+
+	t.Run("ses", func(t *testing.T) {
+		server := newRecorder(t, http.StatusOK)
+		provider, err := NewSESProvider(config.NotifySESConfig{
+			Region:          "eu-west-1",
+			AccessKeyID:     "AKID",
+			SecretAccessKey: "secret",
+			Endpoint:        server.URL,
+		}, "no-reply@acme.io")
+		require.NoError(t, err)
+		require.NoError(t, provider.Send(context.Background(), msg))
+
+		assert.Equal(t, "/v2/email/outbound-emails", server.req.URL.Path)
+		assert.True(t, strings.HasPrefix(server.req.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKID/"+time.Now().UTC().Format("20060102")+"/eu-west-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature="))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(server.body, &body))
+		assert.Equal(t, "<no-reply@acme.io>", body["FromEmailAddress"])
+		assert.Equal(t, map[string]interface{}{"ToAddresses": []interface{}{`"Ann" <ann@example.com>`}}, body["Destination"])
+	})
+}
+
+func TestHTTPProviderFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		temporary bool
+	}{
+		{name: "bad request", status: http.StatusBadRequest},
+		{name: "unauthorized", status: http.StatusUnauthorized},
+		{name: "throttled", status: http.StatusTooManyRequests, temporary: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, temporary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRecorder(t, tt.status)
+			err := NewWebhookProvider(server.URL, "", nil).Send(context.Background(), &Message{Text: "Hi"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "detail")
+			assert.Equal(t, tt.temporary, errors.Is(err, ErrTemporary))
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		err := NewSlackProvider("http://127.0.0.1:1").Send(context.Background(), &Message{Text: "Hi"})
+		assert.True(t, errors.Is(err, ErrTemporary))
+	})
+}
+
+func TestNewSESProviderCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err := NewSESProvider(config.NotifySESConfig{Region: "eu-west-1"}, "")
+	assert.EqualError(t, err, "notify: the ses provider needs an access key ID and a secret access key")
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+	provider, err := NewSESProvider(config.NotifySESConfig{Region: "eu-west-1"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://email.eu-west-1.amazonaws.com", provider.endpoint)
+	assert.Equal(t, "token", provider.credentials.SessionToken)
+}
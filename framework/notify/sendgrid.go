@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/config"
+)
+
+// SendGridProvider sends email with the SendGrid v3 API
+type SendGridProvider struct {
+	apiKey   string
+	endpoint string
+	from     string
+	client   *http.Client
+}
+
+// NewSendGridProvider creates a provider sending with the API key of cfg, from the sender
+// from unless messages have one
+func NewSendGridProvider(cfg config.NotifySendGridConfig, from string) *SendGridProvider {
+	return &SendGridProvider{
+		apiKey:   cfg.APIKey,
+		endpoint: strings.TrimSuffix(valueOr(cfg.Endpoint, "https://api.sendgrid.com"), "/") + "/v3/mail/send",
+		from:     from,
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Name implements Provider
+func (p *SendGridProvider) Name() string {
+	return "sendgrid"
+}
+
+// Channel implements Provider
+func (p *SendGridProvider) Channel() string {
+	return ChannelEmail
+}
+
+// sendGridAddress is an address of the SendGrid API
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridContent is a body of the SendGrid API
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Send posts msg to the mail send endpoint
+func (p *SendGridProvider) Send(ctx context.Context, msg *Message) error {
+	from := valueOr(msg.From, p.from)
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("notify: invalid sender %q: %w", from, err)
+	}
+	recipients, err := parseAddresses(msg.To)
+	if err != nil {
+		return err
+	}
+
+	to := make([]sendGridAddress, len(recipients))
+	for i, recipient := range recipients {
+		to[i] = sendGridAddress{Email: recipient.Address, Name: recipient.Name}
+	}
+	var content []sendGridContent
+	if msg.Text != "" {
+		content = append(content, sendGridContent{Type: "text/plain", Value: msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+
+	header := http.Header{"Authorization": {"Bearer " + p.apiKey}}
+	return postJSON(ctx, p.client, p.endpoint, header, map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": to}},
+		"from":             sendGridAddress{Email: sender.Address, Name: sender.Name},
+		"subject":          msg.Subject,
+		"content":          content,
+	})
+}
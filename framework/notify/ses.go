@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/crypto"
+)
+
+// SESProvider sends email with the Amazon SES v2 API
+type SESProvider struct {
+	region      string
+	credentials crypto.AWSCredentials
+	endpoint    string
+	from        string
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewSESProvider creates a provider sending in the region of cfg, from the sender from
+// unless messages have one. Credentials missing from cfg are read from the AWS environment
+// variables.
+func NewSESProvider(cfg config.NotifySESConfig, from string) (*SESProvider, error) {
+	region := valueOr(cfg.Region, os.Getenv("AWS_REGION"))
+	if region == "" {
+		return nil, fmt.Errorf("notify: the ses provider needs a region")
+	}
+	p := &SESProvider{
+		region: region,
+		credentials: crypto.AWSCredentials{
+			AccessKeyID:     valueOr(cfg.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: valueOr(cfg.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		},
+		endpoint: strings.TrimSuffix(valueOr(cfg.Endpoint, "https://email."+region+".amazonaws.com"), "/"),
+		from:     from,
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+		now:      time.Now,
+	}
+	if cfg.AccessKeyID == "" {
+		p.credentials.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if p.credentials.AccessKeyID == "" || p.credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("notify: the ses provider needs an access key ID and a secret access key")
+	}
+	return p, nil
+}
+
+// Name implements Provider
+func (p *SESProvider) Name() string {
+	return "ses"
+}
+
+// Channel implements Provider
+func (p *SESProvider) Channel() string {
+	return ChannelEmail
+}
+
+// sesContent is a text of the SES API
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+// Send posts msg to the SendEmail action
+func (p *SESProvider) Send(ctx context.Context, msg *Message) error {
+	from := valueOr(msg.From, p.from)
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("notify: invalid sender %q: %w", from, err)
+	}
+	recipients, err := parseAddresses(msg.To)
+	if err != nil {
+		return err
+	}
+
+	to := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		to[i] = recipient.String()
+	}
+	body := map[string]*sesContent{}
+	if msg.Text != "" {
+		body["Text"] = &sesContent{Data: msg.Text, Charset: "UTF-8"}
+	}
+	if msg.HTML != "" {
+		body["Html"] = &sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": sender.String(),
+		"Destination":      map[string][]string{"ToAddresses": to},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": sesContent{Data: msg.Subject, Charset: "UTF-8"},
+				"Body":    body,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/v2/email/outbound-emails", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	crypto.SignAWSV4(req, crypto.HashSHA256Hex(data), p.credentials, p.region, "ses", p.now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Temporary(err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+)
+
+// SMTPProvider sends email through an SMTP server
+type SMTPProvider struct {
+	host        string
+	addr        string
+	username    string
+	password    string
+	implicitTLS bool
+	from        string
+	tlsConfig   *tls.Config
+}
+
+// NewSMTPProvider creates a provider sending through the server of cfg, from the sender
+// from unless messages have one
+func NewSMTPProvider(cfg config.NotifySMTPConfig, from string) *SMTPProvider {
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	return &SMTPProvider{
+		host:        cfg.Host,
+		addr:        net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		username:    cfg.Username,
+		password:    cfg.Password,
+		implicitTLS: cfg.TLS,
+		from:        from,
+		tlsConfig:   &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12},
+	}
+}
+
+// Name implements Provider
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+// Channel implements Provider
+func (p *SMTPProvider) Channel() string {
+	return ChannelEmail
+}
+
+// Send delivers msg to the server. Connection failures and 4xx replies are temporary.
+func (p *SMTPProvider) Send(ctx context.Context, msg *Message) error {
+	from := valueOr(msg.From, p.from)
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("notify: invalid sender %q: %w", from, err)
+	}
+	recipients, err := parseAddresses(msg.To)
+	if err != nil {
+		return err
+	}
+	data, err := buildEmail(sender, recipients, msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := p.dial(ctx)
+	if err != nil {
+		return Temporary(err)
+	}
+	defer client.Close()
+
+	if err := p.deliver(client, sender.Address, recipients, data); err != nil {
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return err
+		}
+		return Temporary(err)
+	}
+	return nil
+}
+
+// dial connects to the server, switching to TLS when the server offers STARTTLS
+func (p *SMTPProvider) dial(ctx context.Context) (*smtp.Client, error) {
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if p.implicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: p.tlsConfig}).DialContext(ctx, "tcp", p.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", p.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("notify: failed to connect to %s: %w", p.addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !p.implicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(p.tlsConfig); err != nil {
+				client.Close()
+				return nil, err
+			}
+		}
+	}
+	return client, nil
+}
+
+// deliver authenticates, if credentials are set, and sends data to recipients
+func (p *SMTPProvider) deliver(client *smtp.Client, sender string, recipients []*mail.Address, data []byte) error {
+	if p.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.username, p.password, p.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// parseAddresses parses the recipients of a message
+func parseAddresses(to []string) ([]*mail.Address, error) {
+	addresses := make([]*mail.Address, len(to))
+	for i, raw := range to {
+		address, err := mail.ParseAddress(raw)
+		if err != nil {
+			return nil, fmt.Errorf("notify: invalid recipient %q: %w", raw, err)
+		}
+		addresses[i] = address
+	}
+	return addresses, nil
+}
+
+// buildEmail formats msg as a MIME message, with text and HTML alternatives when it has both
+func buildEmail(sender *mail.Address, recipients []*mail.Address, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	to := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		to[i] = recipient.String()
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("From", sender.String())
+	header.Set("To", strings.Join(to, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(sender.Address))
+	header.Set("MIME-Version", "1.0")
+
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		parts := multipart.NewWriter(&buf)
+		header.Set("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+		writeHeader(&buf, header)
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, part.body); err != nil {
+				return nil, err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		contentType, body := "text/plain; charset=utf-8", msg.Text
+		if msg.HTML != "" {
+			contentType, body = "text/html; charset=utf-8", msg.HTML
+		}
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeader writes the header of a message and the blank line ending it
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeQuotedPrintable writes body encoded as quoted-printable
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(sender string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(sender, "@"); i >= 0 {
+		domain = sender[i+1:]
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
+
+// valueOr returns value, or fallback if value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
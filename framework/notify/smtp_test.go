@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer is a fake SMTP server accepting one session at a time, replying rcptReply to
+// RCPT commands and recording the commands and data it receives
+type smtpServer struct {
+	addr      string
+	rcptReply string
+	commands  chan []string
+	data      chan string
+}
+
+func newSMTPServer(t *testing.T, rcptReply string) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &smtpServer{
+		addr:      listener.Addr().String(),
+		rcptReply: rcptReply,
+		commands:  make(chan []string, 1),
+		data:      make(chan string, 1),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	var commands []string
+	defer func() { s.commands <- commands }()
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		commands = append(commands, command)
+		switch verb := strings.ToUpper(strings.Fields(command)[0]); verb {
+		case "EHLO", "HELO", "MAIL":
+			reply("250 OK")
+		case "RCPT":
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data <- data.String()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func TestSMTPProvider(t *testing.T) {
+	tests := []struct {
+		name          string
+		rcptReply     string
+		wantTemporary bool
+		wantErr       bool
+	}{
+		{name: "delivers", rcptReply: "250 OK"},
+		{name: "mailbox busy", rcptReply: "450 Mailbox busy", wantErr: true, wantTemporary: true},
+		{name: "no such user", rcptReply: "550 No such user", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, tt.rcptReply)
+			host, port, _ := net.SplitHostPort(server.addr)
+			portNumber, _ := strconv.Atoi(port)
+			provider := NewSMTPProvider(config.NotifySMTPConfig{Host: host, Port: portNumber}, "Acme <no-reply@acme.io>")
+
+			err := provider.Send(context.Background(), &Message{
+				To:      []string{"ann@example.com", "Bob <bob@example.com>"},
+				Subject: "Hello",
+				Text:    "Hi",
+			})
+			if !tt.wantErr {
+				require.NoError(t, err)
+				commands := <-server.commands
+				assert.Contains(t, commands, "MAIL FROM:<no-reply@acme.io>")
+				assert.Contains(t, commands, "RCPT TO:<ann@example.com>")
+				assert.Contains(t, commands, "RCPT TO:<bob@example.com>")
+				data := <-server.data
+				assert.Contains(t, data, "Subject: Hello\r\n")
+				assert.Contains(t, data, "To: <ann@example.com>, \"Bob\" <bob@example.com>\r\n")
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantTemporary, errors.Is(err, ErrTemporary))
+		})
+	}
+
+	t.Run("unreachable server", func(t *testing.T) {
+		provider := NewSMTPProvider(config.NotifySMTPConfig{Host: "127.0.0.1", Port: 1}, "no-reply@acme.io")
+		err := provider.Send(context.Background(), &Message{To: []string{"ann@example.com"}})
+		assert.ErrorIs(t, err, ErrTemporary)
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		provider := NewSMTPProvider(config.NotifySMTPConfig{Host: "127.0.0.1"}, "no-reply@acme.io")
+		err := provider.Send(context.Background(), &Message{To: []string{"not an address"}})
+		assert.ErrorContains(t, err, `notify: invalid recipient "not an address"`)
+	})
+}
+
+func TestBuildEmail(t *testing.T) {
+	sender := &mail.Address{Name: "Acme", Address: "no-reply@acme.io"}
+	recipients := []*mail.Address{{Address: "ann@example.com"}}
+
+	tests := []struct {
+		name     string
+		msg      *Message
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "text",
+			msg:  &Message{Subject: "Hello", Text: "Hi"},
+			contains: []string{
+				"From: \"Acme\" <no-reply@acme.io>\r\n",
+				"Content-Type: text/plain; charset=utf-8\r\n",
+				"Message-ID: <",
+				"@acme.io>\r\n",
+				"\r\n\r\nHi",
+			},
+			excludes: []string{"multipart"},
+		},
+		{
+			name:     "html",
+			msg:      &Message{Subject: "Grüße", HTML: "<p>Hi</p>"},
+			contains: []string{"Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n", "Content-Type: text/html; charset=utf-8\r\n"},
+		},
+		{
+			name: "text and html",
+			msg:  &Message{Subject: "Hello", Text: "Hi", HTML: "<p>a=b</p>"},
+			contains: []string{
+				"Content-Type: multipart/alternative; boundary=",
+				"Content-Type: text/plain; charset=utf-8\r\n",
+				"Content-Type: text/html; charset=utf-8\r\n",
+				"<p>a=3Db</p>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := buildEmail(sender, recipients, tt.msg)
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, string(data), want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, string(data), unwanted)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// ErrTemplateNotFound is returned when rendering a message from a template that is not loaded
+var ErrTemplateNotFound = errors.New("notify: template not found")
+
+// Parts of a message rendered from the template files of the same name
+const (
+	subjectSuffix = ".subject.tmpl"
+	textSuffix    = ".txt.tmpl"
+	htmlSuffix    = ".html.tmpl"
+)
+
+// template holds the parts of a message template; any of them may be nil
+type template struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// executor is a parsed text or HTML template
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// Renderer renders messages from templates. A template is a set of files named after it:
+// <name>.subject.tmpl for the subject, <name>.txt.tmpl for the text body and
+// <name>.html.tmpl for the HTML body, which is escaped with html/template.
+type Renderer struct {
+	mu        sync.RWMutex
+	templates map[string]*template
+}
+
+// NewRenderer creates a renderer without templates
+func NewRenderer() *Renderer {
+	return &Renderer{templates: make(map[string]*template)}
+}
+
+// LoadFS loads the template files of dir in fsys. Files replace the parts of the templates
+// loaded before, so that applications can override the templates of modules.
+func (r *Renderer) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("notify: failed to read templates: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := entry.Name()
+		data, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return fmt.Errorf("notify: failed to read template %s: %w", file, err)
+		}
+
+		var name string
+		var parse func(t *template) error
+		switch {
+		case strings.HasSuffix(file, subjectSuffix):
+			name = strings.TrimSuffix(file, subjectSuffix)
+			parse = func(t *template) (err error) {
+				t.subject, err = texttemplate.New(file).Option("missingkey=error").Parse(string(data))
+				return err
+			}
+		case strings.HasSuffix(file, textSuffix):
+			name = strings.TrimSuffix(file, textSuffix)
+			parse = func(t *template) (err error) {
+				t.text, err = texttemplate.New(file).Option("missingkey=error").Parse(string(data))
+				return err
+			}
+		case strings.HasSuffix(file, htmlSuffix):
+			name = strings.TrimSuffix(file, htmlSuffix)
+			parse = func(t *template) (err error) {
+				t.html, err = htmltemplate.New(file).Option("missingkey=error").Parse(string(data))
+				return err
+			}
+		default:
+			continue
+		}
+
+		t, ok := r.templates[name]
+		if !ok {
+			t = &template{}
+			r.templates[name] = t
+		}
+		if err := parse(t); err != nil {
+			return fmt.Errorf("notify: failed to parse template %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the loaded templates, sorted
+func (r *Renderer) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render sets the subject and bodies of msg from the parts of its template, executed with
+// msg.Data. Parts the template does not have are left as they are.
+func (r *Renderer) Render(msg *Message) error {
+	r.mu.RLock()
+	t, ok := r.templates[msg.Template]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrTemplateNotFound, msg.Template)
+	}
+
+	var buf bytes.Buffer
+	execute := func(tmpl executor, target *string) error {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, msg.Data); err != nil {
+			return fmt.Errorf("notify: failed to render template %q: %w", msg.Template, err)
+		}
+		*target = buf.String()
+		return nil
+	}
+
+	if t.subject != nil {
+		if err := execute(t.subject, &msg.Subject); err != nil {
+			return err
+		}
+		// Subjects are a single line
+		msg.Subject = strings.Join(strings.Fields(msg.Subject), " ")
+	}
+	if t.text != nil {
+		if err := execute(t.text, &msg.Text); err != nil {
+			return err
+		}
+	}
+	if t.html != nil {
+		if err := execute(t.html, &msg.HTML); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+const (
+	// DefaultPageSize is the page size of requests that set none
+	DefaultPageSize = 50
+	// MaxPageSize caps the page size of requests
+	MaxPageSize = 1000
+)
+
+// ErrInvalidPageToken is returned for page tokens that are malformed, tampered with, expired
+// or issued for a different query
+var ErrInvalidPageToken = stderrors.New("invalid page token")
+
+// Request is the page requested from a List endpoint. Over HTTP it is read from the
+// page_size and page_token query parameters, over gRPC from the fields of the same names.
+type Request struct {
+	PageSize  int    `query:"page_size" json:"page_size"`
+	PageToken string `query:"page_token" json:"page_token"`
+}
+
+// Page is the response envelope of List endpoints over HTTP. NextPageToken is empty on the
+// last page.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// NewPage creates a page, never encoding items as null
+func NewPage[T any](items []T, nextPageToken string) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, NextPageToken: nextPageToken}
+}
+
+// Cursor is the position a page token resumes a listing from. Repositories ordering by a
+// unique key use After, others Offset.
+type Cursor struct {
+	After  string `json:"a,omitempty"` // sort key of the last item returned
+	Offset int    `json:"o,omitempty"` // items returned so far
+
+	Query   string `json:"q,omitempty"` // fingerprint of the filters the token was issued for
+	Expires int64  `json:"e,omitempty"` // unix time; 0 never expires
+}
+
+// Paginator resolves page requests and issues signed page tokens, so clients cannot forge
+// positions or reuse a token with different filters
+type Paginator struct {
+	key             []byte
+	defaultPageSize int
+	maxPageSize     int
+	ttl             time.Duration
+	now             func() time.Time
+}
+
+// NewPaginator creates a paginator from the pagination configuration. Without a secret, a
+// random key is used, so page tokens are only valid on the instance that issued them.
+func NewPaginator(cfg config.PaginationConfig) (*Paginator, error) {
+	key := []byte(cfg.Secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, errors.Wrap(err, "failed to generate page token key")
+		}
+	}
+
+	p := &Paginator{
+		key:             key,
+		defaultPageSize: cfg.DefaultPageSize,
+		maxPageSize:     cfg.MaxPageSize,
+		ttl:             time.Duration(cfg.TokenTTL) * time.Second,
+		now:             time.Now,
+	}
+	if p.maxPageSize <= 0 {
+		p.maxPageSize = MaxPageSize
+	}
+	if p.defaultPageSize <= 0 {
+		p.defaultPageSize = DefaultPageSize
+	}
+	if p.defaultPageSize > p.maxPageSize {
+		p.defaultPageSize = p.maxPageSize
+	}
+	return p, nil
+}
+
+// PageSize returns the page size to use for a requested one: the default when none is
+// requested, capped at the maximum
+func (p *Paginator) PageSize(requested int) int {
+	switch {
+	case requested <= 0:
+		return p.defaultPageSize
+	case requested > p.maxPageSize:
+		return p.maxPageSize
+	default:
+		return requested
+	}
+}
+
+// Start resolves a page request for a query, returning the cursor to resume from and the
+// page size. The first page has a zero cursor. query is the fingerprint of the request's
+// filters, see Fingerprint; a token issued for another query is rejected. Errors are
+// framework errors with an INVALID_INPUT code.
+func (p *Paginator) Start(req Request, query string) (Cursor, int, error) {
+	if req.PageSize < 0 {
+		return Cursor{}, 0, errors.NewInvalidInput(errors.ErrInvalidInput, "page_size must not be negative")
+	}
+	size := p.PageSize(req.PageSize)
+	if req.PageToken == "" {
+		return Cursor{}, size, nil
+	}
+
+	cursor, err := p.Decode(req.PageToken)
+	if err != nil {
+		return Cursor{}, 0, errors.NewInvalidInput(err, "invalid page_token")
+	}
+	if cursor.Query != query {
+		return Cursor{}, 0, errors.NewInvalidInput(ErrInvalidPageToken, "page_token was issued for a different query")
+	}
+	return cursor, size, nil
+}
+
+// Next returns the page token of the page after cursor, for the same query as Start
+func (p *Paginator) Next(cursor Cursor, query string) (string, error) {
+	cursor.Query = query
+	cursor.Expires = 0
+	if p.ttl > 0 {
+		cursor.Expires = p.now().Add(p.ttl).Unix()
+	}
+	return p.Encode(cursor)
+}
+
+// Encode signs a cursor into an opaque, URL-safe page token
+func (p *Paginator) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode page token")
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(p.sign(payload)), nil
+}
+
+// Decode verifies a page token and returns its cursor. Tokens that do not verify or have
+// expired return ErrInvalidPageToken.
+func (p *Paginator) Decode(token string) (Cursor, error) {
+	var cursor Cursor
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursor, ErrInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidPageToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, p.sign(payload)) {
+		return cursor, ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return cursor, ErrInvalidPageToken
+	}
+	if cursor.Expires != 0 && p.now().Unix() > cursor.Expires {
+		return Cursor{}, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
+
+// sign returns the HMAC-SHA256 of payload
+func (p *Paginator) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, p.key)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// Fingerprint identifies the filters and sort order of a listing, so page tokens are only
+// accepted for the query they were issued for
+func Fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
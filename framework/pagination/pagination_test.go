@@ -0,0 +1,151 @@
+package pagination
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPaginator(t *testing.T, cfg config.PaginationConfig) *Paginator {
+	t.Helper()
+	p, err := NewPaginator(cfg)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.PaginationConfig
+		requested int
+		want      int
+	}{
+		{name: "default", requested: 0, want: DefaultPageSize},
+		{name: "requested", requested: 20, want: 20},
+		{name: "capped", requested: 5000, want: MaxPageSize},
+		{name: "configured default", cfg: config.PaginationConfig{DefaultPageSize: 10}, want: 10},
+		{name: "configured maximum", cfg: config.PaginationConfig{MaxPageSize: 25}, requested: 100, want: 25},
+		{name: "default above maximum", cfg: config.PaginationConfig{DefaultPageSize: 100, MaxPageSize: 25}, want: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newTestPaginator(t, tt.cfg).PageSize(tt.requested))
+		})
+	}
+}
+
+func TestPageTokens(t *testing.T) {
+	p := newTestPaginator(t, config.PaginationConfig{Secret: "secret"})
+	query := Fingerprint("status=open")
+
+	// First page
+	cursor, size, err := p.Start(Request{PageSize: 2}, query)
+	require.NoError(t, err)
+	assert.Equal(t, Cursor{}, cursor)
+	assert.Equal(t, 2, size)
+
+	token, err := p.Next(Cursor{After: "order-2"}, query)
+	require.NoError(t, err)
+	cursor, _, err = p.Start(Request{PageToken: token}, query)
+	require.NoError(t, err)
+	assert.Equal(t, "order-2", cursor.After)
+
+	// Tokens are portable between instances sharing the secret
+	_, _, err = newTestPaginator(t, config.PaginationConfig{Secret: "secret"}).Start(Request{PageToken: token}, query)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		paginator *Paginator
+		req       Request
+		query     string
+	}{
+		{name: "other secret", paginator: newTestPaginator(t, config.PaginationConfig{Secret: "other"}), req: Request{PageToken: token}, query: query},
+		{name: "other query", paginator: p, req: Request{PageToken: token}, query: Fingerprint("status=closed")},
+		{name: "tampered", paginator: p, req: Request{PageToken: "x" + token}, query: query},
+		{name: "malformed", paginator: p, req: Request{PageToken: "not-a-token"}, query: query},
+		{name: "negative page size", paginator: p, req: Request{PageSize: -1}, query: query},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.paginator.Start(tt.req, tt.query)
+			assert.Error(t, err)
+			assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+			assert.Equal(t, 400, errors.ToHTTPCode(err))
+		})
+	}
+}
+
+func TestPageTokenExpiry(t *testing.T) {
+	p := newTestPaginator(t, config.PaginationConfig{Secret: "secret", TokenTTL: 60})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	token, err := p.Next(Cursor{Offset: 10}, "")
+	require.NoError(t, err)
+	cursor, err := p.Decode(token)
+	require.NoError(t, err)
+	assert.Equal(t, 10, cursor.Offset)
+
+	now = now.Add(2 * time.Minute)
+	_, err = p.Decode(token)
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint("a", "b"), Fingerprint("a", "b"))
+	assert.NotEqual(t, Fingerprint("a", "b"), Fingerprint("ab", ""))
+	assert.NotEqual(t, Fingerprint("a", "b"), Fingerprint("b", "a"))
+}
+
+func TestFromFiber(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		req, err := FromFiber(c)
+		if err != nil {
+			return c.Status(errors.ToHTTPCode(err)).SendString(err.Error())
+		}
+		return c.JSON(NewPage([]Request{req}, ""))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/?page_size=20&page_token=abc", nil))
+	require.NoError(t, err)
+	var page Page[Request]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	assert.Equal(t, []Request{{PageSize: 20, PageToken: "abc"}}, page.Items)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/?page_size=many", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestPageEnvelope(t *testing.T) {
+	data, err := json.Marshal(NewPage[string](nil, ""))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":[]}`, string(data))
+
+	data, err = json.Marshal(NewPage([]string{"a"}, "next"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":["a"],"next_page_token":"next"}`, string(data))
+}
+
+// protoRequest mirrors a generated message with the standard pagination fields
+type protoRequest struct {
+	pageSize  int32
+	pageToken string
+}
+
+func (r *protoRequest) GetPageSize() int32   { return r.pageSize }
+func (r *protoRequest) GetPageToken() string { return r.pageToken }
+
+func TestFromProto(t *testing.T) {
+	assert.Equal(t, Request{PageSize: 5, PageToken: "abc"}, FromProto(&protoRequest{pageSize: 5, pageToken: "abc"}))
+}
@@ -0,0 +1,51 @@
+package pagination
+
+import (
+	"strconv"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+// Module provides the paginator shared by HTTP and gRPC List endpoints
+var Module = fx.Options(
+	fx.Provide(ProvidePaginator),
+)
+
+// ProvidePaginator provides a Paginator, warning when page tokens are signed with a
+// per-process key
+func ProvidePaginator(cfg *config.Config, logger *observability.Logger) (*Paginator, error) {
+	if cfg.Pagination.Secret == "" {
+		logger.Warn("pagination.secret is not set; page tokens are only valid on the instance that issued them")
+	}
+	return NewPaginator(cfg.Pagination)
+}
+
+// FromFiber reads the page request from the page_size and page_token query parameters
+func FromFiber(c *fiber.Ctx) (Request, error) {
+	req := Request{PageToken: c.Query("page_token")}
+	if size := c.Query("page_size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return Request{}, errors.NewInvalidInput(err, "page_size must be an integer")
+		}
+		req.PageSize = n
+	}
+	return req, nil
+}
+
+// ProtoRequest is implemented by generated gRPC request messages with the standard
+// page_size and page_token fields
+type ProtoRequest interface {
+	GetPageSize() int32
+	GetPageToken() string
+}
+
+// FromProto reads the page request from a gRPC request message
+func FromProto(req ProtoRequest) Request {
+	return Request{PageSize: int(req.GetPageSize()), PageToken: req.GetPageToken()}
+}
@@ -0,0 +1,420 @@
+// Package profiling profiles the application continuously. With Pyroscope, profiles of the
+// process are collected every upload interval and pushed to its ingest API, labelled with
+// the service, version and environment. Parca scrapes the pprof endpoints of the admin
+// server instead.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Continuous profiling providers
+const (
+	ProviderPyroscope = "pyroscope"
+	ProviderParca     = "parca"
+)
+
+// Profile types
+const (
+	ProfileCPU       = "cpu"
+	ProfileHeap      = "heap"
+	ProfileGoroutine = "goroutine"
+	ProfileMutex     = "mutex"
+	ProfileBlock     = "block"
+)
+
+// defaultProfileTypes are collected when none are configured
+var defaultProfileTypes = []string{ProfileCPU, ProfileHeap, ProfileGoroutine}
+
+// sampleTypeConfigs describe the sample types of the profiles to Pyroscope. Cumulative
+// profiles are sent with the previous one, so that the difference between them is stored.
+var sampleTypeConfigs = map[string]string{
+	ProfileCPU:       "",
+	ProfileHeap:      `{"alloc_objects":{"units":"objects","cumulative":true},"alloc_space":{"units":"bytes","cumulative":true},"inuse_objects":{"units":"objects","aggregation":"average"},"inuse_space":{"units":"bytes","aggregation":"average"}}`,
+	ProfileGoroutine: `{"goroutine":{"display-name":"goroutines","units":"goroutines","aggregation":"average"}}`,
+	ProfileMutex:     `{"contentions":{"display-name":"mutex_count","units":"lock_samples","cumulative":true},"delay":{"display-name":"mutex_duration","units":"lock_nanoseconds","cumulative":true}}`,
+	ProfileBlock:     `{"contentions":{"display-name":"block_count","units":"lock_samples","cumulative":true},"delay":{"display-name":"block_duration","units":"lock_nanoseconds","cumulative":true}}`,
+}
+
+// cumulative profile types count from the start of the process
+var cumulative = map[string]bool{ProfileHeap: true, ProfileMutex: true, ProfileBlock: true}
+
+// Module provides the profiler and, when continuous profiling is enabled, runs it with the
+// application
+var Module = fx.Options(
+	fx.Provide(NewProfiler),
+	fx.Invoke(Register),
+)
+
+// ServesPprof reports whether the admin server serves the pprof endpoints: when they are
+// enabled, or scraped by Parca
+func ServesPprof(cfg *config.Config) bool {
+	profiling := cfg.Observability.Profiling
+	return profiling.Pprof || (profiling.Enabled && strings.EqualFold(profiling.Provider, ProviderParca))
+}
+
+// Labels returns the labels of the profiles: the service, version and environment of the
+// application, and the configured labels
+func Labels(cfg *config.Config) map[string]string {
+	labels := map[string]string{
+		"service":     cfg.App.Name,
+		"version":     cfg.App.Version,
+		"environment": cfg.App.Environment,
+	}
+	for key, value := range cfg.Observability.Profiling.Labels {
+		labels[key] = value
+	}
+	for key, value := range labels {
+		if value == "" {
+			delete(labels, key)
+		}
+	}
+	return labels
+}
+
+// Profiler collects profiles of the process and pushes them to Pyroscope. A nil profiler
+// does nothing.
+type Profiler struct {
+	endpoint   string
+	name       string // application name with its labels, e.g. orders{environment=prod,service=orders}
+	types      []string
+	interval   time.Duration
+	tenantID   string
+	username   string
+	password   string
+	token      string
+	httpClient *http.Client
+	logger     *observability.Logger
+
+	previous map[string][]byte // last cumulative profile of each type
+	done     chan struct{}
+	stopped  chan struct{}
+	start    sync.Once
+	stop     sync.Once
+
+	uploaded atomic.Int64
+	failed   atomic.Int64
+}
+
+// NewProfiler creates a profiler from the configuration, or returns nil when continuous
+// profiling is disabled or left to Parca
+func NewProfiler(cfg *config.Config, logger *observability.Logger) (*Profiler, error) {
+	pc := cfg.Observability.Profiling
+	if !pc.Enabled {
+		return nil, nil
+	}
+
+	switch provider := strings.ToLower(pc.Provider); provider {
+	case ProviderParca:
+		if !cfg.HTTP.AdminServer.Enabled {
+			return nil, fmt.Errorf("profiling: parca scrapes the pprof endpoints of the admin server; enable http.adminServer")
+		}
+		return nil, nil
+	case "", ProviderPyroscope:
+	default:
+		return nil, fmt.Errorf("profiling: unknown provider %q", pc.Provider)
+	}
+
+	endpoint, err := url.Parse(pc.ServerAddress)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("profiling: invalid pyroscope server address %q", pc.ServerAddress)
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/ingest"
+
+	types := pc.ProfileTypes
+	if len(types) == 0 {
+		types = defaultProfileTypes
+	}
+	for _, profileType := range types {
+		if _, ok := sampleTypeConfigs[profileType]; !ok {
+			return nil, fmt.Errorf("profiling: unknown profile type %q", profileType)
+		}
+	}
+
+	name := pc.ApplicationName
+	if name == "" {
+		name = cfg.App.Name
+	}
+	if name == "" {
+		return nil, fmt.Errorf("profiling: no application name; set app.name or observability.profiling.applicationName")
+	}
+	interval := time.Duration(pc.UploadInterval) * time.Second
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	timeout := time.Duration(pc.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Profiler{
+		endpoint:   endpoint.String(),
+		name:       applicationName(name, Labels(cfg)),
+		types:      types,
+		interval:   interval,
+		tenantID:   pc.TenantID,
+		username:   pc.Username,
+		password:   pc.Password,
+		token:      pc.AuthToken,
+		httpClient: &http.Client{Timeout: timeout},
+		logger:     logger,
+		previous:   make(map[string][]byte),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}, nil
+}
+
+// applicationName returns the name of the application in Pyroscope with its labels
+func applicationName(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = labelValue(key) + "=" + labelValue(labels[key])
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelValue drops the characters delimiting the labels of an application name
+func labelValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("{}=, ", r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Register starts the profiler with the application
+func Register(lc fx.Lifecycle, profiler *Profiler) {
+	if profiler == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			profiler.Start()
+			if profiler.logger != nil {
+				profiler.logger.Info("Profiling continuously",
+					zap.String("endpoint", profiler.endpoint),
+					zap.String("application", profiler.name),
+					zap.Strings("profiles", profiler.types),
+				)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return profiler.Close(ctx)
+		},
+	})
+}
+
+// Start collects and uploads profiles in the background until Close
+func (p *Profiler) Start() {
+	if p == nil {
+		return
+	}
+	p.start.Do(func() {
+		if p.collects(ProfileMutex) {
+			runtime.SetMutexProfileFraction(5)
+		}
+		if p.collects(ProfileBlock) {
+			runtime.SetBlockProfileRate(int(time.Millisecond))
+		}
+		go p.run()
+	})
+}
+
+// collects reports whether the profiler collects a profile type
+func (p *Profiler) collects(profileType string) bool {
+	for _, t := range p.types {
+		if t == profileType {
+			return true
+		}
+	}
+	return false
+}
+
+// run profiles the process one interval at a time until Close, then uploads the profiles
+// of the last interval
+func (p *Profiler) run() {
+	defer close(p.stopped)
+	for {
+		from := time.Now()
+		var cpu bytes.Buffer
+		profilingCPU := p.collects(ProfileCPU) && p.startCPU(&cpu)
+
+		stopping := false
+		select {
+		case <-p.done:
+			stopping = true
+		case <-time.After(p.interval):
+		}
+		if profilingCPU {
+			pprof.StopCPUProfile()
+		}
+		p.upload(from, time.Now(), cpu.Bytes())
+		if stopping {
+			return
+		}
+	}
+}
+
+// startCPU starts profiling the CPU, unless another CPU profile is being taken, e.g. from
+// the pprof endpoints
+func (p *Profiler) startCPU(w io.Writer) bool {
+	if err := pprof.StartCPUProfile(w); err != nil {
+		if p.logger != nil {
+			p.logger.Debug("Skipping the CPU profile of this interval", zap.Error(err))
+		}
+		return false
+	}
+	return true
+}
+
+// upload collects the profiles of an interval and pushes them
+func (p *Profiler) upload(from, until time.Time, cpu []byte) {
+	for _, profileType := range p.types {
+		profile := cpu
+		if profileType != ProfileCPU {
+			var buf bytes.Buffer
+			if err := pprof.Lookup(profileType).WriteTo(&buf, 0); err != nil {
+				p.failure(profileType, err)
+				continue
+			}
+			profile = buf.Bytes()
+		}
+		if len(profile) == 0 {
+			continue
+		}
+		if err := p.send(profileType, from, until, profile); err != nil {
+			p.failure(profileType, err)
+			continue
+		}
+		p.uploaded.Add(1)
+		if cumulative[profileType] {
+			p.previous[profileType] = profile
+		}
+	}
+}
+
+// send posts a profile to the ingest API of Pyroscope
+func (p *Profiler) send(profileType string, from, until time.Time, profile []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	parts := []struct {
+		field, file string
+		data        []byte
+	}{
+		{"profile", "profile.pprof", profile},
+		{"prev_profile", "profile.pprof", p.previous[profileType]},
+		{"sample_type_config", "sample_type_config.json", []byte(sampleTypeConfigs[profileType])},
+	}
+	for _, part := range parts {
+		if len(part.data) == 0 {
+			continue
+		}
+		w, err := form.CreateFormFile(part.field, part.file)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(part.data); err != nil {
+			return err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("name", p.name)
+	query.Set("from", strconv.FormatInt(from.Unix(), 10))
+	query.Set("until", strconv.FormatInt(until.Unix(), 10))
+	query.Set("spyName", "gospy")
+	query.Set("sampleRate", "100")
+	req, err := http.NewRequest(http.MethodPost, p.endpoint+"?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if p.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", p.tenantID)
+	}
+	switch {
+	case p.token != "":
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("profiling: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// failure counts and logs a profile that could not be uploaded
+func (p *Profiler) failure(profileType string, err error) {
+	p.failed.Add(1)
+	if p.logger != nil {
+		p.logger.Warn("Failed to upload profile", zap.String("profile", profileType), zap.Error(err))
+	}
+}
+
+// Close uploads the profiles of the current interval and stops. It returns when ctx is
+// done before they are uploaded.
+func (p *Profiler) Close(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.Start()
+	p.stop.Do(func() { close(p.done) })
+	select {
+	case <-p.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if p.collects(ProfileMutex) {
+		runtime.SetMutexProfileFraction(0)
+	}
+	if p.collects(ProfileBlock) {
+		runtime.SetBlockProfileRate(0)
+	}
+	return nil
+}
+
+// Stats returns how many profiles were uploaded and failed to upload
+func (p *Profiler) Stats() (uploaded, failed int64) {
+	if p == nil {
+		return 0, 0
+	}
+	return p.uploaded.Load(), p.failed.Load()
+}
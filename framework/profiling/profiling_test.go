@@ -0,0 +1,227 @@
+package profiling
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+// upload is a profile received by the ingest endpoint
+type upload struct {
+	name   string
+	from   string
+	until  string
+	tenant string
+	auth   string
+	parts  map[string][]byte
+}
+
+// ingester is a Pyroscope ingest endpoint recording the profiles it receives
+type ingester struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	uploads []upload
+}
+
+func newIngester(t *testing.T) *ingester {
+	i := &ingester{}
+	i.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pyroscope/ingest" {
+			http.NotFound(w, r)
+			return
+		}
+		form, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		u := upload{
+			name:   r.URL.Query().Get("name"),
+			from:   r.URL.Query().Get("from"),
+			until:  r.URL.Query().Get("until"),
+			tenant: r.Header.Get("X-Scope-OrgID"),
+			auth:   r.Header.Get("Authorization"),
+			parts:  make(map[string][]byte),
+		}
+		for {
+			part, err := form.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			u.parts[part.FormName()], _ = io.ReadAll(part)
+		}
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		i.uploads = append(i.uploads, u)
+	}))
+	t.Cleanup(i.Close)
+	return i
+}
+
+func (i *ingester) received() []upload {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]upload(nil), i.uploads...)
+}
+
+func testConfig(pc config.ProfilingConfig) *config.Config {
+	return &config.Config{
+		App:           config.AppConfig{Name: "orders", Version: "1.4.2", Environment: "staging"},
+		Observability: config.ObservabilityConfig{Profiling: pc},
+	}
+}
+
+func TestNewProfiler(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		nilProf bool
+		wantErr string
+	}{
+		{"disabled", testConfig(config.ProfilingConfig{}), true, ""},
+		{"pyroscope", testConfig(config.ProfilingConfig{Enabled: true, ServerAddress: "http://pyroscope:4040"}), false, ""},
+		{"missing server address", testConfig(config.ProfilingConfig{Enabled: true}), true, "invalid pyroscope server address"},
+		{"unknown provider", testConfig(config.ProfilingConfig{Enabled: true, Provider: "datadog"}), true, "unknown provider"},
+		{"unknown profile type", testConfig(config.ProfilingConfig{Enabled: true, ServerAddress: "http://pyroscope:4040", ProfileTypes: []string{"threads"}}), true, "unknown profile type"},
+		{"parca without admin server", testConfig(config.ProfilingConfig{Enabled: true, Provider: "parca"}), true, "enable http.adminServer"},
+		{"parca", func() *config.Config {
+			cfg := testConfig(config.ProfilingConfig{Enabled: true, Provider: "Parca"})
+			cfg.HTTP.AdminServer.Enabled = true
+			return cfg
+		}(), true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiler, err := NewProfiler(tt.cfg, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.nilProf, profiler == nil)
+		})
+	}
+}
+
+func TestServesPprof(t *testing.T) {
+	assert.False(t, ServesPprof(testConfig(config.ProfilingConfig{})))
+	assert.True(t, ServesPprof(testConfig(config.ProfilingConfig{Pprof: true})))
+	assert.True(t, ServesPprof(testConfig(config.ProfilingConfig{Enabled: true, Provider: "parca"})))
+	assert.False(t, ServesPprof(testConfig(config.ProfilingConfig{Enabled: true, Provider: "pyroscope"})))
+}
+
+func TestApplicationName(t *testing.T) {
+	cfg := testConfig(config.ProfilingConfig{Labels: map[string]string{"region": "eu-west-1", "team": "a,b"}})
+	assert.Equal(t, "orders{environment=staging,region=eu-west-1,service=orders,team=ab,version=1.4.2}", applicationName("orders", Labels(cfg)))
+
+	cfg.App.Version = ""
+	assert.NotContains(t, applicationName("orders", Labels(cfg)), "version")
+}
+
+func TestProfiler(t *testing.T) {
+	ingest := newIngester(t)
+	profiler, err := NewProfiler(testConfig(config.ProfilingConfig{
+		Enabled:       true,
+		ServerAddress: ingest.URL + "/pyroscope/",
+		ProfileTypes:  []string{ProfileCPU, ProfileHeap, ProfileGoroutine},
+		TenantID:      "tenant-1",
+		AuthToken:     "secret",
+	}), nil)
+	require.NoError(t, err)
+	profiler.interval = 100 * time.Millisecond
+
+	lc := fxtest.NewLifecycle(t)
+	Register(lc, profiler)
+	require.NoError(t, lc.Start(context.Background()))
+
+	// Keep the CPU busy so the CPU profile has samples
+	deadline := time.Now().Add(350 * time.Millisecond)
+	for time.Now().Before(deadline) {
+	}
+	require.NoError(t, lc.Stop(context.Background()))
+
+	uploads := ingest.received()
+	byType := make(map[string][]upload)
+	for _, u := range uploads {
+		assert.Equal(t, "orders{environment=staging,service=orders,version=1.4.2}", u.name)
+		assert.Equal(t, "tenant-1", u.tenant)
+		assert.Equal(t, "Bearer secret", u.auth)
+		assert.NotEmpty(t, u.from)
+		assert.NotEmpty(t, u.until)
+		require.NotEmpty(t, u.parts["profile"])
+
+		switch config := string(u.parts["sample_type_config"]); {
+		case config == "":
+			byType[ProfileCPU] = append(byType[ProfileCPU], u)
+		case strings.Contains(config, "alloc_space"):
+			byType[ProfileHeap] = append(byType[ProfileHeap], u)
+		case strings.Contains(config, "goroutines"):
+			byType[ProfileGoroutine] = append(byType[ProfileGoroutine], u)
+		}
+	}
+
+	require.GreaterOrEqual(t, len(byType[ProfileHeap]), 2)
+	assert.NotEmpty(t, byType[ProfileCPU])
+	assert.NotEmpty(t, byType[ProfileGoroutine])
+
+	// Cumulative profiles are sent with the previous one, gauges are not
+	assert.Empty(t, byType[ProfileHeap][0].parts["prev_profile"])
+	assert.Equal(t, byType[ProfileHeap][0].parts["profile"], byType[ProfileHeap][1].parts["prev_profile"])
+	for _, u := range byType[ProfileGoroutine] {
+		assert.Empty(t, u.parts["prev_profile"])
+	}
+
+	uploaded, failed := profiler.Stats()
+	assert.Equal(t, int64(len(uploads)), uploaded)
+	assert.Zero(t, failed)
+}
+
+func TestProfilerFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	profiler, err := NewProfiler(testConfig(config.ProfilingConfig{
+		Enabled:       true,
+		ServerAddress: server.URL,
+		ProfileTypes:  []string{ProfileGoroutine},
+	}), nil)
+	require.NoError(t, err)
+	profiler.interval = 20 * time.Millisecond
+
+	profiler.Start()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, profiler.Close(context.Background()))
+
+	uploaded, failed := profiler.Stats()
+	assert.Zero(t, uploaded)
+	assert.Positive(t, failed)
+}
+
+func TestNilProfiler(t *testing.T) {
+	var profiler *Profiler
+	profiler.Start()
+	assert.NoError(t, profiler.Close(context.Background()))
+	uploaded, failed := profiler.Stats()
+	assert.Zero(t, uploaded)
+	assert.Zero(t, failed)
+}
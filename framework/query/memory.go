@@ -0,0 +1,234 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Accessor returns the value of a field of an item, for listings run in memory. Values are
+// strings, booleans, numbers, time.Time, or slices of them, which match a condition when
+// one of their elements does.
+type Accessor[T any] func(item T, field string) any
+
+// Apply runs a listing in memory, as Builder does in SQL: it filters items, leaving out
+// soft-deleted ones, sorts them by their sort fields then by key, and returns the page of params with the total count of
+// matches. Condition values given as strings, as Parse reads them, are parsed into the type
+// of the field; times use RFC 3339.
+func Apply[T any](items []T, schema *Schema, params Params, value Accessor[T]) (ListResult[T], error) {
+	params, err := schema.Check(params)
+	if err != nil {
+		return ListResult[T]{}, err
+	}
+
+	var matches []T
+	for _, item := range items {
+		if schema.DeletedAt != "" && isSet(value(item, schema.DeletedAt)) {
+			continue
+		}
+		if matchesAll(item, params.Filter, value) {
+			matches = append(matches, item)
+		}
+	}
+	total := len(matches)
+
+	keyDesc := false
+	sorts := make([]Sort, 0, len(params.Sort)+1)
+	for _, sort := range params.Sort {
+		if sort.Field == schema.Key {
+			keyDesc = sort.Desc
+			continue
+		}
+		sorts = append(sorts, sort)
+	}
+	sorts = append(sorts, Sort{Field: schema.Key, Desc: keyDesc})
+	slices.SortStableFunc(matches, func(a, b T) int {
+		for _, sort := range sorts {
+			c, _ := compare(value(a, sort.Field), value(b, sort.Field))
+			if sort.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	page := params.Page
+	if page.After != "" {
+		i := 0
+		for ; i < len(matches); i++ {
+			c, _ := compare(value(matches[i], schema.Key), page.After)
+			if keyDesc && c < 0 || !keyDesc && c > 0 {
+				break
+			}
+		}
+		matches = matches[i:]
+	}
+	matches = matches[min(page.Offset, len(matches)):]
+	if page.Limit > 0 {
+		matches = matches[:min(page.Limit+1, len(matches))]
+	}
+
+	key := func(item T) string { return toString(value(item, schema.Key)) }
+	return NewListResult(schema, matches, total, params, key), nil
+}
+
+// isSet reports whether a deletion time is set: neither nil nor zero
+func isSet(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case time.Time:
+		return !v.IsZero()
+	case *time.Time:
+		return v != nil && !v.IsZero()
+	}
+	rv := reflect.ValueOf(v)
+	return !(rv.Kind() == reflect.Pointer && rv.IsNil())
+}
+
+// matchesAll reports whether an item matches every condition of a filter
+func matchesAll[T any](item T, filter Filter, value Accessor[T]) bool {
+	for _, c := range filter {
+		if !matches(value(item, c.Field), c) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether a field value, or one of its elements, matches a condition
+func matches(v any, c Condition) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			if matches(rv.Index(i).Interface(), c) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch c.Op {
+	case In:
+		for _, want := range c.Value.([]string) {
+			if n, ok := compare(v, want); ok && n == 0 {
+				return true
+			}
+		}
+		return false
+	case Contains:
+		return strings.Contains(toString(v), toString(c.Value))
+	}
+
+	n, ok := compare(v, c.Value)
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case Eq:
+		return n == 0
+	case Ne:
+		return n != 0
+	case Lt:
+		return n < 0
+	case Lte:
+		return n <= 0
+	case Gt:
+		return n > 0
+	case Gte:
+		return n >= 0
+	}
+	return false
+}
+
+// compare compares a field value with another value, parsed into the type of the field when
+// it is a string. It returns false for values that cannot be compared.
+func compare(v, other any) (int, bool) {
+	switch v := normalize(v).(type) {
+	case string:
+		return strings.Compare(v, toString(other)), true
+	case int64:
+		o, ok := normalize(other).(int64)
+		if s, isString := other.(string); isString {
+			n, err := strconv.ParseInt(s, 10, 64)
+			o, ok = n, err == nil
+		}
+		return cmp.Compare(v, o), ok
+	case uint64:
+		o, ok := normalize(other).(uint64)
+		if s, isString := other.(string); isString {
+			n, err := strconv.ParseUint(s, 10, 64)
+			o, ok = n, err == nil
+		}
+		return cmp.Compare(v, o), ok
+	case float64:
+		o, ok := normalize(other).(float64)
+		if s, isString := other.(string); isString {
+			n, err := strconv.ParseFloat(s, 64)
+			o, ok = n, err == nil
+		}
+		return cmp.Compare(v, o), ok
+	case bool:
+		o, ok := other.(bool)
+		if s, isString := other.(string); isString {
+			b, err := strconv.ParseBool(s)
+			o, ok = b, err == nil
+		}
+		switch {
+		case v == o:
+			return 0, ok
+		case o:
+			return -1, ok
+		default:
+			return 1, ok
+		}
+	case time.Time:
+		o, ok := other.(time.Time)
+		if s, isString := other.(string); isString {
+			t, err := time.Parse(time.RFC3339, s)
+			o, ok = t, err == nil
+		}
+		return v.Compare(o), ok
+	default:
+		return 0, false
+	}
+}
+
+// normalize converts the numbers of every size to int64, uint64 or float64, and named
+// string types to strings
+func normalize(v any) any {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	default:
+		return v
+	}
+}
+
+// toString formats a value as a string
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(normalize(v))
+	}
+}
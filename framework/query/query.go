@@ -0,0 +1,200 @@
+// Package query is the toolkit of List repositories: the filters, sort order and page of a
+// listing, checked against the fields a repository exposes, run as SQL with Builder or in
+// memory with Apply, and returned in a ListResult with the total count of matches.
+package query
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/pagination"
+)
+
+// Common errors, wrapped in framework errors with an INVALID_INPUT code by Schema.Check
+var (
+	ErrInvalidFilter = stderrors.New("invalid filter")
+	ErrInvalidSort   = stderrors.New("invalid sort")
+	ErrInvalidCursor = stderrors.New("invalid cursor")
+)
+
+// Operator compares a field with the value of a condition
+type Operator string
+
+// Operators of conditions
+const (
+	Eq       Operator = "eq"
+	Ne       Operator = "ne"
+	Lt       Operator = "lt"
+	Lte      Operator = "lte"
+	Gt       Operator = "gt"
+	Gte      Operator = "gte"
+	In       Operator = "in"       // the value is a []string, or a comma-separated string
+	Contains Operator = "contains" // substring match
+)
+
+// Condition restricts a listing to the items whose field compares with Value
+type Condition struct {
+	Field string
+	Op    Operator
+	Value any
+}
+
+// String formats the condition as its query parameter, e.g. count[gt]=3
+func (c Condition) String() string {
+	if c.Op == Eq {
+		return fmt.Sprintf("%s=%v", c.Field, c.Value)
+	}
+	if values, ok := c.Value.([]string); ok {
+		return fmt.Sprintf("%s[%s]=%s", c.Field, c.Op, strings.Join(values, ","))
+	}
+	return fmt.Sprintf("%s[%s]=%v", c.Field, c.Op, c.Value)
+}
+
+// Filter is a conjunction of conditions
+type Filter []Condition
+
+// Where returns the filter with another condition
+func (f Filter) Where(field string, op Operator, value any) Filter {
+	return append(f, Condition{Field: field, Op: op, Value: value})
+}
+
+// Equal returns the value a field must equal, if the filter has such a condition
+func (f Filter) Equal(field string) (any, bool) {
+	for _, c := range f {
+		if c.Field == field && c.Op == Eq {
+			return c.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Sort orders a listing by a field
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String formats the sort as in a sort query parameter, e.g. -created_at
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ParseSort parses a comma-separated list of fields, descending when prefixed with "-",
+// e.g. "-created_at,name"
+func ParseSort(s string) []Sort {
+	var sorts []Sort
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		if field != "" {
+			sorts = append(sorts, Sort{Field: field, Desc: desc})
+		}
+	}
+	return sorts
+}
+
+// Page selects the part of a listing to return. After resumes a listing ordered by the key
+// of its schema after the item with that key; other listings resume at Offset.
+type Page struct {
+	Limit  int // 0 returns every match
+	Offset int
+	After  string
+}
+
+// PageFrom returns the page of size items starting at a page token's cursor
+func PageFrom(cursor pagination.Cursor, size int) Page {
+	return Page{Limit: size, Offset: cursor.Offset, After: cursor.After}
+}
+
+// Params are the filters, sort order and page of a listing
+type Params struct {
+	Filter Filter
+	Sort   []Sort
+	Page   Page
+}
+
+// Fingerprint identifies the filters and sort order of a listing, for pagination.Paginator
+func (p Params) Fingerprint() string {
+	parts := make([]string, 0, len(p.Filter)+len(p.Sort))
+	for _, c := range p.Filter {
+		parts = append(parts, c.String())
+	}
+	for _, s := range p.Sort {
+		parts = append(parts, "sort="+s.String())
+	}
+	return pagination.Fingerprint(parts...)
+}
+
+// ListResult is a page of a listing with the total count of items matching its filters. It
+// is the response envelope of List endpoints over HTTP once signed.
+type ListResult[T any] struct {
+	Items         []T    `json:"items"`
+	Total         int    `json:"total"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+
+	// Next is the position of the next page, nil on the last page
+	Next *pagination.Cursor `json:"-"`
+}
+
+// NewListResult creates the result of a listing from the items a repository found. Builder
+// and Apply fetch one item more than the page to know whether another page follows: it is
+// dropped here. key returns the key of an item, to resume listings ordered by the key of
+// schema after the last item returned.
+func NewListResult[T any](schema *Schema, items []T, total int, params Params, key func(T) string) ListResult[T] {
+	result := ListResult[T]{Items: items, Total: total}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	limit := params.Page.Limit
+	if limit <= 0 || len(items) <= limit {
+		return result
+	}
+
+	result.Items = items[:limit]
+	next := pagination.Cursor{Offset: params.Page.Offset + limit}
+	if keyOrdered(params.Sort, schema.Key) {
+		next = pagination.Cursor{After: key(items[limit-1])}
+	}
+	result.Next = &next
+	return result
+}
+
+// Sign sets the page token of the next page, issued by paginator for the query fingerprint
+// the listing started with
+func (r *ListResult[T]) Sign(paginator *pagination.Paginator, query string) error {
+	r.NextPageToken = ""
+	if r.Next == nil {
+		return nil
+	}
+	token, err := paginator.Next(*r.Next, query)
+	if err != nil {
+		return err
+	}
+	r.NextPageToken = token
+	return nil
+}
+
+// Map converts the items of a result, e.g. entities into responses
+func Map[T, U any](r ListResult[T], convert func(T) U) ListResult[U] {
+	items := make([]U, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = convert(item)
+	}
+	return ListResult[U]{Items: items, Total: r.Total, NextPageToken: r.NextPageToken, Next: r.Next}
+}
+
+// keyOrdered reports whether sorts order a listing by key only, the default order
+func keyOrdered(sorts []Sort, key string) bool {
+	return len(sorts) == 0 || len(sorts) == 1 && sorts[0].Field == key
+}
+
+// invalid wraps err in a framework error with an INVALID_INPUT code
+func invalid(err error, format string, args ...any) error {
+	return errors.NewInvalidInput(err, fmt.Sprintf(format, args...))
+}
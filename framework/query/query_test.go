@@ -0,0 +1,240 @@
+package query
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/pagination"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID        string
+	Status    string
+	Total     int
+	Tags      []string
+	CreatedAt time.Time
+}
+
+var orderSchema = NewSchema("id", map[string]Field{
+	"status":     {Filter: Text, Sortable: true},
+	"total":      {Filter: Comparable, Sortable: true},
+	"tag":        {Filter: []Operator{Eq}},
+	"created_at": {Column: "o.created_at", Filter: Comparable, Sortable: true},
+})
+
+func orderField(o order, field string) any {
+	switch field {
+	case "id":
+		return o.ID
+	case "status":
+		return o.Status
+	case "total":
+		return o.Total
+	case "tag":
+		return o.Tags
+	case "created_at":
+		return o.CreatedAt
+	}
+	return nil
+}
+
+func TestParse(t *testing.T) {
+	params, err := orderSchema.Parse(url.Values{
+		"status[in]": {"open,pending"},
+		"total[gt]":  {"10"},
+		"tag":        {"rush"},
+		"sort":       {"-created_at,id"},
+		"page_size":  {"20"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Filter{
+		{Field: "status", Op: In, Value: []string{"open", "pending"}},
+		{Field: "tag", Op: Eq, Value: "rush"},
+		{Field: "total", Op: Gt, Value: "10"},
+	}, params.Filter, "other parameters are left to the paginator")
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}, {Field: "id"}}, params.Sort)
+
+	tests := []struct {
+		name    string
+		values  url.Values
+		wantErr error
+	}{
+		{"unknown field", url.Values{"secret[eq]": {"x"}}, ErrInvalidFilter},
+		{"unsupported operator", url.Values{"tag[gt]": {"a"}}, ErrInvalidFilter},
+		{"unknown operator", url.Values{"total[between]": {"1"}}, ErrInvalidFilter},
+		{"unsortable field", url.Values{"sort": {"tag"}}, ErrInvalidSort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := orderSchema.Parse(tt.values)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, errors.CodeInvalidInput, errors.GetCode(err))
+		})
+	}
+
+	_, err = orderSchema.Check(Params{Sort: []Sort{{Field: "total"}}, Page: Page{After: "o-1"}})
+	assert.ErrorIs(t, err, ErrInvalidCursor, "only listings ordered by key resume after one")
+}
+
+func TestBuilder(t *testing.T) {
+	params := Params{
+		Filter: Filter{}.Where("status", In, "open,pending").Where("total", Gte, 10).Where("status", Contains, "50%_off"),
+		Sort:   []Sort{{Field: "created_at", Desc: true}},
+		Page:   Page{Limit: 20, Offset: 40},
+	}
+	b, err := Select("o.id", "o.status").From("orders o").
+		Where("EXISTS (SELECT 1 FROM order_tags t WHERE t.order_id = o.id AND t.tag = ?)", "rush").
+		Apply(orderSchema, params)
+	require.NoError(t, err)
+
+	statement, args := b.Build(Dollar)
+	assert.Equal(t, "SELECT o.id, o.status FROM orders o"+
+		" WHERE EXISTS (SELECT 1 FROM order_tags t WHERE t.order_id = o.id AND t.tag = $1)"+
+		" AND status IN ($2, $3) AND total >= $4 AND status LIKE $5 ESCAPE '!'"+
+		" ORDER BY o.created_at DESC, id LIMIT $6 OFFSET $7", statement)
+	assert.Equal(t, []any{"rush", "open", "pending", 10, "%50!%!_off%", 21, 40}, args, "one row more than the page is selected")
+
+	statement, args = b.Count().Build(Question)
+	assert.Equal(t, "SELECT COUNT(*) FROM orders o"+
+		" WHERE EXISTS (SELECT 1 FROM order_tags t WHERE t.order_id = o.id AND t.tag = ?)"+
+		" AND status IN (?, ?) AND total >= ? AND status LIKE ? ESCAPE '!'", statement)
+	assert.Len(t, args, 5)
+
+	b, err = Select("*").From("orders").Apply(orderSchema, Params{Sort: []Sort{{Field: "id", Desc: true}}, Page: Page{After: "o-9"}})
+	require.NoError(t, err)
+	statement, args = b.Build(Dollar)
+	assert.Equal(t, "SELECT * FROM orders WHERE id < $1 ORDER BY id DESC", statement)
+	assert.Equal(t, []any{"o-9"}, args)
+	statement, _ = b.Count().Build(Dollar)
+	assert.Equal(t, "SELECT COUNT(*) FROM orders", statement, "totals count every page")
+
+	assert.Equal(t, "UPDATE orders SET note = '?' WHERE id = $1 AND status = $2", Rebind("UPDATE orders SET note = '?' WHERE id = ? AND status = ?", Dollar))
+
+	_, err = Select("*").From("orders").Apply(orderSchema, Params{Filter: Filter{}.Where("id; DROP TABLE orders", Eq, "1")})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestApply(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var orders []order
+	for i := 1; i <= 5; i++ {
+		orders = append(orders, order{
+			ID:        "o-" + strconv.Itoa(i),
+			Status:    []string{"open", "closed"}[i%2],
+			Total:     i * 10,
+			Tags:      []string{"t" + strconv.Itoa(i%2), "all"},
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	ids := func(r ListResult[order]) []string {
+		var ids []string
+		for _, o := range r.Items {
+			ids = append(ids, o.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		sort   []Sort
+		want   []string
+	}{
+		{"all, by key", nil, nil, []string{"o-1", "o-2", "o-3", "o-4", "o-5"}},
+		{"equal", Filter{}.Where("status", Eq, "open"), nil, []string{"o-2", "o-4"}},
+		{"parsed number", Filter{}.Where("total", Gt, "30"), nil, []string{"o-4", "o-5"}},
+		{"in", Filter{}.Where("status", In, "closed"), nil, []string{"o-1", "o-3", "o-5"}},
+		{"contains", Filter{}.Where("status", Contains, "los"), nil, []string{"o-1", "o-3", "o-5"}},
+		{"slice element", Filter{}.Where("tag", Eq, "t0"), nil, []string{"o-2", "o-4"}},
+		{"parsed time", Filter{}.Where("created_at", Lte, start.Add(2*time.Hour).Format(time.RFC3339)), nil, []string{"o-1", "o-2"}},
+		{"sorted, ties by key", nil, []Sort{{Field: "status"}, {Field: "total", Desc: true}}, []string{"o-5", "o-3", "o-1", "o-4", "o-2"}},
+		{"key descending", nil, []Sort{{Field: "id", Desc: true}}, []string{"o-5", "o-4", "o-3", "o-2", "o-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Apply(orders, orderSchema, Params{Filter: tt.filter, Sort: tt.sort}, orderField)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(result))
+			assert.Equal(t, len(tt.want), result.Total)
+			assert.Nil(t, result.Next)
+		})
+	}
+
+	paginator, err := pagination.NewPaginator(config.PaginationConfig{Secret: "secret"})
+	require.NoError(t, err)
+	pages := func(sort []Sort) [][]string {
+		params := Params{Filter: Filter{}.Where("total", Gte, 20), Sort: sort}
+		fingerprint := params.Fingerprint()
+		var pages [][]string
+		token := ""
+		for {
+			cursor, size, err := paginator.Start(pagination.Request{PageSize: 2, PageToken: token}, fingerprint)
+			require.NoError(t, err)
+			params.Page = PageFrom(cursor, size)
+			result, err := Apply(orders, orderSchema, params, orderField)
+			require.NoError(t, err)
+			assert.Equal(t, 4, result.Total, "totals count every page")
+			require.NoError(t, result.Sign(paginator, fingerprint))
+			pages = append(pages, ids(result))
+			if token = result.NextPageToken; token == "" {
+				return pages
+			}
+		}
+	}
+	assert.Equal(t, [][]string{{"o-2", "o-3"}, {"o-4", "o-5"}}, pages(nil), "listings by key resume after the last key")
+	assert.Equal(t, [][]string{{"o-5", "o-4"}, {"o-3", "o-2"}}, pages([]Sort{{Field: "total", Desc: true}}), "others resume at an offset")
+}
+
+func TestSoftDeleted(t *testing.T) {
+	type note struct {
+		ID        string
+		DeletedAt *time.Time
+	}
+	schema := NewSchema("id", nil).WithDeletedAt("deleted_at")
+	schema.Fields["deleted_at"] = Field{Column: "n.deleted_at"}
+
+	b, err := Select("*").From("notes n").Apply(schema, Params{Filter: Filter{}.Where("id", Ne, "n-0")})
+	require.NoError(t, err)
+	statement, args := b.Build(Question)
+	assert.Equal(t, "SELECT * FROM notes n WHERE n.deleted_at IS NULL AND id <> ? ORDER BY id", statement)
+	assert.Equal(t, []any{"n-0"}, args)
+	statement, _ = b.Count().Build(Question)
+	assert.Equal(t, "SELECT COUNT(*) FROM notes n WHERE n.deleted_at IS NULL AND id <> ?", statement, "totals leave deleted rows out")
+
+	deleted := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notes := []note{{ID: "n-1"}, {ID: "n-2", DeletedAt: &deleted}, {ID: "n-3", DeletedAt: &time.Time{}}}
+	result, err := Apply(notes, schema, Params{}, func(n note, field string) any {
+		if field == "deleted_at" {
+			return n.DeletedAt
+		}
+		return n.ID
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []note{notes[0], notes[2]}, result.Items)
+	assert.Equal(t, 2, result.Total)
+}
+
+func TestFromFiber(t *testing.T) {
+	app := fiber.New()
+	var params Params
+	app.Get("/orders", func(c *fiber.Ctx) error {
+		var err error
+		params, err = FromFiber(c, orderSchema)
+		return err
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders?status=open&total%5Blt%5D=5&sort=-total&page_size=2", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, Filter{{Field: "status", Op: Eq, Value: "open"}, {Field: "total", Op: Lt, Value: "5"}}, params.Filter)
+	assert.Equal(t, []Sort{{Field: "total", Desc: true}}, params.Sort)
+}
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Operators of the usual kinds of fields
+var (
+	// Comparable fields, such as numbers and times, are compared and matched against lists
+	Comparable = []Operator{Eq, Ne, Lt, Lte, Gt, Gte, In}
+	// Text fields are matched exactly, against lists or by substring
+	Text = []Operator{Eq, Ne, In, Contains}
+)
+
+// Field is a field of the items of a listing clients may filter or sort on
+type Field struct {
+	Column   string     // SQL expression of the field; defaults to its name
+	Filter   []Operator // operators the field may be filtered with; none if empty
+	Sortable bool
+}
+
+// Schema lists the fields of a listing clients may filter and sort on. Only these reach
+// SQL, so filters and sorts cannot inject any.
+type Schema struct {
+	Key    string // unique field ordering listings by default and breaking ties, e.g. "id"
+	Fields map[string]Field
+	// DeletedAt is the field of the deletion time of soft-deleted items, which listings
+	// leave out; none if empty
+	DeletedAt string
+}
+
+// NewSchema creates a schema ordering listings by key, itself sortable and comparable
+func NewSchema(key string, fields map[string]Field) *Schema {
+	s := &Schema{Key: key, Fields: make(map[string]Field, len(fields)+1)}
+	s.Fields[key] = Field{Filter: Comparable, Sortable: true}
+	for name, field := range fields {
+		s.Fields[name] = field
+	}
+	return s
+}
+
+// WithDeletedAt sets the field of the deletion time of soft-deleted items, e.g. deleted_at
+func (s *Schema) WithDeletedAt(field string) *Schema {
+	s.DeletedAt = field
+	return s
+}
+
+// Column returns the SQL expression of a field
+func (s *Schema) Column(name string) string {
+	if field, ok := s.Fields[name]; ok && field.Column != "" {
+		return field.Column
+	}
+	return name
+}
+
+// Check validates params against the schema, returning them with the values of In
+// conditions split into lists. Errors are framework errors with an INVALID_INPUT code
+// wrapping ErrInvalidFilter, ErrInvalidSort or ErrInvalidCursor.
+func (s *Schema) Check(params Params) (Params, error) {
+	filter := make(Filter, 0, len(params.Filter))
+	for _, c := range params.Filter {
+		field, ok := s.Fields[c.Field]
+		if !ok || len(field.Filter) == 0 {
+			return Params{}, invalid(ErrInvalidFilter, "cannot filter on %q", c.Field)
+		}
+		if !slices.Contains(field.Filter, c.Op) {
+			return Params{}, invalid(ErrInvalidFilter, "cannot filter on %q with %q", c.Field, c.Op)
+		}
+		if c.Op == In {
+			if value, ok := c.Value.(string); ok {
+				c.Value = strings.Split(value, ",")
+			}
+			if values, ok := c.Value.([]string); !ok || len(values) == 0 {
+				return Params{}, invalid(ErrInvalidFilter, "%q needs a list of values", c.Field+"[in]")
+			}
+		}
+		filter = append(filter, c)
+	}
+	params.Filter = filter
+
+	for _, sort := range params.Sort {
+		if field, ok := s.Fields[sort.Field]; !ok || !field.Sortable {
+			return Params{}, invalid(ErrInvalidSort, "cannot sort on %q", sort.Field)
+		}
+	}
+
+	page := params.Page
+	if page.Limit < 0 || page.Offset < 0 {
+		return Params{}, invalid(ErrInvalidCursor, "limit and offset must not be negative")
+	}
+	if page.After != "" && !keyOrdered(params.Sort, s.Key) {
+		return Params{}, invalid(ErrInvalidCursor, "listings sorted by other fields than %q resume at an offset", s.Key)
+	}
+	return params, nil
+}
+
+// Parse reads the filters and sort order of a listing from query parameters, and checks
+// them against the schema. Fields are filtered with field=value for equality, or with
+// field[op]=value for other operators, e.g. count[gt]=3 or status[in]=open,pending. The sort
+// parameter lists the fields to sort on, see ParseSort. Other parameters, such as page_size,
+// are left to the paginator.
+func (s *Schema) Parse(values url.Values) (Params, error) {
+	var params Params
+	for key, vals := range values {
+		if key == "sort" {
+			for _, v := range vals {
+				params.Sort = append(params.Sort, ParseSort(v)...)
+			}
+			continue
+		}
+
+		name, op := key, Eq
+		if open := strings.IndexByte(key, '['); open > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:open], Operator(key[open+1:len(key)-1])
+		} else if _, ok := s.Fields[key]; !ok {
+			continue
+		}
+		for _, v := range vals {
+			params.Filter = params.Filter.Where(name, op, v)
+		}
+	}
+	// Map iteration is random: order conditions so that fingerprints are stable
+	slices.SortStableFunc(params.Filter, func(a, b Condition) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return s.Check(params)
+}
@@ -0,0 +1,223 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Placeholder is the style of the bind parameters of a database
+type Placeholder int
+
+// Placeholder styles
+const (
+	// Question binds parameters with ?, as MySQL and SQLite do
+	Question Placeholder = iota
+	// Dollar binds parameters with $1, $2..., as PostgreSQL does
+	Dollar
+)
+
+// PlaceholderFor returns the placeholder style of a database driver, e.g. the
+// database.driver configuration
+func PlaceholderFor(driver string) Placeholder {
+	switch strings.ToLower(driver) {
+	case "postgres", "postgresql", "pgx":
+		return Dollar
+	default:
+		return Question
+	}
+}
+
+// Builder builds the SELECT statement of a listing. Conditions are written with ?
+// placeholders, rebound to the placeholder style of the database by Build.
+type Builder struct {
+	columns    string
+	from       string
+	where      []string
+	args       []any
+	cursor     []string // conditions of the page, left out of Count
+	cursorArgs []any
+	orderBy    []string
+	limit      int
+	offset     int
+}
+
+// Select starts a statement selecting columns
+func Select(columns ...string) *Builder {
+	return &Builder{columns: strings.Join(columns, ", ")}
+}
+
+// From sets the table, or the joined tables, the statement selects from
+func (b *Builder) From(from string) *Builder {
+	b.from = from
+	return b
+}
+
+// Where adds a condition, joined to the others with AND
+func (b *Builder) Where(condition string, args ...any) *Builder {
+	b.where = append(b.where, condition)
+	b.args = append(b.args, args...)
+	return b
+}
+
+// OrderBy adds expressions to order by
+func (b *Builder) OrderBy(expressions ...string) *Builder {
+	b.orderBy = append(b.orderBy, expressions...)
+	return b
+}
+
+// Limit sets the maximum number of rows to select; 0 selects every row
+func (b *Builder) Limit(limit int) *Builder {
+	b.limit = limit
+	return b
+}
+
+// Offset sets the number of rows to skip
+func (b *Builder) Offset(offset int) *Builder {
+	b.offset = offset
+	return b
+}
+
+// Apply adds the filters, sort order and page of params, checked against schema. Listings
+// are ordered by the key of schema after their sort fields, so pages are stable, and leave
+// out soft-deleted rows. It selects one row more than the page, for NewListResult to know
+// whether another page follows.
+func (b *Builder) Apply(schema *Schema, params Params) (*Builder, error) {
+	params, err := schema.Check(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.DeletedAt != "" {
+		b.Where(schema.Column(schema.DeletedAt) + " IS NULL")
+	}
+
+	for _, c := range params.Filter {
+		column := schema.Column(c.Field)
+		switch c.Op {
+		case Eq:
+			b.Where(column+" = ?", c.Value)
+		case Ne:
+			b.Where(column+" <> ?", c.Value)
+		case Lt:
+			b.Where(column+" < ?", c.Value)
+		case Lte:
+			b.Where(column+" <= ?", c.Value)
+		case Gt:
+			b.Where(column+" > ?", c.Value)
+		case Gte:
+			b.Where(column+" >= ?", c.Value)
+		case In:
+			values := c.Value.([]string)
+			args := make([]any, len(values))
+			for i, v := range values {
+				args[i] = v
+			}
+			b.Where(column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")+")", args...)
+		case Contains:
+			b.Where(column+` LIKE ? ESCAPE '!'`, "%"+escapeLike(toString(c.Value))+"%")
+		}
+	}
+
+	keyDesc := false
+	for _, sort := range params.Sort {
+		if sort.Field == schema.Key {
+			keyDesc = sort.Desc
+			continue
+		}
+		b.OrderBy(orderBy(schema.Column(sort.Field), sort.Desc))
+	}
+	key := schema.Column(schema.Key)
+	b.OrderBy(orderBy(key, keyDesc))
+
+	if params.Page.After != "" {
+		op := " > ?"
+		if keyDesc {
+			op = " < ?"
+		}
+		b.cursor = append(b.cursor, key+op)
+		b.cursorArgs = append(b.cursorArgs, params.Page.After)
+	}
+	if params.Page.Limit > 0 {
+		b.Limit(params.Page.Limit + 1)
+	}
+	b.Offset(params.Page.Offset)
+	return b, nil
+}
+
+// Count returns a statement counting the rows matching the conditions of b, for the total
+// of a ListResult. The page applied to b is left out.
+func (b *Builder) Count() *Builder {
+	return &Builder{
+		columns: "COUNT(*)",
+		from:    b.from,
+		where:   append([]string(nil), b.where...),
+		args:    append([]any(nil), b.args...),
+	}
+}
+
+// Build returns the statement and its arguments, with placeholders in the given style
+func (b *Builder) Build(placeholder Placeholder) (string, []any) {
+	var sb strings.Builder
+	sb.WriteString("SELECT " + b.columns + " FROM " + b.from)
+	if where := append(append([]string(nil), b.where...), b.cursor...); len(where) > 0 {
+		sb.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
+	if len(b.orderBy) > 0 {
+		sb.WriteString(" ORDER BY " + strings.Join(b.orderBy, ", "))
+	}
+	args := append(append([]any(nil), b.args...), b.cursorArgs...)
+	if b.limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		args = append(args, b.limit)
+	}
+	if b.offset > 0 {
+		if b.limit <= 0 {
+			// MySQL and SQLite need a LIMIT with an OFFSET; PostgreSQL accepts LIMIT ALL
+			sb.WriteString(" LIMIT " + strconv.FormatInt(1<<62, 10))
+		}
+		sb.WriteString(" OFFSET ?")
+		args = append(args, b.offset)
+	}
+	return Rebind(sb.String(), placeholder), args
+}
+
+// orderBy returns the ORDER BY expression of a column
+func orderBy(column string, desc bool) string {
+	if desc {
+		return column + " DESC"
+	}
+	return column
+}
+
+// Rebind replaces the ? placeholders of a statement outside of quotes with the given style,
+// for the statements of a repository not built with Builder
+func Rebind(statement string, placeholder Placeholder) string {
+	if placeholder == Question {
+		return statement
+	}
+	var sb strings.Builder
+	n := 0
+	var quote rune
+	for _, r := range statement {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern with !, an escape character all
+// databases read literally in string constants
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,17 @@
+package query
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FromFiber reads the filters and sort order of a listing from the query parameters of a
+// request, see Schema.Parse
+func FromFiber(c *fiber.Ctx, schema *Schema) (Params, error) {
+	values := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		values.Add(string(key), string(value))
+	})
+	return schema.Parse(values)
+}
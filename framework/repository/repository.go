@@ -0,0 +1,61 @@
+// Package repository holds the building blocks of the repositories of modules: the
+// SoftDelete and Versioned mixins of entities, and Table, which writes them to SQL with
+// soft deletes and optimistic locking. Listings of soft-deleted entities are left to
+// query.Schema.WithDeletedAt.
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/errors"
+)
+
+// SoftDelete is embedded in entities that are marked as deleted rather than removed, so
+// that they can be restored
+type SoftDelete struct {
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Deleted reports whether the entity is marked as deleted
+func (s *SoftDelete) Deleted() bool {
+	return s.DeletedAt != nil && !s.DeletedAt.IsZero()
+}
+
+// MarkDeleted marks the entity as deleted at a time
+func (s *SoftDelete) MarkDeleted(at time.Time) {
+	s.DeletedAt = &at
+}
+
+// Restore clears the deletion mark of the entity
+func (s *SoftDelete) Restore() {
+	s.DeletedAt = nil
+}
+
+// Versioned is embedded in entities updated with optimistic locking: an update only
+// succeeds when the entity was not changed since it was read, at the same version
+type Versioned struct {
+	Version int64 `json:"version"`
+}
+
+// CheckVersion returns a CONFLICT error wrapping errors.ErrConflict when an entity was read
+// at another version than the stored one, for repositories not written with Table, e.g. in
+// memory. kind names the entity in the error, e.g. "order".
+func CheckVersion(kind string, id any, stored, read int64) error {
+	if stored == read {
+		return nil
+	}
+	return conflict(kind, id, stored)
+}
+
+// conflict returns the error of an update of an entity read at another version than the
+// stored one
+func conflict(kind string, id any, stored int64) error {
+	err := errors.NewConflict(errors.ErrConflict, fmt.Sprintf("%s %v was changed concurrently", kind, id))
+	return errors.WithMetadata(err, "version", stored)
+}
+
+// notFound returns the error of a write of an entity that does not exist
+func notFound(kind string, id any) error {
+	return errors.NewNotFound(errors.ErrNotFound, fmt.Sprintf("%s %v", kind, id))
+}
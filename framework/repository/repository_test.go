@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/query"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// script is a database driver recording the statements it runs, and answering them with
+// the rows affected and the rows set up by tests
+type script struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]any
+	affected   int64
+	rows       [][]driver.Value
+}
+
+func (s *script) record(statement string, args []driver.NamedValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statements = append(s.statements, statement)
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	s.args = append(s.args, values)
+}
+
+func (s *script) Connect(ctx context.Context) (driver.Conn, error) { return scriptConn{s}, nil }
+func (s *script) Driver() driver.Driver                            { return nil }
+
+type scriptConn struct{ s *script }
+
+func (c scriptConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, stderrors.New("not supported")
+}
+func (c scriptConn) Close() error              { return nil }
+func (c scriptConn) Begin() (driver.Tx, error) { return nil, stderrors.New("not supported") }
+func (c scriptConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.s.record(query, args)
+	return driver.RowsAffected(c.s.affected), nil
+}
+func (c scriptConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.s.record(query, args)
+	return &scriptRows{rows: c.s.rows}, nil
+}
+
+type scriptRows struct{ rows [][]driver.Value }
+
+func (r *scriptRows) Columns() []string { return []string{"version"} }
+func (r *scriptRows) Close() error      { return nil }
+func (r *scriptRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newDB(t *testing.T, s *script) *database.DB {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	sqlDB := sql.OpenDB(s)
+	t.Cleanup(func() { sqlDB.Close() })
+	return database.New(sqlDB, logger, nil, cfg)
+}
+
+var noteSchema = query.NewSchema("id", map[string]query.Field{
+	"title": {Filter: query.Text, Sortable: true},
+}).WithDeletedAt("deleted_at")
+
+func TestSoftDelete(t *testing.T) {
+	var note struct {
+		SoftDelete
+		Versioned
+	}
+	assert.False(t, note.Deleted())
+	note.MarkDeleted(time.Now())
+	assert.True(t, note.Deleted())
+	note.Restore()
+	assert.False(t, note.Deleted())
+
+	assert.NoError(t, CheckVersion("note", "n-1", 3, 3))
+	err := CheckVersion("note", "n-1", 4, 3)
+	assert.ErrorIs(t, err, errors.ErrConflict)
+	assert.Equal(t, 409, errors.ToHTTPCode(err))
+	assert.Equal(t, int64(4), errors.GetMetadata(err)["version"])
+}
+
+func TestTableUpdate(t *testing.T) {
+	ctx := context.Background()
+	s := &script{affected: 1}
+	db := newDB(t, s)
+	table := NewTable("notes", "note", noteSchema, query.Dollar).WithVersion("version")
+
+	version, err := table.Update(ctx, db, "n-1", 3, map[string]any{"title": "draft", "body": "text"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), version)
+	assert.Equal(t, []string{"UPDATE notes SET body = $1, title = $2, version = version + 1 WHERE id = $3 AND version = $4 AND deleted_at IS NULL"}, s.statements)
+	assert.Equal(t, [][]any{{"text", "draft", "n-1", int64(3)}}, s.args)
+
+	tests := []struct {
+		name     string
+		rows     [][]driver.Value
+		wantErr  error
+		wantCode int
+	}{
+		{"changed concurrently", [][]driver.Value{{int64(5)}}, errors.ErrConflict, 409},
+		{"missing", nil, errors.ErrNotFound, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &script{rows: tt.rows}
+			version, err := table.Update(ctx, newDB(t, s), "n-1", 3, map[string]any{"title": "draft"})
+			assert.Equal(t, int64(3), version)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCode, errors.ToHTTPCode(err))
+			assert.Equal(t, "SELECT version FROM notes WHERE id = $1 AND deleted_at IS NULL", s.statements[1])
+		})
+	}
+
+	s = &script{}
+	_, err = NewTable("notes", "note", query.NewSchema("id", nil), query.Question).Update(ctx, newDB(t, s), "n-1", 3, map[string]any{"title": "draft"})
+	assert.ErrorIs(t, err, errors.ErrNotFound, "rows are missing without a version")
+	assert.Equal(t, []string{"UPDATE notes SET title = ? WHERE id = ?"}, s.statements)
+}
+
+func TestTableDelete(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		table *Table
+		run   func(ctx context.Context, table *Table, db DB) error
+		want  string
+		args  []any
+	}{
+		{
+			"soft delete",
+			NewTable("notes", "note", noteSchema, query.Question),
+			func(ctx context.Context, table *Table, db DB) error { return table.Delete(ctx, db, "n-1") },
+			"UPDATE notes SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+			[]any{now, "n-1"},
+		},
+		{
+			"versioned soft delete",
+			NewTable("notes", "note", noteSchema, query.Question).WithVersion("version"),
+			func(ctx context.Context, table *Table, db DB) error { return table.Delete(ctx, db, "n-1") },
+			"UPDATE notes SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL",
+			[]any{now, "n-1"},
+		},
+		{
+			"hard delete",
+			NewTable("notes", "note", query.NewSchema("id", nil), query.Question),
+			func(ctx context.Context, table *Table, db DB) error { return table.Delete(ctx, db, "n-1") },
+			"DELETE FROM notes WHERE id = ?",
+			[]any{"n-1"},
+		},
+		{
+			"restore",
+			NewTable("notes", "note", noteSchema, query.Dollar).WithVersion("version"),
+			func(ctx context.Context, table *Table, db DB) error { return table.Restore(ctx, db, "n-1") },
+			"UPDATE notes SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL",
+			[]any{"n-1"},
+		},
+		{
+			"purge",
+			NewTable("notes", "note", noteSchema, query.Question),
+			func(ctx context.Context, table *Table, db DB) error { return table.Purge(ctx, db, "n-1") },
+			"DELETE FROM notes WHERE id = ?",
+			[]any{"n-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.now = func() time.Time { return now }
+			s := &script{affected: 1}
+			require.NoError(t, tt.run(ctx, tt.table, newDB(t, s)))
+			assert.Equal(t, []string{tt.want}, s.statements)
+			assert.Equal(t, [][]any{tt.args}, s.args)
+
+			err := tt.run(ctx, tt.table, newDB(t, &script{}))
+			assert.ErrorIs(t, err, errors.ErrNotFound)
+			assert.Equal(t, 404, errors.ToHTTPCode(err))
+		})
+	}
+
+	err := NewTable("notes", "note", query.NewSchema("id", nil), query.Question).Restore(ctx, newDB(t, &script{}), "n-1")
+	assert.Error(t, err, "tables without soft deletes cannot restore rows")
+}
+
+func TestTableGet(t *testing.T) {
+	s := &script{rows: [][]driver.Value{{"draft"}}}
+	var title string
+	table := NewTable("notes", "note", noteSchema, query.Dollar)
+	require.NoError(t, table.Get(context.Background(), newDB(t, s), "title", "n-1").Scan(&title))
+	assert.Equal(t, "draft", title)
+	assert.Equal(t, []string{"SELECT title FROM notes WHERE id = $1 AND deleted_at IS NULL"}, s.statements)
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/query"
+)
+
+// DB runs the statements of a table, such as *database.DB, which runs them in the
+// transaction of their context
+type DB interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Table writes the rows of the entities of a repository to a SQL table. Rows are soft
+// deleted when the schema of the table has a DeletedAt field, and updated with optimistic
+// locking when the table has a version column. Writes of rows that do not exist, or are
+// soft deleted, fail with a NOT_FOUND error wrapping errors.ErrNotFound, and updates of rows
+// changed since they were read with a CONFLICT error wrapping errors.ErrConflict, served
+// as 404 and 409 responses.
+type Table struct {
+	name        string
+	kind        string
+	key         string
+	deletedAt   string
+	version     string
+	placeholder query.Placeholder
+	now         func() time.Time
+}
+
+// NewTable creates the table name of the entities listed with schema, for a placeholder
+// style. The columns of the key and DeletedAt fields of schema must not be qualified with a
+// table alias. kind names the entities in errors, e.g. "order".
+func NewTable(name, kind string, schema *query.Schema, placeholder query.Placeholder) *Table {
+	t := &Table{
+		name:        name,
+		kind:        kind,
+		key:         schema.Column(schema.Key),
+		placeholder: placeholder,
+		now:         time.Now,
+	}
+	if schema.DeletedAt != "" {
+		t.deletedAt = schema.Column(schema.DeletedAt)
+	}
+	return t
+}
+
+// WithVersion locks the rows optimistically with a version column, e.g. version
+func (t *Table) WithVersion(column string) *Table {
+	t.version = column
+	return t
+}
+
+// Get returns the row of the entity with an ID, with columns. Soft-deleted rows are left
+// out, so scanning the row returns sql.ErrNoRows for them.
+func (t *Table) Get(ctx context.Context, db DB, columns string, id any) *sql.Row {
+	statement := "SELECT " + columns + " FROM " + t.name + " WHERE " + t.key + " = ?" + t.live()
+	return db.QueryRow(ctx, query.Rebind(statement, t.placeholder), id)
+}
+
+// Update sets the columns of the row of the entity with an ID, read at version, and
+// returns its new version. Without a version column, version is ignored and returned.
+// Columns are set in the order of their names.
+func (t *Table) Update(ctx context.Context, db DB, id any, version int64, set map[string]any) (int64, error) {
+	columns := make([]string, 0, len(set))
+	for column := range set {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+
+	assignments := make([]string, 0, len(columns)+1)
+	args := make([]any, 0, len(columns)+2)
+	for _, column := range columns {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, set[column])
+	}
+	where := t.key + " = ?"
+	args = append(args, id)
+	if t.version != "" {
+		assignments = append(assignments, t.version+" = "+t.version+" + 1")
+		where += " AND " + t.version + " = ?"
+		args = append(args, version)
+	}
+	if len(assignments) == 0 {
+		return version, errors.New("no columns to update")
+	}
+
+	statement := "UPDATE " + t.name + " SET " + strings.Join(assignments, ", ") + " WHERE " + where + t.live()
+	result, err := db.Exec(ctx, query.Rebind(statement, t.placeholder), args...)
+	if err != nil {
+		return version, fmt.Errorf("failed to update %s %v: %w", t.kind, id, err)
+	}
+	changed, err := t.changed(result, id)
+	if err != nil {
+		return version, err
+	}
+	if !changed {
+		return version, t.missing(ctx, db, id)
+	}
+	if t.version == "" {
+		return version, nil
+	}
+	return version + 1, nil
+}
+
+// Delete deletes the row of the entity with an ID: it is marked as deleted when the table
+// soft deletes rows, and removed otherwise
+func (t *Table) Delete(ctx context.Context, db DB, id any) error {
+	if t.deletedAt == "" {
+		return t.Purge(ctx, db, id)
+	}
+	set := t.deletedAt + " = ?"
+	if t.version != "" {
+		set += ", " + t.version + " = " + t.version + " + 1"
+	}
+	statement := "UPDATE " + t.name + " SET " + set + " WHERE " + t.key + " = ?" + t.live()
+	return t.exec(ctx, db, "delete", statement, id, t.now().UTC(), id)
+}
+
+// Restore clears the deletion mark of the soft-deleted row of the entity with an ID
+func (t *Table) Restore(ctx context.Context, db DB, id any) error {
+	if t.deletedAt == "" {
+		return errors.New("table " + t.name + " does not soft delete rows")
+	}
+	set := t.deletedAt + " = NULL"
+	if t.version != "" {
+		set += ", " + t.version + " = " + t.version + " + 1"
+	}
+	statement := "UPDATE " + t.name + " SET " + set + " WHERE " + t.key + " = ? AND " + t.deletedAt + " IS NOT NULL"
+	return t.exec(ctx, db, "restore", statement, id, id)
+}
+
+// Purge removes the row of the entity with an ID, whether it is soft deleted or not
+func (t *Table) Purge(ctx context.Context, db DB, id any) error {
+	return t.exec(ctx, db, "delete", "DELETE FROM "+t.name+" WHERE "+t.key+" = ?", id, id)
+}
+
+// exec runs a statement changing the row of id, which must exist
+func (t *Table) exec(ctx context.Context, db DB, action, statement string, id any, args ...any) error {
+	result, err := db.Exec(ctx, query.Rebind(statement, t.placeholder), args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s %s %v: %w", action, t.kind, id, err)
+	}
+	changed, err := t.changed(result, id)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return notFound(t.kind, id)
+	}
+	return nil
+}
+
+// changed reports whether a statement changed the row of id
+func (t *Table) changed(result sql.Result, id any) (bool, error) {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to count changed %s %v: %w", t.kind, id, err)
+	}
+	return n > 0, nil
+}
+
+// missing returns why an update changed no row: the row of id does not exist, or it is at
+// another version
+func (t *Table) missing(ctx context.Context, db DB, id any) error {
+	if t.version == "" {
+		return notFound(t.kind, id)
+	}
+	var stored int64
+	err := t.Get(ctx, db, t.version, id).Scan(&stored)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return notFound(t.kind, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %v: %w", t.kind, id, err)
+	}
+	return conflict(t.kind, id, stored)
+}
+
+// live returns the condition leaving soft-deleted rows out, if any
+func (t *Table) live() string {
+	if t.deletedAt == "" {
+		return ""
+	}
+	return " AND " + t.deletedAt + " IS NULL"
+}
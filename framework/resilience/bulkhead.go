@@ -0,0 +1,120 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+)
+
+// ErrBulkheadFull is returned when a bulkhead has no free slot and its queue is full or timed out
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// BulkheadOptions contains options for a bulkhead
+type BulkheadOptions struct {
+	// MaxConcurrent is the maximum number of calls running at once
+	MaxConcurrent int
+	// MaxQueue is the maximum number of calls waiting for a slot; zero rejects at once
+	MaxQueue int
+	// QueueTimeout is how long a call waits for a slot; zero waits until its context is done
+	QueueTimeout time.Duration
+	// Clock times the queue; nil uses the system clock
+	Clock clock.Clock
+}
+
+// DefaultBulkheadOptions returns the default bulkhead options
+func DefaultBulkheadOptions() *BulkheadOptions {
+	return &BulkheadOptions{
+		MaxConcurrent: 10,
+		MaxQueue:      10,
+		QueueTimeout:  time.Second,
+	}
+}
+
+// Bulkhead isolates a dependency by capping the calls in flight to it, so a slow dependency
+// cannot tie up every goroutine of the service
+type Bulkhead struct {
+	options *BulkheadOptions
+	slots   chan struct{}
+	queued  atomic.Int64
+}
+
+// NewBulkhead creates a new bulkhead
+func NewBulkhead(options *BulkheadOptions) *Bulkhead {
+	if options == nil {
+		options = DefaultBulkheadOptions()
+	}
+	maxConcurrent := options.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	return &Bulkhead{
+		options: options,
+		slots:   make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Acquire takes a slot, waiting in the queue if none is free. The returned function releases
+// the slot and must be called once the call is done.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaseFunc(), nil
+	default:
+	}
+
+	if b.queued.Add(1) > int64(b.options.MaxQueue) {
+		b.queued.Add(-1)
+		return nil, ErrBulkheadFull
+	}
+	defer b.queued.Add(-1)
+
+	var timeout <-chan time.Time
+	if b.options.QueueTimeout > 0 {
+		timer := clock.OrSystem(b.options.Clock).NewTimer(b.options.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaseFunc(), nil
+	case <-timeout:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// releaseFunc returns a function freeing one slot, safe to call more than once
+func (b *Bulkhead) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-b.slots })
+	}
+}
+
+// Execute runs fn in a bulkhead slot
+func (b *Bulkhead) Execute(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return fn(ctx)
+}
+
+// InFlight returns the number of calls holding a slot
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+// Queued returns the number of calls waiting for a slot
+func (b *Bulkhead) Queued() int {
+	return int(b.queued.Load())
+}
@@ -0,0 +1,89 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkhead(t *testing.T) {
+	b := NewBulkhead(&BulkheadOptions{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	release, err := b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.InFlight())
+
+	// The queued call times out while the slot is held
+	_, err = b.Acquire(ctx)
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	// With the queue taken, further calls are rejected at once
+	queued := make(chan error, 1)
+	go func() {
+		next, err := b.Acquire(ctx)
+		if err == nil {
+			next()
+		}
+		queued <- err
+	}()
+	assert.Eventually(t, func() bool { return b.Queued() == 1 }, time.Second, time.Millisecond)
+	_, err = b.Acquire(ctx)
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	// Releasing the slot lets the queued call through; releasing twice is harmless
+	release()
+	release()
+	require.NoError(t, <-queued)
+	assert.Equal(t, 0, b.InFlight())
+
+	// Waiting ends with the caller's context
+	release, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	defer release()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = b.Acquire(cancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestResilienceBulkhead(t *testing.T) {
+	options := DefaultResilienceOptions()
+	options.Retry = nil
+	options.Bulkhead = &BulkheadOptions{MaxConcurrent: 1}
+	r := New(options)
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_, _ = r.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-done
+			return nil, nil
+		})
+	}()
+	<-started
+
+	_, err := r.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return "unreachable", nil
+	})
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	// A fallback receives the rejection
+	options.Fallback.FallbackFunc = func(ctx context.Context, err error) (interface{}, error) {
+		if errors.Is(err, ErrBulkheadFull) {
+			return "cached", nil
+		}
+		return nil, err
+	}
+	result, err := r.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return "unreachable", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cached", result)
+	close(done)
+}
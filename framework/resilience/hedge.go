@@ -0,0 +1,172 @@
+package resilience
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+)
+
+// hedgeBurst is the number of hedges allowed before MaxRatio applies, so that services
+// with little traffic can still hedge
+const hedgeBurst = 10
+
+// HedgeOptions contains options for hedged calls
+type HedgeOptions struct {
+	// Delay is how long an attempt runs before the next one is started in parallel;
+	// typically around the 95th percentile latency of the downstream
+	Delay time.Duration
+	// MaxHedges is the number of attempts started in addition to the first
+	MaxHedges int
+	// MaxRatio caps hedges to a share of calls, so hedging cannot multiply the load on a
+	// slow downstream; zero leaves them unlimited
+	MaxRatio float64
+	// Discard receives the results of attempts that are not returned, e.g. to close
+	// response bodies
+	Discard func(result interface{})
+	// Clock times the delays; nil uses the system clock
+	Clock clock.Clock
+}
+
+// DefaultHedgeOptions returns the default hedge options
+func DefaultHedgeOptions() *HedgeOptions {
+	return &HedgeOptions{
+		Delay:     100 * time.Millisecond,
+		MaxHedges: 1,
+		MaxRatio:  0.1,
+	}
+}
+
+// Hedger cuts tail latency by starting another attempt of a slow call in parallel and
+// returning the first success. Only hedge calls that are safe to repeat, such as reads.
+type Hedger struct {
+	options *HedgeOptions
+	mu      sync.Mutex
+	tokens  float64
+	hedges  atomic.Int64
+}
+
+// NewHedger creates a new hedger
+func NewHedger(options *HedgeOptions) *Hedger {
+	defaults := DefaultHedgeOptions()
+	if options == nil {
+		options = defaults
+	}
+	opts := *options
+	if opts.Delay <= 0 {
+		opts.Delay = defaults.Delay
+	}
+	if opts.MaxHedges <= 0 {
+		opts.MaxHedges = defaults.MaxHedges
+	}
+
+	return &Hedger{options: &opts, tokens: hedgeBurst}
+}
+
+// hedgeOutcome is the outcome of one attempt of a hedged call
+type hedgeOutcome struct {
+	attempt int
+	result  interface{}
+	err     error
+}
+
+// Execute runs fn and, each time Delay passes without an outcome, another attempt of it,
+// up to MaxHedges. The first success is returned and the other attempts are canceled; if
+// every attempt fails, the outcome of the last one is returned. The context of the
+// returned attempt is left open, so results tied to it, such as response bodies, stay usable.
+func (h *Hedger) Execute(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	h.deposit()
+
+	outcomes := make(chan hedgeOutcome, h.options.MaxHedges+1)
+	cancels := make([]context.CancelFunc, 0, h.options.MaxHedges+1)
+	start := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			result, err := fn(attemptCtx)
+			outcomes <- hedgeOutcome{attempt: attempt, result: result, err: err}
+		}()
+	}
+
+	start()
+	timer := clock.OrSystem(h.options.Clock).NewTimer(h.options.Delay)
+	defer timer.Stop()
+
+	pending := 1
+	for {
+		select {
+		case <-timer.C():
+			if len(cancels) > h.options.MaxHedges || ctx.Err() != nil || !h.allow() {
+				continue
+			}
+			h.hedges.Add(1)
+			start()
+			pending++
+			if len(cancels) <= h.options.MaxHedges {
+				timer.Reset(h.options.Delay)
+			}
+		case outcome := <-outcomes:
+			pending--
+			if outcome.err != nil && pending > 0 {
+				h.discard(outcome.result)
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != outcome.attempt {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go h.drain(outcomes, pending)
+			}
+			return outcome.result, outcome.err
+		}
+	}
+}
+
+// Hedges returns the number of hedged attempts started
+func (h *Hedger) Hedges() int64 {
+	return h.hedges.Load()
+}
+
+// deposit earns a share of a hedge for a call
+func (h *Hedger) deposit() {
+	if h.options.MaxRatio <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = math.Min(h.tokens+h.options.MaxRatio, hedgeBurst)
+}
+
+// allow reports whether the hedge rate cap leaves room for another hedge
+func (h *Hedger) allow() bool {
+	if h.options.MaxRatio <= 0 {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// drain discards the outcomes of the attempts still running after a call returned
+func (h *Hedger) drain(outcomes <-chan hedgeOutcome, pending int) {
+	for i := 0; i < pending; i++ {
+		h.discard((<-outcomes).result)
+	}
+}
+
+// discard hands a result that is not returned to the Discard option
+func (h *Hedger) discard(result interface{}) {
+	if result != nil && h.options.Discard != nil {
+		h.options.Discard(result)
+	}
+}
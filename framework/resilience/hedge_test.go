@@ -0,0 +1,123 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedger(t *testing.T) {
+	tests := []struct {
+		name       string
+		latencies  []time.Duration // per attempt
+		failures   []bool          // per attempt
+		wantResult interface{}
+		wantErr    bool
+		wantHedges int64
+	}{
+		{
+			name:       "fast calls are not hedged",
+			latencies:  []time.Duration{0},
+			wantResult: 0,
+		},
+		{
+			name:       "a faster hedge wins over a slow first attempt",
+			latencies:  []time.Duration{time.Second, 0},
+			wantResult: 1,
+			wantHedges: 1,
+		},
+		{
+			name:       "a failed hedge waits for the first attempt",
+			latencies:  []time.Duration{50 * time.Millisecond, 0},
+			failures:   []bool{false, true},
+			wantResult: 0,
+			wantHedges: 1,
+		},
+		{
+			name:       "the last failure is returned when all attempts fail",
+			latencies:  []time.Duration{30 * time.Millisecond, 0},
+			failures:   []bool{true, true},
+			wantResult: 0,
+			wantErr:    true,
+			wantHedges: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var discarded sync.WaitGroup
+			discarded.Add(len(tt.latencies) - 1)
+			h := NewHedger(&HedgeOptions{
+				Delay:     10 * time.Millisecond,
+				MaxHedges: len(tt.latencies) - 1,
+				Discard:   func(interface{}) { discarded.Done() },
+			})
+
+			var attempts atomic.Int32
+			result, err := h.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+				attempt := int(attempts.Add(1)) - 1
+				select {
+				case <-time.After(tt.latencies[attempt]):
+				case <-ctx.Done():
+					return attempt, ctx.Err()
+				}
+				if attempt < len(tt.failures) && tt.failures[attempt] {
+					return attempt, errors.New("attempt failed")
+				}
+				return attempt, nil
+			})
+
+			assert.Equal(t, tt.wantResult, result)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantHedges, h.Hedges())
+			if tt.wantHedges > 0 {
+				// Every result that is not returned is discarded, including canceled attempts
+				discarded.Wait()
+			}
+		})
+	}
+}
+
+func TestHedgerRateCap(t *testing.T) {
+	h := NewHedger(&HedgeOptions{Delay: time.Millisecond, MaxHedges: 1, MaxRatio: 0.5})
+	slow := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	// Hedges stay within the burst plus MaxRatio of the calls
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := h.Execute(ctx, slow)
+		cancel()
+		require.Error(t, err)
+	}
+	assert.Greater(t, h.Hedges(), int64(hedgeBurst))
+	assert.LessOrEqual(t, h.Hedges(), int64(hedgeBurst+10))
+}
+
+func TestResilienceHedge(t *testing.T) {
+	options := DefaultResilienceOptions()
+	options.Retry = nil
+	options.Hedge = &HedgeOptions{Delay: 10 * time.Millisecond}
+	r := New(options)
+	require.NotNil(t, r.GetHedger())
+
+	var attempts atomic.Int32
+	result, err := r.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return "hedged", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hedged", result)
+	assert.Equal(t, int64(1), r.GetHedger().Hedges())
+}
@@ -0,0 +1,249 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+)
+
+// ErrLimitExceeded is returned when an adaptive limiter sheds a call
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// Adaptive limit algorithms
+const (
+	// LimitAlgorithmAIMD grows the limit by one after each success and cuts it by BackoffRatio
+	// after each drop
+	LimitAlgorithmAIMD = "aimd"
+	// LimitAlgorithmVegas estimates the queue from how much latency exceeds the no-load
+	// latency and keeps it small, so it backs off before anything fails
+	LimitAlgorithmVegas = "vegas"
+)
+
+// AdaptiveLimiterOptions contains options for an adaptive limiter
+type AdaptiveLimiterOptions struct {
+	// Algorithm is LimitAlgorithmAIMD or LimitAlgorithmVegas
+	Algorithm string
+	// InitialLimit is the concurrency limit before any feedback
+	InitialLimit int
+	// MinLimit and MaxLimit bound the limit
+	MinLimit int
+	MaxLimit int
+	// BackoffRatio multiplies the limit after a drop (AIMD)
+	BackoffRatio float64
+	// LatencyThreshold counts slower calls as drops (AIMD); zero disables it
+	LatencyThreshold time.Duration
+	// ProbeInterval is the number of samples after which the no-load latency is measured
+	// again, so it follows lasting changes (Vegas)
+	ProbeInterval int
+}
+
+// DefaultAdaptiveLimiterOptions returns the default adaptive limiter options
+func DefaultAdaptiveLimiterOptions() *AdaptiveLimiterOptions {
+	return &AdaptiveLimiterOptions{
+		Algorithm:     LimitAlgorithmAIMD,
+		InitialLimit:  20,
+		MinLimit:      1,
+		MaxLimit:      1000,
+		BackoffRatio:  0.9,
+		ProbeInterval: 1000,
+	}
+}
+
+// AdaptiveLimiterOptionsFromConfig maps a server concurrency limit configuration to limiter
+// options; unset values take the defaults
+func AdaptiveLimiterOptionsFromConfig(cfg config.ConcurrencyLimitConfig) *AdaptiveLimiterOptions {
+	options := DefaultAdaptiveLimiterOptions()
+	if cfg.Algorithm != "" {
+		options.Algorithm = cfg.Algorithm
+	}
+	if cfg.InitialLimit > 0 {
+		options.InitialLimit = cfg.InitialLimit
+	}
+	if cfg.MinLimit > 0 {
+		options.MinLimit = cfg.MinLimit
+	}
+	if cfg.MaxLimit > 0 {
+		options.MaxLimit = cfg.MaxLimit
+	}
+	options.LatencyThreshold = time.Duration(cfg.LatencyThreshold) * time.Millisecond
+	return options
+}
+
+// AdaptiveLimiter sheds calls beyond a concurrency limit that it adjusts from the latency
+// and outcome of the calls it lets through, to keep a saturated service responsive
+type AdaptiveLimiter struct {
+	options  *AdaptiveLimiterOptions
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	minRTT   time.Duration
+	samples  int
+}
+
+// NewAdaptiveLimiter creates a new adaptive limiter
+func NewAdaptiveLimiter(options *AdaptiveLimiterOptions) *AdaptiveLimiter {
+	defaults := DefaultAdaptiveLimiterOptions()
+	if options == nil {
+		options = defaults
+	}
+	opts := *options
+	if opts.Algorithm == "" {
+		opts.Algorithm = defaults.Algorithm
+	}
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = defaults.MinLimit
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = defaults.MaxLimit
+	}
+	if opts.MaxLimit < opts.MinLimit {
+		opts.MaxLimit = opts.MinLimit
+	}
+	if opts.InitialLimit <= 0 {
+		opts.InitialLimit = defaults.InitialLimit
+	}
+	if opts.BackoffRatio <= 0 || opts.BackoffRatio >= 1 {
+		opts.BackoffRatio = defaults.BackoffRatio
+	}
+
+	l := &AdaptiveLimiter{options: &opts}
+	l.limit = l.clamp(float64(opts.InitialLimit))
+	return l
+}
+
+// LimiterToken is a call let through by an adaptive limiter. Exactly one of Success, Dropped
+// or Ignore must be called when the call is done; later calls have no effect.
+type LimiterToken struct {
+	limiter  *AdaptiveLimiter
+	start    time.Time
+	inFlight int
+	once     sync.Once
+}
+
+// Acquire lets a call through if the number of calls in flight is below the limit
+func (l *AdaptiveLimiter) Acquire() (*LimiterToken, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		return nil, ErrLimitExceeded
+	}
+	l.inFlight++
+	return &LimiterToken{limiter: l, start: time.Now(), inFlight: l.inFlight}, nil
+}
+
+// Success reports that the call completed; its latency feeds the limit
+func (t *LimiterToken) Success() {
+	t.once.Do(func() { t.limiter.release(t, false, true) })
+}
+
+// Dropped reports that the call failed from overload, e.g. timed out, which lowers the limit
+func (t *LimiterToken) Dropped() {
+	t.once.Do(func() { t.limiter.release(t, true, true) })
+}
+
+// Ignore releases the call without adjusting the limit, e.g. when it failed for unrelated reasons
+func (t *LimiterToken) Ignore() {
+	t.once.Do(func() { t.limiter.release(t, false, false) })
+}
+
+// Execute runs fn if the limit allows it. Timeouts count as drops.
+func (l *AdaptiveLimiter) Execute(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	token, err := l.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := fn(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		token.Dropped()
+	} else {
+		token.Success()
+	}
+	return result, err
+}
+
+// Limit returns the current concurrency limit
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of calls let through and not yet done
+func (l *AdaptiveLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// release frees a token and, if sampled, adjusts the limit
+func (l *AdaptiveLimiter) release(t *LimiterToken, dropped, sample bool) {
+	rtt := time.Since(t.start)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if sample {
+		l.sample(rtt, t.inFlight, dropped)
+	}
+}
+
+// sample adjusts the limit from a call that took rtt with inFlight calls running. l.mu must be held.
+func (l *AdaptiveLimiter) sample(rtt time.Duration, inFlight int, dropped bool) {
+	switch l.options.Algorithm {
+	case LimitAlgorithmVegas:
+		l.vegas(rtt, inFlight, dropped)
+	default:
+		l.aimd(rtt, inFlight, dropped)
+	}
+}
+
+// aimd applies additive increase, multiplicative decrease. l.mu must be held.
+func (l *AdaptiveLimiter) aimd(rtt time.Duration, inFlight int, dropped bool) {
+	if dropped || (l.options.LatencyThreshold > 0 && rtt > l.options.LatencyThreshold) {
+		l.limit = l.clamp(math.Floor(l.limit * l.options.BackoffRatio))
+		return
+	}
+	// Only grow when the limit was actually tested, or an idle service would grow it without bound
+	if inFlight*2 >= int(l.limit) {
+		l.limit = l.clamp(l.limit + 1)
+	}
+}
+
+// vegas adjusts the limit from the estimated queue. l.mu must be held.
+func (l *AdaptiveLimiter) vegas(rtt time.Duration, inFlight int, dropped bool) {
+	l.samples++
+	if l.options.ProbeInterval > 0 && l.samples%l.options.ProbeInterval == 0 {
+		l.minRTT = 0
+	}
+	if l.minRTT == 0 || rtt < l.minRTT {
+		l.minRTT = rtt
+	}
+
+	step := math.Max(1, math.Log10(l.limit))
+	if dropped {
+		l.limit = l.clamp(l.limit - step)
+		return
+	}
+	if inFlight*2 < int(l.limit) || rtt <= 0 {
+		return
+	}
+
+	queue := l.limit * (1 - float64(l.minRTT)/float64(rtt))
+	switch {
+	case queue <= 3*step:
+		l.limit = l.clamp(l.limit + step)
+	case queue >= 6*step:
+		l.limit = l.clamp(l.limit - step)
+	}
+}
+
+// clamp bounds a limit to the configured range
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	return math.Min(math.Max(limit, float64(l.options.MinLimit)), float64(l.options.MaxLimit))
+}
@@ -0,0 +1,120 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fill acquires tokens up to the limit
+func fill(t *testing.T, l *AdaptiveLimiter) []*LimiterToken {
+	t.Helper()
+	var tokens []*LimiterToken
+	for {
+		token, err := l.Acquire()
+		if err != nil {
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+			return tokens
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	l := NewAdaptiveLimiter(&AdaptiveLimiterOptions{Algorithm: LimitAlgorithmAIMD, InitialLimit: 10, MinLimit: 2, MaxLimit: 12})
+
+	tokens := fill(t, l)
+	require.Len(t, tokens, 10)
+	assert.Equal(t, 10, l.InFlight())
+
+	// Successes of calls made at full load raise the limit up to MaxLimit
+	for _, token := range tokens[7:] {
+		token.Success()
+	}
+	assert.Equal(t, 12, l.Limit())
+
+	// A drop backs off multiplicatively; released tokens ignore further reports
+	tokens[6].Dropped()
+	tokens[6].Success()
+	assert.Equal(t, 10, l.Limit())
+
+	// Ignored calls only free their slot
+	for _, token := range tokens[:6] {
+		token.Ignore()
+	}
+	assert.Equal(t, 10, l.Limit())
+	assert.Equal(t, 0, l.InFlight())
+
+	// Successes of a mostly idle service do not grow the limit
+	token, err := l.Acquire()
+	require.NoError(t, err)
+	token.Success()
+	assert.Equal(t, 10, l.Limit())
+
+	// Repeated drops stop at MinLimit
+	for i := 0; i < 20; i++ {
+		token, err := l.Acquire()
+		require.NoError(t, err)
+		token.Dropped()
+	}
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestAdaptiveLimiterAIMDLatencyThreshold(t *testing.T) {
+	l := NewAdaptiveLimiter(&AdaptiveLimiterOptions{InitialLimit: 10, LatencyThreshold: time.Millisecond})
+
+	token, err := l.Acquire()
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	token.Success()
+	assert.Equal(t, 9, l.Limit(), "slow calls count as drops")
+}
+
+func TestAdaptiveLimiterVegas(t *testing.T) {
+	l := NewAdaptiveLimiter(&AdaptiveLimiterOptions{Algorithm: LimitAlgorithmVegas, InitialLimit: 10, MaxLimit: 100, ProbeInterval: 100})
+	sample := func(rtt time.Duration, dropped bool) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.sample(rtt, int(l.limit), dropped)
+	}
+
+	// With latency at its no-load level the queue is empty and the limit grows
+	for i := 0; i < 10; i++ {
+		sample(10*time.Millisecond, false)
+	}
+	grown := l.Limit()
+	assert.GreaterOrEqual(t, grown, 20)
+
+	// Latency at twice the no-load level means half the calls are queuing, so the limit shrinks
+	sample(20*time.Millisecond, false)
+	assert.Less(t, l.Limit(), grown)
+
+	// A moderate queue is tolerated
+	limit := l.Limit()
+	sample(13*time.Millisecond, false)
+	assert.Equal(t, limit, l.Limit())
+
+	// Drops shrink the limit whatever the latency
+	sample(10*time.Millisecond, true)
+	assert.Less(t, l.Limit(), limit)
+}
+
+func TestAdaptiveLimiterExecute(t *testing.T) {
+	l := NewAdaptiveLimiter(&AdaptiveLimiterOptions{InitialLimit: 4})
+
+	result, err := l.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+
+	_, err = l.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return nil, context.DeadlineExceeded
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 3, l.Limit(), "timeouts count as drops")
+	assert.Equal(t, 0, l.InFlight())
+}
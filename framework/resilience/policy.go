@@ -0,0 +1,240 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/config"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the policy registry configured under resilience.policies and makes it
+// the registry behind Get
+var Module = fx.Options(
+	fx.Provide(ProvideRegistry),
+	fx.Invoke(SetDefaultRegistry),
+)
+
+// Execute runs fn with the patterns of r and returns its result as a T, sparing the type
+// assertion of Resilience.Execute. A fallback must return a T or nil.
+func Execute[T any](ctx context.Context, r *Resilience, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	result, err := r.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		return fn(ctx)
+	})
+	if err != nil || result == nil {
+		return zero, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("resilience: result of type %T is not a %T", result, zero)
+	}
+	return typed, nil
+}
+
+// OptionsFromPolicyConfig maps a policy configuration to resilience options; unset values
+// take the defaults, and retries, the bulkhead and hedging stay off unless configured
+func OptionsFromPolicyConfig(name string, cfg config.ResiliencePolicyConfig) *ResilienceOptions {
+	options := DefaultResilienceOptions()
+	options.CircuitBreaker.Name = name
+	if cfg.Timeout > 0 {
+		options.Timeout.Timeout = time.Duration(cfg.Timeout) * time.Millisecond
+	}
+
+	options.Retry = nil
+	if cfg.Retry.MaxRetries > 0 {
+		options.Retry = DefaultRetryOptions()
+		options.Retry.MaxRetries = cfg.Retry.MaxRetries
+		if cfg.Retry.Delay > 0 {
+			options.Retry.RetryDelay = time.Duration(cfg.Retry.Delay) * time.Millisecond
+		}
+		if cfg.Retry.BackoffFactor > 0 {
+			options.Retry.BackoffFactor = cfg.Retry.BackoffFactor
+		}
+		if cfg.Retry.MaxDelay > 0 {
+			options.Retry.MaxDelay = time.Duration(cfg.Retry.MaxDelay) * time.Millisecond
+		}
+	}
+
+	if cfg.CircuitBreaker.MaxFailures > 0 {
+		options.CircuitBreaker.MaxFailures = cfg.CircuitBreaker.MaxFailures
+	}
+	if cfg.CircuitBreaker.ResetTimeout > 0 {
+		options.CircuitBreaker.ResetTimeout = time.Duration(cfg.CircuitBreaker.ResetTimeout) * time.Second
+	}
+	if cfg.CircuitBreaker.HalfOpenLimit > 0 {
+		options.CircuitBreaker.HalfOpenLimit = cfg.CircuitBreaker.HalfOpenLimit
+	}
+
+	if cfg.Bulkhead.MaxConcurrent > 0 {
+		options.Bulkhead = &BulkheadOptions{
+			MaxConcurrent: cfg.Bulkhead.MaxConcurrent,
+			MaxQueue:      cfg.Bulkhead.MaxQueue,
+			QueueTimeout:  time.Duration(cfg.Bulkhead.QueueTimeout) * time.Millisecond,
+		}
+	}
+
+	if cfg.Hedge.Delay > 0 {
+		options.Hedge = DefaultHedgeOptions()
+		options.Hedge.Delay = time.Duration(cfg.Hedge.Delay) * time.Millisecond
+		if cfg.Hedge.MaxHedges > 0 {
+			options.Hedge.MaxHedges = cfg.Hedge.MaxHedges
+		}
+		if cfg.Hedge.MaxRatio > 0 {
+			options.Hedge.MaxRatio = cfg.Hedge.MaxRatio
+		}
+	}
+	return options
+}
+
+// Registry holds named resilience policies, one per dependency, so that their settings
+// live in configuration and their circuit breakers are shared by every caller
+type Registry struct {
+	mu       sync.Mutex
+	options  map[string]*ResilienceOptions
+	policies map[string]*Resilience
+	clock    clock.Clock
+}
+
+// NewRegistry creates an empty policy registry
+func NewRegistry() *Registry {
+	return &Registry{
+		options:  make(map[string]*ResilienceOptions),
+		policies: make(map[string]*Resilience),
+	}
+}
+
+// NewRegistryFromConfig creates a policy registry holding the configured policies
+func NewRegistryFromConfig(cfg config.ResilienceConfig) *Registry {
+	r := NewRegistry()
+	for name, policy := range cfg.Policies {
+		r.Register(name, OptionsFromPolicyConfig(normalizePolicyName(name), policy))
+	}
+	return r
+}
+
+// RegistryParams holds the dependencies of the policy registry
+type RegistryParams struct {
+	fx.In
+
+	Config *config.Config
+	Clock  clock.Clock `optional:"true"`
+}
+
+// ProvideRegistry provides the policy registry configured under resilience.policies, running
+// its policies on the provided clock
+func ProvideRegistry(p RegistryParams) *Registry {
+	r := NewRegistryFromConfig(p.Config.Resilience)
+	if p.Clock != nil {
+		r.WithClock(p.Clock)
+	}
+	return r
+}
+
+// WithClock runs the policies created from now on on c, unless their options set a clock
+func (r *Registry) WithClock(c clock.Clock) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = c
+	return r
+}
+
+// Register sets the options of a policy, replacing the policy and its circuit breaker state
+func (r *Registry) Register(name string, options *ResilienceOptions) {
+	name = normalizePolicyName(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.options[name] = options
+	delete(r.policies, name)
+}
+
+// Get returns the named policy, creating it on first use. Names are case-insensitive, like
+// configuration keys; policies that were not registered use the default options.
+func (r *Registry) Get(name string) *Resilience {
+	name = normalizePolicyName(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if policy, ok := r.policies[name]; ok {
+		return policy
+	}
+	options, ok := r.options[name]
+	if !ok {
+		options = DefaultResilienceOptions()
+		options.CircuitBreaker.Name = name
+	}
+	policy := New(withClock(options, r.clock))
+	r.policies[name] = policy
+	return policy
+}
+
+// withClock returns a copy of options using c where they do not set a clock
+func withClock(options *ResilienceOptions, c clock.Clock) *ResilienceOptions {
+	if c == nil {
+		return options
+	}
+	copied := *options
+	if copied.Retry != nil && copied.Retry.Clock == nil {
+		retry := *copied.Retry
+		retry.Clock = c
+		copied.Retry = &retry
+	}
+	if copied.CircuitBreaker != nil && copied.CircuitBreaker.Clock == nil {
+		breaker := *copied.CircuitBreaker
+		breaker.Clock = c
+		copied.CircuitBreaker = &breaker
+	}
+	if copied.Bulkhead != nil && copied.Bulkhead.Clock == nil {
+		bulkhead := *copied.Bulkhead
+		bulkhead.Clock = c
+		copied.Bulkhead = &bulkhead
+	}
+	if copied.Hedge != nil && copied.Hedge.Clock == nil {
+		hedge := *copied.Hedge
+		hedge.Clock = c
+		copied.Hedge = &hedge
+	}
+	return &copied
+}
+
+// Names returns the names of the registered policies, sorted
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.options))
+	for name := range r.options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultRegistry is the registry behind Get
+var defaultRegistry atomic.Pointer[Registry]
+
+func init() {
+	defaultRegistry.Store(NewRegistry())
+}
+
+// SetDefaultRegistry makes r the registry behind Get
+func SetDefaultRegistry(r *Registry) {
+	defaultRegistry.Store(r)
+}
+
+// Get returns the named policy of the default registry, e.g. resilience.Get("payments")
+func Get(name string) *Resilience {
+	return defaultRegistry.Load().Get(name)
+}
+
+// normalizePolicyName lowercases a policy name, as configuration keys are
+func normalizePolicyName(name string) string {
+	return strings.ToLower(name)
+}
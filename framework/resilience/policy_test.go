@@ -0,0 +1,163 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/testkit/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedExecute(t *testing.T) {
+	type quote struct{ Amount int }
+
+	options := DefaultResilienceOptions()
+	options.Retry = nil
+	r := New(options)
+
+	got, err := Execute(context.Background(), r, func(ctx context.Context) (quote, error) {
+		return quote{Amount: 42}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, quote{Amount: 42}, got)
+
+	_, err = Execute(context.Background(), r, func(ctx context.Context) (*quote, error) {
+		return nil, errors.New("downstream failed")
+	})
+	assert.EqualError(t, err, "downstream failed")
+
+	// Fallbacks must return the same type
+	options.Fallback = &FallbackOptions{FallbackFunc: func(ctx context.Context, err error) (interface{}, error) {
+		return "cached", nil
+	}}
+	r = New(options)
+	_, err = Execute(context.Background(), r, func(ctx context.Context) (quote, error) {
+		return quote{}, errors.New("downstream failed")
+	})
+	assert.ErrorContains(t, err, "result of type string is not a resilience.quote")
+}
+
+func TestRetryClock(t *testing.T) {
+	clk := fake.NewClock(time.Now())
+	options := DefaultResilienceOptions()
+	options.Retry.RetryDelay = time.Minute
+	options.Retry.Clock = clk
+	r := New(options)
+
+	var calls atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+			if calls.Add(1) < 3 {
+				return nil, errors.New("unavailable")
+			}
+			return "ok", nil
+		})
+		done <- err
+	}()
+
+	// Retries wait for the clock, with backoff
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(1), calls.Load())
+	clk.Advance(time.Minute)
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(2), calls.Load())
+	clk.Advance(time.Minute)
+	assert.Equal(t, int32(2), calls.Load(), "the second delay is doubled")
+	clk.Advance(time.Minute)
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestOptionsFromPolicyConfig(t *testing.T) {
+	options := OptionsFromPolicyConfig("payments", config.ResiliencePolicyConfig{})
+	assert.Nil(t, options.Retry, "retries are off unless configured")
+	assert.Nil(t, options.Bulkhead)
+	assert.Nil(t, options.Hedge)
+	assert.Equal(t, 30*time.Second, options.Timeout.Timeout)
+	assert.Equal(t, "payments", options.CircuitBreaker.Name)
+	assert.Equal(t, 5, options.CircuitBreaker.MaxFailures)
+
+	options = OptionsFromPolicyConfig("payments", config.ResiliencePolicyConfig{
+		Timeout:        2000,
+		Retry:          config.RetryPolicyConfig{MaxRetries: 2, Delay: 50},
+		CircuitBreaker: config.CircuitBreakerPolicyConfig{MaxFailures: 3, ResetTimeout: 30},
+		Bulkhead:       config.BulkheadPolicyConfig{MaxConcurrent: 8, QueueTimeout: 250},
+		Hedge:          config.HedgePolicyConfig{Delay: 40},
+	})
+	assert.Equal(t, 2*time.Second, options.Timeout.Timeout)
+	require.NotNil(t, options.Retry)
+	assert.Equal(t, 2, options.Retry.MaxRetries)
+	assert.Equal(t, 50*time.Millisecond, options.Retry.RetryDelay)
+	assert.Equal(t, 2.0, options.Retry.BackoffFactor)
+	assert.Equal(t, 3, options.CircuitBreaker.MaxFailures)
+	assert.Equal(t, 30*time.Second, options.CircuitBreaker.ResetTimeout)
+	assert.Equal(t, &BulkheadOptions{MaxConcurrent: 8, QueueTimeout: 250 * time.Millisecond}, options.Bulkhead)
+	require.NotNil(t, options.Hedge)
+	assert.Equal(t, 40*time.Millisecond, options.Hedge.Delay)
+	assert.Equal(t, 1, options.Hedge.MaxHedges)
+}
+
+func TestPolicyRegistryFromYAML(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "service_default.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`configVersion: 1
+resilience:
+  policies:
+    Payments:
+      timeout: 1500
+      retry:
+        maxRetries: 4
+      circuitBreaker:
+        maxFailures: 2
+`), 0644))
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	registry := NewRegistryFromConfig(cfg.Resilience)
+	assert.Equal(t, []string{"payments"}, registry.Names())
+
+	payments := registry.Get("payments")
+	assert.Same(t, payments, registry.Get("PAYMENTS"), "policies are shared and names are case-insensitive")
+	assert.Equal(t, 1500*time.Millisecond, payments.GetOptions().Timeout.Timeout)
+	assert.Equal(t, 4, payments.GetOptions().Retry.MaxRetries)
+	assert.Equal(t, 2, payments.GetOptions().CircuitBreaker.MaxFailures)
+
+	// Unknown policies use the defaults
+	search := registry.Get("search")
+	assert.Equal(t, DefaultRetryOptions().MaxRetries, search.GetOptions().Retry.MaxRetries)
+	assert.Equal(t, "search", search.GetOptions().CircuitBreaker.Name)
+
+	// The default registry serves Get
+	previous := defaultRegistry.Load()
+	defer SetDefaultRegistry(previous)
+	SetDefaultRegistry(registry)
+	assert.Same(t, payments, Get("Payments"))
+}
+
+func TestPolicyRegistryClock(t *testing.T) {
+	clk := fake.NewClock(time.Now())
+	options := DefaultResilienceOptions()
+	options.Bulkhead = &BulkheadOptions{MaxConcurrent: 1}
+	registry := NewRegistry().WithClock(clk)
+	registry.Register("payments", options)
+
+	policy := registry.Get("payments").GetOptions()
+	assert.Same(t, clk, policy.Retry.Clock)
+	assert.Same(t, clk, policy.CircuitBreaker.Clock)
+	assert.Same(t, clk, policy.Bulkhead.Clock)
+	assert.Nil(t, options.Retry.Clock, "registered options are left unchanged")
+
+	own := fake.NewClock(time.Now())
+	options = DefaultResilienceOptions()
+	options.Retry.Clock = own
+	registry.Register("search", options)
+	assert.Same(t, own, registry.Get("search").GetOptions().Retry.Clock, "options keep their clock")
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/axiomod/axiomod/framework/circuitbreaker"
+	"github.com/axiomod/axiomod/framework/clock"
 )
 
 // Common errors
@@ -28,6 +29,8 @@ type RetryOptions struct {
 	MaxDelay time.Duration
 	// RetryableErrors is a list of errors that should trigger a retry
 	RetryableErrors []error
+	// Clock waits between retries; nil uses the system clock
+	Clock clock.Clock
 }
 
 // DefaultRetryOptions returns the default retry options
@@ -77,6 +80,11 @@ type ResilienceOptions struct {
 	CircuitBreaker *circuitbreaker.Options
 	// Fallback contains fallback options
 	Fallback *FallbackOptions
+	// Bulkhead caps concurrent executions; nil disables it
+	Bulkhead *BulkheadOptions
+	// Hedge starts parallel attempts of slow executions; nil disables it. Only enable it
+	// for functions that are safe to repeat.
+	Hedge *HedgeOptions
 }
 
 // DefaultResilienceOptions returns the default resilience options
@@ -94,6 +102,8 @@ func DefaultResilienceOptions() *ResilienceOptions {
 type Resilience struct {
 	options        *ResilienceOptions
 	circuitBreaker *circuitbreaker.CircuitBreaker
+	bulkhead       *Bulkhead
+	hedger         *Hedger
 }
 
 // New creates a new Resilience instance
@@ -102,10 +112,17 @@ func New(options *ResilienceOptions) *Resilience {
 		options = DefaultResilienceOptions()
 	}
 
-	return &Resilience{
+	r := &Resilience{
 		options:        options,
 		circuitBreaker: circuitbreaker.New(*options.CircuitBreaker),
 	}
+	if options.Bulkhead != nil {
+		r.bulkhead = NewBulkhead(options.Bulkhead)
+	}
+	if options.Hedge != nil {
+		r.hedger = NewHedger(options.Hedge)
+	}
+	return r
 }
 
 // Execute executes a function with resilience patterns
@@ -118,6 +135,18 @@ func (r *Resilience) Execute(ctx context.Context, fn func(ctx context.Context) (
 		return nil, ErrCircuitOpen
 	}
 
+	// Apply bulkhead; the slot is held across retries
+	if r.bulkhead != nil {
+		release, err := r.bulkhead.Acquire(ctx)
+		if err != nil {
+			if r.options.Fallback != nil && r.options.Fallback.FallbackFunc != nil {
+				return r.options.Fallback.FallbackFunc(ctx, err)
+			}
+			return nil, err
+		}
+		defer release()
+	}
+
 	// Apply timeout
 	var timeoutCtx context.Context
 	var cancel context.CancelFunc
@@ -139,8 +168,12 @@ func (r *Resilience) Execute(ctx context.Context, fn func(ctx context.Context) (
 	}
 
 	for {
-		// Execute function
-		result, err = fn(timeoutCtx)
+		// Execute function, hedged if enabled
+		if r.hedger != nil {
+			result, err = r.hedger.Execute(timeoutCtx, fn)
+		} else {
+			result, err = fn(timeoutCtx)
+		}
 
 		// Record result in circuit breaker
 		r.circuitBreaker.RecordResult(err)
@@ -170,7 +203,7 @@ func (r *Resilience) Execute(ctx context.Context, fn func(ctx context.Context) (
 
 		// Wait before retrying
 		select {
-		case <-time.After(delay):
+		case <-clock.OrSystem(r.options.Retry.Clock).After(delay):
 			// Continue to next retry
 		case <-timeoutCtx.Done():
 			if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
@@ -234,6 +267,16 @@ func (r *Resilience) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
 	return r.circuitBreaker
 }
 
+// GetBulkhead returns the bulkhead, or nil if it is disabled
+func (r *Resilience) GetBulkhead() *Bulkhead {
+	return r.bulkhead
+}
+
+// GetHedger returns the hedger, or nil if hedging is disabled
+func (r *Resilience) GetHedger() *Hedger {
+	return r.hedger
+}
+
 // GetOptions returns the resilience options
 func (r *Resilience) GetOptions() *ResilienceOptions {
 	return r.options
@@ -0,0 +1,10 @@
+package router
+
+import (
+	"go.uber.org/fx"
+)
+
+// Module provides the fx options for the router module
+var Module = fx.Options(
+	fx.Provide(NewEventStreams),
+)
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"github.com/axiomod/axiomod/framework/middleware"
 	"github.com/axiomod/axiomod/platform/observability"
 	"time"
 
@@ -11,7 +12,6 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/favicon"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +27,8 @@ type Config struct {
 	CaseSensitive bool
 	// BodyLimit is the maximum allowed size for a request body
 	BodyLimit int
+	// StreamRequestBody hands bodies larger than BodyLimit to handlers as a stream instead of rejecting them
+	StreamRequestBody bool
 	// ReadTimeout is the maximum duration for reading the entire request
 	ReadTimeout int
 	// WriteTimeout is the maximum duration for writing the response
@@ -35,6 +37,9 @@ type Config struct {
 	IdleTimeout int
 	// EnableCORS determines whether to enable CORS
 	EnableCORS bool
+	// Security applies the security headers, the CORS policy and CSRF protection of the HTTP
+	// configuration instead of the default CORS middleware
+	Security *middleware.SecurityMiddleware
 	// EnableCompression determines whether to enable compression
 	EnableCompression bool
 	// EnableETag determines whether to enable ETag
@@ -93,10 +98,18 @@ func New(logger *observability.Logger, config *Config) *Router {
 		ReadTimeout:   time.Duration(config.ReadTimeout) * time.Second,
 		WriteTimeout:  time.Duration(config.WriteTimeout) * time.Second,
 		IdleTimeout:   time.Duration(config.IdleTimeout) * time.Second,
+
+		StreamRequestBody:            config.StreamRequestBody,
+		DisablePreParseMultipartForm: config.StreamRequestBody,
 	})
 
 	// Add middleware
-	if config.EnableCORS {
+	if config.Security != nil {
+		app.Use(config.Security.Headers(), config.Security.CORS())
+		if config.Security.CSRFEnabled() {
+			app.Use(config.Security.CSRF())
+		}
+	} else if config.EnableCORS {
 		app.Use(cors.New())
 	}
 
@@ -121,7 +134,7 @@ func New(logger *observability.Logger, config *Config) *Router {
 	}
 
 	if config.EnableRequestID {
-		app.Use(requestid.New())
+		app.Use(middleware.RequestContext(""))
 	}
 
 	logger.Info("Created router", zap.Bool("prefork", config.Prefork))
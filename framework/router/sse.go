@@ -0,0 +1,330 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Default event stream settings
+const (
+	DefaultHeartbeatInterval = 15 * time.Second
+	DefaultEventBufferSize   = 64
+)
+
+// Common errors
+var (
+	ErrStreamClosed        = errors.New("event stream closed")
+	ErrStreamsShuttingDown = errors.New("event streams are shutting down")
+)
+
+// Event is a Server-Sent Event. Data that is not a string or byte slice is encoded as JSON.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	// Retry overrides the reconnect delay suggested to the client
+	Retry time.Duration
+}
+
+// EventStreamHandler produces the events of a stream. It runs in its own goroutine once the
+// response headers are sent, and the stream ends when it returns.
+type EventStreamHandler func(stream *EventStream) error
+
+// EventStream is a single client's event stream
+type EventStream struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	events      chan Event
+	done        chan struct{}
+	lastEventID string
+	locals      map[string]interface{}
+	params      map[string]string
+}
+
+// Context returns a context that is canceled when the client disconnects or the server shuts down
+func (s *EventStream) Context() context.Context {
+	return s.ctx
+}
+
+// LastEventID returns the ID of the last event a reconnecting client received, or an empty string
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Locals returns a value set on the request context before the stream started, such as "user_id"
+func (s *EventStream) Locals(key string) interface{} {
+	return s.locals[key]
+}
+
+// Params returns a route parameter of the request
+func (s *EventStream) Params(key string) string {
+	return s.params[key]
+}
+
+// Send queues an event, waiting while the buffer is full. It fails with ErrStreamClosed once the
+// stream ends, so a slow client slows the producer down instead of growing memory.
+func (s *EventStream) Send(event Event) error {
+	if s.ctx.Err() != nil {
+		return ErrStreamClosed
+	}
+	select {
+	case s.events <- event:
+		return nil
+	case <-s.ctx.Done():
+		return ErrStreamClosed
+	}
+}
+
+// TrySend queues an event without waiting and reports whether it was queued
+func (s *EventStream) TrySend(event Event) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case s.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// EventStreams serves Server-Sent Event streams and ends them when the server shuts down.
+// Open streams keep their connections busy, so they must end before the HTTP server can stop.
+type EventStreams struct {
+	heartbeat  time.Duration
+	bufferSize int
+	retry      time.Duration
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mu         sync.Mutex
+	count      int
+	closing    bool
+	active     sync.WaitGroup
+	logger     *observability.Logger
+}
+
+// NewEventStreams creates the event stream manager from the SSE configuration
+func NewEventStreams(cfg *config.Config, logger *observability.Logger) *EventStreams {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &EventStreams{
+		heartbeat:  DefaultHeartbeatInterval,
+		bufferSize: DefaultEventBufferSize,
+		retry:      time.Duration(cfg.HTTP.SSE.Retry) * time.Millisecond,
+		ctx:        ctx,
+		cancel:     cancel,
+		logger:     logger,
+	}
+	if cfg.HTTP.SSE.HeartbeatInterval > 0 {
+		m.heartbeat = time.Duration(cfg.HTTP.SSE.HeartbeatInterval) * time.Second
+	}
+	if cfg.HTTP.SSE.BufferSize > 0 {
+		m.bufferSize = cfg.HTTP.SSE.BufferSize
+	}
+	return m
+}
+
+// Count returns the number of open streams
+func (m *EventStreams) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.count
+}
+
+// Handler returns a Fiber handler that streams the events produced by fn to the client.
+// A comment is sent every heartbeat interval so proxies keep idle streams open, and the
+// Last-Event-ID of reconnecting clients is available through EventStream.LastEventID.
+func (m *EventStreams) Handler(fn EventStreamHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stream, err := m.open(c)
+		if err != nil {
+			return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		// Stop reverse proxies such as nginx from buffering the stream
+		c.Set("X-Accel-Buffering", "no")
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer m.close(stream)
+			go func() {
+				defer close(stream.done)
+				if err := fn(stream); err != nil && !errors.Is(err, ErrStreamClosed) {
+					m.logger.Warn("Event stream handler failed", zap.Error(err))
+				}
+			}()
+			m.write(w, stream)
+		})
+		return nil
+	}
+}
+
+// open registers a new stream for the request
+func (m *EventStreams) open(c *fiber.Ctx) (*EventStream, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closing {
+		return nil, ErrStreamsShuttingDown
+	}
+
+	lastEventID := c.Get("Last-Event-ID")
+	if lastEventID == "" {
+		// EventSource polyfills that cannot set headers pass it in the query
+		lastEventID = c.Query("lastEventId")
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	stream := &EventStream{
+		ctx:         ctx,
+		cancel:      cancel,
+		events:      make(chan Event, m.bufferSize),
+		done:        make(chan struct{}),
+		lastEventID: lastEventID,
+		locals:      make(map[string]interface{}),
+		params:      make(map[string]string),
+	}
+	// The request context is recycled once the handler returns, so copy what the producer may need
+	c.Context().VisitUserValues(func(key []byte, value interface{}) {
+		stream.locals[string(key)] = value
+	})
+	for _, name := range c.Route().Params {
+		stream.params[name] = strings.Clone(c.Params(name))
+	}
+
+	m.count++
+	m.active.Add(1)
+	return stream, nil
+}
+
+// close releases a stream once its response ends
+func (m *EventStreams) close(stream *EventStream) {
+	stream.cancel()
+	m.mu.Lock()
+	m.count--
+	m.mu.Unlock()
+	m.active.Done()
+}
+
+// write sends queued events and heartbeats until the producer finishes, the client goes away or
+// the server shuts down
+func (m *EventStreams) write(w *bufio.Writer, stream *EventStream) {
+	if m.retry > 0 {
+		w.WriteString("retry: " + strconv.FormatInt(m.retry.Milliseconds(), 10) + "\n\n")
+	}
+	// Send something right away so the client sees the response headers
+	w.WriteString(": connected\n\n")
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(m.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-stream.events:
+			if err := writeEvent(w, event); err != nil {
+				m.logger.Warn("Dropping event that could not be encoded", zap.String("event", event.Event), zap.Error(err))
+				continue
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		case <-ticker.C:
+			w.WriteString(": heartbeat\n\n")
+			if err := w.Flush(); err != nil {
+				return
+			}
+		case <-stream.done:
+			// Deliver what the producer queued before it returned
+			for {
+				select {
+				case event := <-stream.events:
+					if writeEvent(w, event) == nil {
+						if err := w.Flush(); err != nil {
+							return
+						}
+					}
+				default:
+					return
+				}
+			}
+		case <-stream.ctx.Done():
+			return
+		}
+	}
+}
+
+// writeEvent encodes an event in the text/event-stream format
+func writeEvent(w *bufio.Writer, event Event) error {
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	// Line breaks would end the field early, so they are stripped from single-line fields
+	if event.ID != "" {
+		w.WriteString("id: " + stripLineBreaks(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		w.WriteString("event: " + stripLineBreaks(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		w.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		w.WriteString("data: " + line + "\n")
+	}
+	w.WriteString("\n")
+	return nil
+}
+
+func stripLineBreaks(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// Shutdown stops accepting streams, ends the open ones and waits for their responses to finish
+func (m *EventStreams) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closing = true
+	m.mu.Unlock()
+	m.cancel()
+
+	finished := make(chan struct{})
+	go func() {
+		m.active.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		m.logger.Warn("Event streams did not finish in time", zap.Int("count", m.Count()))
+		return ctx.Err()
+	}
+}
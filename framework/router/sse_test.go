@@ -0,0 +1,161 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startStreams serves handler on a real listener, since event streams outlive app.Test
+func startStreams(t *testing.T, streams *EventStreams, fn EventStreamHandler) string {
+	t.Helper()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	// Compression must not hold events back
+	app.Use(compress.New())
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "alice")
+		return c.Next()
+	})
+	app.Get("/events/:topic", streams.Handler(fn))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+	return "http://" + ln.Addr().String() + "/events/"
+}
+
+// readEvent reads lines up to the next blank line
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func newTestStreams(sse config.SSEConfig) *EventStreams {
+	cfg := &config.Config{HTTP: config.HTTPConfig{SSE: sse}}
+	logger, _ := observability.NewLogger(cfg)
+	return NewEventStreams(cfg, logger)
+}
+
+func TestEventStreams(t *testing.T) {
+	streams := newTestStreams(config.SSEConfig{Retry: 2000})
+	streams.heartbeat = 50 * time.Millisecond
+	release := make(chan struct{})
+	url := startStreams(t, streams, func(stream *EventStream) error {
+		if err := stream.Send(Event{ID: "7", Event: "greeting", Data: "hello\nworld"}); err != nil {
+			return err
+		}
+		<-release
+		return stream.Send(Event{Event: "resume", Data: map[string]string{
+			"topic":   stream.Params("topic"),
+			"user":    stream.Locals("user_id").(string),
+			"resumed": stream.LastEventID(),
+		}})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, url+"orders", nil)
+	req.Header.Set("Last-Event-ID", "6")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, "retry: 2000", readEvent(t, r))
+	assert.Equal(t, ": connected", readEvent(t, r))
+	assert.Equal(t, "id: 7\nevent: greeting\ndata: hello\ndata: world", readEvent(t, r))
+	assert.Equal(t, ": heartbeat", readEvent(t, r), "idle streams get heartbeats")
+	assert.Equal(t, 1, streams.Count())
+
+	close(release)
+	event := readEvent(t, r)
+	for event == ": heartbeat" {
+		event = readEvent(t, r)
+	}
+	assert.Equal(t, `event: resume`+"\n"+`data: {"resumed":"6","topic":"orders","user":"alice"}`, event)
+
+	// The stream ends once the handler returns
+	rest, err := bufio.NewReader(r).ReadString(0)
+	assert.Error(t, err)
+	assert.NotContains(t, rest, "data:")
+	assert.Eventually(t, func() bool { return streams.Count() == 0 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestEventStreamsShutdown(t *testing.T) {
+	streams := newTestStreams(config.SSEConfig{BufferSize: 1})
+	sent := make(chan error, 1)
+	url := startStreams(t, streams, func(stream *EventStream) error {
+		// Block until the stream is canceled
+		<-stream.Context().Done()
+		sent <- stream.Send(Event{Data: "too late"})
+		return nil
+	})
+
+	resp, err := http.Get(url + "orders")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, ": connected", readEvent(t, r))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, streams.Shutdown(ctx))
+	assert.ErrorIs(t, <-sent, ErrStreamClosed)
+	assert.Equal(t, 0, streams.Count())
+
+	resp, err = http.Get(url + "orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestTrySend(t *testing.T) {
+	stream := &EventStream{ctx: context.Background(), events: make(chan Event, 1)}
+	assert.True(t, stream.TrySend(Event{Data: "first"}))
+	assert.False(t, stream.TrySend(Event{Data: "second"}), "a full buffer must not block the producer")
+}
+
+func TestWriteEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"plain data", Event{Data: "ping"}, "data: ping\n\n"},
+		{"bytes", Event{Data: []byte("a\r\nb")}, "data: a\ndata: b\n\n"},
+		{"json", Event{Data: []int{1, 2}}, "data: [1,2]\n\n"},
+		{"fields", Event{ID: "1\n2", Event: "tick", Retry: 3 * time.Second}, "id: 12\nevent: tick\nretry: 3000\ndata: \n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			require.NoError(t, writeEvent(w, tt.event))
+			require.NoError(t, w.Flush())
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"errors"
+
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrAnonymous is returned when exchanging a session without a logged in user for a token
+var ErrAnonymous = errors.New("session: no user is logged in")
+
+// Authenticate returns a Fiber middleware handler that requires a logged in session and
+// stores its claims in the context like the JWT auth middleware, so that role checks and
+// per-user limits apply to browser clients too. It runs after Handle.
+func (m *Manager) Authenticate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return authenticate(c, true)
+	}
+}
+
+// OptionalAuth returns a Fiber middleware handler that stores the claims of logged in
+// sessions in the context and lets anonymous sessions continue. It runs after Handle.
+func (m *Manager) OptionalAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return authenticate(c, false)
+	}
+}
+
+// authenticate stores the claims of the request session in the context
+func authenticate(c *fiber.Ctx, required bool) error {
+	var claims *auth.Claims
+	if s := From(c); s != nil {
+		claims = s.Claims()
+	}
+	if claims == nil {
+		if !required {
+			return c.Next()
+		}
+		return fiber.NewError(fiber.StatusUnauthorized, "not logged in")
+	}
+
+	c.Locals("user_id", claims.UserID)
+	c.Locals("username", claims.Username)
+	c.Locals("email", claims.Email)
+	c.Locals("roles", claims.Roles)
+	c.SetUserContext(ctxkit.WithUserID(c.UserContext(), claims.UserID))
+	return c.Next()
+}
+
+// Token issues a JWT for the user logged in the session, so that browser clients can call
+// APIs authenticated with bearer tokens
+func (s *Session) Token(jwtService *auth.JWTService) (string, error) {
+	claims := s.Claims()
+	if claims == nil {
+		return "", ErrAnonymous
+	}
+	return jwtService.GenerateToken(claims.UserID, claims.Username, claims.Email, claims.Roles)
+}
+
+// LoginWithToken validates a JWT and logs its user in the session, e.g. after a login flow
+// that returned a token. Use the OIDC service to verify ID tokens and SetClaims instead.
+func (s *Session) LoginWithToken(jwtService *auth.JWTService, token string) error {
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		return err
+	}
+	s.SetClaims(claims)
+	return nil
+}
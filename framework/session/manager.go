@@ -0,0 +1,174 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// localsKey is the fiber local holding the session of a request
+const localsKey = "session"
+
+// Options configure the cookie and timeouts of sessions
+type Options struct {
+	CookieName   string // defaults to "session"
+	CookieDomain string
+	CookiePath   string // defaults to "/"
+	SameSite     string // "Lax" (default), "Strict" or "None"
+	Secure       bool   // send the cookie over HTTPS only
+	// IdleTimeout expires sessions unused for longer; defaults to 30 minutes
+	IdleTimeout time.Duration
+	// AbsoluteTimeout expires sessions older than this, however active; defaults to 12 hours
+	AbsoluteTimeout time.Duration
+}
+
+// Manager loads the session of each request from its cookie and saves it once the request
+// is handled
+type Manager struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+}
+
+// NewManager creates a manager keeping sessions in store
+func NewManager(store Store, opts Options) *Manager {
+	if opts.CookieName == "" {
+		opts.CookieName = "session"
+	}
+	if opts.CookiePath == "" {
+		opts.CookiePath = "/"
+	}
+	if opts.SameSite == "" {
+		opts.SameSite = fiber.CookieSameSiteLaxMode
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 30 * time.Minute
+	}
+	if opts.AbsoluteTimeout <= 0 {
+		opts.AbsoluteTimeout = 12 * time.Hour
+	}
+	return &Manager{store: store, opts: opts, now: time.Now}
+}
+
+// From returns the session of the request, or nil when the Handle middleware did not run
+func From(c *fiber.Ctx) *Session {
+	s, _ := c.Locals(localsKey).(*Session)
+	return s
+}
+
+// Handle returns a Fiber middleware handler providing the session of the request to the
+// next handlers through From. Sessions are stored once modified, so anonymous requests do
+// not create any; stored sessions are refreshed as they are used, until they time out.
+func (m *Manager) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		s, err := m.load(c)
+		if err != nil {
+			return err
+		}
+		c.Locals(localsKey, s)
+
+		nextErr := c.Next()
+		if err := m.commit(c, s); err != nil {
+			return err
+		}
+		return nextErr
+	}
+}
+
+// load returns the stored session of the request cookie, or a new session when the cookie
+// is missing or its session has expired
+func (m *Manager) load(c *fiber.Ctx) (*Session, error) {
+	now := m.now()
+	id := c.Cookies(m.opts.CookieName)
+	if id == "" {
+		return newSession(now), nil
+	}
+
+	data, err := m.store.Get(c.UserContext(), storeKey(id))
+	if errors.Is(err, ErrNotFound) {
+		return m.expired(c, now), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if now.Sub(data.LastSeen) > m.opts.IdleTimeout || now.Sub(data.CreatedAt) > m.opts.AbsoluteTimeout {
+		if err := m.store.Delete(c.UserContext(), storeKey(id)); err != nil {
+			return nil, err
+		}
+		return m.expired(c, now), nil
+	}
+	return &Session{id: id, data: *data}, nil
+}
+
+// expired returns the new session replacing the expired session of the request cookie; the
+// cookie is cleared unless the new session is stored
+func (m *Manager) expired(c *fiber.Ctx, now time.Time) *Session {
+	m.clearCookie(c)
+	return newSession(now)
+}
+
+// commit saves the session, or deletes it once destroyed. Unmodified sessions are only saved
+// to refresh them, a tenth of the idle timeout after the previous save.
+func (m *Manager) commit(c *fiber.Ctx, s *Session) error {
+	ctx := c.UserContext()
+	if s.previous != "" {
+		if err := m.store.Delete(ctx, storeKey(s.previous)); err != nil {
+			return err
+		}
+	}
+	if s.destroyed {
+		if !s.isNew {
+			if err := m.store.Delete(ctx, storeKey(s.id)); err != nil {
+				return err
+			}
+			m.clearCookie(c)
+		}
+		return nil
+	}
+
+	now := m.now()
+	refresh := !s.isNew && now.Sub(s.data.LastSeen) >= m.opts.IdleTimeout/10
+	if !s.modified && !refresh {
+		return nil
+	}
+
+	ttl := min(m.opts.IdleTimeout, m.opts.AbsoluteTimeout-now.Sub(s.data.CreatedAt))
+	if ttl <= 0 {
+		return nil
+	}
+	s.data.LastSeen = now
+	if err := m.store.Save(ctx, storeKey(s.id), &s.data, ttl); err != nil {
+		return err
+	}
+	c.Cookie(m.cookie(s.id, now.Add(ttl)))
+	return nil
+}
+
+// cookie returns the session cookie of id, expiring with its session
+func (m *Manager) cookie(id string, expires time.Time) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     m.opts.CookieName,
+		Value:    id,
+		Domain:   m.opts.CookieDomain,
+		Path:     m.opts.CookiePath,
+		Expires:  expires,
+		Secure:   m.opts.Secure,
+		HTTPOnly: true,
+		SameSite: m.opts.SameSite,
+	}
+}
+
+// clearCookie tells the client to delete the session cookie
+func (m *Manager) clearCookie(c *fiber.Ctx) {
+	c.Cookie(m.cookie("", time.Unix(0, 0)))
+}
+
+// storeKey returns the key of a session in the store. Stores only see a hash of the ID, so
+// that reading them does not give away session cookies.
+func storeKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// Backends of sessions
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Module provides the session Manager of the session configuration
+var Module = fx.Options(
+	fx.Provide(ProvideManager),
+)
+
+// ManagerParams holds the dependencies of the manager. The redis backend uses Redis, the
+// client of redis.Module, which the application must then include.
+type ManagerParams struct {
+	fx.In
+
+	Config *config.Config
+	Redis  redis.UniversalClient `optional:"true"`
+}
+
+// ProvideManager creates the manager of the session configuration. Cookies are sent over
+// HTTPS only in production or with TLS enabled.
+func ProvideManager(p ManagerParams) (*Manager, error) {
+	cfg := p.Config.Session
+	sameSite, err := parseSameSite(cfg.CookieSameSite)
+	if err != nil {
+		return nil, err
+	}
+	opts := Options{
+		CookieName:      cfg.CookieName,
+		CookieDomain:    cfg.CookieDomain,
+		CookiePath:      cfg.CookiePath,
+		SameSite:        sameSite,
+		Secure:          p.Config.App.Environment == "production" || p.Config.HTTP.TLS.Enabled,
+		IdleTimeout:     time.Duration(cfg.IdleTimeout) * time.Minute,
+		AbsoluteTimeout: time.Duration(cfg.AbsoluteTimeout) * time.Minute,
+	}
+	if sameSite == fiber.CookieSameSiteNoneMode && !opts.Secure {
+		return nil, fmt.Errorf("session: SameSite=None cookies need HTTPS, in production or with TLS enabled")
+	}
+
+	var store Store
+	switch strings.ToLower(cfg.Backend) {
+	case "", BackendMemory:
+		store = NewMemoryStore()
+	case BackendRedis:
+		if p.Redis == nil {
+			return nil, fmt.Errorf("session: %w", axredis.ErrNoClient)
+		}
+		prefix := cfg.Prefix
+		if prefix == "" {
+			prefix = p.Config.App.Name + ":session"
+		}
+		store = NewRedisStore(p.Redis, prefix)
+	default:
+		return nil, fmt.Errorf("session: unknown backend %q", cfg.Backend)
+	}
+	return NewManager(store, opts), nil
+}
+
+// parseSameSite returns the fiber SameSite mode of a configured value
+func parseSameSite(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "", "lax":
+		return fiber.CookieSameSiteLaxMode, nil
+	case "strict":
+		return fiber.CookieSameSiteStrictMode, nil
+	case "none":
+		return fiber.CookieSameSiteNoneMode, nil
+	default:
+		return "", fmt.Errorf("session: unknown SameSite mode %q", value)
+	}
+}
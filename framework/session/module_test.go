@@ -0,0 +1,103 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		redis   redis.UniversalClient
+		check   func(t *testing.T, m *Manager)
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, m *Manager) {
+				assert.IsType(t, &MemoryStore{}, m.store)
+				assert.Equal(t, Options{
+					CookieName:      "session",
+					CookiePath:      "/",
+					SameSite:        fiber.CookieSameSiteLaxMode,
+					IdleTimeout:     30 * time.Minute,
+					AbsoluteTimeout: 12 * time.Hour,
+				}, m.opts)
+			},
+		},
+		{
+			name: "configured",
+			cfg:  config.Config{Session: config.SessionConfig{CookieName: "sid", CookieSameSite: "Strict", IdleTimeout: 5, AbsoluteTimeout: 60}},
+			check: func(t *testing.T, m *Manager) {
+				assert.Equal(t, "sid", m.opts.CookieName)
+				assert.Equal(t, fiber.CookieSameSiteStrictMode, m.opts.SameSite)
+				assert.Equal(t, 5*time.Minute, m.opts.IdleTimeout)
+				assert.Equal(t, time.Hour, m.opts.AbsoluteTimeout)
+			},
+		},
+		{
+			name: "secure in production",
+			cfg:  config.Config{App: config.AppConfig{Environment: "production"}, Session: config.SessionConfig{CookieSameSite: "None"}},
+			check: func(t *testing.T, m *Manager) {
+				assert.True(t, m.opts.Secure)
+				assert.Equal(t, fiber.CookieSameSiteNoneMode, m.opts.SameSite)
+			},
+		},
+		{
+			name: "secure with TLS",
+			cfg:  config.Config{HTTP: config.HTTPConfig{TLS: config.TLSConfig{Enabled: true}}},
+			check: func(t *testing.T, m *Manager) {
+				assert.True(t, m.opts.Secure)
+			},
+		},
+		{
+			name:  "redis",
+			cfg:   config.Config{App: config.AppConfig{Name: "shop"}, Session: config.SessionConfig{Backend: "Redis"}},
+			redis: redis.NewClient(&redis.Options{Addr: "localhost:0"}),
+			check: func(t *testing.T, m *Manager) {
+				require.IsType(t, &RedisStore{}, m.store)
+				assert.Equal(t, "shop:session:a", m.store.(*RedisStore).key("a"))
+			},
+		},
+		{
+			name:    "redis without a client",
+			cfg:     config.Config{Session: config.SessionConfig{Backend: "redis"}},
+			wantErr: "session: the redis backend needs the client of redis.Module",
+		},
+		{
+			name:    "SameSite=None cookies without HTTPS",
+			cfg:     config.Config{Session: config.SessionConfig{CookieSameSite: "None"}},
+			wantErr: "session: SameSite=None cookies need HTTPS, in production or with TLS enabled",
+		},
+		{
+			name:    "unknown SameSite mode",
+			cfg:     config.Config{Session: config.SessionConfig{CookieSameSite: "loose"}},
+			wantErr: `session: unknown SameSite mode "loose"`,
+		},
+		{
+			name:    "unknown backend",
+			cfg:     config.Config{Session: config.SessionConfig{Backend: "cookie"}},
+			wantErr: `session: unknown backend "cookie"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ProvideManager(ManagerParams{Config: &tt.cfg, Redis: tt.redis})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, m)
+		})
+	}
+}
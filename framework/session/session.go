@@ -0,0 +1,115 @@
+// Package session keeps the state of browser clients in server-side sessions, identified by
+// a secure cookie and stored in memory or in Redis. Sessions carry the identity of logged in
+// users as auth.Claims, so that browser login flows such as OIDC or SAML can authenticate
+// later requests, or exchange the session for a JWT.
+package session
+
+import (
+	"crypto/rand"
+	"errors"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/auth"
+)
+
+// ErrNotFound is returned by stores for sessions that do not exist or have expired
+var ErrNotFound = errors.New("session: not found")
+
+// Data is the stored state of a session
+type Data struct {
+	Values    map[string]string `json:"values,omitempty"`
+	Claims    *auth.Claims      `json:"claims,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	LastSeen  time.Time         `json:"last_seen"`
+}
+
+// Session is the session of a request, saved by the middleware once the request is handled
+type Session struct {
+	id        string
+	data      Data
+	isNew     bool
+	modified  bool
+	destroyed bool
+	previous  string // stored ID replaced by Regenerate, deleted on save
+}
+
+// newSession creates an empty session that is stored once it is modified
+func newSession(now time.Time) *Session {
+	return &Session{
+		id:    newID(),
+		data:  Data{CreatedAt: now, LastSeen: now},
+		isNew: true,
+	}
+}
+
+// newID returns a random session ID of 128 bits
+func newID() string {
+	return rand.Text()
+}
+
+// ID returns the ID of the session, sent in the cookie
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsNew reports whether the session was created by this request
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// CreatedAt returns the time the session was created
+func (s *Session) CreatedAt() time.Time {
+	return s.data.CreatedAt
+}
+
+// Get returns the value of key, or an empty string
+func (s *Session) Get(key string) string {
+	return s.data.Values[key]
+}
+
+// Set sets the value of key
+func (s *Session) Set(key, value string) {
+	if s.data.Values == nil {
+		s.data.Values = make(map[string]string)
+	}
+	s.data.Values[key] = value
+	s.modified = true
+}
+
+// Delete deletes the value of key
+func (s *Session) Delete(key string) {
+	if _, ok := s.data.Values[key]; !ok {
+		return
+	}
+	delete(s.data.Values, key)
+	s.modified = true
+}
+
+// Claims returns the identity of the logged in user, or nil for anonymous sessions
+func (s *Session) Claims() *auth.Claims {
+	return s.data.Claims
+}
+
+// SetClaims logs the user of claims in, or out when claims is nil. The session gets a new ID,
+// so that an ID known before the change of privileges, e.g. planted by an attacker, does not
+// grant them.
+func (s *Session) SetClaims(claims *auth.Claims) {
+	s.data.Claims = claims
+	s.modified = true
+	s.Regenerate()
+}
+
+// Regenerate gives the session a new ID, keeping its values. The old ID stops working once
+// the request is handled.
+func (s *Session) Regenerate() {
+	if !s.isNew && s.previous == "" {
+		s.previous = s.id
+	}
+	s.id = newID()
+	s.modified = true
+}
+
+// Destroy deletes the session and its cookie once the request is handled
+func (s *Session) Destroy() {
+	s.destroyed = true
+}
@@ -0,0 +1,230 @@
+package session
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testApp serves routes reading and changing the session of a manager with a fake clock
+type testApp struct {
+	app   *fiber.App
+	store *MemoryStore
+	now   time.Time
+}
+
+func newTestApp(t *testing.T) *testApp {
+	ta := &testApp{store: NewMemoryStore(), now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	m := NewManager(ta.store, Options{Secure: true, IdleTimeout: 10 * time.Minute, AbsoluteTimeout: time.Hour})
+	m.now = func() time.Time { return ta.now }
+
+	ta.app = fiber.New()
+	ta.app.Use(m.Handle())
+	ta.app.Get("/get", func(c *fiber.Ctx) error {
+		return c.SendString(From(c).Get("cart"))
+	})
+	ta.app.Post("/set", func(c *fiber.Ctx) error {
+		From(c).Set("cart", c.Query("value"))
+		return c.SendStatus(http.StatusNoContent)
+	})
+	ta.app.Post("/login", func(c *fiber.Ctx) error {
+		From(c).SetClaims(&auth.Claims{UserID: "42", Username: "ada", Roles: []string{"admin"}})
+		return c.SendStatus(http.StatusNoContent)
+	})
+	ta.app.Post("/logout", func(c *fiber.Ctx) error {
+		From(c).Destroy()
+		return c.SendStatus(http.StatusNoContent)
+	})
+	ta.app.Get("/me", m.Authenticate(), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user_id": c.Locals("user_id"), "roles": c.Locals("roles")})
+	})
+	ta.app.Get("/maybe", m.OptionalAuth(), func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("user_id").(string)
+		return c.SendString(userID)
+	})
+	return ta
+}
+
+// do sends a request with the session cookie id, returning the response and the cookie set
+func (ta *testApp) do(t *testing.T, method, target, id string) (*http.Response, *http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if id != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: id})
+	}
+	resp, err := ta.app.Test(req)
+	require.NoError(t, err)
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "session" {
+			return resp, cookie
+		}
+	}
+	return resp, nil
+}
+
+func body(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestManager(t *testing.T) {
+	ta := newTestApp(t)
+
+	resp, cookie := ta.do(t, http.MethodGet, "/get", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, cookie, "anonymous requests do not create sessions")
+	assert.Empty(t, ta.store.entries)
+
+	_, cookie = ta.do(t, http.MethodPost, "/set?value=book", "")
+	require.NotNil(t, cookie)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	assert.Equal(t, "/", cookie.Path)
+	id := cookie.Value
+	require.Contains(t, ta.store.entries, storeKey(id))
+	assert.NotContains(t, ta.store.entries, id, "stores only see a hash of the ID")
+
+	resp, cookie = ta.do(t, http.MethodGet, "/get", id)
+	assert.Equal(t, "book", body(t, resp))
+	assert.Nil(t, cookie, "unmodified sessions are not saved on every request")
+
+	resp, _ = ta.do(t, http.MethodGet, "/get", "forged")
+	assert.Empty(t, body(t, resp))
+}
+
+func TestManagerTimeouts(t *testing.T) {
+	t.Run("refreshed while used", func(t *testing.T) {
+		ta := newTestApp(t)
+		_, cookie := ta.do(t, http.MethodPost, "/set?value=book", "")
+		id := cookie.Value
+
+		for range 8 {
+			ta.now = ta.now.Add(5 * time.Minute)
+			resp, cookie := ta.do(t, http.MethodGet, "/get", id)
+			require.Equal(t, "book", body(t, resp))
+			require.NotNil(t, cookie, "used sessions are refreshed")
+			assert.Equal(t, id, cookie.Value)
+		}
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		ta := newTestApp(t)
+		_, cookie := ta.do(t, http.MethodPost, "/set?value=book", "")
+		id := cookie.Value
+
+		ta.now = ta.now.Add(11 * time.Minute)
+		// The memory store would drop the entry itself; keep it to test the manager check
+		ta.store.entries[storeKey(id)] = memoryEntry{data: ta.store.entries[storeKey(id)].data, expires: time.Now().Add(time.Hour)}
+		resp, cookie := ta.do(t, http.MethodGet, "/get", id)
+		assert.Empty(t, body(t, resp))
+		require.NotNil(t, cookie)
+		assert.Empty(t, cookie.Value, "the expired cookie is cleared")
+		assert.NotContains(t, ta.store.entries, storeKey(id))
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		ta := newTestApp(t)
+		_, cookie := ta.do(t, http.MethodPost, "/set?value=book", "")
+		id := cookie.Value
+
+		for range 6 {
+			ta.now = ta.now.Add(9 * time.Minute)
+			ta.do(t, http.MethodGet, "/get", id)
+		}
+		ta.now = ta.now.Add(7 * time.Minute)
+		ta.store.entries[storeKey(id)] = memoryEntry{data: ta.store.entries[storeKey(id)].data, expires: time.Now().Add(time.Hour)}
+		resp, _ := ta.do(t, http.MethodGet, "/get", id)
+		assert.Empty(t, body(t, resp), "active sessions still expire after the absolute timeout")
+	})
+}
+
+func TestManagerLogin(t *testing.T) {
+	ta := newTestApp(t)
+	_, cookie := ta.do(t, http.MethodPost, "/set?value=book", "")
+	anonymous := cookie.Value
+
+	resp, _ := ta.do(t, http.MethodGet, "/me", anonymous)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, _ = ta.do(t, http.MethodGet, "/maybe", anonymous)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, body(t, resp))
+
+	_, cookie = ta.do(t, http.MethodPost, "/login", anonymous)
+	require.NotNil(t, cookie)
+	loggedIn := cookie.Value
+	assert.NotEqual(t, anonymous, loggedIn, "logging in rotates the session ID")
+	assert.NotContains(t, ta.store.entries, storeKey(anonymous))
+
+	resp, _ = ta.do(t, http.MethodGet, "/me", loggedIn)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"user_id":"42","roles":["admin"]}`, body(t, resp))
+	resp, _ = ta.do(t, http.MethodGet, "/get", loggedIn)
+	assert.Equal(t, "book", body(t, resp), "values survive the rotation")
+	resp, _ = ta.do(t, http.MethodGet, "/me", anonymous)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the old ID no longer works")
+
+	_, cookie = ta.do(t, http.MethodPost, "/logout", loggedIn)
+	require.NotNil(t, cookie)
+	assert.Empty(t, cookie.Value)
+	assert.Empty(t, ta.store.entries)
+	resp, _ = ta.do(t, http.MethodGet, "/me", loggedIn)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestSessionToken(t *testing.T) {
+	jwtService := auth.NewJWTService("secret", time.Hour)
+	s := newSession(time.Now())
+
+	_, err := s.Token(jwtService)
+	assert.ErrorIs(t, err, ErrAnonymous)
+
+	token, err := jwtService.GenerateToken("42", "ada", "ada@example.com", []string{"admin"})
+	require.NoError(t, err)
+	require.NoError(t, s.LoginWithToken(jwtService, token))
+	assert.Equal(t, "ada", s.Claims().Username)
+
+	issued, err := s.Token(jwtService)
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateToken(issued)
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims.UserID)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+
+	assert.Error(t, s.LoginWithToken(jwtService, "invalid"))
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	_, err := store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	data := &Data{Values: map[string]string{"cart": "book"}, Claims: &auth.Claims{UserID: "42"}}
+	require.NoError(t, store.Save(ctx, "a", data, time.Minute))
+	data.Values["cart"] = "changed"
+	got, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "book", got.Values["cart"], "stored data is a copy")
+	assert.Equal(t, "42", got.Claims.UserID)
+
+	require.NoError(t, store.Save(ctx, "b", data, -time.Second))
+	_, err = store.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
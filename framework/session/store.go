@@ -0,0 +1,132 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps session data by key until it expires
+type Store interface {
+	// Get returns the data of key, or ErrNotFound
+	Get(ctx context.Context, key string) (*Data, error)
+	// Save stores data under key for ttl
+	Save(ctx context.Context, key string, data *Data, ttl time.Duration) error
+	// Delete deletes the data of key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// memoryEntry is a session kept by a MemoryStore
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// MemoryStore keeps sessions in the memory of the process. Sessions are lost on restart and
+// not shared between replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastPrune time.Time
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the data of key, or ErrNotFound
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Data, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		return nil, ErrNotFound
+	}
+
+	var data Data
+	if err := json.Unmarshal(entry.data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Save stores data under key for ttl, and removes the expired sessions once a minute
+func (s *MemoryStore) Save(ctx context.Context, key string, data *Data, ttl time.Duration) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastPrune) >= time.Minute {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastPrune = now
+	}
+	s.entries[key] = memoryEntry{data: encoded, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete deletes the data of key
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// RedisStore keeps sessions in Redis under a key prefix, shared by every replica
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a store keeping sessions under prefix
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Get returns the data of key, or ErrNotFound
+func (s *RedisStore) Get(ctx context.Context, key string) (*Data, error) {
+	encoded, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data Data
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Save stores data under key for ttl
+func (s *RedisStore) Save(ctx context.Context, key string, data *Data, ttl time.Duration) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key(key), encoded, ttl).Err()
+}
+
+// Delete deletes the data of key
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+func (s *RedisStore) key(key string) string {
+	return s.prefix + ":" + key
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrInvalidSignature is returned for presigned URLs that are tampered with or expired
+var ErrInvalidSignature = errors.New("storage: invalid or expired signature")
+
+// tempPrefix prefixes the files uploads are written to before they are complete
+const tempPrefix = ".upload-"
+
+// LocalStore keeps objects as files under a directory. Content types are detected from the
+// extension of keys. Presigned URLs are served by the routes of RegisterRoutes.
+type LocalStore struct {
+	root    string
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocalStore creates a store keeping objects under dir, which is created on the first
+// upload
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{root: dir, now: time.Now}
+}
+
+// WithPresign enables presigned URLs under baseURL, where the routes of RegisterRoutes are
+// mounted, signed with secret
+func (s *LocalStore) WithPresign(baseURL, secret string) *LocalStore {
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+	s.secret = []byte(secret)
+	return s
+}
+
+// path returns the file of key
+func (s *LocalStore) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes body to a temporary file renamed to the file of key once complete, so that
+// readers never see partial uploads
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("storage: failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), tempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("storage: failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, contextReader{ctx: ctx, r: body}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens the file of key
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, notFound(key, err)
+	}
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		f.Close()
+		return nil, nil, notFound(key, err)
+	}
+	return f, fileInfo(key, stat), nil
+}
+
+// Stat describes the file of key
+func (s *LocalStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(file)
+	if err != nil || stat.IsDir() {
+		return nil, notFound(key, err)
+	}
+	return fileInfo(key, stat), nil
+}
+
+// Delete removes the file of key and the directories it leaves empty
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete %q: %w", key, err)
+	}
+	root := filepath.Clean(s.root)
+	for dir := filepath.Dir(file); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List walks the directory of the prefix, listing the files after the cursor
+func (s *LocalStore) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	// Only the directory holding the prefix needs to be walked
+	dir := s.root
+	if i := strings.LastIndex(opts.Prefix, "/"); i > 0 {
+		if err := ValidateKey(opts.Prefix[:i]); err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(s.root, filepath.FromSlash(opts.Prefix[:i]))
+	}
+
+	var keys []string
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), tempPrefix) {
+			return ctx.Err()
+		}
+		rel, err := filepath.Rel(s.root, file)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, opts.Prefix) && key > opts.Cursor {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to list %q: %w", opts.Prefix, err)
+	}
+	sort.Strings(keys)
+
+	result := &ListResult{}
+	if len(keys) > limit {
+		keys = keys[:limit]
+		result.Next = keys[limit-1]
+	}
+	for _, key := range keys {
+		info, err := s.Stat(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Objects = append(result.Objects, *info)
+	}
+	return result, nil
+}
+
+// PresignGet returns a signed URL of the download route
+func (s *LocalStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(fiber.MethodGet, key, expires)
+}
+
+// PresignPut returns a signed URL of the upload route
+func (s *LocalStore) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(fiber.MethodPut, key, expires)
+}
+
+func (s *LocalStore) presign(method, key string, expires time.Duration) (string, error) {
+	if s.baseURL == "" || len(s.secret) == 0 {
+		return "", ErrPresignUnsupported
+	}
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	expiry := strconv.FormatInt(s.now().Add(expires).Unix(), 10)
+	query := url.Values{"expires": {expiry}, "signature": {s.signature(method, key, expiry)}}
+	return s.baseURL + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// signature signs the method, key and expiry of a presigned URL
+func (s *LocalStore) signature(method, key, expiry string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and expiry of a presigned URL for method and key with query
+func (s *LocalStore) Verify(method, key string, query url.Values) error {
+	if len(s.secret) == 0 {
+		return ErrPresignUnsupported
+	}
+	expiry := query.Get("expires")
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || s.now().Unix() > unix {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(s.signature(method, key, expiry))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// RegisterRoutes registers the routes presigned URLs point to, to be mounted at the path of
+// the base URL
+func (s *LocalStore) RegisterRoutes(router fiber.Router) {
+	router.Get("/*", s.serveGet)
+	router.Put("/*", s.servePut)
+}
+
+// serveGet downloads the object of a presigned URL
+func (s *LocalStore) serveGet(c *fiber.Ctx) error {
+	key, err := s.verifyRequest(c)
+	if err != nil {
+		return err
+	}
+	body, info, err := s.Get(c.UserContext(), key)
+	if errors.Is(err, ErrNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "object not found")
+	}
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, info.ContentType)
+	c.Set(fiber.HeaderLastModified, info.LastModified.UTC().Format(time.RFC1123))
+	return c.SendStream(body, int(info.Size))
+}
+
+// servePut uploads the object of a presigned URL, streaming the body when the server
+// streams request bodies
+func (s *LocalStore) servePut(c *fiber.Ctx) error {
+	key, err := s.verifyRequest(c)
+	if err != nil {
+		return err
+	}
+	body := c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	if err := s.Put(c.UserContext(), key, body, PutOptions{}); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// verifyRequest returns the key of a request to a presigned URL
+func (s *LocalStore) verifyRequest(c *fiber.Ctx) (string, error) {
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil || ValidateKey(key) != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "invalid key")
+	}
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil || s.Verify(c.Method(), key, query) != nil {
+		return "", fiber.NewError(fiber.StatusForbidden, "invalid or expired signature")
+	}
+	return key, nil
+}
+
+// fileInfo describes the file of key
+func fileInfo(key string, stat fs.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  contentType(key, ""),
+		LastModified: stat.ModTime(),
+	}
+}
+
+// notFound returns ErrNotFound for missing files, and err otherwise
+func notFound(key string, err error) error {
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return fmt.Errorf("storage: failed to open %q: %w", key, err)
+}
+
+// contentType returns the content type given, or the one of the extension of key
+func contentType(key, given string) string {
+	if given != "" {
+		return given
+	}
+	if detected := mime.TypeByExtension(path.Ext(key)); detected != "" {
+		return detected
+	}
+	return "application/octet-stream"
+}
+
+// contextReader stops reading when its context is done, e.g. when a client disconnects
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocalStore(dir)
+
+	require.NoError(t, store.Put(ctx, "users/42/avatar.png", strings.NewReader("png"), PutOptions{}))
+	require.NoError(t, store.Put(ctx, "users/42/avatar.png", strings.NewReader("new png"), PutOptions{}))
+	require.NoError(t, store.Put(ctx, "users/7/cv.pdf", strings.NewReader("pdf"), PutOptions{}))
+	require.NoError(t, store.Put(ctx, "users-export.csv", strings.NewReader("csv"), PutOptions{}))
+
+	body, info, err := store.Get(ctx, "users/42/avatar.png")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "new png", string(data))
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.Equal(t, int64(7), info.Size)
+
+	info, err = store.Stat(ctx, "users/7/cv.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", info.ContentType)
+
+	_, _, err = store.Get(ctx, "users/1/missing.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Stat(ctx, "users")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Put(ctx, "../escape", strings.NewReader(""), PutOptions{}), ErrInvalidKey)
+
+	t.Run("list", func(t *testing.T) {
+		keys := func(result *ListResult) []string {
+			var keys []string
+			for _, object := range result.Objects {
+				keys = append(keys, object.Key)
+			}
+			return keys
+		}
+
+		result, err := store.List(ctx, ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"users-export.csv", "users/42/avatar.png", "users/7/cv.pdf"}, keys(result))
+		assert.Empty(t, result.Next)
+
+		result, err = store.List(ctx, ListOptions{Prefix: "users/", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"users/42/avatar.png"}, keys(result))
+		result, err = store.List(ctx, ListOptions{Prefix: "users/", Limit: 1, Cursor: result.Next})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"users/7/cv.pdf"}, keys(result))
+		assert.Empty(t, result.Next)
+
+		result, err = store.List(ctx, ListOptions{Prefix: "orders/"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Objects)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, "users/7/cv.pdf"))
+		require.NoError(t, store.Delete(ctx, "users/7/cv.pdf"))
+		_, err := os.Stat(filepath.Join(dir, "users", "7"))
+		assert.True(t, os.IsNotExist(err), "empty directories are removed")
+		_, err = os.Stat(dir)
+		assert.NoError(t, err, "the root is kept")
+	})
+}
+
+func TestLocalStorePutCanceled(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStore(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := store.Put(ctx, "big.bin", strings.NewReader("data"), PutOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "partial uploads are removed")
+}
+
+func TestLocalStorePresign(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir())
+	_, err := store.PresignGet(ctx, "a.txt", time.Minute)
+	assert.ErrorIs(t, err, ErrPresignUnsupported)
+
+	store.WithPresign("http://localhost/files/", "secret")
+	app := fiber.New()
+	store.RegisterRoutes(app.Group("/files"))
+	request := func(method, rawURL, body string) *http.Response {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		resp, err := app.Test(httptest.NewRequest(method, u.RequestURI(), strings.NewReader(body)))
+		require.NoError(t, err)
+		return resp
+	}
+
+	putURL, err := store.PresignPut(ctx, "docs/report 1.txt", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(putURL, "http://localhost/files/docs/report%201.txt?expires="))
+	assert.Equal(t, http.StatusOK, request(http.MethodPut, putURL, "report").StatusCode)
+
+	getURL, err := store.PresignGet(ctx, "docs/report 1.txt", time.Minute)
+	require.NoError(t, err)
+	resp := request(http.MethodGet, getURL, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "report", string(data))
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		status int
+	}{
+		{name: "signed for another method", method: http.MethodGet, url: putURL, status: http.StatusForbidden},
+		{name: "signed for another key", method: http.MethodGet, url: strings.Replace(getURL, "report%201", "report%202", 1), status: http.StatusForbidden},
+		{name: "unsigned", method: http.MethodGet, url: "http://localhost/files/docs/report%201.txt", status: http.StatusForbidden},
+		{name: "invalid key", method: http.MethodGet, url: "http://localhost/files/docs/..%2F..%2Fsecret", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, request(tt.method, tt.url, "").StatusCode)
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		expiredURL, err := store.PresignGet(ctx, "docs/report 1.txt", -time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, request(http.MethodGet, expiredURL, "").StatusCode)
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		missingURL, err := store.PresignGet(ctx, "docs/missing.txt", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, missingURL, "").StatusCode)
+	})
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore keeps objects in memory, for tests and for development on a single instance.
+// Its presigned URLs use the memory scheme: they cannot be downloaded, only compared.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	clock   clock.Clock
+}
+
+// memoryObject is an object of a MemoryStore with its description
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		objects: make(map[string]memoryObject),
+		clock:   clock.System,
+	}
+}
+
+// WithClock sets the clock telling the modification times of objects and the expiry of
+// presigned URLs
+func (s *MemoryStore) WithClock(c clock.Clock) *MemoryStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock.OrSystem(c)
+	return s
+}
+
+// Put reads body and stores it under key once complete
+func (s *MemoryStore) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(contextReader{ctx: ctx, r: body})
+	if err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	sum := md5.Sum(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryObject{
+		data: data,
+		info: ObjectInfo{
+			Key:          key,
+			Size:         int64(len(data)),
+			ContentType:  contentType(key, opts.ContentType),
+			ETag:         hex.EncodeToString(sum[:]),
+			LastModified: s.clock.Now(),
+		},
+	}
+	return nil
+}
+
+// Get opens the object of key
+func (s *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	object, err := s.object(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.NopCloser(bytes.NewReader(object.data)), &object.info, nil
+}
+
+// Stat describes the object of key
+func (s *MemoryStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	object, err := s.object(key)
+	if err != nil {
+		return nil, err
+	}
+	return &object.info, nil
+}
+
+// object returns the object of key
+func (s *MemoryStore) object(key string) (memoryObject, error) {
+	if err := ValidateKey(key); err != nil {
+		return memoryObject{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return memoryObject{}, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return object, nil
+}
+
+// Delete deletes the object of key
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// List lists the objects of the prefix after the cursor
+func (s *MemoryStore) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, opts.Prefix) && key > opts.Cursor {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := &ListResult{}
+	if len(keys) > limit {
+		keys = keys[:limit]
+		result.Next = keys[limit-1]
+	}
+	for _, key := range keys {
+		result.Objects = append(result.Objects, s.objects[key].info)
+	}
+	return result, nil
+}
+
+// PresignGet returns a memory URL of the download of key
+func (s *MemoryStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign("GET", key, expires)
+}
+
+// PresignPut returns a memory URL of the upload of key
+func (s *MemoryStore) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign("PUT", key, expires)
+}
+
+func (s *MemoryStore) presign(method, key string, expires time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	expiry := strconv.FormatInt(s.clock.Now().Add(expires).Unix(), 10)
+	s.mu.RUnlock()
+	query := url.Values{"method": {method}, "expires": {expiry}}
+	return "memory:///" + escapeKey(key) + "?" + query.Encode(), nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/testkit/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore().WithClock(fake.NewClock(now))
+
+	require.NoError(t, store.Put(ctx, "users/42/avatar.png", strings.NewReader("png"), PutOptions{}))
+	require.NoError(t, store.Put(ctx, "users/42/avatar.png", strings.NewReader("new png"), PutOptions{}))
+	require.NoError(t, store.Put(ctx, "users/7/cv.pdf", strings.NewReader("pdf"), PutOptions{ContentType: "application/x-cv"}))
+	require.NoError(t, store.Put(ctx, "users-export.csv", strings.NewReader("csv"), PutOptions{}))
+
+	body, info, err := store.Get(ctx, "users/42/avatar.png")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "new png", string(data))
+	assert.Equal(t, ObjectInfo{
+		Key:          "users/42/avatar.png",
+		Size:         7,
+		ContentType:  "image/png",
+		ETag:         "5be9d35bdf2a630c7dfaff854145b078",
+		LastModified: now,
+	}, *info)
+
+	info, err = store.Stat(ctx, "users/7/cv.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-cv", info.ContentType)
+
+	_, _, err = store.Get(ctx, "users/1/missing.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Put(ctx, "../escape", strings.NewReader(""), PutOptions{}), ErrInvalidKey)
+
+	result, err := store.List(ctx, ListOptions{Prefix: "users/", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, "users/42/avatar.png", result.Objects[0].Key)
+	result, err = store.List(ctx, ListOptions{Prefix: "users/", Limit: 1, Cursor: result.Next})
+	require.NoError(t, err)
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, "users/7/cv.pdf", result.Objects[0].Key)
+	assert.Empty(t, result.Next)
+
+	presigned, err := store.PresignGet(ctx, "users/7/cv.pdf", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "memory:///users/7/cv.pdf?expires=1775037600&method=GET", presigned)
+
+	require.NoError(t, store.Delete(ctx, "users/7/cv.pdf"))
+	require.NoError(t, store.Delete(ctx, "users/7/cv.pdf"))
+	_, err = store.Stat(ctx, "users/7/cv.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Backends of stores
+const (
+	BackendLocal  = "local"
+	BackendS3     = "s3"
+	BackendMemory = "memory"
+)
+
+// defaultDir is the directory of the local store when none is configured
+const defaultDir = "storage"
+
+// Module provides the Store of the configured backend
+var Module = fx.Options(
+	fx.Provide(ProvideStore),
+)
+
+// StoreParams holds the dependencies of the store
+type StoreParams struct {
+	fx.In
+
+	Config *config.Config
+	Logger *observability.Logger
+}
+
+// ProvideStore creates the store of the configured backend
+func ProvideStore(params StoreParams) (Store, error) {
+	cfg := params.Config.Storage
+	switch strings.ToLower(cfg.Backend) {
+	case "", BackendLocal:
+		dir := cfg.Local.Dir
+		if dir == "" {
+			dir = defaultDir
+		}
+		store := NewLocalStore(dir)
+		if cfg.Local.BaseURL != "" {
+			if cfg.Local.Secret == "" {
+				return nil, fmt.Errorf("storage: presigned URLs of the local backend need a secret")
+			}
+			store.WithPresign(cfg.Local.BaseURL, cfg.Local.Secret)
+		}
+		params.Logger.Debug("Storing objects in a local directory", zap.String("dir", dir))
+		return store, nil
+	case BackendS3:
+		store, err := NewS3Store(cfg.S3)
+		if err != nil {
+			return nil, err
+		}
+		params.Logger.Debug("Storing objects in an s3 bucket", zap.String("bucket", cfg.S3.Bucket))
+		return store, nil
+	case BackendMemory:
+		params.Logger.Debug("Storing objects in memory")
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+}
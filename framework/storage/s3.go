@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/crypto"
+)
+
+const (
+	// defaultPartSize is the size of the parts of multipart uploads, in MB
+	defaultPartSize = 8
+	// minPartSize is the smallest part S3 accepts, in MB
+	minPartSize = 5
+	// maxPresignExpiry is the longest validity of presigned URLs S3 accepts
+	maxPresignExpiry = 7 * 24 * time.Hour
+	// abortTimeout bounds the cleanup of failed multipart uploads
+	abortTimeout = 30 * time.Second
+)
+
+// S3Store keeps objects in a bucket of an S3-compatible service, such as Amazon S3, MinIO or
+// Google Cloud Storage with HMAC keys. Requests are signed with AWS Signature Version 4.
+type S3Store struct {
+	bucket      string
+	region      string
+	endpoint    *url.URL
+	pathStyle   bool
+	credentials crypto.AWSCredentials
+	partSize    int
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewS3Store creates a store of the bucket of cfg. Credentials missing from cfg are read from
+// the AWS environment variables.
+func NewS3Store(cfg config.StorageS3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage: the s3 backend needs a bucket")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(rawEndpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", rawEndpoint)
+	}
+	partSize := cfg.PartSize
+	if partSize == 0 {
+		partSize = defaultPartSize
+	}
+	if partSize < minPartSize {
+		return nil, fmt.Errorf("storage: s3 parts must be at least %d MB", minPartSize)
+	}
+
+	s := &S3Store{
+		bucket:    cfg.Bucket,
+		region:    region,
+		endpoint:  endpoint,
+		pathStyle: cfg.PathStyle,
+		credentials: crypto.AWSCredentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+		},
+		partSize: partSize << 20,
+		client:   &http.Client{},
+		now:      time.Now,
+	}
+	if s.credentials.AccessKeyID == "" {
+		s.credentials = crypto.AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	if s.credentials.AccessKeyID == "" || s.credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("storage: the s3 backend needs an access key ID and a secret access key")
+	}
+	return s, nil
+}
+
+// url returns the URL of key, or of the bucket if key is empty
+func (s *S3Store) url(key string) string {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path += "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	return u.String() + "/" + escapeKey(key)
+}
+
+// s3Error is the error document of S3 responses
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request, turning unsuccessful responses into errors. The caller closes
+// the body of the response.
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.url(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		req.URL.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payloadHash := crypto.HashSHA256Hex(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	crypto.SignAWSV4(req, payloadHash, s.credentials, s.region, "s3", s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 %s %q failed: %w", method, key, err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var doc s3Error
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = xml.Unmarshal(data, &doc)
+	if resp.StatusCode == http.StatusNotFound && (doc.Code == "" || doc.Code == "NoSuchKey") {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return nil, fmt.Errorf("storage: s3 %s %q responded %d %s: %s", method, key, resp.StatusCode, doc.Code, doc.Message)
+}
+
+// Put uploads body in a single request if it fits in a part, and as a multipart upload
+// otherwise, holding one part in memory at a time
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": {contentType(key, opts.ContentType)}}
+
+	body = contextReader{ctx: ctx, r: body}
+	part := make([]byte, s.partSize)
+	n, err := io.ReadFull(body, part)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		resp, err := s.do(ctx, http.MethodPut, key, nil, header, part[:n])
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage: failed to read %q: %w", key, err)
+	}
+	return s.putMultipart(ctx, key, header, body, part)
+}
+
+// multipartUpload is the response to the creation of a multipart upload
+type multipartUpload struct {
+	UploadID string `xml:"UploadId"`
+}
+
+// completedPart is an uploaded part of a multipart upload
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// completeMultipartUpload is the request completing a multipart upload
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// putMultipart uploads the full first part, then the rest of body, in parts. Failed uploads
+// are aborted so that their parts are not kept.
+func (s *S3Store) putMultipart(ctx context.Context, key string, header http.Header, body io.Reader, part []byte) (err error) {
+	var upload multipartUpload
+	if err := s.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil, &upload); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+			defer cancel()
+			if resp, abortErr := s.do(abortCtx, http.MethodDelete, key, url.Values{"uploadId": {upload.UploadID}}, nil, nil); abortErr == nil {
+				resp.Body.Close()
+			}
+		}
+	}()
+
+	var completed completeMultipartUpload
+	n := len(part)
+	for number := 1; n > 0; number++ {
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {upload.UploadID}}
+		resp, err := s.do(ctx, http.MethodPut, key, query, nil, part[:n])
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		completed.Parts = append(completed.Parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		n, err = io.ReadFull(body, part)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("storage: failed to read %q: %w", key, err)
+		}
+	}
+
+	data, err := xml.Marshal(completed)
+	if err != nil {
+		return err
+	}
+	// Completing can fail after the response status was sent, with an error document
+	var result struct {
+		XMLName xml.Name
+		s3Error
+	}
+	if err := s.doXML(ctx, http.MethodPost, key, url.Values{"uploadId": {upload.UploadID}}, nil, data, &result); err != nil {
+		return err
+	}
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("storage: s3 failed to complete the upload of %q: %s: %s", key, result.Code, result.Message)
+	}
+	return nil
+}
+
+// doXML sends a request and decodes its XML response into v
+func (s *S3Store) doXML(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte, v interface{}) error {
+	resp, err := s.do(ctx, method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("storage: invalid s3 response to %s %q: %w", method, key, err)
+	}
+	return nil
+}
+
+// Get downloads the object of key
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, objectInfo(key, resp), nil
+}
+
+// Stat requests the headers of the object of key
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return objectInfo(key, resp), nil
+}
+
+// Delete deletes the object of key
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listBucketResult is the response to a ListObjectsV2 request
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List lists a page of objects with ListObjectsV2. Listings do not have content types.
+func (s *S3Store) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	query := url.Values{"list-type": {"2"}, "max-keys": {strconv.Itoa(limit)}}
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Cursor != "" {
+		query.Set("continuation-token", opts.Cursor)
+	}
+
+	var list listBucketResult
+	if err := s.doXML(ctx, http.MethodGet, "", query, nil, nil, &list); err != nil {
+		return nil, err
+	}
+	result := &ListResult{Objects: make([]ObjectInfo, 0, len(list.Contents))}
+	for _, object := range list.Contents {
+		result.Objects = append(result.Objects, ObjectInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			ETag:         strings.Trim(object.ETag, `"`),
+			LastModified: object.LastModified,
+		})
+	}
+	if list.IsTruncated {
+		result.Next = list.NextContinuationToken
+	}
+	return result, nil
+}
+
+// PresignGet returns a presigned URL downloading the object of key
+func (s *S3Store) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, expires)
+}
+
+// PresignPut returns a presigned URL uploading the object of key
+func (s *S3Store) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, expires)
+}
+
+func (s *S3Store) presign(method, key string, expires time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	if expires < time.Second || expires > maxPresignExpiry {
+		return "", fmt.Errorf("storage: presigned URLs expire within 1 second to 7 days, not %s", expires)
+	}
+	req, err := http.NewRequest(method, s.url(key), nil)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PresignAWSV4(req, s.credentials, s.region, "s3", s.now(), expires), nil
+}
+
+// objectInfo describes the object of key from the headers of a response
+func objectInfo(key string, resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = modified
+	}
+	return info
+}
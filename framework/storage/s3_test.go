@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is an in-memory S3 bucket served with path-style addressing. It checks that
+// requests are signed and their payload hash matches their body.
+type fakeS3 struct {
+	mu        sync.Mutex
+	bucket    string
+	objects   map[string][]byte
+	types     map[string]string
+	uploads   map[string]map[int][]byte
+	requests  []string
+	failParts bool
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{
+		bucket:  "uploads",
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+		uploads: make(map[string]map[int][]byte),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=id/") ||
+		r.Header.Get("X-Amz-Content-Sha256") != crypto.HashSHA256Hex(body) {
+		f.fail(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+f.bucket), "/")
+	query := r.URL.Query()
+	f.requests = append(f.requests, r.Method+" "+key+" "+r.URL.RawQuery)
+
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		f.list(w, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = make(map[int][]byte)
+		f.types[key] = r.Header.Get("Content-Type")
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		if f.failParts {
+			f.fail(w, http.StatusServiceUnavailable, "SlowDown")
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][number] = body
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete completeMultipartUpload
+		if err := xml.Unmarshal(body, &complete); err != nil {
+			f.fail(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var data []byte
+		for _, part := range complete.Parts {
+			data = append(data, f.uploads[query.Get("uploadId")][part.PartNumber]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, query.Get("uploadId"))
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("ETag", etag(data))
+		w.Header().Set("Last-Modified", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	limit, _ := strconv.Atoi(query.Get("max-keys"))
+	truncated := len(keys) > limit
+	if truncated {
+		keys = keys[:limit]
+	}
+
+	fmt.Fprint(w, "<ListBucketResult>")
+	for _, key := range keys {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size><ETag>%s</ETag><LastModified>2024-05-01T12:00:00.000Z</LastModified></Contents>",
+			key, len(f.objects[key]), etag(f.objects[key]))
+	}
+	if truncated {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[len(keys)-1])
+	}
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+func (f *fakeS3) fail(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, http.StatusText(status))
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func newTestS3Store(t *testing.T, endpoint string) *S3Store {
+	store, err := NewS3Store(config.StorageS3Config{
+		Bucket:          "uploads",
+		Endpoint:        endpoint,
+		PathStyle:       true,
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+		PartSize:        minPartSize,
+	})
+	require.NoError(t, err)
+	return store
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeS3(t)
+	store := newTestS3Store(t, server.URL)
+
+	require.NoError(t, store.Put(ctx, "users/42/avatar.png", strings.NewReader("png"), PutOptions{}))
+	require.NoError(t, store.Put(ctx, "docs/report 1.txt", strings.NewReader("report"), PutOptions{ContentType: "text/markdown"}))
+	assert.Equal(t, "image/png", fake.types["users/42/avatar.png"])
+	assert.Equal(t, "text/markdown", fake.types["docs/report 1.txt"])
+
+	body, info, err := store.Get(ctx, "docs/report 1.txt")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "report", string(data))
+	assert.Equal(t, &ObjectInfo{
+		Key:          "docs/report 1.txt",
+		Size:         6,
+		ContentType:  "text/markdown",
+		ETag:         strings.Trim(etag([]byte("report")), `"`),
+		LastModified: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}, info)
+
+	info, err = store.Stat(ctx, "users/42/avatar.png")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), info.Size)
+
+	_, _, err = store.Get(ctx, "missing.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Stat(ctx, "missing.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	result, err := store.List(ctx, ListOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, "docs/report 1.txt", result.Objects[0].Key)
+	result, err = store.List(ctx, ListOptions{Limit: 1, Cursor: result.Next})
+	require.NoError(t, err)
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, "users/42/avatar.png", result.Objects[0].Key)
+	assert.Empty(t, result.Next)
+
+	result, err = store.List(ctx, ListOptions{Prefix: "docs/"})
+	require.NoError(t, err)
+	assert.Len(t, result.Objects, 1)
+
+	require.NoError(t, store.Delete(ctx, "docs/report 1.txt"))
+	assert.NotContains(t, fake.objects, "docs/report 1.txt")
+}
+
+func TestS3StoreMultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeS3(t)
+	store := newTestS3Store(t, server.URL)
+
+	data := bytes.Repeat([]byte("0123456789"), store.partSize/10*2+1)
+	require.NoError(t, store.Put(ctx, "backup.tar", bytes.NewReader(data), PutOptions{ContentType: "application/x-tar"}))
+	assert.Equal(t, data, fake.objects["backup.tar"])
+	assert.Equal(t, "application/x-tar", fake.types["backup.tar"])
+	assert.Equal(t, []string{
+		"POST backup.tar uploads=",
+		"PUT backup.tar partNumber=1&uploadId=1",
+		"PUT backup.tar partNumber=2&uploadId=1",
+		"PUT backup.tar partNumber=3&uploadId=1",
+		"POST backup.tar uploadId=1",
+	}, fake.requests)
+
+	t.Run("aborted on failure", func(t *testing.T) {
+		fake.requests = nil
+		fake.failParts = true
+		err := store.Put(ctx, "backup2.tar", bytes.NewReader(data), PutOptions{})
+		assert.ErrorContains(t, err, "SlowDown")
+		assert.Equal(t, "DELETE backup2.tar uploadId=1", fake.requests[len(fake.requests)-1])
+		assert.Empty(t, fake.uploads)
+	})
+}
+
+func TestS3StorePresign(t *testing.T) {
+	store := newTestS3Store(t, "http://localhost:9000")
+	store.now = func() time.Time {
+		return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	signed, err := store.PresignPut(context.Background(), "docs/report 1.txt", time.Hour)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/docs/report%201.txt", u.EscapedPath())
+	assert.Equal(t, "id/20240501/us-east-1/s3/aws4_request", u.Query().Get("X-Amz-Credential"))
+	assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+	assert.Len(t, u.Query().Get("X-Amz-Signature"), 64)
+
+	_, err = store.PresignGet(context.Background(), "docs/report 1.txt", 8*24*time.Hour)
+	assert.ErrorContains(t, err, "presigned URLs expire within 1 second to 7 days")
+}
+
+func TestNewS3Store(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	tests := []struct {
+		name    string
+		cfg     config.StorageS3Config
+		wantURL string
+		wantErr string
+	}{
+		{
+			name:    "virtual-hosted bucket",
+			cfg:     config.StorageS3Config{Bucket: "uploads", Region: "eu-west-1", AccessKeyID: "id", SecretAccessKey: "secret"},
+			wantURL: "https://uploads.s3.eu-west-1.amazonaws.com/a%20b.txt",
+		},
+		{
+			name:    "path-style bucket",
+			cfg:     config.StorageS3Config{Bucket: "uploads", Endpoint: "http://minio:9000/", PathStyle: true, AccessKeyID: "id", SecretAccessKey: "secret"},
+			wantURL: "http://minio:9000/uploads/a%20b.txt",
+		},
+		{
+			name:    "no credentials",
+			cfg:     config.StorageS3Config{Bucket: "uploads"},
+			wantErr: "storage: the s3 backend needs an access key ID and a secret access key",
+		},
+		{
+			name:    "small parts",
+			cfg:     config.StorageS3Config{Bucket: "uploads", PartSize: 1},
+			wantErr: "storage: s3 parts must be at least 5 MB",
+		},
+		{
+			name:    "invalid endpoint",
+			cfg:     config.StorageS3Config{Bucket: "uploads", Endpoint: "minio"},
+			wantErr: `storage: invalid s3 endpoint "minio"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewS3Store(tt.cfg)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURL, store.url("a b.txt"))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package storage keeps files, such as uploads, in an object store: the local filesystem or
+// an S3-compatible service such as Amazon S3, MinIO or Google Cloud Storage.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Common errors
+var (
+	// ErrNotFound is returned for objects that do not exist
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey is returned for keys that are empty, absolute or escape their prefix
+	ErrInvalidKey = errors.New("storage: invalid key")
+	// ErrPresignUnsupported is returned by stores that cannot presign URLs, such as a local
+	// store without a base URL
+	ErrPresignUnsupported = errors.New("storage: presigned URLs are not supported")
+)
+
+// defaultListLimit is the number of objects listed when ListOptions.Limit is not set
+const defaultListLimit = 1000
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string // not set in the listings of S3 stores
+	ETag         string
+	LastModified time.Time
+}
+
+// PutOptions are the options of an upload
+type PutOptions struct {
+	ContentType string // detected from the key extension when empty
+}
+
+// ListOptions select the objects listed
+type ListOptions struct {
+	Prefix string
+	Cursor string // Next of the previous page
+	Limit  int    // defaults to 1000
+}
+
+// ListResult is a page of objects, sorted by key
+type ListResult struct {
+	Objects []ObjectInfo
+	Next    string // cursor of the next page; empty on the last page
+}
+
+// Store keeps objects by key. Keys are slash-separated paths such as "avatars/42.png".
+type Store interface {
+	// Put stores the content of body under key, replacing any object there. Bodies are
+	// streamed, so uploads of any size can be stored without holding them in memory.
+	Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error
+	// Get opens the object of key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Stat describes the object of key
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete deletes the object of key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// List lists the objects whose key starts with a prefix, a page at a time
+	List(ctx context.Context, opts ListOptions) (*ListResult, error)
+	// PresignGet returns a URL clients download the object of key from, valid for expires
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+	// PresignPut returns a URL clients upload the object of key to with a PUT request,
+	// valid for expires
+	PresignPut(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// ValidateKey checks that key is a relative slash-separated path without "." or ".."
+// segments, so that it stays within its store
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// escapeKey escapes the segments of key for a URL path, leaving only unreserved characters
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
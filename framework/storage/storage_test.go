@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{key: "avatar.png", valid: true},
+		{key: "users/42/avatar.png", valid: true},
+		{key: "reports/2024 Q1 (final).pdf", valid: true},
+		{key: ""},
+		{key: "/etc/passwd"},
+		{key: "users/"},
+		{key: "users//avatar.png"},
+		{key: "../secrets"},
+		{key: "users/../../secrets"},
+		{key: "users/./avatar.png"},
+		{key: `users\avatar.png`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := ValidateKey(tt.key)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidKey)
+			}
+		})
+	}
+}
+
+func TestEscapeKey(t *testing.T) {
+	assert.Equal(t, "users/42/avatar_v1.png", escapeKey("users/42/avatar_v1.png"))
+	assert.Equal(t, "reports/2024%20Q1%20%28final%29%2Bdraft.pdf", escapeKey("reports/2024 Q1 (final)+draft.pdf"))
+	assert.Equal(t, "caf%C3%A9.txt", escapeKey("café.txt"))
+}
+
+func TestProvideStore(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	tests := []struct {
+		name    string
+		storage config.StorageConfig
+		check   func(t *testing.T, store Store)
+		wantErr string
+	}{
+		{
+			name: "local by default",
+			check: func(t *testing.T, store Store) {
+				require.IsType(t, &LocalStore{}, store)
+				assert.Equal(t, defaultDir, store.(*LocalStore).root)
+			},
+		},
+		{
+			name:    "local with presigned URLs",
+			storage: config.StorageConfig{Local: config.StorageLocalConfig{Dir: "files", BaseURL: "http://localhost/files/", Secret: "s"}},
+			check: func(t *testing.T, store Store) {
+				assert.Equal(t, "http://localhost/files", store.(*LocalStore).baseURL)
+			},
+		},
+		{
+			name:    "local presigned URLs without secret",
+			storage: config.StorageConfig{Local: config.StorageLocalConfig{BaseURL: "http://localhost/files"}},
+			wantErr: "storage: presigned URLs of the local backend need a secret",
+		},
+		{
+			name: "s3",
+			storage: config.StorageConfig{Backend: "S3", S3: config.StorageS3Config{
+				Bucket:          "uploads",
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+			}},
+			check: func(t *testing.T, store Store) {
+				require.IsType(t, &S3Store{}, store)
+				assert.Equal(t, "https://uploads.s3.us-east-1.amazonaws.com/a.txt", store.(*S3Store).url("a.txt"))
+			},
+		},
+		{
+			name:    "s3 without bucket",
+			storage: config.StorageConfig{Backend: "s3"},
+			wantErr: "storage: the s3 backend needs a bucket",
+		},
+		{
+			name:    "memory",
+			storage: config.StorageConfig{Backend: "memory"},
+			check: func(t *testing.T, store Store) {
+				require.IsType(t, &MemoryStore{}, store)
+			},
+		},
+		{
+			name:    "unknown backend",
+			storage: config.StorageConfig{Backend: "tape"},
+			wantErr: `storage: unknown backend "tape"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := ProvideStore(StoreParams{Config: &config.Config{Storage: tt.storage}, Logger: logger})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, store)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package containers starts real dependencies of integration tests in Docker with
+// testcontainers-go: Postgres, Kafka, Redis and Keycloak. Each is started once per test
+// binary, the first time a test asks for it, and shared by the tests after it. Its Configure
+// method points a configuration at it:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(containers.Run(m))
+//	}
+//
+//	func TestOrders(t *testing.T) {
+//		pg := containers.StartPostgres(t)
+//		app := testkit.StartTestApp(t, orders.Module, testkit.WithConfig(pg.Configure))
+//		// ...
+//	}
+//
+// Tests asking for a dependency are skipped when Docker is not available.
+package containers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/axiomodtest"
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// StartTimeout bounds how long a dependency may take to start and be ready
+const StartTimeout = 3 * time.Minute
+
+// Dependency is a started dependency
+type Dependency interface {
+	// Configure points cfg at the dependency
+	Configure(cfg *config.Config)
+}
+
+// Config returns axiomodtest.DefaultConfig pointed at deps
+func Config(deps ...Dependency) *config.Config {
+	cfg := axiomodtest.DefaultConfig()
+	for _, dep := range deps {
+		dep.Configure(cfg)
+	}
+	return cfg
+}
+
+// shared is a dependency started once per test binary
+type shared struct {
+	once      sync.Once
+	dep       any
+	container testcontainers.Container
+	err       error
+}
+
+var (
+	mu      sync.Mutex
+	started = make(map[string]*shared)
+
+	dockerOnce sync.Once
+	dockerErr  error
+)
+
+// start returns the dependency of key, started with run by the first test asking for it.
+// The test is skipped without Docker, and fails if the dependency cannot start.
+func start[T any](t testing.TB, key string, run func(ctx context.Context) (T, testcontainers.Container, error)) T {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	mu.Lock()
+	s, ok := started[key]
+	if !ok {
+		s = &shared{}
+		started[key] = s
+	}
+	mu.Unlock()
+
+	s.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+		defer cancel()
+		s.dep, s.container, s.err = run(ctx)
+	})
+	if s.err != nil {
+		t.Fatalf("failed to start %s: %v", key, s.err)
+	}
+	return s.dep.(T)
+}
+
+// skipWithoutDocker skips the test when Docker is not available
+func skipWithoutDocker(t testing.TB) {
+	t.Helper()
+	dockerOnce.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				dockerErr = fmt.Errorf("%v", r)
+			}
+		}()
+		provider, err := testcontainers.ProviderDocker.GetProvider()
+		if err != nil {
+			dockerErr = err
+			return
+		}
+		defer provider.Close()
+		dockerErr = provider.Health(context.Background())
+	})
+	if dockerErr != nil {
+		t.Skipf("Skipping container test; Docker is not available: %v", dockerErr)
+	}
+}
+
+// Run runs the tests of m and then terminates the dependencies they started, returning the
+// exit code of the tests. Without it, dependencies are removed when the test binary exits.
+func Run(m *testing.M) int {
+	code := m.Run()
+	if err := Terminate(context.Background()); err != nil {
+		fmt.Printf("failed to terminate containers: %v\n", err)
+	}
+	return code
+}
+
+// Terminate stops and removes the dependencies started by the tests
+func Terminate(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	var firstErr error
+	for key, s := range started {
+		if s.container != nil {
+			if err := s.container.Terminate(ctx); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		delete(started, key)
+	}
+	return firstErr
+}
@@ -0,0 +1,89 @@
+package containers
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(Run(m))
+}
+
+func TestConfig(t *testing.T) {
+	cfg := Config(
+		&Postgres{Host: "localhost", Port: 32768, User: "u", Password: "p", Database: "d"},
+		&Redis{Host: "localhost", Port: 32769},
+		&Kafka{Brokers: []string{"localhost:32770"}},
+		&Keycloak{URL: "http://localhost:32771", Realm: "shop"},
+	)
+
+	assert.Equal(t, config.DatabaseConfig{
+		Driver: "postgres", Host: "localhost", Port: 32768, User: "u", Password: "p", Name: "d", SSLMode: "disable",
+	}, cfg.Database)
+	assert.Equal(t, "standalone", cfg.Redis.Mode)
+	assert.Equal(t, "localhost:32769", cfg.Redis.Addr)
+	assert.Equal(t, []string{"localhost:32770"}, cfg.Kafka.Brokers)
+	assert.Equal(t, "http://localhost:32771/realms/shop", cfg.Auth.OIDC.IssuerURL)
+	assert.Equal(t, "axiomodtest", cfg.App.Name, "based on the default test configuration")
+}
+
+func TestPostgresDSN(t *testing.T) {
+	pg := &Postgres{Host: "localhost", Port: 32768, User: "u", Password: "p", Database: "d"}
+	assert.Equal(t, "postgres://u:p@localhost:32768/d?sslmode=disable", pg.DSN())
+}
+
+func TestRealmName(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "shop.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"realm": "shop", "clients": []}`), 0o644))
+	unnamed := filepath.Join(dir, "unnamed.json")
+	require.NoError(t, os.WriteFile(unnamed, []byte(`{}`), 0o644))
+
+	name, err := realmName(valid)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", name)
+
+	_, err = realmName(unnamed)
+	assert.Error(t, err)
+	_, err = realmName(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestStartPostgres(t *testing.T) {
+	pg := StartPostgres(t)
+	assert.Same(t, pg, StartPostgres(t), "shared by the tests")
+
+	db, err := sql.Open("postgres", pg.DSN())
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.PingContext(context.Background()))
+}
+
+func TestStartRedis(t *testing.T) {
+	r := StartRedis(t)
+	client := redis.NewClient(&redis.Options{Addr: r.Addr()})
+	defer client.Close()
+	require.NoError(t, client.Ping(context.Background()).Err())
+}
+
+func TestStartKafka(t *testing.T) {
+	k := StartKafka(t)
+	assert.NotEmpty(t, k.Brokers)
+}
+
+func TestStartKeycloak(t *testing.T) {
+	kc := StartKeycloak(t, "")
+	token, err := kc.Token(context.Background(), "admin-cli", "", kc.AdminUser, kc.AdminPassword)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+}
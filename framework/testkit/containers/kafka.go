@@ -0,0 +1,41 @@
+package containers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/kafka"
+)
+
+// KafkaImage is the image of StartKafka, a single KRaft broker
+var KafkaImage = "confluentinc/confluent-local:7.5.0"
+
+// Kafka is a started Kafka broker
+type Kafka struct {
+	Brokers []string
+}
+
+// StartKafka returns the Kafka broker of the test binary, started when ready to accept
+// clients. Its topics are shared by the tests: use topics and consumer groups of their own.
+func StartKafka(t testing.TB) *Kafka {
+	t.Helper()
+	return start(t, "kafka", func(ctx context.Context) (*Kafka, testcontainers.Container, error) {
+		ctr, err := kafka.Run(ctx, KafkaImage, kafka.WithClusterID("axiomod"))
+		if ctr == nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, ctr, err
+		}
+		brokers, err := ctr.Brokers(ctx)
+		return &Kafka{Brokers: brokers}, ctr, err
+	})
+}
+
+// Configure points the Kafka configuration at the broker
+func (k *Kafka) Configure(cfg *config.Config) {
+	cfg.Kafka.Brokers = append([]string(nil), k.Brokers...)
+}
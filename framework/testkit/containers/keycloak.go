@@ -0,0 +1,149 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// KeycloakImage is the image of StartKeycloak
+var KeycloakImage = "quay.io/keycloak/keycloak:26.0"
+
+// Keycloak is a started Keycloak server, issuing the tokens of a realm
+type Keycloak struct {
+	// URL is the address of the server, such as "http://localhost:32768"
+	URL string
+	// Realm is the realm of the issuer, "master" unless one was imported
+	Realm string
+	// AdminUser and AdminPassword are the administrator of the master realm
+	AdminUser     string
+	AdminPassword string
+}
+
+// StartKeycloak returns the Keycloak server of the test binary importing realmFile, an export
+// of a realm with its clients and users, or the master realm when it is empty. The server is
+// started in development mode, when the realm is served.
+func StartKeycloak(t testing.TB, realmFile string) *Keycloak {
+	t.Helper()
+
+	realm := "master"
+	var imports []testcontainers.ContainerCustomizer
+	if realmFile != "" {
+		name, err := realmName(realmFile)
+		if err != nil {
+			t.Fatalf("invalid Keycloak realm: %v", err)
+		}
+		realm = name
+		imports = append(imports,
+			testcontainers.WithFiles(testcontainers.ContainerFile{
+				HostFilePath:      realmFile,
+				ContainerFilePath: "/opt/keycloak/data/import/" + filepath.Base(realmFile),
+				FileMode:          0o644,
+			}),
+			testcontainers.WithCmdArgs("--import-realm"),
+		)
+	}
+
+	return start(t, "keycloak "+realmFile, func(ctx context.Context) (*Keycloak, testcontainers.Container, error) {
+		kc := &Keycloak{Realm: realm, AdminUser: "admin", AdminPassword: "admin"}
+		opts := append([]testcontainers.ContainerCustomizer{
+			testcontainers.WithExposedPorts("8080/tcp"),
+			testcontainers.WithEnv(map[string]string{
+				"KC_BOOTSTRAP_ADMIN_USERNAME": kc.AdminUser,
+				"KC_BOOTSTRAP_ADMIN_PASSWORD": kc.AdminPassword,
+			}),
+			testcontainers.WithCmd("start-dev"),
+			testcontainers.WithWaitStrategy(
+				wait.ForHTTP("/realms/" + realm).WithPort("8080/tcp").WithStartupTimeout(StartTimeout),
+			),
+		}, imports...)
+		ctr, err := testcontainers.Run(ctx, KeycloakImage, opts...)
+		if ctr == nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, ctr, err
+		}
+		host, port, err := endpoint(ctx, ctr, "8080/tcp")
+		kc.URL = fmt.Sprintf("http://%s:%d", host, port)
+		return kc, ctr, err
+	})
+}
+
+// realmName returns the name of the realm exported to file
+func realmName(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	var export struct {
+		Realm string `json:"realm"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	if export.Realm == "" {
+		return "", fmt.Errorf("%s: no realm", file)
+	}
+	return export.Realm, nil
+}
+
+// IssuerURL returns the OIDC issuer of the realm
+func (k *Keycloak) IssuerURL() string {
+	return k.URL + "/realms/" + k.Realm
+}
+
+// Configure points the OIDC configuration at the realm. Tokens of every client of the realm
+// are accepted unless ClientID or Audiences are set after it.
+func (k *Keycloak) Configure(cfg *config.Config) {
+	cfg.Auth.OIDC.IssuerURL = k.IssuerURL()
+	cfg.Auth.OIDC.Issuers = nil
+}
+
+// Token returns an access token of a user of the realm, granted to clientID with the
+// password grant. clientSecret is empty for public clients, such as "admin-cli" of the master
+// realm.
+func (k *Keycloak) Token(ctx context.Context, clientID, clientSecret, username, password string) (string, error) {
+	form := url.Values{
+		"grant_type": {"password"},
+		"client_id":  {clientID},
+		"username":   {username},
+		"password":   {password},
+	}
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.IssuerURL()+"/protocol/openid-connect/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, token.ErrorDescription)
+	}
+	return token.AccessToken, nil
+}
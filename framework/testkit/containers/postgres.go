@@ -0,0 +1,80 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// PostgresImage is the image of StartPostgres
+var PostgresImage = "postgres:16-alpine"
+
+// Postgres is a started Postgres server
+type Postgres struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+}
+
+// StartPostgres returns the Postgres server of the test binary, started when ready to accept
+// connections. Its database is shared by the tests: isolate them with testkit.Postgres,
+// setting testkit.PostgresDSNEnv to DSN.
+func StartPostgres(t testing.TB) *Postgres {
+	t.Helper()
+	return start(t, "postgres", func(ctx context.Context) (*Postgres, testcontainers.Container, error) {
+		pg := &Postgres{User: "axiomod", Password: "axiomod", Database: "axiomod"}
+		ctr, err := postgres.Run(ctx, PostgresImage,
+			postgres.WithUsername(pg.User),
+			postgres.WithPassword(pg.Password),
+			postgres.WithDatabase(pg.Database),
+			postgres.BasicWaitStrategies(),
+		)
+		if ctr == nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, ctr, err
+		}
+		pg.Host, pg.Port, err = endpoint(ctx, ctr, "5432/tcp")
+		return pg, ctr, err
+	})
+}
+
+// DSN returns the connection string of the database
+func (p *Postgres) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable", p.User, p.Password, p.Host, p.Port, p.Database)
+}
+
+// Configure points the database configuration at the server
+func (p *Postgres) Configure(cfg *config.Config) {
+	cfg.Database.Driver = "postgres"
+	cfg.Database.Host = p.Host
+	cfg.Database.Port = p.Port
+	cfg.Database.User = p.User
+	cfg.Database.Password = p.Password
+	cfg.Database.Name = p.Database
+	cfg.Database.SSLMode = "disable"
+}
+
+// endpoint returns the host and mapped port of a port of a container
+func endpoint(ctx context.Context, ctr testcontainers.Container, port string) (string, int, error) {
+	host, err := ctr.Host(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	mapped, err := ctr.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		return "", 0, err
+	}
+	n, err := strconv.Atoi(mapped.Port())
+	return host, n, err
+}
@@ -0,0 +1,52 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// RedisImage is the image of StartRedis
+var RedisImage = "redis:7-alpine"
+
+// Redis is a started Redis server
+type Redis struct {
+	Host string
+	Port int
+}
+
+// StartRedis returns the Redis server of the test binary, started when ready to accept
+// connections. Its keys are shared by the tests: use keys of their own, or a cache prefix.
+func StartRedis(t testing.TB) *Redis {
+	t.Helper()
+	return start(t, "redis", func(ctx context.Context) (*Redis, testcontainers.Container, error) {
+		ctr, err := redis.Run(ctx, RedisImage)
+		if ctr == nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, ctr, err
+		}
+		r := &Redis{}
+		r.Host, r.Port, err = endpoint(ctx, ctr, "6379/tcp")
+		return r, ctr, err
+	})
+}
+
+// Addr returns the address of the server, such as "localhost:32768"
+func (r *Redis) Addr() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+// Configure points the Redis configuration at the server, in the standalone mode
+func (r *Redis) Configure(cfg *config.Config) {
+	cfg.Redis.Mode = "standalone"
+	cfg.Redis.Addr = r.Addr()
+	cfg.Redis.Addrs = nil
+	cfg.Redis.TLS = false
+}
@@ -0,0 +1,230 @@
+// Package fake provides fakes of the interfaces of the framework for unit tests: a Clock
+// whose time only moves when told to, an in-memory Kafka broker and an HTTP transport
+// recording requests and answering them with stubs. storage.MemoryStore and
+// cache.MemoryCache are the fakes of blob stores and caches.
+package fake
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+)
+
+var _ clock.Clock = (*Clock)(nil)
+
+// Clock is a clock.Clock whose time only moves with Advance and Set. Timers, tickers and
+// functions of AfterFunc fire when their time is reached, in the order of their times.
+type Clock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a timer, ticker or function of AfterFunc waiting for its time
+type waiter struct {
+	at     time.Time
+	period time.Duration // of tickers
+	ch     chan time.Time
+	fn     func()
+}
+
+// NewClock creates a clock at now
+func NewClock(now time.Time) *Clock {
+	c := &Clock{now: now}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since returns the time elapsed since t on the clock
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After returns a channel receiving the time once d passed on the clock
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a timer firing once d passed on the clock
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	t := &timer{clock: c, w: &waiter{ch: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// AfterFunc calls f once d passed on the clock: right away in its own goroutine if d is not
+// positive, and otherwise from the Advance or Set reaching its time, before they return
+func (c *Clock) AfterFunc(d time.Duration, f func()) clock.Timer {
+	t := &timer{clock: c, w: &waiter{fn: f}}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker creates a ticker firing every d on the clock
+func (c *Clock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("fake: non-positive interval for NewTicker")
+	}
+	t := &ticker{clock: c, w: &waiter{ch: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing what waits for the times it passes
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing what waits for the times it passes. The clock does not
+// move backwards.
+func (c *Clock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		w := c.next(t)
+		if w == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		if w.at.After(c.now) {
+			c.now = w.at
+		}
+		now := c.now
+		c.remove(w)
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+			c.add(w)
+		}
+		c.mu.Unlock()
+		fire(w, now)
+	}
+}
+
+// BlockUntil waits until n timers, tickers and functions of AfterFunc wait on the clock,
+// e.g. for a goroutine under test to start its timer before the test calls Advance
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+// Waiters returns the number of timers, tickers and functions of AfterFunc waiting
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// next returns the first waiter due at t, nil if there is none
+func (c *Clock) next(t time.Time) *waiter {
+	if len(c.waiters) == 0 || c.waiters[0].at.After(t) {
+		return nil
+	}
+	return c.waiters[0]
+}
+
+// add makes w wait for its time, keeping the waiters sorted by time
+func (c *Clock) add(w *waiter) {
+	c.remove(w)
+	i := sort.Search(len(c.waiters), func(i int) bool { return c.waiters[i].at.After(w.at) })
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+	c.changed.Broadcast()
+}
+
+// remove stops w from waiting, reporting whether it was waiting
+func (c *Clock) remove(w *waiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fire sends now on the channel of w, dropping it if the last one was not received, or
+// calls its function
+func fire(w *waiter, now time.Time) {
+	if w.fn != nil {
+		w.fn()
+		return
+	}
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+type timer struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *timer) C() <-chan time.Time { return t.w.ch }
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t.w)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	active := c.remove(t.w)
+	t.w.at = c.now.Add(d)
+	if d > 0 {
+		c.add(t.w)
+		c.mu.Unlock()
+		return active
+	}
+	now := c.now
+	c.mu.Unlock()
+	if t.w.fn != nil {
+		go t.w.fn()
+	} else {
+		fire(t.w, now)
+	}
+	return active
+}
+
+type ticker struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *ticker) C() <-chan time.Time { return t.w.ch }
+
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.w)
+}
+
+func (t *ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("fake: non-positive interval for Ticker.Reset")
+	}
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.w.period = d
+	t.w.at = c.now.Add(d)
+	c.add(t.w)
+}
@@ -0,0 +1,95 @@
+package fake
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("time moves when told to", func(t *testing.T) {
+		c := NewClock(start)
+		assert.Equal(t, start, c.Now())
+		c.Advance(time.Minute)
+		assert.Equal(t, time.Minute, c.Since(start))
+		c.Set(start)
+		assert.Equal(t, start.Add(time.Minute), c.Now(), "the clock does not move backwards")
+	})
+
+	t.Run("timers", func(t *testing.T) {
+		c := NewClock(start)
+		timer := c.NewTimer(time.Second)
+		after := c.After(2 * time.Second)
+		assert.Equal(t, 2, c.Waiters())
+
+		c.Advance(999 * time.Millisecond)
+		assert.Empty(t, timer.C())
+		c.Advance(time.Millisecond)
+		assert.Equal(t, start.Add(time.Second), <-timer.C())
+		assert.False(t, timer.Stop(), "fired timers are not active")
+
+		assert.False(t, timer.Reset(time.Second))
+		assert.True(t, timer.Stop())
+		c.Advance(time.Second)
+		assert.Equal(t, start.Add(2*time.Second), <-after)
+		assert.Empty(t, timer.C(), "stopped timers do not fire")
+		assert.Zero(t, c.Waiters())
+
+		immediate := c.NewTimer(0)
+		assert.Equal(t, c.Now(), <-immediate.C())
+	})
+
+	t.Run("tickers", func(t *testing.T) {
+		c := NewClock(start)
+		ticker := c.NewTicker(time.Second)
+		c.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Second), <-ticker.C())
+		c.Advance(3 * time.Second)
+		assert.Equal(t, start.Add(2*time.Second), <-ticker.C(), "ticks are dropped while one waits")
+		assert.Empty(t, ticker.C())
+
+		ticker.Reset(time.Minute)
+		c.Advance(time.Second)
+		assert.Empty(t, ticker.C())
+		ticker.Stop()
+		c.Advance(time.Hour)
+		assert.Empty(t, ticker.C())
+	})
+
+	t.Run("functions run in the order of their times", func(t *testing.T) {
+		c := NewClock(start)
+		var order []string
+		c.AfterFunc(2*time.Second, func() { order = append(order, "second") })
+		c.AfterFunc(time.Second, func() {
+			order = append(order, "first")
+			// Functions may use the clock
+			c.AfterFunc(500*time.Millisecond, func() { order = append(order, "nested") })
+		})
+		stopped := c.AfterFunc(time.Second, func() { order = append(order, "stopped") })
+		assert.True(t, stopped.Stop())
+
+		c.Advance(time.Hour)
+		assert.Equal(t, []string{"first", "nested", "second"}, order)
+	})
+
+	t.Run("functions due right away run in their own goroutine", func(t *testing.T) {
+		c := NewClock(start)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		c.AfterFunc(0, wg.Done)
+		wg.Wait()
+	})
+
+	t.Run("BlockUntil waits for timers", func(t *testing.T) {
+		c := NewClock(start)
+		fired := make(chan time.Time)
+		go func() { fired <- <-c.After(time.Minute) }()
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+		assert.Equal(t, start.Add(time.Minute), <-fired)
+	})
+}
@@ -0,0 +1,93 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/kafka"
+)
+
+var (
+	_ kafka.Publisher  = (*Kafka)(nil)
+	_ kafka.Subscriber = (*Kafka)(nil)
+)
+
+// Kafka is an in-memory Kafka broker, producer and consumer at once. It keeps the messages
+// published to it and delivers them to the handlers registered for their topic.
+type Kafka struct {
+	mu       sync.Mutex
+	messages []*kafka.Message
+	offsets  map[string]int64
+	handlers map[string][]kafka.MessageHandler
+	clock    clock.Clock
+}
+
+// NewKafka creates a broker without messages
+func NewKafka() *Kafka {
+	return &Kafka{
+		offsets:  make(map[string]int64),
+		handlers: make(map[string][]kafka.MessageHandler),
+		clock:    clock.System,
+	}
+}
+
+// WithClock sets the clock telling the timestamps of messages
+func (k *Kafka) WithClock(c clock.Clock) *Kafka {
+	k.clock = c
+	return k
+}
+
+// Publish keeps a message and delivers it to the handlers of its topic before returning. The
+// first error of the handlers is returned, so that tests see messages failing to be processed.
+func (k *Kafka) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	k.mu.Lock()
+	message := &kafka.Message{
+		Topic:     topic,
+		Key:       key,
+		Value:     append([]byte(nil), value...),
+		Offset:    k.offsets[topic],
+		Timestamp: k.clock.Now(),
+	}
+	k.offsets[topic]++
+	k.messages = append(k.messages, message)
+	handlers := append([]kafka.MessageHandler(nil), k.handlers[topic]...)
+	k.mu.Unlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterHandler delivers the messages published to topic from now on to handler, along
+// with the handlers registered for it before, like consumers of different groups
+func (k *Kafka) RegisterHandler(topic string, handler kafka.MessageHandler) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.handlers[topic] = append(k.handlers[topic], handler)
+}
+
+// Messages returns the messages published to topic, or to every topic if it is empty, in the
+// order they were published
+func (k *Kafka) Messages(topic string) []*kafka.Message {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	var messages []*kafka.Message
+	for _, m := range k.messages {
+		if topic == "" || m.Topic == topic {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// Reset forgets the published messages, keeping the subscribed handlers
+func (k *Kafka) Reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.messages = nil
+	k.offsets = make(map[string]int64)
+}
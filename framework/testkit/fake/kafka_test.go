@@ -0,0 +1,38 @@
+package fake
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/kafka"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafka(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	k := NewKafka().WithClock(NewClock(now))
+	errFailed := assert.AnError
+	k.RegisterHandler("orders", func(ctx context.Context, message *kafka.Message) error {
+		if message.Key == "bad" {
+			return errFailed
+		}
+		return nil
+	})
+
+	require.NoError(t, k.Publish(ctx, "orders", "o-1", []byte("placed")))
+	require.NoError(t, k.Publish(ctx, "payments", "p-1", []byte("paid")))
+	assert.ErrorIs(t, k.Publish(ctx, "orders", "bad", nil), errFailed)
+
+	orders := k.Messages("orders")
+	require.Len(t, orders, 2)
+	assert.Equal(t, []int64{0, 1}, []int64{orders[0].Offset, orders[1].Offset})
+	assert.Equal(t, now, orders[0].Timestamp)
+	assert.Len(t, k.Messages(""), 3)
+
+	k.Reset()
+	assert.Empty(t, k.Messages(""))
+}
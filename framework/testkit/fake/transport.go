@@ -0,0 +1,112 @@
+package fake
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+)
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// Request is a request sent through a Transport, with its body read
+type Request struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// Transport is an http.RoundTripper recording the requests sent through it and answering
+// them with the handler of their method and path, for clients such as client.HTTPClient.
+// Requests without a handler fail.
+type Transport struct {
+	mu       sync.Mutex
+	routes   []route
+	requests []Request
+}
+
+// route is a handler of the requests of a method, or of any method if it is empty, and path
+type route struct {
+	method  string
+	path    string
+	handler http.Handler
+}
+
+// NewTransport creates a transport without handlers
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Handle answers the requests of method and path with handler, the last handler added for
+// them winning. An empty method matches any method.
+func (t *Transport) Handle(method, path string, handler http.HandlerFunc) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, route{method: method, path: path, handler: handler})
+	return t
+}
+
+// Respond answers the requests of method and path with status and body
+func (t *Transport) Respond(method, path string, status int, body string) *Transport {
+	return t.Handle(method, path, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+// RoundTrip records req and answers it with its handler
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	t.requests = append(t.requests, Request{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	var handler http.Handler
+	for i := len(t.routes) - 1; i >= 0; i-- {
+		r := t.routes[i]
+		if (r.method == "" || r.method == req.Method) && r.path == req.URL.Path {
+			handler = r.handler
+			break
+		}
+	}
+	t.mu.Unlock()
+	if handler == nil {
+		return nil, fmt.Errorf("fake: no handler for %s %s", req.Method, req.URL)
+	}
+
+	served := req.Clone(req.Context())
+	served.Body = io.NopCloser(bytes.NewReader(body))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, served)
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Requests returns the requests sent, in the order they were sent
+func (t *Transport) Requests() []Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Request(nil), t.requests...)
+}
+
+// Client returns an HTTP client sending its requests through the transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
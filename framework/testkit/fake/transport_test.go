@@ -0,0 +1,68 @@
+package fake
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	transport := NewTransport().
+		Respond(http.MethodGet, "/rates", http.StatusOK, `{"eur":1.1}`).
+		Handle(http.MethodPost, "/orders", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Location", "/orders/1")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(body)
+		}).
+		Respond("", "/health", http.StatusNoContent, "")
+
+	c := transport.Client()
+	resp, err := c.Get("http://rates.test/rates?base=usd")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"eur":1.1}`, string(body))
+
+	resp, err = c.Post("http://orders.test/orders", "application/json", strings.NewReader(`{"sku":"A-1"}`))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/orders/1", resp.Header.Get("Location"))
+	assert.Equal(t, `{"sku":"A-1"}`, string(body), "handlers read the body")
+
+	resp, err = c.Head("http://orders.test/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, err = c.Get("http://orders.test/missing")
+	assert.ErrorContains(t, err, "fake: no handler for GET http://orders.test/missing")
+
+	requests := transport.Requests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, "usd", requests[0].URL.Query().Get("base"))
+	assert.Equal(t, http.MethodPost, requests[1].Method)
+	assert.Equal(t, "application/json", requests[1].Header.Get("Content-Type"))
+	assert.Equal(t, `{"sku":"A-1"}`, string(requests[1].Body))
+
+	t.Run("HTTPClient", func(t *testing.T) {
+		transport := NewTransport().Respond(http.MethodGet, "/rates", http.StatusOK, "ok")
+		options := client.DefaultOptions()
+		options.Transport = transport
+		resp, err := client.New(options).Get(t.Context(), "http://rates.test/rates", map[string]string{"Accept": "text/plain"})
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, transport.Requests(), 1)
+		assert.Equal(t, "text/plain", transport.Requests()[0].Header.Get("Accept"))
+	})
+}
@@ -0,0 +1,84 @@
+package testkit
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+	"go.uber.org/fx"
+)
+
+// PostgresDSNEnv is the environment variable holding the Postgres server of Postgres
+const PostgresDSNEnv = "POSTGRES_DSN"
+
+// Postgres provides a *database.DB and its *sql.DB on a schema of the server of POSTGRES_DSN
+// created for the test, with statements run in it first, such as the migrations of the
+// module under test. The schema is dropped when the test ends. Without POSTGRES_DSN, the
+// test is skipped.
+func Postgres(t testing.TB, statements ...string) fx.Option {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("Skipping Postgres test; set %s", PostgresDSNEnv)
+	}
+
+	ctx := context.Background()
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", PostgresDSNEnv, err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	suffix := make([]byte, 6)
+	_, _ = rand.Read(suffix)
+	schema := "testkit_" + hex.EncodeToString(suffix)
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+	// Cleanups run in reverse, so the schema is dropped after the application stopped
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	sqlDB, err := sql.Open("postgres", withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("failed to connect to schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	for _, statement := range statements {
+		if _, err := sqlDB.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return fx.Provide(
+		func() *sql.DB { return sqlDB },
+		func(logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config) *database.DB {
+			return database.New(sqlDB, logger, metrics, cfg)
+		},
+	)
+}
+
+// withSearchPath sets the search_path of the connections of dsn, a URL or key=value DSN, to
+// schema
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
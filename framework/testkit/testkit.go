@@ -0,0 +1,149 @@
+// Package testkit runs the modules of a service in end-to-end tests. StartTestApp boots them
+// in the default application with in-memory stand-ins for its infrastructure: a memory
+// cache, an event bus and a fake Kafka broker, and a Postgres schema of its own with
+// Postgres. Tests then call the service over HTTP and gRPC:
+//
+//	func TestOrders(t *testing.T) {
+//		app := testkit.StartTestApp(t, orders.Module)
+//		resp := app.Request(t, http.MethodPost, "/api/v1/orders", map[string]any{"sku": "A-1"})
+//		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+//	}
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/axiomodtest"
+	"github.com/axiomod/axiomod/framework/cache"
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/kafka"
+	"github.com/axiomod/axiomod/framework/testkit/fake"
+
+	"go.uber.org/fx"
+)
+
+// CacheSize is the maximum number of items of the memory cache of test applications
+const CacheSize = 10000
+
+// App is a running test application, with its clients and the stand-ins of its
+// infrastructure
+type App struct {
+	// App holds the configuration, the HTTP and gRPC clients and the event bus
+	*axiomodtest.App
+	// Cache is the memory cache provided as cache.Cache
+	Cache *cache.MemoryCache
+	// Kafka is the fake broker provided as kafka.Publisher and kafka.Subscriber
+	Kafka *fake.Kafka
+	// DB is the database of Postgres, nil without it
+	DB *database.DB
+}
+
+// StartTestApp boots the default application with opts, such as the modules under test, and
+// the in-memory stand-ins of App, and stops it when the test ends. Configuration changes
+// are made with WithConfig, a fake clock is set with WithClock, and a database is added with
+// Postgres.
+func StartTestApp(t testing.TB, opts ...fx.Option) *App {
+	t.Helper()
+
+	app := &App{}
+	standIns := fx.Options(
+		fx.Provide(
+			func(clk clock.Clock) *cache.MemoryCache { return cache.NewMemoryCache(CacheSize).WithClock(clk) },
+			func(c *cache.MemoryCache) cache.Cache { return c },
+			fake.NewKafka,
+			func(k *fake.Kafka) kafka.Publisher { return k },
+			func(k *fake.Kafka) kafka.Subscriber { return k },
+		),
+		fx.Invoke(func(p standInParams) {
+			app.Cache, app.Kafka, app.DB = p.Cache, p.Kafka, p.DB
+		}),
+	)
+	app.App = axiomodtest.StartDefaultApp(t, nil, append([]fx.Option{standIns}, opts...)...)
+	return app
+}
+
+// standInParams are the stand-ins of a test application
+type standInParams struct {
+	fx.In
+
+	Cache *cache.MemoryCache
+	Kafka *fake.Kafka
+	DB    *database.DB `optional:"true"`
+}
+
+// WithConfig changes the configuration of a test application, based on
+// axiomodtest.DefaultConfig, before its modules read it
+func WithConfig(change func(cfg *config.Config)) fx.Option {
+	return fx.Decorate(func(cfg *config.Config) *config.Config {
+		change(cfg)
+		return cfg
+	})
+}
+
+// WithClock runs a test application on clk, such as a fake.Clock, in place of the system
+// clock: the worker, the JWT service, the resilience policies and the memory cache then only
+// see time pass when the test advances it
+func WithClock(clk clock.Clock) fx.Option {
+	return fx.Decorate(func(clock.Clock) clock.Clock { return clk })
+}
+
+// Response is a response of the HTTP server with its body read
+type Response struct {
+	*http.Response
+	Body []byte
+}
+
+// JSON decodes the body of the response into v, failing the test if it is not JSON
+func (r *Response) JSON(t testing.TB, v any) {
+	t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", r.Body, err)
+	}
+}
+
+// Request sends a request to the HTTP server and reads its response, failing the test if
+// it cannot. body is sent as JSON unless it is nil, a []byte or an io.Reader.
+func (a *App) Request(t testing.TB, method, path string, body any) *Response {
+	t.Helper()
+
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("failed to encode the request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequest(method, a.URL(path), reader)
+	if err != nil {
+		t.Fatalf("invalid request %s %s: %v", method, path, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := a.HTTP.Do(req)
+	if err != nil {
+		t.Fatalf("request %s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read the response of %s %s: %v", method, path, err)
+	}
+	return &Response{Response: resp, Body: data}
+}
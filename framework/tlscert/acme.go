@@ -0,0 +1,200 @@
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	axredis "github.com/axiomod/axiomod/platform/redis"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACME storages
+const (
+	StorageDir   = "dir"
+	StorageRedis = "redis"
+	StorageVault = "vault"
+)
+
+// ACMESource obtains certificates from an ACME CA such as Let's Encrypt and renews them
+// before they expire. The CA validates the domains with the TLS-ALPN-01 challenge on the
+// server's own port, which must be reachable as 443, or with the HTTP-01 challenge when
+// HTTPHandler is served on port 80.
+type ACMESource struct {
+	manager *autocert.Manager
+	domain  string
+}
+
+// NewACMESource creates a new ACME source keeping its account key and certificates in cache
+func NewACMESource(cfg config.ACMEConfig, cache autocert.Cache) (*ACMESource, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, errors.New("acme requires at least one domain")
+	}
+	renewBefore := 30 * 24 * time.Hour
+	if cfg.RenewBefore > 0 {
+		renewBefore = time.Duration(cfg.RenewBefore) * 24 * time.Hour
+	}
+
+	manager := &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       cache,
+		HostPolicy:  autocert.HostWhitelist(cfg.Domains...),
+		Email:       cfg.Email,
+		RenewBefore: renewBefore,
+	}
+	if cfg.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return &ACMESource{manager: manager, domain: cfg.Domains[0]}, nil
+}
+
+// NewACMECache creates the storage configured for ACME accounts and certificates. Shared
+// storages let replicas reuse one certificate instead of each requesting its own, which
+// would soon hit the CA's rate limits. The redis storage keeps them with client, the
+// client of redis.Module.
+func NewACMECache(tlsCfg config.TLSConfig, client redis.UniversalClient) (autocert.Cache, error) {
+	switch strings.ToLower(tlsCfg.ACME.Storage) {
+	case "", StorageDir:
+		dir := tlsCfg.ACME.CacheDir
+		if dir == "" {
+			dir = "acme-cache"
+		}
+		return autocert.DirCache(dir), nil
+	case StorageRedis:
+		if client == nil {
+			return nil, fmt.Errorf("acme: %w", axredis.ErrNoClient)
+		}
+		return NewRedisCache(client, "acme"), nil
+	case StorageVault:
+		cache := NewVaultCache(tlsCfg.Vault, tlsCfg.ACME.VaultPath)
+		if cache.client.Address == "" || cache.prefix == "" {
+			return nil, errors.New("acme vault storage requires the vault address and acme.vaultPath")
+		}
+		return cache, nil
+	default:
+		return nil, fmt.Errorf("unknown acme storage %q", tlsCfg.ACME.Storage)
+	}
+}
+
+// Certificate returns the certificate of the first domain, obtaining or renewing it if needed
+func (s *ACMESource) Certificate(ctx context.Context) (*tls.Certificate, error) {
+	type outcome struct {
+		cert *tls.Certificate
+		err  error
+	}
+	// The manager bounds the request itself; ctx only bounds the wait
+	done := make(chan outcome, 1)
+	go func() {
+		cert, err := s.manager.GetCertificate(&tls.ClientHelloInfo{ServerName: s.domain})
+		done <- outcome{cert: cert, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.cert, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// GetCertificate returns the certificate for the server name of a handshake and answers
+// TLS-ALPN-01 challenges
+func (s *ACMESource) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.manager.GetCertificate(hello)
+}
+
+// HTTPHandler answers HTTP-01 challenges and passes other requests to fallback; a nil
+// fallback redirects them to HTTPS
+func (s *ACMESource) HTTPHandler(fallback http.Handler) http.Handler {
+	return s.manager.HTTPHandler(fallback)
+}
+
+// RedisCache keeps ACME accounts and certificates in Redis
+type RedisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisCache creates a new Redis ACME cache
+func NewRedisCache(client redis.UniversalClient, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+// Get returns the data stored under key
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, err
+}
+
+// Put stores data under key
+func (c *RedisCache) Put(ctx context.Context, key string, data []byte) error {
+	return c.client.Set(ctx, c.key(key), data, 0).Err()
+}
+
+// Delete removes the data stored under key
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.key(key)).Err()
+}
+
+// key returns the Redis key of an entry
+func (c *RedisCache) key(key string) string {
+	return c.prefix + ":" + key
+}
+
+// VaultCache keeps ACME accounts and certificates in a Vault KV version 2 secrets engine,
+// one secret per entry
+type VaultCache struct {
+	client vaultClient
+	prefix string
+}
+
+// NewVaultCache creates a new Vault ACME cache storing entries under prefix, e.g. "secret/data/acme"
+func NewVaultCache(cfg config.VaultTLSConfig, prefix string) *VaultCache {
+	return &VaultCache{client: newVaultClient(cfg), prefix: strings.Trim(prefix, "/")}
+}
+
+// Get returns the data stored under key
+func (c *VaultCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.client.read(ctx, c.path(key))
+	if errors.Is(err, errVaultNotFound) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	value, ok := data["value"].(string)
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+// Put stores data under key
+func (c *VaultCache) Put(ctx context.Context, key string, data []byte) error {
+	return c.client.write(ctx, c.path(key), map[string]interface{}{
+		"value": base64.StdEncoding.EncodeToString(data),
+	})
+}
+
+// Delete removes the data stored under key
+func (c *VaultCache) Delete(ctx context.Context, key string) error {
+	return c.client.delete(ctx, c.path(key))
+}
+
+// path returns the secret path of an entry
+func (c *VaultCache) path(key string) string {
+	return c.prefix + "/" + url.PathEscape(key)
+}
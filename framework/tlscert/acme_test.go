@@ -0,0 +1,111 @@
+package tlscert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// fakeVaultKV serves a KV version 2 secrets engine from memory
+func fakeVaultKV(t *testing.T) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	secrets := make(map[string]map[string]interface{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		path := strings.TrimPrefix(r.URL.EscapedPath(), "/v1/")
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := secrets[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+		case http.MethodPost:
+			var body struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			secrets[path] = body.Data
+		case http.MethodDelete:
+			delete(secrets, path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testCache checks that a cache stores, returns and deletes entries
+func testCache(t *testing.T, cache autocert.Cache) {
+	ctx := context.Background()
+	key := "api.example.com+rsa"
+
+	_, err := cache.Get(ctx, key)
+	assert.ErrorIs(t, err, autocert.ErrCacheMiss)
+
+	require.NoError(t, cache.Put(ctx, key, []byte("certificate\x00data")))
+	data, err := cache.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("certificate\x00data"), data)
+
+	require.NoError(t, cache.Delete(ctx, key))
+	_, err = cache.Get(ctx, key)
+	assert.ErrorIs(t, err, autocert.ErrCacheMiss)
+	assert.NoError(t, cache.Delete(ctx, key), "deleting a missing entry succeeds")
+}
+
+func TestVaultCache(t *testing.T) {
+	server := fakeVaultKV(t)
+	testCache(t, NewVaultCache(config.VaultTLSConfig{Address: server.URL, Token: "token"}, "/secret/data/acme/"))
+
+	_, err := NewVaultCache(config.VaultTLSConfig{Address: server.URL, Token: "wrong"}, "secret/data/acme").Get(context.Background(), "key")
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestRedisCache(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("Skipping Redis ACME cache test; set REDIS_ADDR")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	testCache(t, NewRedisCache(client, "acme-test"))
+}
+
+func TestACMEHTTPHandler(t *testing.T) {
+	source, err := NewACMESource(config.ACMEConfig{Domains: []string{"api.example.com"}}, autocert.DirCache(t.TempDir()))
+	require.NoError(t, err)
+
+	// Requests other than challenges are redirected to HTTPS
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/orders?page=2", nil)
+	rec := httptest.NewRecorder()
+	source.HTTPHandler(nil).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://api.example.com/orders?page=2", rec.Header().Get("Location"))
+
+	// Unknown challenge tokens are not answered
+	req = httptest.NewRequest(http.MethodGet, "http://api.example.com/.well-known/acme-challenge/unknown", nil)
+	rec = httptest.NewRecorder()
+	source.HTTPHandler(nil).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
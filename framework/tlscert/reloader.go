@@ -0,0 +1,348 @@
+// Package tlscert serves TLS certificates that are reloaded while the server runs, so
+// certificates can be rotated without a restart or dropped connections.
+package tlscert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+)
+
+// Certificate sources
+const (
+	SourceFile  = "file"
+	SourceACME  = "acme"
+	SourceVault = "vault"
+)
+
+// ErrNoCertificate is returned to handshakes before a certificate was loaded
+var ErrNoCertificate = errors.New("no TLS certificate loaded")
+
+const (
+	// watchDebounce groups the file events of one rotation, e.g. of the certificate and the key
+	watchDebounce = 100 * time.Millisecond
+	// warningInterval limits how often an expiring certificate is logged
+	warningInterval = time.Hour
+)
+
+// Options contains options for a reloader
+type Options struct {
+	// Interval is how often the source is checked for a new certificate; file sources are
+	// also reloaded as soon as the files change
+	Interval time.Duration
+	// ExpiryWarning logs warnings for certificates expiring within this duration
+	ExpiryWarning time.Duration
+}
+
+// DefaultOptions returns the default reloader options
+func DefaultOptions() *Options {
+	return &Options{
+		Interval:      time.Minute,
+		ExpiryWarning: 14 * 24 * time.Hour,
+	}
+}
+
+// Reloader holds the certificate a server presents and swaps it when the source provides
+// a new one. Handshakes after a swap get the new certificate while established connections
+// keep going.
+type Reloader struct {
+	name     string
+	source   Source
+	options  *Options
+	logger   *observability.Logger
+	metrics  *observability.Metrics
+	cert     atomic.Pointer[tls.Certificate]
+	mu       sync.Mutex
+	warnedAt time.Time
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewReloader creates a new reloader; name identifies the server in logs and metrics
+func NewReloader(name string, source Source, options *Options, logger *observability.Logger) *Reloader {
+	defaults := DefaultOptions()
+	if options == nil {
+		options = defaults
+	}
+	opts := *options
+	if opts.Interval <= 0 {
+		opts.Interval = defaults.Interval
+	}
+	if opts.ExpiryWarning <= 0 {
+		opts.ExpiryWarning = defaults.ExpiryWarning
+	}
+
+	return &Reloader{name: name, source: source, options: &opts, logger: logger}
+}
+
+// NewReloaderFromConfig creates a reloader for the TLS configuration of a server. client, the
+// client of redis.Module, stores ACME certificates kept in Redis; it may be nil otherwise.
+func NewReloaderFromConfig(name string, tlsCfg config.TLSConfig, client redis.UniversalClient, logger *observability.Logger) (*Reloader, error) {
+	var source Source
+	switch strings.ToLower(tlsCfg.Source) {
+	case "", SourceFile:
+		if tlsCfg.CertFile == "" || tlsCfg.KeyFile == "" {
+			return nil, fmt.Errorf("%s TLS requires certFile and keyFile", name)
+		}
+		source = NewFileSource(tlsCfg.CertFile, tlsCfg.KeyFile)
+	case SourceACME:
+		cache, err := NewACMECache(tlsCfg, client)
+		if err != nil {
+			return nil, fmt.Errorf("%s TLS: %w", name, err)
+		}
+		acmeSource, err := NewACMESource(tlsCfg.ACME, cache)
+		if err != nil {
+			return nil, fmt.Errorf("%s TLS: %w", name, err)
+		}
+		source = acmeSource
+	case SourceVault:
+		vaultSource, err := NewVaultSource(tlsCfg.Vault)
+		if err != nil {
+			return nil, fmt.Errorf("%s TLS: %w", name, err)
+		}
+		source = vaultSource
+	default:
+		return nil, fmt.Errorf("unknown %s TLS certificate source %q", name, tlsCfg.Source)
+	}
+
+	return NewReloader(name, source, &Options{
+		Interval:      time.Duration(tlsCfg.ReloadInterval) * time.Second,
+		ExpiryWarning: time.Duration(tlsCfg.ExpiryWarning) * 24 * time.Hour,
+	}, logger), nil
+}
+
+// Source returns the source of the certificates
+func (r *Reloader) Source() Source {
+	return r.source
+}
+
+// WithMetrics records certificate expiry and reloads on metrics
+func (r *Reloader) WithMetrics(metrics *observability.Metrics) *Reloader {
+	r.metrics = metrics
+	return r
+}
+
+// TLSConfig returns a server TLS configuration presenting the reloaded certificate
+func (r *Reloader) TLSConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+	if _, ok := r.source.(*ACMESource); ok {
+		cfg.NextProtos = []string{acme.ALPNProto}
+	}
+	return cfg
+}
+
+// GetCertificate returns the certificate for a handshake
+func (r *Reloader) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if source, ok := r.source.(handshakeSource); ok {
+		return source.GetCertificate(hello)
+	}
+	cert := r.cert.Load()
+	if cert == nil {
+		return nil, ErrNoCertificate
+	}
+	return cert, nil
+}
+
+// Current returns the loaded certificate, or nil before the first load
+func (r *Reloader) Current() *tls.Certificate {
+	return r.cert.Load()
+}
+
+// Reload loads the certificate from the source and swaps it in if it changed. On failure the
+// previous certificate stays in use.
+func (r *Reloader) Reload(ctx context.Context) error {
+	cert, err := r.source.Certificate(ctx)
+	if err == nil && cert.Leaf == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	}
+	if err != nil {
+		r.recordReload("error")
+		r.logger.Error("Failed to reload TLS certificate", zap.String("server", r.name), zap.Error(err))
+		return err
+	}
+
+	previous := r.cert.Load()
+	swapped := previous == nil || !bytes.Equal(previous.Certificate[0], cert.Certificate[0])
+	if swapped {
+		r.cert.Store(cert)
+		r.recordReload("success")
+		r.logger.Info("Loaded TLS certificate",
+			zap.String("server", r.name),
+			zap.String("subject", cert.Leaf.Subject.String()),
+			zap.Strings("dns_names", cert.Leaf.DNSNames),
+			zap.Time("not_after", cert.Leaf.NotAfter))
+	}
+	r.checkExpiry(cert.Leaf, swapped)
+	return nil
+}
+
+// Start loads the certificate and watches the source until Stop is called. Sources choosing
+// the certificate per handshake are loaded in the background, since that may take a while.
+func (r *Reloader) Start(ctx context.Context) error {
+	_, perHandshake := r.source.(handshakeSource)
+	if !perHandshake {
+		if err := r.Reload(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Watch before returning, so no change after the load is missed
+	var watcher *fsnotify.Watcher
+	if source, ok := r.source.(watchedSource); ok {
+		var err error
+		if watcher, err = r.watch(source.Files()); err != nil {
+			r.logger.Warn("Failed to watch TLS certificate files, falling back to polling",
+				zap.String("server", r.name), zap.Error(err))
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(runCtx, watcher, perHandshake)
+	return nil
+}
+
+// Stop stops watching the source
+func (r *Reloader) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+// run reloads the certificate every Interval and when the watched files change
+func (r *Reloader) run(ctx context.Context, watcher *fsnotify.Watcher, loadNow bool) {
+	defer close(r.done)
+
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if watcher != nil {
+		defer watcher.Close()
+		events = watcher.Events
+		watchErrors = watcher.Errors
+	}
+
+	if loadNow {
+		r.reload(ctx)
+	}
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reload(ctx)
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			debounce.Reset(watchDebounce)
+		case err, ok := <-watchErrors:
+			if !ok {
+				watchErrors = nil
+				continue
+			}
+			r.logger.Warn("Error watching TLS certificate files", zap.String("server", r.name), zap.Error(err))
+		case <-debounce.C:
+			r.reload(ctx)
+		}
+	}
+}
+
+// reload reloads the certificate, bounded by Interval
+func (r *Reloader) reload(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.options.Interval)
+	defer cancel()
+	// Failures are logged and counted by Reload
+	_ = r.Reload(ctx)
+}
+
+// watch watches the directories of files. Watching the directories rather than the files
+// follows rotations that replace the files, such as the symlink swap of Kubernetes secrets.
+func (r *Reloader) watch(files []string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	return watcher, nil
+}
+
+// checkExpiry records the expiry of a certificate and warns when it is close. Warnings for
+// the same certificate are repeated at most every warningInterval unless forced.
+func (r *Reloader) checkExpiry(leaf *x509.Certificate, force bool) {
+	if r.metrics != nil && r.metrics.TLSCertificateExpiry != nil {
+		r.metrics.TLSCertificateExpiry.WithLabelValues(r.name).Set(float64(leaf.NotAfter.Unix()))
+	}
+
+	remaining := time.Until(leaf.NotAfter)
+	if remaining > r.options.ExpiryWarning {
+		return
+	}
+
+	r.mu.Lock()
+	if !force && time.Since(r.warnedAt) < warningInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.warnedAt = time.Now()
+	r.mu.Unlock()
+
+	fields := []zap.Field{
+		zap.String("server", r.name),
+		zap.String("subject", leaf.Subject.String()),
+		zap.Time("not_after", leaf.NotAfter),
+	}
+	if remaining <= 0 {
+		r.logger.Error("TLS certificate has expired", fields...)
+		return
+	}
+	r.logger.Warn("TLS certificate expires soon", append(fields, zap.Duration("expires_in", remaining))...)
+}
+
+// recordReload counts a reload with its result
+func (r *Reloader) recordReload(result string) {
+	if r.metrics == nil || r.metrics.TLSCertificateReloadsTotal == nil {
+		return
+	}
+	r.metrics.TLSCertificateReloadsTotal.WithLabelValues(r.name, result).Inc()
+}
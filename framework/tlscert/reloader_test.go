@@ -0,0 +1,293 @@
+package tlscert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generatePEM creates a self-signed certificate for localhost expiring after validFor
+func generatePEM(t *testing.T, serial int64, validFor time.Duration) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeCert writes a new certificate to the files
+func writeCert(t *testing.T, certFile, keyFile string, serial int64, validFor time.Duration) {
+	t.Helper()
+	certPEM, keyPEM := generatePEM(t, serial, validFor)
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+}
+
+// serialOf returns the serial number of a certificate
+func serialOf(cert *tls.Certificate) int64 {
+	if cert == nil || cert.Leaf == nil {
+		return 0
+	}
+	return cert.Leaf.SerialNumber.Int64()
+}
+
+func newTestReloader(t *testing.T, source Source, options *Options) (*Reloader, *observability.Metrics) {
+	t.Helper()
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	return NewReloader("test", source, options, logger).WithMetrics(metrics), metrics
+}
+
+// staticSource returns a fixed certificate or error
+type staticSource struct {
+	cert *tls.Certificate
+	err  error
+}
+
+func (s *staticSource) Certificate(ctx context.Context) (*tls.Certificate, error) {
+	return s.cert, s.err
+}
+
+func TestReload(t *testing.T) {
+	certPEM, keyPEM := generatePEM(t, 1, 90*24*time.Hour)
+	first, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	certPEM, keyPEM = generatePEM(t, 2, 90*24*time.Hour)
+	second, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	source := &staticSource{}
+	r, metrics := newTestReloader(t, source, nil)
+
+	_, err = r.GetCertificate(&tls.ClientHelloInfo{})
+	assert.ErrorIs(t, err, ErrNoCertificate)
+
+	source.cert = &first
+	require.NoError(t, r.Reload(context.Background()))
+	assert.Equal(t, int64(1), serialOf(r.Current()))
+
+	// An unchanged certificate is not counted as a reload
+	require.NoError(t, r.Reload(context.Background()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.TLSCertificateReloadsTotal.WithLabelValues("test", "success")))
+
+	source.cert = &second
+	require.NoError(t, r.Reload(context.Background()))
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), serialOf(cert))
+	assert.Equal(t, float64(second.Leaf.NotAfter.Unix()), testutil.ToFloat64(metrics.TLSCertificateExpiry.WithLabelValues("test")))
+
+	// A failed reload keeps the previous certificate
+	source.err = errors.New("source unavailable")
+	assert.Error(t, r.Reload(context.Background()))
+	assert.Equal(t, int64(2), serialOf(r.Current()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.TLSCertificateReloadsTotal.WithLabelValues("test", "error")))
+}
+
+func TestFileWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1, 90*24*time.Hour)
+
+	// A long interval, so only the file watch can pick up the rotation
+	r, _ := newTestReloader(t, NewFileSource(certFile, keyFile), &Options{Interval: time.Hour})
+	require.NoError(t, r.Start(context.Background()))
+	defer r.Stop()
+	assert.Equal(t, int64(1), serialOf(r.Current()))
+
+	writeCert(t, certFile, keyFile, 2, 90*24*time.Hour)
+	assert.Eventually(t, func() bool {
+		return serialOf(r.Current()) == 2
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestStartFailsWithoutCertificate(t *testing.T) {
+	dir := t.TempDir()
+	r, _ := newTestReloader(t, NewFileSource(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key")), nil)
+	assert.Error(t, r.Start(context.Background()))
+	r.Stop()
+}
+
+func TestRotationKeepsConnections(t *testing.T) {
+	certPEM, keyPEM := generatePEM(t, 1, 90*24*time.Hour)
+	first, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	source := &staticSource{cert: &first}
+	r, _ := newTestReloader(t, source, nil)
+	require.NoError(t, r.Reload(context.Background()))
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", r.TLSConfig())
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					if _, err := conn.Write(buf); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	dial := func() *tls.Conn {
+		conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+		require.NoError(t, err)
+		return conn
+	}
+	echo := func(conn net.Conn) error {
+		if _, err := conn.Write([]byte("x")); err != nil {
+			return err
+		}
+		_, err := conn.Read(make([]byte, 1))
+		return err
+	}
+
+	before := dial()
+	defer before.Close()
+	assert.Equal(t, int64(1), before.ConnectionState().PeerCertificates[0].SerialNumber.Int64())
+
+	certPEM, keyPEM = generatePEM(t, 2, 90*24*time.Hour)
+	second, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	source.cert = &second
+	require.NoError(t, r.Reload(context.Background()))
+
+	after := dial()
+	defer after.Close()
+	assert.Equal(t, int64(2), after.ConnectionState().PeerCertificates[0].SerialNumber.Int64())
+	assert.NoError(t, echo(before), "connections established before the rotation keep working")
+	assert.NoError(t, echo(after))
+}
+
+func TestVaultSource(t *testing.T) {
+	certPEM, keyPEM := generatePEM(t, 7, 90*24*time.Hour)
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{
+			name: "KV version 2",
+			body: map[string]interface{}{"data": map[string]interface{}{
+				"data": map[string]interface{}{"certificate": string(certPEM), "private_key": string(keyPEM)},
+			}},
+		},
+		{
+			name: "KV version 1",
+			body: map[string]interface{}{"data": map[string]interface{}{
+				"certificate": string(certPEM), "private_key": string(keyPEM),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Vault-Token") != "token" || r.URL.Path != "/v1/secret/data/tls/api" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(tt.body)
+			}))
+			defer server.Close()
+
+			source, err := NewVaultSource(config.VaultTLSConfig{Address: server.URL, Token: "token", Path: "/secret/data/tls/api"})
+			require.NoError(t, err)
+			cert, err := source.Certificate(context.Background())
+			require.NoError(t, err)
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), leaf.SerialNumber.Int64())
+
+			source.Token = "wrong"
+			_, err = source.Certificate(context.Background())
+			assert.ErrorContains(t, err, "status 403")
+		})
+	}
+}
+
+func TestNewReloaderFromConfig(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	tests := []struct {
+		name    string
+		cfg     config.TLSConfig
+		client  redis.UniversalClient
+		wantErr bool
+	}{
+		{name: "file", cfg: config.TLSConfig{CertFile: "tls.crt", KeyFile: "tls.key"}},
+		{name: "file without key", cfg: config.TLSConfig{CertFile: "tls.crt"}, wantErr: true},
+		{name: "acme", cfg: config.TLSConfig{Source: "acme", ACME: config.ACMEConfig{Domains: []string{"api.example.com"}}}},
+		{name: "acme without domains", cfg: config.TLSConfig{Source: "acme"}, wantErr: true},
+		{name: "acme in redis", cfg: config.TLSConfig{Source: "acme", ACME: config.ACMEConfig{Domains: []string{"api.example.com"}, Storage: "redis"}}, client: redis.NewClient(&redis.Options{Addr: "localhost:0"})},
+		{name: "acme in redis without client", cfg: config.TLSConfig{Source: "acme", ACME: config.ACMEConfig{Domains: []string{"api.example.com"}, Storage: "redis"}}, wantErr: true},
+		{name: "acme in vault without path", cfg: config.TLSConfig{Source: "acme", ACME: config.ACMEConfig{Domains: []string{"api.example.com"}, Storage: "vault"}, Vault: config.VaultTLSConfig{Address: "http://vault:8200"}}, wantErr: true},
+		{name: "acme in unknown storage", cfg: config.TLSConfig{Source: "acme", ACME: config.ACMEConfig{Domains: []string{"api.example.com"}, Storage: "s3"}}, wantErr: true},
+		{name: "vault", cfg: config.TLSConfig{Source: "vault", Vault: config.VaultTLSConfig{Address: "http://vault:8200", Path: "secret/data/tls"}}},
+		{name: "unknown source", cfg: config.TLSConfig{Source: "s3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReloaderFromConfig("http", tt.cfg, tt.client, logger)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, DefaultOptions().Interval, r.options.Interval)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+)
+
+// Source provides the certificate a server presents
+type Source interface {
+	// Certificate returns the current certificate
+	Certificate(ctx context.Context) (*tls.Certificate, error)
+}
+
+// handshakeSource is implemented by sources choosing the certificate for each handshake,
+// e.g. by server name
+type handshakeSource interface {
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// watchedSource is implemented by sources backed by files, which are reloaded as soon as
+// the files change
+type watchedSource interface {
+	Files() []string
+}
+
+// FileSource reads a PEM certificate chain and private key from files
+type FileSource struct {
+	CertFile string
+	KeyFile  string
+}
+
+// NewFileSource creates a new file source
+func NewFileSource(certFile, keyFile string) *FileSource {
+	return &FileSource{CertFile: certFile, KeyFile: keyFile}
+}
+
+// Certificate reads the certificate files
+func (s *FileSource) Certificate(ctx context.Context) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate %s: %w", s.CertFile, err)
+	}
+	return &cert, nil
+}
+
+// Files returns the certificate and key files
+func (s *FileSource) Files() []string {
+	return []string{s.CertFile, s.KeyFile}
+}
@@ -0,0 +1,166 @@
+package tlscert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+)
+
+// errVaultNotFound is returned for secrets that do not exist
+var errVaultNotFound = errors.New("vault secret not found")
+
+// vaultClient reads and writes Vault KV secrets over the HTTP API
+type vaultClient struct {
+	Address string
+	Token   string
+	Client  *http.Client
+}
+
+// newVaultClient creates a Vault client; the address and token default to VAULT_ADDR and
+// VAULT_TOKEN
+func newVaultClient(cfg config.VaultTLSConfig) vaultClient {
+	c := vaultClient{
+		Address: cfg.Address,
+		Token:   cfg.Token,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	if c.Address == "" {
+		c.Address = os.Getenv("VAULT_ADDR")
+	}
+	if c.Token == "" {
+		c.Token = os.Getenv("VAULT_TOKEN")
+	}
+	return c
+}
+
+// read returns the data of a secret. Both KV version 1 and version 2 secrets are supported.
+func (c *vaultClient) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret %s: %w", path, err)
+	}
+	// KV version 2 nests the secret under data.data
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		return nested, nil
+	}
+	return secret.Data, nil
+}
+
+// write stores data in a KV version 2 secret
+func (c *vaultClient) write(ctx context.Context, path string, data map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// delete deletes a secret
+func (c *vaultClient) delete(ctx context.Context, path string) error {
+	resp, err := c.do(ctx, http.MethodDelete, path, nil)
+	if errors.Is(err, errVaultNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a request for a secret path and fails on statuses other than 2xx
+func (c *vaultClient) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	url := strings.TrimSuffix(c.Address, "/") + "/v1/" + strings.Trim(path, "/")
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request for %s failed: %w", path, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", errVaultNotFound, path)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vault request for %s failed: status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// VaultSource reads a PEM certificate chain and private key from a Vault KV secret,
+// e.g. one kept current by a PKI role or an external rotation job
+type VaultSource struct {
+	vaultClient
+	Path      string
+	CertField string
+	KeyField  string
+}
+
+// NewVaultSource creates a new Vault source; unset values take the defaults
+func NewVaultSource(cfg config.VaultTLSConfig) (*VaultSource, error) {
+	s := &VaultSource{
+		vaultClient: newVaultClient(cfg),
+		Path:        strings.Trim(cfg.Path, "/"),
+		CertField:   cfg.CertField,
+		KeyField:    cfg.KeyField,
+	}
+	if s.CertField == "" {
+		s.CertField = "certificate"
+	}
+	if s.KeyField == "" {
+		s.KeyField = "private_key"
+	}
+	if s.Address == "" || s.Path == "" {
+		return nil, errors.New("vault address and path are required")
+	}
+	return s, nil
+}
+
+// Certificate reads the secret
+func (s *VaultSource) Certificate(ctx context.Context) (*tls.Certificate, error) {
+	data, err := s.read(ctx, s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", s.Path, err)
+	}
+
+	certPEM, _ := data[s.CertField].(string)
+	keyPEM, _ := data[s.KeyField].(string)
+	if certPEM == "" || keyPEM == "" {
+		return nil, fmt.Errorf("vault secret %s has no %s and %s fields", s.Path, s.CertField, s.KeyField)
+	}
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate from vault secret %s: %w", s.Path, err)
+	}
+	return &cert, nil
+}
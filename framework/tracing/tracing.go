@@ -86,10 +86,10 @@ func New(logger *observability.Logger, config *Config) (*Tracer, error) {
 		return nil, fmt.Errorf("failed to create exporter: %w", err)
 	}
 
-	// Create sampler
-	sampler := sdktrace.ParentBased(
+	// Create sampler, recording the requests sent with debug tracing
+	sampler := observability.NewDebugSampler(sdktrace.ParentBased(
 		sdktrace.TraceIDRatioBased(config.SamplingRatio),
-	)
+	))
 
 	// Create trace provider
 	tp := sdktrace.NewTracerProvider(
@@ -0,0 +1,35 @@
+package unitofwork
+
+import (
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the UnitOfWork publishing events with the domain event dispatcher of
+// events.Module. The command bus of cqrs.Module runs commands in it.
+var Module = fx.Options(
+	fx.Provide(ProvideUnitOfWork),
+)
+
+// UnitOfWorkParams holds the dependencies of the unit of work. Without a *database.DB, work
+// runs without a transaction; with an Outbox, events are stored in it instead of published.
+type UnitOfWorkParams struct {
+	fx.In
+
+	Logger     *observability.Logger
+	Dispatcher *events.Dispatcher
+	DB         *database.DB `optional:"true"`
+	Outbox     Outbox       `optional:"true"`
+}
+
+// ProvideUnitOfWork creates the unit of work of the application
+func ProvideUnitOfWork(p UnitOfWorkParams) *UnitOfWork {
+	u := New(p.DB, p.Dispatcher, p.Logger)
+	if p.Outbox != nil {
+		u.WithOutbox(p.Outbox)
+	}
+	return u
+}
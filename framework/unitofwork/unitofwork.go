@@ -0,0 +1,171 @@
+// Package unitofwork runs the work of use cases in a database transaction and delivers the
+// domain events recorded during it once it committed, so that handlers never see events of
+// changes that were rolled back.
+package unitofwork
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+)
+
+// Publisher delivers the events of committed units of work, such as *events.Dispatcher
+type Publisher interface {
+	Publish(ctx context.Context, event any) error
+}
+
+// Outbox stores the events of a unit of work in its transaction, for a relay to publish them
+// once committed. Unlike events published after commit, they survive crashes between the
+// commit and their delivery.
+type Outbox interface {
+	Store(ctx context.Context, events []any) error
+}
+
+// UnitOfWork runs work in transactions of a database and delivers the domain events it
+// recorded with Track and Record, to the publisher after commit or to the outbox before
+type UnitOfWork struct {
+	db        *database.DB
+	publisher Publisher
+	outbox    Outbox
+	logger    *observability.Logger
+}
+
+// New creates a unit of work publishing events with publisher. Without a database, work runs
+// without a transaction and its events are published once it succeeds, e.g. over in-memory
+// repositories.
+func New(db *database.DB, publisher Publisher, logger *observability.Logger) *UnitOfWork {
+	return &UnitOfWork{db: db, publisher: publisher, logger: logger}
+}
+
+// WithOutbox stores events in outbox, in the transaction of the work, instead of publishing
+// them after commit
+func (u *UnitOfWork) WithOutbox(outbox Outbox) *UnitOfWork {
+	u.outbox = outbox
+	return u
+}
+
+// workKey is the context key of the unit of work that work runs in
+type workKey struct{}
+
+// work collects the events recorded by a unit of work, in the order they were recorded
+type work struct {
+	mu      sync.Mutex
+	sources []source
+}
+
+// source is an aggregate tracked by a unit of work, or events recorded without one
+type source struct {
+	aggregate events.Aggregate
+	events    []any
+}
+
+// pull returns the events of the sources and forgets them
+func (w *work) pull() []any {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var pending []any
+	for _, s := range w.sources {
+		if s.aggregate != nil {
+			pending = append(pending, s.aggregate.PullEvents()...)
+			continue
+		}
+		pending = append(pending, s.events...)
+	}
+	w.sources = nil
+	return pending
+}
+
+// Track adds aggregates to the unit of work of ctx, typically when a repository stores them.
+// The events they recorded, before or after Track, are delivered with the events of the unit
+// of work. Outside of a unit of work, Track does nothing.
+func Track(ctx context.Context, aggregates ...events.Aggregate) {
+	w, ok := ctx.Value(workKey{}).(*work)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, a := range aggregates {
+		w.sources = append(w.sources, source{aggregate: a})
+	}
+}
+
+// Record adds domain events to the unit of work of ctx, for events without an aggregate,
+// such as the deletion of an entity that was not loaded. Outside of a unit of work, Record
+// does nothing.
+func Record(ctx context.Context, recorded ...any) {
+	w, ok := ctx.Value(workKey{}).(*work)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sources = append(w.sources, source{events: recorded})
+}
+
+// InWork reports whether ctx runs in a unit of work
+func InWork(ctx context.Context) bool {
+	_, ok := ctx.Value(workKey{}).(*work)
+	return ok
+}
+
+// Do runs fn in a transaction, committed if fn succeeds and rolled back otherwise, and then
+// delivers the events fn recorded. Work done while running in a unit of work joins it, and
+// work done in a transaction started elsewhere runs in it, with its events delivered when fn
+// returns. Errors of the publisher are logged and not returned: the work is committed.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InWork(ctx) {
+		return fn(ctx)
+	}
+	w := &work{}
+	workCtx := context.WithValue(ctx, workKey{}, w)
+
+	_, inTx := database.TxFromContext(ctx)
+	if u.db == nil || inTx {
+		if err := fn(workCtx); err != nil {
+			return err
+		}
+		pending := w.pull()
+		if u.outbox != nil {
+			return u.outbox.Store(ctx, pending)
+		}
+		u.publish(ctx, pending)
+		return nil
+	}
+
+	var pending []any
+	err := u.db.WithTransaction(workCtx, func(txCtx context.Context, _ *sql.Tx) error {
+		if err := fn(txCtx); err != nil {
+			return err
+		}
+		pending = w.pull()
+		if u.outbox != nil {
+			return u.outbox.Store(txCtx, pending)
+		}
+		return nil
+	})
+	if err != nil || u.outbox != nil {
+		return err
+	}
+	// Handlers run outside of the committed transaction and unit of work
+	u.publish(ctx, pending)
+	return nil
+}
+
+// publish delivers the events of a committed unit of work, logging the errors of the publisher
+func (u *UnitOfWork) publish(ctx context.Context, pending []any) {
+	for _, event := range pending {
+		if err := u.publisher.Publish(ctx, event); err != nil {
+			u.logger.Error("Failed to publish domain event",
+				zap.String("event", events.NameOf(event)),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package unitofwork
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"sync"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/database"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a database driver, publisher and outbox recording what they are asked to do
+type recorder struct {
+	mu  sync.Mutex
+	log []string
+	err error // returned by Publish and Store
+}
+
+func (r *recorder) record(entry string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log = append(r.log, entry)
+}
+
+func (r *recorder) entries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.log...)
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                            { return nil }
+
+func (r *recorder) Publish(ctx context.Context, event any) error {
+	_, inTx := database.TxFromContext(ctx)
+	if inTx || InWork(ctx) {
+		r.record("publish in work")
+	}
+	r.record("publish " + event.(string))
+	return r.err
+}
+
+func (r *recorder) Store(ctx context.Context, pending []any) error {
+	if _, inTx := database.TxFromContext(ctx); inTx {
+		r.record("store in transaction")
+	}
+	for _, event := range pending {
+		r.record("store " + event.(string))
+	}
+	return r.err
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, stderrors.New("not supported")
+}
+func (c recorderConn) Close() error { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("begin")
+	return recorderTx(c), nil
+}
+func (c recorderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+type recorderTx struct{ r *recorder }
+
+func (t recorderTx) Commit() error   { t.r.record("commit"); return nil }
+func (t recorderTx) Rollback() error { t.r.record("rollback"); return nil }
+
+type order struct {
+	events.AggregateRoot
+	ID string
+}
+
+func newUnitOfWork(t *testing.T, rec *recorder, withDB bool) (*UnitOfWork, *database.DB) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	if !withDB {
+		return New(nil, rec, logger), nil
+	}
+	sqlDB := sql.OpenDB(rec)
+	t.Cleanup(func() { sqlDB.Close() })
+	db := database.New(sqlDB, logger, nil, cfg)
+	return New(db, rec, logger), db
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	errFailed := stderrors.New("failed")
+
+	tests := []struct {
+		name    string
+		withDB  bool
+		outbox  bool
+		err     error // returned by the publisher and the outbox
+		work    func(ctx context.Context, db *database.DB) error
+		wantErr error
+		want    []string
+	}{
+		{
+			name:   "published after commit",
+			withDB: true,
+			work: func(ctx context.Context, db *database.DB) error {
+				o := &order{ID: "o-1"}
+				o.RecordEvent("placed")
+				Track(ctx, o)
+				if _, err := db.Exec(ctx, "INSERT"); err != nil {
+					return err
+				}
+				Record(ctx, "counted")
+				o.RecordEvent("paid")
+				return nil
+			},
+			want: []string{"begin", "INSERT", "commit", "publish placed", "publish paid", "publish counted"},
+		},
+		{
+			name:   "rolled back",
+			withDB: true,
+			work: func(ctx context.Context, db *database.DB) error {
+				Record(ctx, "placed")
+				return errFailed
+			},
+			wantErr: errFailed,
+			want:    []string{"begin", "rollback"},
+		},
+		{
+			name:   "nested work joins",
+			withDB: true,
+			work: func(ctx context.Context, db *database.DB) error {
+				Record(ctx, "outer")
+				u, _ := newUnitOfWork(t, &recorder{}, false)
+				return u.Do(ctx, func(ctx context.Context) error {
+					Record(ctx, "inner")
+					return nil
+				})
+			},
+			want: []string{"begin", "commit", "publish outer", "publish inner"},
+		},
+		{
+			name: "without database",
+			work: func(ctx context.Context, db *database.DB) error {
+				Record(ctx, "placed")
+				return nil
+			},
+			want: []string{"publish placed"},
+		},
+		{
+			name:   "publish errors are logged",
+			withDB: true,
+			err:    errFailed,
+			work: func(ctx context.Context, db *database.DB) error {
+				Record(ctx, "placed", "paid")
+				return nil
+			},
+			want: []string{"begin", "commit", "publish placed", "publish paid"},
+		},
+		{
+			name:   "outbox",
+			withDB: true,
+			outbox: true,
+			work: func(ctx context.Context, db *database.DB) error {
+				Record(ctx, "placed")
+				return nil
+			},
+			want: []string{"begin", "store in transaction", "store placed", "commit"},
+		},
+		{
+			name:   "outbox failure rolls back",
+			withDB: true,
+			outbox: true,
+			err:    errFailed,
+			work: func(ctx context.Context, db *database.DB) error {
+				Record(ctx, "placed")
+				return nil
+			},
+			wantErr: errFailed,
+			want:    []string{"begin", "store in transaction", "store placed", "rollback"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{err: tt.err}
+			u, db := newUnitOfWork(t, rec, tt.withDB)
+			if tt.outbox {
+				u.WithOutbox(rec)
+			}
+			err := u.Do(ctx, func(ctx context.Context) error {
+				assert.True(t, InWork(ctx))
+				return tt.work(ctx, db)
+			})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, rec.entries())
+		})
+	}
+}
+
+func TestDoInTransaction(t *testing.T) {
+	rec := &recorder{}
+	u, db := newUnitOfWork(t, rec, true)
+	err := db.WithTransaction(context.Background(), func(ctx context.Context, _ *sql.Tx) error {
+		return u.Do(ctx, func(ctx context.Context) error {
+			Record(ctx, "placed")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"begin", "publish in work", "publish placed", "commit"}, rec.entries(), "work joins the transaction")
+}
+
+func TestOutsideOfWork(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, InWork(ctx))
+
+	o := &order{ID: "o-1"}
+	o.RecordEvent("placed")
+	Track(ctx, o)
+	Record(ctx, "counted")
+	assert.Equal(t, []any{"placed"}, o.RecordedEvents(), "events stay recorded")
+}
@@ -0,0 +1,103 @@
+package validatorsdk
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry errors
+var (
+	ErrDuplicateRule = errors.New("validator rule already registered")
+	ErrInvalidRule   = errors.New("invalid validator rule")
+)
+
+// rulePattern is the format of rule IDs, e.g. house/no-println
+var rulePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*(/[a-z0-9][a-z0-9-]*)+$`)
+
+// reservedGroups are the rule ID prefixes of the built-in validators
+var reservedGroups = []string{"architecture", "naming", "domain"}
+
+// Registry holds the rules run by axiomod validator run
+type Registry struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{rules: make(map[string]Rule)}
+}
+
+// Register adds rules to the registry. Nothing is registered if a rule ID is invalid, belongs to
+// a built-in validator or is already registered.
+func (r *Registry) Register(rules ...Rule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule == nil {
+			return fmt.Errorf("%w: rule is nil", ErrInvalidRule)
+		}
+		id := rule.ID()
+		if !rulePattern.MatchString(id) {
+			return fmt.Errorf("%w: ID %q must be lowercase kebab-case with a group, e.g. house/no-println", ErrInvalidRule, id)
+		}
+		group, _, _ := strings.Cut(id, "/")
+		for _, reserved := range reservedGroups {
+			if group == reserved {
+				return fmt.Errorf("%w: %s uses the group of the built-in %s validator", ErrInvalidRule, id, reserved)
+			}
+		}
+		if _, ok := r.rules[id]; ok || seen[id] {
+			return fmt.Errorf("%w: %s", ErrDuplicateRule, id)
+		}
+		seen[id] = true
+	}
+
+	for _, rule := range rules {
+		r.rules[rule.ID()] = rule
+	}
+	return nil
+}
+
+// Lookup returns the rule with an ID
+func (r *Registry) Lookup(id string) (Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[id]
+	return rule, ok
+}
+
+// Rules returns the registered rules sorted by ID
+func (r *Registry) Rules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID() < rules[j].ID() })
+	return rules
+}
+
+// DefaultRegistry is the registry used by Register and run by axiomod validator run
+var DefaultRegistry = NewRegistry()
+
+// Register adds rules to the default registry
+func Register(rules ...Rule) error {
+	return DefaultRegistry.Register(rules...)
+}
+
+// MustRegister adds rules to the default registry and panics if they are invalid, for init
+// functions of rule packages
+func MustRegister(rules ...Rule) {
+	if err := Register(rules...); err != nil {
+		panic(err)
+	}
+}
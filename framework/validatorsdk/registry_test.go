@@ -0,0 +1,109 @@
+package validatorsdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noFindings(ctx context.Context, pkg *Package) []Finding { return nil }
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr error
+	}{
+		{
+			name:  "rules with a group are registered",
+			rules: []Rule{NewRule("house/no-println", "No fmt.Println", noFindings)},
+		},
+		{
+			name:  "rules may have nested groups",
+			rules: []Rule{NewRule("payments/http/idempotency-key", "Handlers read the idempotency key", noFindings)},
+		},
+		{
+			name:    "rule IDs need a group",
+			rules:   []Rule{NewRule("no-println", "No fmt.Println", noFindings)},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name:    "rule IDs are lowercase",
+			rules:   []Rule{NewRule("House/NoPrintln", "No fmt.Println", noFindings)},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name:    "built-in validator groups are reserved",
+			rules:   []Rule{NewRule("naming/house-style", "House naming", noFindings)},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name:    "nil rules are invalid",
+			rules:   []Rule{nil},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name:    "rules may not be registered twice",
+			rules:   []Rule{NewRule("house/a", "A", noFindings), NewRule("house/a", "A", noFindings)},
+			wantErr: ErrDuplicateRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			err := registry.Register(tt.rules...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, registry.Rules(), "nothing is registered when a rule is invalid")
+				return
+			}
+			require.NoError(t, err)
+			for _, rule := range tt.rules {
+				registered, ok := registry.Lookup(rule.ID())
+				assert.True(t, ok)
+				assert.Equal(t, rule, registered)
+			}
+		})
+	}
+}
+
+func TestRegistryRegisterTwice(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(NewRule("house/a", "A", noFindings)))
+
+	err := registry.Register(NewRule("house/b", "B", noFindings), NewRule("house/a", "A", noFindings))
+	assert.ErrorIs(t, err, ErrDuplicateRule)
+
+	_, ok := registry.Lookup("house/b")
+	assert.False(t, ok, "nothing is registered when a rule is already registered")
+}
+
+func TestRegistryRules(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(
+		NewRule("house/b", "B", noFindings),
+		NewRule("api/a", "A", noFindings),
+		NewRule("house/a", "A", noFindings),
+	))
+
+	var ids []string
+	for _, rule := range registry.Rules() {
+		ids = append(ids, rule.ID())
+	}
+	assert.Equal(t, []string{"api/a", "house/a", "house/b"}, ids)
+}
+
+func TestMustRegister(t *testing.T) {
+	original := DefaultRegistry
+	DefaultRegistry = NewRegistry()
+	defer func() { DefaultRegistry = original }()
+
+	MustRegister(NewRule("house/a", "A", noFindings))
+	_, ok := DefaultRegistry.Lookup("house/a")
+	assert.True(t, ok)
+
+	assert.Panics(t, func() { MustRegister(NewRule("house/a", "A", noFindings)) })
+}
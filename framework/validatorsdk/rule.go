@@ -0,0 +1,109 @@
+// Package validatorsdk is the API of project-specific validator rules. A rule package registers
+// its rules in an init function, and `axiomod validator run --rules=./rules/...` builds a program
+// importing the rule packages, runs their rules on the project packages and reports the findings
+// with the built-in validators' severities, suppression comments, baselines and report formats.
+//
+//	func init() {
+//		validatorsdk.MustRegister(validatorsdk.NewFileRule("house/no-println",
+//			"Services log with the logger instead of fmt.Println",
+//			func(ctx context.Context, pkg *validatorsdk.Package, file *ast.File) []validatorsdk.Finding {
+//				...
+//			}))
+//	}
+package validatorsdk
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// Levels of the findings
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// Rule is a project-specific check of packages
+type Rule interface {
+	// ID identifies the rule in reports, severities and suppression comments, e.g. house/no-println
+	ID() string
+	// Description explains what the rule enforces
+	Description() string
+	// Check returns the findings of the rule in a package
+	Check(ctx context.Context, pkg *Package) []Finding
+}
+
+// Finding is an issue reported by a rule
+type Finding struct {
+	// RuleID is set to the ID of the rule reporting the finding when empty
+	RuleID string `json:"ruleId"`
+	// Level is error or warning; empty means error. The rule configuration of the project
+	// can change it.
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// Package is a package checked by the rules, with its syntax and type information
+type Package struct {
+	// Path is the import path of the package
+	Path string
+	Name string
+	// Module is the path of the module of the package
+	Module string
+	Fset   *token.FileSet
+	// Files are the Go files of the package, test files included
+	Files     []*ast.File
+	Types     *types.Package
+	TypesInfo *types.Info
+}
+
+// Finding returns an error finding at a position of the package
+func (p *Package) Finding(pos token.Pos, format string, args ...any) Finding {
+	position := p.Fset.Position(pos)
+	return Finding{
+		Level:   LevelError,
+		Message: fmt.Sprintf(format, args...),
+		File:    position.Filename,
+		Line:    position.Line,
+		Column:  position.Column,
+	}
+}
+
+// funcRule is a rule implemented by a function
+type funcRule struct {
+	id          string
+	description string
+	check       func(ctx context.Context, pkg *Package) []Finding
+}
+
+func (r *funcRule) ID() string          { return r.id }
+func (r *funcRule) Description() string { return r.description }
+
+func (r *funcRule) Check(ctx context.Context, pkg *Package) []Finding {
+	return r.check(ctx, pkg)
+}
+
+// NewRule creates a rule checking packages with a function
+func NewRule(id, description string, check func(ctx context.Context, pkg *Package) []Finding) Rule {
+	return &funcRule{id: id, description: description, check: check}
+}
+
+// NewFileRule creates a rule checking each file of the packages with a function
+func NewFileRule(id, description string, check func(ctx context.Context, pkg *Package, file *ast.File) []Finding) Rule {
+	return NewRule(id, description, func(ctx context.Context, pkg *Package) []Finding {
+		var findings []Finding
+		for _, file := range pkg.Files {
+			if ctx.Err() != nil {
+				break
+			}
+			findings = append(findings, check(ctx, pkg, file)...)
+		}
+		return findings
+	})
+}
@@ -0,0 +1,135 @@
+package validatorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// RuleInfo describes a rule in a Result
+type RuleInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// Result is the output of Main, read by axiomod validator run
+type Result struct {
+	Rules    []RuleInfo `json:"rules"`
+	Findings []Finding  `json:"findings"`
+}
+
+// Run loads the packages matching patterns in dir, ./... by default, with their syntax and types,
+// and checks them with the rules of the registry. Findings are sorted by position.
+func (r *Registry) Run(ctx context.Context, dir string, patterns ...string) ([]Finding, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	config := &packages.Config{
+		Context: ctx,
+		Dir:     dir,
+		Mode:    loadMode(ctx, dir),
+		Tests:   true,
+	}
+	pkgs, err := packages.Load(config, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	rules := r.Rules()
+	findings := []Finding{}
+	seen := make(map[Finding]bool)
+	for _, pkg := range pkgs {
+		// Skip the generated test mains and the packages that could not be parsed
+		if strings.HasSuffix(pkg.ID, ".test") || len(pkg.Syntax) == 0 {
+			continue
+		}
+		checked := &Package{
+			Path:      pkg.PkgPath,
+			Name:      pkg.Name,
+			Fset:      pkg.Fset,
+			Files:     pkg.Syntax,
+			Types:     pkg.Types,
+			TypesInfo: pkg.TypesInfo,
+		}
+		if pkg.Module != nil {
+			checked.Module = pkg.Module.Path
+		}
+
+		for _, rule := range rules {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for _, finding := range rule.Check(ctx, checked) {
+				if finding.RuleID == "" {
+					finding.RuleID = rule.ID()
+				}
+				if finding.Level != LevelWarning {
+					finding.Level = LevelError
+				}
+				// A package and its test variant share their files, so their findings are reported once
+				if !seen[finding] {
+					seen[finding] = true
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.RuleID < b.RuleID
+	})
+	return findings, nil
+}
+
+// loadMode returns the mode loading packages with their syntax and types. The types of the
+// dependencies are read from their export data, unless the toolchain writes export data the
+// loader cannot read, in which case the dependencies are type-checked from source.
+func loadMode(ctx context.Context, dir string) packages.LoadMode {
+	mode := packages.LoadSyntax | packages.NeedModule
+	probe, err := packages.Load(&packages.Config{Context: ctx, Dir: dir, Mode: packages.NeedName | packages.NeedTypes}, "errors")
+	if err != nil || len(probe) != 1 || len(probe[0].Errors) > 0 || probe[0].Types == nil || !probe[0].Types.Complete() {
+		mode |= packages.NeedDeps
+	}
+	return mode
+}
+
+// Main runs the rules of the default registry on the packages matching the arguments, ./... by
+// default, and writes a Result as JSON to stdout. It is the main function of the program built by
+// axiomod validator run.
+func Main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	findings, err := DefaultRegistry.Run(ctx, "", os.Args[1:]...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running validator rules: %v\n", err)
+		os.Exit(2)
+	}
+
+	result := Result{Rules: []RuleInfo{}, Findings: findings}
+	for _, rule := range DefaultRegistry.Rules() {
+		result.Rules = append(result.Rules, RuleInfo{ID: rule.ID(), Description: rule.Description()})
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing validator findings: %v\n", err)
+		os.Exit(2)
+	}
+}
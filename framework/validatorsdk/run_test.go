@@ -0,0 +1,67 @@
+package validatorsdk
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noPrintln reports the calls of fmt.Println, resolved with the type information
+var noPrintln = NewFileRule("house/no-println", "Services log with the logger instead of fmt.Println",
+	func(ctx context.Context, pkg *Package, file *ast.File) []Finding {
+		var findings []Finding
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if fn, ok := pkg.TypesInfo.Uses[selector.Sel].(*types.Func); ok && fn.FullName() == "fmt.Println" {
+				findings = append(findings, pkg.Finding(call.Pos(), "use the logger instead of fmt.Println"))
+			}
+			return true
+		})
+		return findings
+	})
+
+func TestRegistryRun(t *testing.T) {
+	packageNames := NewRule("house/package-name", "Reports the package names", func(ctx context.Context, pkg *Package) []Finding {
+		return []Finding{{Level: LevelWarning, Message: "package " + pkg.Name}}
+	})
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(noPrintln, packageNames))
+
+	findings, err := registry.Run(context.Background(), "", "./testdata/sample")
+	require.NoError(t, err)
+
+	sample, err := filepath.Abs(filepath.Join("testdata", "sample", "sample.go"))
+	require.NoError(t, err)
+	sampleTest, err := filepath.Abs(filepath.Join("testdata", "sample", "sample_test.go"))
+	require.NoError(t, err)
+
+	// The package and its test variant report their shared findings once
+	assert.Equal(t, []Finding{
+		{RuleID: "house/package-name", Level: LevelWarning, Message: "package sample"},
+		{RuleID: "house/no-println", Level: LevelError, Message: "use the logger instead of fmt.Println", File: sample, Line: 7, Column: 2},
+		{RuleID: "house/no-println", Level: LevelError, Message: "use the logger instead of fmt.Println", File: sampleTest, Line: 9, Column: 2},
+	}, findings)
+}
+
+func TestRegistryRunCanceled(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(noPrintln))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := registry.Run(ctx, "", "./testdata/sample")
+	assert.Error(t, err)
+}
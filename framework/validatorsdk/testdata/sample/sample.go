@@ -0,0 +1,8 @@
+package sample
+
+import "fmt"
+
+// Greet prints a greeting
+func Greet(name string) {
+	fmt.Println("hello", name)
+}
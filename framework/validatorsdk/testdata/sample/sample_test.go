@@ -0,0 +1,11 @@
+package sample
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGreet(t *testing.T) {
+	fmt.Println("testing")
+	Greet("test")
+}
@@ -0,0 +1,298 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	fws "github.com/gofiber/contrib/websocket"
+)
+
+// Message types
+const (
+	TextMessage   = fws.TextMessage
+	BinaryMessage = fws.BinaryMessage
+)
+
+// Close codes commonly used by handlers
+const (
+	CloseNormalClosure     = fws.CloseNormalClosure
+	CloseGoingAway         = fws.CloseGoingAway
+	ClosePolicyViolation   = fws.ClosePolicyViolation
+	CloseInternalServerErr = fws.CloseInternalServerErr
+	CloseTryAgainLater     = fws.CloseTryAgainLater
+)
+
+// Common errors
+var (
+	ErrConnClosed      = errors.New("websocket connection closed")
+	ErrSendBufferFull  = errors.New("websocket send buffer full")
+	ErrHubShuttingDown = errors.New("websocket hub is shutting down")
+)
+
+// Claims holds the identity of the authenticated user that opened a connection
+type Claims struct {
+	UserID   string
+	Username string
+	Email    string
+	Roles    []string
+	TenantID string
+}
+
+type contextKey int
+
+const (
+	connKey contextKey = iota
+	claimsKey
+)
+
+// ConnFromContext returns the connection a context belongs to
+func ConnFromContext(ctx context.Context) (*Conn, bool) {
+	conn, ok := ctx.Value(connKey).(*Conn)
+	return conn, ok
+}
+
+// ClaimsFromContext returns the claims of the user that opened the connection
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(Claims)
+	return claims, ok
+}
+
+// message is an outgoing message queued for the write loop
+type message struct {
+	kind int
+	data []byte
+}
+
+// Conn is a WebSocket connection managed by a Hub. Writes are queued and performed by a single
+// writer goroutine, so Send is safe to call from any goroutine.
+type Conn struct {
+	id       string
+	ws       *fws.Conn
+	hub      *Hub
+	ctx      context.Context
+	cancel   context.CancelFunc
+	send     chan message
+	claims   Claims
+	rooms    map[string]struct{} // guarded by hub.mu
+	closing  sync.Once
+	closeMsg []byte
+	closeCh  chan struct{}
+	writerCh chan struct{}
+}
+
+func newConn(id string, ws *fws.Conn, hub *Hub) *Conn {
+	claims := claimsFromLocals(ws)
+	c := &Conn{
+		id:       id,
+		ws:       ws,
+		hub:      hub,
+		send:     make(chan message, hub.settings.sendBuffer),
+		claims:   claims,
+		rooms:    make(map[string]struct{}),
+		closeCh:  make(chan struct{}),
+		writerCh: make(chan struct{}),
+	}
+	ctx := context.WithValue(hub.ctx, claimsKey, claims)
+	c.ctx, c.cancel = context.WithCancel(context.WithValue(ctx, connKey, c))
+	return c
+}
+
+// claimsFromLocals copies the identity set by the auth middleware before the upgrade
+func claimsFromLocals(ws *fws.Conn) Claims {
+	var claims Claims
+	claims.UserID, _ = ws.Locals("user_id").(string)
+	claims.Username, _ = ws.Locals("username").(string)
+	claims.Email, _ = ws.Locals("email").(string)
+	claims.Roles, _ = ws.Locals("roles").([]string)
+	claims.TenantID, _ = ws.Locals("tenant_id").(string)
+	return claims
+}
+
+// ID returns the unique identifier of the connection
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// Context returns a context carrying the connection and its claims.
+// It is canceled when the connection closes.
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Claims returns the identity of the user that opened the connection
+func (c *Conn) Claims() Claims {
+	return c.claims
+}
+
+// Params returns a route parameter of the upgrade request
+func (c *Conn) Params(key string, defaultValue ...string) string {
+	return c.ws.Params(key, defaultValue...)
+}
+
+// Query returns a query parameter of the upgrade request
+func (c *Conn) Query(key string, defaultValue ...string) string {
+	return c.ws.Query(key, defaultValue...)
+}
+
+// IP returns the client address of the upgrade request
+func (c *Conn) IP() string {
+	return c.ws.IP()
+}
+
+// Send queues a text message. It fails with ErrSendBufferFull rather than block on a slow client.
+func (c *Conn) Send(data []byte) error {
+	return c.enqueue(message{kind: TextMessage, data: data})
+}
+
+// SendBinary queues a binary message
+func (c *Conn) SendBinary(data []byte) error {
+	return c.enqueue(message{kind: BinaryMessage, data: data})
+}
+
+// SendJSON encodes v and queues it as a text message
+func (c *Conn) SendJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Send(data)
+}
+
+func (c *Conn) enqueue(msg message) error {
+	select {
+	case <-c.ctx.Done():
+		return ErrConnClosed
+	case <-c.closeCh:
+		return ErrConnClosed
+	default:
+	}
+	select {
+	case c.send <- msg:
+		return nil
+	case <-c.ctx.Done():
+		return ErrConnClosed
+	default:
+		return ErrSendBufferFull
+	}
+}
+
+// Close starts the closing handshake with the given code and reason once the messages already
+// queued are written. The connection is dropped if the client does not answer within the write timeout.
+func (c *Conn) Close(code int, reason string) {
+	c.closing.Do(func() {
+		c.closeMsg = fws.FormatCloseMessage(code, reason)
+		close(c.closeCh)
+		// Bound the wait for the client's answer in case the write loop is stuck
+		_ = c.ws.SetReadDeadline(time.Now().Add(2 * c.hub.settings.writeTimeout))
+	})
+}
+
+// Join adds the connection to a room
+func (c *Conn) Join(room string) {
+	c.hub.join(c, room)
+}
+
+// Leave removes the connection from a room
+func (c *Conn) Leave(room string) {
+	c.hub.leave(c, room)
+}
+
+// readLoop reads messages until the connection fails or closes
+func (c *Conn) readLoop(onMessage MessageHandler) error {
+	s := c.hub.settings
+	if s.maxMessageSize > 0 {
+		c.ws.SetReadLimit(s.maxMessageSize)
+	}
+	_ = c.ws.SetReadDeadline(time.Now().Add(s.pongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		select {
+		case <-c.closeCh:
+			// Keep the closing deadline
+			return nil
+		default:
+			return c.ws.SetReadDeadline(time.Now().Add(s.pongTimeout))
+		}
+	})
+
+	for {
+		kind, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if fws.IsUnexpectedCloseError(err, fws.CloseNormalClosure, fws.CloseGoingAway, fws.CloseNoStatusReceived) {
+				return err
+			}
+			return nil
+		}
+		if onMessage == nil {
+			continue
+		}
+		if err := onMessage(c, kind, data); err != nil {
+			return err
+		}
+	}
+}
+
+// awaitClose discards messages until the client answers the closing handshake or the deadline passes
+func (c *Conn) awaitClose() {
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeLoop writes queued messages and keepalive pings until the connection context ends
+func (c *Conn) writeLoop() {
+	defer close(c.writerCh)
+	s := c.hub.settings
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+			if err := c.ws.WriteMessage(msg.kind, msg.data); err != nil {
+				c.abort()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(fws.PingMessage, nil, time.Now().Add(s.writeTimeout)); err != nil {
+				c.abort()
+				return
+			}
+		case <-c.closeCh:
+			c.flush()
+			deadline := time.Now().Add(s.writeTimeout)
+			_ = c.ws.WriteControl(fws.CloseMessage, c.closeMsg, deadline)
+			// Wake the read loop if the client never answers
+			_ = c.ws.SetReadDeadline(deadline)
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// flush writes the messages still queued without waiting for new ones
+func (c *Conn) flush() {
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(c.hub.settings.writeTimeout))
+			if err := c.ws.WriteMessage(msg.kind, msg.data); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// abort cancels the connection and unblocks the read loop immediately
+func (c *Conn) abort() {
+	c.cancel()
+	_ = c.ws.SetReadDeadline(time.Now())
+}
@@ -0,0 +1,315 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	fws "github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Default connection settings
+const (
+	DefaultPingInterval    = 30 * time.Second
+	DefaultPongTimeout     = 60 * time.Second
+	DefaultWriteTimeout    = 10 * time.Second
+	DefaultMaxMessageSize  = 64 * 1024
+	DefaultSendBuffer      = 256
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// MessageHandler handles a message received on a connection. Returning an error closes the connection.
+type MessageHandler func(conn *Conn, messageType int, data []byte) error
+
+// Handlers are the callbacks of a WebSocket route
+type Handlers struct {
+	// OnConnect is called once the connection is registered. Returning an error closes it.
+	OnConnect func(conn *Conn) error
+	// OnMessage is called for every message received
+	OnMessage MessageHandler
+	// OnClose is called after the connection left the hub
+	OnClose func(conn *Conn)
+}
+
+// settings are the resolved connection settings of a hub
+type settings struct {
+	pingInterval    time.Duration
+	pongTimeout     time.Duration
+	writeTimeout    time.Duration
+	maxMessageSize  int64
+	sendBuffer      int
+	shutdownTimeout time.Duration
+	origins         []string
+}
+
+func newSettings(cfg config.WebSocketConfig) settings {
+	s := settings{
+		pingInterval:    DefaultPingInterval,
+		pongTimeout:     DefaultPongTimeout,
+		writeTimeout:    DefaultWriteTimeout,
+		maxMessageSize:  DefaultMaxMessageSize,
+		sendBuffer:      DefaultSendBuffer,
+		shutdownTimeout: DefaultShutdownTimeout,
+		origins:         cfg.Origins,
+	}
+	if cfg.PingInterval > 0 {
+		s.pingInterval = time.Duration(cfg.PingInterval) * time.Second
+	}
+	if cfg.PongTimeout > 0 {
+		s.pongTimeout = time.Duration(cfg.PongTimeout) * time.Second
+	}
+	if cfg.WriteTimeout > 0 {
+		s.writeTimeout = time.Duration(cfg.WriteTimeout) * time.Second
+	}
+	if cfg.MaxMessageSize > 0 {
+		s.maxMessageSize = cfg.MaxMessageSize
+	}
+	if cfg.SendBuffer > 0 {
+		s.sendBuffer = cfg.SendBuffer
+	}
+	if cfg.ShutdownTimeout > 0 {
+		s.shutdownTimeout = time.Duration(cfg.ShutdownTimeout) * time.Second
+	}
+	// Pings must arrive before the read deadline they extend expires
+	if s.pingInterval >= s.pongTimeout {
+		s.pingInterval = s.pongTimeout * 9 / 10
+	}
+	return s
+}
+
+// Hub tracks open WebSocket connections and the rooms they joined, and broadcasts messages to them
+type Hub struct {
+	settings settings
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.RWMutex
+	conns    map[*Conn]struct{}
+	rooms    map[string]map[*Conn]struct{}
+	closing  bool
+	active   sync.WaitGroup
+	logger   *observability.Logger
+}
+
+// NewHub creates a new Hub from the WebSocket configuration
+func NewHub(cfg *config.Config, logger *observability.Logger) *Hub {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Hub{
+		settings: newSettings(cfg.HTTP.WebSocket),
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    make(map[*Conn]struct{}),
+		rooms:    make(map[string]map[*Conn]struct{}),
+		logger:   logger,
+	}
+}
+
+// Handler returns a Fiber handler that upgrades requests to WebSocket connections served by handlers.
+// Requests that are not upgrade requests get 426 Upgrade Required, and new connections are refused
+// with 503 Service Unavailable once the hub is shutting down. Authentication middleware running
+// before the handler makes the user's claims available through Conn.Claims.
+func (h *Hub) Handler(handlers Handlers) fiber.Handler {
+	upgrade := fws.New(func(ws *fws.Conn) {
+		h.serve(ws, handlers)
+	}, fws.Config{
+		Origins:        h.settings.origins,
+		RecoverHandler: h.recoverPanic,
+	})
+
+	return func(c *fiber.Ctx) error {
+		if !fws.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+		h.mu.RLock()
+		closing := h.closing
+		h.mu.RUnlock()
+		if closing {
+			return fiber.NewError(fiber.StatusServiceUnavailable, ErrHubShuttingDown.Error())
+		}
+		return upgrade(c)
+	}
+}
+
+// serve runs a connection until it closes
+func (h *Hub) serve(ws *fws.Conn, handlers Handlers) {
+	conn := newConn(uuid.NewString(), ws, h)
+	if !h.register(conn) {
+		_ = ws.WriteControl(fws.CloseMessage, fws.FormatCloseMessage(CloseGoingAway, ErrHubShuttingDown.Error()),
+			time.Now().Add(h.settings.writeTimeout))
+		return
+	}
+	defer h.active.Done()
+
+	go conn.writeLoop()
+	defer func() {
+		conn.cancel()
+		// The underlying connection is released once serve returns, so wait for the writer
+		<-conn.writerCh
+		h.unregister(conn)
+		if handlers.OnClose != nil {
+			handlers.OnClose(conn)
+		}
+	}()
+
+	if handlers.OnConnect != nil {
+		if err := handlers.OnConnect(conn); err != nil {
+			h.logger.Debug("WebSocket connection rejected", zap.String("conn_id", conn.id), zap.Error(err))
+			conn.Close(ClosePolicyViolation, err.Error())
+			conn.awaitClose()
+			return
+		}
+	}
+
+	if err := conn.readLoop(handlers.OnMessage); err != nil {
+		h.logger.Debug("WebSocket connection closed with error", zap.String("conn_id", conn.id), zap.Error(err))
+		conn.Close(CloseInternalServerErr, "")
+		conn.awaitClose()
+	}
+}
+
+// recoverPanic logs a panic raised by a handler instead of writing it to the client
+func (h *Hub) recoverPanic(ws *fws.Conn) {
+	if r := recover(); r != nil {
+		h.logger.Error("Panic in WebSocket handler", zap.Any("panic", r), zap.Stack("stack"))
+	}
+}
+
+func (h *Hub) register(conn *Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closing {
+		return false
+	}
+	h.conns[conn] = struct{}{}
+	h.active.Add(1)
+	return true
+}
+
+func (h *Hub) unregister(conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, conn)
+	for room := range conn.rooms {
+		h.removeFromRoom(conn, room)
+	}
+}
+
+func (h *Hub) join(conn *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[conn]; !ok {
+		return
+	}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*Conn]struct{})
+		h.rooms[room] = members
+	}
+	members[conn] = struct{}{}
+	conn.rooms[room] = struct{}{}
+}
+
+func (h *Hub) leave(conn *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeFromRoom(conn, room)
+}
+
+// removeFromRoom must be called with h.mu held
+func (h *Hub) removeFromRoom(conn *Conn, room string) {
+	delete(conn.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, conn)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Count returns the number of open connections
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// RoomCount returns the number of connections in a room
+func (h *Hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Broadcast sends a text message to every open connection
+func (h *Hub) Broadcast(data []byte) {
+	h.mu.RLock()
+	targets := make([]*Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		targets = append(targets, conn)
+	}
+	h.mu.RUnlock()
+	h.deliver(targets, data)
+}
+
+// BroadcastRoom sends a text message to every connection in a room
+func (h *Hub) BroadcastRoom(room string, data []byte) {
+	h.mu.RLock()
+	targets := make([]*Conn, 0, len(h.rooms[room]))
+	for conn := range h.rooms[room] {
+		targets = append(targets, conn)
+	}
+	h.mu.RUnlock()
+	h.deliver(targets, data)
+}
+
+// deliver queues data on each connection, closing connections too slow to keep up
+func (h *Hub) deliver(targets []*Conn, data []byte) {
+	for _, conn := range targets {
+		if err := conn.Send(data); err == ErrSendBufferFull {
+			h.logger.Warn("Closing slow WebSocket connection", zap.String("conn_id", conn.id))
+			conn.Close(CloseTryAgainLater, "too slow")
+		}
+	}
+}
+
+// Shutdown stops accepting connections, asks every open connection to close and waits for them to
+// drain. Connections still open when ctx ends are dropped.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	conns := make([]*Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close(CloseGoingAway, "server shutting down")
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		h.cancel()
+		return nil
+	case <-ctx.Done():
+		h.logger.Warn("Dropping WebSocket connections that did not close in time", zap.Int("count", h.Count()))
+		h.mu.RLock()
+		for conn := range h.conns {
+			conn.abort()
+		}
+		h.mu.RUnlock()
+		h.cancel()
+		return ctx.Err()
+	}
+}
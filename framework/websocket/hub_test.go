@@ -0,0 +1,199 @@
+package websocket
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHub serves a chat-like endpoint on a real listener and returns its WebSocket URL
+func startHub(t *testing.T, hub *Hub) string {
+	t.Helper()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(func(c *fiber.Ctx) error {
+		if user := c.Get("X-User"); user != "" {
+			c.Locals("user_id", user)
+			c.Locals("roles", []string{"member"})
+		}
+		return c.Next()
+	})
+	app.Get("/ws/:room", hub.Handler(Handlers{
+		OnConnect: func(conn *Conn) error {
+			if conn.Claims().UserID == "" {
+				return fiber.ErrUnauthorized
+			}
+			conn.Join(conn.Params("room"))
+			return nil
+		},
+		OnMessage: func(conn *Conn, _ int, data []byte) error {
+			claims, _ := ClaimsFromContext(conn.Context())
+			hub.BroadcastRoom(conn.Params("room"), []byte(claims.UserID+": "+string(data)))
+			return nil
+		},
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+	return "ws://" + ln.Addr().String() + "/ws/"
+}
+
+func dial(t *testing.T, url, user string) *websocket.Conn {
+	t.Helper()
+	header := http.Header{}
+	header.Set("X-User", user)
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func read(t *testing.T, conn *websocket.Conn) (string, error) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	return string(data), err
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	assert.Eventually(t, cond, 2*time.Second, 10*time.Millisecond)
+}
+
+func newTestHub(t *testing.T) *Hub {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	return NewHub(cfg, logger)
+}
+
+func TestHubRooms(t *testing.T) {
+	hub := newTestHub(t)
+	url := startHub(t, hub)
+
+	alice := dial(t, url+"general", "alice")
+	bob := dial(t, url+"general", "bob")
+	carol := dial(t, url+"random", "carol")
+	waitFor(t, func() bool { return hub.RoomCount("general") == 2 })
+	assert.Equal(t, 3, hub.Count())
+
+	require.NoError(t, alice.WriteMessage(websocket.TextMessage, []byte("hello")))
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		msg, err := read(t, conn)
+		require.NoError(t, err)
+		assert.Equal(t, "alice: hello", msg)
+	}
+
+	hub.Broadcast([]byte("maintenance soon"))
+	msg, err := read(t, carol)
+	require.NoError(t, err)
+	assert.Equal(t, "maintenance soon", msg, "carol only receives hub-wide broadcasts")
+
+	require.NoError(t, bob.Close())
+	waitFor(t, func() bool { return hub.RoomCount("general") == 1 })
+}
+
+func TestHubRejectsConnections(t *testing.T) {
+	hub := newTestHub(t)
+	url := startHub(t, hub)
+
+	t.Run("plain HTTP requests need an upgrade", func(t *testing.T) {
+		resp, err := http.Get("http" + strings.TrimPrefix(url, "ws") + "general")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	})
+
+	t.Run("OnConnect errors close the connection", func(t *testing.T) {
+		conn := dial(t, url+"general", "")
+		_, err := read(t, conn)
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+		waitFor(t, func() bool { return hub.Count() == 0 })
+	})
+}
+
+func TestHubKeepalive(t *testing.T) {
+	hub := newTestHub(t)
+	hub.settings.pingInterval = 20 * time.Millisecond
+	hub.settings.pongTimeout = 100 * time.Millisecond
+	url := startHub(t, hub)
+
+	conn := dial(t, url+"general", "alice")
+	pings := make(chan struct{}, 100)
+	conn.SetPingHandler(func(string) error {
+		pings <- struct{}{}
+		return conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second))
+	})
+	// Control frames are only handled while reading
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Answering pings keeps the connection open well past the pong timeout
+	time.Sleep(300 * time.Millisecond)
+	assert.GreaterOrEqual(t, len(pings), 3)
+	assert.Equal(t, 1, hub.Count())
+
+	t.Run("silent clients are dropped", func(t *testing.T) {
+		silent := dial(t, url+"general", "bob")
+		silent.SetPingHandler(func(string) error { return nil })
+		go func() { _, _, _ = silent.ReadMessage() }()
+		waitFor(t, func() bool { return hub.Count() == 1 })
+	})
+}
+
+func TestHubShutdown(t *testing.T) {
+	hub := newTestHub(t)
+	url := startHub(t, hub)
+
+	alice := dial(t, url+"general", "alice")
+	waitFor(t, func() bool { return hub.Count() == 1 })
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := alice.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, hub.Shutdown(ctx))
+	assert.Equal(t, 0, hub.Count())
+
+	select {
+	case err := <-closed:
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("client was not asked to close")
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"general", http.Header{"X-User": {"bob"}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestSettingsDefaults(t *testing.T) {
+	s := newSettings(config.WebSocketConfig{PingInterval: 90, PongTimeout: 60})
+	assert.Equal(t, 54*time.Second, s.pingInterval, "pings must arrive before the pong timeout")
+	assert.Equal(t, DefaultWriteTimeout, s.writeTimeout)
+	assert.EqualValues(t, DefaultMaxMessageSize, s.maxMessageSize)
+}
@@ -0,0 +1,24 @@
+package websocket
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the fx options for the websocket module
+var Module = fx.Options(
+	fx.Provide(NewHub),
+	fx.Invoke(RegisterHub),
+)
+
+// RegisterHub drains the hub's connections when the application stops
+func RegisterHub(lc fx.Lifecycle, h *Hub) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, h.settings.shutdownTimeout)
+			defer cancel()
+			return h.Shutdown(ctx)
+		},
+	})
+}
@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes of job runs
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeTimeout = "timeout"
+	// OutcomeSkipped is a run of a singleton job skipped while another instance ran it. Skipped
+	// runs are counted on metrics but not recorded in the history.
+	OutcomeSkipped = "skipped"
+)
+
+// JobRun is a recorded run of a job
+type JobRun struct {
+	JobID     string
+	JobName   string
+	StartedAt time.Time
+	Duration  time.Duration
+	Outcome   string
+	Error     string // message of the error of failed and timed out runs
+}
+
+// History records the runs of jobs
+type History interface {
+	// Record stores a run
+	Record(ctx context.Context, run JobRun) error
+	// Runs returns the last runs of a job, or of every job if jobID is empty, newest first.
+	// A limit of 0 returns every stored run.
+	Runs(ctx context.Context, jobID string, limit int) ([]JobRun, error)
+}
+
+// DefaultHistorySize is the number of runs a MemoryHistory keeps by default
+const DefaultHistorySize = 1000
+
+// MemoryHistory keeps the last runs of the process in a ring buffer
+type MemoryHistory struct {
+	mu   sync.RWMutex
+	runs []JobRun
+	next int // index the next run is written at once the buffer is full
+}
+
+// NewMemoryHistory creates a history keeping the last size runs, DefaultHistorySize if
+// size is not positive
+func NewMemoryHistory(size int) *MemoryHistory {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &MemoryHistory{runs: make([]JobRun, 0, size)}
+}
+
+// Record stores a run, replacing the oldest one once the buffer is full
+func (h *MemoryHistory) Record(ctx context.Context, run JobRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.runs) < cap(h.runs) {
+		h.runs = append(h.runs, run)
+		return nil
+	}
+	h.runs[h.next] = run
+	h.next = (h.next + 1) % len(h.runs)
+	return nil
+}
+
+// Runs returns the last runs of a job, or of every job if jobID is empty, newest first
+func (h *MemoryHistory) Runs(ctx context.Context, jobID string, limit int) ([]JobRun, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var runs []JobRun
+	for i := 1; i <= len(h.runs); i++ {
+		run := h.runs[(h.next-i+len(h.runs))%len(h.runs)]
+		if jobID != "" && run.JobID != jobID {
+			continue
+		}
+		runs = append(runs, run)
+		if limit > 0 && len(runs) == limit {
+			break
+		}
+	}
+	return runs, nil
+}
+
+// DefaultHistoryTable is the table a PostgresHistory records runs in by default
+const DefaultHistoryTable = "job_runs"
+
+// tableName matches the table names accepted by NewPostgresHistory, optionally qualified
+// by a schema
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// PostgresHistory records runs in a PostgreSQL table, shared by every instance
+type PostgresHistory struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresHistory creates a history recording runs in table, DefaultHistoryTable if
+// empty. Migrate creates the table.
+func NewPostgresHistory(db *sql.DB, table string) (*PostgresHistory, error) {
+	if table == "" {
+		table = DefaultHistoryTable
+	}
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("worker: invalid history table name %q", table)
+	}
+	return &PostgresHistory{db: db, table: table}, nil
+}
+
+// Migrate creates the table of runs and its index if they do not exist
+func (h *PostgresHistory) Migrate(ctx context.Context) error {
+	index := strings.ReplaceAll(h.table, ".", "_") + "_job_started_idx"
+	_, err := h.db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id BIGSERIAL PRIMARY KEY,
+	job_id TEXT NOT NULL,
+	job_name TEXT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	duration_ms BIGINT NOT NULL,
+	outcome TEXT NOT NULL,
+	error TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS %[2]s ON %[1]s (job_id, started_at DESC);`, h.table, index))
+	if err != nil {
+		return fmt.Errorf("worker: failed to create history table: %w", err)
+	}
+	return nil
+}
+
+// Record inserts a run
+func (h *PostgresHistory) Record(ctx context.Context, run JobRun) error {
+	_, err := h.db.ExecContext(ctx,
+		"INSERT INTO "+h.table+" (job_id, job_name, started_at, duration_ms, outcome, error) VALUES ($1, $2, $3, $4, $5, $6)",
+		run.JobID, run.JobName, run.StartedAt, run.Duration.Milliseconds(), run.Outcome, run.Error)
+	if err != nil {
+		return fmt.Errorf("worker: failed to record job run: %w", err)
+	}
+	return nil
+}
+
+// Runs returns the last runs of a job, or of every job if jobID is empty, newest first
+func (h *PostgresHistory) Runs(ctx context.Context, jobID string, limit int) ([]JobRun, error) {
+	query := "SELECT job_id, job_name, started_at, duration_ms, outcome, error FROM " + h.table +
+		" WHERE ($1 = '' OR job_id = $1) ORDER BY started_at DESC"
+	args := []interface{}{jobID}
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("worker: failed to query job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []JobRun
+	for rows.Next() {
+		var run JobRun
+		var durationMs int64
+		if err := rows.Scan(&run.JobID, &run.JobName, &run.StartedAt, &durationMs, &run.Outcome, &run.Error); err != nil {
+			return nil, fmt.Errorf("worker: failed to scan job run: %w", err)
+		}
+		run.Duration = time.Duration(durationMs) * time.Millisecond
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
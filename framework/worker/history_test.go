@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryHistory(t *testing.T) {
+	ctx := context.Background()
+	history := NewMemoryHistory(3)
+	for _, id := range []string{"a", "b", "a", "b", "a"} {
+		require.NoError(t, history.Record(ctx, JobRun{JobID: id, Outcome: OutcomeSuccess}))
+	}
+
+	tests := []struct {
+		name  string
+		jobID string
+		limit int
+		want  []string
+	}{
+		{"keeps the last runs, newest first", "", 0, []string{"a", "b", "a"}},
+		{"filters by job", "a", 0, []string{"a", "a"}},
+		{"limits", "", 2, []string{"a", "b"}},
+		{"unknown job", "c", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := history.Runs(ctx, tt.jobID, tt.limit)
+			require.NoError(t, err)
+			var ids []string
+			for _, run := range runs {
+				ids = append(ids, run.JobID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestNewPostgresHistory(t *testing.T) {
+	history, err := NewPostgresHistory(&sql.DB{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultHistoryTable, history.table)
+
+	_, err = NewPostgresHistory(&sql.DB{}, "ops.job_runs")
+	assert.NoError(t, err)
+	_, err = NewPostgresHistory(&sql.DB{}, "job_runs; DROP TABLE users")
+	assert.Error(t, err)
+}
@@ -2,6 +2,14 @@ package worker
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/lock"
+	"github.com/axiomod/axiomod/platform/observability"
 
 	"go.uber.org/fx"
 )
@@ -9,7 +17,7 @@ import (
 // Module provides the fx options for the worker module
 var Module = fx.Options(
 	fx.Provide(New),
-	fx.Invoke(RegisterWorker),
+	fx.Invoke(RegisterWorker, RegisterClock, RegisterLocker, RegisterHistory, RegisterScheduleStore),
 )
 
 // RegisterWorker registers the worker with the fx lifecycle
@@ -21,3 +29,133 @@ func RegisterWorker(lc fx.Lifecycle, w *Worker) {
 		},
 	})
 }
+
+// ClockParams holds the clock jobs run on, if one is provided
+type ClockParams struct {
+	fx.In
+
+	Worker *Worker
+	Clock  clock.Clock `optional:"true"`
+}
+
+// RegisterClock makes jobs run on the provided clock, such as a fake clock of a test
+func RegisterClock(p ClockParams) {
+	if p.Clock != nil {
+		p.Worker.SetClock(p.Clock)
+	}
+}
+
+// LockerParams holds the locker of singleton jobs, if one is provided
+type LockerParams struct {
+	fx.In
+
+	Worker *Worker
+	Locker *lock.Locker `optional:"true"`
+}
+
+// RegisterLocker makes singleton jobs take their lock with the provided locker
+func RegisterLocker(p LockerParams) {
+	if p.Locker != nil {
+		p.Worker.SetLocker(p.Locker)
+	}
+}
+
+// Backends of the history of job runs and of the schedule store
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// HistoryParams holds the dependencies of the history of job runs. DB is needed by the
+// postgres backend only.
+type HistoryParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Worker    *Worker
+	Config    *config.Config
+	Metrics   *observability.Metrics `optional:"true"`
+	DB        *sql.DB                `optional:"true"`
+}
+
+// RegisterHistory records the runs of jobs on metrics and in the history of the configured
+// backend. The table of the postgres backend is created on start.
+func RegisterHistory(p HistoryParams) error {
+	p.Worker.SetMetrics(p.Metrics)
+
+	historyCfg := p.Config.Worker.History
+	switch strings.ToLower(historyCfg.Backend) {
+	case "":
+	case BackendMemory:
+		p.Worker.SetHistory(NewMemoryHistory(historyCfg.Size))
+	case BackendPostgres:
+		if p.DB == nil {
+			return fmt.Errorf("worker: the postgres history backend needs a *sql.DB provided to the application")
+		}
+		history, err := NewPostgresHistory(p.DB, historyCfg.Table)
+		if err != nil {
+			return err
+		}
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := history.Migrate(ctx); err != nil {
+					return err
+				}
+				p.Worker.SetHistory(history)
+				return nil
+			},
+		})
+	default:
+		return fmt.Errorf("worker: unknown history backend %q", historyCfg.Backend)
+	}
+	return nil
+}
+
+// ScheduleParams holds the dependencies of the schedule store. DB is needed by the postgres
+// backend only.
+type ScheduleParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Worker    *Worker
+	Config    *config.Config
+	DB        *sql.DB `optional:"true"`
+}
+
+// RegisterScheduleStore persists one-shot jobs in the store of the configured backend, and
+// restores the jobs of the store on start. The table of the postgres backend is created on
+// start too.
+func RegisterScheduleStore(p ScheduleParams) error {
+	scheduledCfg := p.Config.Worker.Scheduled
+	var migrate func(ctx context.Context) error
+	switch strings.ToLower(scheduledCfg.Backend) {
+	case "":
+		return nil
+	case BackendMemory:
+		p.Worker.SetScheduleStore(NewMemoryScheduleStore())
+	case BackendPostgres:
+		if p.DB == nil {
+			return fmt.Errorf("worker: the postgres schedule backend needs a *sql.DB provided to the application")
+		}
+		store, err := NewPostgresScheduleStore(p.DB, scheduledCfg.Table)
+		if err != nil {
+			return err
+		}
+		p.Worker.SetScheduleStore(store)
+		migrate = store.Migrate
+	default:
+		return fmt.Errorf("worker: unknown schedule backend %q", scheduledCfg.Backend)
+	}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if migrate != nil {
+				if err := migrate(ctx); err != nil {
+					return err
+				}
+			}
+			return p.Worker.RestoreScheduled(ctx)
+		},
+	})
+	return nil
+}
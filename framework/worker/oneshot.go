@@ -0,0 +1,231 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/clock"
+
+	"go.uber.org/zap"
+)
+
+// ErrHandlerNotFound is returned when scheduling a job whose Handler is not registered
+var ErrHandlerNotFound = errors.New("job handler not found")
+
+// HandlerFunc runs a one-shot job with its payload
+type HandlerFunc func(ctx context.Context, payload []byte) error
+
+// scheduledEntry is a one-shot job waiting for its time
+type scheduledEntry struct {
+	job    *Job
+	runAt  time.Time
+	timer  clock.Timer
+	cancel context.CancelFunc
+}
+
+// stop cancels the job, stopping it if it runs already
+func (e *scheduledEntry) stop() {
+	e.timer.Stop()
+	e.cancel()
+}
+
+// RegisterHandler registers the function of the one-shot jobs naming it as their Handler.
+// Handlers must be registered before RestoreScheduled, usually from an fx.Invoke.
+func (w *Worker) RegisterHandler(name string, fn HandlerFunc) error {
+	if name == "" {
+		return errors.New("handler name cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("handler function cannot be nil")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[name] = fn
+	return nil
+}
+
+// SetScheduleStore sets the store one-shot jobs with a Handler are persisted in
+func (w *Worker) SetScheduleStore(store ScheduleStore) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.store = store
+}
+
+// ScheduleAfter runs job once, after delay. See ScheduleOnce.
+func (w *Worker) ScheduleAfter(ctx context.Context, delay time.Duration, job *Job) error {
+	return w.ScheduleOnce(ctx, w.now().Add(delay), job)
+}
+
+// ScheduleOnce runs job once, at runAt, or right away if runAt has passed. A job scheduled
+// again under the same ID replaces the waiting one. Jobs naming a Handler are persisted in
+// the schedule store, if one is set, so that they run after a restart too; the store is
+// claimed before the job runs, so a job runs at most once across the instances sharing it.
+// Jobs with a Func run in this process only.
+func (w *Worker) ScheduleOnce(ctx context.Context, runAt time.Time, job *Job) error {
+	if job.ID == "" {
+		return errors.New("job ID cannot be empty")
+	}
+	if (job.Func == nil) == (job.Handler == "") {
+		return errors.New("job needs either a function or a handler")
+	}
+
+	w.mu.RLock()
+	_, registered := w.handlers[job.Handler]
+	store := w.store
+	w.mu.RUnlock()
+	if job.Handler != "" && !registered {
+		return fmt.Errorf("%w: %q", ErrHandlerNotFound, job.Handler)
+	}
+
+	// Stores such as PostgreSQL keep times to the microsecond, and claims match them exactly
+	runAt = runAt.Truncate(time.Microsecond)
+	if store != nil && job.Handler != "" {
+		if err := store.Save(ctx, ScheduledJob{
+			ID:        job.ID,
+			Name:      job.Name,
+			Handler:   job.Handler,
+			Payload:   job.Payload,
+			RunAt:     runAt,
+			Timeout:   job.Timeout,
+			Singleton: job.Singleton,
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.schedule(job, runAt)
+	w.logger.Info("Scheduled job", zap.String("id", job.ID), zap.String("name", job.Name), zap.Time("run_at", runAt))
+	return nil
+}
+
+// CancelScheduled cancels a one-shot job, stopping it if it runs already, and removes it
+// from the schedule store. It returns ErrJobNotFound if the job is neither waiting in this
+// process nor in the store.
+func (w *Worker) CancelScheduled(ctx context.Context, jobID string) error {
+	w.mu.Lock()
+	entry, waiting := w.scheduled[jobID]
+	if waiting {
+		entry.stop()
+		delete(w.scheduled, jobID)
+	}
+	store := w.store
+	w.mu.Unlock()
+
+	stored := false
+	if store != nil {
+		var err error
+		if stored, err = store.Delete(ctx, jobID); err != nil {
+			return err
+		}
+	}
+	if !waiting && !stored {
+		return ErrJobNotFound
+	}
+
+	w.logger.Info("Canceled scheduled job", zap.String("id", jobID))
+	return nil
+}
+
+// RestoreScheduled schedules the jobs of the schedule store, running those whose time passed
+// while no instance was up. Jobs whose handler is not registered are left in the store.
+func (w *Worker) RestoreScheduled(ctx context.Context) error {
+	w.mu.RLock()
+	store := w.store
+	w.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	jobs, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, stored := range jobs {
+		w.mu.RLock()
+		_, registered := w.handlers[stored.Handler]
+		w.mu.RUnlock()
+		if !registered {
+			w.logger.Warn("Scheduled job has no registered handler", zap.String("id", stored.ID), zap.String("handler", stored.Handler))
+			continue
+		}
+		w.schedule(&Job{
+			ID:        stored.ID,
+			Name:      stored.Name,
+			Handler:   stored.Handler,
+			Payload:   stored.Payload,
+			Timeout:   stored.Timeout,
+			Singleton: stored.Singleton,
+		}, stored.RunAt)
+	}
+	w.logger.Info("Restored scheduled jobs", zap.Int("count", len(jobs)))
+	return nil
+}
+
+// Scheduled returns the IDs of the one-shot jobs waiting in this process and their times
+func (w *Worker) Scheduled() map[string]time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	scheduled := make(map[string]time.Time, len(w.scheduled))
+	for id, entry := range w.scheduled {
+		scheduled[id] = entry.runAt
+	}
+	return scheduled
+}
+
+// schedule starts the timer of a one-shot job, replacing the waiting job of the same ID
+func (w *Worker) schedule(job *Job, runAt time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+	entry := &scheduledEntry{job: job, runAt: runAt, cancel: cancel}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if previous, exists := w.scheduled[job.ID]; exists {
+		previous.stop()
+	}
+	w.scheduled[job.ID] = entry
+	entry.timer = w.clock.AfterFunc(runAt.Sub(w.clock.Now()), func() { w.runScheduled(ctx, entry) })
+}
+
+// runScheduled runs a one-shot job once its time has come, if it was not replaced or
+// canceled and, for persisted jobs, once this instance claimed it
+func (w *Worker) runScheduled(ctx context.Context, entry *scheduledEntry) {
+	job := entry.job
+	defer entry.cancel()
+
+	w.mu.Lock()
+	if w.scheduled[job.ID] != entry {
+		w.mu.Unlock()
+		return
+	}
+	delete(w.scheduled, job.ID)
+	store := w.store
+	handler := w.handlers[job.Handler]
+	w.mu.Unlock()
+
+	if job.Handler != "" {
+		if store != nil {
+			claimed, err := store.Claim(ctx, job.ID, entry.runAt)
+			if err != nil {
+				w.logger.Error("Failed to claim scheduled job", zap.String("id", job.ID), zap.Error(err))
+				return
+			}
+			if !claimed {
+				w.logger.Debug("Scheduled job was run, rescheduled or canceled elsewhere", zap.String("id", job.ID))
+				return
+			}
+		}
+		payload := job.Payload
+		job = &Job{
+			ID:        job.ID,
+			Name:      job.Name,
+			Func:      func(ctx context.Context) error { return handler(ctx, payload) },
+			Timeout:   job.Timeout,
+			Singleton: job.Singleton,
+		}
+	}
+	w.executeJob(ctx, job)
+}
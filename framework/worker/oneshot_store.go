@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScheduledJob is a one-shot job persisted in a ScheduleStore
+type ScheduledJob struct {
+	ID        string
+	Name      string
+	Handler   string
+	Payload   []byte
+	RunAt     time.Time
+	Timeout   time.Duration
+	Singleton bool
+}
+
+// ScheduleStore persists the one-shot jobs waiting for their time
+type ScheduleStore interface {
+	// Save stores a job, replacing the job of the same ID
+	Save(ctx context.Context, job ScheduledJob) error
+	// Claim removes the job of id scheduled at runAt, reporting whether it was there, so
+	// that a single instance runs it
+	Claim(ctx context.Context, id string, runAt time.Time) (bool, error)
+	// Delete removes the job of id, reporting whether it was there
+	Delete(ctx context.Context, id string) (bool, error)
+	// List returns the stored jobs
+	List(ctx context.Context) ([]ScheduledJob, error)
+}
+
+// MemoryScheduleStore keeps one-shot jobs in the process, for tests and single instances
+// that can lose their jobs on restart
+type MemoryScheduleStore struct {
+	mu   sync.Mutex
+	jobs map[string]ScheduledJob
+}
+
+// NewMemoryScheduleStore creates an empty in-memory schedule store
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{jobs: make(map[string]ScheduledJob)}
+}
+
+// Save stores a job, replacing the job of the same ID
+func (s *MemoryScheduleStore) Save(ctx context.Context, job ScheduledJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Claim removes the job of id scheduled at runAt
+func (s *MemoryScheduleStore) Claim(ctx context.Context, id string, runAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, exists := s.jobs[id]
+	if !exists || !job.RunAt.Equal(runAt) {
+		return false, nil
+	}
+	delete(s.jobs, id)
+	return true, nil
+}
+
+// Delete removes the job of id
+func (s *MemoryScheduleStore) Delete(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.jobs[id]
+	delete(s.jobs, id)
+	return exists, nil
+}
+
+// List returns the stored jobs
+func (s *MemoryScheduleStore) List(ctx context.Context) ([]ScheduledJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// DefaultScheduleTable is the table a PostgresScheduleStore keeps jobs in by default
+const DefaultScheduleTable = "scheduled_jobs"
+
+// PostgresScheduleStore keeps one-shot jobs in a PostgreSQL table, shared by every instance
+type PostgresScheduleStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresScheduleStore creates a schedule store keeping jobs in table,
+// DefaultScheduleTable if empty. Migrate creates the table.
+func NewPostgresScheduleStore(db *sql.DB, table string) (*PostgresScheduleStore, error) {
+	if table == "" {
+		table = DefaultScheduleTable
+	}
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("worker: invalid schedule table name %q", table)
+	}
+	return &PostgresScheduleStore{db: db, table: table}, nil
+}
+
+// Migrate creates the table of jobs and its index if they do not exist
+func (s *PostgresScheduleStore) Migrate(ctx context.Context) error {
+	index := strings.ReplaceAll(s.table, ".", "_") + "_run_at_idx"
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	handler TEXT NOT NULL,
+	payload BYTEA,
+	run_at TIMESTAMPTZ NOT NULL,
+	timeout_ms BIGINT NOT NULL,
+	singleton BOOLEAN NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[2]s ON %[1]s (run_at);`, s.table, index))
+	if err != nil {
+		return fmt.Errorf("worker: failed to create schedule table: %w", err)
+	}
+	return nil
+}
+
+// Save upserts a job
+func (s *PostgresScheduleStore) Save(ctx context.Context, job ScheduledJob) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO "+s.table+" (id, name, handler, payload, run_at, timeout_ms, singleton) VALUES ($1, $2, $3, $4, $5, $6, $7)"+
+			" ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, handler = EXCLUDED.handler, payload = EXCLUDED.payload,"+
+			" run_at = EXCLUDED.run_at, timeout_ms = EXCLUDED.timeout_ms, singleton = EXCLUDED.singleton",
+		job.ID, job.Name, job.Handler, job.Payload, job.RunAt, job.Timeout.Milliseconds(), job.Singleton)
+	if err != nil {
+		return fmt.Errorf("worker: failed to save scheduled job: %w", err)
+	}
+	return nil
+}
+
+// Claim deletes the job of id scheduled at runAt
+func (s *PostgresScheduleStore) Claim(ctx context.Context, id string, runAt time.Time) (bool, error) {
+	return s.delete(ctx, "DELETE FROM "+s.table+" WHERE id = $1 AND run_at = $2", id, runAt)
+}
+
+// Delete deletes the job of id
+func (s *PostgresScheduleStore) Delete(ctx context.Context, id string) (bool, error) {
+	return s.delete(ctx, "DELETE FROM "+s.table+" WHERE id = $1", id)
+}
+
+func (s *PostgresScheduleStore) delete(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("worker: failed to delete scheduled job: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// List returns the stored jobs, soonest first
+func (s *PostgresScheduleStore) List(ctx context.Context) ([]ScheduledJob, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, handler, payload, run_at, timeout_ms, singleton FROM "+s.table+" ORDER BY run_at")
+	if err != nil {
+		return nil, fmt.Errorf("worker: failed to query scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []ScheduledJob
+	for rows.Next() {
+		var job ScheduledJob
+		var timeoutMs int64
+		if err := rows.Scan(&job.ID, &job.Name, &job.Handler, &job.Payload, &job.RunAt, &timeoutMs, &job.Singleton); err != nil {
+			return nil, fmt.Errorf("worker: failed to scan scheduled job: %w", err)
+		}
+		job.Timeout = time.Duration(timeoutMs) * time.Millisecond
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
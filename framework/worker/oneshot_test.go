@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWorker(t *testing.T) *Worker {
+	t.Helper()
+	logger, _ := observability.NewLogger(&config.Config{})
+	w := New(logger)
+	t.Cleanup(w.StopAll)
+	return w
+}
+
+func TestScheduleOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs once after the delay", func(t *testing.T) {
+		w := newTestWorker(t)
+		runs := make(chan time.Time, 2)
+		start := time.Now()
+		require.NoError(t, w.ScheduleAfter(ctx, 50*time.Millisecond, &Job{
+			ID:   "reminder",
+			Func: func(ctx context.Context) error { runs <- time.Now(); return nil },
+		}))
+		assert.Contains(t, w.Scheduled(), "reminder")
+
+		select {
+		case ranAt := <-runs:
+			assert.GreaterOrEqual(t, ranAt.Sub(start), 50*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("job did not run")
+		}
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, runs, "the job runs once")
+		assert.Empty(t, w.Scheduled())
+	})
+
+	t.Run("runs past jobs right away", func(t *testing.T) {
+		w := newTestWorker(t)
+		done := make(chan struct{})
+		require.NoError(t, w.ScheduleOnce(ctx, time.Now().Add(-time.Hour), &Job{
+			ID:   "late",
+			Func: func(ctx context.Context) error { close(done); return nil },
+		}))
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("job did not run")
+		}
+	})
+
+	t.Run("cancels and replaces", func(t *testing.T) {
+		w := newTestWorker(t)
+		runs := make(chan string, 2)
+		job := func(name string) *Job {
+			return &Job{ID: "reminder", Func: func(ctx context.Context) error { runs <- name; return nil }}
+		}
+		require.NoError(t, w.ScheduleAfter(ctx, 20*time.Millisecond, job("first")))
+		require.NoError(t, w.ScheduleAfter(ctx, 40*time.Millisecond, job("second")))
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, []string{"second"}, drain(runs), "scheduling the ID again replaces the job")
+
+		require.NoError(t, w.ScheduleAfter(ctx, 20*time.Millisecond, job("canceled")))
+		require.NoError(t, w.CancelScheduled(ctx, "reminder"))
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, drain(runs))
+		assert.ErrorIs(t, w.CancelScheduled(ctx, "reminder"), ErrJobNotFound)
+	})
+
+	t.Run("rejects invalid jobs", func(t *testing.T) {
+		w := newTestWorker(t)
+		noop := func(ctx context.Context) error { return nil }
+		assert.Error(t, w.ScheduleAfter(ctx, 0, &Job{Func: noop}))
+		assert.Error(t, w.ScheduleAfter(ctx, 0, &Job{ID: "none"}))
+		assert.Error(t, w.ScheduleAfter(ctx, 0, &Job{ID: "both", Func: noop, Handler: "remind"}))
+		assert.ErrorIs(t, w.ScheduleAfter(ctx, 0, &Job{ID: "unknown", Handler: "remind"}), ErrHandlerNotFound)
+	})
+}
+
+func TestScheduleStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryScheduleStore()
+	payloads := make(chan string, 2)
+	newWorker := func() *Worker {
+		w := newTestWorker(t)
+		w.SetScheduleStore(store)
+		require.NoError(t, w.RegisterHandler("remind", func(ctx context.Context, payload []byte) error {
+			payloads <- string(payload)
+			return nil
+		}))
+		return w
+	}
+
+	// The first instance stops before the job runs, and a restarted one restores it
+	first := newWorker()
+	require.NoError(t, first.ScheduleAfter(ctx, 50*time.Millisecond, &Job{ID: "reminder", Handler: "remind", Payload: []byte("user-1")}))
+	first.StopAll()
+	stored, _ := store.List(ctx)
+	assert.Len(t, stored, 1, "stopping keeps the job in the store")
+
+	// Two instances sharing the store run the job once
+	restarted, other := newWorker(), newWorker()
+	require.NoError(t, restarted.RestoreScheduled(ctx))
+	require.NoError(t, other.RestoreScheduled(ctx))
+	select {
+	case payload := <-payloads:
+		assert.Equal(t, "user-1", payload)
+	case <-time.After(time.Second):
+		t.Fatal("restored job did not run")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, drain(payloads), "the job runs on one instance")
+	stored, _ = store.List(ctx)
+	assert.Empty(t, stored, "run jobs leave the store")
+
+	// Jobs canceled on any instance do not run
+	require.NoError(t, restarted.ScheduleAfter(ctx, 50*time.Millisecond, &Job{ID: "reminder", Handler: "remind"}))
+	require.NoError(t, other.RestoreScheduled(ctx))
+	require.NoError(t, restarted.CancelScheduled(ctx, "reminder"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, drain(payloads))
+}
+
+func TestMemoryScheduleStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryScheduleStore()
+	runAt := time.Now()
+	require.NoError(t, store.Save(ctx, ScheduledJob{ID: "a", RunAt: runAt}))
+
+	claimed, err := store.Claim(ctx, "a", runAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, claimed, "a rescheduled job is not claimed at its former time")
+
+	claimed, _ = store.Claim(ctx, "a", runAt)
+	assert.True(t, claimed)
+	claimed, _ = store.Claim(ctx, "a", runAt)
+	assert.False(t, claimed, "a job is claimed once")
+
+	deleted, _ := store.Delete(ctx, "a")
+	assert.False(t, deleted)
+}
+
+// drain returns the values buffered in ch
+func drain[T any](ch chan T) []T {
+	var values []T
+	for {
+		select {
+		case v := <-ch:
+			values = append(values, v)
+		default:
+			return values
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pool errors
+var (
+	ErrPoolClosed   = errors.New("pool is closed")
+	ErrPoolFull     = errors.New("pool queue is full")
+	ErrTaskPanicked = errors.New("task panicked")
+)
+
+// PoolOptions contains options for a pool
+type PoolOptions struct {
+	// Workers is the number of tasks running at once; defaults to GOMAXPROCS
+	Workers int
+	// QueueSize is the number of tasks waiting for a worker, and of results waiting to be
+	// read, before Submit blocks; defaults to Workers
+	QueueSize int
+	// TaskTimeout bounds the context of each task; zero leaves it unbounded
+	TaskTimeout time.Duration
+}
+
+// Result is the outcome of a task
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// task is a submitted function with the context it runs in
+type task[T any] struct {
+	ctx context.Context
+	fn  func(ctx context.Context) (T, error)
+}
+
+// Pool runs tasks on a fixed number of goroutines, for fanning work out while serving a
+// request, and delivers their results on Results. It is unrelated to the scheduled jobs of
+// Worker. When the queue is full, Submit blocks until a worker frees a slot, so producers are
+// slowed down to the pace of the workers, which in turn wait for Results to be read.
+type Pool[T any] struct {
+	options PoolOptions
+	tasks   chan task[T]
+	results chan Result[T]
+	running atomic.Int64
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	// stop is canceled when Close gives up waiting, canceling the running tasks
+	stop      context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	drained   chan struct{}
+}
+
+// NewPool creates a pool and starts its workers
+func NewPool[T any](options PoolOptions) *Pool[T] {
+	if options.Workers <= 0 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = options.Workers
+	}
+
+	stop, cancel := context.WithCancel(context.Background())
+	p := &Pool[T]{
+		options: options,
+		tasks:   make(chan task[T], options.QueueSize),
+		results: make(chan Result[T], options.QueueSize),
+		stop:    stop,
+		cancel:  cancel,
+		drained: make(chan struct{}),
+	}
+	p.workers.Add(options.Workers)
+	for i := 0; i < options.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues fn, waiting while the queue is full. fn runs with ctx, bounded by the task
+// timeout, so canceling ctx cancels both the wait and the task. It returns ctx.Err() if ctx
+// is done first, and ErrPoolClosed once the pool is closed.
+func (p *Pool[T]) Submit(ctx context.Context, fn func(ctx context.Context) (T, error)) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task[T]{ctx: ctx, fn: fn}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.stop.Done():
+		return ErrPoolClosed
+	}
+}
+
+// TrySubmit queues fn, or returns ErrPoolFull without waiting if the queue is full
+func (p *Pool[T]) TrySubmit(ctx context.Context, fn func(ctx context.Context) (T, error)) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task[T]{ctx: ctx, fn: fn}:
+		return nil
+	default:
+		return ErrPoolFull
+	}
+}
+
+// Results returns the channel the results of tasks are delivered on, in the order the tasks
+// end. It is closed once the pool is closed and its tasks have ended.
+func (p *Pool[T]) Results() <-chan Result[T] {
+	return p.results
+}
+
+// Close stops accepting tasks and waits until the queued and running tasks have ended. If
+// ctx is done first, the running tasks are canceled, the queued ones are dropped with
+// ErrPoolClosed, and ctx.Err() is returned. Results must be read while Close waits.
+func (p *Pool[T]) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		go func() {
+			p.mu.Lock()
+			p.closed = true
+			close(p.tasks)
+			p.mu.Unlock()
+
+			p.workers.Wait()
+			close(p.results)
+			close(p.drained)
+		}()
+	})
+
+	select {
+	case <-p.drained:
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Queued returns the number of tasks waiting for a worker
+func (p *Pool[T]) Queued() int {
+	return len(p.tasks)
+}
+
+// Running returns the number of tasks running
+func (p *Pool[T]) Running() int {
+	return int(p.running.Load())
+}
+
+// work runs queued tasks until the queue is closed
+func (p *Pool[T]) work() {
+	defer p.workers.Done()
+	for t := range p.tasks {
+		var result Result[T]
+		if p.stop.Err() != nil {
+			result.Err = ErrPoolClosed
+		} else {
+			result = p.run(t)
+		}
+		select {
+		case p.results <- result:
+		case <-p.stop.Done():
+		}
+	}
+}
+
+// run runs a task, turning a panic into an error so that the worker survives it
+func (p *Pool[T]) run(t task[T]) (result Result[T]) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+
+	ctx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+	defer context.AfterFunc(p.stop, cancel)()
+	if p.options.TaskTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, p.options.TaskTimeout)
+		defer cancelTimeout()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result = Result[T]{Err: fmt.Errorf("%w: %v", ErrTaskPanicked, r)}
+		}
+	}()
+	value, err := t.fn(ctx)
+	return Result[T]{Value: value, Err: err}
+}
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool[int](PoolOptions{Workers: 3, QueueSize: 2})
+
+	var running, maxRunning atomic.Int32
+	go func() {
+		for i := 1; i <= 10; i++ {
+			n := i
+			assert.NoError(t, pool.Submit(ctx, func(ctx context.Context) (int, error) {
+				current := running.Add(1)
+				for {
+					highest := maxRunning.Load()
+					if current <= highest || maxRunning.CompareAndSwap(highest, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				return n * n, nil
+			}))
+		}
+		assert.NoError(t, pool.Close(ctx))
+	}()
+
+	var squares []int
+	for result := range pool.Results() {
+		require.NoError(t, result.Err)
+		squares = append(squares, result.Value)
+	}
+	sort.Ints(squares)
+	assert.Equal(t, []int{1, 4, 9, 16, 25, 36, 49, 64, 81, 100}, squares)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3), "tasks run on the workers only")
+	assert.ErrorIs(t, pool.Submit(ctx, nil), ErrPoolClosed)
+}
+
+func TestPoolFailures(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool[string](PoolOptions{Workers: 1, QueueSize: 4, TaskTimeout: 20 * time.Millisecond})
+
+	tests := []struct {
+		name string
+		fn   func(ctx context.Context) (string, error)
+		err  error
+	}{
+		{"error", func(ctx context.Context) (string, error) { return "", errors.New("boom") }, nil},
+		{"panic", func(ctx context.Context) (string, error) { panic("boom") }, ErrTaskPanicked},
+		{"timeout", func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}, context.DeadlineExceeded},
+		{"after a panic", func(ctx context.Context) (string, error) { return "ok", nil }, nil},
+	}
+	for _, tt := range tests {
+		require.NoError(t, pool.Submit(ctx, tt.fn))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := <-pool.Results()
+			switch {
+			case tt.err != nil:
+				assert.ErrorIs(t, result.Err, tt.err)
+			case tt.name == "error":
+				assert.EqualError(t, result.Err, "boom")
+			default:
+				assert.NoError(t, result.Err)
+				assert.Equal(t, "ok", result.Value, "the worker survives panics")
+			}
+		})
+	}
+	assert.NoError(t, pool.Close(ctx))
+}
+
+func TestPoolBackpressure(t *testing.T) {
+	pool := NewPool[int](PoolOptions{Workers: 1, QueueSize: 1})
+	release := make(chan struct{})
+	block := func(ctx context.Context) (int, error) {
+		<-release
+		return 0, nil
+	}
+
+	// One task runs, one waits in the queue and the next finds the queue full
+	require.NoError(t, pool.Submit(context.Background(), block))
+	require.Eventually(t, func() bool { return pool.Running() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, pool.Submit(context.Background(), block))
+	assert.Equal(t, 1, pool.Queued())
+	assert.ErrorIs(t, pool.TrySubmit(context.Background(), block), ErrPoolFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Submit(ctx, block), context.DeadlineExceeded, "Submit waits for a free slot")
+
+	close(release)
+	<-pool.Results()
+	<-pool.Results()
+	assert.NoError(t, pool.Close(context.Background()))
+}
+
+func TestPoolCloseTimeout(t *testing.T) {
+	pool := NewPool[int](PoolOptions{Workers: 1, QueueSize: 1})
+	started := make(chan struct{})
+	require.NoError(t, pool.Submit(context.Background(), func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}))
+	<-started
+	require.NoError(t, pool.Submit(context.Background(), func(ctx context.Context) (int, error) { return 1, nil }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Close(ctx), context.DeadlineExceeded)
+
+	var errs []error
+	for result := range pool.Results() {
+		errs = append(errs, result.Err)
+	}
+	assert.Subset(t, []error{context.Canceled, ErrPoolClosed}, errs, "running tasks are canceled and queued ones dropped")
+}
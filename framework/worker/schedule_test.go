@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2024, 1, 10, 14, 30, 20, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 10, 14, 31, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2024, 1, 11, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 10, 14, 45, 0, 0, time.UTC)},
+		{"5/20 9-17 * * *", time.Date(2024, 1, 10, 14, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)},
+		{"30 14 10 1 *", time.Date(2025, 1, 10, 14, 30, 0, 0, time.UTC)},
+		{"10,40 * * * *", time.Date(2024, 1, 10, 14, 40, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+
+	schedule, err := ParseSchedule("0 2 * * *")
+	require.NoError(t, err)
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	assert.Equal(t, time.Date(2024, 1, 11, 2, 0, 0, 0, loc), schedule.Next(from.In(loc)), "schedules run in the location of the time")
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@often",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseSchedule(expr)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestScheduledJob(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	w := New(logger)
+
+	err := w.RegisterJob(&Job{ID: "report", Schedule: "0 25 * * *", Func: func(ctx context.Context) error { return nil }})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	ran := make(chan struct{}, 1)
+	require.NoError(t, w.RegisterJob(&Job{ID: "nightly", Schedule: "0 2 * * *", Func: func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}))
+	require.NoError(t, w.StartJob("nightly"))
+	select {
+	case <-ran:
+		t.Fatal("scheduled jobs wait for their time instead of running when started")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, w.StopJob("nightly"))
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/lock"
 	"github.com/axiomod/axiomod/platform/observability"
 
 	"go.uber.org/zap"
@@ -24,14 +26,45 @@ type Job struct {
 	Func     func(ctx context.Context) error
 	Interval time.Duration
 	Timeout  time.Duration
+
+	// Schedule is a cron expression, see ParseSchedule, running the job at the times it
+	// matches instead of every Interval
+	Schedule string
+	schedule *Schedule
+
+	// Singleton runs the job on one instance at a time, holding the lock
+	// "worker:<ID>" of the locker set with SetLocker while it runs
+	Singleton bool
+
+	// Handler names the function, registered with RegisterHandler, of a job scheduled with
+	// ScheduleOnce instead of Func, so that the job can be restored after a restart. Payload
+	// is passed to it.
+	Handler string
+	Payload []byte
 }
 
+// singletonLockTTL is how long the lock of a singleton job outlives an instance that
+// crashed while running it
+const singletonLockTTL = 30 * time.Second
+
+// historyRecordTimeout bounds the recording of a run in the history
+const historyRecordTimeout = 5 * time.Second
+
 // Worker manages background jobs
 type Worker struct {
 	jobs       map[string]*Job
 	cancelFunc map[string]context.CancelFunc
 	mu         sync.RWMutex
 	logger     *observability.Logger
+	locker     *lock.Locker
+	history    History
+	metrics    *observability.Metrics
+	clock      clock.Clock
+
+	// One-shot jobs
+	handlers  map[string]HandlerFunc
+	scheduled map[string]*scheduledEntry
+	store     ScheduleStore
 }
 
 // New creates a new Worker
@@ -40,9 +73,60 @@ func New(logger *observability.Logger) *Worker {
 		jobs:       make(map[string]*Job),
 		cancelFunc: make(map[string]context.CancelFunc),
 		logger:     logger,
+		clock:      clock.System,
+		handlers:   make(map[string]HandlerFunc),
+		scheduled:  make(map[string]*scheduledEntry),
 	}
 }
 
+// SetLocker sets the locker singleton jobs take their lock with
+func (w *Worker) SetLocker(locker *lock.Locker) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.locker = locker
+}
+
+// SetHistory sets the history the runs of jobs are recorded in
+func (w *Worker) SetHistory(history History) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.history = history
+}
+
+// History returns the history the runs of jobs are recorded in, nil if none is set
+func (w *Worker) History() History {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.history
+}
+
+// SetMetrics sets the metrics the runs of jobs are counted and timed on
+func (w *Worker) SetMetrics(metrics *observability.Metrics) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.metrics = metrics
+}
+
+// SetClock sets the clock jobs are run and timed on. Timeouts of jobs stay on the system
+// clock. Set it before starting jobs.
+func (w *Worker) SetClock(c clock.Clock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clock = clock.OrSystem(c)
+}
+
+// now returns the time of the clock of the worker
+func (w *Worker) now() time.Time {
+	return w.getClock().Now()
+}
+
+// getClock returns the clock of the worker
+func (w *Worker) getClock() clock.Clock {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.clock
+}
+
 // RegisterJob registers a new job
 func (w *Worker) RegisterJob(job *Job) error {
 	w.mu.Lock()
@@ -56,6 +140,14 @@ func (w *Worker) RegisterJob(job *Job) error {
 		return errors.New("job function cannot be nil")
 	}
 
+	if job.Schedule != "" {
+		schedule, err := ParseSchedule(job.Schedule)
+		if err != nil {
+			return err
+		}
+		job.schedule = schedule
+	}
+
 	w.jobs[job.ID] = job
 	w.logger.Info("Registered job", zap.String("id", job.ID), zap.String("name", job.Name))
 	return nil
@@ -105,7 +197,8 @@ func (w *Worker) StopJob(jobID string) error {
 	return nil
 }
 
-// StopAll stops all jobs
+// StopAll stops all jobs. One-shot jobs still waiting stay in the schedule store, to be
+// restored on the next start.
 func (w *Worker) StopAll() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -115,11 +208,20 @@ func (w *Worker) StopAll() {
 		delete(w.cancelFunc, jobID)
 		w.logger.Info("Stopped job", zap.String("id", jobID))
 	}
+	for jobID, entry := range w.scheduled {
+		entry.stop()
+		delete(w.scheduled, jobID)
+	}
 }
 
-// runJob runs a job at the specified interval
+// runJob runs a job at the specified interval, or at the times of its schedule
 func (w *Worker) runJob(ctx context.Context, job *Job) {
-	ticker := time.NewTicker(job.Interval)
+	if job.schedule != nil {
+		w.runScheduledJob(ctx, job)
+		return
+	}
+
+	ticker := w.getClock().NewTicker(job.Interval)
 	defer ticker.Stop()
 
 	// Run the job immediately
@@ -128,7 +230,7 @@ func (w *Worker) runJob(ctx context.Context, job *Job) {
 	// Run the job at the specified interval
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			w.executeJob(ctx, job)
 		case <-ctx.Done():
 			w.logger.Info("Job context canceled", zap.String("id", job.ID), zap.String("name", job.Name))
@@ -137,6 +239,27 @@ func (w *Worker) runJob(ctx context.Context, job *Job) {
 	}
 }
 
+// runScheduledJob runs a job at the times of its schedule, in local time
+func (w *Worker) runScheduledJob(ctx context.Context, job *Job) {
+	clk := w.getClock()
+	for {
+		next := job.schedule.Next(clk.Now())
+		if next.IsZero() {
+			w.logger.Warn("Job schedule matches no time", zap.String("id", job.ID), zap.String("schedule", job.Schedule))
+			return
+		}
+		timer := clk.NewTimer(next.Sub(clk.Now()))
+		select {
+		case <-timer.C():
+			w.executeJob(ctx, job)
+		case <-ctx.Done():
+			timer.Stop()
+			w.logger.Info("Job context canceled", zap.String("id", job.ID), zap.String("name", job.Name))
+			return
+		}
+	}
+}
+
 // executeJob executes a job with timeout
 func (w *Worker) executeJob(ctx context.Context, job *Job) {
 	w.logger.Debug("Executing job", zap.String("id", job.ID), zap.String("name", job.Name))
@@ -149,14 +272,58 @@ func (w *Worker) executeJob(ctx context.Context, job *Job) {
 		defer cancel()
 	}
 
-	// Execute the job
-	if err := job.Func(jobCtx); err != nil {
+	// Execute the job, on one instance at a time for singleton jobs
+	w.mu.RLock()
+	locker, history, metrics, clk := w.locker, w.history, w.metrics, w.clock
+	w.mu.RUnlock()
+	start := clk.Now()
+	var err error
+	if job.Singleton && locker != nil {
+		err = locker.TryWithLock(jobCtx, "worker:"+job.ID, singletonLockTTL, job.Func)
+	} else {
+		if job.Singleton {
+			w.logger.Warn("Running singleton job without a locker", zap.String("id", job.ID), zap.String("name", job.Name))
+		}
+		err = job.Func(jobCtx)
+	}
+	duration := clk.Since(start)
+
+	var outcome string
+	if errors.Is(err, lock.ErrNotAcquired) {
+		outcome = OutcomeSkipped
+		w.logger.Debug("Job is running on another instance", zap.String("id", job.ID), zap.String("name", job.Name))
+	} else if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
+			outcome = OutcomeTimeout
 			w.logger.Error("Job timed out", zap.String("id", job.ID), zap.String("name", job.Name), zap.Duration("timeout", job.Timeout))
 		} else {
+			outcome = OutcomeFailure
 			w.logger.Error("Job failed", zap.String("id", job.ID), zap.String("name", job.Name), zap.Error(err))
 		}
 	} else {
+		outcome = OutcomeSuccess
 		w.logger.Debug("Job completed successfully", zap.String("id", job.ID), zap.String("name", job.Name))
 	}
+
+	if metrics != nil && metrics.JobRunsTotal != nil {
+		metrics.JobRunsTotal.WithLabelValues(job.ID, outcome).Inc()
+		if outcome != OutcomeSkipped {
+			metrics.JobDuration.WithLabelValues(job.ID).Observe(duration.Seconds())
+		}
+		if outcome == OutcomeSuccess {
+			metrics.JobLastSuccessTime.WithLabelValues(job.ID).SetToCurrentTime()
+		}
+	}
+	if history != nil && outcome != OutcomeSkipped {
+		run := JobRun{JobID: job.ID, JobName: job.Name, StartedAt: start, Duration: duration, Outcome: outcome}
+		if err != nil {
+			run.Error = err.Error()
+		}
+		// The job context may be done already, e.g. when the job timed out or was stopped
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historyRecordTimeout)
+		defer cancel()
+		if err := history.Record(recordCtx, run); err != nil {
+			w.logger.Warn("Failed to record job run", zap.String("id", job.ID), zap.Error(err))
+		}
+	}
 }
@@ -2,13 +2,20 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/lock"
+	"github.com/axiomod/axiomod/framework/testkit/fake"
 	"github.com/axiomod/axiomod/platform/observability"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWorker(t *testing.T) {
@@ -124,3 +131,158 @@ func TestWorkerErrors(t *testing.T) {
 		_ = w.StopJob("running")
 	})
 }
+
+func TestSingletonJob(t *testing.T) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	locker := lock.New(lock.NewMemoryLock(), logger)
+
+	// Two workers stand in for two instances sharing the lock backend
+	var running, overlaps, runs atomic.Int32
+	workers := []*Worker{New(logger), New(logger)}
+	for _, w := range workers {
+		w.SetLocker(locker)
+		assert.NoError(t, w.RegisterJob(&Job{
+			ID:        "report",
+			Name:      "Report",
+			Interval:  10 * time.Millisecond,
+			Singleton: true,
+			Func: func(ctx context.Context) error {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				runs.Add(1)
+				time.Sleep(30 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			},
+		}))
+		assert.NoError(t, w.StartJob("report"))
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	for _, w := range workers {
+		w.StopAll()
+	}
+	assert.Positive(t, runs.Load())
+	assert.Zero(t, overlaps.Load(), "a singleton job runs on one worker at a time")
+}
+
+func TestJobRunRecording(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	assert.NoError(t, err)
+
+	w := New(logger)
+	w.SetMetrics(metrics)
+	history := NewMemoryHistory(10)
+	w.SetHistory(history)
+
+	tests := []struct {
+		name    string
+		job     *Job
+		outcome string
+		err     string
+	}{
+		{"success", &Job{ID: "ok", Func: func(ctx context.Context) error { return nil }}, OutcomeSuccess, ""},
+		{"failure", &Job{ID: "failing", Func: func(ctx context.Context) error { return errors.New("boom") }}, OutcomeFailure, "boom"},
+		{"timeout", &Job{ID: "slow", Timeout: time.Millisecond, Func: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}, OutcomeTimeout, context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.executeJob(context.Background(), tt.job)
+
+			runs, err := history.Runs(context.Background(), tt.job.ID, 0)
+			assert.NoError(t, err)
+			if assert.Len(t, runs, 1) {
+				assert.Equal(t, tt.outcome, runs[0].Outcome)
+				assert.Equal(t, tt.err, runs[0].Error)
+				assert.False(t, runs[0].StartedAt.IsZero())
+			}
+			assert.Equal(t, 1.0, testutil.ToFloat64(metrics.JobRunsTotal.WithLabelValues(tt.job.ID, tt.outcome)))
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.JobDuration.WithLabelValues(tt.job.ID).(prometheus.Histogram)))
+		})
+	}
+
+	assert.Positive(t, testutil.ToFloat64(metrics.JobLastSuccessTime.WithLabelValues("ok")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.JobLastSuccessTime), "only successful jobs have a last success")
+
+	t.Run("skipped", func(t *testing.T) {
+		locker := lock.New(lock.NewMemoryLock(), logger)
+		w.SetLocker(locker)
+		job := &Job{ID: "singleton", Singleton: true, Func: func(ctx context.Context) error { return nil }}
+		assert.NoError(t, locker.TryWithLock(context.Background(), "worker:singleton", time.Second, func(ctx context.Context) error {
+			w.executeJob(context.Background(), job)
+			return nil
+		}))
+
+		runs, _ := history.Runs(context.Background(), "singleton", 0)
+		assert.Empty(t, runs, "skipped runs are not recorded")
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.JobRunsTotal.WithLabelValues("singleton", OutcomeSkipped)))
+	})
+}
+
+func TestWorkerClock(t *testing.T) {
+	ctx := context.Background()
+	clk := fake.NewClock(time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local))
+	w := newTestWorker(t)
+	w.SetClock(clk)
+	history := NewMemoryHistory(10)
+	w.SetHistory(history)
+
+	runs := make(chan string, 10)
+	require.NoError(t, w.RegisterJob(&Job{
+		ID:       "interval",
+		Interval: time.Minute,
+		Func:     func(ctx context.Context) error { runs <- "interval"; return nil },
+	}))
+	require.NoError(t, w.RegisterJob(&Job{
+		ID:       "cron",
+		Schedule: "30 8 * * *",
+		Func:     func(ctx context.Context) error { runs <- "cron"; return nil },
+	}))
+	require.NoError(t, w.ScheduleAfter(ctx, 10*time.Minute, &Job{
+		ID:   "once",
+		Func: func(ctx context.Context) error { runs <- "once"; return nil },
+	}))
+	require.NoError(t, w.StartJob("interval"))
+	require.NoError(t, w.StartJob("cron"))
+
+	// Intervals passed while a job runs are dropped, so runs are waited for by job
+	waitFor := func(job string) {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case run := <-runs:
+				if run == job {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("job %s did not run", job)
+			}
+		}
+	}
+	waitFor("interval")
+
+	// The ticker, the cron timer and the one-shot timer
+	clk.BlockUntil(3)
+	clk.Advance(time.Minute)
+	waitFor("interval")
+	clk.Advance(9 * time.Minute)
+	waitFor("once")
+	assert.NotContains(t, w.Scheduled(), "once")
+	clk.Set(time.Date(2026, 3, 1, 8, 30, 0, 0, time.Local))
+	waitFor("cron")
+
+	// Runs are timed on the clock
+	runsOfOnce, err := history.Runs(ctx, "once", 1)
+	require.NoError(t, err)
+	require.Len(t, runsOfOnce, 1)
+	assert.Equal(t, time.Date(2026, 3, 1, 8, 10, 0, 0, time.Local), runsOfOnce[0].StartedAt)
+	assert.Zero(t, runsOfOnce[0].Duration)
+}
@@ -0,0 +1,79 @@
+package bootstrap
+
+import (
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/cache"
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/framework/circuitbreaker"
+	"github.com/axiomod/axiomod/framework/clock"
+	"github.com/axiomod/axiomod/framework/cqrs"
+	"github.com/axiomod/axiomod/framework/degradation"
+	"github.com/axiomod/axiomod/framework/di"
+	"github.com/axiomod/axiomod/framework/errorreport"
+	"github.com/axiomod/axiomod/framework/errors"
+	"github.com/axiomod/axiomod/framework/events"
+	"github.com/axiomod/axiomod/framework/featureflags"
+	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/framework/i18n"
+	"github.com/axiomod/axiomod/framework/lock"
+	"github.com/axiomod/axiomod/framework/metering"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/notify"
+	"github.com/axiomod/axiomod/framework/pagination"
+	"github.com/axiomod/axiomod/framework/profiling"
+	"github.com/axiomod/axiomod/framework/resilience"
+	"github.com/axiomod/axiomod/framework/router"
+	"github.com/axiomod/axiomod/framework/session"
+	"github.com/axiomod/axiomod/framework/storage"
+	"github.com/axiomod/axiomod/framework/websocket"
+	"github.com/axiomod/axiomod/framework/worker"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/axiomod/axiomod/platform/server"
+)
+
+// ServerPriority is the boot priority of the servers, higher than the priorities of the modules
+// of applications so that the servers boot after them
+const ServerPriority = 100
+
+// Modules returns the framework modules of the default application with the modules each must
+// boot after. The boot plan built from them decides the order in which their invocations and
+// lifecycle hooks run, instead of leaving it to the order providers happen to be needed in.
+func Modules() []*di.Module {
+	return []*di.Module{
+		di.NewModule("observability").Option(observability.Module).WithPriority(-100),
+		di.NewModule("clock").Option(clock.Module).After("observability"),
+		di.NewModule("errors").Option(errors.Module).After("observability"),
+		di.NewModule("errorreport").Option(errorreport.Module).After("observability", "errors"),
+		di.NewModule("profiling").Option(profiling.Module).After("observability"),
+		di.NewModule("cqrs").Option(cqrs.Module).After("observability"),
+		di.NewModule("events").Option(events.Module).After("observability"),
+		di.NewModule("metering").Option(metering.Module).After("observability"),
+		di.NewModule("auth").Option(auth.Module).After("observability"),
+		di.NewModule("health").Option(health.Module).After("observability"),
+		di.NewModule("cache").Option(cache.Module).After("observability", "health"),
+		di.NewModule("circuitbreaker").Option(circuitbreaker.Module).After("observability"),
+		di.NewModule("resilience").Option(resilience.Module).After("observability"),
+		di.NewModule("chaos").Option(chaos.Module).After("observability", "clock"),
+		di.NewModule("degradation").Option(degradation.Module).After("observability"),
+		di.NewModule("featureflags").Option(featureflags.Module).After("observability"),
+		di.NewModule("i18n").Option(i18n.Module).After("observability"),
+		di.NewModule("pagination").Option(pagination.Module).After("observability"),
+		di.NewModule("middleware").Option(middleware.Module).After("observability", "auth", "metering"),
+		di.NewModule("grpc").Option(grpc_pkg.Module).After("observability"),
+		di.NewModule("router").Option(router.Module).After("observability"),
+		di.NewModule("lock").Option(lock.Module).After("observability"),
+		di.NewModule("worker").Option(worker.Module).After("observability", "lock"),
+		di.NewModule("websocket").Option(websocket.Module).After("observability"),
+		di.NewModule("notify").Option(notify.Module).After("observability"),
+		di.NewModule("storage").Option(storage.Module).After("observability"),
+		di.NewModule("session").Option(session.Module).After("observability"),
+		// Servers start last so that every route and plugin is in place when traffic arrives; the
+		// priority boots them after the modules applications append, such as the plugin registry
+		di.NewModule("server").
+			Option(server.Module).
+			Invoke(server.RegisterChaosAdmin, server.RegisterRoutes, server.RegisterAdminEndpoints, server.RegisterStorageRoutes, server.RegisterHTTPServer, server.RegisterAdminServer, server.RegisterGRPCServer, server.RegisterGateway).
+			After("middleware", "health", "grpc", "router", "storage").
+			WithPriority(ServerPriority),
+	}
+}
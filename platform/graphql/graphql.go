@@ -0,0 +1,57 @@
+// Package graphql serves GraphQL APIs on the HTTP server, sharing its authentication and
+// observability. It mounts any GraphQL http.Handler, such as the handler.Server of gqlgen,
+// after checking the depth and complexity of operations, tracing them and counting them, and
+// provides the helpers resolvers need: the @auth directive, resolver tracing and batching
+// data loaders.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/axiomod/axiomod/framework/auth"
+)
+
+// Common errors
+var (
+	// ErrUnauthenticated is returned by Authorize for anonymous requests
+	ErrUnauthenticated = errors.New("graphql: authentication required")
+	// ErrForbidden is returned by Authorize for users without any of the required roles
+	ErrForbidden = errors.New("graphql: access denied")
+)
+
+type contextKey int
+
+const claimsKey contextKey = iota
+
+// withClaims returns a context carrying the claims of the authenticated user
+func withClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext returns the claims of the user making the request, set by the JWT or
+// session middleware
+func ClaimsFromContext(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*auth.Claims)
+	return claims, ok
+}
+
+// Authorize implements the @auth directive: it resolves the field with next for users having
+// one of roles, or for any authenticated user without roles. With gqlgen:
+//
+//	Directives: generated.DirectiveRoot{
+//		Auth: func(ctx context.Context, obj any, next graphql.Resolver, roles []string) (any, error) {
+//			return axgraphql.Authorize(ctx, next, roles...)
+//		},
+//	}
+func Authorize(ctx context.Context, next func(ctx context.Context) (any, error), roles ...string) (any, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if len(roles) > 0 && !slices.ContainsFunc(roles, claims.HasRole) {
+		return nil, ErrForbidden
+	}
+	return next(ctx)
+}
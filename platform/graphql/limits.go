@@ -0,0 +1,157 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Default limits of operations
+const (
+	DefaultMaxDepth      = 15
+	DefaultMaxComplexity = 1000
+)
+
+// listSizeArguments are the arguments taken as the size of the list a field returns
+var listSizeArguments = []string{"first", "last", "limit"}
+
+// Limits bound the cost of the operations a server executes. A zero or negative limit
+// disables its check.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// LimitError is returned for operations exceeding a limit
+type LimitError struct {
+	Limit string // "depth" or "complexity"
+	Value int
+	Max   int
+}
+
+// Error implements error
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("graphql: operation %s %d exceeds the limit of %d", e.Limit, e.Value, e.Max)
+}
+
+// Check returns a *LimitError when op exceeds the limits. Introspection fields are not
+// counted.
+func (l Limits) Check(doc *ast.QueryDocument, op *ast.OperationDefinition, variables map[string]any) error {
+	m := &measure{fragments: doc.Fragments, variables: variables}
+	if l.MaxDepth > 0 {
+		if depth := m.depth(op.SelectionSet, map[string]bool{}); depth > l.MaxDepth {
+			return &LimitError{Limit: "depth", Value: depth, Max: l.MaxDepth}
+		}
+	}
+	if l.MaxComplexity > 0 {
+		if complexity := m.complexity(op.SelectionSet, map[string]bool{}); complexity > l.MaxComplexity {
+			return &LimitError{Limit: "complexity", Value: complexity, Max: l.MaxComplexity}
+		}
+	}
+	return nil
+}
+
+// measure computes the depth and complexity of selections, expanding fragments
+type measure struct {
+	fragments ast.FragmentDefinitionList
+	variables map[string]any
+}
+
+// depth returns the depth of the deepest field of set; visiting holds the fragments being
+// expanded, so that cycles end
+func (m *measure) depth(set ast.SelectionSet, visiting map[string]bool) int {
+	deepest := 0
+	for _, selection := range set {
+		d := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + m.depth(s.SelectionSet, visiting)
+		case *ast.InlineFragment:
+			d = m.depth(s.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			m.expand(s.Name, visiting, func(set ast.SelectionSet) {
+				d = m.depth(set, visiting)
+			})
+		}
+		deepest = max(deepest, d)
+	}
+	return deepest
+}
+
+// complexity returns the number of fields of set, multiplying the fields under a list by
+// its size
+func (m *measure) complexity(set ast.SelectionSet, visiting map[string]bool) int {
+	total := 0
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			total = addCapped(total, addCapped(1, mulCapped(m.listSize(s.Arguments), m.complexity(s.SelectionSet, visiting))))
+		case *ast.InlineFragment:
+			total = addCapped(total, m.complexity(s.SelectionSet, visiting))
+		case *ast.FragmentSpread:
+			m.expand(s.Name, visiting, func(set ast.SelectionSet) {
+				total = addCapped(total, m.complexity(set, visiting))
+			})
+		}
+	}
+	return total
+}
+
+// expand calls f with the selections of the fragment name, unless it is unknown or already
+// being expanded
+func (m *measure) expand(name string, visiting map[string]bool, f func(ast.SelectionSet)) {
+	fragment := m.fragments.ForName(name)
+	if fragment == nil || visiting[name] {
+		return
+	}
+	visiting[name] = true
+	f(fragment.SelectionSet)
+	delete(visiting, name)
+}
+
+// listSize returns the size of the list selected by arguments such as first: 10, or 1
+func (m *measure) listSize(arguments ast.ArgumentList) int {
+	for _, name := range listSizeArguments {
+		argument := arguments.ForName(name)
+		if argument == nil || argument.Value == nil {
+			continue
+		}
+		switch argument.Value.Kind {
+		case ast.IntValue:
+			if n, err := strconv.Atoi(argument.Value.Raw); err == nil && n > 1 {
+				return min(n, maxComplexity)
+			}
+		case ast.Variable:
+			// JSON numbers decode to float64
+			if n, ok := m.variables[argument.Value.Raw].(float64); ok && n > 1 {
+				return int(min(n, maxComplexity))
+			}
+		}
+	}
+	return 1
+}
+
+// maxComplexity caps complexities, so that large list sizes cannot overflow them
+const maxComplexity = math.MaxInt32
+
+// addCapped returns a+b, at most maxComplexity
+func addCapped(a, b int) int {
+	return min(a+b, maxComplexity)
+}
+
+// mulCapped returns a*b, at most maxComplexity
+func mulCapped(a, b int) int {
+	if b != 0 && a > maxComplexity/b {
+		return maxComplexity
+	}
+	return a * b
+}
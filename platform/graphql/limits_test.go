@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]any
+		depth      int
+		complexity int
+	}{
+		{
+			name:       "flat",
+			query:      `{ me { id name } }`,
+			depth:      2,
+			complexity: 3,
+		},
+		{
+			name:       "list sizes multiply the fields under them",
+			query:      `{ orders(first: 10) { id items(limit: 5) { sku } } }`,
+			depth:      3,
+			complexity: 1 + 10*(1+1+5*1),
+		},
+		{
+			name:       "list size variables",
+			query:      `query Orders($n: Int) { orders(first: $n) { id } }`,
+			variables:  map[string]any{"n": float64(20)},
+			depth:      2,
+			complexity: 1 + 20,
+		},
+		{
+			name:       "fragments",
+			query:      `{ me { ...user ... on User { email } } } fragment user on User { id friends { id } }`,
+			depth:      3,
+			complexity: 1 + 1 + 2 + 1,
+		},
+		{
+			name:       "cyclic fragments end",
+			query:      `{ me { ...a } } fragment a on User { id ...b } fragment b on User { name ...a }`,
+			depth:      2,
+			complexity: 3,
+		},
+		{
+			name:       "introspection is not counted",
+			query:      `{ __schema { types { name fields { name type { ofType { ofType { name } } } } } } me { __typename id } }`,
+			depth:      2,
+			complexity: 2,
+		},
+		{
+			name:       "huge lists do not overflow",
+			query:      `{ a(first: 2147483647) { b(first: 2147483647) { c(first: 2147483647) { d } } } }`,
+			depth:      4,
+			complexity: maxComplexity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.ParseQuery(&ast.Source{Input: tt.query})
+			require.NoError(t, err)
+			op := doc.Operations.ForName("")
+			m := &measure{fragments: doc.Fragments, variables: tt.variables}
+			assert.Equal(t, tt.depth, m.depth(op.SelectionSet, map[string]bool{}))
+			assert.Equal(t, tt.complexity, m.complexity(op.SelectionSet, map[string]bool{}))
+
+			assert.NoError(t, Limits{MaxDepth: tt.depth, MaxComplexity: tt.complexity}.Check(doc, op, tt.variables))
+			assert.NoError(t, Limits{}.Check(doc, op, tt.variables), "zero limits are disabled")
+			err = Limits{MaxDepth: tt.depth - 1}.Check(doc, op, tt.variables)
+			assert.Equal(t, &LimitError{Limit: "depth", Value: tt.depth, Max: tt.depth - 1}, err)
+			err = Limits{MaxComplexity: tt.complexity - 1}.Check(doc, op, tt.variables)
+			assert.Equal(t, &LimitError{Limit: "complexity", Value: tt.complexity, Max: tt.complexity - 1}, err)
+		})
+	}
+}
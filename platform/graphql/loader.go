@@ -0,0 +1,205 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Load for keys the batch function found no value for
+var ErrNotFound = errors.New("graphql: not found")
+
+// Default options of loaders
+const (
+	DefaultLoaderWait     = 2 * time.Millisecond
+	DefaultLoaderMaxBatch = 100
+)
+
+// BatchFunc fetches the values of keys at once, e.g. with a single SQL query. Keys without a
+// value are left out of the map.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// LoaderOptions configure the batching of a loader
+type LoaderOptions struct {
+	Wait     time.Duration // time keys are collected before a batch is fetched; defaults to 2ms
+	MaxBatch int           // keys fetched at once; defaults to 100
+}
+
+// Loader batches and caches the loads of the resolvers of a request, so that resolving a list
+// of N objects fetches their relations with one call instead of N. Create a loader per
+// request, in a RequestFunc, since its cache is never invalidated.
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+	opts  LoaderOptions
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   *batch[K, V]
+}
+
+// result is the value of a key, available once done is closed
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// batch is the set of keys collected before a fetch
+type batch[K comparable, V any] struct {
+	ctx     context.Context // of the first load, without its cancellation
+	keys    []K
+	results []*result[V]
+	timer   *time.Timer
+}
+
+// NewLoader creates a loader fetching its values with fetch
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], opts LoaderOptions) *Loader[K, V] {
+	if opts.Wait <= 0 {
+		opts.Wait = DefaultLoaderWait
+	}
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = DefaultLoaderMaxBatch
+	}
+	return &Loader[K, V]{fetch: fetch, opts: opts, results: make(map[K]*result[V])}
+}
+
+// Load returns the value of key, fetched in a batch with the keys loaded at the same time.
+// The batch is fetched with the context of its first load, without its cancellation.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	r := l.enqueue(ctx, key)
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany returns the values of keys, in the same order, fetched in as few batches as
+// possible. It stops at the first error.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		results[i] = l.enqueue(ctx, key)
+	}
+	values := make([]V, len(keys))
+	for i, r := range results {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		values[i] = r.value
+	}
+	return values, nil
+}
+
+// Prime caches the value of key, e.g. an object fetched by another query
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	r := &result[V]{done: make(chan struct{}), value: value}
+	close(r.done)
+	l.results[key] = r
+}
+
+// Clear removes the cached value of key, e.g. after a mutation changed it
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.results, key)
+}
+
+// enqueue returns the result of key, adding the key to the pending batch unless it is cached
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r, ok := l.results[key]; ok {
+		return r
+	}
+
+	r := &result[V]{done: make(chan struct{})}
+	l.results[key] = r
+	if l.batch == nil {
+		b := &batch[K, V]{ctx: context.WithoutCancel(ctx)}
+		b.timer = time.AfterFunc(l.opts.Wait, func() {
+			l.dispatch(b)
+		})
+		l.batch = b
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	if len(b.keys) >= l.opts.MaxBatch && b.timer.Stop() {
+		l.batch = nil
+		go l.dispatch(b)
+	}
+	return r
+}
+
+// dispatch fetches a batch and completes its results
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.safeFetch(b)
+	for i, key := range b.keys {
+		r := b.results[i]
+		if err != nil {
+			r.err = err
+		} else if value, ok := values[key]; ok {
+			r.value = value
+		} else {
+			r.err = ErrNotFound
+		}
+		close(r.done)
+	}
+
+	// Failed loads are retried by later requests for the keys
+	if err != nil {
+		l.mu.Lock()
+		for i, key := range b.keys {
+			if l.results[key] == b.results[i] {
+				delete(l.results, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// safeFetch fetches a batch, turning a panic of the batch function into an error, since it
+// runs outside of the resolvers
+func (l *Loader[K, V]) safeFetch(b *batch[K, V]) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("graphql: batch function panicked: %v", r)
+		}
+	}()
+	return l.fetch(b.ctx, b.keys)
+}
+
+// loaderKey is the context key of a loader attached with WithLoader
+type loaderKey string
+
+// WithLoader returns a context carrying loader under name, for the resolvers of a request
+func WithLoader[K comparable, V any](ctx context.Context, name string, loader *Loader[K, V]) context.Context {
+	return context.WithValue(ctx, loaderKey(name), loader)
+}
+
+// LoaderFrom returns the loader attached to ctx under name, or nil
+func LoaderFrom[K comparable, V any](ctx context.Context, name string) *Loader[K, V] {
+	loader, _ := ctx.Value(loaderKey(name)).(*Loader[K, V])
+	return loader
+}
@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetch returns a batch function doubling the keys, except 0 which is never found,
+// and the batches it received
+func recordingFetch() (BatchFunc[int, int], func() [][]int) {
+	var mu sync.Mutex
+	var batches [][]int
+	fetch := func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		batches = append(batches, append([]int(nil), keys...))
+		mu.Unlock()
+		values := make(map[int]int, len(keys))
+		for _, key := range keys {
+			if key != 0 {
+				values[key] = key * 2
+			}
+		}
+		return values, nil
+	}
+	return fetch, func() [][]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return batches
+	}
+}
+
+func TestLoaderBatching(t *testing.T) {
+	fetch, batches := recordingFetch()
+	loader := NewLoader(fetch, LoaderOptions{Wait: 10 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	values := make([]int, 5)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), i%3+1)
+			assert.NoError(t, err)
+			values[i] = value
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{2, 4, 6, 2, 4}, values)
+	require.Len(t, batches(), 1, "concurrent loads share a batch")
+	keys := batches()[0]
+	sort.Ints(keys)
+	assert.Equal(t, []int{1, 2, 3}, keys, "keys are deduplicated")
+
+	value, err := loader.Load(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 4, value)
+	assert.Len(t, batches(), 1, "loaded keys are cached")
+
+	loader.Clear(2)
+	loader.Prime(7, 70)
+	got, err := loader.LoadMany(context.Background(), []int{7, 2, 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int{70, 4, 2}, got)
+	assert.Equal(t, []int{2}, batches()[1], "cleared keys are fetched again")
+
+	_, err = loader.Load(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = loader.LoadMany(context.Background(), []int{1, 0})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	fetch, batches := recordingFetch()
+	loader := NewLoader(fetch, LoaderOptions{Wait: time.Hour, MaxBatch: 2})
+
+	values, err := loader.LoadMany(context.Background(), []int{1, 2})
+	require.NoError(t, err, "a full batch is fetched without waiting")
+	assert.Equal(t, []int{2, 4}, values)
+	assert.Equal(t, [][]int{{1, 2}}, batches())
+}
+
+func TestLoaderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		fetch func(calls int) (map[string]int, error)
+		err   string
+	}{
+		{
+			name: "error",
+			fetch: func(calls int) (map[string]int, error) {
+				if calls == 1 {
+					return nil, errors.New("database unavailable")
+				}
+				return map[string]int{"a": 1}, nil
+			},
+			err: "database unavailable",
+		},
+		{
+			name: "panic",
+			fetch: func(calls int) (map[string]int, error) {
+				if calls == 1 {
+					panic("nil map")
+				}
+				return map[string]int{"a": 1}, nil
+			},
+			err: "graphql: batch function panicked: nil map",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			loader := NewLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+				calls++
+				return tt.fetch(calls)
+			}, LoaderOptions{Wait: time.Millisecond})
+
+			_, err := loader.Load(context.Background(), "a")
+			assert.EqualError(t, err, tt.err)
+			value, err := loader.Load(context.Background(), "a")
+			require.NoError(t, err, "failed keys are retried")
+			assert.Equal(t, 1, value)
+		})
+	}
+}
+
+func TestLoaderContext(t *testing.T) {
+	fetch, _ := recordingFetch()
+	loader := NewLoader(fetch, LoaderOptions{Wait: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := loader.Load(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx = WithLoader(context.Background(), "orders", loader)
+	assert.Same(t, loader, LoaderFrom[int, int](ctx, "orders"))
+	assert.Nil(t, LoaderFrom[int, int](ctx, "customers"))
+	assert.Nil(t, LoaderFrom[string, int](ctx, "orders"), "loaders of other types are not returned")
+}
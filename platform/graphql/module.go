@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/axiomod/axiomod/platform/server"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// EndpointsGroup is the fx value group collecting the GraphQL endpoints of modules
+const EndpointsGroup = "graphql_endpoints"
+
+// RequestFuncsGroup is the fx value group collecting the functions preparing requests
+const RequestFuncsGroup = "graphql_request_funcs"
+
+// Module serves the GraphQL endpoints of the modules on the HTTP server, under graphql.path.
+// It is not part of the bootstrap modules; add it to applications serving GraphQL.
+var Module = fx.Options(
+	fx.Provide(ProvideServer),
+	fx.Provide(fx.Annotate(ProvideRoutes, fx.ResultTags(`group:"`+server.HTTPRoutesGroup+`"`))),
+)
+
+// Endpoint is a GraphQL handler served under Path, relative to graphql.path
+type Endpoint struct {
+	Path    string
+	Handler http.Handler
+}
+
+// AsEndpoint serves the GraphQL handler of type T, such as a type embedding the
+// handler.Server of gqlgen, under path:
+//
+//	graphql.AsEndpoint[*graph.Server]("/orders")
+func AsEndpoint[T http.Handler](path string) fx.Option {
+	return fx.Provide(fx.Annotate(func(handler T) Endpoint {
+		return Endpoint{Path: path, Handler: handler}
+	}, fx.ResultTags(`group:"`+EndpointsGroup+`"`)))
+}
+
+// AsRequestFunc annotates a constructor returning a RequestFunc so that it prepares every
+// request, e.g. with the data loaders of a module:
+//
+//	fx.Provide(graphql.AsRequestFunc(NewOrderLoaders))
+func AsRequestFunc(constructor interface{}) interface{} {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"`+RequestFuncsGroup+`"`))
+}
+
+// ServerParams holds the dependencies of the server
+type ServerParams struct {
+	fx.In
+
+	Config       *config.Config
+	Logger       *observability.Logger
+	Tracer       *observability.Tracer  `optional:"true"`
+	Metrics      *observability.Metrics `optional:"true"`
+	RequestFuncs []RequestFunc          `group:"graphql_request_funcs"`
+}
+
+// ProvideServer creates the server with the limits of the graphql configuration
+func ProvideServer(p ServerParams) *Server {
+	cfg := p.Config.GraphQL
+	limits := Limits{MaxDepth: cfg.MaxDepth, MaxComplexity: cfg.MaxComplexity}
+	if limits.MaxDepth == 0 {
+		limits.MaxDepth = DefaultMaxDepth
+	}
+	if limits.MaxComplexity == 0 {
+		limits.MaxComplexity = DefaultMaxComplexity
+	}
+	return NewServer(limits, p.Logger).WithTracer(p.Tracer).WithMetrics(p.Metrics).OnRequest(p.RequestFuncs...)
+}
+
+// RoutesParams holds the endpoints served on the HTTP server
+type RoutesParams struct {
+	fx.In
+
+	Config    *config.Config
+	Logger    *observability.Logger
+	Server    *Server
+	Auth      *middleware.AuthMiddleware `optional:"true"`
+	Endpoints []Endpoint                 `group:"graphql_endpoints"`
+}
+
+// ProvideRoutes returns the routes of the endpoints, accepting GET and POST requests. With
+// graphql.allowAnonymous, the JWT middleware lets requests without a token through.
+func ProvideRoutes(p RoutesParams) server.HTTPRoutes {
+	prefix := p.Config.GraphQL.Path
+	if prefix == "" {
+		prefix = "/graphql"
+	}
+
+	return server.HTTPRoutes{Register: func(router fiber.Router) {
+		for _, endpoint := range p.Endpoints {
+			route := path.Join(prefix, endpoint.Path)
+			if p.Config.GraphQL.AllowAnonymous && p.Auth != nil {
+				p.Auth.AllowAnonymous("", route)
+			}
+			handler := p.Server.Handler(endpoint.Handler)
+			router.Get(route, handler)
+			router.Post(route, handler)
+			p.Logger.Debug("Registered GraphQL endpoint", zap.String("path", route))
+		}
+	}}
+}
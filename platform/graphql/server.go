@@ -0,0 +1,221 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/adaptor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RequestFunc prepares the context of a GraphQL request before it is executed, e.g. to
+// attach the data loaders of the request
+type RequestFunc func(ctx context.Context) context.Context
+
+// requestContextKey is the fiber local passing the context of a request to the handler
+type requestContextKey struct{}
+
+// params are the parameters of a GraphQL request
+type params struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Server runs GraphQL handlers within the limits, authentication and observability of the
+// application
+type Server struct {
+	limits       Limits
+	logger       *observability.Logger
+	tracer       *observability.Tracer
+	metrics      *observability.Metrics
+	requestFuncs []RequestFunc
+}
+
+// NewServer creates a server checking operations against limits
+func NewServer(limits Limits, logger *observability.Logger) *Server {
+	return &Server{limits: limits, logger: logger}
+}
+
+// WithTracer traces operations and resolvers with tracer
+func (s *Server) WithTracer(tracer *observability.Tracer) *Server {
+	s.tracer = tracer
+	return s
+}
+
+// WithMetrics counts operations in metrics
+func (s *Server) WithMetrics(metrics *observability.Metrics) *Server {
+	s.metrics = metrics
+	return s
+}
+
+// OnRequest adds functions preparing the context of every request
+func (s *Server) OnRequest(funcs ...RequestFunc) *Server {
+	s.requestFuncs = append(s.requestFuncs, funcs...)
+	return s
+}
+
+// Handler returns a Fiber handler serving GraphQL requests with h, such as the handler.Server
+// of gqlgen. Operations exceeding the limits are rejected before reaching h. The context of
+// the requests carries the claims of the user, set by the JWT or session middleware, and the
+// span of the operation.
+func (s *Server) Handler(h http.Handler) fiber.Handler {
+	next := adaptor.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctx, ok := r.Context().Value(requestContextKey{}).(context.Context); ok {
+			r = r.WithContext(ctx)
+		}
+		h.ServeHTTP(w, r)
+	}))
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		opType, opName := "unknown", ""
+		if p, ok := requestParams(c); ok {
+			if doc, err := parser.ParseQuery(&ast.Source{Input: p.Query}); err == nil {
+				if op := doc.Operations.ForName(p.OperationName); op != nil {
+					opType, opName = string(op.Operation), op.Name
+					if err := s.limits.Check(doc, op, p.Variables); err != nil {
+						s.logger.Warn("Rejected GraphQL operation", zap.String("operation", opName), zap.Error(err))
+						s.record(opType, "rejected", start)
+						return rejectOperation(c, err)
+					}
+				}
+			}
+		}
+
+		ctx := c.UserContext()
+		if claims := claimsFromLocals(c); claims != nil {
+			ctx = withClaims(ctx, claims)
+		}
+		ctx, span := s.start(ctx, strings.TrimSpace("graphql."+opType+" "+opName), trace.SpanKindServer)
+		defer span.End()
+		span.SetAttributes(
+			attribute.String("graphql.operation.type", opType),
+			attribute.String("graphql.operation.name", opName),
+		)
+		for _, fn := range s.requestFuncs {
+			ctx = fn(ctx)
+		}
+		c.Locals(requestContextKey{}, ctx)
+
+		err := next(c)
+		result := "success"
+		if status := c.Response().StatusCode(); err != nil || status >= http.StatusBadRequest {
+			result = "error"
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		s.record(opType, result, start)
+		return err
+	}
+}
+
+// TraceField resolves the field of object with next in a span. With gqlgen, trace the
+// resolvers, leaving out the fields read from structs:
+//
+//	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (any, error) {
+//		fc := graphql.GetFieldContext(ctx)
+//		if !fc.IsResolver {
+//			return next(ctx)
+//		}
+//		return server.TraceField(ctx, fc.Object, fc.Field.Name, next)
+//	})
+func (s *Server) TraceField(ctx context.Context, object, field string, next func(ctx context.Context) (any, error)) (any, error) {
+	ctx, span := s.start(ctx, "graphql.resolve "+object+"."+field, trace.SpanKindInternal)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("graphql.object", object),
+		attribute.String("graphql.field", field),
+	)
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return res, err
+}
+
+// start starts a span, unless the server has no tracer
+func (s *Server) start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	if s.tracer == nil || s.tracer.Tracer == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return s.tracer.Tracer.Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// record counts an operation
+func (s *Server) record(opType, result string, start time.Time) {
+	if s.metrics == nil || s.metrics.GraphQLOperationsTotal == nil {
+		return
+	}
+	s.metrics.GraphQLOperationsTotal.WithLabelValues(opType, result).Inc()
+	s.metrics.GraphQLOperationDuration.WithLabelValues(opType).Observe(time.Since(start).Seconds())
+}
+
+// requestParams returns the parameters of a GET request, a JSON or application/graphql POST
+// request, or a multipart upload. Other requests are left to the handler.
+func requestParams(c *fiber.Ctx) (params, bool) {
+	var p params
+	switch {
+	case c.Method() == fiber.MethodGet:
+		p.Query, p.OperationName = c.Query("query"), c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			_ = json.Unmarshal([]byte(variables), &p.Variables)
+		}
+	case c.Method() != fiber.MethodPost:
+		return p, false
+	case strings.HasPrefix(c.Get(fiber.HeaderContentType), "application/graphql"):
+		p.Query = string(c.Body())
+	case strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm):
+		if err := json.Unmarshal([]byte(c.FormValue("operations")), &p); err != nil {
+			return p, false
+		}
+	default:
+		if err := json.Unmarshal(c.Body(), &p); err != nil {
+			return p, false
+		}
+	}
+	return p, p.Query != ""
+}
+
+// rejectOperation responds to an operation exceeding a limit with a GraphQL error
+func rejectOperation(c *fiber.Ctx, err error) error {
+	code := "OPERATION_LIMIT_EXCEEDED"
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		code = strings.ToUpper(limitErr.Limit) + "_LIMIT_EXCEEDED"
+	}
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"errors": []fiber.Map{{
+			"message":    err.Error(),
+			"extensions": fiber.Map{"code": code},
+		}},
+	})
+}
+
+// claimsFromLocals returns the identity set by the JWT or session middleware, or nil for
+// anonymous requests
+func claimsFromLocals(c *fiber.Ctx) *auth.Claims {
+	var claims auth.Claims
+	claims.UserID, _ = c.Locals("user_id").(string)
+	if claims.UserID == "" {
+		return nil
+	}
+	claims.Username, _ = c.Locals("username").(string)
+	claims.Email, _ = c.Locals("email").(string)
+	claims.Roles, _ = c.Locals("roles").([]string)
+	return &claims
+}
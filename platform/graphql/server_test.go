@@ -0,0 +1,242 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type tenantKey struct{}
+
+// echoHandler stands in for a GraphQL handler, answering with what its context carries
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var userID string
+	if claims, ok := ClaimsFromContext(r.Context()); ok {
+		userID = claims.UserID
+	}
+	tenant, _ := r.Context().Value(tenantKey{}).(string)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"user": userID, "tenant": tenant})
+})
+
+func TestServerHandler(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{MetricsEnabled: true}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := &observability.Tracer{Tracer: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")}
+
+	s := NewServer(Limits{MaxDepth: 3, MaxComplexity: 20}, logger).WithTracer(tracer).WithMetrics(metrics).
+		OnRequest(func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, tenantKey{}, "acme")
+		})
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("Authorization") != "" {
+			c.Locals("user_id", "42")
+		}
+		return c.Next()
+	})
+	app.All("/graphql", s.Handler(echoHandler))
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		token       bool
+		status      int
+		response    string
+	}{
+		{
+			name:        "json",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{"query":"query Me { me { id } }"}`,
+			token:       true,
+			status:      http.StatusOK,
+			response:    `{"user":"42","tenant":"acme"}`,
+		},
+		{
+			name:     "get",
+			method:   http.MethodGet,
+			target:   "?query=" + strings.ReplaceAll("{ me { id } }", " ", "%20"),
+			status:   http.StatusOK,
+			response: `{"user":"","tenant":"acme"}`,
+		},
+		{
+			name:        "application/graphql",
+			method:      http.MethodPost,
+			contentType: "application/graphql",
+			body:        `mutation { logout }`,
+			status:      http.StatusOK,
+			response:    `{"user":"","tenant":"acme"}`,
+		},
+		{
+			name:        "too deep",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{"query":"{ a { b { c { d } } } }"}`,
+			status:      http.StatusUnprocessableEntity,
+			response:    `{"errors":[{"message":"graphql: operation depth 4 exceeds the limit of 3","extensions":{"code":"DEPTH_LIMIT_EXCEEDED"}}]}`,
+		},
+		{
+			name:        "too complex",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{"query":"query Orders($n: Int) { orders(first: $n) { id } }","variables":{"n":50}}`,
+			status:      http.StatusUnprocessableEntity,
+			response:    `{"errors":[{"message":"graphql: operation complexity 51 exceeds the limit of 20","extensions":{"code":"COMPLEXITY_LIMIT_EXCEEDED"}}]}`,
+		},
+		{
+			name:        "invalid queries are left to the handler",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{"query":"{ a { b { c { d "}`,
+			status:      http.StatusOK,
+			response:    `{"user":"","tenant":"acme"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/graphql"+tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.token {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body, _ := io.ReadAll(resp.Body)
+			assert.JSONEq(t, tt.response, string(body))
+		})
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.GraphQLOperationsTotal.WithLabelValues("query", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.GraphQLOperationsTotal.WithLabelValues("mutation", "success")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.GraphQLOperationsTotal.WithLabelValues("query", "rejected")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.GraphQLOperationsTotal.WithLabelValues("unknown", "success")))
+
+	var spans []string
+	for _, span := range exporter.GetSpans() {
+		spans = append(spans, span.Name)
+	}
+	assert.Equal(t, []string{"graphql.query Me", "graphql.query", "graphql.mutation", "graphql.unknown"}, spans)
+}
+
+func TestServerTraceField(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := &observability.Tracer{Tracer: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")}
+	s := NewServer(Limits{}, logger).WithTracer(tracer)
+
+	res, err := s.TraceField(context.Background(), "Order", "items", func(ctx context.Context) (any, error) {
+		return []string{"book"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"book"}, res)
+	_, err = s.TraceField(context.Background(), "Order", "customer", func(ctx context.Context) (any, error) {
+		return nil, errors.New("customer service unavailable")
+	})
+	assert.EqualError(t, err, "customer service unavailable")
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "graphql.resolve Order.items", spans[0].Name)
+	assert.Equal(t, "graphql.resolve Order.customer", spans[1].Name)
+	assert.Len(t, spans[1].Events, 1, "the error is recorded")
+
+	_, err = NewServer(Limits{}, logger).TraceField(context.Background(), "Order", "id", func(ctx context.Context) (any, error) {
+		return "1", nil
+	})
+	assert.NoError(t, err, "servers without a tracer resolve the field")
+}
+
+func TestAuthorize(t *testing.T) {
+	resolve := func(ctx context.Context) (any, error) {
+		return "secret", nil
+	}
+	admin := withClaims(context.Background(), &auth.Claims{UserID: "1", Roles: []string{"admin"}})
+	user := withClaims(context.Background(), &auth.Claims{UserID: "2", Roles: []string{"user"}})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		roles   []string
+		wantErr error
+	}{
+		{name: "anonymous", ctx: context.Background(), wantErr: ErrUnauthenticated},
+		{name: "any user", ctx: user},
+		{name: "with a role", ctx: admin, roles: []string{"support", "admin"}},
+		{name: "without the roles", ctx: user, roles: []string{"admin"}, wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Authorize(tt.ctx, resolve, tt.roles...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "secret", res)
+		})
+	}
+}
+
+func TestProvideRoutes(t *testing.T) {
+	cfg := &config.Config{
+		HTTP:    config.HTTPConfig{Auth: config.HTTPAuthConfig{Enabled: true}},
+		GraphQL: config.GraphQLConfig{AllowAnonymous: true},
+	}
+	logger, _ := observability.NewLogger(cfg)
+	authMid := middleware.NewAuthMiddleware(cfg, auth.NewJWTService("secret", time.Hour), logger)
+	s := ProvideServer(ServerParams{Config: cfg, Logger: logger})
+	assert.Equal(t, Limits{MaxDepth: DefaultMaxDepth, MaxComplexity: DefaultMaxComplexity}, s.limits)
+
+	routes := ProvideRoutes(RoutesParams{
+		Config:    cfg,
+		Logger:    logger,
+		Server:    s,
+		Auth:      authMid,
+		Endpoints: []Endpoint{{Handler: echoHandler}, {Path: "/orders", Handler: echoHandler}},
+	})
+	app := fiber.New()
+	app.Use(authMid.Handle())
+	routes.Register(app)
+	app.Get("/private", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	for target, status := range map[string]int{
+		"/graphql?query=%7Bme%7D":        http.StatusOK,
+		"/graphql/orders?query=%7Bme%7D": http.StatusOK,
+		"/private":                       http.StatusUnauthorized,
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode, target)
+	}
+}
@@ -0,0 +1,149 @@
+package observability
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Label values used by CardinalityGuard for values that are not tracked individually
+const (
+	OtherLabelValue   = "other"
+	UnknownLabelValue = "unknown"
+)
+
+// CardinalityGuard bounds the number of distinct values used for a metric label.
+// The top-N values by traffic keep their own label; everything else is folded into
+// OtherLabelValue. Rankings are recomputed every interval.
+type CardinalityGuard struct {
+	mu            sync.Mutex
+	limit         int
+	interval      time.Duration
+	admitted      map[string]uint64
+	candidates    map[string]uint64
+	lastRebalance time.Time
+	onEvict       []func(value string)
+}
+
+// NewCardinalityGuard creates a guard allowing at most limit distinct values.
+// An interval of zero disables periodic re-ranking.
+func NewCardinalityGuard(limit int, interval time.Duration) *CardinalityGuard {
+	if limit < 1 {
+		limit = 1
+	}
+	return &CardinalityGuard{
+		limit:         limit,
+		interval:      interval,
+		admitted:      make(map[string]uint64),
+		candidates:    make(map[string]uint64),
+		lastRebalance: time.Now(),
+	}
+}
+
+// OnEvict registers a callback invoked when a value loses its own label,
+// typically used to delete the stale series from a metric vector
+func (g *CardinalityGuard) OnEvict(fn func(value string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onEvict = append(g.onEvict, fn)
+}
+
+// Label returns the label value to use for value
+func (g *CardinalityGuard) Label(value string) string {
+	if value == "" {
+		return UnknownLabelValue
+	}
+
+	var evicted []string
+	g.mu.Lock()
+	if g.interval > 0 && time.Since(g.lastRebalance) >= g.interval {
+		evicted = g.rebalanceLocked()
+	}
+
+	label := OtherLabelValue
+	if _, ok := g.admitted[value]; ok {
+		g.admitted[value]++
+		label = value
+	} else if len(g.admitted) < g.limit {
+		g.admitted[value] = 1
+		label = value
+	} else if _, ok := g.candidates[value]; ok || len(g.candidates) < g.limit*4 {
+		// Bound the candidate map too, so a flood of unique values can't grow it
+		g.candidates[value]++
+	}
+	callbacks := g.onEvict
+	g.mu.Unlock()
+
+	g.notify(callbacks, evicted)
+	return label
+}
+
+// Rebalance re-ranks values by traffic since the last rebalance and keeps the top-N
+func (g *CardinalityGuard) Rebalance() {
+	g.mu.Lock()
+	evicted := g.rebalanceLocked()
+	callbacks := g.onEvict
+	g.mu.Unlock()
+
+	g.notify(callbacks, evicted)
+}
+
+// Admitted returns the values currently tracked with their own label
+func (g *CardinalityGuard) Admitted() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	values := make([]string, 0, len(g.admitted))
+	for value := range g.admitted {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// rebalanceLocked recomputes the admitted set and returns the evicted values
+func (g *CardinalityGuard) rebalanceLocked() []string {
+	type entry struct {
+		value string
+		hits  uint64
+	}
+
+	entries := make([]entry, 0, len(g.admitted)+len(g.candidates))
+	for value, hits := range g.admitted {
+		entries = append(entries, entry{value, hits})
+	}
+	for value, hits := range g.candidates {
+		entries = append(entries, entry{value, hits})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].hits != entries[j].hits {
+			return entries[i].hits > entries[j].hits
+		}
+		return entries[i].value < entries[j].value
+	})
+
+	next := make(map[string]uint64, g.limit)
+	for i := 0; i < len(entries) && i < g.limit; i++ {
+		next[entries[i].value] = 0
+	}
+
+	var evicted []string
+	for value := range g.admitted {
+		if _, ok := next[value]; !ok {
+			evicted = append(evicted, value)
+		}
+	}
+
+	g.admitted = next
+	g.candidates = make(map[string]uint64)
+	g.lastRebalance = time.Now()
+	return evicted
+}
+
+func (g *CardinalityGuard) notify(callbacks []func(string), evicted []string) {
+	for _, value := range evicted {
+		for _, fn := range callbacks {
+			fn(value)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package observability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardinalityGuard(t *testing.T) {
+	t.Run("Folds values beyond the limit into other", func(t *testing.T) {
+		g := NewCardinalityGuard(2, 0)
+
+		assert.Equal(t, "a", g.Label("a"))
+		assert.Equal(t, "b", g.Label("b"))
+		assert.Equal(t, OtherLabelValue, g.Label("c"))
+		assert.Equal(t, "a", g.Label("a"))
+		assert.Equal(t, UnknownLabelValue, g.Label(""))
+		assert.Equal(t, []string{"a", "b"}, g.Admitted())
+	})
+
+	t.Run("Rebalance promotes the busiest values", func(t *testing.T) {
+		g := NewCardinalityGuard(2, 0)
+		var evicted []string
+		g.OnEvict(func(value string) { evicted = append(evicted, value) })
+
+		g.Label("a")
+		g.Label("b")
+		for i := 0; i < 5; i++ {
+			g.Label("c")
+		}
+		g.Label("b")
+
+		g.Rebalance()
+		assert.Equal(t, []string{"b", "c"}, g.Admitted())
+		assert.Equal(t, []string{"a"}, evicted)
+		assert.Equal(t, "c", g.Label("c"))
+		assert.Equal(t, OtherLabelValue, g.Label("a"))
+	})
+
+	t.Run("Candidate tracking is bounded", func(t *testing.T) {
+		g := NewCardinalityGuard(1, 0)
+		g.Label("admitted")
+		for i := 0; i < 100; i++ {
+			g.Label(string(rune('A' + i)))
+		}
+		assert.LessOrEqual(t, len(g.candidates), 4)
+	})
+}
@@ -0,0 +1,121 @@
+package observability
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrCoresUnsupported is returned by AddCore for loggers not created by NewLogger
+var ErrCoresUnsupported = errors.New("logger does not support additional cores")
+
+// extraCores are cores added to a logger at runtime, e.g. by a plugin shipping logs. They
+// are shared by the logger and every logger derived from it.
+type extraCores struct {
+	mu    sync.Mutex // serializes changes
+	cores atomic.Pointer[[]zapcore.Core]
+}
+
+// load returns the current cores
+func (e *extraCores) load() []zapcore.Core {
+	if cores := e.cores.Load(); cores != nil {
+		return *cores
+	}
+	return nil
+}
+
+// add adds a core and returns a function removing it again
+func (e *extraCores) add(core zapcore.Core) func() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cores := append(append([]zapcore.Core(nil), e.load()...), core)
+	e.cores.Store(&cores)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			var remaining []zapcore.Core
+			for _, c := range e.load() {
+				if c != core {
+					remaining = append(remaining, c)
+				}
+			}
+			e.cores.Store(&remaining)
+		})
+	}
+}
+
+// teeCore writes to its base core and to the extra cores. Fields added with With are
+// kept so they can be applied to cores added later.
+type teeCore struct {
+	zapcore.Core
+	extra  *extraCores
+	fields []zapcore.Field
+}
+
+// wrapCore returns a zap option teeing the logger's core to extra
+func wrapCore(extra *extraCores) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &teeCore{Core: core, extra: extra}
+	})
+}
+
+// Enabled reports whether the base core or any extra core logs at level
+func (c *teeCore) Enabled(level zapcore.Level) bool {
+	if c.Core.Enabled(level) {
+		return true
+	}
+	for _, core := range c.extra.load() {
+		if core.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// With adds fields to the base core and remembers them for the extra cores
+func (c *teeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &teeCore{
+		Core:   c.Core.With(fields),
+		extra:  c.extra,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// Check adds the base core and the extra cores that log the entry
+func (c *teeCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(entry, ce)
+	for _, core := range c.extra.load() {
+		if !core.Enabled(entry.Level) {
+			continue
+		}
+		if len(c.fields) > 0 {
+			core = core.With(c.fields)
+		}
+		ce = core.Check(entry, ce)
+	}
+	return ce
+}
+
+// Sync flushes the base core and the extra cores
+func (c *teeCore) Sync() error {
+	err := c.Core.Sync()
+	for _, core := range c.extra.load() {
+		err = errors.Join(err, core.Sync())
+	}
+	return err
+}
+
+// AddCore makes the logger, and every logger derived from it, also write to core, e.g.
+// to ship logs to a remote store. It returns a function removing the core again.
+func (l *Logger) AddCore(core zapcore.Core) (remove func(), err error) {
+	if l == nil || l.cores == nil {
+		return nil, ErrCoresUnsupported
+	}
+	return l.cores.add(core), nil
+}
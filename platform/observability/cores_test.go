@@ -0,0 +1,45 @@
+package observability
+
+import (
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerAddCore(t *testing.T) {
+	logger, err := NewLogger(&config.Config{
+		App:           config.AppConfig{Name: "shop", Environment: "test"},
+		Observability: config.ObservabilityConfig{LogLevel: "error"},
+	})
+	require.NoError(t, err)
+	before := logger.With(zap.String("component", "orders"))
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	remove, err := logger.AddCore(core)
+	require.NoError(t, err)
+
+	logger.Debug("below the logger's level")
+	before.Info("derived before the core was added", zap.Int("attempt", 1))
+	logger.WithTenant("acme").Warn("tenant")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3, "the added core has its own level")
+	assert.Equal(t, map[string]interface{}{
+		"service": "shop", "environment": "test", "component": "orders", "attempt": int64(1),
+	}, entries[1].ContextMap(), "fields added before the core are kept")
+	assert.Equal(t, "acme", entries[2].ContextMap()["tenant_id"])
+
+	remove()
+	remove()
+	logger.Error("after removal")
+	assert.Equal(t, 3, logs.Len())
+
+	_, err = (&Logger{Logger: zap.NewNop()}).AddCore(core)
+	assert.ErrorIs(t, err, ErrCoresUnsupported)
+}
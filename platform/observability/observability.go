@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
 
@@ -35,6 +36,7 @@ var Module = fx.Options(
 // Logger is a wrapper around zap.Logger
 type Logger struct {
 	*zap.Logger
+	cores *extraCores // added with AddCore
 }
 
 // NewLogger creates a new logger
@@ -54,7 +56,9 @@ func NewLogger(cfg *config.Config) (*Logger, error) {
 
 	zapConfig.Level = zap.NewAtomicLevelAt(logLevel)
 
+	cores := &extraCores{}
 	logger, err := zapConfig.Build(
+		wrapCore(cores),
 		zap.AddCallerSkip(1),
 		zap.Fields(
 			zap.String("service", cfg.App.Name),
@@ -65,7 +69,15 @@ func NewLogger(cfg *config.Config) (*Logger, error) {
 		return nil, err
 	}
 
-	return &Logger{Logger: logger}, nil
+	return &Logger{Logger: logger, cores: cores}, nil
+}
+
+// WithTenant returns a logger annotated with the given tenant ID
+func (l *Logger) WithTenant(tenantID string) *Logger {
+	if tenantID == "" {
+		return l
+	}
+	return &Logger{Logger: l.Logger.With(zap.String("tenant_id", tenantID)), cores: l.cores}
 }
 
 // Tracer is a wrapper around trace.Tracer
@@ -141,7 +153,7 @@ func initTracer(cfg *config.Config) (*sdktrace.TracerProvider, error) {
 	}
 
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sampler),
+		sdktrace.WithSampler(NewDebugSampler(sampler)),
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
 	)
@@ -171,7 +183,70 @@ type Metrics struct {
 	HTTPRequestDuration *prometheus.HistogramVec
 	GRPCRequestsTotal   *prometheus.CounterVec
 	GRPCRequestDuration *prometheus.HistogramVec
-	DBQueryDuration     *prometheus.HistogramVec
+	// Messages sent and received on gRPC streams
+	GRPCStreamMessagesTotal *prometheus.CounterVec
+	DBQueryDuration         *prometheus.HistogramVec
+	OIDCRefreshTotal        *prometheus.CounterVec
+	OIDCKeysLastRefresh     prometheus.Gauge
+
+	// In-memory event bus metrics
+	EventBusPublishedTotal *prometheus.CounterVec
+	EventBusDeliveredTotal *prometheus.CounterVec
+	EventBusOverflowTotal  *prometheus.CounterVec
+	EventBusQueuedEvents   *prometheus.GaugeVec
+	EventBusDelayedEvents  prometheus.Gauge
+
+	// Domain event dispatcher metrics
+	DomainEventsPublishedTotal *prometheus.CounterVec
+	DomainEventsHandledTotal   *prometheus.CounterVec
+
+	// Command and query bus metrics
+	CQRSMessagesTotal   *prometheus.CounterVec
+	CQRSMessageDuration *prometheus.HistogramVec
+
+	// Cache warm-up metrics
+	CacheWarmupKeysTotal     *prometheus.CounterVec
+	CacheWarmupFailuresTotal *prometheus.CounterVec
+	CacheWarmupDuration      *prometheus.GaugeVec
+
+	// Circuit breaker metrics
+	CircuitBreakerState            *prometheus.GaugeVec
+	CircuitBreakerTransitionsTotal *prometheus.CounterVec
+	CircuitBreakerRequestsTotal    *prometheus.CounterVec
+
+	// Graceful degradation metrics
+	DegradationResultsTotal *prometheus.CounterVec
+
+	// Distributed lock metrics
+	LockAcquisitionsTotal *prometheus.CounterVec
+	LockHeldDuration      *prometheus.HistogramVec
+	LockLostTotal         *prometheus.CounterVec
+
+	// Background job metrics
+	JobRunsTotal       *prometheus.CounterVec
+	JobDuration        *prometheus.HistogramVec
+	JobLastSuccessTime *prometheus.GaugeVec
+
+	// Notification metrics
+	NotifyMessagesTotal *prometheus.CounterVec
+
+	// Redis client metrics
+	RedisCommandsTotal   *prometheus.CounterVec
+	RedisCommandDuration *prometheus.HistogramVec
+
+	// GraphQL metrics
+	GraphQLOperationsTotal   *prometheus.CounterVec
+	GraphQLOperationDuration *prometheus.HistogramVec
+
+	// TLS certificate metrics
+	TLSCertificateExpiry       *prometheus.GaugeVec
+	TLSCertificateReloadsTotal *prometheus.CounterVec
+
+	// Tenant-labelled metrics, only set when tenant labels are enabled
+	HTTPTenantRequestsTotal   *prometheus.CounterVec
+	HTTPTenantRequestDuration *prometheus.HistogramVec
+	TenantGuard               *CardinalityGuard
+	TenantHeader              string
 }
 
 // NewMetrics creates a new metrics registry
@@ -221,6 +296,13 @@ func NewMetrics(cfg *config.Config, logger *Logger) (*Metrics, error) {
 		},
 		[]string{"service", "method", "status"},
 	)
+	grpcStreamMessagesTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_stream_messages_total",
+			Help: "Total number of messages sent and received on gRPC streams",
+		},
+		[]string{"service", "method", "direction"},
+	)
 
 	dbQueryDuration := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -231,22 +313,371 @@ func NewMetrics(cfg *config.Config, logger *Logger) (*Metrics, error) {
 		[]string{"query_type", "status"},
 	)
 
+	oidcRefreshTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oidc_refresh_total",
+			Help: "Total number of OIDC discovery and JWKS refreshes",
+		},
+		[]string{"result"},
+	)
+	oidcKeysLastRefresh := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "oidc_keys_last_refresh_timestamp_seconds",
+			Help: "Unix time the OIDC signing keys in use were fetched from the issuer",
+		},
+	)
+
+	eventBusPublishedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_bus_published_total",
+			Help: "Total number of events published on the in-memory event bus",
+		},
+		[]string{"topic"},
+	)
+	eventBusDeliveredTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_bus_delivered_total",
+			Help: "Total number of events handed to event bus subscribers",
+		},
+		[]string{"topic", "result"},
+	)
+	eventBusOverflowTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_bus_overflow_total",
+			Help: "Total number of events that found a subscriber's mailbox full, by the action taken",
+		},
+		[]string{"topic", "action"},
+	)
+	eventBusQueuedEvents := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "event_bus_queued_events",
+			Help: "Number of events waiting in event bus subscriber mailboxes",
+		},
+		[]string{"topic"},
+	)
+	eventBusDelayedEvents := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "event_bus_delayed_events",
+			Help: "Number of delayed events waiting to be published on the event bus",
+		},
+	)
+
+	domainEventsPublishedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "domain_events_published_total",
+			Help: "Total number of domain events published on the dispatcher",
+		},
+		[]string{"event"},
+	)
+	domainEventsHandledTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "domain_events_handled_total",
+			Help: "Total number of domain events handled by event, handler and result, after retries",
+		},
+		[]string{"event", "handler", "result"},
+	)
+
+	cqrsMessagesTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cqrs_messages_total",
+			Help: "Total number of commands and queries dispatched by bus, message and result",
+		},
+		[]string{"bus", "message", "result"},
+	)
+	cqrsMessageDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cqrs_message_duration_seconds",
+			Help:    "Time taken to handle commands and queries in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"bus", "message"},
+	)
+
+	cacheWarmupKeysTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_warmup_keys_total",
+			Help: "Total number of cache entries written by warm-up loaders",
+		},
+		[]string{"loader"},
+	)
+	cacheWarmupFailuresTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_warmup_failures_total",
+			Help: "Total number of cache warm-up loaders that failed, timed out or had entries rejected",
+		},
+		[]string{"loader"},
+	)
+	cacheWarmupDuration := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cache_warmup_duration_seconds",
+			Help: "Duration of the last run of each cache warm-up loader in seconds",
+		},
+		[]string{"loader"},
+	)
+	circuitBreakerState := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "circuit_breaker_state",
+			Help: "State of each circuit breaker: 0 closed, 1 open, 2 half-open",
+		},
+		[]string{"name"},
+	)
+	circuitBreakerTransitionsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "circuit_breaker_transitions_total",
+			Help: "Total number of circuit breaker state changes",
+		},
+		[]string{"name", "from", "to"},
+	)
+	circuitBreakerRequestsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "circuit_breaker_requests_total",
+			Help: "Total number of requests through circuit breakers by result: success, failure or rejected",
+		},
+		[]string{"name", "result"},
+	)
+	degradationResultsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "degradation_results_total",
+			Help: "Total number of results of operations with fallbacks by source: primary, the fallback used, or failed",
+		},
+		[]string{"operation", "source"},
+	)
+	lockAcquisitionsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "lock_acquisitions_total",
+			Help: "Total number of attempts to acquire distributed locks by lock and result: acquired, busy or error",
+		},
+		[]string{"lock", "result"},
+	)
+	lockHeldDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "lock_held_duration_seconds",
+			Help:    "Time distributed locks were held in seconds",
+			Buckets: []float64{0.01, 0.1, 1, 10, 60, 300, 1800, 3600},
+		},
+		[]string{"lock"},
+	)
+	lockLostTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "lock_lost_total",
+			Help: "Total number of distributed locks lost while held because they could not be renewed",
+		},
+		[]string{"lock"},
+	)
+	jobRunsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "job_runs_total",
+			Help: "Total number of background job runs by job and outcome: success, failure, timeout or skipped",
+		},
+		[]string{"job", "outcome"},
+	)
+	jobDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "job_duration_seconds",
+			Help:    "Duration of background job runs in seconds",
+			Buckets: []float64{0.01, 0.1, 1, 10, 60, 300, 1800, 3600},
+		},
+		[]string{"job"},
+	)
+	jobLastSuccessTime := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "job_last_success_timestamp_seconds",
+			Help: "End of the last successful run of each background job as a Unix timestamp",
+		},
+		[]string{"job"},
+	)
+	notifyMessagesTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notify_messages_total",
+			Help: "Total number of notifications by channel, provider and result: sent or failed, after retries",
+		},
+		[]string{"channel", "provider", "result"},
+	)
+	redisCommandsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_commands_total",
+			Help: "Total number of Redis commands by command and result: success or error",
+		},
+		[]string{"command", "result"},
+	)
+	redisCommandDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Duration of Redis commands in seconds",
+			Buckets: []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		},
+		[]string{"command"},
+	)
+	graphQLOperationsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graphql_operations_total",
+			Help: "Total number of GraphQL operations by type and result: success, error or rejected",
+		},
+		[]string{"type", "result"},
+	)
+	graphQLOperationDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "graphql_operation_duration_seconds",
+			Help:    "Duration of GraphQL operations in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"type"},
+	)
+	tlsCertificateExpiry := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tls_certificate_expiry_timestamp_seconds",
+			Help: "Expiry of the TLS certificate served by each server as a Unix timestamp",
+		},
+		[]string{"server"},
+	)
+	tlsCertificateReloadsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tls_certificate_reloads_total",
+			Help: "Total number of TLS certificate reloads by server and result",
+		},
+		[]string{"server", "result"},
+	)
+
 	registry.MustRegister(httpRequestsTotal)
 	registry.MustRegister(httpRequestDuration)
 	registry.MustRegister(grpcRequestsTotal)
 	registry.MustRegister(grpcRequestDuration)
+	registry.MustRegister(grpcStreamMessagesTotal)
 	registry.MustRegister(dbQueryDuration)
+	registry.MustRegister(oidcRefreshTotal)
+	registry.MustRegister(oidcKeysLastRefresh)
+	registry.MustRegister(eventBusPublishedTotal)
+	registry.MustRegister(eventBusDeliveredTotal)
+	registry.MustRegister(eventBusOverflowTotal)
+	registry.MustRegister(eventBusQueuedEvents)
+	registry.MustRegister(eventBusDelayedEvents)
+	registry.MustRegister(domainEventsPublishedTotal)
+	registry.MustRegister(domainEventsHandledTotal)
+	registry.MustRegister(cqrsMessagesTotal)
+	registry.MustRegister(cqrsMessageDuration)
+	registry.MustRegister(cacheWarmupKeysTotal)
+	registry.MustRegister(cacheWarmupFailuresTotal)
+	registry.MustRegister(cacheWarmupDuration)
+	registry.MustRegister(circuitBreakerState)
+	registry.MustRegister(circuitBreakerTransitionsTotal)
+	registry.MustRegister(circuitBreakerRequestsTotal)
+	registry.MustRegister(degradationResultsTotal)
+	registry.MustRegister(lockAcquisitionsTotal)
+	registry.MustRegister(lockHeldDuration)
+	registry.MustRegister(lockLostTotal)
+	registry.MustRegister(jobRunsTotal)
+	registry.MustRegister(jobDuration)
+	registry.MustRegister(jobLastSuccessTime)
+	registry.MustRegister(notifyMessagesTotal)
+	registry.MustRegister(redisCommandsTotal)
+	registry.MustRegister(redisCommandDuration)
+	registry.MustRegister(graphQLOperationsTotal)
+	registry.MustRegister(graphQLOperationDuration)
+	registry.MustRegister(tlsCertificateExpiry)
+	registry.MustRegister(tlsCertificateReloadsTotal)
 
 	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 
+	m := &Metrics{
+		Registry:                registry,
+		Handler:                 handler,
+		HTTPRequestsTotal:       httpRequestsTotal,
+		HTTPRequestDuration:     httpRequestDuration,
+		GRPCRequestsTotal:       grpcRequestsTotal,
+		GRPCRequestDuration:     grpcRequestDuration,
+		GRPCStreamMessagesTotal: grpcStreamMessagesTotal,
+		DBQueryDuration:         dbQueryDuration,
+		OIDCRefreshTotal:        oidcRefreshTotal,
+		OIDCKeysLastRefresh:     oidcKeysLastRefresh,
+
+		EventBusPublishedTotal: eventBusPublishedTotal,
+		EventBusDeliveredTotal: eventBusDeliveredTotal,
+		EventBusOverflowTotal:  eventBusOverflowTotal,
+		EventBusQueuedEvents:   eventBusQueuedEvents,
+		EventBusDelayedEvents:  eventBusDelayedEvents,
+
+		DomainEventsPublishedTotal: domainEventsPublishedTotal,
+		DomainEventsHandledTotal:   domainEventsHandledTotal,
+
+		CQRSMessagesTotal:   cqrsMessagesTotal,
+		CQRSMessageDuration: cqrsMessageDuration,
+
+		CacheWarmupKeysTotal:     cacheWarmupKeysTotal,
+		CacheWarmupFailuresTotal: cacheWarmupFailuresTotal,
+		CacheWarmupDuration:      cacheWarmupDuration,
+
+		CircuitBreakerState:            circuitBreakerState,
+		CircuitBreakerTransitionsTotal: circuitBreakerTransitionsTotal,
+		CircuitBreakerRequestsTotal:    circuitBreakerRequestsTotal,
+
+		DegradationResultsTotal: degradationResultsTotal,
+
+		LockAcquisitionsTotal: lockAcquisitionsTotal,
+		LockHeldDuration:      lockHeldDuration,
+		LockLostTotal:         lockLostTotal,
+
+		JobRunsTotal:       jobRunsTotal,
+		JobDuration:        jobDuration,
+		JobLastSuccessTime: jobLastSuccessTime,
+
+		NotifyMessagesTotal: notifyMessagesTotal,
+
+		RedisCommandsTotal:   redisCommandsTotal,
+		RedisCommandDuration: redisCommandDuration,
+
+		GraphQLOperationsTotal:   graphQLOperationsTotal,
+		GraphQLOperationDuration: graphQLOperationDuration,
+
+		TLSCertificateExpiry:       tlsCertificateExpiry,
+		TLSCertificateReloadsTotal: tlsCertificateReloadsTotal,
+	}
+
+	if cfg.Observability.TenantLabelsEnabled {
+		registerTenantMetrics(cfg, m)
+		logger.Info("Tenant metric labels enabled", zap.Int("tenant_label_limit", cfg.Observability.TenantLabelLimit))
+	}
+
 	logger.Info("Metrics initialized", zap.Int("port", metricsPort))
-	return &Metrics{
-		Registry:            registry,
-		Handler:             handler,
-		HTTPRequestsTotal:   httpRequestsTotal,
-		HTTPRequestDuration: httpRequestDuration,
-		GRPCRequestsTotal:   grpcRequestsTotal,
-		GRPCRequestDuration: grpcRequestDuration,
-		DBQueryDuration:     dbQueryDuration,
-	}, nil
+	return m, nil
+}
+
+// registerTenantMetrics registers the tenant-labelled vectors guarded against label explosion
+func registerTenantMetrics(cfg *config.Config, m *Metrics) {
+	limit := cfg.Observability.TenantLabelLimit
+	if limit <= 0 {
+		limit = 20
+	}
+	interval := time.Duration(cfg.Observability.TenantRebalanceInterval) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	header := cfg.Observability.TenantHeader
+	if header == "" {
+		header = "X-Tenant-ID"
+	}
+
+	m.HTTPTenantRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_tenant_requests_total",
+			Help: "Total number of HTTP requests per tenant",
+		},
+		[]string{"tenant", "method", "status"},
+	)
+	m.HTTPTenantRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_tenant_request_duration_seconds",
+			Help:    "Duration of HTTP requests per tenant in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"tenant", "method", "status"},
+	)
+	m.Registry.MustRegister(m.HTTPTenantRequestsTotal)
+	m.Registry.MustRegister(m.HTTPTenantRequestDuration)
+
+	m.TenantGuard = NewCardinalityGuard(limit, interval)
+	m.TenantGuard.OnEvict(func(tenant string) {
+		m.HTTPTenantRequestsTotal.DeletePartialMatch(prometheus.Labels{"tenant": tenant})
+		m.HTTPTenantRequestDuration.DeletePartialMatch(prometheus.Labels{"tenant": tenant})
+	})
+	m.TenantHeader = header
 }
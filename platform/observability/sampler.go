@@ -0,0 +1,36 @@
+package observability
+
+import (
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// debugSampler samples the spans of requests carrying the debug trace baggage member and
+// leaves the other spans to the configured sampler
+type debugSampler struct {
+	base sdktrace.Sampler
+}
+
+// NewDebugSampler returns a sampler recording the spans of requests sent with debug tracing,
+// whatever base decides, so an allowed caller can trace a request through every service
+func NewDebugSampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return debugSampler{base: base}
+}
+
+// ShouldSample implements sdktrace.Sampler
+func (s debugSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if ctxkit.DebugTrace(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+// Description implements sdktrace.Sampler
+func (s debugSampler) Description() string {
+	return "DebugSampler{" + s.base.Description() + "}"
+}
@@ -0,0 +1,25 @@
+package observability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axiomod/axiomod/framework/ctxkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestDebugSampler(t *testing.T) {
+	sampler := NewDebugSampler(sdktrace.NeverSample())
+	assert.Equal(t, "DebugSampler{AlwaysOffSampler}", sampler.Description())
+
+	params := sdktrace.SamplingParameters{ParentContext: context.Background(), Name: "GET /orders"}
+	assert.Equal(t, sdktrace.Drop, sampler.ShouldSample(params).Decision)
+
+	ctx, err := ctxkit.WithBaggage(context.Background(), ctxkit.DebugTraceMember, "1")
+	require.NoError(t, err)
+	params.ParentContext = ctx
+	assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(params).Decision)
+}
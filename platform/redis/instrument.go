@@ -0,0 +1,151 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// hook traces the commands of a client and records their metrics
+type hook struct {
+	tracer  *observability.Tracer
+	metrics *observability.Metrics
+}
+
+// newHook creates a hook; tracer and metrics are optional
+func newHook(tracer *observability.Tracer, metrics *observability.Metrics) *hook {
+	return &hook{tracer: tracer, metrics: metrics}
+}
+
+// DialHook traces the connections opened to the servers
+func (h *hook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.start(ctx, "redis.dial")
+		defer span.End()
+
+		span.SetAttributes(attribute.String("net.peer.name", addr))
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return conn, err
+	}
+}
+
+// ProcessHook traces and measures a single command
+func (h *hook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		name := strings.ToLower(cmd.Name())
+		ctx, span := h.start(ctx, "redis."+name)
+		defer span.End()
+
+		span.SetAttributes(attribute.String("db.operation", name))
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(span, name, time.Since(start), err)
+		return err
+	}
+}
+
+// ProcessPipelineHook traces and measures a pipeline, recording the metrics of its commands
+// under the "pipeline" command
+func (h *hook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		ctx, span := h.start(ctx, "redis.pipeline")
+		defer span.End()
+
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = strings.ToLower(cmd.Name())
+		}
+		span.SetAttributes(
+			attribute.String("db.operation", "pipeline"),
+			attribute.StringSlice("db.redis.commands", names),
+		)
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(span, "pipeline", time.Since(start), err)
+		return err
+	}
+}
+
+// start starts the client span of an operation
+func (h *hook) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	if h.tracer == nil || h.tracer.Tracer == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	ctx, span := h.tracer.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(attribute.String("db.system", "redis"))
+	return ctx, span
+}
+
+// record ends the measure of a command; missing keys are not errors
+func (h *hook) record(span trace.Span, command string, elapsed time.Duration, err error) {
+	result := "success"
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		result = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if h.metrics == nil || h.metrics.RedisCommandsTotal == nil {
+		return
+	}
+	h.metrics.RedisCommandsTotal.WithLabelValues(command, result).Inc()
+	h.metrics.RedisCommandDuration.WithLabelValues(command).Observe(elapsed.Seconds())
+}
+
+// poolCollector exports the connection pool statistics of a client
+type poolCollector struct {
+	client     goredis.UniversalClient
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// newPoolCollector creates a collector of the pool statistics of client
+func newPoolCollector(client goredis.UniversalClient) *poolCollector {
+	return &poolCollector{
+		client:     client,
+		hits:       prometheus.NewDesc("redis_pool_hits_total", "Total number of times a free connection was found in the Redis pool", nil, nil),
+		misses:     prometheus.NewDesc("redis_pool_misses_total", "Total number of times a free connection was not found in the Redis pool", nil, nil),
+		timeouts:   prometheus.NewDesc("redis_pool_timeouts_total", "Total number of times waiting for a Redis connection timed out", nil, nil),
+		totalConns: prometheus.NewDesc("redis_pool_connections", "Number of connections in the Redis pool", nil, nil),
+		idleConns:  prometheus.NewDesc("redis_pool_idle_connections", "Number of idle connections in the Redis pool", nil, nil),
+		staleConns: prometheus.NewDesc("redis_pool_stale_connections_total", "Total number of stale connections removed from the Redis pool", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+// Collect implements prometheus.Collector
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Module provides the instrumented client of the redis configuration. The client is created
+// when a constructor depends on it; it is then reported by the "redis" health check and
+// closed when the application stops.
+var Module = fx.Options(
+	fx.Provide(ProvideClient),
+)
+
+// ErrNoClient is returned by the Redis backends of the framework when the application does
+// not include redis.Module, whose client they share
+var ErrNoClient = errors.New("the redis backend needs the client of redis.Module")
+
+// ClientParams holds the dependencies of the client
+type ClientParams struct {
+	fx.In
+
+	Config    *config.Config
+	Logger    *observability.Logger
+	Lifecycle fx.Lifecycle
+	Health    *health.Health         `optional:"true"`
+	Metrics   *observability.Metrics `optional:"true"`
+	Tracer    *observability.Tracer  `optional:"true"`
+}
+
+// ProvideClient creates the client of the redis configuration, traced and measured by the
+// observability module
+func ProvideClient(p ClientParams) (goredis.UniversalClient, error) {
+	client, err := NewClient(p.Config.Redis)
+	if err != nil {
+		return nil, err
+	}
+	instrument(client, p.Tracer, p.Metrics)
+
+	if p.Health != nil {
+		p.Health.RegisterCheck("redis", func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			return client.Ping(ctx).Err()
+		})
+	}
+	p.Lifecycle.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+
+	mode := p.Config.Redis.Mode
+	if mode == "" {
+		mode = ModeStandalone
+	}
+	p.Logger.Info("Redis client created", zap.String("mode", mode))
+	return client, nil
+}
+
+// instrument traces the commands of client with tracer and records their metrics, along with
+// the statistics of its connection pool, in metrics. Both are optional.
+func instrument(client goredis.UniversalClient, tracer *observability.Tracer, metrics *observability.Metrics) {
+	client.AddHook(newHook(tracer, metrics))
+	if metrics != nil && metrics.RedisCommandsTotal != nil {
+		metrics.Registry.MustRegister(newPoolCollector(client))
+	}
+}
@@ -0,0 +1,71 @@
+// Package redis provides the Redis client of the application, connected in the standalone,
+// cluster or sentinel mode of the redis configuration and instrumented with traces, metrics
+// and a health check.
+package redis
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Modes of connecting to Redis
+const (
+	ModeStandalone = "standalone"
+	ModeCluster    = "cluster"
+	ModeSentinel   = "sentinel"
+)
+
+// NewClient creates a client of the Redis deployment of cfg. Clients connect lazily, so
+// creating one does not check that Redis is reachable.
+func NewClient(cfg config.RedisConfig) (goredis.UniversalClient, error) {
+	opts := &goredis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      time.Duration(cfg.DialTimeout) * time.Millisecond,
+		ReadTimeout:      time.Duration(cfg.ReadTimeout) * time.Millisecond,
+		WriteTimeout:     time.Duration(cfg.WriteTimeout) * time.Millisecond,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	switch strings.ToLower(cfg.Mode) {
+	case "", ModeStandalone:
+		addr := cfg.Addr
+		if addr == "" && len(cfg.Addrs) > 0 {
+			addr = cfg.Addrs[0]
+		}
+		opts.Addrs = []string{addr}
+		return goredis.NewClient(opts.Simple()), nil
+	case ModeCluster:
+		if len(opts.Addrs) == 0 && cfg.Addr != "" {
+			opts.Addrs = []string{cfg.Addr}
+		}
+		if len(opts.Addrs) == 0 {
+			return nil, fmt.Errorf("redis: the cluster mode needs the addresses of its nodes")
+		}
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("redis: the cluster mode does not support selecting a database")
+		}
+		return goredis.NewClusterClient(opts.Cluster()), nil
+	case ModeSentinel:
+		if cfg.MasterName == "" || len(opts.Addrs) == 0 {
+			return nil, fmt.Errorf("redis: the sentinel mode needs the master name and the addresses of the sentinels")
+		}
+		return goredis.NewFailoverClient(opts.Failover()), nil
+	default:
+		return nil, fmt.Errorf("redis: unknown mode %q", cfg.Mode)
+	}
+}
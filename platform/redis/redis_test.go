@@ -0,0 +1,227 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx/fxtest"
+)
+
+// fakeRedis serves PING, GET, SET and DEL on a local port; GET finds no key and the other
+// commands fail
+func fakeRedis(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveRedis(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveRedis(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		var n int
+		if _, err := fmt.Sscanf(line, "*%d", &n); err != nil {
+			return
+		}
+		args := make([]string, n)
+		for i := range args {
+			if _, err := r.ReadString('\n'); err != nil {
+				return
+			}
+			arg, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			args[i] = strings.TrimSuffix(arg, "\r\n")
+		}
+
+		reply := "-ERR unknown command\r\n"
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			reply = "+PONG\r\n"
+		case "GET":
+			reply = "$-1\r\n"
+		case "SET":
+			reply = "+OK\r\n"
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RedisConfig
+		check   func(t *testing.T, client goredis.UniversalClient)
+		wantErr string
+	}{
+		{
+			name: "standalone by default",
+			cfg:  config.RedisConfig{Addr: "redis:6379", DB: 2, PoolSize: 20, DialTimeout: 100, TLS: true},
+			check: func(t *testing.T, client goredis.UniversalClient) {
+				require.IsType(t, &goredis.Client{}, client)
+				opts := client.(*goredis.Client).Options()
+				assert.Equal(t, "redis:6379", opts.Addr)
+				assert.Equal(t, 2, opts.DB)
+				assert.Equal(t, 20, opts.PoolSize)
+				assert.Equal(t, 100*time.Millisecond, opts.DialTimeout)
+				assert.NotNil(t, opts.TLSConfig)
+			},
+		},
+		{
+			name: "cluster",
+			cfg:  config.RedisConfig{Mode: "Cluster", Addrs: []string{"a:6379", "b:6379"}, Password: "secret"},
+			check: func(t *testing.T, client goredis.UniversalClient) {
+				require.IsType(t, &goredis.ClusterClient{}, client)
+				opts := client.(*goredis.ClusterClient).Options()
+				assert.Equal(t, []string{"a:6379", "b:6379"}, opts.Addrs)
+				assert.Equal(t, "secret", opts.Password)
+				assert.Nil(t, opts.TLSConfig)
+			},
+		},
+		{
+			name:    "cluster without nodes",
+			cfg:     config.RedisConfig{Mode: "cluster"},
+			wantErr: "redis: the cluster mode needs the addresses of its nodes",
+		},
+		{
+			name:    "cluster with a database",
+			cfg:     config.RedisConfig{Mode: "cluster", Addrs: []string{"a:6379"}, DB: 1},
+			wantErr: "redis: the cluster mode does not support selecting a database",
+		},
+		{
+			name: "sentinel",
+			cfg:  config.RedisConfig{Mode: "sentinel", Addrs: []string{"s1:26379", "s2:26379"}, MasterName: "main"},
+			check: func(t *testing.T, client goredis.UniversalClient) {
+				assert.IsType(t, &goredis.Client{}, client)
+			},
+		},
+		{
+			name:    "sentinel without master",
+			cfg:     config.RedisConfig{Mode: "sentinel", Addrs: []string{"s1:26379"}},
+			wantErr: "redis: the sentinel mode needs the master name and the addresses of the sentinels",
+		},
+		{
+			name:    "unknown mode",
+			cfg:     config.RedisConfig{Mode: "ring"},
+			wantErr: `redis: unknown mode "ring"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.cfg)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			defer client.Close()
+			tt.check(t, client)
+		})
+	}
+}
+
+func TestProvideClient(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		Observability: config.ObservabilityConfig{MetricsEnabled: true},
+		Redis:         config.RedisConfig{Addr: fakeRedis(t)},
+	}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := &observability.Tracer{Tracer: trace.NewTracerProvider(trace.WithSyncer(exporter)).Tracer("test")}
+	h := health.New(logger)
+	lc := fxtest.NewLifecycle(t)
+
+	client, err := ProvideClient(ClientParams{Config: cfg, Logger: logger, Lifecycle: lc, Health: h, Metrics: metrics, Tracer: tracer})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Set(ctx, "greeting", "hello", 0).Err())
+	assert.ErrorIs(t, client.Get(ctx, "greeting").Err(), goredis.Nil)
+	assert.Error(t, client.Del(ctx, "greeting").Err())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.RedisCommandsTotal.WithLabelValues("set", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.RedisCommandsTotal.WithLabelValues("get", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.RedisCommandsTotal.WithLabelValues("del", "error")))
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.RedisCommandDuration))
+
+	count, err := testutil.GatherAndCount(metrics.Registry, "redis_pool_connections", "redis_pool_hits_total")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	var spans []string
+	for _, span := range exporter.GetSpans() {
+		spans = append(spans, span.Name)
+	}
+	assert.Contains(t, spans, "redis.dial")
+	assert.Contains(t, spans, "redis.set")
+	assert.Contains(t, spans, "redis.get")
+
+	_, err = client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, "a", "1", 0)
+		pipe.Get(ctx, "a")
+		return nil
+	})
+	assert.ErrorIs(t, err, goredis.Nil)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.RedisCommandsTotal.WithLabelValues("pipeline", "success")))
+
+	h.RunChecks()
+	response := h.GetResponse()
+	require.Contains(t, response.Components, "redis")
+	assert.Equal(t, health.StatusUp, response.Components["redis"].Status)
+
+	lc.RequireStart().RequireStop()
+	assert.ErrorIs(t, client.Ping(ctx).Err(), goredis.ErrClosed)
+}
+
+func TestProvideClientWithoutObservability(t *testing.T) {
+	cfg := &config.Config{Redis: config.RedisConfig{Addr: fakeRedis(t)}}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+
+	client, err := ProvideClient(ClientParams{Config: cfg, Logger: logger, Lifecycle: fxtest.NewLifecycle(t), Metrics: metrics})
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.NoError(t, client.Ping(context.Background()).Err())
+	count, err := testutil.GatherAndCount(metrics.Registry)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
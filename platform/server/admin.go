@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/profiling"
+	"github.com/axiomod/axiomod/framework/version"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/gofiber/adaptor/v2"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// defaultAdminPort is the port of the admin server when none is configured
+const defaultAdminPort = 9092
+
+// AdminServer serves the operational endpoints on a dedicated port, away from the public API:
+// the probes, metrics, pprof when enabled, the redacted configuration, the build information
+// and the /admin endpoints. They are protected by the guards of http.endpoints.
+type AdminServer struct {
+	App    *fiber.App // nil when the admin server is disabled
+	Config *config.Config
+	Logger *observability.Logger
+
+	listener net.Listener
+}
+
+// BuildInfo describes the build of the running binary
+type BuildInfo struct {
+	version.Info
+	Module      string `json:"module,omitempty"`
+	VCSRevision string `json:"vcs_revision,omitempty"`
+	VCSTime     string `json:"vcs_time,omitempty"`
+	VCSModified bool   `json:"vcs_modified,omitempty"`
+}
+
+// NewAdminServer creates the admin server; it serves nothing unless http.adminServer.enabled is set
+func NewAdminServer(cfg *config.Config, obsLogger *observability.Logger, metrics *observability.Metrics, errorHandler *middleware.ErrorHandler, endpointGuards *middleware.EndpointGuards, h *health.Health) *AdminServer {
+	server := &AdminServer{Config: cfg, Logger: obsLogger}
+	if !cfg.HTTP.AdminServer.Enabled {
+		return server
+	}
+
+	app := fiber.New(fiber.Config{
+		ReadTimeout:           time.Duration(cfg.HTTP.ReadTimeout) * time.Second,
+		WriteTimeout:          time.Duration(cfg.HTTP.WriteTimeout) * time.Second,
+		AppName:               cfg.App.Name,
+		ErrorHandler:          errorHandler.Handler(),
+		DisableStartupMessage: true,
+	})
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace:  true,
+		StackTraceHandler: middleware.ReportPanic,
+	}))
+
+	// Probes, with the same access rules as /live and /ready of the HTTP server
+	app.Get("/healthz", adaptor.HTTPHandlerFunc(h.LiveHandler()))
+	app.Get("/readyz", readyHandler(h, endpointGuards.HealthDetails))
+
+	mountOperationalEndpoints(app, metrics, endpointGuards)
+
+	// Profiles of the running process, when enabled or scraped by Parca
+	if profiling.ServesPprof(cfg) {
+		app.Use("/debug/pprof", endpointGuards.Admin.Handle())
+		app.Use(pprof.New())
+	}
+
+	app.Get("/admin/config", endpointGuards.Admin.Handle(), func(c *fiber.Ctx) error {
+		return c.JSON(cfg.Redacted())
+	})
+	app.Get("/admin/build-info", endpointGuards.Admin.Handle(), func(c *fiber.Ctx) error {
+		return c.JSON(ReadBuildInfo())
+	})
+
+	server.App = app
+	return server
+}
+
+// Enabled reports whether the admin server serves the operational endpoints
+func (s *AdminServer) Enabled() bool {
+	return s.App != nil
+}
+
+// Addr returns the address the server listens on once it has started, or nil before
+func (s *AdminServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// address returns the address to listen on, defaulting to the host of the HTTP server and
+// to the default admin port
+func (s *AdminServer) address() string {
+	cfg := s.Config.HTTP.AdminServer
+	host := cfg.Host
+	if host == "" {
+		host = s.Config.HTTP.Host
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = defaultAdminPort
+	}
+	return net.JoinHostPort(host, fmt.Sprint(port))
+}
+
+// ReadBuildInfo returns the version set at build time, with the module and version control
+// information embedded by the Go toolchain
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{Info: version.GetInfo()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = build.Main.Path
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.VCSRevision = setting.Value
+		case "vcs.time":
+			info.VCSTime = setting.Value
+		case "vcs.modified":
+			info.VCSModified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// RegisterAdminServer registers the admin server with the fx lifecycle when it is enabled
+func RegisterAdminServer(lc fx.Lifecycle, server *AdminServer) {
+	if !server.Enabled() {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Bind before returning so that a taken port fails the start and Addr is known
+			addr := server.address()
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s for the admin server: %w", addr, err)
+			}
+			server.listener = ln
+
+			go func() {
+				server.Logger.Info("Starting admin server", zap.String("address", ln.Addr().String()))
+				if err := server.App.Listener(ln); err != nil {
+					server.Logger.Error("Failed to start admin server", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			server.Logger.Info("Stopping admin server")
+			return server.App.ShutdownWithContext(ctx)
+		},
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Defaults of the API documentation
+const (
+	DefaultDocsPath     = "/docs"
+	DefaultDocsSpecFile = "docs/api/openapi.yaml"
+)
+
+// docsRoutes returns the paths of the Swagger UI page and of the OpenAPI document it loads,
+// and the file of the document
+func docsRoutes(cfg config.DocsConfig) (page, spec, specFile string) {
+	page = strings.TrimSuffix(cfg.Path, "/")
+	if page == "" {
+		page = DefaultDocsPath
+	}
+	specFile = cfg.SpecFile
+	if specFile == "" {
+		specFile = DefaultDocsSpecFile
+	}
+	spec = page + "/openapi.yaml"
+	if strings.EqualFold(filepath.Ext(specFile), ".json") {
+		spec = page + "/openapi.json"
+	}
+	return page, spec, specFile
+}
+
+// mountDocs serves Swagger UI and the OpenAPI document of the docs configuration. The document
+// is read on every request, so a regenerated one is served without a restart.
+func mountDocs(app *fiber.App, cfg config.DocsConfig) {
+	page, spec, specFile := docsRoutes(cfg)
+	contentType := "application/yaml"
+	if strings.HasSuffix(spec, ".json") {
+		contentType = fiber.MIMEApplicationJSON
+	}
+
+	app.Get(spec, func(c *fiber.Ctx) error {
+		content, err := os.ReadFile(specFile)
+		if os.IsNotExist(err) {
+			return fiber.NewError(fiber.StatusNotFound, "the OpenAPI document has not been generated")
+		}
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.Send(content)
+	})
+
+	script := fmt.Sprintf(swaggerUIScript, spec)
+	hash := sha256.Sum256([]byte(script))
+	// The page loads Swagger UI from its CDN, which the default Content-Security-Policy forbids
+	csp := fmt.Sprintf(swaggerUIPolicy, base64.StdEncoding.EncodeToString(hash[:]))
+	app.Get(page, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		c.Set(fiber.HeaderContentSecurityPolicy, csp)
+		return c.SendString(fmt.Sprintf(swaggerUIPage, script))
+	})
+}
+
+// swaggerUIPolicy is the Content-Security-Policy of the Swagger UI page, with the hash of its
+// inline script
+const swaggerUIPolicy = "default-src 'none'; script-src https://unpkg.com 'sha256-%s'; " +
+	"style-src https://unpkg.com 'unsafe-inline'; img-src 'self' data: https://unpkg.com; " +
+	"connect-src 'self'; frame-ancestors 'none'"
+
+// swaggerUIScript starts Swagger UI with the URL of the OpenAPI document
+const swaggerUIScript = `
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"});
+    };
+  `
+
+// swaggerUIPage loads Swagger UI from its CDN and runs the start script
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>API documentation</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>%s</script>
+</body>
+</html>
+`
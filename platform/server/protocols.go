@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofiber/adaptor/v2"
+	"github.com/gofiber/fiber/v2"
+)
+
+// clientPreface starts every HTTP/2 connection; cleartext connections starting with it are
+// served as h2c with prior knowledge
+var clientPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// defaultProtocolTimeout bounds the TLS handshake or the read of the first bytes that decide
+// the protocol of a connection when no read timeout is configured
+const defaultProtocolTimeout = 10 * time.Second
+
+// newHTTP2Server creates the server of HTTP/2 connections. fasthttp speaks HTTP/1.1 only, so
+// HTTP/2 requests are handed to the Fiber app through net/http; their responses are buffered.
+func newHTTP2Server(app *fiber.App, readTimeout, writeTimeout time.Duration, maxHeaderSize int) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{
+		Handler:        adaptor.FiberApp(app),
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
+		MaxHeaderBytes: maxHeaderSize,
+		Protocols:      protocols,
+	}
+}
+
+// splitListener accepts connections from ln and sorts them by protocol: HTTP/2 connections,
+// negotiated with ALPN or cleartext with prior knowledge when h2c is set, are returned by
+// http2 and all others by http1. Closing either listener closes ln.
+func splitListener(ln net.Listener, h2c bool, timeout time.Duration) (http1, http2 net.Listener) {
+	if timeout <= 0 {
+		timeout = defaultProtocolTimeout
+	}
+	shared := &sharedListener{Listener: ln, done: make(chan struct{})}
+	l1 := &connListener{shared: shared, conns: make(chan net.Conn)}
+	l2 := &connListener{shared: shared, conns: make(chan net.Conn)}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				shared.fail(err)
+				return
+			}
+			go func() {
+				conn, isHTTP2, err := detectProtocol(conn, h2c, timeout)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				target := l1
+				if isHTTP2 {
+					target = l2
+				}
+				select {
+				case target.conns <- conn:
+				case <-shared.done:
+					conn.Close()
+				}
+			}()
+		}
+	}()
+	return l1, l2
+}
+
+// detectProtocol completes the TLS handshake or peeks at the first bytes of conn to tell
+// whether it speaks HTTP/2. The returned connection replays the bytes peeked at.
+func detectProtocol(conn net.Conn, h2c bool, timeout time.Duration) (net.Conn, bool, error) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return conn, false, err
+		}
+		return conn, tlsConn.ConnectionState().NegotiatedProtocol == "h2", nil
+	}
+	if !h2c {
+		return conn, false, nil
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return conn, false, err
+	}
+	reader := bufio.NewReaderSize(conn, len(clientPreface))
+	peeked := &peekedConn{Conn: conn, reader: reader}
+	// Read only as far as the bytes match the preface, so short HTTP/1.1 requests do not block
+	for n := 1; n <= len(clientPreface); n++ {
+		b, err := reader.Peek(n)
+		if err != nil {
+			return conn, false, err
+		}
+		if !bytes.HasPrefix(clientPreface, b) {
+			return peeked, false, conn.SetReadDeadline(time.Time{})
+		}
+	}
+	return peeked, true, conn.SetReadDeadline(time.Time{})
+}
+
+// peekedConn is a connection whose first bytes were read into reader
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// sharedListener is the listener behind the listeners returned by splitListener
+type sharedListener struct {
+	net.Listener
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+
+	mu  sync.Mutex
+	err error // error of the last Accept
+}
+
+func (s *sharedListener) fail(err error) {
+	select {
+	case <-s.done:
+		return // closed by a server
+	default:
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	s.Close()
+}
+
+func (s *sharedListener) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.closeErr = s.Listener.Close()
+	})
+	return s.closeErr
+}
+
+func (s *sharedListener) acceptErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return net.ErrClosed
+}
+
+// connListener returns the connections of one protocol from a sharedListener
+type connListener struct {
+	shared *sharedListener
+	conns  chan net.Conn
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.shared.done:
+		return nil, l.shared.acceptErr()
+	}
+}
+
+func (l *connListener) Close() error {
+	return l.shared.Close()
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.shared.Addr()
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/framework/metering"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/router"
+	"github.com/axiomod/axiomod/platform/observability"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHTTPServer starts an HTTP server on a random loopback port and stops it when the test ends
+func startHTTPServer(t *testing.T, httpCfg config.HTTPConfig) *HTTPServer {
+	httpCfg.Host = "127.0.0.1"
+	httpCfg.ReadTimeout = 5
+	httpCfg.WriteTimeout = 5
+	cfg := &config.Config{App: config.AppConfig{Name: "test-app"}, HTTP: httpCfg}
+
+	logger, err := observability.NewLogger(cfg)
+	require.NoError(t, err)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	guards, err := middleware.NewEndpointGuards(cfg, logger)
+	require.NoError(t, err)
+	security, err := middleware.NewSecurityMiddleware(cfg, logger)
+	require.NoError(t, err)
+	rateLimit, err := middleware.NewRateLimitMiddleware(cfg, logger, nil)
+	require.NoError(t, err)
+	srv := NewHTTPServer(cfg, logger, metrics, middleware.NewMetricsMiddleware(metrics),
+		middleware.NewTracingMiddleware(&observability.Tracer{Tracer: trace.NewNoopTracerProvider().Tracer("test")}),
+		middleware.NewAuthMiddleware(cfg, auth.NewJWTService("test-secret", time.Hour), logger),
+		middleware.NewBodyLimitMiddleware(cfg, logger), rateLimit,
+		middleware.NewConcurrencyLimitMiddleware(cfg, logger), middleware.NewMeteringMiddleware(cfg, metering.NewRecorder()), security, nil, nil, nil, nil,
+		middleware.NewErrorHandler(cfg, logger), guards, health.New(logger))
+
+	lc := fxtest.NewLifecycle(t)
+	RegisterHTTPServer(HTTPServerParams{Lifecycle: lc, Server: srv, Streams: router.NewEventStreams(cfg, logger)})
+	require.NoError(t, lc.Start(context.Background()))
+	t.Cleanup(func() { require.NoError(t, lc.Stop(context.Background())) })
+	require.NotNil(t, srv.Addr())
+	return srv
+}
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 and returns the TLS settings
+func writeTestCert(t *testing.T) config.TLSConfig {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return config.TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile}
+}
+
+func cleartextHTTP2Client() *http.Client {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 5 * time.Second}
+}
+
+func tlsClient() *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}, Timeout: 5 * time.Second}
+}
+
+func TestHTTPServerProtocols(t *testing.T) {
+	plain := &http.Client{Timeout: 5 * time.Second}
+	tests := []struct {
+		name   string
+		cfg    config.HTTPConfig
+		client *http.Client
+		scheme string
+		proto  string
+	}{
+		{"HTTP/1.1", config.HTTPConfig{}, plain, "http", "HTTP/1.1"},
+		{"h2c", config.HTTPConfig{H2C: true}, cleartextHTTP2Client(), "http", "HTTP/2.0"},
+		{"HTTP/1.1 with h2c", config.HTTPConfig{H2C: true}, plain, "http", "HTTP/1.1"},
+		{"TLS", config.HTTPConfig{TLS: writeTestCert(t)}, tlsClient(), "https", "HTTP/1.1"},
+		{"HTTP/2 over TLS", config.HTTPConfig{TLS: writeTestCert(t), HTTP2: true}, tlsClient(), "https", "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startHTTPServer(t, tt.cfg)
+			defer tt.client.CloseIdleConnections()
+
+			for _, path := range []string{"/live", "/health"} {
+				resp, err := tt.client.Get(tt.scheme + "://" + srv.Addr().String() + path)
+				require.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+				assert.Equal(t, tt.proto, resp.Proto, path)
+			}
+		})
+	}
+}
+
+func TestHTTPServerMaxHeaderSize(t *testing.T) {
+	header := strings.Repeat("a", 8192)
+	tests := []struct {
+		name     string
+		cfg      config.HTTPConfig
+		client   *http.Client
+		accepted bool
+	}{
+		{"Default Rejects Large Headers", config.HTTPConfig{}, &http.Client{}, false},
+		{"Configured Size", config.HTTPConfig{MaxHeaderSize: 16384}, &http.Client{}, true},
+		{"h2c Default Rejects Large Headers", config.HTTPConfig{H2C: true}, cleartextHTTP2Client(), false},
+		{"h2c Configured Size", config.HTTPConfig{H2C: true, MaxHeaderSize: 16384}, cleartextHTTP2Client(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startHTTPServer(t, tt.cfg)
+			defer tt.client.CloseIdleConnections()
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+srv.Addr().String()+"/live", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Large", header)
+			resp, err := tt.client.Do(req)
+			if !tt.accepted {
+				// HTTP/1.1 answers 431 unless the connection is closed while the request is
+				// still being written; HTTP/2 closes the connection
+				if err == nil {
+					resp.Body.Close()
+					assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+				}
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/axiomod/axiomod/framework/config"
+	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
+	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// HTTPRoutesGroup is the fx value group collecting the HTTP routes of modules
+const HTTPRoutesGroup = "http_routes"
+
+// GRPCServicesGroup is the fx value group collecting the gRPC services of modules
+const GRPCServicesGroup = "grpc_services"
+
+// AdminEndpointsGroup is the fx value group collecting the admin endpoints of modules
+const AdminEndpointsGroup = "admin_endpoints"
+
+// RouteRegistrar is implemented by handlers that register their routes on a router
+type RouteRegistrar interface {
+	RegisterRoutes(router fiber.Router)
+}
+
+// HTTPRoutes registers routes on the HTTP server, under Prefix when it is set
+type HTTPRoutes struct {
+	Prefix   string
+	Register func(router fiber.Router)
+}
+
+// GRPCService is a gRPC service implementation registered on the gRPC server
+type GRPCService struct {
+	Desc *grpc.ServiceDesc
+	Impl interface{}
+}
+
+// AdminEndpoint is a GET endpoint of the admin endpoints, such as /admin/plugins, protected by
+// endpoints.admin and served on the admin server when it is enabled
+type AdminEndpoint struct {
+	Path    string
+	Handler fiber.Handler
+}
+
+// AsHTTPRoutes registers the routes of the handler of type T under prefix:
+//
+//	server.AsHTTPRoutes[*http.UserHandler]("/api/v1")
+func AsHTTPRoutes[T RouteRegistrar](prefix string) fx.Option {
+	return fx.Provide(fx.Annotate(func(handler T) HTTPRoutes {
+		return HTTPRoutes{Prefix: prefix, Register: handler.RegisterRoutes}
+	}, fx.ResultTags(`group:"`+HTTPRoutesGroup+`"`)))
+}
+
+// AsGRPCService registers the implementation of type T as the gRPC service described by desc:
+//
+//	server.AsGRPCService[*grpc.UserService](&pb.UserService_ServiceDesc)
+func AsGRPCService[T any](desc *grpc.ServiceDesc) fx.Option {
+	return fx.Provide(fx.Annotate(func(impl T) GRPCService {
+		return GRPCService{Desc: desc, Impl: impl}
+	}, fx.ResultTags(`group:"`+GRPCServicesGroup+`"`)))
+}
+
+// AsAdminEndpoint registers the admin endpoint returned by constructor, so that modules serve
+// their state without the server depending on them:
+//
+//	server.AsAdminEndpoint(plugins.AdminEndpoint)
+func AsAdminEndpoint(constructor interface{}) fx.Option {
+	return fx.Provide(fx.Annotate(constructor, fx.ResultTags(`group:"`+AdminEndpointsGroup+`"`)))
+}
+
+// RoutesParams holds the routes and services contributed by modules
+type RoutesParams struct {
+	fx.In
+
+	Server   *HTTPServer
+	GRPC     *grpc_pkg.Server `optional:"true"`
+	Routes   []HTTPRoutes     `group:"http_routes"`
+	Services []GRPCService    `group:"grpc_services"`
+}
+
+// RegisterRoutes registers the routes and services contributed to the value groups, so that
+// modules wire themselves without invoking the servers
+func RegisterRoutes(params RoutesParams) error {
+	if len(params.Services) > 0 && params.GRPC == nil {
+		return fmt.Errorf("%d gRPC services are registered but no gRPC server is provided", len(params.Services))
+	}
+
+	// grpc.Server panics on invalid services, so check them before registering any
+	names := make(map[string]bool, len(params.Services))
+	for _, service := range params.Services {
+		if err := checkService(service); err != nil {
+			return err
+		}
+		if names[service.Desc.ServiceName] {
+			return fmt.Errorf("gRPC service %s is registered twice", service.Desc.ServiceName)
+		}
+		names[service.Desc.ServiceName] = true
+	}
+
+	for _, routes := range params.Routes {
+		if routes.Register == nil {
+			continue
+		}
+		if routes.Prefix == "" || routes.Prefix == "/" {
+			routes.Register(params.Server.App)
+		} else {
+			routes.Register(params.Server.App.Group(routes.Prefix))
+		}
+		params.Server.Logger.Debug("Registered HTTP routes", zap.String("prefix", routes.Prefix))
+	}
+	for _, service := range params.Services {
+		params.GRPC.RegisterService(service.Desc, service.Impl)
+	}
+	return nil
+}
+
+// AdminEndpointsParams holds the admin endpoints contributed by modules
+type AdminEndpointsParams struct {
+	fx.In
+
+	Server    *HTTPServer
+	Admin     *AdminServer
+	Guards    *middleware.EndpointGuards
+	Auth      *middleware.AuthMiddleware `optional:"true"`
+	Endpoints []AdminEndpoint            `group:"admin_endpoints"`
+}
+
+// RegisterAdminEndpoints adds the admin endpoints contributed to the value group, on the admin
+// server when it is enabled. Authentication lets them through, as endpoints.admin guards them.
+func RegisterAdminEndpoints(params AdminEndpointsParams) {
+	router := params.Server.App
+	if params.Admin.Enabled() {
+		router = params.Admin.App
+	}
+	for _, endpoint := range params.Endpoints {
+		if params.Auth != nil {
+			params.Auth.AllowAnonymous(fiber.MethodGet, endpoint.Path)
+		}
+		router.Get(endpoint.Path, params.Guards.Admin.Handle(), endpoint.Handler)
+		params.Server.Logger.Debug("Registered admin endpoint", zap.String("path", endpoint.Path))
+	}
+}
+
+// StorageRoutesParams holds the store whose presigned URLs the HTTP server serves
+type StorageRoutesParams struct {
+	fx.In
+
+	Config *config.Config
+	Server *HTTPServer
+	Auth   *middleware.AuthMiddleware `optional:"true"`
+	Store  storage.Store              `optional:"true"`
+}
+
+// RegisterStorageRoutes serves the presigned URLs of a local store under the path of
+// storage.local.baseURL. The signature of the URLs stands in for authentication.
+func RegisterStorageRoutes(params StorageRoutesParams) error {
+	local, ok := params.Store.(*storage.LocalStore)
+	baseURL := params.Config.Storage.Local.BaseURL
+	if !ok || baseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid storage.local.baseURL %q: %w", baseURL, err)
+	}
+	prefix := strings.TrimSuffix(u.Path, "/")
+	if prefix == "" {
+		return fmt.Errorf("storage.local.baseURL %q needs a path for the storage routes, e.g. /files", baseURL)
+	}
+
+	if params.Auth != nil {
+		params.Auth.AllowAnonymous("", prefix+"/*")
+	}
+	local.RegisterRoutes(params.Server.App.Group(prefix))
+	params.Server.Logger.Debug("Registered storage routes", zap.String("prefix", prefix))
+	return nil
+}
+
+// checkService checks that the implementation of a gRPC service implements its handler type
+func checkService(service GRPCService) error {
+	if service.Desc == nil {
+		return fmt.Errorf("gRPC service %T has no service description", service.Impl)
+	}
+	if service.Impl == nil {
+		return fmt.Errorf("gRPC service %s has no implementation", service.Desc.ServiceName)
+	}
+	if service.Desc.HandlerType == nil {
+		return nil
+	}
+	handlerType := reflect.TypeOf(service.Desc.HandlerType).Elem()
+	if implType := reflect.TypeOf(service.Impl); !implType.Implements(handlerType) {
+		return fmt.Errorf("gRPC service %s: %v does not implement %v", service.Desc.ServiceName, implType, handlerType)
+	}
+	return nil
+}
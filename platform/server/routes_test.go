@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
+	"github.com/axiomod/axiomod/framework/storage"
+	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pingHandler struct{}
+
+func (h *pingHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendString("pong")
+	})
+}
+
+type greeterServer interface {
+	Greet() string
+}
+
+type greeter struct{}
+
+func (g *greeter) Greet() string { return "hello" }
+
+var greeterDesc = &grpc.ServiceDesc{
+	ServiceName: "test.Greeter",
+	HandlerType: (*greeterServer)(nil),
+}
+
+func newRoutesServer(t *testing.T) (*HTTPServer, *grpc_pkg.Server) {
+	cfg := &config.Config{App: config.AppConfig{Name: "test-app"}}
+	logger, err := observability.NewLogger(cfg)
+	require.NoError(t, err)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	tracer := &observability.Tracer{Tracer: trace.NewNoopTracerProvider().Tracer("test")}
+
+	grpcServer, err := grpc_pkg.NewServer(logger, &grpc_pkg.ServerOptions{Host: "127.0.0.1"},
+		grpc_pkg.NewMetricsInterceptor(metrics), grpc_pkg.NewTracingInterceptor(tracer),
+		grpc_pkg.NewErrorInterceptor(logger), nil)
+	require.NoError(t, err)
+	return &HTTPServer{App: fiber.New(), Config: cfg, Logger: logger}, grpcServer
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	srv, grpcServer := newRoutesServer(t)
+
+	app := fx.New(
+		fx.Supply(srv, grpcServer),
+		fx.Provide(func() *pingHandler { return &pingHandler{} }),
+		fx.Provide(func() *greeter { return &greeter{} }),
+		AsHTTPRoutes[*pingHandler]("/api/v1"),
+		AsHTTPRoutes[*pingHandler](""),
+		AsGRPCService[*greeter](greeterDesc),
+		fx.Invoke(RegisterRoutes),
+		fx.NopLogger,
+	)
+	require.NoError(t, app.Err())
+
+	for _, path := range []string{"/api/v1/ping", "/ping"} {
+		resp, err := srv.App.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, 200, resp.StatusCode, path)
+		assert.Equal(t, "pong", string(body), path)
+	}
+
+	assert.Contains(t, grpcServer.GetServer().GetServiceInfo(), "test.Greeter")
+}
+
+func TestRegisterRoutesErrors(t *testing.T) {
+	srv, grpcServer := newRoutesServer(t)
+
+	tests := []struct {
+		name     string
+		grpc     *grpc_pkg.Server
+		services []GRPCService
+		err      string
+	}{
+		{
+			name:     "no gRPC server",
+			services: []GRPCService{{Desc: greeterDesc, Impl: &greeter{}}},
+			err:      "no gRPC server is provided",
+		},
+		{
+			name:     "no service description",
+			grpc:     grpcServer,
+			services: []GRPCService{{Impl: &greeter{}}},
+			err:      "has no service description",
+		},
+		{
+			name:     "wrong implementation",
+			grpc:     grpcServer,
+			services: []GRPCService{{Desc: greeterDesc, Impl: &pingHandler{}}},
+			err:      "does not implement",
+		},
+		{
+			name:     "registered twice",
+			grpc:     grpcServer,
+			services: []GRPCService{{Desc: greeterDesc, Impl: &greeter{}}, {Desc: greeterDesc, Impl: &greeter{}}},
+			err:      "registered twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterRoutes(RoutesParams{Server: srv, GRPC: tt.grpc, Services: tt.services})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+	assert.NotContains(t, grpcServer.GetServer().GetServiceInfo(), "test.Greeter")
+}
+
+func TestRegisterStorageRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		store   storage.Store
+		status  int
+		err     string
+	}{
+		{
+			name:    "local store",
+			baseURL: "http://localhost:8080/files",
+			store:   storage.NewLocalStore(t.TempDir()).WithPresign("http://localhost:8080/files", "secret"),
+			status:  200,
+		},
+		{
+			name:   "no base URL",
+			store:  storage.NewLocalStore(t.TempDir()),
+			status: 404,
+		},
+		{
+			name:    "base URL without path",
+			baseURL: "http://localhost:8080",
+			store:   storage.NewLocalStore(t.TempDir()).WithPresign("http://localhost:8080", "secret"),
+			err:     "needs a path for the storage routes",
+		},
+		{
+			name:    "no store",
+			baseURL: "http://localhost:8080/files",
+			status:  404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newRoutesServer(t)
+			srv.Config.Storage.Local.BaseURL = tt.baseURL
+
+			err := RegisterStorageRoutes(StorageRoutesParams{Config: srv.Config, Server: srv, Store: tt.store})
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			path := "/files/a.txt"
+			if tt.store != nil {
+				require.NoError(t, tt.store.Put(context.Background(), "a.txt", strings.NewReader("a"), storage.PutOptions{}))
+				if signed, err := tt.store.PresignGet(context.Background(), "a.txt", time.Minute); err == nil {
+					u, _ := url.Parse(signed)
+					path = u.RequestURI()
+				}
+			}
+			resp, err := srv.App.Test(httptest.NewRequest("GET", path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
@@ -2,22 +2,31 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/framework/circuitbreaker"
 	"github.com/axiomod/axiomod/framework/config"
+	axerrors "github.com/axiomod/axiomod/framework/errors"
 	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
 	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/framework/i18n"
 	"github.com/axiomod/axiomod/framework/middleware"
+	"github.com/axiomod/axiomod/framework/router"
+	"github.com/axiomod/axiomod/framework/tlscert"
 	"github.com/axiomod/axiomod/platform/observability"
 	"github.com/gofiber/adaptor/v2"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
-	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger" // Import Fiber logger
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -25,6 +34,7 @@ import (
 // Module provides the fx options for the server module
 var Module = fx.Options(
 	fx.Provide(NewHTTPServer),
+	fx.Provide(NewAdminServer),
 )
 
 // HTTPServer represents the HTTP server
@@ -32,62 +42,295 @@ type HTTPServer struct {
 	App    *fiber.App
 	Config *config.Config
 	Logger *observability.Logger
+
+	metrics      *observability.Metrics
+	redis        redis.UniversalClient
+	listener     net.Listener
+	certificates *tlscert.Reloader
+	challenges   *http.Server
+	http2        *http.Server
 }
 
+// defaultMaxHeaderSize is the size of the request line and headers accepted when none is configured
+const defaultMaxHeaderSize = 4096
+
 // NewHTTPServer creates a new HTTP server
-func NewHTTPServer(cfg *config.Config, obsLogger *observability.Logger, metrics *observability.Metrics, metricsMid *middleware.MetricsMiddleware, tracingMid *middleware.TracingMiddleware, h *health.Health) *HTTPServer {
+func NewHTTPServer(cfg *config.Config, obsLogger *observability.Logger, metrics *observability.Metrics, metricsMid *middleware.MetricsMiddleware, tracingMid *middleware.TracingMiddleware, authMid *middleware.AuthMiddleware, bodyLimitMid *middleware.BodyLimitMiddleware, rateLimitMid *middleware.RateLimitMiddleware, concurrencyLimitMid *middleware.ConcurrencyLimitMiddleware, meteringMid *middleware.MeteringMiddleware, securityMid *middleware.SecurityMiddleware, payloadLogMid *middleware.PayloadLogMiddleware, debugTraceMid *middleware.DebugTraceMiddleware, faultMid *middleware.FaultInjectionMiddleware, catalog *i18n.Catalog, errorHandler *middleware.ErrorHandler, endpointGuards *middleware.EndpointGuards, h *health.Health) *HTTPServer {
 	// Create a new Fiber app
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  time.Duration(cfg.HTTP.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.HTTP.WriteTimeout) * time.Second,
 		AppName:      cfg.App.Name,
+		ErrorHandler: errorHandler.Handler(),
+		// Fiber rejects larger bodies outright; per-route limits are applied by the body limit middleware
+		BodyLimit:         bodyLimitMid.MaxLimit(),
+		StreamRequestBody: cfg.HTTP.StreamRequestBody,
+		// Let upload handlers stream multipart bodies instead of having them parsed up front
+		DisablePreParseMultipartForm: cfg.HTTP.StreamRequestBody,
+		// fasthttp reads the request line and headers into the read buffer
+		ReadBufferSize: maxHeaderSize(cfg),
 	})
 
 	// Add middleware
-	app.Use(recover.New())
-	app.Use(cors.New())
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace:  true,
+		StackTraceHandler: middleware.ReportPanic,
+	}))
+	// Carry the request ID, tenant, locale and deadline of the caller in the request context
+	app.Use(middleware.RequestContext(cfg.Observability.TenantHeader))
+	// Negotiate the locale error responses are localized in if enabled
+	if cfg.I18n.Enabled {
+		app.Use(middleware.Localization(catalog))
+	}
+	// Add the security headers and the CORS policy, before authentication so preflights pass
+	app.Use(securityMid.Headers())
+	app.Use(securityMid.CORS())
 	app.Use(compress.New())
 	// Use Fiber's logger middleware
 	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
+		Format: "[${time}] ${status} - ${latency} ${method} ${path} ${respHeader:X-Request-ID}\n",
 	}))
+	// Log request and response payloads if enabled, inside compression so bodies are plain
+	if payloadLogMid.Enabled() {
+		app.Use(payloadLogMid.Handle())
+	}
 
 	// Add metrics middleware
 	app.Use(metricsMid.Handle())
 
+	// Sample the requests of allowed callers asking for debug tracing, and drop the debug trace
+	// baggage of other callers, before the server span starts
+	if debugTraceMid != nil {
+		app.Use(debugTraceMid.Handle())
+	}
+
 	// Add tracing middleware
 	app.Use(tracingMid.Handle())
 
-	// Add health check endpoint (liveness)
-	app.Get("/live", adaptor.HTTPHandlerFunc(h.Handler()))
+	// Flag responses built from fallback data
+	app.Use(middleware.Degradation())
 
-	// Add readiness probe
-	app.Get("/ready", adaptor.HTTPHandlerFunc(h.Handler()))
+	// Inject the faults of chaos experiments if enabled, sparing the probes and the endpoints
+	// ending them
+	if faultMid != nil && faultMid.Enabled() {
+		for _, path := range []string{"/live", "/ready", "/health", "/metrics", "/admin/*"} {
+			faultMid.Exempt(path)
+		}
+		app.Use(faultMid.Handle())
+	}
+
+	// Shed load beyond the adaptive concurrency limit if enabled, before any other work is done
+	if cfg.HTTP.ConcurrencyLimit.Enabled {
+		for _, path := range []string{"/live", "/ready", "/health", "/metrics"} {
+			concurrencyLimitMid.Exempt(path)
+		}
+		app.Use(concurrencyLimitMid.Handle())
+	}
+
+	// Add authentication if enabled; probes, metrics and admin endpoints have their own guards
+	if cfg.HTTP.Auth.Enabled {
+		for _, path := range []string{"/live", "/ready", "/health", "/metrics", "/admin/errors", "/admin/circuit-breakers"} {
+			authMid.AllowAnonymous(fiber.MethodGet, path)
+		}
+		authMid.AllowAnonymous("", "/admin/faults")
+		if cfg.HTTP.Docs.Enabled {
+			page, spec, _ := docsRoutes(cfg.HTTP.Docs)
+			authMid.AllowAnonymous(fiber.MethodGet, page)
+			authMid.AllowAnonymous(fiber.MethodGet, spec)
+		}
+		app.Use(authMid.Handle())
+	}
+
+	// Protect cookie-authenticated requests against cross-site request forgery if enabled
+	if securityMid.CSRFEnabled() {
+		app.Use(securityMid.CSRF())
+	}
+
+	// Evaluate feature flags for the authenticated user and their tenant
+	app.Use(middleware.FeatureFlagContext(cfg.Observability.TenantHeader))
+
+	// Add per-route body and response size limits
+	app.Use(bodyLimitMid.Handle())
+
+	// Add rate limiting middleware if enabled
+	if cfg.HTTP.RateLimit.Enabled {
+		app.Use(rateLimitMid.Handle())
+	}
+
+	// Add resource usage metering if enabled
+	if cfg.Observability.MeteringEnabled {
+		app.Use(meteringMid.Handle())
+	}
+
+	// Add health check endpoint (liveness); it runs no checks and is always public
+	app.Get("/live", adaptor.HTTPHandlerFunc(h.LiveHandler()))
+
+	// Add readiness probe; component details require access to the health details endpoint
+	app.Get("/ready", readyHandler(h, endpointGuards.HealthDetails))
 
 	// Add legacy health check for backward compatibility
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(map[string]string{"status": "ok"})
 	})
 
-	// Add metrics endpoint
-	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler))
+	// Add metrics and the admin endpoints, unless the admin server serves them on its own port
+	if !cfg.HTTP.AdminServer.Enabled {
+		mountOperationalEndpoints(app, metrics, endpointGuards)
+	}
+
+	// Add Swagger UI and the OpenAPI document if enabled
+	if cfg.HTTP.Docs.Enabled {
+		mountDocs(app, cfg.HTTP.Docs)
+	}
 
 	return &HTTPServer{
 		App:    app,
 		Config: cfg,
 		Logger: obsLogger, // Use the observability logger for internal logging
+
+		metrics: metrics,
+	}
+}
+
+// Addr returns the address the server listens on once it has started, or nil before
+func (s *HTTPServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// maxHeaderSize returns the size of the request line and headers accepted by the server
+func maxHeaderSize(cfg *config.Config) int {
+	if cfg.HTTP.MaxHeaderSize > 0 {
+		return cfg.HTTP.MaxHeaderSize
+	}
+	return defaultMaxHeaderSize
+}
+
+// readyHandler serves the readiness probe; component details require access to guard
+func readyHandler(h *health.Health, guard *middleware.EndpointGuard) fiber.Handler {
+	ready := adaptor.HTTPHandlerFunc(h.Handler())
+	readyStatus := adaptor.HTTPHandlerFunc(h.StatusHandler())
+	return func(c *fiber.Ctx) error {
+		if !guard.Enabled() {
+			return ready(c)
+		}
+		err := guard.Check(c)
+		switch {
+		case err == nil:
+			return ready(c)
+		case errors.Is(err, middleware.ErrEndpointCredentialsMissing), errors.Is(err, middleware.ErrEndpointIPNotAllowed):
+			return readyStatus(c)
+		default:
+			return guard.Reject(c, err)
+		}
+	}
+}
+
+// mountOperationalEndpoints adds the metrics endpoint, the catalog of registered error codes
+// and the states of the circuit breakers
+func mountOperationalEndpoints(router fiber.Router, metrics *observability.Metrics, endpointGuards *middleware.EndpointGuards) {
+	router.Get("/metrics", endpointGuards.Metrics.Handle(), adaptor.HTTPHandler(metrics.Handler))
+	router.Get("/admin/errors", endpointGuards.Admin.Handle(), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"codes": axerrors.DefaultRegistry.Definitions()})
+	})
+	router.Get("/admin/circuit-breakers", endpointGuards.Admin.Handle(), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"breakers": circuitbreaker.DefaultRegistry.Snapshots()})
+	})
+}
+
+// RegisterChaosAdmin adds the faults of chaos experiments to the admin endpoints, on the admin
+// server when it is enabled. PUT replaces the faults injected and DELETE ends the experiment;
+// both fail with 409 Conflict unless chaos.enabled is set. The endpoints skip http.auth, so
+// that an experiment can be ended while the auth provider fails, which leaves endpoints.admin
+// as their only guard: with chaos.enabled, it must restrict them.
+func RegisterChaosAdmin(server *HTTPServer, admin *AdminServer, endpointGuards *middleware.EndpointGuards, injector *chaos.Injector) error {
+	if injector.Enabled() && !endpointGuards.Admin.Enabled() {
+		return errors.New("chaos.enabled requires http.endpoints.admin to restrict the /admin/faults endpoints with credentials or allowed addresses")
 	}
+	router := server.App
+	if admin.Enabled() {
+		router = admin.App
+	}
+	faults := func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"enabled": injector.Enabled(), "faults": injector.Faults()})
+	}
+	router.Get("/admin/faults", endpointGuards.Admin.Handle(), faults)
+	router.Put("/admin/faults", endpointGuards.Admin.Handle(), func(c *fiber.Ctx) error {
+		var body struct {
+			Faults []chaos.Fault `json:"faults"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return axerrors.NewInvalidInput(err, "invalid faults")
+		}
+		if err := injector.SetFaults(body.Faults); err != nil {
+			return err
+		}
+		return faults(c)
+	})
+	router.Delete("/admin/faults", endpointGuards.Admin.Handle(), func(c *fiber.Ctx) error {
+		if err := injector.SetFaults(nil); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return nil
+}
+
+// HTTPServerParams holds the dependencies of the HTTP server lifecycle. Redis, the client of
+// redis.Module, stores the ACME certificates of http.tls kept in Redis.
+type HTTPServerParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Server    *HTTPServer
+	Streams   *router.EventStreams
+	Redis     redis.UniversalClient `optional:"true"`
 }
 
 // RegisterHTTPServer registers the HTTP server with the fx lifecycle
-func RegisterHTTPServer(lc fx.Lifecycle, server *HTTPServer) {
-	lc.Append(fx.Hook{
+func RegisterHTTPServer(params HTTPServerParams) {
+	server, streams := params.Server, params.Streams
+	server.redis = params.Redis
+	params.Lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			// Start the server in a goroutine
+			// Bind before returning so that a taken port fails the start and Addr is known
+			addr := fmt.Sprintf("%s:%d", server.Config.HTTP.Host, server.Config.HTTP.Port)
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+
+			httpCfg := server.Config.HTTP
+			if httpCfg.TLS.Enabled {
+				if ln, err = server.startTLS(ctx, ln); err != nil {
+					return err
+				}
+			} else if httpCfg.HTTP2 {
+				server.Logger.Warn("HTTP/2 needs http.tls; set http.h2c to serve cleartext HTTP/2")
+			}
+			server.listener = ln
+
+			// Hand HTTP/2 connections to a net/http server, the others to Fiber
+			http1 := ln
+			if httpCfg.HTTP2 || httpCfg.H2C {
+				var http2 net.Listener
+				http1, http2 = splitListener(ln, httpCfg.H2C, time.Duration(httpCfg.ReadTimeout)*time.Second)
+				server.http2 = newHTTP2Server(server.App, time.Duration(httpCfg.ReadTimeout)*time.Second,
+					time.Duration(httpCfg.WriteTimeout)*time.Second, maxHeaderSize(server.Config))
+				go func() {
+					if err := server.http2.Serve(http2); err != nil && err != http.ErrServerClosed {
+						server.Logger.Error("Failed to serve HTTP/2", zap.Error(err))
+					}
+				}()
+			}
+
+			// Serve in a goroutine
 			go func() {
-				addr := fmt.Sprintf("%s:%d", server.Config.HTTP.Host, server.Config.HTTP.Port)
-				server.Logger.Info("Starting HTTP server", zap.String("address", addr))
-				if err := server.App.Listen(addr); err != nil && err != http.ErrServerClosed {
+				server.Logger.Info("Starting HTTP server", zap.String("address", ln.Addr().String()),
+					zap.Bool("http2", httpCfg.HTTP2 && httpCfg.TLS.Enabled), zap.Bool("h2c", httpCfg.H2C && !httpCfg.TLS.Enabled))
+				if err := server.App.Listener(http1); err != nil && err != http.ErrServerClosed {
 					server.Logger.Error("Failed to start HTTP server", zap.Error(err))
 				}
 			}()
@@ -95,25 +338,117 @@ func RegisterHTTPServer(lc fx.Lifecycle, server *HTTPServer) {
 		},
 		OnStop: func(ctx context.Context) error {
 			server.Logger.Info("Stopping HTTP server")
-			return server.App.Shutdown()
+			// Open event streams would keep the server from shutting down
+			if err := streams.Shutdown(ctx); err != nil {
+				server.Logger.Warn("Event streams did not close cleanly", zap.Error(err))
+			}
+			var err error
+			if server.http2 != nil {
+				err = server.http2.Shutdown(ctx)
+			}
+			if shutdownErr := server.App.ShutdownWithContext(ctx); shutdownErr != nil && err == nil {
+				err = shutdownErr
+			}
+			if server.challenges != nil {
+				if closeErr := server.challenges.Shutdown(ctx); closeErr != nil {
+					server.Logger.Warn("ACME challenge server did not close cleanly", zap.Error(closeErr))
+				}
+			}
+			if server.certificates != nil {
+				server.certificates.Stop()
+			}
+			return err
+		},
+	})
+}
+
+// startTLS loads the TLS certificates and wraps the listener to serve TLS. Handshakes pick
+// up reloaded certificates while open connections are not affected. ln is closed on failure.
+func (s *HTTPServer) startTLS(ctx context.Context, ln net.Listener) (net.Listener, error) {
+	tlsCfg := s.Config.HTTP.TLS
+	certificates, err := tlscert.NewReloaderFromConfig("http", tlsCfg, s.redis, s.Logger)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	// Answer HTTP-01 challenges before the first certificate is requested
+	if acme, ok := certificates.Source().(*tlscert.ACMESource); ok && tlsCfg.ACME.HTTPChallengeAddr != "" {
+		challengeLn, err := net.Listen("tcp", tlsCfg.ACME.HTTPChallengeAddr)
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to listen for ACME challenges on %s: %w", tlsCfg.ACME.HTTPChallengeAddr, err)
+		}
+		s.challenges = &http.Server{Handler: acme.HTTPHandler(nil), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			s.Logger.Info("Serving ACME HTTP-01 challenges", zap.String("address", challengeLn.Addr().String()))
+			if err := s.challenges.Serve(challengeLn); err != nil && err != http.ErrServerClosed {
+				s.Logger.Error("ACME challenge server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	if err := certificates.WithMetrics(s.metrics).Start(ctx); err != nil {
+		ln.Close()
+		if s.challenges != nil {
+			s.challenges.Close()
+		}
+		return nil, fmt.Errorf("failed to load HTTP TLS certificate: %w", err)
+	}
+	s.certificates = certificates
+
+	tlsConfig := certificates.TLSConfig()
+	if s.Config.HTTP.HTTP2 {
+		tlsConfig.NextProtos = append([]string{"h2", "http/1.1"}, tlsConfig.NextProtos...)
+	}
+	return tls.NewListener(ln, tlsConfig), nil
+}
+
+// RegisterGateway mounts the gRPC gateway on the HTTP server when it is enabled, so annotated
+// gRPC services are served as REST behind the same middleware and error handling
+func RegisterGateway(lc fx.Lifecycle, server *HTTPServer, gateway *grpc_pkg.Gateway) {
+	if !gateway.Enabled() {
+		return
+	}
+	handler := gateway.Handler()
+	server.App.All(gateway.Prefix(), handler)
+	server.App.All(gateway.Prefix()+"/*", handler)
+	server.Logger.Info("Mounted gRPC gateway", zap.String("prefix", gateway.Prefix()))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return gateway.Close()
 		},
 	})
 }
 
 // RegisterGRPCServer registers the gRPC server with the fx lifecycle
-func RegisterGRPCServer(lc fx.Lifecycle, server *grpc_pkg.Server) {
+func RegisterGRPCServer(lc fx.Lifecycle, server *grpc_pkg.Server, obsLogger *observability.Logger) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			// Bind before returning so that a taken port fails the start and Addr is known
+			if err := server.Listen(); err != nil {
+				return err
+			}
+			// Load the certificates before serving, so a bad certificate fails the start
+			if certificates := server.Certificates(); certificates != nil {
+				if err := certificates.Start(ctx); err != nil {
+					return fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+				}
+			}
 			go func() {
-				if err := server.Start(); err != nil && err != http.ErrServerClosed {
-					// logger is internal to server
+				if err := server.Start(); err != nil {
+					obsLogger.Error("Failed to start gRPC server", zap.Error(err))
 				}
 			}()
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			server.Stop()
-			return nil
+			err := server.Shutdown(ctx)
+			if certificates := server.Certificates(); certificates != nil {
+				certificates.Stop()
+			}
+			return err
 		},
 	})
 }
@@ -1,18 +1,37 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/axiomod/axiomod/framework/auth"
+	"github.com/axiomod/axiomod/framework/chaos"
+	"github.com/axiomod/axiomod/framework/circuitbreaker"
 	"github.com/axiomod/axiomod/framework/config"
+	grpc_pkg "github.com/axiomod/axiomod/framework/grpc"
 	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/framework/i18n"
+	"github.com/axiomod/axiomod/framework/metering"
 	"github.com/axiomod/axiomod/framework/middleware"
 	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/gofiber/fiber/v2"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -35,9 +54,19 @@ func TestHTTPServer(t *testing.T) {
 	tracingMid := middleware.NewTracingMiddleware(&observability.Tracer{
 		Tracer: trace.NewNoopTracerProvider().Tracer("test"),
 	})
+	authMid := middleware.NewAuthMiddleware(cfg, auth.NewJWTService("test-secret", time.Hour), logger)
+	bodyLimitMid := middleware.NewBodyLimitMiddleware(cfg, logger)
+	rateLimitMid, err := middleware.NewRateLimitMiddleware(cfg, logger, nil)
+	require.NoError(t, err)
+	concurrencyLimitMid := middleware.NewConcurrencyLimitMiddleware(cfg, logger)
+	meteringMid := middleware.NewMeteringMiddleware(cfg, metering.NewRecorder())
+	securityMid, _ := middleware.NewSecurityMiddleware(cfg, logger)
+	catalog, _ := i18n.NewCatalog("")
+	errorHandler := middleware.NewErrorHandler(cfg, logger)
+	endpointGuards, _ := middleware.NewEndpointGuards(cfg, logger)
 	h := health.New(logger)
 
-	srv := NewHTTPServer(cfg, logger, metrics, metricsMid, tracingMid, h)
+	srv := NewHTTPServer(cfg, logger, metrics, metricsMid, tracingMid, authMid, bodyLimitMid, rateLimitMid, concurrencyLimitMid, meteringMid, securityMid, nil, nil, nil, catalog, errorHandler, endpointGuards, h)
 
 	t.Run("Health Endpoints", func(t *testing.T) {
 		// Run server in background for testing probes
@@ -76,4 +105,245 @@ func TestHTTPServer(t *testing.T) {
 			})
 		}
 	})
+	t.Run("Protected Operational Endpoints", func(t *testing.T) {
+		protectedCfg := *cfg
+		protectedCfg.HTTP.Endpoints = config.EndpointsConfig{
+			Metrics:       config.EndpointAuthConfig{Auth: "bearer", Token: "metrics-token"},
+			HealthDetails: config.EndpointAuthConfig{Auth: "bearer", Token: "health-token"},
+			Admin:         config.EndpointAuthConfig{Auth: "bearer", Token: "admin-token"},
+		}
+		guards, err := middleware.NewEndpointGuards(&protectedCfg, logger)
+		assert.NoError(t, err)
+		h := health.New(logger)
+		h.RegisterCheck("db", func() error { return nil })
+		protected := NewHTTPServer(&protectedCfg, logger, metrics, metricsMid, tracingMid, authMid, bodyLimitMid, rateLimitMid, concurrencyLimitMid, meteringMid, securityMid, nil, nil, nil, catalog, errorHandler, guards, h)
+
+		tests := []struct {
+			name       string
+			path       string
+			token      string
+			status     int
+			components bool
+		}{
+			{"Liveness stays public", "/live", "", http.StatusOK, false},
+			{"Readiness without credentials", "/ready", "", http.StatusOK, false},
+			{"Readiness with credentials", "/ready", "health-token", http.StatusOK, true},
+			{"Readiness with wrong credentials", "/ready", "guess", http.StatusUnauthorized, false},
+			{"Metrics without credentials", "/metrics", "", http.StatusUnauthorized, false},
+			{"Metrics with credentials", "/metrics", "metrics-token", http.StatusOK, false},
+			{"Error catalog without credentials", "/admin/errors", "", http.StatusUnauthorized, false},
+			{"Error catalog with credentials", "/admin/errors", "admin-token", http.StatusOK, false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				if tt.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				resp, err := protected.App.Test(req)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.status, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.components, strings.Contains(string(body), `"components"`))
+			})
+		}
+	})
+
+	t.Run("Error Code Catalog", func(t *testing.T) {
+		resp, err := srv.App.Test(httptest.NewRequest(http.MethodGet, "/admin/errors", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `{"code":"NOT_FOUND","kind":"NOT_FOUND",`)
+	})
+
+	t.Run("Circuit Breaker States", func(t *testing.T) {
+		cb := circuitbreaker.New(circuitbreaker.Options{Name: "server-test-payments", MaxFailures: 1, ResetTimeout: time.Minute})
+		cb.RecordResult(errors.New("payment service unavailable"))
+
+		resp, err := srv.App.Test(httptest.NewRequest(http.MethodGet, "/admin/circuit-breakers", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `{"name":"server-test-payments","state":"open","counts":{"successes":0,"failures":1,"rejections":0}}`)
+	})
+
+	t.Run("Admin Endpoints", func(t *testing.T) {
+		RegisterAdminEndpoints(AdminEndpointsParams{
+			Server: srv,
+			Admin:  NewAdminServer(cfg, logger, metrics, errorHandler, endpointGuards, h),
+			Guards: endpointGuards,
+			Endpoints: []AdminEndpoint{{Path: "/admin/widgets", Handler: func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"widgets": 2})
+			}}},
+		})
+
+		resp, err := srv.App.Test(httptest.NewRequest(http.MethodGet, "/admin/widgets", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, `{"widgets":2}`, string(body))
+	})
+
+	t.Run("Fault Injection", func(t *testing.T) {
+		chaosCfg := *cfg
+		chaosCfg.Chaos.Enabled = true
+		injector := chaos.NewInjector(true, logger)
+		faulty := NewHTTPServer(&chaosCfg, logger, metrics, metricsMid, tracingMid, authMid, bodyLimitMid, rateLimitMid, concurrencyLimitMid, meteringMid, securityMid, nil, nil, middleware.NewFaultInjectionMiddleware(injector, logger), catalog, errorHandler, endpointGuards, h)
+
+		// The fault endpoints skip http.auth, so they are refused without an admin guard
+		err := RegisterChaosAdmin(faulty, NewAdminServer(&chaosCfg, logger, metrics, errorHandler, endpointGuards, h), endpointGuards, injector)
+		assert.ErrorContains(t, err, "http.endpoints.admin")
+
+		chaosCfg.HTTP.Endpoints.Admin = config.EndpointAuthConfig{Auth: "bearer", Token: "admin-token"}
+		guards, err := middleware.NewEndpointGuards(&chaosCfg, logger)
+		require.NoError(t, err)
+		require.NoError(t, RegisterChaosAdmin(faulty, NewAdminServer(&chaosCfg, logger, metrics, errorHandler, guards, h), guards, injector))
+		faulty.App.Get("/api/orders", func(c *fiber.Ctx) error { return c.SendString("orders") })
+
+		send := func(method, path, body string) *http.Response {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer admin-token")
+			resp, err := faulty.App.Test(req)
+			require.NoError(t, err)
+			return resp
+		}
+
+		req := httptest.NewRequest(http.MethodPut, "/admin/faults", strings.NewReader(`{"faults":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := faulty.App.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "faults need the admin credentials")
+
+		resp = send(http.MethodPut, "/admin/faults", `{"faults":[{"name":"orders-down","path":"/api/*","errorRate":1,"errorStatus":500}]}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"enabled":true,"faults":[{"name":"orders-down","path":"/api/*","errorRate":1,"errorStatus":500}]}`, string(body))
+
+		assert.Equal(t, http.StatusInternalServerError, send(http.MethodGet, "/api/orders", "").StatusCode)
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/live", "").StatusCode, "probes are spared")
+
+		resp = send(http.MethodPut, "/admin/faults", `{"faults":[{"name":"broken","path":"/api/*","errorRate":2}]}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Len(t, injector.Faults(), 1, "invalid faults are not applied")
+
+		assert.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/admin/faults", "").StatusCode)
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/orders", "").StatusCode)
+
+		// Faults cannot be added unless chaos experiments are enabled
+		require.NoError(t, RegisterChaosAdmin(srv, NewAdminServer(cfg, logger, metrics, errorHandler, endpointGuards, h), endpointGuards, chaos.NewInjector(false, logger)))
+		req = httptest.NewRequest(http.MethodPut, "/admin/faults", strings.NewReader(`{"faults":[{"name":"orders-down","path":"/api/*","errorRate":1}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = srv.App.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Localized Errors", func(t *testing.T) {
+		localizedCfg := *cfg
+		localizedCfg.I18n.Enabled = true
+		localized := NewHTTPServer(&localizedCfg, logger, metrics, metricsMid, tracingMid, authMid, bodyLimitMid, rateLimitMid, concurrencyLimitMid, meteringMid, securityMid, nil, nil, nil, catalog, errorHandler, endpointGuards, h)
+
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		req.Header.Set("Accept-Language", "de-CH, en;q=0.5")
+		resp, err := localized.App.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "de", resp.Header.Get("Content-Language"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `"title":"Nicht gefunden"`)
+
+		// Without i18n, errors are not localized
+		resp, err = srv.App.Test(req)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get("Content-Language"))
+	})
+
+	t.Run("API Docs", func(t *testing.T) {
+		specFile := filepath.Join(t.TempDir(), "openapi.yaml")
+		docsCfg := *cfg
+		docsCfg.HTTP.Docs = config.DocsConfig{Enabled: true, SpecFile: specFile}
+		docsCfg.HTTP.Auth.Enabled = true
+		docs := NewHTTPServer(&docsCfg, logger, metrics, metricsMid, tracingMid, authMid, bodyLimitMid, rateLimitMid, concurrencyLimitMid, meteringMid, securityMid, nil, nil, nil, catalog, errorHandler, endpointGuards, h)
+
+		resp, err := docs.App.Test(httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		assert.NoError(t, os.WriteFile(specFile, []byte("openapi: 3.1.0\n"), 0644))
+		tests := []struct {
+			path        string
+			contentType string
+			body        string
+		}{
+			{"/docs", "text/html; charset=utf-8", `url: "/docs/openapi.yaml"`},
+			{"/docs/openapi.yaml", "application/yaml", "openapi: 3.1.0\n"},
+		}
+		for _, tt := range tests {
+			resp, err := docs.App.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, tt.path)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(resp.Body)
+			assert.Contains(t, string(body), tt.body)
+		}
+
+		// The page allows its CDN and its inline script only
+		resp, err = docs.App.Test(httptest.NewRequest(http.MethodGet, "/docs", nil))
+		assert.NoError(t, err)
+		assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "script-src https://unpkg.com 'sha256-")
+
+		resp, err = srv.App.Test(httptest.NewRequest(http.MethodGet, "/docs", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Unknown Route Returns Problem", func(t *testing.T) {
+		resp, err := srv.App.Test(httptest.NewRequest(http.MethodGet, "/does-not-exist", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, middleware.ProblemContentType, resp.Header.Get("Content-Type"))
+	})
+}
+
+func TestRegisterGRPCServer(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{Name: "test-app"}}
+	logger, err := observability.NewLogger(cfg)
+	require.NoError(t, err)
+	metrics, err := observability.NewMetrics(cfg, logger)
+	require.NoError(t, err)
+	tracer := &observability.Tracer{Tracer: trace.NewNoopTracerProvider().Tracer("test")}
+	newServer := func(port int) *grpc_pkg.Server {
+		server, err := grpc_pkg.NewServer(logger, &grpc_pkg.ServerOptions{Host: "127.0.0.1", Port: port, Timeout: time.Second},
+			grpc_pkg.NewMetricsInterceptor(metrics), grpc_pkg.NewTracingInterceptor(tracer),
+			grpc_pkg.NewErrorInterceptor(logger), nil)
+		require.NoError(t, err)
+		return server
+	}
+
+	grpcServer := newServer(0)
+	assert.Nil(t, grpcServer.Addr(), "the address is bound when the server starts")
+
+	lc := fxtest.NewLifecycle(t)
+	RegisterGRPCServer(lc, grpcServer, logger)
+	require.NoError(t, lc.Start(context.Background()))
+	require.NotNil(t, grpcServer.Addr())
+
+	conn, err := grpc.NewClient(grpcServer.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	// A taken port fails the start instead of leaving the server silently down
+	taken := newServer(grpcServer.Addr().(*net.TCPAddr).Port)
+	takenLc := fxtest.NewLifecycle(t)
+	RegisterGRPCServer(takenLc, taken, logger)
+	err = takenLc.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen")
+
+	require.NoError(t, lc.Stop(context.Background()))
 }
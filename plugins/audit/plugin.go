@@ -1,6 +1,8 @@
 package audit
 
 import (
+	"context"
+
 	"github.com/axiomod/axiomod/framework/config"
 	"github.com/axiomod/axiomod/framework/health"
 	"github.com/axiomod/axiomod/platform/observability"
@@ -19,13 +21,13 @@ func (p *Plugin) Initialize(settings map[string]interface{}, logger *observabili
 	return nil
 }
 
-func (p *Plugin) Start() error {
+func (p *Plugin) Start(ctx context.Context) error {
 	if p.logger != nil {
 		p.logger.Info("Audit Plugin (Stub) started")
 	}
 	return nil
 }
 
-func (p *Plugin) Stop() error {
+func (p *Plugin) Stop(ctx context.Context) error {
 	return nil
 }
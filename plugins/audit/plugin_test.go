@@ -1,6 +1,7 @@
 package audit
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,10 +18,10 @@ func TestPlugin_Lifecycle(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Test Start
-	err = p.Start()
+	err = p.Start(context.Background())
 	assert.NoError(t, err)
 
 	// Test Stop
-	err = p.Stop()
+	err = p.Stop(context.Background())
 	assert.NoError(t, err)
 }
@@ -40,7 +40,7 @@ func (p *MySQLPlugin) Initialize(settings map[string]interface{}, logger *observ
 }
 
 // Start starts the plugin
-func (p *MySQLPlugin) Start() error {
+func (p *MySQLPlugin) Start(ctx context.Context) error {
 	// Connect to the database using the simplified Connect method
 	db, err := database.Connect(p.cfg, p.logger, p.metrics, p.health)
 	if err != nil {
@@ -51,7 +51,7 @@ func (p *MySQLPlugin) Start() error {
 }
 
 // Stop stops the plugin
-func (p *MySQLPlugin) Stop() error {
+func (p *MySQLPlugin) Stop(ctx context.Context) error {
 	if p.db != nil {
 		return p.db.Close()
 	}
@@ -84,7 +84,7 @@ func (p *PostgreSQLPlugin) Initialize(settings map[string]interface{}, logger *o
 }
 
 // Start starts the plugin
-func (p *PostgreSQLPlugin) Start() error {
+func (p *PostgreSQLPlugin) Start(ctx context.Context) error {
 	// Connect to the database using the simplified Connect method
 	db, err := database.Connect(p.cfg, p.logger, p.metrics, p.health)
 	if err != nil {
@@ -95,7 +95,7 @@ func (p *PostgreSQLPlugin) Start() error {
 }
 
 // Stop stops the plugin
-func (p *PostgreSQLPlugin) Stop() error {
+func (p *PostgreSQLPlugin) Stop(ctx context.Context) error {
 	if p.db != nil {
 		return p.db.Close()
 	}
@@ -126,7 +126,7 @@ func (p *JWTPlugin) Initialize(settings map[string]interface{}, logger *observab
 }
 
 // Start starts the plugin
-func (p *JWTPlugin) Start() error {
+func (p *JWTPlugin) Start(ctx context.Context) error {
 	secret, _ := p.config["secret"].(string)
 	durationStr, _ := p.config["duration"].(string)
 	duration, _ := time.ParseDuration(durationStr)
@@ -140,7 +140,7 @@ func (p *JWTPlugin) Start() error {
 }
 
 // Stop stops the plugin
-func (p *JWTPlugin) Stop() error {
+func (p *JWTPlugin) Stop(ctx context.Context) error {
 	return nil
 }
 
@@ -168,7 +168,7 @@ func (p *KeycloakPlugin) Initialize(settings map[string]interface{}, logger *obs
 }
 
 // Start starts the plugin
-func (p *KeycloakPlugin) Start() error {
+func (p *KeycloakPlugin) Start(ctx context.Context) error {
 	issuer, _ := p.config["issuer"].(string)
 	clientID, _ := p.config["client_id"].(string)
 	clientSecret, _ := p.config["client_secret"].(string)
@@ -200,7 +200,7 @@ func (p *KeycloakPlugin) Start() error {
 }
 
 // Stop stops the plugin
-func (p *KeycloakPlugin) Stop() error {
+func (p *KeycloakPlugin) Stop(ctx context.Context) error {
 	return nil
 }
 
@@ -227,12 +227,12 @@ func (p *CasdoorPlugin) Initialize(settings map[string]interface{}, logger *obse
 }
 
 // Start starts the plugin
-func (p *CasdoorPlugin) Start() error {
+func (p *CasdoorPlugin) Start(ctx context.Context) error {
 	return nil
 }
 
 // Stop stops the plugin
-func (p *CasdoorPlugin) Stop() error {
+func (p *CasdoorPlugin) Stop(ctx context.Context) error {
 	return nil
 }
 
@@ -259,11 +259,11 @@ func (p *CasbinPlugin) Initialize(settings map[string]interface{}, logger *obser
 }
 
 // Start starts the plugin
-func (p *CasbinPlugin) Start() error {
+func (p *CasbinPlugin) Start(ctx context.Context) error {
 	return nil
 }
 
 // Stop stops the plugin
-func (p *CasbinPlugin) Stop() error {
+func (p *CasbinPlugin) Stop(ctx context.Context) error {
 	return nil
 }
@@ -1,3 +1,4 @@
+//axiomod:ignore naming/package reason="plugin list skips the example_plugin directory by name"
 package example_plugin
 
 // ExamplePlugin is an example plugin implementation
@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"context"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/observability"
+)
+
+// LegacyPlugin is the plugin interface before Start and Stop took a context. Wrap such
+// plugins with Adapt to register them.
+type LegacyPlugin interface {
+	Name() string
+	Initialize(config map[string]interface{}, logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config, health *health.Health) error
+	Start() error
+	Stop() error
+}
+
+// legacyPlugin adapts a LegacyPlugin to Plugin
+type legacyPlugin struct {
+	LegacyPlugin
+}
+
+// Adapt returns a Plugin for a plugin without context support. Its Start and Stop
+// cannot be cancelled; when they exceed their timeout the registry stops waiting for
+// them, but they keep running in the background.
+func Adapt(plugin LegacyPlugin) Plugin {
+	return &legacyPlugin{LegacyPlugin: plugin}
+}
+
+// Start starts the wrapped plugin, ignoring ctx
+func (p *legacyPlugin) Start(ctx context.Context) error {
+	return p.LegacyPlugin.Start()
+}
+
+// Stop stops the wrapped plugin, ignoring ctx
+func (p *legacyPlugin) Stop(ctx context.Context) error {
+	return p.LegacyPlugin.Stop()
+}
+
+// underlying returns the plugin a registered plugin wraps, so the optional interfaces
+// such as Dependent and HealthChecker are found on adapted plugins too
+func underlying(plugin Plugin) interface{} {
+	if adapted, ok := plugin.(*legacyPlugin); ok {
+		return adapted.LegacyPlugin
+	}
+	return plugin
+}
@@ -0,0 +1,133 @@
+package elk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bufferExt is the extension of spooled batches
+const bufferExt = ".ndjson"
+
+// diskBuffer spools batches that could not be shipped, one file per batch, so they
+// survive outages and restarts. It is used by the shipper's goroutine only.
+type diskBuffer struct {
+	dir      string
+	maxBytes int64
+	seq      int
+}
+
+// newDiskBuffer creates the buffer directory if needed
+func newDiskBuffer(dir string, maxBytes int64) (*diskBuffer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("elk: failed to create buffer directory: %w", err)
+	}
+	return &diskBuffer{dir: dir, maxBytes: maxBytes}, nil
+}
+
+// write spools a batch. It returns how many entries were dropped to stay within the
+// size limit, oldest first.
+func (b *diskBuffer) write(lines [][]byte) (dropped int, err error) {
+	b.seq++
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), b.seq, bufferExt)
+	tmp := filepath.Join(b.dir, "."+name)
+	if err := os.WriteFile(tmp, bytes.Join(lines, nil), 0o644); err != nil {
+		os.Remove(tmp)
+		return len(lines), err
+	}
+	if err := os.Rename(tmp, filepath.Join(b.dir, name)); err != nil {
+		os.Remove(tmp)
+		return len(lines), err
+	}
+	return b.trim()
+}
+
+// trim removes the oldest batches until the buffer fits its size limit
+func (b *diskBuffer) trim() (dropped int, err error) {
+	files, size, err := b.files()
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		if size <= b.maxBytes {
+			break
+		}
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return dropped, err
+		}
+		if err := os.Remove(file.path); err != nil {
+			return dropped, err
+		}
+		dropped += bytes.Count(data, []byte("\n"))
+		size -= file.size
+	}
+	return dropped, nil
+}
+
+// bufferFile is a spooled batch
+type bufferFile struct {
+	path string
+	size int64
+}
+
+// files returns the spooled batches, oldest first, and their total size
+func (b *diskBuffer) files() ([]bufferFile, int64, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	var files []bufferFile
+	var size int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, bufferExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, bufferFile{path: filepath.Join(b.dir, name), size: info.Size()})
+		size += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, size, nil
+}
+
+// oldest returns the oldest spooled batch, or an empty path if there is none
+func (b *diskBuffer) oldest() (path string, lines [][]byte, err error) {
+	files, _, err := b.files()
+	if err != nil || len(files) == 0 {
+		return "", nil, err
+	}
+	data, err := os.ReadFile(files[0].path)
+	if err != nil {
+		return "", nil, err
+	}
+	return files[0].path, splitLines(data), nil
+}
+
+// remove deletes a spooled batch once it is shipped
+func (b *diskBuffer) remove(path string) error {
+	return os.Remove(path)
+}
+
+// splitLines splits newline-terminated entries, keeping the newlines
+func splitLines(data []byte) [][]byte {
+	var lines [][]byte
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			lines = append(lines, append(data, '\n'))
+			break
+		}
+		lines = append(lines, data[:i+1])
+		data = data[i+1:]
+	}
+	return lines
+}
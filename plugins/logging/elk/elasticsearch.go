@@ -0,0 +1,186 @@
+package elk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sink ships batches of newline-terminated JSON entries
+type sink interface {
+	// send ships a batch. It returns the entries to retry and the number of entries
+	// rejected for good, or an error if the whole batch failed.
+	send(ctx context.Context, lines [][]byte) (retry [][]byte, rejected int, err error)
+	// destination describes where entries are shipped, for status reports
+	destination() string
+	// close releases connections
+	close() error
+}
+
+// elasticsearch ships entries with the bulk API to daily indexes
+type elasticsearch struct {
+	url      string
+	index    string
+	username string
+	password string
+	apiKey   string
+	client   *http.Client
+}
+
+// newElasticsearch creates an Elasticsearch sink
+func newElasticsearch(s settings) *elasticsearch {
+	return &elasticsearch{
+		url:      s.ElasticsearchURL,
+		index:    s.Index,
+		username: s.Username,
+		password: s.Password,
+		apiKey:   s.APIKey,
+		client:   &http.Client{Timeout: s.Timeout},
+	}
+}
+
+// destination returns the URL and index pattern
+func (e *elasticsearch) destination() string {
+	return e.url + "/" + e.index + "-*"
+}
+
+// close closes idle connections
+func (e *elasticsearch) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// timestampPrefix starts every entry, see encoderConfig
+var timestampPrefix = []byte(`{"@timestamp":"`)
+
+// indexFor returns the daily index of an entry, from its timestamp so that spooled
+// entries land in the index of the day they were logged
+func (e *elasticsearch) indexFor(line []byte) string {
+	day := time.Now().UTC().Format("2006.01.02")
+	if rest, ok := bytes.CutPrefix(line, timestampPrefix); ok && len(rest) >= 10 {
+		if t, err := time.Parse("2006-01-02", string(rest[:10])); err == nil {
+			day = t.Format("2006.01.02")
+		}
+	}
+	return e.index + "-" + day
+}
+
+// bulkResponse is the part of a bulk API response needed to find failed entries
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+	} `json:"items"`
+}
+
+// send ships a batch with the bulk API. Entries rejected with 429 or 5xx are retried;
+// others, such as mapping conflicts, are not.
+func (e *elasticsearch) send(ctx context.Context, lines [][]byte) ([][]byte, int, error) {
+	var body bytes.Buffer
+	for _, line := range lines {
+		fmt.Fprintf(&body, `{"create":{"_index":%q}}`+"\n", e.indexFor(line))
+		body.Write(line)
+	}
+
+	resp, err := e.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("elk: invalid bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, 0, nil
+	}
+
+	var retry [][]byte
+	rejected := 0
+	for i, item := range result.Items {
+		if i >= len(lines) {
+			break
+		}
+		for _, action := range item {
+			switch {
+			case action.Status == http.StatusTooManyRequests || action.Status >= 500:
+				retry = append(retry, lines[i])
+			case action.Status >= 300:
+				rejected++
+			}
+		}
+	}
+	return retry, rejected, nil
+}
+
+// installTemplate installs an index template mapping the fields of entries for the
+// daily indexes
+func (e *elasticsearch) installTemplate(ctx context.Context) error {
+	template := map[string]interface{}{
+		"index_patterns": []string{e.index + "-*"},
+		"priority":       200,
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"dynamic_templates": []interface{}{
+					map[string]interface{}{
+						"strings_as_keywords": map[string]interface{}{
+							"match_mapping_type": "string",
+							"mapping":            map[string]interface{}{"type": "keyword", "ignore_above": 1024},
+						},
+					},
+				},
+				"properties": map[string]interface{}{
+					"@timestamp": map[string]interface{}{"type": "date"},
+					"level":      map[string]interface{}{"type": "keyword"},
+					"logger":     map[string]interface{}{"type": "keyword"},
+					"caller":     map[string]interface{}{"type": "keyword"},
+					"message":    map[string]interface{}{"type": "text"},
+					"stacktrace": map[string]interface{}{"type": "text", "index": false},
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.do(ctx, http.MethodPut, "/_index_template/"+e.index, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends an authenticated request and fails on error statuses
+func (e *elasticsearch) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	switch {
+	case e.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	case e.username != "":
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("elk: %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("elk: %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
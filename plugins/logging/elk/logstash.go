@@ -0,0 +1,61 @@
+package elk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// logstash ships entries to a tcp input with the json_lines codec
+type logstash struct {
+	address string
+	timeout time.Duration
+	conn    net.Conn
+}
+
+// newLogstash creates a Logstash sink. It connects on the first batch.
+func newLogstash(s settings) *logstash {
+	return &logstash{address: s.LogstashAddress, timeout: s.Timeout}
+}
+
+// destination returns the address of the tcp input
+func (l *logstash) destination() string {
+	return "tcp://" + l.address
+}
+
+// send writes a batch to the connection, reconnecting after errors. A batch that fails
+// part way is retried as a whole, so entries may be shipped twice.
+func (l *logstash) send(ctx context.Context, lines [][]byte) ([][]byte, int, error) {
+	if l.conn == nil {
+		dialer := net.Dialer{Timeout: l.timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", l.address)
+		if err != nil {
+			return nil, 0, fmt.Errorf("elk: failed to connect to logstash: %w", err)
+		}
+		l.conn = conn
+	}
+
+	deadline := time.Now().Add(l.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	l.conn.SetWriteDeadline(deadline)
+
+	if _, err := l.conn.Write(bytes.Join(lines, nil)); err != nil {
+		l.close()
+		return nil, 0, fmt.Errorf("elk: failed to write to logstash: %w", err)
+	}
+	return nil, 0, nil
+}
+
+// close closes the connection
+func (l *logstash) close() error {
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn = nil
+	return err
+}
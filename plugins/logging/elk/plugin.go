@@ -1,13 +1,26 @@
+// Package elk ships logs to Elasticsearch or Logstash. Entries are batched in the
+// background, optionally spooled to disk while the destination is unreachable, and
+// dropped rather than slowing the application down when the queue is full.
 package elk
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/axiomod/axiomod/framework/config"
 	"github.com/axiomod/axiomod/framework/health"
 	"github.com/axiomod/axiomod/platform/observability"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Plugin struct {
-	logger *observability.Logger
+	logger   *observability.Logger
+	settings settings
+	sink     sink
+	shipper  *shipper
+	remove   func() // removes the shipping core from the logger
 }
 
 func (p *Plugin) Name() string {
@@ -16,16 +29,106 @@ func (p *Plugin) Name() string {
 
 func (p *Plugin) Initialize(settings map[string]interface{}, logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config, health *health.Health) error {
 	p.logger = logger
+	parsed, err := parseSettings(settings)
+	if err != nil {
+		return err
+	}
+	p.settings = parsed
 	return nil
 }
 
-func (p *Plugin) Start() error {
-	if p.logger != nil {
-		p.logger.Info("ELK Plugin (Stub) started")
+// Start adds a core shipping the logger's entries to the configured destination
+func (p *Plugin) Start(ctx context.Context) error {
+	s := p.settings
+	if s.Output == "" {
+		if p.logger != nil {
+			p.logger.Warn("ELK plugin has no elasticsearchUrl or logstashAddress, logs are not shipped")
+		}
+		return nil
+	}
+
+	var buffer *diskBuffer
+	if s.BufferDir != "" {
+		var err error
+		if buffer, err = newDiskBuffer(s.BufferDir, int64(s.MaxBufferMB)<<20); err != nil {
+			return err
+		}
+	}
+
+	switch s.Output {
+	case OutputElasticsearch:
+		es := newElasticsearch(s)
+		if s.IndexTemplate {
+			// Entries are spooled until Elasticsearch is reachable, so this is not fatal
+			if err := es.installTemplate(ctx); err != nil {
+				p.logger.Warn("Failed to install Elasticsearch index template", zap.Error(err))
+			}
+		}
+		p.sink = es
+	case OutputLogstash:
+		p.sink = newLogstash(s)
+	}
+
+	p.shipper = newShipper(s, p.sink, buffer)
+	p.shipper.onFailure = func(err error) {
+		p.logger.Error("Failed to ship logs", zap.String("destination", p.sink.destination()), zap.Error(err))
+	}
+	p.shipper.onRecovery = func() {
+		p.logger.Info("Shipping logs again", zap.String("destination", p.sink.destination()))
+	}
+	go p.shipper.run()
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), p.shipper, s.Level)
+	remove, err := p.logger.AddCore(core)
+	if err != nil {
+		p.shipper.Stop(ctx)
+		return fmt.Errorf("elk: %w", err)
 	}
+	p.remove = remove
+
+	p.logger.Info("Shipping logs", zap.String("output", s.Output), zap.String("destination", p.sink.destination()))
 	return nil
 }
 
-func (p *Plugin) Stop() error {
-	return nil
+// Stop detaches the shipping core and ships the queued entries
+func (p *Plugin) Stop(ctx context.Context) error {
+	if p.shipper == nil {
+		return nil
+	}
+	if p.remove != nil {
+		p.remove()
+	}
+	err := p.shipper.Stop(ctx)
+	p.sink.close()
+	return err
+}
+
+// Health returns the error of the last shipment while shipping fails
+func (p *Plugin) Health() error {
+	if p.shipper == nil {
+		return nil
+	}
+	return p.shipper.err()
+}
+
+// Status reports where logs go and how many were shipped, dropped and buffered
+func (p *Plugin) Status() map[string]interface{} {
+	if p.shipper == nil {
+		return map[string]interface{}{"output": "none"}
+	}
+	status := map[string]interface{}{
+		"output":      p.settings.Output,
+		"destination": p.sink.destination(),
+		"shipped":     p.shipper.shipped.Load(),
+		"dropped":     p.shipper.dropped.Load(),
+		"rejected":    p.shipper.rejected.Load(),
+		"queued":      len(p.shipper.queue),
+	}
+	if p.shipper.buffer != nil {
+		if files, size, err := p.shipper.buffer.files(); err == nil {
+			status["bufferedBatches"] = len(files)
+			status["bufferedBytes"] = size
+		}
+	}
+	return status
 }
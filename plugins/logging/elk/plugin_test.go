@@ -1,9 +1,24 @@
 package elk
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/platform/observability"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestPlugin_Lifecycle(t *testing.T) {
@@ -17,10 +32,316 @@ func TestPlugin_Lifecycle(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Test Start
-	err = p.Start()
+	err = p.Start(context.Background())
 	assert.NoError(t, err)
 
 	// Test Stop
-	err = p.Stop()
+	err = p.Stop(context.Background())
 	assert.NoError(t, err)
 }
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		check    func(t *testing.T, s settings)
+		err      string
+	}{
+		{
+			name:     "defaults without a destination",
+			settings: map[string]interface{}{},
+			check: func(t *testing.T, s settings) {
+				assert.Equal(t, "", s.Output)
+				assert.Equal(t, defaultSettings(), s)
+			},
+		},
+		{
+			name: "elasticsearch with lowercased keys",
+			settings: map[string]interface{}{
+				"elasticsearchurl": "http://es:9200/", "index": "shop-logs", "batchsize": 100,
+				"flushinterval": "1s", "level": "warn", "indextemplate": false,
+			},
+			check: func(t *testing.T, s settings) {
+				assert.Equal(t, OutputElasticsearch, s.Output)
+				assert.Equal(t, "http://es:9200", s.ElasticsearchURL)
+				assert.Equal(t, 100, s.BatchSize)
+				assert.Equal(t, time.Second, s.FlushInterval)
+				assert.Equal(t, zapcore.WarnLevel, s.Level)
+				assert.False(t, s.IndexTemplate)
+			},
+		},
+		{
+			name:     "logstash wins when both are set",
+			settings: map[string]interface{}{"elasticsearchUrl": "http://es:9200", "logstashAddress": "ls:5000"},
+			check: func(t *testing.T, s settings) {
+				assert.Equal(t, OutputLogstash, s.Output)
+			},
+		},
+		{name: "explicit output needs its address", settings: map[string]interface{}{"output": "logstash", "elasticsearchUrl": "http://es:9200"}, err: "logstashAddress is required"},
+		{name: "unknown output", settings: map[string]interface{}{"output": "kafka"}, err: "unknown output"},
+		{name: "uppercase index", settings: map[string]interface{}{"elasticsearchUrl": "http://es:9200", "index": "Logs"}, err: "lowercase"},
+		{name: "batch size", settings: map[string]interface{}{"batchSize": 0}, err: "batchSize must be a positive number"},
+		{name: "flush interval", settings: map[string]interface{}{"flushInterval": "soon"}, err: "flushInterval must be a positive duration"},
+		{name: "backpressure", settings: map[string]interface{}{"backpressure": "wait"}, err: "unknown backpressure"},
+		{name: "level", settings: map[string]interface{}{"level": "loud"}, err: "invalid level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSettings(tt.settings)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, s)
+		})
+	}
+}
+
+// fakeElasticsearch records bulk requests and answers them with the given item statuses
+type fakeElasticsearch struct {
+	mu        sync.Mutex
+	status    int   // of the whole request; 200 if 0
+	items     []int // statuses of the first items; 201 for the rest
+	templates []string
+	actions   []string
+	docs      []map[string]interface{}
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodPut {
+		body, _ := io.ReadAll(r.Body)
+		f.templates = append(f.templates, r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"acknowledged":true}`))
+		return
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+
+	var items []map[string]interface{}
+	scanner := bufio.NewScanner(r.Body)
+	for i := 0; scanner.Scan(); i++ {
+		f.actions = append(f.actions, scanner.Text())
+		scanner.Scan()
+		status := http.StatusCreated
+		if len(items) < len(f.items) {
+			status = f.items[len(items)]
+		}
+		if status < 300 {
+			var doc map[string]interface{}
+			json.Unmarshal(scanner.Bytes(), &doc)
+			f.docs = append(f.docs, doc)
+		}
+		items = append(items, map[string]interface{}{"create": map[string]interface{}{"status": status}})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": len(f.items) > 0, "items": items})
+	f.items = nil
+}
+
+func (f *fakeElasticsearch) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var messages []string
+	for _, doc := range f.docs {
+		messages = append(messages, doc["message"].(string))
+	}
+	return messages
+}
+
+func newLogger(t *testing.T) *observability.Logger {
+	logger, err := observability.NewLogger(&config.Config{
+		App:           config.AppConfig{Name: "shop", Environment: "test"},
+		Observability: config.ObservabilityConfig{LogLevel: "fatal"},
+	})
+	require.NoError(t, err)
+	return logger
+}
+
+func TestPluginElasticsearch(t *testing.T) {
+	es := &fakeElasticsearch{}
+	server := httptest.NewServer(es)
+	defer server.Close()
+	logger := newLogger(t)
+
+	p := &Plugin{}
+	require.NoError(t, p.Initialize(map[string]interface{}{
+		"elasticsearchUrl": server.URL,
+		"index":            "shop-logs",
+		"flushInterval":    "1h",
+	}, logger, nil, nil, nil))
+	require.NoError(t, p.Start(context.Background()))
+
+	logger.Debug("below the shipped level")
+	logger.With(zap.String("order_id", "o-1")).Info("order created")
+	require.NoError(t, p.Stop(context.Background()))
+	logger.Info("after stop")
+
+	require.Len(t, es.templates, 1)
+	assert.Contains(t, es.templates[0], `/_index_template/shop-logs {"index_patterns":["shop-logs-*"]`)
+
+	require.Len(t, es.docs, 2, "queued entries are shipped on stop")
+	assert.Equal(t, `{"create":{"_index":"shop-logs-`+time.Now().UTC().Format("2006.01.02")+`"}}`, es.actions[1])
+	doc := es.docs[1]
+	assert.Equal(t, "order created", doc["message"])
+	assert.Equal(t, "info", doc["level"])
+	assert.Equal(t, "o-1", doc["order_id"])
+	assert.Equal(t, "shop", doc["service"])
+	assert.NotEmpty(t, doc["@timestamp"])
+
+	assert.Equal(t, int64(2), p.Status()["shipped"])
+}
+
+func TestPluginLogstash(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := make(chan map[string]interface{}, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var entry map[string]interface{}
+			json.Unmarshal(scanner.Bytes(), &entry)
+			received <- entry
+		}
+	}()
+	logger := newLogger(t)
+
+	p := &Plugin{}
+	require.NoError(t, p.Initialize(map[string]interface{}{
+		"logstashAddress": listener.Addr().String(),
+		"flushInterval":   "10ms",
+		"level":           "warn",
+	}, logger, nil, nil, nil))
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	logger.Info("below the shipped level")
+	logger.Warn("disk almost full", zap.Int("percent", 91))
+
+	select {
+	case entry := <-received:
+		assert.Equal(t, "disk almost full", entry["message"])
+		assert.Equal(t, "warn", entry["level"])
+		assert.Equal(t, float64(91), entry["percent"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no entry received")
+	}
+	assert.Equal(t, "tcp://"+listener.Addr().String(), p.Status()["destination"])
+}
+
+func TestShipperBuffersDuringOutage(t *testing.T) {
+	es := &fakeElasticsearch{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	s, err := parseSettings(map[string]interface{}{"elasticsearchUrl": server.URL, "bufferDir": t.TempDir(), "batchSize": 2})
+	require.NoError(t, err)
+	buffer, err := newDiskBuffer(s.BufferDir, 1<<20)
+	require.NoError(t, err)
+	sh := newShipper(s, newElasticsearch(s), buffer)
+	var failures []error
+	sh.onFailure = func(err error) { failures = append(failures, err) }
+
+	// While Elasticsearch is down, batches are spooled
+	sh.ship([][]byte{[]byte(`{"message":"a"}` + "\n"), []byte(`{"message":"b"}` + "\n")})
+	sh.ship([][]byte{[]byte(`{"message":"c"}` + "\n")})
+	assert.ErrorContains(t, sh.err(), "503")
+	assert.Len(t, failures, 1, "a series of failures is reported once")
+	files, _, err := buffer.files()
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	sh.replay()
+	assert.Empty(t, es.messages(), "nothing is replayed while shipping fails")
+
+	// Once it is back, new entries are shipped and the spooled ones replayed in order;
+	// entries rejected with 429 are spooled again, others are not retried
+	es.mu.Lock()
+	es.status = 0
+	es.items = []int{201, 429, 400}
+	es.mu.Unlock()
+	sh.ship([][]byte{[]byte(`{"message":"d"}` + "\n"), []byte(`{"message":"e"}` + "\n"), []byte(`{"message":"f"}` + "\n")})
+	assert.NoError(t, sh.err())
+	sh.replay()
+
+	assert.Equal(t, []string{"d", "a", "b", "c", "e"}, es.messages())
+	assert.Equal(t, int64(5), sh.shipped.Load())
+	assert.Equal(t, int64(1), sh.rejected.Load())
+	files, _, err = buffer.files()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDiskBufferLimit(t *testing.T) {
+	buffer, err := newDiskBuffer(t.TempDir(), 20)
+	require.NoError(t, err)
+
+	dropped, err := buffer.write([][]byte{[]byte("{\"n\":1}\n"), []byte("{\"n\":2}\n")})
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	dropped, err = buffer.write([][]byte{[]byte("{\"n\":3}\n")})
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropped, "the oldest batch is dropped")
+
+	_, lines, err := buffer.oldest()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("{\"n\":3}\n")}, lines)
+}
+
+// blockedSink blocks until released
+type blockedSink struct {
+	release chan struct{}
+}
+
+func (b *blockedSink) send(ctx context.Context, lines [][]byte) ([][]byte, int, error) {
+	select {
+	case <-b.release:
+		return nil, 0, nil
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+}
+func (b *blockedSink) destination() string { return "blocked" }
+func (b *blockedSink) close() error        { return nil }
+
+func TestShipperBackpressure(t *testing.T) {
+	for _, policy := range []string{BackpressureDrop, BackpressureBlock} {
+		t.Run(policy, func(t *testing.T) {
+			s, err := parseSettings(map[string]interface{}{
+				"queueSize": 2, "batchSize": 1, "backpressure": policy, "blockTimeout": "20ms",
+			})
+			require.NoError(t, err)
+			sink := &blockedSink{release: make(chan struct{})}
+			sh := newShipper(s, sink, nil)
+			go sh.run()
+
+			start := time.Now()
+			for i := 0; i < 10; i++ {
+				_, err := sh.Write([]byte("{}\n"))
+				require.NoError(t, err, "logging never fails")
+			}
+			elapsed := time.Since(start)
+			assert.GreaterOrEqual(t, sh.dropped.Load(), int64(7), "one entry is being sent and two are queued")
+			if policy == BackpressureBlock {
+				assert.GreaterOrEqual(t, elapsed, 7*20*time.Millisecond, "each dropped entry waited for room")
+			} else {
+				assert.Less(t, elapsed, 100*time.Millisecond)
+			}
+
+			// A stop that runs out of time cancels the shipment in progress
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			assert.ErrorIs(t, sh.Stop(ctx), context.DeadlineExceeded)
+			assert.ErrorIs(t, sh.err(), context.Canceled)
+		})
+	}
+}
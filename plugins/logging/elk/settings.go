@@ -0,0 +1,174 @@
+package elk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Outputs logs can be shipped to
+const (
+	OutputElasticsearch = "elasticsearch"
+	OutputLogstash      = "logstash"
+)
+
+// Backpressure policies for when the queue is full
+const (
+	// BackpressureDrop drops entries, so logging never waits
+	BackpressureDrop = "drop"
+	// BackpressureBlock makes logging wait up to blockTimeout for room in the queue
+	BackpressureBlock = "block"
+)
+
+// settings are the plugin settings, read from plugins.settings.elk
+type settings struct {
+	Output string // elasticsearch or logstash; inferred from the address settings if empty
+
+	ElasticsearchURL string
+	Index            string // daily indexes are named <index>-2006.01.02
+	IndexTemplate    bool   // install an index template for <index>-* on start
+	Username         string
+	Password         string
+	APIKey           string
+
+	LogstashAddress string // host:port of a tcp input with the json_lines codec
+
+	Level         zapcore.Level // minimum level shipped
+	BatchSize     int           // entries per request
+	FlushInterval time.Duration // longest time an entry waits in a batch
+	QueueSize     int           // entries waiting to be batched
+	Backpressure  string
+	BlockTimeout  time.Duration
+	Timeout       time.Duration // per request or connection attempt
+
+	BufferDir   string // spools batches that could not be shipped; none if empty
+	MaxBufferMB int    // oldest spooled batches are dropped beyond this size
+}
+
+// defaultSettings returns the settings used for keys that are not set
+func defaultSettings() settings {
+	return settings{
+		Index:         "axiomod-logs",
+		IndexTemplate: true,
+		Level:         zapcore.InfoLevel,
+		BatchSize:     500,
+		FlushInterval: 5 * time.Second,
+		QueueSize:     10000,
+		Backpressure:  BackpressureDrop,
+		BlockTimeout:  time.Second,
+		Timeout:       10 * time.Second,
+		MaxBufferMB:   100,
+	}
+}
+
+// parseSettings reads plugin settings. Keys are matched case-insensitively, since the
+// configuration loader lowercases them.
+func parseSettings(raw map[string]interface{}) (settings, error) {
+	s := defaultSettings()
+	values := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		values[strings.ToLower(key)] = value
+	}
+
+	var err error
+	str := func(key string, target *string) {
+		if v, ok := values[strings.ToLower(key)]; ok && v != nil && err == nil {
+			*target = strings.TrimSpace(fmt.Sprint(v))
+		}
+	}
+	num := func(key string, target *int) {
+		if v, ok := values[strings.ToLower(key)]; ok && v != nil && err == nil {
+			n, convErr := strconv.Atoi(fmt.Sprint(v))
+			if convErr != nil || n <= 0 {
+				err = fmt.Errorf("elk: %s must be a positive number, got %v", key, v)
+				return
+			}
+			*target = n
+		}
+	}
+	duration := func(key string, target *time.Duration) {
+		if v, ok := values[strings.ToLower(key)]; ok && v != nil && err == nil {
+			d, convErr := time.ParseDuration(fmt.Sprint(v))
+			if convErr != nil || d <= 0 {
+				err = fmt.Errorf("elk: %s must be a positive duration such as 5s, got %v", key, v)
+				return
+			}
+			*target = d
+		}
+	}
+	boolean := func(key string, target *bool) {
+		if v, ok := values[strings.ToLower(key)]; ok && v != nil && err == nil {
+			b, convErr := strconv.ParseBool(fmt.Sprint(v))
+			if convErr != nil {
+				err = fmt.Errorf("elk: %s must be true or false, got %v", key, v)
+				return
+			}
+			*target = b
+		}
+	}
+
+	var level string
+	str("output", &s.Output)
+	str("elasticsearchUrl", &s.ElasticsearchURL)
+	str("index", &s.Index)
+	boolean("indexTemplate", &s.IndexTemplate)
+	str("username", &s.Username)
+	str("password", &s.Password)
+	str("apiKey", &s.APIKey)
+	str("logstashAddress", &s.LogstashAddress)
+	str("level", &level)
+	num("batchSize", &s.BatchSize)
+	duration("flushInterval", &s.FlushInterval)
+	num("queueSize", &s.QueueSize)
+	str("backpressure", &s.Backpressure)
+	duration("blockTimeout", &s.BlockTimeout)
+	duration("timeout", &s.Timeout)
+	str("bufferDir", &s.BufferDir)
+	num("maxBufferMB", &s.MaxBufferMB)
+	if err != nil {
+		return s, err
+	}
+
+	if level != "" {
+		if err := s.Level.UnmarshalText([]byte(level)); err != nil {
+			return s, fmt.Errorf("elk: invalid level %q", level)
+		}
+	}
+	s.ElasticsearchURL = strings.TrimRight(s.ElasticsearchURL, "/")
+	s.Output = strings.ToLower(s.Output)
+	if s.Output == "" {
+		switch {
+		case s.LogstashAddress != "":
+			s.Output = OutputLogstash
+		case s.ElasticsearchURL != "":
+			s.Output = OutputElasticsearch
+		}
+	}
+
+	switch s.Output {
+	case "":
+	case OutputElasticsearch:
+		if s.ElasticsearchURL == "" {
+			return s, fmt.Errorf("elk: elasticsearchUrl is required for the elasticsearch output")
+		}
+		if s.Index == "" || strings.ToLower(s.Index) != s.Index {
+			return s, fmt.Errorf("elk: index must be a lowercase name, got %q", s.Index)
+		}
+	case OutputLogstash:
+		if s.LogstashAddress == "" {
+			return s, fmt.Errorf("elk: logstashAddress is required for the logstash output")
+		}
+	default:
+		return s, fmt.Errorf("elk: unknown output %q, use %s or %s", s.Output, OutputElasticsearch, OutputLogstash)
+	}
+
+	switch s.Backpressure {
+	case BackpressureDrop, BackpressureBlock:
+	default:
+		return s, fmt.Errorf("elk: unknown backpressure %q, use %s or %s", s.Backpressure, BackpressureDrop, BackpressureBlock)
+	}
+	return s, nil
+}
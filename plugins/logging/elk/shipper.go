@@ -0,0 +1,264 @@
+package elk
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxReplayBatches bounds how many spooled batches are shipped per flush interval, so
+// new entries are not held up for long after an outage
+const maxReplayBatches = 10
+
+// encoderConfig encodes entries for Elasticsearch. @timestamp comes first, which
+// elasticsearch.indexFor relies on.
+var encoderConfig = zapcore.EncoderConfig{
+	TimeKey:        "@timestamp",
+	LevelKey:       "level",
+	NameKey:        "logger",
+	CallerKey:      "caller",
+	MessageKey:     "message",
+	StacktraceKey:  "stacktrace",
+	LineEnding:     "\n",
+	EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+	EncodeLevel:    zapcore.LowercaseLevelEncoder,
+	EncodeDuration: zapcore.MillisDurationEncoder,
+	EncodeCaller:   zapcore.ShortCallerEncoder,
+}
+
+// shipper batches encoded entries and ships them in the background. It is the
+// WriteSyncer of the zap core the plugin adds to the logger.
+type shipper struct {
+	sink          sink
+	buffer        *diskBuffer // nil without a buffer directory
+	queue         chan []byte
+	block         bool
+	blockTimeout  time.Duration
+	batchSize     int
+	flushInterval time.Duration
+	onFailure     func(err error) // called when shipping starts failing
+	onRecovery    func()          // called when shipping works again
+
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	send    context.Context // cancelled when Stop's context is done
+	cancel  context.CancelFunc
+	stop    sync.Once
+
+	shipped  atomic.Int64
+	dropped  atomic.Int64
+	rejected atomic.Int64
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// newShipper creates a shipper; run starts it
+func newShipper(s settings, sink sink, buffer *diskBuffer) *shipper {
+	send, cancel := context.WithCancel(context.Background())
+	return &shipper{
+		sink:          sink,
+		buffer:        buffer,
+		queue:         make(chan []byte, s.QueueSize),
+		block:         s.Backpressure == BackpressureBlock,
+		blockTimeout:  s.BlockTimeout,
+		batchSize:     s.BatchSize,
+		flushInterval: s.FlushInterval,
+		onFailure:     func(error) {},
+		onRecovery:    func() {},
+		flush:         make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+		send:          send,
+		cancel:        cancel,
+	}
+}
+
+// Write queues an encoded entry. When the queue is full the entry is dropped, or with
+// the block policy dropped after waiting blockTimeout for room. It never fails, so a
+// logging outage never breaks logging.
+func (s *shipper) Write(p []byte) (int, error) {
+	entry := append([]byte(nil), p...)
+	select {
+	case s.queue <- entry:
+		return len(p), nil
+	case <-s.done:
+		s.dropped.Add(1)
+		return len(p), nil
+	default:
+	}
+
+	if s.block {
+		timer := time.NewTimer(s.blockTimeout)
+		defer timer.Stop()
+		select {
+		case s.queue <- entry:
+			return len(p), nil
+		case <-s.done:
+		case <-timer.C:
+		}
+	}
+	s.dropped.Add(1)
+	return len(p), nil
+}
+
+// Sync asks for the queued entries to be shipped without waiting for them
+func (s *shipper) Sync() error {
+	select {
+	case s.flush <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// run batches and ships entries until Stop
+func (s *shipper) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.batchSize)
+	ship := func() {
+		if len(batch) > 0 {
+			s.ship(batch)
+			batch = make([][]byte, 0, s.batchSize)
+		}
+	}
+
+	for {
+		select {
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				ship()
+			}
+		case <-s.flush:
+			ship()
+		case <-ticker.C:
+			ship()
+			s.replay()
+		case <-s.done:
+			for {
+				select {
+				case entry := <-s.queue:
+					batch = append(batch, entry)
+					if len(batch) >= s.batchSize {
+						ship()
+					}
+					continue
+				default:
+				}
+				break
+			}
+			ship()
+			return
+		}
+	}
+}
+
+// ship sends a batch, spooling what could not be shipped
+func (s *shipper) ship(batch [][]byte) {
+	retry, rejected, err := s.sink.send(s.send, batch)
+	if err != nil {
+		s.failed(err)
+		s.spool(batch)
+		return
+	}
+	s.succeeded()
+	s.shipped.Add(int64(len(batch) - len(retry) - rejected))
+	s.rejected.Add(int64(rejected))
+	s.spool(retry)
+}
+
+// spool writes entries to the disk buffer, or drops them without one
+func (s *shipper) spool(lines [][]byte) {
+	if len(lines) == 0 {
+		return
+	}
+	if s.buffer == nil {
+		s.dropped.Add(int64(len(lines)))
+		return
+	}
+	dropped, err := s.buffer.write(lines)
+	s.dropped.Add(int64(dropped))
+	if err != nil {
+		s.failed(err)
+	}
+}
+
+// replay ships spooled batches, oldest first, while shipping works
+func (s *shipper) replay() {
+	if s.buffer == nil || s.err() != nil {
+		return
+	}
+	for i := 0; i < maxReplayBatches; i++ {
+		path, lines, err := s.buffer.oldest()
+		if err != nil {
+			s.failed(err)
+			return
+		}
+		if path == "" {
+			return
+		}
+		retry, rejected, err := s.sink.send(s.send, lines)
+		if err != nil {
+			s.failed(err)
+			return
+		}
+		s.shipped.Add(int64(len(lines) - len(retry) - rejected))
+		s.rejected.Add(int64(rejected))
+		if err := s.buffer.remove(path); err != nil {
+			s.failed(err)
+			return
+		}
+		s.spool(retry)
+	}
+}
+
+// failed records a shipping error, reporting the first of a series
+func (s *shipper) failed(err error) {
+	s.mu.Lock()
+	first := s.lastErr == nil
+	s.lastErr = err
+	s.mu.Unlock()
+	if first {
+		s.onFailure(err)
+	}
+}
+
+// succeeded clears the shipping error, reporting the recovery
+func (s *shipper) succeeded() {
+	s.mu.Lock()
+	recovered := s.lastErr != nil
+	s.lastErr = nil
+	s.mu.Unlock()
+	if recovered {
+		s.onRecovery()
+	}
+}
+
+// err returns the error of the last shipment, nil if it worked
+func (s *shipper) err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// Stop ships the queued entries and stops. When ctx is done, the shipment in progress
+// is cancelled and what is left is spooled.
+func (s *shipper) Stop(ctx context.Context) error {
+	s.stop.Do(func() { close(s.done) })
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-s.stopped
+		return ctx.Err()
+	}
+}
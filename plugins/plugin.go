@@ -2,12 +2,18 @@ package plugins
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/di"
 	"github.com/axiomod/axiomod/framework/health"
 	"github.com/axiomod/axiomod/platform/observability"
+	"github.com/axiomod/axiomod/platform/server"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -16,17 +22,33 @@ import (
 // Module provides the fx options for the plugins module
 var Module = fx.Options(
 	fx.Provide(NewPluginRegistry),
+	server.AsAdminEndpoint(AdminEndpoint),
 	fx.Invoke(RegisterPlugins),
 )
 
+// BootModule returns the module booting the plugin registry, with the plugins of the application
+// registered besides the built-in ones, to be appended to bootstrap.Modules:
+//
+//	modules = append(modules, plugins.BootModule(&ldap.Plugin{}, &audit.Plugin{}))
+func BootModule(registered ...Plugin) *di.Module {
+	return di.NewModule("plugins").
+		Option(Module).
+		Invoke(func(r *PluginRegistry) {
+			for _, plugin := range registered {
+				r.Register(plugin)
+			}
+		}).
+		After("observability", "health")
+}
+
 // RegisterPlugins registers the plugin registry with the fx lifecycle
 func RegisterPlugins(lc fx.Lifecycle, registry *PluginRegistry) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			return registry.StartAll()
+			return registry.StartAll(ctx)
 		},
 		OnStop: func(ctx context.Context) error {
-			return registry.StopAll()
+			return registry.StopAll(ctx)
 		},
 	})
 }
@@ -39,13 +61,37 @@ type Plugin interface {
 	// Initialize initializes the plugin with the given configuration, logger, and metrics
 	Initialize(config map[string]interface{}, logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config, health *health.Health) error
 
-	// Start starts the plugin
-	Start() error
+	// Start starts the plugin. It should return once ctx is done; the registry cancels ctx
+	// when the plugin's start timeout expires.
+	Start(ctx context.Context) error
+
+	// Stop stops the plugin. It should return once ctx is done; the registry cancels ctx
+	// when the plugin's stop timeout expires.
+	Stop(ctx context.Context) error
+}
 
-	// Stop stops the plugin
-	Stop() error
+// Dependent is implemented by plugins that need other plugins, e.g. a database, to be
+// started before them and stopped after them
+type Dependent interface {
+	// DependsOn returns the names of the plugins this plugin needs
+	DependsOn() []string
 }
 
+// Plugin ordering errors
+var (
+	ErrDependencyCycle   = errors.New("plugin dependency cycle")
+	ErrMissingDependency = errors.New("plugin dependency not enabled")
+)
+
+// ErrTimeout is returned when a plugin does not start or stop within its timeout
+var ErrTimeout = errors.New("plugin timed out")
+
+// Default plugin timeouts
+const (
+	DefaultStartTimeout = 10 * time.Second
+	DefaultStopTimeout  = 10 * time.Second
+)
+
 // PluginRegistry manages the registration and lifecycle of plugins
 type PluginRegistry struct {
 	plugins map[string]Plugin
@@ -54,6 +100,11 @@ type PluginRegistry struct {
 	metrics *observability.Metrics
 	health  *health.Health
 	mu      sync.RWMutex
+	started []Plugin                // in start order
+	states  map[string]*pluginState // by plugin name
+	// initialized is set once NewPluginRegistry initialized the plugins registered then;
+	// StartAll initializes the plugins registered after it, such as those of applications
+	initialized bool
 }
 
 // NewPluginRegistry creates a new plugin registry
@@ -73,6 +124,7 @@ func NewPluginRegistry(cfg *config.Config, logger *observability.Logger, metrics
 	if err := registry.initializeEnabledPlugins(); err != nil {
 		return nil, err
 	}
+	registry.initialized = true
 
 	return registry, nil
 }
@@ -98,6 +150,7 @@ func (r *PluginRegistry) Register(plugin Plugin) {
 	defer r.mu.Unlock()
 
 	r.plugins[plugin.Name()] = plugin
+	*r.stateOf(plugin.Name()) = pluginState{state: StateRegistered}
 	r.logger.Info("Registered plugin", zap.String("name", plugin.Name()))
 }
 
@@ -114,18 +167,19 @@ func (r *PluginRegistry) Get(name string) (Plugin, error) {
 	return plugin, nil
 }
 
-// initializeEnabledPlugins initializes all enabled plugins
+// initializeEnabledPlugins initializes the enabled plugins not initialized yet,
+// dependencies first
 func (r *PluginRegistry) initializeEnabledPlugins() error {
-	// Iterate over the map of enabled plugins
-	for name, enabled := range r.config.Plugins.Enabled {
-		if !enabled {
-			continue // Skip disabled plugins
-		}
+	// Plugins registered later may still provide missing dependencies, so only StartAll
+	// requires them
+	order, err := r.order(false)
+	if err != nil {
+		return err
+	}
 
-		plugin, err := r.Get(name)
-		if err != nil {
-			// Log error but continue, maybe plugin wasn't registered
-			r.logger.Error("Plugin defined in config but not found in registry", zap.String("name", name), zap.Error(err))
+	for _, plugin := range order {
+		name := plugin.Name()
+		if r.state(name) != StateRegistered {
 			continue
 		}
 
@@ -137,8 +191,10 @@ func (r *PluginRegistry) initializeEnabledPlugins() error {
 
 		// Initialize plugin
 		if err := plugin.Initialize(pluginSettings, r.logger, r.metrics, r.config, r.health); err != nil {
+			r.setState(name, StateFailed, err)
 			return fmt.Errorf("failed to initialize plugin %s: %w", name, err)
 		}
+		r.setState(name, StateInitialized, nil)
 
 		r.logger.Info("Initialized plugin", zap.String("name", name))
 	}
@@ -146,58 +202,200 @@ func (r *PluginRegistry) initializeEnabledPlugins() error {
 	return nil
 }
 
-// StartAll starts all enabled plugins
-func (r *PluginRegistry) StartAll() error {
+// Order returns the names of the enabled plugins in start order: each plugin after the
+// plugins it depends on. It fails on dependency cycles and on dependencies that are not
+// enabled.
+func (r *PluginRegistry) Order() ([]string, error) {
+	order, err := r.order(true)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(order))
+	for i, plugin := range order {
+		names[i] = plugin.Name()
+	}
+	return names, nil
+}
+
+// dependencies returns the plugins a plugin needs, declared by the plugin or in config
+func (r *PluginRegistry) dependencies(plugin Plugin) []string {
+	var deps []string
+	if dependent, ok := underlying(plugin).(Dependent); ok {
+		deps = append(deps, dependent.DependsOn()...)
+	}
+	return append(deps, r.config.Plugins.DependsOn[plugin.Name()]...)
+}
+
+// order sorts the enabled plugins topologically. Plugins are visited by name, so the order
+// is stable. Without strict, dependencies that are not enabled or registered are ignored.
+func (r *PluginRegistry) order(strict bool) ([]Plugin, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Iterate over the map of enabled plugins
+	names := make([]string, 0, len(r.config.Plugins.Enabled))
 	for name, enabled := range r.config.Plugins.Enabled {
 		if !enabled {
 			continue // Skip disabled plugins
 		}
-
-		plugin, err := r.Get(name)
-		if err != nil {
+		if _, ok := r.plugins[name]; !ok {
 			// Log error but continue, maybe plugin wasn't registered
-			r.logger.Error("Plugin defined in config but not found in registry", zap.String("name", name), zap.Error(err))
+			r.logger.Error("Plugin defined in config but not found in registry", zap.String("name", name))
 			continue
 		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[string]int, len(names))
+	order := make([]Plugin, 0, len(names))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string(nil), path[start:]...), name)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
 
-		if err := plugin.Start(); err != nil {
-			return fmt.Errorf("failed to start plugin %s: %w", name, err)
+		plugin := r.plugins[name]
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dep := range r.dependencies(plugin) {
+			if _, ok := r.plugins[dep]; !ok || !r.config.Plugins.Enabled[dep] {
+				if strict {
+					return fmt.Errorf("%w: %s depends on %s", ErrMissingDependency, name, dep)
+				}
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
 		}
+		path = path[:len(path)-1]
+		marks[name] = visited
+		order = append(order, plugin)
+		return nil
+	}
 
-		r.logger.Info("Started plugin", zap.String("name", name))
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// StartAll starts all enabled plugins, each after the plugins it depends on and within
+// its start timeout. Plugins registered after NewPluginRegistry are initialized first. If a plugin fails to start, the plugins already started are stopped
+// again.
+func (r *PluginRegistry) StartAll(ctx context.Context) error {
+	order, err := r.order(true)
+	if err != nil {
+		return err
+	}
+	if r.initialized {
+		if err := r.initializeEnabledPlugins(); err != nil {
+			return err
+		}
 	}
 
+	started := make([]Plugin, 0, len(order))
+	for _, plugin := range order {
+		name := plugin.Name()
+		begin := time.Now()
+		if err := r.call(ctx, plugin, "start", r.timeout(name, true), plugin.Start); err != nil {
+			r.setState(name, StateFailed, err)
+			// Roll back even if ctx is done; each plugin still has its stop timeout
+			r.stop(context.WithoutCancel(ctx), started)
+			return fmt.Errorf("failed to start plugin %s: %w", name, err)
+		}
+		r.setState(name, StateRunning, nil)
+		r.registerHealthCheck(plugin)
+		started = append(started, plugin)
+		r.logger.Info("Started plugin", zap.String("name", name), zap.Duration("duration", time.Since(begin)))
+	}
+
+	r.mu.Lock()
+	r.started = started
+	r.mu.Unlock()
 	return nil
 }
 
-// StopAll stops all enabled plugins
-func (r *PluginRegistry) StopAll() error {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+// StopAll stops the started plugins in reverse start order, so plugins stop before the
+// plugins they depend on. Every plugin is stopped even if others fail; the failures are
+// returned together.
+func (r *PluginRegistry) StopAll(ctx context.Context) error {
+	r.mu.Lock()
+	started := r.started
+	r.started = nil
+	r.mu.Unlock()
 
-	// Iterate over the map of enabled plugins
-	for name, enabled := range r.config.Plugins.Enabled {
-		if !enabled {
-			continue // Skip disabled plugins
-		}
+	return r.stop(ctx, started)
+}
 
-		plugin, err := r.Get(name)
+// stop stops plugins in reverse order, each within its stop timeout
+func (r *PluginRegistry) stop(ctx context.Context, plugins []Plugin) error {
+	var errs []error
+	for i := len(plugins) - 1; i >= 0; i-- {
+		name := plugins[i].Name()
+		err := r.call(ctx, plugins[i], "stop", r.timeout(name, false), plugins[i].Stop)
+		r.setState(name, StateStopped, err)
 		if err != nil {
-			// Log error but continue, maybe plugin wasn't registered
-			r.logger.Error("Plugin defined in config but not found in registry", zap.String("name", name), zap.Error(err))
-			continue
-		}
-
-		if err := plugin.Stop(); err != nil {
 			r.logger.Error("Failed to stop plugin", zap.String("name", name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to stop plugin %s: %w", name, err))
 		} else {
 			r.logger.Info("Stopped plugin", zap.String("name", name))
 		}
 	}
+	return errors.Join(errs...)
+}
 
-	return nil
+// timeout returns the start or stop timeout of a plugin
+func (r *PluginRegistry) timeout(name string, start bool) time.Duration {
+	cfg := r.config.Plugins
+	seconds, override, fallback := cfg.StopTimeout, cfg.Timeouts[name].Stop, DefaultStopTimeout
+	if start {
+		seconds, override, fallback = cfg.StartTimeout, cfg.Timeouts[name].Start, DefaultStartTimeout
+	}
+	if override > 0 {
+		seconds = override
+	}
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// call runs a plugin's Start or Stop with a timeout. A plugin that ignores its context is
+// abandoned when the timeout expires, so it cannot block the lifecycle.
+func (r *PluginRegistry) call(ctx context.Context, plugin Plugin, op string, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		r.logger.Error("Plugin did not return in time", zap.String("name", plugin.Name()), zap.String("operation", op), zap.Duration("timeout", timeout))
+		return fmt.Errorf("%w: %s did not return within %s: %w", ErrTimeout, op, timeout, ctx.Err())
+	}
 }
@@ -1,13 +1,22 @@
 package plugins
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/axiomod/axiomod/framework/config"
 	"github.com/axiomod/axiomod/framework/health"
 	"github.com/axiomod/axiomod/platform/observability"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockPlugin struct {
@@ -22,11 +31,11 @@ func (m *mockPlugin) Initialize(settings map[string]interface{}, logger *observa
 	m.initialized = true
 	return nil
 }
-func (m *mockPlugin) Start() error {
+func (m *mockPlugin) Start(ctx context.Context) error {
 	m.started = true
 	return nil
 }
-func (m *mockPlugin) Stop() error {
+func (m *mockPlugin) Stop(ctx context.Context) error {
 	m.stopped = true
 	return nil
 }
@@ -65,15 +74,34 @@ func TestPluginRegistry(t *testing.T) {
 		assert.NoError(t, err)
 		assert.True(t, mock.initialized)
 
-		err = registry.StartAll()
+		err = registry.StartAll(context.Background())
 		assert.NoError(t, err)
 		assert.True(t, mock.started)
 
-		err = registry.StopAll()
+		err = registry.StopAll(context.Background())
 		assert.NoError(t, err)
 		assert.True(t, mock.stopped)
 	})
 
+	t.Run("Plugins Registered Later Are Initialized On Start", func(t *testing.T) {
+		metrics, _ := observability.NewMetrics(obsCfg, logger)
+		registry, err := NewPluginRegistry(cfg, logger, metrics, nil)
+		require.NoError(t, err)
+
+		mock := &mockPlugin{name: "mock"}
+		registry.Register(mock)
+		assert.False(t, mock.initialized)
+
+		require.NoError(t, registry.StartAll(context.Background()))
+		assert.True(t, mock.initialized)
+		assert.True(t, mock.started)
+		require.NoError(t, registry.StopAll(context.Background()))
+
+		mock.initialized = false
+		require.NoError(t, registry.StartAll(context.Background()))
+		assert.False(t, mock.initialized, "restarted plugins are not initialized again")
+	})
+
 	t.Run("Get Plugin", func(t *testing.T) {
 		metrics, _ := observability.NewMetrics(obsCfg, logger)
 		registry, _ := NewPluginRegistry(cfg, logger, metrics, nil)
@@ -88,3 +116,335 @@ func TestPluginRegistry(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+// orderedPlugin records its lifecycle calls in a shared log
+type orderedPlugin struct {
+	name     string
+	deps     []string
+	startErr error
+	log      *[]string
+}
+
+func (p *orderedPlugin) Name() string        { return p.name }
+func (p *orderedPlugin) DependsOn() []string { return p.deps }
+func (p *orderedPlugin) Initialize(settings map[string]interface{}, logger *observability.Logger, metrics *observability.Metrics, cfg *config.Config, health *health.Health) error {
+	*p.log = append(*p.log, "init "+p.name)
+	return nil
+}
+func (p *orderedPlugin) Start(ctx context.Context) error {
+	if p.startErr != nil {
+		return p.startErr
+	}
+	*p.log = append(*p.log, "start "+p.name)
+	return nil
+}
+func (p *orderedPlugin) Stop(ctx context.Context) error {
+	*p.log = append(*p.log, "stop "+p.name)
+	return nil
+}
+
+func TestPluginOrdering(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	newRegistry := func(enabled []string, dependsOn map[string][]string, plugins ...*orderedPlugin) *PluginRegistry {
+		cfg := &config.Config{Plugins: config.PluginsConfig{Enabled: map[string]bool{}, DependsOn: dependsOn}}
+		for _, name := range enabled {
+			cfg.Plugins.Enabled[name] = true
+		}
+		registry := &PluginRegistry{plugins: make(map[string]Plugin), config: cfg, logger: logger}
+		for _, plugin := range plugins {
+			registry.Register(plugin)
+		}
+		return registry
+	}
+
+	t.Run("Dependencies Start First And Stop Last", func(t *testing.T) {
+		var log []string
+		registry := newRegistry(
+			[]string{"auditing", "cache", "database"},
+			map[string][]string{"cache": {"database"}},
+			&orderedPlugin{name: "auditing", deps: []string{"database", "cache"}, log: &log},
+			&orderedPlugin{name: "cache", log: &log},
+			&orderedPlugin{name: "database", log: &log},
+		)
+
+		order, err := registry.Order()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"database", "cache", "auditing"}, order)
+
+		require.NoError(t, registry.initializeEnabledPlugins())
+		require.NoError(t, registry.StartAll(context.Background()))
+		require.NoError(t, registry.StopAll(context.Background()))
+		assert.Equal(t, []string{
+			"init database", "init cache", "init auditing",
+			"start database", "start cache", "start auditing",
+			"stop auditing", "stop cache", "stop database",
+		}, log)
+	})
+
+	t.Run("Cycle Is Rejected", func(t *testing.T) {
+		var log []string
+		registry := newRegistry(
+			[]string{"a", "b", "c"},
+			map[string][]string{"c": {"a"}},
+			&orderedPlugin{name: "a", deps: []string{"b"}, log: &log},
+			&orderedPlugin{name: "b", deps: []string{"c"}, log: &log},
+			&orderedPlugin{name: "c", log: &log},
+		)
+
+		_, err := registry.Order()
+		assert.ErrorIs(t, err, ErrDependencyCycle)
+		assert.ErrorContains(t, err, "a -> b -> c -> a")
+		assert.ErrorIs(t, registry.StartAll(context.Background()), ErrDependencyCycle)
+		assert.Empty(t, log)
+	})
+
+	t.Run("Missing Dependency Is Rejected", func(t *testing.T) {
+		var log []string
+		registry := newRegistry(
+			[]string{"auditing"},
+			nil,
+			&orderedPlugin{name: "auditing", deps: []string{"database"}, log: &log},
+			&orderedPlugin{name: "database", log: &log},
+		)
+
+		assert.NoError(t, registry.initializeEnabledPlugins(), "dependencies may still be registered after initialization")
+		err := registry.StartAll(context.Background())
+		assert.ErrorIs(t, err, ErrMissingDependency)
+		assert.ErrorContains(t, err, "auditing depends on database")
+	})
+
+	t.Run("Failed Start Stops Started Plugins", func(t *testing.T) {
+		var log []string
+		registry := newRegistry(
+			[]string{"auditing", "database"},
+			nil,
+			&orderedPlugin{name: "auditing", deps: []string{"database"}, startErr: errors.New("no audit table"), log: &log},
+			&orderedPlugin{name: "database", log: &log},
+		)
+
+		assert.ErrorContains(t, registry.StartAll(context.Background()), "failed to start plugin auditing")
+		assert.Equal(t, []string{"start database", "stop database"}, log)
+		require.NoError(t, registry.StopAll(context.Background()))
+		assert.Len(t, log, 2, "stopped plugins are not stopped again")
+	})
+}
+
+// observedPlugin reports its health and status
+type observedPlugin struct {
+	orderedPlugin
+	healthErr error
+}
+
+func (p *observedPlugin) Health() error { return p.healthErr }
+func (p *observedPlugin) Status() map[string]interface{} {
+	return map[string]interface{}{"connections": 3}
+}
+
+func TestPluginStatuses(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+	h := health.New(logger)
+	cfg := &config.Config{Plugins: config.PluginsConfig{
+		Enabled: map[string]bool{"database": true, "auditing": true},
+		Settings: map[string]map[string]interface{}{
+			"database": {
+				"host":     "db.internal",
+				"password": "hunter2",
+				"replica":  map[string]interface{}{"host": "replica.internal", "apiKey": "abc"},
+			},
+		},
+	}}
+	var log []string
+	database := &observedPlugin{orderedPlugin: orderedPlugin{name: "database", log: &log}}
+	registry := &PluginRegistry{plugins: make(map[string]Plugin), config: cfg, logger: logger, health: h}
+	registry.Register(database)
+	registry.Register(&orderedPlugin{name: "auditing", deps: []string{"database"}, startErr: errors.New("no audit table"), log: &log})
+	registry.Register(&orderedPlugin{name: "cache", log: &log})
+
+	require.NoError(t, registry.initializeEnabledPlugins())
+	before := time.Now()
+	require.Error(t, registry.StartAll(context.Background()))
+
+	// The failed start of auditing stopped database again
+	byName := func() map[string]Status {
+		statuses := make(map[string]Status)
+		for _, status := range registry.Statuses() {
+			statuses[status.Name] = status
+		}
+		return statuses
+	}
+	statuses := byName()
+	assert.Equal(t, StateFailed, statuses["auditing"].State)
+	assert.Equal(t, "no audit table", statuses["auditing"].LastError)
+	assert.Equal(t, StateStopped, statuses["database"].State)
+	assert.Equal(t, StateRegistered, statuses["cache"].State)
+	assert.False(t, statuses["cache"].Enabled)
+	assert.Equal(t, health.StatusUnknown, statuses["cache"].Health)
+
+	// Start again without the failing plugin
+	cfg.Plugins.Enabled["auditing"] = false
+	require.NoError(t, registry.StartAll(context.Background()))
+	statuses = byName()
+	status := statuses["database"]
+	assert.True(t, status.Enabled)
+	assert.Equal(t, StateRunning, status.State)
+	assert.Equal(t, health.StatusUp, status.Health)
+	require.NotNil(t, status.StartedAt)
+	assert.False(t, status.StartedAt.Before(before))
+	assert.Equal(t, map[string]interface{}{"connections": 3}, status.Details)
+	assert.Equal(t, map[string]interface{}{
+		"host":     "db.internal",
+		"password": "[REDACTED]",
+		"replica":  map[string]interface{}{"host": "replica.internal", "apiKey": "[REDACTED]"},
+	}, status.Settings)
+	assert.Equal(t, "hunter2", cfg.Plugins.Settings["database"]["password"], "settings are not modified")
+
+	// Failing health checks show in the status and in framework/health
+	database.healthErr = errors.New("connection refused")
+	h.RunChecks()
+	assert.Equal(t, health.StatusDown, h.GetResponse().Components["plugin_database"].Status)
+	status = byName()["database"]
+	assert.Equal(t, health.StatusDown, status.Health)
+	assert.Equal(t, "connection refused", status.LastError)
+}
+
+func TestAdminEndpoint(t *testing.T) {
+	cfg := &config.Config{}
+	logger, _ := observability.NewLogger(cfg)
+	metrics, _ := observability.NewMetrics(cfg, logger)
+	registry, err := NewPluginRegistry(cfg, logger, metrics, health.New(logger))
+	require.NoError(t, err)
+
+	endpoint := AdminEndpoint(registry)
+	assert.Equal(t, "/admin/plugins", endpoint.Path)
+	app := fiber.New()
+	app.Get(endpoint.Path, endpoint.Handler)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/plugins", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `{"name":"jwt","enabled":false,"state":"registered","health":"UNKNOWN"}`)
+}
+
+// hangingPlugin blocks in Start until its context is done, or until release is closed if
+// it ignores the context
+type hangingPlugin struct {
+	orderedPlugin
+	release chan struct{}
+	stopErr error
+}
+
+func (p *hangingPlugin) Start(ctx context.Context) error {
+	if p.release != nil {
+		<-p.release
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (p *hangingPlugin) Stop(ctx context.Context) error { return p.stopErr }
+
+// legacyStub implements the plugin interface without contexts
+type legacyStub struct {
+	orderedPlugin
+	block chan struct{}
+}
+
+func (p *legacyStub) Start() error {
+	if p.block != nil {
+		<-p.block
+	}
+	return p.orderedPlugin.Start(context.Background())
+}
+func (p *legacyStub) Stop() error { return p.orderedPlugin.Stop(context.Background()) }
+
+func TestPluginTimeouts(t *testing.T) {
+	logger, _ := observability.NewLogger(&config.Config{})
+
+	newRegistry := func(plugins ...Plugin) *PluginRegistry {
+		cfg := &config.Config{Plugins: config.PluginsConfig{Enabled: map[string]bool{}}}
+		registry := &PluginRegistry{plugins: make(map[string]Plugin), config: cfg, logger: logger}
+		for _, plugin := range plugins {
+			cfg.Plugins.Enabled[plugin.Name()] = true
+			registry.Register(plugin)
+		}
+		return registry
+	}
+
+	t.Run("Timeouts From Config", func(t *testing.T) {
+		registry := newRegistry()
+		registry.config.Plugins.StopTimeout = 3
+		registry.config.Plugins.Timeouts = map[string]config.PluginTimeouts{"database": {Start: 60}}
+
+		assert.Equal(t, DefaultStartTimeout, registry.timeout("cache", true))
+		assert.Equal(t, 3*time.Second, registry.timeout("cache", false))
+		assert.Equal(t, time.Minute, registry.timeout("database", true))
+		assert.Equal(t, 3*time.Second, registry.timeout("database", false))
+	})
+
+	for _, ignoreContext := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Hanging Start Is Attributed (ignores context: %t)", ignoreContext), func(t *testing.T) {
+			var log []string
+			search := &hangingPlugin{orderedPlugin: orderedPlugin{name: "search", deps: []string{"database"}, log: &log}}
+			if ignoreContext {
+				search.release = make(chan struct{})
+				defer close(search.release)
+			}
+			registry := newRegistry(&orderedPlugin{name: "database", log: &log}, search)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := registry.StartAll(ctx)
+			assert.ErrorIs(t, err, ErrTimeout)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.ErrorContains(t, err, "failed to start plugin search")
+			assert.Equal(t, []string{"start database", "stop database"}, log, "started plugins are stopped even though ctx is done")
+
+			statuses := registry.Statuses()
+			assert.Equal(t, StateFailed, statuses[1].State)
+			assert.Contains(t, statuses[1].LastError, "plugin timed out")
+		})
+	}
+
+	t.Run("Stop Failures Are Joined", func(t *testing.T) {
+		var log []string
+		registry := newRegistry(
+			&hangingPlugin{orderedPlugin: orderedPlugin{name: "cache", log: &log}, stopErr: errors.New("flush failed")},
+			&hangingPlugin{orderedPlugin: orderedPlugin{name: "queue", log: &log}, stopErr: errors.New("drain failed")},
+		)
+		for _, plugin := range registry.plugins {
+			registry.started = append(registry.started, plugin)
+		}
+
+		err := registry.StopAll(context.Background())
+		assert.ErrorContains(t, err, "failed to stop plugin cache: flush failed")
+		assert.ErrorContains(t, err, "failed to stop plugin queue: drain failed")
+	})
+
+	t.Run("Legacy Plugins Are Adapted", func(t *testing.T) {
+		var log []string
+		legacy := &legacyStub{orderedPlugin: orderedPlugin{name: "database", log: &log}}
+		dependent := Adapt(&legacyStub{orderedPlugin: orderedPlugin{name: "auditing", deps: []string{"database"}, log: &log}})
+		registry := newRegistry(Adapt(legacy), dependent)
+
+		order, err := registry.Order()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"database", "auditing"}, order, "optional interfaces of adapted plugins are used")
+
+		require.NoError(t, registry.StartAll(context.Background()))
+		require.NoError(t, registry.StopAll(context.Background()))
+		assert.Equal(t, []string{"start database", "start auditing", "stop auditing", "stop database"}, log)
+	})
+
+	t.Run("Hanging Legacy Plugin Is Abandoned", func(t *testing.T) {
+		var log []string
+		block := make(chan struct{})
+		defer close(block)
+		registry := newRegistry(Adapt(&legacyStub{orderedPlugin: orderedPlugin{name: "search", log: &log}, block: block}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, registry.StartAll(ctx), ErrTimeout)
+	})
+}
@@ -0,0 +1,238 @@
+package plugins
+
+import (
+	"sort"
+	"time"
+
+	"github.com/axiomod/axiomod/framework/config"
+	"github.com/axiomod/axiomod/framework/health"
+	"github.com/axiomod/axiomod/platform/server"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthChecker is implemented by plugins that can check whether they work, e.g. whether
+// their connection is up. Plugins without it are healthy while they run.
+type HealthChecker interface {
+	// Health returns an error when the plugin does not work
+	Health() error
+}
+
+// StatusReporter is implemented by plugins that report details of their state, such as
+// pool sizes or the backend in use, for the admin API
+type StatusReporter interface {
+	// Status returns details of the plugin's state
+	Status() map[string]interface{}
+}
+
+// State is the lifecycle state of a plugin
+type State string
+
+const (
+	// StateRegistered means the plugin is registered but was not initialized, e.g. because it is disabled
+	StateRegistered State = "registered"
+	// StateInitialized means the plugin is initialized but not started
+	StateInitialized State = "initialized"
+	// StateRunning means the plugin is started
+	StateRunning State = "running"
+	// StateStopped means the plugin was stopped
+	StateStopped State = "stopped"
+	// StateFailed means the plugin failed to initialize or start
+	StateFailed State = "failed"
+)
+
+// Status describes a plugin at a point in time
+type Status struct {
+	Name      string                 `json:"name"`
+	Enabled   bool                   `json:"enabled"`
+	State     State                  `json:"state"`
+	Health    health.Status          `json:"health"`
+	DependsOn []string               `json:"dependsOn,omitempty"`
+	Settings  map[string]interface{} `json:"settings,omitempty"` // secrets redacted
+	StartedAt *time.Time             `json:"startedAt,omitempty"`
+	LastError string                 `json:"lastError,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// pluginState tracks the lifecycle of a registered plugin
+type pluginState struct {
+	state     State
+	startedAt time.Time
+	lastError string
+}
+
+// healthCheckName returns the name of a plugin's check in framework/health
+func healthCheckName(name string) string {
+	return "plugin_" + name
+}
+
+// setState records a lifecycle change of a plugin, with the error that caused it if any
+func (r *PluginRegistry) setState(name string, state State, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stateOf(name)
+	s.state = state
+	if state == StateRunning {
+		s.startedAt = time.Now()
+	}
+	if err != nil {
+		s.lastError = err.Error()
+	}
+}
+
+// state returns the lifecycle state of a plugin
+func (r *PluginRegistry) state(name string) State {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if s, ok := r.states[name]; ok {
+		return s.state
+	}
+	return StateRegistered
+}
+
+// recordError records an error of a plugin without changing its state
+func (r *PluginRegistry) recordError(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stateOf(name).lastError = err.Error()
+}
+
+// stateOf returns the tracked state of a plugin; r.mu must be held for writing
+func (r *PluginRegistry) stateOf(name string) *pluginState {
+	if r.states == nil {
+		r.states = make(map[string]*pluginState)
+	}
+	s, ok := r.states[name]
+	if !ok {
+		s = &pluginState{state: StateRegistered}
+		r.states[name] = s
+	}
+	return s
+}
+
+// registerHealthCheck adds the health check of a started plugin to framework/health
+func (r *PluginRegistry) registerHealthCheck(plugin Plugin) {
+	checker, ok := underlying(plugin).(HealthChecker)
+	if !ok || r.health == nil {
+		return
+	}
+	name := plugin.Name()
+	r.health.RegisterCheck(healthCheckName(name), func() error {
+		err := checker.Health()
+		if err != nil {
+			r.recordError(name, err)
+		}
+		return err
+	})
+}
+
+// AdminEndpoint serves the statuses of the plugins at /admin/plugins
+func AdminEndpoint(registry *PluginRegistry) server.AdminEndpoint {
+	return server.AdminEndpoint{
+		Path: "/admin/plugins",
+		Handler: func(c *fiber.Ctx) error {
+			return c.JSON(fiber.Map{"plugins": registry.Statuses()})
+		},
+	}
+}
+
+// Statuses returns the status of every registered plugin, sorted by name. Health is
+// checked live for running plugins.
+func (r *PluginRegistry) Statuses() []Status {
+	r.mu.RLock()
+	plugins := make([]Plugin, 0, len(r.plugins))
+	for _, plugin := range r.plugins {
+		plugins = append(plugins, plugin)
+	}
+	states := make(map[string]pluginState, len(r.states))
+	for name, s := range r.states {
+		states[name] = *s
+	}
+	r.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(plugins))
+	for _, plugin := range plugins {
+		name := plugin.Name()
+		s, ok := states[name]
+		if !ok {
+			s.state = StateRegistered
+		}
+
+		status := Status{
+			Name:      name,
+			Enabled:   r.config.Plugins.Enabled[name],
+			State:     s.state,
+			Health:    health.StatusUnknown,
+			DependsOn: r.dependencies(plugin),
+			Settings:  redactSettings(r.config.Plugins.Settings[name]),
+			LastError: s.lastError,
+		}
+		if !s.startedAt.IsZero() {
+			startedAt := s.startedAt
+			status.StartedAt = &startedAt
+		}
+		if s.state == StateRunning {
+			status.Health = health.StatusUp
+			if checker, ok := underlying(plugin).(HealthChecker); ok {
+				if err := checker.Health(); err != nil {
+					r.recordError(name, err)
+					status.Health = health.StatusDown
+					status.LastError = err.Error()
+				}
+			}
+		}
+		if reporter, ok := underlying(plugin).(StatusReporter); ok {
+			status.Details = reporter.Status()
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// redacted replaces secret setting values
+const redacted = "[REDACTED]"
+
+// redactSettings copies plugin settings, replacing the values of secret keys at any depth
+func redactSettings(settings map[string]interface{}) map[string]interface{} {
+	if len(settings) == 0 {
+		return nil
+	}
+	redactedSettings := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if config.IsSecretKey(key) {
+			redactedSettings[key] = redacted
+			continue
+		}
+		redactedSettings[key] = redactValue(value)
+	}
+	return redactedSettings
+}
+
+// redactValue redacts the secrets of nested settings
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactSettings(v)
+	case map[interface{}]interface{}:
+		settings := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if s, ok := key.(string); ok {
+				settings[s] = value
+			}
+		}
+		return redactSettings(settings)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = redactValue(item)
+		}
+		return values
+	default:
+		return value
+	}
+}